@@ -0,0 +1,50 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/dstathis/openswiss/internal/auth"
+	"github.com/dstathis/openswiss/internal/db"
+)
+
+// bootstrapAdmin creates or promotes the admin account named by ADMIN_EMAIL,
+// so a fresh deployment has an admin without hand-written SQL. loadConfig
+// has already checked the hash is there. Neither the password nor its hash
+// is ever logged.
+func bootstrapAdmin(ctx context.Context, database *sql.DB, cfg *config) error {
+	if cfg.AdminEmail == "" {
+		return nil
+	}
+	created, err := db.EnsureAdminUser(ctx, database, cfg.AdminEmail, cfg.AdminDisplayName, cfg.AdminPasswordHash)
+	if err != nil {
+		return fmt.Errorf("ensure admin user: %w", err)
+	}
+	if created {
+		slog.Info("bootstrap admin created", "email", cfg.AdminEmail)
+	}
+	return nil
+}
+
+// runHashPassword reads a password from the first line of stdin and prints
+// its bcrypt hash, for use as ADMIN_PASSWORD_HASH.
+func runHashPassword(_ []string) {
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		fatal("read password from stdin", "err", err)
+	}
+	password := strings.TrimRight(line, "\r\n")
+	if len(password) < 8 {
+		fatal("password must be at least 8 characters")
+	}
+	hash, err := auth.HashPassword(password)
+	if err != nil {
+		fatal("hash password", "err", err)
+	}
+	fmt.Println(hash)
+}
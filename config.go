@@ -0,0 +1,452 @@
+package main
+
+import (
+	"bufio"
+	"crypto/ed25519"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dstathis/openswiss/internal/auth"
+	"github.com/dstathis/openswiss/internal/discord"
+	"github.com/dstathis/openswiss/internal/email"
+	"github.com/dstathis/openswiss/internal/i18n"
+	mw "github.com/dstathis/openswiss/internal/middleware"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/dstathis/openswiss/internal/stripe"
+)
+
+// A setting is one configuration value, named as its environment variable.
+// It can also be given in the config file, as its name in lower case
+// (listen_addr), or under a [section] named after its first word (host
+// under [smtp] for SMTP_HOST); and as a flag, in lower case with dashes
+// (-listen-addr). Flags win over the environment, which wins over the file.
+type setting struct {
+	name  string
+	def   string
+	usage string
+}
+
+var settings = []setting{
+	{"DATABASE_URL", "", "PostgreSQL connection string (required)"},
+	{"LISTEN_ADDR", ":8080", "address the server listens on"},
+	{"BASE_URL", "http://localhost:8080", "public URL, used in emails and links"},
+	{"SECURE_COOKIES", "true", "mark cookies Secure; false for plain HTTP"},
+	{"TLS_CERT_FILE", "", "serve HTTPS with this certificate (with TLS_KEY_FILE)"},
+	{"TLS_KEY_FILE", "", "private key of TLS_CERT_FILE"},
+	{"RATE_LIMIT_PER_MIN", "60", "API and kiosk requests per IP per minute"},
+	{"AUTH_RATE_LIMIT_PER_MIN", "10", "login, registration and reset requests per IP per minute"},
+	{"TRUSTED_PROXIES", "", "comma-separated CIDRs allowed to set X-Forwarded-For"},
+	{"ADMIN_ALLOWLIST", "", "comma-separated CIDRs the admin pages and login are limited to; empty for anywhere"},
+	{"REQUEST_TIMEOUT", "30s", "deadline for each request's database work; 0 for none"},
+	{"SNAPSHOT_INTERVAL", "5m", "how often changed tournaments are snapshotted; 0 turns it off"},
+	{"SNAPSHOT_KEEP", "50", "snapshots kept per tournament"},
+	{"LOCALE", "", "serve every page in this language instead of negotiating"},
+	{"READ_ONLY", "false", "start in read-only mode"},
+	{"READ_ONLY_REASON", "", "reason shown while read-only"},
+	{"DEV", "false", "read templates and static files from the working directory on every request, uncached"},
+	{"ADMIN_EMAIL", "", "create or promote this admin account on startup"},
+	{"ADMIN_DISPLAY_NAME", "Admin", "display name of a created admin account"},
+	{"ADMIN_PASSWORD_HASH", "", "bcrypt hash of the admin's password"},
+	{"ADMIN_PASSWORD_HASH_FILE", "", "file holding ADMIN_PASSWORD_HASH instead"},
+	{"SMTP_HOST", "", "mail server; emails are off without it"},
+	{"SMTP_PORT", "587", "mail server port"},
+	{"SMTP_USER", "", "mail server user"},
+	{"SMTP_PASSWORD", "", "mail server password"},
+	{"SMTP_FROM", "", "sender address of emails"},
+	{"WEBHOOK_ALLOW_PRIVATE", "false", "let webhooks reach loopback and private addresses"},
+	{"DISCORD_PUBLIC_KEY", "", "Discord application public key, for slash commands"},
+	{"STRIPE_SECRET_KEY", "", "Stripe secret key; players pay entry fees online with it"},
+	{"STRIPE_WEBHOOK_SECRET", "", "signing secret of the Stripe webhook endpoint"},
+	{"STRIPE_CURRENCY", "usd", "currency entry fees are charged in through Stripe"},
+	{"TOURNAMENT_TIME_ZONE", models.StandardDefaults.TimeZone, "time zone new tournaments start with"},
+	{"TOURNAMENT_MAX_PLAYERS", "0", "player cap new tournaments start with; 0 for none"},
+	{"TOURNAMENT_POINTS_WIN", "3", "points for a win in new tournaments"},
+	{"TOURNAMENT_POINTS_DRAW", "1", "points for a draw in new tournaments"},
+	{"TOURNAMENT_POINTS_LOSS", "0", "points for a loss in new tournaments"},
+	{"TOURNAMENT_TIEBREAKERS", "", "tiebreak order new tournaments start with (empty: omw, gw, ogw)"},
+}
+
+// config is the validated configuration.
+type config struct {
+	DatabaseURL         string
+	ListenAddr          string
+	BaseURL             string
+	SecureCookies       bool
+	TLSCertFile         string
+	TLSKeyFile          string
+	RateLimit           int
+	AuthRateLimit       int
+	TrustedProxies      []*net.IPNet
+	AdminAllowlist      []*net.IPNet
+	RequestTimeout      time.Duration
+	SnapshotInterval    time.Duration
+	SnapshotKeep        int
+	Locale              string
+	ReadOnly            bool
+	ReadOnlyReason      string
+	Dev                 bool
+	AdminEmail          string
+	AdminDisplayName    string
+	AdminPasswordHash   string
+	SMTP                email.Config
+	WebhookAllowPrivate bool
+	DiscordPublicKey    ed25519.PublicKey
+	Stripe              stripe.Config
+	Defaults            models.TournamentDefaults
+}
+
+// loadConfig reads the configuration of the command run with args: the file
+// named by -config or OPENSWISS_CONFIG, then the environment (as read by
+// getenv; empty counts as unset), then the flags in args. Every invalid
+// value is reported, not just the first. Usage for -h and bad flags goes
+// to out. A command with flags of its own defines them on the flag set with
+// define.
+func loadConfig(cmd string, args []string, getenv func(string) string, out io.Writer, define ...func(*flag.FlagSet)) (*config, error) {
+	fs := flag.NewFlagSet("openswiss "+cmd, flag.ContinueOnError)
+	fs.SetOutput(out)
+	path := fs.String("config", getenv("OPENSWISS_CONFIG"), "config file (TOML)")
+	for _, d := range define {
+		d(fs)
+	}
+	flags := make(map[string]*settingFlag, len(settings))
+	for _, s := range settings {
+		f := &settingFlag{boolean: s.def == "true" || s.def == "false"}
+		fs.Var(f, flagName(s.name), s.usage+" ["+s.name+"]")
+		flags[s.name] = f
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if fs.NArg() > 0 {
+		return nil, fmt.Errorf("unexpected argument %q", fs.Arg(0))
+	}
+
+	values := make(map[string]string, len(settings))
+	for _, s := range settings {
+		values[s.name] = s.def
+	}
+	if *path != "" {
+		file, err := readConfigFile(*path)
+		if err != nil {
+			return nil, err
+		}
+		for name, v := range file {
+			values[name] = v
+		}
+	}
+	for _, s := range settings {
+		if v := getenv(s.name); v != "" {
+			values[s.name] = v
+		}
+	}
+	fs.Visit(func(f *flag.Flag) {
+		name := strings.ToUpper(strings.ReplaceAll(f.Name, "-", "_"))
+		if f, ok := flags[name]; ok {
+			values[name] = f.value
+		}
+	})
+	return parseConfig(values)
+}
+
+// mustLoadConfig loads the configuration of cmd from its arguments and the
+// environment, exiting with every problem found if it is invalid.
+func mustLoadConfig(cmd string, args []string, define ...func(*flag.FlagSet)) *config {
+	cfg, err := loadConfig(cmd, args, os.Getenv, os.Stderr, define...)
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	}
+	if err != nil {
+		fatal("invalid configuration", "err", err)
+	}
+	return cfg
+}
+
+// settingFlag is a setting's flag. The flag of a true or false setting
+// can be given bare, -dev for -dev=true.
+type settingFlag struct {
+	value   string
+	boolean bool
+}
+
+func (f *settingFlag) String() string {
+	if f == nil {
+		return ""
+	}
+	return f.value
+}
+
+func (f *settingFlag) Set(v string) error {
+	f.value = v
+	return nil
+}
+
+func (f *settingFlag) IsBoolFlag() bool { return f.boolean }
+
+func flagName(setting string) string {
+	return strings.ReplaceAll(strings.ToLower(setting), "_", "-")
+}
+
+// parseConfig checks and converts the settings' values.
+func parseConfig(v map[string]string) (*config, error) {
+	var errs []error
+	bad := func(name string, format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%s: %s", name, fmt.Sprintf(format, args...)))
+	}
+	boolean := func(name string) bool {
+		b, err := strconv.ParseBool(v[name])
+		if err != nil {
+			bad(name, "%q is not true or false", v[name])
+		}
+		return b
+	}
+	// count parses a whole number of at least min.
+	count := func(name string, min int) int {
+		n, err := strconv.Atoi(v[name])
+		if err != nil || n < min {
+			bad(name, "%q is not a whole number of at least %d", v[name], min)
+		}
+		return n
+	}
+	duration := func(name string) time.Duration {
+		d, err := time.ParseDuration(v[name])
+		if err != nil || d < 0 {
+			bad(name, "%q is not a duration such as 30s or 5m", v[name])
+		}
+		return d
+	}
+
+	c := &config{
+		DatabaseURL:      v["DATABASE_URL"],
+		ListenAddr:       v["LISTEN_ADDR"],
+		BaseURL:          strings.TrimRight(v["BASE_URL"], "/"),
+		SecureCookies:    boolean("SECURE_COOKIES"),
+		TLSCertFile:      v["TLS_CERT_FILE"],
+		TLSKeyFile:       v["TLS_KEY_FILE"],
+		RateLimit:        count("RATE_LIMIT_PER_MIN", 1),
+		AuthRateLimit:    count("AUTH_RATE_LIMIT_PER_MIN", 1),
+		RequestTimeout:   duration("REQUEST_TIMEOUT"),
+		SnapshotInterval: duration("SNAPSHOT_INTERVAL"),
+		SnapshotKeep:     count("SNAPSHOT_KEEP", 1),
+		Locale:           v["LOCALE"],
+		ReadOnly:         boolean("READ_ONLY"),
+		ReadOnlyReason:   v["READ_ONLY_REASON"],
+		Dev:              boolean("DEV"),
+		AdminEmail:       v["ADMIN_EMAIL"],
+		AdminDisplayName: v["ADMIN_DISPLAY_NAME"],
+		SMTP: email.Config{
+			Host:     v["SMTP_HOST"],
+			Port:     v["SMTP_PORT"],
+			User:     v["SMTP_USER"],
+			Password: v["SMTP_PASSWORD"],
+			From:     v["SMTP_FROM"],
+		},
+		WebhookAllowPrivate: boolean("WEBHOOK_ALLOW_PRIVATE"),
+		Stripe: stripe.Config{
+			SecretKey:     v["STRIPE_SECRET_KEY"],
+			WebhookSecret: v["STRIPE_WEBHOOK_SECRET"],
+			Currency:      strings.ToLower(v["STRIPE_CURRENCY"]),
+		},
+		Defaults: models.TournamentDefaults{
+			TimeZone:   v["TOURNAMENT_TIME_ZONE"],
+			MaxPlayers: count("TOURNAMENT_MAX_PLAYERS", 0),
+			PointsWin:  count("TOURNAMENT_POINTS_WIN", 0),
+			PointsDraw: count("TOURNAMENT_POINTS_DRAW", 0),
+			PointsLoss: count("TOURNAMENT_POINTS_LOSS", 0),
+		},
+	}
+
+	if c.DatabaseURL == "" {
+		bad("DATABASE_URL", "required")
+	}
+	if c.ListenAddr == "" {
+		bad("LISTEN_ADDR", "required")
+	}
+	if u, err := url.Parse(c.BaseURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		bad("BASE_URL", "%q is not an http or https URL", c.BaseURL)
+	}
+	if (c.TLSCertFile == "") != (c.TLSKeyFile == "") {
+		bad("TLS_CERT_FILE", "TLS_CERT_FILE and TLS_KEY_FILE go together")
+	}
+	for _, name := range []string{"TLS_CERT_FILE", "TLS_KEY_FILE"} {
+		if v[name] == "" {
+			continue
+		}
+		if _, err := os.Stat(v[name]); err != nil {
+			bad(name, "%v", err)
+		}
+	}
+	var err error
+	if c.TrustedProxies, err = mw.ParseTrustedProxies(v["TRUSTED_PROXIES"]); err != nil {
+		bad("TRUSTED_PROXIES", "%v", err)
+	}
+	if c.AdminAllowlist, err = mw.ParseTrustedProxies(v["ADMIN_ALLOWLIST"]); err != nil {
+		bad("ADMIN_ALLOWLIST", "%v", err)
+	}
+	if c.Locale != "" && !i18n.Valid(c.Locale) {
+		bad("LOCALE", "%q is not one of %v", c.Locale, i18n.Supported)
+	}
+	if c.AdminPasswordHash, err = adminPasswordHash(v["ADMIN_PASSWORD_HASH"], v["ADMIN_PASSWORD_HASH_FILE"]); err != nil {
+		bad("ADMIN_PASSWORD_HASH", "%v", err)
+	} else if c.AdminEmail != "" && c.AdminPasswordHash == "" {
+		bad("ADMIN_EMAIL", "set, but neither ADMIN_PASSWORD_HASH nor ADMIN_PASSWORD_HASH_FILE is")
+	}
+	if _, err := strconv.ParseUint(c.SMTP.Port, 10, 16); err != nil {
+		bad("SMTP_PORT", "%q is not a port number", c.SMTP.Port)
+	}
+	if (c.SMTP.Host == "") != (c.SMTP.From == "") {
+		bad("SMTP_HOST", "SMTP_HOST and SMTP_FROM go together")
+	}
+	if key := v["DISCORD_PUBLIC_KEY"]; key != "" {
+		if c.DiscordPublicKey, err = discord.ParsePublicKey(key); err != nil {
+			bad("DISCORD_PUBLIC_KEY", "%v", err)
+		}
+	}
+	if (c.Stripe.SecretKey == "") != (c.Stripe.WebhookSecret == "") {
+		bad("STRIPE_SECRET_KEY", "STRIPE_SECRET_KEY and STRIPE_WEBHOOK_SECRET go together")
+	}
+	if !stripe.ValidCurrency(c.Stripe.Currency) {
+		bad("STRIPE_CURRENCY", "%q is not a three-letter currency code such as usd", v["STRIPE_CURRENCY"])
+	}
+	c.Defaults.Tiebreakers = models.ParseTiebreakers(v["TOURNAMENT_TIEBREAKERS"])
+	if err := c.Defaults.Validate(); err != nil {
+		bad("TOURNAMENT_*", "%v", err)
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return c, nil
+}
+
+// adminPasswordHash returns the bootstrap admin's bcrypt hash: hash, or
+// failing that the contents of the file at path (e.g. a Docker secret).
+// Returns "" when neither is set.
+func adminPasswordHash(hash, path string) (string, error) {
+	if hash == "" {
+		if path == "" {
+			return "", nil
+		}
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("read ADMIN_PASSWORD_HASH_FILE: %w", err)
+		}
+		hash = strings.TrimSpace(string(b))
+	}
+	if err := auth.ValidatePasswordHash(hash); err != nil {
+		return "", fmt.Errorf("admin password hash: %w", err)
+	}
+	return hash, nil
+}
+
+var configKeyRe = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// readConfigFile reads a config file: TOML with [sections] and key = value
+// pairs, where values are strings, numbers or booleans. Every key must name
+// a setting, and none may be given twice.
+func readConfigFile(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("config file: %w", err)
+	}
+	defer f.Close()
+	values, err := parseConfigFile(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return values, nil
+}
+
+func parseConfigFile(r io.Reader) (map[string]string, error) {
+	known := make(map[string]bool, len(settings))
+	for _, s := range settings {
+		known[s.name] = true
+	}
+	values := make(map[string]string)
+	section := ""
+	sc := bufio.NewScanner(r)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			name, rest, ok := strings.Cut(line[1:], "]")
+			rest = strings.TrimSpace(rest)
+			if !ok || !configKeyRe.MatchString(name) || (rest != "" && !strings.HasPrefix(rest, "#")) {
+				return nil, fmt.Errorf("line %d: bad section header %s", n, line)
+			}
+			section = name + "_"
+			continue
+		}
+		key, raw, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || !configKeyRe.MatchString(key) {
+			return nil, fmt.Errorf("line %d: expected key = value", n)
+		}
+		value, err := configValue(strings.TrimSpace(raw))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s: %v", n, key, err)
+		}
+		name := strings.ToUpper(section + key)
+		if !known[name] {
+			return nil, fmt.Errorf("line %d: unknown setting %s", n, section+key)
+		}
+		if _, dup := values[name]; dup {
+			return nil, fmt.Errorf("line %d: %s is set twice", n, section+key)
+		}
+		values[name] = value
+	}
+	return values, sc.Err()
+}
+
+// configValue decodes a TOML value: a "basic" or 'literal' string, or a
+// bare number or boolean, any of them followed by a # comment.
+func configValue(raw string) (string, error) {
+	switch {
+	case strings.HasPrefix(raw, `"`):
+		for i := 1; i < len(raw); i++ {
+			switch raw[i] {
+			case '\\':
+				i++
+			case '"':
+				s, err := strconv.Unquote(raw[:i+1])
+				if err != nil {
+					return "", errors.New("bad string")
+				}
+				return s, trailing(raw[i+1:])
+			}
+		}
+		return "", errors.New("unterminated string")
+	case strings.HasPrefix(raw, "'"):
+		end := strings.IndexByte(raw[1:], '\'')
+		if end < 0 {
+			return "", errors.New("unterminated string")
+		}
+		return raw[1 : end+1], trailing(raw[end+2:])
+	}
+	value, _, _ := strings.Cut(raw, "#")
+	value = strings.TrimSpace(value)
+	if value != "true" && value != "false" {
+		if _, err := strconv.ParseFloat(strings.ReplaceAll(value, "_", ""), 64); err != nil {
+			return "", fmt.Errorf("%q is not a string, number or boolean; quote strings", value)
+		}
+	}
+	return strings.ReplaceAll(value, "_", ""), nil
+}
+
+// trailing checks that only a comment follows a value.
+func trailing(rest string) error {
+	if rest = strings.TrimSpace(rest); rest != "" && !strings.HasPrefix(rest, "#") {
+		return fmt.Errorf("unexpected %q after the value", rest)
+	}
+	return nil
+}
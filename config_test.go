@@ -0,0 +1,219 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dstathis/openswiss/internal/auth"
+)
+
+func TestAdminPasswordHash(t *testing.T) {
+	hash, err := auth.HashPassword("bootstrap-pass")
+	if err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(t.TempDir(), "admin_hash")
+	if err := os.WriteFile(file, []byte(hash+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		env     string
+		file    string
+		want    string
+		wantErr bool
+	}{
+		{"unset", "", "", "", false},
+		{"env", hash, "", hash, false},
+		{"file, trailing newline trimmed", "", file, hash, false},
+		{"env wins over file", hash, "/nonexistent", hash, false},
+		{"plaintext rejected", "hunter22", "", "", true},
+		{"missing file", "", "/nonexistent", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := adminPasswordHash(tt.env, tt.file)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("hash = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// env returns a getenv over vars.
+func env(vars map[string]string) func(string) string {
+	return func(name string) string { return vars[name] }
+}
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "openswiss.toml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadConfig_Defaults(t *testing.T) {
+	cfg, err := loadConfig("serve", nil, env(map[string]string{"DATABASE_URL": "postgres://db"}), io.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.ListenAddr != ":8080" || cfg.BaseURL != "http://localhost:8080" || !cfg.SecureCookies || cfg.Dev {
+		t.Errorf("server defaults = %+v", cfg)
+	}
+	if cfg.RateLimit != 60 || cfg.AuthRateLimit != 10 || cfg.RequestTimeout != 30*time.Second {
+		t.Errorf("limits = %d, %d, %v", cfg.RateLimit, cfg.AuthRateLimit, cfg.RequestTimeout)
+	}
+	if cfg.SMTP.Port != "587" || cfg.AdminDisplayName != "Admin" {
+		t.Errorf("SMTP port %q, admin name %q", cfg.SMTP.Port, cfg.AdminDisplayName)
+	}
+	if cfg.Stripe.Enabled() || cfg.Stripe.Currency != "usd" {
+		t.Errorf("stripe = %+v, want off, in usd", cfg.Stripe)
+	}
+	if cfg.Defaults.TimeZone != "UTC" || cfg.Defaults.PointsWin != 3 || cfg.Defaults.PointsDraw != 1 || len(cfg.Defaults.Tiebreakers) != 0 {
+		t.Errorf("tournament defaults = %+v", cfg.Defaults)
+	}
+}
+
+func TestLoadConfig_Precedence(t *testing.T) {
+	path := writeConfig(t, `
+# Settings with no section are named as the environment variable, in lower case.
+database_url = "postgres://file"
+listen_addr = ":7000"
+base_url = 'https://swiss.example.com/' # trailing slash trimmed
+rate_limit_per_min = 1_000
+
+[smtp]
+host = "mail.example.com"
+from = "OpenSwiss <noreply@example.com>"
+
+[stripe]
+secret_key = "sk_test_123"
+webhook_secret = "whsec_123"
+currency = "EUR"
+
+[tournament]
+time_zone = "Europe/Berlin"
+points_win = 2
+tiebreakers = "gw, omw"
+`)
+	cfg, err := loadConfig("serve",
+		[]string{"-listen-addr", ":9000", "-dev", "-secure-cookies=false", "-tournament-max-players=64"},
+		env(map[string]string{"OPENSWISS_CONFIG": path, "LISTEN_ADDR": ":8000", "SMTP_HOST": "smtp.example.com"}), io.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []struct{ name, got, want string }{
+		{"database_url, from the file", cfg.DatabaseURL, "postgres://file"},
+		{"listen_addr, flag over env and file", cfg.ListenAddr, ":9000"},
+		{"base_url", cfg.BaseURL, "https://swiss.example.com"},
+		{"smtp host, env over file", cfg.SMTP.Host, "smtp.example.com"},
+		{"smtp from", cfg.SMTP.From, "OpenSwiss <noreply@example.com>"},
+		{"stripe currency, in lower case", cfg.Stripe.Currency, "eur"},
+		{"time zone", cfg.Defaults.TimeZone, "Europe/Berlin"},
+		{"tiebreakers", strings.Join(cfg.Defaults.Tiebreakers, ","), "gw,omw"},
+	} {
+		if c.got != c.want {
+			t.Errorf("%s = %q, want %q", c.name, c.got, c.want)
+		}
+	}
+	if cfg.RateLimit != 1000 || cfg.Defaults.PointsWin != 2 || cfg.Defaults.MaxPlayers != 64 {
+		t.Errorf("rate limit %d, points %d, max players %d", cfg.RateLimit, cfg.Defaults.PointsWin, cfg.Defaults.MaxPlayers)
+	}
+	// A true or false setting's flag can be given bare.
+	if !cfg.Dev || cfg.SecureCookies {
+		t.Errorf("dev %v, secure cookies %v; want true, false", cfg.Dev, cfg.SecureCookies)
+	}
+
+	// -config names the file in place of OPENSWISS_CONFIG.
+	other := writeConfig(t, `database_url = "postgres://other"`)
+	cfg, err = loadConfig("migrate", []string{"-config", other}, env(map[string]string{"OPENSWISS_CONFIG": path}), io.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.DatabaseURL != "postgres://other" {
+		t.Errorf("database_url = %q, want the -config file's", cfg.DatabaseURL)
+	}
+}
+
+func TestLoadConfig_Invalid(t *testing.T) {
+	_, err := loadConfig("serve", []string{"-snapshot-keep", "0"}, env(map[string]string{
+		"BASE_URL":               "swiss.example.com",
+		"SECURE_COOKIES":         "yes please",
+		"REQUEST_TIMEOUT":        "30",
+		"TLS_CERT_FILE":          "/nonexistent/cert.pem",
+		"LOCALE":                 "tlh",
+		"ADMIN_EMAIL":            "admin@example.com",
+		"SMTP_HOST":              "mail.example.com",
+		"STRIPE_SECRET_KEY":      "sk_test",
+		"STRIPE_CURRENCY":        "euro",
+		"TOURNAMENT_TIME_ZONE":   "Mars/Olympus",
+		"TOURNAMENT_POINTS_DRAW": "-1",
+		"ADMIN_ALLOWLIST":        "192.168.1.0/33",
+	}), io.Discard)
+	if err == nil {
+		t.Fatal("no error")
+	}
+	// Every problem is reported at once.
+	for _, want := range []string{
+		"DATABASE_URL", "BASE_URL", "SECURE_COOKIES", "REQUEST_TIMEOUT", "SNAPSHOT_KEEP",
+		"TLS_CERT_FILE and TLS_KEY_FILE", "LOCALE", "ADMIN_EMAIL", "SMTP_HOST and SMTP_FROM", "TOURNAMENT_POINTS_DRAW",
+		"STRIPE_SECRET_KEY and STRIPE_WEBHOOK_SECRET", "STRIPE_CURRENCY", "ADMIN_ALLOWLIST",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error doesn't mention %s:\n%v", want, err)
+		}
+	}
+
+	if _, err := loadConfig("serve", []string{"-no-such-flag"}, env(nil), io.Discard); err == nil {
+		t.Error("unknown flag accepted")
+	}
+	if _, err := loadConfig("serve", []string{"-h"}, env(nil), io.Discard); !errors.Is(err, flag.ErrHelp) {
+		t.Errorf("-h: err = %v, want flag.ErrHelp", err)
+	}
+	if _, err := loadConfig("serve", []string{"-config", "/nonexistent.toml"}, env(nil), io.Discard); err == nil {
+		t.Error("missing config file accepted")
+	}
+}
+
+func TestParseConfigFile(t *testing.T) {
+	tests := []struct {
+		name, in, wantErr string
+	}{
+		{"unknown setting", "listen_port = 80", "line 1: unknown setting listen_port"},
+		{"unknown section", "[smpt]\nhost = \"x\"", "line 2: unknown setting smpt_host"},
+		{"set twice", "[smtp]\nport = 25\n\n[smtp]\nport = 587", "line 5: smtp_port is set twice"},
+		{"unquoted string", "listen_addr = :80", "line 1: listen_addr"},
+		{"unterminated string", `base_url = "http://x`, "unterminated string"},
+		{"junk after value", `base_url = "http://x" y`, "after the value"},
+		{"no value", "read_only", "line 1: expected key = value"},
+		{"bad section", "[smtp", "line 1: bad section header"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseConfigFile(strings.NewReader(tt.in))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	got, err := parseConfigFile(strings.NewReader("read_only = true\nread_only_reason = \"Moving \\\"servers\\\"\" # for now\n[snapshot]\ninterval = '10m'"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got["READ_ONLY"] != "true" || got["READ_ONLY_REASON"] != `Moving "servers"` || got["SNAPSHOT_INTERVAL"] != "10m" {
+		t.Errorf("values = %v", got)
+	}
+}
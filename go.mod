@@ -8,6 +8,7 @@ require (
 	github.com/golang-migrate/migrate/v4 v4.19.1
 	github.com/lib/pq v1.12.3
 	golang.org/x/crypto v0.50.0
+	golang.org/x/text v0.36.0
 )
 
 require (
@@ -0,0 +1,193 @@
+// Package analytics counts views of tournaments' public pages for their
+// organizers, without identifying anyone. Handlers report each view to a
+// Tracker, which counts them in memory and adds them to the database every
+// Interval, when it also samples how many people are watching each
+// tournament and keeps the peak.
+//
+// A viewer is known only by a hash of their IP address and User-Agent under
+// a salt drawn when the process starts, kept in memory for Window and never
+// stored. Reloading the same page for the same round within Window counts
+// once, so projectors and share pages that reload themselves don't inflate
+// the views.
+package analytics
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/middleware"
+)
+
+// Window is how recently a viewer must have loaded one of a tournament's
+// pages to count as watching it.
+const Window = 5 * time.Minute
+
+type viewKey struct {
+	tournament int64
+	round      int
+	page       string
+}
+
+type viewerKey struct {
+	tournament int64
+	visitor    [sha256.Size]byte
+}
+
+// Tracker counts page views and viewers. A nil Tracker counts nothing.
+type Tracker struct {
+	DB       *sql.DB
+	Interval time.Duration
+
+	mu   sync.Mutex
+	salt []byte
+	// lastView is when each viewer's view of a page was last counted, and
+	// lastSeen when they last loaded any page of the tournament.
+	lastView map[viewerKey]map[viewKey]time.Time
+	lastSeen map[viewerKey]time.Time
+	pending  map[viewKey]int64
+}
+
+// New returns a tracker writing to database every interval.
+func New(database *sql.DB, interval time.Duration) *Tracker {
+	salt := make([]byte, 32)
+	rand.Read(salt)
+	return &Tracker{
+		DB:       database,
+		Interval: interval,
+		salt:     salt,
+		lastView: make(map[viewerKey]map[viewKey]time.Time),
+		lastSeen: make(map[viewerKey]time.Time),
+		pending:  make(map[viewKey]int64),
+	}
+}
+
+// View counts r as a view of tournament tournamentID's page (a
+// models.Page* kind) while it showed round.
+func (t *Tracker) View(r *http.Request, tournamentID int64, round int, page string) {
+	if t == nil {
+		return
+	}
+	h := sha256.New()
+	h.Write(t.salt)
+	h.Write([]byte(middleware.ClientIP(r)))
+	h.Write([]byte{0})
+	h.Write([]byte(r.UserAgent()))
+	var visitor [sha256.Size]byte
+	copy(visitor[:], h.Sum(nil))
+	t.view(viewerKey{tournamentID, visitor}, viewKey{tournamentID, round, page}, time.Now())
+}
+
+func (t *Tracker) view(who viewerKey, what viewKey, now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.lastSeen[who] = now
+	views := t.lastView[who]
+	if views == nil {
+		views = make(map[viewKey]time.Time)
+		t.lastView[who] = views
+	}
+	if last, ok := views[what]; ok && now.Sub(last) < Window {
+		return
+	}
+	views[what] = now
+	t.pending[what]++
+}
+
+// Viewers returns how many people are watching tournament tournamentID:
+// those who loaded one of its pages within Window.
+func (t *Tracker) Viewers(tournamentID int64) int {
+	if t == nil {
+		return 0
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.viewers(time.Now())[tournamentID]
+}
+
+func (t *Tracker) viewers(now time.Time) map[int64]int {
+	out := make(map[int64]int)
+	for who, seen := range t.lastSeen {
+		if now.Sub(seen) < Window {
+			out[who.tournament]++
+		}
+	}
+	return out
+}
+
+// take returns the views counted since it was last called and how many
+// are watching each tournament, and forgets viewers gone for Window.
+func (t *Tracker) take(now time.Time) (map[viewKey]int64, map[int64]int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	views := t.pending
+	t.pending = make(map[viewKey]int64)
+	watching := t.viewers(now)
+	for who, seen := range t.lastSeen {
+		if now.Sub(seen) >= Window {
+			delete(t.lastSeen, who)
+			delete(t.lastView, who)
+		}
+	}
+	return views, watching
+}
+
+// putBack returns views that couldn't be saved, to be tried again.
+func (t *Tracker) putBack(views map[viewKey]int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for k, n := range views {
+		t.pending[k] += n
+	}
+}
+
+// Flush adds the views counted since the last flush to the database and
+// records each watched tournament's viewers, in case they are a new peak.
+// Views that fail to save are kept for the next flush.
+func (t *Tracker) Flush(ctx context.Context) error {
+	now := time.Now()
+	views, watching := t.take(now)
+	var firstErr error
+	for k, n := range views {
+		if err := db.AddPageViews(ctx, t.DB, k.tournament, k.round, k.page, n); err != nil {
+			t.putBack(map[viewKey]int64{k: n})
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	for id, n := range watching {
+		if err := db.RecordViewers(ctx, t.DB, id, n, now); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// Run flushes every Interval until ctx is cancelled, then once more.
+func (t *Tracker) Run(ctx context.Context) {
+	ticker := time.NewTicker(t.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			// The request contexts are gone; give the last flush its own.
+			flushCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			if err := t.Flush(flushCtx); err != nil {
+				slog.Error("page views", "err", err)
+			}
+			cancel()
+			return
+		case <-ticker.C:
+		}
+		if err := t.Flush(ctx); err != nil && ctx.Err() == nil {
+			slog.ErrorContext(ctx, "page views", "err", err)
+		}
+	}
+}
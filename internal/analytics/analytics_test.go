@@ -0,0 +1,68 @@
+package analytics
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+func TestTracker_Views(t *testing.T) {
+	tr := New(nil, time.Minute)
+	now := time.Now()
+	ann := viewerKey{tournament: 1, visitor: [32]byte{1}}
+	bob := viewerKey{tournament: 1, visitor: [32]byte{2}}
+	pairings := viewKey{1, 3, models.PagePairings}
+	standings := viewKey{1, 3, models.PageStandings}
+
+	tr.view(ann, pairings, now)
+	tr.view(ann, pairings, now.Add(time.Minute)) // a reload: not counted again
+	tr.view(ann, standings, now.Add(time.Minute))
+	tr.view(bob, pairings, now.Add(2*time.Minute))
+	tr.view(ann, pairings, now.Add(Window+time.Second)) // back after a while
+
+	views, watching := tr.take(now.Add(Window + time.Second))
+	if views[pairings] != 3 || views[standings] != 1 {
+		t.Errorf("views = %v, want 3 pairings and 1 standings", views)
+	}
+	if watching[1] != 2 {
+		t.Errorf("watching = %v, want 2", watching)
+	}
+	if views, _ := tr.take(now); len(views) != 0 {
+		t.Errorf("views taken twice: %v", views)
+	}
+
+	// Bob leaves; Ann keeps watching.
+	_, watching = tr.take(now.Add(2*time.Minute + Window))
+	if watching[1] != 1 || len(tr.lastSeen) != 1 {
+		t.Errorf("watching = %v with %d viewers remembered, want only Ann", watching, len(tr.lastSeen))
+	}
+
+	tr.putBack(map[viewKey]int64{pairings: 2})
+	if views, _ := tr.take(now); views[pairings] != 2 {
+		t.Errorf("views put back = %v, want 2 pairings", views)
+	}
+}
+
+func TestTracker_View(t *testing.T) {
+	tr := New(nil, time.Minute)
+	r := httptest.NewRequest("GET", "/tournaments/1", nil)
+	r.Header.Set("User-Agent", "browser")
+	tr.View(r, 1, 0, models.PageTournament)
+	tr.View(r, 1, 0, models.PageTournament)
+	r.Header.Set("User-Agent", "phone")
+	tr.View(r, 1, 0, models.PageTournament)
+	if n := tr.Viewers(1); n != 2 {
+		t.Errorf("Viewers = %d, want 2: one per browser", n)
+	}
+	if views, _ := tr.take(time.Now()); views[viewKey{1, 0, models.PageTournament}] != 2 {
+		t.Errorf("views = %v, want 2", views)
+	}
+
+	var none *Tracker
+	none.View(r, 1, 0, models.PageTournament)
+	if none.Viewers(1) != 0 {
+		t.Error("nil tracker counted a viewer")
+	}
+}
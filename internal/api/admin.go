@@ -2,16 +2,23 @@ package api
 
 import (
 	"database/sql"
+	"log/slog"
 	"net/http"
 	"strconv"
 
+	"github.com/dstathis/openswiss/internal/auth"
 	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/engine"
+	"github.com/dstathis/openswiss/internal/middleware"
 	"github.com/dstathis/openswiss/internal/models"
+	"github.com/dstathis/openswiss/internal/readonly"
 	"github.com/go-chi/chi/v5"
 )
 
 type AdminAPI struct {
-	DB *sql.DB
+	DB       *sql.DB
+	ReadOnly *readonly.Mode
+	BaseURL  string
 }
 
 func (a *AdminAPI) ListUsers(w http.ResponseWriter, r *http.Request) {
@@ -46,3 +53,132 @@ func (a *AdminAPI) UpdateUser(w http.ResponseWriter, r *http.Request) {
 	user, _ := db.GetUserByID(r.Context(), a.DB, userID)
 	jsonResponse(w, http.StatusOK, user)
 }
+
+// AnonymizeUser replaces the user's name with "Player #N" in every
+// tournament that has started and unlinks those registrations from the
+// account (see engine.AnonymizeUser). Returns {"anonymized": n}, the
+// registrations changed.
+func (a *AdminAPI) AnonymizeUser(w http.ResponseWriter, r *http.Request) {
+	userID, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if _, err := db.GetUserByID(r.Context(), a.DB, userID); err != nil {
+		jsonError(w, http.StatusNotFound, "not found")
+		return
+	}
+	n, err := engine.AnonymizeUser(r.Context(), a.DB, userID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "anonymize user", "err", err, "user_id", userID)
+		jsonError(w, http.StatusInternalServerError, "failed to anonymize user")
+		return
+	}
+	slog.InfoContext(r.Context(), "user anonymized by admin",
+		"user_id", userID, "admin_id", middleware.GetUser(r.Context()).ID, "registrations", n)
+	jsonResponse(w, http.StatusOK, map[string]int{"anonymized": n})
+}
+
+// DeleteUser anonymizes the user like AnonymizeUser and deletes their
+// account (see engine.DeleteUser). Tournaments they created pass to the
+// caller. Returns {"anonymized": n}; 400 for the caller's own account.
+func (a *AdminAPI) DeleteUser(w http.ResponseWriter, r *http.Request) {
+	userID, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	admin := middleware.GetUser(r.Context())
+	if userID == admin.ID {
+		jsonError(w, http.StatusBadRequest, "you can't delete your own account")
+		return
+	}
+	if _, err := db.GetUserByID(r.Context(), a.DB, userID); err != nil {
+		jsonError(w, http.StatusNotFound, "not found")
+		return
+	}
+	n, err := engine.DeleteUser(r.Context(), a.DB, userID, admin.ID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "delete user", "err", err, "user_id", userID)
+		jsonError(w, http.StatusInternalServerError, "failed to delete user")
+		return
+	}
+	slog.InfoContext(r.Context(), "user deleted by admin",
+		"user_id", userID, "admin_id", admin.ID, "registrations", n)
+	jsonResponse(w, http.StatusOK, map[string]int{"anonymized": n})
+}
+
+// ListAPIKeys returns every user's API keys with their owners, newest
+// first.
+func (a *AdminAPI) ListAPIKeys(w http.ResponseWriter, r *http.Request) {
+	keys, err := db.ListAllAPIKeys(r.Context(), a.DB)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, "failed to list keys")
+		return
+	}
+	if keys == nil {
+		keys = []db.OwnedAPIKey{}
+	}
+	jsonResponse(w, http.StatusOK, keys)
+}
+
+// CreateAPIKey creates an API key for the user, e.g. a service account for
+// a results-entry script. Body and response as for /users/me/api-keys.
+func (a *AdminAPI) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	userID, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if _, err := db.GetUserByID(r.Context(), a.DB, userID); err != nil {
+		jsonError(w, http.StatusNotFound, "not found")
+		return
+	}
+	createAPIKey(w, r, a.DB, userID)
+}
+
+// RevokeAPIKey deletes any user's API key. 204.
+func (a *AdminAPI) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	keyID, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err := db.RevokeAPIKey(r.Context(), a.DB, keyID); err != nil {
+		jsonError(w, http.StatusNotFound, "key not found")
+		return
+	}
+	slog.Info("API key revoked by admin", "key_id", keyID, "admin_id", middleware.GetUser(r.Context()).ID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// IssueResetLink creates a one-time password reset link for a user and
+// returns {"url", "expires_at"}, 201. The link replaces any earlier one and
+// is not shown again.
+func (a *AdminAPI) IssueResetLink(w http.ResponseWriter, r *http.Request) {
+	userID, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if _, err := db.GetUserByID(r.Context(), a.DB, userID); err != nil {
+		jsonError(w, http.StatusNotFound, "not found")
+		return
+	}
+	rawToken, tokenHash, expiresAt, err := auth.NewPasswordReset(auth.IssuedResetLinkTTL)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, "failed to create reset link")
+		return
+	}
+	if err := db.CreatePasswordReset(r.Context(), a.DB, userID, tokenHash, expiresAt); err != nil {
+		jsonError(w, http.StatusInternalServerError, "failed to create reset link")
+		return
+	}
+	slog.Info("password reset link issued by admin", "user_id", userID, "admin_id", middleware.GetUser(r.Context()).ID)
+	jsonResponse(w, http.StatusCreated, map[string]interface{}{
+		"url":        a.BaseURL + auth.ResetPath(rawToken),
+		"expires_at": expiresAt,
+	})
+}
+
+// GetReadOnly returns the read-only mode status.
+func (a *AdminAPI) GetReadOnly(w http.ResponseWriter, r *http.Request) {
+	jsonResponse(w, http.StatusOK, a.ReadOnly.Status())
+}
+
+// SetReadOnly switches manual read-only mode. Body: {"enabled": bool,
+// "reason": string}. Returns the resulting status, which stays enabled while
+// automatic read-only mode is on.
+func (a *AdminAPI) SetReadOnly(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Enabled *bool  `json:"enabled"`
+		Reason  string `json:"reason"`
+	}
+	if err := decodeJSON(r, &body); err != nil || body.Enabled == nil {
+		jsonError(w, http.StatusBadRequest, "enabled is required")
+		return
+	}
+	a.ReadOnly.Set(*body.Enabled, body.Reason)
+	slog.Info("read-only mode set by admin", "enabled", *body.Enabled, "user_id", middleware.GetUser(r.Context()).ID)
+	jsonResponse(w, http.StatusOK, a.ReadOnly.Status())
+}
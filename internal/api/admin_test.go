@@ -8,10 +8,14 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"strconv"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/dstathis/openswiss/internal/auth"
 	"github.com/dstathis/openswiss/internal/db"
 	"github.com/dstathis/openswiss/internal/models"
+	"github.com/dstathis/openswiss/internal/readonly"
 )
 
 func TestAdminAPI_ListUsers(t *testing.T) {
@@ -127,3 +131,171 @@ func TestAdminAPI_UpdateUser_InvalidJSON(t *testing.T) {
 		t.Errorf("expected 400 for bad JSON, got %d", rec.Code)
 	}
 }
+
+func TestAdminAPI_ReadOnly(t *testing.T) {
+	database := testDB(t)
+	admin := mustCreateUser(t, database, "admin@example.com", "Admin", models.RoleAdmin)
+	api := &AdminAPI{DB: database, ReadOnly: readonly.New(readonly.Config{})}
+
+	rec := httptest.NewRecorder()
+	api.SetReadOnly(rec, requestWithUser("POST", "/api/v1/admin/read-only", `{"reason":"x"}`, admin, nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("missing enabled: status %d, want 400", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	api.SetReadOnly(rec, requestWithUser("POST", "/api/v1/admin/read-only", `{"enabled":true,"reason":"db upgrade"}`, admin, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("enable: status %d body %s", rec.Code, rec.Body.String())
+	}
+	var got readonly.Status
+	json.NewDecoder(rec.Body).Decode(&got)
+	if !got.Enabled || !got.Manual || got.Reason != "db upgrade" {
+		t.Errorf("status = %+v", got)
+	}
+
+	rec = httptest.NewRecorder()
+	api.SetReadOnly(rec, requestWithUser("POST", "/api/v1/admin/read-only", `{"enabled":false}`, admin, nil))
+	rec = httptest.NewRecorder()
+	api.GetReadOnly(rec, requestWithUser("GET", "/api/v1/admin/read-only", "", admin, nil))
+	got = readonly.Status{}
+	json.NewDecoder(rec.Body).Decode(&got)
+	if got.Enabled {
+		t.Errorf("still read-only after disable: %+v", got)
+	}
+}
+
+func TestAdminAPI_IssueResetLink(t *testing.T) {
+	database := testDB(t)
+	api := &AdminAPI{DB: database, BaseURL: "https://swiss.example"}
+	admin := mustCreateUser(t, database, "admin-reset@example.com", "AdminReset", models.RoleAdmin)
+	target := mustCreateUser(t, database, "target-reset@example.com", "TargetReset")
+
+	rec := httptest.NewRecorder()
+	api.IssueResetLink(rec, requestWithUser("POST", "/", "", admin, map[string]string{"id": strconv.FormatInt(target.ID, 10)}))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want 201", rec.Code)
+	}
+	var got struct {
+		URL       string    `json:"url"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+	json.NewDecoder(rec.Body).Decode(&got)
+	token := strings.TrimPrefix(got.URL, "https://swiss.example/reset-password?token=")
+	if token == got.URL || got.ExpiresAt.IsZero() {
+		t.Fatalf("response = %+v", got)
+	}
+	reset, err := db.GetPasswordResetByTokenHash(context.Background(), database, auth.HashResetToken(token))
+	if err != nil || reset.UserID != target.ID {
+		t.Errorf("stored reset = %+v, %v", reset, err)
+	}
+
+	rec = httptest.NewRecorder()
+	api.IssueResetLink(rec, requestWithUser("POST", "/", "", admin, map[string]string{"id": "999999"}))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("unknown user: status = %d, want 404", rec.Code)
+	}
+}
+
+func TestAdminAPI_APIKeys(t *testing.T) {
+	database := testDB(t)
+	api := &AdminAPI{DB: database}
+	admin := mustCreateUser(t, database, "admin-keys@example.com", "AdminKeys", models.RoleAdmin)
+	bot := mustCreateUser(t, database, "scanner@example.com", "Scanner")
+	params := map[string]string{"id": strconv.FormatInt(bot.ID, 10)}
+
+	rec := httptest.NewRecorder()
+	api.CreateAPIKey(rec, requestWithUser("POST", "/", `{"name":"scanner","scope":"read"}`, admin, params))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create: status %d body %s", rec.Code, rec.Body.String())
+	}
+	var created struct {
+		ID     int64  `json:"id"`
+		UserID int64  `json:"user_id"`
+		Key    string `json:"key"`
+		Scope  string `json:"scope"`
+	}
+	json.NewDecoder(rec.Body).Decode(&created)
+	if created.UserID != bot.ID || created.Scope != models.APIKeyScopeRead || created.Key == "" {
+		t.Errorf("created = %+v", created)
+	}
+
+	for body, want := range map[string]int{
+		`{"name":"x","scope":"admin"}`: http.StatusBadRequest,
+		`{"scope":"read"}`:             http.StatusBadRequest,
+	} {
+		rec = httptest.NewRecorder()
+		api.CreateAPIKey(rec, requestWithUser("POST", "/", body, admin, params))
+		if rec.Code != want {
+			t.Errorf("%s: status %d, want %d", body, rec.Code, want)
+		}
+	}
+	rec = httptest.NewRecorder()
+	api.CreateAPIKey(rec, requestWithUser("POST", "/", `{"name":"x"}`, admin, map[string]string{"id": "999999"}))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("unknown user: status %d, want 404", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	api.ListAPIKeys(rec, requestWithUser("GET", "/", "", admin, nil))
+	var keys []db.OwnedAPIKey
+	json.NewDecoder(rec.Body).Decode(&keys)
+	if len(keys) != 1 || keys[0].UserEmail != bot.Email || keys[0].Scope != models.APIKeyScopeRead {
+		t.Errorf("keys = %+v", keys)
+	}
+
+	keyParams := map[string]string{"id": strconv.FormatInt(created.ID, 10)}
+	rec = httptest.NewRecorder()
+	api.RevokeAPIKey(rec, requestWithUser("DELETE", "/", "", admin, keyParams))
+	if rec.Code != http.StatusNoContent {
+		t.Errorf("revoke: status %d, want 204", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	api.RevokeAPIKey(rec, requestWithUser("DELETE", "/", "", admin, keyParams))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("revoke again: status %d, want 404", rec.Code)
+	}
+}
+
+func TestAdminAPI_AnonymizeAndDeleteUser(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	api := &AdminAPI{DB: database}
+	admin := mustCreateUser(t, database, "admin-gdpr@example.com", "AdminGDPR", models.RoleAdmin)
+	_, tourn := startedTournament(t, database)
+	regs, _ := db.ListRegistrations(ctx, database, tourn.ID)
+	userID := *regs[0].UserID
+	params := map[string]string{"id": strconv.FormatInt(userID, 10)}
+
+	rec := httptest.NewRecorder()
+	api.AnonymizeUser(rec, requestWithUser("POST", "/", "", admin, params))
+	var got map[string]int
+	json.NewDecoder(rec.Body).Decode(&got)
+	if rec.Code != http.StatusOK || got["anonymized"] != 1 {
+		t.Fatalf("anonymize: status %d body %v", rec.Code, got)
+	}
+	if reg, _ := db.GetRegistrationByID(ctx, database, regs[0].ID); reg.DisplayName != "Player #"+strconv.FormatInt(regs[0].ID, 10) {
+		t.Errorf("registration name = %q", reg.DisplayName)
+	}
+
+	rec = httptest.NewRecorder()
+	api.DeleteUser(rec, requestWithUser("DELETE", "/", "", admin, map[string]string{"id": strconv.FormatInt(admin.ID, 10)}))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("deleting yourself: status %d, want 400", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	api.DeleteUser(rec, requestWithUser("DELETE", "/", "", admin, params))
+	got = nil
+	json.NewDecoder(rec.Body).Decode(&got)
+	if rec.Code != http.StatusOK || got["anonymized"] != 0 {
+		t.Fatalf("delete: status %d body %v", rec.Code, got)
+	}
+	if _, err := db.GetUserByID(ctx, database, userID); err == nil {
+		t.Error("account still there after deleting")
+	}
+	rec = httptest.NewRecorder()
+	api.AnonymizeUser(rec, requestWithUser("POST", "/", "", admin, params))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("anonymize deleted user: status %d, want 404", rec.Code)
+	}
+}
@@ -0,0 +1,37 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+
+	"github.com/dstathis/openswiss/internal/analytics"
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/middleware"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/go-chi/chi/v5"
+)
+
+// AnalyticsAPI reports how many spectators follow a tournament.
+type AnalyticsAPI struct {
+	DB *sql.DB
+	// Views knows who is watching now; nil reports nobody.
+	Views *analytics.Tracker
+}
+
+// Get returns the tournament's peak and current viewers and its page views
+// per round. Views reach the database once a minute, so the latest ones may
+// be missing. Min tier: Co-organizer.
+func (a *AnalyticsAPI) Get(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if !middleware.AuthorizeTournament(w, r, a.DB, id, models.TierCoOrganizer) {
+		return
+	}
+	stats, err := db.GetAnalytics(r.Context(), a.DB, id)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, "failed to load analytics")
+		return
+	}
+	stats.Viewers = a.Views.Viewers(id)
+	jsonResponse(w, http.StatusOK, stats)
+}
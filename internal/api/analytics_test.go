@@ -0,0 +1,42 @@
+//go:build integration
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+func TestAnalyticsAPI_Get(t *testing.T) {
+	database := testDB(t)
+	api := &AnalyticsAPI{DB: database}
+	owner := mustCreateUser(t, database, "owner-analytics@example.com", "OwnerAnalytics")
+	tourn := mustCreateTournament(t, database, owner.ID, models.TournamentStatusInProgress)
+	db.AddPageViews(context.Background(), database, tourn.ID, 2, models.PageStandings, 9)
+	params := map[string]string{"id": strconv.FormatInt(tourn.ID, 10)}
+
+	rec := httptest.NewRecorder()
+	api.Get(rec, requestWithUser("GET", "/", "", owner, params))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d body %s", rec.Code, rec.Body.String())
+	}
+	var got models.Analytics
+	json.NewDecoder(rec.Body).Decode(&got)
+	if got.Views != 9 || len(got.Rounds) != 1 || got.Rounds[0].Round != 2 || got.Rounds[0].Standings != 9 {
+		t.Errorf("analytics = %+v, want 9 standings views in round 2", got)
+	}
+
+	player := mustCreateUser(t, database, "player-analytics@example.com", "PlayerAnalytics")
+	rec = httptest.NewRecorder()
+	api.Get(rec, requestWithUser("GET", "/", "", player, params))
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("player: status %d, want 403", rec.Code)
+	}
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/engine"
+	"github.com/dstathis/openswiss/internal/middleware"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/go-chi/chi/v5"
+)
+
+// ListAnnouncements returns the tournament's announcements being shown,
+// newest first, or with ?all=true every one ever posted. Public.
+func (a *TournamentAPI) ListAnnouncements(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if _, err := db.GetTournament(r.Context(), a.DB, id); err != nil {
+		jsonError(w, http.StatusNotFound, "not found")
+		return
+	}
+	var as []models.Announcement
+	var err error
+	if r.URL.Query().Get("all") == "true" {
+		as, err = db.ListAnnouncements(r.Context(), a.DB, id)
+	} else {
+		as, err = db.ActiveAnnouncements(r.Context(), a.DB, id, time.Now())
+	}
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, "failed to load announcements")
+		return
+	}
+	if as == nil {
+		as = []models.Announcement{}
+	}
+	jsonResponse(w, http.StatusOK, as)
+}
+
+// Announce posts {"message", "minutes"} as an announcement, shown for
+// minutes from now (0 or left out: until ended), and returns it. Min tier:
+// Judge.
+func (a *TournamentAPI) Announce(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if !middleware.AuthorizeTournament(w, r, a.DB, id, models.TierJudge) {
+		return
+	}
+	var req struct {
+		Message string `json:"message"`
+		Minutes int    `json:"minutes"`
+	}
+	if err := decodeJSON(r, &req); err != nil {
+		jsonError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	user := middleware.GetUser(r.Context())
+	ann, err := engine.Announce(r.Context(), a.DB, id, user.ID, req.Message, req.Minutes)
+	if err != nil {
+		if errors.Is(err, engine.ErrAnnouncementsClosed) {
+			jsonError(w, http.StatusConflict, err.Error())
+			return
+		}
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	jsonResponse(w, http.StatusCreated, ann)
+}
+
+// EndAnnouncement stops showing an announcement; it stays in the history.
+// Min tier: Judge.
+func (a *TournamentAPI) EndAnnouncement(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if !middleware.AuthorizeTournament(w, r, a.DB, id, models.TierJudge) {
+		return
+	}
+	aid, _ := strconv.ParseInt(chi.URLParam(r, "announcementID"), 10, 64)
+	if err := db.EndAnnouncement(r.Context(), a.DB, id, aid, time.Now()); err != nil {
+		if errors.Is(err, db.ErrAnnouncementNotFound) {
+			jsonError(w, http.StatusNotFound, "no such active announcement")
+			return
+		}
+		jsonError(w, http.StatusInternalServerError, "failed to end the announcement")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,68 @@
+//go:build integration
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+func TestTournamentAPI_Announcements(t *testing.T) {
+	database := testDB(t)
+	owner, tourn := freshStarted(t, database)
+	a := &TournamentAPI{DB: database}
+	params := map[string]string{"id": strconv.FormatInt(tourn.ID, 10)}
+
+	stranger := mustCreateUser(t, database, "stranger-announce@example.com", "StrangerAnnounce")
+	rec := httptest.NewRecorder()
+	a.Announce(rec, requestWithUser("POST", "/", `{"message":"Hi"}`, stranger, params))
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("stranger: status %d, want 403", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	a.Announce(rec, requestWithUser("POST", "/", `{"message":""}`, owner, params))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("empty message: status %d, want 400", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	a.Announce(rec, requestWithUser("POST", "/", `{"message":"Round 2 starts at 14:00","minutes":30}`, owner, params))
+	var posted models.Announcement
+	json.Unmarshal(rec.Body.Bytes(), &posted)
+	if rec.Code != http.StatusCreated || posted.ID == 0 || posted.ExpiresAt == nil {
+		t.Fatalf("announce: status %d, body %s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	a.ListAnnouncements(rec, requestWithUser("GET", "/", "", nil, params))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "Round 2 starts at 14:00") {
+		t.Errorf("list: status %d, body %s", rec.Code, rec.Body.String())
+	}
+
+	endParams := map[string]string{"id": params["id"], "announcementID": strconv.FormatInt(posted.ID, 10)}
+	rec = httptest.NewRecorder()
+	a.EndAnnouncement(rec, requestWithUser("POST", "/", "", owner, endParams))
+	if rec.Code != http.StatusNoContent {
+		t.Errorf("end: status %d", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	a.EndAnnouncement(rec, requestWithUser("POST", "/", "", owner, endParams))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("end twice: status %d, want 404", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	a.ListAnnouncements(rec, requestWithUser("GET", "/", "", nil, params))
+	if strings.TrimSpace(rec.Body.String()) != "[]" {
+		t.Errorf("list after ending: %s", rec.Body.String())
+	}
+	rec = httptest.NewRecorder()
+	a.ListAnnouncements(rec, requestWithUser("GET", "/?all=true", "", nil, params))
+	if !strings.Contains(rec.Body.String(), "Round 2 starts at 14:00") {
+		t.Errorf("history: %s", rec.Body.String())
+	}
+}
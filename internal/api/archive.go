@@ -0,0 +1,72 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/go-chi/chi/v5"
+)
+
+// ArchiveAPI serves the public archive of complete tournaments.
+type ArchiveAPI struct {
+	DB *sql.DB
+}
+
+// List returns every archived tournament, most recent first.
+func (a *ArchiveAPI) List(w http.ResponseWriter, r *http.Request) {
+	events, err := db.ListArchivedEvents(r.Context(), a.DB)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, "failed to list the archive")
+		return
+	}
+	if events == nil {
+		events = []models.ArchivedEvent{}
+	}
+	jsonResponse(w, http.StatusOK, events)
+}
+
+// Get returns an archived tournament with its final standings.
+func (a *ArchiveAPI) Get(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	e, err := db.GetArchivedEvent(r.Context(), a.DB, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		jsonError(w, http.StatusNotFound, "not found")
+		return
+	} else if err != nil {
+		jsonError(w, http.StatusInternalServerError, "failed to load the archive")
+		return
+	}
+	results, err := db.ListArchivedResults(r.Context(), a.DB, id)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, "failed to load the archive")
+		return
+	}
+	if results == nil {
+		results = []models.ArchivedResult{}
+	}
+	jsonResponse(w, http.StatusOK, map[string]interface{}{"event": e, "results": results})
+}
+
+// Search returns the archived finishes of players whose name contains ?q=,
+// most recent event first. q is required.
+func (a *ArchiveAPI) Search(w http.ResponseWriter, r *http.Request) {
+	q := strings.TrimSpace(r.URL.Query().Get("q"))
+	if q == "" {
+		jsonError(w, http.StatusBadRequest, "q is required")
+		return
+	}
+	found, err := db.SearchArchive(r.Context(), a.DB, q)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, "failed to search the archive")
+		return
+	}
+	if found == nil {
+		found = []models.ArchivedResult{}
+	}
+	jsonResponse(w, http.StatusOK, found)
+}
@@ -0,0 +1,67 @@
+//go:build integration
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+func TestArchiveAPI(t *testing.T) {
+	database := testDB(t)
+	api := &ArchiveAPI{DB: database}
+	owner := mustCreateUser(t, database, "owner-archive@example.com", "OwnerArchive")
+	tourn := mustCreateTournament(t, database, owner.ID, models.TournamentStatusFinished)
+	if err := db.ArchiveTournament(context.Background(), database, tourn.ID, []models.ArchivedResult{
+		{Place: 1, Name: "Alice", Points: 6, Wins: 2},
+		{Place: 2, Name: "Bob", Points: 3, Wins: 1, Losses: 1},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	rec := httptest.NewRecorder()
+	api.List(rec, requestWithUser("GET", "/", "", nil, nil))
+	var events []models.ArchivedEvent
+	json.NewDecoder(rec.Body).Decode(&events)
+	if len(events) != 1 || events[0].Winner != "Alice" || events[0].Players != 2 {
+		t.Errorf("events = %+v, want one won by Alice", events)
+	}
+
+	rec = httptest.NewRecorder()
+	api.Get(rec, requestWithUser("GET", "/", "", nil, map[string]string{"id": strconv.FormatInt(tourn.ID, 10)}))
+	var got struct {
+		Event   models.ArchivedEvent    `json:"event"`
+		Results []models.ArchivedResult `json:"results"`
+	}
+	json.NewDecoder(rec.Body).Decode(&got)
+	if rec.Code != http.StatusOK || got.Event.TournamentID != tourn.ID || len(got.Results) != 2 {
+		t.Errorf("status %d, got %+v", rec.Code, got)
+	}
+
+	rec = httptest.NewRecorder()
+	api.Get(rec, requestWithUser("GET", "/", "", nil, map[string]string{"id": "0"}))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("unarchived: status %d, want 404", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	api.Search(rec, requestWithUser("GET", "/?q=bo", "", nil, nil))
+	var found []models.ArchivedResult
+	json.NewDecoder(rec.Body).Decode(&found)
+	if len(found) != 1 || found[0].Name != "Bob" || found[0].Tournament != tourn.Name {
+		t.Errorf("search = %+v, want Bob", found)
+	}
+
+	rec = httptest.NewRecorder()
+	api.Search(rec, requestWithUser("GET", "/", "", nil, nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("no q: status %d, want 400", rec.Code)
+	}
+}
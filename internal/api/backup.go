@@ -0,0 +1,68 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/engine"
+	"github.com/dstathis/openswiss/internal/middleware"
+	"github.com/go-chi/chi/v5"
+)
+
+// Backup returns one tournament's backup, as a download.
+func (a *AdminAPI) Backup(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	b, err := db.BackupTournament(r.Context(), a.DB, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		jsonError(w, http.StatusNotFound, "not found")
+		return
+	}
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, "failed to back up tournament")
+		return
+	}
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, b.Filename()))
+	jsonResponse(w, http.StatusOK, b)
+}
+
+// Restore loads the backup in the request body into a new tournament, or
+// over tournament ?replace= when given. Returns {"tournament_id": id}.
+func (a *AdminAPI) Restore(w http.ResponseWriter, r *http.Request) {
+	var replaceID int64
+	if v := r.URL.Query().Get("replace"); v != "" {
+		var err error
+		if replaceID, err = strconv.ParseInt(v, 10, 64); err != nil {
+			jsonError(w, http.StatusBadRequest, "invalid replace")
+			return
+		}
+	}
+	b, err := engine.ReadBackup(r.Body)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	user := middleware.GetUser(r.Context())
+	id, err := engine.RestoreBackup(r.Context(), a.DB, b, user.ID, replaceID)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		jsonError(w, http.StatusNotFound, "tournament to replace not found")
+		return
+	case errors.Is(err, db.ErrBackupUserConflict):
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	case err != nil:
+		jsonError(w, http.StatusInternalServerError, "failed to restore backup")
+		return
+	}
+	slog.Info("tournament restored from backup", "tournament_id", id, "replaced", replaceID != 0, "user_id", user.ID)
+	status := http.StatusCreated
+	if replaceID != 0 {
+		status = http.StatusOK
+	}
+	jsonResponse(w, status, map[string]int64{"tournament_id": id})
+}
@@ -0,0 +1,66 @@
+//go:build integration
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+func TestAdminAPI_BackupAndRestore(t *testing.T) {
+	database := testDB(t)
+	api := &AdminAPI{DB: database}
+	admin := mustCreateUser(t, database, "admin@example.com", "Admin", models.RoleAdmin, models.RolePlayer)
+	_, tourn := startedTournament(t, database)
+
+	rec := httptest.NewRecorder()
+	api.Backup(rec, requestWithUser("GET", "/", "", admin, map[string]string{"id": strconv.FormatInt(tourn.ID, 10)}))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("backup: status %d body %s", rec.Code, rec.Body.String())
+	}
+	if cd := rec.Header().Get("Content-Disposition"); !strings.HasPrefix(cd, "attachment;") {
+		t.Errorf("Content-Disposition = %q", cd)
+	}
+	backup := rec.Body.String()
+
+	rec = httptest.NewRecorder()
+	api.Restore(rec, requestWithUser("POST", "/admin/restore", backup, admin, nil))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("restore: status %d body %s", rec.Code, rec.Body.String())
+	}
+	var got struct {
+		TournamentID int64 `json:"tournament_id"`
+	}
+	json.NewDecoder(rec.Body).Decode(&got)
+	if got.TournamentID == 0 || got.TournamentID == tourn.ID {
+		t.Errorf("tournament_id = %d", got.TournamentID)
+	}
+
+	rec = httptest.NewRecorder()
+	api.Restore(rec, requestWithUser("POST", "/admin/restore?replace="+strconv.FormatInt(tourn.ID, 10), backup, admin, nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("replace: status %d body %s", rec.Code, rec.Body.String())
+	}
+
+	for target, want := range map[string]int{
+		"/admin/restore?replace=999999": http.StatusNotFound,
+		"/admin/restore?replace=x":      http.StatusBadRequest,
+	} {
+		rec = httptest.NewRecorder()
+		api.Restore(rec, requestWithUser("POST", target, backup, admin, nil))
+		if rec.Code != want {
+			t.Errorf("%s: status %d, want %d", target, rec.Code, want)
+		}
+	}
+	rec = httptest.NewRecorder()
+	api.Restore(rec, requestWithUser("POST", "/admin/restore", `{"openswiss_backup":1}`, admin, nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("invalid backup: status %d, want 400", rec.Code)
+	}
+}
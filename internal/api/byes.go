@@ -0,0 +1,103 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/engine"
+	"github.com/dstathis/openswiss/internal/middleware"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/go-chi/chi/v5"
+)
+
+type ByesAPI struct {
+	DB *sql.DB
+}
+
+// List returns the byes staff assigned in the tournament, by round. Min
+// tier: Judge.
+func (a *ByesAPI) List(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if !middleware.AuthorizeTournament(w, r, a.DB, id, models.TierJudge) {
+		return
+	}
+	byes, err := db.ListAssignedByes(r.Context(), a.DB, id)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, "failed to list byes")
+		return
+	}
+	if byes == nil {
+		byes = []models.AssignedBye{}
+	}
+	jsonResponse(w, http.StatusOK, byes)
+}
+
+type assignByeRequest struct {
+	RegistrationID int64 `json:"registration_id"`
+	Round          int   `json:"round"`
+}
+
+// Assign gives a registered player a bye in a round, on top of any bye the
+// pairing hands out. Min tier: Co-organizer.
+func (a *ByesAPI) Assign(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	t, err := db.GetTournament(r.Context(), a.DB, id)
+	if err != nil {
+		jsonError(w, http.StatusNotFound, "not found")
+		return
+	}
+	if !middleware.AuthorizeTournament(w, r, a.DB, t.ID, models.TierCoOrganizer) {
+		return
+	}
+	var req assignByeRequest
+	if err := decodeJSON(r, &req); err != nil {
+		jsonError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	reg, _ := db.GetRegistrationByID(r.Context(), a.DB, req.RegistrationID)
+	if err := engine.CheckAssignedBye(t, reg, req.Round); err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	user := middleware.GetUser(r.Context())
+	if err := db.AddAssignedBye(r.Context(), a.DB, &models.AssignedBye{
+		TournamentID:   t.ID,
+		Round:          req.Round,
+		RegistrationID: reg.ID,
+		AssignedBy:     &user.ID,
+	}); err != nil {
+		jsonError(w, http.StatusInternalServerError, "failed to assign bye")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Remove takes an assigned bye back. Min tier: Co-organizer.
+func (a *ByesAPI) Remove(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	round, err := strconv.Atoi(chi.URLParam(r, "round"))
+	if err != nil {
+		jsonError(w, http.StatusNotFound, "not found")
+		return
+	}
+	regID, err := strconv.ParseInt(chi.URLParam(r, "regID"), 10, 64)
+	if err != nil {
+		jsonError(w, http.StatusNotFound, "not found")
+		return
+	}
+	if !middleware.AuthorizeTournament(w, r, a.DB, id, models.TierCoOrganizer) {
+		return
+	}
+	if err := db.DeleteAssignedBye(r.Context(), a.DB, id, round, regID); err != nil {
+		if errors.Is(err, db.ErrAssignedByeNotFound) {
+			jsonError(w, http.StatusNotFound, "not found")
+			return
+		}
+		jsonError(w, http.StatusInternalServerError, "failed to remove bye")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
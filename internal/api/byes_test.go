@@ -0,0 +1,83 @@
+//go:build integration
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/dstathis/swisstools"
+)
+
+func TestByesAPI_Lifecycle(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	owner, tourn := startedTournament(t, database)
+	api := &ByesAPI{DB: database}
+	params := map[string]string{"id": strconv.FormatInt(tourn.ID, 10)}
+	regs, _ := db.ListRegistrations(ctx, database, tourn.ID)
+	judge := regs[0]
+
+	for name, body := range map[string]string{
+		"played round": fmt.Sprintf(`{"registration_id": %d, "round": 0}`, judge.ID),
+		"past the end": fmt.Sprintf(`{"registration_id": %d, "round": 3}`, judge.ID),
+		"unknown":      `{"registration_id": 999999, "round": 2}`,
+	} {
+		rec := httptest.NewRecorder()
+		api.Assign(rec, requestWithUser("POST", "/", body, owner, params))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status %d, want 400", name, rec.Code)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	api.Assign(rec, requestWithUser("POST", "/", fmt.Sprintf(`{"registration_id": %d, "round": 2}`, judge.ID), owner, params))
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("assign: status %d body %s", rec.Code, rec.Body.String())
+	}
+	rec = httptest.NewRecorder()
+	api.List(rec, requestWithUser("GET", "/", "", owner, params))
+	var byes []models.AssignedBye
+	json.Unmarshal(rec.Body.Bytes(), &byes)
+	if rec.Code != http.StatusOK || len(byes) != 1 || byes[0].Round != 2 || byes[0].RegistrationID != judge.ID {
+		t.Fatalf("list: status %d body %s", rec.Code, rec.Body.String())
+	}
+
+	// Round 2 pairs the three others around the judge, so two byes.
+	rec = httptest.NewRecorder()
+	(&RoundsAPI{DB: database}).NextRound(rec, requestWithUser("POST", "/", "", owner, params))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("next round: status %d body %s", rec.Code, rec.Body.String())
+	}
+	got, _ := db.GetTournament(ctx, database, tourn.ID)
+	eng, _ := swisstools.LoadTournament(got.EngineState)
+	n, judgeBye := 0, false
+	for _, p := range eng.GetRound() {
+		if p.PlayerB() == swisstools.BYE_OPPONENT_ID {
+			n++
+			judgeBye = judgeBye || p.PlayerA() == *judge.EnginePlayerID
+		}
+	}
+	if n != 2 || !judgeBye {
+		t.Errorf("round 2 has %d byes, judge's among them: %v", n, judgeBye)
+	}
+
+	remove := map[string]string{"id": params["id"], "round": "2", "regID": strconv.FormatInt(judge.ID, 10)}
+	rec = httptest.NewRecorder()
+	api.Remove(rec, requestWithUser("DELETE", "/", "", owner, remove))
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("remove: status %d", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	api.Remove(rec, requestWithUser("DELETE", "/", "", owner, remove))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("second remove: status %d, want 404", rec.Code)
+	}
+}
@@ -0,0 +1,44 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/engine"
+	"github.com/dstathis/openswiss/internal/middleware"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/go-chi/chi/v5"
+)
+
+// SetClubs sets players' clubs from a list of {"registration_id", "club"};
+// a null or empty club clears one and players left out keep theirs.
+// Returns the registrations. Until the tournament finishes.
+func (a *PlayersAPI) SetClubs(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if !middleware.AuthorizeTournament(w, r, a.DB, id, models.TierCoOrganizer) {
+		return
+	}
+	var body []struct {
+		RegistrationID int64   `json:"registration_id"`
+		Club           *string `json:"club"`
+	}
+	if err := decodeJSON(r, &body); err != nil {
+		jsonError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	clubs := make(map[int64]*string, len(body))
+	for _, b := range body {
+		clubs[b.RegistrationID] = b.Club
+	}
+	if err := engine.SetClubs(r.Context(), a.DB, id, clubs); err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	regs, err := db.ListRegistrations(r.Context(), a.DB, id)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, "failed to list registrations")
+		return
+	}
+	jsonResponse(w, http.StatusOK, regs)
+}
@@ -0,0 +1,55 @@
+//go:build integration
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+func TestPlayersAPI_SetClubs(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	api := &PlayersAPI{DB: database}
+	owner := mustCreateUser(t, database, "owner-clubs@example.com", "OwnerClubs")
+	tourn := mustCreateTournament(t, database, owner.ID, models.TournamentStatusRegistrationOpen)
+	alice, _ := db.CreateGuestRegistration(ctx, database, tourn.ID, "Alice")
+	params := map[string]string{"id": strconv.FormatInt(tourn.ID, 10)}
+
+	rec := httptest.NewRecorder()
+	api.SetClubs(rec, requestWithUser("PUT", "/", fmt.Sprintf(`[{"registration_id":%d,"club":" North CC "}]`, alice.ID), owner, params))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d body %s", rec.Code, rec.Body.String())
+	}
+	var regs []models.Registration
+	json.NewDecoder(rec.Body).Decode(&regs)
+	if len(regs) != 1 || regs[0].Club == nil || *regs[0].Club != "North CC" {
+		t.Errorf("registrations = %+v", regs)
+	}
+
+	long := strings.Repeat("x", models.MaxClubName+1)
+	for body, want := range map[string]int{
+		fmt.Sprintf(`[{"registration_id":%d,"club":"%s"}]`, alice.ID, long): http.StatusBadRequest,
+		`[{"registration_id":999999,"club":"South"}]`:                       http.StatusBadRequest,
+		`{"club":"South"}`: http.StatusBadRequest,
+		fmt.Sprintf(`[{"registration_id":%d,"club":null}]`, alice.ID): http.StatusOK,
+	} {
+		rec = httptest.NewRecorder()
+		api.SetClubs(rec, requestWithUser("PUT", "/", body, owner, params))
+		if rec.Code != want {
+			t.Errorf("%s: status %d, want %d", body, rec.Code, want)
+		}
+	}
+	if got, _ := db.GetRegistrationByID(ctx, database, alice.ID); got.Club != nil {
+		t.Errorf("club = %q, want cleared", *got.Club)
+	}
+}
@@ -0,0 +1,268 @@
+package api
+
+import (
+	"context"
+	"crypto/ed25519"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/discord"
+	"github.com/dstathis/openswiss/internal/engine"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/dstathis/swisstools"
+	"github.com/go-chi/chi/v5"
+)
+
+// DiscordAPI serves pairings and standings as ready-to-post Discord
+// messages, and answers Discord slash commands when PublicKey is set.
+type DiscordAPI struct {
+	DB        *sql.DB
+	PublicKey ed25519.PublicKey
+	BaseURL   string
+}
+
+type discordMessages struct {
+	Messages []string `json:"messages"`
+}
+
+// loadStarted loads a tournament and its engine, or reports why it can't.
+func (a *DiscordAPI) loadStarted(ctx context.Context, id int64) (*models.Tournament, *swisstools.Tournament, int, string) {
+	t, err := db.GetTournament(ctx, a.DB, id)
+	if err != nil {
+		return nil, nil, http.StatusNotFound, "not found"
+	}
+	if t.EngineState == nil {
+		return nil, nil, http.StatusBadRequest, "tournament not started"
+	}
+	e, err := swisstools.LoadTournament(t.EngineState)
+	if err != nil {
+		return nil, nil, http.StatusInternalServerError, "failed to load engine state"
+	}
+	return t, &e, 0, ""
+}
+
+func (a *DiscordAPI) pairingsMessages(ctx context.Context, id int64, round, limit int) ([]string, int, string) {
+	t, eng, status, msg := a.loadStarted(ctx, id)
+	if eng == nil {
+		return nil, status, msg
+	}
+	if round == 0 {
+		round = eng.GetCurrentRound()
+	}
+	pairings, err := eng.GetRoundByNumber(round)
+	if err != nil {
+		return nil, http.StatusNotFound, "round not found"
+	}
+	// Discord channels are public; a draft waits for publishing.
+	if t.PairingsDraft(round) {
+		return nil, http.StatusNotFound, "pairings not published yet"
+	}
+	ps := make([]discord.Pairing, 0, len(pairings))
+	for _, pr := range formatPairings(eng, pairings) {
+		p := discord.Pairing{Table: pr.Table, PlayerA: pr.PlayerAName, PlayerB: pr.PlayerBName, Bye: pr.IsBye}
+		if !pr.IsBye && pr.PlayerAWins != swisstools.UNINITIALIZED_RESULT {
+			p.Result = fmt.Sprintf("%d-%d", pr.PlayerAWins, pr.PlayerBWins)
+			if pr.Draws > 0 {
+				p.Result += fmt.Sprintf("-%d", pr.Draws)
+			}
+		}
+		ps = append(ps, p)
+	}
+	return discord.PairingsMessages(t.Name, round, ps, limit), 0, ""
+}
+
+func (a *DiscordAPI) standingsMessages(ctx context.Context, id int64, top, limit int) ([]string, int, string) {
+	t, eng, status, msg := a.loadStarted(ctx, id)
+	if eng == nil {
+		return nil, status, msg
+	}
+	// Discord channels are public, so a blind final round hides them.
+	if engine.StandingsHidden(t, eng) {
+		return nil, http.StatusForbidden, "standings are hidden until the final round ends"
+	}
+	regs, err := db.ListRegistrations(ctx, a.DB, id)
+	if err != nil {
+		return nil, http.StatusInternalServerError, "failed to list registrations"
+	}
+	standings := engine.CachedStandings(t, eng, engine.TiebreakSeeds(regs))
+	return discord.StandingsMessages(t.Name, eng.GetCurrentRound(), standings, top, limit), 0, ""
+}
+
+// queryInt reads a non-negative integer query parameter, 0 when absent.
+func queryInt(r *http.Request, key string) (int, bool) {
+	v := r.URL.Query().Get(key)
+	if v == "" {
+		return 0, true
+	}
+	n, err := strconv.Atoi(v)
+	return n, err == nil && n >= 0
+}
+
+// Pairings returns a round's pairings as Discord messages. Query: round
+// (default current), limit (characters per message, default and max 2000).
+func (a *DiscordAPI) Pairings(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	round, ok1 := queryInt(r, "round")
+	limit, ok2 := queryInt(r, "limit")
+	if !ok1 || !ok2 {
+		jsonError(w, http.StatusBadRequest, "round and limit must be non-negative integers")
+		return
+	}
+	msgs, status, msg := a.pairingsMessages(r.Context(), id, round, limit)
+	if msgs == nil {
+		jsonError(w, status, msg)
+		return
+	}
+	jsonResponse(w, http.StatusOK, discordMessages{Messages: msgs})
+}
+
+// Outstanding returns the current round's tables still without a result
+// as Discord messages, to remind them to report. Query: limit (characters
+// per message, default and max 2000).
+func (a *DiscordAPI) Outstanding(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	limit, ok := queryInt(r, "limit")
+	if !ok {
+		jsonError(w, http.StatusBadRequest, "limit must be a non-negative integer")
+		return
+	}
+	t, eng, status, msg := a.loadStarted(r.Context(), id)
+	if eng == nil {
+		jsonError(w, status, msg)
+		return
+	}
+	round := eng.GetCurrentRound()
+	if t.PairingsDraft(round) {
+		jsonError(w, http.StatusNotFound, "pairings not published yet")
+		return
+	}
+	var ps []discord.Pairing
+	if eng.GetStatus() != "finished" {
+		for _, p := range engine.Outstanding(eng) {
+			ps = append(ps, discord.Pairing{Table: p.Table, PlayerA: p.PlayerAName, PlayerB: p.PlayerBName})
+		}
+	}
+	jsonResponse(w, http.StatusOK, discordMessages{Messages: discord.OutstandingMessages(t.Name, round, ps, limit)})
+}
+
+// Standings returns the standings as Discord messages, or 403 in a blind
+// final round. Query: top (only the first N players), limit (characters
+// per message, default and max 2000).
+func (a *DiscordAPI) Standings(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	top, ok1 := queryInt(r, "top")
+	limit, ok2 := queryInt(r, "limit")
+	if !ok1 || !ok2 {
+		jsonError(w, http.StatusBadRequest, "top and limit must be non-negative integers")
+		return
+	}
+	msgs, status, msg := a.standingsMessages(r.Context(), id, top, limit)
+	if msgs == nil {
+		jsonError(w, status, msg)
+		return
+	}
+	jsonResponse(w, http.StatusOK, discordMessages{Messages: msgs})
+}
+
+// Announcements returns the tournament's announcements being shown, newest
+// first, as Discord messages. Query: limit (characters per message, default
+// and max 2000).
+func (a *DiscordAPI) Announcements(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	limit, ok := queryInt(r, "limit")
+	if !ok {
+		jsonError(w, http.StatusBadRequest, "limit must be a non-negative integer")
+		return
+	}
+	msgs, status, msg := a.announcementMessages(r.Context(), id, limit)
+	if msgs == nil {
+		jsonError(w, status, msg)
+		return
+	}
+	jsonResponse(w, http.StatusOK, discordMessages{Messages: msgs})
+}
+
+func (a *DiscordAPI) announcementMessages(ctx context.Context, id int64, limit int) ([]string, int, string) {
+	t, err := db.GetTournament(ctx, a.DB, id)
+	if err != nil {
+		return nil, http.StatusNotFound, "not found"
+	}
+	as, err := db.ActiveAnnouncements(ctx, a.DB, id, time.Now())
+	if err != nil {
+		return nil, http.StatusInternalServerError, "failed to load announcements"
+	}
+	texts := make([]string, 0, len(as))
+	for _, an := range as {
+		texts = append(texts, an.Message)
+	}
+	return discord.AnnouncementMessages(t.Name, texts, limit), 0, ""
+}
+
+// Interactions is the endpoint Discord calls for the /pairings, /standings
+// and /announcements slash commands, each taking a "tournament" integer
+// option.
+// Requests must carry a valid signature for PublicKey. A slash command can
+// only answer with one message, so longer output ends with a link to the
+// tournament page.
+func (a *DiscordAPI) Interactions(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, "bad request")
+		return
+	}
+	if !discord.Verify(a.PublicKey, r.Header.Get("X-Signature-Ed25519"), r.Header.Get("X-Signature-Timestamp"), body) {
+		jsonError(w, http.StatusUnauthorized, "invalid request signature")
+		return
+	}
+	var in discord.Interaction
+	if err := json.Unmarshal(body, &in); err != nil {
+		jsonError(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	if in.Type == discord.InteractionPing {
+		jsonResponse(w, http.StatusOK, discord.Response{Type: discord.ResponsePong})
+		return
+	}
+	if in.Type != discord.InteractionApplicationCommand {
+		jsonError(w, http.StatusBadRequest, "unsupported interaction type")
+		return
+	}
+
+	id, ok := in.IntOption("tournament")
+	if !ok {
+		jsonResponse(w, http.StatusOK, discord.Message("Please give a tournament ID."))
+		return
+	}
+	// Leave room in the message for the link to the full list.
+	link := fmt.Sprintf("Full list: %s/tournaments/%d", a.BaseURL, id)
+	limit := discord.MessageLimit - utf8.RuneCountInString(link) - 1
+	var msgs []string
+	var msg string
+	switch in.Data.Name {
+	case "pairings":
+		msgs, _, msg = a.pairingsMessages(r.Context(), id, 0, limit)
+	case "standings":
+		msgs, _, msg = a.standingsMessages(r.Context(), id, 0, limit)
+	case "announcements":
+		msgs, _, msg = a.announcementMessages(r.Context(), id, limit)
+	default:
+		jsonResponse(w, http.StatusOK, discord.Message("Unknown command."))
+		return
+	}
+	if msgs == nil {
+		jsonResponse(w, http.StatusOK, discord.Message("Tournament "+strconv.FormatInt(id, 10)+": "+msg+"."))
+		return
+	}
+	content := msgs[0]
+	if len(msgs) > 1 {
+		content += "\n" + link
+	}
+	jsonResponse(w, http.StatusOK, discord.Message(content))
+}
@@ -0,0 +1,122 @@
+//go:build integration
+
+package api
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/discord"
+)
+
+func TestDiscordAPI_Pairings(t *testing.T) {
+	database := testDB(t)
+	_, tourn := freshStarted(t, database)
+	a := &DiscordAPI{DB: database}
+	params := map[string]string{"id": strconv.FormatInt(tourn.ID, 10)}
+
+	rec := httptest.NewRecorder()
+	a.Pairings(rec, requestWithUser("GET", "/?limit=60", "", nil, params))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body=%s", rec.Code, rec.Body.String())
+	}
+	var got discordMessages
+	json.Unmarshal(rec.Body.Bytes(), &got)
+	if len(got.Messages) < 2 || !strings.Contains(got.Messages[0], "Round 1 pairings") {
+		t.Errorf("messages = %q", got.Messages)
+	}
+	for _, m := range got.Messages {
+		if len([]rune(m)) > 60 {
+			t.Errorf("message over limit: %q", m)
+		}
+	}
+
+	rec = httptest.NewRecorder()
+	a.Pairings(rec, requestWithUser("GET", "/?round=7", "", nil, params))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("unknown round: status %d", rec.Code)
+	}
+}
+
+func TestDiscordAPI_Outstanding(t *testing.T) {
+	database := testDB(t)
+	_, tourn := freshStarted(t, database)
+	a := &DiscordAPI{DB: database}
+
+	rec := httptest.NewRecorder()
+	a.Outstanding(rec, requestWithUser("GET", "/", "", nil, map[string]string{"id": strconv.FormatInt(tourn.ID, 10)}))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body=%s", rec.Code, rec.Body.String())
+	}
+	var got discordMessages
+	json.Unmarshal(rec.Body.Bytes(), &got)
+	if len(got.Messages) != 1 || !strings.Contains(got.Messages[0], "Round 1: results outstanding") || strings.Count(got.Messages[0], "`T") != 2 {
+		t.Errorf("messages = %q", got.Messages)
+	}
+}
+
+func TestDiscordAPI_Standings(t *testing.T) {
+	database := testDB(t)
+	_, tourn := startedTournament(t, database)
+	a := &DiscordAPI{DB: database}
+
+	rec := httptest.NewRecorder()
+	a.Standings(rec, requestWithUser("GET", "/?top=1", "", nil, map[string]string{"id": strconv.FormatInt(tourn.ID, 10)}))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body=%s", rec.Code, rec.Body.String())
+	}
+	var got discordMessages
+	json.Unmarshal(rec.Body.Bytes(), &got)
+	if len(got.Messages) != 1 || strings.Count(got.Messages[0], "\n") != 1 || !strings.Contains(got.Messages[0], "`1.`") {
+		t.Errorf("messages = %q", got.Messages)
+	}
+}
+
+func TestDiscordAPI_Interactions(t *testing.T) {
+	database := testDB(t)
+	_, tourn := freshStarted(t, database)
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	a := &DiscordAPI{DB: database, PublicKey: pub, BaseURL: "https://example.com"}
+
+	send := func(body string, sign bool) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/api/v1/discord/interactions", bytes.NewBufferString(body))
+		ts := "1700000000"
+		req.Header.Set("X-Signature-Timestamp", ts)
+		sig := ed25519.Sign(priv, []byte(ts+body))
+		if !sign {
+			sig[0] ^= 0xff
+		}
+		req.Header.Set("X-Signature-Ed25519", hex.EncodeToString(sig))
+		rec := httptest.NewRecorder()
+		a.Interactions(rec, req)
+		return rec
+	}
+
+	if rec := send(`{"type":1}`, false); rec.Code != http.StatusUnauthorized {
+		t.Errorf("bad signature: status %d", rec.Code)
+	}
+	rec := send(`{"type":1}`, true)
+	var resp discord.Response
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	if rec.Code != http.StatusOK || resp.Type != discord.ResponsePong {
+		t.Errorf("ping: %d %s", rec.Code, rec.Body.String())
+	}
+
+	cmd := `{"type":2,"data":{"name":"pairings","options":[{"name":"tournament","value":` + strconv.FormatInt(tourn.ID, 10) + `}]}}`
+	rec = send(cmd, true)
+	resp = discord.Response{}
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	if resp.Type != discord.ResponseChannelMessageWithSource || resp.Data == nil || !strings.Contains(resp.Data.Content, "Round 1 pairings") {
+		t.Errorf("pairings command: %s", rec.Body.String())
+	}
+	if resp.Data != nil && resp.Data.AllowedMentions.Parse == nil {
+		t.Error("mentions not disabled")
+	}
+}
@@ -0,0 +1,63 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/engine"
+	"github.com/dstathis/openswiss/internal/export"
+	"github.com/dstathis/swisstools"
+	"github.com/go-chi/chi/v5"
+)
+
+// Export downloads a complete tournament's results: the OTR JSON document
+// by default, or with ?format=wer the WER-style XML event file that
+// tournament-reporting software imports. 409 until the tournament is
+// complete (see engine.Complete).
+func (a *RoundsAPI) Export(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "otr" && format != "wer" {
+		jsonError(w, http.StatusBadRequest, "format must be otr or wer")
+		return
+	}
+	t, err := db.GetTournament(r.Context(), a.DB, id)
+	if err != nil {
+		jsonError(w, http.StatusNotFound, "not found")
+		return
+	}
+	if t.EngineState == nil {
+		jsonError(w, http.StatusConflict, "tournament is not finished")
+		return
+	}
+	eng, err := swisstools.LoadTournament(t.EngineState)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, "failed to load engine state")
+		return
+	}
+	if !engine.Complete(t, &eng) {
+		jsonError(w, http.StatusConflict, "tournament is not finished")
+		return
+	}
+	regs, err := db.ListRegistrations(r.Context(), a.DB, id)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, "failed to list registrations")
+		return
+	}
+	seeds := engine.TiebreakSeeds(regs)
+
+	generate, contentType, filename := export.GenerateOTR, "application/json", "otr.json"
+	if format == "wer" {
+		generate, contentType, filename = export.GenerateWER, "application/xml; charset=utf-8", "wer.xml"
+	}
+	body, err := generate(t, &eng, seeds)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, "failed to export")
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="tournament-%d-%s"`, id, filename))
+	w.Write(body)
+}
@@ -0,0 +1,60 @@
+//go:build integration
+
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/export"
+)
+
+func TestRoundsAPI_Export(t *testing.T) {
+	database := testDB(t)
+	owner, tourn := startedTournament(t, database)
+	api := &RoundsAPI{DB: database}
+	params := map[string]string{"id": strconv.FormatInt(tourn.ID, 10)}
+
+	rec := httptest.NewRecorder()
+	api.Export(rec, requestWithUser("GET", "/", "", nil, params))
+	if rec.Code != http.StatusConflict {
+		t.Errorf("running tournament: status %d, want 409", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	(&TournamentAPI{DB: database}).Finish(rec, requestWithUser("POST", "/", "", owner, params))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Finish status = %d, body=%s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	api.Export(rec, requestWithUser("GET", "/", "", nil, params))
+	var otr export.OTR
+	if rec.Code != http.StatusOK || json.Unmarshal(rec.Body.Bytes(), &otr) != nil || len(otr.Players) != 4 {
+		t.Fatalf("OTR: status %d, body %s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	api.Export(rec, requestWithUser("GET", "/?format=wer", "", nil, params))
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "application/xml; charset=utf-8" {
+		t.Fatalf("WER: status %d, content type %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	var ev export.WEREvent
+	if err := xml.Unmarshal(rec.Body.Bytes(), &ev); err != nil || len(ev.Persons) != 4 || len(ev.Rounds) == 0 {
+		t.Errorf("WER event = %+v, err %v", ev, err)
+	}
+	if !bytes.Contains([]byte(rec.Header().Get("Content-Disposition")), []byte("-wer.xml")) {
+		t.Errorf("Content-Disposition = %q", rec.Header().Get("Content-Disposition"))
+	}
+
+	rec = httptest.NewRecorder()
+	api.Export(rec, requestWithUser("GET", "/?format=csv", "", nil, params))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("unknown format: status %d, want 400", rec.Code)
+	}
+}
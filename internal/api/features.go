@@ -0,0 +1,102 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/engine"
+	"github.com/dstathis/openswiss/internal/middleware"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/dstathis/swisstools"
+	"github.com/go-chi/chi/v5"
+)
+
+// featureMatchesResponse is the feature matches feed: small and flat, so a
+// stream overlay can poll it and lay it out without further requests.
+type featureMatchesResponse struct {
+	Tournament struct {
+		ID   int64  `json:"id"`
+		Name string `json:"name"`
+	} `json:"tournament"`
+	Round int `json:"round"`
+	// Hidden is set when the players' records and ranks are left out, in a
+	// blind final round.
+	Hidden  bool                  `json:"hidden"`
+	Matches []engine.FeatureMatch `json:"matches"`
+}
+
+// GetFeatureMatches returns the current Swiss round's feature matches, with
+// the players' standings going into the round and the results so far. It
+// has no matches before the start or while the round is a draft, and no
+// standings in a blind final round unless the caller is staff. Public.
+func (a *RoundsAPI) GetFeatureMatches(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	t, err := db.GetTournament(r.Context(), a.DB, id)
+	if err != nil {
+		jsonError(w, http.StatusNotFound, "not found")
+		return
+	}
+	var resp featureMatchesResponse
+	resp.Tournament.ID, resp.Tournament.Name = t.ID, t.Name
+	resp.Matches = []engine.FeatureMatch{}
+	if len(t.EngineState) == 0 {
+		jsonResponse(w, http.StatusOK, resp)
+		return
+	}
+	eng, err := swisstools.LoadTournament(t.EngineState)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, "failed to load engine state")
+		return
+	}
+	resp.Round = eng.GetCurrentRound()
+	if hidesDraft(r, a.DB, t, resp.Round) {
+		jsonResponse(w, http.StatusOK, resp)
+		return
+	}
+	tables, err := db.ListFeatureMatches(r.Context(), a.DB, id, resp.Round)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, "failed to load feature matches")
+		return
+	}
+	if len(tables) > 0 {
+		var standings []swisstools.PlayerStanding
+		if resp.Hidden = hidesStandings(r, a.DB, t, &eng); !resp.Hidden {
+			regs, _ := db.ListRegistrations(r.Context(), a.DB, id)
+			standings = engine.CachedStandings(t, &eng, engine.TiebreakSeeds(regs))
+		}
+		resp.Matches = engine.FeatureMatches(&eng, standings, tables)
+	}
+	jsonResponse(w, http.StatusOK, resp)
+}
+
+// SetFeatureMatches makes {"tables": [...]} the current Swiss round's
+// feature matches, replacing the ones set before; an empty list features
+// none. It answers 409 when no Swiss round is under way. Min tier: Judge.
+func (a *RoundsAPI) SetFeatureMatches(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if !middleware.AuthorizeTournament(w, r, a.DB, id, models.TierJudge) {
+		return
+	}
+	var req struct {
+		Tables []int `json:"tables"`
+	}
+	if err := decodeJSON(r, &req); err != nil {
+		jsonError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	round, tables, err := engine.SetFeatureMatches(r.Context(), a.DB, id, req.Tables)
+	switch {
+	case errors.Is(err, engine.ErrNoRoundUnderway):
+		jsonError(w, http.StatusConflict, err.Error())
+		return
+	case err != nil:
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	jsonResponse(w, http.StatusOK, map[string]interface{}{
+		"round":  round,
+		"tables": tables,
+	})
+}
@@ -0,0 +1,53 @@
+//go:build integration
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestRoundsAPI_FeatureMatches(t *testing.T) {
+	database := testDB(t)
+	owner, tourn := freshStarted(t, database)
+	a := &RoundsAPI{DB: database}
+	params := map[string]string{"id": strconv.FormatInt(tourn.ID, 10)}
+
+	put := func(body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		a.SetFeatureMatches(rec, requestWithUser("PUT", "/", body, owner, params))
+		return rec
+	}
+	stranger := mustCreateUser(t, database, "stranger-feature@example.com", "StrangerFeature")
+	rec := httptest.NewRecorder()
+	a.SetFeatureMatches(rec, requestWithUser("PUT", "/", `{"tables":[1]}`, stranger, params))
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("stranger: status %d, want 403", rec.Code)
+	}
+	if rec := put(`{"tables":[5]}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("table 5: status %d, want 400", rec.Code)
+	}
+	if rec := put(`{"tables":[2,2]}`); rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"tables":[2]`) {
+		t.Fatalf("set: status %d, body %s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	a.GetFeatureMatches(rec, requestWithUser("GET", "/", "", nil, params))
+	var feed featureMatchesResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &feed); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("feed: status %d, body %s", rec.Code, rec.Body.String())
+	}
+	if feed.Tournament.ID != tourn.ID || feed.Round != 1 || len(feed.Matches) != 1 || feed.Matches[0].Table != 2 || feed.Matches[0].PlayerA.Name == "" {
+		t.Errorf("feed = %+v", feed)
+	}
+
+	rec = httptest.NewRecorder()
+	(&RoundsAPI{DB: database}).GetCurrentRound(rec, requestWithUser("GET", "/", "", nil, params))
+	if strings.Count(rec.Body.String(), `"feature":true`) != 1 {
+		t.Errorf("current round: %s", rec.Body.String())
+	}
+}
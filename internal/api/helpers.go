@@ -2,8 +2,12 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
+
+	"github.com/dstathis/openswiss/internal/db"
 )
 
 func jsonResponse(w http.ResponseWriter, status int, data interface{}) {
@@ -18,6 +22,17 @@ func jsonError(w http.ResponseWriter, status int, msg string) {
 	json.NewEncoder(w).Encode(map[string]string{"error": msg})
 }
 
+// engineError answers a tournament change that failed: 503 with Retry-After
+// if the tournament was busy (see db.ErrBusy), else 400 with the reason.
+func engineError(w http.ResponseWriter, err error) {
+	if errors.Is(err, db.ErrBusy) {
+		w.Header().Set("Retry-After", "5")
+		jsonError(w, http.StatusServiceUnavailable, db.ErrBusy.Error())
+		return
+	}
+	jsonError(w, http.StatusBadRequest, err.Error())
+}
+
 func paginationParams(r *http.Request) (page, perPage int) {
 	page = 1
 	perPage = 50
@@ -34,3 +49,42 @@ func paginationParams(r *http.Request) (page, perPage int) {
 	}
 	return
 }
+
+// nameFilter returns the ?q= player search of a list endpoint, lowercased.
+func nameFilter(r *http.Request) string {
+	return strings.ToLower(strings.TrimSpace(r.URL.Query().Get("q")))
+}
+
+// filterRows keeps the rows for which any of the names returned by names
+// contains q (from nameFilter). An empty q keeps every row. The result is
+// never nil, so it encodes as [].
+func filterRows[T any](rows []T, q string, names func(T) []string) []T {
+	out := make([]T, 0, len(rows))
+	for _, row := range rows {
+		if q == "" {
+			out = append(out, row)
+			continue
+		}
+		for _, n := range names(row) {
+			if strings.Contains(strings.ToLower(n), q) {
+				out = append(out, row)
+				break
+			}
+		}
+	}
+	return out
+}
+
+// pageRows sets X-Total-Count to the number of rows and, when the request
+// has ?page= or ?per_page=, returns just that page (see paginationParams).
+// Without either the whole list is returned.
+func pageRows[T any](w http.ResponseWriter, r *http.Request, rows []T) []T {
+	w.Header().Set("X-Total-Count", strconv.Itoa(len(rows)))
+	q := r.URL.Query()
+	if !q.Has("page") && !q.Has("per_page") {
+		return rows
+	}
+	page, perPage := paginationParams(r)
+	start := min((page-1)*perPage, len(rows))
+	return rows[start:min(start+perPage, len(rows))]
+}
@@ -2,9 +2,13 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+
+	"github.com/dstathis/openswiss/internal/db"
 )
 
 func TestJsonResponse(t *testing.T) {
@@ -52,6 +56,25 @@ func TestJsonError(t *testing.T) {
 	}
 }
 
+func TestEngineError(t *testing.T) {
+	rec := httptest.NewRecorder()
+	engineError(rec, fmt.Errorf("begin tx: %w", db.ErrBusy))
+	if rec.Code != http.StatusServiceUnavailable || rec.Header().Get("Retry-After") == "" {
+		t.Errorf("busy: got %d, Retry-After %q", rec.Code, rec.Header().Get("Retry-After"))
+	}
+	var body map[string]string
+	json.NewDecoder(rec.Body).Decode(&body)
+	if body["error"] != db.ErrBusy.Error() {
+		t.Errorf("busy: error %q", body["error"])
+	}
+
+	rec = httptest.NewRecorder()
+	engineError(rec, errors.New("tournament is not running"))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("refused: got %d", rec.Code)
+	}
+}
+
 func TestPaginationParams_Defaults(t *testing.T) {
 	req := httptest.NewRequest("GET", "/api/v1/tournaments", nil)
 	page, perPage := paginationParams(req)
@@ -129,3 +152,36 @@ func TestDecodeJSON_NilBody(t *testing.T) {
 		t.Error("expected error for nil body")
 	}
 }
+
+func TestFilterRows(t *testing.T) {
+	rows := []string{"Alice", "bob", "Carol"}
+	self := func(s string) []string { return []string{s} }
+	if got := filterRows(rows, "", self); len(got) != 3 {
+		t.Errorf("empty search kept %v", got)
+	}
+	if got := filterRows(rows, "o", self); len(got) != 2 || got[0] != "bob" || got[1] != "Carol" {
+		t.Errorf("search o kept %v", got)
+	}
+	if got := filterRows(rows, "zed", self); got == nil || len(got) != 0 {
+		t.Errorf("no match = %#v, want empty non-nil", got)
+	}
+}
+
+func TestPageRows(t *testing.T) {
+	rows := []int{1, 2, 3, 4, 5}
+	for query, want := range map[string][]int{
+		"":                   {1, 2, 3, 4, 5},
+		"?per_page=2":        {1, 2},
+		"?page=3&per_page=2": {5},
+		"?page=9&per_page=2": {},
+	} {
+		rec := httptest.NewRecorder()
+		got := pageRows(rec, httptest.NewRequest("GET", "/"+query, nil), rows)
+		if len(got) != len(want) || (len(got) > 0 && got[0] != want[0]) {
+			t.Errorf("%q: rows = %v, want %v", query, got, want)
+		}
+		if n := rec.Header().Get("X-Total-Count"); n != "5" {
+			t.Errorf("%q: X-Total-Count = %q, want 5", query, n)
+		}
+	}
+}
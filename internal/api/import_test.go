@@ -0,0 +1,41 @@
+//go:build integration
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+func TestTournamentAPI_Import(t *testing.T) {
+	database := testDB(t)
+	a := &TournamentAPI{DB: database}
+	owner := mustCreateUser(t, database, "import-api@example.com", "ImportAPI")
+	tourn := mustCreateTournament(t, database, owner.ID, models.TournamentStatusRegistrationOpen)
+	params := map[string]string{"id": strconv.FormatInt(tourn.ID, 10)}
+
+	rec := httptest.NewRecorder()
+	a.Import(rec, requestWithUser("POST", "/", "round,player\n1,Alice\n", owner, params))
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "invalid import") {
+		t.Errorf("bad file: status %d body %s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	a.Import(rec, requestWithUser("POST", "/", "round,player,opponent,wins,losses\n1,Alice,Bob,2,1\n1,Carol,,,\n", owner, params))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("import: status %d body %s", rec.Code, rec.Body.String())
+	}
+	var got models.Tournament
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != models.TournamentStatusInProgress {
+		t.Errorf("status = %s", got.Status)
+	}
+}
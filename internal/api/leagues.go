@@ -0,0 +1,166 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/engine"
+	"github.com/dstathis/openswiss/internal/middleware"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/go-chi/chi/v5"
+)
+
+// LeaguesAPI serves leagues. Listing them and their leaderboards is public;
+// changing them needs the organizer role.
+type LeaguesAPI struct {
+	DB *sql.DB
+}
+
+type leagueRequest struct {
+	Name                string `json:"name"`
+	PlacementPoints     []int  `json:"placement_points"`
+	ParticipationPoints int    `json:"participation_points"`
+}
+
+// List returns every league, newest first.
+func (a *LeaguesAPI) List(w http.ResponseWriter, r *http.Request) {
+	leagues, err := db.ListLeagues(r.Context(), a.DB)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, "failed to list leagues")
+		return
+	}
+	if leagues == nil {
+		leagues = []models.League{}
+	}
+	jsonResponse(w, http.StatusOK, leagues)
+}
+
+// Get returns a league's leaderboard with the tournaments counted towards
+// it and those still pending (see engine.LeagueTable).
+func (a *LeaguesAPI) Get(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "lid"), 10, 64)
+	l, err := db.GetLeague(r.Context(), a.DB, id)
+	if err != nil {
+		jsonError(w, http.StatusNotFound, "not found")
+		return
+	}
+	table, err := engine.LoadLeagueTable(r.Context(), a.DB, l)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, "failed to load the league's tournaments")
+		return
+	}
+	jsonResponse(w, http.StatusOK, table)
+}
+
+// Create adds a league. Without placement_points it gets
+// models.DefaultPlacementPoints.
+func (a *LeaguesAPI) Create(w http.ResponseWriter, r *http.Request) {
+	var req leagueRequest
+	if err := decodeJSON(r, &req); err != nil {
+		jsonError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.PlacementPoints == nil {
+		req.PlacementPoints = models.DefaultPlacementPoints
+	}
+	l := &models.League{
+		Name:                req.Name,
+		PlacementPoints:     req.PlacementPoints,
+		ParticipationPoints: req.ParticipationPoints,
+		CreatedBy:           &middleware.GetUser(r.Context()).ID,
+	}
+	if err := l.Validate(); err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := db.CreateLeague(r.Context(), a.DB, l); err != nil {
+		jsonError(w, http.StatusInternalServerError, "failed to create league")
+		return
+	}
+	jsonResponse(w, http.StatusCreated, l)
+}
+
+// Update replaces a league's name and points table; a missing
+// placement_points leaves no places scoring more than participation.
+func (a *LeaguesAPI) Update(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "lid"), 10, 64)
+	var req leagueRequest
+	if err := decodeJSON(r, &req); err != nil {
+		jsonError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	l, err := db.GetLeague(r.Context(), a.DB, id)
+	if err != nil {
+		jsonError(w, http.StatusNotFound, "not found")
+		return
+	}
+	l.Name, l.PlacementPoints, l.ParticipationPoints = req.Name, req.PlacementPoints, req.ParticipationPoints
+	if l.PlacementPoints == nil {
+		l.PlacementPoints = []int{}
+	}
+	if err := l.Validate(); err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := db.UpdateLeague(r.Context(), a.DB, l); err != nil {
+		if errors.Is(err, db.ErrLeagueNotFound) {
+			jsonError(w, http.StatusNotFound, "not found")
+			return
+		}
+		jsonError(w, http.StatusInternalServerError, "failed to save league")
+		return
+	}
+	jsonResponse(w, http.StatusOK, l)
+}
+
+// Delete removes a league. Its tournaments are untouched.
+func (a *LeaguesAPI) Delete(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "lid"), 10, 64)
+	if err := db.DeleteLeague(r.Context(), a.DB, id); err != nil {
+		if errors.Is(err, db.ErrLeagueNotFound) {
+			jsonError(w, http.StatusNotFound, "not found")
+			return
+		}
+		jsonError(w, http.StatusInternalServerError, "failed to delete league")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// AddTournament counts a tournament towards a league; adding it again is a
+// no-op.
+func (a *LeaguesAPI) AddTournament(w http.ResponseWriter, r *http.Request) {
+	lid, _ := strconv.ParseInt(chi.URLParam(r, "lid"), 10, 64)
+	tid, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if _, err := db.GetLeague(r.Context(), a.DB, lid); err != nil {
+		jsonError(w, http.StatusNotFound, "league not found")
+		return
+	}
+	if _, err := db.GetTournament(r.Context(), a.DB, tid); err != nil {
+		jsonError(w, http.StatusNotFound, "tournament not found")
+		return
+	}
+	if err := db.AddLeagueTournament(r.Context(), a.DB, lid, tid); err != nil {
+		jsonError(w, http.StatusInternalServerError, "failed to add tournament")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RemoveTournament stops a tournament counting towards a league.
+func (a *LeaguesAPI) RemoveTournament(w http.ResponseWriter, r *http.Request) {
+	lid, _ := strconv.ParseInt(chi.URLParam(r, "lid"), 10, 64)
+	tid, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err := db.RemoveLeagueTournament(r.Context(), a.DB, lid, tid); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			jsonError(w, http.StatusNotFound, "not found")
+			return
+		}
+		jsonError(w, http.StatusInternalServerError, "failed to remove tournament")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
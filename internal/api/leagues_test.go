@@ -0,0 +1,85 @@
+//go:build integration
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/engine"
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+func TestLeaguesAPI(t *testing.T) {
+	database := testDB(t)
+	api := &LeaguesAPI{DB: database}
+	org := mustCreateUser(t, database, "org@example.com", "Org", models.RoleOrganizer)
+
+	rec := httptest.NewRecorder()
+	api.Create(rec, requestWithUser("POST", "/", `{"name":"Season","placement_points":[5,-1]}`, org, nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("negative points: status %d, want 400", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	api.Create(rec, requestWithUser("POST", "/", `{"name":"Season"}`, org, nil))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create: status %d body %s", rec.Code, rec.Body.String())
+	}
+	var l models.League
+	json.NewDecoder(rec.Body).Decode(&l)
+	if len(l.PlacementPoints) != len(models.DefaultPlacementPoints) {
+		t.Errorf("created with points %v, want the defaults", l.PlacementPoints)
+	}
+	lid := strconv.FormatInt(l.ID, 10)
+
+	tourn := mustCreateTournament(t, database, org.ID, models.TournamentStatusScheduled)
+	params := map[string]string{"lid": lid, "id": strconv.FormatInt(tourn.ID, 10)}
+	rec = httptest.NewRecorder()
+	api.AddTournament(rec, requestWithUser("PUT", "/", "", org, params))
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("add tournament: status %d body %s", rec.Code, rec.Body.String())
+	}
+	rec = httptest.NewRecorder()
+	api.AddTournament(rec, requestWithUser("PUT", "/", "", org, map[string]string{"lid": lid, "id": "999999"}))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("missing tournament: status %d, want 404", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	api.Get(rec, requestWithUser("GET", "/", "", nil, map[string]string{"lid": lid}))
+	var table engine.LeagueTable
+	json.NewDecoder(rec.Body).Decode(&table)
+	if rec.Code != http.StatusOK || len(table.Pending) != 1 || len(table.Counted) != 0 || len(table.Standings) != 0 {
+		t.Errorf("get: status %d, table %+v", rec.Code, table)
+	}
+
+	rec = httptest.NewRecorder()
+	api.Update(rec, requestWithUser("PUT", "/", `{"name":"Spring","participation_points":1}`, org, map[string]string{"lid": lid}))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("update: status %d body %s", rec.Code, rec.Body.String())
+	}
+	if got, _ := db.GetLeague(context.Background(), database, l.ID); got.Name != "Spring" || len(got.PlacementPoints) != 0 || got.ParticipationPoints != 1 {
+		t.Errorf("after update = %+v", got)
+	}
+
+	rec = httptest.NewRecorder()
+	api.RemoveTournament(rec, requestWithUser("DELETE", "/", "", org, params))
+	if rec.Code != http.StatusNoContent {
+		t.Errorf("remove: status %d", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	api.Delete(rec, requestWithUser("DELETE", "/", "", org, map[string]string{"lid": lid}))
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("delete: status %d", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	api.Get(rec, requestWithUser("GET", "/", "", nil, map[string]string{"lid": lid}))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("get after delete: status %d, want 404", rec.Code)
+	}
+}
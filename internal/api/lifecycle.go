@@ -0,0 +1,127 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/engine"
+	"github.com/dstathis/openswiss/internal/middleware"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/dstathis/swisstools"
+	"github.com/go-chi/chi/v5"
+)
+
+// LifecycleAPI drives a tournament through start, pairing, rounds, finish
+// and reset, for scripts and bots. Every action reports its preconditions
+// up front, and a refused action says which one failed.
+type LifecycleAPI struct {
+	DB *sql.DB
+}
+
+type lifecycleState struct {
+	Status         string                         `json:"status"`
+	Round          int                            `json:"round"`
+	PendingResults int                            `json:"pending_results"`
+	Actions        map[string]engine.Precondition `json:"actions"`
+}
+
+// state loads the tournament's status and the preconditions of every action.
+func (a *LifecycleAPI) state(r *http.Request, id int64) (*lifecycleState, *swisstools.Tournament, error) {
+	t, err := db.GetTournament(r.Context(), a.DB, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	regs, err := db.ListRegistrations(r.Context(), a.DB, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	s := &lifecycleState{Status: t.Status}
+	var eng *swisstools.Tournament
+	if t.EngineState != nil {
+		e, err := swisstools.LoadTournament(t.EngineState)
+		if err != nil {
+			return nil, nil, err
+		}
+		eng = &e
+		s.Round = eng.GetCurrentRound()
+		s.PendingResults = engine.PendingResults(eng)
+	}
+	s.Actions = engine.Preconditions(t, eng, regs)
+	return s, eng, nil
+}
+
+// Get reports the tournament's status, current round and whether each
+// lifecycle action may run now. Min tier: Judge.
+func (a *LifecycleAPI) Get(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if !middleware.AuthorizeTournament(w, r, a.DB, id, models.TierJudge) {
+		return
+	}
+	s, _, err := a.state(r, id)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, "failed to load tournament")
+		return
+	}
+	jsonResponse(w, http.StatusOK, s)
+}
+
+// Run performs the lifecycle action named in the URL. The min tier depends
+// on the action (see engine.ActionTier). A failed precondition is a 409
+// carrying the precondition; success returns the new status and the current
+// round's pairings.
+func (a *LifecycleAPI) Run(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	action := chi.URLParam(r, "action")
+	tier, ok := engine.ActionTier(action)
+	if !ok {
+		jsonError(w, http.StatusNotFound, "unknown action")
+		return
+	}
+	if !middleware.AuthorizeTournament(w, r, a.DB, id, tier) {
+		return
+	}
+
+	var err error
+	if action == engine.ActionReset {
+		err = engine.Reset(r.Context(), a.DB, id)
+	} else {
+		regs, _ := db.ListRegistrations(r.Context(), a.DB, id)
+		err = engine.WithTournamentEngine(r.Context(), a.DB, id,
+			func(tx *sql.Tx, t *models.Tournament, eng *swisstools.Tournament) (string, error) {
+				return engine.RunAction(r.Context(), tx, t, eng, regs, action)
+			})
+	}
+	var pe *engine.PreconditionError
+	if errors.As(err, &pe) {
+		jsonResponse(w, http.StatusConflict, map[string]interface{}{
+			"error":        pe.Reason,
+			"action":       action,
+			"precondition": pe.Precondition,
+		})
+		return
+	}
+	if err != nil {
+		engineError(w, err)
+		return
+	}
+
+	s, eng, err := a.state(r, id)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, "failed to load tournament")
+		return
+	}
+	resp := map[string]interface{}{
+		"action":   action,
+		"status":   s.Status,
+		"round":    s.Round,
+		"actions":  s.Actions,
+		"pairings": []pairingResponse{},
+	}
+	if eng != nil {
+		resp["pairings"] = formatPairings(eng, eng.GetRound())
+	}
+	jsonResponse(w, http.StatusOK, resp)
+}
@@ -0,0 +1,191 @@
+//go:build integration
+
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/engine"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/dstathis/swisstools"
+)
+
+type lifecycleBody struct {
+	Error        string                         `json:"error"`
+	Action       string                         `json:"action"`
+	Status       string                         `json:"status"`
+	Round        int                            `json:"round"`
+	Actions      map[string]engine.Precondition `json:"actions"`
+	Precondition engine.Precondition            `json:"precondition"`
+	Pairings     []pairingResponse              `json:"pairings"`
+}
+
+func runLifecycle(t *testing.T, a *LifecycleAPI, user *models.User, id int64, action string) (int, lifecycleBody) {
+	t.Helper()
+	params := map[string]string{"id": strconv.FormatInt(id, 10), "action": action}
+	rec := httptest.NewRecorder()
+	a.Run(rec, requestWithUser("POST", "/", "", user, params))
+	var body lifecycleBody
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("%s: decode %q: %v", action, rec.Body.String(), err)
+	}
+	return rec.Code, body
+}
+
+func TestLifecycleAPI_DriveTournament(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	a := &LifecycleAPI{DB: database}
+	owner := mustCreateUser(t, database, "owner@example.com", "Owner")
+	tourn := mustCreateTournament(t, database, owner.ID, models.TournamentStatusRegistrationOpen)
+	for i := 0; i < 4; i++ {
+		u := mustCreateUser(t, database, "p"+strconv.Itoa(i)+"@example.com", "P"+strconv.Itoa(i))
+		if _, err := db.CreateRegistration(ctx, database, tourn.ID, u.ID, u.DisplayName); err != nil {
+			t.Fatalf("register: %v", err)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	a.Get(rec, requestWithUser("GET", "/", "", owner, map[string]string{"id": strconv.FormatInt(tourn.ID, 10)}))
+	var state lifecycleBody
+	if err := json.Unmarshal(rec.Body.Bytes(), &state); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if !state.Actions[engine.ActionStart].Allowed || state.Actions[engine.ActionNextRound].Allowed || state.Actions[engine.ActionReset].Allowed {
+		t.Errorf("actions before start = %+v", state.Actions)
+	}
+
+	code, body := runLifecycle(t, a, owner, tourn.ID, engine.ActionStart)
+	if code != http.StatusOK || body.Status != models.TournamentStatusInProgress || body.Round != 1 || len(body.Pairings) != 2 {
+		t.Fatalf("start: %d %+v", code, body)
+	}
+
+	code, body = runLifecycle(t, a, owner, tourn.ID, engine.ActionNextRound)
+	if code != http.StatusConflict || body.Precondition.Allowed || body.Precondition.Reason == "" {
+		t.Fatalf("next_round with pending results: %d %+v", code, body)
+	}
+
+	if err := engine.WithTournamentEngine(ctx, database, tourn.ID,
+		func(tx *sql.Tx, tm *models.Tournament, eng *swisstools.Tournament) (string, error) {
+			for _, p := range eng.GetRound() {
+				if err := eng.AddResult(p.PlayerA(), 2, 0, 0); err != nil {
+					return "", err
+				}
+			}
+			return "", nil
+		}); err != nil {
+		t.Fatalf("enter results: %v", err)
+	}
+
+	code, body = runLifecycle(t, a, owner, tourn.ID, engine.ActionNextRound)
+	if code != http.StatusOK || body.Round != 2 {
+		t.Fatalf("next_round: %d %+v", code, body)
+	}
+	if !body.Actions[engine.ActionPair].Allowed || body.Actions[engine.ActionFinish].Allowed {
+		t.Errorf("actions in round 2 = %+v", body.Actions)
+	}
+
+	code, body = runLifecycle(t, a, owner, tourn.ID, engine.ActionReset)
+	if code != http.StatusOK || body.Status != models.TournamentStatusRegistrationOpen || body.Round != 0 {
+		t.Fatalf("reset: %d %+v", code, body)
+	}
+	got, _ := db.GetTournament(ctx, database, tourn.ID)
+	if got.EngineState != nil {
+		t.Error("engine state survived reset")
+	}
+	regs, _ := db.ListRegistrations(ctx, database, tourn.ID)
+	for _, r := range regs {
+		if r.EnginePlayerID != nil {
+			t.Errorf("registration %d kept engine player %d", r.ID, *r.EnginePlayerID)
+		}
+	}
+}
+
+func TestLifecycleAPI_ResetNeedsAdmin(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	a := &LifecycleAPI{DB: database}
+	owner, tourn := freshStarted(t, database)
+	co := mustCreateUser(t, database, "co@example.com", "Co")
+	if err := db.AddTournamentStaff(ctx, database, &models.TournamentStaff{
+		TournamentID: tourn.ID, UserID: co.ID, Tier: models.TierCoOrganizer, GrantedBy: &owner.ID,
+	}); err != nil {
+		t.Fatalf("add staff: %v", err)
+	}
+
+	params := map[string]string{"id": strconv.FormatInt(tourn.ID, 10), "action": engine.ActionReset}
+	rec := httptest.NewRecorder()
+	a.Run(rec, requestWithUser("POST", "/", "", co, params))
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("co-organizer reset: status %d, want 403", rec.Code)
+	}
+
+	if code, body := runLifecycle(t, a, co, tourn.ID, engine.ActionPair); code != http.StatusOK || body.Round != 1 {
+		t.Errorf("co-organizer re-pair: %d %+v", code, body)
+	}
+}
+
+func TestLifecycleAPI_UnknownAction(t *testing.T) {
+	database := testDB(t)
+	a := &LifecycleAPI{DB: database}
+	owner := mustCreateUser(t, database, "owner@example.com", "Owner")
+	tourn := mustCreateTournament(t, database, owner.ID, models.TournamentStatusRegistrationOpen)
+
+	rec := httptest.NewRecorder()
+	a.Run(rec, requestWithUser("POST", "/", "", owner, map[string]string{"id": strconv.FormatInt(tourn.ID, 10), "action": "explode"}))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", rec.Code)
+	}
+}
+
+func TestLifecycleAPI_PublishDraft(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	a := &LifecycleAPI{DB: database}
+	rounds := &RoundsAPI{DB: database}
+	owner, tourn := startedTournament(t, database) // round 1 results already in
+	tourn.PreviewPairings = true
+	if err := db.UpdateTournament(ctx, database, tourn); err != nil {
+		t.Fatalf("enable preview: %v", err)
+	}
+	params := map[string]string{"id": strconv.FormatInt(tourn.ID, 10)}
+
+	code, body := runLifecycle(t, a, owner, tourn.ID, engine.ActionNextRound)
+	if code != http.StatusOK || body.Round != 2 || !body.Actions[engine.ActionPublish].Allowed || body.Actions[engine.ActionNextRound].Allowed {
+		t.Fatalf("next_round: %d %+v", code, body)
+	}
+
+	// Anonymous callers get no pairings until they are published.
+	current := func() map[string]interface{} {
+		rec := httptest.NewRecorder()
+		rounds.GetCurrentRound(rec, requestWithUser("GET", "/", "", nil, params))
+		var got map[string]interface{}
+		if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+			t.Fatalf("decode %q: %v", rec.Body.String(), err)
+		}
+		return got
+	}
+	if got := current(); got["published"] != false || len(got["pairings"].([]interface{})) != 0 {
+		t.Errorf("draft round = %+v", got)
+	}
+	rec := httptest.NewRecorder()
+	rounds.GetRound(rec, requestWithUser("GET", "/", "", nil, map[string]string{"id": params["id"], "round": "2"}))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("GetRound on a draft: status %d", rec.Code)
+	}
+
+	code, body = runLifecycle(t, a, owner, tourn.ID, engine.ActionPublish)
+	if code != http.StatusOK || body.Actions[engine.ActionPublish].Allowed {
+		t.Fatalf("publish: %d %+v", code, body)
+	}
+	if got := current(); got["published"] != true || len(got["pairings"].([]interface{})) != 2 {
+		t.Errorf("published round = %+v", got)
+	}
+}
@@ -0,0 +1,141 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/engine"
+	"github.com/dstathis/openswiss/internal/middleware"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/go-chi/chi/v5"
+	"github.com/lib/pq"
+)
+
+// RenamePlayer changes a registration's name, and the engine player's once
+// the tournament has started. A name another player has is a 409.
+func (a *PlayersAPI) RenamePlayer(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	regID, _ := strconv.ParseInt(chi.URLParam(r, "regID"), 10, 64)
+	if !middleware.AuthorizeTournament(w, r, a.DB, id, models.TierCoOrganizer) {
+		return
+	}
+	var body struct {
+		Name string `json:"name"`
+	}
+	if err := decodeJSON(r, &body); err != nil {
+		jsonError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if err := engine.RenamePlayer(r.Context(), a.DB, id, regID, body.Name); err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			jsonError(w, http.StatusConflict, "another player already has that name")
+			return
+		}
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	reg, err := db.GetRegistrationByID(r.Context(), a.DB, regID)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, "failed to load registration")
+		return
+	}
+	jsonResponse(w, http.StatusOK, reg)
+}
+
+// AnonymizePlayer removes a registration's personal data, a guest's or a
+// user's, leaving "Player #N" (see engine.AnonymizeRegistration). Returns
+// the registration. Min tier: Admin.
+func (a *PlayersAPI) AnonymizePlayer(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	regID, _ := strconv.ParseInt(chi.URLParam(r, "regID"), 10, 64)
+	if !middleware.AuthorizeTournament(w, r, a.DB, id, models.TierAdmin) {
+		return
+	}
+	if err := engine.AnonymizeRegistration(r.Context(), a.DB, id, regID); err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	reg, err := db.GetRegistrationByID(r.Context(), a.DB, regID)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, "failed to load registration")
+		return
+	}
+	jsonResponse(w, http.StatusOK, reg)
+}
+
+// MergePlayers folds the registration duplicate_id into keep_id and returns
+// the one kept.
+func (a *PlayersAPI) MergePlayers(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if !middleware.AuthorizeTournament(w, r, a.DB, id, models.TierCoOrganizer) {
+		return
+	}
+	var body struct {
+		KeepID      int64 `json:"keep_id"`
+		DuplicateID int64 `json:"duplicate_id"`
+	}
+	if err := decodeJSON(r, &body); err != nil || body.KeepID == 0 || body.DuplicateID == 0 {
+		jsonError(w, http.StatusBadRequest, "keep_id and duplicate_id are required")
+		return
+	}
+	if err := engine.MergePlayers(r.Context(), a.DB, id, body.KeepID, body.DuplicateID); err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	reg, err := db.GetRegistrationByID(r.Context(), a.DB, body.KeepID)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, "failed to load registration")
+		return
+	}
+	jsonResponse(w, http.StatusOK, reg)
+}
+
+// PossibleDuplicates lists the registrations whose names are close to an
+// earlier one's, with the earlier one (see engine.PossibleDuplicates).
+func (a *PlayersAPI) PossibleDuplicates(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if !middleware.AuthorizeTournament(w, r, a.DB, id, models.TierCoOrganizer) {
+		return
+	}
+	regs, err := db.ListRegistrations(r.Context(), a.DB, id)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, "failed to list registrations")
+		return
+	}
+	distinct, err := db.ListDistinctRegistrations(r.Context(), a.DB, id)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, "failed to list registrations")
+		return
+	}
+	dups := engine.PossibleDuplicates(regs, distinct)
+	if dups == nil {
+		dups = []engine.PossibleDuplicate{}
+	}
+	jsonResponse(w, http.StatusOK, dups)
+}
+
+// MarkDistinct records that the registration and other_id are two different
+// people, so they are no longer listed as possible duplicates.
+func (a *PlayersAPI) MarkDistinct(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	regID, _ := strconv.ParseInt(chi.URLParam(r, "regID"), 10, 64)
+	if !middleware.AuthorizeTournament(w, r, a.DB, id, models.TierCoOrganizer) {
+		return
+	}
+	var body struct {
+		OtherID int64 `json:"other_id"`
+	}
+	if err := decodeJSON(r, &body); err != nil || body.OtherID == 0 {
+		jsonError(w, http.StatusBadRequest, "other_id is required")
+		return
+	}
+	user := middleware.GetUser(r.Context())
+	if err := engine.MarkDistinct(r.Context(), a.DB, id, regID, body.OtherID, user.ID); err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
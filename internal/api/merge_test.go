@@ -0,0 +1,168 @@
+//go:build integration
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/engine"
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+func TestPlayersAPI_RenamePlayer(t *testing.T) {
+	database := testDB(t)
+	api := &PlayersAPI{DB: database}
+	ctx := context.Background()
+	owner := mustCreateUser(t, database, "owner@example.com", "Owner")
+	tourn := mustCreateTournament(t, database, owner.ID, models.TournamentStatusRegistrationOpen)
+	reg, _ := db.CreateGuestRegistration(ctx, database, tourn.ID, "Jonh")
+	if _, err := db.CreateGuestRegistration(ctx, database, tourn.ID, "Mary"); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	params := map[string]string{"id": strconv.FormatInt(tourn.ID, 10), "regID": strconv.FormatInt(reg.ID, 10)}
+
+	rec := httptest.NewRecorder()
+	api.RenamePlayer(rec, requestWithUser("PUT", "/", `{"name":"John"}`, owner, params))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d body %s", rec.Code, rec.Body.String())
+	}
+	var got models.Registration
+	json.NewDecoder(rec.Body).Decode(&got)
+	if got.DisplayName != "John" {
+		t.Errorf("display_name = %q, want John", got.DisplayName)
+	}
+
+	for body, want := range map[string]int{
+		`{"name":"MARY"}`: http.StatusConflict,
+		`{"name":" "}`:    http.StatusBadRequest,
+		`not-json`:        http.StatusBadRequest,
+	} {
+		rec = httptest.NewRecorder()
+		api.RenamePlayer(rec, requestWithUser("PUT", "/", body, owner, params))
+		if rec.Code != want {
+			t.Errorf("%s: status %d, want %d", body, rec.Code, want)
+		}
+	}
+}
+
+func TestPlayersAPI_AnonymizePlayer(t *testing.T) {
+	database := testDB(t)
+	api := &PlayersAPI{DB: database}
+	ctx := context.Background()
+	owner := mustCreateUser(t, database, "owner-anonymize@example.com", "Owner")
+	tourn := mustCreateTournament(t, database, owner.ID, models.TournamentStatusRegistrationOpen)
+	guest, _ := db.CreateGuestRegistration(ctx, database, tourn.ID, "Walk In")
+	if err := db.ArchiveTournament(ctx, database, tourn.ID, []models.ArchivedResult{{Place: 1, Name: "Walk In"}}); err != nil {
+		t.Fatal(err)
+	}
+	params := map[string]string{"id": strconv.FormatInt(tourn.ID, 10), "regID": strconv.FormatInt(guest.ID, 10)}
+
+	rec := httptest.NewRecorder()
+	api.AnonymizePlayer(rec, requestWithUser("POST", "/", "", nil, params))
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("anonymous: status %d, want 403", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	api.AnonymizePlayer(rec, requestWithUser("POST", "/", "", owner, params))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d body %s", rec.Code, rec.Body.String())
+	}
+	var got models.Registration
+	json.NewDecoder(rec.Body).Decode(&got)
+	if want := engine.AnonymousName(guest.ID); got.DisplayName != want {
+		t.Errorf("display_name = %q, want %q", got.DisplayName, want)
+	}
+	if results, _ := db.ListArchivedResults(ctx, database, tourn.ID); len(results) != 1 || results[0].Name != got.DisplayName {
+		t.Errorf("archived = %+v", results)
+	}
+}
+
+func TestPlayersAPI_MergePlayers(t *testing.T) {
+	database := testDB(t)
+	api := &PlayersAPI{DB: database}
+	ctx := context.Background()
+	owner := mustCreateUser(t, database, "owner@example.com", "Owner")
+	tourn := mustCreateTournament(t, database, owner.ID, models.TournamentStatusRegistrationOpen)
+	keep, _ := db.CreateGuestRegistration(ctx, database, tourn.ID, "Sam Lee")
+	user := mustCreateUser(t, database, "sam@example.com", "Sam")
+	dup, _ := db.CreateRegistration(ctx, database, tourn.ID, user.ID, user.DisplayName)
+	params := map[string]string{"id": strconv.FormatInt(tourn.ID, 10)}
+
+	rec := httptest.NewRecorder()
+	api.MergePlayers(rec, requestWithUser("POST", "/", `{"keep_id":0}`, owner, params))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("missing duplicate_id: status %d, want 400", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	api.MergePlayers(rec, requestWithUser("POST", "/", fmt.Sprintf(`{"keep_id":%d,"duplicate_id":%d}`, keep.ID, dup.ID), owner, params))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d body %s", rec.Code, rec.Body.String())
+	}
+	var got models.Registration
+	json.NewDecoder(rec.Body).Decode(&got)
+	if got.ID != keep.ID || got.DisplayName != "Sam Lee" || got.UserID == nil || *got.UserID != user.ID {
+		t.Errorf("kept registration = %+v, want Sam Lee with Sam's account", got)
+	}
+	if n, _ := db.CountRegistrations(ctx, database, tourn.ID); n != 1 {
+		t.Errorf("%d registrations left, want 1", n)
+	}
+
+	other := mustCreateTournament(t, database, owner.ID, models.TournamentStatusRegistrationOpen)
+	elsewhere, _ := db.CreateGuestRegistration(ctx, database, other.ID, "Elsewhere")
+	rec = httptest.NewRecorder()
+	api.MergePlayers(rec, requestWithUser("POST", "/", fmt.Sprintf(`{"keep_id":%d,"duplicate_id":%d}`, keep.ID, elsewhere.ID), owner, params))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("registration from another tournament: status %d, want 400", rec.Code)
+	}
+}
+
+func TestPlayersAPI_PossibleDuplicates(t *testing.T) {
+	database := testDB(t)
+	api := &PlayersAPI{DB: database}
+	ctx := context.Background()
+	owner := mustCreateUser(t, database, "owner@example.com", "Owner")
+	tourn := mustCreateTournament(t, database, owner.ID, models.TournamentStatusRegistrationOpen)
+	first, _ := db.CreateGuestRegistration(ctx, database, tourn.ID, "José García")
+	second, _ := db.CreateGuestRegistration(ctx, database, tourn.ID, "Jose Garcia")
+	db.CreateGuestRegistration(ctx, database, tourn.ID, "Maria Rossi")
+	params := map[string]string{"id": strconv.FormatInt(tourn.ID, 10)}
+
+	list := func() []engine.PossibleDuplicate {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		api.PossibleDuplicates(rec, requestWithUser("GET", "/", "", owner, params))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("list: status %d body %s", rec.Code, rec.Body.String())
+		}
+		var dups []engine.PossibleDuplicate
+		json.NewDecoder(rec.Body).Decode(&dups)
+		return dups
+	}
+	if dups := list(); len(dups) != 1 || dups[0].Registration.ID != second.ID || dups[0].Existing.ID != first.ID {
+		t.Fatalf("duplicates = %+v, want Jose Garcia against José García", dups)
+	}
+
+	params["regID"] = strconv.FormatInt(second.ID, 10)
+	rec := httptest.NewRecorder()
+	api.MarkDistinct(rec, requestWithUser("POST", "/", `{}`, owner, params))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("missing other_id: status %d, want 400", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	api.MarkDistinct(rec, requestWithUser("POST", "/", fmt.Sprintf(`{"other_id":%d}`, first.ID), owner, params))
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("mark distinct: status %d body %s", rec.Code, rec.Body.String())
+	}
+	if dups := list(); len(dups) != 0 {
+		t.Errorf("after marking distinct: %+v, want none", dups)
+	}
+}
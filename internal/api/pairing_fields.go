@@ -0,0 +1,181 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/engine"
+	"github.com/dstathis/openswiss/internal/middleware"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/dstathis/swisstools"
+	"github.com/go-chi/chi/v5"
+	"github.com/lib/pq"
+)
+
+type PairingFieldsAPI struct {
+	DB *sql.DB
+}
+
+// List returns every custom pairing field of the tournament, staff-only ones
+// included. Min tier: Judge.
+func (a *PairingFieldsAPI) List(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if !middleware.AuthorizeTournament(w, r, a.DB, id, models.TierJudge) {
+		return
+	}
+	fields, err := db.ListPairingFields(r.Context(), a.DB, id)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, "failed to list pairing fields")
+		return
+	}
+	if fields == nil {
+		fields = []models.PairingField{}
+	}
+	jsonResponse(w, http.StatusOK, fields)
+}
+
+type createPairingFieldRequest struct {
+	Label  string `json:"label"`
+	Public bool   `json:"public"`
+}
+
+// Create defines a new custom pairing field. Min tier: Co-organizer.
+func (a *PairingFieldsAPI) Create(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if !middleware.AuthorizeTournament(w, r, a.DB, id, models.TierCoOrganizer) {
+		return
+	}
+	var req createPairingFieldRequest
+	if err := decodeJSON(r, &req); err != nil {
+		jsonError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if !models.ValidPairingFieldLabel(req.Label) {
+		jsonError(w, http.StatusBadRequest, fmt.Sprintf("label is required and may be at most %d characters", models.MaxPairingFieldLabel))
+		return
+	}
+	f := &models.PairingField{
+		TournamentID: id,
+		Label:        strings.TrimSpace(req.Label),
+		Public:       req.Public,
+	}
+	if err := db.CreatePairingField(r.Context(), a.DB, f); err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			jsonError(w, http.StatusConflict, "a field with that label already exists")
+			return
+		}
+		jsonError(w, http.StatusInternalServerError, "failed to create pairing field")
+		return
+	}
+	jsonResponse(w, http.StatusCreated, f)
+}
+
+// Delete removes a custom pairing field and all of its values. Min tier:
+// Co-organizer.
+func (a *PairingFieldsAPI) Delete(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	fieldID, err := strconv.ParseInt(chi.URLParam(r, "fieldID"), 10, 64)
+	if err != nil {
+		jsonError(w, http.StatusNotFound, "not found")
+		return
+	}
+	if !middleware.AuthorizeTournament(w, r, a.DB, id, models.TierCoOrganizer) {
+		return
+	}
+	if err := db.DeletePairingField(r.Context(), a.DB, id, fieldID); err != nil {
+		if errors.Is(err, db.ErrPairingFieldNotFound) {
+			jsonError(w, http.StatusNotFound, "not found")
+			return
+		}
+		jsonError(w, http.StatusInternalServerError, "failed to delete pairing field")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type setPairingFieldsRequest struct {
+	// Fields maps field label to value. An empty value clears the field;
+	// labels not present are left unchanged.
+	Fields map[string]string `json:"fields"`
+}
+
+// SetValues sets custom field values on one table of the current round.
+// Min tier: Judge.
+func (a *PairingFieldsAPI) SetValues(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	table, err := strconv.Atoi(chi.URLParam(r, "table"))
+	if err != nil || table < 1 {
+		jsonError(w, http.StatusNotFound, "not found")
+		return
+	}
+	if !middleware.AuthorizeTournament(w, r, a.DB, id, models.TierJudge) {
+		return
+	}
+	var req setPairingFieldsRequest
+	if err := decodeJSON(r, &req); err != nil {
+		jsonError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	fields, err := db.ListPairingFields(r.Context(), a.DB, id)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, "failed to list pairing fields")
+		return
+	}
+	byLabel := make(map[string]int64, len(fields))
+	for _, f := range fields {
+		byLabel[f.Label] = f.ID
+	}
+	for label, value := range req.Fields {
+		if _, ok := byLabel[label]; !ok {
+			jsonError(w, http.StatusBadRequest, fmt.Sprintf("unknown field %q", label))
+			return
+		}
+		if utf8.RuneCountInString(strings.TrimSpace(value)) > models.MaxPairingFieldValue {
+			jsonError(w, http.StatusBadRequest, fmt.Sprintf("%s is longer than %d characters", label, models.MaxPairingFieldValue))
+			return
+		}
+	}
+
+	t, err := db.GetTournament(r.Context(), a.DB, id)
+	if err != nil {
+		jsonError(w, http.StatusNotFound, "not found")
+		return
+	}
+	if t.EngineState == nil {
+		jsonError(w, http.StatusBadRequest, "tournament not started")
+		return
+	}
+	eng, err := swisstools.LoadTournament(t.EngineState)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, "failed to load engine state")
+		return
+	}
+	found := false
+	for i, p := range eng.GetRound() {
+		if engine.TableNumber(i, p) == table {
+			found = true
+			break
+		}
+	}
+	if !found {
+		jsonError(w, http.StatusNotFound, "no such table in the current round")
+		return
+	}
+
+	round := eng.GetCurrentRound()
+	for label, value := range req.Fields {
+		if err := db.SetPairingFieldValue(r.Context(), a.DB, byLabel[label], round, table, strings.TrimSpace(value)); err != nil {
+			jsonError(w, http.StatusInternalServerError, "failed to save pairing fields")
+			return
+		}
+	}
+	jsonResponse(w, http.StatusOK, map[string]string{"status": "ok"})
+}
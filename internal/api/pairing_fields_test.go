@@ -0,0 +1,119 @@
+//go:build integration
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+func TestPairingFieldsAPI_CreateListDelete(t *testing.T) {
+	database := testDB(t)
+	owner, tourn := freshStarted(t, database)
+	a := &PairingFieldsAPI{DB: database}
+	idStr := strconv.FormatInt(tourn.ID, 10)
+	params := map[string]string{"id": idStr}
+
+	w := httptest.NewRecorder()
+	a.Create(w, requestWithUser("POST", "/", `{"label":"Stream","public":true}`, owner, params))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create: status %d body %s", w.Code, w.Body.String())
+	}
+	var created models.PairingField
+	json.NewDecoder(w.Body).Decode(&created)
+
+	w = httptest.NewRecorder()
+	a.Create(w, requestWithUser("POST", "/", `{"label":"Stream"}`, owner, params))
+	if w.Code != http.StatusConflict {
+		t.Errorf("duplicate: status %d, want 409", w.Code)
+	}
+	w = httptest.NewRecorder()
+	a.Create(w, requestWithUser("POST", "/", `{"label":"  "}`, owner, params))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("blank label: status %d, want 400", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	a.List(w, requestWithUser("GET", "/", "", owner, params))
+	var fields []models.PairingField
+	json.NewDecoder(w.Body).Decode(&fields)
+	if len(fields) != 1 || fields[0].Label != "Stream" || !fields[0].Public {
+		t.Errorf("list = %+v", fields)
+	}
+
+	stranger := mustCreateUser(t, database, "stranger@example.com", "Stranger")
+	w = httptest.NewRecorder()
+	a.List(w, requestWithUser("GET", "/", "", stranger, params))
+	if w.Code != http.StatusForbidden {
+		t.Errorf("stranger list: status %d, want 403", w.Code)
+	}
+
+	delParams := map[string]string{"id": idStr, "fieldID": strconv.FormatInt(created.ID, 10)}
+	w = httptest.NewRecorder()
+	a.Delete(w, requestWithUser("DELETE", "/", "", owner, delParams))
+	if w.Code != http.StatusNoContent {
+		t.Errorf("delete: status %d", w.Code)
+	}
+	w = httptest.NewRecorder()
+	a.Delete(w, requestWithUser("DELETE", "/", "", owner, delParams))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("second delete: status %d, want 404", w.Code)
+	}
+}
+
+func TestPairingFieldsAPI_SetValuesShownPublicly(t *testing.T) {
+	database := testDB(t)
+	owner, tourn := freshStarted(t, database)
+	a := &PairingFieldsAPI{DB: database}
+	idStr := strconv.FormatInt(tourn.ID, 10)
+	params := map[string]string{"id": idStr}
+
+	for _, body := range []string{`{"label":"Stream","public":true}`, `{"label":"Notes"}`} {
+		w := httptest.NewRecorder()
+		a.Create(w, requestWithUser("POST", "/", body, owner, params))
+		if w.Code != http.StatusCreated {
+			t.Fatalf("create %s: status %d", body, w.Code)
+		}
+	}
+
+	tableParams := map[string]string{"id": idStr, "table": "1"}
+	w := httptest.NewRecorder()
+	a.SetValues(w, requestWithUser("PUT", "/", `{"fields":{"Stream":"Main stage","Notes":"late start"}}`, owner, tableParams))
+	if w.Code != http.StatusOK {
+		t.Fatalf("set: status %d body %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	a.SetValues(w, requestWithUser("PUT", "/", `{"fields":{"Map":"x"}}`, owner, tableParams))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("unknown field: status %d, want 400", w.Code)
+	}
+	w = httptest.NewRecorder()
+	a.SetValues(w, requestWithUser("PUT", "/", `{"fields":{"Stream":"x"}}`, owner, map[string]string{"id": idStr, "table": "99"}))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("missing table: status %d, want 404", w.Code)
+	}
+
+	rounds := &RoundsAPI{DB: database}
+	w = httptest.NewRecorder()
+	rounds.GetCurrentRound(w, requestWithUser("GET", "/", "", nil, params))
+	var resp struct {
+		Pairings []pairingResponse `json:"pairings"`
+	}
+	json.NewDecoder(w.Body).Decode(&resp)
+	if len(resp.Pairings) == 0 {
+		t.Fatal("no pairings")
+	}
+	got := resp.Pairings[0].Fields
+	if got["Stream"] != "Main stage" {
+		t.Errorf("public field missing: %v", got)
+	}
+	if _, leaked := got["Notes"]; leaked {
+		t.Errorf("staff-only field leaked into public response: %v", got)
+	}
+}
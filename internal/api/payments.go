@@ -0,0 +1,43 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/dstathis/openswiss/internal/engine"
+	"github.com/dstathis/openswiss/internal/middleware"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/go-chi/chi/v5"
+)
+
+// SetPayment records how a player paid from {"status", "amount",
+// "method"}: status unpaid, paid or comped, amount in minor units and
+// method free text, both optional. A player marked paid without an amount
+// is taken to have paid the entry fee. Returns the registration; 404 for a
+// registration of another tournament. Min tier: Co-organizer.
+func (a *PlayersAPI) SetPayment(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	regID, _ := strconv.ParseInt(chi.URLParam(r, "regID"), 10, 64)
+	if !middleware.AuthorizeTournament(w, r, a.DB, id, models.TierCoOrganizer) {
+		return
+	}
+	var body struct {
+		Status string  `json:"status"`
+		Amount *int    `json:"amount"`
+		Method *string `json:"method"`
+	}
+	if err := decodeJSON(r, &body); err != nil {
+		jsonError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	reg, err := engine.SetPayment(r.Context(), a.DB, id, regID, engine.Payment{Status: body.Status, Amount: body.Amount, Method: body.Method})
+	switch {
+	case errors.Is(err, engine.ErrNotRegistered):
+		jsonError(w, http.StatusNotFound, err.Error())
+	case err != nil:
+		jsonError(w, http.StatusBadRequest, err.Error())
+	default:
+		jsonResponse(w, http.StatusOK, reg)
+	}
+}
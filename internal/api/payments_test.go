@@ -0,0 +1,53 @@
+//go:build integration
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+func TestPlayersAPI_SetPayment(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	api := &PlayersAPI{DB: database}
+	owner := mustCreateUser(t, database, "owner-payment@example.com", "OwnerPayment")
+	tourn := mustCreateTournament(t, database, owner.ID, models.TournamentStatusRegistrationOpen)
+	tourn.EntryFee = 1500
+	if err := db.UpdateTournament(ctx, database, tourn); err != nil {
+		t.Fatal(err)
+	}
+	reg, _ := db.CreateGuestRegistration(ctx, database, tourn.ID, "Walk-in")
+	setPayment := func(regID int64, body string) *httptest.ResponseRecorder {
+		params := map[string]string{"id": strconv.FormatInt(tourn.ID, 10), "regID": strconv.FormatInt(regID, 10)}
+		rec := httptest.NewRecorder()
+		api.SetPayment(rec, requestWithUser("PUT", "/", body, owner, params))
+		return rec
+	}
+
+	rec := setPayment(reg.ID, `{"status": "paid", "method": "cash"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d body %s", rec.Code, rec.Body.String())
+	}
+	var got models.Registration
+	json.NewDecoder(rec.Body).Decode(&got)
+	if got.PaymentStatus != models.PaymentPaid || got.PaymentAmount == nil || *got.PaymentAmount != 1500 || got.PaymentMethod == nil || *got.PaymentMethod != "cash" {
+		t.Errorf("registration = %+v, want paid 1500 in cash", got)
+	}
+
+	if rec := setPayment(reg.ID, `{"status": "owed"}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("bad status: status %d, want 400", rec.Code)
+	}
+	other := mustCreateTournament(t, database, owner.ID, models.TournamentStatusRegistrationOpen)
+	stranger, _ := db.CreateGuestRegistration(ctx, database, other.ID, "Elsewhere")
+	if rec := setPayment(stranger.ID, `{"status": "paid"}`); rec.Code != http.StatusNotFound {
+		t.Errorf("other tournament's player: status %d, want 404", rec.Code)
+	}
+}
@@ -1,8 +1,10 @@
 package api
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -12,6 +14,7 @@ import (
 	"github.com/dstathis/openswiss/internal/engine"
 	"github.com/dstathis/openswiss/internal/middleware"
 	"github.com/dstathis/openswiss/internal/models"
+	"github.com/dstathis/openswiss/internal/pow"
 	"github.com/dstathis/swisstools"
 	"github.com/go-chi/chi/v5"
 )
@@ -32,11 +35,12 @@ func (a *PlayersAPI) List(w http.ResponseWriter, r *http.Request) {
 	}
 
 	type playerResponse struct {
-		RegistrationID int64  `json:"registration_id"`
-		UserID         *int64 `json:"user_id,omitempty"`
-		DisplayName    string `json:"display_name"`
-		IsGuest        bool   `json:"is_guest"`
-		Status         string `json:"status"`
+		RegistrationID int64    `json:"registration_id"`
+		UserID         *int64   `json:"user_id,omitempty"`
+		DisplayName    string   `json:"display_name"`
+		IsGuest        bool     `json:"is_guest"`
+		Status         string   `json:"status"`
+		Members        []string `json:"members,omitempty"`
 	}
 	var players []playerResponse
 	for _, r := range regs {
@@ -46,6 +50,7 @@ func (a *PlayersAPI) List(w http.ResponseWriter, r *http.Request) {
 			DisplayName:    r.DisplayName,
 			IsGuest:        r.IsGuest(),
 			Status:         r.Status,
+			Members:        r.Members,
 		})
 	}
 	if players == nil {
@@ -66,27 +71,56 @@ func (a *PlayersAPI) Register(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	user := middleware.GetUser(r.Context())
-	if t.MaxPlayers > 0 {
-		count, _ := db.CountRegistrations(r.Context(), a.DB, id)
-		if count >= t.MaxPlayers {
-			jsonError(w, http.StatusBadRequest, "tournament is full")
+	// The body is optional.
+	var body struct {
+		PowNonce       string `json:"pow_nonce"`
+		ContactConsent bool   `json:"contact_consent"`
+	}
+	decodeJSON(r, &body)
+	if t.BotCheck {
+		if !pow.Verify(pow.Challenge(t.ID, user.ID), body.PowNonce, pow.Bits) {
+			jsonError(w, http.StatusBadRequest, fmt.Sprintf("bot check: pow_nonce must solve %q at %d bits", pow.Challenge(t.ID, user.ID), pow.Bits))
 			return
 		}
 	}
-
-	var reg *models.Registration
+	status := models.RegistrationStatusConfirmed
 	if t.RequireDecklist {
-		reg, err = db.CreatePendingRegistration(r.Context(), a.DB, id, user.ID, user.DisplayName)
-	} else {
-		reg, err = db.CreateRegistration(r.Context(), a.DB, id, user.ID, user.DisplayName)
+		status = models.RegistrationStatusPending
 	}
+	reg, err := db.RegisterUser(r.Context(), a.DB, id, user.ID, user.DisplayName, status, t.MaxPlayers)
 	if err != nil {
 		jsonError(w, http.StatusBadRequest, "already registered or error")
 		return
 	}
+	if body.ContactConsent {
+		if err := db.SetContactConsent(r.Context(), a.DB, id, user.ID, true); err != nil {
+			jsonError(w, http.StatusInternalServerError, "failed to save contact consent")
+			return
+		}
+		reg.ContactConsent = true
+	}
 	jsonResponse(w, http.StatusCreated, reg)
 }
 
+// SetContactConsent gives or withdraws the caller's consent to hear from
+// the organizer after the event. Body: {"contact_consent": bool}.
+func (a *PlayersAPI) SetContactConsent(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	var body struct {
+		ContactConsent *bool `json:"contact_consent"`
+	}
+	if err := decodeJSON(r, &body); err != nil || body.ContactConsent == nil {
+		jsonError(w, http.StatusBadRequest, "contact_consent is required")
+		return
+	}
+	user := middleware.GetUser(r.Context())
+	if err := db.SetContactConsent(r.Context(), a.DB, id, user.ID, *body.ContactConsent); err != nil {
+		jsonError(w, http.StatusNotFound, "not registered")
+		return
+	}
+	jsonResponse(w, http.StatusOK, map[string]bool{"contact_consent": *body.ContactConsent})
+}
+
 func (a *PlayersAPI) Unregister(w http.ResponseWriter, r *http.Request) {
 	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
 	t, err := db.GetTournament(r.Context(), a.DB, id)
@@ -103,8 +137,11 @@ func (a *PlayersAPI) Unregister(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
-// AddPlayer manually adds a guest player. Pre-tournament writes a guest
+// AddPlayer manually adds a guest player, named by player_name or picked
+// from the player registry by player_id. Pre-tournament writes a guest
 // registration only; mid-tournament also registers the player in the engine.
+// A name the tournament already has is 409 unless allow_duplicate is true
+// (see engine.CheckNewName).
 func (a *PlayersAPI) AddPlayer(w http.ResponseWriter, r *http.Request) {
 	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
 	t, err := db.GetTournament(r.Context(), a.DB, id)
@@ -123,20 +160,78 @@ func (a *PlayersAPI) AddPlayer(w http.ResponseWriter, r *http.Request) {
 		jsonError(w, http.StatusBadRequest, "cannot add players in this tournament state")
 		return
 	}
+	if t.Status == models.TournamentStatusInProgress && t.PairingAlgorithm == models.PairingRoundRobin {
+		jsonError(w, http.StatusBadRequest, "a round robin can't take new players once it has started")
+		return
+	}
 
 	var body struct {
-		PlayerName string `json:"player_name"`
+		PlayerName     string   `json:"player_name"`
+		PlayerID       int64    `json:"player_id"`
+		Members        []string `json:"members"`
+		AllowDuplicate bool     `json:"allow_duplicate"`
 	}
-	if err := decodeJSON(r, &body); err != nil || strings.TrimSpace(body.PlayerName) == "" {
+	if err := decodeJSON(r, &body); err != nil || (strings.TrimSpace(body.PlayerName) == "" && body.PlayerID == 0) {
 		jsonError(w, http.StatusBadRequest, "player_name is required")
 		return
 	}
+	// In a team event the new registration is a team, entered with its roster.
+	if t.TeamSize > 0 {
+		if body.Members, err = engine.CheckMembers(t, body.Members); err != nil {
+			jsonError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
 
-	reg, err := db.CreateGuestRegistration(r.Context(), a.DB, id, body.PlayerName)
+	var picked *models.Player
+	if body.PlayerID != 0 {
+		// The registry is for organizers, not every co-organizer.
+		if !middleware.GetUser(r.Context()).CanOrganize() {
+			jsonError(w, http.StatusForbidden, "forbidden")
+			return
+		}
+		if picked, err = db.GetPlayer(r.Context(), a.DB, body.PlayerID); err != nil {
+			jsonError(w, http.StatusBadRequest, "no such player in the registry")
+			return
+		}
+		body.PlayerName = picked.Name
+	} else if body.PlayerName, err = models.ValidatePlayerName(body.PlayerName); err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if !body.AllowDuplicate {
+		err := engine.CheckNewName(r.Context(), a.DB, id, body.PlayerName)
+		var dup *engine.DuplicateNameError
+		switch {
+		case errors.As(err, &dup):
+			// A registry player entered twice is refused below either way.
+			if picked == nil || dup.Existing.PlayerID == nil || *dup.Existing.PlayerID != picked.ID {
+				jsonError(w, http.StatusConflict, err.Error())
+				return
+			}
+		case err != nil:
+			jsonError(w, http.StatusInternalServerError, "failed to check the name")
+			return
+		}
+	}
+
+	var reg *models.Registration
+	if picked != nil {
+		reg, err = db.CreatePlayerRegistration(r.Context(), a.DB, id, picked)
+	} else {
+		reg, err = db.CreateGuestRegistration(r.Context(), a.DB, id, body.PlayerName)
+	}
 	if err != nil {
 		jsonError(w, http.StatusBadRequest, err.Error())
 		return
 	}
+	if t.TeamSize > 0 {
+		if err := db.SetTeamMembers(r.Context(), a.DB, id, reg.ID, body.Members); err != nil {
+			jsonError(w, http.StatusInternalServerError, "failed to save roster")
+			return
+		}
+		reg.Members = body.Members
+	}
 
 	if t.Status == models.TournamentStatusInProgress {
 		err := engine.WithTournamentEngine(r.Context(), a.DB, id,
@@ -190,7 +285,7 @@ func (a *PlayersAPI) DropPlayer(w http.ResponseWriter, r *http.Request) {
 			return "", eng.RemovePlayerById(enginePlayerID)
 		})
 	if err != nil {
-		jsonError(w, http.StatusBadRequest, err.Error())
+		engineError(w, err)
 		return
 	}
 	if reg, err := db.GetRegistrationByEnginePlayerID(r.Context(), a.DB, id, enginePlayerID); err == nil {
@@ -327,3 +422,40 @@ func decodeJSON(r *http.Request, v interface{}) error {
 	}
 	return json.NewDecoder(r.Body).Decode(v)
 }
+
+// AcceptAllPending confirms every pending registration. Returns
+// {"accepted": n}. Min tier: Co-organizer.
+func (a *PlayersAPI) AcceptAllPending(w http.ResponseWriter, r *http.Request) {
+	a.bulkPending(w, r, "accepted", db.ConfirmPendingRegistrations)
+}
+
+// RejectAllPending removes every pending registration. Returns
+// {"rejected": n}. Min tier: Co-organizer.
+func (a *PlayersAPI) RejectAllPending(w http.ResponseWriter, r *http.Request) {
+	a.bulkPending(w, r, "rejected", db.DeletePendingRegistrations)
+}
+
+func (a *PlayersAPI) bulkPending(w http.ResponseWriter, r *http.Request, key string,
+	apply func(ctx context.Context, database *sql.DB, tournamentID int64) (int64, error)) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	t, err := db.GetTournament(r.Context(), a.DB, id)
+	if err != nil {
+		jsonError(w, http.StatusNotFound, "not found")
+		return
+	}
+	if !middleware.AuthorizeTournament(w, r, a.DB, t.ID, models.TierCoOrganizer) {
+		return
+	}
+	switch t.Status {
+	case models.TournamentStatusScheduled, models.TournamentStatusRegistrationOpen:
+	default:
+		jsonError(w, http.StatusBadRequest, "pending registrations can only be changed before the tournament starts")
+		return
+	}
+	n, err := apply(r.Context(), a.DB, id)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, "failed to update registrations")
+		return
+	}
+	jsonResponse(w, http.StatusOK, map[string]int64{key: n})
+}
@@ -12,6 +12,7 @@ import (
 
 	"github.com/dstathis/openswiss/internal/db"
 	"github.com/dstathis/openswiss/internal/models"
+	"github.com/dstathis/openswiss/internal/pow"
 )
 
 func TestPlayersAPI_List_Empty(t *testing.T) {
@@ -89,6 +90,68 @@ func TestPlayersAPI_Register(t *testing.T) {
 	}
 }
 
+func TestPlayersAPI_Register_BotCheck(t *testing.T) {
+	database := testDB(t)
+	api := &PlayersAPI{DB: database}
+	owner := mustCreateUser(t, database, "owner@example.com", "Owner")
+	tourn := mustCreateTournament(t, database, owner.ID, models.TournamentStatusRegistrationOpen)
+	tourn.BotCheck = true
+	if err := db.UpdateTournament(context.Background(), database, tourn); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	user := mustCreateUser(t, database, "p1@example.com", "P1")
+	params := map[string]string{"id": strconv.FormatInt(tourn.ID, 10)}
+
+	rec := httptest.NewRecorder()
+	api.Register(rec, requestWithUser("POST", "/", "", user, params))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("without pow_nonce: status = %d, want 400", rec.Code)
+	}
+
+	nonce := pow.Solve(pow.Challenge(tourn.ID, user.ID), pow.Bits)
+	rec = httptest.NewRecorder()
+	api.Register(rec, requestWithUser("POST", "/", `{"pow_nonce":"`+nonce+`"}`, user, params))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("solved: status = %d, body=%s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestPlayersAPI_ContactConsent(t *testing.T) {
+	database := testDB(t)
+	api := &PlayersAPI{DB: database}
+	owner := mustCreateUser(t, database, "owner@example.com", "Owner")
+	tourn := mustCreateTournament(t, database, owner.ID, models.TournamentStatusRegistrationOpen)
+	user := mustCreateUser(t, database, "p1@example.com", "P1")
+	params := map[string]string{"id": strconv.FormatInt(tourn.ID, 10)}
+
+	rec := httptest.NewRecorder()
+	api.Register(rec, requestWithUser("POST", "/", `{"contact_consent":true}`, user, params))
+	var reg models.Registration
+	json.NewDecoder(rec.Body).Decode(&reg)
+	if rec.Code != http.StatusCreated || !reg.ContactConsent {
+		t.Fatalf("register: status %d, registration %+v", rec.Code, reg)
+	}
+
+	rec = httptest.NewRecorder()
+	api.SetContactConsent(rec, requestWithUser("PUT", "/", `{}`, user, params))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("no value: status %d, want 400", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	api.SetContactConsent(rec, requestWithUser("PUT", "/", `{"contact_consent":false}`, user, params))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("withdraw: status %d body %s", rec.Code, rec.Body.String())
+	}
+	if got, _ := db.GetRegistration(context.Background(), database, tourn.ID, user.ID); got.ContactConsent {
+		t.Error("consent still set after withdrawing")
+	}
+	rec = httptest.NewRecorder()
+	api.SetContactConsent(rec, requestWithUser("PUT", "/", `{"contact_consent":true}`, owner, params))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("unregistered: status %d, want 404", rec.Code)
+	}
+}
+
 func TestPlayersAPI_Register_RegistrationClosed(t *testing.T) {
 	database := testDB(t)
 	api := &PlayersAPI{DB: database}
@@ -119,7 +182,7 @@ func TestPlayersAPI_Register_NotFound(t *testing.T) {
 	}
 }
 
-func TestPlayersAPI_Register_TournamentFull(t *testing.T) {
+func TestPlayersAPI_Register_Waitlisted(t *testing.T) {
 	database := testDB(t)
 	api := &PlayersAPI{DB: database}
 	ctx := context.Background()
@@ -140,8 +203,13 @@ func TestPlayersAPI_Register_TournamentFull(t *testing.T) {
 	rec := httptest.NewRecorder()
 	api.Register(rec, r)
 
-	if rec.Code != http.StatusBadRequest {
-		t.Errorf("expected 400 for full tournament, got %d", rec.Code)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201 for full tournament, got %d", rec.Code)
+	}
+	var reg models.Registration
+	json.NewDecoder(rec.Body).Decode(&reg)
+	if reg.Status != models.RegistrationStatusWaitlisted {
+		t.Errorf("status = %q, want waitlisted", reg.Status)
 	}
 }
 
@@ -274,6 +342,23 @@ func TestPlayersAPI_AddPlayer_WrongState(t *testing.T) {
 	}
 }
 
+func TestPlayersAPI_AddPlayer_RoundRobinStarted(t *testing.T) {
+	database := testDB(t)
+	api := &PlayersAPI{DB: database}
+	owner, tourn := startedTournament(t, database)
+	tourn.PairingAlgorithm = models.PairingRoundRobin
+	if err := db.UpdateTournament(context.Background(), database, tourn); err != nil {
+		t.Fatalf("switch to round robin: %v", err)
+	}
+
+	r := requestWithUser("POST", "/", `{"player_name":"Late"}`, owner, map[string]string{"id": strconv.FormatInt(tourn.ID, 10)})
+	rec := httptest.NewRecorder()
+	api.AddPlayer(rec, r)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", rec.Code)
+	}
+}
+
 func TestPlayersAPI_AddPlayer_BlankName(t *testing.T) {
 	database := testDB(t)
 	api := &PlayersAPI{DB: database}
@@ -288,6 +373,30 @@ func TestPlayersAPI_AddPlayer_BlankName(t *testing.T) {
 	}
 }
 
+func TestPlayersAPI_AddPlayer_DuplicateName(t *testing.T) {
+	database := testDB(t)
+	api := &PlayersAPI{DB: database}
+	owner := mustCreateUser(t, database, "owner@example.com", "Owner")
+	tourn := mustCreateTournament(t, database, owner.ID, models.TournamentStatusRegistrationOpen)
+	if _, err := db.CreateGuestRegistration(context.Background(), database, tourn.ID, "Bob"); err != nil {
+		t.Fatal(err)
+	}
+
+	r := requestWithUser("POST", "/", `{"player_name":"BOB"}`, owner, map[string]string{"id": strconv.FormatInt(tourn.ID, 10)})
+	rec := httptest.NewRecorder()
+	api.AddPlayer(rec, r)
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("expected 409, got %d", rec.Code)
+	}
+
+	r = requestWithUser("POST", "/", `{"player_name":"BOB","allow_duplicate":true}`, owner, map[string]string{"id": strconv.FormatInt(tourn.ID, 10)})
+	rec = httptest.NewRecorder()
+	api.AddPlayer(rec, r)
+	if rec.Code != http.StatusCreated {
+		t.Errorf("with allow_duplicate: status = %d, body=%s", rec.Code, rec.Body.String())
+	}
+}
+
 func TestPlayersAPI_AddPlayer_MidTournament(t *testing.T) {
 	database := testDB(t)
 	owner, tourn := startedTournament(t, database)
@@ -638,3 +747,45 @@ func TestPlayersAPI_GetPlayerDecklist_Forbidden(t *testing.T) {
 		t.Errorf("expected 403, got %d", rec.Code)
 	}
 }
+
+func TestPlayersAPI_AcceptRejectAllPending(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	api := &PlayersAPI{DB: database}
+	owner := mustCreateUser(t, database, "owner@example.com", "Owner")
+	tourn := mustCreateTournament(t, database, owner.ID, models.TournamentStatusRegistrationOpen)
+	params := map[string]string{"id": strconv.FormatInt(tourn.ID, 10)}
+	for _, name := range []string{"P1", "P2"} {
+		u := mustCreateUser(t, database, name+"@example.com", name)
+		db.CreatePendingRegistration(ctx, database, tourn.ID, u.ID, u.DisplayName)
+	}
+
+	rec := httptest.NewRecorder()
+	api.AcceptAllPending(rec, requestWithUser("POST", "/", "", owner, params))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("accept: status %d body %s", rec.Code, rec.Body.String())
+	}
+	var got map[string]int64
+	json.NewDecoder(rec.Body).Decode(&got)
+	if got["accepted"] != 2 {
+		t.Errorf("accept response = %v", got)
+	}
+
+	rec = httptest.NewRecorder()
+	api.RejectAllPending(rec, requestWithUser("POST", "/", "", owner, params))
+	got = nil
+	json.NewDecoder(rec.Body).Decode(&got)
+	if rec.Code != http.StatusOK || got["rejected"] != 0 {
+		t.Errorf("reject with none pending: status %d body %v", rec.Code, got)
+	}
+	if n, _ := db.CountRegistrations(ctx, database, tourn.ID); n != 2 {
+		t.Errorf("registrations = %d, want 2", n)
+	}
+
+	other := mustCreateUser(t, database, "other@example.com", "Other")
+	rec = httptest.NewRecorder()
+	api.RejectAllPending(rec, requestWithUser("POST", "/", "", other, params))
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("non-staff: status %d, want 403", rec.Code)
+	}
+}
@@ -29,14 +29,18 @@ func (a *PlayoffAPI) Start(w http.ResponseWriter, r *http.Request) {
 			if t.TopCut <= 0 {
 				return "", fmt.Errorf("tournament has no top cut configured")
 			}
-			if err := eng.StartPlayoff(t.TopCut); err != nil {
+			regs, err := db.ListRegistrations(r.Context(), tx, id)
+			if err != nil {
+				return "", err
+			}
+			if err := engine.StartPlayoff(eng, t, engine.TiebreakSeeds(regs)); err != nil {
 				return "", err
 			}
 			return models.TournamentStatusPlayoff, nil
 		})
 
 	if err != nil {
-		jsonError(w, http.StatusBadRequest, err.Error())
+		engineError(w, err)
 		return
 	}
 	jsonResponse(w, http.StatusOK, map[string]string{"status": "ok"})
@@ -112,6 +116,9 @@ func (a *PlayoffAPI) SubmitResults(w http.ResponseWriter, r *http.Request) {
 	err := engine.WithTournamentEngine(r.Context(), a.DB, id,
 		func(tx *sql.Tx, t *models.Tournament, eng *swisstools.Tournament) (string, error) {
 			for _, res := range batch.Results {
+				if err := engine.CheckScore(t, res.Wins, res.Losses, res.Draws); err != nil {
+					return "", fmt.Errorf("player %d: %w", res.PlayerID, err)
+				}
 				if err := eng.AddPlayoffResult(res.PlayerID, res.Wins, res.Losses, res.Draws); err != nil {
 					return "", fmt.Errorf("player %d: %w", res.PlayerID, err)
 				}
@@ -120,7 +127,7 @@ func (a *PlayoffAPI) SubmitResults(w http.ResponseWriter, r *http.Request) {
 		})
 
 	if err != nil {
-		jsonError(w, http.StatusBadRequest, err.Error())
+		engineError(w, err)
 		return
 	}
 	jsonResponse(w, http.StatusOK, map[string]string{"status": "ok"})
@@ -144,7 +151,7 @@ func (a *PlayoffAPI) NextRound(w http.ResponseWriter, r *http.Request) {
 		})
 
 	if err != nil {
-		jsonError(w, http.StatusBadRequest, err.Error())
+		engineError(w, err)
 		return
 	}
 	jsonResponse(w, http.StatusOK, map[string]string{"status": "ok"})
@@ -0,0 +1,123 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/engine"
+	"github.com/dstathis/openswiss/internal/middleware"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/go-chi/chi/v5"
+)
+
+// ListPods returns the pods of a multi-stage event, oldest first. Public.
+func (a *TournamentAPI) ListPods(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if _, err := db.GetTournament(r.Context(), a.DB, id); err != nil {
+		jsonError(w, http.StatusNotFound, "not found")
+		return
+	}
+	pods, err := db.ListPods(r.Context(), a.DB, id)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, "failed to list pods")
+		return
+	}
+	if pods == nil {
+		pods = []models.Tournament{}
+	}
+	jsonResponse(w, http.StatusOK, pods)
+}
+
+// CreatePod adds a pod to a tournament. Body: {"name": "..."}. Returns the
+// pod. Min tier: Co-organizer.
+func (a *TournamentAPI) CreatePod(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if !middleware.AuthorizeTournament(w, r, a.DB, id, models.TierCoOrganizer) {
+		return
+	}
+	var body struct {
+		Name string `json:"name"`
+	}
+	if err := decodeJSON(r, &body); err != nil {
+		jsonError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	pod, err := engine.CreatePod(r.Context(), a.DB, id, body.Name)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	jsonResponse(w, http.StatusCreated, pod)
+}
+
+// SplitPools deals the tournament's confirmed players into round robin
+// pools. Body: {"count": n}. Returns the pools. Min tier: Co-organizer.
+func (a *TournamentAPI) SplitPools(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if !middleware.AuthorizeTournament(w, r, a.DB, id, models.TierCoOrganizer) {
+		return
+	}
+	var body struct {
+		Count int `json:"count"`
+	}
+	if err := decodeJSON(r, &body); err != nil {
+		jsonError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	pools, err := engine.SplitPools(r.Context(), a.DB, id, body.Count)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	jsonResponse(w, http.StatusCreated, pools)
+}
+
+// AdvancePods registers the top finishers of every pod into the finals.
+// Returns {"advanced": n}, the number of players added. Min tier:
+// Co-organizer.
+func (a *TournamentAPI) AdvancePods(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if !middleware.AuthorizeTournament(w, r, a.DB, id, models.TierCoOrganizer) {
+		return
+	}
+	n, err := engine.AdvancePods(r.Context(), a.DB, id)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	jsonResponse(w, http.StatusOK, map[string]int{"advanced": n})
+}
+
+// GetCombinedStandings returns the standings of a multi-stage event across
+// its pods and finals, filtered by ?q= and paged like the other standings.
+// Below Judge, a stage in a blind final round is left out (see
+// engine.HideStages). Public.
+func (a *TournamentAPI) GetCombinedStandings(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	t, err := db.GetTournament(r.Context(), a.DB, id)
+	if err != nil {
+		jsonError(w, http.StatusNotFound, "not found")
+		return
+	}
+	pods, err := db.ListPods(r.Context(), a.DB, id)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, "failed to list pods")
+		return
+	}
+	if len(pods) == 0 {
+		jsonError(w, http.StatusBadRequest, "tournament has no pods")
+		return
+	}
+	finals, stages, err := engine.LoadStages(r.Context(), a.DB, t, pods)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, "failed to load stages")
+		return
+	}
+	if engine.StagesHidden(finals, stages) && !seesHiddenStandings(r, a.DB, t.ID) {
+		engine.HideStages(finals, stages)
+	}
+	standings := filterRows(engine.CombinedStandings(finals, stages), nameFilter(r),
+		func(s engine.StagePlacing) []string { return []string{s.Name} })
+	jsonResponse(w, http.StatusOK, pageRows(w, r, standings))
+}
@@ -0,0 +1,173 @@
+//go:build integration
+
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/engine"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/dstathis/swisstools"
+)
+
+// finishPod registers two guests in pod podID and plays its only round,
+// the first-listed player winning.
+func finishPod(t *testing.T, database *sql.DB, podID int64) {
+	t.Helper()
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		if _, err := db.CreateGuestRegistration(ctx, database, podID, fmt.Sprintf("Pod%d P%d", podID, i)); err != nil {
+			t.Fatalf("register %d: %v", i, err)
+		}
+	}
+	regs, _ := db.ListRegistrations(ctx, database, podID)
+	if err := engine.WithTournamentEngine(ctx, database, podID,
+		func(tx *sql.Tx, tm *models.Tournament, eng *swisstools.Tournament) (string, error) {
+			state, err := engine.InitTournamentEngine(ctx, tx, tm, regs)
+			if err != nil {
+				return "", err
+			}
+			ne, err := swisstools.LoadTournament(state)
+			if err != nil {
+				return "", err
+			}
+			*eng = ne
+			if err := eng.AddResult(eng.GetRound()[0].PlayerA(), 2, 0, 0); err != nil {
+				return "", err
+			}
+			return models.TournamentStatusFinished, eng.FinishTournament()
+		}); err != nil {
+		t.Fatalf("finish pod: %v", err)
+	}
+}
+
+func TestTournamentAPI_Pods(t *testing.T) {
+	database := testDB(t)
+	api := &TournamentAPI{DB: database}
+	owner := mustCreateUser(t, database, "owner-pods@example.com", "Owner Pods", "organizer")
+	finals := mustCreateTournament(t, database, owner.ID, models.TournamentStatusScheduled)
+	params := map[string]string{"id": strconv.FormatInt(finals.ID, 10)}
+
+	rec := httptest.NewRecorder()
+	api.CreatePod(rec, requestWithUser("POST", "/", `{"name":"Pod A"}`, owner, params))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create pod: status %d %s", rec.Code, rec.Body.String())
+	}
+	var pod models.Tournament
+	json.NewDecoder(rec.Body).Decode(&pod)
+	if pod.ParentID == nil || *pod.ParentID != finals.ID {
+		t.Errorf("pod = %+v", pod)
+	}
+
+	rec = httptest.NewRecorder()
+	api.AdvancePods(rec, requestWithUser("POST", "/", "", owner, params))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("advance with pod_advance 0: status %d", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	api.Update(rec, requestWithUser("PATCH", "/", `{"pod_advance":1}`, owner, params))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("set pod_advance: status %d %s", rec.Code, rec.Body.String())
+	}
+
+	finishPod(t, database, pod.ID)
+	rec = httptest.NewRecorder()
+	api.AdvancePods(rec, requestWithUser("POST", "/", "", owner, params))
+	var advanced map[string]int
+	json.NewDecoder(rec.Body).Decode(&advanced)
+	if rec.Code != http.StatusOK || advanced["advanced"] != 1 {
+		t.Fatalf("advance: status %d, %v", rec.Code, advanced)
+	}
+
+	rec = httptest.NewRecorder()
+	api.ListPods(rec, requestWithUser("GET", "/", "", nil, params))
+	var pods []models.Tournament
+	json.NewDecoder(rec.Body).Decode(&pods)
+	if len(pods) != 1 || pods[0].Status != models.TournamentStatusFinished {
+		t.Errorf("pods = %+v", pods)
+	}
+
+	rec = httptest.NewRecorder()
+	api.GetCombinedStandings(rec, requestWithUser("GET", "/", "", nil, params))
+	var standings []engine.StagePlacing
+	if err := json.Unmarshal(rec.Body.Bytes(), &standings); err != nil {
+		t.Fatalf("decode standings: %v (%s)", err, rec.Body.String())
+	}
+	if len(standings) != 2 || !standings[0].Advanced || standings[0].Stage != "Pod A" || standings[1].Advanced {
+		t.Errorf("combined standings = %+v", standings)
+	}
+}
+
+func TestTournamentAPI_CombinedStandings_BlindFinalRound(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	api := &TournamentAPI{DB: database}
+	owner := mustCreateUser(t, database, "owner-blindpods@example.com", "Owner Blind Pods", "organizer")
+	finals := mustCreateTournament(t, database, owner.ID, models.TournamentStatusScheduled)
+	finals.BlindFinalRound = true
+	if err := db.UpdateTournament(ctx, database, finals); err != nil {
+		t.Fatal(err)
+	}
+	params := map[string]string{"id": strconv.FormatInt(finals.ID, 10)}
+	pods := make([]int64, 2)
+	for i, name := range []string{"Pod A", "Pod B"} {
+		pod, err := engine.CreatePod(ctx, database, finals.ID, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pods[i] = pod.ID
+	}
+	finishPod(t, database, pods[0])
+
+	// Pod B pairs its only round, which is blind.
+	for i := 0; i < 2; i++ {
+		if _, err := db.CreateGuestRegistration(ctx, database, pods[1], fmt.Sprintf("Blind P%d", i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	regs, _ := db.ListRegistrations(ctx, database, pods[1])
+	if err := engine.WithTournamentEngine(ctx, database, pods[1],
+		func(tx *sql.Tx, tm *models.Tournament, eng *swisstools.Tournament) (string, error) {
+			state, err := engine.InitTournamentEngine(ctx, tx, tm, regs)
+			if err != nil {
+				return "", err
+			}
+			ne, err := swisstools.LoadTournament(state)
+			if err != nil {
+				return "", err
+			}
+			*eng = ne
+			eng.SetMaxRounds(1)
+			return models.TournamentStatusInProgress, nil
+		}); err != nil {
+		t.Fatalf("start pod: %v", err)
+	}
+
+	for _, c := range []struct {
+		user *models.User
+		rows int
+	}{{nil, 2}, {owner, 4}} {
+		rec := httptest.NewRecorder()
+		api.GetCombinedStandings(rec, requestWithUser("GET", "/", "", c.user, params))
+		var standings []engine.StagePlacing
+		if err := json.Unmarshal(rec.Body.Bytes(), &standings); err != nil {
+			t.Fatalf("decode standings: %v (%s)", err, rec.Body.String())
+		}
+		if len(standings) != c.rows {
+			t.Errorf("staff %v: combined standings = %+v", c.user != nil, standings)
+		}
+		for _, s := range standings {
+			if c.user == nil && s.Stage != "Pod A" {
+				t.Errorf("spectator sees %+v", s)
+			}
+		}
+	}
+}
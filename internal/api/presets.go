@@ -0,0 +1,114 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/engine"
+	"github.com/dstathis/openswiss/internal/middleware"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/go-chi/chi/v5"
+)
+
+// PresetsAPI serves the settings presets new tournaments start from (see
+// models.Preset). Every endpoint needs the organizer role.
+type PresetsAPI struct {
+	DB *sql.DB
+}
+
+// List returns every preset in name order.
+func (a *PresetsAPI) List(w http.ResponseWriter, r *http.Request) {
+	presets, err := db.ListPresets(r.Context(), a.DB)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, "failed to list presets")
+		return
+	}
+	if presets == nil {
+		presets = []models.Preset{}
+	}
+	jsonResponse(w, http.StatusOK, presets)
+}
+
+// Get returns a preset, which is also its export: POST it to Import on
+// another server.
+func (a *PresetsAPI) Get(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "pid"), 10, 64)
+	p, err := db.GetPreset(r.Context(), a.DB, id)
+	if err != nil {
+		jsonError(w, http.StatusNotFound, "not found")
+		return
+	}
+	jsonResponse(w, http.StatusOK, p)
+}
+
+// Import saves a preset from its JSON, {"name": "...", "settings": {...}}
+// as Get returns it, replacing the preset of the same name.
+func (a *PresetsAPI) Import(w http.ResponseWriter, r *http.Request) {
+	if r.Body == nil {
+		jsonError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	p, err := engine.ReadPreset(r.Body)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	a.save(w, r, p)
+}
+
+// Save saves the tournament's settings and pairing fields as a preset.
+// JSON body: {"name": "..."}. Min tier: Co-organizer.
+func (a *PresetsAPI) Save(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	t, err := db.GetTournament(r.Context(), a.DB, id)
+	if err != nil {
+		jsonError(w, http.StatusNotFound, "not found")
+		return
+	}
+	if !middleware.AuthorizeTournament(w, r, a.DB, t.ID, models.TierCoOrganizer) {
+		return
+	}
+	var body struct {
+		Name string `json:"name"`
+	}
+	if err := decodeJSON(r, &body); err != nil {
+		jsonError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	fields, err := db.ListPairingFields(r.Context(), a.DB, t.ID)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, "failed to load pairing fields")
+		return
+	}
+	a.save(w, r, &models.Preset{Name: body.Name, Settings: models.PresetOf(t, fields)})
+}
+
+func (a *PresetsAPI) save(w http.ResponseWriter, r *http.Request, p *models.Preset) {
+	if err := engine.SavePreset(r.Context(), a.DB, p, middleware.GetUser(r.Context()).ID); err != nil {
+		if errors.Is(err, engine.ErrInvalidPreset) {
+			jsonError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		jsonError(w, http.StatusInternalServerError, "failed to save preset")
+		return
+	}
+	jsonResponse(w, http.StatusCreated, p)
+}
+
+// Delete removes a preset. Tournaments created from it keep their
+// settings.
+func (a *PresetsAPI) Delete(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "pid"), 10, 64)
+	if err := db.DeletePreset(r.Context(), a.DB, id); err != nil {
+		if errors.Is(err, db.ErrPresetNotFound) {
+			jsonError(w, http.StatusNotFound, "not found")
+			return
+		}
+		jsonError(w, http.StatusInternalServerError, "failed to delete preset")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,102 @@
+//go:build integration
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+func TestPresetsAPI(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	api := &PresetsAPI{DB: database}
+	org := mustCreateUser(t, database, "org@example.com", "Org", models.RoleOrganizer)
+	src := mustCreateTournament(t, database, org.ID, models.TournamentStatusScheduled)
+	src.PointsWin = 2
+	src.PairingAlgorithm = models.PairingDanish
+	if err := db.UpdateTournament(ctx, database, src); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.CreatePairingField(ctx, database, &models.PairingField{TournamentID: src.ID, Label: "Judge"}); err != nil {
+		t.Fatal(err)
+	}
+
+	rec := httptest.NewRecorder()
+	api.Save(rec, requestWithUser("POST", "/", `{"name":"Danish"}`, org, map[string]string{"id": strconv.FormatInt(src.ID, 10)}))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("save: status %d body %s", rec.Code, rec.Body.String())
+	}
+	var preset models.Preset
+	json.NewDecoder(rec.Body).Decode(&preset)
+	if preset.Settings.PointsWin != 2 || preset.Settings.PairingAlgorithm != models.PairingDanish || len(preset.Settings.PairingFields) != 1 {
+		t.Errorf("saved = %+v", preset)
+	}
+	params := map[string]string{"pid": strconv.FormatInt(preset.ID, 10)}
+
+	// Get is the export; importing it under another name copies it.
+	rec = httptest.NewRecorder()
+	api.Get(rec, requestWithUser("GET", "/", "", org, params))
+	var exported map[string]any
+	json.NewDecoder(rec.Body).Decode(&exported)
+	exported["name"] = "Danish Copy"
+	body, _ := json.Marshal(exported)
+	rec = httptest.NewRecorder()
+	api.Import(rec, requestWithUser("POST", "/", string(body), org, nil))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("import: status %d body %s", rec.Code, rec.Body.String())
+	}
+	rec = httptest.NewRecorder()
+	api.Import(rec, requestWithUser("POST", "/", `{"name":"Bad","settings":{"num_rounds":0}}`, org, nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("invalid import: status %d, want 400", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	api.List(rec, requestWithUser("GET", "/", "", org, nil))
+	var list []models.Preset
+	json.NewDecoder(rec.Body).Decode(&list)
+	if len(list) != 2 || list[0].Name != "Danish" || list[1].Name != "Danish Copy" {
+		t.Errorf("list = %+v", list)
+	}
+
+	// A tournament created from the preset takes its settings, with the
+	// body's on top, and its pairing fields.
+	rec = httptest.NewRecorder()
+	(&TournamentAPI{DB: database}).Create(rec, requestWithUser("POST", "/",
+		fmt.Sprintf(`{"name":"From Preset","preset_id":%d,"points_draw":0}`, preset.ID), org, nil))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create: status %d body %s", rec.Code, rec.Body.String())
+	}
+	var created models.Tournament
+	json.NewDecoder(rec.Body).Decode(&created)
+	if created.PointsWin != 2 || created.PointsDraw != 0 || created.PairingAlgorithm != models.PairingDanish {
+		t.Errorf("created = %+v", created)
+	}
+	if fields, _ := db.ListPairingFields(ctx, database, created.ID); len(fields) != 1 || fields[0].Label != "Judge" {
+		t.Errorf("created fields = %+v", fields)
+	}
+	rec = httptest.NewRecorder()
+	(&TournamentAPI{DB: database}).Create(rec, requestWithUser("POST", "/", `{"name":"X","preset_id":999999}`, org, nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("unknown preset: status %d, want 400", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	api.Delete(rec, requestWithUser("DELETE", "/", "", org, params))
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("delete: status %d", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	api.Get(rec, requestWithUser("GET", "/", "", org, params))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("get after delete: status %d, want 404", rec.Code)
+	}
+}
@@ -0,0 +1,84 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/engine"
+	"github.com/dstathis/openswiss/internal/middleware"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/dstathis/swisstools"
+	"github.com/go-chi/chi/v5"
+)
+
+// SetRatings sets players' ratings for seeding round 1 from a list of
+// {"registration_id", "rating"}; a null rating clears one and players left
+// out keep theirs. Returns the registrations. Only before the start.
+func (a *PlayersAPI) SetRatings(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if !middleware.AuthorizeTournament(w, r, a.DB, id, models.TierCoOrganizer) {
+		return
+	}
+	var body []struct {
+		RegistrationID int64 `json:"registration_id"`
+		Rating         *int  `json:"rating"`
+	}
+	if err := decodeJSON(r, &body); err != nil {
+		jsonError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	ratings := make(map[int64]*int, len(body))
+	for _, b := range body {
+		ratings[b.RegistrationID] = b.Rating
+	}
+	if err := engine.SetRatings(r.Context(), a.DB, id, ratings); err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	regs, err := db.ListRegistrations(r.Context(), a.DB, id)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, "failed to list registrations")
+		return
+	}
+	jsonResponse(w, http.StatusOK, regs)
+}
+
+// RatingChanges returns the Elo changes of a finished tournament's rated
+// players (see engine.RatingChanges), with the K-factor from ?k=, by
+// default engine.DefaultRatingK.
+func (a *PlayersAPI) RatingChanges(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	k, err := engine.ParseRatingK(r.URL.Query().Get("k"))
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	t, err := db.GetTournament(r.Context(), a.DB, id)
+	if err != nil {
+		jsonError(w, http.StatusNotFound, "not found")
+		return
+	}
+	if len(t.EngineState) == 0 {
+		jsonError(w, http.StatusConflict, "tournament is not finished")
+		return
+	}
+	eng, err := swisstools.LoadTournament(t.EngineState)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, "failed to load engine state")
+		return
+	}
+	if !engine.Complete(t, &eng) {
+		jsonError(w, http.StatusConflict, "tournament is not finished")
+		return
+	}
+	regs, err := db.ListRegistrations(r.Context(), a.DB, id)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, "failed to list registrations")
+		return
+	}
+	jsonResponse(w, http.StatusOK, map[string]interface{}{
+		"k":       k,
+		"changes": engine.RatingChanges(regs, &eng, k),
+	})
+}
@@ -0,0 +1,110 @@
+//go:build integration
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+func TestPlayersAPI_SetRatings(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	api := &PlayersAPI{DB: database}
+	owner := mustCreateUser(t, database, "owner-ratings@example.com", "OwnerRatings")
+	tourn := mustCreateTournament(t, database, owner.ID, models.TournamentStatusRegistrationOpen)
+	alice, _ := db.CreateGuestRegistration(ctx, database, tourn.ID, "Alice")
+	params := map[string]string{"id": strconv.FormatInt(tourn.ID, 10)}
+
+	rec := httptest.NewRecorder()
+	api.SetRatings(rec, requestWithUser("PUT", "/", fmt.Sprintf(`[{"registration_id":%d,"rating":1900}]`, alice.ID), owner, params))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d body %s", rec.Code, rec.Body.String())
+	}
+	var regs []models.Registration
+	json.NewDecoder(rec.Body).Decode(&regs)
+	if len(regs) != 1 || regs[0].Rating == nil || *regs[0].Rating != 1900 {
+		t.Errorf("registrations = %+v", regs)
+	}
+
+	for body, want := range map[string]int{
+		fmt.Sprintf(`[{"registration_id":%d,"rating":null}]`, alice.ID): http.StatusOK,
+		fmt.Sprintf(`[{"registration_id":%d,"rating":-5}]`, alice.ID):   http.StatusBadRequest,
+		`[{"registration_id":999999,"rating":1500}]`:                    http.StatusBadRequest,
+		`{"rating":1500}`: http.StatusBadRequest,
+	} {
+		rec = httptest.NewRecorder()
+		api.SetRatings(rec, requestWithUser("PUT", "/", body, owner, params))
+		if rec.Code != want {
+			t.Errorf("%s: status %d, want %d", body, rec.Code, want)
+		}
+	}
+	if got, _ := db.GetRegistrationByID(ctx, database, alice.ID); got.Rating != nil {
+		t.Errorf("rating = %d, want cleared", *got.Rating)
+	}
+}
+
+func TestPlayersAPI_RatingChanges(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	owner, tourn := startedTournament(t, database)
+	api := &PlayersAPI{DB: database}
+	params := map[string]string{"id": strconv.FormatInt(tourn.ID, 10)}
+
+	rec := httptest.NewRecorder()
+	api.RatingChanges(rec, requestWithUser("GET", "/", "", nil, params))
+	if rec.Code != http.StatusConflict {
+		t.Errorf("running tournament: status %d, want 409", rec.Code)
+	}
+
+	regs, _ := db.ListRegistrations(ctx, database, tourn.ID)
+	ratings := make(map[int64]*int)
+	for i, r := range regs[:3] {
+		rating := 1500 + 100*i
+		ratings[r.ID] = &rating
+	}
+	if err := db.SetRatings(ctx, database, tourn.ID, ratings); err != nil {
+		t.Fatal(err)
+	}
+	rec = httptest.NewRecorder()
+	(&TournamentAPI{DB: database}).Finish(rec, requestWithUser("POST", "/", "", owner, params))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Finish status = %d, body=%s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	api.RatingChanges(rec, requestWithUser("GET", "/?k=32", "", nil, params))
+	var got struct {
+		K       int `json:"k"`
+		Changes []struct {
+			Rating int `json:"rating"`
+			Delta  int `json:"delta"`
+			New    int `json:"new_rating"`
+		} `json:"changes"`
+	}
+	if rec.Code != http.StatusOK || json.Unmarshal(rec.Body.Bytes(), &got) != nil {
+		t.Fatalf("status %d, body %s", rec.Code, rec.Body.String())
+	}
+	if got.K != 32 || len(got.Changes) != 3 {
+		t.Fatalf("k %d with %d changes, want 32 with the 3 rated players", got.K, len(got.Changes))
+	}
+	for _, c := range got.Changes {
+		if c.New != c.Rating+c.Delta {
+			t.Errorf("change %+v doesn't add up", c)
+		}
+	}
+
+	rec = httptest.NewRecorder()
+	api.RatingChanges(rec, requestWithUser("GET", "/?k=500", "", nil, params))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("k=500: status %d, want 400", rec.Code)
+	}
+}
@@ -0,0 +1,128 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/engine"
+	"github.com/dstathis/openswiss/internal/middleware"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/go-chi/chi/v5"
+)
+
+// RegistryAPI serves the player registry. Every endpoint needs the
+// organizer role.
+type RegistryAPI struct {
+	DB *sql.DB
+}
+
+type playerRequest struct {
+	Name    string  `json:"name"`
+	Contact *string `json:"contact"`
+	Rating  *int    `json:"rating"`
+	Club    *string `json:"club"`
+}
+
+// List returns the registry in name order; ?q= keeps the players whose
+// name or contact contains it.
+func (a *RegistryAPI) List(w http.ResponseWriter, r *http.Request) {
+	players, err := db.ListPlayers(r.Context(), a.DB, r.URL.Query().Get("q"))
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, "failed to list players")
+		return
+	}
+	if players == nil {
+		players = []models.Player{}
+	}
+	jsonResponse(w, http.StatusOK, players)
+}
+
+// Create adds a player to the registry.
+func (a *RegistryAPI) Create(w http.ResponseWriter, r *http.Request) {
+	var req playerRequest
+	if err := decodeJSON(r, &req); err != nil {
+		jsonError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	p := &models.Player{Name: req.Name, Contact: req.Contact, Rating: req.Rating, Club: req.Club, CreatedBy: &middleware.GetUser(r.Context()).ID}
+	if err := engine.CheckPlayer(p); err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := db.CreatePlayer(r.Context(), a.DB, p); err != nil {
+		jsonError(w, http.StatusInternalServerError, "failed to add player")
+		return
+	}
+	jsonResponse(w, http.StatusCreated, p)
+}
+
+// Get returns a registry player with their lifetime record, their record
+// by season and their tournaments (see engine.PlayerHistory).
+func (a *RegistryAPI) Get(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "pid"), 10, 64)
+	p, err := db.GetPlayer(r.Context(), a.DB, id)
+	if err != nil {
+		jsonError(w, http.StatusNotFound, "not found")
+		return
+	}
+	events, err := engine.PlayerHistory(r.Context(), a.DB, p.ID)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, "failed to load the player's tournaments")
+		return
+	}
+	jsonResponse(w, http.StatusOK, map[string]interface{}{
+		"player":   p,
+		"lifetime": engine.Lifetime(events),
+		"seasons":  engine.Seasons(events),
+		"events":   events,
+	})
+}
+
+// Update replaces a registry player's name, contact, rating and club; a
+// missing contact, rating or club clears it. Registrations already made from the entry
+// keep their own.
+func (a *RegistryAPI) Update(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "pid"), 10, 64)
+	var req playerRequest
+	if err := decodeJSON(r, &req); err != nil {
+		jsonError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	p, err := db.GetPlayer(r.Context(), a.DB, id)
+	if err != nil {
+		jsonError(w, http.StatusNotFound, "not found")
+		return
+	}
+	p.Name, p.Contact, p.Rating, p.Club = req.Name, req.Contact, req.Rating, req.Club
+	if err := engine.CheckPlayer(p); err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := db.UpdatePlayer(r.Context(), a.DB, p); err != nil {
+		if errors.Is(err, db.ErrPlayerNotFound) {
+			jsonError(w, http.StatusNotFound, "not found")
+			return
+		}
+		jsonError(w, http.StatusInternalServerError, "failed to save player")
+		return
+	}
+	jsonResponse(w, http.StatusOK, p)
+}
+
+// Delete removes a player from the registry. Their registrations stay in
+// their tournaments, no longer linked.
+func (a *RegistryAPI) Delete(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "pid"), 10, 64)
+	if err := db.DeletePlayer(r.Context(), a.DB, id); err != nil {
+		if errors.Is(err, db.ErrPlayerNotFound) {
+			jsonError(w, http.StatusNotFound, "not found")
+			return
+		}
+		jsonError(w, http.StatusInternalServerError, "failed to delete player")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,93 @@
+//go:build integration
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+func TestRegistryAPI(t *testing.T) {
+	database := testDB(t)
+	api := &RegistryAPI{DB: database}
+	org := mustCreateUser(t, database, "org@example.com", "Org", models.RoleOrganizer)
+
+	rec := httptest.NewRecorder()
+	api.Create(rec, requestWithUser("POST", "/", `{"name":" "}`, org, nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("blank name: status %d, want 400", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	api.Create(rec, requestWithUser("POST", "/", `{"name":"Ada","contact":"ada@example.com","rating":1600}`, org, nil))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create: status %d body %s", rec.Code, rec.Body.String())
+	}
+	var ada models.Player
+	json.NewDecoder(rec.Body).Decode(&ada)
+	params := map[string]string{"pid": strconv.FormatInt(ada.ID, 10)}
+
+	rec = httptest.NewRecorder()
+	api.List(rec, requestWithUser("GET", "/?q=example", "", org, nil))
+	var list []models.Player
+	json.NewDecoder(rec.Body).Decode(&list)
+	if len(list) != 1 || list[0].ID != ada.ID {
+		t.Errorf("list = %+v", list)
+	}
+
+	// Update replaces the entry: the contact left out is cleared.
+	rec = httptest.NewRecorder()
+	api.Update(rec, requestWithUser("PUT", "/", `{"name":"Ada L.","rating":1650}`, org, params))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("update: status %d body %s", rec.Code, rec.Body.String())
+	}
+	if got, _ := db.GetPlayer(context.Background(), database, ada.ID); got.Name != "Ada L." || got.Contact != nil || *got.Rating != 1650 {
+		t.Errorf("after update = %+v", got)
+	}
+
+	// Adding from the registry links the registration into the record.
+	tourn := mustCreateTournament(t, database, org.ID, models.TournamentStatusRegistrationOpen)
+	rec = httptest.NewRecorder()
+	(&PlayersAPI{DB: database}).AddPlayer(rec, requestWithUser("POST", "/", fmt.Sprintf(`{"player_id":%d}`, ada.ID), org,
+		map[string]string{"id": strconv.FormatInt(tourn.ID, 10)}))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("add from registry: status %d body %s", rec.Code, rec.Body.String())
+	}
+	var reg models.Registration
+	json.NewDecoder(rec.Body).Decode(&reg)
+	if reg.DisplayName != "Ada L." || reg.PlayerID == nil || *reg.PlayerID != ada.ID || reg.Rating == nil || *reg.Rating != 1650 {
+		t.Errorf("registration = %+v", reg)
+	}
+
+	rec = httptest.NewRecorder()
+	api.Get(rec, requestWithUser("GET", "/", "", org, params))
+	var got struct {
+		Player models.Player `json:"player"`
+		Events []struct {
+			TournamentID int64 `json:"tournament_id"`
+			Played       bool  `json:"played"`
+		} `json:"events"`
+	}
+	json.NewDecoder(rec.Body).Decode(&got)
+	if got.Player.ID != ada.ID || len(got.Events) != 1 || got.Events[0].TournamentID != tourn.ID || got.Events[0].Played {
+		t.Errorf("get = %+v", got)
+	}
+
+	rec = httptest.NewRecorder()
+	api.Delete(rec, requestWithUser("DELETE", "/", "", org, params))
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("delete: status %d", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	api.Get(rec, requestWithUser("GET", "/", "", org, params))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("get after delete: status %d, want 404", rec.Code)
+	}
+}
@@ -0,0 +1,132 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/email"
+	"github.com/dstathis/openswiss/internal/engine"
+	"github.com/dstathis/openswiss/internal/middleware"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/dstathis/openswiss/internal/webhook"
+	"github.com/dstathis/swisstools"
+	"github.com/go-chi/chi/v5"
+)
+
+// RemindersAPI serves the current Swiss round's clock and the tables still
+// waiting for a result. Reading them is public; setting the clock and
+// sending reminders needs the Judge tier.
+type RemindersAPI struct {
+	DB      *sql.DB
+	Email   *email.Sender
+	BaseURL string
+}
+
+type outstandingResponse struct {
+	Round    int                `json:"round"`
+	Clock    *models.RoundClock `json:"clock"`
+	Pairings []webhook.Pairing  `json:"pairings"`
+}
+
+// Outstanding returns the current Swiss round, its clock (null if none is
+// set) and its matches without a result. Before the start, in an
+// unpublished round and once the Swiss is over there are none.
+func (a *RemindersAPI) Outstanding(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	t, err := db.GetTournament(r.Context(), a.DB, id)
+	if err != nil {
+		jsonError(w, http.StatusNotFound, "not found")
+		return
+	}
+	resp := outstandingResponse{Pairings: []webhook.Pairing{}}
+	if t.Status != models.TournamentStatusInProgress || t.EngineState == nil {
+		jsonResponse(w, http.StatusOK, resp)
+		return
+	}
+	eng, err := swisstools.LoadTournament(t.EngineState)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, "failed to load engine state")
+		return
+	}
+	resp.Round = eng.GetCurrentRound()
+	if eng.GetStatus() != "finished" && !hidesDraft(r, a.DB, t, resp.Round) {
+		resp.Pairings = engine.Outstanding(&eng)
+	}
+	if resp.Clock, err = db.GetRoundClock(r.Context(), a.DB, id, resp.Round); err != nil {
+		jsonError(w, http.StatusInternalServerError, "failed to load the round clock")
+		return
+	}
+	jsonResponse(w, http.StatusOK, resp)
+}
+
+// SetClock starts the current round's clock, running {"minutes": n} from
+// now (1 to models.MaxRoundMinutes), and returns it. Min tier: Judge.
+func (a *RemindersAPI) SetClock(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if !middleware.AuthorizeTournament(w, r, a.DB, id, models.TierJudge) {
+		return
+	}
+	var req struct {
+		Minutes int `json:"minutes"`
+	}
+	if err := decodeJSON(r, &req); err != nil {
+		jsonError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.Minutes < 1 || req.Minutes > models.MaxRoundMinutes {
+		jsonError(w, http.StatusBadRequest, fmt.Sprintf("minutes must be 1 to %d", models.MaxRoundMinutes))
+		return
+	}
+	t, err := db.GetTournament(r.Context(), a.DB, id)
+	if err != nil {
+		jsonError(w, http.StatusNotFound, "not found")
+		return
+	}
+	clock, err := engine.StartRoundClock(r.Context(), a.DB, t, req.Minutes)
+	if err != nil {
+		if errors.Is(err, engine.ErrNoRoundUnderway) {
+			jsonError(w, http.StatusConflict, err.Error())
+			return
+		}
+		jsonError(w, http.StatusInternalServerError, "failed to start the clock")
+		return
+	}
+	jsonResponse(w, http.StatusOK, clock)
+}
+
+// StopClock stops the round clock; no reminder goes out. Min tier: Judge.
+func (a *RemindersAPI) StopClock(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if !middleware.AuthorizeTournament(w, r, a.DB, id, models.TierJudge) {
+		return
+	}
+	if err := db.StopRoundClock(r.Context(), a.DB, id); err != nil {
+		jsonError(w, http.StatusInternalServerError, "failed to stop the clock")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Remind reminds the current round's tables without a result now and
+// returns what was sent (see engine.Remind). Min tier: Judge.
+func (a *RemindersAPI) Remind(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if !middleware.AuthorizeTournament(w, r, a.DB, id, models.TierJudge) {
+		return
+	}
+	url := fmt.Sprintf("%s/tournaments/%d", a.BaseURL, id)
+	sent, err := engine.Remind(r.Context(), a.DB, a.Email, url, id)
+	if err != nil {
+		if errors.Is(err, engine.ErrNothingOutstanding) {
+			jsonError(w, http.StatusConflict, err.Error())
+			return
+		}
+		jsonError(w, http.StatusInternalServerError, "failed to send the reminder")
+		return
+	}
+	jsonResponse(w, http.StatusOK, sent)
+}
@@ -0,0 +1,66 @@
+//go:build integration
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestRemindersAPI(t *testing.T) {
+	database := testDB(t)
+	owner, tourn := freshStarted(t, database)
+	a := &RemindersAPI{DB: database}
+	params := map[string]string{"id": strconv.FormatInt(tourn.ID, 10)}
+
+	rec := httptest.NewRecorder()
+	a.Outstanding(rec, requestWithUser("GET", "/", "", nil, params))
+	var got outstandingResponse
+	json.Unmarshal(rec.Body.Bytes(), &got)
+	if rec.Code != http.StatusOK || got.Round != 1 || len(got.Pairings) != 2 || got.Clock != nil {
+		t.Fatalf("outstanding: status %d, %+v", rec.Code, got)
+	}
+
+	stranger := mustCreateUser(t, database, "stranger-clock@example.com", "StrangerClock")
+	rec = httptest.NewRecorder()
+	a.SetClock(rec, requestWithUser("PUT", "/", `{"minutes":50}`, stranger, params))
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("stranger: status %d, want 403", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	a.SetClock(rec, requestWithUser("PUT", "/", `{"minutes":500}`, owner, params))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("500 minutes: status %d, want 400", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	a.SetClock(rec, requestWithUser("PUT", "/", `{"minutes":50}`, owner, params))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"ends_at"`) {
+		t.Fatalf("set clock: status %d, body %s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	a.Remind(rec, requestWithUser("POST", "/", "", owner, params))
+	var sent struct {
+		Round    int               `json:"round"`
+		Pairings []json.RawMessage `json:"pairings"`
+	}
+	json.Unmarshal(rec.Body.Bytes(), &sent)
+	if rec.Code != http.StatusOK || sent.Round != 1 || len(sent.Pairings) != 2 {
+		t.Errorf("remind: status %d, body %s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	a.StopClock(rec, requestWithUser("DELETE", "/", "", owner, params))
+	if rec.Code != http.StatusNoContent {
+		t.Errorf("stop clock: status %d", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	a.Outstanding(rec, requestWithUser("GET", "/", "", nil, params))
+	if !strings.Contains(rec.Body.String(), `"clock":null`) {
+		t.Errorf("outstanding after stop: %s", rec.Body.String())
+	}
+}
@@ -0,0 +1,113 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/engine"
+	"github.com/dstathis/openswiss/internal/middleware"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/dstathis/swisstools"
+	"github.com/go-chi/chi/v5"
+)
+
+// ReportResult is the caller reporting their current-round match result,
+// from their side: body {"wins", "losses", "draws"}. It answers
+// {"status"}: "confirmed" once the opponent's report agrees and the result
+// is in, "pending" while it waits and "conflict" when the reports differ
+// (see engine.ReportOwnResult).
+func (a *PlayersAPI) ReportResult(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	user := middleware.GetUser(r.Context())
+	reg, err := db.GetRegistration(r.Context(), a.DB, id, user.ID)
+	if err != nil || reg.EnginePlayerID == nil || reg.Status == models.RegistrationStatusDropped {
+		jsonError(w, http.StatusBadRequest, "you are not playing in this tournament")
+		return
+	}
+	var req struct {
+		Wins   int `json:"wins"`
+		Losses int `json:"losses"`
+		Draws  int `json:"draws"`
+	}
+	if err := decodeJSON(r, &req); err != nil {
+		jsonError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	var status string
+	err = engine.WithTournamentEngine(r.Context(), a.DB, id,
+		func(tx *sql.Tx, t *models.Tournament, eng *swisstools.Tournament) (string, error) {
+			status, err = engine.ReportOwnResult(r.Context(), tx, t, eng, *reg.EnginePlayerID, req.Wins, req.Losses, req.Draws, &user.ID)
+			return "", err
+		})
+	if err != nil {
+		engineError(w, err)
+		return
+	}
+	jsonResponse(w, http.StatusOK, map[string]string{"status": status})
+}
+
+// Reports returns the player reports of the current round still waiting to
+// count, by table, each with its "status": "pending" or "conflict". Min
+// tier: Judge.
+func (a *RoundsAPI) Reports(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if !middleware.AuthorizeTournament(w, r, a.DB, id, models.TierJudge) {
+		return
+	}
+	t, err := db.GetTournament(r.Context(), a.DB, id)
+	if err != nil {
+		jsonError(w, http.StatusNotFound, "not found")
+		return
+	}
+	type tableReports struct {
+		Table   int                   `json:"table"`
+		Status  string                `json:"status"`
+		Reports []models.ResultReport `json:"reports"`
+	}
+	out := []tableReports{}
+	if len(t.EngineState) > 0 {
+		eng, err := swisstools.LoadTournament(t.EngineState)
+		if err != nil {
+			jsonError(w, http.StatusInternalServerError, "failed to load tournament")
+			return
+		}
+		reports, err := db.ListResultReports(r.Context(), a.DB, id, eng.GetCurrentRound())
+		if err != nil {
+			jsonError(w, http.StatusInternalServerError, "failed to list reports")
+			return
+		}
+		for i, p := range eng.GetRound() {
+			table := engine.TableNumber(i, p)
+			if rs, ok := reports[table]; ok {
+				out = append(out, tableReports{Table: table, Status: engine.ReportState(rs), Reports: rs})
+			}
+		}
+	}
+	jsonResponse(w, http.StatusOK, out)
+}
+
+// AcceptReport resolves the player reports at a table of the current round
+// by entering side {side}'s ("a" or "b"). Min tier: Judge.
+func (a *RoundsAPI) AcceptReport(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	table, err := strconv.Atoi(chi.URLParam(r, "table"))
+	if err != nil || table < 1 {
+		jsonError(w, http.StatusNotFound, "not found")
+		return
+	}
+	side := chi.URLParam(r, "side")
+	if !middleware.AuthorizeTournament(w, r, a.DB, id, models.TierJudge) {
+		return
+	}
+	err = engine.WithTournamentEngine(r.Context(), a.DB, id,
+		func(tx *sql.Tx, t *models.Tournament, eng *swisstools.Tournament) (string, error) {
+			return "", engine.AcceptReport(r.Context(), tx, t, eng, table, side)
+		})
+	if err != nil {
+		engineError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
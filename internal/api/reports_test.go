@@ -0,0 +1,106 @@
+//go:build integration
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/engine"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/dstathis/swisstools"
+)
+
+func TestPlayersAPI_ReportResult(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	players := &PlayersAPI{DB: database}
+	rounds := &RoundsAPI{DB: database}
+	owner, tourn := freshStarted(t, database)
+	params := map[string]string{"id": strconv.FormatInt(tourn.ID, 10)}
+
+	report := func(u *models.User, body string) (int, string) {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		players.ReportResult(rec, requestWithUser("PUT", "/", body, u, params))
+		var resp struct{ Status string }
+		json.NewDecoder(rec.Body).Decode(&resp)
+		return rec.Code, resp.Status
+	}
+	// The two players at each table, by engine player ID.
+	users := map[int]*models.User{}
+	regs, _ := db.ListRegistrations(ctx, database, tourn.ID)
+	for _, reg := range regs {
+		users[*reg.EnginePlayerID], _ = db.GetUserByID(ctx, database, *reg.UserID)
+	}
+	eng, _ := swisstools.LoadTournament(tourn.EngineState)
+	t1, t2 := eng.GetRound()[0], eng.GetRound()[1]
+
+	if code, _ := report(users[t1.PlayerA()], `{"wins":2}`); code != http.StatusBadRequest {
+		t.Errorf("report with player reporting off: status %d", code)
+	}
+	tourn.SelfReport = true
+	tourn.BestOf = 3
+	if err := db.UpdateTournament(ctx, database, tourn); err != nil {
+		t.Fatal(err)
+	}
+
+	if code, status := report(users[t1.PlayerA()], `{"wins":2,"losses":1}`); code != http.StatusOK || status != engine.ReportPending {
+		t.Fatalf("first report: %d %q", code, status)
+	}
+	if code, status := report(users[t1.PlayerB()], `{"wins":1,"losses":2}`); code != http.StatusOK || status != engine.ReportConfirmed {
+		t.Fatalf("agreeing report: %d %q", code, status)
+	}
+	if code, status := report(users[t2.PlayerA()], `{"wins":2}`); code != http.StatusOK || status != engine.ReportPending {
+		t.Fatalf("table 2 first report: %d %q", code, status)
+	}
+	if code, status := report(users[t2.PlayerB()], `{"wins":2}`); code != http.StatusOK || status != engine.ReportConflict {
+		t.Fatalf("differing report: %d %q", code, status)
+	}
+	if code, _ := report(users[t1.PlayerA()], `{"wins":2}`); code != http.StatusBadRequest {
+		t.Errorf("report after the result: status %d", code)
+	}
+
+	tm, _ := db.GetTournament(ctx, database, tourn.ID)
+	eng, _ = swisstools.LoadTournament(tm.EngineState)
+	if p := eng.GetRound()[0]; p.PlayerAWins() != 2 || p.PlayerBWins() != 1 {
+		t.Errorf("table 1 = %d-%d, want 2-1", p.PlayerAWins(), p.PlayerBWins())
+	}
+	if p := eng.GetRound()[1]; p.PlayerAWins() != swisstools.UNINITIALIZED_RESULT {
+		t.Errorf("conflicting table 2 has a result: %d-%d", p.PlayerAWins(), p.PlayerBWins())
+	}
+
+	rec := httptest.NewRecorder()
+	rounds.Reports(rec, requestWithUser("GET", "/", "", users[t1.PlayerA()], params))
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("reports as a player: status %d", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	rounds.Reports(rec, requestWithUser("GET", "/", "", owner, params))
+	var listed []struct {
+		Table   int
+		Status  string
+		Reports []models.ResultReport
+	}
+	json.NewDecoder(rec.Body).Decode(&listed)
+	if rec.Code != http.StatusOK || len(listed) != 1 || listed[0].Table != 2 || listed[0].Status != engine.ReportConflict || len(listed[0].Reports) != 2 {
+		t.Fatalf("reports: %d %+v", rec.Code, listed)
+	}
+
+	accept := map[string]string{"id": params["id"], "table": "2", "side": "a"}
+	rec = httptest.NewRecorder()
+	rounds.AcceptReport(rec, requestWithUser("POST", "/", "", owner, accept))
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("accept: status %d %s", rec.Code, rec.Body.String())
+	}
+	tm, _ = db.GetTournament(ctx, database, tourn.ID)
+	eng, _ = swisstools.LoadTournament(tm.EngineState)
+	if p := eng.GetRound()[1]; p.PlayerAWins() != 2 || p.PlayerBWins() != 0 {
+		t.Errorf("table 2 after accepting A's report = %d-%d, want 2-0", p.PlayerAWins(), p.PlayerBWins())
+	}
+}
@@ -1,10 +1,13 @@
 package api
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/dstathis/openswiss/internal/db"
 	"github.com/dstathis/openswiss/internal/engine"
@@ -18,6 +21,30 @@ type RoundsAPI struct {
 	DB *sql.DB
 }
 
+// hidesDraft reports whether round's pairings are a draft the caller can't
+// see yet: until published, drafts are for staff (Judge and up) only.
+func hidesDraft(r *http.Request, database *sql.DB, t *models.Tournament, round int) bool {
+	if !t.PairingsDraft(round) {
+		return false
+	}
+	tier, err := db.EffectiveTournamentTier(r.Context(), database, t.ID, middleware.GetUser(r.Context()))
+	return err != nil || !tier.AtLeast(models.TierJudge)
+}
+
+// hidesStandings reports whether t's standings are hidden from the caller:
+// in a blind final round only staff (Judge and up) see them (see
+// engine.StandingsHidden).
+func hidesStandings(r *http.Request, database *sql.DB, t *models.Tournament, eng *swisstools.Tournament) bool {
+	return engine.StandingsHidden(t, eng) && !seesHiddenStandings(r, database, t.ID)
+}
+
+// seesHiddenStandings reports whether the requester is a Judge or above of
+// tournament id, and so sees standings a blind final round hides.
+func seesHiddenStandings(r *http.Request, database *sql.DB, id int64) bool {
+	tier, err := db.EffectiveTournamentTier(r.Context(), database, id, middleware.GetUser(r.Context()))
+	return err == nil && tier.AtLeast(models.TierJudge)
+}
+
 func (a *RoundsAPI) ListRounds(w http.ResponseWriter, r *http.Request) {
 	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
 	t, err := db.GetTournament(r.Context(), a.DB, id)
@@ -42,12 +69,12 @@ func (a *RoundsAPI) ListRounds(w http.ResponseWriter, r *http.Request) {
 	var rounds []roundData
 	for i := 1; i <= eng.GetCurrentRound(); i++ {
 		pairings, err := eng.GetRoundByNumber(i)
-		if err != nil {
+		if err != nil || hidesDraft(r, a.DB, t, i) {
 			continue
 		}
 		rounds = append(rounds, roundData{
 			RoundNumber: i,
-			Pairings:    formatPairings(&eng, pairings),
+			Pairings:    withSeatResults(r.Context(), a.DB, t, i, withSeriesGames(r.Context(), a.DB, t, i, withPairingFields(r.Context(), a.DB, id, i, formatPairings(&eng, pairings)))),
 		})
 	}
 	if rounds == nil {
@@ -72,10 +99,23 @@ func (a *RoundsAPI) GetCurrentRound(w http.ResponseWriter, r *http.Request) {
 		jsonError(w, http.StatusInternalServerError, "failed to load engine state")
 		return
 	}
-	pairings := eng.GetRound()
+	round := eng.GetCurrentRound()
+	if hidesDraft(r, a.DB, t, round) {
+		jsonResponse(w, http.StatusOK, map[string]interface{}{
+			"round_number": round,
+			"published":    false,
+			"pairings":     []pairingResponse{},
+		})
+		return
+	}
+	pairings := filterPairings(w, r, formatPairings(&eng, eng.GetRound()))
+	pairings = withPairingFields(r.Context(), a.DB, id, round, pairings)
+	pairings = withResultTypes(r.Context(), a.DB, id, round, pairings)
+	pairings = withFeatureMatches(r.Context(), a.DB, id, round, pairings)
 	jsonResponse(w, http.StatusOK, map[string]interface{}{
-		"round_number": eng.GetCurrentRound(),
-		"pairings":     formatPairings(&eng, pairings),
+		"round_number": round,
+		"published":    !t.PairingsDraft(round),
+		"pairings":     withSeatResults(r.Context(), a.DB, t, round, withSeriesGames(r.Context(), a.DB, t, round, pairings)),
 	})
 }
 
@@ -101,9 +141,56 @@ func (a *RoundsAPI) GetRound(w http.ResponseWriter, r *http.Request) {
 		jsonError(w, http.StatusNotFound, err.Error())
 		return
 	}
+	if hidesDraft(r, a.DB, t, roundNum) {
+		jsonError(w, http.StatusNotFound, "pairings not published yet")
+		return
+	}
+	prs := filterPairings(w, r, formatPairings(&eng, pairings))
+	prs = withPairingFields(r.Context(), a.DB, id, roundNum, prs)
+	prs = withResultTypes(r.Context(), a.DB, id, roundNum, prs)
+	prs = withFeatureMatches(r.Context(), a.DB, id, roundNum, prs)
 	jsonResponse(w, http.StatusOK, map[string]interface{}{
 		"round_number": roundNum,
-		"pairings":     formatPairings(&eng, pairings),
+		"pairings":     withSeatResults(r.Context(), a.DB, t, roundNum, withSeriesGames(r.Context(), a.DB, t, roundNum, prs)),
+	})
+}
+
+// LookupPairing returns the current round matches of the players whose
+// name contains ?q= (see engine.LookupMatches): just their table and
+// opponent, for a player looking themselves up on a phone. q is required.
+// A draft round finds nobody but for judges and up until it is published.
+func (a *RoundsAPI) LookupPairing(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	q := strings.TrimSpace(r.URL.Query().Get("q"))
+	if q == "" {
+		jsonError(w, http.StatusBadRequest, "q is required")
+		return
+	}
+	t, err := db.GetTournament(r.Context(), a.DB, id)
+	if err != nil {
+		jsonError(w, http.StatusNotFound, "not found")
+		return
+	}
+	if t.EngineState == nil {
+		jsonError(w, http.StatusBadRequest, "tournament not started")
+		return
+	}
+	eng, err := swisstools.LoadTournament(t.EngineState)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, "failed to load engine state")
+		return
+	}
+	round := eng.GetCurrentRound()
+	matches := []engine.PlayerMatch{}
+	if !hidesDraft(r, a.DB, t, round) {
+		if found := engine.LookupMatches(&eng, eng.GetRound(), q); found != nil {
+			matches = found
+		}
+	}
+	jsonResponse(w, http.StatusOK, map[string]interface{}{
+		"round_number": round,
+		"published":    !t.PairingsDraft(round),
+		"matches":      matches,
 	})
 }
 
@@ -111,11 +198,15 @@ type resultBatch struct {
 	Results []resultEntry `json:"results"`
 }
 
+// resultEntry is one match result, from PlayerID's side. Type may be
+// "intentional_draw" for the player's match or "concession" for the player
+// conceding it; the scores are then ignored.
 type resultEntry struct {
-	PlayerID int `json:"player_id"`
-	Wins     int `json:"wins"`
-	Losses   int `json:"losses"`
-	Draws    int `json:"draws"`
+	PlayerID int    `json:"player_id"`
+	Wins     int    `json:"wins"`
+	Losses   int    `json:"losses"`
+	Draws    int    `json:"draws"`
+	Type     string `json:"type,omitempty"`
 }
 
 func (a *RoundsAPI) SubmitResults(w http.ResponseWriter, r *http.Request) {
@@ -129,11 +220,18 @@ func (a *RoundsAPI) SubmitResults(w http.ResponseWriter, r *http.Request) {
 		jsonError(w, http.StatusBadRequest, "invalid request body")
 		return
 	}
+	user := middleware.GetUser(r.Context())
 
 	err := engine.WithTournamentEngine(r.Context(), a.DB, id,
 		func(tx *sql.Tx, t *models.Tournament, eng *swisstools.Tournament) (string, error) {
 			for _, res := range batch.Results {
-				if err := eng.AddResult(res.PlayerID, res.Wins, res.Losses, res.Draws); err != nil {
+				var err error
+				if res.Type == "" {
+					err = engine.RecordResult(r.Context(), tx, t, eng, res.PlayerID, res.Wins, res.Losses, res.Draws)
+				} else {
+					err = engine.RecordSpecialResult(r.Context(), tx, t, eng, res.PlayerID, res.Type, &user.ID)
+				}
+				if err != nil {
 					return "", fmt.Errorf("player %d: %w", res.PlayerID, err)
 				}
 			}
@@ -141,39 +239,272 @@ func (a *RoundsAPI) SubmitResults(w http.ResponseWriter, r *http.Request) {
 		})
 
 	if err != nil {
-		jsonError(w, http.StatusBadRequest, err.Error())
+		engineError(w, err)
 		return
 	}
 	jsonResponse(w, http.StatusOK, map[string]string{"status": "ok"})
 }
 
+// correctionRequest is the new result of a match in a closed round, from
+// player A's side.
+type correctionRequest struct {
+	Wins   int `json:"wins"`
+	Losses int `json:"losses"`
+	Draws  int `json:"draws"`
+}
+
+// CorrectResult changes the result at a table of a closed Swiss round and
+// returns the correction. Min tier: Admin.
+func (a *RoundsAPI) CorrectResult(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if !middleware.AuthorizeTournament(w, r, a.DB, id, models.TierAdmin) {
+		return
+	}
+	round, errR := strconv.Atoi(chi.URLParam(r, "round"))
+	table, errT := strconv.Atoi(chi.URLParam(r, "table"))
+	if errR != nil || errT != nil {
+		jsonError(w, http.StatusNotFound, "not found")
+		return
+	}
+	var req correctionRequest
+	if err := decodeJSON(r, &req); err != nil {
+		jsonError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	user := middleware.GetUser(r.Context())
+
+	var c *models.ResultCorrection
+	err := engine.WithTournamentEngine(r.Context(), a.DB, id,
+		func(tx *sql.Tx, t *models.Tournament, eng *swisstools.Tournament) (string, error) {
+			var err error
+			c, err = engine.CorrectResult(r.Context(), tx, t, eng, round, table, req.Wins, req.Losses, req.Draws, &user.ID)
+			return "", err
+		})
+	if err != nil {
+		engineError(w, err)
+		return
+	}
+	jsonResponse(w, http.StatusOK, c)
+}
+
+// ListCorrections returns the tournament's result corrections, oldest
+// first.
+func (a *RoundsAPI) ListCorrections(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if _, err := db.GetTournament(r.Context(), a.DB, id); err != nil {
+		jsonError(w, http.StatusNotFound, "not found")
+		return
+	}
+	corrections, err := db.ListResultCorrections(r.Context(), a.DB, id)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, "failed to list corrections")
+		return
+	}
+	if corrections == nil {
+		corrections = []models.ResultCorrection{}
+	}
+	jsonResponse(w, http.StatusOK, corrections)
+}
+
+type reportGameRequest struct {
+	Winner      string `json:"winner"`
+	WentFirst   string `json:"went_first"`
+	Map         string `json:"map"`
+	PlayerAPick string `json:"player_a_pick"`
+	PlayerBPick string `json:"player_b_pick"`
+	Notes       string `json:"notes"`
+}
+
+// game returns the game req describes at table, reported by the caller.
+func (req reportGameRequest) game(r *http.Request, table int) *models.MatchGame {
+	user := middleware.GetUser(r.Context())
+	return &models.MatchGame{
+		Table:       table,
+		Winner:      req.Winner,
+		WentFirst:   req.WentFirst,
+		Map:         req.Map,
+		PlayerAPick: req.PlayerAPick,
+		PlayerBPick: req.PlayerBPick,
+		Notes:       req.Notes,
+		ReportedBy:  &user.ID,
+	}
+}
+
+// ReportGame records the next game of the series at a table of the current
+// round. The match result is written once the series is decided. Min tier:
+// Judge.
+func (a *RoundsAPI) ReportGame(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	table, err := strconv.Atoi(chi.URLParam(r, "table"))
+	if err != nil || table < 1 {
+		jsonError(w, http.StatusNotFound, "not found")
+		return
+	}
+	if !middleware.AuthorizeTournament(w, r, a.DB, id, models.TierJudge) {
+		return
+	}
+	var req reportGameRequest
+	if err := decodeJSON(r, &req); err != nil {
+		jsonError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	g := req.game(r, table)
+	err = engine.WithTournamentEngine(r.Context(), a.DB, id,
+		func(tx *sql.Tx, t *models.Tournament, eng *swisstools.Tournament) (string, error) {
+			return "", engine.ReportGame(r.Context(), tx, t, eng, g)
+		})
+	if err != nil {
+		engineError(w, err)
+		return
+	}
+	jsonResponse(w, http.StatusCreated, g)
+}
+
+// EditGame changes a game already reported at a table of the current round,
+// taking the same body as ReportGame, and updates the match result. Min
+// tier: Judge.
+func (a *RoundsAPI) EditGame(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	table, err := strconv.Atoi(chi.URLParam(r, "table"))
+	if err != nil || table < 1 {
+		jsonError(w, http.StatusNotFound, "not found")
+		return
+	}
+	number, err := strconv.Atoi(chi.URLParam(r, "game"))
+	if err != nil || number < 1 {
+		jsonError(w, http.StatusNotFound, "not found")
+		return
+	}
+	if !middleware.AuthorizeTournament(w, r, a.DB, id, models.TierJudge) {
+		return
+	}
+	var req reportGameRequest
+	if err := decodeJSON(r, &req); err != nil {
+		jsonError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	g := req.game(r, table)
+	g.GameNumber = number
+	err = engine.WithTournamentEngine(r.Context(), a.DB, id,
+		func(tx *sql.Tx, t *models.Tournament, eng *swisstools.Tournament) (string, error) {
+			return "", engine.EditGame(r.Context(), tx, t, eng, g)
+		})
+	if err != nil {
+		engineError(w, err)
+		return
+	}
+	jsonResponse(w, http.StatusOK, g)
+}
+
+// Games returns every series game of the tournament, by round, table and
+// game number, with a summary for stats (see engine.SummarizeGames).
+// Draft rounds are left out for callers below Judge. Public.
+func (a *RoundsAPI) Games(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	t, err := db.GetTournament(r.Context(), a.DB, id)
+	if err != nil {
+		jsonError(w, http.StatusNotFound, "tournament not found")
+		return
+	}
+	all, err := db.ListAllMatchGames(r.Context(), a.DB, id)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, "failed to list games")
+		return
+	}
+	hidden := 0
+	if hidesDraft(r, a.DB, t, t.DraftRound) {
+		hidden = t.DraftRound
+	}
+	games := []models.MatchGame{}
+	for _, g := range all {
+		if g.Round != hidden {
+			g.ReportedBy = nil
+			games = append(games, g)
+		}
+	}
+	jsonResponse(w, http.StatusOK, map[string]interface{}{
+		"summary": engine.SummarizeGames(games),
+		"games":   games,
+	})
+}
+
+// UndoGame removes the last reported game of the series at a table of the
+// current round. Min tier: Judge.
+func (a *RoundsAPI) UndoGame(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	table, err := strconv.Atoi(chi.URLParam(r, "table"))
+	if err != nil || table < 1 {
+		jsonError(w, http.StatusNotFound, "not found")
+		return
+	}
+	if !middleware.AuthorizeTournament(w, r, a.DB, id, models.TierJudge) {
+		return
+	}
+	err = engine.WithTournamentEngine(r.Context(), a.DB, id,
+		func(tx *sql.Tx, t *models.Tournament, eng *swisstools.Tournament) (string, error) {
+			return "", engine.UndoGame(r.Context(), tx, t, eng, table)
+		})
+	if err != nil {
+		engineError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// NextRound closes the current round and pairs the next. Optional body:
+// {"force": true} records unreported matches as 0-0-1 draws; without it they
+// make the call fail with 409 and a "missing_tables" list. Min tier:
+// Co-organizer.
 func (a *RoundsAPI) NextRound(w http.ResponseWriter, r *http.Request) {
 	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
 	if !middleware.AuthorizeTournament(w, r, a.DB, id, models.TierCoOrganizer) {
 		return
 	}
+	var req struct {
+		Force bool `json:"force"`
+	}
+	if r.ContentLength != 0 {
+		if err := decodeJSON(r, &req); err != nil {
+			jsonError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+	}
 
 	err := engine.WithTournamentEngine(r.Context(), a.DB, id,
 		func(tx *sql.Tx, t *models.Tournament, eng *swisstools.Tournament) (string, error) {
-			if err := eng.NextRound(); err != nil {
+			assigned, err := engine.AssignedByes(r.Context(), tx, t, eng)
+			if err != nil {
 				return "", err
 			}
-			if eng.GetStatus() == "finished" {
-				return models.TournamentStatusFinished, nil
+			clubs, err := engine.Clubs(r.Context(), tx, t)
+			if err != nil {
+				return "", err
 			}
-			if err := eng.Pair(false); err != nil {
+			seeds, err := engine.Seeds(r.Context(), tx, t)
+			if err != nil {
 				return "", err
 			}
-			return "", nil
+			return engine.AdvanceRound(eng, t, req.Force, assigned, clubs, seeds)
 		})
 
+	var missing *engine.MissingResultsError
+	if errors.As(err, &missing) {
+		jsonResponse(w, http.StatusConflict, map[string]interface{}{
+			"error":          err.Error(),
+			"round":          missing.Round,
+			"missing_tables": missing.Tables,
+		})
+		return
+	}
 	if err != nil {
-		jsonError(w, http.StatusBadRequest, err.Error())
+		engineError(w, err)
 		return
 	}
 	jsonResponse(w, http.StatusOK, map[string]string{"status": "ok"})
 }
 
+// GetStandings returns the Swiss standings, filtered by ?q= and paged. In a
+// blind final round they are 403 for all but staff.
 func (a *RoundsAPI) GetStandings(w http.ResponseWriter, r *http.Request) {
 	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
 	t, err := db.GetTournament(r.Context(), a.DB, id)
@@ -190,12 +521,92 @@ func (a *RoundsAPI) GetStandings(w http.ResponseWriter, r *http.Request) {
 		jsonError(w, http.StatusInternalServerError, "failed to load engine state")
 		return
 	}
-	jsonResponse(w, http.StatusOK, eng.GetStandings())
+	if hidesStandings(r, a.DB, t, &eng) {
+		jsonError(w, http.StatusForbidden, "standings are hidden until the final round ends")
+		return
+	}
+	regs, err := db.ListRegistrations(r.Context(), a.DB, id)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, "failed to list registrations")
+		return
+	}
+	standings := filterRows(engine.CachedStandings(t, &eng, engine.TiebreakSeeds(regs)), nameFilter(r),
+		func(s swisstools.PlayerStanding) []string { return []string{s.Name} })
+	jsonResponse(w, http.StatusOK, pageRows(w, r, standings))
+}
+
+// GetResults returns a complete tournament's final standings, playoff
+// finishers first (see engine.FinalStandings). Supports ?q= and paging like
+// the standings. 409 until the tournament is complete (see engine.Complete).
+func (a *RoundsAPI) GetResults(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	t, err := db.GetTournament(r.Context(), a.DB, id)
+	if err != nil {
+		jsonError(w, http.StatusNotFound, "not found")
+		return
+	}
+	if t.EngineState == nil {
+		jsonError(w, http.StatusConflict, "tournament is not finished")
+		return
+	}
+	eng, err := swisstools.LoadTournament(t.EngineState)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, "failed to load engine state")
+		return
+	}
+	if !engine.Complete(t, &eng) {
+		jsonError(w, http.StatusConflict, "tournament is not finished")
+		return
+	}
+	regs, err := db.ListRegistrations(r.Context(), a.DB, id)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, "failed to list registrations")
+		return
+	}
+	placings := filterRows(engine.FinalStandings(&eng, t.TiebreakOrder(), engine.TiebreakSeeds(regs)), nameFilter(r),
+		func(p engine.Placing) []string { return []string{p.Standing.Name} })
+	jsonResponse(w, http.StatusOK, pageRows(w, r, placings))
+}
+
+// GetMeta returns the field broken down by archetype, with top-cut
+// conversion once the playoff is seeded (see engine.BuildMetagame).
+func (a *RoundsAPI) GetMeta(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	t, err := db.GetTournament(r.Context(), a.DB, id)
+	if err != nil {
+		jsonError(w, http.StatusNotFound, "not found")
+		return
+	}
+	if t.EngineState == nil {
+		jsonError(w, http.StatusBadRequest, "tournament not started")
+		return
+	}
+	eng, err := swisstools.LoadTournament(t.EngineState)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, "failed to load engine state")
+		return
+	}
+	regs, err := db.ListRegistrations(r.Context(), a.DB, id)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, "failed to list registrations")
+		return
+	}
+	jsonResponse(w, http.StatusOK, engine.BuildMetagame(regs, &eng))
+}
+
+// filterPairings applies a round endpoint's ?q= player search and paging.
+// A pairing matches when either player's name does.
+func filterPairings(w http.ResponseWriter, r *http.Request, prs []pairingResponse) []pairingResponse {
+	prs = filterRows(prs, nameFilter(r), func(p pairingResponse) []string {
+		return []string{p.PlayerAName, p.PlayerBName}
+	})
+	return pageRows(w, r, prs)
 }
 
 // Helpers
 
 type pairingResponse struct {
+	Table       int    `json:"table"`
 	PlayerA     int    `json:"player_a"`
 	PlayerB     int    `json:"player_b"`
 	PlayerAName string `json:"player_a_name"`
@@ -204,12 +615,115 @@ type pairingResponse struct {
 	PlayerBWins int    `json:"player_b_wins"`
 	Draws       int    `json:"draws"`
 	IsBye       bool   `json:"is_bye"`
+	// Fields holds public custom pairing field values keyed by label.
+	Fields map[string]string `json:"fields,omitempty"`
+	// Games lists the reported games of the pairing's series in series mode.
+	Games []models.MatchGame `json:"games,omitempty"`
+	// Seats lists the reported seat results of the match in a team event.
+	Seats []models.SeatResult `json:"seats,omitempty"`
+	// ResultType is "intentional_draw" or "concession" for a result that
+	// wasn't played out; ConcededBy is the conceding side, "a" or "b".
+	ResultType string `json:"result_type,omitempty"`
+	ConcededBy string `json:"conceded_by,omitempty"`
+	// Feature is set on a feature match, one staff put on stream.
+	Feature bool `json:"feature,omitempty"`
+}
+
+// withSeriesGames fills in the reported games of a round's pairings when the
+// tournament is in series mode.
+func withSeriesGames(ctx context.Context, database db.DBTX, t *models.Tournament, round int, prs []pairingResponse) []pairingResponse {
+	if !t.SeriesMode {
+		return prs
+	}
+	games, err := db.ListMatchGames(ctx, database, t.ID, round)
+	if err != nil {
+		return prs
+	}
+	for i := range prs {
+		prs[i].Games = games[prs[i].Table]
+	}
+	return prs
+}
+
+// withSeatResults fills in the reported seat results of a round's pairings
+// when the tournament is a team event.
+func withSeatResults(ctx context.Context, database db.DBTX, t *models.Tournament, round int, prs []pairingResponse) []pairingResponse {
+	if t.TeamSize == 0 {
+		return prs
+	}
+	seats, err := db.ListSeatResults(ctx, database, t.ID, round)
+	if err != nil {
+		return prs
+	}
+	for i := range prs {
+		prs[i].Seats = seats[prs[i].Table]
+	}
+	return prs
+}
+
+// withResultTypes marks the round's intentional draws and concessions.
+func withResultTypes(ctx context.Context, database db.DBTX, tournamentID int64, round int, prs []pairingResponse) []pairingResponse {
+	types, err := db.ListMatchResultTypes(ctx, database, tournamentID, round)
+	if err != nil {
+		return prs
+	}
+	for i := range prs {
+		if rt, ok := types[prs[i].Table]; ok {
+			prs[i].ResultType = rt.Type
+			prs[i].ConcededBy = rt.ConcededBy
+		}
+	}
+	return prs
+}
+
+// withFeatureMatches marks the round's feature matches.
+func withFeatureMatches(ctx context.Context, database db.DBTX, tournamentID int64, round int, prs []pairingResponse) []pairingResponse {
+	tables, err := db.ListFeatureMatches(ctx, database, tournamentID, round)
+	if err != nil {
+		return prs
+	}
+	for _, table := range tables {
+		for i := range prs {
+			if prs[i].Table == table {
+				prs[i].Feature = true
+			}
+		}
+	}
+	return prs
+}
+
+// withPairingFields fills in the public custom field values of a round's
+// pairings. Staff-only fields are never included; the public round
+// endpoints are unauthenticated.
+func withPairingFields(ctx context.Context, database db.DBTX, tournamentID int64, round int, prs []pairingResponse) []pairingResponse {
+	fields, err := db.ListPairingFields(ctx, database, tournamentID)
+	if err != nil || len(fields) == 0 {
+		return prs
+	}
+	values, err := db.ListPairingFieldValues(ctx, database, tournamentID, round)
+	if err != nil {
+		return prs
+	}
+	for i := range prs {
+		for _, f := range fields {
+			v, ok := values[prs[i].Table][f.ID]
+			if !ok || !f.Public {
+				continue
+			}
+			if prs[i].Fields == nil {
+				prs[i].Fields = map[string]string{}
+			}
+			prs[i].Fields[f.Label] = v
+		}
+	}
+	return prs
 }
 
 func formatPairings(eng *swisstools.Tournament, pairings []swisstools.Pairing) []pairingResponse {
 	result := make([]pairingResponse, 0, len(pairings))
-	for _, p := range pairings {
+	for i, p := range pairings {
 		pr := pairingResponse{
+			Table:       engine.TableNumber(i, p),
 			PlayerA:     p.PlayerA(),
 			PlayerB:     p.PlayerB(),
 			PlayerAWins: p.PlayerAWins(),
@@ -227,3 +741,112 @@ func formatPairings(eng *swisstools.Tournament, pairings []swisstools.Pairing) [
 	}
 	return result
 }
+
+// RepairPreview proposes a re-pairing of the current round without changing
+// anything. Returns the current and proposed pairings, what happens to each
+// current match, and the round_key and proposal to post to Repair.
+// Min tier: Co-organizer.
+func (a *RoundsAPI) RepairPreview(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	t, err := db.GetTournament(r.Context(), a.DB, id)
+	if err != nil {
+		jsonError(w, http.StatusNotFound, "not found")
+		return
+	}
+	if !middleware.AuthorizeTournament(w, r, a.DB, t.ID, models.TierCoOrganizer) {
+		return
+	}
+	if t.EngineState == nil {
+		jsonError(w, http.StatusBadRequest, "tournament not started")
+		return
+	}
+	eng, err := swisstools.LoadTournament(t.EngineState)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, "failed to load engine state")
+		return
+	}
+	assigned, err := engine.AssignedByes(r.Context(), a.DB, t, &eng)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, "failed to list assigned byes")
+		return
+	}
+	clubs, err := engine.Clubs(r.Context(), a.DB, t)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, "failed to list clubs")
+		return
+	}
+	seeds, err := engine.Seeds(r.Context(), a.DB, t)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, "failed to list registrations")
+		return
+	}
+	proposed, err := engine.PreviewRepair(t, &eng, assigned, clubs, seeds)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	jsonResponse(w, http.StatusOK, map[string]interface{}{
+		"round_number": eng.GetCurrentRound(),
+		"round_key":    engine.RoundKey(&eng),
+		"proposal":     engine.EncodeRound(proposed.GetRound()),
+		"current":      formatPairings(&eng, eng.GetRound()),
+		"proposed":     formatPairings(proposed, proposed.GetRound()),
+		"changes":      engine.DiffRepair(eng.GetRound(), proposed.GetRound()),
+	})
+}
+
+// Repair replaces the current round with a proposal from RepairPreview.
+// Body: {"round_key": "...", "proposal": "..."}. Returns 409 if the round
+// changed since the preview. Min tier: Co-organizer.
+func (a *RoundsAPI) Repair(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if !middleware.AuthorizeTournament(w, r, a.DB, id, models.TierCoOrganizer) {
+		return
+	}
+	var body struct {
+		RoundKey string `json:"round_key"`
+		Proposal string `json:"proposal"`
+	}
+	if err := decodeJSON(r, &body); err != nil || body.RoundKey == "" || body.Proposal == "" {
+		jsonError(w, http.StatusBadRequest, "round_key and proposal are required")
+		return
+	}
+
+	err := engine.WithTournamentEngine(r.Context(), a.DB, id,
+		func(tx *sql.Tx, t *models.Tournament, eng *swisstools.Tournament) (string, error) {
+			return "", engine.ApplyRepair(r.Context(), tx, t, eng, body.RoundKey, body.Proposal)
+		})
+	if errors.Is(err, engine.ErrRoundChanged) {
+		jsonError(w, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		engineError(w, err)
+		return
+	}
+	jsonResponse(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// Diagnostics returns the pairing quality report of the tournament's Swiss
+// rounds: rematches, matches across records and byes per round, with
+// totals (see engine.DiagnosePairings). Before the start it has no rounds.
+// Min tier: Co-organizer.
+func (a *RoundsAPI) Diagnostics(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if !middleware.AuthorizeTournament(w, r, a.DB, id, models.TierCoOrganizer) {
+		return
+	}
+	t, err := db.GetTournament(r.Context(), a.DB, id)
+	if err != nil {
+		jsonError(w, http.StatusNotFound, "not found")
+		return
+	}
+	eng := swisstools.NewTournament()
+	if len(t.EngineState) > 0 {
+		if eng, err = swisstools.LoadTournament(t.EngineState); err != nil {
+			jsonError(w, http.StatusInternalServerError, "failed to load tournament")
+			return
+		}
+	}
+	jsonResponse(w, http.StatusOK, engine.DiagnosePairings(t, &eng))
+}
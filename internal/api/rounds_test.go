@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/dstathis/openswiss/internal/db"
@@ -131,6 +132,35 @@ func TestRoundsAPI_GetCurrentRound_Started(t *testing.T) {
 	}
 }
 
+func TestRoundsAPI_LookupPairing(t *testing.T) {
+	database := testDB(t)
+	_, tourn := freshStarted(t, database)
+	api := &RoundsAPI{DB: database}
+	params := map[string]string{"id": strconv.FormatInt(tourn.ID, 10)}
+
+	rec := httptest.NewRecorder()
+	api.LookupPairing(rec, requestWithUser("GET", "/?q=fp1-", "", nil, params))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d body %s", rec.Code, rec.Body.String())
+	}
+	var resp struct {
+		RoundNumber int                  `json:"round_number"`
+		Published   bool                 `json:"published"`
+		Matches     []engine.PlayerMatch `json:"matches"`
+	}
+	json.NewDecoder(rec.Body).Decode(&resp)
+	if resp.RoundNumber != 1 || !resp.Published || len(resp.Matches) != 1 ||
+		!strings.HasPrefix(resp.Matches[0].Name, "FP1-") || resp.Matches[0].Opponent == "" {
+		t.Errorf("lookup = %+v, want FP1's round 1 match", resp)
+	}
+
+	rec = httptest.NewRecorder()
+	api.LookupPairing(rec, requestWithUser("GET", "/", "", nil, params))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("no q: status %d, want 400", rec.Code)
+	}
+}
+
 func TestRoundsAPI_GetCurrentRound_NotStarted(t *testing.T) {
 	database := testDB(t)
 	api := &RoundsAPI{DB: database}
@@ -279,6 +309,73 @@ func TestRoundsAPI_SubmitResults_Forbidden(t *testing.T) {
 	}
 }
 
+func TestRoundsAPI_ReportGame(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	api := &RoundsAPI{DB: database}
+	owner, tourn := freshStarted(t, database)
+	tourn.BestOf = 3
+	tourn.SeriesMode = true
+	if err := db.UpdateTournament(ctx, database, tourn); err != nil {
+		t.Fatalf("enable series mode: %v", err)
+	}
+	params := map[string]string{"id": strconv.FormatInt(tourn.ID, 10), "table": "1"}
+
+	for i, body := range []string{`{"winner":"a","map":"Nuke"}`, `{"winner":"a","map":"Mirage"}`} {
+		rec := httptest.NewRecorder()
+		api.ReportGame(rec, requestWithUser("POST", "/", body, owner, params))
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("game %d: status %d body %s", i+1, rec.Code, rec.Body.String())
+		}
+	}
+	rec := httptest.NewRecorder()
+	api.ReportGame(rec, requestWithUser("POST", "/", `{"winner":"b"}`, owner, params))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("game after 2-0: status %d, want 400", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	api.GetCurrentRound(rec, requestWithUser("GET", "/", "", nil, params))
+	var round struct {
+		Pairings []pairingResponse `json:"pairings"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &round); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	p := round.Pairings[0]
+	if p.PlayerAWins != 2 || p.PlayerBWins != 0 || len(p.Games) != 2 || p.Games[1].Map != "Mirage" {
+		t.Errorf("table 1 = %+v", p)
+	}
+
+	rec = httptest.NewRecorder()
+	api.UndoGame(rec, requestWithUser("DELETE", "/", "", owner, params))
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("undo: status %d body %s", rec.Code, rec.Body.String())
+	}
+	games, _ := db.ListTableGames(ctx, database, tourn.ID, 1, 1)
+	if len(games) != 1 {
+		t.Errorf("games after undo = %+v", games)
+	}
+}
+
+func TestRoundsAPI_ReportGame_BadWinner(t *testing.T) {
+	database := testDB(t)
+	api := &RoundsAPI{DB: database}
+	owner, tourn := freshStarted(t, database)
+	tourn.BestOf = 1
+	tourn.SeriesMode = true
+	if err := db.UpdateTournament(context.Background(), database, tourn); err != nil {
+		t.Fatalf("enable series mode: %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	api.ReportGame(rec, requestWithUser("POST", "/", `{"winner":"c"}`, owner,
+		map[string]string{"id": strconv.FormatInt(tourn.ID, 10), "table": "1"}))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", rec.Code)
+	}
+}
+
 func TestRoundsAPI_NextRound(t *testing.T) {
 	database := testDB(t)
 	owner, tourn := startedTournament(t, database) // round 1 results already in
@@ -293,6 +390,72 @@ func TestRoundsAPI_NextRound(t *testing.T) {
 	}
 }
 
+func TestRoundsAPI_SubmitResults_Concession(t *testing.T) {
+	database := testDB(t)
+	owner, tourn := freshStarted(t, database)
+	api := &RoundsAPI{DB: database}
+	params := map[string]string{"id": strconv.FormatInt(tourn.ID, 10)}
+	eng, _ := swisstools.LoadTournament(tourn.EngineState)
+	loser := eng.GetRound()[0].PlayerB()
+
+	body := `{"results": [{"player_id": ` + strconv.Itoa(loser) + `, "type": "concession"}]}`
+	rec := httptest.NewRecorder()
+	api.SubmitResults(rec, requestWithUser("POST", "/", body, owner, params))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body=%s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	api.GetCurrentRound(rec, requestWithUser("GET", "/", "", nil, params))
+	var round struct {
+		Pairings []pairingResponse `json:"pairings"`
+	}
+	json.Unmarshal(rec.Body.Bytes(), &round)
+	p := round.Pairings[0]
+	if p.ResultType != models.ResultConcession || p.ConcededBy != models.GameWinnerB || p.PlayerAWins != 2 || p.PlayerBWins != 0 {
+		t.Errorf("pairing = %+v", p)
+	}
+
+	body = `{"results": [{"player_id": ` + strconv.Itoa(loser) + `, "type": "forfeit"}]}`
+	rec = httptest.NewRecorder()
+	api.SubmitResults(rec, requestWithUser("POST", "/", body, owner, params))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("unknown type: status %d, want 400", rec.Code)
+	}
+}
+
+func TestRoundsAPI_NextRound_MissingResults(t *testing.T) {
+	database := testDB(t)
+	owner, tourn := freshStarted(t, database)
+	api := &RoundsAPI{DB: database}
+	params := map[string]string{"id": strconv.FormatInt(tourn.ID, 10)}
+
+	rec := httptest.NewRecorder()
+	api.NextRound(rec, requestWithUser("POST", "/", "", owner, params))
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("without force: status %d, body=%s", rec.Code, rec.Body.String())
+	}
+	var body struct {
+		Round         int   `json:"round"`
+		MissingTables []int `json:"missing_tables"`
+	}
+	json.Unmarshal(rec.Body.Bytes(), &body)
+	if body.Round != 1 || len(body.MissingTables) != 2 {
+		t.Errorf("conflict body = %s", rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	api.NextRound(rec, requestWithUser("POST", "/", `{"force": true}`, owner, params))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("forced: status %d, body=%s", rec.Code, rec.Body.String())
+	}
+	got, _ := db.GetTournament(context.Background(), database, tourn.ID)
+	eng, _ := swisstools.LoadTournament(got.EngineState)
+	if eng.GetCurrentRound() != 2 {
+		t.Errorf("round = %d, want 2", eng.GetCurrentRound())
+	}
+}
+
 func TestRoundsAPI_NextRound_Forbidden(t *testing.T) {
 	database := testDB(t)
 	_, tourn := startedTournament(t, database)
@@ -307,6 +470,54 @@ func TestRoundsAPI_NextRound_Forbidden(t *testing.T) {
 	}
 }
 
+func TestRoundsAPI_Repair(t *testing.T) {
+	database := testDB(t)
+	owner, tourn := startedTournament(t, database)
+	api := &RoundsAPI{DB: database}
+	params := map[string]string{"id": strconv.FormatInt(tourn.ID, 10)}
+
+	rec := httptest.NewRecorder()
+	api.RepairPreview(rec, requestWithUser("GET", "/", "", owner, params))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("preview: status %d body %s", rec.Code, rec.Body.String())
+	}
+	var preview struct {
+		RoundKey string                `json:"round_key"`
+		Proposal string                `json:"proposal"`
+		Current  []pairingResponse     `json:"current"`
+		Changes  []engine.RepairChange `json:"changes"`
+	}
+	json.NewDecoder(rec.Body).Decode(&preview)
+	if preview.RoundKey == "" || preview.Proposal == "" || len(preview.Changes) != len(preview.Current) {
+		t.Fatalf("preview = %+v", preview)
+	}
+	// Round 1 results are in, so every real match reports a lost result.
+	for i, c := range preview.Changes {
+		if !preview.Current[i].IsBye && !c.Reported {
+			t.Errorf("change %d not marked reported: %+v", i, c)
+		}
+	}
+
+	body := `{"round_key":"` + preview.RoundKey + `","proposal":"` + preview.Proposal + `"}`
+	rec = httptest.NewRecorder()
+	api.Repair(rec, requestWithUser("POST", "/", body, owner, params))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("repair: status %d body %s", rec.Code, rec.Body.String())
+	}
+	rec = httptest.NewRecorder()
+	api.Repair(rec, requestWithUser("POST", "/", body, owner, params))
+	if rec.Code != http.StatusConflict {
+		t.Errorf("stale repair: status %d, want 409", rec.Code)
+	}
+
+	other := mustCreateUser(t, database, "other-repair@example.com", "OtherRepair")
+	rec = httptest.NewRecorder()
+	api.RepairPreview(rec, requestWithUser("GET", "/", "", other, params))
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("non-staff preview: status %d, want 403", rec.Code)
+	}
+}
+
 func TestRoundsAPI_GetStandings_NotStarted(t *testing.T) {
 	database := testDB(t)
 	api := &RoundsAPI{DB: database}
@@ -342,6 +553,95 @@ func TestRoundsAPI_GetStandings_Started(t *testing.T) {
 	}
 }
 
+func TestRoundsAPI_GetStandings_BlindFinalRound(t *testing.T) {
+	database := testDB(t)
+	owner, tourn := startedTournament(t, database)
+	api := &RoundsAPI{DB: database}
+	params := map[string]string{"id": strconv.FormatInt(tourn.ID, 10)}
+	tourn.BlindFinalRound = true
+	if err := db.UpdateTournament(context.Background(), database, tourn); err != nil {
+		t.Fatal(err)
+	}
+	rec := httptest.NewRecorder()
+	api.NextRound(rec, requestWithUser("POST", "/", "", owner, params))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("next round: status %d body %s", rec.Code, rec.Body.String())
+	}
+
+	// Round 2 of 2 is the final round: only staff see the standings.
+	rec = httptest.NewRecorder()
+	api.GetStandings(rec, requestWithUser("GET", "/", "", nil, params))
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("spectator: expected 403, got %d", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	api.GetStandings(rec, requestWithUser("GET", "/", "", owner, params))
+	if rec.Code != http.StatusOK {
+		t.Errorf("staff: expected 200, got %d", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	(&DiscordAPI{DB: database}).Standings(rec, requestWithUser("GET", "/", "", owner, params))
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("discord: expected 403, got %d", rec.Code)
+	}
+
+	// Feature matches leave the records out for spectators only.
+	if err := db.ReplaceFeatureMatches(context.Background(), database, tourn.ID, 2, []int{1}); err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []struct {
+		user   *models.User
+		hidden bool
+	}{{nil, true}, {owner, false}} {
+		rec = httptest.NewRecorder()
+		api.GetFeatureMatches(rec, requestWithUser("GET", "/", "", c.user, params))
+		var resp featureMatchesResponse
+		json.NewDecoder(rec.Body).Decode(&resp)
+		if len(resp.Matches) != 1 || resp.Hidden != c.hidden {
+			t.Fatalf("feature matches (hidden %v): %+v", c.hidden, resp)
+		}
+		if p := resp.Matches[0].PlayerA; (p.Rank == 0 && p.Points == 0 && p.Wins == 0) != c.hidden {
+			t.Errorf("feature match player (hidden %v): %+v", c.hidden, p)
+		}
+	}
+}
+
+func TestRoundsAPI_SearchAndPage(t *testing.T) {
+	database := testDB(t)
+	_, tourn := startedTournament(t, database)
+	api := &RoundsAPI{DB: database}
+	params := map[string]string{"id": strconv.FormatInt(tourn.ID, 10)}
+
+	rec := httptest.NewRecorder()
+	api.GetStandings(rec, requestWithUser("GET", "/?q=p1-", "", nil, params))
+	var standings []map[string]interface{}
+	json.NewDecoder(rec.Body).Decode(&standings)
+	if len(standings) != 1 || rec.Header().Get("X-Total-Count") != "1" {
+		t.Errorf("search p1-: %d standings, X-Total-Count %q", len(standings), rec.Header().Get("X-Total-Count"))
+	}
+
+	rec = httptest.NewRecorder()
+	api.GetStandings(rec, requestWithUser("GET", "/?per_page=3&page=2", "", nil, params))
+	standings = nil
+	json.NewDecoder(rec.Body).Decode(&standings)
+	if len(standings) != 1 || rec.Header().Get("X-Total-Count") != "4" {
+		t.Errorf("page 2 of 3: %d standings, X-Total-Count %q", len(standings), rec.Header().Get("X-Total-Count"))
+	}
+
+	rec = httptest.NewRecorder()
+	api.GetCurrentRound(rec, requestWithUser("GET", "/?q=P2-", "", nil, params))
+	var round struct {
+		Pairings []pairingResponse `json:"pairings"`
+	}
+	json.NewDecoder(rec.Body).Decode(&round)
+	if len(round.Pairings) != 1 {
+		t.Fatalf("search P2-: %d pairings, want 1", len(round.Pairings))
+	}
+	if p := round.Pairings[0]; !strings.HasPrefix(p.PlayerAName, "P2-") && !strings.HasPrefix(p.PlayerBName, "P2-") {
+		t.Errorf("search P2- returned %+v", p)
+	}
+}
+
 func TestRoundsAPI_GetStandings_NotFound(t *testing.T) {
 	database := testDB(t)
 	api := &RoundsAPI{DB: database}
@@ -443,3 +743,141 @@ func nameOf(eng *swisstools.Tournament, id int) (string, bool) {
 	}
 	return player.Name, true
 }
+
+func TestRoundsAPI_GetResults(t *testing.T) {
+	database := testDB(t)
+	owner, tourn := startedTournament(t, database)
+	api := &RoundsAPI{DB: database}
+	params := map[string]string{"id": strconv.FormatInt(tourn.ID, 10)}
+
+	rec := httptest.NewRecorder()
+	api.GetResults(rec, requestWithUser("GET", "/", "", nil, params))
+	if rec.Code != http.StatusConflict {
+		t.Errorf("running tournament: status %d, want 409", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	(&TournamentAPI{DB: database}).Finish(rec, requestWithUser("POST", "/", "", owner, params))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Finish status = %d, body=%s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	api.GetResults(rec, requestWithUser("GET", "/", "", nil, params))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d, body %s", rec.Code, rec.Body.String())
+	}
+	var placings []engine.Placing
+	json.NewDecoder(rec.Body).Decode(&placings)
+	if len(placings) != 4 || placings[0].Place != 1 || placings[0].Standing.Rank != 1 {
+		t.Errorf("placings = %+v", placings)
+	}
+
+	rec = httptest.NewRecorder()
+	api.GetResults(rec, requestWithUser("GET", "/?q=p2-", "", nil, params))
+	placings = nil
+	json.NewDecoder(rec.Body).Decode(&placings)
+	if len(placings) != 1 || !strings.HasPrefix(placings[0].Standing.Name, "P2-") {
+		t.Errorf("search: placings = %+v", placings)
+	}
+
+	// The results are locked in.
+	rec = httptest.NewRecorder()
+	(&TournamentAPI{DB: database}).Finish(rec, requestWithUser("POST", "/", "", owner, params))
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), engine.ErrTournamentFinished.Error()) {
+		t.Errorf("finishing again: status %d, body %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestRoundsAPI_GetMeta(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	_, tourn := startedTournament(t, database)
+	api := &RoundsAPI{DB: database}
+	regs, _ := db.ListRegistrations(ctx, database, tourn.ID)
+	db.SetArchetype(ctx, database, regs[0].ID, "Elves")
+
+	rec := httptest.NewRecorder()
+	api.GetMeta(rec, requestWithUser("GET", "/", "", nil, map[string]string{"id": strconv.FormatInt(tourn.ID, 10)}))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d, body %s", rec.Code, rec.Body.String())
+	}
+	var m engine.Metagame
+	json.NewDecoder(rec.Body).Decode(&m)
+	if m.Players != 4 || m.TopCut || len(m.Archetypes) != 2 || m.Archetypes[0].Name != "Elves" || m.Archetypes[0].Share != 0.25 {
+		t.Errorf("meta = %+v", m)
+	}
+}
+
+func TestRoundsAPI_CorrectResult(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	owner, tourn := freshStarted(t, database)
+	api := &RoundsAPI{DB: database}
+	id := strconv.FormatInt(tourn.ID, 10)
+
+	// Close round 1 with every unreported match drawn.
+	rec := httptest.NewRecorder()
+	api.NextRound(rec, requestWithUser("POST", "/", `{"force": true}`, owner, map[string]string{"id": id}))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("next round: status %d, body=%s", rec.Code, rec.Body.String())
+	}
+
+	params := map[string]string{"id": id, "round": "1", "table": "1"}
+	rec = httptest.NewRecorder()
+	api.CorrectResult(rec, requestWithUser("POST", "/api/v1/", `{"wins": 2, "losses": 1}`, owner, params))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("correct: status %d, body=%s", rec.Code, rec.Body.String())
+	}
+	var c models.ResultCorrection
+	json.NewDecoder(rec.Body).Decode(&c)
+	if c.Round != 1 || c.Table != 1 || c.OldDraws != 1 || c.NewA != 2 || c.NewB != 1 || c.PairedThrough != 2 {
+		t.Errorf("correction = %+v", c)
+	}
+
+	rec = httptest.NewRecorder()
+	api.CorrectResult(rec, requestWithUser("POST", "/api/v1/", `{"wins": 2}`, owner,
+		map[string]string{"id": id, "round": "2", "table": "1"}))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("current round: status %d, want 400", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	api.ListCorrections(rec, requestWithUser("GET", "/", "", nil, map[string]string{"id": id}))
+	var list []models.ResultCorrection
+	json.NewDecoder(rec.Body).Decode(&list)
+	if rec.Code != http.StatusOK || len(list) != 1 || list[0].CorrectedBy == nil {
+		t.Errorf("list: status %d, %+v", rec.Code, list)
+	}
+	tm, _ := db.GetTournament(ctx, database, tourn.ID)
+	eng, _ := swisstools.LoadTournament(tm.EngineState)
+	first, _ := eng.GetRoundByNumber(1)
+	if first[0].PlayerAWins() != 2 || first[0].Draws() != 0 {
+		t.Errorf("round 1 table 1 = %d-%d-%d", first[0].PlayerAWins(), first[0].PlayerBWins(), first[0].Draws())
+	}
+}
+
+func TestRoundsAPI_Diagnostics(t *testing.T) {
+	database := testDB(t)
+	owner, tourn := freshStarted(t, database)
+	api := &RoundsAPI{DB: database}
+	params := map[string]string{"id": strconv.FormatInt(tourn.ID, 10)}
+
+	rec := httptest.NewRecorder()
+	api.Diagnostics(rec, requestWithUser("GET", "/", "", owner, params))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body=%s", rec.Code, rec.Body.String())
+	}
+	var d engine.PairingDiagnostics
+	json.NewDecoder(rec.Body).Decode(&d)
+	if len(d.Rounds) != 1 || d.Rounds[0].Round != 1 || d.Rematches != 0 {
+		t.Errorf("diagnostics = %+v, want round 1 without rematches", d)
+	}
+
+	stranger := mustCreateUser(t, database, "diag-stranger@example.com", "Diag Stranger")
+	rec = httptest.NewRecorder()
+	api.Diagnostics(rec, requestWithUser("GET", "/", "", stranger, params))
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("stranger: status %d, want 403", rec.Code)
+	}
+}
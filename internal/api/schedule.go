@@ -0,0 +1,69 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/engine"
+	"github.com/dstathis/openswiss/internal/middleware"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/go-chi/chi/v5"
+)
+
+// scheduleResponse is a tournament's schedule with the time zone it is
+// kept in.
+type scheduleResponse struct {
+	TimeZone string                `json:"time_zone"`
+	Items    []models.ScheduleItem `json:"items"`
+}
+
+// GetSchedule returns the tournament's schedule in time order. Public.
+func (a *TournamentAPI) GetSchedule(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	t, err := db.GetTournament(r.Context(), a.DB, id)
+	if err != nil {
+		jsonError(w, http.StatusNotFound, "not found")
+		return
+	}
+	a.writeSchedule(w, r, t)
+}
+
+// SetSchedule replaces the schedule with {"items": [{"starts_at",
+// "label"}]}, start times in RFC 3339, and returns it. Min tier:
+// Co-organizer.
+func (a *TournamentAPI) SetSchedule(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if !middleware.AuthorizeTournament(w, r, a.DB, id, models.TierCoOrganizer) {
+		return
+	}
+	var body struct {
+		Items []models.ScheduleItem `json:"items"`
+	}
+	if err := decodeJSON(r, &body); err != nil {
+		jsonError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if err := engine.SetSchedule(r.Context(), a.DB, id, body.Items); err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	t, err := db.GetTournament(r.Context(), a.DB, id)
+	if err != nil {
+		jsonError(w, http.StatusNotFound, "not found")
+		return
+	}
+	a.writeSchedule(w, r, t)
+}
+
+func (a *TournamentAPI) writeSchedule(w http.ResponseWriter, r *http.Request, t *models.Tournament) {
+	items, err := db.ListSchedule(r.Context(), a.DB, t.ID)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, "failed to load schedule")
+		return
+	}
+	if items == nil {
+		items = []models.ScheduleItem{}
+	}
+	jsonResponse(w, http.StatusOK, scheduleResponse{TimeZone: t.TimeZone, Items: items})
+}
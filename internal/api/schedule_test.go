@@ -0,0 +1,57 @@
+//go:build integration
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func TestTournamentAPI_Schedule(t *testing.T) {
+	database := testDB(t)
+	api := &TournamentAPI{DB: database}
+	owner := mustCreateUser(t, database, "owner-schedule@example.com", "OwnerSchedule")
+	tourn := mustCreateTournament(t, database, owner.ID, "scheduled")
+	params := map[string]string{"id": strconv.FormatInt(tourn.ID, 10)}
+
+	rec := httptest.NewRecorder()
+	api.Update(rec, requestWithUser("PATCH", "/", `{"time_zone":"America/Chicago"}`, owner, params))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("update: status %d body %s", rec.Code, rec.Body.String())
+	}
+	rec = httptest.NewRecorder()
+	api.Update(rec, requestWithUser("PATCH", "/", `{"time_zone":"Local"}`, owner, params))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Local time zone: status %d, want 400", rec.Code)
+	}
+
+	body := `{"items":[{"starts_at":"2026-05-01T15:00:00Z","label":"Round 2"},{"starts_at":"2026-05-01T14:00:00Z","label":"Round 1"}]}`
+	rec = httptest.NewRecorder()
+	api.SetSchedule(rec, requestWithUser("PUT", "/", body, owner, params))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("set: status %d body %s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	api.GetSchedule(rec, requestWithUser("GET", "/", "", nil, params))
+	var got scheduleResponse
+	json.NewDecoder(rec.Body).Decode(&got)
+	if got.TimeZone != "America/Chicago" || len(got.Items) != 2 || got.Items[0].Label != "Round 1" {
+		t.Errorf("schedule = %+v", got)
+	}
+
+	rec = httptest.NewRecorder()
+	api.SetSchedule(rec, requestWithUser("PUT", "/", `{"items":[{"starts_at":"2026-05-01T14:00:00Z","label":""}]}`, owner, params))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("empty label: status %d, want 400", rec.Code)
+	}
+	stranger := mustCreateUser(t, database, "stranger-schedule@example.com", "StrangerSchedule")
+	rec = httptest.NewRecorder()
+	api.SetSchedule(rec, requestWithUser("PUT", "/", body, stranger, params))
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("stranger: status %d, want 403", rec.Code)
+	}
+}
@@ -0,0 +1,70 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/dstathis/openswiss/internal/auth"
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/middleware"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/go-chi/chi/v5"
+)
+
+// shareLinkResponse is a tournament's read-only share link.
+type shareLinkResponse struct {
+	Token string `json:"token"`
+	Path  string `json:"path"`
+}
+
+// GetShareLink returns the tournament's share link, or 404 if it has none.
+// Min tier: Judge.
+func (a *TournamentAPI) GetShareLink(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if !middleware.AuthorizeTournament(w, r, a.DB, id, models.TierJudge) {
+		return
+	}
+	token, err := db.GetShareToken(r.Context(), a.DB, id)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, "failed to load share link")
+		return
+	}
+	if token == "" {
+		jsonError(w, http.StatusNotFound, "tournament has no share link")
+		return
+	}
+	jsonResponse(w, http.StatusOK, shareLinkResponse{Token: token, Path: models.SharePath(id, token)})
+}
+
+// CreateShareLink gives the tournament a new share link, revoking any
+// earlier one, and returns it. Min tier: Co-organizer.
+func (a *TournamentAPI) CreateShareLink(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if !middleware.AuthorizeTournament(w, r, a.DB, id, models.TierCoOrganizer) {
+		return
+	}
+	token, err := auth.GenerateShareToken()
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, "failed to create share link")
+		return
+	}
+	if err := db.SetShareToken(r.Context(), a.DB, id, token); err != nil {
+		jsonError(w, http.StatusInternalServerError, "failed to create share link")
+		return
+	}
+	jsonResponse(w, http.StatusCreated, shareLinkResponse{Token: token, Path: models.SharePath(id, token)})
+}
+
+// RevokeShareLink turns the tournament's share link off. Min tier:
+// Co-organizer.
+func (a *TournamentAPI) RevokeShareLink(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if !middleware.AuthorizeTournament(w, r, a.DB, id, models.TierCoOrganizer) {
+		return
+	}
+	if err := db.SetShareToken(r.Context(), a.DB, id, ""); err != nil {
+		jsonError(w, http.StatusInternalServerError, "failed to revoke share link")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,61 @@
+//go:build integration
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestTournamentAPI_ShareLink(t *testing.T) {
+	database := testDB(t)
+	api := &TournamentAPI{DB: database}
+	owner, tourn := startedTournament(t, database)
+	params := map[string]string{"id": strconv.FormatInt(tourn.ID, 10)}
+
+	rec := httptest.NewRecorder()
+	api.GetShareLink(rec, requestWithUser("GET", "/", "", owner, params))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("no link: status %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	api.CreateShareLink(rec, requestWithUser("POST", "/", "", owner, params))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create: status %d %s", rec.Code, rec.Body.String())
+	}
+	var link shareLinkResponse
+	json.NewDecoder(rec.Body).Decode(&link)
+	if link.Token == "" || !strings.HasSuffix(link.Path, "/view/"+link.Token) {
+		t.Errorf("link = %+v", link)
+	}
+
+	rec = httptest.NewRecorder()
+	api.GetShareLink(rec, requestWithUser("GET", "/", "", owner, params))
+	var got shareLinkResponse
+	json.NewDecoder(rec.Body).Decode(&got)
+	if got != link {
+		t.Errorf("get = %+v, want %+v", got, link)
+	}
+
+	rec = httptest.NewRecorder()
+	api.GetShareLink(rec, requestWithUser("GET", "/", "", nil, params))
+	if rec.Code == http.StatusOK {
+		t.Error("anonymous caller read the share link")
+	}
+
+	rec = httptest.NewRecorder()
+	api.RevokeShareLink(rec, requestWithUser("DELETE", "/", "", owner, params))
+	if rec.Code != http.StatusNoContent {
+		t.Errorf("revoke: status %d", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	api.GetShareLink(rec, requestWithUser("GET", "/", "", owner, params))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("after revoke: status %d", rec.Code)
+	}
+}
@@ -0,0 +1,93 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/engine"
+	"github.com/dstathis/openswiss/internal/middleware"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/go-chi/chi/v5"
+)
+
+type SnapshotsAPI struct {
+	DB *sql.DB
+}
+
+// List returns the tournament's snapshots, newest first. Min tier: Admin.
+func (a *SnapshotsAPI) List(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if !middleware.AuthorizeTournament(w, r, a.DB, id, models.TierAdmin) {
+		return
+	}
+	snapshots, err := db.ListSnapshots(r.Context(), a.DB, id)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, "failed to list snapshots")
+		return
+	}
+	if snapshots == nil {
+		snapshots = []models.TournamentSnapshot{}
+	}
+	jsonResponse(w, http.StatusOK, snapshots)
+}
+
+// Get returns a snapshot's backup, in the format of
+// GET /api/v1/admin/backup/{id}. Min tier: Admin.
+func (a *SnapshotsAPI) Get(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	snapshotID, err := strconv.ParseInt(chi.URLParam(r, "snapshotID"), 10, 64)
+	if err != nil {
+		jsonError(w, http.StatusNotFound, "not found")
+		return
+	}
+	if !middleware.AuthorizeTournament(w, r, a.DB, id, models.TierAdmin) {
+		return
+	}
+	b, err := db.GetSnapshotBackup(r.Context(), a.DB, id, snapshotID)
+	if errors.Is(err, sql.ErrNoRows) {
+		jsonError(w, http.StatusNotFound, "not found")
+		return
+	}
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, "failed to load snapshot")
+		return
+	}
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, b.Filename()))
+	jsonResponse(w, http.StatusOK, b)
+}
+
+// RollBack returns the tournament to a snapshot, after saving the current
+// state as one. Returns the updated tournament. Min tier: Admin.
+func (a *SnapshotsAPI) RollBack(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	snapshotID, err := strconv.ParseInt(chi.URLParam(r, "snapshotID"), 10, 64)
+	if err != nil {
+		jsonError(w, http.StatusNotFound, "not found")
+		return
+	}
+	if !middleware.AuthorizeTournament(w, r, a.DB, id, models.TierAdmin) {
+		return
+	}
+	err = engine.RollBack(r.Context(), a.DB, id, snapshotID)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		jsonError(w, http.StatusNotFound, "not found")
+		return
+	case errors.Is(err, engine.ErrInvalidBackup), errors.Is(err, db.ErrBackupUserConflict):
+		jsonError(w, http.StatusConflict, err.Error())
+		return
+	case err != nil:
+		jsonError(w, http.StatusInternalServerError, "failed to roll back")
+		return
+	}
+	t, err := db.GetTournament(r.Context(), a.DB, id)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, "failed to load tournament")
+		return
+	}
+	jsonResponse(w, http.StatusOK, t)
+}
@@ -0,0 +1,62 @@
+//go:build integration
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+func TestSnapshotsAPI(t *testing.T) {
+	database := testDB(t)
+	api := &SnapshotsAPI{DB: database}
+	owner, tourn := startedTournament(t, database)
+	stranger := mustCreateUser(t, database, "stranger@example.com", "Stranger")
+	params := map[string]string{"id": strconv.FormatInt(tourn.ID, 10)}
+
+	rec := httptest.NewRecorder()
+	(&RoundsAPI{DB: database}).NextRound(rec, requestWithUser("POST", "/", "", owner, params))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("next round: status %d body %s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	api.List(rec, requestWithUser("GET", "/", "", stranger, params))
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("stranger: status %d, want 403", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	api.List(rec, requestWithUser("GET", "/", "", owner, params))
+	var snaps []models.TournamentSnapshot
+	json.NewDecoder(rec.Body).Decode(&snaps)
+	if rec.Code != http.StatusOK || len(snaps) != 2 || snaps[1].Round != 1 {
+		t.Fatalf("list: status %d, snapshots %+v", rec.Code, snaps)
+	}
+
+	snapParams := map[string]string{"id": params["id"], "snapshotID": strconv.FormatInt(snaps[1].ID, 10)}
+	rec = httptest.NewRecorder()
+	api.Get(rec, requestWithUser("GET", "/", "", owner, snapParams))
+	var backup struct {
+		Version int `json:"openswiss_backup"`
+	}
+	json.NewDecoder(rec.Body).Decode(&backup)
+	if rec.Code != http.StatusOK || backup.Version == 0 {
+		t.Errorf("get: status %d, backup %+v", rec.Code, backup)
+	}
+
+	rec = httptest.NewRecorder()
+	api.RollBack(rec, requestWithUser("POST", "/", "", owner, snapParams))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("roll back: status %d body %s", rec.Code, rec.Body.String())
+	}
+	rec = httptest.NewRecorder()
+	api.RollBack(rec, requestWithUser("POST", "/", "", owner, map[string]string{"id": params["id"], "snapshotID": "999999"}))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("missing snapshot: status %d, want 404", rec.Code)
+	}
+}
@@ -240,7 +240,7 @@ func (a *StaffAPI) resolveTarget(w http.ResponseWriter, r *http.Request, userID
 }
 
 func (a *StaffAPI) sendGrantEmail(target, granter *models.User, t *models.Tournament, tier models.TournamentTier) {
-	if a.Email == nil || !a.Email.Config.Enabled() {
+	if a.Email == nil || !a.Email.Config.Enabled() || !target.Notifies(models.NotifyEmail) {
 		return
 	}
 	url := fmt.Sprintf("%s/tournaments/%d", a.BaseURL, t.ID)
@@ -0,0 +1,67 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/dstathis/openswiss/internal/engine"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/dstathis/openswiss/internal/stripe"
+)
+
+// StripeMethod is the payment method noted for entry fees paid online.
+const StripeMethod = "Stripe"
+
+// StripeAPI receives Stripe's webhook events and marks players paid once
+// their Checkout payment goes through.
+type StripeAPI struct {
+	DB            *sql.DB
+	WebhookSecret string
+	// Now is the clock signatures are checked against; nil means time.Now.
+	Now func() time.Time
+}
+
+// Webhook handles one signed event. Events other than a paid entry fee are
+// acknowledged and ignored. A registration removed since is logged and
+// acknowledged too, so Stripe stops retrying; any other failure is a 500,
+// which Stripe retries.
+func (a *StripeAPI) Webhook(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, "bad request")
+		return
+	}
+	now := time.Now
+	if a.Now != nil {
+		now = a.Now
+	}
+	if err := stripe.VerifySignature(body, r.Header.Get("Stripe-Signature"), a.WebhookSecret, now()); err != nil {
+		jsonError(w, http.StatusUnauthorized, "invalid request signature")
+		return
+	}
+	p, err := stripe.ParsePayment(body)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	if p != nil {
+		method := StripeMethod
+		_, err := engine.SetPayment(r.Context(), a.DB, p.TournamentID, p.RegistrationID,
+			engine.Payment{Status: models.PaymentPaid, Amount: &p.Amount, Method: &method})
+		switch {
+		case errors.Is(err, engine.ErrNotRegistered) || errors.Is(err, sql.ErrNoRows):
+			slog.WarnContext(r.Context(), "stripe payment for a missing registration",
+				"tournament_id", p.TournamentID, "registration_id", p.RegistrationID, "amount", p.Amount)
+		case err != nil:
+			slog.ErrorContext(r.Context(), "record stripe payment", "err", err,
+				"tournament_id", p.TournamentID, "registration_id", p.RegistrationID)
+			jsonError(w, http.StatusInternalServerError, "failed to record payment")
+			return
+		}
+	}
+	jsonResponse(w, http.StatusOK, map[string]bool{"received": true})
+}
@@ -0,0 +1,68 @@
+//go:build integration
+
+package api
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+func TestStripeAPI_Webhook(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	now := time.Unix(1_700_000_000, 0)
+	api := &StripeAPI{DB: database, WebhookSecret: "whsec", Now: func() time.Time { return now }}
+	owner := mustCreateUser(t, database, "owner-stripe@example.com", "OwnerStripe")
+	tourn := mustCreateTournament(t, database, owner.ID, models.TournamentStatusRegistrationOpen)
+	tourn.EntryFee = 1000
+	if err := db.UpdateTournament(ctx, database, tourn); err != nil {
+		t.Fatal(err)
+	}
+	reg, _ := db.CreateGuestRegistration(ctx, database, tourn.ID, "Online")
+
+	send := func(regID int64, secret string) *httptest.ResponseRecorder {
+		payload := fmt.Sprintf(`{"type": "checkout.session.completed", "data": {"object": {
+			"payment_status": "paid", "amount_total": 1000, "currency": "usd",
+			"metadata": {"tournament": "%d", "registration": "%d"}}}}`, tourn.ID, regID)
+		mac := hmac.New(sha256.New, []byte(secret))
+		fmt.Fprintf(mac, "%d.%s", now.Unix(), payload)
+		req := httptest.NewRequest("POST", "/api/v1/stripe/webhook", strings.NewReader(payload))
+		req.Header.Set("Stripe-Signature", fmt.Sprintf("t=%d,v1=%s", now.Unix(), hex.EncodeToString(mac.Sum(nil))))
+		rec := httptest.NewRecorder()
+		api.Webhook(rec, req)
+		return rec
+	}
+
+	if rec := send(reg.ID, "forged"); rec.Code != http.StatusUnauthorized {
+		t.Errorf("forged event: status %d, want 401", rec.Code)
+	}
+	if got, _ := db.GetRegistrationByID(ctx, database, reg.ID); got.PaymentStatus != models.PaymentUnpaid {
+		t.Errorf("forged event marked %q", got.PaymentStatus)
+	}
+
+	if rec := send(reg.ID, "whsec"); rec.Code != http.StatusOK {
+		t.Fatalf("status %d body %s", rec.Code, rec.Body.String())
+	}
+	got, _ := db.GetRegistrationByID(ctx, database, reg.ID)
+	if got.PaymentStatus != models.PaymentPaid || got.PaymentAmount == nil || *got.PaymentAmount != 1000 || got.PaymentMethod == nil || *got.PaymentMethod != StripeMethod {
+		t.Errorf("registration = %+v, want paid 1000 through Stripe", got)
+	}
+	// Stripe may deliver an event twice; so may a removed player's.
+	if rec := send(reg.ID, "whsec"); rec.Code != http.StatusOK {
+		t.Errorf("repeat: status %d", rec.Code)
+	}
+	if rec := send(reg.ID+1000, "whsec"); rec.Code != http.StatusOK {
+		t.Errorf("missing registration: status %d, want 200 so Stripe stops", rec.Code)
+	}
+}
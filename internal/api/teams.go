@@ -0,0 +1,127 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/engine"
+	"github.com/dstathis/openswiss/internal/middleware"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/dstathis/swisstools"
+	"github.com/go-chi/chi/v5"
+)
+
+// SetTeamMembers replaces a team's roster. Body: {"members": [...]}, one
+// name per seat in seat order. Returns the registration. Min tier:
+// Co-organizer.
+func (a *PlayersAPI) SetTeamMembers(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	regID, _ := strconv.ParseInt(chi.URLParam(r, "regID"), 10, 64)
+	if !middleware.AuthorizeTournament(w, r, a.DB, id, models.TierCoOrganizer) {
+		return
+	}
+	var body struct {
+		Members []string `json:"members"`
+	}
+	if err := decodeJSON(r, &body); err != nil {
+		jsonError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if err := engine.SetTeamMembers(r.Context(), a.DB, id, regID, body.Members); err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	reg, err := db.GetRegistrationByID(r.Context(), a.DB, regID)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, "failed to load registration")
+		return
+	}
+	jsonResponse(w, http.StatusOK, reg)
+}
+
+// ReportSeat records one seat of the team match at a table of the current
+// round. Body: {"winner": "a"|"b"|"draw"}; an empty winner clears the seat.
+// The match result is written once every seat is in. Min tier: Judge.
+func (a *RoundsAPI) ReportSeat(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	table, err := strconv.Atoi(chi.URLParam(r, "table"))
+	if err != nil || table < 1 {
+		jsonError(w, http.StatusNotFound, "not found")
+		return
+	}
+	seat, err := strconv.Atoi(chi.URLParam(r, "seat"))
+	if err != nil {
+		jsonError(w, http.StatusNotFound, "not found")
+		return
+	}
+	if !middleware.AuthorizeTournament(w, r, a.DB, id, models.TierJudge) {
+		return
+	}
+	var req struct {
+		Winner string `json:"winner"`
+	}
+	if err := decodeJSON(r, &req); err != nil {
+		jsonError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	user := middleware.GetUser(r.Context())
+	s := &models.SeatResult{Table: table, Seat: seat, Winner: req.Winner, ReportedBy: &user.ID}
+	err = engine.WithTournamentEngine(r.Context(), a.DB, id,
+		func(tx *sql.Tx, t *models.Tournament, eng *swisstools.Tournament) (string, error) {
+			return "", engine.ReportSeat(r.Context(), tx, t, eng, s)
+		})
+	if err != nil {
+		engineError(w, err)
+		return
+	}
+	if s.Winner == "" {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	jsonResponse(w, http.StatusOK, s)
+}
+
+// GetIndividualStandings returns a team event's standings by player, tallied
+// from the seat results (see engine.IndividualStandings). Supports ?q= and
+// paging like the standings. 400 for an individual event, and hidden like
+// the standings in a blind final round.
+func (a *RoundsAPI) GetIndividualStandings(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	t, err := db.GetTournament(r.Context(), a.DB, id)
+	if err != nil {
+		jsonError(w, http.StatusNotFound, "not found")
+		return
+	}
+	if t.TeamSize == 0 {
+		jsonError(w, http.StatusBadRequest, "tournament is not a team event")
+		return
+	}
+	if t.EngineState == nil {
+		jsonResponse(w, http.StatusOK, []interface{}{})
+		return
+	}
+	eng, err := swisstools.LoadTournament(t.EngineState)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, "failed to load engine state")
+		return
+	}
+	if hidesStandings(r, a.DB, t, &eng) {
+		jsonError(w, http.StatusForbidden, "standings are hidden until the final round ends")
+		return
+	}
+	regs, err := db.ListRegistrations(r.Context(), a.DB, id)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, "failed to list registrations")
+		return
+	}
+	results, err := db.ListAllSeatResults(r.Context(), a.DB, id)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, "failed to list seat results")
+		return
+	}
+	standings := filterRows(engine.IndividualStandings(t, &eng, regs, results), nameFilter(r),
+		func(s engine.IndividualStanding) []string { return []string{s.Name, s.Team} })
+	jsonResponse(w, http.StatusOK, pageRows(w, r, standings))
+}
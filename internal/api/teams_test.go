@@ -0,0 +1,123 @@
+//go:build integration
+
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/engine"
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+// freshTeamEvent turns freshStarted's tournament into a team event of pairs,
+// each player's registration standing in for a team.
+func freshTeamEvent(t *testing.T, database *sql.DB) (*models.User, *models.Tournament) {
+	t.Helper()
+	ctx := context.Background()
+	owner, tourn := freshStarted(t, database)
+	tourn.TeamSize = 2
+	if err := db.UpdateTournament(ctx, database, tourn); err != nil {
+		t.Fatalf("make team event: %v", err)
+	}
+	regs, _ := db.ListRegistrations(ctx, database, tourn.ID)
+	for i, reg := range regs {
+		members := []string{fmt.Sprintf("Lead %d", i), fmt.Sprintf("Second %d", i)}
+		if err := db.SetTeamMembers(ctx, database, tourn.ID, reg.ID, members); err != nil {
+			t.Fatalf("roster %d: %v", i, err)
+		}
+	}
+	return owner, tourn
+}
+
+func TestRoundsAPI_ReportSeat(t *testing.T) {
+	database := testDB(t)
+	api := &RoundsAPI{DB: database}
+	owner, tourn := freshTeamEvent(t, database)
+	id := strconv.FormatInt(tourn.ID, 10)
+
+	for seat, want := range map[string]int{"1": http.StatusOK, "2": http.StatusOK, "3": http.StatusBadRequest} {
+		rec := httptest.NewRecorder()
+		api.ReportSeat(rec, requestWithUser("PUT", "/", `{"winner":"a"}`, owner,
+			map[string]string{"id": id, "table": "1", "seat": seat}))
+		if rec.Code != want {
+			t.Errorf("seat %s: status %d, want %d (%s)", seat, rec.Code, want, rec.Body.String())
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	api.GetCurrentRound(rec, requestWithUser("GET", "/", "", nil, map[string]string{"id": id}))
+	var round struct {
+		Pairings []pairingResponse `json:"pairings"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &round); err != nil {
+		t.Fatalf("decode round: %v", err)
+	}
+	if p := round.Pairings[0]; len(p.Seats) != 2 || p.PlayerAWins != 2 || p.PlayerBWins != 0 {
+		t.Errorf("pairing = %+v", p)
+	}
+
+	rec = httptest.NewRecorder()
+	api.ReportSeat(rec, requestWithUser("PUT", "/", `{"winner":""}`, owner,
+		map[string]string{"id": id, "table": "1", "seat": "2"}))
+	if rec.Code != http.StatusNoContent {
+		t.Errorf("clear seat: status %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	api.GetIndividualStandings(rec, requestWithUser("GET", "/", "", nil, map[string]string{"id": id}))
+	var standings []engine.IndividualStanding
+	if err := json.Unmarshal(rec.Body.Bytes(), &standings); err != nil {
+		t.Fatalf("decode standings: %v", err)
+	}
+	if len(standings) != 8 || standings[0].Wins != 1 || standings[0].Seat != 1 {
+		t.Errorf("individual standings = %+v", standings)
+	}
+}
+
+func TestRoundsAPI_IndividualStandings_NotTeamEvent(t *testing.T) {
+	database := testDB(t)
+	api := &RoundsAPI{DB: database}
+	_, tourn := freshStarted(t, database)
+
+	rec := httptest.NewRecorder()
+	api.GetIndividualStandings(rec, requestWithUser("GET", "/", "", nil, map[string]string{"id": strconv.FormatInt(tourn.ID, 10)}))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status %d, want 400", rec.Code)
+	}
+}
+
+func TestPlayersAPI_SetTeamMembers(t *testing.T) {
+	database := testDB(t)
+	api := &PlayersAPI{DB: database}
+	ctx := context.Background()
+	owner, tourn := freshTeamEvent(t, database)
+	regs, _ := db.ListRegistrations(ctx, database, tourn.ID)
+	params := map[string]string{"id": strconv.FormatInt(tourn.ID, 10), "regID": strconv.FormatInt(regs[0].ID, 10)}
+
+	rec := httptest.NewRecorder()
+	api.SetTeamMembers(rec, requestWithUser("PUT", "/", `{"members":["Ann","Bo"]}`, owner, params))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d body %s", rec.Code, rec.Body.String())
+	}
+	var got models.Registration
+	json.NewDecoder(rec.Body).Decode(&got)
+	if len(got.Members) != 2 || got.Members[1] != "Bo" {
+		t.Errorf("members = %v", got.Members)
+	}
+
+	for _, body := range []string{`{"members":["Ann"]}`, `{"members":["Ann","ann"]}`, `not-json`} {
+		rec = httptest.NewRecorder()
+		api.SetTeamMembers(rec, requestWithUser("PUT", "/", body, owner, params))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status %d, want 400", body, rec.Code)
+		}
+	}
+}
@@ -50,7 +50,7 @@ func testDB(t *testing.T) *sql.DB {
 		t.Fatalf("run migrations: %v", err)
 	}
 
-	for _, table := range []string{"password_resets", "registrations", "api_keys", "sessions", "tournaments", "users"} {
+	for _, table := range []string{"password_resets", "registrations", "players", "tournament_presets", "leagues", "api_keys", "sessions", "tournaments", "users"} {
 		if _, err := database.Exec(fmt.Sprintf("DELETE FROM %s", table)); err != nil {
 			t.Fatalf("clean table %s: %v", table, err)
 		}
@@ -3,7 +3,7 @@ package api
 import (
 	"database/sql"
 	"encoding/json"
-	"fmt"
+	"io"
 	"net/http"
 	"strconv"
 
@@ -17,6 +17,9 @@ import (
 
 type TournamentAPI struct {
 	DB *sql.DB
+	// Defaults are what a new tournament's settings start as; nil means
+	// models.StandardDefaults.
+	Defaults *models.TournamentDefaults
 }
 
 func (a *TournamentAPI) List(w http.ResponseWriter, r *http.Request) {
@@ -49,23 +52,97 @@ func (a *TournamentAPI) Get(w http.ResponseWriter, r *http.Request) {
 
 func (a *TournamentAPI) Create(w http.ResponseWriter, r *http.Request) {
 	user := middleware.GetUser(r.Context())
+	defaults := models.StandardDefaults
+	if a.Defaults != nil {
+		defaults = *a.Defaults
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		jsonError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	// With a preset_id, the preset's settings stand in for the defaults
+	// and its pairing fields are added.
+	var from struct {
+		PresetID int64 `json:"preset_id"`
+	}
+	if err := json.Unmarshal(body, &from); err != nil {
+		jsonError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	// Fields the request leaves out keep their defaults.
 	var t models.Tournament
-	if err := json.NewDecoder(r.Body).Decode(&t); err != nil {
+	defaults.Apply(&t)
+	var fields []models.PresetField
+	if from.PresetID != 0 {
+		preset, err := db.GetPreset(r.Context(), a.DB, from.PresetID)
+		if err != nil {
+			jsonError(w, http.StatusBadRequest, "preset_id: no such preset")
+			return
+		}
+		preset.Settings.Apply(&t)
+		fields = preset.Settings.PairingFields
+	}
+	if err := json.Unmarshal(body, &t); err != nil {
 		jsonError(w, http.StatusBadRequest, "invalid request body")
 		return
 	}
 	t.OrganizerID = user.ID
+	// Pods are added under their event with POST .../pods.
+	t.ParentID = nil
+	if t.PairingAlgorithm != "" && !models.ValidPairingAlgorithm(t.PairingAlgorithm) {
+		jsonError(w, http.StatusBadRequest, "pairing_algorithm must be swiss, weighted, round_robin or danish")
+		return
+	}
+	if t.ByePolicy != "" && !models.ValidByePolicy(t.ByePolicy) {
+		jsonError(w, http.StatusBadRequest, "bye_policy must be lowest or random")
+		return
+	}
+	if t.Round1Pairing != "" && !models.ValidRound1Pairing(t.Round1Pairing) {
+		jsonError(w, http.StatusBadRequest, "round1_pairing must be random, cross or fold")
+		return
+	}
+	if t.MinPlayers == 0 {
+		t.MinPlayers = models.DefaultMinPlayers
+	}
+	if t.TimeZone == "" {
+		t.TimeZone = defaults.TimeZone
+	}
+	if err := t.ValidatePlayerLimits(); err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := t.ValidateTimeZone(); err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := t.ValidateTiebreakers(); err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := t.ValidateClubRounds(); err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := t.ValidateEntryFee(); err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := t.ValidateMatchFormat(); err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 	if t.Status == "" {
 		t.Status = models.TournamentStatusScheduled
 	}
 	if t.PointsWin == 0 {
-		t.PointsWin = 3
+		t.PointsWin = defaults.PointsWin
 	}
 	if t.PointsDraw == 0 {
-		t.PointsDraw = 1
+		t.PointsDraw = defaults.PointsDraw
 	}
 
-	if err := db.CreateTournament(r.Context(), a.DB, &t); err != nil {
+	if err := db.CreateTournamentWithFields(r.Context(), a.DB, &t, fields); err != nil {
 		jsonError(w, http.StatusInternalServerError, "failed to create tournament")
 		return
 	}
@@ -87,7 +164,26 @@ func (a *TournamentAPI) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var update models.Tournament
+	// best_of, series_mode, team_size, pod_advance, preview_pairings,
+	// colors, avoid_club_rounds, auto_rounds, entry_fee, self_report,
+	// report_confirm_minutes, bot_check and blind_final_round are pointers
+	// so they can be set back to their zero values.
+	var update struct {
+		models.Tournament
+		BestOf               *int  `json:"best_of"`
+		SeriesMode           *bool `json:"series_mode"`
+		TeamSize             *int  `json:"team_size"`
+		PodAdvance           *int  `json:"pod_advance"`
+		PreviewPairings      *bool `json:"preview_pairings"`
+		Colors               *bool `json:"colors"`
+		AvoidClubRounds      *int  `json:"avoid_club_rounds"`
+		AutoRounds           *bool `json:"auto_rounds"`
+		EntryFee             *int  `json:"entry_fee"`
+		SelfReport           *bool `json:"self_report"`
+		ReportConfirmMinutes *int  `json:"report_confirm_minutes"`
+		BotCheck             *bool `json:"bot_check"`
+		BlindFinalRound      *bool `json:"blind_final_round"`
+	}
 	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
 		jsonError(w, http.StatusBadRequest, "invalid request body")
 		return
@@ -105,15 +201,108 @@ func (a *TournamentAPI) Update(w http.ResponseWriter, r *http.Request) {
 	if update.ScheduledAt != nil {
 		t.ScheduledAt = update.ScheduledAt
 	}
+	if update.TimeZone != "" {
+		t.TimeZone = update.TimeZone
+	}
 	if update.MaxPlayers != 0 {
 		t.MaxPlayers = update.MaxPlayers
 	}
+	if update.MinPlayers != 0 {
+		t.MinPlayers = update.MinPlayers
+	}
 	if update.NumRounds != nil {
 		t.NumRounds = update.NumRounds
 	}
 	if update.TopCut != 0 {
 		t.TopCut = update.TopCut
 	}
+	if update.PairingAlgorithm != "" {
+		if !models.ValidPairingAlgorithm(update.PairingAlgorithm) {
+			jsonError(w, http.StatusBadRequest, "pairing_algorithm must be swiss, weighted, round_robin or danish")
+			return
+		}
+		t.PairingAlgorithm = update.PairingAlgorithm
+	}
+	if update.ByePolicy != "" {
+		if !models.ValidByePolicy(update.ByePolicy) {
+			jsonError(w, http.StatusBadRequest, "bye_policy must be lowest or random")
+			return
+		}
+		t.ByePolicy = update.ByePolicy
+	}
+	if update.Round1Pairing != "" {
+		if !models.ValidRound1Pairing(update.Round1Pairing) {
+			jsonError(w, http.StatusBadRequest, "round1_pairing must be random, cross or fold")
+			return
+		}
+		t.Round1Pairing = update.Round1Pairing
+	}
+	if update.BestOf != nil {
+		t.BestOf = *update.BestOf
+	}
+	if update.SeriesMode != nil {
+		t.SeriesMode = *update.SeriesMode
+	}
+	if update.TeamSize != nil {
+		t.TeamSize = *update.TeamSize
+	}
+	if update.PodAdvance != nil {
+		t.PodAdvance = *update.PodAdvance
+	}
+	if update.PreviewPairings != nil {
+		t.PreviewPairings = *update.PreviewPairings
+	}
+	if update.Colors != nil {
+		t.Colors = *update.Colors
+	}
+	if update.AvoidClubRounds != nil {
+		t.AvoidClubRounds = *update.AvoidClubRounds
+	}
+	if update.AutoRounds != nil {
+		t.AutoRounds = *update.AutoRounds
+	}
+	if update.EntryFee != nil {
+		t.EntryFee = *update.EntryFee
+	}
+	if update.SelfReport != nil {
+		t.SelfReport = *update.SelfReport
+	}
+	if update.ReportConfirmMinutes != nil {
+		t.ReportConfirmMinutes = *update.ReportConfirmMinutes
+	}
+	if update.BotCheck != nil {
+		t.BotCheck = *update.BotCheck
+	}
+	if update.BlindFinalRound != nil {
+		t.BlindFinalRound = *update.BlindFinalRound
+	}
+	if update.Tiebreakers != nil {
+		t.Tiebreakers = update.Tiebreakers
+	}
+	if err := t.ValidatePlayerLimits(); err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := t.ValidateTimeZone(); err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := t.ValidateTiebreakers(); err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := t.ValidateClubRounds(); err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := t.ValidateEntryFee(); err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := t.ValidateMatchFormat(); err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
 	if err := db.UpdateTournament(r.Context(), a.DB, t); err != nil {
 		jsonError(w, http.StatusInternalServerError, "failed to update tournament")
@@ -168,8 +357,8 @@ func (a *TournamentAPI) Start(w http.ResponseWriter, r *http.Request) {
 
 	err := engine.WithTournamentEngine(r.Context(), a.DB, id,
 		func(tx *sql.Tx, t *models.Tournament, eng *swisstools.Tournament) (string, error) {
-			if t.Status != models.TournamentStatusRegistrationOpen && t.Status != models.TournamentStatusScheduled {
-				return "", fmt.Errorf("tournament cannot be started from state %s", t.Status)
+			if err := engine.CheckStart(t, regs).Err(); err != nil {
+				return "", err
 			}
 			state, err := engine.InitTournamentEngine(r.Context(), tx, t, regs)
 			if err != nil {
@@ -183,14 +372,70 @@ func (a *TournamentAPI) Start(w http.ResponseWriter, r *http.Request) {
 			return models.TournamentStatusInProgress, nil
 		})
 
+	if err != nil {
+		engineError(w, err)
+		return
+	}
+	t, _ := db.GetTournament(r.Context(), a.DB, id)
+	jsonResponse(w, http.StatusOK, t)
+}
+
+// Import starts the tournament from a CSV, sent as the request body, of the
+// rounds played so far in another tool (see engine.ReadImportCSV). Min
+// tier: Co-organizer.
+func (a *TournamentAPI) Import(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if !middleware.AuthorizeTournament(w, r, a.DB, id, models.TierCoOrganizer) {
+		return
+	}
+	ev, err := engine.ReadImportCSV(r.Body)
 	if err != nil {
 		jsonError(w, http.StatusBadRequest, err.Error())
 		return
 	}
+	regs, _ := db.ListRegistrations(r.Context(), a.DB, id)
+
+	err = engine.WithTournamentEngine(r.Context(), a.DB, id,
+		func(tx *sql.Tx, t *models.Tournament, eng *swisstools.Tournament) (string, error) {
+			state, err := engine.ImportTournament(r.Context(), tx, t, regs, ev)
+			if err != nil {
+				return "", err
+			}
+			newEng, err := swisstools.LoadTournament(state)
+			if err != nil {
+				return "", err
+			}
+			*eng = newEng
+			return models.TournamentStatusInProgress, nil
+		})
+	if err != nil {
+		engineError(w, err)
+		return
+	}
 	t, _ := db.GetTournament(r.Context(), a.DB, id)
 	jsonResponse(w, http.StatusOK, t)
 }
 
+// CanStart reports whether the tournament can be started with its current
+// registrations, and why not. Min tier: Judge.
+func (a *TournamentAPI) CanStart(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if !middleware.AuthorizeTournament(w, r, a.DB, id, models.TierJudge) {
+		return
+	}
+	t, err := db.GetTournament(r.Context(), a.DB, id)
+	if err != nil {
+		jsonError(w, http.StatusNotFound, "not found")
+		return
+	}
+	regs, err := db.ListRegistrations(r.Context(), a.DB, id)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, "failed to list registrations")
+		return
+	}
+	jsonResponse(w, http.StatusOK, engine.CheckStart(t, regs))
+}
+
 func (a *TournamentAPI) Finish(w http.ResponseWriter, r *http.Request) {
 	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
 	if !middleware.AuthorizeTournament(w, r, a.DB, id, models.TierCoOrganizer) {
@@ -199,6 +444,9 @@ func (a *TournamentAPI) Finish(w http.ResponseWriter, r *http.Request) {
 
 	err := engine.WithTournamentEngine(r.Context(), a.DB, id,
 		func(tx *sql.Tx, t *models.Tournament, eng *swisstools.Tournament) (string, error) {
+			if t.PairingsDraft(eng.GetCurrentRound()) {
+				return "", engine.ErrPairingsDraft
+			}
 			if err := eng.FinishTournament(); err != nil {
 				return "", err
 			}
@@ -206,7 +454,7 @@ func (a *TournamentAPI) Finish(w http.ResponseWriter, r *http.Request) {
 		})
 
 	if err != nil {
-		jsonError(w, http.StatusBadRequest, err.Error())
+		engineError(w, err)
 		return
 	}
 	t, _ := db.GetTournament(r.Context(), a.DB, id)
@@ -5,12 +5,15 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/engine"
 	"github.com/dstathis/openswiss/internal/models"
 )
 
@@ -125,6 +128,36 @@ func TestTournamentAPI_Create(t *testing.T) {
 	if got.PointsDraw != 1 {
 		t.Errorf("points_draw default = %d, want 1", got.PointsDraw)
 	}
+	if strings.Join(got.Tiebreakers, ",") != "omw,gw,ogw" {
+		t.Errorf("tiebreakers default = %v", got.Tiebreakers)
+	}
+}
+
+func TestTournamentAPI_Create_Defaults(t *testing.T) {
+	database := testDB(t)
+	api := &TournamentAPI{DB: database, Defaults: &models.TournamentDefaults{
+		TimeZone: "Europe/Berlin", MaxPlayers: 24, PointsWin: 2, PointsDraw: 1, Tiebreakers: []string{models.TiebreakGW},
+	}}
+	user := mustCreateUser(t, database, "creator@example.com", "Creator", models.RoleOrganizer)
+
+	r := requestWithUser("POST", "/api/v1/tournaments", `{"name":"Spring Open","points_draw":0,"tiebreakers":["ogw"]}`, user, nil)
+	rec := httptest.NewRecorder()
+	api.Create(rec, r)
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want 201: %s", rec.Code, rec.Body)
+	}
+	var got models.Tournament
+	json.NewDecoder(rec.Body).Decode(&got)
+	if got.TimeZone != "Europe/Berlin" || got.MaxPlayers != 24 || got.PointsWin != 2 {
+		t.Errorf("defaults not applied: %+v", got)
+	}
+	if strings.Join(got.Tiebreakers, ",") != "ogw" {
+		t.Errorf("tiebreakers = %v, want the request's", got.Tiebreakers)
+	}
+	if api.Defaults.Tiebreakers[0] != models.TiebreakGW {
+		t.Errorf("request changed the defaults: %v", api.Defaults.Tiebreakers)
+	}
 }
 
 func TestTournamentAPI_Create_InvalidJSON(t *testing.T) {
@@ -165,6 +198,163 @@ func TestTournamentAPI_Update(t *testing.T) {
 	}
 }
 
+func TestTournamentAPI_Update_SeriesMode(t *testing.T) {
+	database := testDB(t)
+	api := &TournamentAPI{DB: database}
+	user := mustCreateUser(t, database, "owner@example.com", "Owner")
+	tourn := mustCreateTournament(t, database, user.ID, models.TournamentStatusScheduled)
+	params := map[string]string{"id": strconv.FormatInt(tourn.ID, 10)}
+
+	rec := httptest.NewRecorder()
+	api.Update(rec, requestWithUser("PATCH", "/", `{"series_mode":true}`, user, params))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("series mode without best_of: status %d, want 400", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	api.Update(rec, requestWithUser("PATCH", "/", `{"best_of":3,"series_mode":true}`, user, params))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body=%s", rec.Code, rec.Body.String())
+	}
+	got, _ := db.GetTournament(context.Background(), database, tourn.ID)
+	if got.BestOf != 3 || !got.SeriesMode {
+		t.Errorf("match format = Bo%d series=%v", got.BestOf, got.SeriesMode)
+	}
+
+	rec = httptest.NewRecorder()
+	api.Update(rec, requestWithUser("PATCH", "/", `{"series_mode":false}`, user, params))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("turn off: status = %d, body=%s", rec.Code, rec.Body.String())
+	}
+	got, _ = db.GetTournament(context.Background(), database, tourn.ID)
+	if got.SeriesMode || got.BestOf != 3 {
+		t.Errorf("after turning off: Bo%d series=%v", got.BestOf, got.SeriesMode)
+	}
+}
+
+func TestTournamentAPI_Update_Tiebreakers(t *testing.T) {
+	database := testDB(t)
+	api := &TournamentAPI{DB: database}
+	user := mustCreateUser(t, database, "owner@example.com", "Owner")
+	tourn := mustCreateTournament(t, database, user.ID, models.TournamentStatusScheduled)
+	params := map[string]string{"id": strconv.FormatInt(tourn.ID, 10)}
+
+	rec := httptest.NewRecorder()
+	api.Update(rec, requestWithUser("PATCH", "/", `{"tiebreakers":["omw","sb"]}`, user, params))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("unknown tiebreaker: status %d, want 400", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	api.Update(rec, requestWithUser("PATCH", "/", `{"tiebreakers":["gw","omw"]}`, user, params))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body=%s", rec.Code, rec.Body.String())
+	}
+	got, _ := db.GetTournament(context.Background(), database, tourn.ID)
+	if strings.Join(got.Tiebreakers, ",") != "gw,omw" {
+		t.Errorf("tiebreakers = %v, want gw,omw", got.Tiebreakers)
+	}
+
+	// Leaving them out keeps them.
+	rec = httptest.NewRecorder()
+	api.Update(rec, requestWithUser("PATCH", "/", `{"name":"Renamed"}`, user, params))
+	got, _ = db.GetTournament(context.Background(), database, tourn.ID)
+	if strings.Join(got.Tiebreakers, ",") != "gw,omw" {
+		t.Errorf("after rename: tiebreakers = %v", got.Tiebreakers)
+	}
+}
+
+func TestTournamentAPI_Update_Colors(t *testing.T) {
+	database := testDB(t)
+	api := &TournamentAPI{DB: database}
+	user := mustCreateUser(t, database, "owner@example.com", "Owner")
+	tourn := mustCreateTournament(t, database, user.ID, models.TournamentStatusScheduled)
+	params := map[string]string{"id": strconv.FormatInt(tourn.ID, 10)}
+
+	for _, on := range []bool{true, false} {
+		rec := httptest.NewRecorder()
+		api.Update(rec, requestWithUser("PATCH", "/", fmt.Sprintf(`{"colors":%v}`, on), user, params))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, body=%s", rec.Code, rec.Body.String())
+		}
+		if got, _ := db.GetTournament(context.Background(), database, tourn.ID); got.Colors != on {
+			t.Errorf("colors = %v, want %v", got.Colors, on)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	api.Update(rec, requestWithUser("PATCH", "/", `{"colors":true,"team_size":2}`, user, params))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("team event with colours: status %d, want 400", rec.Code)
+	}
+}
+
+func TestTournamentAPI_Update_AvoidClubRounds(t *testing.T) {
+	database := testDB(t)
+	api := &TournamentAPI{DB: database}
+	user := mustCreateUser(t, database, "owner@example.com", "Owner")
+	tourn := mustCreateTournament(t, database, user.ID, models.TournamentStatusScheduled)
+	params := map[string]string{"id": strconv.FormatInt(tourn.ID, 10)}
+
+	rec := httptest.NewRecorder()
+	api.Update(rec, requestWithUser("PATCH", "/", `{"avoid_club_rounds":2}`, user, params))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body=%s", rec.Code, rec.Body.String())
+	}
+	if got, _ := db.GetTournament(context.Background(), database, tourn.ID); got.AvoidClubRounds != 2 {
+		t.Errorf("avoid_club_rounds = %d, want 2", got.AvoidClubRounds)
+	}
+
+	rec = httptest.NewRecorder()
+	api.Update(rec, requestWithUser("PATCH", "/", `{"avoid_club_rounds":-1}`, user, params))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("negative rounds: status %d, want 400", rec.Code)
+	}
+}
+
+func TestTournamentAPI_Update_AutoRounds(t *testing.T) {
+	database := testDB(t)
+	api := &TournamentAPI{DB: database}
+	user := mustCreateUser(t, database, "owner@example.com", "Owner")
+	tourn := mustCreateTournament(t, database, user.ID, models.TournamentStatusScheduled)
+	params := map[string]string{"id": strconv.FormatInt(tourn.ID, 10)}
+
+	for _, on := range []bool{true, false} {
+		rec := httptest.NewRecorder()
+		api.Update(rec, requestWithUser("PATCH", "/", fmt.Sprintf(`{"auto_rounds":%v}`, on), user, params))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, body=%s", rec.Code, rec.Body.String())
+		}
+		if got, _ := db.GetTournament(context.Background(), database, tourn.ID); got.AutoRounds != on {
+			t.Errorf("auto_rounds = %v, want %v", got.AutoRounds, on)
+		}
+	}
+}
+
+func TestTournamentAPI_Update_EntryFee(t *testing.T) {
+	database := testDB(t)
+	api := &TournamentAPI{DB: database}
+	user := mustCreateUser(t, database, "owner@example.com", "Owner")
+	tourn := mustCreateTournament(t, database, user.ID, models.TournamentStatusScheduled)
+	params := map[string]string{"id": strconv.FormatInt(tourn.ID, 10)}
+
+	for _, fee := range []int{1250, 0} {
+		rec := httptest.NewRecorder()
+		api.Update(rec, requestWithUser("PATCH", "/", fmt.Sprintf(`{"entry_fee":%d}`, fee), user, params))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, body=%s", rec.Code, rec.Body.String())
+		}
+		if got, _ := db.GetTournament(context.Background(), database, tourn.ID); got.EntryFee != fee {
+			t.Errorf("entry_fee = %d, want %d", got.EntryFee, fee)
+		}
+	}
+	rec := httptest.NewRecorder()
+	api.Update(rec, requestWithUser("PATCH", "/", `{"entry_fee":-1}`, user, params))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("negative fee: status = %d, want 400", rec.Code)
+	}
+}
+
 func TestTournamentAPI_Update_Forbidden(t *testing.T) {
 	database := testDB(t)
 	api := &TournamentAPI{DB: database}
@@ -404,6 +594,61 @@ func TestTournamentAPI_Finish(t *testing.T) {
 	}
 }
 
+func TestTournamentAPI_CanStart(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	api := &TournamentAPI{DB: database}
+	owner := mustCreateUser(t, database, "owner@example.com", "Owner")
+	tourn := mustCreateTournament(t, database, owner.ID, models.TournamentStatusRegistrationOpen)
+	tourn.MinPlayers = 4
+	if err := db.UpdateTournament(ctx, database, tourn); err != nil {
+		t.Fatalf("set min_players: %v", err)
+	}
+	params := map[string]string{"id": strconv.FormatInt(tourn.ID, 10)}
+	for i := 0; i < 3; i++ {
+		u := mustCreateUser(t, database, "p"+strconv.Itoa(i)+"@example.com", "P"+strconv.Itoa(i))
+		if _, err := db.CreateRegistration(ctx, database, tourn.ID, u.ID, u.DisplayName); err != nil {
+			t.Fatalf("register: %v", err)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	api.CanStart(rec, requestWithUser("GET", "/", "", owner, params))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body=%s", rec.Code, rec.Body.String())
+	}
+	var check engine.StartCheck
+	if err := json.Unmarshal(rec.Body.Bytes(), &check); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if check.CanStart || check.Players != 3 || check.MinPlayers != 4 || check.Reason == "" {
+		t.Errorf("check = %+v", check)
+	}
+
+	rec = httptest.NewRecorder()
+	api.Start(rec, requestWithUser("POST", "/", "", owner, params))
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "at least 4") {
+		t.Errorf("Start below minimum: status %d body %s", rec.Code, rec.Body.String())
+	}
+	if got, _ := db.GetTournament(ctx, database, tourn.ID); got.Status != models.TournamentStatusRegistrationOpen {
+		t.Errorf("status = %q after refused start", got.Status)
+	}
+}
+
+func TestTournamentAPI_Update_MinPlayersOverMax(t *testing.T) {
+	database := testDB(t)
+	api := &TournamentAPI{DB: database}
+	user := mustCreateUser(t, database, "owner@example.com", "Owner")
+	tourn := mustCreateTournament(t, database, user.ID, models.TournamentStatusScheduled)
+
+	rec := httptest.NewRecorder()
+	api.Update(rec, requestWithUser("PATCH", "/", `{"max_players":8,"min_players":9}`, user,
+		map[string]string{"id": strconv.FormatInt(tourn.ID, 10)}))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", rec.Code)
+	}
+}
+
 func TestTournamentAPI_Start_Forbidden(t *testing.T) {
 	database := testDB(t)
 	api := &TournamentAPI{DB: database}
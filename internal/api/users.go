@@ -8,6 +8,7 @@ import (
 	"github.com/dstathis/openswiss/internal/auth"
 	"github.com/dstathis/openswiss/internal/db"
 	"github.com/dstathis/openswiss/internal/middleware"
+	"github.com/dstathis/openswiss/internal/models"
 	"github.com/go-chi/chi/v5"
 )
 
@@ -20,28 +21,79 @@ func (a *UsersAPI) GetMe(w http.ResponseWriter, r *http.Request) {
 	jsonResponse(w, http.StatusOK, user)
 }
 
-func (a *UsersAPI) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+// SetNotifications sets how the caller is notified. JSON body:
+// {"notify_by": "email"|"none"}. Returns the user.
+func (a *UsersAPI) SetNotifications(w http.ResponseWriter, r *http.Request) {
 	user := middleware.GetUser(r.Context())
 	var body struct {
-		Name string `json:"name"`
+		NotifyBy string `json:"notify_by"`
 	}
-	if err := decodeJSON(r, &body); err != nil || body.Name == "" {
-		jsonError(w, http.StatusBadRequest, "name is required")
+	if err := decodeJSON(r, &body); err != nil {
+		jsonError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if !models.ValidNotifyChannel(body.NotifyBy) {
+		jsonError(w, http.StatusBadRequest, `notify_by must be "email" or "none"`)
+		return
+	}
+	if err := db.SetUserNotifyBy(r.Context(), a.DB, user.ID, body.NotifyBy); err != nil {
+		jsonError(w, http.StatusInternalServerError, "failed to save notification settings")
 		return
 	}
+	u := *user
+	u.NotifyBy = body.NotifyBy
+	jsonResponse(w, http.StatusOK, &u)
+}
 
-	fullKey, prefix, err := auth.GenerateAPIKey()
-	if err != nil {
-		jsonError(w, http.StatusInternalServerError, "failed to generate key")
+// RequestDeletion asks the admins to delete the caller's personal data
+// (POST), or takes the request back (DELETE). Returns the user.
+func (a *UsersAPI) RequestDeletion(w http.ResponseWriter, r *http.Request) {
+	user := middleware.GetUser(r.Context())
+	requested := r.Method != http.MethodDelete
+	if err := db.SetDeletionRequested(r.Context(), a.DB, user.ID, requested); err != nil {
+		jsonError(w, http.StatusInternalServerError, "failed to save the request")
 		return
 	}
-	hash, err := auth.HashAPIKey(fullKey)
+	u, err := db.GetUserByID(r.Context(), a.DB, user.ID)
 	if err != nil {
-		jsonError(w, http.StatusInternalServerError, "failed to hash key")
+		jsonError(w, http.StatusInternalServerError, "failed to load user")
 		return
 	}
+	jsonResponse(w, http.StatusOK, u)
+}
+
+// CreateAPIKey creates a key for the caller. Body: {"name", "scope"}; the
+// scope is "read" or "read_write" (the default).
+func (a *UsersAPI) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	createAPIKey(w, r, a.DB, middleware.GetUser(r.Context()).ID)
+}
 
-	key, err := db.CreateAPIKey(r.Context(), a.DB, user.ID, hash, prefix, body.Name, nil)
+// createAPIKey creates an API key for userID from a {"name", "scope"} body
+// and returns it, the full key included, with 201. The key is not shown
+// again.
+func createAPIKey(w http.ResponseWriter, r *http.Request, database *sql.DB, userID int64) {
+	var body struct {
+		Name  string `json:"name"`
+		Scope string `json:"scope"`
+	}
+	if err := decodeJSON(r, &body); err != nil || body.Name == "" {
+		jsonError(w, http.StatusBadRequest, "name is required")
+		return
+	}
+	if body.Scope == "" {
+		body.Scope = models.APIKeyScopeReadWrite
+	}
+	if !models.ValidAPIKeyScope(body.Scope) {
+		jsonError(w, http.StatusBadRequest, "scope must be read or read_write")
+		return
+	}
+
+	fullKey, prefix, hash, err := auth.NewAPIKey()
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, "failed to generate key")
+		return
+	}
+	key, err := db.CreateAPIKey(r.Context(), database, userID, hash, prefix, body.Name, body.Scope, nil)
 	if err != nil {
 		jsonError(w, http.StatusInternalServerError, "failed to create key")
 		return
@@ -49,9 +101,11 @@ func (a *UsersAPI) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
 
 	jsonResponse(w, http.StatusCreated, map[string]interface{}{
 		"id":         key.ID,
+		"user_id":    key.UserID,
 		"key":        fullKey,
 		"prefix":     prefix,
 		"name":       key.Name,
+		"scope":      key.Scope,
 		"created_at": key.CreatedAt,
 	})
 }
@@ -3,6 +3,7 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
@@ -35,6 +36,52 @@ func TestUsersAPI_GetMe(t *testing.T) {
 	}
 }
 
+func TestUsersAPI_SetNotifications(t *testing.T) {
+	database := testDB(t)
+	api := &UsersAPI{DB: database}
+	user := mustCreateUser(t, database, "notify@example.com", "Notify")
+
+	rec := httptest.NewRecorder()
+	api.SetNotifications(rec, requestWithUser("PUT", "/", `{"notify_by":"sms"}`, user, nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("unknown channel: status %d, want 400", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	api.SetNotifications(rec, requestWithUser("PUT", "/", `{"notify_by":"none"}`, user, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d body %s", rec.Code, rec.Body.String())
+	}
+	var got models.User
+	json.NewDecoder(rec.Body).Decode(&got)
+	if got.NotifyBy != models.NotifyNone {
+		t.Errorf("response notify_by = %q", got.NotifyBy)
+	}
+	if u, _ := db.GetUserByID(context.Background(), database, user.ID); u.NotifyBy != models.NotifyNone {
+		t.Errorf("stored notify_by = %q", u.NotifyBy)
+	}
+}
+
+func TestUsersAPI_RequestDeletion(t *testing.T) {
+	database := testDB(t)
+	api := &UsersAPI{DB: database}
+	user := mustCreateUser(t, database, "forget@example.com", "Forget")
+
+	rec := httptest.NewRecorder()
+	api.RequestDeletion(rec, requestWithUser("POST", "/", "", user, nil))
+	var got models.User
+	json.NewDecoder(rec.Body).Decode(&got)
+	if rec.Code != http.StatusOK || got.DeletionRequestedAt == nil {
+		t.Fatalf("request: status %d, user %+v", rec.Code, got)
+	}
+	rec = httptest.NewRecorder()
+	api.RequestDeletion(rec, requestWithUser("DELETE", "/", "", user, nil))
+	got = models.User{}
+	json.NewDecoder(rec.Body).Decode(&got)
+	if rec.Code != http.StatusOK || got.DeletionRequestedAt != nil {
+		t.Errorf("withdraw: status %d, user %+v", rec.Code, got)
+	}
+}
+
 func TestUsersAPI_CreateAPIKey(t *testing.T) {
 	database := testDB(t)
 	api := &UsersAPI{DB: database}
@@ -51,8 +98,8 @@ func TestUsersAPI_CreateAPIKey(t *testing.T) {
 	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
 		t.Fatalf("decode: %v", err)
 	}
-	if resp["name"] != "My Key" {
-		t.Errorf("name = %v, want My Key", resp["name"])
+	if resp["name"] != "My Key" || resp["scope"] != models.APIKeyScopeReadWrite {
+		t.Errorf("name = %v, scope = %v; want My Key, read_write", resp["name"], resp["scope"])
 	}
 	keyStr, ok := resp["key"].(string)
 	if !ok || keyStr == "" {
@@ -108,7 +155,7 @@ func TestUsersAPI_ListAPIKeys(t *testing.T) {
 	for _, name := range []string{"k1", "k2"} {
 		full, prefix, _ := auth.GenerateAPIKey()
 		hash, _ := auth.HashAPIKey(full)
-		if _, err := db.CreateAPIKey(req(t).Context(), database, user.ID, hash, prefix, name, nil); err != nil {
+		if _, err := db.CreateAPIKey(req(t).Context(), database, user.ID, hash, prefix, name, models.APIKeyScopeReadWrite, nil); err != nil {
 			t.Fatalf("seed key: %v", err)
 		}
 	}
@@ -136,7 +183,7 @@ func TestUsersAPI_DeleteAPIKey(t *testing.T) {
 
 	full, prefix, _ := auth.GenerateAPIKey()
 	hash, _ := auth.HashAPIKey(full)
-	key, err := db.CreateAPIKey(req(t).Context(), database, user.ID, hash, prefix, "k", nil)
+	key, err := db.CreateAPIKey(req(t).Context(), database, user.ID, hash, prefix, "k", models.APIKeyScopeReadWrite, nil)
 	if err != nil {
 		t.Fatalf("create key: %v", err)
 	}
@@ -162,7 +209,7 @@ func TestUsersAPI_DeleteAPIKey_WrongUser(t *testing.T) {
 
 	full, prefix, _ := auth.GenerateAPIKey()
 	hash, _ := auth.HashAPIKey(full)
-	key, err := db.CreateAPIKey(req(t).Context(), database, owner.ID, hash, prefix, "k", nil)
+	key, err := db.CreateAPIKey(req(t).Context(), database, owner.ID, hash, prefix, "k", models.APIKeyScopeReadWrite, nil)
 	if err != nil {
 		t.Fatalf("create key: %v", err)
 	}
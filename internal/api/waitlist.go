@@ -0,0 +1,35 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/engine"
+	"github.com/dstathis/openswiss/internal/middleware"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/go-chi/chi/v5"
+)
+
+// PromoteWaitlisted takes a registration off the waitlist and returns it,
+// now pending or confirmed. 400 once the tournament has started; 409 if the
+// registration isn't waitlisted. Min tier: Co-organizer.
+func (a *PlayersAPI) PromoteWaitlisted(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	regID, _ := strconv.ParseInt(chi.URLParam(r, "regID"), 10, 64)
+	if !middleware.AuthorizeTournament(w, r, a.DB, id, models.TierCoOrganizer) {
+		return
+	}
+	reg, err := engine.PromoteWaitlisted(r.Context(), a.DB, id, regID)
+	switch {
+	case errors.Is(err, engine.ErrWaitlistClosed):
+		jsonError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, db.ErrNotWaitlisted):
+		jsonError(w, http.StatusConflict, err.Error())
+	case err != nil:
+		jsonError(w, http.StatusInternalServerError, "failed to promote player")
+	default:
+		jsonResponse(w, http.StatusOK, reg)
+	}
+}
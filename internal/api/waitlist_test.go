@@ -0,0 +1,48 @@
+//go:build integration
+
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+func TestPlayersAPI_PromoteWaitlisted(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	api := &PlayersAPI{DB: database}
+	owner := mustCreateUser(t, database, "owner-waitlist@example.com", "OwnerWaitlist")
+	tourn := mustCreateTournament(t, database, owner.ID, models.TournamentStatusRegistrationOpen)
+	first := mustCreateUser(t, database, "first-waitlist@example.com", "FirstWaitlist")
+	second := mustCreateUser(t, database, "second-waitlist@example.com", "SecondWaitlist")
+	db.RegisterUser(ctx, database, tourn.ID, first.ID, first.DisplayName, models.RegistrationStatusConfirmed, 1)
+	waiting, err := db.RegisterUser(ctx, database, tourn.ID, second.ID, second.DisplayName, models.RegistrationStatusConfirmed, 1)
+	if err != nil || waiting.Status != models.RegistrationStatusWaitlisted {
+		t.Fatalf("second sign-up = %+v, %v; want waitlisted", waiting, err)
+	}
+	promote := func(user *models.User) int {
+		params := map[string]string{"id": strconv.FormatInt(tourn.ID, 10), "regID": strconv.FormatInt(waiting.ID, 10)}
+		rec := httptest.NewRecorder()
+		api.PromoteWaitlisted(rec, requestWithUser("POST", "/", "", user, params))
+		return rec.Code
+	}
+
+	if code := promote(first); code != http.StatusForbidden {
+		t.Errorf("player: status %d, want 403", code)
+	}
+	if code := promote(owner); code != http.StatusOK {
+		t.Fatalf("status %d", code)
+	}
+	if got, _ := db.GetRegistrationByID(ctx, database, waiting.ID); got.Status != models.RegistrationStatusConfirmed {
+		t.Errorf("status = %q, want confirmed", got.Status)
+	}
+	if code := promote(owner); code != http.StatusConflict {
+		t.Errorf("promoted twice: status %d, want 409", code)
+	}
+}
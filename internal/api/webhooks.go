@@ -0,0 +1,117 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/dstathis/openswiss/internal/auth"
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/middleware"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/dstathis/openswiss/internal/webhook"
+	"github.com/go-chi/chi/v5"
+)
+
+type WebhooksAPI struct {
+	DB *sql.DB
+}
+
+// List returns the tournament's webhooks, secrets included. Min tier:
+// Co-organizer.
+func (a *WebhooksAPI) List(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if !middleware.AuthorizeTournament(w, r, a.DB, id, models.TierCoOrganizer) {
+		return
+	}
+	hooks, err := db.ListWebhooks(r.Context(), a.DB, id)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, "failed to list webhooks")
+		return
+	}
+	if hooks == nil {
+		hooks = []models.Webhook{}
+	}
+	jsonResponse(w, http.StatusOK, hooks)
+}
+
+type createWebhookRequest struct {
+	URL string `json:"url"`
+	// Events defaults to every event type when empty.
+	Events []string `json:"events"`
+}
+
+// Create registers a webhook and returns it with its generated secret.
+// Min tier: Co-organizer.
+func (a *WebhooksAPI) Create(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if !middleware.AuthorizeTournament(w, r, a.DB, id, models.TierCoOrganizer) {
+		return
+	}
+	var req createWebhookRequest
+	if err := decodeJSON(r, &req); err != nil {
+		jsonError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	req.URL = strings.TrimSpace(req.URL)
+	if err := webhook.ValidateURL(req.URL); err != nil {
+		jsonError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if len(req.Events) == 0 {
+		req.Events = models.WebhookEvents
+	}
+	for _, e := range req.Events {
+		if !models.ValidWebhookEvent(e) {
+			jsonError(w, http.StatusBadRequest, fmt.Sprintf("unknown event %q", e))
+			return
+		}
+	}
+	hooks, err := db.ListWebhooks(r.Context(), a.DB, id)
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, "failed to create webhook")
+		return
+	}
+	if len(hooks) >= models.MaxWebhooks {
+		jsonError(w, http.StatusConflict, fmt.Sprintf("a tournament can have at most %d webhooks", models.MaxWebhooks))
+		return
+	}
+	secret, err := auth.GenerateWebhookSecret()
+	if err != nil {
+		jsonError(w, http.StatusInternalServerError, "failed to create webhook")
+		return
+	}
+	user := middleware.GetUser(r.Context())
+	wh := &models.Webhook{TournamentID: id, URL: req.URL, Secret: secret, Events: req.Events, CreatedBy: &user.ID}
+	if err := db.CreateWebhook(r.Context(), a.DB, wh); err != nil {
+		jsonError(w, http.StatusInternalServerError, "failed to create webhook")
+		return
+	}
+	jsonResponse(w, http.StatusCreated, wh)
+}
+
+// Delete removes a webhook and its queued deliveries. Min tier:
+// Co-organizer.
+func (a *WebhooksAPI) Delete(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	webhookID, err := strconv.ParseInt(chi.URLParam(r, "webhookID"), 10, 64)
+	if err != nil {
+		jsonError(w, http.StatusNotFound, "not found")
+		return
+	}
+	if !middleware.AuthorizeTournament(w, r, a.DB, id, models.TierCoOrganizer) {
+		return
+	}
+	if err := db.DeleteWebhook(r.Context(), a.DB, id, webhookID); err != nil {
+		if errors.Is(err, db.ErrWebhookNotFound) {
+			jsonError(w, http.StatusNotFound, "not found")
+			return
+		}
+		jsonError(w, http.StatusInternalServerError, "failed to delete webhook")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,148 @@
+//go:build integration
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/dstathis/openswiss/internal/webhook"
+	"github.com/dstathis/swisstools"
+)
+
+func TestWebhooksAPI_CreateListDelete(t *testing.T) {
+	database := testDB(t)
+	owner, tourn := freshStarted(t, database)
+	a := &WebhooksAPI{DB: database}
+	params := map[string]string{"id": strconv.FormatInt(tourn.ID, 10)}
+
+	w := httptest.NewRecorder()
+	a.Create(w, requestWithUser("POST", "/", `{"url":"https://example.com/hook"}`, owner, params))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create: status %d body %s", w.Code, w.Body.String())
+	}
+	var created models.Webhook
+	json.NewDecoder(w.Body).Decode(&created)
+	if created.Secret == "" || len(created.Events) != len(models.WebhookEvents) {
+		t.Errorf("created = %+v", created)
+	}
+
+	for _, body := range []string{`{"url":"ftp://example.com"}`, `{"url":"https://example.com","events":["nope"]}`} {
+		w = httptest.NewRecorder()
+		a.Create(w, requestWithUser("POST", "/", body, owner, params))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status %d, want 400", body, w.Code)
+		}
+	}
+
+	stranger := mustCreateUser(t, database, "stranger-wh@example.com", "StrangerWH")
+	w = httptest.NewRecorder()
+	a.List(w, requestWithUser("GET", "/", "", stranger, params))
+	if w.Code != http.StatusForbidden {
+		t.Errorf("stranger list: status %d, want 403", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	a.List(w, requestWithUser("GET", "/", "", owner, params))
+	var hooks []models.Webhook
+	json.NewDecoder(w.Body).Decode(&hooks)
+	if len(hooks) != 1 || hooks[0].URL != "https://example.com/hook" {
+		t.Errorf("list = %+v", hooks)
+	}
+
+	delParams := map[string]string{"id": params["id"], "webhookID": strconv.FormatInt(created.ID, 10)}
+	w = httptest.NewRecorder()
+	a.Delete(w, requestWithUser("DELETE", "/", "", owner, delParams))
+	if w.Code != http.StatusNoContent {
+		t.Errorf("delete: status %d", w.Code)
+	}
+	w = httptest.NewRecorder()
+	a.Delete(w, requestWithUser("DELETE", "/", "", owner, delParams))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("second delete: status %d, want 404", w.Code)
+	}
+}
+
+// Entering results and advancing queues events that the dispatcher then
+// delivers, signed.
+func TestWebhooks_Delivery(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	owner, tourn := freshStarted(t, database)
+	params := map[string]string{"id": strconv.FormatInt(tourn.ID, 10)}
+
+	var mu sync.Mutex
+	var received []string
+	var secret string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		defer mu.Unlock()
+		if r.Header.Get(webhook.HeaderSignature) != webhook.Sign(secret, body) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		received = append(received, r.Header.Get(webhook.HeaderEvent))
+	}))
+	defer srv.Close()
+
+	w := httptest.NewRecorder()
+	(&WebhooksAPI{DB: database}).Create(w, requestWithUser("POST", "/",
+		`{"url":"`+srv.URL+`","events":["result.entered","round.paired"]}`, owner, params))
+	var hook models.Webhook
+	json.NewDecoder(w.Body).Decode(&hook)
+	secret = hook.Secret
+
+	tourn, _ = db.GetTournament(ctx, database, tourn.ID)
+	eng, _ := swisstools.LoadTournament(tourn.EngineState)
+	var results []map[string]int
+	for _, p := range eng.GetRound() {
+		results = append(results, map[string]int{"player_id": p.PlayerA(), "wins": 2})
+	}
+	body, _ := json.Marshal(map[string]interface{}{"results": results})
+	rounds := &RoundsAPI{DB: database}
+	w = httptest.NewRecorder()
+	rounds.SubmitResults(w, requestWithUser("POST", "/", string(body), owner, params))
+	if w.Code != http.StatusOK {
+		t.Fatalf("submit: status %d body %s", w.Code, w.Body.String())
+	}
+	w = httptest.NewRecorder()
+	rounds.NextRound(w, requestWithUser("POST", "/", "", owner, params))
+	if w.Code != http.StatusOK {
+		t.Fatalf("next round: status %d body %s", w.Code, w.Body.String())
+	}
+
+	deliveries, err := db.ListWebhookDeliveries(ctx, database, tourn.ID, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Two results in a four-player round, then round 2's pairings.
+	if len(deliveries) != 3 || deliveries[0].Event != models.WebhookRoundPaired {
+		t.Fatalf("queued = %+v", deliveries)
+	}
+
+	d := &webhook.Dispatcher{DB: database, Client: webhook.NewClient(true), Interval: time.Second}
+	if err := d.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(received) != 3 {
+		t.Errorf("received = %v", received)
+	}
+	deliveries, _ = db.ListWebhookDeliveries(ctx, database, tourn.ID, 10)
+	for _, d := range deliveries {
+		if d.DeliveredAt == nil || d.NextAttemptAt != nil {
+			t.Errorf("delivery %d not marked delivered: %+v", d.ID, d)
+		}
+	}
+}
@@ -3,8 +3,13 @@ package auth
 import (
 	"crypto/rand"
 	"crypto/sha256"
+	"encoding/base64"
 	"encoding/hex"
 	"fmt"
+	"math/big"
+	"strings"
+	"time"
+	"unicode"
 
 	"golang.org/x/crypto/bcrypt"
 )
@@ -21,6 +26,15 @@ func CheckPassword(hash, password string) bool {
 	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
 }
 
+// ValidatePasswordHash reports whether hash is a bcrypt hash, so a
+// misconfigured hash is caught at startup rather than at the first login.
+func ValidatePasswordHash(hash string) error {
+	if _, err := bcrypt.Cost([]byte(hash)); err != nil {
+		return fmt.Errorf("not a bcrypt hash: %w", err)
+	}
+	return nil
+}
+
 func GenerateSessionToken() (string, error) {
 	b := make([]byte, 32)
 	if _, err := rand.Read(b); err != nil {
@@ -29,6 +43,47 @@ func GenerateSessionToken() (string, error) {
 	return hex.EncodeToString(b), nil
 }
 
+// GenerateCSRFToken creates a random token for the double-submit CSRF cookie.
+// It is URL-safe base64 so it can go into cookies and form fields as is.
+func GenerateCSRFToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generating CSRF token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// GenerateWebhookSecret creates the key a webhook's payloads are signed
+// with. Unlike the other tokens it is stored as is, since signing needs it.
+func GenerateWebhookSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generating webhook secret: %w", err)
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
+
+// GenerateShareToken creates the token of a tournament's read-only share
+// link. Like a webhook secret it is stored as is, so the link can be shown
+// again.
+func GenerateShareToken() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generating share token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// GenerateKioskSecret creates the secret a tournament's kiosk PINs are
+// derived from. It never leaves the server; staff see only the PINs.
+func GenerateKioskSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generating kiosk secret: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
 // GenerateAPIKey creates a new API key and returns the full key and its prefix.
 func GenerateAPIKey() (fullKey, prefix string, err error) {
 	b := make([]byte, 32)
@@ -40,6 +95,20 @@ func GenerateAPIKey() (fullKey, prefix string, err error) {
 	return fullKey, prefix, nil
 }
 
+// NewAPIKey creates an API key, returning the full key to show its owner
+// once, its prefix for lookups and the bcrypt hash to store.
+func NewAPIKey() (fullKey, prefix, keyHash string, err error) {
+	fullKey, prefix, err = GenerateAPIKey()
+	if err != nil {
+		return "", "", "", err
+	}
+	keyHash, err = HashAPIKey(fullKey)
+	if err != nil {
+		return "", "", "", err
+	}
+	return fullKey, prefix, keyHash, nil
+}
+
 func HashAPIKey(key string) (string, error) {
 	hash, err := bcrypt.GenerateFromPassword([]byte(key), bcrypt.DefaultCost)
 	if err != nil {
@@ -70,3 +139,59 @@ func HashResetToken(rawToken string) string {
 	hash := sha256.Sum256([]byte(rawToken))
 	return hex.EncodeToString(hash[:])
 }
+
+// Reset links work for ResetLinkTTL when emailed to the user. One an admin
+// issues for them is passed on by hand, so it gets IssuedResetLinkTTL.
+const (
+	ResetLinkTTL       = time.Hour
+	IssuedResetLinkTTL = 24 * time.Hour
+)
+
+// NewPasswordReset creates a reset token that expires ttl from now. It
+// returns the raw token for the link, the hash to store and the expiry.
+func NewPasswordReset(ttl time.Duration) (rawToken, tokenHash string, expiresAt time.Time, err error) {
+	rawToken, tokenHash, err = GenerateResetToken()
+	if err != nil {
+		return "", "", time.Time{}, err
+	}
+	return rawToken, tokenHash, time.Now().Add(ttl), nil
+}
+
+// ResetPath is the path of the page where token sets a new password.
+func ResetPath(token string) string {
+	return "/reset-password?token=" + token
+}
+
+// handoffAlphabet leaves out characters that are easy to misread when a code
+// is read out or copied by hand (0/O, 1/I/L).
+const handoffAlphabet = "ABCDEFGHJKMNPQRSTUVWXYZ23456789"
+
+// GenerateHandoffCode creates a tournament handoff code formatted as
+// XXXX-XXXX-XXXX, and the hash to store for it.
+func GenerateHandoffCode() (code, codeHash string, err error) {
+	out := make([]byte, 0, 14)
+	for i := 0; i < 12; i++ {
+		if i > 0 && i%4 == 0 {
+			out = append(out, '-')
+		}
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(handoffAlphabet))))
+		if err != nil {
+			return "", "", fmt.Errorf("generating handoff code: %w", err)
+		}
+		out = append(out, handoffAlphabet[n.Int64()])
+	}
+	code = string(out)
+	return code, HashHandoffCode(code), nil
+}
+
+// HashHandoffCode returns the stored form of a handoff code. Case, spaces and
+// dashes are ignored, so a code typed in by hand still matches.
+func HashHandoffCode(code string) string {
+	normalized := strings.Map(func(r rune) rune {
+		if r == '-' || unicode.IsSpace(r) {
+			return -1
+		}
+		return unicode.ToUpper(r)
+	}, code)
+	return HashResetToken(normalized)
+}
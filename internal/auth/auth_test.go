@@ -2,9 +2,11 @@ package auth
 
 import (
 "crypto/sha256"
+"encoding/base64"
 "encoding/hex"
 "strings"
 "testing"
+"time"
 )
 
 func TestHashPassword(t *testing.T) {
@@ -58,6 +60,41 @@ t.Error("two successive tokens should be different")
 }
 }
 
+func TestGenerateCSRFToken(t *testing.T) {
+token, err := GenerateCSRFToken()
+if err != nil {
+t.Fatalf("GenerateCSRFToken returned error: %v", err)
+}
+b, err := base64.RawURLEncoding.DecodeString(token)
+if err != nil {
+t.Fatalf("token is not URL-safe base64: %v", err)
+}
+if len(b) != 32 {
+t.Errorf("expected 32 random bytes, got %d", len(b))
+}
+other, _ := GenerateCSRFToken()
+if token == other {
+t.Error("two successive tokens should be different")
+}
+}
+
+func TestGenerateShareToken(t *testing.T) {
+token, err := GenerateShareToken()
+if err != nil {
+t.Fatalf("GenerateShareToken returned error: %v", err)
+}
+b, err := base64.RawURLEncoding.DecodeString(token)
+if err != nil {
+t.Fatalf("token is not URL-safe base64: %v", err)
+}
+if len(b) != 24 {
+t.Errorf("expected 24 random bytes, got %d", len(b))
+}
+if other, _ := GenerateShareToken(); token == other {
+t.Error("two successive tokens should be different")
+}
+}
+
 func TestGenerateAPIKey(t *testing.T) {
 fullKey, prefix, err := GenerateAPIKey()
 if err != nil {
@@ -77,6 +114,21 @@ t.Errorf("prefix %q should be first 11 chars of key", prefix)
 }
 }
 
+func TestValidatePasswordHash(t *testing.T) {
+hash, err := HashPassword("correct horse")
+if err != nil {
+t.Fatalf("HashPassword returned error: %v", err)
+}
+if err := ValidatePasswordHash(hash); err != nil {
+t.Errorf("ValidatePasswordHash rejected a bcrypt hash: %v", err)
+}
+for _, bad := range []string{"", "correct horse", "$2a$10$short"} {
+if ValidatePasswordHash(bad) == nil {
+t.Errorf("ValidatePasswordHash(%q) accepted a non-hash", bad)
+}
+}
+}
+
 func TestGenerateAPIKey_Unique(t *testing.T) {
 key1, _, _ := GenerateAPIKey()
 key2, _, _ := GenerateAPIKey()
@@ -151,3 +203,41 @@ if tokenHash != recomputed {
 t.Errorf("hash mismatch: %s != %s", tokenHash, recomputed)
 }
 }
+
+func TestGenerateHandoffCode(t *testing.T) {
+code, hash, err := GenerateHandoffCode()
+if err != nil {
+t.Fatalf("GenerateHandoffCode returned error: %v", err)
+}
+if len(code) != 14 || code[4] != '-' || code[9] != '-' {
+t.Errorf("code %q is not XXXX-XXXX-XXXX", code)
+}
+if strings.ContainsAny(code, "01OIL") {
+t.Errorf("code %q contains an ambiguous character", code)
+}
+typed := strings.ToLower(strings.ReplaceAll(code, "-", " "))
+if HashHandoffCode(typed) != hash {
+t.Error("hand-typed code does not match its hash")
+}
+other, _, _ := GenerateHandoffCode()
+if other == code {
+t.Error("two successive handoff codes should be different")
+}
+}
+
+func TestNewPasswordReset(t *testing.T) {
+	before := time.Now()
+	rawToken, tokenHash, expiresAt, err := NewPasswordReset(IssuedResetLinkTTL)
+	if err != nil {
+		t.Fatalf("NewPasswordReset: %v", err)
+	}
+	if HashResetToken(rawToken) != tokenHash {
+		t.Error("token hash does not match the raw token")
+	}
+	if expiresAt.Before(before.Add(IssuedResetLinkTTL)) || expiresAt.After(time.Now().Add(IssuedResetLinkTTL)) {
+		t.Errorf("expires at %v, want %v from now", expiresAt, IssuedResetLinkTTL)
+	}
+	if got := ResetPath(rawToken); got != "/reset-password?token="+rawToken {
+		t.Errorf("ResetPath = %q", got)
+	}
+}
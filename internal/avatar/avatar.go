@@ -0,0 +1,86 @@
+// Package avatar turns an uploaded picture into a player's avatar: a small
+// PNG the server can store and serve itself. Re-encoding drops whatever
+// else the upload carried, such as the EXIF data of a phone photo, and
+// means only images the server drew itself are ever served back.
+package avatar
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/gif" // uploads may be GIF, JPEG or PNG
+	_ "image/jpeg"
+	"image/png"
+	"io"
+)
+
+// Size is the width and height of an avatar, in pixels.
+const Size = 64
+
+// MaxSide is the widest or tallest upload accepted, so a small file can't
+// decode into a huge image.
+const MaxSide = 4096
+
+var (
+	// ErrFormat is returned for an upload that isn't a GIF, JPEG or PNG.
+	ErrFormat = errors.New("avatar: not a GIF, JPEG or PNG image")
+	// ErrDimensions is returned for an upload wider or taller than MaxSide.
+	ErrDimensions = errors.New("avatar: image is too large")
+)
+
+// Normalize decodes an uploaded image, crops it to a centred square and
+// scales that to Size×Size, and returns it as PNG.
+func Normalize(r io.Reader) ([]byte, error) {
+	var buf bytes.Buffer
+	cfg, _, err := image.DecodeConfig(io.TeeReader(r, &buf))
+	if err != nil {
+		return nil, ErrFormat
+	}
+	if cfg.Width < 1 || cfg.Height < 1 || cfg.Width > MaxSide || cfg.Height > MaxSide {
+		return nil, ErrDimensions
+	}
+	src, _, err := image.Decode(io.MultiReader(&buf, r))
+	if err != nil {
+		return nil, ErrFormat
+	}
+	var out bytes.Buffer
+	if err := png.Encode(&out, scale(square(src), Size)); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// square returns the largest square centred in img.
+func square(img image.Image) image.Image {
+	b := img.Bounds()
+	side := min(b.Dx(), b.Dy())
+	x, y := b.Min.X+(b.Dx()-side)/2, b.Min.Y+(b.Dy()-side)/2
+	dst := image.NewNRGBA(image.Rect(0, 0, side, side))
+	draw.Draw(dst, dst.Bounds(), img, image.Pt(x, y), draw.Src)
+	return dst
+}
+
+// scale resizes the square src to size×size, each pixel the average of the
+// source pixels it covers. An image smaller than size is enlarged the same
+// way, by repeating pixels.
+func scale(src image.Image, size int) *image.NRGBA {
+	side := src.Bounds().Dx()
+	dst := image.NewNRGBA(image.Rect(0, 0, size, size))
+	for y := 0; y < size; y++ {
+		y0, y1 := y*side/size, max((y+1)*side/size, y*side/size+1)
+		for x := 0; x < size; x++ {
+			x0, x1 := x*side/size, max((x+1)*side/size, x*side/size+1)
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					c := color.NRGBAModel.Convert(src.At(sx, sy)).(color.NRGBA)
+					r, g, b, a, n = r+uint64(c.R), g+uint64(c.G), b+uint64(c.B), a+uint64(c.A), n+1
+				}
+			}
+			dst.SetNRGBA(x, y, color.NRGBA{R: uint8(r / n), G: uint8(g / n), B: uint8(b / n), A: uint8(a / n)})
+		}
+	}
+	return dst
+}
@@ -0,0 +1,88 @@
+package avatar
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+func encode(t *testing.T, img image.Image, format string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	var err error
+	if format == "jpeg" {
+		err = jpeg.Encode(&buf, img, nil)
+	} else {
+		err = png.Encode(&buf, img)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// halves is a w×h image, red on its left half and blue on its right.
+func halves(w, h int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c := color.RGBA{R: 255, A: 255}
+			if x >= w/2 {
+				c = color.RGBA{B: 255, A: 255}
+			}
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+func TestNormalize(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		w, h   int
+		format string
+	}{
+		{"large png", 300, 300, "png"},
+		{"wide jpeg, cropped", 400, 200, "jpeg"},
+		{"tiny png, enlarged", 8, 8, "png"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := Normalize(bytes.NewReader(encode(t, halves(tt.w, tt.h), tt.format)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			img, format, err := image.Decode(bytes.NewReader(out))
+			if err != nil || format != "png" {
+				t.Fatalf("output: format %q, err %v", format, err)
+			}
+			if b := img.Bounds(); b.Dx() != Size || b.Dy() != Size {
+				t.Errorf("size = %v, want %dx%d", b, Size, Size)
+			}
+			// The crop is centred, so the colours still split down the middle.
+			left := color.NRGBAModel.Convert(img.At(2, Size/2)).(color.NRGBA)
+			right := color.NRGBAModel.Convert(img.At(Size-3, Size/2)).(color.NRGBA)
+			if left.R < 200 || left.B > 55 || right.B < 200 || right.R > 55 {
+				t.Errorf("left %v, right %v; want red, blue", left, right)
+			}
+		})
+	}
+}
+
+func TestNormalize_Rejects(t *testing.T) {
+	if _, err := Normalize(strings.NewReader("<svg xmlns='http://www.w3.org/2000/svg'/>")); !errors.Is(err, ErrFormat) {
+		t.Errorf("svg: err = %v, want ErrFormat", err)
+	}
+	truncated := encode(t, halves(32, 32), "png")
+	if _, err := Normalize(bytes.NewReader(truncated[:len(truncated)/2])); !errors.Is(err, ErrFormat) {
+		t.Errorf("truncated: err = %v, want ErrFormat", err)
+	}
+	huge := encode(t, image.NewGray(image.Rect(0, 0, MaxSide+1, 1)), "png")
+	if _, err := Normalize(bytes.NewReader(huge)); !errors.Is(err, ErrDimensions) {
+		t.Errorf("too wide: err = %v, want ErrDimensions", err)
+	}
+}
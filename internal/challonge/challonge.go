@@ -0,0 +1,269 @@
+// Package challonge pushes a finished tournament to Challonge through its
+// v1 REST API: the final Swiss standings, and the top-cut bracket with its
+// results when there is one. Challonge then hosts the bracket for players
+// who follow events there.
+package challonge
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/dstathis/openswiss/internal/engine"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/dstathis/swisstools"
+)
+
+// DefaultBaseURL is Challonge's v1 API.
+const DefaultBaseURL = "https://api.challonge.com/v1"
+
+// Client talks to the Challonge API with one account's API key.
+type Client struct {
+	APIKey  string
+	BaseURL string
+	HTTP    *http.Client
+}
+
+// New returns a client for apiKey against baseURL, or DefaultBaseURL when
+// it is empty.
+func New(apiKey, baseURL string) *Client {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	return &Client{APIKey: apiKey, BaseURL: strings.TrimRight(baseURL, "/"), HTTP: &http.Client{Timeout: 15 * time.Second}}
+}
+
+// Result is a played playoff match, by player name.
+type Result struct {
+	PlayerA, PlayerB string
+	AWins, BWins     int
+}
+
+// Push is what is sent for one tournament. Participants are in seed order.
+// With Bracket set they are the top cut, the Challonge tournament is
+// started and Results reported into it in order; otherwise they are every
+// player in standings order and the tournament is left unstarted, as a
+// record of the standings.
+type Push struct {
+	Name         string
+	Slug         string
+	Description  string
+	Bracket      bool
+	Participants []string
+	Results      []Result
+}
+
+// Build assembles the push for a complete tournament: the Swiss standings
+// go in the description, and the playoff seeds and played matches make the
+// bracket.
+func Build(t *models.Tournament, eng *swisstools.Tournament, seeds map[int]int64) Push {
+	standings := engine.Standings(eng, t.TiebreakOrder(), seeds)
+	var desc strings.Builder
+	desc.WriteString("<p>Final Swiss standings, exported from OpenSwiss.</p><ol>")
+	for _, s := range standings {
+		fmt.Fprintf(&desc, "<li>%s: %d pts (%d-%d-%d)</li>",
+			html.EscapeString(s.Name), s.Points, s.Wins, s.Losses, s.Draws)
+	}
+	desc.WriteString("</ol>")
+
+	p := Push{
+		Name:        t.Name,
+		Slug:        fmt.Sprintf("openswiss_%d_%d", t.ID, time.Now().Unix()),
+		Description: desc.String(),
+	}
+	name := func(id int) string {
+		if player, ok := eng.GetPlayerById(id); ok {
+			return player.Name
+		}
+		return ""
+	}
+	po := eng.GetPlayoff()
+	if po == nil {
+		for _, s := range standings {
+			p.Participants = append(p.Participants, s.Name)
+		}
+		return p
+	}
+	p.Bracket = true
+	for _, id := range po.Seeds {
+		p.Participants = append(p.Participants, name(id))
+	}
+	for _, round := range po.Rounds {
+		for _, m := range round {
+			if m.PlayerB() == swisstools.BYE_OPPONENT_ID || m.PlayerAWins() == swisstools.UNINITIALIZED_RESULT {
+				continue
+			}
+			p.Results = append(p.Results, Result{
+				PlayerA: name(m.PlayerA()), PlayerB: name(m.PlayerB()),
+				AWins: m.PlayerAWins(), BWins: m.PlayerBWins(),
+			})
+		}
+	}
+	return p
+}
+
+type tournamentResponse struct {
+	Tournament struct {
+		ID               int64  `json:"id"`
+		FullChallongeURL string `json:"full_challonge_url"`
+	} `json:"tournament"`
+}
+
+type participantResponse struct {
+	Participant struct {
+		ID   int64  `json:"id"`
+		Name string `json:"name"`
+	} `json:"participant"`
+}
+
+type matchResponse struct {
+	Match struct {
+		ID        int64 `json:"id"`
+		Player1ID int64 `json:"player1_id"`
+		Player2ID int64 `json:"player2_id"`
+	} `json:"match"`
+}
+
+// Push creates the tournament on Challonge and returns its page's URL. If a
+// later step fails the tournament is left as far as it got, and the error
+// says so with its URL.
+func (c *Client) Push(ctx context.Context, p Push) (string, error) {
+	kind := "single elimination"
+	if !p.Bracket {
+		kind = "swiss"
+	}
+	var created tournamentResponse
+	if err := c.call(ctx, http.MethodPost, "/tournaments.json", map[string]any{
+		"tournament": map[string]any{
+			"name":            p.Name,
+			"url":             p.Slug,
+			"tournament_type": kind,
+			"description":     p.Description,
+		},
+	}, &created); err != nil {
+		return "", err
+	}
+	pageURL := created.Tournament.FullChallongeURL
+	base := fmt.Sprintf("/tournaments/%d", created.Tournament.ID)
+	fail := func(step string, err error) (string, error) {
+		return pageURL, fmt.Errorf("challonge: %s for %s: %w", step, pageURL, err)
+	}
+
+	participants := make([]map[string]any, len(p.Participants))
+	for i, name := range p.Participants {
+		participants[i] = map[string]any{"name": name, "seed": i + 1}
+	}
+	var added []participantResponse
+	if err := c.call(ctx, http.MethodPost, base+"/participants/bulk_add.json",
+		map[string]any{"participants": participants}, &added); err != nil {
+		return fail("add participants", err)
+	}
+	if !p.Bracket {
+		return pageURL, nil
+	}
+	ids := make(map[string]int64, len(added))
+	for _, a := range added {
+		ids[a.Participant.Name] = a.Participant.ID
+	}
+	if err := c.call(ctx, http.MethodPost, base+"/start.json", nil, nil); err != nil {
+		return fail("start", err)
+	}
+
+	// Challonge opens a round's matches once the one before is reported,
+	// so results go in round by round.
+	for _, r := range p.Results {
+		var open []matchResponse
+		if err := c.call(ctx, http.MethodGet, base+"/matches.json?state=open", nil, &open); err != nil {
+			return fail("list matches", err)
+		}
+		a, b := ids[r.PlayerA], ids[r.PlayerB]
+		var matchID, winner int64
+		scores := fmt.Sprintf("%d-%d", r.AWins, r.BWins)
+		for _, m := range open {
+			switch {
+			case m.Match.Player1ID == a && m.Match.Player2ID == b:
+				matchID = m.Match.ID
+			case m.Match.Player1ID == b && m.Match.Player2ID == a:
+				matchID = m.Match.ID
+				scores = fmt.Sprintf("%d-%d", r.BWins, r.AWins)
+			}
+		}
+		if matchID == 0 {
+			return fail("report", fmt.Errorf("no open match for %s vs %s", r.PlayerA, r.PlayerB))
+		}
+		winner = a
+		if r.BWins > r.AWins {
+			winner = b
+		}
+		if err := c.call(ctx, http.MethodPut, fmt.Sprintf("%s/matches/%d.json", base, matchID), map[string]any{
+			"match": map[string]any{"scores_csv": scores, "winner_id": winner},
+		}, nil); err != nil {
+			return fail("report", err)
+		}
+	}
+	return pageURL, nil
+}
+
+// call sends one API request, authenticated with the API key, and decodes
+// the JSON reply into out unless it is nil.
+func (c *Client) call(ctx context.Context, method, path string, body, out any) error {
+	u, err := url.Parse(c.BaseURL + path)
+	if err != nil {
+		return err
+	}
+	q := u.Query()
+	q.Set("api_key", c.APIKey)
+	u.RawQuery = q.Encode()
+
+	var reader io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return apiError(resp)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// apiError turns a failed reply into an error, with Challonge's own
+// messages when it sent any.
+func apiError(resp *http.Response) error {
+	if resp.StatusCode == http.StatusUnauthorized {
+		return fmt.Errorf("the API key was refused (%s)", resp.Status)
+	}
+	var e struct {
+		Errors []string `json:"errors"`
+	}
+	b, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if json.Unmarshal(b, &e) == nil && len(e.Errors) > 0 {
+		return fmt.Errorf("%s: %s", resp.Status, strings.Join(e.Errors, "; "))
+	}
+	return fmt.Errorf("%s", resp.Status)
+}
@@ -0,0 +1,171 @@
+package challonge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/dstathis/swisstools"
+)
+
+// fakeChallonge plays a four-player single-elimination bracket: the two
+// semifinals open once started, the final once both are reported.
+type fakeChallonge struct {
+	mu       sync.Mutex
+	created  map[string]any
+	names    []string
+	started  bool
+	reported map[int64]string // match ID -> scores_csv
+	winners  map[int64]int64
+}
+
+func (f *fakeChallonge) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if r.URL.Query().Get("api_key") != "secret" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	var body map[string]any
+	json.NewDecoder(r.Body).Decode(&body)
+	switch {
+	case r.Method == "POST" && r.URL.Path == "/tournaments.json":
+		f.created = body["tournament"].(map[string]any)
+		fmt.Fprint(w, `{"tournament": {"id": 7, "full_challonge_url": "https://challonge.com/x"}}`)
+	case r.Method == "POST" && r.URL.Path == "/tournaments/7/participants/bulk_add.json":
+		var out []string
+		for i, p := range body["participants"].([]any) {
+			name := p.(map[string]any)["name"].(string)
+			f.names = append(f.names, name)
+			out = append(out, fmt.Sprintf(`{"participant": {"id": %d, "name": %q}}`, 100+i, name))
+		}
+		fmt.Fprint(w, "["+strings.Join(out, ",")+"]")
+	case r.Method == "POST" && r.URL.Path == "/tournaments/7/start.json":
+		f.started = true
+		fmt.Fprint(w, `{}`)
+	case r.Method == "GET" && r.URL.Path == "/tournaments/7/matches.json":
+		// Seeds 1v4 (match 1) and 2v3 (match 2), written player2 first
+		// for match 2; the final (match 3) opens after both.
+		var open []string
+		if _, ok := f.reported[1]; !ok {
+			open = append(open, `{"match": {"id": 1, "player1_id": 100, "player2_id": 103}}`)
+		}
+		if _, ok := f.reported[2]; !ok {
+			open = append(open, `{"match": {"id": 2, "player1_id": 102, "player2_id": 101}}`)
+		}
+		if len(f.reported) == 2 {
+			open = append(open, fmt.Sprintf(`{"match": {"id": 3, "player1_id": %d, "player2_id": %d}}`, f.winners[1], f.winners[2]))
+		}
+		fmt.Fprint(w, "["+strings.Join(open, ",")+"]")
+	case r.Method == "PUT" && strings.HasPrefix(r.URL.Path, "/tournaments/7/matches/"):
+		var id int64
+		fmt.Sscanf(strings.TrimPrefix(r.URL.Path, "/tournaments/7/matches/"), "%d.json", &id)
+		m := body["match"].(map[string]any)
+		f.reported[id] = m["scores_csv"].(string)
+		f.winners[id] = int64(m["winner_id"].(float64))
+		fmt.Fprint(w, `{}`)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"errors": ["no such route"]}`)
+	}
+}
+
+func TestPush_Bracket(t *testing.T) {
+	f := &fakeChallonge{reported: map[int64]string{}, winners: map[int64]int64{}}
+	srv := httptest.NewServer(f)
+	defer srv.Close()
+
+	push := Push{
+		Name: "Spring Open", Slug: "openswiss_1_1", Description: "<ol></ol>", Bracket: true,
+		Participants: []string{"A", "B", "C", "D"},
+		Results: []Result{
+			{PlayerA: "A", PlayerB: "D", AWins: 2, BWins: 0},
+			{PlayerA: "B", PlayerB: "C", AWins: 1, BWins: 2},
+			{PlayerA: "A", PlayerB: "C", AWins: 2, BWins: 1},
+		},
+	}
+	got, err := New("secret", srv.URL).Push(context.Background(), push)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "https://challonge.com/x" {
+		t.Errorf("url = %q", got)
+	}
+	if f.created["tournament_type"] != "single elimination" || f.created["url"] != "openswiss_1_1" || !f.started {
+		t.Errorf("created %v, started %v", f.created, f.started)
+	}
+	// Match 2 lists C first, so its score is flipped to C's side.
+	want := map[int64]string{1: "2-0", 2: "2-1", 3: "2-1"}
+	for id, s := range want {
+		if f.reported[id] != s {
+			t.Errorf("match %d scores %q, want %q", id, f.reported[id], s)
+		}
+	}
+	if f.winners[2] != 102 || f.winners[3] != 100 {
+		t.Errorf("winners = %v", f.winners)
+	}
+}
+
+func TestPush_StandingsOnlyAndErrors(t *testing.T) {
+	f := &fakeChallonge{reported: map[int64]string{}, winners: map[int64]int64{}}
+	srv := httptest.NewServer(f)
+	defer srv.Close()
+
+	if _, err := New("secret", srv.URL).Push(context.Background(), Push{Name: "Casual", Participants: []string{"A", "B"}}); err != nil {
+		t.Fatal(err)
+	}
+	if f.created["tournament_type"] != "swiss" || f.started || len(f.names) != 2 {
+		t.Errorf("created %v, started %v, names %v", f.created, f.started, f.names)
+	}
+
+	_, err := New("wrong", srv.URL).Push(context.Background(), Push{Name: "X"})
+	if err == nil || !strings.Contains(err.Error(), "API key") {
+		t.Errorf("bad key: err = %v", err)
+	}
+
+	_, err = New("secret", srv.URL).Push(context.Background(), Push{Name: "X", Bracket: true,
+		Participants: []string{"A", "B"}, Results: []Result{{PlayerA: "A", PlayerB: "Z", AWins: 2}}})
+	if err == nil || !strings.Contains(err.Error(), "https://challonge.com/x") || !strings.Contains(err.Error(), "no open match") {
+		t.Errorf("unknown match: err = %v", err)
+	}
+}
+
+func TestBuild(t *testing.T) {
+	eng := swisstools.NewTournamentWithConfig(swisstools.TournamentConfig{PointsForWin: 3, ByeWins: swisstools.BYE_WINS})
+	eng.SetMaxRounds(1)
+	for _, n := range []string{"Alice", "Bob", "Carol", "Dan <3"} {
+		eng.AddPlayer(n)
+	}
+	eng.StartTournament()
+	for _, p := range eng.GetRound() {
+		eng.AddResult(p.PlayerA(), 2, 0, 0)
+	}
+	eng.NextRound()
+	mt := &models.Tournament{ID: 5, Name: "Spring Open", TopCut: 4}
+
+	p := Build(mt, &eng, nil)
+	if p.Bracket || len(p.Participants) != 4 || !strings.HasPrefix(p.Slug, "openswiss_5_") {
+		t.Errorf("before the cut: %+v", p)
+	}
+	if !strings.Contains(p.Description, "Dan &lt;3") || strings.Contains(p.Description, "Dan <3") {
+		t.Error("names not escaped in the description")
+	}
+
+	if err := eng.StartPlayoff(4); err != nil {
+		t.Fatal(err)
+	}
+	semi := eng.GetPlayoff().Rounds[0][0]
+	if err := eng.AddPlayoffResult(semi.PlayerA(), 2, 1, 0); err != nil {
+		t.Fatal(err)
+	}
+	p = Build(mt, &eng, nil)
+	if !p.Bracket || len(p.Participants) != 4 || len(p.Results) != 1 || p.Results[0].AWins != 2 {
+		t.Errorf("with the cut: %+v", p)
+	}
+}
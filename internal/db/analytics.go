@@ -0,0 +1,80 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+// AddPageViews adds n views of page during round to tournament
+// tournamentID's counts. Views of a tournament deleted since are dropped.
+func AddPageViews(ctx context.Context, db DBTX, tournamentID int64, round int, page string, n int64) error {
+	_, err := db.ExecContext(ctx,
+		`INSERT INTO page_views (tournament_id, round, page, views)
+		 SELECT $1, $2, $3, $4 WHERE EXISTS (SELECT 1 FROM tournaments WHERE id = $1)
+		 ON CONFLICT (tournament_id, round, page) DO UPDATE SET views = page_views.views + EXCLUDED.views`,
+		tournamentID, round, page, n,
+	)
+	return err
+}
+
+// RecordViewers notes that viewers people were watching tournament
+// tournamentID at at, keeping it if it is a new peak.
+func RecordViewers(ctx context.Context, db DBTX, tournamentID int64, viewers int, at time.Time) error {
+	_, err := db.ExecContext(ctx,
+		`INSERT INTO viewer_peaks (tournament_id, viewers, seen_at)
+		 SELECT $1, $2, $3 WHERE EXISTS (SELECT 1 FROM tournaments WHERE id = $1)
+		 ON CONFLICT (tournament_id) DO UPDATE SET viewers = EXCLUDED.viewers, seen_at = EXCLUDED.seen_at
+		 WHERE viewer_peaks.viewers < EXCLUDED.viewers`,
+		tournamentID, viewers, at,
+	)
+	return err
+}
+
+// GetAnalytics returns tournament tournamentID's peak viewers and page
+// views. Viewers is left for the caller, who knows who is watching now.
+func GetAnalytics(ctx context.Context, db DBTX, tournamentID int64) (*models.Analytics, error) {
+	a := &models.Analytics{Rounds: []models.RoundViews{}}
+	var at time.Time
+	err := db.QueryRowContext(ctx,
+		`SELECT viewers, seen_at FROM viewer_peaks WHERE tournament_id = $1`, tournamentID,
+	).Scan(&a.PeakViewers, &at)
+	switch {
+	case err == nil:
+		a.PeakAt = &at
+	case !errors.Is(err, sql.ErrNoRows):
+		return nil, err
+	}
+
+	rows, err := db.QueryContext(ctx,
+		`SELECT round, page, views FROM page_views WHERE tournament_id = $1 ORDER BY round`, tournamentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var round int
+		var page string
+		var views int64
+		if err := rows.Scan(&round, &page, &views); err != nil {
+			return nil, err
+		}
+		if n := len(a.Rounds); n == 0 || a.Rounds[n-1].Round != round {
+			a.Rounds = append(a.Rounds, models.RoundViews{Round: round})
+		}
+		rv := &a.Rounds[len(a.Rounds)-1]
+		switch page {
+		case models.PageTournament:
+			rv.Tournament += views
+		case models.PagePairings:
+			rv.Pairings += views
+		case models.PageStandings:
+			rv.Standings += views
+		}
+		a.Views += views
+	}
+	return a, rows.Err()
+}
@@ -0,0 +1,62 @@
+//go:build integration
+
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+func TestAnalytics(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	org := createTestOrganizer(t, database)
+	tourn := &models.Tournament{Name: "Watched", Status: models.TournamentStatusInProgress, OrganizerID: org.ID}
+	if err := CreateTournament(ctx, database, tourn); err != nil {
+		t.Fatalf("CreateTournament: %v", err)
+	}
+
+	if a, err := GetAnalytics(ctx, database, tourn.ID); err != nil || a.PeakAt != nil || a.Views != 0 || len(a.Rounds) != 0 {
+		t.Fatalf("fresh analytics = %+v, %v", a, err)
+	}
+	for _, v := range []struct {
+		round int
+		page  string
+		n     int64
+	}{
+		{0, models.PageTournament, 4},
+		{1, models.PagePairings, 10},
+		{1, models.PagePairings, 5},
+		{1, models.PageStandings, 2},
+	} {
+		if err := AddPageViews(ctx, database, tourn.ID, v.round, v.page, v.n); err != nil {
+			t.Fatalf("AddPageViews: %v", err)
+		}
+	}
+	noon := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	RecordViewers(ctx, database, tourn.ID, 7, noon)
+	RecordViewers(ctx, database, tourn.ID, 3, noon.Add(time.Hour)) // not a peak
+
+	a, err := GetAnalytics(ctx, database, tourn.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.PeakViewers != 7 || a.PeakAt == nil || !a.PeakAt.Equal(noon) {
+		t.Errorf("peak = %d at %v, want 7 at noon", a.PeakViewers, a.PeakAt)
+	}
+	want := []models.RoundViews{{Round: 0, Tournament: 4}, {Round: 1, Pairings: 15, Standings: 2}}
+	if a.Views != 21 || len(a.Rounds) != 2 || a.Rounds[0] != want[0] || a.Rounds[1] != want[1] {
+		t.Errorf("views = %d %+v, want 21 %+v", a.Views, a.Rounds, want)
+	}
+
+	// Views of a deleted tournament are dropped without an error.
+	if err := AddPageViews(ctx, database, tourn.ID+1000, 1, models.PagePairings, 1); err != nil {
+		t.Errorf("views of a missing tournament: %v", err)
+	}
+	if err := RecordViewers(ctx, database, tourn.ID+1000, 1, noon); err != nil {
+		t.Errorf("viewers of a missing tournament: %v", err)
+	}
+}
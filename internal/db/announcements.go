@@ -0,0 +1,120 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+// ErrAnnouncementNotFound is returned by EndAnnouncement when the
+// tournament has no active announcement with that ID.
+var ErrAnnouncementNotFound = errors.New("announcement not found")
+
+const announcementCols = `id, tournament_id, message, created_by, created_at, expires_at`
+
+func scanAnnouncements(rows interface {
+	Next() bool
+	Scan(dest ...interface{}) error
+	Err() error
+}) ([]models.Announcement, error) {
+	var out []models.Announcement
+	for rows.Next() {
+		var a models.Announcement
+		if err := rows.Scan(&a.ID, &a.TournamentID, &a.Message, &a.CreatedBy, &a.CreatedAt, &a.ExpiresAt); err != nil {
+			return nil, err
+		}
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
+
+// CreateAnnouncement saves a new announcement, filling in its ID and
+// CreatedAt.
+func CreateAnnouncement(ctx context.Context, db DBTX, a *models.Announcement) error {
+	return db.QueryRowContext(ctx,
+		`INSERT INTO announcements (tournament_id, message, created_by, expires_at)
+		 VALUES ($1, $2, $3, $4) RETURNING id, created_at`,
+		a.TournamentID, a.Message, a.CreatedBy, a.ExpiresAt,
+	).Scan(&a.ID, &a.CreatedAt)
+}
+
+// ListAnnouncements returns every announcement of tournament tournamentID,
+// ended and expired ones included, newest first.
+func ListAnnouncements(ctx context.Context, db DBTX, tournamentID int64) ([]models.Announcement, error) {
+	rows, err := db.QueryContext(ctx,
+		`SELECT `+announcementCols+` FROM announcements
+		 WHERE tournament_id = $1 ORDER BY created_at DESC, id DESC`,
+		tournamentID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanAnnouncements(rows)
+}
+
+// ActiveAnnouncements returns tournament tournamentID's announcements
+// still shown at now, newest first.
+func ActiveAnnouncements(ctx context.Context, db DBTX, tournamentID int64, now time.Time) ([]models.Announcement, error) {
+	rows, err := db.QueryContext(ctx,
+		`SELECT `+announcementCols+` FROM announcements
+		 WHERE tournament_id = $1 AND (expires_at IS NULL OR expires_at > $2)
+		 ORDER BY created_at DESC, id DESC`,
+		tournamentID, now,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanAnnouncements(rows)
+}
+
+// UserAnnouncements returns the announcements still shown at now of the
+// unfinished tournaments user userID is registered in and hasn't dropped
+// from, newest first, with their tournament's name.
+func UserAnnouncements(ctx context.Context, db DBTX, userID int64, now time.Time) ([]models.Announcement, error) {
+	rows, err := db.QueryContext(ctx,
+		`SELECT a.id, a.tournament_id, a.message, a.created_by, a.created_at, a.expires_at, t.name
+		 FROM announcements a
+		 JOIN tournaments t ON t.id = a.tournament_id
+		 WHERE t.status <> 'finished' AND (a.expires_at IS NULL OR a.expires_at > $2)
+		   AND EXISTS (SELECT 1 FROM registrations r
+		               WHERE r.tournament_id = t.id AND r.user_id = $1 AND r.status <> 'dropped')
+		 ORDER BY a.created_at DESC, a.id DESC`,
+		userID, now,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []models.Announcement
+	for rows.Next() {
+		var a models.Announcement
+		if err := rows.Scan(&a.ID, &a.TournamentID, &a.Message, &a.CreatedBy, &a.CreatedAt, &a.ExpiresAt, &a.TournamentName); err != nil {
+			return nil, err
+		}
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
+
+// EndAnnouncement stops showing tournament tournamentID's announcement id
+// from now on. It returns ErrAnnouncementNotFound if there is no such
+// announcement or it has already ended.
+func EndAnnouncement(ctx context.Context, db DBTX, tournamentID, id int64, now time.Time) error {
+	res, err := db.ExecContext(ctx,
+		`UPDATE announcements SET expires_at = $3
+		 WHERE tournament_id = $1 AND id = $2 AND (expires_at IS NULL OR expires_at > $3)`,
+		tournamentID, id, now,
+	)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrAnnouncementNotFound
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+//go:build integration
+
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+func TestAnnouncements(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	org := createTestOrganizer(t, database)
+	player := createTestPlayer(t, database, "Listener")
+	tourn := &models.Tournament{Name: "Announced", Status: models.TournamentStatusInProgress, OrganizerID: org.ID}
+	if err := CreateTournament(ctx, database, tourn); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := CreateRegistration(ctx, database, tourn.ID, player.ID, player.DisplayName); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	soon := now.Add(10 * time.Minute)
+	delay := &models.Announcement{TournamentID: tourn.ID, Message: "Round 3 is delayed", CreatedBy: &org.ID, ExpiresAt: &soon}
+	lunch := &models.Announcement{TournamentID: tourn.ID, Message: "Lunch is served"}
+	for _, a := range []*models.Announcement{delay, lunch} {
+		if err := CreateAnnouncement(ctx, database, a); err != nil || a.ID == 0 {
+			t.Fatalf("CreateAnnouncement = %v, id %d", err, a.ID)
+		}
+	}
+
+	active, err := ActiveAnnouncements(ctx, database, tourn.ID, now)
+	if err != nil || len(active) != 2 || active[0].ID != lunch.ID {
+		t.Fatalf("active = %+v, %v; want lunch, then delay", active, err)
+	}
+	if active, _ = ActiveAnnouncements(ctx, database, tourn.ID, soon); len(active) != 1 || active[0].ID != lunch.ID {
+		t.Errorf("active once the delay expired = %+v", active)
+	}
+	mine, err := UserAnnouncements(ctx, database, player.ID, now)
+	if err != nil || len(mine) != 2 || mine[0].TournamentName != "Announced" {
+		t.Errorf("UserAnnouncements = %+v, %v", mine, err)
+	}
+	if mine, _ = UserAnnouncements(ctx, database, org.ID, now); len(mine) != 0 {
+		t.Errorf("announcements for an unregistered user: %+v", mine)
+	}
+
+	if err := EndAnnouncement(ctx, database, tourn.ID, lunch.ID, now); err != nil {
+		t.Fatal(err)
+	}
+	if err := EndAnnouncement(ctx, database, tourn.ID, lunch.ID, now); !errors.Is(err, ErrAnnouncementNotFound) {
+		t.Errorf("ending twice: err = %v, want ErrAnnouncementNotFound", err)
+	}
+	if active, _ = ActiveAnnouncements(ctx, database, tourn.ID, now); len(active) != 1 || active[0].ID != delay.ID {
+		t.Errorf("active after ending lunch = %+v", active)
+	}
+	all, err := ListAnnouncements(ctx, database, tourn.ID)
+	if err != nil || len(all) != 2 || all[0].ExpiresAt == nil {
+		t.Errorf("history = %+v, %v; want both, lunch ended", all, err)
+	}
+}
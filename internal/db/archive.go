@@ -0,0 +1,156 @@
+package db
+
+import (
+	"context"
+
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+// ArchiveTournament files tournament tournamentID in the archive with its
+// final results, replacing any it was archived with before.
+func ArchiveTournament(ctx context.Context, db DBTX, tournamentID int64, results []models.ArchivedResult) error {
+	if err := UnarchiveTournament(ctx, db, tournamentID); err != nil {
+		return err
+	}
+	if _, err := db.ExecContext(ctx,
+		`INSERT INTO archives (tournament_id, players) VALUES ($1, $2)`, tournamentID, len(results),
+	); err != nil {
+		return err
+	}
+	for _, r := range results {
+		if _, err := db.ExecContext(ctx,
+			`INSERT INTO archive_results (tournament_id, place, name, playoff, points, wins, losses, draws, user_id, player_id)
+			 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
+			tournamentID, r.Place, r.Name, r.Playoff, r.Points, r.Wins, r.Losses, r.Draws, r.UserID, r.PlayerID,
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UnarchiveTournament takes tournament tournamentID out of the archive, as
+// when it is reset or rolled back to before it finished.
+func UnarchiveTournament(ctx context.Context, db DBTX, tournamentID int64) error {
+	_, err := db.ExecContext(ctx, `DELETE FROM archives WHERE tournament_id = $1`, tournamentID)
+	return err
+}
+
+// ListArchivedEvents returns every archived tournament, most recent first:
+// by the date it was scheduled for, else when it was archived.
+func ListArchivedEvents(ctx context.Context, db DBTX) ([]models.ArchivedEvent, error) {
+	rows, err := db.QueryContext(ctx,
+		`SELECT t.id, t.name, t.scheduled_at, t.location, a.players, COALESCE(w.name, ''), a.archived_at
+		 FROM archives a
+		 JOIN tournaments t ON t.id = a.tournament_id
+		 LEFT JOIN archive_results w ON w.tournament_id = a.tournament_id AND w.place = 1
+		 ORDER BY COALESCE(t.scheduled_at, a.archived_at) DESC, t.id DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []models.ArchivedEvent
+	for rows.Next() {
+		var e models.ArchivedEvent
+		if err := rows.Scan(&e.TournamentID, &e.Name, &e.ScheduledAt, &e.Location, &e.Players, &e.Winner, &e.ArchivedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
+
+// SearchArchive returns the archived finishes of players whose name
+// contains name, ignoring case, most recent event first.
+func SearchArchive(ctx context.Context, db DBTX, name string) ([]models.ArchivedResult, error) {
+	rows, err := db.QueryContext(ctx,
+		`SELECT r.tournament_id, t.name, r.place, r.name, r.playoff, r.points, r.wins, r.losses, r.draws, r.user_id, r.player_id
+		 FROM archive_results r
+		 JOIN archives a ON a.tournament_id = r.tournament_id
+		 JOIN tournaments t ON t.id = r.tournament_id
+		 WHERE r.name ILIKE '%' || $1 || '%'
+		 ORDER BY COALESCE(t.scheduled_at, a.archived_at) DESC, t.id DESC, r.place`,
+		name,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []models.ArchivedResult
+	for rows.Next() {
+		var r models.ArchivedResult
+		if err := rows.Scan(&r.TournamentID, &r.Tournament, &r.Place, &r.Name, &r.Playoff,
+			&r.Points, &r.Wins, &r.Losses, &r.Draws, &r.UserID, &r.PlayerID); err != nil {
+			return nil, err
+		}
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
+
+// GetArchivedEvent returns tournament tournamentID as the archive lists it,
+// or sql.ErrNoRows if it isn't archived.
+func GetArchivedEvent(ctx context.Context, db DBTX, tournamentID int64) (*models.ArchivedEvent, error) {
+	var e models.ArchivedEvent
+	err := db.QueryRowContext(ctx,
+		`SELECT t.id, t.name, t.scheduled_at, t.location, a.players, COALESCE(w.name, ''), a.archived_at
+		 FROM archives a
+		 JOIN tournaments t ON t.id = a.tournament_id
+		 LEFT JOIN archive_results w ON w.tournament_id = a.tournament_id AND w.place = 1
+		 WHERE a.tournament_id = $1`,
+		tournamentID,
+	).Scan(&e.TournamentID, &e.Name, &e.ScheduledAt, &e.Location, &e.Players, &e.Winner, &e.ArchivedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// ListArchivedResults returns an archived tournament's final standings by
+// place.
+func ListArchivedResults(ctx context.Context, db DBTX, tournamentID int64) ([]models.ArchivedResult, error) {
+	rows, err := db.QueryContext(ctx,
+		`SELECT tournament_id, place, name, playoff, points, wins, losses, draws, user_id, player_id
+		 FROM archive_results WHERE tournament_id = $1 ORDER BY place`,
+		tournamentID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []models.ArchivedResult
+	for rows.Next() {
+		var r models.ArchivedResult
+		if err := rows.Scan(&r.TournamentID, &r.Place, &r.Name, &r.Playoff,
+			&r.Points, &r.Wins, &r.Losses, &r.Draws, &r.UserID, &r.PlayerID); err != nil {
+			return nil, err
+		}
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
+
+// ListUnarchivedFinished returns the IDs of finished tournaments missing
+// from the archive, such as those finished before it existed.
+func ListUnarchivedFinished(ctx context.Context, db DBTX) ([]int64, error) {
+	rows, err := db.QueryContext(ctx,
+		`SELECT t.id FROM tournaments t
+		 WHERE t.status = $1
+		   AND NOT EXISTS (SELECT 1 FROM archives a WHERE a.tournament_id = t.id)
+		 ORDER BY t.id`,
+		models.TournamentStatusFinished,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
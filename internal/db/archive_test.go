@@ -0,0 +1,74 @@
+//go:build integration
+
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+func TestArchive(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	org := createTestOrganizer(t, database)
+	spring := time.Date(2026, 4, 1, 10, 0, 0, 0, time.UTC)
+	summer := time.Date(2026, 7, 1, 10, 0, 0, 0, time.UTC)
+	older := &models.Tournament{Name: "Spring Open", ScheduledAt: &spring, Status: models.TournamentStatusFinished, OrganizerID: org.ID}
+	newer := &models.Tournament{Name: "Summer Open", ScheduledAt: &summer, Status: models.TournamentStatusFinished, OrganizerID: org.ID}
+	for _, tourn := range []*models.Tournament{older, newer} {
+		if err := CreateTournament(ctx, database, tourn); err != nil {
+			t.Fatalf("CreateTournament: %v", err)
+		}
+	}
+
+	if err := ArchiveTournament(ctx, database, older.ID, []models.ArchivedResult{
+		{Place: 1, Name: "Alice", Points: 9, Wins: 3},
+		{Place: 2, Name: "Bob", Points: 6, Wins: 2, Losses: 1},
+	}); err != nil {
+		t.Fatalf("ArchiveTournament: %v", err)
+	}
+	if err := ArchiveTournament(ctx, database, newer.ID, []models.ArchivedResult{
+		{Place: 1, Name: "Bob", Playoff: "Champion", Points: 9, Wins: 3},
+	}); err != nil {
+		t.Fatalf("ArchiveTournament: %v", err)
+	}
+	// Archiving again replaces the results.
+	if err := ArchiveTournament(ctx, database, older.ID, []models.ArchivedResult{
+		{Place: 1, Name: "Alice", Points: 9, Wins: 3},
+		{Place: 2, Name: "Bob", Points: 6, Wins: 2, Losses: 1},
+		{Place: 3, Name: "Carol", Points: 0, Losses: 3},
+	}); err != nil {
+		t.Fatalf("ArchiveTournament again: %v", err)
+	}
+
+	events, err := ListArchivedEvents(ctx, database)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 || events[0].TournamentID != newer.ID || events[0].Winner != "Bob" ||
+		events[1].Players != 3 || events[1].Winner != "Alice" {
+		t.Errorf("events = %+v, want Summer Open won by Bob, then Spring Open's 3 players won by Alice", events)
+	}
+
+	found, err := SearchArchive(ctx, database, "bOB")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 2 || found[0].Tournament != "Summer Open" || found[0].Playoff != "Champion" ||
+		found[1].Tournament != "Spring Open" || found[1].Place != 2 {
+		t.Errorf("search = %+v, want Bob's title then his second place", found)
+	}
+
+	if err := UnarchiveTournament(ctx, database, newer.ID); err != nil {
+		t.Fatal(err)
+	}
+	if found, _ := SearchArchive(ctx, database, "bob"); len(found) != 1 {
+		t.Errorf("search after unarchiving = %+v, want only the Spring Open", found)
+	}
+	if events, _ := ListArchivedEvents(ctx, database); len(events) != 1 {
+		t.Errorf("events after unarchiving = %+v", events)
+	}
+}
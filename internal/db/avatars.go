@@ -0,0 +1,77 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+// ErrAvatarNotFound is returned when a user has no avatar.
+var ErrAvatarNotFound = errors.New("avatar: not found")
+
+// SetAvatar saves a's icon or image as its user's avatar, replacing any
+// earlier one, and fills in UpdatedAt.
+func SetAvatar(ctx context.Context, db DBTX, a *models.Avatar) error {
+	var icon *string
+	var image []byte
+	if a.Image != nil {
+		image = a.Image
+	} else {
+		icon = &a.Icon
+	}
+	return db.QueryRowContext(ctx,
+		`INSERT INTO avatars (user_id, icon, image) VALUES ($1, $2, $3)
+		 ON CONFLICT (user_id) DO UPDATE SET icon = $2, image = $3, updated_at = now()
+		 RETURNING updated_at`,
+		a.UserID, icon, image,
+	).Scan(&a.UpdatedAt)
+}
+
+// GetAvatar returns userID's avatar, image included, or ErrAvatarNotFound.
+func GetAvatar(ctx context.Context, db DBTX, userID int64) (*models.Avatar, error) {
+	a := &models.Avatar{UserID: userID}
+	var icon sql.NullString
+	err := db.QueryRowContext(ctx,
+		`SELECT icon, image, updated_at FROM avatars WHERE user_id = $1`, userID,
+	).Scan(&icon, &a.Image, &a.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrAvatarNotFound
+	}
+	a.Icon = icon.String
+	return a, err
+}
+
+// DeleteAvatar removes userID's avatar, if any.
+func DeleteAvatar(ctx context.Context, db DBTX, userID int64) error {
+	_, err := db.ExecContext(ctx, `DELETE FROM avatars WHERE user_id = $1`, userID)
+	return err
+}
+
+// TournamentAvatars returns the avatars of tournamentID's players who have
+// one, keyed by engine player ID, without their images. Guests have none.
+func TournamentAvatars(ctx context.Context, db DBTX, tournamentID int64) (map[int]*models.Avatar, error) {
+	rows, err := db.QueryContext(ctx,
+		`SELECT r.engine_player_id, a.user_id, a.icon, a.updated_at
+		 FROM registrations r JOIN avatars a ON a.user_id = r.user_id
+		 WHERE r.tournament_id = $1 AND r.engine_player_id IS NOT NULL`,
+		tournamentID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := map[int]*models.Avatar{}
+	for rows.Next() {
+		var enginePlayerID int
+		var icon sql.NullString
+		a := &models.Avatar{}
+		if err := rows.Scan(&enginePlayerID, &a.UserID, &icon, &a.UpdatedAt); err != nil {
+			return nil, err
+		}
+		a.Icon = icon.String
+		out[enginePlayerID] = a
+	}
+	return out, rows.Err()
+}
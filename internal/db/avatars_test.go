@@ -0,0 +1,77 @@
+//go:build integration
+
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+func TestAvatars(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	org := createTestOrganizer(t, database)
+	player, err := CreateUser(ctx, database, "avatar@example.com", "Ava", "hash")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := GetAvatar(ctx, database, player.ID); !errors.Is(err, ErrAvatarNotFound) {
+		t.Fatalf("no avatar: err = %v", err)
+	}
+	if err := SetAvatar(ctx, database, &models.Avatar{UserID: player.ID, Icon: "owl"}); err != nil {
+		t.Fatal(err)
+	}
+	png := []byte("\x89PNG fake")
+	if err := SetAvatar(ctx, database, &models.Avatar{UserID: org.ID, Image: png}); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := GetAvatar(ctx, database, org.ID); err != nil || string(got.Image) != string(png) || got.Icon != "" {
+		t.Errorf("image avatar = %+v, %v", got, err)
+	}
+
+	// An upload replaces the icon.
+	if err := SetAvatar(ctx, database, &models.Avatar{UserID: player.ID, Image: png}); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := GetAvatar(ctx, database, player.ID); got.Icon != "" || got.Image == nil {
+		t.Errorf("after upload = %+v", got)
+	}
+	if err := SetAvatar(ctx, database, &models.Avatar{UserID: player.ID, Icon: "fox"}); err != nil {
+		t.Fatal(err)
+	}
+
+	tourn := &models.Tournament{Name: "Faces", Status: models.TournamentStatusRegistrationOpen, OrganizerID: org.ID}
+	if err := CreateTournament(ctx, database, tourn); err != nil {
+		t.Fatal(err)
+	}
+	for i, u := range []*models.User{player, org} {
+		reg, err := CreateRegistration(ctx, database, tourn.ID, u.ID, u.DisplayName)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := UpdateRegistrationEnginePlayerID(ctx, database, reg.ID, i+1); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := CreateGuestRegistration(ctx, database, tourn.ID, "Guest"); err != nil {
+		t.Fatal(err)
+	}
+	avatars, err := TournamentAvatars(ctx, database, tourn.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(avatars) != 2 || avatars[1].Icon != "fox" || avatars[2].UserID != org.ID || avatars[2].Image != nil {
+		t.Errorf("TournamentAvatars = %+v", avatars)
+	}
+
+	if err := DeleteAvatar(ctx, database, player.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := GetAvatar(ctx, database, player.ID); !errors.Is(err, ErrAvatarNotFound) {
+		t.Errorf("after delete: err = %v", err)
+	}
+}
@@ -0,0 +1,458 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/lib/pq"
+)
+
+// BackupVersion is the format version written into every tournament
+// backup. Restores refuse other versions.
+const BackupVersion = 1
+
+// TournamentBackup is everything needed to carry one tournament to another
+// OpenSwiss server: its settings, status and engine state, every
+// registration (pending and dropped ones included), assigned byes, custom
+// pairing fields with their values, series games, seat results, result
+// types, result corrections, feature matches and the schedule. Staff,
+// webhooks and handoffs belong to the server's accounts and stay behind.
+//
+// Registrations are tied to accounts by email, since user IDs differ
+// between servers; IDs in the backup are only references within it.
+type TournamentBackup struct {
+	Version       int                       `json:"openswiss_backup"`
+	CreatedAt     time.Time                 `json:"created_at"`
+	Tournament    models.Tournament         `json:"tournament"`
+	EngineState   json.RawMessage           `json:"engine_state,omitempty"`
+	Registrations []BackupRegistration      `json:"registrations"`
+	AssignedByes  []BackupBye               `json:"assigned_byes"`
+	PairingFields []BackupPairingField      `json:"pairing_fields"`
+	MatchGames    []models.MatchGame        `json:"match_games"`
+	SeatResults   []models.SeatResult       `json:"seat_results,omitempty"`
+	ResultTypes   []models.MatchResultType  `json:"result_types"`
+	Corrections   []models.ResultCorrection `json:"corrections,omitempty"`
+	Schedule      []models.ScheduleItem     `json:"schedule,omitempty"`
+	Features      []BackupFeatureMatch      `json:"feature_matches,omitempty"`
+}
+
+// BackupRegistration is a registration in a backup. UserEmail is empty for
+// a guest.
+type BackupRegistration struct {
+	ID             int64           `json:"id"`
+	UserEmail      string          `json:"user_email,omitempty"`
+	DisplayName    string          `json:"display_name"`
+	Decklist       json.RawMessage `json:"decklist,omitempty"`
+	Status         string          `json:"status"`
+	EnginePlayerID *int            `json:"engine_player_id,omitempty"`
+	Rating         *int            `json:"rating,omitempty"`
+	Archetype      *string         `json:"archetype,omitempty"`
+	Club           *string         `json:"club,omitempty"`
+	Members        []string        `json:"members,omitempty"`
+	TiebreakSeed   *int64          `json:"tiebreak_seed,omitempty"`
+	PaymentStatus  string          `json:"payment_status,omitempty"`
+	PaymentAmount  *int            `json:"payment_amount,omitempty"`
+	PaymentMethod  *string         `json:"payment_method,omitempty"`
+	ContactConsent bool            `json:"contact_consent,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+// BackupBye is an assigned bye; RegistrationID refers to a
+// BackupRegistration.
+type BackupBye struct {
+	Round          int   `json:"round"`
+	RegistrationID int64 `json:"registration_id"`
+}
+
+// BackupPairingField is a custom pairing field and every value entered
+// for it.
+type BackupPairingField struct {
+	Label  string             `json:"label"`
+	Public bool               `json:"public"`
+	Values []BackupFieldValue `json:"values"`
+}
+
+// BackupFieldValue is one pairing field value, by round and table.
+type BackupFieldValue struct {
+	Round int    `json:"round"`
+	Table int    `json:"table"`
+	Value string `json:"value"`
+}
+
+// BackupFeatureMatch is a feature match, by round and table.
+type BackupFeatureMatch struct {
+	Round int `json:"round"`
+	Table int `json:"table"`
+}
+
+// Filename is the name a backup downloads under: the tournament's ID and
+// when the backup was taken.
+func (b *TournamentBackup) Filename() string {
+	return fmt.Sprintf("openswiss-tournament-%d-%s.json", b.Tournament.ID, b.CreatedAt.Format("20060102-150405"))
+}
+
+// ErrBackupUserConflict is returned when a backup holds two registrations
+// for the same account.
+var ErrBackupUserConflict = errors.New("backup: two registrations for one account")
+
+// BackupTournament reads a tournament into a backup. It reads in one
+// repeatable-read transaction, so a round advancing meanwhile can't leave
+// the engine state and the result tables out of step.
+func BackupTournament(ctx context.Context, database *sql.DB, id int64) (*TournamentBackup, error) {
+	tx, err := database.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	return backupTournament(ctx, tx, id)
+}
+
+func backupTournament(ctx context.Context, db DBTX, id int64) (*TournamentBackup, error) {
+	b := &TournamentBackup{Version: BackupVersion, CreatedAt: time.Now().UTC()}
+	var engineState []byte
+	if err := db.QueryRowContext(ctx,
+		`SELECT `+tournamentCols+`, engine_state FROM tournaments WHERE id = $1`, id,
+	).Scan(append(tournamentDest(&b.Tournament), &engineState)...); err != nil {
+		return nil, err
+	}
+	b.EngineState = engineState
+
+	rows, err := db.QueryContext(ctx,
+		`SELECT r.id, COALESCE(u.email, ''), r.display_name, r.decklist, r.status,
+		        r.engine_player_id, r.rating, r.archetype, r.club, r.members, r.tiebreak_seed,
+		        r.payment_status, r.payment_amount, r.payment_method, r.contact_consent, r.created_at
+		 FROM registrations r LEFT JOIN users u ON u.id = r.user_id
+		 WHERE r.tournament_id = $1 ORDER BY r.id`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var r BackupRegistration
+		var decklist []byte
+		if err := rows.Scan(&r.ID, &r.UserEmail, &r.DisplayName, &decklist, &r.Status,
+			&r.EnginePlayerID, &r.Rating, &r.Archetype, &r.Club, pq.Array(&r.Members), &r.TiebreakSeed,
+			&r.PaymentStatus, &r.PaymentAmount, &r.PaymentMethod, &r.ContactConsent, &r.CreatedAt); err != nil {
+			return nil, err
+		}
+		r.Decklist = decklist
+		b.Registrations = append(b.Registrations, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	byes, err := ListAssignedByes(ctx, db, id)
+	if err != nil {
+		return nil, err
+	}
+	for _, bye := range byes {
+		b.AssignedByes = append(b.AssignedByes, BackupBye{Round: bye.Round, RegistrationID: bye.RegistrationID})
+	}
+
+	fields, err := ListPairingFields(ctx, db, id)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range fields {
+		bf := BackupPairingField{Label: f.Label, Public: f.Public}
+		if bf.Values, err = listFieldValues(ctx, db, f.ID); err != nil {
+			return nil, err
+		}
+		b.PairingFields = append(b.PairingFields, bf)
+	}
+
+	if b.MatchGames, err = ListAllMatchGames(ctx, db, id); err != nil {
+		return nil, err
+	}
+	if b.SeatResults, err = ListAllSeatResults(ctx, db, id); err != nil {
+		return nil, err
+	}
+	if b.ResultTypes, err = listAllResultTypes(ctx, db, id); err != nil {
+		return nil, err
+	}
+	if b.Corrections, err = ListResultCorrections(ctx, db, id); err != nil {
+		return nil, err
+	}
+	if b.Schedule, err = ListSchedule(ctx, db, id); err != nil {
+		return nil, err
+	}
+	if b.Features, err = listAllFeatureMatches(ctx, db, id); err != nil {
+		return nil, err
+	}
+	// Reporters are accounts of this server; the IDs mean nothing elsewhere.
+	for i := range b.MatchGames {
+		b.MatchGames[i].ReportedBy = nil
+	}
+	for i := range b.SeatResults {
+		b.SeatResults[i].ReportedBy = nil
+	}
+	for i := range b.ResultTypes {
+		b.ResultTypes[i].ReportedBy = nil
+	}
+	for i := range b.Corrections {
+		b.Corrections[i].CorrectedBy = nil
+	}
+	return b, nil
+}
+
+func listFieldValues(ctx context.Context, db DBTX, fieldID int64) ([]BackupFieldValue, error) {
+	rows, err := db.QueryContext(ctx,
+		`SELECT round, table_number, value FROM pairing_field_values
+		 WHERE field_id = $1 ORDER BY round, table_number`, fieldID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []BackupFieldValue
+	for rows.Next() {
+		var v BackupFieldValue
+		if err := rows.Scan(&v.Round, &v.Table, &v.Value); err != nil {
+			return nil, err
+		}
+		out = append(out, v)
+	}
+	return out, rows.Err()
+}
+
+func listAllResultTypes(ctx context.Context, db DBTX, tournamentID int64) ([]models.MatchResultType, error) {
+	rows, err := db.QueryContext(ctx,
+		`SELECT round, table_number, type, conceded_by, reported_by, reported_at
+		 FROM match_result_types WHERE tournament_id = $1 ORDER BY round, table_number`, tournamentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []models.MatchResultType
+	for rows.Next() {
+		rt := models.MatchResultType{TournamentID: tournamentID}
+		if err := rows.Scan(&rt.Round, &rt.Table, &rt.Type, &rt.ConcededBy, &rt.ReportedBy, &rt.ReportedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, rt)
+	}
+	return out, rows.Err()
+}
+
+func listAllFeatureMatches(ctx context.Context, db DBTX, tournamentID int64) ([]BackupFeatureMatch, error) {
+	rows, err := db.QueryContext(ctx,
+		`SELECT round, table_number FROM feature_matches
+		 WHERE tournament_id = $1 ORDER BY round, table_number`, tournamentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []BackupFeatureMatch
+	for rows.Next() {
+		var f BackupFeatureMatch
+		if err := rows.Scan(&f.Round, &f.Table); err != nil {
+			return nil, err
+		}
+		out = append(out, f)
+	}
+	return out, rows.Err()
+}
+
+// RestoreTournamentBackup writes a backup within tx. With replaceID 0 it
+// creates a new tournament owned, and administered, by organizerID.
+// Otherwise it replaces the contents of tournament replaceID, keeping its
+// organizer, staff and webhooks. Registrations whose email matches an
+// account on this server are linked to it; the rest become guests under
+// their display name. The backup must already have been checked (see
+// engine.CheckBackup).
+func RestoreTournamentBackup(ctx context.Context, tx *sql.Tx, b *TournamentBackup, organizerID, replaceID int64) (int64, error) {
+	t := b.Tournament
+	if t.Round1Pairing == "" {
+		// Backups from before seeded round 1 existed.
+		t.Round1Pairing = models.Round1Random
+	}
+	if t.TimeZone == "" {
+		// Backups from before tournaments had a time zone.
+		t.TimeZone = models.DefaultTimeZone
+	}
+	var engineState []byte
+	if len(b.EngineState) > 0 {
+		engineState = b.EngineState
+	}
+	id := replaceID
+	if id == 0 {
+		if err := tx.QueryRowContext(ctx,
+			`INSERT INTO tournaments (name, description, scheduled_at, location, max_players, num_rounds,
+			 require_decklist, decklist_public, points_win, points_draw, points_loss, top_cut, pairing_algorithm,
+			 bye_policy, round1_pairing, best_of, series_mode, team_size, min_players, status, organizer_id, engine_state,
+			 time_zone, preview_pairings, draft_round, tiebreakers, colors, avoid_club_rounds, auto_rounds, entry_fee,
+			 self_report, report_confirm_minutes, bot_check, blind_final_round)
+			 VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18,$19,$20,$21,$22,$23,$24,$25,$26,$27,$28,$29,$30,$31,$32,$33,$34)
+			 RETURNING id`,
+			t.Name, t.Description, t.ScheduledAt, t.Location, t.MaxPlayers, t.NumRounds,
+			t.RequireDecklist, t.DecklistPublic, t.PointsWin, t.PointsDraw, t.PointsLoss,
+			t.TopCut, t.PairingAlgorithm, t.ByePolicy, t.Round1Pairing, t.BestOf, t.SeriesMode, t.TeamSize, t.MinPlayers, t.Status, organizerID, engineState,
+			t.TimeZone, t.PreviewPairings, t.DraftRound, pq.Array(t.TiebreakOrder()), t.Colors, t.AvoidClubRounds, t.AutoRounds, t.EntryFee,
+			t.SelfReport, t.ReportConfirmMinutes, t.BotCheck, t.BlindFinalRound,
+		).Scan(&id); err != nil {
+			return 0, err
+		}
+		if err := AddTournamentStaff(ctx, tx, &models.TournamentStaff{
+			TournamentID: id,
+			UserID:       organizerID,
+			Tier:         models.TierAdmin,
+			GrantedBy:    &organizerID,
+		}); err != nil {
+			return 0, err
+		}
+	} else {
+		if _, err := GetTournamentForUpdate(ctx, tx, id); err != nil {
+			return 0, err
+		}
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE tournaments SET name=$1, description=$2, scheduled_at=$3, location=$4,
+			 max_players=$5, num_rounds=$6, require_decklist=$7, decklist_public=$8,
+			 points_win=$9, points_draw=$10, points_loss=$11, top_cut=$12, pairing_algorithm=$13,
+			 bye_policy=$14, round1_pairing=$15, best_of=$16, series_mode=$17, team_size=$18, min_players=$19,
+			 status=$20, engine_state=$21, time_zone=$22, preview_pairings=$23, draft_round=$24, tiebreakers=$25, colors=$26, avoid_club_rounds=$27, auto_rounds=$28, entry_fee=$29,
+			 self_report=$30, report_confirm_minutes=$31, bot_check=$32, blind_final_round=$33, updated_at=now()
+			 WHERE id=$34`,
+			t.Name, t.Description, t.ScheduledAt, t.Location, t.MaxPlayers, t.NumRounds,
+			t.RequireDecklist, t.DecklistPublic, t.PointsWin, t.PointsDraw, t.PointsLoss,
+			t.TopCut, t.PairingAlgorithm, t.ByePolicy, t.Round1Pairing, t.BestOf, t.SeriesMode, t.TeamSize, t.MinPlayers, t.Status,
+			engineState, t.TimeZone, t.PreviewPairings, t.DraftRound, pq.Array(t.TiebreakOrder()), t.Colors, t.AvoidClubRounds, t.AutoRounds, t.EntryFee,
+			t.SelfReport, t.ReportConfirmMinutes, t.BotCheck, t.BlindFinalRound, id,
+		); err != nil {
+			return 0, err
+		}
+		// Assigned byes and field values go with their rows via the cascades.
+		for _, q := range []string{
+			`DELETE FROM registrations WHERE tournament_id = $1`,
+			`DELETE FROM pairing_fields WHERE tournament_id = $1`,
+			`DELETE FROM match_games WHERE tournament_id = $1`,
+			`DELETE FROM seat_results WHERE tournament_id = $1`,
+			`DELETE FROM match_result_types WHERE tournament_id = $1`,
+			`DELETE FROM result_reports WHERE tournament_id = $1`,
+			`DELETE FROM result_corrections WHERE tournament_id = $1`,
+			`DELETE FROM feature_matches WHERE tournament_id = $1`,
+		} {
+			if _, err := tx.ExecContext(ctx, q, id); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	regIDs := make(map[int64]int64, len(b.Registrations))
+	linked := map[int64]bool{}
+	for _, r := range b.Registrations {
+		var userID *int64
+		if r.UserEmail != "" {
+			var uid int64
+			err := tx.QueryRowContext(ctx, `SELECT id FROM users WHERE email = $1`, r.UserEmail).Scan(&uid)
+			switch {
+			case err == nil:
+				if linked[uid] {
+					return 0, fmt.Errorf("%w: %s", ErrBackupUserConflict, r.UserEmail)
+				}
+				linked[uid] = true
+				userID = &uid
+			case !errors.Is(err, sql.ErrNoRows):
+				return 0, err
+			}
+		}
+		var guestName *string
+		if userID == nil {
+			guestName = &r.DisplayName
+		}
+		var decklist []byte
+		if len(r.Decklist) > 0 {
+			decklist = r.Decklist
+		}
+		createdAt := r.CreatedAt
+		if createdAt.IsZero() {
+			createdAt = time.Now()
+		}
+		// Backups from before payments were tracked have no payment status.
+		paymentStatus := r.PaymentStatus
+		if paymentStatus == "" {
+			paymentStatus = models.PaymentUnpaid
+		}
+		var newID int64
+		if err := tx.QueryRowContext(ctx,
+			`INSERT INTO registrations (tournament_id, user_id, guest_name, display_name, decklist,
+			 status, engine_player_id, rating, archetype, club, members, tiebreak_seed,
+			 payment_status, payment_amount, payment_method, contact_consent, created_at)
+			 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
+			 RETURNING id`,
+			id, userID, guestName, r.DisplayName, decklist, r.Status, r.EnginePlayerID, r.Rating,
+			r.Archetype, r.Club, pq.Array(members(r.Members)), r.TiebreakSeed,
+			paymentStatus, r.PaymentAmount, r.PaymentMethod, r.ContactConsent && userID != nil, createdAt,
+		).Scan(&newID); err != nil {
+			return 0, err
+		}
+		regIDs[r.ID] = newID
+	}
+
+	for _, bye := range b.AssignedByes {
+		if err := AddAssignedBye(ctx, tx, &models.AssignedBye{
+			TournamentID:   id,
+			Round:          bye.Round,
+			RegistrationID: regIDs[bye.RegistrationID],
+		}); err != nil {
+			return 0, err
+		}
+	}
+	for _, bf := range b.PairingFields {
+		f := &models.PairingField{TournamentID: id, Label: bf.Label, Public: bf.Public}
+		if err := CreatePairingField(ctx, tx, f); err != nil {
+			return 0, err
+		}
+		for _, v := range bf.Values {
+			if err := SetPairingFieldValue(ctx, tx, f.ID, v.Round, v.Table, v.Value); err != nil {
+				return 0, err
+			}
+		}
+	}
+	for _, g := range b.MatchGames {
+		g.TournamentID, g.ReportedBy = id, nil
+		if err := AddMatchGame(ctx, tx, &g); err != nil {
+			return 0, err
+		}
+	}
+	for _, sr := range b.SeatResults {
+		sr.TournamentID, sr.ReportedBy = id, nil
+		if err := SetSeatResult(ctx, tx, &sr); err != nil {
+			return 0, err
+		}
+	}
+	for _, rt := range b.ResultTypes {
+		rt.TournamentID, rt.ReportedBy = id, nil
+		if err := SetMatchResultType(ctx, tx, &rt); err != nil {
+			return 0, err
+		}
+	}
+	for _, c := range b.Corrections {
+		c.TournamentID, c.CorrectedBy = id, nil
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO result_corrections (tournament_id, round, table_number, old_a, old_b, old_draws,
+			 new_a, new_b, new_draws, paired_through, created_at)
+			 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`,
+			c.TournamentID, c.Round, c.Table, c.OldA, c.OldB, c.OldDraws,
+			c.NewA, c.NewB, c.NewDraws, c.PairedThrough, c.CreatedAt,
+		); err != nil {
+			return 0, err
+		}
+	}
+	for _, f := range b.Features {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO feature_matches (tournament_id, round, table_number) VALUES ($1, $2, $3)`,
+			id, f.Round, f.Table,
+		); err != nil {
+			return 0, err
+		}
+	}
+	if err := ReplaceSchedule(ctx, tx, id, b.Schedule); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
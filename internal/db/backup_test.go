@@ -0,0 +1,182 @@
+//go:build integration
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+func TestBackupAndRestoreTournament(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	org := createTestOrganizer(t, database)
+	rounds := 3
+	tourn := &models.Tournament{Name: "Backup", NumRounds: &rounds, PointsWin: 3, BestOf: 3, SeriesMode: true,
+		TimeZone: "Europe/Berlin", PreviewPairings: true, AvoidClubRounds: 2, AutoRounds: true, EntryFee: 500, Status: models.TournamentStatusRegistrationOpen, OrganizerID: org.ID}
+	if err := CreateTournament(ctx, database, tourn); err != nil {
+		t.Fatalf("CreateTournament: %v", err)
+	}
+	player := createTestPlayer(t, database, "Backup Player")
+	reg, err := CreateRegistration(ctx, database, tourn.ID, player.ID, player.DisplayName)
+	if err != nil {
+		t.Fatalf("CreateRegistration: %v", err)
+	}
+	if err := UpdateRegistrationDecklistByID(ctx, database, reg.ID, []byte(`{"main":{"Forest":60}}`)); err != nil {
+		t.Fatalf("UpdateRegistrationDecklistByID: %v", err)
+	}
+	guest, err := CreateGuestRegistration(ctx, database, tourn.ID, "Walk-in")
+	if err != nil {
+		t.Fatalf("CreateGuestRegistration: %v", err)
+	}
+	club := "Walkers"
+	if err := SetClubs(ctx, database, tourn.ID, map[int64]*string{guest.ID: &club}); err != nil {
+		t.Fatalf("SetClubs: %v", err)
+	}
+	if _, err := SetPayment(ctx, database, tourn.ID, guest.ID, models.PaymentComped, nil, nil); err != nil {
+		t.Fatalf("SetPayment: %v", err)
+	}
+	if err := AddAssignedBye(ctx, database, &models.AssignedBye{TournamentID: tourn.ID, Round: 2, RegistrationID: guest.ID}); err != nil {
+		t.Fatalf("AddAssignedBye: %v", err)
+	}
+	field := &models.PairingField{TournamentID: tourn.ID, Label: "Stream", Public: true}
+	if err := CreatePairingField(ctx, database, field); err != nil {
+		t.Fatalf("CreatePairingField: %v", err)
+	}
+	if err := SetPairingFieldValue(ctx, database, field.ID, 1, 1, "main stage"); err != nil {
+		t.Fatalf("SetPairingFieldValue: %v", err)
+	}
+	if err := AddMatchGame(ctx, database, &models.MatchGame{TournamentID: tourn.ID, Round: 1, Table: 1, GameNumber: 1, Winner: models.GameWinnerA, ReportedBy: &org.ID}); err != nil {
+		t.Fatalf("AddMatchGame: %v", err)
+	}
+	if err := SetMatchResultType(ctx, database, &models.MatchResultType{TournamentID: tourn.ID, Round: 1, Table: 2, Type: models.ResultIntentionalDraw}); err != nil {
+		t.Fatalf("SetMatchResultType: %v", err)
+	}
+	tx, err := database.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	lunch := models.ScheduleItem{StartsAt: time.Date(2026, 5, 1, 11, 0, 0, 0, time.UTC), Label: "Lunch"}
+	if err := ReplaceSchedule(ctx, tx, tourn.ID, []models.ScheduleItem{lunch}); err != nil {
+		t.Fatalf("ReplaceSchedule: %v", err)
+	}
+	if err := ReplaceFeatureMatches(ctx, tx, tourn.ID, 1, []int{2}); err != nil {
+		t.Fatalf("ReplaceFeatureMatches: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := BackupTournament(ctx, database, tourn.ID)
+	if err != nil {
+		t.Fatalf("BackupTournament: %v", err)
+	}
+	if b.Version != BackupVersion || b.Tournament.Name != "Backup" || !b.Tournament.SeriesMode {
+		t.Errorf("tournament = %+v", b.Tournament)
+	}
+	if len(b.Registrations) != 2 || b.Registrations[0].UserEmail != player.Email || b.Registrations[0].Decklist == nil || b.Registrations[1].UserEmail != "" {
+		t.Errorf("registrations = %+v", b.Registrations)
+	}
+	if len(b.AssignedByes) != 1 || b.AssignedByes[0].RegistrationID != guest.ID {
+		t.Errorf("byes = %+v", b.AssignedByes)
+	}
+	if len(b.PairingFields) != 1 || len(b.PairingFields[0].Values) != 1 {
+		t.Errorf("pairing fields = %+v", b.PairingFields)
+	}
+	if len(b.MatchGames) != 1 || b.MatchGames[0].ReportedBy != nil || len(b.ResultTypes) != 1 {
+		t.Errorf("games %+v, result types %+v", b.MatchGames, b.ResultTypes)
+	}
+	if len(b.Schedule) != 1 || b.Schedule[0].Label != "Lunch" {
+		t.Errorf("schedule = %+v", b.Schedule)
+	}
+	if len(b.Features) != 1 || b.Features[0] != (BackupFeatureMatch{Round: 1, Table: 2}) {
+		t.Errorf("feature matches = %+v", b.Features)
+	}
+	if _, err := BackupTournament(ctx, database, -1); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("missing tournament: err = %v", err)
+	}
+
+	// Into a new tournament, owned by whoever restores it.
+	admin := createTestPlayer(t, database, "Restorer")
+	id, err := restoreBackup(t, database, b, admin.ID, 0)
+	if err != nil {
+		t.Fatalf("RestoreTournamentBackup: %v", err)
+	}
+	restored, _ := GetTournament(ctx, database, id)
+	if restored.Name != "Backup" || restored.OrganizerID != admin.ID || !restored.SeriesMode || restored.TimeZone != "Europe/Berlin" || !restored.PreviewPairings || restored.AvoidClubRounds != 2 || !restored.AutoRounds || restored.EntryFee != 500 {
+		t.Errorf("restored tournament = %+v", restored)
+	}
+	if tier, err := GetTournamentTier(ctx, database, id, admin.ID); err != nil || tier != models.TierAdmin {
+		t.Errorf("restorer tier = %q, %v; want admin", tier, err)
+	}
+	regs, _ := ListRegistrations(ctx, database, id)
+	if len(regs) != 2 || regs[0].UserID == nil || *regs[0].UserID != player.ID || regs[0].Decklist == nil || !regs[1].IsGuest() || regs[1].Club == nil || *regs[1].Club != club ||
+		regs[0].PaymentStatus != models.PaymentUnpaid || regs[1].PaymentStatus != models.PaymentComped {
+		t.Errorf("restored registrations = %+v", regs)
+	}
+	byes, _ := ListAssignedByes(ctx, database, id)
+	if len(byes) != 1 || byes[0].DisplayName != "Walk-in" {
+		t.Errorf("restored byes = %+v", byes)
+	}
+	values, _ := ListPairingFieldValues(ctx, database, id, 1)
+	if len(values[1]) != 1 {
+		t.Errorf("restored field values = %+v", values)
+	}
+	games, _ := ListMatchGames(ctx, database, id, 1)
+	types, _ := ListMatchResultTypes(ctx, database, id, 1)
+	if len(games[1]) != 1 || types[2].Type != models.ResultIntentionalDraw {
+		t.Errorf("restored games %+v, result types %+v", games, types)
+	}
+	if schedule, _ := ListSchedule(ctx, database, id); len(schedule) != 1 || !schedule[0].StartsAt.Equal(lunch.StartsAt) {
+		t.Errorf("restored schedule = %+v", schedule)
+	}
+	if features, _ := ListFeatureMatches(ctx, database, id, 1); len(features) != 1 || features[0] != 2 {
+		t.Errorf("restored feature matches = %v", features)
+	}
+
+	// Over the original, after it moved on: the backup's contents win and an
+	// account unknown here turns into a guest.
+	if err := RenameRegistration(ctx, database, guest.ID, "Renamed"); err != nil {
+		t.Fatalf("RenameRegistration: %v", err)
+	}
+	b.Registrations[0].UserEmail = "nobody-here@example.com"
+	if _, err := restoreBackup(t, database, b, admin.ID, tourn.ID); err != nil {
+		t.Fatalf("RestoreTournamentBackup (replace): %v", err)
+	}
+	regs, _ = ListRegistrations(ctx, database, tourn.ID)
+	if len(regs) != 2 || !regs[0].IsGuest() || regs[0].DisplayName != player.DisplayName || regs[1].DisplayName != "Walk-in" {
+		t.Errorf("replaced registrations = %+v", regs)
+	}
+	if got, _ := GetTournament(ctx, database, tourn.ID); got.OrganizerID != org.ID {
+		t.Errorf("replace changed the organizer to %d", got.OrganizerID)
+	}
+	if _, err := restoreBackup(t, database, b, admin.ID, -1); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("replacing a missing tournament: err = %v", err)
+	}
+
+	b.Registrations[0].UserEmail = player.Email
+	b.Registrations[1].UserEmail = player.Email
+	if _, err := restoreBackup(t, database, b, admin.ID, 0); !errors.Is(err, ErrBackupUserConflict) {
+		t.Errorf("two registrations for one account: err = %v", err)
+	}
+}
+
+// restoreBackup runs RestoreTournamentBackup in a transaction of its own.
+func restoreBackup(t *testing.T, database *sql.DB, b *TournamentBackup, organizerID, replaceID int64) (int64, error) {
+	t.Helper()
+	tx, err := database.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+	id, err := RestoreTournamentBackup(context.Background(), tx, b, organizerID, replaceID)
+	if err != nil {
+		return 0, err
+	}
+	return id, tx.Commit()
+}
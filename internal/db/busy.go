@@ -0,0 +1,49 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// ErrBusy is returned when a write gives up waiting for its tournament:
+// another change held the lock past LockTimeout, or the request's deadline
+// passed first. Trying again shortly usually works.
+var ErrBusy = errors.New("the tournament is busy, try again in a moment")
+
+// LockTimeout bounds how long a write waits for the lock on its tournament
+// row. A variable so tests can shorten it.
+var LockTimeout = 5 * time.Second
+
+// lockTournamentRow runs query, a SELECT … FOR UPDATE of a tournament row,
+// waiting at most LockTimeout for the lock. Scan errors from a wait that ran
+// out become ErrBusy.
+func lockTournamentRow(ctx context.Context, tx *sql.Tx, query string, id int64, dest ...any) error {
+	ms := strconv.FormatInt(LockTimeout.Milliseconds(), 10)
+	if _, err := tx.ExecContext(ctx, `SELECT set_config('lock_timeout', $1, true)`, ms); err != nil {
+		return Busy(ctx, err)
+	}
+	return Busy(ctx, tx.QueryRowContext(ctx, query, id).Scan(dest...))
+}
+
+// Busy returns ErrBusy, wrapping err, if err comes from running out of time:
+// the lock timeout, or ctx's deadline (also what waiting for a free
+// connection ends in). Other errors are returned as they are.
+func Busy(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "55P03" { // lock_not_available
+		return fmt.Errorf("%w: %v", ErrBusy, err)
+	}
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("%w: %v", ErrBusy, err)
+	}
+	return err
+}
@@ -0,0 +1,71 @@
+//go:build integration
+
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+func TestGetTournamentForUpdate_Busy(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	org := createTestOrganizer(t, database)
+	tourn := &models.Tournament{Name: "Contended", Status: models.TournamentStatusInProgress, OrganizerID: org.ID}
+	if err := CreateTournament(ctx, database, tourn); err != nil {
+		t.Fatal(err)
+	}
+	old := LockTimeout
+	LockTimeout = 100 * time.Millisecond
+	t.Cleanup(func() { LockTimeout = old })
+
+	holder, err := database.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer holder.Rollback()
+	if _, err := GetTournamentForUpdate(ctx, holder, tourn.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	waiter, err := database.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer waiter.Rollback()
+	start := time.Now()
+	if _, err := GetTournamentForUpdate(ctx, waiter, tourn.ID); !errors.Is(err, ErrBusy) {
+		t.Fatalf("err = %v, want ErrBusy", err)
+	}
+	if waited := time.Since(start); waited > 2*time.Second {
+		t.Errorf("waited %v for a 100ms lock timeout", waited)
+	}
+
+	// The request's deadline counts too, however long the lock timeout.
+	LockTimeout = time.Minute
+	short, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	other, err := database.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer other.Rollback()
+	if err := lockTournament(short, other, tourn.ID); !errors.Is(err, ErrBusy) {
+		t.Fatalf("err = %v, want ErrBusy", err)
+	}
+
+	// Once the holder is done the lock is taken as usual.
+	holder.Rollback()
+	again, err := database.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer again.Rollback()
+	if _, err := GetTournamentForUpdate(ctx, again, tourn.ID); err != nil {
+		t.Errorf("after release: %v", err)
+	}
+}
@@ -0,0 +1,67 @@
+package db
+
+import (
+	"context"
+	"errors"
+
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+// ErrAssignedByeNotFound is returned when removing a bye that wasn't
+// assigned.
+var ErrAssignedByeNotFound = errors.New("assigned bye: not found")
+
+// ListAssignedByes returns a tournament's assigned byes by round, then
+// display name.
+func ListAssignedByes(ctx context.Context, db DBTX, tournamentID int64) ([]models.AssignedBye, error) {
+	rows, err := db.QueryContext(ctx,
+		`SELECT b.tournament_id, b.round, b.registration_id, r.display_name, r.engine_player_id,
+		        b.assigned_by, b.assigned_at
+		 FROM assigned_byes b
+		 JOIN registrations r ON r.id = b.registration_id
+		 WHERE b.tournament_id = $1
+		 ORDER BY b.round, r.display_name`,
+		tournamentID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []models.AssignedBye
+	for rows.Next() {
+		var b models.AssignedBye
+		if err := rows.Scan(&b.TournamentID, &b.Round, &b.RegistrationID, &b.DisplayName,
+			&b.EnginePlayerID, &b.AssignedBy, &b.AssignedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, b)
+	}
+	return out, rows.Err()
+}
+
+// AddAssignedBye gives a registration a bye in a round. Assigning the same
+// bye twice is not an error.
+func AddAssignedBye(ctx context.Context, db DBTX, b *models.AssignedBye) error {
+	_, err := db.ExecContext(ctx,
+		`INSERT INTO assigned_byes (tournament_id, round, registration_id, assigned_by)
+		 VALUES ($1, $2, $3, $4)
+		 ON CONFLICT (tournament_id, round, registration_id) DO NOTHING`,
+		b.TournamentID, b.Round, b.RegistrationID, b.AssignedBy,
+	)
+	return err
+}
+
+// DeleteAssignedBye takes an assigned bye back.
+func DeleteAssignedBye(ctx context.Context, db DBTX, tournamentID int64, round int, registrationID int64) error {
+	res, err := db.ExecContext(ctx,
+		`DELETE FROM assigned_byes WHERE tournament_id = $1 AND round = $2 AND registration_id = $3`,
+		tournamentID, round, registrationID,
+	)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrAssignedByeNotFound
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+//go:build integration
+
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+func TestAssignedByes_Lifecycle(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	org := createTestOrganizer(t, database)
+	tourn := &models.Tournament{Name: "Byes", Status: models.TournamentStatusRegistrationOpen, OrganizerID: org.ID}
+	if err := CreateTournament(ctx, database, tourn); err != nil {
+		t.Fatalf("CreateTournament: %v", err)
+	}
+	if tourn.ByePolicy != models.ByeLowest {
+		t.Errorf("default ByePolicy = %q, want %q", tourn.ByePolicy, models.ByeLowest)
+	}
+	judge, err := CreateGuestRegistration(ctx, database, tourn.ID, "Judge Judy")
+	if err != nil {
+		t.Fatalf("CreateGuestRegistration: %v", err)
+	}
+	player, err := CreateGuestRegistration(ctx, database, tourn.ID, "Alice")
+	if err != nil {
+		t.Fatalf("CreateGuestRegistration: %v", err)
+	}
+
+	for _, b := range []models.AssignedBye{
+		{TournamentID: tourn.ID, Round: 2, RegistrationID: judge.ID, AssignedBy: &org.ID},
+		{TournamentID: tourn.ID, Round: 1, RegistrationID: player.ID},
+		{TournamentID: tourn.ID, Round: 1, RegistrationID: judge.ID},
+		{TournamentID: tourn.ID, Round: 1, RegistrationID: judge.ID}, // again: no error
+	} {
+		if err := AddAssignedBye(ctx, database, &b); err != nil {
+			t.Fatalf("AddAssignedBye(%+v): %v", b, err)
+		}
+	}
+
+	byes, err := ListAssignedByes(ctx, database, tourn.ID)
+	if err != nil {
+		t.Fatalf("ListAssignedByes: %v", err)
+	}
+	if len(byes) != 3 {
+		t.Fatalf("byes = %+v, want 3", byes)
+	}
+	if byes[0].DisplayName != "Alice" || byes[1].DisplayName != "Judge Judy" || byes[2].Round != 2 {
+		t.Errorf("order = %+v, want round 1 Alice, round 1 Judge Judy, round 2", byes)
+	}
+	if byes[2].AssignedBy == nil || *byes[2].AssignedBy != org.ID {
+		t.Errorf("AssignedBy = %v, want %d", byes[2].AssignedBy, org.ID)
+	}
+
+	if err := DeleteAssignedBye(ctx, database, tourn.ID, 1, judge.ID); err != nil {
+		t.Fatalf("DeleteAssignedBye: %v", err)
+	}
+	if err := DeleteAssignedBye(ctx, database, tourn.ID, 1, judge.ID); !errors.Is(err, ErrAssignedByeNotFound) {
+		t.Errorf("second delete: got %v, want ErrAssignedByeNotFound", err)
+	}
+
+	// Removing the registration takes its byes with it.
+	if err := DeleteRegistrationByID(ctx, database, player.ID); err != nil {
+		t.Fatalf("DeleteRegistrationByID: %v", err)
+	}
+	if byes, _ = ListAssignedByes(ctx, database, tourn.ID); len(byes) != 1 || byes[0].RegistrationID != judge.ID {
+		t.Errorf("byes after deleting a registration = %+v", byes)
+	}
+}
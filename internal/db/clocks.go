@@ -0,0 +1,86 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+const roundClockCols = `tournament_id, round, ends_at, reminded_at`
+
+func scanRoundClock(row interface {
+	Scan(dest ...interface{}) error
+}) (*models.RoundClock, error) {
+	c := &models.RoundClock{}
+	if err := row.Scan(&c.TournamentID, &c.Round, &c.EndsAt, &c.RemindedAt); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// SetRoundClock starts tournament tournamentID's clock for round, running
+// until endsAt. It replaces any earlier clock, reminder included.
+func SetRoundClock(ctx context.Context, db DBTX, tournamentID int64, round int, endsAt time.Time) (*models.RoundClock, error) {
+	return scanRoundClock(db.QueryRowContext(ctx,
+		`INSERT INTO round_clocks (tournament_id, round, ends_at) VALUES ($1, $2, $3)
+		 ON CONFLICT (tournament_id) DO UPDATE SET round = $2, ends_at = $3, reminded_at = NULL
+		 RETURNING `+roundClockCols,
+		tournamentID, round, endsAt,
+	))
+}
+
+// GetRoundClock returns tournament tournamentID's clock for round, or nil
+// if the clock isn't running for that round.
+func GetRoundClock(ctx context.Context, db DBTX, tournamentID int64, round int) (*models.RoundClock, error) {
+	c, err := scanRoundClock(db.QueryRowContext(ctx,
+		`SELECT `+roundClockCols+` FROM round_clocks WHERE tournament_id = $1 AND round = $2`,
+		tournamentID, round,
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return c, err
+}
+
+// StopRoundClock removes tournament tournamentID's clock, if any.
+func StopRoundClock(ctx context.Context, db DBTX, tournamentID int64) error {
+	_, err := db.ExecContext(ctx, `DELETE FROM round_clocks WHERE tournament_id = $1`, tournamentID)
+	return err
+}
+
+// ListDueRoundClocks returns the clocks not yet reminded that end before
+// before, soonest first.
+func ListDueRoundClocks(ctx context.Context, db DBTX, before time.Time) ([]models.RoundClock, error) {
+	rows, err := db.QueryContext(ctx,
+		`SELECT `+roundClockCols+` FROM round_clocks
+		 WHERE reminded_at IS NULL AND ends_at <= $1
+		 ORDER BY ends_at`,
+		before,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []models.RoundClock
+	for rows.Next() {
+		c, err := scanRoundClock(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *c)
+	}
+	return out, rows.Err()
+}
+
+// MarkRoundClockReminded records that tournament tournamentID's clock for
+// round has had its reminder. A clock for another round is left alone.
+func MarkRoundClockReminded(ctx context.Context, db DBTX, tournamentID int64, round int) error {
+	_, err := db.ExecContext(ctx,
+		`UPDATE round_clocks SET reminded_at = now() WHERE tournament_id = $1 AND round = $2`,
+		tournamentID, round,
+	)
+	return err
+}
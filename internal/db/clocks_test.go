@@ -0,0 +1,66 @@
+//go:build integration
+
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+func TestRoundClocks(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	org := createTestOrganizer(t, database)
+	tourn := &models.Tournament{Name: "Clocked", Status: models.TournamentStatusInProgress, OrganizerID: org.ID}
+	if err := CreateTournament(ctx, database, tourn); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	if _, err := SetRoundClock(ctx, database, tourn.ID, 1, now.Add(2*time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	due, err := ListDueRoundClocks(ctx, database, now.Add(5*time.Minute))
+	if err != nil || len(due) != 1 || due[0].Round != 1 {
+		t.Fatalf("due clocks = %+v, %v", due, err)
+	}
+	if due, _ = ListDueRoundClocks(ctx, database, now); len(due) != 0 {
+		t.Errorf("clock due before its lead: %+v", due)
+	}
+
+	// Marking another round leaves the clock due.
+	if err := MarkRoundClockReminded(ctx, database, tourn.ID, 2); err != nil {
+		t.Fatal(err)
+	}
+	if due, _ = ListDueRoundClocks(ctx, database, now.Add(5*time.Minute)); len(due) != 1 {
+		t.Errorf("clock no longer due after marking round 2: %+v", due)
+	}
+	if err := MarkRoundClockReminded(ctx, database, tourn.ID, 1); err != nil {
+		t.Fatal(err)
+	}
+	if due, _ = ListDueRoundClocks(ctx, database, now.Add(5*time.Minute)); len(due) != 0 {
+		t.Errorf("reminded clock still due: %+v", due)
+	}
+
+	// The next round's clock replaces it, reminder and all.
+	c, err := SetRoundClock(ctx, database, tourn.ID, 2, now.Add(50*time.Minute))
+	if err != nil || c.Round != 2 || c.RemindedAt != nil {
+		t.Fatalf("SetRoundClock = %+v, %v", c, err)
+	}
+	if c, err = GetRoundClock(ctx, database, tourn.ID, 1); c != nil || err != nil {
+		t.Errorf("round 1 clock = %+v, %v; want none", c, err)
+	}
+	if c, err = GetRoundClock(ctx, database, tourn.ID, 2); c == nil || err != nil {
+		t.Errorf("round 2 clock = %+v, %v", c, err)
+	}
+
+	if err := StopRoundClock(ctx, database, tourn.ID); err != nil {
+		t.Fatal(err)
+	}
+	if c, _ = GetRoundClock(ctx, database, tourn.ID, 2); c != nil {
+		t.Errorf("clock after stop = %+v", c)
+	}
+}
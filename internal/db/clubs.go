@@ -0,0 +1,50 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+)
+
+// SetClubs sets the clubs of registrations of tournament tournamentID,
+// keyed by registration ID; a nil club clears it. It returns sql.ErrNoRows
+// at the first registration that isn't in the tournament, so run it in a
+// transaction to set all or none.
+func SetClubs(ctx context.Context, db DBTX, tournamentID int64, clubs map[int64]*string) error {
+	for regID, club := range clubs {
+		res, err := db.ExecContext(ctx,
+			`UPDATE registrations SET club = $1 WHERE id = $2 AND tournament_id = $3`,
+			club, regID, tournamentID,
+		)
+		if err != nil {
+			return err
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			return sql.ErrNoRows
+		}
+	}
+	return nil
+}
+
+// TournamentClubs returns the clubs of tournamentID's players who have
+// one, keyed by engine player ID.
+func TournamentClubs(ctx context.Context, db DBTX, tournamentID int64) (map[int]string, error) {
+	rows, err := db.QueryContext(ctx,
+		`SELECT engine_player_id, club FROM registrations
+		 WHERE tournament_id = $1 AND engine_player_id IS NOT NULL AND club IS NOT NULL`,
+		tournamentID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := map[int]string{}
+	for rows.Next() {
+		var enginePlayerID int
+		var club string
+		if err := rows.Scan(&enginePlayerID, &club); err != nil {
+			return nil, err
+		}
+		out[enginePlayerID] = club
+	}
+	return out, rows.Err()
+}
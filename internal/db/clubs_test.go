@@ -0,0 +1,53 @@
+//go:build integration
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+func TestSetClubs(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	org := createTestOrganizer(t, database)
+	tourn := &models.Tournament{Name: "Clubs", Status: models.TournamentStatusRegistrationOpen, OrganizerID: org.ID, AvoidClubRounds: 2}
+	if err := CreateTournament(ctx, database, tourn); err != nil {
+		t.Fatalf("CreateTournament: %v", err)
+	}
+	if got, _ := GetTournament(ctx, database, tourn.ID); got.AvoidClubRounds != 2 {
+		t.Errorf("avoid_club_rounds = %d, want 2", got.AvoidClubRounds)
+	}
+	a, _ := CreateGuestRegistration(ctx, database, tourn.ID, "Alice")
+	b, _ := CreateGuestRegistration(ctx, database, tourn.ID, "Bob")
+	north := "North Chess Club"
+
+	if err := SetClubs(ctx, database, tourn.ID, map[int64]*string{a.ID: &north, b.ID: &north}); err != nil {
+		t.Fatalf("SetClubs: %v", err)
+	}
+	if err := SetClubs(ctx, database, tourn.ID, map[int64]*string{b.ID: nil}); err != nil {
+		t.Fatalf("SetClubs (clear): %v", err)
+	}
+	regs, _ := ListRegistrations(ctx, database, tourn.ID)
+	if regs[0].Club == nil || *regs[0].Club != north || regs[1].Club != nil {
+		t.Errorf("clubs = %v, %v; want %q, nil", regs[0].Club, regs[1].Club, north)
+	}
+
+	// Only seated players with a club are listed for pairing.
+	UpdateRegistrationEnginePlayerID(ctx, database, a.ID, 1)
+	UpdateRegistrationEnginePlayerID(ctx, database, b.ID, 2)
+	if clubs, err := TournamentClubs(ctx, database, tourn.ID); err != nil || len(clubs) != 1 || clubs[1] != north {
+		t.Errorf("TournamentClubs = %v, %v", clubs, err)
+	}
+
+	other := &models.Tournament{Name: "Other", Status: models.TournamentStatusRegistrationOpen, OrganizerID: org.ID}
+	CreateTournament(ctx, database, other)
+	stranger, _ := CreateGuestRegistration(ctx, database, other.ID, "Stranger")
+	if err := SetClubs(ctx, database, tourn.ID, map[int64]*string{stranger.ID: &north}); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("foreign registration: err = %v", err)
+	}
+}
@@ -0,0 +1,55 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+)
+
+// SetContactConsent records whether the user registered for tournament
+// tournamentID agrees to hear from the organizer after the event. It
+// returns sql.ErrNoRows if they aren't registered.
+func SetContactConsent(ctx context.Context, db DBTX, tournamentID, userID int64, consent bool) error {
+	res, err := db.ExecContext(ctx,
+		`UPDATE registrations SET contact_consent = $1
+		 WHERE tournament_id = $2 AND user_id = $3`,
+		consent, tournamentID, userID,
+	)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// MailingContact is a player of a tournament who agreed to be contacted.
+type MailingContact struct {
+	Name   string
+	Email  string
+	Status string
+}
+
+// ListMailingList returns the players of tournament tournamentID who
+// consented to be contacted, in sign-up order, with their account's email.
+// Guests and everyone who declined or withdrew consent are left out.
+func ListMailingList(ctx context.Context, db DBTX, tournamentID int64) ([]MailingContact, error) {
+	rows, err := db.QueryContext(ctx,
+		`SELECT r.display_name, u.email, r.status
+		 FROM registrations r JOIN users u ON u.id = r.user_id
+		 WHERE r.tournament_id = $1 AND r.contact_consent
+		 ORDER BY r.created_at, r.id`, tournamentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var contacts []MailingContact
+	for rows.Next() {
+		var c MailingContact
+		if err := rows.Scan(&c.Name, &c.Email, &c.Status); err != nil {
+			return nil, err
+		}
+		contacts = append(contacts, c)
+	}
+	return contacts, rows.Err()
+}
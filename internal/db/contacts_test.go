@@ -0,0 +1,53 @@
+//go:build integration
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+func TestMailingList(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	org := createTestOrganizer(t, database)
+	tourn := &models.Tournament{Name: "Mailing", Status: models.TournamentStatusRegistrationOpen, OrganizerID: org.ID}
+	if err := CreateTournament(ctx, database, tourn); err != nil {
+		t.Fatalf("CreateTournament: %v", err)
+	}
+	ann, err := CreateUser(ctx, database, "ann-contact@example.com", "Ann", "hash")
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	if _, err := RegisterUser(ctx, database, tourn.ID, ann.ID, "Ann", models.RegistrationStatusConfirmed, 0); err != nil {
+		t.Fatalf("RegisterUser: %v", err)
+	}
+	guest, _ := CreateGuestRegistration(ctx, database, tourn.ID, "Ann B.")
+
+	if list, err := ListMailingList(ctx, database, tourn.ID); err != nil || len(list) != 0 {
+		t.Errorf("before consent: %+v, %v", list, err)
+	}
+	if err := SetContactConsent(ctx, database, tourn.ID, ann.ID, true); err != nil {
+		t.Fatalf("SetContactConsent: %v", err)
+	}
+	if err := SetContactConsent(ctx, database, tourn.ID, org.ID, true); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("unregistered: err = %v, want sql.ErrNoRows", err)
+	}
+	list, err := ListMailingList(ctx, database, tourn.ID)
+	if err != nil || len(list) != 1 || list[0] != (MailingContact{Name: "Ann", Email: "ann-contact@example.com", Status: models.RegistrationStatusConfirmed}) {
+		t.Errorf("list = %+v, %v", list, err)
+	}
+
+	// Merged into a guest, the consent goes along with the account.
+	annReg, _ := GetRegistration(ctx, database, tourn.ID, ann.ID)
+	if err := MergeRegistrations(ctx, database, guest.ID, annReg.ID); err != nil {
+		t.Fatalf("MergeRegistrations: %v", err)
+	}
+	if got, _ := GetRegistrationByID(ctx, database, guest.ID); !got.ContactConsent || got.UserID == nil {
+		t.Errorf("merged = %+v, want Ann's account and consent", got)
+	}
+}
@@ -0,0 +1,44 @@
+package db
+
+import (
+	"context"
+
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+const correctionCols = `id, tournament_id, round, table_number, old_a, old_b, old_draws,
+	new_a, new_b, new_draws, paired_through, corrected_by, created_at`
+
+// AddResultCorrection records a corrected result, setting c.ID and
+// c.CreatedAt.
+func AddResultCorrection(ctx context.Context, db DBTX, c *models.ResultCorrection) error {
+	return db.QueryRowContext(ctx,
+		`INSERT INTO result_corrections (tournament_id, round, table_number, old_a, old_b, old_draws,
+		 new_a, new_b, new_draws, paired_through, corrected_by)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		 RETURNING id, created_at`,
+		c.TournamentID, c.Round, c.Table, c.OldA, c.OldB, c.OldDraws,
+		c.NewA, c.NewB, c.NewDraws, c.PairedThrough, c.CorrectedBy,
+	).Scan(&c.ID, &c.CreatedAt)
+}
+
+// ListResultCorrections returns a tournament's corrections, oldest first.
+func ListResultCorrections(ctx context.Context, db DBTX, tournamentID int64) ([]models.ResultCorrection, error) {
+	rows, err := db.QueryContext(ctx,
+		`SELECT `+correctionCols+` FROM result_corrections
+		 WHERE tournament_id = $1 ORDER BY created_at, id`, tournamentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []models.ResultCorrection
+	for rows.Next() {
+		var c models.ResultCorrection
+		if err := rows.Scan(&c.ID, &c.TournamentID, &c.Round, &c.Table, &c.OldA, &c.OldB, &c.OldDraws,
+			&c.NewA, &c.NewB, &c.NewDraws, &c.PairedThrough, &c.CorrectedBy, &c.CreatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, c)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,55 @@
+package db
+
+import (
+	"context"
+)
+
+// ListFeatureMatches returns the tables featured in a round, in table
+// order.
+func ListFeatureMatches(ctx context.Context, db DBTX, tournamentID int64, round int) ([]int, error) {
+	rows, err := db.QueryContext(ctx,
+		`SELECT table_number FROM feature_matches
+		 WHERE tournament_id = $1 AND round = $2 ORDER BY table_number`,
+		tournamentID, round,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []int
+	for rows.Next() {
+		var table int
+		if err := rows.Scan(&table); err != nil {
+			return nil, err
+		}
+		out = append(out, table)
+	}
+	return out, rows.Err()
+}
+
+// ReplaceFeatureMatches makes tables the feature matches of a round. Tables
+// featured before and not in tables stop being featured.
+func ReplaceFeatureMatches(ctx context.Context, db DBTX, tournamentID int64, round int, tables []int) error {
+	if err := ClearFeatureMatches(ctx, db, tournamentID, round); err != nil {
+		return err
+	}
+	for _, table := range tables {
+		if _, err := db.ExecContext(ctx,
+			`INSERT INTO feature_matches (tournament_id, round, table_number) VALUES ($1, $2, $3)`,
+			tournamentID, round, table,
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ClearFeatureMatches stops featuring every match of a round. Used when the
+// round is re-paired.
+func ClearFeatureMatches(ctx context.Context, db DBTX, tournamentID int64, round int) error {
+	_, err := db.ExecContext(ctx,
+		`DELETE FROM feature_matches WHERE tournament_id = $1 AND round = $2`,
+		tournamentID, round,
+	)
+	return err
+}
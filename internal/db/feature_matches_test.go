@@ -0,0 +1,46 @@
+//go:build integration
+
+package db
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+func TestFeatureMatches(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	org := createTestOrganizer(t, database)
+	tourn := &models.Tournament{Name: "Feature matches", Status: models.TournamentStatusScheduled, OrganizerID: org.ID}
+	if err := CreateTournament(ctx, database, tourn); err != nil {
+		t.Fatalf("CreateTournament: %v", err)
+	}
+
+	if err := ReplaceFeatureMatches(ctx, database, tourn.ID, 1, []int{3, 1}); err != nil {
+		t.Fatalf("ReplaceFeatureMatches: %v", err)
+	}
+	if err := ReplaceFeatureMatches(ctx, database, tourn.ID, 2, []int{1}); err != nil {
+		t.Fatalf("ReplaceFeatureMatches round 2: %v", err)
+	}
+	if got, err := ListFeatureMatches(ctx, database, tourn.ID, 1); err != nil || !reflect.DeepEqual(got, []int{1, 3}) {
+		t.Errorf("round 1 = %v, %v; want [1 3]", got, err)
+	}
+	if err := ReplaceFeatureMatches(ctx, database, tourn.ID, 1, []int{2}); err != nil {
+		t.Fatalf("replace: %v", err)
+	}
+	if got, _ := ListFeatureMatches(ctx, database, tourn.ID, 1); !reflect.DeepEqual(got, []int{2}) {
+		t.Errorf("round 1 after replace = %v, want [2]", got)
+	}
+	if err := ClearFeatureMatches(ctx, database, tourn.ID, 1); err != nil {
+		t.Fatalf("ClearFeatureMatches: %v", err)
+	}
+	if got, _ := ListFeatureMatches(ctx, database, tourn.ID, 1); len(got) != 0 {
+		t.Errorf("round 1 after clear = %v", got)
+	}
+	if got, _ := ListFeatureMatches(ctx, database, tourn.ID, 2); !reflect.DeepEqual(got, []int{1}) {
+		t.Errorf("round 2 = %v, want [1]", got)
+	}
+}
@@ -0,0 +1,131 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+// ErrHandoffInvalid is returned when a handoff code is unknown, expired or
+// already claimed. The cases aren't told apart so a guesser learns nothing.
+var ErrHandoffInvalid = errors.New("handoff: invalid or expired code")
+
+// ErrHandoffSelf is returned when the issuing admin tries to claim their own
+// code.
+var ErrHandoffSelf = errors.New("handoff: cannot claim your own code")
+
+// CreateHandoff stores a new handoff code for the tournament. Earlier
+// unclaimed codes are expired, so only the latest code works.
+func CreateHandoff(ctx context.Context, database *sql.DB, tournamentID, createdBy int64, codeHash string, expiresAt time.Time) error {
+	tx, err := database.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE tournament_handoffs SET expires_at = now()
+		 WHERE tournament_id = $1 AND claimed_at IS NULL AND expires_at > now()`,
+		tournamentID,
+	); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO tournament_handoffs (tournament_id, code_hash, created_by, expires_at)
+		 VALUES ($1, $2, $3, $4)`,
+		tournamentID, codeHash, createdBy, expiresAt,
+	); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ClaimHandoff transfers admin rights using a handoff code, in one
+// transaction: the claimer becomes an admin of the tournament, the issuer
+// is removed from its staff and logged out of every session, and the code
+// is marked claimed. Returns the claimed handoff.
+func ClaimHandoff(ctx context.Context, database *sql.DB, codeHash string, claimerID int64) (*models.TournamentHandoff, error) {
+	tx, err := database.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	h := &models.TournamentHandoff{}
+	err = tx.QueryRowContext(ctx,
+		`SELECT id, tournament_id, created_by, created_at, expires_at FROM tournament_handoffs
+		 WHERE code_hash = $1 AND claimed_at IS NULL AND expires_at > now()
+		 FOR UPDATE`,
+		codeHash,
+	).Scan(&h.ID, &h.TournamentID, &h.CreatedBy, &h.CreatedAt, &h.ExpiresAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrHandoffInvalid
+	}
+	if err != nil {
+		return nil, err
+	}
+	if h.CreatedBy != nil && *h.CreatedBy == claimerID {
+		return nil, ErrHandoffSelf
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO tournament_staff (tournament_id, user_id, tier, granted_by)
+		 VALUES ($1, $2, 'admin', $3)
+		 ON CONFLICT (tournament_id, user_id) DO UPDATE SET tier = 'admin', granted_by = $3, granted_at = now()`,
+		h.TournamentID, claimerID, h.CreatedBy,
+	); err != nil {
+		return nil, err
+	}
+	if h.CreatedBy != nil {
+		if _, err := tx.ExecContext(ctx,
+			`DELETE FROM tournament_staff WHERE tournament_id = $1 AND user_id = $2`,
+			h.TournamentID, *h.CreatedBy,
+		); err != nil {
+			return nil, err
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM sessions WHERE user_id = $1`, *h.CreatedBy); err != nil {
+			return nil, err
+		}
+	}
+	err = tx.QueryRowContext(ctx,
+		`UPDATE tournament_handoffs SET claimed_by = $2, claimed_at = now() WHERE id = $1
+		 RETURNING claimed_by, claimed_at`,
+		h.ID, claimerID,
+	).Scan(&h.ClaimedBy, &h.ClaimedAt)
+	if err != nil {
+		return nil, err
+	}
+	return h, tx.Commit()
+}
+
+// ListHandoffs returns the tournament's handoff codes, newest first, with
+// the issuer's and claimer's display names.
+func ListHandoffs(ctx context.Context, db DBTX, tournamentID int64) ([]models.TournamentHandoff, error) {
+	rows, err := db.QueryContext(ctx,
+		`SELECT h.id, h.tournament_id, h.created_by, COALESCE(c.display_name, ''), h.created_at, h.expires_at,
+		        h.claimed_by, COALESCE(u.display_name, ''), h.claimed_at
+		 FROM tournament_handoffs h
+		 LEFT JOIN users c ON c.id = h.created_by
+		 LEFT JOIN users u ON u.id = h.claimed_by
+		 WHERE h.tournament_id = $1
+		 ORDER BY h.created_at DESC, h.id DESC`,
+		tournamentID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []models.TournamentHandoff
+	for rows.Next() {
+		var h models.TournamentHandoff
+		if err := rows.Scan(&h.ID, &h.TournamentID, &h.CreatedBy, &h.CreatedByName, &h.CreatedAt, &h.ExpiresAt,
+			&h.ClaimedBy, &h.ClaimedByName, &h.ClaimedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, h)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,71 @@
+//go:build integration
+
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+func TestClaimHandoff(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	org := createTestOrganizer(t, database)
+	next, err := CreateUser(ctx, database, "handoff-next@example.com", "Next", "hash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tourn := &models.Tournament{Name: "Handoff", Status: models.TournamentStatusScheduled, OrganizerID: org.ID}
+	if err := CreateTournament(ctx, database, tourn); err != nil {
+		t.Fatal(err)
+	}
+	if err := CreateSession(ctx, database, "handoff-session", org.ID, time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	expires := time.Now().Add(models.HandoffTTL)
+
+	if err := CreateHandoff(ctx, database, tourn.ID, org.ID, "old-hash", expires); err != nil {
+		t.Fatal(err)
+	}
+	if err := CreateHandoff(ctx, database, tourn.ID, org.ID, "new-hash", expires); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ClaimHandoff(ctx, database, "old-hash", next.ID); !errors.Is(err, ErrHandoffInvalid) {
+		t.Errorf("superseded code: err = %v, want ErrHandoffInvalid", err)
+	}
+	if _, err := ClaimHandoff(ctx, database, "new-hash", org.ID); !errors.Is(err, ErrHandoffSelf) {
+		t.Errorf("self claim: err = %v, want ErrHandoffSelf", err)
+	}
+
+	h, err := ClaimHandoff(ctx, database, "new-hash", next.ID)
+	if err != nil {
+		t.Fatalf("ClaimHandoff: %v", err)
+	}
+	if h.TournamentID != tourn.ID || h.ClaimedBy == nil || *h.ClaimedBy != next.ID {
+		t.Errorf("claimed handoff = %+v", h)
+	}
+	if tier, _ := GetTournamentTier(ctx, database, tourn.ID, next.ID); tier != models.TierAdmin {
+		t.Errorf("claimer tier = %q, want admin", tier)
+	}
+	if tier, _ := GetTournamentTier(ctx, database, tourn.ID, org.ID); tier != "" {
+		t.Errorf("issuer tier = %q, want none", tier)
+	}
+	if _, err := GetSession(ctx, database, "handoff-session"); err == nil {
+		t.Error("issuer's session survived the handoff")
+	}
+	if _, err := ClaimHandoff(ctx, database, "new-hash", next.ID); !errors.Is(err, ErrHandoffInvalid) {
+		t.Errorf("reused code: err = %v, want ErrHandoffInvalid", err)
+	}
+
+	log, err := ListHandoffs(ctx, database, tourn.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(log) != 2 || log[0].ClaimedByName != "Next" || log[1].ClaimedAt != nil {
+		t.Errorf("handoff log = %+v", log)
+	}
+}
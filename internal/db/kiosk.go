@@ -0,0 +1,33 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+)
+
+// GetKioskSecret returns the secret tournament id's kiosk PINs are derived
+// from, or "" if the kiosk is off.
+func GetKioskSecret(ctx context.Context, db DBTX, id int64) (string, error) {
+	var secret sql.NullString
+	if err := db.QueryRowContext(ctx,
+		`SELECT kiosk_secret FROM tournaments WHERE id = $1`, id,
+	).Scan(&secret); err != nil {
+		return "", err
+	}
+	return secret.String, nil
+}
+
+// SetKioskSecret replaces tournament id's kiosk secret, which changes every
+// table PIN. An empty secret turns the kiosk off. It returns sql.ErrNoRows
+// if there is no such tournament.
+func SetKioskSecret(ctx context.Context, db DBTX, id int64, secret string) error {
+	res, err := db.ExecContext(ctx,
+		`UPDATE tournaments SET kiosk_secret = NULLIF($1, '') WHERE id = $2`, secret, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
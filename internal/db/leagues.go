@@ -0,0 +1,147 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/lib/pq"
+)
+
+// ErrLeagueNotFound is returned when a league doesn't exist.
+var ErrLeagueNotFound = errors.New("league: not found")
+
+const leagueCols = `id, name, placement_points, participation_points, created_by, created_at, updated_at`
+
+func scanLeague(row interface {
+	Scan(dest ...interface{}) error
+}) (*models.League, error) {
+	l := &models.League{}
+	var points pq.Int64Array
+	if err := row.Scan(&l.ID, &l.Name, &points, &l.ParticipationPoints, &l.CreatedBy, &l.CreatedAt, &l.UpdatedAt); err != nil {
+		return nil, err
+	}
+	l.PlacementPoints = make([]int, len(points))
+	for i, p := range points {
+		l.PlacementPoints[i] = int(p)
+	}
+	return l, nil
+}
+
+// CreateLeague inserts a league, filling in its ID and timestamps.
+func CreateLeague(ctx context.Context, db DBTX, l *models.League) error {
+	return db.QueryRowContext(ctx,
+		`INSERT INTO leagues (name, placement_points, participation_points, created_by)
+		 VALUES ($1, $2, $3, $4)
+		 RETURNING id, created_at, updated_at`,
+		l.Name, pq.Array(l.PlacementPoints), l.ParticipationPoints, l.CreatedBy,
+	).Scan(&l.ID, &l.CreatedAt, &l.UpdatedAt)
+}
+
+// GetLeague returns a league, or ErrLeagueNotFound.
+func GetLeague(ctx context.Context, db DBTX, id int64) (*models.League, error) {
+	l, err := scanLeague(db.QueryRowContext(ctx,
+		`SELECT `+leagueCols+` FROM leagues WHERE id = $1`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrLeagueNotFound
+	}
+	return l, err
+}
+
+// ListLeagues returns every league, newest first.
+func ListLeagues(ctx context.Context, db DBTX) ([]models.League, error) {
+	rows, err := db.QueryContext(ctx,
+		`SELECT `+leagueCols+` FROM leagues ORDER BY created_at DESC, id DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []models.League
+	for rows.Next() {
+		l, err := scanLeague(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *l)
+	}
+	return out, rows.Err()
+}
+
+// UpdateLeague saves a league's name and points table.
+func UpdateLeague(ctx context.Context, db DBTX, l *models.League) error {
+	err := db.QueryRowContext(ctx,
+		`UPDATE leagues SET name = $1, placement_points = $2, participation_points = $3, updated_at = now()
+		 WHERE id = $4
+		 RETURNING updated_at`,
+		l.Name, pq.Array(l.PlacementPoints), l.ParticipationPoints, l.ID,
+	).Scan(&l.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrLeagueNotFound
+	}
+	return err
+}
+
+// DeleteLeague removes a league. Its tournaments are untouched.
+func DeleteLeague(ctx context.Context, db DBTX, id int64) error {
+	res, err := db.ExecContext(ctx, `DELETE FROM leagues WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrLeagueNotFound
+	}
+	return nil
+}
+
+// AddLeagueTournament counts a tournament towards a league. Adding one
+// twice is a no-op.
+func AddLeagueTournament(ctx context.Context, db DBTX, leagueID, tournamentID int64) error {
+	_, err := db.ExecContext(ctx,
+		`INSERT INTO league_tournaments (league_id, tournament_id) VALUES ($1, $2)
+		 ON CONFLICT DO NOTHING`,
+		leagueID, tournamentID,
+	)
+	return err
+}
+
+// RemoveLeagueTournament stops a tournament counting towards a league. It
+// returns sql.ErrNoRows if it didn't.
+func RemoveLeagueTournament(ctx context.Context, db DBTX, leagueID, tournamentID int64) error {
+	res, err := db.ExecContext(ctx,
+		`DELETE FROM league_tournaments WHERE league_id = $1 AND tournament_id = $2`,
+		leagueID, tournamentID,
+	)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// ListLeagueTournaments returns the tournaments of a league, engine state
+// included, in the order they were held: by scheduled start, or by when
+// they were created if they have none.
+func ListLeagueTournaments(ctx context.Context, db DBTX, leagueID int64) ([]models.Tournament, error) {
+	rows, err := db.QueryContext(ctx,
+		`SELECT `+tournamentCols+`, engine_state FROM tournaments
+		 WHERE id IN (SELECT tournament_id FROM league_tournaments WHERE league_id = $1)
+		 ORDER BY COALESCE(scheduled_at, created_at), id`,
+		leagueID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []models.Tournament
+	for rows.Next() {
+		var t models.Tournament
+		if err := rows.Scan(append(tournamentDest(&t), &t.EngineState)...); err != nil {
+			return nil, err
+		}
+		out = append(out, t)
+	}
+	return out, rows.Err()
+}
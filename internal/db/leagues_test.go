@@ -0,0 +1,76 @@
+//go:build integration
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+func TestLeagues(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	org := createTestOrganizer(t, database)
+
+	l := &models.League{Name: "Spring Season", PlacementPoints: []int{5, 3, 1}, ParticipationPoints: 1, CreatedBy: &org.ID}
+	if err := CreateLeague(ctx, database, l); err != nil {
+		t.Fatal(err)
+	}
+	got, err := GetLeague(ctx, database, l.ID)
+	if err != nil || got.Name != "Spring Season" || len(got.PlacementPoints) != 3 || got.PlacementPoints[0] != 5 || got.ParticipationPoints != 1 {
+		t.Fatalf("GetLeague = %+v, %v", got, err)
+	}
+
+	later := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	earlier := later.AddDate(0, -1, 0)
+	may := &models.Tournament{Name: "May", Status: models.TournamentStatusScheduled, OrganizerID: org.ID, ScheduledAt: &later}
+	april := &models.Tournament{Name: "April", Status: models.TournamentStatusScheduled, OrganizerID: org.ID, ScheduledAt: &earlier}
+	for _, tourn := range []*models.Tournament{may, april} {
+		if err := CreateTournament(ctx, database, tourn); err != nil {
+			t.Fatal(err)
+		}
+		if err := AddLeagueTournament(ctx, database, l.ID, tourn.ID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := AddLeagueTournament(ctx, database, l.ID, may.ID); err != nil {
+		t.Errorf("adding twice: %v", err)
+	}
+	if ts, _ := ListLeagueTournaments(ctx, database, l.ID); len(ts) != 2 || ts[0].ID != april.ID || ts[1].ID != may.ID {
+		t.Errorf("ListLeagueTournaments = %+v, want April then May", ts)
+	}
+
+	if err := RemoveLeagueTournament(ctx, database, l.ID, may.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := RemoveLeagueTournament(ctx, database, l.ID, may.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("second remove: err = %v, want sql.ErrNoRows", err)
+	}
+
+	l.Name, l.PlacementPoints = "Spring", []int{}
+	if err := UpdateLeague(ctx, database, l); err != nil {
+		t.Fatal(err)
+	}
+	if all, _ := ListLeagues(ctx, database); len(all) != 1 || all[0].Name != "Spring" || len(all[0].PlacementPoints) != 0 {
+		t.Errorf("ListLeagues = %+v", all)
+	}
+
+	// Deleting the league keeps its tournaments.
+	if err := DeleteLeague(ctx, database, l.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := GetLeague(ctx, database, l.ID); !errors.Is(err, ErrLeagueNotFound) {
+		t.Errorf("GetLeague after delete: err = %v", err)
+	}
+	if _, err := GetTournament(ctx, database, april.ID); err != nil {
+		t.Errorf("tournament gone with its league: %v", err)
+	}
+	if err := UpdateLeague(ctx, database, l); !errors.Is(err, ErrLeagueNotFound) {
+		t.Errorf("UpdateLeague after delete: err = %v", err)
+	}
+}
@@ -0,0 +1,139 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+// ErrMatchGameNotFound is returned when undoing a game at a table that has
+// none recorded, or editing a game that wasn't reported.
+var ErrMatchGameNotFound = errors.New("match game: not found")
+
+const matchGameCols = `tournament_id, round, table_number, game_number, winner, map,
+	player_a_pick, player_b_pick, reported_by, reported_at, went_first, notes`
+
+// scanMatchGames reads rows selected with matchGameCols and closes them.
+func scanMatchGames(rows *sql.Rows) ([]models.MatchGame, error) {
+	defer rows.Close()
+	var out []models.MatchGame
+	for rows.Next() {
+		var g models.MatchGame
+		if err := rows.Scan(&g.TournamentID, &g.Round, &g.Table, &g.GameNumber, &g.Winner, &g.Map,
+			&g.PlayerAPick, &g.PlayerBPick, &g.ReportedBy, &g.ReportedAt, &g.WentFirst, &g.Notes); err != nil {
+			return nil, err
+		}
+		out = append(out, g)
+	}
+	return out, rows.Err()
+}
+
+// ListMatchGames returns every game reported in a round of the tournament,
+// grouped by table and in game order.
+func ListMatchGames(ctx context.Context, db DBTX, tournamentID int64, round int) (map[int][]models.MatchGame, error) {
+	rows, err := db.QueryContext(ctx,
+		`SELECT `+matchGameCols+` FROM match_games
+		 WHERE tournament_id = $1 AND round = $2
+		 ORDER BY table_number, game_number`,
+		tournamentID, round,
+	)
+	if err != nil {
+		return nil, err
+	}
+	games, err := scanMatchGames(rows)
+	if err != nil {
+		return nil, err
+	}
+	out := map[int][]models.MatchGame{}
+	for _, g := range games {
+		out[g.Table] = append(out[g.Table], g)
+	}
+	return out, nil
+}
+
+// ListTableGames returns the games reported at one table of a round, in game
+// order.
+func ListTableGames(ctx context.Context, db DBTX, tournamentID int64, round, table int) ([]models.MatchGame, error) {
+	rows, err := db.QueryContext(ctx,
+		`SELECT `+matchGameCols+` FROM match_games
+		 WHERE tournament_id = $1 AND round = $2 AND table_number = $3
+		 ORDER BY game_number`,
+		tournamentID, round, table,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return scanMatchGames(rows)
+}
+
+// AddMatchGame inserts a game. The caller assigns GameNumber; a duplicate
+// number surfaces as a unique violation (23505).
+func AddMatchGame(ctx context.Context, db DBTX, g *models.MatchGame) error {
+	return db.QueryRowContext(ctx,
+		`INSERT INTO match_games (tournament_id, round, table_number, game_number, winner, map,
+		 player_a_pick, player_b_pick, reported_by, went_first, notes)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		 RETURNING reported_at`,
+		g.TournamentID, g.Round, g.Table, g.GameNumber, g.Winner, g.Map,
+		g.PlayerAPick, g.PlayerBPick, g.ReportedBy, g.WentFirst, g.Notes,
+	).Scan(&g.ReportedAt)
+}
+
+// UpdateMatchGame rewrites the winner and details of a reported game, and
+// who reported it, as of now.
+func UpdateMatchGame(ctx context.Context, db DBTX, g *models.MatchGame) error {
+	err := db.QueryRowContext(ctx,
+		`UPDATE match_games SET winner = $5, map = $6, player_a_pick = $7, player_b_pick = $8,
+		 reported_by = $9, went_first = $10, notes = $11, reported_at = now()
+		 WHERE tournament_id = $1 AND round = $2 AND table_number = $3 AND game_number = $4
+		 RETURNING reported_at`,
+		g.TournamentID, g.Round, g.Table, g.GameNumber, g.Winner, g.Map,
+		g.PlayerAPick, g.PlayerBPick, g.ReportedBy, g.WentFirst, g.Notes,
+	).Scan(&g.ReportedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrMatchGameNotFound
+	}
+	return err
+}
+
+// DeleteLastMatchGame removes the highest-numbered game at a table.
+func DeleteLastMatchGame(ctx context.Context, db DBTX, tournamentID int64, round, table int) error {
+	res, err := db.ExecContext(ctx,
+		`DELETE FROM match_games
+		 WHERE tournament_id = $1 AND round = $2 AND table_number = $3
+		   AND game_number = (SELECT max(game_number) FROM match_games
+		                      WHERE tournament_id = $1 AND round = $2 AND table_number = $3)`,
+		tournamentID, round, table,
+	)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrMatchGameNotFound
+	}
+	return nil
+}
+
+// ListAllMatchGames returns every game of the tournament, by round, table
+// and game number.
+func ListAllMatchGames(ctx context.Context, db DBTX, tournamentID int64) ([]models.MatchGame, error) {
+	rows, err := db.QueryContext(ctx,
+		`SELECT `+matchGameCols+` FROM match_games
+		 WHERE tournament_id = $1 ORDER BY round, table_number, game_number`, tournamentID)
+	if err != nil {
+		return nil, err
+	}
+	return scanMatchGames(rows)
+}
+
+// ClearMatchGames deletes every game of a round. Used when the round is
+// re-paired.
+func ClearMatchGames(ctx context.Context, db DBTX, tournamentID int64, round int) error {
+	_, err := db.ExecContext(ctx,
+		`DELETE FROM match_games WHERE tournament_id = $1 AND round = $2`,
+		tournamentID, round,
+	)
+	return err
+}
@@ -0,0 +1,89 @@
+//go:build integration
+
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/lib/pq"
+)
+
+func TestMatchGames_Lifecycle(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	org := createTestOrganizer(t, database)
+	tourn := &models.Tournament{
+		Name: "Series", Status: models.TournamentStatusScheduled, OrganizerID: org.ID,
+		BestOf: 3, SeriesMode: true,
+	}
+	if err := CreateTournament(ctx, database, tourn); err != nil {
+		t.Fatalf("CreateTournament: %v", err)
+	}
+	got, err := GetTournament(ctx, database, tourn.ID)
+	if err != nil {
+		t.Fatalf("GetTournament: %v", err)
+	}
+	if got.BestOf != 3 || !got.SeriesMode {
+		t.Errorf("match format = Bo%d series=%v", got.BestOf, got.SeriesMode)
+	}
+
+	add := []models.MatchGame{
+		{Table: 1, GameNumber: 1, Winner: models.GameWinnerA, Map: "Dust2"},
+		{Table: 1, GameNumber: 2, Winner: models.GameWinnerB, PlayerAPick: "Ryu", PlayerBPick: "Ken"},
+		{Table: 2, GameNumber: 1, Winner: models.GameDraw},
+	}
+	for i := range add {
+		add[i].TournamentID = tourn.ID
+		add[i].Round = 1
+		add[i].ReportedBy = &org.ID
+		if err := AddMatchGame(ctx, database, &add[i]); err != nil {
+			t.Fatalf("AddMatchGame %d: %v", i, err)
+		}
+	}
+	dup := models.MatchGame{TournamentID: tourn.ID, Round: 1, Table: 1, GameNumber: 2, Winner: models.GameWinnerA}
+	var pqErr *pq.Error
+	if err := AddMatchGame(ctx, database, &dup); !errors.As(err, &pqErr) || pqErr.Code != "23505" {
+		t.Errorf("duplicate game number: got %v, want unique violation", err)
+	}
+
+	games, err := ListMatchGames(ctx, database, tourn.ID, 1)
+	if err != nil {
+		t.Fatalf("ListMatchGames: %v", err)
+	}
+	if len(games[1]) != 2 || games[1][1].PlayerBPick != "Ken" || len(games[2]) != 1 {
+		t.Errorf("games = %+v", games)
+	}
+
+	if err := DeleteLastMatchGame(ctx, database, tourn.ID, 1, 1); err != nil {
+		t.Fatalf("DeleteLastMatchGame: %v", err)
+	}
+	table1, err := ListTableGames(ctx, database, tourn.ID, 1, 1)
+	if err != nil {
+		t.Fatalf("ListTableGames: %v", err)
+	}
+	if len(table1) != 1 || table1[0].Map != "Dust2" {
+		t.Errorf("table 1 after undo = %+v", table1)
+	}
+	if err := DeleteLastMatchGame(ctx, database, tourn.ID, 1, 3); !errors.Is(err, ErrMatchGameNotFound) {
+		t.Errorf("undo empty table: got %v, want ErrMatchGameNotFound", err)
+	}
+
+	if err := ClearMatchGames(ctx, database, tourn.ID, 1); err != nil {
+		t.Fatalf("ClearMatchGames: %v", err)
+	}
+	if games, _ = ListMatchGames(ctx, database, tourn.ID, 1); len(games) != 0 {
+		t.Errorf("games after clear = %+v", games)
+	}
+}
+
+func TestMatchGames_SeriesModeNeedsBestOf(t *testing.T) {
+	database := testDB(t)
+	org := createTestOrganizer(t, database)
+	tourn := &models.Tournament{Name: "Bad", Status: models.TournamentStatusScheduled, OrganizerID: org.ID, SeriesMode: true}
+	if err := CreateTournament(context.Background(), database, tourn); err == nil {
+		t.Error("series mode without best_of was accepted")
+	}
+}
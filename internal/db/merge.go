@@ -0,0 +1,129 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+)
+
+// RenameRegistration sets a registration's display name, and for a guest its
+// guest name too. A name another registration of the tournament already has
+// (ignoring case) surfaces as a unique violation (23505).
+func RenameRegistration(ctx context.Context, db DBTX, regID int64, name string) error {
+	res, err := db.ExecContext(ctx,
+		`UPDATE registrations
+		 SET display_name = $1, guest_name = CASE WHEN user_id IS NULL THEN $1 END
+		 WHERE id = $2`,
+		name, regID,
+	)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// MergeRegistrations folds the registration dupID into keepID and deletes
+// it. keepID takes over what it lacks: the user account when it is a guest,
+// the decklist, archetype, team roster or registry entry when it has none,
+// the duplicate's place when it is waitlisted or pending and the duplicate
+// is further along (confirmed, or pending over waitlisted), the duplicate's
+// payment when it is unpaid, and the duplicate's assigned byes. Consent
+// to be contacted goes with the account. Its name is left alone.
+func MergeRegistrations(ctx context.Context, db DBTX, keepID, dupID int64) error {
+	if _, err := db.ExecContext(ctx,
+		`INSERT INTO assigned_byes (tournament_id, round, registration_id, assigned_by, assigned_at)
+		 SELECT tournament_id, round, $1, assigned_by, assigned_at
+		 FROM assigned_byes WHERE registration_id = $2
+		 ON CONFLICT DO NOTHING`,
+		keepID, dupID,
+	); err != nil {
+		return err
+	}
+	res, err := db.ExecContext(ctx,
+		`UPDATE registrations k
+		 SET decklist = COALESCE(k.decklist, d.decklist),
+		     archetype = COALESCE(k.archetype, d.archetype),
+		     player_id = COALESCE(k.player_id, d.player_id),
+		     members  = CASE WHEN cardinality(k.members) = 0 THEN d.members ELSE k.members END,
+		     status   = CASE WHEN k.status IN ('pending', 'waitlisted') AND d.status = 'confirmed' THEN 'confirmed'
+		                     WHEN k.status = 'waitlisted' AND d.status = 'pending' THEN 'pending'
+		                     ELSE k.status END,
+		     payment_status = CASE WHEN k.payment_status = 'unpaid' THEN d.payment_status ELSE k.payment_status END,
+		     payment_amount = CASE WHEN k.payment_status = 'unpaid' THEN d.payment_amount ELSE k.payment_amount END,
+		     payment_method = CASE WHEN k.payment_status = 'unpaid' THEN d.payment_method ELSE k.payment_method END,
+		     contact_consent = CASE WHEN k.user_id IS NULL THEN d.contact_consent ELSE k.contact_consent END
+		 FROM registrations d
+		 WHERE k.id = $1 AND d.id = $2`,
+		keepID, dupID,
+	)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	// The account moves only once the duplicate is gone: a user has one
+	// registration per tournament.
+	var userID sql.NullInt64
+	if err := db.QueryRowContext(ctx,
+		`DELETE FROM registrations WHERE id = $1 RETURNING user_id`, dupID,
+	).Scan(&userID); err != nil {
+		return err
+	}
+	if !userID.Valid {
+		return nil
+	}
+	_, err = db.ExecContext(ctx,
+		`UPDATE registrations SET user_id = $2, guest_name = NULL
+		 WHERE id = $1 AND user_id IS NULL`,
+		keepID, userID.Int64,
+	)
+	return err
+}
+
+// DistinctPair orders two registration IDs as distinct_registrations keeps
+// them and ListDistinctRegistrations returns them, lower first.
+func DistinctPair(a, b int64) [2]int64 {
+	if a > b {
+		a, b = b, a
+	}
+	return [2]int64{a, b}
+}
+
+// MarkRegistrationsDistinct records that registrations a and b of the
+// tournament, whose names look alike, are two different people. Marking a
+// pair again is a no-op.
+func MarkRegistrationsDistinct(ctx context.Context, db DBTX, tournamentID, a, b, markedBy int64) error {
+	pair := DistinctPair(a, b)
+	_, err := db.ExecContext(ctx,
+		`INSERT INTO distinct_registrations (tournament_id, registration_id, other_id, marked_by)
+		 VALUES ($1, $2, $3, $4)
+		 ON CONFLICT DO NOTHING`,
+		tournamentID, pair[0], pair[1], markedBy,
+	)
+	return err
+}
+
+// ListDistinctRegistrations returns the pairs of the tournament's
+// registrations marked as different people, lower ID first.
+func ListDistinctRegistrations(ctx context.Context, db DBTX, tournamentID int64) (map[[2]int64]bool, error) {
+	rows, err := db.QueryContext(ctx,
+		`SELECT registration_id, other_id FROM distinct_registrations WHERE tournament_id = $1`,
+		tournamentID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	pairs := map[[2]int64]bool{}
+	for rows.Next() {
+		var a, b int64
+		if err := rows.Scan(&a, &b); err != nil {
+			return nil, err
+		}
+		pairs[DistinctPair(a, b)] = true
+	}
+	return pairs, rows.Err()
+}
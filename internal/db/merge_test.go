@@ -0,0 +1,142 @@
+//go:build integration
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/lib/pq"
+)
+
+// createTestPlayer creates a user account called name.
+func createTestPlayer(t *testing.T, database *sql.DB, name string) *models.User {
+	t.Helper()
+	u, err := CreateUser(context.Background(), database, name+"-"+t.Name()+"@example.com", name, "hash")
+	if err != nil {
+		t.Fatalf("create player %s: %v", name, err)
+	}
+	return u
+}
+
+func TestRenameRegistration(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	org := createTestOrganizer(t, database)
+	tourn := &models.Tournament{Name: "Rename", Status: models.TournamentStatusRegistrationOpen, OrganizerID: org.ID}
+	if err := CreateTournament(ctx, database, tourn); err != nil {
+		t.Fatalf("CreateTournament: %v", err)
+	}
+	guest, err := CreateGuestRegistration(ctx, database, tourn.ID, "Alcie")
+	if err != nil {
+		t.Fatalf("CreateGuestRegistration: %v", err)
+	}
+	user, err := CreateRegistration(ctx, database, tourn.ID, org.ID, org.DisplayName)
+	if err != nil {
+		t.Fatalf("CreateRegistration: %v", err)
+	}
+
+	if err := RenameRegistration(ctx, database, guest.ID, "Alice"); err != nil {
+		t.Fatalf("RenameRegistration: %v", err)
+	}
+	got, _ := GetRegistrationByID(ctx, database, guest.ID)
+	if got.DisplayName != "Alice" || got.GuestName == nil || *got.GuestName != "Alice" {
+		t.Errorf("guest = %q (guest name %v), want Alice", got.DisplayName, got.GuestName)
+	}
+
+	if err := RenameRegistration(ctx, database, user.ID, "Org Renamed"); err != nil {
+		t.Fatalf("RenameRegistration: %v", err)
+	}
+	got, _ = GetRegistrationByID(ctx, database, user.ID)
+	if got.DisplayName != "Org Renamed" || got.GuestName != nil {
+		t.Errorf("user = %q (guest name %v), want Org Renamed and no guest name", got.DisplayName, got.GuestName)
+	}
+
+	var pqErr *pq.Error
+	if err := RenameRegistration(ctx, database, user.ID, "alice"); !errors.As(err, &pqErr) || pqErr.Code != "23505" {
+		t.Errorf("renaming onto a taken name: err = %v, want a unique violation", err)
+	}
+	if err := RenameRegistration(ctx, database, -1, "Nobody"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("missing registration: err = %v, want sql.ErrNoRows", err)
+	}
+
+	// The renamed user's registration is bumped like a guest's when its
+	// new name is the one another user signs up with.
+	if _, err := CreateRegistration(ctx, database, tourn.ID, createTestPlayer(t, database, "Org Renamed").ID, "Org Renamed"); err != nil {
+		t.Fatalf("CreateRegistration onto the renamed name: %v", err)
+	}
+	got, _ = GetRegistrationByID(ctx, database, user.ID)
+	if got.DisplayName != "Org Renamed (2)" || got.GuestName != nil {
+		t.Errorf("bumped user = %q (guest name %v)", got.DisplayName, got.GuestName)
+	}
+}
+
+func TestMergeRegistrations(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	org := createTestOrganizer(t, database)
+	tourn := &models.Tournament{Name: "Merge", Status: models.TournamentStatusRegistrationOpen, OrganizerID: org.ID}
+	if err := CreateTournament(ctx, database, tourn); err != nil {
+		t.Fatalf("CreateTournament: %v", err)
+	}
+	// Added by hand at the door, then registered online too.
+	keep, err := CreateGuestRegistration(ctx, database, tourn.ID, "Bob Smith")
+	if err != nil {
+		t.Fatalf("CreateGuestRegistration: %v", err)
+	}
+	player := createTestPlayer(t, database, "Bobby")
+	dup, err := CreateRegistration(ctx, database, tourn.ID, player.ID, "Bobby")
+	if err != nil {
+		t.Fatalf("CreateRegistration: %v", err)
+	}
+	if err := UpdateRegistrationStatusByID(ctx, database, keep.ID, models.RegistrationStatusPending); err != nil {
+		t.Fatalf("UpdateRegistrationStatusByID: %v", err)
+	}
+	if err := UpdateRegistrationDecklistByID(ctx, database, dup.ID, []byte(`{"main":{"Island":60}}`)); err != nil {
+		t.Fatalf("UpdateRegistrationDecklistByID: %v", err)
+	}
+	if err := AddAssignedBye(ctx, database, &models.AssignedBye{TournamentID: tourn.ID, Round: 1, RegistrationID: dup.ID}); err != nil {
+		t.Fatalf("AddAssignedBye: %v", err)
+	}
+	fee, method := 1000, "card"
+	if _, err := SetPayment(ctx, database, tourn.ID, dup.ID, models.PaymentPaid, &fee, &method); err != nil {
+		t.Fatalf("SetPayment: %v", err)
+	}
+
+	if err := MergeRegistrations(ctx, database, keep.ID, dup.ID); err != nil {
+		t.Fatalf("MergeRegistrations: %v", err)
+	}
+	got, err := GetRegistrationByID(ctx, database, keep.ID)
+	if err != nil {
+		t.Fatalf("GetRegistrationByID: %v", err)
+	}
+	if got.DisplayName != "Bob Smith" {
+		t.Errorf("DisplayName = %q, want the kept name", got.DisplayName)
+	}
+	if got.UserID == nil || *got.UserID != player.ID || got.GuestName != nil {
+		t.Errorf("UserID = %v, GuestName = %v; want the duplicate's account", got.UserID, got.GuestName)
+	}
+	if got.Status != models.RegistrationStatusConfirmed || got.Decklist == nil {
+		t.Errorf("status %q, decklist %s; want confirmed with the duplicate's decklist", got.Status, got.Decklist)
+	}
+	if got.PaymentStatus != models.PaymentPaid || got.PaymentAmount == nil || *got.PaymentAmount != fee {
+		t.Errorf("payment %q %v; want the duplicate's", got.PaymentStatus, got.PaymentAmount)
+	}
+	if _, err := GetRegistrationByID(ctx, database, dup.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("duplicate still there: %v", err)
+	}
+	byes, _ := ListAssignedByes(ctx, database, tourn.ID)
+	if len(byes) != 1 || byes[0].RegistrationID != keep.ID {
+		t.Errorf("byes = %+v, want the duplicate's bye moved over", byes)
+	}
+	if reg, err := GetRegistration(ctx, database, tourn.ID, player.ID); err != nil || reg.ID != keep.ID {
+		t.Errorf("player's registration = %+v, %v; want the kept one", reg, err)
+	}
+
+	if err := MergeRegistrations(ctx, database, keep.ID, dup.ID); err == nil {
+		t.Error("merged a missing duplicate")
+	}
+}
@@ -0,0 +1,130 @@
+package db
+
+import (
+	"context"
+	"errors"
+
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+// ErrPairingFieldNotFound is returned when a pairing field doesn't exist or
+// belongs to a different tournament.
+var ErrPairingFieldNotFound = errors.New("pairing field: not found")
+
+// PairingFieldValues holds one round's custom field values, keyed by table
+// number and then by field ID.
+type PairingFieldValues map[int]map[int64]string
+
+// CreatePairingField inserts a field definition. A duplicate label within the
+// tournament surfaces as a unique violation (23505).
+func CreatePairingField(ctx context.Context, db DBTX, f *models.PairingField) error {
+	return db.QueryRowContext(ctx,
+		`INSERT INTO pairing_fields (tournament_id, label, public)
+		 VALUES ($1, $2, $3)
+		 RETURNING id, created_at`,
+		f.TournamentID, f.Label, f.Public,
+	).Scan(&f.ID, &f.CreatedAt)
+}
+
+// ListPairingFields returns a tournament's field definitions in creation
+// order, which is also the column order on the pairings pages.
+func ListPairingFields(ctx context.Context, db DBTX, tournamentID int64) ([]models.PairingField, error) {
+	rows, err := db.QueryContext(ctx,
+		`SELECT id, tournament_id, label, public, created_at
+		 FROM pairing_fields WHERE tournament_id = $1 ORDER BY id`,
+		tournamentID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []models.PairingField
+	for rows.Next() {
+		var f models.PairingField
+		if err := rows.Scan(&f.ID, &f.TournamentID, &f.Label, &f.Public, &f.CreatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, f)
+	}
+	return out, rows.Err()
+}
+
+// DeletePairingField removes a field definition and, via the cascade, every
+// value recorded for it.
+func DeletePairingField(ctx context.Context, db DBTX, tournamentID, fieldID int64) error {
+	res, err := db.ExecContext(ctx,
+		`DELETE FROM pairing_fields WHERE id = $1 AND tournament_id = $2`,
+		fieldID, tournamentID,
+	)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrPairingFieldNotFound
+	}
+	return nil
+}
+
+// ListPairingFieldValues returns every value recorded for a round of the
+// tournament.
+func ListPairingFieldValues(ctx context.Context, db DBTX, tournamentID int64, round int) (PairingFieldValues, error) {
+	rows, err := db.QueryContext(ctx,
+		`SELECT v.table_number, v.field_id, v.value
+		 FROM pairing_field_values v
+		 JOIN pairing_fields f ON f.id = v.field_id
+		 WHERE f.tournament_id = $1 AND v.round = $2`,
+		tournamentID, round,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := PairingFieldValues{}
+	for rows.Next() {
+		var table int
+		var fieldID int64
+		var value string
+		if err := rows.Scan(&table, &fieldID, &value); err != nil {
+			return nil, err
+		}
+		if out[table] == nil {
+			out[table] = map[int64]string{}
+		}
+		out[table][fieldID] = value
+	}
+	return out, rows.Err()
+}
+
+// SetPairingFieldValue records the value of a field for one table of a
+// round, replacing any previous value. An empty value clears it. The caller
+// must have checked that the field belongs to the tournament.
+func SetPairingFieldValue(ctx context.Context, db DBTX, fieldID int64, round, table int, value string) error {
+	if value == "" {
+		_, err := db.ExecContext(ctx,
+			`DELETE FROM pairing_field_values WHERE field_id = $1 AND round = $2 AND table_number = $3`,
+			fieldID, round, table,
+		)
+		return err
+	}
+	_, err := db.ExecContext(ctx,
+		`INSERT INTO pairing_field_values (field_id, round, table_number, value)
+		 VALUES ($1, $2, $3, $4)
+		 ON CONFLICT (field_id, round, table_number)
+		 DO UPDATE SET value = EXCLUDED.value, updated_at = now()`,
+		fieldID, round, table, value,
+	)
+	return err
+}
+
+// ClearPairingFieldValues deletes every value for a round of the tournament.
+// Used when the round is re-paired, since the values describe tables whose
+// matches have changed.
+func ClearPairingFieldValues(ctx context.Context, db DBTX, tournamentID int64, round int) error {
+	_, err := db.ExecContext(ctx,
+		`DELETE FROM pairing_field_values
+		 WHERE round = $2
+		   AND field_id IN (SELECT id FROM pairing_fields WHERE tournament_id = $1)`,
+		tournamentID, round,
+	)
+	return err
+}
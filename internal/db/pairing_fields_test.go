@@ -0,0 +1,92 @@
+//go:build integration
+
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/lib/pq"
+)
+
+func TestPairingFields_Lifecycle(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	org := createTestOrganizer(t, database)
+	tourn := &models.Tournament{Name: "Fields", Status: models.TournamentStatusScheduled, OrganizerID: org.ID}
+	if err := CreateTournament(ctx, database, tourn); err != nil {
+		t.Fatalf("CreateTournament: %v", err)
+	}
+
+	stream := &models.PairingField{TournamentID: tourn.ID, Label: "Stream", Public: true}
+	board := &models.PairingField{TournamentID: tourn.ID, Label: "Board"}
+	for _, f := range []*models.PairingField{stream, board} {
+		if err := CreatePairingField(ctx, database, f); err != nil {
+			t.Fatalf("CreatePairingField %s: %v", f.Label, err)
+		}
+	}
+	dup := &models.PairingField{TournamentID: tourn.ID, Label: "Stream"}
+	var pqErr *pq.Error
+	if err := CreatePairingField(ctx, database, dup); !errors.As(err, &pqErr) || pqErr.Code != "23505" {
+		t.Errorf("duplicate label: got %v, want unique violation", err)
+	}
+
+	fields, err := ListPairingFields(ctx, database, tourn.ID)
+	if err != nil {
+		t.Fatalf("ListPairingFields: %v", err)
+	}
+	if len(fields) != 2 || fields[0].Label != "Stream" || !fields[0].Public || fields[1].Label != "Board" {
+		t.Errorf("fields = %+v", fields)
+	}
+
+	if err := SetPairingFieldValue(ctx, database, stream.ID, 1, 1, "Main stage"); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+	if err := SetPairingFieldValue(ctx, database, stream.ID, 1, 1, "Side stage"); err != nil {
+		t.Fatalf("overwrite: %v", err)
+	}
+	if err := SetPairingFieldValue(ctx, database, board.ID, 1, 2, "B"); err != nil {
+		t.Fatalf("set board: %v", err)
+	}
+	if err := SetPairingFieldValue(ctx, database, board.ID, 2, 1, "C"); err != nil {
+		t.Fatalf("set round 2: %v", err)
+	}
+	values, err := ListPairingFieldValues(ctx, database, tourn.ID, 1)
+	if err != nil {
+		t.Fatalf("ListPairingFieldValues: %v", err)
+	}
+	if values[1][stream.ID] != "Side stage" || values[2][board.ID] != "B" || len(values) != 2 {
+		t.Errorf("round 1 values = %v", values)
+	}
+
+	// Empty value clears.
+	if err := SetPairingFieldValue(ctx, database, board.ID, 1, 2, ""); err != nil {
+		t.Fatalf("clear: %v", err)
+	}
+	values, _ = ListPairingFieldValues(ctx, database, tourn.ID, 1)
+	if _, ok := values[2]; ok {
+		t.Errorf("table 2 still has values: %v", values)
+	}
+
+	if err := ClearPairingFieldValues(ctx, database, tourn.ID, 1); err != nil {
+		t.Fatalf("ClearPairingFieldValues: %v", err)
+	}
+	if values, _ = ListPairingFieldValues(ctx, database, tourn.ID, 1); len(values) != 0 {
+		t.Errorf("round 1 not cleared: %v", values)
+	}
+	if values, _ = ListPairingFieldValues(ctx, database, tourn.ID, 2); values[1][board.ID] != "C" {
+		t.Errorf("round 2 should be untouched: %v", values)
+	}
+
+	if err := DeletePairingField(ctx, database, tourn.ID, board.ID); err != nil {
+		t.Fatalf("DeletePairingField: %v", err)
+	}
+	if err := DeletePairingField(ctx, database, tourn.ID, board.ID); !errors.Is(err, ErrPairingFieldNotFound) {
+		t.Errorf("second delete: got %v, want ErrPairingFieldNotFound", err)
+	}
+	if values, _ = ListPairingFieldValues(ctx, database, tourn.ID, 2); len(values) != 0 {
+		t.Errorf("values should cascade with the field: %v", values)
+	}
+}
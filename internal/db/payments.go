@@ -0,0 +1,20 @@
+package db
+
+import (
+	"context"
+
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+// SetPayment records how registration regID of tournament tournamentID
+// paid: its status, with the amount in minor units and the method, either
+// nil if not noted. It returns the updated registration, or sql.ErrNoRows
+// if the registration isn't in the tournament.
+func SetPayment(ctx context.Context, db DBTX, tournamentID, regID int64, status string, amount *int, method *string) (*models.Registration, error) {
+	return scanRegistration(db.QueryRowContext(ctx,
+		`UPDATE registrations SET payment_status = $1, payment_amount = $2, payment_method = $3
+		 WHERE id = $4 AND tournament_id = $5
+		 RETURNING `+regCols,
+		status, amount, method, regID, tournamentID,
+	))
+}
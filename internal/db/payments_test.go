@@ -0,0 +1,49 @@
+//go:build integration
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+func TestSetPayment(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	org := createTestOrganizer(t, database)
+	tourn := &models.Tournament{Name: "Payments", Status: models.TournamentStatusRegistrationOpen, OrganizerID: org.ID, EntryFee: 1000}
+	if err := CreateTournament(ctx, database, tourn); err != nil {
+		t.Fatalf("CreateTournament: %v", err)
+	}
+	if got, _ := GetTournament(ctx, database, tourn.ID); got.EntryFee != 1000 {
+		t.Errorf("entry_fee = %d, want 1000", got.EntryFee)
+	}
+	a, _ := CreateGuestRegistration(ctx, database, tourn.ID, "Alice")
+	if a.PaymentStatus != models.PaymentUnpaid || a.PaymentAmount != nil {
+		t.Errorf("new registration payment = %q %v, want unpaid", a.PaymentStatus, a.PaymentAmount)
+	}
+
+	amount, method := 1000, "cash"
+	reg, err := SetPayment(ctx, database, tourn.ID, a.ID, models.PaymentPaid, &amount, &method)
+	if err != nil {
+		t.Fatalf("SetPayment: %v", err)
+	}
+	if reg.PaymentStatus != models.PaymentPaid || reg.PaymentAmount == nil || *reg.PaymentAmount != 1000 || reg.PaymentMethod == nil || *reg.PaymentMethod != "cash" {
+		t.Errorf("paid registration = %+v", reg)
+	}
+	if reg, _ = SetPayment(ctx, database, tourn.ID, a.ID, models.PaymentComped, nil, nil); reg.PaymentStatus != models.PaymentComped || reg.PaymentAmount != nil || reg.PaymentMethod != nil {
+		t.Errorf("comped registration = %+v", reg)
+	}
+
+	other := &models.Tournament{Name: "Other", Status: models.TournamentStatusRegistrationOpen, OrganizerID: org.ID}
+	if err := CreateTournament(ctx, database, other); err != nil {
+		t.Fatalf("CreateTournament: %v", err)
+	}
+	if _, err := SetPayment(ctx, database, other.ID, a.ID, models.PaymentPaid, nil, nil); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("other tournament: err = %v, want sql.ErrNoRows", err)
+	}
+}
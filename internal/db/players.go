@@ -0,0 +1,170 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+// ErrPlayerNotFound is returned when a player registry entry doesn't exist.
+var ErrPlayerNotFound = errors.New("player: not found")
+
+// ErrPlayerRegistered is returned when a registry entry is added to a
+// tournament it is already registered in.
+var ErrPlayerRegistered = errors.New("player is already registered in this tournament")
+
+const playerCols = `id, name, contact, rating, club, created_by, created_at, updated_at`
+
+func scanPlayer(row interface {
+	Scan(dest ...interface{}) error
+}) (*models.Player, error) {
+	p := &models.Player{}
+	if err := row.Scan(&p.ID, &p.Name, &p.Contact, &p.Rating, &p.Club, &p.CreatedBy, &p.CreatedAt, &p.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// CreatePlayer inserts a registry entry, filling in its ID and timestamps.
+func CreatePlayer(ctx context.Context, db DBTX, p *models.Player) error {
+	return db.QueryRowContext(ctx,
+		`INSERT INTO players (name, contact, rating, club, created_by)
+		 VALUES ($1, $2, $3, $4, $5)
+		 RETURNING id, created_at, updated_at`,
+		p.Name, p.Contact, p.Rating, p.Club, p.CreatedBy,
+	).Scan(&p.ID, &p.CreatedAt, &p.UpdatedAt)
+}
+
+// GetPlayer returns a registry entry, or ErrPlayerNotFound.
+func GetPlayer(ctx context.Context, db DBTX, id int64) (*models.Player, error) {
+	p, err := scanPlayer(db.QueryRowContext(ctx,
+		`SELECT `+playerCols+` FROM players WHERE id = $1`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrPlayerNotFound
+	}
+	return p, err
+}
+
+// ListPlayers returns the registry in name order. A non-empty query keeps
+// the entries whose name or contact contains it, ignoring case.
+func ListPlayers(ctx context.Context, db DBTX, query string) ([]models.Player, error) {
+	rows, err := db.QueryContext(ctx,
+		`SELECT `+playerCols+` FROM players
+		 WHERE $1 = '' OR name ILIKE '%' || $1 || '%' OR contact ILIKE '%' || $1 || '%'
+		 ORDER BY lower(name), id`,
+		strings.TrimSpace(query),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []models.Player
+	for rows.Next() {
+		p, err := scanPlayer(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *p)
+	}
+	return out, rows.Err()
+}
+
+// UpdatePlayer saves a registry entry's name, contact, rating and club. The
+// registrations already made from it keep theirs.
+func UpdatePlayer(ctx context.Context, db DBTX, p *models.Player) error {
+	err := db.QueryRowContext(ctx,
+		`UPDATE players SET name = $1, contact = $2, rating = $3, club = $4, updated_at = now()
+		 WHERE id = $5
+		 RETURNING updated_at`,
+		p.Name, p.Contact, p.Rating, p.Club, p.ID,
+	).Scan(&p.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrPlayerNotFound
+	}
+	return err
+}
+
+// DeletePlayer removes a registry entry. Registrations made from it stay,
+// unlinked.
+func DeletePlayer(ctx context.Context, db DBTX, id int64) error {
+	res, err := db.ExecContext(ctx, `DELETE FROM players WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrPlayerNotFound
+	}
+	return nil
+}
+
+// ListPlayerRegistrations returns the registrations made from a registry
+// entry, newest first.
+func ListPlayerRegistrations(ctx context.Context, db DBTX, playerID int64) ([]models.Registration, error) {
+	rows, err := db.QueryContext(ctx,
+		`SELECT `+regCols+` FROM registrations
+		 WHERE player_id = $1 ORDER BY created_at DESC, id DESC`,
+		playerID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []models.Registration
+	for rows.Next() {
+		r, err := scanRegistration(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *r)
+	}
+	return out, rows.Err()
+}
+
+// CreatePlayerRegistration registers a registry entry in a tournament as a
+// confirmed guest under the entry's name (suffixed like any guest's if the
+// name is taken), with its rating and club, linked to the entry. It returns
+// ErrPlayerRegistered if the entry is already in the tournament.
+func CreatePlayerRegistration(ctx context.Context, database *sql.DB, tournamentID int64, p *models.Player) (*models.Registration, error) {
+	name, err := models.ValidatePlayerName(p.Name)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := database.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := lockTournament(ctx, tx, tournamentID); err != nil {
+		return nil, err
+	}
+	var exists bool
+	if err := tx.QueryRowContext(ctx,
+		`SELECT EXISTS (SELECT 1 FROM registrations WHERE tournament_id = $1 AND player_id = $2)`,
+		tournamentID, p.ID,
+	).Scan(&exists); err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, ErrPlayerRegistered
+	}
+	r, err := insertGuestRegistration(ctx, tx, tournamentID, name)
+	if err != nil {
+		return nil, err
+	}
+	r, err = scanRegistration(tx.QueryRowContext(ctx,
+		`UPDATE registrations SET player_id = $1, rating = $2, club = $3 WHERE id = $4
+		 RETURNING `+regCols,
+		p.ID, p.Rating, p.Club, r.ID,
+	))
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
@@ -0,0 +1,75 @@
+//go:build integration
+
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+func TestPlayerRegistry(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	org := createTestOrganizer(t, database)
+
+	contact, rating, club := "ada@example.com", 1650, "Analytical"
+	ada := &models.Player{Name: "Ada", Contact: &contact, Rating: &rating, Club: &club, CreatedBy: &org.ID}
+	bob := &models.Player{Name: "Bob"}
+	for _, p := range []*models.Player{ada, bob} {
+		if err := CreatePlayer(ctx, database, p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if all, _ := ListPlayers(ctx, database, ""); len(all) != 2 || all[0].Name != "Ada" {
+		t.Errorf("ListPlayers = %+v", all)
+	}
+	if found, _ := ListPlayers(ctx, database, "EXAMPLE"); len(found) != 1 || found[0].ID != ada.ID {
+		t.Errorf("search by contact = %+v", found)
+	}
+
+	tourn := &models.Tournament{Name: "Weekly", Status: models.TournamentStatusRegistrationOpen, OrganizerID: org.ID}
+	if err := CreateTournament(ctx, database, tourn); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := CreateGuestRegistration(ctx, database, tourn.ID, "Ada"); err != nil {
+		t.Fatal(err)
+	}
+	reg, err := CreatePlayerRegistration(ctx, database, tourn.ID, ada)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reg.DisplayName != "Ada (2)" || reg.PlayerID == nil || *reg.PlayerID != ada.ID || reg.Rating == nil || *reg.Rating != rating || reg.Club == nil || *reg.Club != club {
+		t.Errorf("registration = %+v", reg)
+	}
+	if _, err := CreatePlayerRegistration(ctx, database, tourn.ID, ada); !errors.Is(err, ErrPlayerRegistered) {
+		t.Errorf("second add: err = %v, want ErrPlayerRegistered", err)
+	}
+
+	ada.Name, ada.Rating = "Ada L.", nil
+	if err := UpdatePlayer(ctx, database, ada); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := GetPlayer(ctx, database, ada.ID); got.Name != "Ada L." || got.Rating != nil {
+		t.Errorf("after update = %+v", got)
+	}
+	if regs, _ := ListPlayerRegistrations(ctx, database, ada.ID); len(regs) != 1 || regs[0].ID != reg.ID {
+		t.Errorf("ListPlayerRegistrations = %+v", regs)
+	}
+
+	// Deleting the entry keeps the registration, unlinked.
+	if err := DeletePlayer(ctx, database, ada.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := GetPlayer(ctx, database, ada.ID); !errors.Is(err, ErrPlayerNotFound) {
+		t.Errorf("GetPlayer after delete: err = %v", err)
+	}
+	if got, err := GetRegistrationByID(ctx, database, reg.ID); err != nil || got.PlayerID != nil {
+		t.Errorf("registration after delete = %+v, %v", got, err)
+	}
+	if err := DeletePlayer(ctx, database, ada.ID); !errors.Is(err, ErrPlayerNotFound) {
+		t.Errorf("second delete: err = %v", err)
+	}
+}
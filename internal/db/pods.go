@@ -0,0 +1,156 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/lib/pq"
+)
+
+// CreatePod inserts pod, whose ParentID must be set, and gives the parent's
+// staff the same tiers on it, so whoever runs the event runs its pods.
+// The pod's organizer becomes an admin as with CreateTournament.
+func CreatePod(ctx context.Context, database *sql.DB, pod *models.Tournament) error {
+	tx, err := database.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := insertPod(ctx, tx, pod); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// insertPod inserts pod and copies its parent's staff onto it.
+func insertPod(ctx context.Context, tx *sql.Tx, pod *models.Tournament) error {
+	if err := insertTournament(ctx, tx, pod); err != nil {
+		return err
+	}
+	_, err := tx.ExecContext(ctx,
+		`INSERT INTO tournament_staff (tournament_id, user_id, tier, granted_by)
+		 SELECT $1, user_id, tier, granted_by FROM tournament_staff WHERE tournament_id = $2
+		 ON CONFLICT DO NOTHING`,
+		pod.ID, *pod.ParentID,
+	)
+	return err
+}
+
+// CreatePools inserts pools, pods of the same parent, as CreatePod does and
+// moves the registrations members[i] from the parent into pools[i], with
+// their decklists, payments and everything else. Byes staff assigned them
+// in the parent are dropped. All or nothing.
+func CreatePools(ctx context.Context, database *sql.DB, parentID int64, pools []*models.Tournament, members [][]int64) error {
+	tx, err := database.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := lockTournament(ctx, tx, parentID); err != nil {
+		return err
+	}
+	for i, pool := range pools {
+		if err := insertPod(ctx, tx, pool); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx,
+			`DELETE FROM assigned_byes WHERE tournament_id = $1 AND registration_id = ANY($2)`,
+			parentID, pq.Array(members[i]),
+		); err != nil {
+			return err
+		}
+		res, err := tx.ExecContext(ctx,
+			`UPDATE registrations SET tournament_id = $1 WHERE tournament_id = $2 AND id = ANY($3)`,
+			pool.ID, parentID, pq.Array(members[i]),
+		)
+		if err != nil {
+			return err
+		}
+		if n, err := res.RowsAffected(); err != nil {
+			return err
+		} else if n != int64(len(members[i])) {
+			return errors.New("registrations changed while dealing the pools; try again")
+		}
+	}
+	return tx.Commit()
+}
+
+// ListPods returns the pods of tournament parentID in the order they were
+// created, engine state included.
+func ListPods(ctx context.Context, db DBTX, parentID int64) ([]models.Tournament, error) {
+	rows, err := db.QueryContext(ctx,
+		`SELECT `+tournamentCols+`, engine_state FROM tournaments
+		 WHERE parent_id = $1 ORDER BY id`,
+		parentID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var pods []models.Tournament
+	for rows.Next() {
+		var t models.Tournament
+		if err := rows.Scan(append(tournamentDest(&t), &t.EngineState)...); err != nil {
+			return nil, err
+		}
+		pods = append(pods, t)
+	}
+	return pods, rows.Err()
+}
+
+// AdvanceRegistrations registers each of from, registrations in pods of
+// tournament finalsID, into it as a confirmed player under the same name,
+// rating, archetype, club, roster and registry entry, recording where they came from in
+// advanced_from and their place in from, counting from 1, as pod_seed. It
+// skips anyone already advanced, and accounts already registered some
+// other way, so advancing twice adds nobody. It returns how many it added.
+func AdvanceRegistrations(ctx context.Context, database *sql.DB, finalsID int64, from []models.Registration) (int, error) {
+	tx, err := database.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if err := lockTournament(ctx, tx, finalsID); err != nil {
+		return 0, err
+	}
+	added := 0
+	for i, src := range from {
+		var exists bool
+		if err := tx.QueryRowContext(ctx,
+			`SELECT EXISTS (SELECT 1 FROM registrations
+			 WHERE tournament_id = $1 AND (advanced_from = $2 OR user_id = $3))`,
+			finalsID, src.ID, src.UserID,
+		).Scan(&exists); err != nil {
+			return 0, err
+		}
+		if exists {
+			continue
+		}
+
+		var r *models.Registration
+		if src.UserID != nil {
+			r, err = insertUserRegistration(ctx, tx, finalsID, *src.UserID, src.DisplayName, models.RegistrationStatusConfirmed)
+		} else {
+			r, err = insertGuestRegistration(ctx, tx, finalsID, src.DisplayName)
+		}
+		if err != nil {
+			return 0, err
+		}
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE registrations SET advanced_from = $1, rating = $2, archetype = $3, club = $4, members = $5, player_id = $6, pod_seed = $7 WHERE id = $8`,
+			src.ID, src.Rating, src.Archetype, src.Club, pq.Array(members(src.Members)), src.PlayerID, i+1, r.ID,
+		); err != nil {
+			return 0, err
+		}
+		added++
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return added, nil
+}
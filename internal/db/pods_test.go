@@ -0,0 +1,80 @@
+//go:build integration
+
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+func TestPods_CreateListAdvance(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	org := createTestOrganizer(t, database)
+	judge, err := CreateUser(ctx, database, "judge-pods@example.com", "Judge Pods", "hash")
+	if err != nil {
+		t.Fatalf("create judge: %v", err)
+	}
+	finals := &models.Tournament{Name: "Regionals", Status: models.TournamentStatusScheduled, OrganizerID: org.ID, PodAdvance: 2}
+	if err := CreateTournament(ctx, database, finals); err != nil {
+		t.Fatalf("CreateTournament: %v", err)
+	}
+	if err := AddTournamentStaff(ctx, database, &models.TournamentStaff{
+		TournamentID: finals.ID, UserID: judge.ID, Tier: models.TierJudge, GrantedBy: &org.ID,
+	}); err != nil {
+		t.Fatalf("add judge: %v", err)
+	}
+
+	pod := &models.Tournament{Name: "Pod A", Status: models.TournamentStatusRegistrationOpen,
+		OrganizerID: org.ID, PointsWin: 3, MinPlayers: 2, PairingAlgorithm: models.PairingSwiss,
+		ByePolicy: models.ByeLowest, Round1Pairing: models.Round1Random, ParentID: &finals.ID}
+	if err := CreatePod(ctx, database, pod); err != nil {
+		t.Fatalf("CreatePod: %v", err)
+	}
+	if tier, err := GetTournamentTier(ctx, database, pod.ID, judge.ID); err != nil || tier != models.TierJudge {
+		t.Errorf("judge on pod: tier %q, err %v", tier, err)
+	}
+	pods, err := ListPods(ctx, database, finals.ID)
+	if err != nil || len(pods) != 1 || pods[0].ID != pod.ID || pods[0].ParentID == nil || *pods[0].ParentID != finals.ID {
+		t.Fatalf("ListPods = %+v, %v", pods, err)
+	}
+	if got, _ := GetTournament(ctx, database, finals.ID); got.PodAdvance != 2 || got.ParentID != nil {
+		t.Errorf("finals = %+v", got)
+	}
+
+	guest, _ := CreateGuestRegistration(ctx, database, pod.ID, "Walk In")
+	rating, club := 1800, "Walkers"
+	guest.Rating, guest.Club = &rating, &club
+	user, _ := CreateRegistration(ctx, database, pod.ID, judge.ID, "Judge Pods")
+	for round := 0; round < 2; round++ {
+		n, err := AdvanceRegistrations(ctx, database, finals.ID, []models.Registration{*guest, *user})
+		if err != nil {
+			t.Fatalf("AdvanceRegistrations: %v", err)
+		}
+		if want := 2 - 2*round; n != want {
+			t.Errorf("pass %d added %d, want %d", round+1, n, want)
+		}
+	}
+	regs, _ := ListRegistrations(ctx, database, finals.ID)
+	if len(regs) != 2 {
+		t.Fatalf("finals registrations = %+v", regs)
+	}
+	for _, r := range regs {
+		if r.AdvancedFrom == nil || r.Status != models.RegistrationStatusConfirmed {
+			t.Errorf("finalist = %+v", r)
+		}
+		if *r.AdvancedFrom == guest.ID && (r.Rating == nil || *r.Rating != 1800 || r.Club == nil || *r.Club != club || r.UserID != nil) {
+			t.Errorf("advanced guest = %+v", r)
+		}
+	}
+
+	// Deleting the event takes its pods with it.
+	if err := DeleteTournament(ctx, database, finals.ID); err != nil {
+		t.Fatalf("DeleteTournament: %v", err)
+	}
+	if _, err := GetTournament(ctx, database, pod.ID); err == nil {
+		t.Error("pod outlived its event")
+	}
+}
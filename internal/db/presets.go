@@ -0,0 +1,90 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+// ErrPresetNotFound is returned when a settings preset doesn't exist.
+var ErrPresetNotFound = errors.New("preset: not found")
+
+const presetCols = `id, name, settings, created_by, created_at, updated_at`
+
+func scanPreset(row interface {
+	Scan(dest ...interface{}) error
+}) (*models.Preset, error) {
+	p := &models.Preset{}
+	var settings []byte
+	if err := row.Scan(&p.ID, &p.Name, &settings, &p.CreatedBy, &p.CreatedAt, &p.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(settings, &p.Settings); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// SavePreset stores p, filling in its ID and timestamps. A preset whose
+// name matches p's, ignoring case, is replaced: it takes p's name, settings
+// and author and keeps its ID.
+func SavePreset(ctx context.Context, db DBTX, p *models.Preset) error {
+	settings, err := json.Marshal(p.Settings)
+	if err != nil {
+		return err
+	}
+	return db.QueryRowContext(ctx,
+		`INSERT INTO tournament_presets (name, settings, created_by)
+		 VALUES ($1, $2, $3)
+		 ON CONFLICT ((lower(name))) DO UPDATE
+		 SET name = EXCLUDED.name, settings = EXCLUDED.settings,
+		     created_by = EXCLUDED.created_by, updated_at = now()
+		 RETURNING id, created_at, updated_at`,
+		p.Name, settings, p.CreatedBy,
+	).Scan(&p.ID, &p.CreatedAt, &p.UpdatedAt)
+}
+
+// GetPreset returns a preset, or ErrPresetNotFound.
+func GetPreset(ctx context.Context, db DBTX, id int64) (*models.Preset, error) {
+	p, err := scanPreset(db.QueryRowContext(ctx,
+		`SELECT `+presetCols+` FROM tournament_presets WHERE id = $1`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrPresetNotFound
+	}
+	return p, err
+}
+
+// ListPresets returns every preset in name order.
+func ListPresets(ctx context.Context, db DBTX) ([]models.Preset, error) {
+	rows, err := db.QueryContext(ctx,
+		`SELECT `+presetCols+` FROM tournament_presets ORDER BY lower(name), id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []models.Preset
+	for rows.Next() {
+		p, err := scanPreset(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *p)
+	}
+	return out, rows.Err()
+}
+
+// DeletePreset removes a preset, or returns ErrPresetNotFound. Tournaments
+// created from it keep their settings.
+func DeletePreset(ctx context.Context, db DBTX, id int64) error {
+	res, err := db.ExecContext(ctx, `DELETE FROM tournament_presets WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrPresetNotFound
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+//go:build integration
+
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+func TestPresets_Lifecycle(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	org := createTestOrganizer(t, database)
+
+	weekly := &models.Preset{Name: "Weekly", CreatedBy: &org.ID, Settings: models.PresetSettings{
+		PairingAlgorithm: models.PairingSwiss, PointsWin: 3, PointsDraw: 1,
+		PairingFields: []models.PresetField{{Label: "Board", Public: true}},
+	}}
+	if err := SavePreset(ctx, database, weekly); err != nil {
+		t.Fatalf("SavePreset: %v", err)
+	}
+	got, err := GetPreset(ctx, database, weekly.ID)
+	if err != nil {
+		t.Fatalf("GetPreset: %v", err)
+	}
+	if got.Name != "Weekly" || got.Settings.PointsWin != 3 || len(got.Settings.PairingFields) != 1 || *got.CreatedBy != org.ID {
+		t.Errorf("got %+v", got)
+	}
+
+	// The same name in another case replaces it, keeping the ID.
+	again := &models.Preset{Name: "WEEKLY", Settings: models.PresetSettings{PairingAlgorithm: models.PairingSwiss, PointsWin: 2}}
+	if err := SavePreset(ctx, database, again); err != nil {
+		t.Fatalf("SavePreset again: %v", err)
+	}
+	if again.ID != weekly.ID {
+		t.Errorf("replaced preset has ID %d, want %d", again.ID, weekly.ID)
+	}
+	if err := SavePreset(ctx, database, &models.Preset{Name: "Another"}); err != nil {
+		t.Fatalf("SavePreset another: %v", err)
+	}
+	presets, err := ListPresets(ctx, database)
+	if err != nil {
+		t.Fatalf("ListPresets: %v", err)
+	}
+	if len(presets) != 2 || presets[0].Name != "Another" || presets[1].Name != "WEEKLY" || presets[1].Settings.PointsWin != 2 || presets[1].CreatedBy != nil {
+		t.Errorf("presets = %+v", presets)
+	}
+
+	// A tournament created with the preset's fields gets them.
+	tourn := &models.Tournament{Name: "From Preset", Status: models.TournamentStatusScheduled, OrganizerID: org.ID}
+	if err := CreateTournamentWithFields(ctx, database, tourn, weekly.Settings.PairingFields); err != nil {
+		t.Fatalf("CreateTournamentWithFields: %v", err)
+	}
+	if fields, _ := ListPairingFields(ctx, database, tourn.ID); len(fields) != 1 || fields[0].Label != "Board" || !fields[0].Public {
+		t.Errorf("fields = %+v", fields)
+	}
+
+	if err := DeletePreset(ctx, database, weekly.ID); err != nil {
+		t.Fatalf("DeletePreset: %v", err)
+	}
+	if err := DeletePreset(ctx, database, weekly.ID); !errors.Is(err, ErrPresetNotFound) {
+		t.Errorf("delete again: got %v, want ErrPresetNotFound", err)
+	}
+	if _, err := GetPreset(ctx, database, weekly.ID); !errors.Is(err, ErrPresetNotFound) {
+		t.Errorf("get deleted: got %v, want ErrPresetNotFound", err)
+	}
+}
@@ -0,0 +1,70 @@
+package db
+
+import (
+	"context"
+
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+// ListAllUserRegistrations returns every registration of the user,
+// dropped ones included, oldest first.
+func ListAllUserRegistrations(ctx context.Context, db DBTX, userID int64) ([]models.Registration, error) {
+	rows, err := db.QueryContext(ctx,
+		`SELECT `+regCols+` FROM registrations WHERE user_id = $1 ORDER BY created_at, id`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var regs []models.Registration
+	for rows.Next() {
+		r, err := scanRegistration(rows)
+		if err != nil {
+			return nil, err
+		}
+		regs = append(regs, *r)
+	}
+	return regs, rows.Err()
+}
+
+// AnonymizeRegistration turns registration regID into a guest called
+// name, unlinked from its account and registry entry, with its club and
+// contact consent cleared. Its results, decklist and payment stay.
+func AnonymizeRegistration(ctx context.Context, db DBTX, regID int64, name string) error {
+	_, err := db.ExecContext(ctx,
+		`UPDATE registrations
+		 SET user_id = NULL, guest_name = $2, display_name = $2, club = NULL,
+		     player_id = NULL, contact_consent = false
+		 WHERE id = $1`,
+		regID, name,
+	)
+	return err
+}
+
+// DeleteRegistrationPlayer deletes the registry entry registration regID
+// was made from, if any, with its name, contact, rating and club. Other
+// registrations made from it stay, unlinked.
+func DeleteRegistrationPlayer(ctx context.Context, db DBTX, regID int64) error {
+	_, err := db.ExecContext(ctx,
+		`DELETE FROM players WHERE id = (SELECT player_id FROM registrations WHERE id = $1)`, regID)
+	return err
+}
+
+// AnonymizeArchivedResults renames a player's results in the archive of
+// tournament tournamentID to name and unlinks them from their account and
+// registry entry. The player is the user userID or, for a guest (nil), the
+// unlinked results under oldName.
+func AnonymizeArchivedResults(ctx context.Context, db DBTX, tournamentID int64, userID *int64, oldName, name string) error {
+	_, err := db.ExecContext(ctx,
+		`UPDATE archive_results SET name = $4, user_id = NULL, player_id = NULL
+		 WHERE tournament_id = $1 AND (user_id = $2 OR ($2::bigint IS NULL AND user_id IS NULL AND name = $3))`,
+		tournamentID, userID, oldName, name,
+	)
+	return err
+}
+
+// DeleteSnapshots deletes every snapshot of tournament tournamentID.
+func DeleteSnapshots(ctx context.Context, db DBTX, tournamentID int64) error {
+	_, err := db.ExecContext(ctx,
+		`DELETE FROM tournament_snapshots WHERE tournament_id = $1`, tournamentID)
+	return err
+}
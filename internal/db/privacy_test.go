@@ -0,0 +1,107 @@
+//go:build integration
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+func TestAnonymizeRegistration(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	org := createTestOrganizer(t, database)
+	tourn := &models.Tournament{Name: "Privacy", Status: models.TournamentStatusRegistrationOpen, OrganizerID: org.ID}
+	if err := CreateTournament(ctx, database, tourn); err != nil {
+		t.Fatalf("CreateTournament: %v", err)
+	}
+	ann, err := CreateUser(ctx, database, "ann-privacy@example.com", "Ann", "hash")
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	reg, err := RegisterUser(ctx, database, tourn.ID, ann.ID, "Ann", models.RegistrationStatusConfirmed, 0)
+	if err != nil {
+		t.Fatalf("RegisterUser: %v", err)
+	}
+	SetContactConsent(ctx, database, tourn.ID, ann.ID, true)
+	contact := "ann-privacy@example.com"
+	entry := &models.Player{Name: "Ann", Contact: &contact}
+	if err := CreatePlayer(ctx, database, entry); err != nil {
+		t.Fatalf("CreatePlayer: %v", err)
+	}
+	if _, err := database.ExecContext(ctx, `UPDATE registrations SET player_id = $1 WHERE id = $2`, entry.ID, reg.ID); err != nil {
+		t.Fatalf("link registry entry: %v", err)
+	}
+	if regs, _ := ListAllUserRegistrations(ctx, database, ann.ID); len(regs) != 1 {
+		t.Fatalf("registrations = %+v", regs)
+	}
+	if err := ArchiveTournament(ctx, database, tourn.ID, []models.ArchivedResult{{Place: 1, Name: "Ann", UserID: &ann.ID}}); err != nil {
+		t.Fatalf("ArchiveTournament: %v", err)
+	}
+
+	if err := DeleteRegistrationPlayer(ctx, database, reg.ID); err != nil {
+		t.Fatalf("DeleteRegistrationPlayer: %v", err)
+	}
+	if err := AnonymizeRegistration(ctx, database, reg.ID, "Player #1"); err != nil {
+		t.Fatalf("AnonymizeRegistration: %v", err)
+	}
+	if err := AnonymizeArchivedResults(ctx, database, tourn.ID, &ann.ID, "Ann", "Player #1"); err != nil {
+		t.Fatalf("AnonymizeArchivedResults: %v", err)
+	}
+	got, _ := GetRegistrationByID(ctx, database, reg.ID)
+	if got.UserID != nil || got.GuestName == nil || *got.GuestName != "Player #1" || got.DisplayName != "Player #1" || got.ContactConsent || got.Status != models.RegistrationStatusConfirmed {
+		t.Errorf("anonymized = %+v", got)
+	}
+	if results, _ := ListArchivedResults(ctx, database, tourn.ID); len(results) != 1 || results[0].Name != "Player #1" || results[0].UserID != nil {
+		t.Errorf("archived = %+v", results)
+	}
+	if regs, _ := ListAllUserRegistrations(ctx, database, ann.ID); len(regs) != 0 {
+		t.Errorf("still linked: %+v", regs)
+	}
+	if _, err := GetPlayer(ctx, database, entry.ID); !errors.Is(err, ErrPlayerNotFound) {
+		t.Errorf("registry entry: err = %v, want ErrPlayerNotFound", err)
+	}
+	var left int
+	if err := database.QueryRowContext(ctx,
+		`SELECT count(*) FROM players WHERE contact = $1`, contact,
+	).Scan(&left); err != nil || left != 0 {
+		t.Errorf("players still holding Ann's data = %d (err %v)", left, err)
+	}
+}
+
+func TestDeleteUser(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	org := createTestOrganizer(t, database)
+	heir, err := CreateUser(ctx, database, "heir@example.com", "Heir", "hash")
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	tourn := &models.Tournament{Name: "Orphan", Status: models.TournamentStatusScheduled, OrganizerID: org.ID}
+	if err := CreateTournament(ctx, database, tourn); err != nil {
+		t.Fatalf("CreateTournament: %v", err)
+	}
+	if err := SetDeletionRequested(ctx, database, org.ID, true); err != nil {
+		t.Fatalf("SetDeletionRequested: %v", err)
+	}
+	if requests, _ := ListDeletionRequests(ctx, database); len(requests) != 1 || requests[0].DeletionRequestedAt == nil {
+		t.Errorf("requests = %+v", requests)
+	}
+
+	if err := DeleteUser(ctx, database, org.ID, heir.ID); err != nil {
+		t.Fatalf("DeleteUser: %v", err)
+	}
+	if got, _ := GetTournament(ctx, database, tourn.ID); got.OrganizerID != heir.ID {
+		t.Errorf("organizer = %d, want %d", got.OrganizerID, heir.ID)
+	}
+	if requests, _ := ListDeletionRequests(ctx, database); len(requests) != 0 {
+		t.Errorf("requests after deleting = %+v", requests)
+	}
+	if err := DeleteUser(ctx, database, org.ID, heir.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("again: err = %v, want sql.ErrNoRows", err)
+	}
+}
@@ -0,0 +1,26 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+)
+
+// SetRatings sets the ratings of registrations of tournament tournamentID,
+// keyed by registration ID; a nil rating clears it. It returns
+// sql.ErrNoRows at the first registration that isn't in the tournament, so
+// run it in a transaction to set all or none.
+func SetRatings(ctx context.Context, db DBTX, tournamentID int64, ratings map[int64]*int) error {
+	for regID, rating := range ratings {
+		res, err := db.ExecContext(ctx,
+			`UPDATE registrations SET rating = $1 WHERE id = $2 AND tournament_id = $3`,
+			rating, regID, tournamentID,
+		)
+		if err != nil {
+			return err
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			return sql.ErrNoRows
+		}
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+//go:build integration
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+func TestSetRatings(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	org := createTestOrganizer(t, database)
+	tourn := &models.Tournament{Name: "Ratings", Status: models.TournamentStatusRegistrationOpen, OrganizerID: org.ID}
+	if err := CreateTournament(ctx, database, tourn); err != nil {
+		t.Fatalf("CreateTournament: %v", err)
+	}
+	if got, _ := GetTournament(ctx, database, tourn.ID); got.Round1Pairing != models.Round1Random {
+		t.Errorf("round1_pairing defaults to %q", got.Round1Pairing)
+	}
+	a, _ := CreateGuestRegistration(ctx, database, tourn.ID, "Alice")
+	b, _ := CreateGuestRegistration(ctx, database, tourn.ID, "Bob")
+	high, low := 2100, 1500
+
+	if err := SetRatings(ctx, database, tourn.ID, map[int64]*int{a.ID: &high, b.ID: &low}); err != nil {
+		t.Fatalf("SetRatings: %v", err)
+	}
+	if err := SetRatings(ctx, database, tourn.ID, map[int64]*int{b.ID: nil}); err != nil {
+		t.Fatalf("SetRatings (clear): %v", err)
+	}
+	regs, _ := ListRegistrations(ctx, database, tourn.ID)
+	if regs[0].Rating == nil || *regs[0].Rating != high || regs[1].Rating != nil {
+		t.Errorf("ratings = %v, %v; want %d, nil", regs[0].Rating, regs[1].Rating, high)
+	}
+
+	other := &models.Tournament{Name: "Other", Status: models.TournamentStatusRegistrationOpen, OrganizerID: org.ID}
+	CreateTournament(ctx, database, other)
+	stranger, _ := CreateGuestRegistration(ctx, database, other.ID, "Stranger")
+	if err := SetRatings(ctx, database, tourn.ID, map[int64]*int{stranger.ID: &low}); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("foreign registration: err = %v", err)
+	}
+	if got, _ := GetRegistrationByID(ctx, database, stranger.ID); got.Rating != nil {
+		t.Errorf("another tournament's player was rated %d", *got.Rating)
+	}
+}
@@ -0,0 +1,119 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+const resultReportCols = `tournament_id, round, table_number, side, wins, losses, draws, user_id, reported_at`
+
+// scanResultReports reads rows selected with resultReportCols and closes
+// them.
+func scanResultReports(rows *sql.Rows) ([]models.ResultReport, error) {
+	defer rows.Close()
+	var out []models.ResultReport
+	for rows.Next() {
+		var r models.ResultReport
+		if err := rows.Scan(&r.TournamentID, &r.Round, &r.Table, &r.Side, &r.Wins, &r.Losses, &r.Draws,
+			&r.UserID, &r.ReportedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
+
+// ListResultReports returns the player reports of a round still waiting to
+// count, grouped by table, side A's first.
+func ListResultReports(ctx context.Context, db DBTX, tournamentID int64, round int) (map[int][]models.ResultReport, error) {
+	rows, err := db.QueryContext(ctx,
+		`SELECT `+resultReportCols+` FROM result_reports
+		 WHERE tournament_id = $1 AND round = $2
+		 ORDER BY table_number, side`,
+		tournamentID, round,
+	)
+	if err != nil {
+		return nil, err
+	}
+	reports, err := scanResultReports(rows)
+	if err != nil {
+		return nil, err
+	}
+	out := map[int][]models.ResultReport{}
+	for _, r := range reports {
+		out[r.Table] = append(out[r.Table], r)
+	}
+	return out, nil
+}
+
+// ListTableReports returns the player reports at one table of a round,
+// side A's first.
+func ListTableReports(ctx context.Context, db DBTX, tournamentID int64, round, table int) ([]models.ResultReport, error) {
+	rows, err := db.QueryContext(ctx,
+		`SELECT `+resultReportCols+` FROM result_reports
+		 WHERE tournament_id = $1 AND round = $2 AND table_number = $3
+		 ORDER BY side`,
+		tournamentID, round, table,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return scanResultReports(rows)
+}
+
+// SetResultReport records a player's report for their side of a table,
+// replacing the one they made before.
+func SetResultReport(ctx context.Context, db DBTX, r *models.ResultReport) error {
+	return db.QueryRowContext(ctx,
+		`INSERT INTO result_reports (tournament_id, round, table_number, side, wins, losses, draws, user_id)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		 ON CONFLICT (tournament_id, round, table_number, side)
+		 DO UPDATE SET wins = EXCLUDED.wins, losses = EXCLUDED.losses, draws = EXCLUDED.draws,
+		               user_id = EXCLUDED.user_id, reported_at = now()
+		 RETURNING reported_at`,
+		r.TournamentID, r.Round, r.Table, r.Side, r.Wins, r.Losses, r.Draws, r.UserID,
+	).Scan(&r.ReportedAt)
+}
+
+// DeleteResultReports removes the player reports of a table, if any. Used
+// once the table has a result.
+func DeleteResultReports(ctx context.Context, db DBTX, tournamentID int64, round, table int) error {
+	_, err := db.ExecContext(ctx,
+		`DELETE FROM result_reports WHERE tournament_id = $1 AND round = $2 AND table_number = $3`,
+		tournamentID, round, table,
+	)
+	return err
+}
+
+// ClearResultReports deletes every player report of a round. Used when the
+// round is re-paired.
+func ClearResultReports(ctx context.Context, db DBTX, tournamentID int64, round int) error {
+	_, err := db.ExecContext(ctx,
+		`DELETE FROM result_reports WHERE tournament_id = $1 AND round = $2`,
+		tournamentID, round,
+	)
+	return err
+}
+
+// ListLapsedReports returns the lone player reports of running tournaments
+// that have waited out their tournament's report_confirm_minutes without
+// the opponent reporting, oldest first.
+func ListLapsedReports(ctx context.Context, db DBTX) ([]models.ResultReport, error) {
+	rows, err := db.QueryContext(ctx,
+		`SELECT r.tournament_id, r.round, r.table_number, r.side, r.wins, r.losses, r.draws, r.user_id, r.reported_at
+		 FROM result_reports r JOIN tournaments t ON t.id = r.tournament_id
+		 WHERE t.status = $1 AND t.self_report AND t.report_confirm_minutes > 0
+		   AND r.reported_at < now() - make_interval(mins => t.report_confirm_minutes)
+		   AND NOT EXISTS (SELECT 1 FROM result_reports o
+		                   WHERE o.tournament_id = r.tournament_id AND o.round = r.round
+		                     AND o.table_number = r.table_number AND o.side <> r.side)
+		 ORDER BY r.reported_at`,
+		models.TournamentStatusInProgress,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return scanResultReports(rows)
+}
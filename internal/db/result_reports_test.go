@@ -0,0 +1,88 @@
+//go:build integration
+
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+func TestResultReports_Lifecycle(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	org := createTestOrganizer(t, database)
+	tourn := &models.Tournament{
+		Name: "Reports", Status: models.TournamentStatusInProgress, OrganizerID: org.ID,
+		SelfReport: true, ReportConfirmMinutes: 5,
+	}
+	if err := CreateTournament(ctx, database, tourn); err != nil {
+		t.Fatalf("CreateTournament: %v", err)
+	}
+	got, err := GetTournament(ctx, database, tourn.ID)
+	if err != nil {
+		t.Fatalf("GetTournament: %v", err)
+	}
+	if !got.SelfReport || got.ReportConfirmMinutes != 5 {
+		t.Errorf("self_report = %v, report_confirm_minutes = %d", got.SelfReport, got.ReportConfirmMinutes)
+	}
+
+	for _, r := range []models.ResultReport{
+		{Table: 1, Side: models.GameWinnerA, Wins: 2},
+		{Table: 2, Side: models.GameWinnerB, Wins: 2, Losses: 1},
+		{Table: 2, Side: models.GameWinnerA, Wins: 1, Losses: 2},
+		{Table: 2, Side: models.GameWinnerA, Wins: 2, Losses: 1}, // reported again
+	} {
+		r.TournamentID, r.Round, r.UserID = tourn.ID, 1, &org.ID
+		if err := SetResultReport(ctx, database, &r); err != nil {
+			t.Fatalf("SetResultReport: %v", err)
+		}
+	}
+	reports, err := ListResultReports(ctx, database, tourn.ID, 1)
+	if err != nil {
+		t.Fatalf("ListResultReports: %v", err)
+	}
+	if len(reports[1]) != 1 || len(reports[2]) != 2 || reports[2][0].Side != models.GameWinnerA || !reports[2][0].Agrees(reports[2][1]) {
+		t.Errorf("reports = %+v", reports)
+	}
+
+	// Other tests' tournaments share the database.
+	lapsedHere := func() []models.ResultReport {
+		t.Helper()
+		all, err := ListLapsedReports(ctx, database)
+		if err != nil {
+			t.Fatalf("ListLapsedReports: %v", err)
+		}
+		var out []models.ResultReport
+		for _, r := range all {
+			if r.TournamentID == tourn.ID {
+				out = append(out, r)
+			}
+		}
+		return out
+	}
+	if lapsed := lapsedHere(); len(lapsed) != 0 {
+		t.Errorf("lapsed before the wait = %+v", lapsed)
+	}
+	if _, err := database.ExecContext(ctx,
+		`UPDATE result_reports SET reported_at = now() - interval '10 minutes' WHERE tournament_id = $1`, tourn.ID); err != nil {
+		t.Fatal(err)
+	}
+	if lapsed := lapsedHere(); len(lapsed) != 1 || lapsed[0].Table != 1 {
+		t.Errorf("lapsed = %+v, want table 1's lone report", lapsed)
+	}
+
+	if err := DeleteResultReports(ctx, database, tourn.ID, 1, 1); err != nil {
+		t.Fatalf("DeleteResultReports: %v", err)
+	}
+	if table1, _ := ListTableReports(ctx, database, tourn.ID, 1, 1); len(table1) != 0 {
+		t.Errorf("table 1 after delete = %+v", table1)
+	}
+	if err := ClearResultReports(ctx, database, tourn.ID, 1); err != nil {
+		t.Fatalf("ClearResultReports: %v", err)
+	}
+	if reports, _ = ListResultReports(ctx, database, tourn.ID, 1); len(reports) != 0 {
+		t.Errorf("reports after clear = %+v", reports)
+	}
+}
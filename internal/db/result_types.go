@@ -0,0 +1,66 @@
+package db
+
+import (
+	"context"
+
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+// ListMatchResultTypes returns the special results of a round, keyed by
+// table.
+func ListMatchResultTypes(ctx context.Context, db DBTX, tournamentID int64, round int) (map[int]models.MatchResultType, error) {
+	rows, err := db.QueryContext(ctx,
+		`SELECT tournament_id, round, table_number, type, conceded_by, reported_by, reported_at
+		 FROM match_result_types
+		 WHERE tournament_id = $1 AND round = $2`,
+		tournamentID, round,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := map[int]models.MatchResultType{}
+	for rows.Next() {
+		var rt models.MatchResultType
+		if err := rows.Scan(&rt.TournamentID, &rt.Round, &rt.Table, &rt.Type, &rt.ConcededBy,
+			&rt.ReportedBy, &rt.ReportedAt); err != nil {
+			return nil, err
+		}
+		out[rt.Table] = rt
+	}
+	return out, rows.Err()
+}
+
+// SetMatchResultType records the special result of a table, replacing any
+// previous one.
+func SetMatchResultType(ctx context.Context, db DBTX, rt *models.MatchResultType) error {
+	return db.QueryRowContext(ctx,
+		`INSERT INTO match_result_types (tournament_id, round, table_number, type, conceded_by, reported_by)
+		 VALUES ($1, $2, $3, $4, $5, $6)
+		 ON CONFLICT (tournament_id, round, table_number)
+		 DO UPDATE SET type = EXCLUDED.type, conceded_by = EXCLUDED.conceded_by,
+		               reported_by = EXCLUDED.reported_by, reported_at = now()
+		 RETURNING reported_at`,
+		rt.TournamentID, rt.Round, rt.Table, rt.Type, rt.ConcededBy, rt.ReportedBy,
+	).Scan(&rt.ReportedAt)
+}
+
+// DeleteMatchResultType removes the special result of a table, if any. Used
+// when a plain result replaces it.
+func DeleteMatchResultType(ctx context.Context, db DBTX, tournamentID int64, round, table int) error {
+	_, err := db.ExecContext(ctx,
+		`DELETE FROM match_result_types WHERE tournament_id = $1 AND round = $2 AND table_number = $3`,
+		tournamentID, round, table,
+	)
+	return err
+}
+
+// ClearMatchResultTypes deletes every special result of a round. Used when
+// the round is re-paired.
+func ClearMatchResultTypes(ctx context.Context, db DBTX, tournamentID int64, round int) error {
+	_, err := db.ExecContext(ctx,
+		`DELETE FROM match_result_types WHERE tournament_id = $1 AND round = $2`,
+		tournamentID, round,
+	)
+	return err
+}
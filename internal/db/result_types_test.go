@@ -0,0 +1,65 @@
+//go:build integration
+
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/lib/pq"
+)
+
+func TestMatchResultTypes_Lifecycle(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	org := createTestOrganizer(t, database)
+	tourn := &models.Tournament{Name: "Result types", Status: models.TournamentStatusScheduled, OrganizerID: org.ID}
+	if err := CreateTournament(ctx, database, tourn); err != nil {
+		t.Fatalf("CreateTournament: %v", err)
+	}
+
+	id := &models.MatchResultType{TournamentID: tourn.ID, Round: 1, Table: 1, Type: models.ResultIntentionalDraw, ReportedBy: &org.ID}
+	if err := SetMatchResultType(ctx, database, id); err != nil {
+		t.Fatalf("set ID: %v", err)
+	}
+	if id.ReportedAt.IsZero() {
+		t.Error("ReportedAt not filled in")
+	}
+	concession := &models.MatchResultType{TournamentID: tourn.ID, Round: 1, Table: 2, Type: models.ResultConcession, ConcededBy: models.GameWinnerB}
+	if err := SetMatchResultType(ctx, database, concession); err != nil {
+		t.Fatalf("set concession: %v", err)
+	}
+	// A later report replaces the table's type.
+	id.Type, id.ConcededBy = models.ResultConcession, models.GameWinnerA
+	if err := SetMatchResultType(ctx, database, id); err != nil {
+		t.Fatalf("overwrite: %v", err)
+	}
+	bad := &models.MatchResultType{TournamentID: tourn.ID, Round: 1, Table: 3, Type: models.ResultConcession}
+	var pqErr *pq.Error
+	if err := SetMatchResultType(ctx, database, bad); !errors.As(err, &pqErr) || pqErr.Code != "23514" {
+		t.Errorf("concession without a side: got %v, want check violation", err)
+	}
+
+	types, err := ListMatchResultTypes(ctx, database, tourn.ID, 1)
+	if err != nil {
+		t.Fatalf("ListMatchResultTypes: %v", err)
+	}
+	if len(types) != 2 || types[1].ConcededBy != models.GameWinnerA || types[2].ConcededBy != models.GameWinnerB {
+		t.Errorf("types = %+v", types)
+	}
+
+	if err := DeleteMatchResultType(ctx, database, tourn.ID, 1, 1); err != nil {
+		t.Fatalf("DeleteMatchResultType: %v", err)
+	}
+	if types, _ = ListMatchResultTypes(ctx, database, tourn.ID, 1); len(types) != 1 {
+		t.Errorf("types after delete = %+v", types)
+	}
+	if err := ClearMatchResultTypes(ctx, database, tourn.ID, 1); err != nil {
+		t.Fatalf("ClearMatchResultTypes: %v", err)
+	}
+	if types, _ = ListMatchResultTypes(ctx, database, tourn.ID, 1); len(types) != 0 {
+		t.Errorf("types after clear = %+v", types)
+	}
+}
@@ -0,0 +1,86 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+// ListSchedule returns tournament id's schedule in time order.
+func ListSchedule(ctx context.Context, db DBTX, id int64) ([]models.ScheduleItem, error) {
+	rows, err := db.QueryContext(ctx,
+		`SELECT id, tournament_id, starts_at, label FROM schedule_items
+		 WHERE tournament_id = $1 ORDER BY starts_at, id`,
+		id,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []models.ScheduleItem
+	for rows.Next() {
+		var s models.ScheduleItem
+		if err := rows.Scan(&s.ID, &s.TournamentID, &s.StartsAt, &s.Label); err != nil {
+			return nil, err
+		}
+		items = append(items, s)
+	}
+	return items, rows.Err()
+}
+
+// ReplaceSchedule replaces tournament id's schedule with items.
+func ReplaceSchedule(ctx context.Context, tx *sql.Tx, id int64, items []models.ScheduleItem) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM schedule_items WHERE tournament_id = $1`, id); err != nil {
+		return err
+	}
+	for _, s := range items {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO schedule_items (tournament_id, starts_at, label) VALUES ($1, $2, $3)`,
+			id, s.StartsAt, s.Label,
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UpNext is the next item on an unfinished tournament's schedule.
+type UpNext struct {
+	Tournament models.Tournament
+	Item       models.ScheduleItem
+}
+
+// ListUpNext returns the next schedule item after now of up to limit
+// unfinished tournaments, soonest first.
+func ListUpNext(ctx context.Context, db *sql.DB, now time.Time, limit int) ([]UpNext, error) {
+	rows, err := db.QueryContext(ctx,
+		`SELECT `+tournamentCols+`, n.item_id, n.starts_at, n.label
+		 FROM tournaments
+		 JOIN LATERAL (
+		     SELECT id AS item_id, starts_at, label FROM schedule_items
+		     WHERE tournament_id = tournaments.id AND starts_at > $1
+		     ORDER BY starts_at, id LIMIT 1
+		 ) n ON true
+		 WHERE status <> 'finished'
+		 ORDER BY n.starts_at, id LIMIT $2`,
+		now, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []UpNext
+	for rows.Next() {
+		var u UpNext
+		if err := rows.Scan(append(tournamentDest(&u.Tournament), &u.Item.ID, &u.Item.StartsAt, &u.Item.Label)...); err != nil {
+			return nil, err
+		}
+		u.Item.TournamentID = u.Tournament.ID
+		out = append(out, u)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,70 @@
+//go:build integration
+
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+func TestSchedule(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	org := createTestOrganizer(t, database)
+	tourn := &models.Tournament{Name: "Scheduled", Status: models.TournamentStatusInProgress, OrganizerID: org.ID}
+	if err := CreateTournament(ctx, database, tourn); err != nil {
+		t.Fatalf("CreateTournament: %v", err)
+	}
+	if got, _ := GetTournament(ctx, database, tourn.ID); got.TimeZone != models.DefaultTimeZone {
+		t.Errorf("time zone = %q, want the default", got.TimeZone)
+	}
+
+	now := time.Now().Truncate(time.Second)
+	items := []models.ScheduleItem{
+		{StartsAt: now.Add(2 * time.Hour), Label: "Round 3"},
+		{StartsAt: now.Add(-time.Hour), Label: "Round 1"},
+		{StartsAt: now.Add(time.Hour), Label: "Lunch"},
+	}
+	tx, err := database.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ReplaceSchedule(ctx, tx, tourn.ID, items); err != nil {
+		t.Fatalf("ReplaceSchedule: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ListSchedule(ctx, database, tourn.ID)
+	if err != nil || len(got) != 3 || got[0].Label != "Round 1" || got[2].Label != "Round 3" {
+		t.Fatalf("schedule = %+v, %v", got, err)
+	}
+
+	next, err := ListUpNext(ctx, database, now, 50)
+	if err != nil {
+		t.Fatalf("ListUpNext: %v", err)
+	}
+	var found bool
+	for _, u := range next {
+		if u.Tournament.ID == tourn.ID {
+			found = true
+			if u.Item.Label != "Lunch" {
+				t.Errorf("next item = %q, want Lunch", u.Item.Label)
+			}
+		}
+	}
+	if !found {
+		t.Error("tournament missing from up next")
+	}
+
+	UpdateTournamentStatus(ctx, database, tourn.ID, models.TournamentStatusFinished)
+	next, _ = ListUpNext(ctx, database, now, 50)
+	for _, u := range next {
+		if u.Tournament.ID == tourn.ID {
+			t.Error("finished tournament listed as up next")
+		}
+	}
+}
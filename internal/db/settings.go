@@ -0,0 +1,53 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+// Setting keys.
+const (
+	SettingChallongeAPIKey = "challonge_api_key"
+)
+
+// GetSetting returns a site setting's value, or "" if it isn't set.
+func GetSetting(ctx context.Context, db DBTX, key string) (string, error) {
+	var value string
+	err := db.QueryRowContext(ctx, `SELECT value FROM site_settings WHERE key = $1`, key).Scan(&value)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	return value, err
+}
+
+// SetSetting saves a site setting. An empty value clears it.
+func SetSetting(ctx context.Context, db DBTX, key, value string) error {
+	if value == "" {
+		_, err := db.ExecContext(ctx, `DELETE FROM site_settings WHERE key = $1`, key)
+		return err
+	}
+	_, err := db.ExecContext(ctx,
+		`INSERT INTO site_settings (key, value) VALUES ($1, $2)
+		 ON CONFLICT (key) DO UPDATE SET value = EXCLUDED.value, updated_at = now()`, key, value)
+	return err
+}
+
+// GetChallongeURL returns where tournament id was last pushed on
+// Challonge, or "" if it never was.
+func GetChallongeURL(ctx context.Context, db DBTX, id int64) (string, error) {
+	var url sql.NullString
+	if err := db.QueryRowContext(ctx,
+		`SELECT challonge_url FROM tournaments WHERE id = $1`, id,
+	).Scan(&url); err != nil {
+		return "", err
+	}
+	return url.String, nil
+}
+
+// SetChallongeURL records where tournament id was pushed on Challonge.
+func SetChallongeURL(ctx context.Context, db DBTX, id int64, url string) error {
+	_, err := db.ExecContext(ctx,
+		`UPDATE tournaments SET challonge_url = NULLIF($1, '') WHERE id = $2`, url, id)
+	return err
+}
@@ -0,0 +1,33 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+)
+
+// GetShareToken returns the token of tournament id's read-only share link,
+// or "" if it has none.
+func GetShareToken(ctx context.Context, db DBTX, id int64) (string, error) {
+	var token sql.NullString
+	if err := db.QueryRowContext(ctx,
+		`SELECT share_token FROM tournaments WHERE id = $1`, id,
+	).Scan(&token); err != nil {
+		return "", err
+	}
+	return token.String, nil
+}
+
+// SetShareToken replaces tournament id's share link token, revoking the old
+// link. An empty token removes the link. It returns sql.ErrNoRows if there
+// is no such tournament.
+func SetShareToken(ctx context.Context, db DBTX, id int64, token string) error {
+	res, err := db.ExecContext(ctx,
+		`UPDATE tournaments SET share_token = NULLIF($1, '') WHERE id = $2`, token, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
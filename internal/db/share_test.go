@@ -0,0 +1,40 @@
+//go:build integration
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+func TestShareToken(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	org := createTestOrganizer(t, database)
+	tourn := &models.Tournament{Name: "Shared", Status: models.TournamentStatusScheduled, OrganizerID: org.ID}
+	if err := CreateTournament(ctx, database, tourn); err != nil {
+		t.Fatalf("CreateTournament: %v", err)
+	}
+	if token, err := GetShareToken(ctx, database, tourn.ID); err != nil || token != "" {
+		t.Errorf("new tournament token = %q, %v", token, err)
+	}
+	if err := SetShareToken(ctx, database, tourn.ID, "abc"); err != nil {
+		t.Fatalf("SetShareToken: %v", err)
+	}
+	if token, _ := GetShareToken(ctx, database, tourn.ID); token != "abc" {
+		t.Errorf("token = %q, want abc", token)
+	}
+	if err := SetShareToken(ctx, database, tourn.ID, ""); err != nil {
+		t.Fatalf("clear: %v", err)
+	}
+	if token, _ := GetShareToken(ctx, database, tourn.ID); token != "" {
+		t.Errorf("cleared token = %q", token)
+	}
+	if err := SetShareToken(ctx, database, -1, "x"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("missing tournament: err = %v", err)
+	}
+}
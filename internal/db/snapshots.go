@@ -0,0 +1,111 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+const snapshotCols = `id, tournament_id, reason, status, round, octet_length(data::text), created_at`
+
+// SnapshotTournament saves a snapshot of tournament id as it stands in db.
+// round is the tournament's current round, for listing.
+func SnapshotTournament(ctx context.Context, db DBTX, id int64, reason string, round int) (*models.TournamentSnapshot, error) {
+	b, err := backupTournament(ctx, db, id)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(b)
+	if err != nil {
+		return nil, err
+	}
+	s := &models.TournamentSnapshot{TournamentID: id, Reason: reason, Status: b.Tournament.Status, Round: round, Size: len(data)}
+	err = db.QueryRowContext(ctx,
+		`INSERT INTO tournament_snapshots (tournament_id, reason, status, round, data)
+		 VALUES ($1, $2, $3, $4, $5)
+		 RETURNING id, created_at`,
+		id, reason, s.Status, round, data,
+	).Scan(&s.ID, &s.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// ListSnapshots returns a tournament's snapshots, newest first.
+func ListSnapshots(ctx context.Context, db DBTX, tournamentID int64) ([]models.TournamentSnapshot, error) {
+	rows, err := db.QueryContext(ctx,
+		`SELECT `+snapshotCols+` FROM tournament_snapshots
+		 WHERE tournament_id = $1 ORDER BY created_at DESC, id DESC`, tournamentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []models.TournamentSnapshot
+	for rows.Next() {
+		var s models.TournamentSnapshot
+		if err := rows.Scan(&s.ID, &s.TournamentID, &s.Reason, &s.Status, &s.Round, &s.Size, &s.CreatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, s)
+	}
+	return out, rows.Err()
+}
+
+// GetSnapshotBackup returns the backup stored in snapshot id of tournament
+// tournamentID, or sql.ErrNoRows if the tournament has no such snapshot.
+func GetSnapshotBackup(ctx context.Context, db DBTX, tournamentID, id int64) (*TournamentBackup, error) {
+	var data []byte
+	if err := db.QueryRowContext(ctx,
+		`SELECT data FROM tournament_snapshots WHERE id = $1 AND tournament_id = $2`, id, tournamentID,
+	).Scan(&data); err != nil {
+		return nil, err
+	}
+	var b TournamentBackup
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// ListTournamentsToSnapshot returns the started, unfinished tournaments
+// that changed since their latest snapshot.
+func ListTournamentsToSnapshot(ctx context.Context, db *sql.DB) ([]int64, error) {
+	rows, err := db.QueryContext(ctx,
+		`SELECT t.id FROM tournaments t
+		 WHERE t.engine_state IS NOT NULL AND t.status <> $1
+		   AND t.updated_at > COALESCE(
+		       (SELECT max(s.created_at) FROM tournament_snapshots s WHERE s.tournament_id = t.id),
+		       '-infinity')
+		 ORDER BY t.id`, models.TournamentStatusFinished)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		out = append(out, id)
+	}
+	return out, rows.Err()
+}
+
+// PruneSnapshots deletes all but the newest keep snapshots of every
+// tournament and returns how many it deleted.
+func PruneSnapshots(ctx context.Context, db *sql.DB, keep int) (int64, error) {
+	res, err := db.ExecContext(ctx,
+		`DELETE FROM tournament_snapshots WHERE id IN (
+		     SELECT id FROM (
+		         SELECT id, row_number() OVER (PARTITION BY tournament_id ORDER BY created_at DESC, id DESC) AS n
+		         FROM tournament_snapshots
+		     ) ranked WHERE n > $1)`, keep)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
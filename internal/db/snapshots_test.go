@@ -0,0 +1,72 @@
+//go:build integration
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+func TestSnapshots(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	org := createTestOrganizer(t, database)
+	tourn := &models.Tournament{Name: "Snapshots", PointsWin: 3, Status: models.TournamentStatusRegistrationOpen, OrganizerID: org.ID}
+	if err := CreateTournament(ctx, database, tourn); err != nil {
+		t.Fatalf("CreateTournament: %v", err)
+	}
+	if _, err := CreateGuestRegistration(ctx, database, tourn.ID, "Walk-in"); err != nil {
+		t.Fatalf("CreateGuestRegistration: %v", err)
+	}
+
+	// Not started: nothing for the periodic snapshots to do.
+	if ids, _ := ListTournamentsToSnapshot(ctx, database); len(ids) != 0 {
+		t.Errorf("unstarted tournament listed: %v", ids)
+	}
+	tx, _ := database.Begin()
+	if err := UpdateTournamentEngineState(ctx, tx, tourn.ID, models.TournamentStatusInProgress, []byte(`{}`)); err != nil {
+		t.Fatalf("UpdateTournamentEngineState: %v", err)
+	}
+	tx.Commit()
+	if ids, _ := ListTournamentsToSnapshot(ctx, database); len(ids) != 1 || ids[0] != tourn.ID {
+		t.Errorf("started tournament not listed: %v", ids)
+	}
+
+	var taken []int64
+	for _, reason := range []string{models.SnapshotRound, models.SnapshotPeriodic, models.SnapshotRollback} {
+		s, err := SnapshotTournament(ctx, database, tourn.ID, reason, 1)
+		if err != nil {
+			t.Fatalf("SnapshotTournament(%s): %v", reason, err)
+		}
+		if s.Status != models.TournamentStatusInProgress || s.Size == 0 {
+			t.Errorf("snapshot = %+v", s)
+		}
+		taken = append(taken, s.ID)
+	}
+	if ids, _ := ListTournamentsToSnapshot(ctx, database); len(ids) != 0 {
+		t.Errorf("just snapshotted tournament listed: %v", ids)
+	}
+
+	snaps, err := ListSnapshots(ctx, database, tourn.ID)
+	if err != nil || len(snaps) != 3 || snaps[0].ID != taken[2] || snaps[0].Reason != models.SnapshotRollback || snaps[0].Round != 1 {
+		t.Fatalf("ListSnapshots = %+v, %v", snaps, err)
+	}
+	b, err := GetSnapshotBackup(ctx, database, tourn.ID, taken[0])
+	if err != nil || b.Tournament.Name != "Snapshots" || len(b.Registrations) != 1 {
+		t.Errorf("GetSnapshotBackup = %+v, %v", b, err)
+	}
+	if _, err := GetSnapshotBackup(ctx, database, tourn.ID+1, taken[0]); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("snapshot of another tournament: err = %v", err)
+	}
+
+	if n, err := PruneSnapshots(ctx, database, 2); err != nil || n != 1 {
+		t.Errorf("PruneSnapshots = %d, %v; want 1", n, err)
+	}
+	if _, err := GetSnapshotBackup(ctx, database, tourn.ID, taken[0]); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("oldest snapshot survived pruning: err = %v", err)
+	}
+}
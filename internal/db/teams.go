@@ -0,0 +1,119 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/lib/pq"
+)
+
+// members returns a roster ready to bind to registrations.members, which is
+// NOT NULL: a nil roster is stored as an empty one.
+func members(names []string) []string {
+	if names == nil {
+		return []string{}
+	}
+	return names
+}
+
+// SetTeamMembers replaces the roster of registration regID of tournament
+// tournamentID, in seat order. It returns sql.ErrNoRows if the tournament
+// has no such registration.
+func SetTeamMembers(ctx context.Context, db DBTX, tournamentID, regID int64, names []string) error {
+	res, err := db.ExecContext(ctx,
+		`UPDATE registrations SET members = $1 WHERE id = $2 AND tournament_id = $3`,
+		pq.Array(members(names)), regID, tournamentID,
+	)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+const seatResultCols = `tournament_id, round, table_number, seat, winner, reported_by, reported_at`
+
+// scanSeatResults reads rows selected with seatResultCols and closes them.
+func scanSeatResults(rows *sql.Rows) ([]models.SeatResult, error) {
+	defer rows.Close()
+	var out []models.SeatResult
+	for rows.Next() {
+		var s models.SeatResult
+		if err := rows.Scan(&s.TournamentID, &s.Round, &s.Table, &s.Seat, &s.Winner, &s.ReportedBy, &s.ReportedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, s)
+	}
+	return out, rows.Err()
+}
+
+// ListSeatResults returns the seat results of a round of the tournament,
+// grouped by table and in seat order.
+func ListSeatResults(ctx context.Context, db DBTX, tournamentID int64, round int) (map[int][]models.SeatResult, error) {
+	rows, err := db.QueryContext(ctx,
+		`SELECT `+seatResultCols+` FROM seat_results
+		 WHERE tournament_id = $1 AND round = $2
+		 ORDER BY table_number, seat`,
+		tournamentID, round,
+	)
+	if err != nil {
+		return nil, err
+	}
+	results, err := scanSeatResults(rows)
+	if err != nil {
+		return nil, err
+	}
+	out := map[int][]models.SeatResult{}
+	for _, s := range results {
+		out[s.Table] = append(out[s.Table], s)
+	}
+	return out, nil
+}
+
+// ListAllSeatResults returns every seat result of the tournament, by round,
+// table and seat. The individual standings are tallied from these.
+func ListAllSeatResults(ctx context.Context, db DBTX, tournamentID int64) ([]models.SeatResult, error) {
+	rows, err := db.QueryContext(ctx,
+		`SELECT `+seatResultCols+` FROM seat_results
+		 WHERE tournament_id = $1 ORDER BY round, table_number, seat`, tournamentID)
+	if err != nil {
+		return nil, err
+	}
+	return scanSeatResults(rows)
+}
+
+// SetSeatResult records a seat result, replacing any earlier one for the
+// same seat.
+func SetSeatResult(ctx context.Context, db DBTX, s *models.SeatResult) error {
+	return db.QueryRowContext(ctx,
+		`INSERT INTO seat_results (tournament_id, round, table_number, seat, winner, reported_by)
+		 VALUES ($1, $2, $3, $4, $5, $6)
+		 ON CONFLICT (tournament_id, round, table_number, seat) DO UPDATE
+		 SET winner = EXCLUDED.winner, reported_by = EXCLUDED.reported_by, reported_at = now()
+		 RETURNING reported_at`,
+		s.TournamentID, s.Round, s.Table, s.Seat, s.Winner, s.ReportedBy,
+	).Scan(&s.ReportedAt)
+}
+
+// DeleteSeatResult removes the result of one seat, if there is one.
+func DeleteSeatResult(ctx context.Context, db DBTX, tournamentID int64, round, table, seat int) error {
+	_, err := db.ExecContext(ctx,
+		`DELETE FROM seat_results
+		 WHERE tournament_id = $1 AND round = $2 AND table_number = $3 AND seat = $4`,
+		tournamentID, round, table, seat,
+	)
+	return err
+}
+
+// ClearSeatResults deletes every seat result of a round. Used when the
+// round is re-paired.
+func ClearSeatResults(ctx context.Context, db DBTX, tournamentID int64, round int) error {
+	_, err := db.ExecContext(ctx,
+		`DELETE FROM seat_results WHERE tournament_id = $1 AND round = $2`,
+		tournamentID, round,
+	)
+	return err
+}
@@ -0,0 +1,107 @@
+//go:build integration
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+func TestTeams_MembersAndSeatResults(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	org := createTestOrganizer(t, database)
+	tourn := &models.Tournament{Name: "Teams", Status: models.TournamentStatusScheduled, OrganizerID: org.ID, TeamSize: 3}
+	if err := CreateTournament(ctx, database, tourn); err != nil {
+		t.Fatalf("CreateTournament: %v", err)
+	}
+	if got, _ := GetTournament(ctx, database, tourn.ID); got.TeamSize != 3 {
+		t.Errorf("team_size = %d, want 3", got.TeamSize)
+	}
+
+	reg, err := CreateGuestRegistration(ctx, database, tourn.ID, "Team Rocket")
+	if err != nil {
+		t.Fatalf("CreateGuestRegistration: %v", err)
+	}
+	if len(reg.Members) != 0 {
+		t.Errorf("new registration members = %v", reg.Members)
+	}
+	if err := SetTeamMembers(ctx, database, tourn.ID, reg.ID, []string{"Jessie", "James", "Meowth"}); err != nil {
+		t.Fatalf("SetTeamMembers: %v", err)
+	}
+	regs, _ := ListRegistrations(ctx, database, tourn.ID)
+	if len(regs) != 1 || len(regs[0].Members) != 3 || regs[0].Members[2] != "Meowth" {
+		t.Errorf("members = %+v", regs)
+	}
+	if err := SetTeamMembers(ctx, database, -1, reg.ID, nil); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("wrong tournament: err = %v", err)
+	}
+
+	for _, s := range []models.SeatResult{
+		{Round: 1, Table: 1, Seat: 1, Winner: models.GameWinnerA},
+		{Round: 1, Table: 1, Seat: 2, Winner: models.GameWinnerB},
+		{Round: 1, Table: 2, Seat: 1, Winner: models.GameDraw},
+		{Round: 2, Table: 1, Seat: 1, Winner: models.GameWinnerA},
+	} {
+		s.TournamentID = tourn.ID
+		s.ReportedBy = &org.ID
+		if err := SetSeatResult(ctx, database, &s); err != nil {
+			t.Fatalf("SetSeatResult: %v", err)
+		}
+	}
+	again := models.SeatResult{TournamentID: tourn.ID, Round: 1, Table: 1, Seat: 2, Winner: models.GameDraw}
+	if err := SetSeatResult(ctx, database, &again); err != nil {
+		t.Fatalf("SetSeatResult (replace): %v", err)
+	}
+	seats, err := ListSeatResults(ctx, database, tourn.ID, 1)
+	if err != nil {
+		t.Fatalf("ListSeatResults: %v", err)
+	}
+	if len(seats[1]) != 2 || seats[1][1].Winner != models.GameDraw || seats[1][1].ReportedBy != nil || len(seats[2]) != 1 {
+		t.Errorf("round 1 seats = %+v", seats)
+	}
+
+	if err := DeleteSeatResult(ctx, database, tourn.ID, 1, 1, 1); err != nil {
+		t.Fatalf("DeleteSeatResult: %v", err)
+	}
+	if err := ClearSeatResults(ctx, database, tourn.ID, 2); err != nil {
+		t.Fatalf("ClearSeatResults: %v", err)
+	}
+	all, err := ListAllSeatResults(ctx, database, tourn.ID)
+	if err != nil {
+		t.Fatalf("ListAllSeatResults: %v", err)
+	}
+	if len(all) != 2 || all[0].Seat != 2 || all[1].Table != 2 {
+		t.Errorf("seats left = %+v", all)
+	}
+
+	b, err := BackupTournament(ctx, database, tourn.ID)
+	if err != nil {
+		t.Fatalf("BackupTournament: %v", err)
+	}
+	if b.Tournament.TeamSize != 3 || len(b.Registrations[0].Members) != 3 || len(b.SeatResults) != 2 {
+		t.Errorf("backup = %+v", b)
+	}
+	id, err := restoreBackup(t, database, b, org.ID, 0)
+	if err != nil {
+		t.Fatalf("RestoreTournamentBackup: %v", err)
+	}
+	regs, _ = ListRegistrations(ctx, database, id)
+	if all, _ = ListAllSeatResults(ctx, database, id); len(regs) != 1 || len(regs[0].Members) != 3 || len(all) != 2 {
+		t.Errorf("restored members %+v, seats %+v", regs, all)
+	}
+}
+
+func TestTeams_NoSeriesMode(t *testing.T) {
+	database := testDB(t)
+	org := createTestOrganizer(t, database)
+	tourn := &models.Tournament{Name: "Bad", Status: models.TournamentStatusScheduled, OrganizerID: org.ID,
+		TeamSize: 2, BestOf: 3, SeriesMode: true}
+	if err := CreateTournament(context.Background(), database, tourn); err == nil {
+		t.Error("team event in series mode was accepted")
+	}
+}
@@ -42,7 +42,7 @@ t.Fatalf("run migrations: %v", err)
 }
 
 // Clean all tables before each test
-for _, table := range []string{"password_resets", "registrations", "api_keys", "sessions", "tournaments", "users"} {
+for _, table := range []string{"password_resets", "registrations", "players", "tournament_presets", "leagues", "api_keys", "sessions", "tournaments", "users"} {
 if _, err := db.Exec(fmt.Sprintf("DELETE FROM %s", table)); err != nil {
 t.Fatalf("clean table %s: %v", table, err)
 }
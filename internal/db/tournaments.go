@@ -4,26 +4,71 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/dstathis/openswiss/internal/models"
+	"github.com/lib/pq"
 )
 
 func CreateTournament(ctx context.Context, database *sql.DB, t *models.Tournament) error {
+	return CreateTournamentWithFields(ctx, database, t, nil)
+}
+
+// CreateTournamentWithFields is CreateTournament that also gives the new
+// tournament the pairing fields, in the same transaction: a tournament
+// created from a preset.
+func CreateTournamentWithFields(ctx context.Context, database *sql.DB, t *models.Tournament, fields []models.PresetField) error {
+	if t.PairingAlgorithm == "" {
+		t.PairingAlgorithm = models.PairingSwiss
+	}
+	if t.ByePolicy == "" {
+		t.ByePolicy = models.ByeLowest
+	}
+	if t.Round1Pairing == "" {
+		t.Round1Pairing = models.Round1Random
+	}
+	if t.MinPlayers == 0 {
+		t.MinPlayers = models.DefaultMinPlayers
+	}
+	if t.TimeZone == "" {
+		t.TimeZone = models.DefaultTimeZone
+	}
+	if len(t.Tiebreakers) == 0 {
+		t.Tiebreakers = slices.Clone(models.DefaultTiebreakers)
+	}
 	tx, err := database.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback()
+	if err := insertTournament(ctx, tx, t); err != nil {
+		return err
+	}
+	for _, pf := range fields {
+		f := &models.PairingField{TournamentID: t.ID, Label: pf.Label, Public: pf.Public}
+		if err := CreatePairingField(ctx, tx, f); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
 
+// insertTournament is CreateTournament within tx, defaults aside.
+func insertTournament(ctx context.Context, tx *sql.Tx, t *models.Tournament) error {
 	if err := tx.QueryRowContext(ctx,
 		`INSERT INTO tournaments (name, description, scheduled_at, location, max_players, num_rounds,
-		 require_decklist, decklist_public, points_win, points_draw, points_loss, top_cut, status, organizer_id, engine_state)
-		 VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15)
+		 require_decklist, decklist_public, points_win, points_draw, points_loss, top_cut, pairing_algorithm,
+		 bye_policy, round1_pairing, best_of, series_mode, team_size, min_players, status, organizer_id, engine_state,
+		 parent_id, pod_advance, time_zone, preview_pairings, tiebreakers, colors, avoid_club_rounds, auto_rounds, entry_fee,
+		 self_report, report_confirm_minutes, bot_check, blind_final_round)
+		 VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18,$19,$20,$21,$22,$23,$24,$25,$26,$27,$28,$29,$30,$31,$32,$33,$34,$35)
 		 RETURNING id, created_at, updated_at`,
 		t.Name, t.Description, t.ScheduledAt, t.Location, t.MaxPlayers, t.NumRounds,
 		t.RequireDecklist, t.DecklistPublic, t.PointsWin, t.PointsDraw, t.PointsLoss,
-		t.TopCut, t.Status, t.OrganizerID, t.EngineState,
+		t.TopCut, t.PairingAlgorithm, t.ByePolicy, t.Round1Pairing, t.BestOf, t.SeriesMode, t.TeamSize, t.MinPlayers, t.Status, t.OrganizerID, t.EngineState,
+		t.ParentID, t.PodAdvance, t.TimeZone, t.PreviewPairings, pq.Array(t.TiebreakOrder()), t.Colors, t.AvoidClubRounds, t.AutoRounds, t.EntryFee,
+		t.SelfReport, t.ReportConfirmMinutes, t.BotCheck, t.BlindFinalRound,
 	).Scan(&t.ID, &t.CreatedAt, &t.UpdatedAt); err != nil {
 		return err
 	}
@@ -31,47 +76,50 @@ func CreateTournament(ctx context.Context, database *sql.DB, t *models.Tournamen
 	// Creator becomes the first Admin. All permission checks route through
 	// tournament_staff, so a tournament with no admin row would be
 	// unmanageable; doing this in the same tx preserves that invariant.
-	if err := AddTournamentStaff(ctx, tx, &models.TournamentStaff{
+	return AddTournamentStaff(ctx, tx, &models.TournamentStaff{
 		TournamentID: t.ID,
 		UserID:       t.OrganizerID,
 		Tier:         models.TierAdmin,
 		GrantedBy:    &t.OrganizerID,
-	}); err != nil {
-		return err
-	}
+	})
+}
 
-	return tx.Commit()
+// tournamentCols is every tournaments column except engine_state, which only
+// the single-row getters read; list pages don't need the blob.
+const tournamentCols = `id, name, description, scheduled_at, location, max_players, num_rounds,
+	require_decklist, decklist_public, points_win, points_draw, points_loss, top_cut,
+	pairing_algorithm, bye_policy, round1_pairing, best_of, series_mode, team_size, min_players, parent_id, pod_advance, time_zone,
+	preview_pairings, draft_round, tiebreakers, colors, avoid_club_rounds, auto_rounds, entry_fee, self_report, report_confirm_minutes, bot_check, blind_final_round,
+	status, organizer_id, created_at, updated_at`
+
+// tournamentDest returns the scan destinations matching tournamentCols.
+func tournamentDest(t *models.Tournament) []interface{} {
+	return []interface{}{&t.ID, &t.Name, &t.Description, &t.ScheduledAt, &t.Location, &t.MaxPlayers,
+		&t.NumRounds, &t.RequireDecklist, &t.DecklistPublic, &t.PointsWin, &t.PointsDraw,
+		&t.PointsLoss, &t.TopCut, &t.PairingAlgorithm, &t.ByePolicy, &t.Round1Pairing, &t.BestOf, &t.SeriesMode, &t.TeamSize, &t.MinPlayers, &t.ParentID, &t.PodAdvance, &t.TimeZone,
+		&t.PreviewPairings, &t.DraftRound, pq.Array(&t.Tiebreakers), &t.Colors, &t.AvoidClubRounds, &t.AutoRounds, &t.EntryFee, &t.SelfReport, &t.ReportConfirmMinutes, &t.BotCheck, &t.BlindFinalRound,
+		&t.Status, &t.OrganizerID, &t.CreatedAt, &t.UpdatedAt}
 }
 
 func GetTournament(ctx context.Context, db *sql.DB, id int64) (*models.Tournament, error) {
 	t := &models.Tournament{}
 	err := db.QueryRowContext(ctx,
-		`SELECT id, name, description, scheduled_at, location, max_players, num_rounds,
-		 require_decklist, decklist_public, points_win, points_draw, points_loss, top_cut,
-		 status, organizer_id, engine_state, created_at, updated_at
-		 FROM tournaments WHERE id = $1`,
+		`SELECT `+tournamentCols+`, engine_state FROM tournaments WHERE id = $1`,
 		id,
-	).Scan(&t.ID, &t.Name, &t.Description, &t.ScheduledAt, &t.Location, &t.MaxPlayers,
-		&t.NumRounds, &t.RequireDecklist, &t.DecklistPublic, &t.PointsWin, &t.PointsDraw,
-		&t.PointsLoss, &t.TopCut, &t.Status, &t.OrganizerID, &t.EngineState, &t.CreatedAt, &t.UpdatedAt)
+	).Scan(append(tournamentDest(t), &t.EngineState)...)
 	if err != nil {
 		return nil, err
 	}
 	return t, nil
 }
 
-// GetTournamentForUpdate locks the row for update within a transaction.
+// GetTournamentForUpdate locks the row for update within a transaction,
+// giving up with ErrBusy after LockTimeout.
 func GetTournamentForUpdate(ctx context.Context, tx *sql.Tx, id int64) (*models.Tournament, error) {
 	t := &models.Tournament{}
-	err := tx.QueryRowContext(ctx,
-		`SELECT id, name, description, scheduled_at, location, max_players, num_rounds,
-		 require_decklist, decklist_public, points_win, points_draw, points_loss, top_cut,
-		 status, organizer_id, engine_state, created_at, updated_at
-		 FROM tournaments WHERE id = $1 FOR UPDATE`,
-		id,
-	).Scan(&t.ID, &t.Name, &t.Description, &t.ScheduledAt, &t.Location, &t.MaxPlayers,
-		&t.NumRounds, &t.RequireDecklist, &t.DecklistPublic, &t.PointsWin, &t.PointsDraw,
-		&t.PointsLoss, &t.TopCut, &t.Status, &t.OrganizerID, &t.EngineState, &t.CreatedAt, &t.UpdatedAt)
+	err := lockTournamentRow(ctx, tx,
+		`SELECT `+tournamentCols+`, engine_state FROM tournaments WHERE id = $1 FOR UPDATE`,
+		id, append(tournamentDest(t), &t.EngineState)...)
 	if err != nil {
 		return nil, err
 	}
@@ -98,11 +146,52 @@ func UpdateTournament(ctx context.Context, db *sql.DB, t *models.Tournament) err
 	_, err := db.ExecContext(ctx,
 		`UPDATE tournaments SET name=$1, description=$2, scheduled_at=$3, location=$4,
 		 max_players=$5, num_rounds=$6, require_decklist=$7, decklist_public=$8,
-		 points_win=$9, points_draw=$10, points_loss=$11, top_cut=$12, updated_at=now()
-		 WHERE id=$13`,
+		 points_win=$9, points_draw=$10, points_loss=$11, top_cut=$12, pairing_algorithm=$13,
+		 best_of=$14, series_mode=$15, min_players=$16, bye_policy=$17, round1_pairing=$18, team_size=$19,
+		 pod_advance=$20, time_zone=$21, preview_pairings=$22, tiebreakers=$23, colors=$24, avoid_club_rounds=$25, auto_rounds=$26, entry_fee=$27,
+		 self_report=$28, report_confirm_minutes=$29, bot_check=$30, blind_final_round=$31, updated_at=now()
+		 WHERE id=$32`,
 		t.Name, t.Description, t.ScheduledAt, t.Location, t.MaxPlayers, t.NumRounds,
 		t.RequireDecklist, t.DecklistPublic, t.PointsWin, t.PointsDraw, t.PointsLoss,
-		t.TopCut, t.ID,
+		t.TopCut, t.PairingAlgorithm, t.BestOf, t.SeriesMode, t.MinPlayers, t.ByePolicy, t.Round1Pairing, t.TeamSize, t.PodAdvance, t.TimeZone, t.PreviewPairings, pq.Array(t.TiebreakOrder()), t.Colors, t.AvoidClubRounds, t.AutoRounds, t.EntryFee,
+		t.SelfReport, t.ReportConfirmMinutes, t.BotCheck, t.BlindFinalRound, t.ID,
+	)
+	return err
+}
+
+// SetDraftRound records round as the Swiss round whose pairings wait to be
+// published, or with 0 that none do.
+func SetDraftRound(ctx context.Context, db DBTX, id int64, round int) error {
+	_, err := db.ExecContext(ctx,
+		`UPDATE tournaments SET draft_round = $1, updated_at = now() WHERE id = $2`,
+		round, id,
+	)
+	return err
+}
+
+// ResetTournament puts a tournament back to registration_open: the engine
+// state, series games, seat results, result types, player result reports,
+// pairing field values and feature matches are deleted, the tournament leaves the archive and registrations lose
+// their engine player IDs. Registrations themselves, drops and team rosters included, are kept.
+func ResetTournament(ctx context.Context, tx *sql.Tx, id int64) error {
+	for _, q := range []string{
+		`DELETE FROM match_games WHERE tournament_id = $1`,
+		`DELETE FROM seat_results WHERE tournament_id = $1`,
+		`DELETE FROM match_result_types WHERE tournament_id = $1`,
+		`DELETE FROM result_reports WHERE tournament_id = $1`,
+		`DELETE FROM pairing_field_values v USING pairing_fields f
+		 WHERE v.field_id = f.id AND f.tournament_id = $1`,
+		`DELETE FROM feature_matches WHERE tournament_id = $1`,
+		`DELETE FROM archives WHERE tournament_id = $1`,
+		`UPDATE registrations SET engine_player_id = NULL WHERE tournament_id = $1`,
+	} {
+		if _, err := tx.ExecContext(ctx, q, id); err != nil {
+			return err
+		}
+	}
+	_, err := tx.ExecContext(ctx,
+		`UPDATE tournaments SET engine_state = NULL, status = $1, updated_at = now() WHERE id = $2`,
+		models.TournamentStatusRegistrationOpen, id,
 	)
 	return err
 }
@@ -119,17 +208,13 @@ func ListTournaments(ctx context.Context, db *sql.DB, status string, page, perPa
 
 	if status != "" {
 		rows, err = db.QueryContext(ctx,
-			`SELECT id, name, description, scheduled_at, location, max_players, num_rounds,
-			 require_decklist, decklist_public, points_win, points_draw, points_loss, top_cut,
-			 status, organizer_id, created_at, updated_at
+			`SELECT `+tournamentCols+`
 			 FROM tournaments WHERE status = $1 ORDER BY scheduled_at DESC NULLS LAST, id DESC LIMIT $2 OFFSET $3`,
 			status, perPage, offset,
 		)
 	} else {
 		rows, err = db.QueryContext(ctx,
-			`SELECT id, name, description, scheduled_at, location, max_players, num_rounds,
-			 require_decklist, decklist_public, points_win, points_draw, points_loss, top_cut,
-			 status, organizer_id, created_at, updated_at
+			`SELECT `+tournamentCols+`
 			 FROM tournaments ORDER BY scheduled_at DESC NULLS LAST, id DESC LIMIT $1 OFFSET $2`,
 			perPage, offset,
 		)
@@ -142,10 +227,7 @@ func ListTournaments(ctx context.Context, db *sql.DB, status string, page, perPa
 	var tournaments []models.Tournament
 	for rows.Next() {
 		var t models.Tournament
-		if err := rows.Scan(&t.ID, &t.Name, &t.Description, &t.ScheduledAt, &t.Location,
-			&t.MaxPlayers, &t.NumRounds, &t.RequireDecklist, &t.DecklistPublic,
-			&t.PointsWin, &t.PointsDraw, &t.PointsLoss, &t.TopCut,
-			&t.Status, &t.OrganizerID, &t.CreatedAt, &t.UpdatedAt); err != nil {
+		if err := rows.Scan(tournamentDest(&t)...); err != nil {
 			return nil, err
 		}
 		tournaments = append(tournaments, t)
@@ -155,9 +237,7 @@ func ListTournaments(ctx context.Context, db *sql.DB, status string, page, perPa
 
 func ListUpcomingTournaments(ctx context.Context, db *sql.DB, limit int) ([]models.Tournament, error) {
 	rows, err := db.QueryContext(ctx,
-		`SELECT id, name, description, scheduled_at, location, max_players, num_rounds,
-		 require_decklist, decklist_public, points_win, points_draw, points_loss, top_cut,
-		 status, organizer_id, created_at, updated_at
+		`SELECT `+tournamentCols+`
 		 FROM tournaments WHERE status IN ('scheduled','registration_open')
 		 ORDER BY scheduled_at ASC NULLS LAST LIMIT $1`,
 		limit,
@@ -170,10 +250,7 @@ func ListUpcomingTournaments(ctx context.Context, db *sql.DB, limit int) ([]mode
 	var tournaments []models.Tournament
 	for rows.Next() {
 		var t models.Tournament
-		if err := rows.Scan(&t.ID, &t.Name, &t.Description, &t.ScheduledAt, &t.Location,
-			&t.MaxPlayers, &t.NumRounds, &t.RequireDecklist, &t.DecklistPublic,
-			&t.PointsWin, &t.PointsDraw, &t.PointsLoss, &t.TopCut,
-			&t.Status, &t.OrganizerID, &t.CreatedAt, &t.UpdatedAt); err != nil {
+		if err := rows.Scan(tournamentDest(&t)...); err != nil {
 			return nil, err
 		}
 		tournaments = append(tournaments, t)
@@ -188,13 +265,13 @@ func ListUpcomingTournaments(ctx context.Context, db *sql.DB, limit int) ([]mode
 // display_name is denormalized onto the row so a single unique index
 // (tournament_id, lower(display_name)) prevents collisions across both kinds.
 
-const regCols = `id, tournament_id, user_id, guest_name, display_name, decklist, status, engine_player_id, created_at`
+const regCols = `id, tournament_id, user_id, guest_name, display_name, decklist, status, engine_player_id, rating, archetype, club, members, tiebreak_seed, advanced_from, pod_seed, player_id, payment_status, payment_amount, payment_method, contact_consent, created_at`
 
 func scanRegistration(row interface {
 	Scan(dest ...interface{}) error
 }) (*models.Registration, error) {
 	r := &models.Registration{}
-	err := row.Scan(&r.ID, &r.TournamentID, &r.UserID, &r.GuestName, &r.DisplayName, &r.Decklist, &r.Status, &r.EnginePlayerID, &r.CreatedAt)
+	err := row.Scan(&r.ID, &r.TournamentID, &r.UserID, &r.GuestName, &r.DisplayName, &r.Decklist, &r.Status, &r.EnginePlayerID, &r.Rating, &r.Archetype, &r.Club, pq.Array(&r.Members), &r.TiebreakSeed, &r.AdvancedFrom, &r.PodSeed, &r.PlayerID, &r.PaymentStatus, &r.PaymentAmount, &r.PaymentMethod, &r.ContactConsent, &r.CreatedAt)
 	if err != nil {
 		return nil, err
 	}
@@ -237,25 +314,26 @@ func nextFreeName(base string, taken map[string]bool) string {
 }
 
 // lockTournament acquires SELECT … FOR UPDATE on the tournament row to
-// serialize registration writes for that tournament.
+// serialize registration writes for that tournament, giving up with ErrBusy
+// after LockTimeout.
 func lockTournament(ctx context.Context, tx *sql.Tx, tournamentID int64) error {
 	var id int64
-	if err := tx.QueryRowContext(ctx,
-		`SELECT id FROM tournaments WHERE id = $1 FOR UPDATE`, tournamentID,
-	).Scan(&id); err != nil {
+	if err := lockTournamentRow(ctx, tx,
+		`SELECT id FROM tournaments WHERE id = $1 FOR UPDATE`, tournamentID, &id); err != nil {
 		return fmt.Errorf("lock tournament: %w", err)
 	}
 	return nil
 }
 
-// CreateGuestRegistration inserts a guest (no user account) into a tournament.
+// CreateGuestRegistration inserts a guest (no user account) into a tournament,
+// with the name checked and tidied by models.ValidatePlayerName.
 // If the requested name collides with an existing player, the guest's name is
 // suffixed with " (2)", " (3)", … until unique. Returns the stored registration
 // (its DisplayName/GuestName may differ from the input name).
 func CreateGuestRegistration(ctx context.Context, database *sql.DB, tournamentID int64, name string) (*models.Registration, error) {
-	name = strings.TrimSpace(name)
-	if name == "" {
-		return nil, fmt.Errorf("name is required")
+	name, err := models.ValidatePlayerName(name)
+	if err != nil {
+		return nil, err
 	}
 	tx, err := database.BeginTx(ctx, nil)
 	if err != nil {
@@ -266,6 +344,29 @@ func CreateGuestRegistration(ctx context.Context, database *sql.DB, tournamentID
 	if err := lockTournament(ctx, tx, tournamentID); err != nil {
 		return nil, err
 	}
+	r, err := insertGuestRegistration(ctx, tx, tournamentID, name)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// AddGuestRegistration is CreateGuestRegistration within tx, which must
+// already hold the tournament's lock (as WithTournamentEngine's does).
+func AddGuestRegistration(ctx context.Context, tx *sql.Tx, tournamentID int64, name string) (*models.Registration, error) {
+	name, err := models.ValidatePlayerName(name)
+	if err != nil {
+		return nil, err
+	}
+	return insertGuestRegistration(ctx, tx, tournamentID, name)
+}
+
+// insertGuestRegistration is CreateGuestRegistration within tx, which must
+// hold the tournament's lock.
+func insertGuestRegistration(ctx context.Context, tx *sql.Tx, tournamentID int64, name string) (*models.Registration, error) {
 	taken, err := existingDisplayNames(ctx, tx, tournamentID)
 	if err != nil {
 		return nil, err
@@ -278,14 +379,7 @@ func CreateGuestRegistration(ctx context.Context, database *sql.DB, tournamentID
 		 RETURNING `+regCols,
 		tournamentID, finalName,
 	)
-	r, err := scanRegistration(row)
-	if err != nil {
-		return nil, err
-	}
-	if err := tx.Commit(); err != nil {
-		return nil, err
-	}
-	return r, nil
+	return scanRegistration(row)
 }
 
 // createUserRegistration inserts a registration for a real user. If a guest
@@ -301,9 +395,21 @@ func createUserRegistration(ctx context.Context, database *sql.DB, tournamentID,
 	if err := lockTournament(ctx, tx, tournamentID); err != nil {
 		return nil, err
 	}
+	r, err := insertUserRegistration(ctx, tx, tournamentID, userID, displayName, status)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
 
+// insertUserRegistration is createUserRegistration within tx, which must
+// hold the tournament's lock.
+func insertUserRegistration(ctx context.Context, tx *sql.Tx, tournamentID, userID int64, displayName, status string) (*models.Registration, error) {
 	var collidingID int64
-	err = tx.QueryRowContext(ctx,
+	err := tx.QueryRowContext(ctx,
 		`SELECT id FROM registrations
 		 WHERE tournament_id = $1 AND lower(display_name) = lower($2)`,
 		tournamentID, displayName,
@@ -312,8 +418,10 @@ func createUserRegistration(ctx context.Context, database *sql.DB, tournamentID,
 		return nil, err
 	}
 	if err == nil {
-		// Collision must be a guest — users.display_name is globally unique.
-		// Keep the original name in the taken set so nextFreeName skips it and
+		// Collision is a guest, or a registration staff renamed: two
+		// users can't collide, users.display_name is globally unique. Only
+		// a guest has a guest_name to follow the new name. Keep the
+		// original name in the taken set so nextFreeName skips it and
 		// returns the smallest "Name (n)" variant that's actually free.
 		taken, err := existingDisplayNames(ctx, tx, tournamentID)
 		if err != nil {
@@ -321,7 +429,9 @@ func createUserRegistration(ctx context.Context, database *sql.DB, tournamentID,
 		}
 		bumped := nextFreeName(displayName, taken)
 		if _, err := tx.ExecContext(ctx,
-			`UPDATE registrations SET display_name = $1, guest_name = $1 WHERE id = $2`,
+			`UPDATE registrations
+			 SET display_name = $1, guest_name = CASE WHEN user_id IS NULL THEN $1 END
+			 WHERE id = $2`,
 			bumped, collidingID,
 		); err != nil {
 			return nil, err
@@ -334,14 +444,7 @@ func createUserRegistration(ctx context.Context, database *sql.DB, tournamentID,
 		 RETURNING `+regCols,
 		tournamentID, userID, displayName, status,
 	)
-	r, err := scanRegistration(row)
-	if err != nil {
-		return nil, err
-	}
-	if err := tx.Commit(); err != nil {
-		return nil, err
-	}
-	return r, nil
+	return scanRegistration(row)
 }
 
 func CreateRegistration(ctx context.Context, database *sql.DB, tournamentID, userID int64, displayName string) (*models.Registration, error) {
@@ -361,7 +464,7 @@ func GetRegistration(ctx context.Context, database *sql.DB, tournamentID, userID
 	return scanRegistration(row)
 }
 
-func GetRegistrationByID(ctx context.Context, database *sql.DB, regID int64) (*models.Registration, error) {
+func GetRegistrationByID(ctx context.Context, database DBTX, regID int64) (*models.Registration, error) {
 	row := database.QueryRowContext(ctx,
 		`SELECT `+regCols+` FROM registrations WHERE id = $1`,
 		regID,
@@ -369,7 +472,7 @@ func GetRegistrationByID(ctx context.Context, database *sql.DB, regID int64) (*m
 	return scanRegistration(row)
 }
 
-func ListRegistrations(ctx context.Context, database *sql.DB, tournamentID int64) ([]models.Registration, error) {
+func ListRegistrations(ctx context.Context, database DBTX, tournamentID int64) ([]models.Registration, error) {
 	rows, err := database.QueryContext(ctx,
 		`SELECT `+regCols+` FROM registrations
 		 WHERE tournament_id = $1 ORDER BY created_at`,
@@ -392,10 +495,11 @@ func ListRegistrations(ctx context.Context, database *sql.DB, tournamentID int64
 }
 
 // UpdateRegistrationDecklist updates the decklist for a real user's registration
-// and marks it confirmed (the player-self-service path).
+// and marks it confirmed, unless it is waitlisted (the player-self-service
+// path).
 func UpdateRegistrationDecklist(ctx context.Context, database *sql.DB, tournamentID, userID int64, decklist []byte) error {
 	_, err := database.ExecContext(ctx,
-		`UPDATE registrations SET decklist = $1, status = 'confirmed'
+		`UPDATE registrations SET decklist = $1, status = CASE status WHEN 'waitlisted' THEN status ELSE 'confirmed' END
 		 WHERE tournament_id = $2 AND user_id = $3`,
 		decklist, tournamentID, userID,
 	)
@@ -403,22 +507,42 @@ func UpdateRegistrationDecklist(ctx context.Context, database *sql.DB, tournamen
 }
 
 // UpdateRegistrationDecklistByID updates a registration's decklist by its id
-// (used by organizer-edit paths and works for guests too).
+// and confirms it as UpdateRegistrationDecklist does (used by organizer-edit
+// paths and works for guests too).
 func UpdateRegistrationDecklistByID(ctx context.Context, database *sql.DB, regID int64, decklist []byte) error {
 	_, err := database.ExecContext(ctx,
-		`UPDATE registrations SET decklist = $1, status = 'confirmed' WHERE id = $2`,
+		`UPDATE registrations SET decklist = $1, status = CASE status WHEN 'waitlisted' THEN status ELSE 'confirmed' END WHERE id = $2`,
 		decklist, regID,
 	)
 	return err
 }
 
+// MaxArchetypeLength caps an archetype name, in characters.
+const MaxArchetypeLength = 60
+
+// SetArchetype sets the deck archetype of registration regID; an empty one
+// clears it.
+func SetArchetype(ctx context.Context, db DBTX, regID int64, archetype string) error {
+	var v *string
+	if archetype = strings.TrimSpace(archetype); archetype != "" {
+		v = &archetype
+	}
+	_, err := db.ExecContext(ctx, `UPDATE registrations SET archetype = $1 WHERE id = $2`, v, regID)
+	return err
+}
+
 // UpdateRegistrationEnginePlayerID sets the engine_player_id on a registration
-// by registration id. Accepts a *sql.DB or *sql.Tx.
+// by registration id. Accepts a *sql.DB or *sql.Tx. This is the moment a
+// player enters the engine, so it also rolls the registration's tiebreak
+// seed if it doesn't have one yet.
 func UpdateRegistrationEnginePlayerID(ctx context.Context, dbtx interface {
 	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
 }, regID int64, enginePlayerID int) error {
 	_, err := dbtx.ExecContext(ctx,
-		`UPDATE registrations SET engine_player_id = $1 WHERE id = $2`,
+		`UPDATE registrations
+		 SET engine_player_id = $1,
+		     tiebreak_seed = COALESCE(tiebreak_seed, floor(random() * 9007199254740991)::bigint)
+		 WHERE id = $2`,
 		enginePlayerID, regID,
 	)
 	return err
@@ -440,6 +564,33 @@ func UpdateRegistrationStatusByID(ctx context.Context, database *sql.DB, regID i
 	return err
 }
 
+// ConfirmPendingRegistrations confirms every pending registration of the
+// tournament at once, decklist or not, and returns how many it confirmed.
+func ConfirmPendingRegistrations(ctx context.Context, database *sql.DB, tournamentID int64) (int64, error) {
+	res, err := database.ExecContext(ctx,
+		`UPDATE registrations SET status = 'confirmed'
+		 WHERE tournament_id = $1 AND status = 'pending'`,
+		tournamentID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// DeletePendingRegistrations removes every pending registration of the
+// tournament at once and returns how many it removed.
+func DeletePendingRegistrations(ctx context.Context, database *sql.DB, tournamentID int64) (int64, error) {
+	res, err := database.ExecContext(ctx,
+		`DELETE FROM registrations WHERE tournament_id = $1 AND status = 'pending'`,
+		tournamentID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
 func DeleteRegistration(ctx context.Context, database *sql.DB, tournamentID, userID int64) error {
 	_, err := database.ExecContext(ctx,
 		`DELETE FROM registrations WHERE tournament_id = $1 AND user_id = $2`,
@@ -455,10 +606,17 @@ func DeleteRegistrationByID(ctx context.Context, database *sql.DB, regID int64)
 	return err
 }
 
+// CountRegistrations returns how many registrations count toward the
+// tournament's Max Players: all but dropped and waitlisted ones.
 func CountRegistrations(ctx context.Context, database *sql.DB, tournamentID int64) (int, error) {
+	return countRegistrations(ctx, database, tournamentID)
+}
+
+func countRegistrations(ctx context.Context, db DBTX, tournamentID int64) (int, error) {
 	var count int
-	err := database.QueryRowContext(ctx,
-		`SELECT COUNT(*) FROM registrations WHERE tournament_id = $1 AND status != 'dropped'`,
+	err := db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM registrations
+		 WHERE tournament_id = $1 AND status NOT IN ('dropped', 'waitlisted')`,
 		tournamentID,
 	).Scan(&count)
 	return count, err
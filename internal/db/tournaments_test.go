@@ -319,6 +319,52 @@ t.Errorf("status = %q, want pending", reg.Status)
 }
 }
 
+func TestConfirmAndDeletePendingRegistrations(t *testing.T) {
+database := testDB(t)
+ctx := context.Background()
+
+org, _ := CreateUser(ctx, database, "org-bulk@example.com", "OrgBulk", "hash")
+tourn := &models.Tournament{
+Name:        "Bulk Pending",
+PointsWin:   3,
+PointsDraw:  1,
+PointsLoss:  0,
+Status:      models.TournamentStatusRegistrationOpen,
+OrganizerID: org.ID,
+}
+CreateTournament(ctx, database, tourn)
+
+for i, name := range []string{"BulkA", "BulkB", "BulkC"} {
+u, _ := CreateUser(ctx, database, fmt.Sprintf("bulk%d@example.com", i), name, "hash")
+if _, err := CreatePendingRegistration(ctx, database, tourn.ID, u.ID, u.DisplayName); err != nil {
+t.Fatalf("CreatePendingRegistration: %v", err)
+}
+}
+guest, _ := CreateGuestRegistration(ctx, database, tourn.ID, "BulkGuest")
+
+n, err := DeletePendingRegistrations(ctx, database, tourn.ID)
+if err != nil || n != 3 {
+t.Fatalf("DeletePendingRegistrations = %d, %v; want 3", n, err)
+}
+if _, err := GetRegistrationByID(ctx, database, guest.ID); err != nil {
+t.Errorf("confirmed registration deleted: %v", err)
+}
+
+u, _ := CreateUser(ctx, database, "bulk-late@example.com", "BulkLate", "hash")
+late, _ := CreatePendingRegistration(ctx, database, tourn.ID, u.ID, u.DisplayName)
+n, err = ConfirmPendingRegistrations(ctx, database, tourn.ID)
+if err != nil || n != 1 {
+t.Fatalf("ConfirmPendingRegistrations = %d, %v; want 1", n, err)
+}
+got, _ := GetRegistrationByID(ctx, database, late.ID)
+if got.Status != models.RegistrationStatusConfirmed {
+t.Errorf("status = %q, want confirmed", got.Status)
+}
+if n, _ := ConfirmPendingRegistrations(ctx, database, tourn.ID); n != 0 {
+t.Errorf("second confirm = %d, want 0", n)
+}
+}
+
 func TestDeleteRegistration(t *testing.T) {
 database := testDB(t)
 ctx := context.Background()
@@ -14,21 +14,39 @@ func CreateUser(ctx context.Context, db *sql.DB, email, displayName, passwordHas
 	u := &models.User{}
 	err := db.QueryRowContext(ctx,
 		`INSERT INTO users (email, display_name, password_hash) VALUES ($1, $2, $3)
-		 RETURNING id, email, display_name, password_hash, roles, email_verified_at, failed_login_attempts, locked_until, created_at, updated_at`,
+		 RETURNING id, email, display_name, password_hash, roles, email_verified_at, failed_login_attempts, locked_until, notify_by, deletion_requested_at, created_at, updated_at`,
 		email, displayName, passwordHash,
-	).Scan(&u.ID, &u.Email, &u.DisplayName, &u.PasswordHash, pq.Array(&u.Roles), &u.EmailVerifiedAt, &u.FailedLoginAttempts, &u.LockedUntil, &u.CreatedAt, &u.UpdatedAt)
+	).Scan(&u.ID, &u.Email, &u.DisplayName, &u.PasswordHash, pq.Array(&u.Roles), &u.EmailVerifiedAt, &u.FailedLoginAttempts, &u.LockedUntil, &u.NotifyBy, &u.DeletionRequestedAt, &u.CreatedAt, &u.UpdatedAt)
 	if err != nil {
 		return nil, err
 	}
 	return u, nil
 }
 
+// EnsureAdminUser makes sure the user with this email exists and holds the
+// organizer and admin roles. A missing user is created with the given
+// password hash, already verified; an existing user keeps their password, so
+// a password changed through the web UI survives restarts. Reports whether
+// the user was created.
+func EnsureAdminUser(ctx context.Context, db *sql.DB, email, displayName, passwordHash string) (created bool, err error) {
+	err = db.QueryRowContext(ctx,
+		`INSERT INTO users (email, display_name, password_hash, roles, email_verified_at)
+		 VALUES ($1, $2, $3, '{player,organizer,admin}', now())
+		 ON CONFLICT (email) DO UPDATE
+		   SET roles = ARRAY(SELECT DISTINCT unnest(users.roles || '{organizer,admin}'::text[])),
+		       updated_at = now()
+		 RETURNING xmax = 0`,
+		email, displayName, passwordHash,
+	).Scan(&created)
+	return created, err
+}
+
 func GetUserByEmail(ctx context.Context, db *sql.DB, email string) (*models.User, error) {
 	u := &models.User{}
 	err := db.QueryRowContext(ctx,
-		`SELECT id, email, display_name, password_hash, roles, email_verified_at, failed_login_attempts, locked_until, created_at, updated_at FROM users WHERE email = $1`,
+		`SELECT id, email, display_name, password_hash, roles, email_verified_at, failed_login_attempts, locked_until, notify_by, deletion_requested_at, created_at, updated_at FROM users WHERE email = $1`,
 		email,
-	).Scan(&u.ID, &u.Email, &u.DisplayName, &u.PasswordHash, pq.Array(&u.Roles), &u.EmailVerifiedAt, &u.FailedLoginAttempts, &u.LockedUntil, &u.CreatedAt, &u.UpdatedAt)
+	).Scan(&u.ID, &u.Email, &u.DisplayName, &u.PasswordHash, pq.Array(&u.Roles), &u.EmailVerifiedAt, &u.FailedLoginAttempts, &u.LockedUntil, &u.NotifyBy, &u.DeletionRequestedAt, &u.CreatedAt, &u.UpdatedAt)
 	if err != nil {
 		return nil, err
 	}
@@ -79,9 +97,9 @@ func SearchUsersByDisplayName(ctx context.Context, db *sql.DB, query string, lim
 func GetUserByDisplayName(ctx context.Context, db *sql.DB, displayName string) (*models.User, error) {
 	u := &models.User{}
 	err := db.QueryRowContext(ctx,
-		`SELECT id, email, display_name, password_hash, roles, email_verified_at, failed_login_attempts, locked_until, created_at, updated_at FROM users WHERE lower(display_name) = lower($1)`,
+		`SELECT id, email, display_name, password_hash, roles, email_verified_at, failed_login_attempts, locked_until, notify_by, deletion_requested_at, created_at, updated_at FROM users WHERE lower(display_name) = lower($1)`,
 		displayName,
-	).Scan(&u.ID, &u.Email, &u.DisplayName, &u.PasswordHash, pq.Array(&u.Roles), &u.EmailVerifiedAt, &u.FailedLoginAttempts, &u.LockedUntil, &u.CreatedAt, &u.UpdatedAt)
+	).Scan(&u.ID, &u.Email, &u.DisplayName, &u.PasswordHash, pq.Array(&u.Roles), &u.EmailVerifiedAt, &u.FailedLoginAttempts, &u.LockedUntil, &u.NotifyBy, &u.DeletionRequestedAt, &u.CreatedAt, &u.UpdatedAt)
 	if err != nil {
 		return nil, err
 	}
@@ -91,15 +109,81 @@ func GetUserByDisplayName(ctx context.Context, db *sql.DB, displayName string) (
 func GetUserByID(ctx context.Context, db *sql.DB, id int64) (*models.User, error) {
 	u := &models.User{}
 	err := db.QueryRowContext(ctx,
-		`SELECT id, email, display_name, password_hash, roles, email_verified_at, failed_login_attempts, locked_until, created_at, updated_at FROM users WHERE id = $1`,
+		`SELECT id, email, display_name, password_hash, roles, email_verified_at, failed_login_attempts, locked_until, notify_by, deletion_requested_at, created_at, updated_at FROM users WHERE id = $1`,
 		id,
-	).Scan(&u.ID, &u.Email, &u.DisplayName, &u.PasswordHash, pq.Array(&u.Roles), &u.EmailVerifiedAt, &u.FailedLoginAttempts, &u.LockedUntil, &u.CreatedAt, &u.UpdatedAt)
+	).Scan(&u.ID, &u.Email, &u.DisplayName, &u.PasswordHash, pq.Array(&u.Roles), &u.EmailVerifiedAt, &u.FailedLoginAttempts, &u.LockedUntil, &u.NotifyBy, &u.DeletionRequestedAt, &u.CreatedAt, &u.UpdatedAt)
 	if err != nil {
 		return nil, err
 	}
 	return u, nil
 }
 
+// SetUserNotifyBy sets the channel the user is notified through, one of
+// the models.Notify* channels.
+func SetUserNotifyBy(ctx context.Context, db *sql.DB, userID int64, channel string) error {
+	_, err := db.ExecContext(ctx,
+		`UPDATE users SET notify_by = $1, updated_at = now() WHERE id = $2`,
+		channel, userID,
+	)
+	return err
+}
+
+// SetDeletionRequested records that the user asked for their personal
+// data to be deleted, or with requested false withdraws the request. A
+// repeated request keeps the time of the first.
+func SetDeletionRequested(ctx context.Context, db *sql.DB, userID int64, requested bool) error {
+	_, err := db.ExecContext(ctx,
+		`UPDATE users SET deletion_requested_at = CASE WHEN $1 THEN COALESCE(deletion_requested_at, now()) END,
+		 updated_at = now() WHERE id = $2`,
+		requested, userID,
+	)
+	return err
+}
+
+// ListDeletionRequests returns the users waiting for their personal data
+// to be deleted, oldest request first.
+func ListDeletionRequests(ctx context.Context, db *sql.DB) ([]models.User, error) {
+	rows, err := db.QueryContext(ctx,
+		`SELECT id, email, display_name, password_hash, roles, email_verified_at, failed_login_attempts, locked_until, notify_by, deletion_requested_at, created_at, updated_at
+		 FROM users WHERE deletion_requested_at IS NOT NULL ORDER BY deletion_requested_at, id`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []models.User
+	for rows.Next() {
+		var u models.User
+		if err := rows.Scan(&u.ID, &u.Email, &u.DisplayName, &u.PasswordHash, pq.Array(&u.Roles), &u.EmailVerifiedAt, &u.FailedLoginAttempts, &u.LockedUntil, &u.NotifyBy, &u.DeletionRequestedAt, &u.CreatedAt, &u.UpdatedAt); err != nil {
+			return nil, err
+		}
+		users = append(users, u)
+	}
+	return users, rows.Err()
+}
+
+// DeleteUser deletes the user's account along with everything that
+// cascades from it: sessions, API keys, staff grants, their avatar and
+// any registrations still linked to it. Tournaments they created are
+// handed to heirID as creator-of-record. It returns sql.ErrNoRows if
+// there is no such user.
+func DeleteUser(ctx context.Context, db DBTX, userID, heirID int64) error {
+	if _, err := db.ExecContext(ctx,
+		`UPDATE tournaments SET organizer_id = $2 WHERE organizer_id = $1`, userID, heirID,
+	); err != nil {
+		return err
+	}
+	res, err := db.ExecContext(ctx, `DELETE FROM users WHERE id = $1`, userID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
 func UpdateUserRoles(ctx context.Context, db *sql.DB, userID int64, roles []string) error {
 	_, err := db.ExecContext(ctx,
 		`UPDATE users SET roles = $1, updated_at = now() WHERE id = $2`,
@@ -111,7 +195,7 @@ func UpdateUserRoles(ctx context.Context, db *sql.DB, userID int64, roles []stri
 func ListUsers(ctx context.Context, db *sql.DB, page, perPage int) ([]models.User, error) {
 	offset := (page - 1) * perPage
 	rows, err := db.QueryContext(ctx,
-		`SELECT id, email, display_name, password_hash, roles, email_verified_at, failed_login_attempts, locked_until, created_at, updated_at
+		`SELECT id, email, display_name, password_hash, roles, email_verified_at, failed_login_attempts, locked_until, notify_by, deletion_requested_at, created_at, updated_at
 		 FROM users ORDER BY id LIMIT $1 OFFSET $2`,
 		perPage, offset,
 	)
@@ -123,7 +207,7 @@ func ListUsers(ctx context.Context, db *sql.DB, page, perPage int) ([]models.Use
 	var users []models.User
 	for rows.Next() {
 		var u models.User
-		if err := rows.Scan(&u.ID, &u.Email, &u.DisplayName, &u.PasswordHash, pq.Array(&u.Roles), &u.EmailVerifiedAt, &u.FailedLoginAttempts, &u.LockedUntil, &u.CreatedAt, &u.UpdatedAt); err != nil {
+		if err := rows.Scan(&u.ID, &u.Email, &u.DisplayName, &u.PasswordHash, pq.Array(&u.Roles), &u.EmailVerifiedAt, &u.FailedLoginAttempts, &u.LockedUntil, &u.NotifyBy, &u.DeletionRequestedAt, &u.CreatedAt, &u.UpdatedAt); err != nil {
 			return nil, err
 		}
 		users = append(users, u)
@@ -158,6 +242,13 @@ func DeleteSession(ctx context.Context, db *sql.DB, id string) error {
 	return err
 }
 
+// DeleteOtherSessions logs a user out everywhere except the session with
+// keepID. Used after a password change.
+func DeleteOtherSessions(ctx context.Context, db *sql.DB, userID int64, keepID string) error {
+	_, err := db.ExecContext(ctx, `DELETE FROM sessions WHERE user_id = $1 AND id <> $2`, userID, keepID)
+	return err
+}
+
 func DeleteExpiredSessions(ctx context.Context, db *sql.DB) error {
 	_, err := db.ExecContext(ctx, `DELETE FROM sessions WHERE expires_at < now()`)
 	return err
@@ -170,14 +261,14 @@ func DeleteExpiredPasswordResets(ctx context.Context, db *sql.DB) error {
 
 // API Keys
 
-func CreateAPIKey(ctx context.Context, db *sql.DB, userID int64, keyHash, prefix, name string, expiresAt *time.Time) (*models.APIKey, error) {
+func CreateAPIKey(ctx context.Context, db *sql.DB, userID int64, keyHash, prefix, name, scope string, expiresAt *time.Time) (*models.APIKey, error) {
 	k := &models.APIKey{}
 	err := db.QueryRowContext(ctx,
-		`INSERT INTO api_keys (user_id, key_hash, prefix, name, expires_at)
-		 VALUES ($1, $2, $3, $4, $5)
-		 RETURNING id, user_id, key_hash, prefix, name, last_used, created_at, expires_at`,
-		userID, keyHash, prefix, name, expiresAt,
-	).Scan(&k.ID, &k.UserID, &k.KeyHash, &k.Prefix, &k.Name, &k.LastUsed, &k.CreatedAt, &k.ExpiresAt)
+		`INSERT INTO api_keys (user_id, key_hash, prefix, name, scope, expires_at)
+		 VALUES ($1, $2, $3, $4, $5, $6)
+		 RETURNING id, user_id, key_hash, prefix, name, scope, last_used, created_at, expires_at`,
+		userID, keyHash, prefix, name, scope, expiresAt,
+	).Scan(&k.ID, &k.UserID, &k.KeyHash, &k.Prefix, &k.Name, &k.Scope, &k.LastUsed, &k.CreatedAt, &k.ExpiresAt)
 	if err != nil {
 		return nil, err
 	}
@@ -186,7 +277,7 @@ func CreateAPIKey(ctx context.Context, db *sql.DB, userID int64, keyHash, prefix
 
 func ListAPIKeysByUser(ctx context.Context, db *sql.DB, userID int64) ([]models.APIKey, error) {
 	rows, err := db.QueryContext(ctx,
-		`SELECT id, user_id, prefix, name, last_used, created_at, expires_at
+		`SELECT id, user_id, prefix, name, scope, last_used, created_at, expires_at
 		 FROM api_keys WHERE user_id = $1 ORDER BY created_at DESC`,
 		userID,
 	)
@@ -198,7 +289,7 @@ func ListAPIKeysByUser(ctx context.Context, db *sql.DB, userID int64) ([]models.
 	var keys []models.APIKey
 	for rows.Next() {
 		var k models.APIKey
-		if err := rows.Scan(&k.ID, &k.UserID, &k.Prefix, &k.Name, &k.LastUsed, &k.CreatedAt, &k.ExpiresAt); err != nil {
+		if err := rows.Scan(&k.ID, &k.UserID, &k.Prefix, &k.Name, &k.Scope, &k.LastUsed, &k.CreatedAt, &k.ExpiresAt); err != nil {
 			return nil, err
 		}
 		keys = append(keys, k)
@@ -208,7 +299,7 @@ func ListAPIKeysByUser(ctx context.Context, db *sql.DB, userID int64) ([]models.
 
 func GetAPIKeysByPrefix(ctx context.Context, db *sql.DB, prefix string) ([]models.APIKey, error) {
 	rows, err := db.QueryContext(ctx,
-		`SELECT id, user_id, key_hash, prefix, name, last_used, created_at, expires_at
+		`SELECT id, user_id, key_hash, prefix, name, scope, last_used, created_at, expires_at
 		 FROM api_keys WHERE prefix = $1 AND (expires_at IS NULL OR expires_at > now())`,
 		prefix,
 	)
@@ -220,7 +311,7 @@ func GetAPIKeysByPrefix(ctx context.Context, db *sql.DB, prefix string) ([]model
 	var keys []models.APIKey
 	for rows.Next() {
 		var k models.APIKey
-		if err := rows.Scan(&k.ID, &k.UserID, &k.KeyHash, &k.Prefix, &k.Name, &k.LastUsed, &k.CreatedAt, &k.ExpiresAt); err != nil {
+		if err := rows.Scan(&k.ID, &k.UserID, &k.KeyHash, &k.Prefix, &k.Name, &k.Scope, &k.LastUsed, &k.CreatedAt, &k.ExpiresAt); err != nil {
 			return nil, err
 		}
 		keys = append(keys, k)
@@ -233,6 +324,52 @@ func UpdateAPIKeyLastUsed(ctx context.Context, db *sql.DB, id int64) error {
 	return err
 }
 
+// OwnedAPIKey is an API key with its owner, as admins see it.
+type OwnedAPIKey struct {
+	models.APIKey
+	UserEmail       string `json:"user_email"`
+	UserDisplayName string `json:"user_display_name"`
+}
+
+// ListAllAPIKeys returns every user's API keys, newest first.
+func ListAllAPIKeys(ctx context.Context, db *sql.DB) ([]OwnedAPIKey, error) {
+	rows, err := db.QueryContext(ctx,
+		`SELECT k.id, k.user_id, k.prefix, k.name, k.scope, k.last_used, k.created_at, k.expires_at,
+		        u.email, u.display_name
+		 FROM api_keys k JOIN users u ON u.id = k.user_id
+		 ORDER BY k.created_at DESC, k.id DESC`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []OwnedAPIKey
+	for rows.Next() {
+		var k OwnedAPIKey
+		if err := rows.Scan(&k.ID, &k.UserID, &k.Prefix, &k.Name, &k.Scope, &k.LastUsed, &k.CreatedAt, &k.ExpiresAt,
+			&k.UserEmail, &k.UserDisplayName); err != nil {
+			return nil, err
+		}
+		keys = append(keys, k)
+	}
+	return keys, rows.Err()
+}
+
+// RevokeAPIKey deletes any user's API key, for admins. Returns
+// sql.ErrNoRows if there is no such key.
+func RevokeAPIKey(ctx context.Context, db *sql.DB, id int64) error {
+	result, err := db.ExecContext(ctx, `DELETE FROM api_keys WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	n, _ := result.RowsAffected()
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
 func DeleteAPIKey(ctx context.Context, db *sql.DB, id, userID int64) error {
 	result, err := db.ExecContext(ctx, `DELETE FROM api_keys WHERE id = $1 AND user_id = $2`, id, userID)
 	if err != nil {
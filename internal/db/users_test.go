@@ -7,6 +7,8 @@ import (
 "database/sql"
 "testing"
 "time"
+
+"github.com/dstathis/openswiss/internal/models"
 )
 
 func TestCreateAndGetUser(t *testing.T) {
@@ -115,6 +117,31 @@ t.Errorf("password_hash = %q, want %q", got.PasswordHash, "newhash")
 }
 }
 
+func TestEnsureAdminUser(t *testing.T) {
+database := testDB(t)
+ctx := context.Background()
+
+created, err := EnsureAdminUser(ctx, database, "boot@example.com", "Boot", "boothash")
+if err != nil || !created {
+t.Fatalf("EnsureAdminUser = %v, %v; want created", created, err)
+}
+u, _ := GetUserByEmail(ctx, database, "boot@example.com")
+if !u.HasRole("admin") || u.EmailVerifiedAt == nil {
+t.Errorf("bootstrap admin = %+v", u)
+}
+
+// An existing user is promoted but keeps their password.
+existing, _ := CreateUser(ctx, database, "old@example.com", "Old", "oldhash")
+created, err = EnsureAdminUser(ctx, database, "old@example.com", "Ignored", "newhash")
+if err != nil || created {
+t.Fatalf("EnsureAdminUser on existing = %v, %v", created, err)
+}
+got, _ := GetUserByID(ctx, database, existing.ID)
+if !got.HasRole("admin") || !got.HasRole("player") || got.PasswordHash != "oldhash" {
+t.Errorf("promoted user = %+v", got)
+}
+}
+
 func TestSessions(t *testing.T) {
 database := testDB(t)
 ctx := context.Background()
@@ -146,6 +173,28 @@ t.Errorf("expected sql.ErrNoRows after delete, got %v", err)
 }
 }
 
+func TestDeleteOtherSessions(t *testing.T) {
+database := testDB(t)
+ctx := context.Background()
+
+u, _ := CreateUser(ctx, database, "multi@example.com", "Multi", "hash")
+expires := time.Now().Add(time.Hour)
+for _, id := range []string{"keep", "phone", "laptop"} {
+if err := CreateSession(ctx, database, id, u.ID, expires); err != nil {
+t.Fatalf("CreateSession: %v", err)
+}
+}
+if err := DeleteOtherSessions(ctx, database, u.ID, "keep"); err != nil {
+t.Fatalf("DeleteOtherSessions: %v", err)
+}
+if _, err := GetSession(ctx, database, "keep"); err != nil {
+t.Errorf("kept session gone: %v", err)
+}
+if _, err := GetSession(ctx, database, "phone"); err != sql.ErrNoRows {
+t.Errorf("other session survived: %v", err)
+}
+}
+
 func TestExpiredSession(t *testing.T) {
 database := testDB(t)
 ctx := context.Background()
@@ -172,7 +221,7 @@ ctx := context.Background()
 
 u, _ := CreateUser(ctx, database, "api@example.com", "APIUser", "hash")
 
-k, err := CreateAPIKey(ctx, database, u.ID, "keyhash123", "os_abc", "My Key", nil)
+k, err := CreateAPIKey(ctx, database, u.ID, "keyhash123", "os_abc", "My Key", models.APIKeyScopeReadWrite, nil)
 if err != nil {
 t.Fatalf("CreateAPIKey: %v", err)
 }
@@ -225,7 +274,7 @@ ctx := context.Background()
 u1, _ := CreateUser(ctx, database, "u1@example.com", "User1", "hash")
 u2, _ := CreateUser(ctx, database, "u2@example.com", "User2", "hash")
 
-k, _ := CreateAPIKey(ctx, database, u1.ID, "keyhash", "os_xyz", "Key", nil)
+k, _ := CreateAPIKey(ctx, database, u1.ID, "keyhash", "os_xyz", "Key", models.APIKeyScopeReadWrite, nil)
 
 err := DeleteAPIKey(ctx, database, k.ID, u2.ID)
 if err != sql.ErrNoRows {
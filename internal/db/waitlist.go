@@ -0,0 +1,66 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+// ErrNotWaitlisted is returned by PromoteRegistration for a registration
+// that isn't on the tournament's waitlist.
+var ErrNotWaitlisted = errors.New("registration is not on the waitlist")
+
+// RegisterUser registers user userID for tournament tournamentID with
+// status, like CreateRegistration and CreatePendingRegistration, unless
+// maxPlayers registrations already count toward the cap (see
+// CountRegistrations): then the user goes on the waitlist instead.
+// maxPlayers 0 is no cap. Counting and inserting happen under the
+// tournament's lock, so two sign-ups can't both take the last place.
+func RegisterUser(ctx context.Context, database *sql.DB, tournamentID, userID int64, displayName, status string, maxPlayers int) (*models.Registration, error) {
+	tx, err := database.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := lockTournament(ctx, tx, tournamentID); err != nil {
+		return nil, err
+	}
+	if maxPlayers > 0 {
+		count, err := countRegistrations(ctx, tx, tournamentID)
+		if err != nil {
+			return nil, err
+		}
+		if count >= maxPlayers {
+			status = models.RegistrationStatusWaitlisted
+		}
+	}
+	r, err := insertUserRegistration(ctx, tx, tournamentID, userID, displayName, status)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// PromoteRegistration takes registration regID off tournament
+// tournamentID's waitlist: to pending when requireDecklist is set and it
+// has no decklist yet, otherwise to confirmed. It returns the updated
+// registration, or ErrNotWaitlisted.
+func PromoteRegistration(ctx context.Context, db DBTX, tournamentID, regID int64, requireDecklist bool) (*models.Registration, error) {
+	r, err := scanRegistration(db.QueryRowContext(ctx,
+		`UPDATE registrations
+		 SET status = CASE WHEN $3 AND decklist IS NULL THEN 'pending' ELSE 'confirmed' END
+		 WHERE tournament_id = $1 AND id = $2 AND status = 'waitlisted'
+		 RETURNING `+regCols,
+		tournamentID, regID, requireDecklist,
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotWaitlisted
+	}
+	return r, err
+}
@@ -0,0 +1,66 @@
+//go:build integration
+
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+func TestRegisterUser_Waitlist(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	org := createTestOrganizer(t, database)
+	tourn := &models.Tournament{Name: "Capped", Status: models.TournamentStatusRegistrationOpen, OrganizerID: org.ID, MaxPlayers: 2}
+	if err := CreateTournament(ctx, database, tourn); err != nil {
+		t.Fatalf("CreateTournament: %v", err)
+	}
+	register := func(name, status string) *models.Registration {
+		u, err := CreateUser(ctx, database, name+"@example.com", name, "hash")
+		if err != nil {
+			t.Fatal(err)
+		}
+		r, err := RegisterUser(ctx, database, tourn.ID, u.ID, u.DisplayName, status, tourn.MaxPlayers)
+		if err != nil {
+			t.Fatalf("RegisterUser %s: %v", name, err)
+		}
+		return r
+	}
+
+	register("Alice", models.RegistrationStatusConfirmed)
+	if r := register("Bob", models.RegistrationStatusPending); r.Status != models.RegistrationStatusPending {
+		t.Errorf("Bob: status %q, want pending", r.Status)
+	}
+	carol := register("Carol", models.RegistrationStatusPending)
+	dave := register("Dave", models.RegistrationStatusConfirmed)
+	for _, r := range []*models.Registration{carol, dave} {
+		if r.Status != models.RegistrationStatusWaitlisted {
+			t.Errorf("%s: status %q, want waitlisted", r.DisplayName, r.Status)
+		}
+	}
+	if n, _ := CountRegistrations(ctx, database, tourn.ID); n != 2 {
+		t.Errorf("CountRegistrations = %d, want 2", n)
+	}
+
+	// A decklist doesn't jump the queue.
+	if err := UpdateRegistrationDecklistByID(ctx, database, dave.ID, []byte(`{}`)); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := GetRegistrationByID(ctx, database, dave.ID); got.Status != models.RegistrationStatusWaitlisted {
+		t.Errorf("after a decklist: status %q, want waitlisted", got.Status)
+	}
+
+	// Promoted with the decklist required: Carol has none, Dave has one.
+	if got, err := PromoteRegistration(ctx, database, tourn.ID, carol.ID, true); err != nil || got.Status != models.RegistrationStatusPending {
+		t.Errorf("promote Carol = %+v, %v; want pending", got, err)
+	}
+	if got, err := PromoteRegistration(ctx, database, tourn.ID, dave.ID, true); err != nil || got.Status != models.RegistrationStatusConfirmed {
+		t.Errorf("promote Dave = %+v, %v; want confirmed", got, err)
+	}
+	if _, err := PromoteRegistration(ctx, database, tourn.ID, dave.ID, true); !errors.Is(err, ErrNotWaitlisted) {
+		t.Errorf("promoting twice: err = %v, want ErrNotWaitlisted", err)
+	}
+}
@@ -0,0 +1,180 @@
+package db
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/lib/pq"
+)
+
+// ErrWebhookNotFound is returned when a webhook doesn't exist or belongs to
+// a different tournament.
+var ErrWebhookNotFound = errors.New("webhook: not found")
+
+// PendingWebhookDelivery is a claimed delivery together with the endpoint it
+// goes to.
+type PendingWebhookDelivery struct {
+	models.WebhookDelivery
+	URL    string
+	Secret string
+}
+
+// CreateWebhook inserts a webhook.
+func CreateWebhook(ctx context.Context, db DBTX, wh *models.Webhook) error {
+	return db.QueryRowContext(ctx,
+		`INSERT INTO tournament_webhooks (tournament_id, url, secret, events, created_by)
+		 VALUES ($1, $2, $3, $4, $5)
+		 RETURNING id, created_at`,
+		wh.TournamentID, wh.URL, wh.Secret, pq.Array(wh.Events), wh.CreatedBy,
+	).Scan(&wh.ID, &wh.CreatedAt)
+}
+
+// ListWebhooks returns a tournament's webhooks in creation order.
+func ListWebhooks(ctx context.Context, db DBTX, tournamentID int64) ([]models.Webhook, error) {
+	rows, err := db.QueryContext(ctx,
+		`SELECT id, tournament_id, url, secret, events, created_by, created_at
+		 FROM tournament_webhooks WHERE tournament_id = $1 ORDER BY id`,
+		tournamentID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []models.Webhook
+	for rows.Next() {
+		var wh models.Webhook
+		if err := rows.Scan(&wh.ID, &wh.TournamentID, &wh.URL, &wh.Secret, pq.Array(&wh.Events),
+			&wh.CreatedBy, &wh.CreatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, wh)
+	}
+	return out, rows.Err()
+}
+
+// DeleteWebhook removes a webhook and, via the cascade, its queued
+// deliveries.
+func DeleteWebhook(ctx context.Context, db DBTX, tournamentID, webhookID int64) error {
+	res, err := db.ExecContext(ctx,
+		`DELETE FROM tournament_webhooks WHERE id = $1 AND tournament_id = $2`,
+		webhookID, tournamentID,
+	)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrWebhookNotFound
+	}
+	return nil
+}
+
+// HasWebhooks reports whether the tournament has any webhooks, so callers
+// can skip building event payloads nobody will receive.
+func HasWebhooks(ctx context.Context, db DBTX, tournamentID int64) (bool, error) {
+	var exists bool
+	err := db.QueryRowContext(ctx,
+		`SELECT EXISTS (SELECT 1 FROM tournament_webhooks WHERE tournament_id = $1)`,
+		tournamentID,
+	).Scan(&exists)
+	return exists, err
+}
+
+// EnqueueWebhookEvent queues payload, a JSON document, for every webhook of
+// the tournament subscribed to event. Run it in the transaction that caused the event so
+// the delivery exists exactly when the change does.
+func EnqueueWebhookEvent(ctx context.Context, db DBTX, tournamentID int64, event string, payload []byte) error {
+	_, err := db.ExecContext(ctx,
+		`INSERT INTO webhook_deliveries (webhook_id, event, payload)
+		 SELECT id, $2, $3 FROM tournament_webhooks
+		 WHERE tournament_id = $1 AND $2 = ANY(events)`,
+		tournamentID, event, string(payload),
+	)
+	return err
+}
+
+// ClaimWebhookDeliveries picks up to limit deliveries that are due, counts
+// an attempt on each and pushes their next attempt back by lease, so another
+// worker (or this one, after a crash) only retries them once the lease runs
+// out. Oldest first.
+func ClaimWebhookDeliveries(ctx context.Context, db DBTX, limit int, lease time.Duration) ([]PendingWebhookDelivery, error) {
+	rows, err := db.QueryContext(ctx,
+		`UPDATE webhook_deliveries d
+		 SET attempts = d.attempts + 1, next_attempt_at = now() + $2 * interval '1 second'
+		 FROM tournament_webhooks w
+		 WHERE w.id = d.webhook_id AND d.id IN (
+		     SELECT id FROM webhook_deliveries
+		     WHERE next_attempt_at <= now()
+		     ORDER BY id LIMIT $1
+		     FOR UPDATE SKIP LOCKED)
+		 RETURNING d.id, d.webhook_id, d.event, d.payload, d.attempts, d.created_at, w.url, w.secret`,
+		limit, lease.Seconds(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []PendingWebhookDelivery
+	for rows.Next() {
+		var d PendingWebhookDelivery
+		if err := rows.Scan(&d.ID, &d.WebhookID, &d.Event, &d.Payload, &d.Attempts, &d.CreatedAt,
+			&d.URL, &d.Secret); err != nil {
+			return nil, err
+		}
+		out = append(out, d)
+	}
+	// RETURNING has no order of its own.
+	slices.SortFunc(out, func(a, b PendingWebhookDelivery) int { return cmp.Compare(a.ID, b.ID) })
+	return out, rows.Err()
+}
+
+// MarkWebhookDelivered records a successful delivery.
+func MarkWebhookDelivered(ctx context.Context, db DBTX, id int64) error {
+	_, err := db.ExecContext(ctx,
+		`UPDATE webhook_deliveries SET delivered_at = now(), next_attempt_at = NULL, last_error = ''
+		 WHERE id = $1`,
+		id,
+	)
+	return err
+}
+
+// FailWebhookDelivery records a failed attempt. A nil retryAt gives up on
+// the delivery.
+func FailWebhookDelivery(ctx context.Context, db DBTX, id int64, msg string, retryAt *time.Time) error {
+	_, err := db.ExecContext(ctx,
+		`UPDATE webhook_deliveries SET last_error = $2, next_attempt_at = $3 WHERE id = $1`,
+		id, msg, retryAt,
+	)
+	return err
+}
+
+// ListWebhookDeliveries returns the tournament's most recent deliveries,
+// newest first.
+func ListWebhookDeliveries(ctx context.Context, db DBTX, tournamentID int64, limit int) ([]models.WebhookDelivery, error) {
+	rows, err := db.QueryContext(ctx,
+		`SELECT d.id, d.webhook_id, d.event, d.payload, d.attempts, d.next_attempt_at, d.delivered_at,
+		        d.last_error, d.created_at
+		 FROM webhook_deliveries d
+		 JOIN tournament_webhooks w ON w.id = d.webhook_id
+		 WHERE w.tournament_id = $1
+		 ORDER BY d.id DESC LIMIT $2`,
+		tournamentID, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []models.WebhookDelivery
+	for rows.Next() {
+		var d models.WebhookDelivery
+		if err := rows.Scan(&d.ID, &d.WebhookID, &d.Event, &d.Payload, &d.Attempts, &d.NextAttemptAt,
+			&d.DeliveredAt, &d.LastError, &d.CreatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, d)
+	}
+	return out, rows.Err()
+}
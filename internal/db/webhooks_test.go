@@ -0,0 +1,77 @@
+//go:build integration
+
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+func TestWebhookDeliveries(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	org := createTestOrganizer(t, database)
+	tourn := &models.Tournament{Name: "Webhooks", Status: models.TournamentStatusScheduled, OrganizerID: org.ID}
+	if err := CreateTournament(ctx, database, tourn); err != nil {
+		t.Fatal(err)
+	}
+	if has, _ := HasWebhooks(ctx, database, tourn.ID); has {
+		t.Error("HasWebhooks before any were added")
+	}
+	all := &models.Webhook{TournamentID: tourn.ID, URL: "https://a.example", Secret: "a", Events: models.WebhookEvents}
+	results := &models.Webhook{TournamentID: tourn.ID, URL: "https://b.example", Secret: "b", Events: []string{models.WebhookResultEntered}}
+	for _, wh := range []*models.Webhook{all, results} {
+		if err := CreateWebhook(ctx, database, wh); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Only subscribed webhooks get a delivery.
+	if err := EnqueueWebhookEvent(ctx, database, tourn.ID, models.WebhookRoundPaired, []byte(`{"n":1}`)); err != nil {
+		t.Fatal(err)
+	}
+	if err := EnqueueWebhookEvent(ctx, database, tourn.ID, models.WebhookResultEntered, []byte(`{"n":2}`)); err != nil {
+		t.Fatal(err)
+	}
+	claimed, err := ClaimWebhookDeliveries(ctx, database, 10, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(claimed) != 3 || claimed[0].URL != all.URL || string(claimed[0].Payload) != `{"n": 1}` || claimed[0].Attempts != 1 {
+		t.Fatalf("claimed = %+v", claimed)
+	}
+	// Claimed deliveries are leased out.
+	if again, _ := ClaimWebhookDeliveries(ctx, database, 10, time.Minute); len(again) != 0 {
+		t.Errorf("claimed %d leased deliveries", len(again))
+	}
+
+	if err := MarkWebhookDelivered(ctx, database, claimed[0].ID); err != nil {
+		t.Fatal(err)
+	}
+	past := time.Now().Add(-time.Second)
+	if err := FailWebhookDelivery(ctx, database, claimed[1].ID, "HTTP 500", &past); err != nil {
+		t.Fatal(err)
+	}
+	if err := FailWebhookDelivery(ctx, database, claimed[2].ID, "gone", nil); err != nil {
+		t.Fatal(err)
+	}
+	retry, _ := ClaimWebhookDeliveries(ctx, database, 10, time.Minute)
+	if len(retry) != 1 || retry[0].ID != claimed[1].ID || retry[0].Attempts != 2 {
+		t.Errorf("retry = %+v", retry)
+	}
+
+	log, _ := ListWebhookDeliveries(ctx, database, tourn.ID, 10)
+	if len(log) != 3 || log[2].DeliveredAt == nil || log[0].NextAttemptAt != nil || log[0].LastError != "gone" {
+		t.Errorf("log = %+v", log)
+	}
+
+	if err := DeleteWebhook(ctx, database, tourn.ID, all.ID); err != nil {
+		t.Fatal(err)
+	}
+	if log, _ = ListWebhookDeliveries(ctx, database, tourn.ID, 10); len(log) != 2 {
+		t.Errorf("deliveries after delete = %d, want 2", len(log))
+	}
+}
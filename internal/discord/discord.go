@@ -0,0 +1,178 @@
+// Package discord formats pairings and standings as Discord messages and
+// verifies the signatures Discord puts on interaction requests. It backs the
+// bot companion endpoints in the API.
+package discord
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	st "github.com/dstathis/swisstools"
+)
+
+// MessageLimit is the most characters Discord accepts in one message.
+const MessageLimit = 2000
+
+// Pairing is what a pairings message shows of one match.
+type Pairing struct {
+	Table   int
+	PlayerA string
+	PlayerB string
+	Bye     bool
+	// Result is the score from player A's side, e.g. "2-1", or "" while
+	// the match is unreported.
+	Result string
+}
+
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "*", `\*`, "_", `\_`, "~", `\~`, "`", "\\`", "|", `\|`, ">", `\>`,
+	// A zero-width space after @ keeps names like "@everyone" from pinging.
+	"@", "@\u200b",
+)
+
+// Escape makes a player or tournament name safe to embed in a message:
+// Markdown is escaped and mentions are defused.
+func Escape(s string) string {
+	return markdownEscaper.Replace(s)
+}
+
+// Chunk joins lines into messages of at most limit characters, never
+// splitting a line. The header opens the first message. A line that can't
+// fit in a message on its own is cut short with an ellipsis.
+func Chunk(header string, lines []string, limit int) []string {
+	if limit <= 0 || limit > MessageLimit {
+		limit = MessageLimit
+	}
+	var out []string
+	var b strings.Builder
+	n := 0 // characters in b
+	add := func(line string) {
+		ln := utf8.RuneCountInString(line)
+		if ln > limit {
+			line = string([]rune(line)[:limit-1]) + "…"
+			ln = limit
+		}
+		if n > 0 && n+1+ln > limit {
+			out = append(out, b.String())
+			b.Reset()
+			n = 0
+		}
+		if n > 0 {
+			b.WriteByte('\n')
+			n++
+		}
+		b.WriteString(line)
+		n += ln
+	}
+	if header != "" {
+		add(header)
+	}
+	for _, l := range lines {
+		add(l)
+	}
+	if n > 0 {
+		out = append(out, b.String())
+	}
+	return out
+}
+
+// PairingsMessages renders a round's pairings, one line per table, byes
+// last.
+func PairingsMessages(tournament string, round int, pairings []Pairing, limit int) []string {
+	header := fmt.Sprintf("**%s — Round %d pairings**", Escape(tournament), round)
+	var lines, byes []string
+	for _, p := range pairings {
+		if p.Bye {
+			byes = append(byes, fmt.Sprintf("%s has a bye", Escape(p.PlayerA)))
+			continue
+		}
+		line := fmt.Sprintf("`T%d` %s vs %s", p.Table, Escape(p.PlayerA), Escape(p.PlayerB))
+		if p.Result != "" {
+			line += " — " + p.Result
+		}
+		lines = append(lines, line)
+	}
+	if len(lines)+len(byes) == 0 {
+		lines = append(lines, "No pairings yet.")
+	}
+	return Chunk(header, append(lines, byes...), limit)
+}
+
+// OutstandingMessages renders the tables of a round still waiting for a
+// result, as a reminder to report them.
+func OutstandingMessages(tournament string, round int, pairings []Pairing, limit int) []string {
+	header := fmt.Sprintf("**%s — Round %d: results outstanding**", Escape(tournament), round)
+	lines := make([]string, 0, len(pairings))
+	for _, p := range pairings {
+		lines = append(lines, fmt.Sprintf("`T%d` %s vs %s", p.Table, Escape(p.PlayerA), Escape(p.PlayerB)))
+	}
+	if len(lines) == 0 {
+		lines = append(lines, "Every result is in.")
+	} else {
+		lines = append(lines, "Please report your result to the judges.")
+	}
+	return Chunk(header, lines, limit)
+}
+
+// AnnouncementMessages renders a tournament's announcements, newest first,
+// one line each.
+func AnnouncementMessages(tournament string, announcements []string, limit int) []string {
+	header := fmt.Sprintf("**%s — Announcements**", Escape(tournament))
+	lines := make([]string, 0, len(announcements))
+	for _, a := range announcements {
+		lines = append(lines, "📢 "+Escape(strings.Join(strings.Fields(a), " ")))
+	}
+	if len(lines) == 0 {
+		lines = append(lines, "No announcements right now.")
+	}
+	return Chunk(header, lines, limit)
+}
+
+// StandingsMessages renders the standings in rank order, cut to the top
+// players when top > 0.
+func StandingsMessages(tournament string, round int, standings []st.PlayerStanding, top, limit int) []string {
+	header := fmt.Sprintf("**%s — Standings after round %d**", Escape(tournament), round)
+	if top > 0 && top < len(standings) {
+		standings = standings[:top]
+	}
+	lines := make([]string, 0, len(standings))
+	for _, s := range standings {
+		lines = append(lines, fmt.Sprintf("`%d.` %s — %d pts (%d-%d-%d)",
+			s.Rank, Escape(s.Name), s.Points, s.Wins, s.Losses, s.Draws))
+	}
+	if len(lines) == 0 {
+		lines = append(lines, "No standings yet.")
+	}
+	return Chunk(header, lines, limit)
+}
+
+// ParsePublicKey decodes the application's public key as shown, in hex, in
+// the Discord developer portal.
+func ParsePublicKey(hexKey string) (ed25519.PublicKey, error) {
+	b, err := hex.DecodeString(strings.TrimSpace(hexKey))
+	if err != nil {
+		return nil, fmt.Errorf("discord public key: %w", err)
+	}
+	if len(b) != ed25519.PublicKeySize {
+		return nil, errors.New("discord public key: wrong length")
+	}
+	return ed25519.PublicKey(b), nil
+}
+
+// Verify checks the signature Discord sends on an interaction request: the
+// hex Ed25519 signature from X-Signature-Ed25519 over the
+// X-Signature-Timestamp value followed by the raw body.
+func Verify(publicKey ed25519.PublicKey, signatureHex, timestamp string, body []byte) bool {
+	sig, err := hex.DecodeString(signatureHex)
+	if err != nil || len(sig) != ed25519.SignatureSize || timestamp == "" {
+		return false
+	}
+	msg := make([]byte, 0, len(timestamp)+len(body))
+	msg = append(msg, timestamp...)
+	msg = append(msg, body...)
+	return ed25519.Verify(publicKey, msg, sig)
+}
@@ -0,0 +1,145 @@
+package discord
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	st "github.com/dstathis/swisstools"
+)
+
+func TestEscape(t *testing.T) {
+	if got := Escape("*Bold* _al_ @everyone"); got != "\\*Bold\\* \\_al\\_ @\u200beveryone" {
+		t.Errorf("Escape = %q", got)
+	}
+}
+
+func TestChunk(t *testing.T) {
+	lines := make([]string, 50)
+	for i := range lines {
+		lines[i] = strings.Repeat("x", 30)
+	}
+	msgs := Chunk("header", lines, 100)
+	if len(msgs) < 2 {
+		t.Fatalf("got %d messages, want several", len(msgs))
+	}
+	total := 0
+	for _, m := range msgs {
+		if n := utf8.RuneCountInString(m); n > 100 {
+			t.Errorf("message of %d characters exceeds limit", n)
+		}
+		total += strings.Count(m, strings.Repeat("x", 30))
+	}
+	if total != 50 {
+		t.Errorf("lines across messages = %d, want 50", total)
+	}
+	if !strings.HasPrefix(msgs[0], "header\n") {
+		t.Errorf("first message = %q", msgs[0])
+	}
+
+	long := Chunk("", []string{strings.Repeat("é", 150)}, 100)
+	if len(long) != 1 || utf8.RuneCountInString(long[0]) != 100 || !strings.HasSuffix(long[0], "…") {
+		t.Errorf("overlong line = %q", long)
+	}
+	if got := Chunk("h", nil, 5000); len(got) != 1 {
+		t.Errorf("limit above MessageLimit: %q", got)
+	}
+}
+
+func TestPairingsMessages(t *testing.T) {
+	msgs := PairingsMessages("Friday *Night*", 2, []Pairing{
+		{Table: 0, PlayerA: "Eve", Bye: true},
+		{Table: 1, PlayerA: "Alice", PlayerB: "Bob", Result: "2-1"},
+		{Table: 2, PlayerA: "Carol", PlayerB: "Dave"},
+	}, MessageLimit)
+	want := "**Friday \\*Night\\* — Round 2 pairings**\n" +
+		"`T1` Alice vs Bob — 2-1\n" +
+		"`T2` Carol vs Dave\n" +
+		"Eve has a bye"
+	if len(msgs) != 1 || msgs[0] != want {
+		t.Errorf("got %q\nwant %q", msgs, want)
+	}
+}
+
+func TestOutstandingMessages(t *testing.T) {
+	msgs := OutstandingMessages("Cup", 3, []Pairing{{Table: 4, PlayerA: "Alice", PlayerB: "@Bob"}}, MessageLimit)
+	want := "**Cup — Round 3: results outstanding**\n`T4` Alice vs @\u200bBob\nPlease report your result to the judges."
+	if len(msgs) != 1 || msgs[0] != want {
+		t.Errorf("got %q\nwant %q", msgs, want)
+	}
+	if got := OutstandingMessages("Cup", 3, nil, MessageLimit); len(got) != 1 || !strings.HasSuffix(got[0], "Every result is in.") {
+		t.Errorf("nothing outstanding = %q", got)
+	}
+}
+
+func TestAnnouncementMessages(t *testing.T) {
+	msgs := AnnouncementMessages("Cup", []string{"Round 3 is *delayed*\n10 minutes", "Lunch @ 13:00"}, MessageLimit)
+	want := "**Cup — Announcements**\n📢 Round 3 is \\*delayed\\* 10 minutes\n📢 Lunch @\u200b 13:00"
+	if len(msgs) != 1 || msgs[0] != want {
+		t.Errorf("got %q\nwant %q", msgs, want)
+	}
+	if got := AnnouncementMessages("Cup", nil, MessageLimit); len(got) != 1 || !strings.HasSuffix(got[0], "No announcements right now.") {
+		t.Errorf("no announcements = %q", got)
+	}
+}
+
+func TestStandingsMessages(t *testing.T) {
+	standings := []st.PlayerStanding{
+		{Rank: 1, Name: "Alice", Points: 6, Wins: 2},
+		{Rank: 2, Name: "Bob", Points: 3, Wins: 1, Losses: 1},
+		{Rank: 3, Name: "Carol", Points: 0, Losses: 2},
+	}
+	msgs := StandingsMessages("Cup", 2, standings, 2, MessageLimit)
+	want := "**Cup — Standings after round 2**\n`1.` Alice — 6 pts (2-0-0)\n`2.` Bob — 3 pts (1-1-0)"
+	if len(msgs) != 1 || msgs[0] != want {
+		t.Errorf("got %q\nwant %q", msgs, want)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := ParsePublicKey(hex.EncodeToString(pub))
+	if err != nil {
+		t.Fatalf("ParsePublicKey: %v", err)
+	}
+	body := []byte(`{"type":1}`)
+	sig := hex.EncodeToString(ed25519.Sign(priv, append([]byte("1700000000"), body...)))
+
+	if !Verify(parsed, sig, "1700000000", body) {
+		t.Error("valid signature rejected")
+	}
+	if Verify(parsed, sig, "1700000001", body) {
+		t.Error("signature accepted with another timestamp")
+	}
+	if Verify(parsed, sig, "1700000000", []byte(`{"type":2}`)) {
+		t.Error("signature accepted for another body")
+	}
+	if Verify(parsed, "zz", "1700000000", body) {
+		t.Error("malformed signature accepted")
+	}
+	if _, err := ParsePublicKey("abcd"); err == nil {
+		t.Error("short key accepted")
+	}
+}
+
+func TestInteraction_IntOption(t *testing.T) {
+	var in Interaction
+	if err := json.Unmarshal([]byte(`{"type":2,"data":{"name":"pairings","options":[{"name":"tournament","value":42},{"name":"round","value":"3"}]}}`), &in); err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := in.IntOption("tournament"); !ok || v != 42 {
+		t.Errorf("tournament = %d, %v", v, ok)
+	}
+	if v, ok := in.IntOption("round"); !ok || v != 3 {
+		t.Errorf("round = %d, %v", v, ok)
+	}
+	if _, ok := in.IntOption("missing"); ok {
+		t.Error("missing option found")
+	}
+}
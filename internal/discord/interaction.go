@@ -0,0 +1,72 @@
+package discord
+
+import (
+	"encoding/json"
+	"strconv"
+)
+
+// Interaction and response types used by the slash-command endpoint. See
+// Discord's "Receiving and Responding" documentation.
+const (
+	InteractionPing               = 1
+	InteractionApplicationCommand = 2
+
+	ResponsePong                     = 1
+	ResponseChannelMessageWithSource = 4
+)
+
+// Interaction is the part of an incoming interaction the endpoint reads.
+type Interaction struct {
+	Type int `json:"type"`
+	Data struct {
+		Name    string `json:"name"`
+		Options []struct {
+			Name  string          `json:"name"`
+			Value json.RawMessage `json:"value"`
+		} `json:"options"`
+	} `json:"data"`
+}
+
+// IntOption returns the named integer option of a slash command.
+func (i *Interaction) IntOption(name string) (int64, bool) {
+	for _, o := range i.Data.Options {
+		if o.Name != name {
+			continue
+		}
+		var n json.Number
+		if err := json.Unmarshal(o.Value, &n); err != nil {
+			// Some clients send numbers as strings.
+			var s string
+			if json.Unmarshal(o.Value, &s) != nil {
+				return 0, false
+			}
+			n = json.Number(s)
+		}
+		v, err := strconv.ParseInt(n.String(), 10, 64)
+		return v, err == nil
+	}
+	return 0, false
+}
+
+// Response is an interaction response. Mentions are always disabled.
+type Response struct {
+	Type int           `json:"type"`
+	Data *ResponseData `json:"data,omitempty"`
+}
+
+type ResponseData struct {
+	Content         string          `json:"content"`
+	AllowedMentions allowedMentions `json:"allowed_mentions"`
+}
+
+type allowedMentions struct {
+	Parse []string `json:"parse"`
+}
+
+// Message builds a response that posts content to the channel.
+func Message(content string) Response {
+	return Response{
+		Type: ResponseChannelMessageWithSource,
+		Data: &ResponseData{Content: content, AllowedMentions: allowedMentions{Parse: []string{}}},
+	}
+}
@@ -71,6 +71,20 @@ func (s *Sender) SendEmailVerification(to, verifyURL string) error {
 	return s.send(to, subject, body)
 }
 
+// SendResultReminder asks a player whose match hasn't been reported yet to
+// report it, near the end of the round. The link points to the tournament's
+// detail page.
+func (s *Sender) SendResultReminder(to, tournamentName string, round, table int, tournamentURL string) error {
+	subject := fmt.Sprintf("OpenSwiss — Round %d result needed at table %d", round, table)
+	body := fmt.Sprintf(
+		"Round %d of %q is nearly over, and there is no result yet for your match at table %d.\n\n"+
+			"Please report it to the judges as soon as it is done.\n\n"+
+			"Tournament page: %s",
+		round, tournamentName, table, tournamentURL,
+	)
+	return s.send(to, subject, body)
+}
+
 func (s *Sender) buildMessage(to, subject, body string) []byte {
 	msg := strings.Join([]string{
 		"From: " + s.Config.From,
@@ -0,0 +1,104 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/dstathis/openswiss/internal/models"
+	st "github.com/dstathis/swisstools"
+)
+
+// ErrIncompleteRound is returned by WithTournamentEngine when a change would
+// close a round that still has a match without a result.
+var ErrIncompleteRound = errors.New("a closed round has a match without a result")
+
+// MissingResultsError is returned by AdvanceRound when the current round has
+// matches without a result and the caller didn't force the advance.
+type MissingResultsError struct {
+	Round  int
+	Tables []int
+}
+
+func (e *MissingResultsError) Error() string {
+	tables := make([]string, len(e.Tables))
+	for i, n := range e.Tables {
+		tables[i] = strconv.Itoa(n)
+	}
+	label := "tables"
+	if len(tables) == 1 {
+		label = "table"
+	}
+	return fmt.Sprintf("round %d has no result at %s %s", e.Round, label, strings.Join(tables, ", "))
+}
+
+// MissingTables returns the table numbers of the current round's matches
+// still waiting for a result, in table order.
+func MissingTables(eng *st.Tournament) []int {
+	var out []int
+	for i, p := range eng.GetRound() {
+		if p.PlayerB() != st.BYE_OPPONENT_ID && p.PlayerAWins() == st.UNINITIALIZED_RESULT {
+			out = append(out, TableNumber(i, p))
+		}
+	}
+	return out
+}
+
+// AdvanceRound closes the current round and pairs the next one, or finishes
+// the tournament when the last round is done. It refuses with a
+// *MissingResultsError while matches are unreported, unless force is set, in
+// which case each of them is recorded as a 0-0-1 draw first. A round whose
+// pairings are still a draft can't be closed (ErrPairingsDraft). assigned holds
+// the byes staff assigned, by round (see AssignedByes), clubs the players'
+// clubs (see Clubs) and seeds their tiebreak seeds (see Seeds). It returns the tournament's new status, or ""
+// to keep it.
+func AdvanceRound(eng *st.Tournament, t *models.Tournament, force bool, assigned map[int][]int, clubs map[int]string, seeds map[int]int64) (string, error) {
+	if t.PairingsDraft(eng.GetCurrentRound()) {
+		return "", ErrPairingsDraft
+	}
+	if missing := MissingTables(eng); len(missing) > 0 {
+		if !force {
+			return "", &MissingResultsError{Round: eng.GetCurrentRound(), Tables: missing}
+		}
+		for _, p := range eng.GetRound() {
+			if p.PlayerB() != st.BYE_OPPONENT_ID && p.PlayerAWins() == st.UNINITIALIZED_RESULT {
+				if err := eng.AddResult(p.PlayerA(), 0, 0, 1); err != nil {
+					return "", err
+				}
+			}
+		}
+	}
+	if err := eng.NextRound(); err != nil {
+		return "", err
+	}
+	if eng.GetStatus() == "finished" {
+		return models.TournamentStatusFinished, nil
+	}
+	return "", PairRound(eng, t, false, assigned[eng.GetCurrentRound()], clubs, seeds)
+}
+
+// checkClosedRounds verifies that every round closed since round from, the
+// round that was current when the change started, has a result for each
+// match. The current round counts as closed once the Swiss is finished.
+func checkClosedRounds(eng *st.Tournament, from int) error {
+	if from < 1 {
+		return nil
+	}
+	last := eng.GetCurrentRound() - 1
+	if eng.GetStatus() == "finished" {
+		last++
+	}
+	for r := from; r <= last; r++ {
+		pairings, err := eng.GetRoundByNumber(r)
+		if err != nil {
+			return err
+		}
+		for _, p := range pairings {
+			if p.PlayerB() != st.BYE_OPPONENT_ID && p.PlayerAWins() == st.UNINITIALIZED_RESULT {
+				return fmt.Errorf("round %d: %w", r, ErrIncompleteRound)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package engine
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/models"
+	st "github.com/dstathis/swisstools"
+)
+
+// fourPlayerRound starts a four-player, two-round tournament and reports a
+// result at table 1 only.
+func fourPlayerRound(t *testing.T) *st.Tournament {
+	t.Helper()
+	eng := st.NewTournament()
+	for _, name := range []string{"A", "B", "C", "D"} {
+		if err := eng.AddPlayer(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	eng.SetMaxRounds(2)
+	if err := eng.StartTournament(); err != nil {
+		t.Fatal(err)
+	}
+	if err := eng.AddResult(eng.GetRound()[0].PlayerA(), 2, 0, 0); err != nil {
+		t.Fatal(err)
+	}
+	return &eng
+}
+
+func TestAdvanceRound_Missing(t *testing.T) {
+	eng := fourPlayerRound(t)
+	tm := &models.Tournament{}
+	if got := MissingTables(eng); !reflect.DeepEqual(got, []int{2}) {
+		t.Fatalf("MissingTables = %v, want [2]", got)
+	}
+
+	_, err := AdvanceRound(eng, tm, false, nil, nil, nil)
+	var missing *MissingResultsError
+	if !errors.As(err, &missing) || missing.Round != 1 || !reflect.DeepEqual(missing.Tables, []int{2}) {
+		t.Fatalf("err = %v, want missing results at table 2", err)
+	}
+	if err.Error() != "round 1 has no result at table 2" {
+		t.Errorf("message = %q", err.Error())
+	}
+	if eng.GetCurrentRound() != 1 {
+		t.Errorf("round advanced to %d", eng.GetCurrentRound())
+	}
+}
+
+func TestAdvanceRound_Force(t *testing.T) {
+	eng := fourPlayerRound(t)
+	tm := &models.Tournament{}
+	status, err := AdvanceRound(eng, tm, true, nil, nil, nil)
+	if err != nil || status != "" {
+		t.Fatalf("forced advance: status %q, err %v", status, err)
+	}
+	if eng.GetCurrentRound() != 2 {
+		t.Fatalf("round = %d, want 2", eng.GetCurrentRound())
+	}
+	round1, _ := eng.GetRoundByNumber(1)
+	if p := round1[1]; p.PlayerAWins() != 0 || p.PlayerBWins() != 0 || p.Draws() != 1 {
+		t.Errorf("forced result = %d-%d-%d, want 0-0-1", p.PlayerAWins(), p.PlayerBWins(), p.Draws())
+	}
+	if err := checkClosedRounds(eng, 1); err != nil {
+		t.Errorf("checkClosedRounds after forced advance: %v", err)
+	}
+
+	for _, p := range eng.GetRound() {
+		if err := eng.AddResult(p.PlayerA(), 2, 1, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if status, err = AdvanceRound(eng, tm, false, nil, nil, nil); err != nil || status != models.TournamentStatusFinished {
+		t.Errorf("last round: status %q, err %v", status, err)
+	}
+}
+
+func TestCheckClosedRounds(t *testing.T) {
+	eng := fourPlayerRound(t)
+	if err := checkClosedRounds(eng, 0); err != nil {
+		t.Errorf("before start: %v", err)
+	}
+	if err := checkClosedRounds(eng, 1); err != nil {
+		t.Errorf("open round with a missing result: %v", err)
+	}
+	// Finish properly, then clear a result behind the engine's back, as a
+	// bad save would.
+	u := st.UNINITIALIZED_RESULT
+	if err := eng.AddResult(eng.GetRound()[1].PlayerA(), 1, 0, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := eng.FinishTournament(); err != nil {
+		t.Fatal(err)
+	}
+	if err := eng.AddResult(eng.GetRound()[1].PlayerA(), u, u, u); err != nil {
+		t.Fatal(err)
+	}
+	if err := checkClosedRounds(eng, 1); !errors.Is(err, ErrIncompleteRound) {
+		t.Errorf("finished round with a cleared result: err = %v, want ErrIncompleteRound", err)
+	}
+}
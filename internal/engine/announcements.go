@@ -0,0 +1,62 @@
+package engine
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/dstathis/openswiss/internal/webhook"
+)
+
+// ErrAnnouncementsClosed is returned by Announce for a finished tournament.
+var ErrAnnouncementsClosed = errors.New("a finished tournament can't post announcements")
+
+// Announce posts an announcement to tournament id's players on behalf of
+// user userID: it is shown for minutes from now, or until ended when
+// minutes is 0, and an announcement.posted webhook event is queued with it.
+func Announce(ctx context.Context, database *sql.DB, id, userID int64, message string, minutes int) (*models.Announcement, error) {
+	message = strings.TrimSpace(message)
+	if err := models.ValidateAnnouncement(message, minutes); err != nil {
+		return nil, err
+	}
+	tx, err := database.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+	t, err := db.GetTournamentForUpdate(ctx, tx, id)
+	if err != nil {
+		return nil, fmt.Errorf("get tournament: %w", err)
+	}
+	if t.Status == models.TournamentStatusFinished {
+		return nil, ErrAnnouncementsClosed
+	}
+	now := time.Now().UTC()
+	a := &models.Announcement{TournamentID: id, Message: message, CreatedBy: &userID}
+	if minutes > 0 {
+		expires := now.Add(time.Duration(minutes) * time.Minute)
+		a.ExpiresAt = &expires
+	}
+	if err := db.CreateAnnouncement(ctx, tx, a); err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(webhook.Envelope{
+		Event:      models.WebhookAnnouncementPosted,
+		Tournament: webhook.Tournament{ID: t.ID, Name: t.Name},
+		OccurredAt: now,
+		Data:       webhook.AnnouncementPosted{ID: a.ID, Message: a.Message, ExpiresAt: a.ExpiresAt},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := db.EnqueueWebhookEvent(ctx, tx, t.ID, models.WebhookAnnouncementPosted, body); err != nil {
+		return nil, err
+	}
+	return a, tx.Commit()
+}
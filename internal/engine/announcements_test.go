@@ -0,0 +1,49 @@
+//go:build integration
+
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/dstathis/openswiss/internal/webhook"
+)
+
+func TestAnnounce(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	tourn, _ := setupTournamentWithPlayers(t, database, 2)
+	wh := &models.Webhook{TournamentID: tourn.ID, URL: "https://hooks.example", Secret: "s", Events: []string{models.WebhookAnnouncementPosted}}
+	if err := db.CreateWebhook(ctx, database, wh); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Announce(ctx, database, tourn.ID, tourn.OrganizerID, "  ", 0); err == nil {
+		t.Error("blank announcement accepted")
+	}
+	a, err := Announce(ctx, database, tourn.ID, tourn.OrganizerID, " Round 3 is delayed 10 minutes ", 15)
+	if err != nil || a.Message != "Round 3 is delayed 10 minutes" || a.ExpiresAt == nil {
+		t.Fatalf("Announce = %+v, %v", a, err)
+	}
+	ds, _ := db.ListWebhookDeliveries(ctx, database, tourn.ID, 10)
+	if len(ds) != 1 || ds[0].Event != models.WebhookAnnouncementPosted {
+		t.Fatalf("deliveries = %+v", ds)
+	}
+	var env struct {
+		Data webhook.AnnouncementPosted `json:"data"`
+	}
+	if err := json.Unmarshal(ds[0].Payload, &env); err != nil || env.Data.ID != a.ID || env.Data.Message != a.Message {
+		t.Errorf("payload = %s, %v", ds[0].Payload, err)
+	}
+
+	if err := db.UpdateTournamentStatus(ctx, database, tourn.ID, models.TournamentStatusFinished); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Announce(ctx, database, tourn.ID, tourn.OrganizerID, "Thanks for playing", 0); !errors.Is(err, ErrAnnouncementsClosed) {
+		t.Errorf("finished tournament: err = %v, want ErrAnnouncementsClosed", err)
+	}
+}
@@ -0,0 +1,94 @@
+package engine
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/models"
+	st "github.com/dstathis/swisstools"
+)
+
+// ArchivedResults is how placings are kept in the archive: each player's
+// place, top cut finish and record, with the account and registry entry
+// of the registration behind them, if any.
+func ArchivedResults(placings []Placing, regs []models.Registration) []models.ArchivedResult {
+	regByEngine := make(map[int]models.Registration, len(regs))
+	for _, r := range regs {
+		if r.EnginePlayerID != nil {
+			regByEngine[*r.EnginePlayerID] = r
+		}
+	}
+	out := make([]models.ArchivedResult, len(placings))
+	for i, p := range placings {
+		reg := regByEngine[p.Standing.PlayerID]
+		out[i] = models.ArchivedResult{
+			Place:    p.Place,
+			Name:     p.Standing.Name,
+			Playoff:  p.Playoff,
+			Points:   p.Standing.Points,
+			Wins:     p.Standing.Wins,
+			Losses:   p.Standing.Losses,
+			Draws:    p.Standing.Draws,
+			UserID:   reg.UserID,
+			PlayerID: reg.PlayerID,
+		}
+	}
+	return out
+}
+
+// archive files t in the archive with eng's final standings if it is
+// complete, and takes it out otherwise.
+func archive(ctx context.Context, tx *sql.Tx, t *models.Tournament, eng *st.Tournament) error {
+	if !Complete(t, eng) {
+		return db.UnarchiveTournament(ctx, tx, t.ID)
+	}
+	regs, err := db.ListRegistrations(ctx, tx, t.ID)
+	if err != nil {
+		return err
+	}
+	placings := FinalStandings(eng, t.TiebreakOrder(), TiebreakSeeds(regs))
+	return db.ArchiveTournament(ctx, tx, t.ID, ArchivedResults(placings, regs))
+}
+
+// rearchive brings tournament tournamentID's place in the archive up to
+// date after its state was replaced, as by a restore or rollback.
+func rearchive(ctx context.Context, tx *sql.Tx, tournamentID int64) error {
+	t, err := db.GetTournamentForUpdate(ctx, tx, tournamentID)
+	if err != nil {
+		return err
+	}
+	if len(t.EngineState) == 0 {
+		return db.UnarchiveTournament(ctx, tx, t.ID)
+	}
+	eng, err := st.LoadTournament(t.EngineState)
+	if err != nil {
+		return fmt.Errorf("load engine state: %w", err)
+	}
+	return archive(ctx, tx, t, &eng)
+}
+
+// ArchiveFinished files every finished tournament missing from the archive,
+// such as those finished before it existed. A tournament whose top cut was
+// never played stays out.
+func ArchiveFinished(ctx context.Context, database *sql.DB) error {
+	ids, err := db.ListUnarchivedFinished(ctx, database)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		tx, err := database.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		if err := rearchive(ctx, tx, id); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("tournament %d: %w", id, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+func TestArchivedResults(t *testing.T) {
+	eng := swissDone(t)
+	placings := FinalStandings(eng, nil, nil)
+	userID, playerID := int64(7), int64(9)
+	first := placings[0].Standing.PlayerID
+	regs := []models.Registration{
+		{ID: 1, EnginePlayerID: &first, UserID: &userID, PlayerID: &playerID},
+		{ID: 2}, // never entered the engine
+	}
+
+	got := ArchivedResults(placings, regs)
+	if len(got) != len(placings) {
+		t.Fatalf("got %d results, want %d", len(got), len(placings))
+	}
+	for i, r := range got {
+		s := placings[i].Standing
+		if r.Place != i+1 || r.Name != s.Name || r.Points != s.Points || r.Wins != s.Wins || r.Losses != s.Losses {
+			t.Errorf("result %d = %+v, want placing %+v", i, r, placings[i])
+		}
+	}
+	if got[0].UserID == nil || *got[0].UserID != userID || got[0].PlayerID == nil || *got[0].PlayerID != playerID {
+		t.Errorf("winner = %+v, want user %d and player %d", got[0], userID, playerID)
+	}
+	if got[1].UserID != nil || got[1].PlayerID != nil {
+		t.Errorf("second = %+v, want no account", got[1])
+	}
+}
@@ -0,0 +1,185 @@
+package engine
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/dstathis/openswiss/internal/readonly"
+	st "github.com/dstathis/swisstools"
+)
+
+// ErrInvalidBackup wraps every reason a backup is refused before anything
+// is written.
+var ErrInvalidBackup = errors.New("invalid backup")
+
+// ReadBackup decodes a tournament backup and checks it with CheckBackup.
+func ReadBackup(r io.Reader) (*db.TournamentBackup, error) {
+	var b db.TournamentBackup
+	if err := json.NewDecoder(r).Decode(&b); err != nil {
+		return nil, fmt.Errorf("%w: not an OpenSwiss backup file (%v)", ErrInvalidBackup, err)
+	}
+	if err := CheckBackup(&b); err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// CheckBackup checks that a backup describes a tournament this server can
+// run: a known format version, valid settings, an engine state that loads
+// whenever the tournament has started, and registrations, team rosters,
+// seat results, byes, pairing fields and feature matches that hang
+// together.
+func CheckBackup(b *db.TournamentBackup) error {
+	if err := checkBackup(b); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidBackup, err)
+	}
+	return nil
+}
+
+func checkBackup(b *db.TournamentBackup) error {
+	if b.Version != db.BackupVersion {
+		return fmt.Errorf("unsupported version %d (this server reads version %d)", b.Version, db.BackupVersion)
+	}
+	t := &b.Tournament
+	if strings.TrimSpace(t.Name) == "" {
+		return errors.New("no tournament name")
+	}
+	if err := t.ValidatePlayerLimits(); err != nil {
+		return err
+	}
+	if err := t.ValidateMatchFormat(); err != nil {
+		return err
+	}
+	if t.TimeZone != "" {
+		if err := t.ValidateTimeZone(); err != nil {
+			return err
+		}
+	}
+	if err := models.ValidateSchedule(b.Schedule); err != nil {
+		return err
+	}
+	if !models.ValidPairingAlgorithm(t.PairingAlgorithm) || !models.ValidByePolicy(t.ByePolicy) {
+		return errors.New("unknown pairing algorithm or bye policy")
+	}
+	if t.Round1Pairing != "" && !models.ValidRound1Pairing(t.Round1Pairing) {
+		return errors.New("unknown round 1 pairing")
+	}
+	if err := t.ValidateTiebreakers(); err != nil {
+		return err
+	}
+
+	var eng *st.Tournament
+	switch t.Status {
+	case models.TournamentStatusScheduled, models.TournamentStatusRegistrationOpen:
+		if len(b.EngineState) > 0 {
+			return errors.New("engine state for a tournament that hasn't started")
+		}
+	case models.TournamentStatusInProgress, models.TournamentStatusPlayoff, models.TournamentStatusFinished:
+		loaded, err := st.LoadTournament(b.EngineState)
+		if err != nil {
+			return fmt.Errorf("engine state doesn't load: %w", err)
+		}
+		eng = &loaded
+	default:
+		return fmt.Errorf("unknown tournament status %q", t.Status)
+	}
+
+	regs := map[int64]bool{}
+	names := map[string]bool{}
+	emails := map[string]bool{}
+	players := map[int]bool{}
+	for _, r := range b.Registrations {
+		name := strings.ToLower(strings.TrimSpace(r.DisplayName))
+		switch {
+		case regs[r.ID]:
+			return fmt.Errorf("registration %d appears twice", r.ID)
+		case name == "" || names[name]:
+			return fmt.Errorf("blank or repeated player name %q", r.DisplayName)
+		case r.UserEmail != "" && emails[r.UserEmail]:
+			return fmt.Errorf("%s is registered twice", r.UserEmail)
+		}
+		switch r.Status {
+		case models.RegistrationStatusPending, models.RegistrationStatusConfirmed, models.RegistrationStatusDropped, models.RegistrationStatusWaitlisted:
+		default:
+			return fmt.Errorf("registration %q has unknown status %q", r.DisplayName, r.Status)
+		}
+		if r.PaymentStatus != "" && !models.ValidPaymentStatus(r.PaymentStatus) {
+			return fmt.Errorf("registration %q has unknown payment status %q", r.DisplayName, r.PaymentStatus)
+		}
+		if r.EnginePlayerID != nil {
+			if _, ok := engPlayer(eng, *r.EnginePlayerID); !ok || players[*r.EnginePlayerID] {
+				return fmt.Errorf("registration %q points at no player, or a player taken, in the engine", r.DisplayName)
+			}
+			players[*r.EnginePlayerID] = true
+		}
+		if len(r.Members) > 0 && len(r.Members) != t.TeamSize {
+			return fmt.Errorf("registration %q has %d team members in an event with teams of %d", r.DisplayName, len(r.Members), t.TeamSize)
+		}
+		regs[r.ID], names[name] = true, true
+		if r.UserEmail != "" {
+			emails[r.UserEmail] = true
+		}
+	}
+	for _, bye := range b.AssignedByes {
+		if !regs[bye.RegistrationID] || bye.Round < 1 {
+			return fmt.Errorf("assigned bye for registration %d in round %d doesn't match a registration", bye.RegistrationID, bye.Round)
+		}
+	}
+	for _, sr := range b.SeatResults {
+		if sr.Seat < 1 || sr.Seat > t.TeamSize {
+			return fmt.Errorf("seat result for seat %d in an event with teams of %d", sr.Seat, t.TeamSize)
+		}
+	}
+	featured := map[db.BackupFeatureMatch]bool{}
+	for _, f := range b.Features {
+		if f.Round < 1 || f.Table < 1 || featured[f] {
+			return fmt.Errorf("invalid or repeated feature match at table %d in round %d", f.Table, f.Round)
+		}
+		featured[f] = true
+	}
+	labels := map[string]bool{}
+	for _, f := range b.PairingFields {
+		if !models.ValidPairingFieldLabel(f.Label) || labels[f.Label] {
+			return fmt.Errorf("invalid or repeated pairing field %q", f.Label)
+		}
+		labels[f.Label] = true
+	}
+	return nil
+}
+
+// engPlayer looks a player up in eng, which is nil before the start.
+func engPlayer(eng *st.Tournament, id int) (st.Player, bool) {
+	if eng == nil {
+		return st.Player{}, false
+	}
+	return eng.GetPlayerById(id)
+}
+
+// RestoreBackup checks a backup and restores it: into a new tournament
+// organized by organizerID, or over tournament replaceID when that is
+// non-zero (see db.RestoreTournamentBackup). It returns the tournament's ID.
+func RestoreBackup(ctx context.Context, database *sql.DB, b *db.TournamentBackup, organizerID, replaceID int64) (id int64, err error) {
+	if err := CheckBackup(b); err != nil {
+		return 0, err
+	}
+	defer func() { readonly.Report(ctx, err) }()
+	tx, err := database.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+	if id, err = db.RestoreTournamentBackup(ctx, tx, b, organizerID, replaceID); err != nil {
+		return 0, err
+	}
+	if err := rearchive(ctx, tx, id); err != nil {
+		return 0, fmt.Errorf("archive: %w", err)
+	}
+	return id, tx.Commit()
+}
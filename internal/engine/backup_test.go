@@ -0,0 +1,109 @@
+package engine
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+// startedBackup returns a valid backup of a four-player tournament in round
+// 2, with a registration for each engine player and one pending sign-up.
+func startedBackup(t *testing.T) *db.TournamentBackup {
+	t.Helper()
+	eng := playedEngine(t, 4)
+	state, err := eng.DumpTournament()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := &db.TournamentBackup{
+		Version: db.BackupVersion,
+		Tournament: models.Tournament{
+			Name: "Backed Up", PointsWin: 3, PointsDraw: 1, MinPlayers: 2,
+			PairingAlgorithm: models.PairingSwiss, ByePolicy: models.ByeLowest,
+			Status: models.TournamentStatusInProgress,
+		},
+		EngineState: state,
+	}
+	for id, p := range eng.GetPlayers() {
+		pid := id
+		b.Registrations = append(b.Registrations, db.BackupRegistration{
+			ID: int64(100 + id), DisplayName: p.Name, Status: models.RegistrationStatusConfirmed, EnginePlayerID: &pid,
+		})
+	}
+	b.Registrations = append(b.Registrations, db.BackupRegistration{
+		ID: 1, UserEmail: "late@example.com", DisplayName: "Late", Status: models.RegistrationStatusPending,
+	})
+	b.AssignedByes = []db.BackupBye{{Round: 3, RegistrationID: 1}}
+	b.PairingFields = []db.BackupPairingField{{Label: "Stream", Values: []db.BackupFieldValue{{Round: 1, Table: 1, Value: "yes"}}}}
+	b.Features = []db.BackupFeatureMatch{{Round: 2, Table: 1}}
+	return b
+}
+
+func TestCheckBackup(t *testing.T) {
+	if err := CheckBackup(startedBackup(t)); err != nil {
+		t.Fatalf("valid backup refused: %v", err)
+	}
+
+	for name, spoil := range map[string]func(b *db.TournamentBackup){
+		"version":          func(b *db.TournamentBackup) { b.Version = 99 },
+		"no name":          func(b *db.TournamentBackup) { b.Tournament.Name = " " },
+		"bad status":       func(b *db.TournamentBackup) { b.Tournament.Status = "paused" },
+		"no engine":        func(b *db.TournamentBackup) { b.EngineState = nil },
+		"engine pre-start": func(b *db.TournamentBackup) { b.Tournament.Status = models.TournamentStatusScheduled },
+		"bad algorithm":    func(b *db.TournamentBackup) { b.Tournament.PairingAlgorithm = "coin" },
+		"same name": func(b *db.TournamentBackup) {
+			b.Registrations[1].DisplayName = strings.ToUpper(b.Registrations[0].DisplayName)
+		},
+		"same id": func(b *db.TournamentBackup) { b.Registrations[1].ID = b.Registrations[0].ID },
+		"same email": func(b *db.TournamentBackup) {
+			b.Registrations[0].UserEmail = "late@example.com"
+		},
+		"reg status":     func(b *db.TournamentBackup) { b.Registrations[0].Status = "maybe" },
+		"payment status": func(b *db.TournamentBackup) { b.Registrations[0].PaymentStatus = "owed" },
+		"same engine player": func(b *db.TournamentBackup) {
+			b.Registrations[1].EnginePlayerID = b.Registrations[0].EnginePlayerID
+		},
+		"unknown engine player": func(b *db.TournamentBackup) {
+			id := 999
+			b.Registrations[0].EnginePlayerID = &id
+		},
+		"bye for nobody": func(b *db.TournamentBackup) { b.AssignedByes[0].RegistrationID = 2 },
+		"field label":    func(b *db.TournamentBackup) { b.PairingFields[0].Label = "" },
+		"repeated feature match": func(b *db.TournamentBackup) {
+			b.Features = append(b.Features, b.Features[0])
+		},
+		"roster outside team event": func(b *db.TournamentBackup) {
+			b.Registrations[0].Members = []string{"One", "Two", "Three"}
+		},
+		"seat outside team event": func(b *db.TournamentBackup) {
+			b.SeatResults = []models.SeatResult{{Round: 1, Table: 1, Seat: 1, Winner: models.GameWinnerA}}
+		},
+	} {
+		b := startedBackup(t)
+		spoil(b)
+		if err := CheckBackup(b); !errors.Is(err, ErrInvalidBackup) {
+			t.Errorf("%s: err = %v, want ErrInvalidBackup", name, err)
+		}
+	}
+}
+
+func TestReadBackup(t *testing.T) {
+	data, err := json.Marshal(startedBackup(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := ReadBackup(strings.NewReader(string(data)))
+	if err != nil {
+		t.Fatalf("ReadBackup: %v", err)
+	}
+	if b.Tournament.Name != "Backed Up" || len(b.Registrations) != 5 || len(b.EngineState) == 0 {
+		t.Errorf("round trip lost data: %+v", b)
+	}
+	if _, err := ReadBackup(strings.NewReader("PK\x03\x04")); !errors.Is(err, ErrInvalidBackup) {
+		t.Errorf("non-JSON upload: err = %v", err)
+	}
+}
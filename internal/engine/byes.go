@@ -0,0 +1,147 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/models"
+	st "github.com/dstathis/swisstools"
+)
+
+// activePlayers counts the players still in the tournament.
+func activePlayers(eng *st.Tournament) int {
+	n := 0
+	for _, p := range eng.GetPlayers() {
+		if !p.Removed {
+			n++
+		}
+	}
+	return n
+}
+
+// priorByes counts the byes each player received before the current round.
+func priorByes(eng *st.Tournament) map[int]int {
+	out := make(map[int]int)
+	for r := 1; r < eng.GetCurrentRound(); r++ {
+		round, err := eng.GetRoundByNumber(r)
+		if err != nil {
+			continue
+		}
+		for _, p := range round {
+			if p.PlayerB() == st.BYE_OPPONENT_ID {
+				out[p.PlayerA()]++
+			}
+		}
+	}
+	return out
+}
+
+// chooseByes returns the players who sit out the current round: the
+// assigned ones, then, if that leaves an odd number to pair, one more picked
+// among the players with the fewest byes so far. Under ByeLowest that is the
+// lowest in the standings (in a seeded round 1, the lowest seed), under
+// ByeRandom any of them; exact ties are broken at random either way.
+func chooseByes(eng *st.Tournament, t *models.Tournament, assigned []int) []int {
+	byes := append([]int(nil), assigned...)
+	skip := make(map[int]bool, len(assigned))
+	for _, id := range assigned {
+		skip[id] = true
+	}
+	players := eng.GetPlayers()
+	var pool []st.PlayerStanding
+	for _, s := range eng.GetStandings() {
+		if p, ok := players[s.PlayerID]; ok && !p.Removed && !skip[s.PlayerID] {
+			pool = append(pool, s)
+		}
+	}
+	if len(pool)%2 == 0 {
+		return byes
+	}
+
+	counts := priorByes(eng)
+	rand.Shuffle(len(pool), func(i, j int) { pool[i], pool[j] = pool[j], pool[i] })
+	below := func(a, b st.PlayerStanding) bool {
+		if counts[a.PlayerID] != counts[b.PlayerID] {
+			return counts[a.PlayerID] < counts[b.PlayerID]
+		}
+		if t.ByePolicy == models.ByeRandom {
+			return false
+		}
+		if seeded(t) && eng.GetCurrentRound() == 1 {
+			// Seeds follow engine player IDs; see InitTournamentEngine.
+			return a.PlayerID > b.PlayerID
+		}
+		if a.Points != b.Points {
+			return a.Points < b.Points
+		}
+		for _, name := range t.TiebreakOrder() {
+			if va, vb := TiebreakValue(name, a.Tiebreakers), TiebreakValue(name, b.Tiebreakers); va != vb {
+				return va < vb
+			}
+		}
+		return false
+	}
+	pick := 0
+	for i := range pool {
+		if below(pool[i], pool[pick]) {
+			pick = i
+		}
+	}
+	return append(byes, pool[pick].PlayerID)
+}
+
+// AssignedByes returns the byes staff assigned for each round of a started
+// tournament, as engine player IDs keyed by round. Players no longer in the
+// tournament are left out.
+func AssignedByes(ctx context.Context, tx db.DBTX, t *models.Tournament, eng *st.Tournament) (map[int][]int, error) {
+	list, err := db.ListAssignedByes(ctx, tx, t.ID)
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[int][]int)
+	for _, b := range list {
+		if b.EnginePlayerID == nil {
+			continue
+		}
+		if p, ok := eng.GetPlayerById(*b.EnginePlayerID); !ok || p.Removed {
+			continue
+		}
+		out[b.Round] = append(out[b.Round], *b.EnginePlayerID)
+	}
+	return out, nil
+}
+
+// CheckAssignedBye reports why reg can't be given a bye in round, or nil if
+// it can. Byes can be assigned from round 1 before the start and from the
+// current round on while the Swiss runs; one for the current round takes
+// effect when the round is re-paired.
+func CheckAssignedBye(t *models.Tournament, reg *models.Registration, round int) error {
+	if reg == nil || reg.TournamentID != t.ID {
+		return errors.New("player is not registered for this tournament")
+	}
+	if reg.Status != models.RegistrationStatusConfirmed {
+		return errors.New("only confirmed players can be given a bye")
+	}
+	if t.Status == models.TournamentStatusPlayoff || t.Status == models.TournamentStatusFinished {
+		return errors.New("the Swiss rounds are over")
+	}
+	if round < 1 {
+		return errors.New("round must be at least 1")
+	}
+	if t.NumRounds != nil && *t.NumRounds > 0 && round > *t.NumRounds {
+		return fmt.Errorf("the tournament has only %d rounds", *t.NumRounds)
+	}
+	if len(t.EngineState) > 0 {
+		eng, err := st.LoadTournament(t.EngineState)
+		if err != nil {
+			return fmt.Errorf("load engine state: %w", err)
+		}
+		if round < eng.GetCurrentRound() {
+			return fmt.Errorf("round %d has already been played", round)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,192 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/models"
+	st "github.com/dstathis/swisstools"
+)
+
+// byesOf returns the players with a bye in the current round.
+func byesOf(eng *st.Tournament) []int {
+	var out []int
+	for _, p := range eng.GetRound() {
+		if p.PlayerB() == st.BYE_OPPONENT_ID {
+			out = append(out, p.PlayerA())
+		}
+	}
+	return out
+}
+
+// checkSeated fails unless every active player is in the current round
+// exactly once.
+func checkSeated(t *testing.T, eng *st.Tournament) {
+	t.Helper()
+	seen := map[int]int{}
+	for _, p := range eng.GetRound() {
+		seen[p.PlayerA()]++
+		if p.PlayerB() != st.BYE_OPPONENT_ID {
+			seen[p.PlayerB()]++
+		}
+	}
+	for id, p := range eng.GetPlayers() {
+		if !p.Removed && seen[id] != 1 {
+			t.Errorf("player %d seated %d times", id, seen[id])
+		}
+	}
+}
+
+func TestPairRound_ByeGoesToLowest(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		eng := playedEngine(t, 9)
+		if err := PairRound(eng, &models.Tournament{ByePolicy: models.ByeLowest}, false, nil, nil, nil); err != nil {
+			t.Fatal(err)
+		}
+		byes := byesOf(eng)
+		if len(byes) != 1 {
+			t.Fatalf("byes = %v, want one", byes)
+		}
+		if p, _ := eng.GetPlayerById(byes[0]); p.Points != 0 {
+			t.Fatalf("bye went to player %d with %d points", byes[0], p.Points)
+		}
+	}
+}
+
+func TestPairRound_ByesRotate(t *testing.T) {
+	for _, policy := range []string{models.ByeLowest, models.ByeRandom} {
+		tourn := &models.Tournament{ByePolicy: policy}
+		eng := st.NewTournament()
+		for _, name := range []string{"A", "B", "C", "D", "E"} {
+			if err := eng.AddPlayer(name); err != nil {
+				t.Fatal(err)
+			}
+		}
+		eng.SetMaxRounds(6)
+		if err := eng.StartTournament(); err != nil {
+			t.Fatal(err)
+		}
+		got := map[int]int{}
+		for round := 1; round <= 5; round++ {
+			if round > 1 {
+				if err := PairRound(&eng, tourn, false, nil, nil, nil); err != nil {
+					t.Fatal(err)
+				}
+			}
+			for _, id := range byesOf(&eng) {
+				got[id]++
+			}
+			for _, p := range eng.GetRound() {
+				if p.PlayerB() != st.BYE_OPPONENT_ID {
+					if err := eng.AddResult(p.PlayerA(), 2, 0, 0); err != nil {
+						t.Fatal(err)
+					}
+				}
+			}
+			if err := eng.NextRound(); err != nil {
+				t.Fatal(err)
+			}
+		}
+		for id := range eng.GetPlayers() {
+			if got[id] != 1 {
+				t.Errorf("%s: player %d had %d byes in five rounds, want 1", policy, id, got[id])
+			}
+		}
+	}
+}
+
+func TestPairRound_AssignedByes(t *testing.T) {
+	eng := playedEngine(t, 9)
+	var ids []int
+	for id := range eng.GetPlayers() {
+		ids = append(ids, id)
+	}
+	tourn := &models.Tournament{Status: models.TournamentStatusInProgress}
+
+	// One assigned bye leaves eight to pair, so nobody else sits out.
+	if err := PairRound(eng, tourn, false, ids[:1], nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if byes := byesOf(eng); len(byes) != 1 || byes[0] != ids[0] {
+		t.Errorf("byes = %v, want [%d]", byes, ids[0])
+	}
+	checkSeated(t, eng)
+
+	// Two leave seven, so one more player gets a bye too.
+	if err := PairRound(eng, tourn, true, ids[:2], nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	byes := map[int]bool{}
+	for _, id := range byesOf(eng) {
+		byes[id] = true
+	}
+	if len(byes) != 3 || !byes[ids[0]] || !byes[ids[1]] {
+		t.Errorf("byes = %v, want %d, %d and one more", byes, ids[0], ids[1])
+	}
+	checkSeated(t, eng)
+	for i, p := range eng.GetRound() {
+		if p.PlayerB() == st.BYE_OPPONENT_ID && TableNumber(i, p) != 0 {
+			t.Errorf("bye at table %d", TableNumber(i, p))
+		}
+	}
+
+	enc := EncodeRound(eng.GetRound())
+	if _, err := decodeRound(eng, enc, 2); err != nil {
+		t.Errorf("round with two assigned byes doesn't decode: %v", err)
+	}
+	if _, err := decodeRound(eng, enc, 0); err == nil {
+		t.Error("three byes accepted with none assigned")
+	}
+
+	// The preview honours the round's assignments.
+	proposed, err := PreviewRepair(tourn, eng, map[int][]int{eng.GetCurrentRound(): ids[:2]}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(byesOf(proposed)); n != 3 {
+		t.Errorf("preview has %d byes, want 3", n)
+	}
+}
+
+func TestCheckAssignedBye(t *testing.T) {
+	rounds := 3
+	tourn := &models.Tournament{ID: 1, Status: models.TournamentStatusRegistrationOpen, NumRounds: &rounds}
+	reg := &models.Registration{ID: 7, TournamentID: 1, Status: models.RegistrationStatusConfirmed}
+	if err := CheckAssignedBye(tourn, reg, 1); err != nil {
+		t.Errorf("round 1 before the start: %v", err)
+	}
+
+	eng := playedEngine(t, 4)
+	data, err := eng.DumpTournament()
+	if err != nil {
+		t.Fatal(err)
+	}
+	started := *tourn
+	started.Status, started.EngineState = models.TournamentStatusInProgress, data
+	if err := CheckAssignedBye(&started, reg, 2); err != nil {
+		t.Errorf("current round: %v", err)
+	}
+
+	pending := *reg
+	pending.Status = models.RegistrationStatusPending
+	other := *reg
+	other.TournamentID = 2
+	finished := started
+	finished.Status = models.TournamentStatusFinished
+	for name, c := range map[string]struct {
+		t     *models.Tournament
+		reg   *models.Registration
+		round int
+	}{
+		"no registration": {tourn, nil, 1},
+		"other event":     {tourn, &other, 1},
+		"pending":         {tourn, &pending, 1},
+		"round 0":         {tourn, reg, 0},
+		"past the end":    {tourn, reg, 4},
+		"played round":    {&started, reg, 1},
+		"finished":        {&finished, reg, 3},
+	} {
+		if err := CheckAssignedBye(c.t, c.reg, c.round); err == nil {
+			t.Errorf("%s: accepted", name)
+		}
+	}
+}
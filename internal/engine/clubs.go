@@ -0,0 +1,120 @@
+package engine
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/dstathis/openswiss/internal/pairing"
+	st "github.com/dstathis/swisstools"
+)
+
+// CheckClub trims a club as staff typed it and checks its length. An empty
+// club comes back nil.
+func CheckClub(club *string) (*string, error) {
+	if club == nil {
+		return nil, nil
+	}
+	c := strings.TrimSpace(*club)
+	if c == "" {
+		return nil, nil
+	}
+	if utf8.RuneCountInString(c) > models.MaxClubName {
+		return nil, fmt.Errorf("clubs may be at most %d characters", models.MaxClubName)
+	}
+	return &c, nil
+}
+
+// SetClubs sets players' clubs, keyed by registration ID; a nil or empty
+// club clears one. Clubs can change until the tournament finishes and count
+// from the next round paired. Registrations of another tournament are
+// refused and nothing is changed.
+func SetClubs(ctx context.Context, database *sql.DB, tournamentID int64, clubs map[int64]*string) error {
+	checked := make(map[int64]*string, len(clubs))
+	for id, c := range clubs {
+		club, err := CheckClub(c)
+		if err != nil {
+			return err
+		}
+		checked[id] = club
+	}
+	tx, err := database.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+	t, err := db.GetTournamentForUpdate(ctx, tx, tournamentID)
+	if err != nil {
+		return fmt.Errorf("get tournament: %w", err)
+	}
+	if t.Status == models.TournamentStatusFinished {
+		return errors.New("clubs can't be changed once the tournament is finished")
+	}
+	if err := db.SetClubs(ctx, tx, tournamentID, checked); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return errors.New("player is not registered for this tournament")
+		}
+		return err
+	}
+	return tx.Commit()
+}
+
+// ParseClubs reads clubs pasted or typed as one player per line, "name,
+// club", the way ParseRatings reads ratings. A line without a club clears
+// it.
+func ParseClubs(text string, regs []models.Registration) (map[int64]*string, error) {
+	return parseByName(text, regs, func(value string) (string, error) {
+		if utf8.RuneCountInString(value) > models.MaxClubName {
+			return "", fmt.Errorf("%q is longer than %d characters", value, models.MaxClubName)
+		}
+		return value, nil
+	})
+}
+
+// clubKey is how clubs are compared: "North CC" and "north cc " are the
+// same club.
+func clubKey(club string) string {
+	return strings.ToLower(strings.TrimSpace(club))
+}
+
+// Clubs returns the clubs of t's players, keyed by engine player ID, for
+// PairRound; nil when t doesn't keep club mates apart. Players without a
+// club are left out.
+func Clubs(ctx context.Context, tx db.DBTX, t *models.Tournament) (map[int]string, error) {
+	if t.AvoidClubRounds == 0 {
+		return nil, nil
+	}
+	clubs, err := db.TournamentClubs(ctx, tx, t.ID)
+	if err != nil {
+		return nil, err
+	}
+	for id, club := range clubs {
+		clubs[id] = clubKey(club)
+	}
+	return clubs, nil
+}
+
+// keepsClubsApart reports whether t keeps club mates apart in round.
+func keepsClubsApart(t *models.Tournament, round int, clubs map[int]string) bool {
+	return round <= t.AvoidClubRounds && len(clubs) > 0
+}
+
+// withClubs fills in each player's club from clubs.
+func withClubs(players []pairing.Player, clubs map[int]string) {
+	for i := range players {
+		players[i].Club = clubs[players[i].ID]
+	}
+}
+
+// avoidClubs breaks up the club mates pairs holds by swapping opponents
+// (see pairing.AvoidClubs), seeing eng's players as the pairer would.
+func avoidClubs(eng *st.Tournament, pairs []pairing.Pair, clubs map[int]string) []pairing.Pair {
+	players := pairingPlayers(eng)
+	withClubs(players, clubs)
+	return pairing.AvoidClubs(players, pairs)
+}
@@ -0,0 +1,97 @@
+package engine
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/models"
+	st "github.com/dstathis/swisstools"
+)
+
+func TestParseClubs(t *testing.T) {
+	regs := []models.Registration{{ID: 1, DisplayName: "Alice"}, {ID: 2, DisplayName: "Bob"}}
+	got, err := ParseClubs("alice, North CC\nBob;\n", regs)
+	if err != nil {
+		t.Fatalf("ParseClubs: %v", err)
+	}
+	if len(got) != 2 || *got[1] != "North CC" || got[2] != nil {
+		t.Errorf("clubs = %v", got)
+	}
+	long := strings.Repeat("x", models.MaxClubName+1)
+	if _, err := ParseClubs("Alice, "+long, regs); err == nil || !strings.Contains(err.Error(), "line 1") {
+		t.Errorf("long club: err = %v", err)
+	}
+}
+
+func TestCheckClub(t *testing.T) {
+	blank, padded := "  ", " North CC "
+	if c, err := CheckClub(&blank); c != nil || err != nil {
+		t.Errorf("blank club = %v, %v; want nil", c, err)
+	}
+	if c, err := CheckClub(&padded); err != nil || *c != "North CC" {
+		t.Errorf("padded club = %v, %v", c, err)
+	}
+}
+
+// clubEngine is round 2 of an eight-player event, paired from the round 1
+// winners and losers, with two winners and two losers in club "a".
+func clubEngine(t *testing.T) (*st.Tournament, map[int]string) {
+	t.Helper()
+	eng := playedEngine(t, 8)
+	clubs := map[int]string{}
+	var winners, losers int
+	for id, p := range eng.GetPlayers() {
+		if p.Points > 0 && winners < 2 {
+			clubs[id] = "a"
+			winners++
+		} else if p.Points == 0 && losers < 2 {
+			clubs[id] = "a"
+			losers++
+		}
+	}
+	return eng, clubs
+}
+
+// clubMates counts the pairings of eng's current round between players of
+// the same club.
+func clubMates(eng *st.Tournament, clubs map[int]string) int {
+	n := 0
+	for _, p := range eng.GetRound() {
+		if c := clubs[p.PlayerA()]; c != "" && c == clubs[p.PlayerB()] {
+			n++
+		}
+	}
+	return n
+}
+
+func TestPairRound_AvoidsClubMates(t *testing.T) {
+	for _, alg := range []string{models.PairingSwiss, models.PairingWeighted, models.PairingDanish} {
+		for i := 0; i < 20; i++ {
+			eng, clubs := clubEngine(t)
+			tourn := &models.Tournament{PairingAlgorithm: alg, AvoidClubRounds: 2}
+			if err := PairRound(eng, tourn, false, nil, clubs, nil); err != nil {
+				t.Fatal(err)
+			}
+			checkSeated(t, eng)
+			if n := clubMates(eng, clubs); n > 0 {
+				t.Fatalf("%s: %d pairings of club mates: %v", alg, n, eng.GetRound())
+			}
+		}
+	}
+}
+
+func TestPairRound_ClubsOnlyEarly(t *testing.T) {
+	// Past AvoidClubRounds clubs are ignored, so in enough tries the
+	// shuffle within score groups pairs club mates.
+	met := false
+	for i := 0; i < 50 && !met; i++ {
+		eng, clubs := clubEngine(t)
+		if err := PairRound(eng, &models.Tournament{AvoidClubRounds: 1}, false, nil, clubs, nil); err != nil {
+			t.Fatal(err)
+		}
+		met = clubMates(eng, clubs) > 0
+	}
+	if !met {
+		t.Error("club mates never met in round 2 with clubs kept apart in round 1 only")
+	}
+}
@@ -0,0 +1,78 @@
+package engine
+
+import (
+	"github.com/dstathis/openswiss/internal/pairing"
+	st "github.com/dstathis/swisstools"
+)
+
+// colorsThrough returns the colours each player had in rounds 1 to last,
+// oldest first: player A of a pairing played white, player B black. Byes
+// have no colour and are skipped.
+func colorsThrough(eng *st.Tournament, last int) map[int][]pairing.Color {
+	colors := make(map[int][]pairing.Color)
+	for r := 1; r <= last; r++ {
+		round, err := eng.GetRoundByNumber(r)
+		if err != nil {
+			continue
+		}
+		for _, p := range round {
+			if p.PlayerB() == st.BYE_OPPONENT_ID {
+				continue
+			}
+			colors[p.PlayerA()] = append(colors[p.PlayerA()], pairing.White)
+			colors[p.PlayerB()] = append(colors[p.PlayerB()], pairing.Black)
+		}
+	}
+	return colors
+}
+
+// ColorHistory returns each player's colours through the current round as
+// a string such as "WBW", for the standings. Players who have only had byes
+// are left out.
+func ColorHistory(eng *st.Tournament) map[int]string {
+	out := make(map[int]string)
+	for id, colors := range colorsThrough(eng, eng.GetCurrentRound()) {
+		b := make([]byte, len(colors))
+		for i, c := range colors {
+			b[i] = byte(c)
+		}
+		out[id] = string(b)
+	}
+	return out
+}
+
+// allocateColors turns every match in pairs so that player A is the one who
+// plays white, by pairing.Orient on the colours of the rounds before the
+// current one. The player with more points is passed first, and the
+// fallback alternates from match to match so a first round splits evenly.
+func allocateColors(eng *st.Tournament, pairs []pairing.Pair) {
+	colors := colorsThrough(eng, eng.GetCurrentRound()-1)
+	points := make(map[int]int)
+	for id, p := range eng.GetPlayers() {
+		points[id] = p.Points
+	}
+	board := 0
+	for i, pr := range pairs {
+		if pr.B == pairing.Bye {
+			continue
+		}
+		a, b := pr.A, pr.B
+		if points[b] > points[a] || (points[b] == points[a] && b < a) {
+			a, b = b, a
+		}
+		pairs[i] = pairing.Orient(
+			pairing.Player{ID: a, Colors: colors[a]},
+			pairing.Player{ID: b, Colors: colors[b]},
+			board%2 == 0)
+		board++
+	}
+}
+
+// withColors sets each player's colour history for the pairer, so it can
+// keep apart players who both have to get the same colour.
+func withColors(eng *st.Tournament, players []pairing.Player) {
+	colors := colorsThrough(eng, eng.GetCurrentRound()-1)
+	for i := range players {
+		players[i].Colors = colors[players[i].ID]
+	}
+}
@@ -0,0 +1,70 @@
+package engine
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/models"
+	st "github.com/dstathis/swisstools"
+)
+
+func TestPairRound_Colors(t *testing.T) {
+	tourn := &models.Tournament{PairingAlgorithm: models.PairingWeighted, Colors: true}
+	eng := playedEngine(t, 10)
+
+	for round := 2; round <= 5; round++ {
+		if err := PairRound(eng, tourn, false, nil, nil, nil); err != nil {
+			t.Fatalf("round %d: pair: %v", round, err)
+		}
+		for i, p := range eng.GetRound() {
+			if err := eng.AddResult(p.PlayerA(), 2-i%2*2, i%2*2, 0); err != nil {
+				t.Fatalf("add result: %v", err)
+			}
+		}
+		if err := eng.NextRound(); err != nil {
+			t.Fatalf("round %d: next round: %v", round, err)
+		}
+	}
+
+	hist := ColorHistory(eng)
+	if len(hist) != 10 {
+		t.Fatalf("colour histories for %d players, want 10", len(hist))
+	}
+	for id, h := range hist {
+		if len(h) != 5 {
+			t.Errorf("player %d: colours %q, want 5 games", id, h)
+		}
+		if strings.Contains(h, "WWW") || strings.Contains(h, "BBB") {
+			t.Errorf("player %d: same colour three times running: %q", id, h)
+		}
+		if w, b := strings.Count(h, "W"), strings.Count(h, "B"); w-b > 2 || b-w > 2 {
+			t.Errorf("player %d: colours %q are out of balance", id, h)
+		}
+	}
+}
+
+func TestAllocateColors_FirstRoundSplits(t *testing.T) {
+	eng := st.NewTournament()
+	for _, name := range []string{"A", "B", "C", "D", "E", "F"} {
+		if err := eng.AddPlayer(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := eng.StartTournament(); err != nil {
+		t.Fatal(err)
+	}
+	if err := PairRound(&eng, &models.Tournament{Colors: true}, true, nil, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	// Nobody has a colour yet, so white alternates between the lower and
+	// the higher ID from board to board.
+	lower := 0
+	for _, p := range eng.GetRound() {
+		if p.PlayerA() < p.PlayerB() {
+			lower++
+		}
+	}
+	if lower != 2 {
+		t.Errorf("lower ID has white on %d of 3 boards, want 2", lower)
+	}
+}
@@ -0,0 +1,149 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/models"
+	st "github.com/dstathis/swisstools"
+)
+
+// CorrectResult replaces the result of the match at table in round, a Swiss
+// round that has already closed, with wins, losses and draws from player
+// A's side. swisstools folds a round's results into its players' totals
+// when the round closes, so the old match is taken out of both players'
+// points, match record and game record and the new one put in; the
+// standings follow from there. Later rounds keep their pairings: the
+// correction is recorded with the rounds paired on the old result, for
+// staff to review. It is refused for the current round (enter the result as
+// usual), outside the Swiss rounds, for series and team matches, whose
+// results come from their games and seats, and when nothing changes.
+func CorrectResult(ctx context.Context, tx db.DBTX, t *models.Tournament, eng *st.Tournament, round, table, wins, losses, draws int, correctedBy *int64) (*models.ResultCorrection, error) {
+	if t.Status != models.TournamentStatusInProgress {
+		return nil, errors.New("results can only be corrected during the Swiss rounds")
+	}
+	current := eng.GetCurrentRound()
+	if round < 1 || round >= current {
+		return nil, fmt.Errorf("round %d is not a closed round; enter current round results as usual", round)
+	}
+	if t.SeriesMode || t.TeamSize > 0 {
+		return nil, errors.New("series and team results come from their games and seats; roll back to a snapshot to change them")
+	}
+	if wins < 0 || losses < 0 || draws < 0 || wins+losses+draws == 0 {
+		return nil, errors.New("enter the number of games each player won")
+	}
+	if err := CheckScore(t, wins, losses, draws); err != nil {
+		return nil, err
+	}
+
+	old, err := correctMatch(eng, round, table, score{wins, losses, draws})
+	if err != nil {
+		return nil, err
+	}
+	c := &models.ResultCorrection{
+		TournamentID:  t.ID,
+		Round:         round,
+		Table:         table,
+		OldA:          old.won,
+		OldB:          old.lost,
+		OldDraws:      old.drawn,
+		NewA:          wins,
+		NewB:          losses,
+		NewDraws:      draws,
+		PairedThrough: current,
+		CorrectedBy:   correctedBy,
+	}
+	// A correction is a played result, whatever the table was before.
+	if err := db.DeleteMatchResultType(ctx, tx, t.ID, round, table); err != nil {
+		return nil, err
+	}
+	if err := db.AddResultCorrection(ctx, tx, c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// correctMatch sets the result of table in a closed round to s, from
+// player A's side, and moves both players' totals with it. It returns the
+// result it replaced.
+func correctMatch(eng *st.Tournament, round, table int, s score) (score, error) {
+	var old score
+	err := editState(eng, func(es *engineState) error {
+		i := table - 1
+		if table < 1 || round >= len(es.Rounds) || i >= len(es.Rounds[round]) || es.Rounds[round][i].isBye() {
+			return fmt.Errorf("round %d has no table %d", round, table)
+		}
+		p := &es.Rounds[round][i]
+		old = score{p.PlayerAWins, p.PlayerBWins, p.Draws}
+		if old == s {
+			return errors.New("that is already the result")
+		}
+		for _, sp := range es.Players {
+			var err error
+			switch sp.id() {
+			case p.PlayerA:
+				err = adjustTotals(sp, es.Config, old, s)
+			case p.PlayerB:
+				err = adjustTotals(sp, es.Config, old.flip(), s.flip())
+			}
+			if err != nil {
+				return err
+			}
+		}
+		p.PlayerAWins, p.PlayerBWins, p.Draws = s.won, s.lost, s.drawn
+		return nil
+	})
+	return old, err
+}
+
+// score is one player's side of a match: games won, lost and drawn.
+type score struct{ won, lost, drawn int }
+
+// flip is the same match from the other side.
+func (s score) flip() score {
+	return score{s.lost, s.won, s.drawn}
+}
+
+// adjustTotals swaps one of a player's matches in their totals in the dump
+// from score old to score new, counting each the way swisstools'
+// UpdatePlayerStandings does.
+func adjustTotals(p statePlayer, cfg st.TournamentConfig, old, new score) error {
+	totals := map[string]int{}
+	for _, key := range []string{"points", "wins", "losses", "draws", "gameWins", "gameLosses", "gameDraws"} {
+		var v int
+		if err := json.Unmarshal(p[key], &v); err != nil {
+			return fmt.Errorf("decode player %s: %w", key, err)
+		}
+		totals[key] = v
+	}
+	for _, m := range []struct {
+		sign int
+		score
+	}{{-1, old}, {1, new}} {
+		totals["gameWins"] += m.sign * m.won
+		totals["gameLosses"] += m.sign * m.lost
+		totals["gameDraws"] += m.sign * m.drawn
+		switch {
+		case m.won > m.lost:
+			totals["wins"] += m.sign
+			totals["points"] += m.sign * cfg.PointsForWin
+		case m.lost > m.won:
+			totals["losses"] += m.sign
+			totals["points"] += m.sign * cfg.PointsForLoss
+		default:
+			totals["draws"] += m.sign
+			totals["points"] += m.sign * cfg.PointsForDraw
+		}
+	}
+	for key, v := range totals {
+		raw, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		p[key] = raw
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package engine
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/models"
+	st "github.com/dstathis/swisstools"
+)
+
+func standingOf(eng *st.Tournament, playerID int) st.PlayerStanding {
+	for _, s := range eng.GetStandings() {
+		if s.PlayerID == playerID {
+			return s
+		}
+	}
+	return st.PlayerStanding{}
+}
+
+func TestCorrectMatch(t *testing.T) {
+	eng := playedEngine(t, 4) // round 1 all 2-0 to player A
+	if err := eng.Pair(false); err != nil {
+		t.Fatal(err)
+	}
+	round2 := eng.GetRound()
+	round1, _ := eng.GetRoundByNumber(1)
+	a, b := round1[0].PlayerA(), round1[0].PlayerB()
+	otherA := round1[1].PlayerA()
+	before := standingOf(eng, otherA)
+
+	old, err := correctMatch(eng, 1, 1, score{1, 2, 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if old != (score{2, 0, 0}) {
+		t.Errorf("old = %+v, want 2-0-0", old)
+	}
+	sa, sb := standingOf(eng, a), standingOf(eng, b)
+	if sa.Points != 0 || sa.Wins != 0 || sa.Losses != 1 {
+		t.Errorf("player A now %d pts %d-%d, want 0 pts 0-1", sa.Points, sa.Wins, sa.Losses)
+	}
+	if sb.Points != 3 || sb.Wins != 1 || sb.Losses != 0 {
+		t.Errorf("player B now %d pts %d-%d, want 3 pts 1-0", sb.Points, sb.Wins, sb.Losses)
+	}
+	if got := standingOf(eng, otherA); got.Points != before.Points || got.Wins != before.Wins {
+		t.Error("another table's players changed")
+	}
+	round1, _ = eng.GetRoundByNumber(1)
+	if p := round1[0]; p.PlayerAWins() != 1 || p.PlayerBWins() != 2 || p.Draws() != 0 {
+		t.Errorf("round 1 table 1 now %d-%d-%d", p.PlayerAWins(), p.PlayerBWins(), p.Draws())
+	}
+	for i, p := range eng.GetRound() {
+		if p.PlayerA() != round2[i].PlayerA() || p.PlayerB() != round2[i].PlayerB() {
+			t.Error("round 2 pairings changed")
+		}
+	}
+
+	// A draw: one draw each, game record 1-1-1.
+	if _, err := correctMatch(eng, 1, 1, score{1, 1, 1}); err != nil {
+		t.Fatal(err)
+	}
+	sa = standingOf(eng, a)
+	if sa.Points != 1 || sa.Draws != 1 || sa.Losses != 0 {
+		t.Errorf("after draw player A %d pts, %d draws, %d losses", sa.Points, sa.Draws, sa.Losses)
+	}
+
+	if _, err := correctMatch(eng, 1, 1, score{1, 1, 1}); err == nil || !strings.Contains(err.Error(), "already") {
+		t.Errorf("same result: err = %v", err)
+	}
+	if _, err := correctMatch(eng, 1, 3, score{2, 0, 0}); err == nil {
+		t.Error("table 3 of a four-player round accepted")
+	}
+}
+
+func TestCorrectResult_Refused(t *testing.T) {
+	ctx := context.Background()
+	running := &models.Tournament{Status: models.TournamentStatusInProgress, BestOf: 3}
+	for _, tt := range []struct {
+		name                string
+		t                   *models.Tournament
+		round, table        int
+		wins, losses, draws int
+		want                string
+	}{
+		{"current round", running, 2, 1, 2, 0, 0, "not a closed round"},
+		{"round 0", running, 0, 1, 2, 0, 0, "not a closed round"},
+		{"playoff", &models.Tournament{Status: models.TournamentStatusPlayoff}, 1, 1, 2, 0, 0, "Swiss rounds"},
+		{"series", &models.Tournament{Status: models.TournamentStatusInProgress, SeriesMode: true}, 1, 1, 2, 0, 0, "snapshot"},
+		{"teams", &models.Tournament{Status: models.TournamentStatusInProgress, TeamSize: 3}, 1, 1, 2, 0, 0, "snapshot"},
+		{"no games", running, 1, 1, 0, 0, 0, "number of games"},
+		{"negative", running, 1, 1, -1, 2, 0, "number of games"},
+		{"too many games", running, 1, 1, 2, 2, 0, "best-of-3"},
+		{"no such table", running, 1, 9, 2, 1, 0, "no table 9"},
+		{"unchanged", running, 1, 1, 2, 0, 0, "already"},
+	} {
+		eng := playedEngine(t, 4)
+		_, err := CorrectResult(ctx, nil, tt.t, eng, tt.round, tt.table, tt.wins, tt.losses, tt.draws, nil)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: err = %v, want %q", tt.name, err, tt.want)
+		}
+	}
+}
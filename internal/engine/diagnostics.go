@@ -0,0 +1,178 @@
+package engine
+
+import (
+	"sort"
+
+	"github.com/dstathis/openswiss/internal/models"
+	st "github.com/dstathis/swisstools"
+)
+
+// DiagnosticPlayer is a player of a flagged pairing or bye, with their
+// match points going into the round.
+type DiagnosticPlayer struct {
+	ID     int    `json:"id"`
+	Name   string `json:"name"`
+	Points int    `json:"points"`
+}
+
+// FlaggedPairing is a match DiagnosePairings picks out: a rematch, or two
+// players on different points.
+type FlaggedPairing struct {
+	Table   int              `json:"table"`
+	PlayerA DiagnosticPlayer `json:"player_a"`
+	PlayerB DiagnosticPlayer `json:"player_b"`
+	// Met counts the times the two played each other in earlier rounds.
+	Met int `json:"met"`
+}
+
+// RoundBye is a bye of a round. Byes counts the player's byes up to and
+// including this one, and Lower the players paired that round on fewer
+// points, who would have come first for it.
+type RoundBye struct {
+	Player DiagnosticPlayer `json:"player"`
+	Byes   int              `json:"byes"`
+	Lower  int              `json:"lower"`
+}
+
+// Flagged reports whether the bye is a repeat or skipped someone lower.
+func (b RoundBye) Flagged() bool {
+	return b.Byes > 1 || b.Lower > 0
+}
+
+// RoundDiagnostics sums up how well one Swiss round was paired.
+type RoundDiagnostics struct {
+	Round int `json:"round"`
+	// Draft is set on the current round while its pairings are a draft.
+	Draft  bool `json:"draft,omitempty"`
+	Tables int  `json:"tables"`
+	// Rematches are the matches of players who had met before.
+	Rematches []FlaggedPairing `json:"rematches"`
+	// CrossRecord are the matches of players on different points.
+	CrossRecord []FlaggedPairing `json:"cross_record"`
+	Byes        []RoundBye       `json:"byes"`
+}
+
+// PlayerByes is a player who has had a bye, with the rounds they had one
+// in. Points are their points now.
+type PlayerByes struct {
+	Player DiagnosticPlayer `json:"player"`
+	Rounds []int            `json:"rounds"`
+}
+
+// PairingDiagnostics is the pairing quality report of a tournament's Swiss
+// rounds (see DiagnosePairings), with totals across them.
+type PairingDiagnostics struct {
+	Rounds      []RoundDiagnostics `json:"rounds"`
+	Rematches   int                `json:"rematches"`
+	CrossRecord int                `json:"cross_record"`
+	RepeatByes  int                `json:"repeat_byes"`
+	// Byes lists every player who has had a bye, most byes first.
+	Byes []PlayerByes `json:"byes"`
+}
+
+// DiagnosePairings goes through eng's Swiss rounds, the current one
+// included, and picks out what a pairing should avoid: rematches, matches
+// across records (players on different match points going in) and byes
+// that went to a player who had one already or ahead of someone on fewer
+// points. Points going into a round are worked out from the earlier
+// rounds' results as they stand now, using t's match points; a match
+// without a result counts nothing. Top cut rounds aren't covered.
+func DiagnosePairings(t *models.Tournament, eng *st.Tournament) *PairingDiagnostics {
+	out := &PairingDiagnostics{Rounds: []RoundDiagnostics{}, Byes: []PlayerByes{}}
+	points := map[int]int{}
+	met := map[[2]int]int{}
+	byes := map[int][]int{}
+	player := func(id int) DiagnosticPlayer {
+		d := DiagnosticPlayer{ID: id, Points: points[id]}
+		if p, ok := eng.GetPlayerById(id); ok {
+			d.Name = p.Name
+		}
+		return d
+	}
+	pair := func(a, b int) [2]int {
+		if a > b {
+			a, b = b, a
+		}
+		return [2]int{a, b}
+	}
+	for r := 1; r <= eng.GetCurrentRound(); r++ {
+		round, err := eng.GetRoundByNumber(r)
+		if err != nil || len(round) == 0 {
+			continue
+		}
+		rd := RoundDiagnostics{
+			Round:       r,
+			Draft:       r == eng.GetCurrentRound() && t.PairingsDraft(r),
+			Rematches:   []FlaggedPairing{},
+			CrossRecord: []FlaggedPairing{},
+			Byes:        []RoundBye{},
+		}
+		for i, p := range round {
+			a, b := p.PlayerA(), p.PlayerB()
+			if b == st.BYE_OPPONENT_ID {
+				byes[a] = append(byes[a], r)
+				bye := RoundBye{Player: player(a), Byes: len(byes[a])}
+				for _, q := range round {
+					if q.PlayerB() == st.BYE_OPPONENT_ID {
+						continue
+					}
+					for _, id := range []int{q.PlayerA(), q.PlayerB()} {
+						if points[id] < points[a] {
+							bye.Lower++
+						}
+					}
+				}
+				if bye.Byes > 1 {
+					out.RepeatByes++
+				}
+				rd.Byes = append(rd.Byes, bye)
+				continue
+			}
+			rd.Tables++
+			f := FlaggedPairing{Table: TableNumber(i, p), PlayerA: player(a), PlayerB: player(b), Met: met[pair(a, b)]}
+			if f.Met > 0 {
+				rd.Rematches = append(rd.Rematches, f)
+			}
+			if f.PlayerA.Points != f.PlayerB.Points {
+				rd.CrossRecord = append(rd.CrossRecord, f)
+			}
+		}
+		out.Rematches += len(rd.Rematches)
+		out.CrossRecord += len(rd.CrossRecord)
+		out.Rounds = append(out.Rounds, rd)
+
+		for _, p := range round {
+			a, b := p.PlayerA(), p.PlayerB()
+			if b == st.BYE_OPPONENT_ID {
+				points[a] += t.PointsWin
+				continue
+			}
+			met[pair(a, b)]++
+			if p.PlayerAWins() == st.UNINITIALIZED_RESULT {
+				continue
+			}
+			switch {
+			case p.PlayerAWins() > p.PlayerBWins():
+				points[a] += t.PointsWin
+				points[b] += t.PointsLoss
+			case p.PlayerBWins() > p.PlayerAWins():
+				points[b] += t.PointsWin
+				points[a] += t.PointsLoss
+			default:
+				points[a] += t.PointsDraw
+				points[b] += t.PointsDraw
+			}
+		}
+	}
+	for id, rounds := range byes {
+		out.Byes = append(out.Byes, PlayerByes{Player: player(id), Rounds: rounds})
+	}
+	sort.Slice(out.Byes, func(i, j int) bool {
+		a, b := out.Byes[i], out.Byes[j]
+		if len(a.Rounds) != len(b.Rounds) {
+			return len(a.Rounds) > len(b.Rounds)
+		}
+		return a.Player.Name < b.Player.Name
+	})
+	return out
+}
@@ -0,0 +1,59 @@
+package engine
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/dstathis/openswiss/internal/pairing"
+	st "github.com/dstathis/swisstools"
+)
+
+func TestDiagnosePairings(t *testing.T) {
+	tourn := &models.Tournament{PointsWin: 3, PointsDraw: 1}
+	eng := playedEngine(t, 5)
+	first, err := eng.GetRoundByNumber(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Round 2 repeats round 1: every match a rematch of winner against
+	// loser, and the same player's bye again.
+	var pairs []pairing.Pair
+	byePlayer := 0
+	for _, p := range first {
+		if p.PlayerB() == st.BYE_OPPONENT_ID {
+			byePlayer = p.PlayerA()
+			pairs = append(pairs, pairing.Pair{A: p.PlayerA(), B: pairing.Bye})
+			continue
+		}
+		pairs = append(pairs, pairing.Pair{A: p.PlayerA(), B: p.PlayerB()})
+	}
+	if err := writeRound(eng, pairs, false); err != nil {
+		t.Fatal(err)
+	}
+
+	d := DiagnosePairings(tourn, eng)
+	if len(d.Rounds) != 2 {
+		t.Fatalf("got %d rounds, want 2", len(d.Rounds))
+	}
+	r1, r2 := d.Rounds[0], d.Rounds[1]
+	if len(r1.Rematches) != 0 || len(r1.CrossRecord) != 0 || len(r1.Byes) != 1 || r1.Byes[0].Flagged() {
+		t.Errorf("round 1 = %+v, want no flags", r1)
+	}
+	if r2.Tables != 2 || len(r2.Rematches) != 2 || len(r2.CrossRecord) != 2 {
+		t.Errorf("round 2 = %+v, want 2 rematches across records at 2 tables", r2)
+	}
+	if m := r2.Rematches[0]; m.Met != 1 || m.PlayerA.Points != 3 || m.PlayerB.Points != 0 {
+		t.Errorf("rematch = %+v, want met once, 3 points against 0", m)
+	}
+	want := RoundBye{Player: DiagnosticPlayer{ID: byePlayer, Name: r2.Byes[0].Player.Name, Points: 3}, Byes: 2, Lower: 2}
+	if len(r2.Byes) != 1 || r2.Byes[0] != want || !r2.Byes[0].Flagged() {
+		t.Errorf("round 2 byes = %+v, want %+v", r2.Byes, want)
+	}
+	if d.Rematches != 2 || d.CrossRecord != 2 || d.RepeatByes != 1 {
+		t.Errorf("totals = %d rematches, %d cross record, %d repeat byes; want 2, 2, 1", d.Rematches, d.CrossRecord, d.RepeatByes)
+	}
+	if len(d.Byes) != 1 || !reflect.DeepEqual(d.Byes[0].Rounds, []int{1, 2}) || d.Byes[0].Player.Points != 6 {
+		t.Errorf("byes = %+v, want one player with byes in rounds 1 and 2 on 6 points", d.Byes)
+	}
+}
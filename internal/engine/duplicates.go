@@ -0,0 +1,56 @@
+package engine
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+// PossibleDuplicate is a registration whose name is close to that of one
+// made before it (see models.SimilarPlayerName): maybe the same person
+// signed up twice, or a walk-in added by hand registered online later.
+type PossibleDuplicate struct {
+	Registration models.Registration `json:"registration"`
+	Existing     models.Registration `json:"existing"`
+}
+
+// PossibleDuplicates pairs each registration in regs with the first earlier
+// one whose name is similar, unless staff marked the two distinct (see
+// db.ListDistinctRegistrations). regs must be in sign-up order, as
+// db.ListRegistrations returns them. Dropped players are left out.
+func PossibleDuplicates(regs []models.Registration, distinct map[[2]int64]bool) []PossibleDuplicate {
+	var out []PossibleDuplicate
+	for i, reg := range regs {
+		if reg.Status == models.RegistrationStatusDropped {
+			continue
+		}
+		for _, earlier := range regs[:i] {
+			if earlier.Status == models.RegistrationStatusDropped || distinct[db.DistinctPair(reg.ID, earlier.ID)] {
+				continue
+			}
+			if models.SimilarPlayerName(reg.DisplayName, earlier.DisplayName) {
+				out = append(out, PossibleDuplicate{Registration: reg, Existing: earlier})
+				break
+			}
+		}
+	}
+	return out
+}
+
+// MarkDistinct records that registrations regID and otherID of tournament
+// id are two different people, so PossibleDuplicates stops pairing them.
+func MarkDistinct(ctx context.Context, database *sql.DB, tournamentID, regID, otherID, userID int64) error {
+	if regID == otherID {
+		return errors.New("choose two different players")
+	}
+	for _, id := range []int64{regID, otherID} {
+		reg, err := db.GetRegistrationByID(ctx, database, id)
+		if err != nil || reg.TournamentID != tournamentID {
+			return errors.New("player is not registered for this tournament")
+		}
+	}
+	return db.MarkRegistrationsDistinct(ctx, database, tournamentID, regID, otherID, userID)
+}
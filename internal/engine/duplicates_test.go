@@ -0,0 +1,29 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+func TestPossibleDuplicates(t *testing.T) {
+	regs := []models.Registration{
+		{ID: 1, DisplayName: "John Smith", Status: models.RegistrationStatusConfirmed},
+		{ID: 2, DisplayName: "Ann Lee", Status: models.RegistrationStatusDropped},
+		{ID: 3, DisplayName: "Jon Smith", Status: models.RegistrationStatusPending},
+		{ID: 4, DisplayName: "Ann Lee (2)", Status: models.RegistrationStatusConfirmed},
+		{ID: 5, DisplayName: "Smith, John", Status: models.RegistrationStatusWaitlisted},
+		{ID: 6, DisplayName: "Maria Rossi", Status: models.RegistrationStatusPending},
+	}
+	got := PossibleDuplicates(regs, nil)
+	if len(got) != 2 || got[0].Registration.ID != 3 || got[0].Existing.ID != 1 ||
+		got[1].Registration.ID != 5 || got[1].Existing.ID != 1 {
+		t.Fatalf("PossibleDuplicates = %+v, want 3 and 5 against 1", got)
+	}
+
+	// Once 5 and 1 are marked distinct, 5 is still close to 3.
+	got = PossibleDuplicates(regs, map[[2]int64]bool{{1, 3}: true, {1, 5}: true})
+	if len(got) != 1 || got[0].Registration.ID != 5 || got[0].Existing.ID != 3 {
+		t.Errorf("with 1 marked distinct from 3 and 5: %+v, want 5 against 3", got)
+	}
+}
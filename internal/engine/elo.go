@@ -0,0 +1,111 @@
+package engine
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dstathis/openswiss/internal/models"
+	st "github.com/dstathis/swisstools"
+)
+
+// The Elo K-factor is the most a rating can move in one match. Staff can
+// pick one up to MaxRatingK for the report.
+const (
+	DefaultRatingK = 20
+	MaxRatingK     = 100
+)
+
+// ParseRatingK reads a K-factor as given in a query string; empty means
+// DefaultRatingK.
+func ParseRatingK(s string) (int, error) {
+	if s == "" {
+		return DefaultRatingK, nil
+	}
+	k, err := strconv.Atoi(s)
+	if err != nil || k < 1 || k > MaxRatingK {
+		return 0, fmt.Errorf("k must be a whole number from 1 to %d", MaxRatingK)
+	}
+	return k, nil
+}
+
+// RatingChange is one rated player's Elo result over a tournament.
+type RatingChange struct {
+	RegistrationID int64  `json:"registration_id"`
+	Name           string `json:"name"`
+	// Rating is the player's rating going in, as staff entered it.
+	Rating int `json:"rating"`
+	// Matches counts the rated matches: those against a rated opponent.
+	Matches  int     `json:"matches"`
+	Score    float64 `json:"score"`
+	Expected float64 `json:"expected"`
+	Delta    int     `json:"delta"`
+	New      int     `json:"new_rating"`
+}
+
+// expectedScore is the Elo expectation of a player rated a against one
+// rated b: 1 for a sure win, 0.5 for an even match.
+func expectedScore(a, b int) float64 {
+	return 1 / (1 + math.Pow(10, float64(b-a)/400))
+}
+
+// RatingChanges computes Elo changes from every reported match, Swiss and
+// top cut, between two players who both have a rating. Each match counts
+// once, as a win (1), draw (½) or loss (0) on games, against the opponent's
+// rating going in, so the order of the rounds doesn't matter. A player's
+// change is k times their score less their expected score, rounded. Byes,
+// unreported matches and matches against unrated players don't count;
+// unrated players are left out. The biggest gains come first.
+func RatingChanges(regs []models.Registration, eng *st.Tournament, k int) []RatingChange {
+	byPlayer := make(map[int]*RatingChange)
+	for _, r := range regs {
+		if r.EnginePlayerID == nil || r.Rating == nil {
+			continue
+		}
+		byPlayer[*r.EnginePlayerID] = &RatingChange{RegistrationID: r.ID, Name: r.DisplayName, Rating: *r.Rating}
+	}
+	count := func(round []st.Pairing) {
+		for _, p := range round {
+			a, b := byPlayer[p.PlayerA()], byPlayer[p.PlayerB()]
+			if a == nil || b == nil || p.PlayerAWins() == st.UNINITIALIZED_RESULT {
+				continue
+			}
+			score := 0.5
+			switch {
+			case p.PlayerAWins() > p.PlayerBWins():
+				score = 1
+			case p.PlayerAWins() < p.PlayerBWins():
+				score = 0
+			}
+			ea := expectedScore(a.Rating, b.Rating)
+			a.Matches, a.Score, a.Expected = a.Matches+1, a.Score+score, a.Expected+ea
+			b.Matches, b.Score, b.Expected = b.Matches+1, b.Score+1-score, b.Expected+1-ea
+		}
+	}
+	for r := 1; r <= eng.GetCurrentRound(); r++ {
+		if round, err := eng.GetRoundByNumber(r); err == nil {
+			count(round)
+		}
+	}
+	if po := eng.GetPlayoff(); po != nil {
+		for _, round := range po.Rounds {
+			count(round)
+		}
+	}
+
+	out := make([]RatingChange, 0, len(byPlayer))
+	for _, c := range byPlayer {
+		c.Delta = int(math.Round(float64(k) * (c.Score - c.Expected)))
+		c.New = c.Rating + c.Delta
+		out = append(out, *c)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Delta != out[j].Delta {
+			return out[i].Delta > out[j].Delta
+		}
+		return strings.ToLower(out[i].Name) < strings.ToLower(out[j].Name)
+	})
+	return out
+}
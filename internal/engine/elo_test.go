@@ -0,0 +1,79 @@
+package engine
+
+import (
+	"math"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/models"
+	st "github.com/dstathis/swisstools"
+)
+
+func TestExpectedScore(t *testing.T) {
+	if got := expectedScore(1500, 1500); got != 0.5 {
+		t.Errorf("even match: %v, want 0.5", got)
+	}
+	if got := expectedScore(1800, 1400); math.Abs(got-0.909) > 0.001 {
+		t.Errorf("400 points up: %v, want about 0.909", got)
+	}
+	if a, b := expectedScore(1600, 1450), expectedScore(1450, 1600); math.Abs(a+b-1) > 1e-9 {
+		t.Errorf("expectations %v and %v don't add up to 1", a, b)
+	}
+}
+
+func TestRatingChanges(t *testing.T) {
+	eng := st.NewTournament()
+	for _, name := range []string{"Ann", "Bob", "Cy", "Dee"} {
+		if err := eng.AddPlayer(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := eng.StartTournament(); err != nil {
+		t.Fatal(err)
+	}
+	round := eng.GetRound()
+	// Board 1 is won by player A, board 2 is drawn.
+	if err := eng.AddResult(round[0].PlayerA(), 2, 0, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := eng.AddResult(round[1].PlayerA(), 1, 1, 0); err != nil {
+		t.Fatal(err)
+	}
+	winner, loser := round[0].PlayerA(), round[0].PlayerB()
+	drawA, drawB := round[1].PlayerA(), round[1].PlayerB()
+
+	ptr := func(n int) *int { return &n }
+	regs := []models.Registration{
+		{ID: 1, DisplayName: "Winner", EnginePlayerID: ptr(winner), Rating: ptr(1500)},
+		{ID: 2, DisplayName: "Loser", EnginePlayerID: ptr(loser), Rating: ptr(1500)},
+		{ID: 3, DisplayName: "Drew", EnginePlayerID: ptr(drawA), Rating: ptr(1700)},
+		{ID: 4, DisplayName: "Unrated", EnginePlayerID: ptr(drawB)},
+	}
+	got := RatingChanges(regs, &eng, 32)
+	want := []RatingChange{
+		{RegistrationID: 1, Name: "Winner", Rating: 1500, Matches: 1, Score: 1, Expected: 0.5, Delta: 16, New: 1516},
+		// The draw was against an unrated player, so it doesn't count.
+		{RegistrationID: 3, Name: "Drew", Rating: 1700, New: 1700},
+		{RegistrationID: 2, Name: "Loser", Rating: 1500, Matches: 1, Score: 0, Expected: 0.5, Delta: -16, New: 1484},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("changes = %+v", got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("change %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestParseRatingK(t *testing.T) {
+	for in, want := range map[string]int{"": DefaultRatingK, "32": 32, "100": 100} {
+		if k, err := ParseRatingK(in); err != nil || k != want {
+			t.Errorf("ParseRatingK(%q) = %d, %v; want %d", in, k, err, want)
+		}
+	}
+	for _, in := range []string{"0", "101", "ten", "-5"} {
+		if _, err := ParseRatingK(in); err == nil {
+			t.Errorf("ParseRatingK(%q) accepted", in)
+		}
+	}
+}
@@ -5,19 +5,34 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"sort"
 
 	"github.com/dstathis/openswiss/internal/db"
 	"github.com/dstathis/openswiss/internal/models"
+	"github.com/dstathis/openswiss/internal/pairing"
+	"github.com/dstathis/openswiss/internal/readonly"
 	st "github.com/dstathis/swisstools"
 )
 
 // WithTournamentEngine loads the tournament engine state within a transaction,
 // calls the provided function, then saves the state back. The callback receives
-// the tournament model and the loaded swisstools Tournament engine.
-func WithTournamentEngine(ctx context.Context, database *sql.DB, tournamentID int64, fn func(tx *sql.Tx, t *models.Tournament, eng *st.Tournament) (string, error)) error {
+// the tournament model and the loaded swisstools Tournament engine. If the
+// tournament has webhooks, the events the change caused are queued for them
+// in the same transaction. A change that would close a round with a match
+// still unreported is refused with ErrIncompleteRound, and any change to a
+// complete tournament (see Complete) with ErrTournamentFinished. A change
+// that pairs a new round or finishes the tournament saves a snapshot of the
+// result (see RollBack), and one that completes it files its final standings
+// in the archive. With PreviewPairings set, a newly paired Swiss round
+// becomes the draft round (see PublishPairings). Waiting too long for the
+// tournament, or past ctx's deadline, fails with db.ErrBusy. Storage
+// failures switch the server to read-only mode (see readonly.Report).
+func WithTournamentEngine(ctx context.Context, database *sql.DB, tournamentID int64, fn func(tx *sql.Tx, t *models.Tournament, eng *st.Tournament) (string, error)) (err error) {
+	defer func() { readonly.Report(ctx, err) }()
+
 	tx, err := database.BeginTx(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("begin tx: %w", err)
+		return fmt.Errorf("begin tx: %w", db.Busy(ctx, err))
 	}
 	defer tx.Rollback()
 
@@ -33,20 +48,40 @@ func WithTournamentEngine(ctx context.Context, database *sql.DB, tournamentID in
 			return fmt.Errorf("load engine state: %w", err)
 		}
 	} else {
-		eng = st.NewTournamentWithConfig(st.TournamentConfig{
-			PointsForWin:  t.PointsWin,
-			PointsForDraw: t.PointsDraw,
-			PointsForLoss: t.PointsLoss,
-			ByeWins:       st.BYE_WINS,
-			ByeLosses:     st.BYE_LOSSES,
-			ByeDraws:      st.BYE_DRAWS,
-		})
+		eng = st.NewTournamentWithConfig(engineConfig(t))
+	}
+
+	if Complete(t, &eng) {
+		return ErrTournamentFinished
+	}
+
+	hooked, err := db.HasWebhooks(ctx, tx, tournamentID)
+	if err != nil {
+		return fmt.Errorf("check webhooks: %w", err)
+	}
+	oldStatus := t.Status
+	fromRound := 0
+	if len(t.EngineState) > 0 {
+		fromRound = eng.GetCurrentRound()
+	}
+	fromStage := stageOf(&eng, oldStatus)
+	wasDraft := t.PairingsDraft(fromRound)
+	var before *st.Tournament
+	if hooked && len(t.EngineState) > 0 {
+		b, err := st.LoadTournament(t.EngineState)
+		if err != nil {
+			return fmt.Errorf("load engine state: %w", err)
+		}
+		before = &b
 	}
 
 	newStatus, err := fn(tx, t, &eng)
 	if err != nil {
 		return err
 	}
+	if err := checkClosedRounds(&eng, fromRound); err != nil {
+		return err
+	}
 
 	data, err := eng.DumpTournament()
 	if err != nil {
@@ -56,66 +91,209 @@ func WithTournamentEngine(ctx context.Context, database *sql.DB, tournamentID in
 	if newStatus == "" {
 		newStatus = t.Status
 	}
+	if round := eng.GetCurrentRound(); t.PreviewPairings && newStatus == models.TournamentStatusInProgress && round > 0 && round != fromRound {
+		if err := db.SetDraftRound(ctx, tx, tournamentID, round); err != nil {
+			return fmt.Errorf("save draft round: %w", err)
+		}
+		t.DraftRound = round
+	}
 	if err := db.UpdateTournamentEngineState(ctx, tx, tournamentID, newStatus, data); err != nil {
 		return fmt.Errorf("save engine state: %w", err)
 	}
+	// A new round or a finish is worth going back to.
+	if stageOf(&eng, newStatus) != fromStage {
+		if _, err := db.SnapshotTournament(ctx, tx, tournamentID, models.SnapshotRound, eng.GetCurrentRound()); err != nil {
+			return fmt.Errorf("snapshot: %w", err)
+		}
+	}
+	finished := *t
+	finished.Status = newStatus
+	if Complete(&finished, &eng) {
+		if err := archive(ctx, tx, &finished, &eng); err != nil {
+			return fmt.Errorf("archive: %w", err)
+		}
+	}
+	if hooked {
+		drafts := [2]bool{wasDraft, t.PairingsDraft(eng.GetCurrentRound())}
+		if err := queueWebhookEvents(ctx, database, tx, t, before, &eng, oldStatus, newStatus, drafts); err != nil {
+			return fmt.Errorf("queue webhook events: %w", err)
+		}
+	}
 
-	return tx.Commit()
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	standingsByTournament.forget(tournamentID)
+	return nil
 }
 
 // InitTournamentEngine creates a new engine with the tournament's config,
-// adds all confirmed registrations as players, and returns the engine state.
+// adds all confirmed registrations as players, pairs round 1 and returns the
+// engine state. Round 1 is paired at random unless it is seeded by rating
+// (Round1Pairing), staff assigned byes for it, it keeps club mates apart or
+// the tournament is a round robin, in which case it goes through
+// PairRound. A seeded tournament seats its players in seeding order (see
+// seatingOrder), so engine player IDs are the seeding. Without a set number of rounds, the
+// engine stops after autoRounds.
 func InitTournamentEngine(ctx context.Context, tx *sql.Tx, t *models.Tournament, regs []models.Registration) ([]byte, error) {
-	eng := st.NewTournamentWithConfig(st.TournamentConfig{
-		PointsForWin:  t.PointsWin,
-		PointsForDraw: t.PointsDraw,
-		PointsForLoss: t.PointsLoss,
-		ByeWins:       st.BYE_WINS,
-		ByeLosses:     st.BYE_LOSSES,
-		ByeDraws:      st.BYE_DRAWS,
-	})
+	eng := st.NewTournamentWithConfig(engineConfig(t))
 
 	if t.NumRounds != nil && *t.NumRounds > 0 {
 		eng.SetMaxRounds(*t.NumRounds)
 	}
 
-	for _, r := range regs {
+	playerIDs := make(map[int64]int)
+	clubs := make(map[int]string)
+	for _, r := range seatingOrder(t, regs) {
 		if r.Status != models.RegistrationStatusConfirmed {
 			continue
 		}
-
-		if err := eng.AddPlayer(r.DisplayName); err != nil {
-			return nil, fmt.Errorf("add player %s: %w", r.DisplayName, err)
+		playerID, err := seatPlayer(ctx, tx, &eng, r)
+		if err != nil {
+			return nil, err
+		}
+		playerIDs[r.ID] = playerID
+		if r.Club != nil {
+			clubs[playerID] = clubKey(*r.Club)
 		}
+	}
 
-		playerID, ok := eng.GetPlayerID(r.DisplayName)
-		if !ok {
-			return nil, fmt.Errorf("player %s not found after adding", r.DisplayName)
+	assigned, err := db.ListAssignedByes(ctx, tx, t.ID)
+	if err != nil {
+		return nil, fmt.Errorf("list assigned byes: %w", err)
+	}
+	var byes []int
+	for _, b := range assigned {
+		if id, ok := playerIDs[b.RegistrationID]; ok && b.Round == 1 {
+			byes = append(byes, id)
 		}
+	}
+
+	// seatPlayer rolled the tiebreak seeds, which regs predate.
+	seeds, err := Seeds(ctx, tx, t)
+	if err != nil {
+		return nil, err
+	}
 
-		// Guests have no user account, so no external ID to link.
-		if r.UserID != nil {
-			if err := eng.SetPlayerExternalID(playerID, int(*r.UserID)); err != nil {
-				return nil, fmt.Errorf("set external ID for %s: %w", r.DisplayName, err)
-			}
+	if err := eng.StartTournament(); err != nil {
+		return nil, fmt.Errorf("start tournament: %w", err)
+	}
+	if n := autoRounds(t, len(playerIDs)); n > 0 {
+		eng.SetMaxRounds(n)
+	}
+	if len(byes) > 0 || seeded(t) || keepsClubsApart(t, 1, clubs) || t.PairingAlgorithm == models.PairingRoundRobin {
+		if err := PairRound(&eng, t, true, byes, clubs, seeds); err != nil {
+			return nil, fmt.Errorf("pair round 1: %w", err)
 		}
+	} else if err := AssignTables(&eng, t.TiebreakOrder(), seeds); err != nil {
+		return nil, fmt.Errorf("assign tables: %w", err)
+	}
+
+	return eng.DumpTournament()
+}
+
+// engineConfig returns the engine configuration for t: its match points,
+// and a bye worth a clean sweep of its match format (see
+// models.Tournament.GamesToWin), so byes feed the game tiebreakers like a
+// win played out. The engine keeps it in its state from the start.
+func engineConfig(t *models.Tournament) st.TournamentConfig {
+	byeWins := st.BYE_WINS
+	if n := t.GamesToWin(); n > 0 {
+		byeWins = n
+	}
+	return st.TournamentConfig{
+		PointsForWin:  t.PointsWin,
+		PointsForDraw: t.PointsDraw,
+		PointsForLoss: t.PointsLoss,
+		ByeWins:       byeWins,
+		ByeLosses:     st.BYE_LOSSES,
+		ByeDraws:      st.BYE_DRAWS,
+	}
+}
+
+// autoRounds returns how many rounds t runs with players in it when it has
+// no NumRounds: a round robin's full cycle, or the recommended Swiss rounds
+// (pairing.SwissRounds) with AutoRounds on. It is 0 when t has a NumRounds
+// or runs until staff finish it.
+func autoRounds(t *models.Tournament, players int) int {
+	switch {
+	case t.NumRounds != nil && *t.NumRounds > 0:
+		return 0
+	case t.PairingAlgorithm == models.PairingRoundRobin:
+		return pairing.Cycle(players)
+	case t.AutoRounds:
+		return pairing.SwissRounds(players)
+	}
+	return 0
+}
+
+// seatPlayer adds registration r to the engine as a player, with their
+// account as external ID and their decklist, and records the engine player
+// ID on the registration.
+func seatPlayer(ctx context.Context, tx *sql.Tx, eng *st.Tournament, r models.Registration) (int, error) {
+	if err := eng.AddPlayer(r.DisplayName); err != nil {
+		return 0, fmt.Errorf("add player %s: %w", r.DisplayName, err)
+	}
+
+	playerID, ok := eng.GetPlayerID(r.DisplayName)
+	if !ok {
+		return 0, fmt.Errorf("player %s not found after adding", r.DisplayName)
+	}
 
-		// Set decklist if available
-		if r.Decklist != nil {
-			var dl st.Decklist
-			if err := json.Unmarshal(r.Decklist, &dl); err == nil {
-				eng.SetPlayerDecklist(playerID, dl)
-			}
+	// Guests have no user account, so no external ID to link.
+	if r.UserID != nil {
+		if err := eng.SetPlayerExternalID(playerID, int(*r.UserID)); err != nil {
+			return 0, fmt.Errorf("set external ID for %s: %w", r.DisplayName, err)
 		}
+	}
 
-		if err := db.UpdateRegistrationEnginePlayerID(ctx, tx, r.ID, playerID); err != nil {
-			return nil, fmt.Errorf("update engine player id: %w", err)
+	// Set decklist if available
+	if r.Decklist != nil {
+		var dl st.Decklist
+		if err := json.Unmarshal(r.Decklist, &dl); err == nil {
+			eng.SetPlayerDecklist(playerID, dl)
 		}
 	}
 
-	if err := eng.StartTournament(); err != nil {
-		return nil, fmt.Errorf("start tournament: %w", err)
+	if err := db.UpdateRegistrationEnginePlayerID(ctx, tx, r.ID, playerID); err != nil {
+		return 0, fmt.Errorf("update engine player id: %w", err)
 	}
+	return playerID, nil
+}
 
-	return eng.DumpTournament()
+// seeded reports whether t pairs round 1 by rating.
+func seeded(t *models.Tournament) bool {
+	return t.Round1Pairing == models.Round1Cross || t.Round1Pairing == models.Round1Fold
+}
+
+// seatingOrder returns regs in the order InitTournamentEngine adds them:
+// as registered, or for a seeded tournament by pod seed and then by
+// rating, highest first, with unrated players last.
+func seatingOrder(t *models.Tournament, regs []models.Registration) []models.Registration {
+	if !seeded(t) {
+		return regs
+	}
+	out := byRating(regs)
+	sort.SliceStable(out, func(i, j int) bool {
+		a, b := out[i].PodSeed, out[j].PodSeed
+		if a == nil || b == nil {
+			return a != nil && b == nil
+		}
+		return *a < *b
+	})
+	return out
+}
+
+// byRating returns a copy of regs sorted by rating, highest first, with
+// unrated players last in the order given.
+func byRating(regs []models.Registration) []models.Registration {
+	out := append([]models.Registration(nil), regs...)
+	sort.SliceStable(out, func(i, j int) bool {
+		a, b := out[i].Rating, out[j].Rating
+		if a == nil || b == nil {
+			return a != nil && b == nil
+		}
+		return *a > *b
+	})
+	return out
 }
@@ -135,6 +135,76 @@ func TestInitTournamentEngine(t *testing.T) {
 	}
 }
 
+func TestInitTournamentEngine_SeededByRating(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	tourn, regs := setupTournamentWithPlayers(t, database, 4)
+	tourn.Round1Pairing = models.Round1Fold
+	// Registered 0..3, rated so the seeding is 2, 0, 3 and unrated 1 last.
+	ratings := map[int64]*int{}
+	for i, r := range []int{1800, 0, 2000, 1500} {
+		if r > 0 {
+			rating := r
+			ratings[regs[i].ID] = &rating
+			regs[i].Rating = &rating
+		}
+	}
+	if err := SetRatings(ctx, database, tourn.ID, ratings); err != nil {
+		t.Fatalf("SetRatings: %v", err)
+	}
+
+	tx, err := database.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatalf("begin tx: %v", err)
+	}
+	defer tx.Rollback()
+	state, err := InitTournamentEngine(ctx, tx, tourn, regs)
+	if err != nil {
+		t.Fatalf("InitTournamentEngine: %v", err)
+	}
+	eng, err := st.LoadTournament(state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	name := func(id int) string {
+		p, _ := eng.GetPlayerById(id)
+		return p.Name
+	}
+	// Folded: seed 1 v 4, 2 v 3.
+	want := map[string]string{regs[2].DisplayName: regs[1].DisplayName, regs[0].DisplayName: regs[3].DisplayName}
+	for _, p := range eng.GetRound() {
+		a, b := name(p.PlayerA()), name(p.PlayerB())
+		if want[a] != b && want[b] != a {
+			t.Errorf("round 1 pairs %s with %s", a, b)
+		}
+	}
+}
+
+func TestInitTournamentEngine_AutoRounds(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	tourn, regs := setupTournamentWithPlayers(t, database, 5)
+	tourn.AutoRounds = true
+
+	tx, err := database.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatalf("begin tx: %v", err)
+	}
+	defer tx.Rollback()
+	state, err := InitTournamentEngine(ctx, tx, tourn, regs)
+	if err != nil {
+		t.Fatalf("InitTournamentEngine: %v", err)
+	}
+	eng, err := st.LoadTournament(state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Five players call for three rounds.
+	if got := eng.GetMaxRounds(); got != 3 {
+		t.Errorf("max rounds = %d, want 3", got)
+	}
+}
+
 func TestWithTournamentEngine(t *testing.T) {
 	database := testDB(t)
 	ctx := context.Background()
@@ -0,0 +1,176 @@
+package engine
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/dstathis/openswiss/internal/webhook"
+	st "github.com/dstathis/swisstools"
+)
+
+// webhookEvent is an event found by diffing the engine, before it is wrapped
+// in an envelope.
+type webhookEvent struct {
+	name string
+	data interface{}
+}
+
+// diffEvents compares the engine before and after a change and returns the
+// webhook events the change caused: results first, then new pairings, then
+// the finish. before is nil when the tournament hadn't started. drafts says
+// whether the current Swiss round was an unpublished draft before and after
+// the change: a draft is announced once it is published. order and seeds
+// are the tiebreak order and seeds for the final standings and only used on
+// finishing.
+func diffEvents(before, after *st.Tournament, oldStatus, newStatus string, drafts [2]bool, order []string, seeds map[int]int64) []webhookEvent {
+	var events []webhookEvent
+
+	// Swiss rounds.
+	beforeRound := 0
+	var beforePairings []st.Pairing
+	if before != nil {
+		beforeRound = before.GetCurrentRound()
+		beforePairings = before.GetRound()
+	}
+	if beforeRound > 0 {
+		if now, err := after.GetRoundByNumber(beforeRound); err == nil {
+			events = append(events, resultEvents(after, webhook.StageSwiss, beforeRound, beforePairings, now)...)
+		}
+	}
+	if round, now := after.GetCurrentRound(), after.GetRound(); round > 0 && len(now) > 0 && !drafts[1] &&
+		(round != beforeRound || !samePairings(beforePairings, now) || drafts[0]) {
+		events = append(events, pairedEvent(after, webhook.StageSwiss, round, now))
+	}
+
+	// Playoff rounds, numbered from 1 like the Swiss ones.
+	if po := after.GetPlayoff(); po != nil {
+		var bp *st.Playoff
+		if before != nil {
+			bp = before.GetPlayoff()
+		}
+		if bp != nil {
+			was, _ := before.GetPlayoffRoundByNumber(bp.CurrentRound)
+			if now, err := after.GetPlayoffRoundByNumber(bp.CurrentRound); err == nil {
+				events = append(events, resultEvents(after, webhook.StagePlayoff, bp.CurrentRound+1, was, now)...)
+			}
+		}
+		if bp == nil || bp.CurrentRound != po.CurrentRound {
+			events = append(events, pairedEvent(after, webhook.StagePlayoff, po.CurrentRound+1, after.GetPlayoffRound()))
+		}
+	}
+
+	if newStatus == models.TournamentStatusFinished && oldStatus != models.TournamentStatusFinished {
+		var standings []webhook.Standing
+		for _, s := range Standings(after, order, seeds) {
+			standings = append(standings, webhook.Standing{
+				Rank: s.Rank, PlayerID: s.PlayerID, Name: s.Name, Points: s.Points,
+				Wins: s.Wins, Losses: s.Losses, Draws: s.Draws,
+			})
+		}
+		stage := webhook.StageSwiss
+		if after.GetPlayoff() != nil {
+			stage = webhook.StagePlayoff
+		}
+		events = append(events, webhookEvent{models.WebhookTournamentFinished, webhook.TournamentFinished{Stage: stage, Standings: standings}})
+	}
+	return events
+}
+
+// samePairings reports whether two rounds seat the same players at the same
+// tables.
+func samePairings(a, b []st.Pairing) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].PlayerA() != b[i].PlayerA() || a[i].PlayerB() != b[i].PlayerB() {
+			return false
+		}
+	}
+	return true
+}
+
+func webhookPairing(eng *st.Tournament, i int, p st.Pairing) webhook.Pairing {
+	wp := webhook.Pairing{
+		Table:   TableNumber(i, p),
+		PlayerA: p.PlayerA(),
+		PlayerB: p.PlayerB(),
+		IsBye:   p.PlayerB() == st.BYE_OPPONENT_ID,
+	}
+	if player, ok := eng.GetPlayerById(p.PlayerA()); ok {
+		wp.PlayerAName = player.Name
+	}
+	if player, ok := eng.GetPlayerById(p.PlayerB()); ok {
+		wp.PlayerBName = player.Name
+	}
+	return wp
+}
+
+func pairedEvent(eng *st.Tournament, stage string, round int, pairings []st.Pairing) webhookEvent {
+	data := webhook.RoundPaired{Stage: stage, Round: round, Pairings: []webhook.Pairing{}}
+	for i, p := range pairings {
+		data.Pairings = append(data.Pairings, webhookPairing(eng, i, p))
+	}
+	return webhookEvent{models.WebhookRoundPaired, data}
+}
+
+// resultEvents returns a result.entered event for each match of a round
+// whose result was set or changed between was and now. Byes, and rounds
+// whose pairings changed in between, have none.
+func resultEvents(eng *st.Tournament, stage string, round int, was, now []st.Pairing) []webhookEvent {
+	if !samePairings(was, now) {
+		return nil
+	}
+	var events []webhookEvent
+	for i, p := range now {
+		if p.PlayerB() == st.BYE_OPPONENT_ID || p.PlayerAWins() == st.UNINITIALIZED_RESULT {
+			continue
+		}
+		w := was[i]
+		if w.PlayerAWins() == p.PlayerAWins() && w.PlayerBWins() == p.PlayerBWins() && w.Draws() == p.Draws() {
+			continue
+		}
+		events = append(events, webhookEvent{models.WebhookResultEntered, webhook.ResultEntered{
+			Stage:       stage,
+			Round:       round,
+			Pairing:     webhookPairing(eng, i, p),
+			PlayerAWins: p.PlayerAWins(),
+			PlayerBWins: p.PlayerBWins(),
+			Draws:       p.Draws(),
+		}})
+	}
+	return events
+}
+
+// queueWebhookEvents queues the webhook deliveries for the events a change
+// caused. It runs inside WithTournamentEngine's transaction.
+func queueWebhookEvents(ctx context.Context, database *sql.DB, tx *sql.Tx, t *models.Tournament, before, after *st.Tournament, oldStatus, newStatus string, drafts [2]bool) error {
+	var seeds map[int]int64
+	if newStatus == models.TournamentStatusFinished && oldStatus != models.TournamentStatusFinished {
+		regs, err := db.ListRegistrations(ctx, database, t.ID)
+		if err != nil {
+			return err
+		}
+		seeds = TiebreakSeeds(regs)
+	}
+	now := time.Now().UTC()
+	for _, e := range diffEvents(before, after, oldStatus, newStatus, drafts, t.TiebreakOrder(), seeds) {
+		body, err := json.Marshal(webhook.Envelope{
+			Event:      e.name,
+			Tournament: webhook.Tournament{ID: t.ID, Name: t.Name},
+			OccurredAt: now,
+			Data:       e.data,
+		})
+		if err != nil {
+			return err
+		}
+		if err := db.EnqueueWebhookEvent(ctx, tx, t.ID, e.name, body); err != nil {
+			return err
+		}
+	}
+	return nil
+}
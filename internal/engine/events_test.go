@@ -0,0 +1,144 @@
+package engine
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/dstathis/openswiss/internal/webhook"
+	st "github.com/dstathis/swisstools"
+)
+
+func cloneEngine(t *testing.T, eng *st.Tournament) *st.Tournament {
+	t.Helper()
+	data, err := eng.DumpTournament()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := st.LoadTournament(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &c
+}
+
+func eventNames(events []webhookEvent) []string {
+	var names []string
+	for _, e := range events {
+		names = append(names, e.name)
+	}
+	return names
+}
+
+func TestDiffEvents(t *testing.T) {
+	const running, finished = models.TournamentStatusInProgress, models.TournamentStatusFinished
+	eng := st.NewTournament()
+	for i := 0; i < 5; i++ {
+		if err := eng.AddPlayer(fmt.Sprintf("P%d", i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := eng.StartTournament(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Starting pairs round 1.
+	events := diffEvents(nil, &eng, models.TournamentStatusRegistrationOpen, running, [2]bool{}, nil, nil)
+	if len(events) != 1 || events[0].name != models.WebhookRoundPaired {
+		t.Fatalf("start: events = %v", eventNames(events))
+	}
+	paired := events[0].data.(webhook.RoundPaired)
+	if paired.Stage != webhook.StageSwiss || paired.Round != 1 || len(paired.Pairings) != 3 {
+		t.Errorf("start: data = %+v", paired)
+	}
+	if bye := paired.Pairings[2]; !bye.IsBye || bye.Table != 0 || bye.PlayerAName == "" {
+		t.Errorf("start: bye = %+v", bye)
+	}
+
+	// Entering a result reports that match only; re-entering it unchanged
+	// reports nothing.
+	before := cloneEngine(t, &eng)
+	p := eng.GetRound()[1]
+	if err := eng.AddResult(p.PlayerA(), 2, 1, 0); err != nil {
+		t.Fatal(err)
+	}
+	events = diffEvents(before, &eng, running, running, [2]bool{}, nil, nil)
+	if len(events) != 1 || events[0].name != models.WebhookResultEntered {
+		t.Fatalf("result: events = %v", eventNames(events))
+	}
+	res := events[0].data.(webhook.ResultEntered)
+	if res.Round != 1 || res.Table != 2 || res.PlayerA != p.PlayerA() || res.PlayerAWins != 2 || res.PlayerBWins != 1 {
+		t.Errorf("result: data = %+v", res)
+	}
+	if events = diffEvents(cloneEngine(t, &eng), &eng, running, running, [2]bool{}, nil, nil); len(events) != 0 {
+		t.Errorf("no change: events = %v", eventNames(events))
+	}
+
+	// Advancing pairs round 2.
+	for _, p := range eng.GetRound() {
+		if p.PlayerB() != st.BYE_OPPONENT_ID && p.PlayerAWins() == st.UNINITIALIZED_RESULT {
+			if err := eng.AddResult(p.PlayerA(), 2, 0, 0); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	before = cloneEngine(t, &eng)
+	if err := eng.NextRound(); err != nil {
+		t.Fatal(err)
+	}
+	if err := PairRound(&eng, &models.Tournament{}, false, nil, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	events = diffEvents(before, &eng, running, running, [2]bool{}, nil, nil)
+	if len(events) != 1 || events[0].data.(webhook.RoundPaired).Round != 2 {
+		t.Fatalf("next round: events = %v", eventNames(events))
+	}
+
+	// Finishing the Swiss part sends the standings.
+	for _, p := range eng.GetRound() {
+		if p.PlayerB() != st.BYE_OPPONENT_ID {
+			if err := eng.AddResult(p.PlayerA(), 2, 0, 0); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	before = cloneEngine(t, &eng)
+	if err := eng.FinishTournament(); err != nil {
+		t.Fatal(err)
+	}
+	events = diffEvents(before, &eng, running, finished, [2]bool{}, nil, nil)
+	if len(events) != 1 || events[0].name != models.WebhookTournamentFinished {
+		t.Fatalf("finish: events = %v", eventNames(events))
+	}
+	done := events[0].data.(webhook.TournamentFinished)
+	if done.Stage != webhook.StageSwiss || len(done.Standings) != 5 || done.Standings[0].Rank != 1 {
+		t.Errorf("finish: data = %+v", done)
+	}
+
+	// Starting the playoff pairs its first round.
+	before = cloneEngine(t, &eng)
+	if err := eng.StartPlayoff(4); err != nil {
+		t.Fatal(err)
+	}
+	events = diffEvents(before, &eng, running, models.TournamentStatusPlayoff, [2]bool{}, nil, nil)
+	if len(events) != 1 {
+		t.Fatalf("playoff: events = %v", eventNames(events))
+	}
+	if got := events[0].data.(webhook.RoundPaired); got.Stage != webhook.StagePlayoff || got.Round != 1 || len(got.Pairings) != 2 {
+		t.Errorf("playoff: data = %+v", got)
+	}
+	before = cloneEngine(t, &eng)
+	if err := eng.AddPlayoffResult(eng.GetPlayoffRound()[0].PlayerA(), 2, 0, 0); err != nil {
+		t.Fatal(err)
+	}
+	events = diffEvents(before, &eng, models.TournamentStatusPlayoff, models.TournamentStatusPlayoff, [2]bool{}, nil, nil)
+	if len(events) != 1 || events[0].data.(webhook.ResultEntered).Stage != webhook.StagePlayoff {
+		t.Fatalf("playoff result: events = %v", eventNames(events))
+	}
+
+	// Finishing the playoff is the second finish.
+	events = diffEvents(cloneEngine(t, &eng), &eng, models.TournamentStatusPlayoff, finished, [2]bool{}, nil, nil)
+	if len(events) != 1 || events[0].data.(webhook.TournamentFinished).Stage != webhook.StagePlayoff {
+		t.Fatalf("playoff finish: events = %v", eventNames(events))
+	}
+}
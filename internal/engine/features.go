@@ -0,0 +1,139 @@
+package engine
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/models"
+	st "github.com/dstathis/swisstools"
+)
+
+// ErrFeatureTable is returned for a feature match at a table the current
+// round doesn't have. Byes have no table, so they can't be featured.
+var ErrFeatureTable = errors.New("no match of the current round is at that table")
+
+// CheckFeatureTables checks tables as the feature matches of eng's current
+// round and returns them sorted, without duplicates.
+func CheckFeatureTables(eng *st.Tournament, tables []int) ([]int, error) {
+	seen := make(map[int]bool, len(tables))
+	out := []int{}
+	for _, table := range tables {
+		if seen[table] {
+			continue
+		}
+		if _, ok := pairingAtTable(eng, table); !ok {
+			return nil, fmt.Errorf("%w: %d", ErrFeatureTable, table)
+		}
+		seen[table] = true
+		out = append(out, table)
+	}
+	if len(out) > models.MaxFeatureMatches {
+		return nil, fmt.Errorf("at most %d matches can be featured", models.MaxFeatureMatches)
+	}
+	sort.Ints(out)
+	return out, nil
+}
+
+// SetFeatureMatches makes tables the feature matches of tournament id's
+// current Swiss round, replacing the ones set before, and returns the round
+// and the tables as checked by CheckFeatureTables. It returns
+// ErrNoRoundUnderway before the start and once the Swiss is over.
+func SetFeatureMatches(ctx context.Context, database *sql.DB, id int64, tables []int) (int, []int, error) {
+	tx, err := database.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+	t, err := db.GetTournamentForUpdate(ctx, tx, id)
+	if err != nil {
+		return 0, nil, fmt.Errorf("get tournament: %w", err)
+	}
+	round, tables, err := setFeatureMatches(ctx, tx, t, tables)
+	if err != nil {
+		return 0, nil, err
+	}
+	return round, tables, tx.Commit()
+}
+
+func setFeatureMatches(ctx context.Context, tx db.DBTX, t *models.Tournament, tables []int) (int, []int, error) {
+	if t.Status != models.TournamentStatusInProgress || len(t.EngineState) == 0 {
+		return 0, nil, ErrNoRoundUnderway
+	}
+	eng, err := st.LoadTournament(t.EngineState)
+	if err != nil {
+		return 0, nil, err
+	}
+	if eng.GetStatus() == "finished" || eng.GetCurrentRound() == 0 {
+		return 0, nil, ErrNoRoundUnderway
+	}
+	if tables, err = CheckFeatureTables(&eng, tables); err != nil {
+		return 0, nil, err
+	}
+	round := eng.GetCurrentRound()
+	return round, tables, db.ReplaceFeatureMatches(ctx, tx, t.ID, round, tables)
+}
+
+// FeaturePlayer is a player of a feature match, with their place in the
+// standings.
+type FeaturePlayer struct {
+	ID     int    `json:"id"`
+	Name   string `json:"name"`
+	Rank   int    `json:"rank,omitempty"`
+	Points int    `json:"points"`
+	Wins   int    `json:"wins"`
+	Losses int    `json:"losses"`
+	Draws  int    `json:"draws"`
+}
+
+// FeatureMatch is a feature match as a stream overlay shows it: the players
+// with their standings going into the round, and the result once
+// reported.
+type FeatureMatch struct {
+	Table       int           `json:"table"`
+	PlayerA     FeaturePlayer `json:"player_a"`
+	PlayerB     FeaturePlayer `json:"player_b"`
+	PlayerAWins int           `json:"player_a_wins"`
+	PlayerBWins int           `json:"player_b_wins"`
+	Draws       int           `json:"draws"`
+	Reported    bool          `json:"reported"`
+}
+
+// FeatureMatches returns the matches of eng's current round at tables, in
+// table order, with the players' places in standings.
+func FeatureMatches(eng *st.Tournament, standings []st.PlayerStanding, tables []int) []FeatureMatch {
+	byID := make(map[int]st.PlayerStanding, len(standings))
+	for _, s := range standings {
+		byID[s.PlayerID] = s
+	}
+	player := func(id int) FeaturePlayer {
+		s := byID[id]
+		fp := FeaturePlayer{ID: id, Rank: s.Rank, Points: s.Points, Wins: s.Wins, Losses: s.Losses, Draws: s.Draws}
+		if p, ok := eng.GetPlayerById(id); ok {
+			fp.Name = p.Name
+		}
+		return fp
+	}
+	sorted := append([]int(nil), tables...)
+	sort.Ints(sorted)
+	out := []FeatureMatch{}
+	for _, table := range sorted {
+		p, ok := pairingAtTable(eng, table)
+		if !ok {
+			continue
+		}
+		out = append(out, FeatureMatch{
+			Table:       table,
+			PlayerA:     player(p.PlayerA()),
+			PlayerB:     player(p.PlayerB()),
+			PlayerAWins: max(p.PlayerAWins(), 0),
+			PlayerBWins: max(p.PlayerBWins(), 0),
+			Draws:       max(p.Draws(), 0),
+			Reported:    p.PlayerAWins() != st.UNINITIALIZED_RESULT,
+		})
+	}
+	return out
+}
@@ -0,0 +1,57 @@
+//go:build integration
+
+package engine
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/models"
+	st "github.com/dstathis/swisstools"
+)
+
+func TestSetFeatureMatches(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	tourn, regs := setupTournamentWithPlayers(t, database, 4)
+	if _, _, err := SetFeatureMatches(ctx, database, tourn.ID, []int{1}); !errors.Is(err, ErrNoRoundUnderway) {
+		t.Errorf("before the start: err = %v, want ErrNoRoundUnderway", err)
+	}
+	if err := WithTournamentEngine(ctx, database, tourn.ID, func(tx *sql.Tx, tm *models.Tournament, eng *st.Tournament) (string, error) {
+		state, err := InitTournamentEngine(ctx, tx, tm, regs)
+		if err != nil {
+			return "", err
+		}
+		*eng, err = st.LoadTournament(state)
+		return models.TournamentStatusInProgress, err
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	round, tables, err := SetFeatureMatches(ctx, database, tourn.ID, []int{2, 1})
+	if err != nil || round != 1 || !reflect.DeepEqual(tables, []int{1, 2}) {
+		t.Fatalf("SetFeatureMatches = %d, %v, %v", round, tables, err)
+	}
+	if _, _, err := SetFeatureMatches(ctx, database, tourn.ID, []int{3}); !errors.Is(err, ErrFeatureTable) {
+		t.Errorf("table 3: err = %v, want ErrFeatureTable", err)
+	}
+	if _, _, err := SetFeatureMatches(ctx, database, tourn.ID, []int{2}); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := db.ListFeatureMatches(ctx, database, tourn.ID, 1); !reflect.DeepEqual(got, []int{2}) {
+		t.Errorf("feature matches = %v, want [2]", got)
+	}
+
+	// A re-paired round starts without any.
+	tm, _ := db.GetTournament(ctx, database, tourn.ID)
+	if err := clearRoundExtras(ctx, database, tm, 1); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := db.ListFeatureMatches(ctx, database, tourn.ID, 1); len(got) != 0 {
+		t.Errorf("after re-pair: %v", got)
+	}
+}
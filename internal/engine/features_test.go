@@ -0,0 +1,43 @@
+package engine
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestCheckFeatureTables(t *testing.T) {
+	eng := startedEngine(t, 5) // tables 1 and 2, and a bye
+	got, err := CheckFeatureTables(eng, []int{2, 1, 2})
+	if err != nil || !reflect.DeepEqual(got, []int{1, 2}) {
+		t.Errorf("CheckFeatureTables = %v, %v; want [1 2]", got, err)
+	}
+	if got, err := CheckFeatureTables(eng, nil); err != nil || len(got) != 0 {
+		t.Errorf("no tables: %v, %v", got, err)
+	}
+	for _, tables := range [][]int{{3}, {0}, {-1}} {
+		if _, err := CheckFeatureTables(eng, tables); !errors.Is(err, ErrFeatureTable) {
+			t.Errorf("%v: err = %v, want ErrFeatureTable", tables, err)
+		}
+	}
+}
+
+func TestFeatureMatches(t *testing.T) {
+	eng := fourPlayerRound(t) // table 1 has a result, table 2 doesn't
+	standings := eng.GetStandings()
+	matches := FeatureMatches(eng, standings, []int{2, 1, 7})
+	if len(matches) != 2 || matches[0].Table != 1 || matches[1].Table != 2 {
+		t.Fatalf("matches = %+v, want tables 1 and 2", matches)
+	}
+	first, p := matches[0], eng.GetRound()[0]
+	if first.PlayerA.ID != p.PlayerA() || first.PlayerA.Name == "" || first.PlayerB.ID != p.PlayerB() {
+		t.Errorf("table 1 players = %+v, %+v", first.PlayerA, first.PlayerB)
+	}
+	// Standings count closed rounds only, so the records are still 0-0-0.
+	if !first.Reported || first.PlayerAWins != 2 || first.PlayerA.Wins != 0 || first.PlayerA.Rank != 1 {
+		t.Errorf("table 1 = %+v", first)
+	}
+	if matches[1].Reported || matches[1].PlayerAWins != 0 {
+		t.Errorf("table 2 = %+v", matches[1])
+	}
+}
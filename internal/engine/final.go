@@ -0,0 +1,110 @@
+package engine
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+
+	"github.com/dstathis/openswiss/internal/models"
+	st "github.com/dstathis/swisstools"
+)
+
+// ErrTournamentFinished is returned by WithTournamentEngine for any change
+// to a complete tournament. Its results stand until it is reset or rolled
+// back.
+var ErrTournamentFinished = errors.New("the tournament is finished; its results can no longer change")
+
+// Complete reports whether a tournament has its final results: it is
+// finished, and either has no top cut or has played it. A tournament whose
+// Swiss rounds finished with the top cut still to start is not complete.
+func Complete(t *models.Tournament, eng *st.Tournament) bool {
+	return t.Status == models.TournamentStatusFinished && (t.TopCut == 0 || eng.GetPlayoff() != nil)
+}
+
+// StandingsHidden reports whether t's standings are hidden from players and
+// spectators: with BlindFinalRound set, from the pairing of the last Swiss
+// round until the Swiss rounds finish, when they show again with the final
+// round's results. A tournament without a set number of rounds, which runs
+// until staff finish it, has no last round to hide.
+func StandingsHidden(t *models.Tournament, eng *st.Tournament) bool {
+	return t.BlindFinalRound && t.Status == models.TournamentStatusInProgress && eng.GetStatus() == "in_progress" &&
+		eng.GetMaxRounds() > 0 && eng.GetCurrentRound() >= eng.GetMaxRounds()
+}
+
+// Placing is a player's final place. Standing holds their Swiss record and
+// tiebreakers. Playoff names how far they got in the top cut ("Champion",
+// "Finalist", "Top 4", ...), and is empty for players outside it.
+type Placing struct {
+	Place    int               `json:"place"`
+	Playoff  string            `json:"playoff,omitempty"`
+	Standing st.PlayerStanding `json:"standing"`
+}
+
+// FinalStandings ranks every player for the results page. Without a top
+// cut this is the Swiss standings. With one, the champion comes first, then
+// the players knocked out in each playoff round from the last round back,
+// those knocked out in the same round in Swiss order, and then everyone
+// outside the cut in Swiss order. order and seeds are as for Standings.
+func FinalStandings(eng *st.Tournament, order []string, seeds map[int]int64) []Placing {
+	swiss := Standings(eng, order, seeds)
+	swissRank := make(map[int]int, len(swiss))
+	for _, s := range swiss {
+		swissRank[s.PlayerID] = s.Rank
+	}
+
+	// out[id] is the playoff round a player went out in; the champion's is
+	// one past the last round.
+	out := map[int]int{}
+	po := eng.GetPlayoff()
+	if po != nil {
+		for r, round := range po.Rounds {
+			for _, p := range round {
+				a, b := p.PlayerA(), p.PlayerB()
+				out[a], out[b] = r, r
+				switch {
+				case p.PlayerAWins() > p.PlayerBWins():
+					out[a] = r + 1
+				case p.PlayerBWins() > p.PlayerAWins():
+					out[b] = r + 1
+				}
+			}
+		}
+	}
+
+	placings := make([]Placing, len(swiss))
+	for i, s := range swiss {
+		placings[i] = Placing{Standing: s}
+		if r, ok := out[s.PlayerID]; ok {
+			placings[i].Playoff = playoffFinish(len(po.Seeds), r)
+		}
+	}
+	sort.SliceStable(placings, func(i, j int) bool {
+		ri, inI := out[placings[i].Standing.PlayerID]
+		rj, inJ := out[placings[j].Standing.PlayerID]
+		if inI != inJ {
+			return inI
+		}
+		if ri != rj {
+			return ri > rj
+		}
+		return swissRank[placings[i].Standing.PlayerID] < swissRank[placings[j].Standing.PlayerID]
+	})
+	for i := range placings {
+		placings[i].Place = i + 1
+	}
+	return placings
+}
+
+// playoffFinish names how far a player got in a bracket of size players
+// by the round, 0-based, they were knocked out in.
+func playoffFinish(size, round int) string {
+	remaining := size >> round
+	switch {
+	case remaining <= 1:
+		return "Champion"
+	case remaining == 2:
+		return "Finalist"
+	default:
+		return "Top " + strconv.Itoa(remaining)
+	}
+}
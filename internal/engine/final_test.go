@@ -0,0 +1,141 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/models"
+	st "github.com/dstathis/swisstools"
+)
+
+// swissDone plays two rounds of four players, the first-listed player of
+// each pairing winning 2-0, and finishes the Swiss rounds.
+func swissDone(t *testing.T) *st.Tournament {
+	t.Helper()
+	eng := playedEngine(t, 4)
+	if err := eng.Pair(false); err != nil {
+		t.Fatalf("pair: %v", err)
+	}
+	for _, p := range eng.GetRound() {
+		if err := eng.AddResult(p.PlayerA(), 2, 0, 0); err != nil {
+			t.Fatalf("add result: %v", err)
+		}
+	}
+	if err := eng.FinishTournament(); err != nil {
+		t.Fatalf("finish: %v", err)
+	}
+	return eng
+}
+
+func TestFinalStandings_Swiss(t *testing.T) {
+	eng := swissDone(t)
+	got := FinalStandings(eng, nil, nil)
+	swiss := Standings(eng, nil, nil)
+	if len(got) != len(swiss) {
+		t.Fatalf("got %d placings, want %d", len(got), len(swiss))
+	}
+	for i, p := range got {
+		if p.Place != i+1 || p.Standing.PlayerID != swiss[i].PlayerID || p.Playoff != "" {
+			t.Errorf("placing %d = %+v, want Swiss rank %d", i, p, swiss[i].Rank)
+		}
+	}
+}
+
+func TestFinalStandings_Playoff(t *testing.T) {
+	eng := swissDone(t)
+	swiss := Standings(eng, nil, nil)
+	if err := eng.StartPlayoff(2); err != nil {
+		t.Fatalf("start playoff: %v", err)
+	}
+	final := eng.GetPlayoff().Rounds[0][0]
+	// The lower seed wins the final.
+	if err := eng.AddPlayoffResult(final.PlayerB(), 2, 1, 0); err != nil {
+		t.Fatalf("add playoff result: %v", err)
+	}
+	if err := eng.NextPlayoffRound(); err != nil {
+		t.Fatalf("next playoff round: %v", err)
+	}
+
+	got := FinalStandings(eng, nil, nil)
+	if got[0].Standing.PlayerID != final.PlayerB() || got[0].Playoff != "Champion" {
+		t.Errorf("first = %+v, want champion %d", got[0], final.PlayerB())
+	}
+	if got[1].Standing.PlayerID != final.PlayerA() || got[1].Playoff != "Finalist" {
+		t.Errorf("second = %+v, want finalist %d", got[1], final.PlayerA())
+	}
+	var rest []st.PlayerStanding
+	for _, s := range swiss {
+		if s.PlayerID != final.PlayerA() && s.PlayerID != final.PlayerB() {
+			rest = append(rest, s)
+		}
+	}
+	for i, p := range got[2:] {
+		if p.Place != i+3 || p.Playoff != "" || p.Standing.PlayerID != rest[i].PlayerID {
+			t.Errorf("placing %d = %+v, want %+v outside the cut", i+2, p, rest[i])
+		}
+	}
+}
+
+func TestComplete(t *testing.T) {
+	eng := swissDone(t)
+	finished := &models.Tournament{Status: models.TournamentStatusFinished}
+	if !Complete(finished, eng) {
+		t.Error("finished tournament without a top cut is not complete")
+	}
+	cut := &models.Tournament{Status: models.TournamentStatusFinished, TopCut: 2}
+	if Complete(cut, eng) {
+		t.Error("complete before its top cut started")
+	}
+	if err := eng.StartPlayoff(2); err != nil {
+		t.Fatal(err)
+	}
+	if Complete(&models.Tournament{Status: models.TournamentStatusPlayoff, TopCut: 2}, eng) {
+		t.Error("complete during its top cut")
+	}
+	if !Complete(cut, eng) {
+		t.Error("finished top cut is not complete")
+	}
+	if Complete(&models.Tournament{Status: models.TournamentStatusInProgress}, eng) {
+		t.Error("running tournament is complete")
+	}
+}
+
+func TestStandingsHidden(t *testing.T) {
+	eng := playedEngine(t, 4)
+	eng.SetMaxRounds(3)
+	blind := &models.Tournament{Status: models.TournamentStatusInProgress, BlindFinalRound: true}
+	if StandingsHidden(blind, eng) {
+		t.Error("hidden in round 2 of 3")
+	}
+	if StandingsHidden(&models.Tournament{Status: models.TournamentStatusInProgress}, eng) {
+		t.Error("hidden without BlindFinalRound")
+	}
+	eng.SetMaxRounds(2)
+	if !StandingsHidden(blind, eng) {
+		t.Error("shown in the last round")
+	}
+	if StandingsHidden(&models.Tournament{Status: models.TournamentStatusInProgress}, eng) {
+		t.Error("hidden in the last round without BlindFinalRound")
+	}
+	eng.SetMaxRounds(0)
+	if StandingsHidden(blind, eng) {
+		t.Error("hidden with no set number of rounds")
+	}
+
+	eng = swissDone(t)
+	if StandingsHidden(&models.Tournament{Status: models.TournamentStatusFinished, BlindFinalRound: true}, eng) {
+		t.Error("hidden once the Swiss rounds finished")
+	}
+}
+
+func TestPlayoffFinish(t *testing.T) {
+	for _, tc := range []struct {
+		size, round int
+		want        string
+	}{
+		{8, 0, "Top 8"}, {8, 1, "Top 4"}, {8, 2, "Finalist"}, {8, 3, "Champion"}, {2, 0, "Finalist"},
+	} {
+		if got := playoffFinish(tc.size, tc.round); got != tc.want {
+			t.Errorf("playoffFinish(%d, %d) = %q, want %q", tc.size, tc.round, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,433 @@
+package engine
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/models"
+	st "github.com/dstathis/swisstools"
+)
+
+// ErrInvalidImport wraps every reason an imported file is refused before
+// anything is written.
+var ErrInvalidImport = errors.New("invalid import")
+
+// ImportedMatch is one match of an imported round, from PlayerA's side.
+// PlayerB is empty for a bye. A match still being played is not Reported.
+type ImportedMatch struct {
+	Table            int
+	PlayerA, PlayerB string
+	Wins, Losses     int
+	Draws            int
+	Reported         bool
+}
+
+func (m ImportedMatch) isBye() bool { return m.PlayerB == "" }
+
+// ImportedEvent is a tournament read from another tool's export. Rounds[0]
+// is round 1; only the last round may have matches still being played.
+type ImportedEvent struct {
+	Rounds [][]ImportedMatch
+}
+
+// Players returns everyone who played, in the order they first appear.
+func (ev *ImportedEvent) Players() []string {
+	var names []string
+	seen := make(map[string]bool)
+	add := func(name string) {
+		if name != "" && !seen[strings.ToLower(name)] {
+			seen[strings.ToLower(name)] = true
+			names = append(names, name)
+		}
+	}
+	for _, round := range ev.Rounds {
+		for _, m := range round {
+			add(m.PlayerA)
+			add(m.PlayerB)
+		}
+	}
+	return names
+}
+
+// importColumns are the headings each column goes by in the exports of
+// common pairing tools, normalised by importHeading.
+var importColumns = map[string][]string{
+	"round":    {"round", "rnd", "round number", "round no"},
+	"table":    {"table", "table number", "table no"},
+	"player":   {"player", "player a", "player 1", "player1", "name", "player name"},
+	"opponent": {"opponent", "player b", "player 2", "player2", "opponent name"},
+	"wins":     {"wins", "wins a", "player a wins", "player 1 wins", "games won", "game wins"},
+	"losses":   {"losses", "wins b", "player b wins", "player 2 wins", "games lost", "game losses"},
+	"draws":    {"draws", "games drawn", "game draws"},
+	"result":   {"result", "score"},
+}
+
+// importHeading lower-cases a column heading and reduces everything but
+// letters and digits to single spaces, so "Player_1" and "Table #" match.
+func importHeading(s string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+}
+
+// ReadImportCSV reads a tournament's matches from a CSV file: one row per
+// match with a header row naming the columns. Round, player and opponent
+// are required, and the result either as wins and losses (draws optional)
+// or as one "2-1" or "2-1-0" result column. An empty opponent or "BYE" is
+// a bye, and an empty result a match still being played. Tools that write
+// each match twice, once from each player's side, are read too: the mirror
+// row must agree. Table numbers, when given, set the seating order.
+func ReadImportCSV(r io.Reader) (*ImportedEvent, error) {
+	ev, err := readImportCSV(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImport, err)
+	}
+	return ev, nil
+}
+
+func readImportCSV(r io.Reader) (*ImportedEvent, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, errors.New("the file is empty")
+	}
+	if err != nil {
+		return nil, err
+	}
+	col := make(map[string]int)
+	for i, h := range header {
+		h = importHeading(strings.TrimPrefix(h, "\ufeff"))
+		for name, aliases := range importColumns {
+			for _, a := range aliases {
+				if _, dup := col[name]; h == a && !dup {
+					col[name] = i
+				}
+			}
+		}
+	}
+	for _, name := range []string{"round", "player", "opponent"} {
+		if _, ok := col[name]; !ok {
+			return nil, fmt.Errorf("no %s column in the header row", name)
+		}
+	}
+	_, hasWins := col["wins"]
+	_, hasLosses := col["losses"]
+	_, hasResult := col["result"]
+	if !(hasWins && hasLosses) && !hasResult {
+		return nil, errors.New("no result columns in the header row: give wins and losses, or a result like 2-1")
+	}
+
+	type seen struct {
+		line  int
+		match ImportedMatch
+	}
+	rounds := make(map[int][]*seen)
+	byPlayer := make(map[[2]string]*seen) // round number and player -> their match
+	key := func(round int, name string) [2]string {
+		return [2]string{strconv.Itoa(round), strings.ToLower(name)}
+	}
+	last := 0
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := cr.FieldPos(0)
+		field := func(name string) string {
+			if i, ok := col[name]; ok && i < len(rec) {
+				return strings.TrimSpace(rec[i])
+			}
+			return ""
+		}
+		if strings.Join(rec, "") == "" {
+			continue
+		}
+
+		round, err := strconv.Atoi(field("round"))
+		if err != nil || round < 1 {
+			return nil, fmt.Errorf("line %d: round %q is not a round number", line, field("round"))
+		}
+		m := ImportedMatch{PlayerA: field("player"), PlayerB: field("opponent")}
+		if m.PlayerA == "" {
+			return nil, fmt.Errorf("line %d: no player", line)
+		}
+		if strings.EqualFold(m.PlayerB, "bye") {
+			m.PlayerB = ""
+		}
+		if strings.EqualFold(m.PlayerA, "bye") {
+			if m.PlayerB == "" {
+				return nil, fmt.Errorf("line %d: no player", line)
+			}
+			m.PlayerA, m.PlayerB = m.PlayerB, ""
+		}
+		if strings.EqualFold(m.PlayerA, m.PlayerB) {
+			return nil, fmt.Errorf("line %d: %s is paired against themselves", line, m.PlayerA)
+		}
+		if t := field("table"); t != "" {
+			if m.Table, err = strconv.Atoi(t); err != nil || m.Table < 0 {
+				return nil, fmt.Errorf("line %d: table %q is not a number", line, t)
+			}
+		}
+		if m.isBye() {
+			m.Table, m.Reported = 0, true
+		} else if m.Reported, err = importResult(&m, field); err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+
+		a, b := byPlayer[key(round, m.PlayerA)], byPlayer[key(round, m.PlayerB)]
+		if m.isBye() {
+			b = nil
+		}
+		switch {
+		case a == nil && b == nil:
+			s := &seen{line: line, match: m}
+			rounds[round] = append(rounds[round], s)
+			byPlayer[key(round, m.PlayerA)] = s
+			if !m.isBye() {
+				byPlayer[key(round, m.PlayerB)] = s
+			}
+			last = max(last, round)
+		case a != nil && a == b || a != nil && m.isBye() && a.match.isBye():
+			// The same match again, maybe from the other player's side.
+			if err := mergeMirror(&a.match, m); err != nil {
+				return nil, fmt.Errorf("line %d: %v from line %d", line, err, a.line)
+			}
+		default:
+			other, name := a, m.PlayerA
+			if other == nil {
+				other, name = b, m.PlayerB
+			}
+			return nil, fmt.Errorf("line %d: %s is already paired in round %d on line %d", line, name, round, other.line)
+		}
+	}
+	if last == 0 {
+		return nil, errors.New("the file has no matches")
+	}
+
+	ev := &ImportedEvent{}
+	for n := 1; n <= last; n++ {
+		if len(rounds[n]) == 0 {
+			return nil, fmt.Errorf("round %d has no matches", n)
+		}
+		round := make([]ImportedMatch, len(rounds[n]))
+		for i, s := range rounds[n] {
+			if !s.match.Reported && n < last {
+				return nil, fmt.Errorf("line %d: round %d has no result; only the latest round can still be playing", s.line, n)
+			}
+			round[i] = s.match
+		}
+		// Seated by table, byes last; matches without a table keep their
+		// order after those with one.
+		sort.SliceStable(round, func(i, j int) bool {
+			if round[i].isBye() != round[j].isBye() {
+				return !round[i].isBye()
+			}
+			ti, tj := round[i].Table, round[j].Table
+			return ti != 0 && (tj == 0 || ti < tj)
+		})
+		ev.Rounds = append(ev.Rounds, round)
+	}
+	return ev, nil
+}
+
+// importResult reads the result of match m from its row, and reports
+// whether there was one.
+func importResult(m *ImportedMatch, field func(string) string) (bool, error) {
+	var parts []string
+	if res := field("result"); res != "" {
+		parts = strings.FieldsFunc(res, func(r rune) bool { return r == '-' || r == ':' || r == '/' || unicode.IsSpace(r) })
+		if len(parts) < 2 || len(parts) > 3 {
+			return false, fmt.Errorf("result %q is not like 2-1 or 2-1-0", res)
+		}
+	} else if field("wins") != "" || field("losses") != "" {
+		parts = []string{field("wins"), field("losses"), field("draws")}
+	} else {
+		return false, nil
+	}
+	var n [3]int
+	for i, p := range parts {
+		if p == "" {
+			continue
+		}
+		v, err := strconv.Atoi(p)
+		if err != nil || v < 0 {
+			return false, fmt.Errorf("result %q is not a number of games", p)
+		}
+		n[i] = v
+	}
+	if n[0]+n[1]+n[2] == 0 {
+		return false, errors.New("the result has no games")
+	}
+	m.Wins, m.Losses, m.Draws = n[0], n[1], n[2]
+	return true, nil
+}
+
+// mergeMirror folds m, a second row for the same match as into, into it:
+// either the same row again or the mirror from the opponent's side. A
+// result on either row counts; two results must agree.
+func mergeMirror(into *ImportedMatch, m ImportedMatch) error {
+	if !strings.EqualFold(into.PlayerA, m.PlayerA) {
+		m.Wins, m.Losses = m.Losses, m.Wins
+	}
+	if into.Table == 0 {
+		into.Table = m.Table
+	} else if m.Table != 0 && m.Table != into.Table {
+		return errors.New("the table differs")
+	}
+	if !m.Reported {
+		return nil
+	}
+	if into.Reported && (into.Wins != m.Wins || into.Losses != m.Losses || into.Draws != m.Draws) {
+		return errors.New("the result differs")
+	}
+	into.Wins, into.Losses, into.Draws, into.Reported = m.Wins, m.Losses, m.Draws, true
+	return nil
+}
+
+// ImportTournament starts t from an event run so far in another tool and
+// returns the engine state, in place of InitTournamentEngine. Players are
+// matched by name, ignoring case, to the confirmed registrations; anyone
+// else becomes a guest, and a confirmed player not in the file is refused,
+// to be dropped first. Every round of ev is replayed with its pairings and
+// results, and the last one is left as the current round, with any result
+// still missing to be entered as usual. A player missing from every round
+// from some point on dropped before it.
+func ImportTournament(ctx context.Context, tx *sql.Tx, t *models.Tournament, regs []models.Registration, ev *ImportedEvent) ([]byte, error) {
+	switch {
+	case t.Status != models.TournamentStatusRegistrationOpen && t.Status != models.TournamentStatusScheduled:
+		return nil, fmt.Errorf("tournament cannot be started from state %s", t.Status)
+	case t.TeamSize > 0:
+		return nil, errors.New("team events can't be imported")
+	case len(ev.Rounds) == 0:
+		return nil, fmt.Errorf("%w: the file has no matches", ErrInvalidImport)
+	case t.NumRounds != nil && *t.NumRounds > 0 && len(ev.Rounds) > *t.NumRounds:
+		return nil, fmt.Errorf("%w: the file has %d rounds but the tournament has %d", ErrInvalidImport, len(ev.Rounds), *t.NumRounds)
+	}
+
+	confirmed := make(map[string]models.Registration)
+	for _, r := range regs {
+		if r.Status == models.RegistrationStatusConfirmed {
+			confirmed[strings.ToLower(r.DisplayName)] = r
+		}
+	}
+	names := ev.Players()
+	var missing []string
+	for key, r := range confirmed {
+		found := false
+		for _, name := range names {
+			found = found || strings.ToLower(name) == key
+		}
+		if !found {
+			missing = append(missing, r.DisplayName)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return nil, fmt.Errorf("%w: %s registered but not in the file; drop them or add their matches",
+			ErrInvalidImport, strings.Join(missing, ", "))
+	}
+
+	eng := st.NewTournamentWithConfig(engineConfig(t))
+	if t.NumRounds != nil && *t.NumRounds > 0 {
+		eng.SetMaxRounds(*t.NumRounds)
+	}
+
+	ids := make(map[string]int) // lower-cased name in the file -> engine player
+	for _, name := range names {
+		r, ok := confirmed[strings.ToLower(name)]
+		if !ok {
+			guest, err := db.AddGuestRegistration(ctx, tx, t.ID, name)
+			if err != nil {
+				return nil, fmt.Errorf("add %s: %w", name, err)
+			}
+			r = *guest
+		}
+		id, err := seatPlayer(ctx, tx, &eng, r)
+		if err != nil {
+			return nil, err
+		}
+		ids[strings.ToLower(name)] = id
+	}
+	if err := replayImport(&eng, ids, ev); err != nil {
+		return nil, err
+	}
+	if n := autoRounds(t, len(names)); n > 0 {
+		eng.SetMaxRounds(max(n, len(ev.Rounds)))
+	}
+	return eng.DumpTournament()
+}
+
+// replayImport starts eng, whose players are ids by lower-cased name, and
+// plays ev's rounds into it.
+func replayImport(eng *st.Tournament, ids map[string]int, ev *ImportedEvent) error {
+	lastRound := make(map[int]int) // engine player -> last round they play
+	for i, round := range ev.Rounds {
+		for _, m := range round {
+			lastRound[ids[strings.ToLower(m.PlayerA)]] = i + 1
+			if !m.isBye() {
+				lastRound[ids[strings.ToLower(m.PlayerB)]] = i + 1
+			}
+		}
+	}
+
+	if err := eng.StartTournament(); err != nil {
+		return fmt.Errorf("start tournament: %w", err)
+	}
+	for i, round := range ev.Rounds {
+		n := i + 1
+		if n > 1 {
+			if err := eng.NextRound(); err != nil {
+				return fmt.Errorf("close round %d: %w", n-1, err)
+			}
+			for id, last := range lastRound {
+				if last < n {
+					if err := eng.RemovePlayerById(id); err != nil {
+						return err
+					}
+					delete(lastRound, id)
+				}
+			}
+		}
+		if err := editState(eng, func(s *engineState) error {
+			pairings := make([]statePairing, 0, len(round))
+			for _, m := range round {
+				p := statePairing{
+					PlayerA:     ids[strings.ToLower(m.PlayerA)],
+					PlayerB:     st.BYE_OPPONENT_ID,
+					PlayerAWins: s.Config.ByeWins,
+					PlayerBWins: s.Config.ByeLosses,
+					Draws:       s.Config.ByeDraws,
+				}
+				switch {
+				case m.isBye():
+				case m.Reported:
+					p.PlayerB = ids[strings.ToLower(m.PlayerB)]
+					p.PlayerAWins, p.PlayerBWins, p.Draws = m.Wins, m.Losses, m.Draws
+				default:
+					p.PlayerB = ids[strings.ToLower(m.PlayerB)]
+					p.PlayerAWins, p.PlayerBWins, p.Draws = st.UNINITIALIZED_RESULT, st.UNINITIALIZED_RESULT, st.UNINITIALIZED_RESULT
+				}
+				pairings = append(pairings, p)
+			}
+			s.Rounds[s.CurrentRound] = pairings
+			return nil
+		}); err != nil {
+			return fmt.Errorf("round %d: %w", n, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/models"
+	st "github.com/dstathis/swisstools"
+)
+
+func TestReadImportCSV(t *testing.T) {
+	// Mirrored rows from each side, a bye, and a round still in play, out
+	// of table order.
+	file := "\ufeffRound,Table #,Player_1,Player 2,Result\n" +
+		"1,1,Alice,Bob,2-1\n" +
+		"1,1,Bob,Alice,1-2\n" +
+		"1,2,Carol,Dan,0-2-1\n" +
+		"1,,Eve,BYE,\n" +
+		"\n" +
+		"2,2,Alice,Dan,\n" +
+		"2,1,Bob,Eve,2-0\n" +
+		"2,,bye,Carol,\n"
+	ev, err := ReadImportCSV(strings.NewReader(file))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ev.Rounds) != 2 || len(ev.Rounds[0]) != 3 || len(ev.Rounds[1]) != 3 {
+		t.Fatalf("rounds = %+v", ev.Rounds)
+	}
+	if m := ev.Rounds[0][1]; m.PlayerA != "Carol" || m.Wins != 0 || m.Losses != 2 || m.Draws != 1 || !m.Reported {
+		t.Errorf("round 1 table 2 = %+v", m)
+	}
+	if m := ev.Rounds[0][2]; m.PlayerA != "Eve" || !m.isBye() {
+		t.Errorf("round 1 bye = %+v", m)
+	}
+	if m := ev.Rounds[1][0]; m.Table != 1 || m.PlayerA != "Bob" || !m.Reported {
+		t.Errorf("round 2 table 1 = %+v", m)
+	}
+	if m := ev.Rounds[1][1]; m.PlayerA != "Alice" || m.Reported {
+		t.Errorf("round 2 table 2 = %+v", m)
+	}
+	if m := ev.Rounds[1][2]; m.PlayerA != "Carol" || !m.isBye() {
+		t.Errorf("round 2 bye = %+v", m)
+	}
+	if got := strings.Join(ev.Players(), ","); got != "Alice,Bob,Carol,Dan,Eve" {
+		t.Errorf("players = %s", got)
+	}
+
+	// Separate game columns, draws optional.
+	ev, err = ReadImportCSV(strings.NewReader("rnd,player,opponent,games won,games lost\n1,Alice,Bob,1,2\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m := ev.Rounds[0][0]; m.Wins != 1 || m.Losses != 2 || m.Draws != 0 || !m.Reported {
+		t.Errorf("game columns = %+v", m)
+	}
+}
+
+func TestReadImportCSV_Refused(t *testing.T) {
+	for name, tc := range map[string]struct{ file, want string }{
+		"empty":           {"", "empty"},
+		"no opponent":     {"round,player,result\n", "no opponent column"},
+		"no result":       {"round,player,opponent\n", "no result columns"},
+		"no matches":      {"round,player,opponent,result\n", "no matches"},
+		"bad round":       {"round,player,opponent,result\nfirst,A,B,2-0\n", `line 2: round "first"`},
+		"bad result":      {"round,player,opponent,result\n1,A,B,won\n", `result "won"`},
+		"no games":        {"round,player,opponent,result\n1,A,B,0-0\n", "no games"},
+		"against self":    {"round,player,opponent,result\n1,A,a,2-0\n", "against themselves"},
+		"paired twice":    {"round,player,opponent,result\n1,A,B,2-0\n1,C,A,2-0\n", "A is already paired in round 1 on line 2"},
+		"mirror disagree": {"round,player,opponent,result\n1,A,B,2-0\n1,B,A,2-0\n", "line 3: the result differs from line 2"},
+		"round gap":       {"round,player,opponent,result\n1,A,B,2-0\n3,A,B,2-0\n", "round 2 has no matches"},
+		"unreported":      {"round,player,opponent,result\n1,A,B,\n2,A,B,\n", "only the latest round"},
+	} {
+		_, err := ReadImportCSV(strings.NewReader(tc.file))
+		if !errors.Is(err, ErrInvalidImport) || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: err = %v, want %q", name, err, tc.want)
+		}
+	}
+}
+
+func TestImportTournament_Refused(t *testing.T) {
+	ev := &ImportedEvent{Rounds: [][]ImportedMatch{{{PlayerA: "Alice", PlayerB: "Bob", Wins: 2, Reported: true}}}}
+	one := 1
+	open := models.Tournament{Status: models.TournamentStatusRegistrationOpen}
+	for name, tc := range map[string]struct {
+		t    models.Tournament
+		regs []models.Registration
+		want string
+	}{
+		"started":     {models.Tournament{Status: models.TournamentStatusInProgress}, nil, "cannot be started"},
+		"team event":  {models.Tournament{Status: models.TournamentStatusScheduled, TeamSize: 3}, nil, "team events"},
+		"not in file": {open, []models.Registration{{DisplayName: "Carol", Status: models.RegistrationStatusConfirmed}}, "Carol registered but not in the file"},
+	} {
+		if _, err := ImportTournament(context.Background(), nil, &tc.t, tc.regs, ev); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: err = %v, want %q", name, err, tc.want)
+		}
+	}
+	two := &ImportedEvent{Rounds: append(ev.Rounds, ev.Rounds[0])}
+	if _, err := ImportTournament(context.Background(), nil, &models.Tournament{Status: models.TournamentStatusScheduled, NumRounds: &one}, nil, two); !errors.Is(err, ErrInvalidImport) {
+		t.Errorf("more rounds than the tournament: err = %v", err)
+	}
+}
+
+func TestReplayImport(t *testing.T) {
+	ev, err := ReadImportCSV(strings.NewReader("round,table,player,opponent,result\n" +
+		"1,1,Carol,Dan,0-2-1\n1,2,Alice,Bob,2-1\n1,,Eve,bye,\n" +
+		"2,1,Bob,Eve,2-0\n2,2,Alice,Carol,\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	eng := st.NewTournamentWithConfig(st.TournamentConfig{PointsForWin: 3, PointsForDraw: 1, ByeWins: st.BYE_WINS})
+	ids := make(map[string]int)
+	for _, name := range ev.Players() {
+		eng.AddPlayer(name)
+		ids[strings.ToLower(name)], _ = eng.GetPlayerID(name)
+	}
+	if err := replayImport(&eng, ids, ev); err != nil {
+		t.Fatal(err)
+	}
+
+	if eng.GetCurrentRound() != 2 {
+		t.Fatalf("current round = %d, want 2", eng.GetCurrentRound())
+	}
+	round1, _ := eng.GetRoundByNumber(1)
+	if len(round1) != 3 || round1[0].PlayerA() != ids["carol"] || round1[2].PlayerB() != st.BYE_OPPONENT_ID {
+		t.Errorf("round 1 = %v", round1)
+	}
+	points := map[string]int{"alice": 3, "bob": 0, "carol": 0, "dan": 3, "eve": 3}
+	for name, want := range points {
+		if p, _ := eng.GetPlayerById(ids[name]); p.Points != want {
+			t.Errorf("%s has %d points, want %d", name, p.Points, want)
+		}
+	}
+	// Dan is missing from round 2, the last, so has dropped; the match
+	// still being played waits for its result.
+	if p, _ := eng.GetPlayerById(ids["dan"]); !p.Removed || p.RemovedInRound != 2 {
+		t.Errorf("dan = %+v, want dropped in round 2", p)
+	}
+	round2 := eng.GetRound()
+	if len(round2) != 2 || round2[0].PlayerAWins() != 2 || round2[1].PlayerAWins() != st.UNINITIALIZED_RESULT {
+		t.Errorf("round 2 = %v", round2)
+	}
+	if n := PendingResults(&eng); n != 1 {
+		t.Errorf("pending results = %d, want 1", n)
+	}
+}
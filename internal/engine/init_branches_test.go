@@ -185,3 +185,49 @@ func TestInitTournamentEngine_GuestNoExternalID(t *testing.T) {
 		}
 	}
 }
+
+func TestInitTournamentEngine_RoundRobinRunsOneCycle(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+
+	org, _ := db.CreateUser(ctx, database, "org-rr@example.com", "OrgRR", "hash")
+	tourn := &models.Tournament{
+		Name:             "Round Robin",
+		PointsWin:        3,
+		PointsDraw:       1,
+		PairingAlgorithm: models.PairingRoundRobin,
+		Status:           models.TournamentStatusRegistrationOpen,
+		OrganizerID:      org.ID,
+	}
+	if err := db.CreateTournament(ctx, database, tourn); err != nil {
+		t.Fatalf("create tournament: %v", err)
+	}
+	for _, name := range []string{"RR1", "RR2", "RR3", "RR4", "RR5"} {
+		if _, err := db.CreateGuestRegistration(ctx, database, tourn.ID, name); err != nil {
+			t.Fatalf("guest %s: %v", name, err)
+		}
+	}
+
+	regs, _ := db.ListRegistrations(ctx, database, tourn.ID)
+	tx, err := database.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+	defer tx.Rollback()
+	state, err := InitTournamentEngine(ctx, tx, tourn, regs)
+	if err != nil {
+		t.Fatalf("InitTournamentEngine: %v", err)
+	}
+	eng, _ := st.LoadTournament(state)
+	if got := eng.GetMaxRounds(); got != 5 {
+		t.Errorf("max rounds = %d, want 5 for five players", got)
+	}
+	// Round 1 of the schedule: the lowest seed sits out.
+	opp := map[int]int{}
+	for _, p := range eng.GetRound() {
+		opp[p.PlayerA()], opp[p.PlayerB()] = p.PlayerB(), p.PlayerA()
+	}
+	if id, _ := eng.GetPlayerID("RR5"); opp[id] != st.BYE_OPPONENT_ID {
+		t.Errorf("round 1 = %v, want a bye for RR5", eng.GetRound())
+	}
+}
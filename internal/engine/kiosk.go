@@ -0,0 +1,79 @@
+package engine
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/models"
+	st "github.com/dstathis/swisstools"
+)
+
+// ErrKioskPIN is returned when a kiosk's table number and PIN don't match a
+// match of the current round. It doesn't say which was wrong.
+var ErrKioskPIN = errors.New("wrong table number or PIN")
+
+// TablePIN is the six-digit PIN on the slip of pairing p, at table in round.
+// It is an HMAC of the seat under the tournament's kiosk secret, so none
+// are stored. The players are part of it: once a re-pair seats someone
+// else at the table, the old slip stops working.
+func TablePIN(secret string, round, table int, p st.Pairing) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d:%d:%d:%d", round, table, p.PlayerA(), p.PlayerB())
+	return fmt.Sprintf("%06d", binary.BigEndian.Uint32(mac.Sum(nil))%1_000_000)
+}
+
+// TablePINs returns the PIN of every table in the current round, keyed by
+// table number. Byes have no table and no PIN.
+func TablePINs(secret string, eng *st.Tournament) map[int]string {
+	pins := make(map[int]string)
+	for i, p := range eng.GetRound() {
+		if table := TableNumber(i, p); table > 0 {
+			pins[table] = TablePIN(secret, eng.GetCurrentRound(), table, p)
+		}
+	}
+	return pins
+}
+
+// KioskMatch returns the current round's match at table if pin is its PIN
+// and players may report it at the kiosk: the round is a published Swiss
+// round, the match has no result yet, and the tournament doesn't report
+// game by game (series mode) or seat by seat (teams). Results already in
+// are left to staff.
+func KioskMatch(t *models.Tournament, eng *st.Tournament, secret string, table int, pin string) (st.Pairing, error) {
+	round := eng.GetCurrentRound()
+	if secret == "" || t.Status != models.TournamentStatusInProgress || t.PairingsDraft(round) {
+		return st.Pairing{}, errors.New("there is no round to report a result for")
+	}
+	p, ok := pairingAtTable(eng, table)
+	if !ok || subtle.ConstantTimeCompare([]byte(TablePIN(secret, round, table, p)), []byte(pin)) != 1 {
+		return st.Pairing{}, ErrKioskPIN
+	}
+	if t.SeriesMode || t.TeamSize > 0 {
+		return st.Pairing{}, errors.New("this match can't be reported at the kiosk; please see a judge")
+	}
+	if p.PlayerAWins() != st.UNINITIALIZED_RESULT {
+		return st.Pairing{}, errors.New("this match already has a result; please see a judge to change it")
+	}
+	return p, nil
+}
+
+// KioskReport enters a result from the kiosk: the games won by the table's
+// player A (wins) and player B (losses), and drawn games. The table and PIN
+// are checked as in KioskMatch.
+func KioskReport(ctx context.Context, tx db.DBTX, t *models.Tournament, eng *st.Tournament, secret string, table int, pin string, wins, losses, draws int) error {
+	p, err := KioskMatch(t, eng, secret, table, pin)
+	if err != nil {
+		return err
+	}
+	games := wins + losses + draws
+	if wins < 0 || losses < 0 || draws < 0 || games == 0 {
+		return errors.New("enter the number of games each player won")
+	}
+	return RecordResult(ctx, tx, t, eng, p.PlayerA(), wins, losses, draws)
+}
@@ -0,0 +1,64 @@
+package engine
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+func TestTablePINs(t *testing.T) {
+	eng := fourPlayerRound(t)
+	pins := TablePINs("secret", eng)
+	if len(pins) != 2 || len(pins[1]) != 6 || len(pins[2]) != 6 {
+		t.Fatalf("pins = %v, want six digits for tables 1 and 2", pins)
+	}
+	if again := TablePINs("secret", eng); again[1] != pins[1] {
+		t.Error("PINs aren't stable")
+	}
+	if other := TablePINs("other", eng); other[1] == pins[1] && other[2] == pins[2] {
+		t.Error("a new secret kept the PINs")
+	}
+	p := eng.GetRound()[0]
+	if TablePIN("secret", 2, 1, p) == pins[1] && TablePIN("secret", 1, 2, p) == pins[1] {
+		t.Error("PIN doesn't depend on the round or table")
+	}
+}
+
+func TestKioskMatch(t *testing.T) {
+	eng := fourPlayerRound(t) // table 1 has a result, table 2 doesn't
+	pins := TablePINs("secret", eng)
+	tm := &models.Tournament{Status: models.TournamentStatusInProgress}
+
+	p, err := KioskMatch(tm, eng, "secret", 2, pins[2])
+	if err != nil {
+		t.Fatalf("KioskMatch: %v", err)
+	}
+	if want := eng.GetRound()[1]; p.PlayerA() != want.PlayerA() {
+		t.Errorf("match = %v, want table 2's", p)
+	}
+	for _, tc := range []struct {
+		table int
+		pin   string
+	}{{2, pins[1]}, {3, pins[2]}, {2, ""}} {
+		if _, err := KioskMatch(tm, eng, "secret", tc.table, tc.pin); !errors.Is(err, ErrKioskPIN) {
+			t.Errorf("table %d pin %q: err = %v, want ErrKioskPIN", tc.table, tc.pin, err)
+		}
+	}
+	if _, err := KioskMatch(tm, eng, "secret", 1, pins[1]); err == nil {
+		t.Error("reported match accepted")
+	}
+	if _, err := KioskMatch(tm, eng, "", 2, pins[2]); err == nil {
+		t.Error("kiosk off but match accepted")
+	}
+	for _, refused := range []*models.Tournament{
+		{Status: models.TournamentStatusFinished},
+		{Status: models.TournamentStatusInProgress, PreviewPairings: true, DraftRound: 1},
+		{Status: models.TournamentStatusInProgress, SeriesMode: true, BestOf: 3},
+		{Status: models.TournamentStatusInProgress, TeamSize: 3},
+	} {
+		if _, err := KioskMatch(refused, eng, "secret", 2, pins[2]); err == nil || errors.Is(err, ErrKioskPIN) {
+			t.Errorf("%+v: err = %v, want a refusal", refused, err)
+		}
+	}
+}
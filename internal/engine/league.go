@@ -0,0 +1,167 @@
+package engine
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/models"
+	st "github.com/dstathis/swisstools"
+)
+
+// LeagueEvent is one of a league's complete tournaments with its final
+// placings (see FinalStandings) and the registrations behind them.
+type LeagueEvent struct {
+	Tournament *models.Tournament
+	Regs       []models.Registration
+	Placings   []Placing
+}
+
+// LeagueFinish is where a player finished in one of a league's tournaments
+// and the league points it earned them.
+type LeagueFinish struct {
+	TournamentID int64 `json:"tournament_id"`
+	Place        int   `json:"place"`
+	Points       int   `json:"points"`
+}
+
+// LeagueStanding is one player's line on a league leaderboard. Players
+// level on points, titles and best finish share a rank. PlayerID is their
+// player registry entry, when they have one.
+type LeagueStanding struct {
+	Rank     int            `json:"rank"`
+	Name     string         `json:"name"`
+	PlayerID *int64         `json:"player_id,omitempty"`
+	Points   int            `json:"points"`
+	Events   int            `json:"events"`
+	Titles   int            `json:"titles"`
+	Best     int            `json:"best"`
+	Finishes []LeagueFinish `json:"finishes"`
+}
+
+// leagueKey is who a registration's player is across tournaments: their
+// registry entry, else their account, else their name ignoring case.
+func leagueKey(r models.Registration) string {
+	switch {
+	case r.PlayerID != nil:
+		return "player:" + strconv.FormatInt(*r.PlayerID, 10)
+	case r.UserID != nil:
+		return "user:" + strconv.FormatInt(*r.UserID, 10)
+	}
+	return "name:" + strings.ToLower(r.DisplayName)
+}
+
+// LeagueStandings adds up the league points of every player placed in
+// events, which should be in the order they were held: each finish is
+// worth l.PointsFor its place. A player's name is the one they last played
+// under. The leaderboard is ordered by points, then titles (first places),
+// then best finish, then name.
+func LeagueStandings(l *models.League, events []LeagueEvent) []LeagueStanding {
+	byKey := make(map[string]*LeagueStanding)
+	for _, e := range events {
+		regByEngine := make(map[int]models.Registration, len(e.Regs))
+		for _, r := range e.Regs {
+			if r.EnginePlayerID != nil {
+				regByEngine[*r.EnginePlayerID] = r
+			}
+		}
+		for _, p := range e.Placings {
+			reg, ok := regByEngine[p.Standing.PlayerID]
+			if !ok {
+				reg = models.Registration{DisplayName: p.Standing.Name}
+			}
+			key := leagueKey(reg)
+			s := byKey[key]
+			if s == nil {
+				s = &LeagueStanding{PlayerID: reg.PlayerID}
+				byKey[key] = s
+			}
+			s.Name = p.Standing.Name
+			points := l.PointsFor(p.Place)
+			s.Points += points
+			s.Events++
+			if p.Place == 1 {
+				s.Titles++
+			}
+			if s.Best == 0 || p.Place < s.Best {
+				s.Best = p.Place
+			}
+			s.Finishes = append(s.Finishes, LeagueFinish{TournamentID: e.Tournament.ID, Place: p.Place, Points: points})
+		}
+	}
+
+	out := make([]LeagueStanding, 0, len(byKey))
+	for _, s := range byKey {
+		out = append(out, *s)
+	}
+	level := func(a, b LeagueStanding) bool {
+		return a.Points == b.Points && a.Titles == b.Titles && a.Best == b.Best
+	}
+	sort.Slice(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		switch {
+		case a.Points != b.Points:
+			return a.Points > b.Points
+		case a.Titles != b.Titles:
+			return a.Titles > b.Titles
+		case a.Best != b.Best:
+			return a.Best < b.Best
+		}
+		return strings.ToLower(a.Name) < strings.ToLower(b.Name)
+	})
+	for i := range out {
+		out[i].Rank = i + 1
+		if i > 0 && level(out[i], out[i-1]) {
+			out[i].Rank = out[i-1].Rank
+		}
+	}
+	return out
+}
+
+// LeagueTable is a league's leaderboard with the tournaments behind it:
+// Counted are complete and scored, Pending are not complete yet and don't
+// count until they are.
+type LeagueTable struct {
+	League    *models.League      `json:"league"`
+	Counted   []models.Tournament `json:"counted"`
+	Pending   []models.Tournament `json:"pending"`
+	Standings []LeagueStanding    `json:"standings"`
+}
+
+// LoadLeagueTable loads l's tournaments and scores the complete ones.
+func LoadLeagueTable(ctx context.Context, database *sql.DB, l *models.League) (*LeagueTable, error) {
+	tournaments, err := db.ListLeagueTournaments(ctx, database, l.ID)
+	if err != nil {
+		return nil, err
+	}
+	table := &LeagueTable{League: l, Counted: []models.Tournament{}, Pending: []models.Tournament{}}
+	var events []LeagueEvent
+	for i := range tournaments {
+		t := &tournaments[i]
+		var eng st.Tournament
+		if len(t.EngineState) > 0 {
+			if eng, err = st.LoadTournament(t.EngineState); err != nil {
+				return nil, err
+			}
+		}
+		if len(t.EngineState) == 0 || !Complete(t, &eng) {
+			table.Pending = append(table.Pending, *t)
+			continue
+		}
+		regs, err := db.ListRegistrations(ctx, database, t.ID)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, LeagueEvent{
+			Tournament: t,
+			Regs:       regs,
+			Placings:   FinalStandings(&eng, t.TiebreakOrder(), TiebreakSeeds(regs)),
+		})
+		table.Counted = append(table.Counted, *t)
+	}
+	table.Standings = LeagueStandings(l, events)
+	return table, nil
+}
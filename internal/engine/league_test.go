@@ -0,0 +1,65 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/models"
+	st "github.com/dstathis/swisstools"
+)
+
+// leagueEvent builds a finished event whose players placed in the order
+// given; each name is registered as the guest it is unless ids maps it to
+// a registry player.
+func leagueEvent(id int64, ids map[string]int64, names ...string) LeagueEvent {
+	e := LeagueEvent{Tournament: &models.Tournament{ID: id}}
+	for i, name := range names {
+		engineID := i + 1
+		reg := models.Registration{DisplayName: name, EnginePlayerID: &engineID}
+		if pid, ok := ids[name]; ok {
+			reg.PlayerID = &pid
+		}
+		e.Regs = append(e.Regs, reg)
+		e.Placings = append(e.Placings, Placing{Place: i + 1, Standing: st.PlayerStanding{PlayerID: engineID, Name: name}})
+	}
+	return e
+}
+
+func TestLeagueStandings(t *testing.T) {
+	l := &models.League{PlacementPoints: []int{5, 3, 1}, ParticipationPoints: 1}
+	ids := map[string]int64{"Ada": 1, "Ada L.": 1}
+	events := []LeagueEvent{
+		leagueEvent(1, ids, "Ada", "Bea", "Cy", "Dee"),
+		leagueEvent(2, ids, "bea", "Ada L.", "Dee"),
+		leagueEvent(3, ids, "Cy", "Eve"),
+	}
+	got := LeagueStandings(l, events)
+
+	want := []struct {
+		rank             int
+		name             string
+		points, titles   int
+		events, best     int
+		registryPlayerID bool
+	}{
+		// Bea and bea are one guest by name; Ada plays on under a new name
+		// but is the same registry player.
+		{1, "Ada L.", 8, 1, 2, 1, true},
+		{1, "bea", 8, 1, 2, 1, false},
+		{3, "Cy", 6, 1, 2, 1, false},
+		{4, "Eve", 3, 0, 1, 2, false},
+		{5, "Dee", 2, 0, 2, 3, false},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d standings, want %d: %+v", len(got), len(want), got)
+	}
+	for i, w := range want {
+		g := got[i]
+		if g.Rank != w.rank || g.Name != w.name || g.Points != w.points || g.Titles != w.titles ||
+			g.Events != w.events || g.Best != w.best || (g.PlayerID != nil) != w.registryPlayerID {
+			t.Errorf("standing %d = %+v, want %+v", i, g, w)
+		}
+	}
+	if f := got[0].Finishes; len(f) != 2 || f[1] != (LeagueFinish{TournamentID: 2, Place: 2, Points: 3}) {
+		t.Errorf("Ada's finishes = %+v", f)
+	}
+}
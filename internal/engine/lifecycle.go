@@ -0,0 +1,230 @@
+package engine
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/models"
+	st "github.com/dstathis/swisstools"
+)
+
+// Lifecycle actions, as named by the lifecycle API.
+const (
+	ActionStart     = "start"
+	ActionPair      = "pair"
+	ActionNextRound = "next_round"
+	ActionPublish   = "publish"
+	ActionFinish    = "finish"
+	ActionReset     = "reset"
+)
+
+// actionTiers is the staff tier each lifecycle action needs. Reset throws
+// away every result, so it is reserved for tournament admins.
+var actionTiers = map[string]models.TournamentTier{
+	ActionStart:     models.TierCoOrganizer,
+	ActionPair:      models.TierCoOrganizer,
+	ActionNextRound: models.TierCoOrganizer,
+	ActionPublish:   models.TierCoOrganizer,
+	ActionFinish:    models.TierCoOrganizer,
+	ActionReset:     models.TierAdmin,
+}
+
+// ActionTier returns the staff tier needed to run action, and false for an
+// unknown action.
+func ActionTier(action string) (models.TournamentTier, bool) {
+	tier, ok := actionTiers[action]
+	return tier, ok
+}
+
+// Precondition says whether a lifecycle action can run in the tournament's
+// current state. Reason explains why not.
+type Precondition struct {
+	Allowed bool                  `json:"allowed"`
+	MinTier models.TournamentTier `json:"min_tier"`
+	Reason  string                `json:"reason,omitempty"`
+}
+
+// PreconditionError is returned by RunAction and Reset when the action's
+// precondition doesn't hold.
+type PreconditionError struct {
+	Action string
+	Precondition
+}
+
+func (e *PreconditionError) Error() string {
+	return e.Reason
+}
+
+// PendingResults counts the matches of the current round still waiting for a
+// result. Byes never do.
+func PendingResults(eng *st.Tournament) int {
+	return len(MissingTables(eng))
+}
+
+// Preconditions evaluates every lifecycle action against the tournament's
+// state. eng is nil until the tournament has started.
+func Preconditions(t *models.Tournament, eng *st.Tournament, regs []models.Registration) map[string]Precondition {
+	out := make(map[string]Precondition, len(actionTiers))
+	set := func(action, reason string) {
+		out[action] = Precondition{Allowed: reason == "", MinTier: actionTiers[action], Reason: reason}
+	}
+
+	start := CheckStart(t, regs)
+	set(ActionStart, start.Reason)
+
+	inProgress := ""
+	if t.Status != models.TournamentStatusInProgress || eng == nil {
+		inProgress = fmt.Sprintf("tournament is %s, not in_progress", t.Status)
+	}
+	set(ActionPair, inProgress)
+	draft := inProgress
+	if draft == "" && !t.PairingsDraft(eng.GetCurrentRound()) {
+		draft = fmt.Sprintf("round %d has no draft pairings", eng.GetCurrentRound())
+	}
+	set(ActionPublish, draft)
+	resultsIn := inProgress
+	if resultsIn == "" {
+		if t.PairingsDraft(eng.GetCurrentRound()) {
+			resultsIn = fmt.Sprintf("round %d's pairings are still a draft", eng.GetCurrentRound())
+		} else if n := PendingResults(eng); n > 0 {
+			matches := "matches"
+			if n == 1 {
+				matches = "match"
+			}
+			resultsIn = fmt.Sprintf("round %d has %d %s without a result", eng.GetCurrentRound(), n, matches)
+		}
+	}
+	set(ActionNextRound, resultsIn)
+	set(ActionFinish, resultsIn)
+
+	switch t.Status {
+	case models.TournamentStatusInProgress, models.TournamentStatusPlayoff, models.TournamentStatusFinished:
+		set(ActionReset, "")
+	default:
+		set(ActionReset, "tournament has not started")
+	}
+	return out
+}
+
+// RunAction performs a lifecycle action other than reset. It must run inside
+// WithTournamentEngine; the precondition is re-checked against the locked
+// row so concurrent callers can't both advance a round. It returns the
+// tournament's new status, or "" to keep it.
+func RunAction(ctx context.Context, tx *sql.Tx, t *models.Tournament, eng *st.Tournament, regs []models.Registration, action string) (string, error) {
+	var started *st.Tournament
+	if t.EngineState != nil {
+		started = eng
+	}
+	pc, ok := Preconditions(t, started, regs)[action]
+	if !ok || action == ActionReset {
+		return "", fmt.Errorf("unknown action %q", action)
+	}
+	if !pc.Allowed {
+		return "", &PreconditionError{Action: action, Precondition: pc}
+	}
+
+	switch action {
+	case ActionStart:
+		state, err := InitTournamentEngine(ctx, tx, t, regs)
+		if err != nil {
+			return "", err
+		}
+		ne, err := st.LoadTournament(state)
+		if err != nil {
+			return "", err
+		}
+		*eng = ne
+		return models.TournamentStatusInProgress, nil
+	case ActionPair:
+		return "", RepairRound(ctx, tx, t, eng)
+	case ActionPublish:
+		return "", PublishPairings(ctx, tx, t, eng)
+	case ActionNextRound:
+		assigned, err := AssignedByes(ctx, tx, t, eng)
+		if err != nil {
+			return "", err
+		}
+		clubs, err := Clubs(ctx, tx, t)
+		if err != nil {
+			return "", err
+		}
+		seeds, err := Seeds(ctx, tx, t)
+		if err != nil {
+			return "", err
+		}
+		return AdvanceRound(eng, t, false, assigned, clubs, seeds)
+	default: // ActionFinish
+		if err := eng.FinishTournament(); err != nil {
+			return "", err
+		}
+		return models.TournamentStatusFinished, nil
+	}
+}
+
+// RepairRound throws away the current round's pairings and pairs it again.
+// Pairing field values, series games, seat results and result types
+// describe tables, whose matches just changed, so they go too.
+func RepairRound(ctx context.Context, tx db.DBTX, t *models.Tournament, eng *st.Tournament) error {
+	assigned, err := AssignedByes(ctx, tx, t, eng)
+	if err != nil {
+		return err
+	}
+	clubs, err := Clubs(ctx, tx, t)
+	if err != nil {
+		return err
+	}
+	seeds, err := Seeds(ctx, tx, t)
+	if err != nil {
+		return err
+	}
+	if err := PairRound(eng, t, true, assigned[eng.GetCurrentRound()], clubs, seeds); err != nil {
+		return err
+	}
+	return clearRoundExtras(ctx, tx, t, eng.GetCurrentRound())
+}
+
+// clearRoundExtras deletes the series games, seat results, special result
+// types, player result reports, pairing field values and feature matches of
+// a round whose pairings were replaced.
+func clearRoundExtras(ctx context.Context, tx db.DBTX, t *models.Tournament, round int) error {
+	if err := db.ClearMatchGames(ctx, tx, t.ID, round); err != nil {
+		return err
+	}
+	if err := db.ClearSeatResults(ctx, tx, t.ID, round); err != nil {
+		return err
+	}
+	if err := db.ClearMatchResultTypes(ctx, tx, t.ID, round); err != nil {
+		return err
+	}
+	if err := db.ClearResultReports(ctx, tx, t.ID, round); err != nil {
+		return err
+	}
+	if err := db.ClearPairingFieldValues(ctx, tx, t.ID, round); err != nil {
+		return err
+	}
+	return db.ClearFeatureMatches(ctx, tx, t.ID, round)
+}
+
+// Reset puts a started tournament back to registration_open, discarding its
+// pairings and results (see db.ResetTournament).
+func Reset(ctx context.Context, database *sql.DB, tournamentID int64) error {
+	tx, err := database.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	t, err := db.GetTournamentForUpdate(ctx, tx, tournamentID)
+	if err != nil {
+		return fmt.Errorf("get tournament: %w", err)
+	}
+	if pc := Preconditions(t, nil, nil)[ActionReset]; !pc.Allowed {
+		return &PreconditionError{Action: ActionReset, Precondition: pc}
+	}
+	if err := db.ResetTournament(ctx, tx, tournamentID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,62 @@
+package engine
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/models"
+	st "github.com/dstathis/swisstools"
+)
+
+func TestPreconditions(t *testing.T) {
+	tm := &models.Tournament{Status: models.TournamentStatusRegistrationOpen, MinPlayers: 2}
+	pcs := Preconditions(tm, nil, confirmedRegs(3))
+	if len(pcs) != len(actionTiers) {
+		t.Fatalf("got %d actions, want %d", len(pcs), len(actionTiers))
+	}
+	if !pcs[ActionStart].Allowed {
+		t.Errorf("start refused: %s", pcs[ActionStart].Reason)
+	}
+	for _, a := range []string{ActionPair, ActionNextRound, ActionFinish, ActionReset} {
+		if pcs[a].Allowed || pcs[a].Reason == "" {
+			t.Errorf("%s before start = %+v", a, pcs[a])
+		}
+	}
+	if pcs[ActionReset].MinTier != models.TierAdmin || pcs[ActionStart].MinTier != models.TierCoOrganizer {
+		t.Errorf("tiers: reset %s, start %s", pcs[ActionReset].MinTier, pcs[ActionStart].MinTier)
+	}
+
+	// Three players: one match and a bye. Only the match needs a result.
+	eng := st.NewTournament()
+	for _, name := range []string{"A", "B", "C"} {
+		if err := eng.AddPlayer(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := eng.StartTournament(); err != nil {
+		t.Fatal(err)
+	}
+	if n := PendingResults(&eng); n != 1 {
+		t.Errorf("PendingResults = %d, want 1", n)
+	}
+	tm.Status = models.TournamentStatusInProgress
+	pcs = Preconditions(tm, &eng, confirmedRegs(3))
+	if pcs[ActionStart].Allowed || !pcs[ActionPair].Allowed || !pcs[ActionReset].Allowed {
+		t.Errorf("in progress = %+v", pcs)
+	}
+	if r := pcs[ActionNextRound].Reason; pcs[ActionNextRound].Allowed || !strings.Contains(r, "round 1 has 1 match without") {
+		t.Errorf("next_round = %+v", pcs[ActionNextRound])
+	}
+
+	for _, p := range eng.GetRound() {
+		if p.PlayerB() != st.BYE_OPPONENT_ID {
+			if err := eng.AddResult(p.PlayerA(), 2, 1, 0); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	pcs = Preconditions(tm, &eng, confirmedRegs(3))
+	if !pcs[ActionNextRound].Allowed || !pcs[ActionFinish].Allowed {
+		t.Errorf("with results in = %+v", pcs)
+	}
+}
@@ -0,0 +1,70 @@
+package engine
+
+import (
+	"sort"
+	"strings"
+
+	st "github.com/dstathis/swisstools"
+)
+
+// LookupLimit caps how many players a pairing lookup returns, so a short
+// name typed in a big event still gets a short answer.
+const LookupLimit = 10
+
+// PlayerMatch is a player's match in a round as the pairing lookup shows
+// it: their table and opponent, or that they have the bye.
+type PlayerMatch struct {
+	Name     string `json:"name"`
+	Table    int    `json:"table,omitempty"`
+	Opponent string `json:"opponent,omitempty"`
+	Bye      bool   `json:"bye,omitempty"`
+}
+
+// LookupMatches returns the matches in pairings, a round of eng, of the
+// players whose name contains q, ignoring case, by name and at most
+// LookupLimit of them. If any name is q itself only those are returned, so
+// a player who types their full name gets just their own match. A blank q
+// finds nobody.
+func LookupMatches(eng *st.Tournament, pairings []st.Pairing, q string) []PlayerMatch {
+	q = strings.ToLower(strings.TrimSpace(q))
+	if q == "" {
+		return nil
+	}
+	name := func(id int) string {
+		if p, ok := eng.GetPlayerById(id); ok {
+			return p.Name
+		}
+		return ""
+	}
+	var exact, partial []PlayerMatch
+	add := func(m PlayerMatch) {
+		switch n := strings.ToLower(m.Name); {
+		case n == q:
+			exact = append(exact, m)
+		case strings.Contains(n, q):
+			partial = append(partial, m)
+		}
+	}
+	for i, p := range pairings {
+		a := name(p.PlayerA())
+		if p.PlayerB() == st.BYE_OPPONENT_ID {
+			add(PlayerMatch{Name: a, Bye: true})
+			continue
+		}
+		b := name(p.PlayerB())
+		table := TableNumber(i, p)
+		add(PlayerMatch{Name: a, Table: table, Opponent: b})
+		add(PlayerMatch{Name: b, Table: table, Opponent: a})
+	}
+	found := partial
+	if len(exact) > 0 {
+		found = exact
+	}
+	sort.SliceStable(found, func(i, j int) bool {
+		return strings.ToLower(found[i].Name) < strings.ToLower(found[j].Name)
+	})
+	if len(found) > LookupLimit {
+		found = found[:LookupLimit]
+	}
+	return found
+}
@@ -0,0 +1,73 @@
+package engine
+
+import (
+	"fmt"
+	"testing"
+
+	st "github.com/dstathis/swisstools"
+)
+
+func TestLookupMatches(t *testing.T) {
+	eng := st.NewTournament()
+	for _, name := range []string{"Ann", "Anna", "Bob", "Carl", "Dee"} {
+		if err := eng.AddPlayer(name); err != nil {
+			t.Fatalf("add player: %v", err)
+		}
+	}
+	if err := eng.StartTournament(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	round := eng.GetRound()
+
+	// Every player is found once, at the table the round puts them, facing
+	// the player sharing it.
+	seen := map[string]PlayerMatch{}
+	for _, name := range []string{"Ann", "Anna", "Bob", "Carl", "Dee"} {
+		got := LookupMatches(&eng, round, name)
+		if len(got) != 1 || got[0].Name != name {
+			t.Fatalf("lookup %q = %+v, want just %s", name, got, name)
+		}
+		seen[name] = got[0]
+	}
+	byes := 0
+	for name, m := range seen {
+		if m.Bye {
+			byes++
+			if m.Table != 0 || m.Opponent != "" {
+				t.Errorf("%s has the bye but %+v", name, m)
+			}
+			continue
+		}
+		if opp := seen[m.Opponent]; opp.Opponent != name || opp.Table != m.Table || m.Table == 0 {
+			t.Errorf("%s = %+v but %s = %+v", name, m, m.Opponent, opp)
+		}
+	}
+	if byes != 1 {
+		t.Errorf("%d byes among %+v, want 1", byes, seen)
+	}
+
+	if got := LookupMatches(&eng, round, " AN "); len(got) != 2 || got[0].Name != "Ann" || got[1].Name != "Anna" {
+		t.Errorf("lookup \"an\" = %+v, want Ann and Anna", got)
+	}
+	if got := LookupMatches(&eng, round, "zed"); len(got) != 0 {
+		t.Errorf("lookup \"zed\" = %+v, want nobody", got)
+	}
+	if got := LookupMatches(&eng, round, " "); got != nil {
+		t.Errorf("blank lookup = %+v, want nil", got)
+	}
+}
+
+func TestLookupMatches_Limit(t *testing.T) {
+	eng := st.NewTournament()
+	for i := 0; i < LookupLimit+4; i++ {
+		if err := eng.AddPlayer(fmt.Sprintf("P%d", i)); err != nil {
+			t.Fatalf("add player: %v", err)
+		}
+	}
+	if err := eng.StartTournament(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	if got := LookupMatches(&eng, eng.GetRound(), "p"); len(got) != LookupLimit {
+		t.Errorf("got %d matches, want %d", len(got), LookupLimit)
+	}
+}
@@ -0,0 +1,89 @@
+package engine
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/dstathis/openswiss/internal/models"
+	st "github.com/dstathis/swisstools"
+)
+
+// ArchetypeShare is how many players brought one archetype, and how many of
+// them made the top cut.
+type ArchetypeShare struct {
+	Name       string  `json:"name"`
+	Unknown    bool    `json:"unknown,omitempty"`
+	Players    int     `json:"players"`
+	Share      float64 `json:"share"`
+	TopCut     int     `json:"top_cut"`
+	Conversion float64 `json:"conversion"`
+}
+
+// Metagame is a tournament's field broken down by archetype. TopCut is set
+// once the playoff is seeded; until then the cut counts are zero.
+type Metagame struct {
+	Players    int              `json:"players"`
+	TopCut     bool             `json:"top_cut"`
+	Archetypes []ArchetypeShare `json:"archetypes"`
+}
+
+// BuildMetagame breaks down the players in the engine by the archetype on
+// their registration. Names are grouped ignoring case and spacing, under the
+// spelling that comes first; players who gave none count as unknown, listed
+// last. Share is of the whole field, Conversion of the archetype's players
+// who made the cut. The most played archetypes come first.
+func BuildMetagame(regs []models.Registration, eng *st.Tournament) Metagame {
+	var m Metagame
+	cut := make(map[int]bool)
+	if po := eng.GetPlayoff(); po != nil {
+		m.TopCut = true
+		for _, id := range po.Seeds {
+			cut[id] = true
+		}
+	}
+
+	byKey := make(map[string]*ArchetypeShare)
+	var order []string
+	for _, r := range regs {
+		if r.EnginePlayerID == nil {
+			continue
+		}
+		if _, ok := eng.GetPlayerById(*r.EnginePlayerID); !ok {
+			continue
+		}
+		name, key := "", ""
+		if r.Archetype != nil {
+			name = strings.Join(strings.Fields(*r.Archetype), " ")
+			key = strings.ToLower(name)
+		}
+		a, ok := byKey[key]
+		if !ok {
+			a = &ArchetypeShare{Name: name, Unknown: key == ""}
+			byKey[key] = a
+			order = append(order, key)
+		}
+		m.Players++
+		a.Players++
+		if cut[*r.EnginePlayerID] {
+			a.TopCut++
+		}
+	}
+
+	for _, key := range order {
+		a := byKey[key]
+		a.Share = float64(a.Players) / float64(m.Players)
+		a.Conversion = float64(a.TopCut) / float64(a.Players)
+		m.Archetypes = append(m.Archetypes, *a)
+	}
+	sort.SliceStable(m.Archetypes, func(i, j int) bool {
+		a, b := m.Archetypes[i], m.Archetypes[j]
+		if a.Unknown != b.Unknown {
+			return b.Unknown
+		}
+		if a.Players != b.Players {
+			return a.Players > b.Players
+		}
+		return strings.ToLower(a.Name) < strings.ToLower(b.Name)
+	})
+	return m
+}
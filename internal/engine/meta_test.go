@@ -0,0 +1,61 @@
+package engine
+
+import (
+	"math"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/models"
+	st "github.com/dstathis/swisstools"
+)
+
+func TestBuildMetagame(t *testing.T) {
+	eng := st.NewTournamentWithConfig(st.TournamentConfig{PointsForWin: 3, ByeWins: st.BYE_WINS})
+	eng.SetMaxRounds(1)
+	for _, n := range []string{"Ann", "Ben", "Cat", "Dev", "Eli"} {
+		eng.AddPlayer(n)
+	}
+	eng.StartTournament()
+	for _, p := range eng.GetRound() {
+		if p.PlayerB() != st.BYE_OPPONENT_ID {
+			eng.AddResult(p.PlayerA(), 2, 0, 0)
+		}
+	}
+	eng.NextRound()
+
+	archetype := func(s string) *string { return &s }
+	regs := []models.Registration{{DisplayName: "Pending", Archetype: archetype("Burn")}}
+	names := map[int]*string{1: archetype("Burn"), 2: archetype(" burn  "), 3: archetype("Control"), 4: nil, 5: archetype("Tron")}
+	for id := 1; id <= 5; id++ {
+		pid := id
+		regs = append(regs, models.Registration{EnginePlayerID: &pid, Archetype: names[id]})
+	}
+
+	m := BuildMetagame(regs, &eng)
+	if m.Players != 5 || m.TopCut || len(m.Archetypes) != 4 {
+		t.Fatalf("before the cut: %+v", m)
+	}
+	if a := m.Archetypes[0]; a.Name != "Burn" || a.Players != 2 || math.Abs(a.Share-0.4) > 1e-9 {
+		t.Errorf("most played = %+v", a)
+	}
+	if a := m.Archetypes[1]; a.Name != "Control" {
+		t.Errorf("ties by name: second = %+v", a)
+	}
+	if a := m.Archetypes[3]; !a.Unknown || a.Players != 1 {
+		t.Errorf("unknown last: %+v", a)
+	}
+
+	if err := eng.StartPlayoff(2); err != nil {
+		t.Fatal(err)
+	}
+	m = BuildMetagame(regs, &eng)
+	cut := 0
+	for _, a := range m.Archetypes {
+		cut += a.TopCut
+		if a.Players > 0 && math.Abs(a.Conversion-float64(a.TopCut)/float64(a.Players)) > 1e-9 {
+			t.Errorf("%s conversion = %v", a.Name, a.Conversion)
+		}
+	}
+	if !m.TopCut || cut != 2 {
+		t.Errorf("with the cut: %+v", m)
+	}
+}
@@ -0,0 +1,283 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/dstathis/openswiss/internal/pairing"
+	st "github.com/dstathis/swisstools"
+)
+
+// PairRound pairs the current round with the tournament's pairing algorithm
+// and assigns table numbers. Every code path that creates pairings (next
+// round, re-pair) goes through here so the stored round is always in table
+// order. Byes are settled first (see chooseByes): assigned holds the active
+// players staff gave a bye this round, and the rest of the field is paired
+// without the players sitting out. A round robin follows its schedule
+// instead (see scheduledRound). clubs holds the players' clubs, keyed by
+// engine player ID (see Clubs); in the first t.AvoidClubRounds rounds the
+// Swiss keeps club mates apart where it can. When the tournament tracks
+// colours, each Swiss match is then turned so player A is the one due white
+// (see allocateColors); a round robin keeps its schedule's sides. seeds
+// holds the tiebreak seeds (see TiebreakSeeds), which with t's tiebreak
+// order place players for the Danish pairing and for the tables.
+func PairRound(eng *st.Tournament, t *models.Tournament, allowRepair bool, assigned []int, clubs map[int]string, seeds map[int]int64) error {
+	if activePlayers(eng) == 0 {
+		return errors.New("cannot pair tournament with no players")
+	}
+	var pairs []pairing.Pair
+	if t.PairingAlgorithm == models.PairingRoundRobin {
+		var err error
+		if pairs, err = scheduledRound(eng, assigned); err != nil {
+			return err
+		}
+	} else {
+		byes := chooseByes(eng, t, assigned)
+		var err error
+		if pairs, err = pairRest(eng, t, byes, clubs, seeds); err != nil {
+			return err
+		}
+		if t.Colors {
+			allocateColors(eng, pairs)
+		}
+		for _, id := range byes {
+			pairs = append(pairs, pairing.Pair{A: id, B: pairing.Bye})
+		}
+	}
+	if err := writeRound(eng, pairs, allowRepair); err != nil {
+		return err
+	}
+	return AssignTables(eng, t.TiebreakOrder(), seeds)
+}
+
+// pairerFor returns the pairing.Pairer the tournament uses for round, or
+// nil for the greedy pairing built into swisstools. Round 1 follows
+// Round1Pairing, later rounds PairingAlgorithm. A Danish round 1 that isn't
+// seeded is paired at random, as there are no standings to go down yet.
+func pairerFor(t *models.Tournament, round int) pairing.Pairer {
+	if round == 1 {
+		switch t.Round1Pairing {
+		case models.Round1Cross:
+			return pairing.Cross{}
+		case models.Round1Fold:
+			return pairing.Fold{}
+		}
+	}
+	switch t.PairingAlgorithm {
+	case models.PairingWeighted:
+		return pairing.Weighted{}
+	case models.PairingDanish:
+		if round > 1 {
+			return pairing.Danish{}
+		}
+	}
+	return nil
+}
+
+// scheduledRound pairs the current round of a round robin. The schedule is
+// drawn up over every player the engine has ever held, in seed order, so a
+// drop doesn't reshuffle it: whoever was due to meet a dropped player, or
+// one staff gave a bye this round (assigned), gets a bye instead.
+func scheduledRound(eng *st.Tournament, assigned []int) ([]pairing.Pair, error) {
+	players := eng.GetPlayers()
+	field := make([]pairing.Player, 0, len(players))
+	for id := range players {
+		field = append(field, pairing.Player{ID: id})
+	}
+	sort.Slice(field, func(i, j int) bool { return field[i].ID < field[j].ID })
+	for i := range field {
+		field[i].Seed = i + 1
+	}
+	scheduled, err := pairing.RoundRobin{Round: eng.GetCurrentRound()}.Pair(field)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(map[int]bool, len(assigned))
+	for _, id := range assigned {
+		out[id] = true
+	}
+	for id, p := range players {
+		if p.Removed {
+			out[id] = true
+		}
+	}
+	var pairs, byes []pairing.Pair
+	bye := func(id int) {
+		if id != pairing.Bye && !players[id].Removed {
+			byes = append(byes, pairing.Pair{A: id, B: pairing.Bye})
+		}
+	}
+	for _, p := range scheduled {
+		if p.B == pairing.Bye || out[p.A] || out[p.B] {
+			bye(p.A)
+			bye(p.B)
+			continue
+		}
+		pairs = append(pairs, p)
+	}
+	return append(pairs, byes...), nil
+}
+
+// pairRest pairs every active player except byes, who must leave an even
+// number behind. It works on a copy of eng with the current round emptied
+// and the bye players removed, so swisstools' own pairing never sees them.
+// In a round that keeps club mates apart, the weighted pairer weighs clubs
+// itself; the other algorithms' pairings are mended by pairing.AvoidClubs.
+func pairRest(eng *st.Tournament, t *models.Tournament, byes []int, clubs map[int]string, seeds map[int]int64) ([]pairing.Pair, error) {
+	data, err := eng.DumpTournament()
+	if err != nil {
+		return nil, fmt.Errorf("dump engine state: %w", err)
+	}
+	rest, err := st.LoadTournament(data)
+	if err != nil {
+		return nil, fmt.Errorf("load engine state: %w", err)
+	}
+	if err := editState(&rest, func(s *engineState) error {
+		if s.CurrentRound < 1 || s.CurrentRound >= len(s.Rounds) {
+			return errors.New("invalid tournament state: current round must be >= 1")
+		}
+		s.Rounds[s.CurrentRound] = []statePairing{}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	for _, id := range byes {
+		if err := rest.RemovePlayerById(id); err != nil {
+			return nil, err
+		}
+	}
+	if activePlayers(&rest) == 0 {
+		return nil, nil
+	}
+
+	if !keepsClubsApart(t, rest.GetCurrentRound(), clubs) {
+		clubs = nil
+	}
+	if p := pairerFor(t, rest.GetCurrentRound()); p != nil {
+		players := pairingPlayers(&rest)
+		if t.Colors {
+			withColors(&rest, players)
+		}
+		if t.PairingAlgorithm == models.PairingDanish {
+			standingsOrder(&rest, t.TiebreakOrder(), seeds, players)
+		}
+		withClubs(players, clubs)
+		pairs, err := p.Pair(players)
+		if _, weighted := p.(pairing.Weighted); err != nil || weighted || clubs == nil {
+			return pairs, err
+		}
+		return pairing.AvoidClubs(players, pairs), nil
+	}
+	if err := rest.Pair(true); err != nil {
+		return nil, err
+	}
+	var pairs []pairing.Pair
+	for _, p := range rest.GetRound() {
+		pairs = append(pairs, pairing.Pair{A: p.PlayerA(), B: p.PlayerB()})
+	}
+	if clubs != nil {
+		pairs = avoidClubs(&rest, pairs, clubs)
+	}
+	return pairs, nil
+}
+
+// writeRound stores pairs as the current round, mirroring swisstools' Pair:
+// results start uninitialised and byes get the configured bye score.
+func writeRound(eng *st.Tournament, pairs []pairing.Pair, allowRepair bool) error {
+	return editState(eng, func(s *engineState) error {
+		if s.CurrentRound < 1 || s.CurrentRound >= len(s.Rounds) {
+			return errors.New("invalid tournament state: current round must be >= 1")
+		}
+		if len(s.Rounds[s.CurrentRound]) > 0 && !allowRepair {
+			return errors.New("round already has pairings - use Pair(true) to allow re-pairing")
+		}
+		round := make([]statePairing, 0, len(pairs))
+		for _, pr := range pairs {
+			if pr.B == pairing.Bye {
+				round = append(round, statePairing{
+					PlayerA:     pr.A,
+					PlayerB:     st.BYE_OPPONENT_ID,
+					PlayerAWins: s.Config.ByeWins,
+					PlayerBWins: s.Config.ByeLosses,
+					Draws:       s.Config.ByeDraws,
+				})
+				continue
+			}
+			round = append(round, statePairing{
+				PlayerA:     pr.A,
+				PlayerB:     pr.B,
+				PlayerAWins: st.UNINITIALIZED_RESULT,
+				PlayerBWins: st.UNINITIALIZED_RESULT,
+				Draws:       st.UNINITIALIZED_RESULT,
+			})
+		}
+		s.Rounds[s.CurrentRound] = round
+		return nil
+	})
+}
+
+// standingsOrder sorts players into standings order, which breaks ties in
+// points by the tiebreakers in order, then by seeds (see Standings).
+func standingsOrder(eng *st.Tournament, order []string, seeds map[int]int64, players []pairing.Player) {
+	rank := make(map[int]int)
+	for i, s := range Standings(eng, order, seeds) {
+		rank[s.PlayerID] = i
+	}
+	sort.SliceStable(players, func(i, j int) bool { return rank[players[i].ID] < rank[players[j].ID] })
+}
+
+// pairingPlayers builds the Pairer input from the engine: every active
+// player with their points, bye count and previous opponents. Seeds follow
+// engine player IDs, which InitTournamentEngine hands out in rating order
+// when round 1 is seeded. Players are shuffled within score groups, as
+// swisstools does, so equally good pairings vary between events.
+func pairingPlayers(eng *st.Tournament) []pairing.Player {
+	byID := make(map[int]*pairing.Player)
+	var players []*pairing.Player
+	for id, p := range eng.GetPlayers() {
+		if p.Removed {
+			continue
+		}
+		pp := &pairing.Player{ID: id, Points: p.Points}
+		byID[id] = pp
+		players = append(players, pp)
+	}
+	for r := 1; r < eng.GetCurrentRound(); r++ {
+		round, err := eng.GetRoundByNumber(r)
+		if err != nil {
+			continue
+		}
+		for _, pr := range round {
+			a, b := byID[pr.PlayerA()], byID[pr.PlayerB()]
+			if pr.PlayerB() == st.BYE_OPPONENT_ID {
+				if a != nil {
+					a.Byes++
+				}
+				continue
+			}
+			if a != nil {
+				a.Opponents = append(a.Opponents, pr.PlayerB())
+			}
+			if b != nil {
+				b.Opponents = append(b.Opponents, pr.PlayerA())
+			}
+		}
+	}
+
+	sort.Slice(players, func(i, j int) bool { return players[i].ID < players[j].ID })
+	for i, p := range players {
+		p.Seed = i + 1
+	}
+
+	rand.Shuffle(len(players), func(i, j int) { players[i], players[j] = players[j], players[i] })
+	sort.SliceStable(players, func(i, j int) bool { return players[i].Points > players[j].Points })
+	out := make([]pairing.Player, len(players))
+	for i, p := range players {
+		out[i] = *p
+	}
+	return out
+}
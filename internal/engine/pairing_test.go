@@ -0,0 +1,223 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/models"
+	st "github.com/dstathis/swisstools"
+)
+
+func TestPairRound_Weighted(t *testing.T) {
+	tourn := &models.Tournament{PairingAlgorithm: models.PairingWeighted}
+	eng := playedEngine(t, 9)
+
+	for round := 2; round <= 4; round++ {
+		if err := PairRound(eng, tourn, false, nil, nil, nil); err != nil {
+			t.Fatalf("round %d: pair: %v", round, err)
+		}
+		seen := map[int]bool{}
+		byes := 0
+		for _, p := range eng.GetRound() {
+			for _, id := range []int{p.PlayerA(), p.PlayerB()} {
+				if id == st.BYE_OPPONENT_ID {
+					continue
+				}
+				if seen[id] {
+					t.Fatalf("round %d: player %d paired twice", round, id)
+				}
+				seen[id] = true
+			}
+			if p.PlayerB() == st.BYE_OPPONENT_ID {
+				byes++
+				continue
+			}
+			if p.PlayerAWins() != st.UNINITIALIZED_RESULT {
+				t.Errorf("round %d: new pairing already has a result", round)
+			}
+			for r := 1; r < round; r++ {
+				prev, _ := eng.GetRoundByNumber(r)
+				for _, q := range prev {
+					if (q.PlayerA() == p.PlayerA() && q.PlayerB() == p.PlayerB()) ||
+						(q.PlayerA() == p.PlayerB() && q.PlayerB() == p.PlayerA()) {
+						t.Errorf("round %d: rematch %d-%d from round %d", round, p.PlayerA(), p.PlayerB(), r)
+					}
+				}
+			}
+			if err := eng.AddResult(p.PlayerA(), 2, 1, 0); err != nil {
+				t.Fatalf("add result: %v", err)
+			}
+		}
+		if len(seen) != 9 || byes != 1 {
+			t.Fatalf("round %d: paired %d players with %d byes", round, len(seen), byes)
+		}
+		if err := eng.NextRound(); err != nil {
+			t.Fatalf("round %d: next round: %v", round, err)
+		}
+	}
+}
+
+func TestPairRound_WeightedRefusesExistingPairings(t *testing.T) {
+	tourn := &models.Tournament{PairingAlgorithm: models.PairingWeighted}
+	eng := playedEngine(t, 8)
+	if err := PairRound(eng, tourn, false, nil, nil, nil); err != nil {
+		t.Fatalf("pair: %v", err)
+	}
+	if err := PairRound(eng, tourn, false, nil, nil, nil); err == nil {
+		t.Error("pairing an already-paired round without allowRepair: want error")
+	}
+	if err := PairRound(eng, tourn, true, nil, nil, nil); err != nil {
+		t.Errorf("re-pair: %v", err)
+	}
+}
+
+func TestPairRound_SeededRoundOne(t *testing.T) {
+	for _, tc := range []struct {
+		mode string
+		want map[int]int
+	}{
+		{models.Round1Cross, map[int]int{1: 3, 2: 4}},
+		{models.Round1Fold, map[int]int{1: 4, 2: 3}},
+	} {
+		eng := startedEngine(t, 5)
+		tourn := &models.Tournament{Round1Pairing: tc.mode, ByePolicy: models.ByeLowest}
+		if err := PairRound(eng, tourn, true, nil, nil, nil); err != nil {
+			t.Fatalf("%s: %v", tc.mode, err)
+		}
+		opp := map[int]int{}
+		for _, p := range eng.GetRound() {
+			opp[p.PlayerA()], opp[p.PlayerB()] = p.PlayerB(), p.PlayerA()
+		}
+		// Seeds follow engine IDs; the fifth seed sits out.
+		if opp[5] != st.BYE_OPPONENT_ID {
+			t.Errorf("%s: bye did not go to the lowest seed: %v", tc.mode, eng.GetRound())
+		}
+		for a, b := range tc.want {
+			if opp[a] != b {
+				t.Errorf("%s: seed %d plays %d, want %d", tc.mode, a, opp[a], b)
+			}
+		}
+	}
+}
+
+func TestSeatingOrder(t *testing.T) {
+	r := func(id int64, rating int) models.Registration {
+		reg := models.Registration{ID: id}
+		if rating > 0 {
+			reg.Rating = &rating
+		}
+		return reg
+	}
+	regs := []models.Registration{r(1, 0), r(2, 1500), r(3, 0), r(4, 2000), r(5, 1500)}
+
+	if got := seatingOrder(&models.Tournament{Round1Pairing: models.Round1Random}, regs); got[0].ID != 1 {
+		t.Errorf("random round 1 reordered the field: %v", got)
+	}
+	got := seatingOrder(&models.Tournament{Round1Pairing: models.Round1Fold}, regs)
+	var ids []int64
+	for _, reg := range got {
+		ids = append(ids, reg.ID)
+	}
+	want := []int64{4, 2, 5, 1, 3}
+	for i := range want {
+		if ids[i] != want[i] {
+			t.Fatalf("seating order = %v, want %v", ids, want)
+		}
+	}
+
+	// Finalists seeded by their pods come first, in seed order.
+	seed := func(n int) *int { return &n }
+	regs[0].PodSeed, regs[2].PodSeed = seed(2), seed(1)
+	ids = nil
+	for _, reg := range seatingOrder(&models.Tournament{Round1Pairing: models.Round1Cross}, regs) {
+		ids = append(ids, reg.ID)
+	}
+	want = []int64{3, 1, 4, 2, 5}
+	for i := range want {
+		if ids[i] != want[i] {
+			t.Fatalf("seeded seating order = %v, want %v", ids, want)
+		}
+	}
+}
+
+func TestPairRound_RoundRobin(t *testing.T) {
+	tourn := &models.Tournament{PairingAlgorithm: models.PairingRoundRobin}
+	eng := startedEngine(t, 5)
+	met := map[[2]int]bool{}
+	byes := map[int]int{}
+	for round := 1; round <= 5; round++ {
+		if err := PairRound(eng, tourn, true, nil, nil, nil); err != nil {
+			t.Fatalf("round %d: pair: %v", round, err)
+		}
+		for _, p := range eng.GetRound() {
+			if p.PlayerB() == st.BYE_OPPONENT_ID {
+				byes[p.PlayerA()]++
+				continue
+			}
+			a, b := p.PlayerA(), p.PlayerB()
+			if a > b {
+				a, b = b, a
+			}
+			if met[[2]int{a, b}] {
+				t.Errorf("round %d: rematch %d-%d", round, a, b)
+			}
+			met[[2]int{a, b}] = true
+			if err := eng.AddResult(p.PlayerA(), 2, 0, 0); err != nil {
+				t.Fatalf("add result: %v", err)
+			}
+		}
+		if round < 5 {
+			if err := eng.NextRound(); err != nil {
+				t.Fatalf("round %d: next round: %v", round, err)
+			}
+		}
+	}
+	if len(met) != 10 || len(byes) != 5 {
+		t.Errorf("%d matches, byes %v; want 10 and one bye each", len(met), byes)
+	}
+}
+
+func TestPairRound_RoundRobinDrop(t *testing.T) {
+	tourn := &models.Tournament{PairingAlgorithm: models.PairingRoundRobin}
+	eng := startedEngine(t, 4)
+	if err := PairRound(eng, tourn, true, nil, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	var dropped, opponent int
+	for _, p := range eng.GetRound() {
+		dropped, opponent = p.PlayerA(), p.PlayerB()
+		break
+	}
+	if err := eng.RemovePlayerById(dropped); err != nil {
+		t.Fatal(err)
+	}
+	// Re-pairing the same round keeps the schedule; the dropped player's
+	// opponent sits out with a bye and the other match stands.
+	if err := PairRound(eng, tourn, true, nil, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	round := eng.GetRound()
+	if len(round) != 2 {
+		t.Fatalf("round = %v, want a match and a bye", round)
+	}
+	for _, p := range round {
+		if p.PlayerA() == opponent && p.PlayerB() != st.BYE_OPPONENT_ID {
+			t.Errorf("opponent of the dropped player plays %d, want a bye", p.PlayerB())
+		}
+	}
+}
+
+func TestPairRound_Danish(t *testing.T) {
+	tourn := &models.Tournament{PairingAlgorithm: models.PairingDanish}
+	eng := playedEngine(t, 8)
+	if err := PairRound(eng, tourn, false, nil, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	// Four players won round 1 and four lost; going down the standings
+	// pairs winners with winners.
+	players := eng.GetPlayers()
+	for _, p := range eng.GetRound() {
+		if a, b := players[p.PlayerA()], players[p.PlayerB()]; a.Points != b.Points {
+			t.Errorf("%s (%d) paired with %s (%d)", a.Name, a.Points, b.Name, b.Points)
+		}
+	}
+}
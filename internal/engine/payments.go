@@ -0,0 +1,92 @@
+package engine
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+// ErrNotRegistered is returned for a registration of another tournament.
+var ErrNotRegistered = errors.New("player is not registered for this tournament")
+
+// Payment is what staff note about how a player paid: Amount in minor
+// units and Method, either nil if not noted.
+type Payment struct {
+	Status string
+	Amount *int
+	Method *string
+}
+
+// CheckPayment checks p and trims its method, an empty one coming back
+// nil. A player marked paid with no amount noted is taken to have paid
+// fee, the tournament's entry fee, when there is one.
+func CheckPayment(p Payment, fee int) (Payment, error) {
+	if !models.ValidPaymentStatus(p.Status) {
+		return p, errors.New("payment status must be unpaid, paid or comped")
+	}
+	if p.Amount != nil && (*p.Amount < 0 || *p.Amount > models.MaxAmount) {
+		return p, fmt.Errorf("amount must be between 0 and %s", models.FormatAmount(models.MaxAmount))
+	}
+	if p.Amount == nil && p.Status == models.PaymentPaid && fee > 0 {
+		p.Amount = &fee
+	}
+	if p.Method != nil {
+		m := strings.TrimSpace(*p.Method)
+		switch {
+		case m == "":
+			p.Method = nil
+		case utf8.RuneCountInString(m) > models.MaxPaymentMethod:
+			return p, fmt.Errorf("payment methods may be at most %d characters", models.MaxPaymentMethod)
+		default:
+			p.Method = &m
+		}
+	}
+	return p, nil
+}
+
+// SetPayment records registration regID's payment for tournament id (see
+// CheckPayment) and returns the registration. Payments can be noted at any
+// time, after the tournament too. It returns ErrNotRegistered for a
+// registration of another tournament.
+func SetPayment(ctx context.Context, database *sql.DB, id, regID int64, p Payment) (*models.Registration, error) {
+	t, err := db.GetTournament(ctx, database, id)
+	if err != nil {
+		return nil, fmt.Errorf("get tournament: %w", err)
+	}
+	if p, err = CheckPayment(p, t.EntryFee); err != nil {
+		return nil, err
+	}
+	reg, err := db.SetPayment(ctx, database, id, regID, p.Status, p.Amount, p.Method)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotRegistered
+	}
+	return reg, err
+}
+
+// Unpaid returns the registrations among regs that hold a place and
+// haven't paid: everyone unpaid but the dropped and the waitlisted.
+func Unpaid(regs []models.Registration) []models.Registration {
+	var out []models.Registration
+	for _, r := range regs {
+		if unpaid(&r) {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+func unpaid(r *models.Registration) bool {
+	return r.PaymentStatus == models.PaymentUnpaid && r.Status != models.RegistrationStatusDropped && r.Status != models.RegistrationStatusWaitlisted
+}
+
+// OwesEntryFee reports whether reg still has t's entry fee to pay: there is
+// one, and reg holds a place and is unpaid (see Unpaid).
+func OwesEntryFee(t *models.Tournament, reg *models.Registration) bool {
+	return t.EntryFee > 0 && reg != nil && unpaid(reg)
+}
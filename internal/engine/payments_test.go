@@ -0,0 +1,87 @@
+package engine
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+func TestCheckPayment(t *testing.T) {
+	five, negative, method, blank := 500, -1, "  card ", " "
+
+	p, err := CheckPayment(Payment{Status: models.PaymentPaid, Method: &method}, 1000)
+	if err != nil || p.Amount == nil || *p.Amount != 1000 || p.Method == nil || *p.Method != "card" {
+		t.Errorf("paid without amount: %+v, %v; want the entry fee, method card", p, err)
+	}
+	if p, err = CheckPayment(Payment{Status: models.PaymentPaid, Amount: &five}, 1000); err != nil || *p.Amount != 500 {
+		t.Errorf("paid with amount: %+v, %v; want 500 kept", p, err)
+	}
+	if p, err = CheckPayment(Payment{Status: models.PaymentComped, Method: &blank}, 1000); err != nil || p.Amount != nil || p.Method != nil {
+		t.Errorf("comped: %+v, %v; want no amount or method", p, err)
+	}
+	if p, err = CheckPayment(Payment{Status: models.PaymentPaid}, 0); err != nil || p.Amount != nil {
+		t.Errorf("paid, free event: %+v, %v; want no amount", p, err)
+	}
+
+	long := strings.Repeat("x", models.MaxPaymentMethod+1)
+	for name, bad := range map[string]Payment{
+		"status":   {Status: "refunded"},
+		"negative": {Status: models.PaymentPaid, Amount: &negative},
+		"method":   {Status: models.PaymentPaid, Method: &long},
+	} {
+		if _, err := CheckPayment(bad, 1000); err == nil {
+			t.Errorf("%s: accepted %+v", name, bad)
+		}
+	}
+}
+
+func TestUnpaid(t *testing.T) {
+	regs := []models.Registration{
+		{ID: 1, Status: models.RegistrationStatusConfirmed, PaymentStatus: models.PaymentUnpaid},
+		{ID: 2, Status: models.RegistrationStatusConfirmed, PaymentStatus: models.PaymentPaid},
+		{ID: 3, Status: models.RegistrationStatusPending, PaymentStatus: models.PaymentUnpaid},
+		{ID: 4, Status: models.RegistrationStatusWaitlisted, PaymentStatus: models.PaymentUnpaid},
+		{ID: 5, Status: models.RegistrationStatusDropped, PaymentStatus: models.PaymentUnpaid},
+		{ID: 6, Status: models.RegistrationStatusConfirmed, PaymentStatus: models.PaymentComped},
+	}
+	if u := Unpaid(regs); len(u) != 2 || u[0].ID != 1 || u[1].ID != 3 {
+		t.Errorf("Unpaid = %+v, want registrations 1 and 3", u)
+	}
+}
+
+func TestOwesEntryFee(t *testing.T) {
+	tm := &models.Tournament{EntryFee: 1000}
+	reg := &models.Registration{Status: models.RegistrationStatusPending, PaymentStatus: models.PaymentUnpaid}
+	if !OwesEntryFee(tm, reg) {
+		t.Error("unpaid pending player doesn't owe the fee")
+	}
+	if OwesEntryFee(tm, nil) || OwesEntryFee(&models.Tournament{}, reg) {
+		t.Error("fee owed without a registration or a fee")
+	}
+	reg.Status = models.RegistrationStatusWaitlisted
+	if OwesEntryFee(tm, reg) {
+		t.Error("waitlisted player owes the fee")
+	}
+	reg.Status, reg.PaymentStatus = models.RegistrationStatusConfirmed, models.PaymentComped
+	if OwesEntryFee(tm, reg) {
+		t.Error("comped player owes the fee")
+	}
+}
+
+func TestCheckStart_Unpaid(t *testing.T) {
+	regs := confirmedRegs(4)
+	for i := range regs {
+		regs[i].PaymentStatus = models.PaymentUnpaid
+	}
+	regs[0].PaymentStatus = models.PaymentPaid
+	tm := &models.Tournament{Status: models.TournamentStatusRegistrationOpen}
+	if c := CheckStart(tm, regs); c.Unpaid != 0 || len(c.Warnings) != 0 {
+		t.Errorf("free event: Unpaid %d, Warnings %v; want none", c.Unpaid, c.Warnings)
+	}
+	tm.EntryFee = 1000
+	c := CheckStart(tm, regs)
+	if !c.CanStart || c.Unpaid != 3 || len(c.Warnings) != 1 || !strings.Contains(c.Warnings[0], "3 confirmed players haven't paid") {
+		t.Errorf("CanStart %v, Unpaid %d, Warnings %v; want a start with 3 unpaid warned about", c.CanStart, c.Unpaid, c.Warnings)
+	}
+}
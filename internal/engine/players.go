@@ -0,0 +1,191 @@
+package engine
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/models"
+	st "github.com/dstathis/swisstools"
+)
+
+// withPlayers runs fn in a transaction holding the tournament's row lock.
+// Once the tournament has started it goes through WithTournamentEngine and
+// fn gets the engine to keep in step with the registrations; before that
+// eng is nil.
+func withPlayers(ctx context.Context, database *sql.DB, tournamentID int64, fn func(tx *sql.Tx, t *models.Tournament, eng *st.Tournament) error) error {
+	tx, err := database.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+	t, err := db.GetTournamentForUpdate(ctx, tx, tournamentID)
+	if err != nil {
+		return fmt.Errorf("get tournament: %w", err)
+	}
+	if len(t.EngineState) > 0 {
+		tx.Rollback()
+		return WithTournamentEngine(ctx, database, tournamentID,
+			func(tx *sql.Tx, t *models.Tournament, eng *st.Tournament) (string, error) {
+				return "", fn(tx, t, eng)
+			})
+	}
+	if err := fn(tx, t, nil); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// DuplicateNameError is a new entry's name that a registration of the
+// tournament already has (see models.SamePlayerName).
+type DuplicateNameError struct {
+	Name     string
+	Existing models.Registration
+}
+
+func (e *DuplicateNameError) Error() string {
+	return fmt.Sprintf("%q is already entered as %q (%s)", e.Name, e.Existing.DisplayName, e.Existing.Status)
+}
+
+// CheckNewName returns a *DuplicateNameError if a registration of
+// tournament id has name, whatever its status: confirmed, pending,
+// waitlisted or dropped. Staff adding a walk-in see the clash before a
+// second entry is made for someone already there; if they go ahead, the
+// new entry is suffixed "(2)" as usual.
+func CheckNewName(ctx context.Context, q db.DBTX, tournamentID int64, name string) error {
+	regs, err := db.ListRegistrations(ctx, q, tournamentID)
+	if err != nil {
+		return err
+	}
+	if reg := models.SameNameRegistration(regs, name); reg != nil {
+		return &DuplicateNameError{Name: name, Existing: *reg}
+	}
+	return nil
+}
+
+// registrationOf loads a registration and checks it belongs to t.
+func registrationOf(ctx context.Context, tx db.DBTX, t *models.Tournament, regID int64) (*models.Registration, error) {
+	reg, err := db.GetRegistrationByID(ctx, tx, regID)
+	if err != nil || reg.TournamentID != t.ID {
+		return nil, errors.New("player is not registered for this tournament")
+	}
+	return reg, nil
+}
+
+// RenamePlayer changes a registered player's name. The registration (and so
+// the registration and pending lists) and, once the tournament has started,
+// the engine player behind the standings and pairings change together. A
+// name another registration already has surfaces as the database's unique
+// violation.
+func RenamePlayer(ctx context.Context, database *sql.DB, tournamentID, regID int64, name string) error {
+	name, err := models.ValidatePlayerName(name)
+	if err != nil {
+		return err
+	}
+	return withPlayers(ctx, database, tournamentID, func(tx *sql.Tx, t *models.Tournament, eng *st.Tournament) error {
+		reg, err := registrationOf(ctx, tx, t, regID)
+		if err != nil {
+			return err
+		}
+		if err := db.RenameRegistration(ctx, tx, reg.ID, name); err != nil {
+			return err
+		}
+		if eng == nil || reg.EnginePlayerID == nil {
+			return nil
+		}
+		return renameEnginePlayer(eng, *reg.EnginePlayerID, name)
+	})
+}
+
+// renameEnginePlayer sets a player's name in the engine. swisstools has no
+// setter, so it goes through the dump. Engine names are unique, dropped
+// players included.
+func renameEnginePlayer(eng *st.Tournament, playerID int, name string) error {
+	if id, ok := eng.GetPlayerID(name); ok && id != playerID {
+		return fmt.Errorf("another player in the tournament is already called %q", name)
+	}
+	return editState(eng, func(s *engineState) error {
+		for _, p := range s.Players {
+			if p.id() == playerID {
+				p["name"], _ = json.Marshal(name)
+				return nil
+			}
+		}
+		return fmt.Errorf("player %d not found", playerID)
+	})
+}
+
+// hasPlayed reports whether playerID has any match on record: a pairing in
+// an earlier round, or a reported match in the current one. A bye in the
+// current round doesn't count.
+func hasPlayed(eng *st.Tournament, playerID int) bool {
+	for r := 1; r < eng.GetCurrentRound(); r++ {
+		round, _ := eng.GetRoundByNumber(r)
+		for _, p := range round {
+			if p.PlayerA() == playerID || p.PlayerB() == playerID {
+				return true
+			}
+		}
+	}
+	p, _, ok := pairingOf(eng, playerID)
+	return ok && p.PlayerB() != st.BYE_OPPONENT_ID && p.PlayerAWins() != st.UNINITIALIZED_RESULT
+}
+
+// MergePlayers folds the duplicate registration dupID into keepID (see
+// db.MergeRegistrations) and deletes the duplicate. Once the tournament has
+// started, keepID must be in the engine and the duplicate must not have
+// played: it is taken out of the engine altogether, and a current-round
+// opponent is given a bye, as when a player drops. Re-pair the round to seat
+// them again.
+func MergePlayers(ctx context.Context, database *sql.DB, tournamentID, keepID, dupID int64) error {
+	if keepID == dupID {
+		return errors.New("choose two different players")
+	}
+	return withPlayers(ctx, database, tournamentID, func(tx *sql.Tx, t *models.Tournament, eng *st.Tournament) error {
+		if t.Status == models.TournamentStatusPlayoff || t.Status == models.TournamentStatusFinished {
+			return errors.New("players can't be merged once the Swiss rounds are over")
+		}
+		keep, err := registrationOf(ctx, tx, t, keepID)
+		if err != nil {
+			return err
+		}
+		dup, err := registrationOf(ctx, tx, t, dupID)
+		if err != nil {
+			return err
+		}
+		if eng != nil && dup.EnginePlayerID != nil {
+			if keep.EnginePlayerID == nil {
+				return fmt.Errorf("%s is not in the tournament; merge the other way round", keep.DisplayName)
+			}
+			if hasPlayed(eng, *dup.EnginePlayerID) {
+				return fmt.Errorf("%s has already played a match", dup.DisplayName)
+			}
+			if err := deleteEnginePlayer(eng, *dup.EnginePlayerID); err != nil {
+				return err
+			}
+		}
+		return db.MergeRegistrations(ctx, tx, keep.ID, dup.ID)
+	})
+}
+
+// deleteEnginePlayer takes a player who has never played out of the engine
+// entirely, so no trace of the duplicate is left in standings, exports or
+// the engine's name index. Their current-round pairing is handled like a
+// drop first.
+func deleteEnginePlayer(eng *st.Tournament, playerID int) error {
+	if err := eng.RemovePlayerById(playerID); err != nil {
+		return err
+	}
+	return editState(eng, func(s *engineState) error {
+		for i, p := range s.Players {
+			if p.id() == playerID {
+				s.Players = append(s.Players[:i], s.Players[i+1:]...)
+				return nil
+			}
+		}
+		return fmt.Errorf("player %d not found", playerID)
+	})
+}
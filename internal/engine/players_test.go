@@ -0,0 +1,106 @@
+package engine
+
+import (
+	"testing"
+
+	st "github.com/dstathis/swisstools"
+)
+
+// startedEngine returns an engine with n players paired for round 1 and no
+// results in.
+func startedEngine(t *testing.T, n int) *st.Tournament {
+	t.Helper()
+	eng := st.NewTournament()
+	for _, name := range []string{"A", "B", "C", "D", "E"}[:n] {
+		if err := eng.AddPlayer(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := eng.StartTournament(); err != nil {
+		t.Fatal(err)
+	}
+	return &eng
+}
+
+func TestRenameEnginePlayer(t *testing.T) {
+	eng := playedEngine(t, 4)
+	id, _ := eng.GetPlayerID("P0")
+	before, _ := eng.GetPlayerById(id)
+	if err := renameEnginePlayer(eng, id, "Zed"); err != nil {
+		t.Fatal(err)
+	}
+	after, _ := eng.GetPlayerById(id)
+	if after.Name != "Zed" || after.Points != before.Points {
+		t.Errorf("player = %+v, want %+v renamed Zed", after, before)
+	}
+	if got, ok := eng.GetPlayerID("Zed"); !ok || got != id {
+		t.Errorf("GetPlayerID(Zed) = %d, %v", got, ok)
+	}
+	if _, ok := eng.GetPlayerID("P0"); ok {
+		t.Error("old name still found")
+	}
+	if err := renameEnginePlayer(eng, id, "P1"); err == nil {
+		t.Error("renamed onto another player's name")
+	}
+	if err := renameEnginePlayer(eng, id, "Zed"); err != nil {
+		t.Errorf("renaming to the current name: %v", err)
+	}
+}
+
+func TestHasPlayed(t *testing.T) {
+	eng := startedEngine(t, 5)
+	var paired st.Pairing
+	for _, p := range eng.GetRound() {
+		if p.PlayerB() == st.BYE_OPPONENT_ID {
+			if hasPlayed(eng, p.PlayerA()) {
+				t.Errorf("player %d counted as played for a current-round bye", p.PlayerA())
+			}
+		} else {
+			paired = p
+		}
+	}
+	if hasPlayed(eng, paired.PlayerA()) {
+		t.Error("unreported match counted as played")
+	}
+	if err := eng.AddResult(paired.PlayerA(), 2, 1, 0); err != nil {
+		t.Fatal(err)
+	}
+	if !hasPlayed(eng, paired.PlayerA()) || !hasPlayed(eng, paired.PlayerB()) {
+		t.Error("reported match not counted as played")
+	}
+
+	played := playedEngine(t, 4)
+	for id := range played.GetPlayers() {
+		if !hasPlayed(played, id) {
+			t.Errorf("player %d has a round behind them", id)
+		}
+	}
+}
+
+func TestDeleteEnginePlayer(t *testing.T) {
+	eng := startedEngine(t, 4)
+	p := eng.GetRound()[0]
+	gone, opp := p.PlayerA(), p.PlayerB()
+	name := eng.GetPlayers()[gone].Name
+	if err := deleteEnginePlayer(eng, gone); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := eng.GetPlayerById(gone); ok {
+		t.Error("player still in the engine")
+	}
+	if _, ok := eng.GetPlayerID(name); ok {
+		t.Error("name still taken")
+	}
+	if got, _, ok := pairingOf(eng, opp); !ok || got.PlayerB() != st.BYE_OPPONENT_ID {
+		t.Errorf("opponent pairing = %+v, want a bye", got)
+	}
+	if len(eng.GetStandings()) != 3 {
+		t.Errorf("standings have %d players, want 3", len(eng.GetStandings()))
+	}
+	if err := eng.AddPlayer(name); err != nil {
+		t.Errorf("re-adding %s: %v", name, err)
+	}
+	if err := deleteEnginePlayer(eng, gone); err == nil {
+		t.Error("deleted a missing player")
+	}
+}
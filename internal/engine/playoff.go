@@ -0,0 +1,39 @@
+package engine
+
+import (
+	"github.com/dstathis/openswiss/internal/models"
+	st "github.com/dstathis/swisstools"
+)
+
+// StartPlayoff starts t's top cut of t.TopCut players on the finished Swiss
+// rounds of eng. swisstools picks and seeds the cut by its own standings,
+// which know nothing of the tournament's tiebreaker order or the players'
+// tiebreak seeds (see TiebreakSeeds); here the cut is the top of Standings
+// instead, the order the site publishes, and seed 1 plays seed N, seed 2
+// seed N-1 and so on.
+func StartPlayoff(eng *st.Tournament, t *models.Tournament, seeds map[int]int64) error {
+	if err := eng.StartPlayoff(t.TopCut); err != nil {
+		return err
+	}
+	standings := Standings(eng, t.TiebreakOrder(), seeds)
+	return editState(eng, func(s *engineState) error {
+		n := len(s.Playoff.Seeds)
+		cut := make([]int, n)
+		for i := range cut {
+			cut[i] = standings[i].PlayerID
+		}
+		first := make([]statePairing, 0, n/2)
+		for i := 0; i < n/2; i++ {
+			first = append(first, statePairing{
+				PlayerA:     cut[i],
+				PlayerB:     cut[n-1-i],
+				PlayerAWins: st.UNINITIALIZED_RESULT,
+				PlayerBWins: st.UNINITIALIZED_RESULT,
+				Draws:       st.UNINITIALIZED_RESULT,
+			})
+		}
+		s.Playoff.Seeds = cut
+		s.Playoff.Rounds[0] = first
+		return nil
+	})
+}
@@ -0,0 +1,130 @@
+package engine
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/models"
+	st "github.com/dstathis/swisstools"
+)
+
+func TestStartPlayoff_TiebreakOrder(t *testing.T) {
+	// Two rounds: the round 1 winners, one 2-0 and one 2-1, draw each
+	// other 1-1 in round 2 while the 2-1 loser beats the 2-0 loser. The
+	// winners tie on points; the 2-1 winner leads on OMW% and OGW%, the
+	// 2-0 winner on GW%. (swisstools leaves the final round out of the
+	// opponents' percentages.)
+	setup := func() (*st.Tournament, int, int) {
+		eng := st.NewTournament()
+		for i := 0; i < 4; i++ {
+			if err := eng.AddPlayer(fmt.Sprintf("P%d", i)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		eng.SetMaxRounds(2)
+		if err := eng.StartTournament(); err != nil {
+			t.Fatal(err)
+		}
+		round := eng.GetRound()
+		clean, narrow, loser := round[0].PlayerA(), round[1].PlayerA(), round[1].PlayerB()
+		for _, r := range [][4]int{{clean, 2, 0, 0}, {narrow, 2, 1, 0}} {
+			if err := eng.AddResult(r[0], r[1], r[2], r[3]); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := eng.NextRound(); err != nil {
+			t.Fatal(err)
+		}
+		if err := eng.Pair(false); err != nil {
+			t.Fatal(err)
+		}
+		for _, r := range [][4]int{{clean, 1, 1, 0}, {loser, 2, 0, 0}} {
+			if err := eng.AddResult(r[0], r[1], r[2], r[3]); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := eng.NextRound(); err != nil {
+			t.Fatal(err)
+		}
+		return &eng, clean, narrow
+	}
+
+	for _, tc := range []struct {
+		order []string
+		first bool // whether the 2-0 winner is seed 1
+	}{
+		{nil, false},
+		{[]string{models.TiebreakGW, models.TiebreakOGW}, true},
+	} {
+		eng, clean, narrow := setup()
+		tour := &models.Tournament{TopCut: 2, Tiebreakers: tc.order}
+		if err := StartPlayoff(eng, tour, nil); err != nil {
+			t.Fatalf("order %v: %v", tc.order, err)
+		}
+		want := []int{clean, narrow}
+		if !tc.first {
+			want = []int{narrow, clean}
+		}
+		po := eng.GetPlayoff()
+		if len(po.Seeds) != 2 || po.Seeds[0] != want[0] || po.Seeds[1] != want[1] {
+			t.Errorf("order %v: seeds %v, want %v", tc.order, po.Seeds, want)
+		}
+		final := eng.GetPlayoffRound()
+		if len(final) != 1 || final[0].PlayerA() != want[0] || final[0].PlayerB() != want[1] || final[0].PlayerAWins() != st.UNINITIALIZED_RESULT {
+			t.Errorf("order %v: final %+v", tc.order, final)
+		}
+		// The bracket plays on from the rewritten seeding.
+		if err := eng.AddPlayoffResult(want[1], 2, 0, 0); err != nil {
+			t.Fatalf("order %v: playoff result: %v", tc.order, err)
+		}
+	}
+
+	// Swiss rounds still running can't be cut.
+	eng := playedEngine(t, 4)
+	if err := StartPlayoff(eng, &models.Tournament{TopCut: 2}, nil); err == nil {
+		t.Error("playoff started before the Swiss rounds finished")
+	}
+}
+
+func TestStartPlayoff_TiedCut(t *testing.T) {
+	// All four players draw round 1 of 1, so they tie on points and every
+	// tiebreaker and only the tiebreak seeds pick the top 2.
+	eng := st.NewTournament()
+	for i := 0; i < 4; i++ {
+		if err := eng.AddPlayer(fmt.Sprintf("P%d", i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	eng.SetMaxRounds(1)
+	if err := eng.StartTournament(); err != nil {
+		t.Fatal(err)
+	}
+	var ids []int
+	for _, p := range eng.GetRound() {
+		ids = append(ids, p.PlayerA(), p.PlayerB())
+		if err := eng.AddResult(p.PlayerA(), 1, 1, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := eng.NextRound(); err != nil {
+		t.Fatal(err)
+	}
+	// Seeded against the player IDs, the last fallback, so the highest
+	// IDs make the cut.
+	sort.Sort(sort.Reverse(sort.IntSlice(ids)))
+	seeds := map[int]int64{}
+	for i, id := range ids {
+		seeds[id] = int64(i)
+	}
+	if err := StartPlayoff(&eng, &models.Tournament{TopCut: 2}, seeds); err != nil {
+		t.Fatal(err)
+	}
+	want := []int{ids[0], ids[1]}
+	if po := eng.GetPlayoff(); len(po.Seeds) != 2 || po.Seeds[0] != want[0] || po.Seeds[1] != want[1] {
+		t.Errorf("seeds %v, want %v", po.Seeds, want)
+	}
+	if final := eng.GetPlayoffRound(); len(final) != 1 || final[0].PlayerA() != want[0] || final[0].PlayerB() != want[1] {
+		t.Errorf("final %+v, want %v", final, want)
+	}
+}
@@ -0,0 +1,409 @@
+package engine
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/models"
+	st "github.com/dstathis/swisstools"
+)
+
+// FinalsStage is the stage name CombinedStandings gives a multi-stage
+// event's own rounds.
+const FinalsStage = "Finals"
+
+var (
+	errPodOfPod      = errors.New("a pod can't have pods of its own")
+	errFinalsStarted = errors.New("the finals have already started")
+)
+
+// preStart reports whether t hasn't started yet.
+func preStart(t *models.Tournament) bool {
+	return t.Status == models.TournamentStatusScheduled || t.Status == models.TournamentStatusRegistrationOpen
+}
+
+// CreatePod adds a pod called name to tournament parentID, making it a
+// multi-stage event whose own rounds are the finals. The pod takes the
+// event's schedule, format and scoring, but no player cap or top cut, and
+// opens for registration; the event's staff run it too. Pods can be added
+// until the finals start.
+func CreatePod(ctx context.Context, database *sql.DB, parentID int64, name string) (*models.Tournament, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, errors.New("a pod needs a name")
+	}
+	parent, err := db.GetTournament(ctx, database, parentID)
+	if err != nil {
+		return nil, err
+	}
+	if parent.ParentID != nil {
+		return nil, errPodOfPod
+	}
+	if !preStart(parent) {
+		return nil, errFinalsStarted
+	}
+	pod := podOf(parent, name)
+	if err := db.CreatePod(ctx, database, pod); err != nil {
+		return nil, err
+	}
+	return pod, nil
+}
+
+// podOf returns a pod of parent called name, as CreatePod adds it.
+func podOf(parent *models.Tournament, name string) *models.Tournament {
+	return &models.Tournament{
+		Name:                 name,
+		ScheduledAt:          parent.ScheduledAt,
+		Location:             parent.Location,
+		TimeZone:             parent.TimeZone,
+		PreviewPairings:      parent.PreviewPairings,
+		NumRounds:            parent.NumRounds,
+		RequireDecklist:      parent.RequireDecklist,
+		DecklistPublic:       parent.DecklistPublic,
+		PointsWin:            parent.PointsWin,
+		PointsDraw:           parent.PointsDraw,
+		PointsLoss:           parent.PointsLoss,
+		PairingAlgorithm:     parent.PairingAlgorithm,
+		ByePolicy:            parent.ByePolicy,
+		Round1Pairing:        parent.Round1Pairing,
+		Tiebreakers:          parent.Tiebreakers,
+		BestOf:               parent.BestOf,
+		SeriesMode:           parent.SeriesMode,
+		Colors:               parent.Colors,
+		AvoidClubRounds:      parent.AvoidClubRounds,
+		AutoRounds:           parent.AutoRounds,
+		SelfReport:           parent.SelfReport,
+		ReportConfirmMinutes: parent.ReportConfirmMinutes,
+		BotCheck:             parent.BotCheck,
+		BlindFinalRound:      parent.BlindFinalRound,
+		TeamSize:             parent.TeamSize,
+		MinPlayers:           parent.MinPlayers,
+		ParentID:             &parent.ID,
+		Status:               models.TournamentStatusRegistrationOpen,
+		OrganizerID:          parent.OrganizerID,
+	}
+}
+
+// MaxPools caps how many pools SplitPools deals, so each has a letter.
+const MaxPools = 26
+
+// SplitPools deals the confirmed players of tournament parentID into count
+// new pods called Pool A, Pool B, ... that play a round robin, making it a
+// multi-stage event as CreatePod does. Players are dealt by rating, highest
+// first and unrated last, snaking back and forth across the pools so each
+// gets an even share of the strong players. Their registrations move to
+// their pool; players still pending or waitlisted stay where they are.
+// The tournament must have no pods yet and enough players for two in each
+// pool.
+func SplitPools(ctx context.Context, database *sql.DB, parentID int64, count int) ([]models.Tournament, error) {
+	parent, err := db.GetTournament(ctx, database, parentID)
+	if err != nil {
+		return nil, err
+	}
+	if parent.ParentID != nil {
+		return nil, errPodOfPod
+	}
+	if !preStart(parent) {
+		return nil, errFinalsStarted
+	}
+	if count < 2 || count > MaxPools {
+		return nil, fmt.Errorf("split into 2 to %d pools", MaxPools)
+	}
+	if pods, err := db.ListPods(ctx, database, parentID); err != nil {
+		return nil, err
+	} else if len(pods) > 0 {
+		return nil, errors.New("the tournament already has pods")
+	}
+	regs, err := db.ListRegistrations(ctx, database, parentID)
+	if err != nil {
+		return nil, err
+	}
+	var confirmed []models.Registration
+	for _, r := range regs {
+		if r.Status == models.RegistrationStatusConfirmed {
+			confirmed = append(confirmed, r)
+		}
+	}
+	if len(confirmed) < 2*count {
+		return nil, fmt.Errorf("%d pools need at least %d confirmed players", count, 2*count)
+	}
+
+	pools := make([]*models.Tournament, count)
+	members := make([][]int64, count)
+	for i, dealt := range DealPools(byRating(confirmed), count) {
+		pools[i] = podOf(parent, fmt.Sprintf("Pool %c", 'A'+i))
+		pools[i].PairingAlgorithm = models.PairingRoundRobin
+		pools[i].NumRounds = nil
+		pools[i].AutoRounds = false
+		pools[i].MinPlayers = 2
+		for _, r := range dealt {
+			members[i] = append(members[i], r.ID)
+		}
+	}
+	if err := db.CreatePools(ctx, database, parentID, pools, members); err != nil {
+		return nil, err
+	}
+	out := make([]models.Tournament, count)
+	for i, p := range pools {
+		out[i] = *p
+	}
+	return out, nil
+}
+
+// DealPools deals regs, best first, into count pools like cards, snaking:
+// the first count go to pools 1..count, the next count back from count to
+// 1, and so on.
+func DealPools(regs []models.Registration, count int) [][]models.Registration {
+	pools := make([][]models.Registration, count)
+	for i, r := range regs {
+		pool := i % count
+		if (i/count)%2 == 1 {
+			pool = count - 1 - pool
+		}
+		pools[pool] = append(pools[pool], r)
+	}
+	return pools
+}
+
+// AdvancePods registers the top PodAdvance finishers of each pod of
+// tournament finalsID into it, by each pod's final standings, with their
+// seed for the finals (see models.Registration.PodSeed). Players who
+// dropped are passed over for the next in line. Every pod must be complete
+// and the finals not yet started. Players already advanced are skipped, so
+// running it again only fills gaps; it returns how many it added.
+func AdvancePods(ctx context.Context, database *sql.DB, finalsID int64) (int, error) {
+	t, err := db.GetTournament(ctx, database, finalsID)
+	if err != nil {
+		return 0, err
+	}
+	if t.ParentID != nil {
+		return 0, errPodOfPod
+	}
+	if t.PodAdvance == 0 {
+		return 0, errors.New("set how many players advance from each pod first")
+	}
+	if !preStart(t) {
+		return 0, errFinalsStarted
+	}
+	pods, err := db.ListPods(ctx, database, finalsID)
+	if err != nil {
+		return 0, err
+	}
+	if len(pods) == 0 {
+		return 0, errors.New("the tournament has no pods")
+	}
+
+	// Finalists are seeded pod winners first, then runners-up and so on,
+	// more points first among equal places, as in CombinedStandings.
+	type finalist struct {
+		reg    models.Registration
+		place  int
+		points int
+	}
+	var advancing []finalist
+	for i := range pods {
+		pod := &pods[i]
+		res, err := loadStage(ctx, database, pod)
+		if err != nil {
+			return 0, err
+		}
+		if res.eng == nil || !Complete(pod, res.eng) {
+			return 0, fmt.Errorf("pod %q isn't finished yet", pod.Name)
+		}
+		taken := 0
+		for _, p := range res.Placings {
+			if taken == t.PodAdvance {
+				break
+			}
+			reg, ok := res.reg(p.Standing.PlayerID)
+			if !ok || reg.Status == models.RegistrationStatusDropped {
+				continue
+			}
+			advancing = append(advancing, finalist{reg, taken + 1, p.Standing.Points})
+			taken++
+		}
+	}
+	sort.SliceStable(advancing, func(i, j int) bool {
+		a, b := advancing[i], advancing[j]
+		if a.place != b.place {
+			return a.place < b.place
+		}
+		if a.points != b.points {
+			return a.points > b.points
+		}
+		return a.reg.DisplayName < b.reg.DisplayName
+	})
+	seeded := make([]models.Registration, len(advancing))
+	for i, f := range advancing {
+		seeded[i] = f.reg
+	}
+	return db.AdvanceRegistrations(ctx, database, finalsID, seeded)
+}
+
+// StageResult is one stage of a multi-stage event: its name, its final
+// standings so far (none before it starts) and its registrations. Hidden
+// marks a stage in a blind final round (see StandingsHidden).
+type StageResult struct {
+	Name     string
+	Placings []Placing
+	Regs     []models.Registration
+	Hidden   bool
+
+	eng *st.Tournament
+}
+
+// reg returns the registration playing as engine player id.
+func (s *StageResult) reg(id int) (models.Registration, bool) {
+	for _, r := range s.Regs {
+		if r.EnginePlayerID != nil && *r.EnginePlayerID == id {
+			return r, true
+		}
+	}
+	return models.Registration{}, false
+}
+
+// loadStage reads t's registrations and, once it has started, its final
+// standings.
+func loadStage(ctx context.Context, database *sql.DB, t *models.Tournament) (*StageResult, error) {
+	regs, err := db.ListRegistrations(ctx, database, t.ID)
+	if err != nil {
+		return nil, err
+	}
+	res := &StageResult{Name: t.Name, Regs: regs}
+	if len(t.EngineState) > 0 {
+		eng, err := st.LoadTournament(t.EngineState)
+		if err != nil {
+			return nil, err
+		}
+		res.eng = &eng
+		res.Placings = FinalStandings(&eng, t.TiebreakOrder(), TiebreakSeeds(regs))
+		res.Hidden = StandingsHidden(t, &eng)
+	}
+	return res, nil
+}
+
+// StagesHidden reports whether any of the stages is Hidden.
+func StagesHidden(finals *StageResult, pods []StageResult) bool {
+	if finals.Hidden {
+		return true
+	}
+	for _, p := range pods {
+		if p.Hidden {
+			return true
+		}
+	}
+	return false
+}
+
+// HideStages drops the standings of the Hidden stages, for viewers below
+// Judge. CombinedStandings then leaves a hidden pod's players out and,
+// for hidden finals, ranks the finalists by their pods as before the
+// finals started.
+func HideStages(finals *StageResult, pods []StageResult) {
+	if finals.Hidden {
+		finals.Placings = nil
+	}
+	for i := range pods {
+		if pods[i].Hidden {
+			pods[i].Placings = nil
+		}
+	}
+}
+
+// LoadStages reads the finals (t itself) and each of pods for
+// CombinedStandings.
+func LoadStages(ctx context.Context, database *sql.DB, t *models.Tournament, pods []models.Tournament) (*StageResult, []StageResult, error) {
+	finals, err := loadStage(ctx, database, t)
+	if err != nil {
+		return nil, nil, err
+	}
+	finals.Name = FinalsStage
+	stages := make([]StageResult, 0, len(pods))
+	for i := range pods {
+		res, err := loadStage(ctx, database, &pods[i])
+		if err != nil {
+			return nil, nil, err
+		}
+		stages = append(stages, *res)
+	}
+	return finals, stages, nil
+}
+
+// StagePlacing is a player's line in a multi-stage event's combined
+// standings: their overall place, the last stage they played and where
+// they finished in it. Advanced marks a pod finisher who has a place in the
+// finals that haven't started yet.
+type StagePlacing struct {
+	Place      int    `json:"place"`
+	Name       string `json:"name"`
+	Stage      string `json:"stage"`
+	StagePlace int    `json:"stage_place"`
+	Points     int    `json:"points"`
+	Advanced   bool   `json:"advanced,omitempty"`
+}
+
+// CombinedStandings ranks everyone in a multi-stage event. Players in the
+// finals come first, in finals order; then everyone else by their place in
+// their pod, with pod winners ahead of pod runners-up and so on, points
+// deciding between equal places and then name. Before the finals start,
+// that puts the players who advanced at the top in pod order.
+func CombinedStandings(finals *StageResult, pods []StageResult) []StagePlacing {
+	var rows []StagePlacing
+	inFinals := map[int64]bool{}
+	advanced := map[int64]bool{}
+	for _, r := range finals.Regs {
+		if r.AdvancedFrom != nil {
+			advanced[*r.AdvancedFrom] = true
+		}
+	}
+	for _, p := range finals.Placings {
+		if r, ok := finals.reg(p.Standing.PlayerID); ok && r.AdvancedFrom != nil {
+			inFinals[*r.AdvancedFrom] = true
+		}
+		rows = append(rows, StagePlacing{
+			Name:       p.Standing.Name,
+			Stage:      finals.Name,
+			StagePlace: p.Place,
+			Points:     p.Standing.Points,
+		})
+	}
+
+	var rest []StagePlacing
+	for i := range pods {
+		pod := &pods[i]
+		for _, p := range pod.Placings {
+			r, _ := pod.reg(p.Standing.PlayerID)
+			if inFinals[r.ID] {
+				continue
+			}
+			rest = append(rest, StagePlacing{
+				Name:       p.Standing.Name,
+				Stage:      pod.Name,
+				StagePlace: p.Place,
+				Points:     p.Standing.Points,
+				Advanced:   advanced[r.ID],
+			})
+		}
+	}
+	sort.SliceStable(rest, func(i, j int) bool {
+		a, b := rest[i], rest[j]
+		if a.StagePlace != b.StagePlace {
+			return a.StagePlace < b.StagePlace
+		}
+		if a.Points != b.Points {
+			return a.Points > b.Points
+		}
+		return a.Name < b.Name
+	})
+
+	rows = append(rows, rest...)
+	for i := range rows {
+		rows[i].Place = i + 1
+	}
+	return rows
+}
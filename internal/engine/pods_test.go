@@ -0,0 +1,109 @@
+package engine
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/models"
+	st "github.com/dstathis/swisstools"
+)
+
+// podStage builds a finished stage whose players, named in finishing order,
+// have engine player IDs from 1 and registration IDs from firstReg.
+func podStage(name string, firstReg int64, points []int, players ...string) StageResult {
+	s := StageResult{Name: name}
+	for i, p := range players {
+		id := i + 1
+		s.Placings = append(s.Placings, Placing{
+			Place:    id,
+			Standing: st.PlayerStanding{PlayerID: id, Name: p, Points: points[i]},
+		})
+		s.Regs = append(s.Regs, models.Registration{ID: firstReg + int64(i), EnginePlayerID: &id, DisplayName: p})
+	}
+	return s
+}
+
+func TestCombinedStandings(t *testing.T) {
+	pods := []StageResult{
+		podStage("Pod A", 10, []int{9, 6, 3, 0}, "Ann", "Ben", "Cal", "Dee"),
+		podStage("Pod B", 20, []int{9, 3, 3}, "Eve", "Fay", "Gus"),
+	}
+	from := func(id int64) *int64 { return &id }
+
+	// Before the finals start: the advanced players lead, in pod order.
+	finals := &StageResult{Name: FinalsStage, Regs: []models.Registration{
+		{ID: 30, AdvancedFrom: from(10)}, {ID: 31, AdvancedFrom: from(11)},
+		{ID: 32, AdvancedFrom: from(20)}, {ID: 33, AdvancedFrom: from(21)},
+	}}
+	got := CombinedStandings(finals, pods)
+	want := []string{"Ann", "Eve", "Ben", "Fay", "Cal", "Gus", "Dee"}
+	if len(got) != len(want) {
+		t.Fatalf("got %d rows, want %d: %+v", len(got), len(want), got)
+	}
+	for i, name := range want {
+		if got[i].Name != name || got[i].Place != i+1 {
+			t.Errorf("row %d = %+v, want %s", i, got[i], name)
+		}
+		if adv := i < 4; got[i].Advanced != adv {
+			t.Errorf("%s advanced = %v, want %v", name, got[i].Advanced, adv)
+		}
+	}
+
+	// Once the finals are played, their order decides the top four.
+	played := podStage(FinalsStage, 30, []int{6, 6, 3, 0}, "Fay", "Ann", "Eve", "Ben")
+	for i, podReg := range []int64{21, 10, 20, 11} {
+		played.Regs[i].AdvancedFrom = from(podReg)
+	}
+	got = CombinedStandings(&played, pods)
+	want = []string{"Fay", "Ann", "Eve", "Ben", "Cal", "Gus", "Dee"}
+	if len(got) != len(want) {
+		t.Fatalf("got %d rows, want %d: %+v", len(got), len(want), got)
+	}
+	for i, name := range want {
+		if got[i].Name != name || got[i].Place != i+1 {
+			t.Errorf("row %d = %+v, want %s", i, got[i], name)
+		}
+	}
+	if got[0].Stage != FinalsStage || got[4].Stage != "Pod A" || got[4].StagePlace != 3 {
+		t.Errorf("stages = %+v", got)
+	}
+
+	// A blind final round hides its stage: hidden finals rank as before
+	// they started, and a hidden pod's players drop out.
+	if StagesHidden(&played, pods) {
+		t.Error("no stage is hidden")
+	}
+	played.Hidden, pods[1].Hidden = true, true
+	if !StagesHidden(&played, pods) {
+		t.Error("hidden stages not reported")
+	}
+	HideStages(&played, pods)
+	got = CombinedStandings(&played, pods)
+	want = []string{"Ann", "Ben", "Cal", "Dee"}
+	if len(got) != len(want) {
+		t.Fatalf("hidden: got %d rows, want %d: %+v", len(got), len(want), got)
+	}
+	for i, name := range want {
+		if got[i].Name != name || got[i].Stage != "Pod A" {
+			t.Errorf("hidden: row %d = %+v, want %s", i, got[i], name)
+		}
+	}
+}
+
+func TestDealPools(t *testing.T) {
+	var regs []models.Registration
+	for i := int64(0); i < 8; i++ {
+		regs = append(regs, models.Registration{ID: i})
+	}
+	got := DealPools(regs, 3)
+	want := [][]int64{{0, 5, 6}, {1, 4, 7}, {2, 3}}
+	for i := range want {
+		var ids []int64
+		for _, r := range got[i] {
+			ids = append(ids, r.ID)
+		}
+		if fmt.Sprint(ids) != fmt.Sprint(want[i]) {
+			t.Errorf("pool %d = %v, want %v", i, ids, want[i])
+		}
+	}
+}
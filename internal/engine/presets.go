@@ -0,0 +1,40 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+// ErrInvalidPreset wraps every reason a preset is refused before it is
+// saved.
+var ErrInvalidPreset = errors.New("invalid preset")
+
+// ReadPreset decodes an exported preset file. Its ID, author and times are
+// dropped: it is saved as a new preset, or over the one of its name.
+func ReadPreset(r io.Reader) (*models.Preset, error) {
+	var p models.Preset
+	if err := json.NewDecoder(r).Decode(&p); err != nil {
+		return nil, fmt.Errorf("%w: not an OpenSwiss preset file (%v)", ErrInvalidPreset, err)
+	}
+	return &models.Preset{Name: p.Name, Settings: p.Settings}, nil
+}
+
+// SavePreset checks p's name and settings and saves it as userID's,
+// replacing the preset of the same name if there is one.
+func SavePreset(ctx context.Context, database db.DBTX, p *models.Preset, userID int64) error {
+	name, err := models.ValidatePresetName(p.Name)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidPreset, err)
+	}
+	if err := p.Settings.Validate(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidPreset, err)
+	}
+	p.Name, p.CreatedBy = name, &userID
+	return db.SavePreset(ctx, database, p)
+}
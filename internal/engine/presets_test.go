@@ -0,0 +1,20 @@
+package engine
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestReadPreset(t *testing.T) {
+	p, err := ReadPreset(strings.NewReader(`{"id": 9, "name": "Weekly", "created_by": 2, "settings": {"points_win": 3, "pairing_fields": [{"label": "Board"}]}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.ID != 0 || p.CreatedBy != nil || p.Name != "Weekly" || p.Settings.PointsWin != 3 || len(p.Settings.PairingFields) != 1 {
+		t.Errorf("preset = %+v", p)
+	}
+	if _, err := ReadPreset(strings.NewReader("Name,Rounds\n")); !errors.Is(err, ErrInvalidPreset) {
+		t.Errorf("not JSON: got %v, want ErrInvalidPreset", err)
+	}
+}
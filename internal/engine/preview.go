@@ -0,0 +1,71 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/models"
+	st "github.com/dstathis/swisstools"
+)
+
+// ErrPairingsDraft is returned when a change needs the current round's
+// pairings published first.
+var ErrPairingsDraft = errors.New("the round's pairings are still a draft; publish them first")
+
+// PublishPairings makes the current round's draft pairings visible to
+// players and spectators. Must run inside WithTournamentEngine, which then
+// announces the round to webhooks.
+func PublishPairings(ctx context.Context, tx db.DBTX, t *models.Tournament, eng *st.Tournament) error {
+	round := eng.GetCurrentRound()
+	if t.Status != models.TournamentStatusInProgress || !t.PairingsDraft(round) {
+		return fmt.Errorf("round %d has no draft pairings to publish", round)
+	}
+	if err := db.SetDraftRound(ctx, tx, t.ID, 0); err != nil {
+		return err
+	}
+	t.DraftRound = 0
+	return nil
+}
+
+// SwapPlayers hand-edits the current draft round by swapping two players'
+// seats: each takes over the other's opponent and table, or bye. Only a
+// draft can be edited this way; a published round is re-paired instead (see
+// ApplyRepair). Must run inside WithTournamentEngine.
+func SwapPlayers(ctx context.Context, tx db.DBTX, t *models.Tournament, eng *st.Tournament, a, b int) error {
+	if !t.PairingsDraft(eng.GetCurrentRound()) {
+		return errors.New("only draft pairings can be edited by hand")
+	}
+	if a == b {
+		return errors.New("pick two different players")
+	}
+	p, _, okA := pairingOf(eng, a)
+	_, _, okB := pairingOf(eng, b)
+	if !okA || !okB {
+		return errors.New("both players must be paired this round")
+	}
+	if p.PlayerA() == b || p.PlayerB() == b {
+		return errors.New("those players are already paired against each other")
+	}
+	swap := func(id int) int {
+		switch id {
+		case a:
+			return b
+		case b:
+			return a
+		}
+		return id
+	}
+	parts := make([]string, 0, len(eng.GetRound()))
+	for _, p := range eng.GetRound() {
+		opp := "bye"
+		if p.PlayerB() != st.BYE_OPPONENT_ID {
+			opp = strconv.Itoa(swap(p.PlayerB()))
+		}
+		parts = append(parts, strconv.Itoa(swap(p.PlayerA()))+"-"+opp)
+	}
+	return ApplyRepair(ctx, tx, t, eng, RoundKey(eng), strings.Join(parts, ","))
+}
@@ -0,0 +1,79 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/dstathis/openswiss/internal/webhook"
+	st "github.com/dstathis/swisstools"
+)
+
+func TestAdvanceRound_Draft(t *testing.T) {
+	eng := fourPlayerRound(t)
+	tm := &models.Tournament{Status: models.TournamentStatusInProgress, PreviewPairings: true, DraftRound: 1}
+	if _, err := AdvanceRound(eng, tm, true, nil, nil, nil); !errors.Is(err, ErrPairingsDraft) {
+		t.Fatalf("AdvanceRound on a draft = %v, want ErrPairingsDraft", err)
+	}
+	if eng.GetCurrentRound() != 1 {
+		t.Errorf("round = %d, want 1", eng.GetCurrentRound())
+	}
+
+	pcs := Preconditions(tm, eng, confirmedRegs(4))
+	if !pcs[ActionPublish].Allowed || pcs[ActionNextRound].Allowed {
+		t.Errorf("draft: publish %+v, next_round %+v", pcs[ActionPublish], pcs[ActionNextRound])
+	}
+	tm.DraftRound = 0
+	pcs = Preconditions(tm, eng, confirmedRegs(4))
+	if pcs[ActionPublish].Allowed {
+		t.Errorf("publish without a draft = %+v", pcs[ActionPublish])
+	}
+}
+
+func TestDiffEvents_Draft(t *testing.T) {
+	const running = models.TournamentStatusInProgress
+	eng := st.NewTournament()
+	for _, name := range []string{"A", "B", "C", "D"} {
+		if err := eng.AddPlayer(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := eng.StartTournament(); err != nil {
+		t.Fatal(err)
+	}
+
+	// A draft round isn't announced when it is paired, nor when it is
+	// re-paired, but once it is published.
+	if events := diffEvents(nil, &eng, models.TournamentStatusRegistrationOpen, running, [2]bool{false, true}, nil, nil); len(events) != 0 {
+		t.Errorf("draft paired: events = %v", eventNames(events))
+	}
+	events := diffEvents(cloneEngine(t, &eng), &eng, running, running, [2]bool{true, false}, nil, nil)
+	if len(events) != 1 || events[0].name != models.WebhookRoundPaired || events[0].data.(webhook.RoundPaired).Round != 1 {
+		t.Errorf("published: events = %v", eventNames(events))
+	}
+}
+
+func TestSwapPlayers_Refused(t *testing.T) {
+	eng := fourPlayerRound(t)
+	ctx := context.Background()
+	tm := &models.Tournament{Status: models.TournamentStatusInProgress, PreviewPairings: true}
+	p := eng.GetRound()[0]
+	if err := SwapPlayers(ctx, nil, tm, eng, p.PlayerA(), eng.GetRound()[1].PlayerA()); err == nil {
+		t.Error("swapped in a published round")
+	}
+	tm.DraftRound = 1
+	for _, pair := range [][2]int{{p.PlayerA(), p.PlayerA()}, {p.PlayerA(), p.PlayerB()}, {p.PlayerA(), 99}} {
+		if err := SwapPlayers(ctx, nil, tm, eng, pair[0], pair[1]); err == nil {
+			t.Errorf("swap %v accepted", pair)
+		}
+	}
+}
+
+func TestPublishPairings_NoDraft(t *testing.T) {
+	eng := fourPlayerRound(t)
+	tm := &models.Tournament{Status: models.TournamentStatusInProgress, PreviewPairings: true}
+	if err := PublishPairings(context.Background(), nil, tm, eng); err == nil {
+		t.Error("published a round that isn't a draft")
+	}
+}
@@ -0,0 +1,142 @@
+package engine
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/models"
+	st "github.com/dstathis/swisstools"
+)
+
+// AnonymousName is what an anonymized registration is called: "Player #"
+// and its ID, unique in the tournament.
+func AnonymousName(regID int64) string {
+	return fmt.Sprintf("Player #%d", regID)
+}
+
+// AnonymizeUser removes the user's personal data from every tournament
+// that has started: each of their registrations there becomes a guest
+// called AnonymousName, in the engine too, so pairings and standings keep
+// their shape under the new name (see AnonymizeRegistration). Finished
+// tournaments are changed as well; nothing else about them is. Sign-ups
+// for tournaments that haven't started are left alone. It all happens in
+// one transaction, so a failure leaves nothing half done. It returns how
+// many registrations it anonymized.
+func AnonymizeUser(ctx context.Context, database *sql.DB, userID int64) (int, error) {
+	return anonymizeUser(ctx, database, userID, nil)
+}
+
+// DeleteUser anonymizes the user's tournaments (see AnonymizeUser) and
+// then deletes their account, with their sign-ups for tournaments that
+// haven't started (see db.DeleteUser), in the same transaction. Tournaments
+// they created go to heirID as creator-of-record.
+func DeleteUser(ctx context.Context, database *sql.DB, userID, heirID int64) (int, error) {
+	return anonymizeUser(ctx, database, userID, func(tx *sql.Tx) error {
+		return db.DeleteUser(ctx, tx, userID, heirID)
+	})
+}
+
+// anonymizeUser does AnonymizeUser's work and then, if it is set, runs
+// then in the same transaction.
+func anonymizeUser(ctx context.Context, database *sql.DB, userID int64, then func(tx *sql.Tx) error) (int, error) {
+	tx, err := database.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("begin tx: %w", db.Busy(ctx, err))
+	}
+	defer tx.Rollback()
+	regs, err := db.ListAllUserRegistrations(ctx, tx, userID)
+	if err != nil {
+		return 0, err
+	}
+	// Tournaments are locked in ID order, so two of these can't deadlock.
+	sort.SliceStable(regs, func(i, j int) bool { return regs[i].TournamentID < regs[j].TournamentID })
+	n := 0
+	for _, reg := range regs {
+		t, err := db.GetTournamentForUpdate(ctx, tx, reg.TournamentID)
+		if err != nil {
+			return 0, fmt.Errorf("tournament %d: get tournament: %w", reg.TournamentID, err)
+		}
+		if len(t.EngineState) == 0 {
+			continue
+		}
+		// Read again under the lock, in case it changed hands meanwhile.
+		cur, err := db.GetRegistrationByID(ctx, tx, reg.ID)
+		if err != nil || cur.UserID == nil || *cur.UserID != userID {
+			continue
+		}
+		if err := anonymizeRegistration(ctx, tx, t, cur); err != nil {
+			return 0, fmt.Errorf("tournament %d: %w", reg.TournamentID, err)
+		}
+		n++
+	}
+	if then != nil {
+		if err := then(tx); err != nil {
+			return 0, err
+		}
+	}
+	return n, tx.Commit()
+}
+
+// AnonymizeRegistration removes the personal data of one registration of
+// tournament tournamentID, a guest's or a user's, in whatever state the
+// tournament is: it becomes a guest called AnonymousName, in the engine
+// too. The archived results follow, the registry entry it was made from is
+// deleted, and so are the tournament's snapshots, which still hold the old
+// name. It takes the tournament's row lock itself rather than going
+// through WithTournamentEngine, which refuses changes to a complete
+// tournament.
+func AnonymizeRegistration(ctx context.Context, database *sql.DB, tournamentID, regID int64) error {
+	tx, err := database.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", db.Busy(ctx, err))
+	}
+	defer tx.Rollback()
+	t, err := db.GetTournamentForUpdate(ctx, tx, tournamentID)
+	if err != nil {
+		return fmt.Errorf("get tournament: %w", err)
+	}
+	reg, err := registrationOf(ctx, tx, t, regID)
+	if err != nil {
+		return err
+	}
+	if err := anonymizeRegistration(ctx, tx, t, reg); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// anonymizeRegistration does AnonymizeRegistration's work on reg, in
+// tournament t, which tx holds locked.
+func anonymizeRegistration(ctx context.Context, tx *sql.Tx, t *models.Tournament, reg *models.Registration) error {
+	name := AnonymousName(reg.ID)
+	if len(t.EngineState) > 0 && reg.EnginePlayerID != nil {
+		eng, err := st.LoadTournament(t.EngineState)
+		if err != nil {
+			return fmt.Errorf("load engine state: %w", err)
+		}
+		if err := renameEnginePlayer(&eng, *reg.EnginePlayerID, name); err != nil {
+			return err
+		}
+		data, err := eng.DumpTournament()
+		if err != nil {
+			return fmt.Errorf("dump engine state: %w", err)
+		}
+		if err := db.UpdateTournamentEngineState(ctx, tx, t.ID, t.Status, data); err != nil {
+			return err
+		}
+		t.EngineState = data
+	}
+	if err := db.DeleteRegistrationPlayer(ctx, tx, reg.ID); err != nil {
+		return err
+	}
+	if err := db.AnonymizeRegistration(ctx, tx, reg.ID, name); err != nil {
+		return err
+	}
+	if err := db.AnonymizeArchivedResults(ctx, tx, t.ID, reg.UserID, reg.DisplayName, name); err != nil {
+		return err
+	}
+	return db.DeleteSnapshots(ctx, tx, t.ID)
+}
@@ -0,0 +1,107 @@
+//go:build integration
+
+package engine
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/models"
+	st "github.com/dstathis/swisstools"
+)
+
+func TestAnonymizeRegistration_Guest(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	tourn, _ := setupTournamentWithPlayers(t, database, 3)
+	guest, err := db.CreateGuestRegistration(ctx, database, tourn.ID, "Walk In")
+	if err != nil {
+		t.Fatal(err)
+	}
+	startEngine(t, database, tourn.ID)
+	if err := db.ArchiveTournament(ctx, database, tourn.ID, []models.ArchivedResult{{Place: 1, Name: "Walk In"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := AnonymizeRegistration(ctx, database, tourn.ID, guest.ID); err != nil {
+		t.Fatalf("AnonymizeRegistration: %v", err)
+	}
+	name := AnonymousName(guest.ID)
+	got, _ := db.GetRegistrationByID(ctx, database, guest.ID)
+	if got.DisplayName != name || got.EnginePlayerID == nil {
+		t.Fatalf("guest = %+v", got)
+	}
+	updated, _ := db.GetTournament(ctx, database, tourn.ID)
+	eng, err := st.LoadTournament(updated.EngineState)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p, _ := eng.GetPlayerById(*got.EnginePlayerID); p.Name != name {
+		t.Errorf("engine name = %q, want %q", p.Name, name)
+	}
+	if results, _ := db.ListArchivedResults(ctx, database, tourn.ID); len(results) != 1 || results[0].Name != name {
+		t.Errorf("archived = %+v", results)
+	}
+
+	other := &models.Tournament{Name: "Other", Status: models.TournamentStatusScheduled, OrganizerID: tourn.OrganizerID}
+	if err := db.CreateTournament(ctx, database, other); err != nil {
+		t.Fatal(err)
+	}
+	if err := AnonymizeRegistration(ctx, database, other.ID, guest.ID); err == nil {
+		t.Error("anonymized a registration of another tournament")
+	}
+}
+
+func TestDeleteUser_AllOrNothing(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	tourn, regs := setupTournamentWithPlayers(t, database, 2)
+	startEngine(t, database, tourn.ID)
+	userID := *regs[0].UserID
+	created := &models.Tournament{Name: "Theirs", Status: models.TournamentStatusScheduled, OrganizerID: userID}
+	if err := db.CreateTournament(ctx, database, created); err != nil {
+		t.Fatal(err)
+	}
+
+	// No heir to take their tournament: the deletion fails, and so does
+	// the anonymization before it.
+	if _, err := DeleteUser(ctx, database, userID, -1); err == nil {
+		t.Fatal("deleted with no heir")
+	}
+	if got, _ := db.GetRegistrationByID(ctx, database, regs[0].ID); got.UserID == nil || got.DisplayName != regs[0].DisplayName {
+		t.Errorf("registration after failed delete = %+v", got)
+	}
+	if _, err := db.GetUserByID(ctx, database, userID); err != nil {
+		t.Errorf("account after failed delete: %v", err)
+	}
+
+	if n, err := DeleteUser(ctx, database, userID, *regs[1].UserID); err != nil || n != 1 {
+		t.Fatalf("DeleteUser = %d, %v", n, err)
+	}
+	if got, _ := db.GetRegistrationByID(ctx, database, regs[0].ID); got.UserID != nil || got.DisplayName != AnonymousName(regs[0].ID) {
+		t.Errorf("registration after delete = %+v", got)
+	}
+}
+
+// startEngine starts tournament id with its registrations.
+func startEngine(t *testing.T, database *sql.DB, id int64) {
+	t.Helper()
+	ctx := context.Background()
+	regs, _ := db.ListRegistrations(ctx, database, id)
+	if err := WithTournamentEngine(ctx, database, id, func(tx *sql.Tx, tm *models.Tournament, eng *st.Tournament) (string, error) {
+		state, err := InitTournamentEngine(ctx, tx, tm, regs)
+		if err != nil {
+			return "", err
+		}
+		ne, err := st.LoadTournament(state)
+		if err != nil {
+			return "", err
+		}
+		*eng = ne
+		return models.TournamentStatusInProgress, nil
+	}); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+}
@@ -0,0 +1,80 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/models"
+	st "github.com/dstathis/swisstools"
+)
+
+// ErrTableReported is returned by QuickReport when the table already has a
+// result, e.g. because another scorekeeper got there first.
+var ErrTableReported = errors.New("this table already has a result")
+
+// QuickResult is one of the results a scorekeeper can tap on the quick
+// entry page: the games won by the table's player A (Wins) and player B
+// (Losses), and drawn games. Value names it in the form.
+type QuickResult struct {
+	Value  string
+	Label  string
+	Wins   int
+	Losses int
+	Draws  int
+}
+
+// QuickResults returns the results a best-of-bestOf match can end in, from
+// player A's clean sweep to player B's, then a draw: 2-0, 2-1, 1-2, 0-2 and
+// 1-1 for a best of three, the default when bestOf is unset. A best-of-one
+// draw is a drawn game.
+func QuickResults(bestOf int) []QuickResult {
+	if bestOf <= 0 {
+		bestOf = 3
+	}
+	need := bestOf/2 + 1
+	var out []QuickResult
+	add := func(wins, losses, draws int) {
+		v := fmt.Sprintf("%d-%d", wins, losses)
+		out = append(out, QuickResult{Value: v, Label: v, Wins: wins, Losses: losses, Draws: draws})
+	}
+	for lost := 0; lost < need; lost++ {
+		add(need, lost, 0)
+	}
+	for won := need - 1; won >= 0; won-- {
+		add(won, need, 0)
+	}
+	draw := QuickResult{Value: "draw", Label: "Draw", Wins: need - 1, Losses: need - 1}
+	if need == 1 {
+		draw.Draws = 1
+	}
+	return append(out, draw)
+}
+
+// QuickReport enters the result named value (see QuickResults) for the
+// match at table in the current round. Like the kiosk it only takes
+// matches without a result, and not in series mode or team events, which
+// report game by game or seat by seat; results already in are changed
+// from the rounds dashboard.
+func QuickReport(ctx context.Context, tx db.DBTX, t *models.Tournament, eng *st.Tournament, table int, value string) error {
+	if t.Status != models.TournamentStatusInProgress || eng.GetCurrentRound() == 0 {
+		return errors.New("there is no round to report a result for")
+	}
+	if t.SeriesMode || t.TeamSize > 0 {
+		return errors.New("quick entry doesn't take series or team results")
+	}
+	p, ok := pairingAtTable(eng, table)
+	if !ok {
+		return fmt.Errorf("there is no table %d this round", table)
+	}
+	if p.PlayerAWins() != st.UNINITIALIZED_RESULT {
+		return ErrTableReported
+	}
+	for _, q := range QuickResults(t.BestOf) {
+		if q.Value == value {
+			return RecordResult(ctx, tx, t, eng, p.PlayerA(), q.Wins, q.Losses, q.Draws)
+		}
+	}
+	return fmt.Errorf("unknown result %q", value)
+}
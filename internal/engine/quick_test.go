@@ -0,0 +1,72 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+func TestQuickResults(t *testing.T) {
+	values := func(bestOf int) []string {
+		var out []string
+		for _, q := range QuickResults(bestOf) {
+			out = append(out, q.Value)
+		}
+		return out
+	}
+	for _, tc := range []struct {
+		bestOf int
+		want   []string
+	}{
+		{0, []string{"2-0", "2-1", "1-2", "0-2", "draw"}},
+		{3, []string{"2-0", "2-1", "1-2", "0-2", "draw"}},
+		{1, []string{"1-0", "0-1", "draw"}},
+		{5, []string{"3-0", "3-1", "3-2", "2-3", "1-3", "0-3", "draw"}},
+	} {
+		if got := values(tc.bestOf); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("best of %d: %v, want %v", tc.bestOf, got, tc.want)
+		}
+	}
+
+	for bestOf, want := range map[int]QuickResult{
+		3: {Value: "draw", Label: "Draw", Wins: 1, Losses: 1},
+		1: {Value: "draw", Label: "Draw", Draws: 1},
+	} {
+		results := QuickResults(bestOf)
+		if got := results[len(results)-1]; got != want {
+			t.Errorf("best of %d draw = %+v, want %+v", bestOf, got, want)
+		}
+	}
+	if q := QuickResults(3)[1]; q.Wins != 2 || q.Losses != 1 || q.Draws != 0 {
+		t.Errorf("2-1 = %+v", q)
+	}
+}
+
+func TestQuickReport_Refused(t *testing.T) {
+	eng := fourPlayerRound(t) // table 1 has a result, table 2 doesn't
+	tm := &models.Tournament{Status: models.TournamentStatusInProgress}
+
+	if err := QuickReport(context.Background(), nil, tm, eng, 1, "2-0"); !errors.Is(err, ErrTableReported) {
+		t.Errorf("reported table: err = %v, want ErrTableReported", err)
+	}
+	for _, tc := range []struct {
+		table int
+		value string
+	}{{3, "2-0"}, {0, "2-0"}, {2, "3-0"}, {2, ""}} {
+		if err := QuickReport(context.Background(), nil, tm, eng, tc.table, tc.value); err == nil {
+			t.Errorf("table %d result %q accepted", tc.table, tc.value)
+		}
+	}
+	for _, refused := range []*models.Tournament{
+		{Status: models.TournamentStatusFinished},
+		{Status: models.TournamentStatusInProgress, SeriesMode: true, BestOf: 3},
+		{Status: models.TournamentStatusInProgress, TeamSize: 3},
+	} {
+		if err := QuickReport(context.Background(), nil, refused, eng, 2, "2-0"); err == nil {
+			t.Errorf("%+v: result accepted", refused)
+		}
+	}
+}
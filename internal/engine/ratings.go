@@ -0,0 +1,103 @@
+package engine
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+// MaxRating bounds the ratings staff can enter.
+const MaxRating = 10000
+
+// SetRatings sets players' ratings, keyed by registration ID, for seeding
+// round 1 (see InitTournamentEngine); a nil rating clears one. Ratings can
+// change until the tournament starts. Registrations of another tournament
+// are refused and nothing is changed.
+func SetRatings(ctx context.Context, database *sql.DB, tournamentID int64, ratings map[int64]*int) error {
+	for _, r := range ratings {
+		if r != nil && (*r < 0 || *r > MaxRating) {
+			return fmt.Errorf("ratings must be between 0 and %d", MaxRating)
+		}
+	}
+	tx, err := database.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+	t, err := db.GetTournamentForUpdate(ctx, tx, tournamentID)
+	if err != nil {
+		return fmt.Errorf("get tournament: %w", err)
+	}
+	if t.Status != models.TournamentStatusScheduled && t.Status != models.TournamentStatusRegistrationOpen {
+		return errors.New("ratings can only be changed before the tournament starts")
+	}
+	if err := db.SetRatings(ctx, tx, tournamentID, ratings); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return errors.New("player is not registered for this tournament")
+		}
+		return err
+	}
+	return tx.Commit()
+}
+
+// ParseRatings reads ratings pasted or typed as one player per line, "name,
+// rating", and maps them to regs by name, ignoring case. A tab or semicolon
+// works in place of the comma, so a spreadsheet column pair can be pasted
+// as is. A line without a rating clears it; blank lines are skipped. Every
+// unknown name or bad rating is reported at once.
+func ParseRatings(text string, regs []models.Registration) (map[int64]*int, error) {
+	return parseByName(text, regs, func(value string) (int, error) {
+		rating, err := strconv.Atoi(value)
+		if err != nil {
+			return 0, fmt.Errorf("%q is not a rating", value)
+		}
+		return rating, nil
+	})
+}
+
+// parseByName reads one "name, value" line per player, as ParseRatings
+// describes, turning each value with parse. The result is keyed by
+// registration ID; an empty value maps to nil.
+func parseByName[T any](text string, regs []models.Registration, parse func(string) (T, error)) (map[int64]*T, error) {
+	byName := make(map[string]int64, len(regs))
+	for _, r := range regs {
+		byName[strings.ToLower(r.DisplayName)] = r.ID
+	}
+	out := make(map[int64]*T)
+	var problems []string
+	for i, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		name, value := line, ""
+		if cut := strings.LastIndexAny(line, ",\t;"); cut >= 0 {
+			name, value = strings.TrimSpace(line[:cut]), strings.TrimSpace(line[cut+1:])
+		}
+		id, ok := byName[strings.ToLower(name)]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("line %d: no player called %q", i+1, name))
+			continue
+		}
+		if value == "" {
+			out[id] = nil
+			continue
+		}
+		v, err := parse(value)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("line %d: %v", i+1, err))
+			continue
+		}
+		out[id] = &v
+	}
+	if len(problems) > 0 {
+		return nil, errors.New(strings.Join(problems, "; "))
+	}
+	return out, nil
+}
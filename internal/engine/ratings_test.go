@@ -0,0 +1,28 @@
+package engine
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+func TestParseRatings(t *testing.T) {
+	regs := []models.Registration{
+		{ID: 1, DisplayName: "Alice"},
+		{ID: 2, DisplayName: "Bob, Jr."},
+		{ID: 3, DisplayName: "Carol"},
+	}
+	got, err := ParseRatings("alice, 2100\n\nBob, Jr.\t1850\r\nCarol;\n", regs)
+	if err != nil {
+		t.Fatalf("ParseRatings: %v", err)
+	}
+	if len(got) != 3 || *got[1] != 2100 || *got[2] != 1850 || got[3] != nil {
+		t.Errorf("ratings = %v", got)
+	}
+
+	_, err = ParseRatings("Alice, 2100\nDave, 1500\nCarol, high", regs)
+	if err == nil || !strings.Contains(err.Error(), `line 2: no player called "Dave"`) || !strings.Contains(err.Error(), `line 3: "high"`) {
+		t.Errorf("err = %v, want both bad lines reported", err)
+	}
+}
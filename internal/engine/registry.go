@@ -0,0 +1,172 @@
+package engine
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/models"
+	st "github.com/dstathis/swisstools"
+)
+
+// CheckPlayer tidies a registry entry before it is saved: it normalizes the
+// name (see models.ValidatePlayerName) and trims the contact and club,
+// dropping an empty contact or club, and makes sure the name is a valid
+// one and any rating and club are ones staff could enter on a
+// registration.
+func CheckPlayer(p *models.Player) error {
+	name, err := models.ValidatePlayerName(p.Name)
+	if err != nil {
+		return err
+	}
+	p.Name = name
+	if p.Contact != nil {
+		if c := strings.TrimSpace(*p.Contact); c != "" {
+			p.Contact = &c
+		} else {
+			p.Contact = nil
+		}
+	}
+	if p.Rating != nil && (*p.Rating < 0 || *p.Rating > MaxRating) {
+		return fmt.Errorf("ratings must be between 0 and %d", MaxRating)
+	}
+	club, err := CheckClub(p.Club)
+	p.Club = club
+	return err
+}
+
+// EventRecord is how a registry player did in one tournament. Until the
+// tournament has started only the tournament is set; Place is their
+// standing so far while it runs, and their final place once it is Final.
+// Points, Wins, Losses and Draws are their Swiss record.
+type EventRecord struct {
+	TournamentID int64     `json:"tournament_id"`
+	Tournament   string    `json:"tournament"`
+	Date         time.Time `json:"date"`
+	Name         string    `json:"name"`
+	Played       bool      `json:"played"`
+	Final        bool      `json:"final"`
+	Place        int       `json:"place,omitempty"`
+	Field        int       `json:"field,omitempty"`
+	Playoff      string    `json:"playoff,omitempty"`
+	Points       int       `json:"points"`
+	Wins         int       `json:"wins"`
+	Losses       int       `json:"losses"`
+	Draws        int       `json:"draws"`
+}
+
+// PlayerEvent is reg's record in t, whose registrations are regs and whose
+// engine is eng (nil before it starts). The date is the tournament's
+// scheduled start, or when it was created if it had none.
+func PlayerEvent(t *models.Tournament, eng *st.Tournament, regs []models.Registration, reg models.Registration) EventRecord {
+	e := EventRecord{TournamentID: t.ID, Tournament: t.Name, Date: t.CreatedAt, Name: reg.DisplayName}
+	if t.ScheduledAt != nil {
+		e.Date = *t.ScheduledAt
+	}
+	if eng == nil || reg.EnginePlayerID == nil {
+		return e
+	}
+	placings := FinalStandings(eng, t.TiebreakOrder(), TiebreakSeeds(regs))
+	for _, p := range placings {
+		if p.Standing.PlayerID != *reg.EnginePlayerID {
+			continue
+		}
+		e.Played = true
+		e.Final = Complete(t, eng)
+		e.Place, e.Field, e.Playoff = p.Place, len(placings), p.Playoff
+		e.Points, e.Wins, e.Losses, e.Draws = p.Standing.Points, p.Standing.Wins, p.Standing.Losses, p.Standing.Draws
+	}
+	return e
+}
+
+// PlayerHistory loads a registry player's record in every tournament they
+// were registered in from the entry, newest registration first.
+func PlayerHistory(ctx context.Context, database *sql.DB, playerID int64) ([]EventRecord, error) {
+	linked, err := db.ListPlayerRegistrations(ctx, database, playerID)
+	if err != nil {
+		return nil, err
+	}
+	events := make([]EventRecord, 0, len(linked))
+	for _, reg := range linked {
+		t, err := db.GetTournament(ctx, database, reg.TournamentID)
+		if err != nil {
+			return nil, err
+		}
+		var eng *st.Tournament
+		var regs []models.Registration
+		if len(t.EngineState) > 0 {
+			loaded, err := st.LoadTournament(t.EngineState)
+			if err != nil {
+				return nil, err
+			}
+			eng = &loaded
+			if regs, err = db.ListRegistrations(ctx, database, t.ID); err != nil {
+				return nil, err
+			}
+		}
+		events = append(events, PlayerEvent(t, eng, regs, reg))
+	}
+	return events, nil
+}
+
+// Record is a registry player's results summed over tournaments: all of
+// them, or those of one Season (a calendar year). Events counts the
+// tournaments they played in, Titles those they won outright.
+type Record struct {
+	Season int `json:"season,omitempty"`
+	Events int `json:"events"`
+	Titles int `json:"titles"`
+	Points int `json:"points"`
+	Wins   int `json:"wins"`
+	Losses int `json:"losses"`
+	Draws  int `json:"draws"`
+}
+
+func (r *Record) add(e EventRecord) {
+	if !e.Played {
+		return
+	}
+	r.Events++
+	if e.Final && e.Place == 1 {
+		r.Titles++
+	}
+	r.Points += e.Points
+	r.Wins += e.Wins
+	r.Losses += e.Losses
+	r.Draws += e.Draws
+}
+
+// Lifetime sums events into a player's lifetime record.
+func Lifetime(events []EventRecord) Record {
+	var r Record
+	for _, e := range events {
+		r.add(e)
+	}
+	return r
+}
+
+// Seasons sums events by the year they were held, latest first. Years in
+// which the player only registered are left out.
+func Seasons(events []EventRecord) []Record {
+	byYear := make(map[int]*Record)
+	for _, e := range events {
+		if !e.Played {
+			continue
+		}
+		y := e.Date.Year()
+		if byYear[y] == nil {
+			byYear[y] = &Record{Season: y}
+		}
+		byYear[y].add(e)
+	}
+	out := make([]Record, 0, len(byYear))
+	for _, r := range byYear {
+		out = append(out, *r)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Season > out[j].Season })
+	return out
+}
@@ -0,0 +1,79 @@
+package engine
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+func TestPlayerEvent(t *testing.T) {
+	eng := swissDone(t)
+	winner := Standings(eng, nil, nil)[0]
+	id := winner.PlayerID
+	reg := models.Registration{ID: 7, DisplayName: winner.Name, EnginePlayerID: &id}
+	held := time.Date(2026, 3, 14, 10, 0, 0, 0, time.UTC)
+	tourn := &models.Tournament{ID: 3, Name: "March Open", ScheduledAt: &held, Status: models.TournamentStatusFinished}
+
+	got := PlayerEvent(tourn, eng, []models.Registration{reg}, reg)
+	want := EventRecord{
+		TournamentID: 3, Tournament: "March Open", Date: held, Name: winner.Name,
+		Played: true, Final: true, Place: 1, Field: 4,
+		Points: winner.Points, Wins: winner.Wins, Losses: winner.Losses, Draws: winner.Draws,
+	}
+	if got != want {
+		t.Errorf("PlayerEvent = %+v, want %+v", got, want)
+	}
+
+	// Before the tournament starts only the tournament is known.
+	tourn.Status = models.TournamentStatusRegistrationOpen
+	if got := PlayerEvent(tourn, nil, nil, models.Registration{DisplayName: "Ada"}); got.Played || got.Place != 0 || got.Date != held {
+		t.Errorf("unstarted PlayerEvent = %+v", got)
+	}
+}
+
+func TestLifetimeAndSeasons(t *testing.T) {
+	day := func(y int) time.Time { return time.Date(y, 6, 1, 0, 0, 0, 0, time.UTC) }
+	events := []EventRecord{
+		{Date: day(2026), Played: true, Final: true, Place: 1, Points: 9, Wins: 3},
+		{Date: day(2026), Played: true, Place: 1, Points: 3, Wins: 1, Losses: 1},
+		{Date: day(2025), Played: true, Final: true, Place: 4, Points: 4, Wins: 1, Losses: 1, Draws: 1},
+		{Date: day(2024)},
+	}
+	if got, want := Lifetime(events), (Record{Events: 3, Titles: 1, Points: 16, Wins: 5, Losses: 2, Draws: 1}); got != want {
+		t.Errorf("Lifetime = %+v, want %+v", got, want)
+	}
+	seasons := Seasons(events)
+	if len(seasons) != 2 {
+		t.Fatalf("Seasons = %+v, want 2025 and 2026 only", seasons)
+	}
+	if want := (Record{Season: 2026, Events: 2, Titles: 1, Points: 12, Wins: 4, Losses: 1}); seasons[0] != want {
+		t.Errorf("2026 = %+v, want %+v", seasons[0], want)
+	}
+	if seasons[1].Season != 2025 || seasons[1].Events != 1 || seasons[1].Titles != 0 {
+		t.Errorf("2025 = %+v", seasons[1])
+	}
+}
+
+func TestCheckPlayer(t *testing.T) {
+	blank, contact, bad := "  ", " ada@example.com ", MaxRating+1
+	p := &models.Player{Name: " Ada ", Contact: &contact}
+	if err := CheckPlayer(p); err != nil || p.Name != "Ada" || *p.Contact != "ada@example.com" {
+		t.Errorf("CheckPlayer = %v, %+v", err, p)
+	}
+	p.Contact = &blank
+	if err := CheckPlayer(p); err != nil || p.Contact != nil {
+		t.Errorf("blank contact kept: %v, %+v", err, p)
+	}
+	if err := CheckPlayer(&models.Player{Name: " "}); err == nil {
+		t.Error("CheckPlayer accepted a blank name")
+	}
+	if err := CheckPlayer(&models.Player{Name: "Ada", Rating: &bad}); err == nil {
+		t.Error("CheckPlayer accepted a rating above MaxRating")
+	}
+	club := " North CC "
+	p.Club = &club
+	if err := CheckPlayer(p); err != nil || p.Club == nil || *p.Club != "North CC" {
+		t.Errorf("club not trimmed: %v, %+v", err, p)
+	}
+}
@@ -0,0 +1,212 @@
+package engine
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/email"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/dstathis/openswiss/internal/webhook"
+	st "github.com/dstathis/swisstools"
+)
+
+// ReminderLead is how long before a round clock runs out the tables still
+// playing are reminded to report.
+const ReminderLead = 5 * time.Minute
+
+// ErrNoRoundUnderway is returned by StartRoundClock when the tournament has
+// no Swiss round being played.
+var ErrNoRoundUnderway = errors.New("no Swiss round is under way")
+
+// ErrNothingOutstanding is returned by Remind when the tournament has no
+// published Swiss round with a match still waiting for a result.
+var ErrNothingOutstanding = errors.New("no results are outstanding")
+
+// StartRoundClock starts the clock of tournament t's current Swiss round,
+// running minutes from now, and returns it. Its reminder goes out
+// ReminderLead before the end. It returns ErrNoRoundUnderway before the
+// start and once the Swiss is over.
+func StartRoundClock(ctx context.Context, database *sql.DB, t *models.Tournament, minutes int) (*models.RoundClock, error) {
+	if t.Status != models.TournamentStatusInProgress || len(t.EngineState) == 0 {
+		return nil, ErrNoRoundUnderway
+	}
+	eng, err := st.LoadTournament(t.EngineState)
+	if err != nil {
+		return nil, err
+	}
+	if eng.GetStatus() == "finished" || eng.GetCurrentRound() == 0 {
+		return nil, ErrNoRoundUnderway
+	}
+	return db.SetRoundClock(ctx, database, t.ID, eng.GetCurrentRound(), time.Now().Add(time.Duration(minutes)*time.Minute))
+}
+
+// Outstanding returns the current Swiss round's matches still waiting for a
+// result, in table order (see MissingTables), with the players' names.
+func Outstanding(eng *st.Tournament) []webhook.Pairing {
+	out := []webhook.Pairing{}
+	for i, p := range eng.GetRound() {
+		if p.PlayerB() != st.BYE_OPPONENT_ID && p.PlayerAWins() == st.UNINITIALIZED_RESULT {
+			out = append(out, webhookPairing(eng, i, p))
+		}
+	}
+	return out
+}
+
+// Reminded is a reminder that went out: the round, its clock if one was
+// set, and the matches reminded.
+type Reminded struct {
+	Round    int                `json:"round"`
+	Clock    *models.RoundClock `json:"clock,omitempty"`
+	Pairings []webhook.Pairing  `json:"pairings"`
+}
+
+// Remind reminds the tables of tournament tournamentID's current Swiss
+// round that have no result yet to report: it queues a results.outstanding
+// webhook event, marks the round's clock reminded, and then emails each of
+// their players who has an account and hasn't turned email notifications
+// off, when sender is configured. tournamentURL is linked from the emails.
+// It returns ErrNothingOutstanding if no match is waiting, before the
+// start, in a draft round or once the Swiss is over.
+func Remind(ctx context.Context, database *sql.DB, sender *email.Sender, tournamentURL string, tournamentID int64) (*Reminded, error) {
+	return remind(ctx, database, sender, tournamentURL, tournamentID, 0)
+}
+
+// remind is Remind, except that unless only is 0 it also returns
+// ErrNothingOutstanding when the current round isn't round only.
+func remind(ctx context.Context, database *sql.DB, sender *email.Sender, tournamentURL string, tournamentID int64, only int) (*Reminded, error) {
+	tx, err := database.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	t, err := db.GetTournamentForUpdate(ctx, tx, tournamentID)
+	if err != nil {
+		return nil, err
+	}
+	if t.Status != models.TournamentStatusInProgress || len(t.EngineState) == 0 {
+		return nil, ErrNothingOutstanding
+	}
+	eng, err := st.LoadTournament(t.EngineState)
+	if err != nil {
+		return nil, err
+	}
+	round := eng.GetCurrentRound()
+	pairings := Outstanding(&eng)
+	if eng.GetStatus() == "finished" || t.PairingsDraft(round) || len(pairings) == 0 || (only != 0 && round != only) {
+		return nil, ErrNothingOutstanding
+	}
+	clock, err := db.GetRoundClock(ctx, tx, t.ID, round)
+	if err != nil {
+		return nil, err
+	}
+	data := webhook.ResultsOutstanding{Stage: webhook.StageSwiss, Round: round, Pairings: pairings}
+	if clock != nil {
+		data.EndsAt = &clock.EndsAt
+	}
+	body, err := json.Marshal(webhook.Envelope{
+		Event:      models.WebhookResultsOutstanding,
+		Tournament: webhook.Tournament{ID: t.ID, Name: t.Name},
+		OccurredAt: time.Now().UTC(),
+		Data:       data,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := db.EnqueueWebhookEvent(ctx, tx, t.ID, models.WebhookResultsOutstanding, body); err != nil {
+		return nil, err
+	}
+	if err := db.MarkRoundClockReminded(ctx, tx, t.ID, round); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	if sender != nil && sender.Config.Enabled() {
+		emailPlayers(ctx, database, sender, t, round, pairings, tournamentURL)
+	}
+	return &Reminded{Round: round, Clock: clock, Pairings: pairings}, nil
+}
+
+// emailPlayers sends the reminder email to every player of pairings who
+// has an account and is notified by email. Failures are logged; the
+// reminder stands.
+func emailPlayers(ctx context.Context, database *sql.DB, sender *email.Sender, t *models.Tournament, round int, pairings []webhook.Pairing, tournamentURL string) {
+	regs, err := db.ListRegistrations(ctx, database, t.ID)
+	if err != nil {
+		slog.ErrorContext(ctx, "result reminder emails", "tournament_id", t.ID, "err", err)
+		return
+	}
+	users := make(map[int]int64, len(regs))
+	for _, reg := range regs {
+		if reg.EnginePlayerID != nil && reg.UserID != nil {
+			users[*reg.EnginePlayerID] = *reg.UserID
+		}
+	}
+	for _, p := range pairings {
+		for _, player := range []int{p.PlayerA, p.PlayerB} {
+			userID, ok := users[player]
+			if !ok {
+				continue
+			}
+			u, err := db.GetUserByID(ctx, database, userID)
+			if err == nil && u.Notifies(models.NotifyEmail) {
+				err = sender.SendResultReminder(u.Email, t.Name, round, p.Table, tournamentURL)
+			}
+			if err != nil {
+				slog.ErrorContext(ctx, "result reminder email", "tournament_id", t.ID, "user_id", userID, "err", err)
+			}
+		}
+	}
+}
+
+// Reminder sends the results reminder of every round clock about to run out
+// (see ReminderLead), checking every Interval.
+type Reminder struct {
+	DB       *sql.DB
+	Email    *email.Sender
+	BaseURL  string
+	Interval time.Duration
+}
+
+// Run checks the round clocks every Interval until ctx is cancelled.
+func (r *Reminder) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if err := r.Remind(ctx); err != nil && ctx.Err() == nil {
+			slog.ErrorContext(ctx, "result reminders", "err", err)
+		}
+	}
+}
+
+// Remind sends the reminders that are due. A clock whose round is over, or
+// whose tables have all reported, is marked reminded without sending one.
+func (r *Reminder) Remind(ctx context.Context) error {
+	clocks, err := db.ListDueRoundClocks(ctx, r.DB, time.Now().Add(ReminderLead))
+	if err != nil {
+		return err
+	}
+	for _, c := range clocks {
+		url := fmt.Sprintf("%s/tournaments/%d", r.BaseURL, c.TournamentID)
+		_, err := remind(ctx, r.DB, r.Email, url, c.TournamentID, c.Round)
+		if errors.Is(err, ErrNothingOutstanding) || errors.Is(err, sql.ErrNoRows) {
+			err = db.MarkRoundClockReminded(ctx, r.DB, c.TournamentID, c.Round)
+		}
+		if err != nil {
+			slog.ErrorContext(ctx, "result reminder", "tournament_id", c.TournamentID, "err", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+//go:build integration
+
+package engine
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/models"
+	st "github.com/dstathis/swisstools"
+)
+
+func TestReminder(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	tourn, regs := setupTournamentWithPlayers(t, database, 4)
+	wh := &models.Webhook{TournamentID: tourn.ID, URL: "https://hooks.example", Secret: "s", Events: []string{models.WebhookResultsOutstanding}}
+	if err := db.CreateWebhook(ctx, database, wh); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := StartRoundClock(ctx, database, tourn, 50); !errors.Is(err, ErrNoRoundUnderway) {
+		t.Errorf("clock before the start: err = %v", err)
+	}
+
+	// Start with a result at table 1 only.
+	if err := WithTournamentEngine(ctx, database, tourn.ID, func(tx *sql.Tx, tm *models.Tournament, eng *st.Tournament) (string, error) {
+		state, err := InitTournamentEngine(ctx, tx, tm, regs)
+		if err != nil {
+			return "", err
+		}
+		if *eng, err = st.LoadTournament(state); err != nil {
+			return "", err
+		}
+		return models.TournamentStatusInProgress, eng.AddResult(eng.GetRound()[0].PlayerA(), 2, 0, 0)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	tourn, _ = db.GetTournament(ctx, database, tourn.ID)
+
+	// A clock far from its end isn't due yet.
+	r := &Reminder{DB: database, BaseURL: "https://swiss.example"}
+	if _, err := StartRoundClock(ctx, database, tourn, 60); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Remind(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if ds, _ := db.ListWebhookDeliveries(ctx, database, tourn.ID, 10); len(ds) != 0 {
+		t.Errorf("reminded an hour early: %+v", ds)
+	}
+
+	clock, err := StartRoundClock(ctx, database, tourn, 3)
+	if err != nil || clock.Round != 1 {
+		t.Fatalf("StartRoundClock = %+v, %v", clock, err)
+	}
+	if err := r.Remind(ctx); err != nil {
+		t.Fatal(err)
+	}
+	ds, _ := db.ListWebhookDeliveries(ctx, database, tourn.ID, 10)
+	if len(ds) != 1 || ds[0].Event != models.WebhookResultsOutstanding {
+		t.Fatalf("deliveries after the due reminder = %+v", ds)
+	}
+	if c, _ := db.GetRoundClock(ctx, database, tourn.ID, 1); c == nil || c.RemindedAt == nil {
+		t.Errorf("clock after its reminder = %+v", c)
+	}
+	// Once is enough.
+	if err := r.Remind(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if ds, _ = db.ListWebhookDeliveries(ctx, database, tourn.ID, 10); len(ds) != 1 {
+		t.Errorf("reminded twice: %d deliveries", len(ds))
+	}
+
+	// Staff can still remind by hand until every result is in.
+	sent, err := Remind(ctx, database, nil, "", tourn.ID)
+	if err != nil || sent.Round != 1 || len(sent.Pairings) != 1 || sent.Pairings[0].Table != 2 || sent.Clock == nil {
+		t.Fatalf("Remind = %+v, %v", sent, err)
+	}
+	if err := WithTournamentEngine(ctx, database, tourn.ID, func(tx *sql.Tx, tm *models.Tournament, eng *st.Tournament) (string, error) {
+		return "", eng.AddResult(eng.GetRound()[1].PlayerA(), 2, 1, 0)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Remind(ctx, database, nil, "", tourn.ID); !errors.Is(err, ErrNothingOutstanding) {
+		t.Errorf("Remind with every result in: err = %v", err)
+	}
+}
@@ -0,0 +1,21 @@
+package engine
+
+import (
+	"testing"
+)
+
+func TestOutstanding(t *testing.T) {
+	eng := fourPlayerRound(t)
+	got := Outstanding(eng)
+	if len(got) != 1 || got[0].Table != 2 || got[0].PlayerAName == "" || got[0].PlayerBName == "" {
+		t.Fatalf("Outstanding = %+v, want table 2", got)
+	}
+
+	p := eng.GetRound()[1]
+	if err := eng.AddResult(p.PlayerA(), 1, 2, 0); err != nil {
+		t.Fatal(err)
+	}
+	if got := Outstanding(eng); got == nil || len(got) != 0 {
+		t.Errorf("Outstanding with every result in = %#v, want empty", got)
+	}
+}
@@ -0,0 +1,196 @@
+package engine
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/models"
+	st "github.com/dstathis/swisstools"
+)
+
+// ErrRoundChanged is returned by ApplyRepair when the current round no longer
+// matches the one the proposal was previewed against.
+var ErrRoundChanged = errors.New("the round changed since the re-pair was previewed; preview it again")
+
+// PreviewRepair pairs the current round again on a copy of eng and returns
+// the copy. eng itself is left untouched, so the proposal can be shown next
+// to the live round and applied later with ApplyRepair. assigned holds the
+// byes staff assigned, by round, clubs the players' clubs (see Clubs) and
+// seeds their tiebreak seeds (see Seeds).
+func PreviewRepair(t *models.Tournament, eng *st.Tournament, assigned map[int][]int, clubs map[int]string, seeds map[int]int64) (*st.Tournament, error) {
+	if t.Status != models.TournamentStatusInProgress {
+		return nil, errors.New("only a running Swiss round can be re-paired")
+	}
+	data, err := eng.DumpTournament()
+	if err != nil {
+		return nil, fmt.Errorf("dump engine state: %w", err)
+	}
+	proposed, err := st.LoadTournament(data)
+	if err != nil {
+		return nil, fmt.Errorf("load engine state: %w", err)
+	}
+	if err := PairRound(&proposed, t, true, assigned[proposed.GetCurrentRound()], clubs, seeds); err != nil {
+		return nil, err
+	}
+	return &proposed, nil
+}
+
+// RoundKey identifies the current round's pairings and results. A preview
+// carries it so ApplyRepair can tell whether anything changed in between.
+func RoundKey(eng *st.Tournament) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d;", eng.GetCurrentRound())
+	for _, p := range eng.GetRound() {
+		fmt.Fprintf(&b, "%d-%d:%d/%d/%d,", p.PlayerA(), p.PlayerB(), p.PlayerAWins(), p.PlayerBWins(), p.Draws())
+	}
+	sum := sha256.Sum256([]byte(b.String()))
+	return hex.EncodeToString(sum[:8])
+}
+
+// EncodeRound writes a round's pairings, in table order, as "a-b,c-d,e-bye"
+// using engine player IDs. ApplyRepair takes this form back.
+func EncodeRound(pairings []st.Pairing) string {
+	parts := make([]string, len(pairings))
+	for i, p := range pairings {
+		b := strconv.Itoa(p.PlayerB())
+		if p.PlayerB() == st.BYE_OPPONENT_ID {
+			b = "bye"
+		}
+		parts[i] = strconv.Itoa(p.PlayerA()) + "-" + b
+	}
+	return strings.Join(parts, ",")
+}
+
+// decodeRound parses EncodeRound's form and checks that it seats every
+// active player of eng exactly once, with byes only for the assigned players
+// and an odd player out.
+func decodeRound(eng *st.Tournament, s string, assigned int) ([]statePairing, error) {
+	active := activePlayers(eng)
+	seen := map[int]bool{}
+	seat := func(field string) (int, error) {
+		id, err := strconv.Atoi(field)
+		if err != nil {
+			return 0, fmt.Errorf("invalid player %q", field)
+		}
+		p, ok := eng.GetPlayerById(id)
+		if !ok || p.Removed {
+			return 0, fmt.Errorf("player %d is not in the tournament", id)
+		}
+		if seen[id] {
+			return 0, fmt.Errorf("player %d is paired twice", id)
+		}
+		seen[id] = true
+		return id, nil
+	}
+
+	var round []statePairing
+	byes := 0
+	for _, part := range strings.Split(s, ",") {
+		a, b, ok := strings.Cut(strings.TrimSpace(part), "-")
+		if !ok {
+			return nil, fmt.Errorf("invalid pairing %q", part)
+		}
+		pa, err := seat(a)
+		if err != nil {
+			return nil, err
+		}
+		pb := st.BYE_OPPONENT_ID
+		if b == "bye" {
+			byes++
+		} else if pb, err = seat(b); err != nil {
+			return nil, err
+		}
+		round = append(round, statePairing{PlayerA: pa, PlayerB: pb})
+	}
+	if len(seen) != active {
+		return nil, fmt.Errorf("proposal seats %d of %d players", len(seen), active)
+	}
+	if byes != assigned+(active-assigned)%2 {
+		return nil, errors.New("proposal has the wrong number of byes")
+	}
+	return round, nil
+}
+
+// ApplyRepair replaces the current round with a proposal previewed by
+// PreviewRepair, provided the round still has the key it was previewed
+// against. Byes get the configured bye score and every other result starts
+// unset, as with RepairRound. Must run inside WithTournamentEngine.
+func ApplyRepair(ctx context.Context, tx db.DBTX, t *models.Tournament, eng *st.Tournament, roundKey, proposal string) error {
+	if t.Status != models.TournamentStatusInProgress {
+		return errors.New("only a running Swiss round can be re-paired")
+	}
+	if RoundKey(eng) != roundKey {
+		return ErrRoundChanged
+	}
+	assigned, err := AssignedByes(ctx, tx, t, eng)
+	if err != nil {
+		return err
+	}
+	round, err := decodeRound(eng, proposal, len(assigned[eng.GetCurrentRound()]))
+	if err != nil {
+		return err
+	}
+	err = editState(eng, func(s *engineState) error {
+		if s.CurrentRound < 1 || s.CurrentRound >= len(s.Rounds) {
+			return errors.New("invalid tournament state: current round must be >= 1")
+		}
+		for i, p := range round {
+			if p.isBye() {
+				round[i].PlayerAWins, round[i].PlayerBWins, round[i].Draws = s.Config.ByeWins, s.Config.ByeLosses, s.Config.ByeDraws
+			} else {
+				u := st.UNINITIALIZED_RESULT
+				round[i].PlayerAWins, round[i].PlayerBWins, round[i].Draws = u, u, u
+			}
+		}
+		s.Rounds[s.CurrentRound] = round
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return clearRoundExtras(ctx, tx, t, eng.GetCurrentRound())
+}
+
+// RepairChange says what re-pairing does to one match of the current round.
+type RepairChange struct {
+	// Table is the match's current table, 0 for a bye.
+	Table int `json:"table"`
+	// NewTable is where the same match sits in the proposal (0 for a bye
+	// that stays a bye), or -1 if the match is broken up.
+	NewTable int `json:"new_table"`
+	// Reported is set when a result has been entered; re-pairing discards
+	// it even if the match survives.
+	Reported bool `json:"reported"`
+}
+
+// DiffRepair compares the current round with a proposed one, returning one
+// change per current pairing in the same order.
+func DiffRepair(current, proposed []st.Pairing) []RepairChange {
+	type match struct{ a, b int }
+	key := func(p st.Pairing) match {
+		if p.PlayerB() != st.BYE_OPPONENT_ID && p.PlayerB() < p.PlayerA() {
+			return match{p.PlayerB(), p.PlayerA()}
+		}
+		return match{p.PlayerA(), p.PlayerB()}
+	}
+	newTable := make(map[match]int, len(proposed))
+	for i, p := range proposed {
+		newTable[key(p)] = TableNumber(i, p)
+	}
+	out := make([]RepairChange, len(current))
+	for i, p := range current {
+		c := RepairChange{Table: TableNumber(i, p), NewTable: -1}
+		if n, ok := newTable[key(p)]; ok {
+			c.NewTable = n
+		}
+		c.Reported = p.PlayerB() != st.BYE_OPPONENT_ID && p.PlayerAWins() != st.UNINITIALIZED_RESULT
+		out[i] = c
+	}
+	return out
+}
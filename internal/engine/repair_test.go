@@ -0,0 +1,127 @@
+package engine
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/models"
+	st "github.com/dstathis/swisstools"
+)
+
+func TestPreviewRepair(t *testing.T) {
+	tourn := &models.Tournament{Status: models.TournamentStatusInProgress}
+	eng := playedEngine(t, 9)
+	if err := PairRound(eng, tourn, false, nil, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := eng.AddResult(eng.GetRound()[0].PlayerA(), 2, 1, 0); err != nil {
+		t.Fatal(err)
+	}
+	key := RoundKey(eng)
+	before := EncodeRound(eng.GetRound())
+
+	proposed, err := PreviewRepair(tourn, eng, nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if RoundKey(eng) != key || EncodeRound(eng.GetRound()) != before {
+		t.Fatal("preview changed the live round")
+	}
+	if _, err := decodeRound(eng, EncodeRound(proposed.GetRound()), 0); err != nil {
+		t.Errorf("proposal doesn't decode: %v", err)
+	}
+
+	// Entering a result changes the key.
+	if err := eng.AddResult(eng.GetRound()[1].PlayerA(), 0, 2, 0); err != nil {
+		t.Fatal(err)
+	}
+	if RoundKey(eng) == key {
+		t.Error("RoundKey unchanged after a result")
+	}
+
+	if _, err := PreviewRepair(&models.Tournament{Status: models.TournamentStatusFinished}, eng, nil, nil, nil); err == nil {
+		t.Error("previewed a re-pair of a finished tournament")
+	}
+}
+
+func TestDecodeRound(t *testing.T) {
+	eng := playedEngine(t, 5)
+	var ids []int
+	for id := range eng.GetPlayers() {
+		ids = append(ids, id)
+	}
+	enc := func(pairs ...[2]int) string {
+		var parts []string
+		for _, p := range pairs {
+			b := "bye"
+			if p[1] >= 0 {
+				b = strconv.Itoa(ids[p[1]])
+			}
+			parts = append(parts, strconv.Itoa(ids[p[0]])+"-"+b)
+		}
+		return strings.Join(parts, ",")
+	}
+
+	round, err := decodeRound(eng, enc([2]int{0, 1}, [2]int{2, 3}, [2]int{4, -1}), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(round) != 3 || !round[2].isBye() || round[0].PlayerA != ids[0] {
+		t.Errorf("round = %+v", round)
+	}
+
+	for name, s := range map[string]string{
+		"empty":          "",
+		"missing player": enc([2]int{0, 1}, [2]int{2, 3}),
+		"paired twice":   enc([2]int{0, 1}, [2]int{1, 3}, [2]int{4, -1}),
+		"two byes":       enc([2]int{0, 1}, [2]int{2, -1}, [2]int{3, 4}, [2]int{4, -1}),
+		"unknown player": "999-" + strconv.Itoa(ids[0]),
+		"garbage":        "a-b",
+	} {
+		if _, err := decodeRound(eng, s, 0); err == nil {
+			t.Errorf("%s: %q accepted", name, s)
+		}
+	}
+}
+
+func TestDiffRepair(t *testing.T) {
+	eng := playedEngine(t, 4)
+	if err := PairRound(eng, &models.Tournament{}, false, nil, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	current := eng.GetRound()
+	if err := eng.AddResult(current[1].PlayerA(), 2, 0, 0); err != nil {
+		t.Fatal(err)
+	}
+	current = eng.GetRound()
+
+	// Same round, tables swapped: both matches survive at the other table.
+	swapped := []st.Pairing{current[1], current[0]}
+	changes := DiffRepair(current, swapped)
+	if changes[0].NewTable != 2 || changes[1].NewTable != 1 {
+		t.Errorf("swapped: %+v", changes)
+	}
+	if changes[0].Reported || !changes[1].Reported {
+		t.Errorf("reported flags: %+v", changes)
+	}
+
+	// A different round breaks both matches up.
+	other, err := PreviewRepair(&models.Tournament{Status: models.TournamentStatusInProgress}, eng, nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, c := range DiffRepair(current, other.GetRound()) {
+		p := other.GetRound()
+		same := false
+		for _, q := range p {
+			if (q.PlayerA() == current[i].PlayerA() && q.PlayerB() == current[i].PlayerB()) ||
+				(q.PlayerA() == current[i].PlayerB() && q.PlayerB() == current[i].PlayerA()) {
+				same = true
+			}
+		}
+		if same != (c.NewTable > 0) {
+			t.Errorf("match %d: NewTable %d, survives %v", i, c.NewTable, same)
+		}
+	}
+}
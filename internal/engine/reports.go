@@ -0,0 +1,186 @@
+package engine
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/models"
+	st "github.com/dstathis/swisstools"
+)
+
+// The outcomes of a player's result report (see ReportOwnResult).
+const (
+	// ReportPending waits for the opponent's report, or for the
+	// tournament's ReportConfirmMinutes to pass.
+	ReportPending = "pending"
+	// ReportConfirmed agreed with the opponent's report, and the result is
+	// in.
+	ReportConfirmed = "confirmed"
+	// ReportConflict differs from the opponent's report; staff resolve it
+	// by entering the result, or a player reports again.
+	ReportConflict = "conflict"
+)
+
+// ReportOwnResult records playerID's report of their current-round match
+// result, from their side, and returns what came of it: with the
+// opponent's report agreeing the result goes in (ReportConfirmed), and
+// otherwise it waits (ReportPending) or is left to staff
+// (ReportConflict). A player reporting again replaces their report. Only a
+// tournament with SelfReport takes reports, and only for a published round's
+// match without a result; the score must fit t's match format (see
+// CheckScore).
+func ReportOwnResult(ctx context.Context, tx db.DBTX, t *models.Tournament, eng *st.Tournament, playerID, wins, losses, draws int, userID *int64) (string, error) {
+	if !t.SelfReport {
+		return "", errors.New("players don't report results in this tournament")
+	}
+	if t.Status != models.TournamentStatusInProgress || eng.GetCurrentRound() == 0 || t.PairingsDraft(eng.GetCurrentRound()) {
+		return "", errors.New("there is no round to report a result for")
+	}
+	if t.SeriesMode || t.TeamSize > 0 {
+		return "", errors.New("series and team results are reported by staff")
+	}
+	p, table, ok := pairingOf(eng, playerID)
+	if !ok {
+		return "", errors.New("you are not paired this round")
+	}
+	if p.PlayerB() == st.BYE_OPPONENT_ID {
+		return "", errors.New("a bye has no result to report")
+	}
+	if p.PlayerAWins() != st.UNINITIALIZED_RESULT {
+		return "", errors.New("your match already has a result")
+	}
+	if err := CheckScore(t, wins, losses, draws); err != nil {
+		return "", err
+	}
+	r := &models.ResultReport{
+		TournamentID: t.ID,
+		Round:        eng.GetCurrentRound(),
+		Table:        table,
+		Side:         models.GameWinnerA,
+		Wins:         wins,
+		Losses:       losses,
+		Draws:        draws,
+		UserID:       userID,
+	}
+	if playerID == p.PlayerB() {
+		r.Side, r.Wins, r.Losses = models.GameWinnerB, losses, wins
+	}
+	if err := db.SetResultReport(ctx, tx, r); err != nil {
+		return "", err
+	}
+	reports, err := db.ListTableReports(ctx, tx, t.ID, r.Round, table)
+	if err != nil {
+		return "", err
+	}
+	return settleReports(ctx, tx, t, eng, p, reports)
+}
+
+// settleReports enters the result of pairing p when both its players'
+// reports agree.
+func settleReports(ctx context.Context, tx db.DBTX, t *models.Tournament, eng *st.Tournament, p st.Pairing, reports []models.ResultReport) (string, error) {
+	if len(reports) < 2 {
+		return ReportPending, nil
+	}
+	if !reports[0].Agrees(reports[1]) {
+		return ReportConflict, nil
+	}
+	r := reports[0]
+	if err := RecordResult(ctx, tx, t, eng, p.PlayerA(), r.Wins, r.Losses, r.Draws); err != nil {
+		return "", err
+	}
+	return ReportConfirmed, nil
+}
+
+// ReportState sums up the reports of a table for staff: ReportPending for
+// one and ReportConflict for two that differ. Two that agree are
+// ReportConfirmed, though their result goes in as the second arrives.
+func ReportState(reports []models.ResultReport) string {
+	switch {
+	case len(reports) < 2:
+		return ReportPending
+	case reports[0].Agrees(reports[1]):
+		return ReportConfirmed
+	}
+	return ReportConflict
+}
+
+// AcceptReport resolves the reports at table in the current round by
+// entering the result side's player reported, GameWinnerA or GameWinnerB.
+func AcceptReport(ctx context.Context, tx db.DBTX, t *models.Tournament, eng *st.Tournament, table int, side string) error {
+	p, ok := pairingAtTable(eng, table)
+	if !ok {
+		return fmt.Errorf("there is no table %d this round", table)
+	}
+	reports, err := db.ListTableReports(ctx, tx, t.ID, eng.GetCurrentRound(), table)
+	if err != nil {
+		return err
+	}
+	for _, r := range reports {
+		if r.Side == side {
+			return RecordResult(ctx, tx, t, eng, p.PlayerA(), r.Wins, r.Losses, r.Draws)
+		}
+	}
+	return fmt.Errorf("table %d has no report from that player", table)
+}
+
+// ReportLocker enters the result of every lone player report that has
+// waited out its tournament's ReportConfirmMinutes, checking every
+// Interval.
+type ReportLocker struct {
+	DB       *sql.DB
+	Interval time.Duration
+}
+
+// Run checks for lapsed reports every Interval until ctx is cancelled.
+func (l *ReportLocker) Run(ctx context.Context) {
+	ticker := time.NewTicker(l.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if err := l.Lock(ctx); err != nil && ctx.Err() == nil {
+			slog.ErrorContext(ctx, "lapsed result reports", "err", err)
+		}
+	}
+}
+
+// Lock enters the results of the lapsed reports. A report whose match has
+// since got a result, or whose round is over, is dropped instead.
+func (l *ReportLocker) Lock(ctx context.Context) error {
+	lapsed, err := db.ListLapsedReports(ctx, l.DB)
+	if err != nil {
+		return err
+	}
+	for _, r := range lapsed {
+		err := WithTournamentEngine(ctx, l.DB, r.TournamentID,
+			func(tx *sql.Tx, t *models.Tournament, eng *st.Tournament) (string, error) {
+				return "", lockReport(ctx, tx, t, eng, r)
+			})
+		if err != nil {
+			slog.ErrorContext(ctx, "lapsed result report", "tournament_id", r.TournamentID, "table", r.Table, "err", err)
+		}
+	}
+	return nil
+}
+
+// lockReport enters the result lone report r gives, after checking again
+// under the tournament lock that it is still alone and its match open.
+func lockReport(ctx context.Context, tx db.DBTX, t *models.Tournament, eng *st.Tournament, r models.ResultReport) error {
+	p, ok := pairingAtTable(eng, r.Table)
+	if r.Round != eng.GetCurrentRound() || !ok || p.PlayerAWins() != st.UNINITIALIZED_RESULT {
+		return db.DeleteResultReports(ctx, tx, t.ID, r.Round, r.Table)
+	}
+	reports, err := db.ListTableReports(ctx, tx, t.ID, r.Round, r.Table)
+	if err != nil || len(reports) != 1 {
+		return err
+	}
+	return RecordResult(ctx, tx, t, eng, p.PlayerA(), reports[0].Wins, reports[0].Losses, reports[0].Draws)
+}
@@ -0,0 +1,100 @@
+//go:build integration
+
+package engine
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/models"
+	st "github.com/dstathis/swisstools"
+)
+
+func TestReportLocker(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	tourn, regs := setupTournamentWithPlayers(t, database, 4)
+	tourn.SelfReport = true
+	tourn.ReportConfirmMinutes = 5
+	if err := db.UpdateTournament(ctx, database, tourn); err != nil {
+		t.Fatal(err)
+	}
+	var a1, b2, a2 int
+	if err := WithTournamentEngine(ctx, database, tourn.ID, func(tx *sql.Tx, tm *models.Tournament, eng *st.Tournament) (string, error) {
+		state, err := InitTournamentEngine(ctx, tx, tm, regs)
+		if err != nil {
+			return "", err
+		}
+		*eng, err = st.LoadTournament(state)
+		a1, a2, b2 = eng.GetRound()[0].PlayerA(), eng.GetRound()[1].PlayerA(), eng.GetRound()[1].PlayerB()
+		return models.TournamentStatusInProgress, err
+	}); err != nil {
+		t.Fatal(err)
+	}
+	report := func(player, wins, losses int) string {
+		t.Helper()
+		var status string
+		if err := WithTournamentEngine(ctx, database, tourn.ID, func(tx *sql.Tx, tm *models.Tournament, eng *st.Tournament) (string, error) {
+			var err error
+			status, err = ReportOwnResult(ctx, tx, tm, eng, player, wins, losses, 0, nil)
+			return "", err
+		}); err != nil {
+			t.Fatalf("report by %d: %v", player, err)
+		}
+		return status
+	}
+
+	// Table 1 has a lone report; table 2 two that differ.
+	if got := report(a1, 2, 0); got != ReportPending {
+		t.Errorf("lone report = %q", got)
+	}
+	report(a2, 2, 1)
+	if got := report(b2, 2, 1); got != ReportConflict {
+		t.Errorf("differing report = %q", got)
+	}
+
+	l := &ReportLocker{DB: database}
+	if err := l.Lock(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if reports, _ := db.ListResultReports(ctx, database, tourn.ID, 1); len(reports[1]) != 1 || len(reports[2]) != 2 {
+		t.Fatalf("reports locked early: %+v", reports)
+	}
+
+	if _, err := database.ExecContext(ctx,
+		`UPDATE result_reports SET reported_at = now() - interval '10 minutes' WHERE tournament_id = $1`, tourn.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Lock(ctx); err != nil {
+		t.Fatal(err)
+	}
+	tm, _ := db.GetTournament(ctx, database, tourn.ID)
+	eng, _ := st.LoadTournament(tm.EngineState)
+	if p := eng.GetRound()[0]; p.PlayerAWins() != 2 || p.PlayerBWins() != 0 {
+		t.Errorf("table 1 after the wait = %d-%d, want 2-0", p.PlayerAWins(), p.PlayerBWins())
+	}
+	if p := eng.GetRound()[1]; p.PlayerAWins() != st.UNINITIALIZED_RESULT {
+		t.Errorf("conflict at table 2 locked: %d-%d", p.PlayerAWins(), p.PlayerBWins())
+	}
+	reports, _ := db.ListResultReports(ctx, database, tourn.ID, 1)
+	if len(reports[1]) != 0 || len(reports[2]) != 2 {
+		t.Errorf("reports after locking = %+v", reports)
+	}
+
+	// Accepting a side settles the conflict.
+	if err := WithTournamentEngine(ctx, database, tourn.ID, func(tx *sql.Tx, tm *models.Tournament, eng *st.Tournament) (string, error) {
+		return "", AcceptReport(ctx, tx, tm, eng, 2, models.GameWinnerB)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	tm, _ = db.GetTournament(ctx, database, tourn.ID)
+	eng, _ = st.LoadTournament(tm.EngineState)
+	if p := eng.GetRound()[1]; p.PlayerAWins() != 1 || p.PlayerBWins() != 2 {
+		t.Errorf("table 2 after accepting B's report = %d-%d, want 1-2", p.PlayerAWins(), p.PlayerBWins())
+	}
+	if reports, _ := db.ListResultReports(ctx, database, tourn.ID, 1); len(reports) != 0 {
+		t.Errorf("reports left = %+v", reports)
+	}
+}
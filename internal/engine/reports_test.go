@@ -0,0 +1,52 @@
+package engine
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+func TestReportOwnResult_Refused(t *testing.T) {
+	eng := fourPlayerRound(t) // table 1 has a result, table 2 doesn't
+	reported, open := eng.GetRound()[0].PlayerA(), eng.GetRound()[1].PlayerB()
+	on := models.Tournament{Status: models.TournamentStatusInProgress, SelfReport: true, BestOf: 3}
+
+	for _, tc := range []struct {
+		name   string
+		t      models.Tournament
+		player int
+		wins   int
+	}{
+		{"reporting off", models.Tournament{Status: models.TournamentStatusInProgress, BestOf: 3}, open, 2},
+		{"finished", models.Tournament{Status: models.TournamentStatusFinished, SelfReport: true}, open, 2},
+		{"draft round", models.Tournament{Status: models.TournamentStatusInProgress, SelfReport: true, PreviewPairings: true, DraftRound: 1}, open, 2},
+		{"series", models.Tournament{Status: models.TournamentStatusInProgress, SelfReport: true, BestOf: 3, SeriesMode: true}, open, 2},
+		{"teams", models.Tournament{Status: models.TournamentStatusInProgress, SelfReport: true, TeamSize: 2}, open, 2},
+		{"not paired", on, 99, 2},
+		{"already reported", on, reported, 2},
+		{"too many games", on, open, 3},
+	} {
+		if _, err := ReportOwnResult(context.Background(), nil, &tc.t, eng, tc.player, tc.wins, 0, 0, nil); err == nil {
+			t.Errorf("%s: report accepted", tc.name)
+		}
+	}
+}
+
+func TestReportState(t *testing.T) {
+	a := models.ResultReport{Side: models.GameWinnerA, Wins: 2, Losses: 1}
+	b := models.ResultReport{Side: models.GameWinnerB, Wins: 2, Losses: 1}
+	differs := models.ResultReport{Side: models.GameWinnerB, Wins: 1, Losses: 2}
+	for _, tc := range []struct {
+		reports []models.ResultReport
+		want    string
+	}{
+		{[]models.ResultReport{a}, ReportPending},
+		{[]models.ResultReport{a, b}, ReportConfirmed},
+		{[]models.ResultReport{a, differs}, ReportConflict},
+	} {
+		if got := ReportState(tc.reports); got != tc.want {
+			t.Errorf("ReportState(%+v) = %q, want %q", tc.reports, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/models"
+	st "github.com/dstathis/swisstools"
+)
+
+// IntentionalDrawGames is the game score of an intentional draw: no games
+// won by either side, three drawn.
+const IntentionalDrawGames = 3
+
+// concessionWins is the game score a concession is recorded with: the games
+// needed to take the tournament's best-of, or 2 when it has none.
+func concessionWins(t *models.Tournament) int {
+	if n := t.GamesToWin(); n > 0 {
+		return n
+	}
+	return 2
+}
+
+// CheckScore refuses a played score t's match format can't end in: negative
+// games, or with BestOf set, more games than it has or a player winning
+// more than GamesToWin. Intentional draws and concessions are recorded
+// with fixed scores and aren't checked.
+func CheckScore(t *models.Tournament, wins, losses, draws int) error {
+	if wins < 0 || losses < 0 || draws < 0 {
+		return errors.New("games can't be negative")
+	}
+	if t.BestOf == 0 {
+		return nil
+	}
+	if wins+losses+draws > t.BestOf {
+		return fmt.Errorf("a best-of-%d match has at most %d games", t.BestOf, t.BestOf)
+	}
+	if need := t.GamesToWin(); wins > need || losses > need {
+		return fmt.Errorf("a best-of-%d match is over once a player wins %d games", t.BestOf, need)
+	}
+	return nil
+}
+
+// pairingOf finds playerID's pairing in the current round and returns it
+// with its table number.
+func pairingOf(eng *st.Tournament, playerID int) (st.Pairing, int, bool) {
+	for i, p := range eng.GetRound() {
+		if p.PlayerA() == playerID || p.PlayerB() == playerID {
+			return p, TableNumber(i, p), true
+		}
+	}
+	return st.Pairing{}, 0, false
+}
+
+// RecordResult enters a played result for playerID's match in the current
+// round, from that player's side, replacing any intentional draw or
+// concession recorded for the table and settling its player reports (see
+// ReportOwnResult). The score must fit t's match format
+// (see CheckScore).
+func RecordResult(ctx context.Context, tx db.DBTX, t *models.Tournament, eng *st.Tournament, playerID, wins, losses, draws int) error {
+	if err := CheckScore(t, wins, losses, draws); err != nil {
+		return err
+	}
+	if err := eng.AddResult(playerID, wins, losses, draws); err != nil {
+		return err
+	}
+	if _, table, ok := pairingOf(eng, playerID); ok && table > 0 {
+		if err := db.DeleteResultReports(ctx, tx, t.ID, eng.GetCurrentRound(), table); err != nil {
+			return err
+		}
+		return db.DeleteMatchResultType(ctx, tx, t.ID, eng.GetCurrentRound(), table)
+	}
+	return nil
+}
+
+// RecordSpecialResult enters an intentional draw (0-0-3) for playerID's match
+// in the current round, or with typ ResultConcession, playerID conceding it:
+// the opponent gets a straight win. The type is stored so reports can tell
+// these apart from played results. Player reports of the match are
+// dropped.
+func RecordSpecialResult(ctx context.Context, tx db.DBTX, t *models.Tournament, eng *st.Tournament, playerID int, typ string, reportedBy *int64) error {
+	p, table, ok := pairingOf(eng, playerID)
+	if !ok {
+		return fmt.Errorf("player %d is not paired this round", playerID)
+	}
+	if p.PlayerB() == st.BYE_OPPONENT_ID {
+		return errors.New("a bye can't be drawn or conceded")
+	}
+	rt := &models.MatchResultType{
+		TournamentID: t.ID,
+		Round:        eng.GetCurrentRound(),
+		Table:        table,
+		Type:         typ,
+		ReportedBy:   reportedBy,
+	}
+	var err error
+	switch typ {
+	case models.ResultIntentionalDraw:
+		err = eng.AddResult(p.PlayerA(), 0, 0, IntentionalDrawGames)
+	case models.ResultConcession:
+		rt.ConcededBy = models.GameWinnerA
+		if playerID == p.PlayerB() {
+			rt.ConcededBy = models.GameWinnerB
+		}
+		err = eng.AddResult(playerID, 0, concessionWins(t), 0)
+	default:
+		return fmt.Errorf("unknown result type %q", typ)
+	}
+	if err != nil {
+		return err
+	}
+	if err := db.DeleteResultReports(ctx, tx, t.ID, rt.Round, table); err != nil {
+		return err
+	}
+	return db.SetMatchResultType(ctx, tx, rt)
+}
+
+// ConcedeMatch is a player conceding their own current-round match. Unlike
+// staff, a player can only concede a match that has no result yet.
+func ConcedeMatch(ctx context.Context, tx db.DBTX, t *models.Tournament, eng *st.Tournament, playerID int, reportedBy *int64) error {
+	if t.Status != models.TournamentStatusInProgress || t.PairingsDraft(eng.GetCurrentRound()) {
+		return errors.New("there is no Swiss round to concede")
+	}
+	p, _, ok := pairingOf(eng, playerID)
+	if !ok {
+		return errors.New("you are not paired this round")
+	}
+	if p.PlayerB() != st.BYE_OPPONENT_ID && p.PlayerAWins() != st.UNINITIALIZED_RESULT {
+		return errors.New("your match already has a result")
+	}
+	return RecordSpecialResult(ctx, tx, t, eng, playerID, models.ResultConcession, reportedBy)
+}
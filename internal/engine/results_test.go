@@ -0,0 +1,67 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+func TestConcessionWins(t *testing.T) {
+	for _, tt := range []struct{ bestOf, want int }{{0, 2}, {1, 1}, {3, 2}, {5, 3}} {
+		if got := concessionWins(&models.Tournament{BestOf: tt.bestOf}); got != tt.want {
+			t.Errorf("Bo%d: concessionWins = %d, want %d", tt.bestOf, got, tt.want)
+		}
+	}
+}
+
+func TestCheckScore(t *testing.T) {
+	for _, tt := range []struct {
+		bestOf, wins, losses, draws int
+		ok                          bool
+	}{
+		{0, 5, 4, 3, true},
+		{0, -1, 0, 0, false},
+		{3, 2, 1, 0, true},
+		{3, 1, 1, 1, true},
+		{3, 1, 0, 0, true},
+		{3, 2, 0, 1, true},
+		{3, 3, 0, 0, false},
+		{3, 2, 2, 0, false},
+		{3, 1, 1, 2, false},
+		{1, 1, 0, 0, true},
+		{1, 0, 0, 1, true},
+		{1, 1, 1, 0, false},
+		{5, 3, 2, 0, true},
+		{5, 3, 1, 1, true},
+		{5, 4, 0, 0, false},
+	} {
+		err := CheckScore(&models.Tournament{BestOf: tt.bestOf}, tt.wins, tt.losses, tt.draws)
+		if (err == nil) != tt.ok {
+			t.Errorf("Bo%d %d-%d-%d: err = %v, want ok %v", tt.bestOf, tt.wins, tt.losses, tt.draws, err, tt.ok)
+		}
+	}
+}
+
+func TestEngineConfig_ByeWins(t *testing.T) {
+	for _, tt := range []struct{ bestOf, want int }{{0, 2}, {1, 1}, {3, 2}, {5, 3}} {
+		if got := engineConfig(&models.Tournament{BestOf: tt.bestOf}).ByeWins; got != tt.want {
+			t.Errorf("Bo%d: bye wins = %d, want %d", tt.bestOf, got, tt.want)
+		}
+	}
+}
+
+func TestPairingOf(t *testing.T) {
+	eng := fourPlayerRound(t)
+	round := eng.GetRound()
+	for i, p := range round {
+		for _, id := range []int{p.PlayerA(), p.PlayerB()} {
+			got, table, ok := pairingOf(eng, id)
+			if !ok || table != i+1 || got.PlayerA() != p.PlayerA() {
+				t.Errorf("player %d: table %d ok %v, want table %d", id, table, ok, i+1)
+			}
+		}
+	}
+	if _, _, ok := pairingOf(eng, 99); ok {
+		t.Error("found a pairing for an unknown player")
+	}
+}
@@ -0,0 +1,143 @@
+package engine
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/ical"
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+// Layouts of the schedule text staff type: an optional date, a time of day
+// and a label per line.
+const (
+	scheduleDate = "2006-01-02"
+	scheduleTime = "15:04"
+)
+
+// SetSchedule replaces tournament id's schedule with items, which are kept
+// in time order. The schedule can change until the tournament is finished.
+func SetSchedule(ctx context.Context, database *sql.DB, id int64, items []models.ScheduleItem) error {
+	for i := range items {
+		items[i].Label = strings.TrimSpace(items[i].Label)
+	}
+	if err := models.ValidateSchedule(items); err != nil {
+		return err
+	}
+	tx, err := database.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+	t, err := db.GetTournamentForUpdate(ctx, tx, id)
+	if err != nil {
+		return fmt.Errorf("get tournament: %w", err)
+	}
+	if t.Status == models.TournamentStatusFinished {
+		return errors.New("the schedule of a finished tournament can't be changed")
+	}
+	if err := db.ReplaceSchedule(ctx, tx, id, items); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ParseSchedule reads a schedule typed as one item per line, "2026-05-01
+// 10:00 Round 1", in time zone loc. The date can be left out to repeat the
+// previous line's, or on the first line to use day, the tournament's date.
+// Blank lines are skipped. Every bad line is reported at once.
+func ParseSchedule(text string, loc *time.Location, day *time.Time) ([]models.ScheduleItem, error) {
+	var date string
+	if day != nil {
+		date = day.In(loc).Format(scheduleDate)
+	}
+	var items []models.ScheduleItem
+	var problems []string
+	for i, line := range strings.Split(text, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if _, err := time.Parse(scheduleDate, fields[0]); err == nil {
+			date, fields = fields[0], fields[1:]
+		}
+		if len(fields) < 2 {
+			problems = append(problems, fmt.Sprintf("line %d: expected a time and a label", i+1))
+			continue
+		}
+		if date == "" {
+			problems = append(problems, fmt.Sprintf("line %d: start with a date (YYYY-MM-DD)", i+1))
+			continue
+		}
+		at, err := time.ParseInLocation(scheduleDate+" "+scheduleTime, date+" "+fields[0], loc)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("line %d: %q is not a time (HH:MM)", i+1, fields[0]))
+			continue
+		}
+		items = append(items, models.ScheduleItem{StartsAt: at, Label: strings.Join(fields[1:], " ")})
+	}
+	if len(problems) > 0 {
+		return nil, errors.New(strings.Join(problems, "; "))
+	}
+	return items, nil
+}
+
+// FormatSchedule writes items in ParseSchedule's form, in time zone loc,
+// giving the date only where it changes.
+func FormatSchedule(items []models.ScheduleItem, loc *time.Location) string {
+	var b strings.Builder
+	var date string
+	for _, s := range items {
+		at := s.StartsAt.In(loc)
+		if d := at.Format(scheduleDate); d != date {
+			date = d
+			b.WriteString(d + " ")
+		}
+		b.WriteString(at.Format(scheduleTime) + " " + s.Label + "\n")
+	}
+	return b.String()
+}
+
+// ScheduleRefresh is how often subscribers are asked to fetch a schedule
+// feed again, so a changed schedule reaches their calendars the same day.
+const ScheduleRefresh = time.Hour
+
+// ScheduleCalendar returns t's schedule as a calendar feed, each item an
+// event named after the tournament and lasting until the next item that
+// day. An event's UID is its place in the schedule, not the item's ID,
+// which changes whenever the schedule is saved: a subscriber's calendar
+// moves the events it has rather than replacing them. baseURL is the
+// server's, for the UIDs and the link back to the tournament page.
+func ScheduleCalendar(t *models.Tournament, items []models.ScheduleItem, baseURL string, now time.Time) *ical.Calendar {
+	host := "openswiss"
+	if u, err := url.Parse(baseURL); err == nil && u.Host != "" {
+		host = u.Host
+	}
+	c := &ical.Calendar{Name: t.Name, Refresh: ScheduleRefresh, Stamp: now}
+	loc := t.Zone()
+	for i, s := range items {
+		e := ical.Event{
+			UID:     fmt.Sprintf("tournament-%d-schedule-%d@%s", t.ID, i+1, host),
+			Start:   s.StartsAt,
+			Summary: t.Name + ": " + s.Label,
+			URL:     fmt.Sprintf("%s/tournaments/%d", baseURL, t.ID),
+		}
+		if i+1 < len(items) {
+			next := items[i+1].StartsAt
+			if next.In(loc).Format(scheduleDate) == s.StartsAt.In(loc).Format(scheduleDate) {
+				e.End = next
+			}
+		}
+		if t.Location != nil {
+			e.Location = *t.Location
+		}
+		c.Events = append(c.Events, e)
+	}
+	return c
+}
@@ -0,0 +1,72 @@
+package engine
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+func TestParseSchedule(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	day := time.Date(2026, 5, 1, 7, 0, 0, 0, time.UTC)
+	text := "09:00 Round 1\n\n12:30  Lunch   break\r\n2026-05-02 10:00 Top 8\n"
+	got, err := ParseSchedule(text, berlin, &day)
+	if err != nil {
+		t.Fatalf("ParseSchedule: %v", err)
+	}
+	want := []struct {
+		at    time.Time
+		label string
+	}{
+		{time.Date(2026, 5, 1, 7, 0, 0, 0, time.UTC), "Round 1"},
+		{time.Date(2026, 5, 1, 10, 30, 0, 0, time.UTC), "Lunch break"},
+		{time.Date(2026, 5, 2, 8, 0, 0, 0, time.UTC), "Top 8"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d items, want %d", len(got), len(want))
+	}
+	for i, w := range want {
+		if !got[i].StartsAt.Equal(w.at) || got[i].Label != w.label {
+			t.Errorf("item %d = %v %q, want %v %q", i, got[i].StartsAt, got[i].Label, w.at, w.label)
+		}
+	}
+	if back := FormatSchedule(got, berlin); back != "2026-05-01 09:00 Round 1\n12:30 Lunch break\n2026-05-02 10:00 Top 8\n" {
+		t.Errorf("FormatSchedule = %q", back)
+	}
+
+	_, err = ParseSchedule("9am Round 1\n10:00\n", berlin, &day)
+	if err == nil || !strings.Contains(err.Error(), `line 1: "9am"`) || !strings.Contains(err.Error(), "line 2: expected a time and a label") {
+		t.Errorf("err = %v, want both bad lines reported", err)
+	}
+	if _, err := ParseSchedule("10:00 Round 1", time.UTC, nil); err == nil {
+		t.Error("a first line without a date parsed without a tournament date")
+	}
+}
+
+func TestScheduleCalendar(t *testing.T) {
+	venue := "Town Hall"
+	tourn := &models.Tournament{ID: 7, Name: "Spring Open", TimeZone: "Europe/Berlin", Location: &venue}
+	items := []models.ScheduleItem{
+		{ID: 40, StartsAt: time.Date(2026, 5, 1, 7, 0, 0, 0, time.UTC), Label: "Round 1"},
+		{ID: 41, StartsAt: time.Date(2026, 5, 1, 8, 30, 0, 0, time.UTC), Label: "Round 2"},
+		{ID: 42, StartsAt: time.Date(2026, 5, 2, 8, 0, 0, 0, time.UTC), Label: "Top 8"},
+	}
+	c := ScheduleCalendar(tourn, items, "https://swiss.example.com", time.Now())
+	if c.Name != "Spring Open" || len(c.Events) != 3 {
+		t.Fatalf("calendar = %+v", c)
+	}
+	first := c.Events[0]
+	if first.UID != "tournament-7-schedule-1@swiss.example.com" || first.Summary != "Spring Open: Round 1" ||
+		!first.End.Equal(items[1].StartsAt) || first.Location != "Town Hall" || first.URL != "https://swiss.example.com/tournaments/7" {
+		t.Errorf("first event = %+v", first)
+	}
+	// Round 2 is the day's last item: it has no end.
+	if !c.Events[1].End.IsZero() || !c.Events[2].End.IsZero() {
+		t.Errorf("ends = %v, %v; want none", c.Events[1].End, c.Events[2].End)
+	}
+}
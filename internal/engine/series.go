@@ -0,0 +1,251 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/models"
+	st "github.com/dstathis/swisstools"
+)
+
+// SeriesScore tallies the games of a series from player A's side.
+func SeriesScore(games []models.MatchGame) (aWins, bWins, draws int) {
+	for _, g := range games {
+		switch g.Winner {
+		case models.GameWinnerA:
+			aWins++
+		case models.GameWinnerB:
+			bWins++
+		case models.GameDraw:
+			draws++
+		}
+	}
+	return aWins, bWins, draws
+}
+
+// SeriesOver reports whether a best-of-bestOf series with the given score is
+// finished: one side has won a majority of the games, or all of them have
+// been played.
+func SeriesOver(bestOf, aWins, bWins, draws int) bool {
+	need := bestOf/2 + 1
+	return aWins >= need || bWins >= need || aWins+bWins+draws >= bestOf
+}
+
+// pairingAtTable returns the current round's pairing seated at table.
+func pairingAtTable(eng *st.Tournament, table int) (st.Pairing, bool) {
+	for i, p := range eng.GetRound() {
+		if table > 0 && TableNumber(i, p) == table {
+			return p, true
+		}
+	}
+	return st.Pairing{}, false
+}
+
+// applySeries writes the series' aggregate score as the match result once it
+// is decided. While it is still running the result is left unset, so the
+// round can't advance past an unfinished series.
+func applySeries(eng *st.Tournament, playerA, bestOf int, games []models.MatchGame) error {
+	a, b, d := SeriesScore(games)
+	if !SeriesOver(bestOf, a, b, d) {
+		u := st.UNINITIALIZED_RESULT
+		return eng.AddResult(playerA, u, u, u)
+	}
+	return eng.AddResult(playerA, a, b, d)
+}
+
+// cleanGame checks g's winner and who went first, and trims its details,
+// checking their length.
+func cleanGame(g *models.MatchGame) error {
+	switch g.Winner {
+	case models.GameWinnerA, models.GameWinnerB, models.GameDraw:
+	default:
+		return errors.New("winner must be a, b or draw")
+	}
+	switch g.WentFirst {
+	case "", models.GameWinnerA, models.GameWinnerB:
+	default:
+		return errors.New("went_first must be a, b or empty")
+	}
+	g.Map = strings.TrimSpace(g.Map)
+	g.PlayerAPick = strings.TrimSpace(g.PlayerAPick)
+	g.PlayerBPick = strings.TrimSpace(g.PlayerBPick)
+	g.Notes = strings.TrimSpace(g.Notes)
+	for _, s := range []string{g.Map, g.PlayerAPick, g.PlayerBPick} {
+		if utf8.RuneCountInString(s) > models.MaxGameDetail {
+			return fmt.Errorf("game details may be at most %d characters", models.MaxGameDetail)
+		}
+	}
+	if utf8.RuneCountInString(g.Notes) > models.MaxGameNotes {
+		return fmt.Errorf("game notes may be at most %d characters", models.MaxGameNotes)
+	}
+	return nil
+}
+
+// ReportGame records the next game of the series at g.Table in the current
+// round and updates the match result, replacing any intentional draw or
+// concession recorded for the table. g.Winner, the metadata and ReportedBy
+// come from the caller; the rest is filled in. Must run inside
+// WithTournamentEngine so the game and the result commit together.
+func ReportGame(ctx context.Context, tx db.DBTX, t *models.Tournament, eng *st.Tournament, g *models.MatchGame) error {
+	if !t.SeriesMode {
+		return errors.New("tournament is not in series mode")
+	}
+	if err := cleanGame(g); err != nil {
+		return err
+	}
+	p, ok := pairingAtTable(eng, g.Table)
+	if !ok {
+		return fmt.Errorf("no match at table %d", g.Table)
+	}
+
+	round := eng.GetCurrentRound()
+	games, err := db.ListTableGames(ctx, tx, t.ID, round, g.Table)
+	if err != nil {
+		return err
+	}
+	if a, b, d := SeriesScore(games); SeriesOver(t.BestOf, a, b, d) {
+		return fmt.Errorf("the series at table %d is already decided", g.Table)
+	}
+	g.TournamentID = t.ID
+	g.Round = round
+	g.GameNumber = len(games) + 1
+	if err := db.AddMatchGame(ctx, tx, g); err != nil {
+		return err
+	}
+	if err := db.DeleteMatchResultType(ctx, tx, t.ID, round, g.Table); err != nil {
+		return err
+	}
+	return applySeries(eng, p.PlayerA(), t.BestOf, append(games, *g))
+}
+
+// UndoGame removes the last reported game of the series at table in the
+// current round and updates the match result to match.
+func UndoGame(ctx context.Context, tx db.DBTX, t *models.Tournament, eng *st.Tournament, table int) error {
+	if !t.SeriesMode {
+		return errors.New("tournament is not in series mode")
+	}
+	p, ok := pairingAtTable(eng, table)
+	if !ok {
+		return fmt.Errorf("no match at table %d", table)
+	}
+	round := eng.GetCurrentRound()
+	if err := db.DeleteLastMatchGame(ctx, tx, t.ID, round, table); err != nil {
+		if errors.Is(err, db.ErrMatchGameNotFound) {
+			return fmt.Errorf("no games reported at table %d", table)
+		}
+		return err
+	}
+	games, err := db.ListTableGames(ctx, tx, t.ID, round, table)
+	if err != nil {
+		return err
+	}
+	if err := db.DeleteMatchResultType(ctx, tx, t.ID, round, table); err != nil {
+		return err
+	}
+	return applySeries(eng, p.PlayerA(), t.BestOf, games)
+}
+
+// EditGame rewrites game g.GameNumber of the series at g.Table in the
+// current round, its winner and details, and updates the match result to
+// match, replacing any intentional draw or concession recorded for the
+// table. A new winner can't decide the series before its last game; undo
+// the games after it instead. Must run inside WithTournamentEngine.
+func EditGame(ctx context.Context, tx db.DBTX, t *models.Tournament, eng *st.Tournament, g *models.MatchGame) error {
+	if !t.SeriesMode {
+		return errors.New("tournament is not in series mode")
+	}
+	if err := cleanGame(g); err != nil {
+		return err
+	}
+	p, ok := pairingAtTable(eng, g.Table)
+	if !ok {
+		return fmt.Errorf("no match at table %d", g.Table)
+	}
+	round := eng.GetCurrentRound()
+	games, err := db.ListTableGames(ctx, tx, t.ID, round, g.Table)
+	if err != nil {
+		return err
+	}
+	if g.GameNumber < 1 || g.GameNumber > len(games) {
+		return fmt.Errorf("no game %d reported at table %d", g.GameNumber, g.Table)
+	}
+	g.TournamentID = t.ID
+	g.Round = round
+	games[g.GameNumber-1] = *g
+	for n := 1; n < len(games); n++ {
+		if a, b, d := SeriesScore(games[:n]); SeriesOver(t.BestOf, a, b, d) {
+			return fmt.Errorf("that would decide the series at game %d, before game %d", n, len(games))
+		}
+	}
+	if err := db.UpdateMatchGame(ctx, tx, g); err != nil {
+		return err
+	}
+	if err := db.DeleteMatchResultType(ctx, tx, t.ID, round, g.Table); err != nil {
+		return err
+	}
+	return applySeries(eng, p.PlayerA(), t.BestOf, games)
+}
+
+// GameStats sums up a tournament's series games for stats: how many were
+// played and drawn, how the player who went first fared in the games that
+// note it, and how often each map was played.
+type GameStats struct {
+	Games int `json:"games"`
+	Draws int `json:"draws"`
+	// Noted counts the games that note who went first, and FirstWins those
+	// of them won by that player.
+	Noted     int        `json:"went_first_noted"`
+	FirstWins int        `json:"went_first_wins"`
+	Maps      []MapGames `json:"maps"`
+}
+
+// MapGames is how many games were played on a map.
+type MapGames struct {
+	Map   string `json:"map"`
+	Games int    `json:"games"`
+}
+
+// FirstWinRate is the percentage of the games noting who went first that
+// the player going first won, rounded; 0 when none note it.
+func (s GameStats) FirstWinRate() int {
+	if s.Noted == 0 {
+		return 0
+	}
+	return (200*s.FirstWins + s.Noted) / (2 * s.Noted)
+}
+
+// SummarizeGames tallies games into GameStats. Maps are listed most played
+// first, then by name; games without a map aren't listed.
+func SummarizeGames(games []models.MatchGame) GameStats {
+	s := GameStats{Games: len(games), Maps: []MapGames{}}
+	maps := map[string]int{}
+	for _, g := range games {
+		if g.Winner == models.GameDraw {
+			s.Draws++
+		}
+		if g.WentFirst != "" {
+			s.Noted++
+			if g.Winner == g.WentFirst {
+				s.FirstWins++
+			}
+		}
+		if g.Map != "" {
+			maps[g.Map]++
+		}
+	}
+	for m, n := range maps {
+		s.Maps = append(s.Maps, MapGames{Map: m, Games: n})
+	}
+	sort.Slice(s.Maps, func(i, j int) bool {
+		if s.Maps[i].Games != s.Maps[j].Games {
+			return s.Maps[i].Games > s.Maps[j].Games
+		}
+		return s.Maps[i].Map < s.Maps[j].Map
+	})
+	return s
+}
@@ -0,0 +1,123 @@
+package engine
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/models"
+	st "github.com/dstathis/swisstools"
+)
+
+func games(winners ...string) []models.MatchGame {
+	out := make([]models.MatchGame, len(winners))
+	for i, w := range winners {
+		out[i] = models.MatchGame{GameNumber: i + 1, Winner: w}
+	}
+	return out
+}
+
+func TestSeriesOver(t *testing.T) {
+	const a, b, d = models.GameWinnerA, models.GameWinnerB, models.GameDraw
+	tests := []struct {
+		bestOf int
+		games  []models.MatchGame
+		want   bool
+	}{
+		{1, nil, false},
+		{1, games(a), true},
+		{1, games(d), true},
+		{3, games(a), false},
+		{3, games(a, a), true},
+		{3, games(a, b), false},
+		{3, games(a, b, b), true},
+		{3, games(d, d), false},
+		{3, games(d, d, d), true},
+		{5, games(a, a, b, b), false},
+		{5, games(a, a, b, a), true},
+		{2, games(a, b), true},
+	}
+	for _, tt := range tests {
+		aw, bw, dr := SeriesScore(tt.games)
+		if got := SeriesOver(tt.bestOf, aw, bw, dr); got != tt.want {
+			t.Errorf("Bo%d %v: SeriesOver = %v, want %v", tt.bestOf, tt.games, got, tt.want)
+		}
+	}
+}
+
+func TestApplySeries(t *testing.T) {
+	eng := st.NewTournament()
+	for _, name := range []string{"A", "B"} {
+		if err := eng.AddPlayer(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := eng.StartTournament(); err != nil {
+		t.Fatal(err)
+	}
+	p, ok := pairingAtTable(&eng, 1)
+	if !ok {
+		t.Fatal("no pairing at table 1")
+	}
+	if _, ok := pairingAtTable(&eng, 2); ok {
+		t.Error("found a pairing at table 2 of a one-table round")
+	}
+
+	// Running series: result stays unset.
+	if err := applySeries(&eng, p.PlayerA(), 3, games(models.GameWinnerA)); err != nil {
+		t.Fatal(err)
+	}
+	if got := eng.GetRound()[0]; got.PlayerAWins() != st.UNINITIALIZED_RESULT {
+		t.Errorf("running series wrote a result: %d-%d-%d", got.PlayerAWins(), got.PlayerBWins(), got.Draws())
+	}
+
+	// Decided: aggregate written from A's side.
+	if err := applySeries(&eng, p.PlayerA(), 3, games(models.GameWinnerB, models.GameWinnerA, models.GameWinnerA)); err != nil {
+		t.Fatal(err)
+	}
+	got := eng.GetRound()[0]
+	if got.PlayerAWins() != 2 || got.PlayerBWins() != 1 || got.Draws() != 0 {
+		t.Errorf("result = %d-%d-%d, want 2-1-0", got.PlayerAWins(), got.PlayerBWins(), got.Draws())
+	}
+}
+
+func TestCleanGame(t *testing.T) {
+	g := &models.MatchGame{Winner: models.GameWinnerA, WentFirst: models.GameWinnerB, Map: " Dust ", Notes: " slow play warning "}
+	if err := cleanGame(g); err != nil || g.Map != "Dust" || g.Notes != "slow play warning" {
+		t.Errorf("cleanGame = %v, %+v", err, g)
+	}
+	for _, bad := range []models.MatchGame{
+		{Winner: "c"},
+		{Winner: models.GameWinnerA, WentFirst: models.GameDraw},
+		{Winner: models.GameWinnerA, Notes: strings.Repeat("x", models.MaxGameNotes+1)},
+	} {
+		if err := cleanGame(&bad); err == nil {
+			t.Errorf("%+v accepted", bad)
+		}
+	}
+}
+
+func TestSummarizeGames(t *testing.T) {
+	const a, b, d = models.GameWinnerA, models.GameWinnerB, models.GameDraw
+	gs := []models.MatchGame{
+		{Winner: a, WentFirst: a, Map: "Nuke"},
+		{Winner: b, WentFirst: a, Map: "Dust"},
+		{Winner: b, WentFirst: b, Map: "Nuke"},
+		{Winner: d, Map: "Mirage"},
+	}
+	s := SummarizeGames(gs)
+	if s.Games != 4 || s.Draws != 1 || s.Noted != 3 || s.FirstWins != 2 || s.FirstWinRate() != 67 {
+		t.Errorf("stats = %+v, rate %d", s, s.FirstWinRate())
+	}
+	want := []MapGames{{"Nuke", 2}, {"Dust", 1}, {"Mirage", 1}}
+	if len(s.Maps) != len(want) {
+		t.Fatalf("maps = %+v", s.Maps)
+	}
+	for i := range want {
+		if s.Maps[i] != want[i] {
+			t.Errorf("map %d = %+v, want %+v", i, s.Maps[i], want[i])
+		}
+	}
+	if empty := SummarizeGames(nil); empty.FirstWinRate() != 0 || empty.Maps == nil {
+		t.Errorf("no games = %+v", empty)
+	}
+}
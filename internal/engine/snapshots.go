@@ -0,0 +1,140 @@
+package engine
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/dstathis/openswiss/internal/readonly"
+	st "github.com/dstathis/swisstools"
+)
+
+// stage identifies how far a tournament has got, so WithTournamentEngine
+// can tell when a change paired a new round or finished it.
+type stage struct {
+	round, playoffRound int
+	status              string
+}
+
+func stageOf(eng *st.Tournament, status string) stage {
+	s := stage{round: eng.GetCurrentRound(), playoffRound: -1, status: status}
+	if po := eng.GetPlayoff(); po != nil {
+		s.playoffRound = po.CurrentRound
+	}
+	return s
+}
+
+// stateRound returns the current Swiss round of an engine state, or 0 for
+// a tournament that hasn't started.
+func stateRound(state []byte) int {
+	if len(state) == 0 {
+		return 0
+	}
+	eng, err := st.LoadTournament(state)
+	if err != nil {
+		return 0
+	}
+	return eng.GetCurrentRound()
+}
+
+// Snapshotter takes a snapshot of every running tournament that changed
+// since its last one, and prunes old snapshots, every Interval.
+type Snapshotter struct {
+	DB       *sql.DB
+	Interval time.Duration
+	// Keep is how many snapshots are kept per tournament.
+	Keep int
+}
+
+// Run snapshots and prunes every Interval until ctx is cancelled.
+func (s *Snapshotter) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if err := s.Snapshot(ctx); err != nil && ctx.Err() == nil {
+			slog.ErrorContext(ctx, "tournament snapshots", "err", err)
+		}
+	}
+}
+
+// Snapshot takes one round of periodic snapshots, then prunes.
+func (s *Snapshotter) Snapshot(ctx context.Context) error {
+	ids, err := db.ListTournamentsToSnapshot(ctx, s.DB)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if err := takeSnapshot(ctx, s.DB, id); err != nil {
+			slog.ErrorContext(ctx, "tournament snapshot", "tournament_id", id, "err", err)
+		}
+	}
+	if s.Keep > 0 {
+		if _, err := db.PruneSnapshots(ctx, s.DB, s.Keep); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// takeSnapshot saves a periodic snapshot of tournament id, holding its row
+// lock so no change lands halfway through.
+func takeSnapshot(ctx context.Context, database *sql.DB, id int64) error {
+	tx, err := database.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	t, err := db.GetTournamentForUpdate(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+	if _, err := db.SnapshotTournament(ctx, tx, id, models.SnapshotPeriodic, stateRound(t.EngineState)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// RollBack returns tournament tournamentID to snapshot snapshotID. The
+// state it leaves is saved as a rollback snapshot first, so a rollback can
+// itself be undone. It returns sql.ErrNoRows if the tournament or snapshot
+// doesn't exist, and ErrInvalidBackup if the snapshot no longer passes
+// CheckBackup.
+func RollBack(ctx context.Context, database *sql.DB, tournamentID, snapshotID int64) (err error) {
+	b, err := db.GetSnapshotBackup(ctx, database, tournamentID, snapshotID)
+	if err != nil {
+		return err
+	}
+	if err := CheckBackup(b); err != nil {
+		return err
+	}
+	defer func() { readonly.Report(ctx, err) }()
+
+	tx, err := database.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+	t, err := db.GetTournamentForUpdate(ctx, tx, tournamentID)
+	if err != nil {
+		return err
+	}
+	if _, err := db.SnapshotTournament(ctx, tx, tournamentID, models.SnapshotRollback, stateRound(t.EngineState)); err != nil {
+		return fmt.Errorf("snapshot current state: %w", err)
+	}
+	if _, err := db.RestoreTournamentBackup(ctx, tx, b, 0, tournamentID); err != nil {
+		return err
+	}
+	if err := rearchive(ctx, tx, tournamentID); err != nil {
+		return fmt.Errorf("archive: %w", err)
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,92 @@
+//go:build integration
+
+package engine
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/models"
+	st "github.com/dstathis/swisstools"
+)
+
+func TestSnapshotsAndRollBack(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	tourn, regs := setupTournamentWithPlayers(t, database, 4)
+
+	start := func(tx *sql.Tx, tm *models.Tournament, eng *st.Tournament) (string, error) {
+		state, err := InitTournamentEngine(ctx, tx, tm, regs)
+		if err != nil {
+			return "", err
+		}
+		*eng, err = st.LoadTournament(state)
+		return models.TournamentStatusInProgress, err
+	}
+	advance := func(tx *sql.Tx, tm *models.Tournament, eng *st.Tournament) (string, error) {
+		for _, p := range eng.GetRound() {
+			if p.PlayerB() != st.BYE_OPPONENT_ID {
+				if err := eng.AddResult(p.PlayerA(), 2, 0, 0); err != nil {
+					return "", err
+				}
+			}
+		}
+		return AdvanceRound(eng, tm, false, nil, nil, nil)
+	}
+	noop := func(tx *sql.Tx, tm *models.Tournament, eng *st.Tournament) (string, error) { return "", nil }
+	for _, fn := range []func(*sql.Tx, *models.Tournament, *st.Tournament) (string, error){start, noop, advance} {
+		if err := WithTournamentEngine(ctx, database, tourn.ID, fn); err != nil {
+			t.Fatalf("WithTournamentEngine: %v", err)
+		}
+	}
+
+	snaps, _ := db.ListSnapshots(ctx, database, tourn.ID)
+	if len(snaps) != 2 || snaps[0].Round != 2 || snaps[1].Round != 1 || snaps[0].Reason != models.SnapshotRound {
+		t.Fatalf("snapshots after start and one advance = %+v", snaps)
+	}
+
+	// Nothing changed since the round 2 snapshot.
+	s := &Snapshotter{DB: database, Keep: 3}
+	if err := s.Snapshot(ctx); err != nil {
+		t.Fatalf("Snapshot: %v", err)
+	}
+	if snaps, _ = db.ListSnapshots(ctx, database, tourn.ID); len(snaps) != 2 {
+		t.Errorf("unchanged tournament snapshotted again: %+v", snaps)
+	}
+	if err := WithTournamentEngine(ctx, database, tourn.ID, noop); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Snapshot(ctx); err != nil {
+		t.Fatalf("Snapshot: %v", err)
+	}
+	if snaps, _ = db.ListSnapshots(ctx, database, tourn.ID); len(snaps) != 3 || snaps[0].Reason != models.SnapshotPeriodic {
+		t.Errorf("changed tournament not snapshotted: %+v", snaps)
+	}
+
+	// Back to round 1. The round 2 state is kept as a rollback snapshot, and
+	// pruning leaves the newest three, so round 1's goes.
+	round1 := snaps[2].ID
+	if err := RollBack(ctx, database, tourn.ID, round1); err != nil {
+		t.Fatalf("RollBack: %v", err)
+	}
+	got, _ := db.GetTournament(ctx, database, tourn.ID)
+	if r := stateRound(got.EngineState); r != 1 {
+		t.Errorf("round after roll back = %d, want 1", r)
+	}
+	if restored, _ := db.ListRegistrations(ctx, database, tourn.ID); len(restored) != 4 || restored[0].UserID == nil {
+		t.Errorf("registrations after roll back = %+v", restored)
+	}
+	if err := s.Snapshot(ctx); err != nil {
+		t.Fatalf("Snapshot: %v", err)
+	}
+	snaps, _ = db.ListSnapshots(ctx, database, tourn.ID)
+	if len(snaps) != 3 || snaps[0].Reason != models.SnapshotRollback || snaps[0].Round != 2 || snaps[1].Reason != models.SnapshotPeriodic {
+		t.Errorf("snapshots after roll back = %+v", snaps)
+	}
+
+	if err := RollBack(ctx, database, tourn.ID, round1); err == nil {
+		t.Error("rolled back to a pruned snapshot")
+	}
+}
@@ -0,0 +1,190 @@
+package engine
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/models"
+	st "github.com/dstathis/swisstools"
+)
+
+// Standings returns the engine's standings in a stable order: by points,
+// then by the tiebreakers in order (see models.Tournament.TiebreakOrder;
+// nil is the default order). swisstools leaves fully tied players in
+// arbitrary order; here the per-player tiebreak seed (see TiebreakSeeds)
+// decides instead, lowest seed first, so the order and the ranks are the
+// same on every call. Players without a seed follow the seeded ones in a
+// tie, by engine player ID. Ranks are the final positions, 1-based.
+func Standings(eng *st.Tournament, order []string, seeds map[int]int64) []st.PlayerStanding {
+	if len(order) == 0 {
+		order = models.DefaultTiebreakers
+	}
+	standings := eng.GetStandings()
+	sort.SliceStable(standings, func(i, j int) bool {
+		a, b := standings[i], standings[j]
+		if a.Points != b.Points {
+			return a.Points > b.Points
+		}
+		for _, name := range order {
+			if va, vb := TiebreakValue(name, a.Tiebreakers), TiebreakValue(name, b.Tiebreakers); va != vb {
+				return va > vb
+			}
+		}
+		sa, okA := seeds[a.PlayerID]
+		sb, okB := seeds[b.PlayerID]
+		if okA != okB {
+			return okA
+		}
+		if sa != sb {
+			return sa < sb
+		}
+		return a.PlayerID < b.PlayerID
+	})
+	for i := range standings {
+		standings[i].Rank = i + 1
+	}
+	return standings
+}
+
+// TiebreakValue returns the tiebreaker called name (models.TiebreakOMW,
+// TiebreakGW or TiebreakOGW) from tb, 0 for an unknown name.
+func TiebreakValue(name string, tb st.TiebreakerData) float64 {
+	switch name {
+	case models.TiebreakOMW:
+		return tb.OpponentMatchWinPct
+	case models.TiebreakGW:
+		return tb.GameWinPercentage
+	case models.TiebreakOGW:
+		return tb.OpponentGameWinPct
+	}
+	return 0
+}
+
+// maxCachedStandings bounds the standings cache, which holds one entry per
+// tournament.
+const maxCachedStandings = 512
+
+// standingsCache keeps each tournament's latest standings with a hash of
+// the engine state and seeds they were computed from, dropping the oldest
+// tournament once it holds max of them.
+type standingsCache struct {
+	mu      sync.Mutex
+	max     int
+	entries map[int64]cachedStandings
+	order   []int64
+}
+
+type cachedStandings struct {
+	version   [sha256.Size]byte
+	standings []st.PlayerStanding
+}
+
+var standingsByTournament = newStandingsCache(maxCachedStandings)
+
+func newStandingsCache(max int) *standingsCache {
+	return &standingsCache{max: max, entries: map[int64]cachedStandings{}}
+}
+
+func (c *standingsCache) get(id int64, version [sha256.Size]byte) ([]st.PlayerStanding, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[id]
+	if !ok || e.version != version {
+		return nil, false
+	}
+	return slices.Clone(e.standings), true
+}
+
+func (c *standingsCache) put(id int64, version [sha256.Size]byte, standings []st.PlayerStanding) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.entries[id]; !ok {
+		if len(c.order) >= c.max {
+			delete(c.entries, c.order[0])
+			c.order = c.order[1:]
+		}
+		c.order = append(c.order, id)
+	}
+	c.entries[id] = cachedStandings{version: version, standings: slices.Clone(standings)}
+}
+
+func (c *standingsCache) forget(id int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.entries[id]; ok {
+		delete(c.entries, id)
+		c.order = slices.DeleteFunc(c.order, func(o int64) bool { return o == id })
+	}
+}
+
+// standingsVersion hashes what standings depend on: the engine state, the
+// tiebreak order and the tiebreak seeds.
+func standingsVersion(state []byte, order []string, seeds map[int]int64) [sha256.Size]byte {
+	h := sha256.New()
+	h.Write(state)
+	for _, name := range order {
+		h.Write([]byte(name + ","))
+	}
+	h.Write([]byte{0})
+	ids := make([]int, 0, len(seeds))
+	for id := range seeds {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	var buf [16]byte
+	for _, id := range ids {
+		binary.BigEndian.PutUint64(buf[:8], uint64(id))
+		binary.BigEndian.PutUint64(buf[8:], uint64(seeds[id]))
+		h.Write(buf[:])
+	}
+	var v [sha256.Size]byte
+	h.Sum(v[:0])
+	return v
+}
+
+// CachedStandings is Standings for t, in t's tiebreak order, whose engine
+// state eng was loaded from. The result is reused until t's engine state,
+// tiebreak order or the seeds change, so a burst of page views after a
+// round goes up computes it once. A change through WithTournamentEngine
+// also drops it.
+func CachedStandings(t *models.Tournament, eng *st.Tournament, seeds map[int]int64) []st.PlayerStanding {
+	order := t.TiebreakOrder()
+	if len(t.EngineState) == 0 {
+		return Standings(eng, order, seeds)
+	}
+	version := standingsVersion(t.EngineState, order, seeds)
+	if standings, ok := standingsByTournament.get(t.ID, version); ok {
+		return standings
+	}
+	standings := Standings(eng, order, seeds)
+	standingsByTournament.put(t.ID, version, standings)
+	return standings
+}
+
+// TiebreakSeeds maps engine player ID to tiebreak seed for the registrations
+// that have both.
+func TiebreakSeeds(regs []models.Registration) map[int]int64 {
+	seeds := make(map[int]int64)
+	for _, r := range regs {
+		if r.EnginePlayerID != nil && r.TiebreakSeed != nil {
+			seeds[*r.EnginePlayerID] = *r.TiebreakSeed
+		}
+	}
+	return seeds
+}
+
+// Seeds loads the tiebreak seeds of t's players, keyed by engine player ID
+// (see TiebreakSeeds).
+func Seeds(ctx context.Context, tx db.DBTX, t *models.Tournament) (map[int]int64, error) {
+	regs, err := db.ListRegistrations(ctx, tx, t.ID)
+	if err != nil {
+		return nil, fmt.Errorf("list registrations: %w", err)
+	}
+	return TiebreakSeeds(regs), nil
+}
@@ -0,0 +1,176 @@
+package engine
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/models"
+	st "github.com/dstathis/swisstools"
+)
+
+func TestStandings_StableAcrossCalls(t *testing.T) {
+	// Nobody has played yet, so everyone is fully tied.
+	eng := st.NewTournament()
+	seeds := map[int]int64{}
+	for i := 0; i < 8; i++ {
+		if err := eng.AddPlayer(fmt.Sprintf("P%d", i)); err != nil {
+			t.Fatal(err)
+		}
+		id, _ := eng.GetPlayerID(fmt.Sprintf("P%d", i))
+		seeds[id] = int64((i * 37) % 8)
+	}
+
+	first := Standings(&eng, nil, seeds)
+	for n := 0; n < 20; n++ {
+		again := Standings(&eng, nil, seeds)
+		for i := range first {
+			if again[i].PlayerID != first[i].PlayerID {
+				t.Fatalf("call %d: position %d is player %d, first call had %d", n, i, again[i].PlayerID, first[i].PlayerID)
+			}
+		}
+	}
+	for i, s := range first {
+		if s.Rank != i+1 {
+			t.Errorf("position %d has rank %d", i, s.Rank)
+		}
+		if i > 0 && seeds[first[i-1].PlayerID] > seeds[s.PlayerID] {
+			t.Errorf("seed order broken at position %d", i)
+		}
+	}
+}
+
+func TestStandings_SeedOnlyBreaksFullTies(t *testing.T) {
+	eng := playedEngine(t, 4)
+	// Give the round-1 losers the lowest seeds: they must still sort after
+	// the winners.
+	seeds := map[int]int64{}
+	round, _ := eng.GetRoundByNumber(1)
+	for _, p := range round {
+		seeds[p.PlayerA()] = 100
+		seeds[p.PlayerB()] = 1
+	}
+	standings := Standings(eng, nil, seeds)
+	if standings[0].Points != 3 || standings[1].Points != 3 || standings[2].Points != 0 {
+		t.Errorf("points order broken: %+v", standings)
+	}
+}
+
+func TestStandings_TiebreakOrder(t *testing.T) {
+	eng := st.NewTournament()
+	for i := 0; i < 4; i++ {
+		if err := eng.AddPlayer(fmt.Sprintf("P%d", i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := eng.StartTournament(); err != nil {
+		t.Fatal(err)
+	}
+	// Both winners finish on 3 points with the same OMW%: one won 2-0, so
+	// has the better GW%, the other 2-1, against an opponent with the
+	// better OGW%.
+	round := eng.GetRound()
+	clean, narrow := round[0].PlayerA(), round[1].PlayerA()
+	if err := eng.AddResult(clean, 2, 0, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := eng.AddResult(narrow, 2, 1, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := eng.NextRound(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		order []string
+		first int
+	}{
+		{nil, clean},
+		{[]string{models.TiebreakGW, models.TiebreakOGW}, clean},
+		{[]string{models.TiebreakOGW, models.TiebreakGW}, narrow},
+		{[]string{models.TiebreakOMW, models.TiebreakOGW}, narrow},
+	} {
+		if got := Standings(&eng, tc.order, nil)[0].PlayerID; got != tc.first {
+			t.Errorf("order %v: leader is %d, want %d", tc.order, got, tc.first)
+		}
+	}
+}
+
+func TestTiebreakSeeds(t *testing.T) {
+	pid, seed := 3, int64(42)
+	regs := []models.Registration{
+		{EnginePlayerID: &pid, TiebreakSeed: &seed},
+		{EnginePlayerID: nil, TiebreakSeed: &seed},
+		{EnginePlayerID: &pid},
+	}
+	got := TiebreakSeeds(regs)
+	if len(got) != 1 || got[3] != 42 {
+		t.Errorf("TiebreakSeeds = %v, want map[3:42]", got)
+	}
+}
+
+func TestCachedStandings(t *testing.T) {
+	fresh := st.NewTournament()
+	for i := 0; i < 4; i++ {
+		if err := fresh.AddPlayer(fmt.Sprintf("P%d", i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	played := playedEngine(t, 4)
+	state, err := played.DumpTournament()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tour := &models.Tournament{ID: -71, EngineState: state}
+	seeds := map[int]int64{}
+	defer standingsByTournament.forget(tour.ID)
+
+	first := CachedStandings(tour, played, seeds)
+	if first[0].Points != 3 {
+		t.Fatalf("leader has %d points, want 3", first[0].Points)
+	}
+	first[0].Points = 99 // callers' changes don't reach the cache
+
+	// Same state and seeds: the cached standings come back, not the
+	// (different) engine's.
+	if got := CachedStandings(tour, &fresh, seeds); got[0].Points != 3 {
+		t.Errorf("same version: leader has %d points, want the cached 3", got[0].Points)
+	}
+	// New seeds or new state: computed again.
+	if got := CachedStandings(tour, &fresh, map[int]int64{1: 5}); got[0].Points != 0 {
+		t.Errorf("new seeds: leader has %d points, want 0", got[0].Points)
+	}
+	// A new tiebreak order: computed again.
+	seeds = map[int]int64{1: 5}
+	tour.Tiebreakers = []string{models.TiebreakOGW}
+	if got := CachedStandings(tour, played, seeds); got[0].Points != 3 {
+		t.Errorf("new order: leader has %d points, want 3", got[0].Points)
+	}
+	tour.EngineState = append([]byte(nil), state...)
+	tour.EngineState = append(tour.EngineState, ' ')
+	if got := CachedStandings(tour, &fresh, seeds); got[0].Points != 0 {
+		t.Errorf("new state: leader has %d points, want 0", got[0].Points)
+	}
+}
+
+func TestStandingsCache_BoundedAndForget(t *testing.T) {
+	c := newStandingsCache(2)
+	v := standingsVersion([]byte("state"), nil, nil)
+	one := []st.PlayerStanding{{PlayerID: 1}}
+	c.put(1, v, one)
+	c.put(2, v, one)
+	c.put(1, v, one) // an update doesn't count as a new tournament
+	c.put(3, v, one)
+	if _, ok := c.get(1, v); ok {
+		t.Error("oldest tournament kept past the bound")
+	}
+	if _, ok := c.get(3, v); !ok {
+		t.Error("newest tournament dropped")
+	}
+	c.forget(3)
+	if _, ok := c.get(3, v); ok {
+		t.Error("forgotten tournament still cached")
+	}
+	if _, ok := c.get(2, standingsVersion([]byte("other"), nil, nil)); ok {
+		t.Error("stale version served")
+	}
+}
@@ -0,0 +1,83 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/dstathis/openswiss/internal/pairing"
+)
+
+// StartCheck reports whether a tournament can be started with its current
+// registrations. Reason explains a refusal; Warnings are shown to the
+// organizer but don't block the start. RecommendedRounds is the Swiss
+// rounds the field calls for (pairing.SwissRounds), and Rounds how many the
+// tournament would run if started now, 0 when staff finish it by hand.
+// Unpaid counts the confirmed players who haven't paid an entry fee.
+type StartCheck struct {
+	CanStart          bool     `json:"can_start"`
+	Players           int      `json:"players"`
+	MinPlayers        int      `json:"min_players"`
+	RecommendedRounds int      `json:"recommended_rounds"`
+	Rounds            int      `json:"rounds"`
+	Unpaid            int      `json:"unpaid"`
+	Reason            string   `json:"reason,omitempty"`
+	Warnings          []string `json:"warnings,omitempty"`
+}
+
+// Err returns the refusal as an error, or nil if the tournament can start.
+func (c StartCheck) Err() error {
+	if c.CanStart {
+		return nil
+	}
+	return errors.New(c.Reason)
+}
+
+// CheckStart decides whether t can be started. Only confirmed registrations
+// are seated by InitTournamentEngine, so only they count toward the minimum.
+// In a team event each of them is a team, and every one needs a full roster.
+func CheckStart(t *models.Tournament, regs []models.Registration) StartCheck {
+	min := t.MinPlayers
+	if min < models.DefaultMinPlayers {
+		min = models.DefaultMinPlayers
+	}
+	c := StartCheck{MinPlayers: min}
+	var short string
+	for _, r := range regs {
+		if r.Status == models.RegistrationStatusConfirmed {
+			c.Players++
+			if t.EntryFee > 0 && r.PaymentStatus == models.PaymentUnpaid {
+				c.Unpaid++
+			}
+			if t.TeamSize > 0 && len(r.Members) != t.TeamSize && short == "" {
+				short = r.DisplayName
+			}
+		}
+	}
+
+	switch {
+	case t.Status != models.TournamentStatusRegistrationOpen && t.Status != models.TournamentStatusScheduled:
+		c.Reason = fmt.Sprintf("tournament cannot be started from state %s", t.Status)
+	case short != "":
+		c.Reason = fmt.Sprintf("team %s needs a roster of %d players", short, t.TeamSize)
+	case c.Players < min:
+		c.Reason = fmt.Sprintf("at least %d confirmed players are needed to start; %d registered", min, c.Players)
+	default:
+		c.CanStart = true
+	}
+	if c.Players >= min && c.Players%2 == 1 {
+		c.Warnings = append(c.Warnings, fmt.Sprintf("%d players is an odd number: one player gets a bye each round", c.Players))
+	}
+	if c.Unpaid > 0 {
+		c.Warnings = append(c.Warnings, fmt.Sprintf("%d confirmed players haven't paid the entry fee", c.Unpaid))
+	}
+	c.RecommendedRounds = pairing.SwissRounds(c.Players)
+	c.Rounds = autoRounds(t, c.Players)
+	if t.NumRounds != nil && *t.NumRounds > 0 {
+		c.Rounds = *t.NumRounds
+		if c.Players >= min && c.Rounds < c.RecommendedRounds && t.PairingAlgorithm != models.PairingRoundRobin {
+			c.Warnings = append(c.Warnings, fmt.Sprintf("%d rounds is fewer than the %d recommended for %d players: more than one player may finish undefeated", c.Rounds, c.RecommendedRounds, c.Players))
+		}
+	}
+	return c
+}
@@ -0,0 +1,104 @@
+package engine
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+func confirmedRegs(n int) []models.Registration {
+	regs := make([]models.Registration, 0, n+1)
+	for i := 0; i < n; i++ {
+		regs = append(regs, models.Registration{Status: models.RegistrationStatusConfirmed})
+	}
+	// Pending registrations aren't seated and don't count.
+	return append(regs, models.Registration{Status: models.RegistrationStatusPending})
+}
+
+func TestCheckStart(t *testing.T) {
+	open := models.TournamentStatusRegistrationOpen
+	tests := []struct {
+		name       string
+		status     string
+		minPlayers int
+		players    int
+		canStart   bool
+		reason     string
+		odd        bool
+	}{
+		{"empty", open, 0, 0, false, "at least 2 confirmed players", false},
+		{"one player", open, 2, 1, false, "at least 2 confirmed players are needed to start; 1 registered", false},
+		{"below configured min", open, 8, 6, false, "at least 8", false},
+		{"at min", open, 8, 8, true, "", false},
+		{"odd field", models.TournamentStatusScheduled, 4, 5, true, "", true},
+		{"already started", models.TournamentStatusInProgress, 2, 4, false, "cannot be started from state in_progress", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tm := &models.Tournament{Status: tt.status, MinPlayers: tt.minPlayers}
+			c := CheckStart(tm, confirmedRegs(tt.players))
+			if c.CanStart != tt.canStart {
+				t.Fatalf("CanStart = %v (%s), want %v", c.CanStart, c.Reason, tt.canStart)
+			}
+			if c.Players != tt.players {
+				t.Errorf("Players = %d, want %d", c.Players, tt.players)
+			}
+			if !strings.Contains(c.Reason, tt.reason) {
+				t.Errorf("Reason = %q, want it to contain %q", c.Reason, tt.reason)
+			}
+			if (c.Err() == nil) != tt.canStart {
+				t.Errorf("Err() = %v with CanStart %v", c.Err(), c.CanStart)
+			}
+			if odd := len(c.Warnings) > 0; odd != tt.odd {
+				t.Errorf("Warnings = %v, want odd-count warning %v", c.Warnings, tt.odd)
+			}
+		})
+	}
+}
+
+func TestCheckStart_Rounds(t *testing.T) {
+	four, two := 4, 2
+	tests := []struct {
+		name        string
+		tm          models.Tournament
+		rounds      int
+		fewerRounds bool
+	}{
+		{"manual", models.Tournament{}, 0, false},
+		{"auto", models.Tournament{AutoRounds: true}, 4, false},
+		{"set", models.Tournament{NumRounds: &four, AutoRounds: true}, 4, false},
+		{"set too few", models.Tournament{NumRounds: &two}, 2, true},
+		{"round robin", models.Tournament{PairingAlgorithm: models.PairingRoundRobin}, 9, false},
+		{"round robin, too few", models.Tournament{PairingAlgorithm: models.PairingRoundRobin, NumRounds: &two}, 2, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.tm.Status = models.TournamentStatusRegistrationOpen
+			c := CheckStart(&tt.tm, confirmedRegs(10))
+			if c.RecommendedRounds != 4 {
+				t.Errorf("RecommendedRounds = %d, want 4", c.RecommendedRounds)
+			}
+			if c.Rounds != tt.rounds {
+				t.Errorf("Rounds = %d, want %d", c.Rounds, tt.rounds)
+			}
+			if fewer := len(c.Warnings) > 0; fewer != tt.fewerRounds {
+				t.Errorf("Warnings = %v, want too-few-rounds warning %v", c.Warnings, tt.fewerRounds)
+			}
+		})
+	}
+}
+
+func TestCheckStart_TeamRosters(t *testing.T) {
+	tm := &models.Tournament{Status: models.TournamentStatusRegistrationOpen, MinPlayers: 2, TeamSize: 3}
+	regs := confirmedRegs(2)
+	regs[0].DisplayName, regs[0].Members = "Full", []string{"A", "B", "C"}
+	regs[1].DisplayName, regs[1].Members = "Short", []string{"D", "E"}
+	if c := CheckStart(tm, regs); c.CanStart || !strings.Contains(c.Reason, "team Short needs a roster of 3") {
+		t.Errorf("short roster: CanStart %v, Reason %q", c.CanStart, c.Reason)
+	}
+	regs[1].Members = append(regs[1].Members, "F")
+	if c := CheckStart(tm, regs); !c.CanStart {
+		t.Errorf("full rosters refused: %s", c.Reason)
+	}
+}
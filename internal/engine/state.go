@@ -0,0 +1,115 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+
+	st "github.com/dstathis/swisstools"
+)
+
+// statePairing mirrors the pairing object in the swisstools dump format.
+// swisstools keeps Pairing fields unexported, so any change to a round's
+// pairings (ordering, hand-made pairings) goes through the dump: decode,
+// edit, re-encode and LoadTournament.
+type statePairing struct {
+	PlayerA     int `json:"playerA"`
+	PlayerB     int `json:"playerB"`
+	PlayerAWins int `json:"playerAWins"`
+	PlayerBWins int `json:"playerBWins"`
+	Draws       int `json:"draws"`
+}
+
+func (p statePairing) isBye() bool {
+	return p.PlayerB == st.BYE_OPPONENT_ID
+}
+
+// statePlayer is a player object of the swisstools dump. It is kept as raw
+// fields so an edit only rewrites the ones it sets.
+type statePlayer map[string]json.RawMessage
+
+func (p statePlayer) id() int {
+	var id int
+	json.Unmarshal(p["id"], &id)
+	return id
+}
+
+// statePlayoff mirrors the playoff object of the dump. Rounds[0] is the
+// first bracket round.
+type statePlayoff struct {
+	Seeds        []int            `json:"seeds"`
+	Rounds       [][]statePairing `json:"rounds"`
+	CurrentRound int              `json:"currentRound"`
+	Finished     bool             `json:"finished"`
+}
+
+// engineState is the editable subset of a swisstools dump. Rounds is indexed
+// like swisstools' own slice: index 0 is the unused pre-tournament slot and
+// Rounds[CurrentRound] is the round being played. Playoff is nil until the
+// top cut starts.
+type engineState struct {
+	Config       st.TournamentConfig
+	CurrentRound int
+	Players      []statePlayer
+	Rounds       [][]statePairing
+	Playoff      *statePlayoff
+}
+
+// editState dumps the engine, hands the editable fields to fn, then reloads
+// the edited dump into eng. Every other field of the dump is passed through
+// untouched. If fn returns an error eng is left as it was.
+func editState(eng *st.Tournament, fn func(s *engineState) error) error {
+	data, err := eng.DumpTournament()
+	if err != nil {
+		return fmt.Errorf("dump engine state: %w", err)
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("decode engine state: %w", err)
+	}
+	var s engineState
+	for key, dst := range map[string]interface{}{
+		"config":       &s.Config,
+		"currentRound": &s.CurrentRound,
+		"players":      &s.Players,
+		"rounds":       &s.Rounds,
+	} {
+		if err := json.Unmarshal(raw[key], dst); err != nil {
+			return fmt.Errorf("decode %s: %w", key, err)
+		}
+	}
+	if p, ok := raw["playoff"]; ok {
+		if err := json.Unmarshal(p, &s.Playoff); err != nil {
+			return fmt.Errorf("decode playoff: %w", err)
+		}
+	}
+
+	if err := fn(&s); err != nil {
+		return err
+	}
+
+	for key, src := range map[string]interface{}{
+		"config":       s.Config,
+		"currentRound": s.CurrentRound,
+		"players":      s.Players,
+		"rounds":       s.Rounds,
+	} {
+		if raw[key], err = json.Marshal(src); err != nil {
+			return fmt.Errorf("encode %s: %w", key, err)
+		}
+	}
+	delete(raw, "playoff")
+	if s.Playoff != nil {
+		if raw["playoff"], err = json.Marshal(s.Playoff); err != nil {
+			return fmt.Errorf("encode playoff: %w", err)
+		}
+	}
+	if data, err = json.Marshal(raw); err != nil {
+		return fmt.Errorf("encode engine state: %w", err)
+	}
+	loaded, err := st.LoadTournament(data)
+	if err != nil {
+		return fmt.Errorf("reload engine state: %w", err)
+	}
+	*eng = loaded
+	return nil
+}
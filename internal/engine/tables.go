@@ -0,0 +1,55 @@
+package engine
+
+import (
+	"sort"
+
+	st "github.com/dstathis/swisstools"
+)
+
+// AssignTables reorders the current round's pairings so that the pairing
+// containing the best-placed player sits at table 1, the next at table 2,
+// and so on, with byes last. Players are placed as on the standings pages:
+// by the tiebreakers in order, then by seeds (see Standings). The order is persisted in the engine state, so
+// the table number of a pairing is simply its position in the round (see
+// TableNumber) and stays put when results come in or a player drops.
+func AssignTables(eng *st.Tournament, order []string, seeds map[int]int64) error {
+	position := make(map[int]int)
+	for i, s := range Standings(eng, order, seeds) {
+		position[s.PlayerID] = i
+	}
+	best := func(p statePairing) (int, int) {
+		a, b := position[p.PlayerA], position[p.PlayerB]
+		if p.isBye() {
+			return a, a
+		}
+		return min(a, b), max(a, b)
+	}
+
+	return editState(eng, func(s *engineState) error {
+		if s.CurrentRound < 1 || s.CurrentRound >= len(s.Rounds) {
+			return nil
+		}
+		round := s.Rounds[s.CurrentRound]
+		sort.SliceStable(round, func(i, j int) bool {
+			if round[i].isBye() != round[j].isBye() {
+				return !round[i].isBye()
+			}
+			bi, wi := best(round[i])
+			bj, wj := best(round[j])
+			if bi != bj {
+				return bi < bj
+			}
+			return wi < wj
+		})
+		return nil
+	})
+}
+
+// TableNumber returns the 1-based table for the pairing at index i of a
+// round, or 0 for a bye (byes don't occupy a table).
+func TableNumber(i int, p st.Pairing) int {
+	if p.PlayerB() == st.BYE_OPPONENT_ID {
+		return 0
+	}
+	return i + 1
+}
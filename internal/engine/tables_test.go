@@ -0,0 +1,133 @@
+package engine
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/models"
+	st "github.com/dstathis/swisstools"
+)
+
+// playedEngine returns an engine with n players that has finished round 1
+// (player A wins every match 2-0) and is ready to pair round 2.
+func playedEngine(t *testing.T, n int) *st.Tournament {
+	t.Helper()
+	eng := st.NewTournament()
+	for i := 0; i < n; i++ {
+		if err := eng.AddPlayer(fmt.Sprintf("P%d", i)); err != nil {
+			t.Fatalf("add player: %v", err)
+		}
+	}
+	if err := eng.StartTournament(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	for _, p := range eng.GetRound() {
+		if p.PlayerB() == st.BYE_OPPONENT_ID {
+			continue
+		}
+		if err := eng.AddResult(p.PlayerA(), 2, 0, 0); err != nil {
+			t.Fatalf("add result: %v", err)
+		}
+	}
+	if err := eng.NextRound(); err != nil {
+		t.Fatalf("next round: %v", err)
+	}
+	return &eng
+}
+
+func TestPairRound_TopStandingAtTableOne(t *testing.T) {
+	eng := playedEngine(t, 9)
+	if err := PairRound(eng, &models.Tournament{}, false, nil, nil, nil); err != nil {
+		t.Fatalf("pair: %v", err)
+	}
+
+	// Fully tied players come back in arbitrary order from GetStandings,
+	// so compare ranks rather than list positions.
+	rank := map[int]int{}
+	for _, s := range eng.GetStandings() {
+		rank[s.PlayerID] = s.Rank
+	}
+	round := eng.GetRound()
+	last := 0
+	for i, p := range round {
+		if p.PlayerB() == st.BYE_OPPONENT_ID {
+			if i != len(round)-1 {
+				t.Errorf("bye at index %d, want last (%d)", i, len(round)-1)
+			}
+			if TableNumber(i, p) != 0 {
+				t.Errorf("bye table = %d, want 0", TableNumber(i, p))
+			}
+			continue
+		}
+		if got := TableNumber(i, p); got != i+1 {
+			t.Errorf("TableNumber(%d) = %d, want %d", i, got, i+1)
+		}
+		best := min(rank[p.PlayerA()], rank[p.PlayerB()])
+		if best < last {
+			t.Errorf("table %d holds rank %d, after a table holding rank %d", i+1, best, last)
+		}
+		last = best
+	}
+	if best := min(rank[round[0].PlayerA()], rank[round[0].PlayerB()]); best != 1 {
+		t.Errorf("table 1 best rank = %d, want 1", best)
+	}
+}
+
+func TestAssignTables_PreservesResultsAndState(t *testing.T) {
+	eng := playedEngine(t, 8)
+	if err := PairRound(eng, &models.Tournament{}, false, nil, nil, nil); err != nil {
+		t.Fatalf("pair: %v", err)
+	}
+	p := eng.GetRound()[0]
+	if err := eng.AddResult(p.PlayerA(), 2, 1, 0); err != nil {
+		t.Fatalf("add result: %v", err)
+	}
+	if err := AssignTables(eng, nil, nil); err != nil {
+		t.Fatalf("assign tables: %v", err)
+	}
+	if eng.GetCurrentRound() != 2 {
+		t.Errorf("current round = %d, want 2", eng.GetCurrentRound())
+	}
+	if len(eng.GetRound()) != 4 {
+		t.Fatalf("pairings = %d, want 4", len(eng.GetRound()))
+	}
+	var found bool
+	for _, q := range eng.GetRound() {
+		if q.PlayerA() == p.PlayerA() {
+			found = true
+			if q.PlayerAWins() != 2 || q.PlayerBWins() != 1 {
+				t.Errorf("result lost: got %d-%d", q.PlayerAWins(), q.PlayerBWins())
+			}
+		}
+	}
+	if !found {
+		t.Error("pairing missing after reassigning tables")
+	}
+}
+
+func TestAssignTables_FollowsSeeds(t *testing.T) {
+	eng := playedEngine(t, 8)
+	if err := PairRound(eng, &models.Tournament{}, false, nil, nil, nil); err != nil {
+		t.Fatalf("pair: %v", err)
+	}
+
+	// The round 1 winners are fully tied, so the seeds place them, as on
+	// the standings pages: whichever winner has the lowest seed is at
+	// table 1.
+	for _, p := range eng.GetRound() {
+		if eng.GetPlayers()[p.PlayerA()].Points == 0 {
+			continue
+		}
+		seeds := map[int]int64{}
+		for id := range eng.GetPlayers() {
+			seeds[id] = 100
+		}
+		seeds[p.PlayerB()] = 1
+		if err := AssignTables(eng, nil, seeds); err != nil {
+			t.Fatalf("assign tables: %v", err)
+		}
+		if top := eng.GetRound()[0]; top.PlayerA() != p.PlayerB() && top.PlayerB() != p.PlayerB() {
+			t.Errorf("table 1 = %d vs %d, want the pairing of %d", top.PlayerA(), top.PlayerB(), p.PlayerB())
+		}
+	}
+}
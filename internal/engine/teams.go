@@ -0,0 +1,251 @@
+package engine
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/models"
+	st "github.com/dstathis/swisstools"
+)
+
+// errNotTeamEvent is returned by the team operations on an individual event.
+var errNotTeamEvent = errors.New("tournament is not a team event")
+
+// ParseMembers splits a roster typed one name per line, in seat order.
+// Blank lines are skipped.
+func ParseMembers(text string) []string {
+	var names []string
+	for _, line := range strings.Split(text, "\n") {
+		if name := strings.TrimSpace(line); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// CheckMembers trims a roster and checks it fills exactly t.TeamSize seats
+// with distinct names.
+func CheckMembers(t *models.Tournament, names []string) ([]string, error) {
+	if len(names) != t.TeamSize {
+		return nil, fmt.Errorf("a team needs exactly %d players; got %d", t.TeamSize, len(names))
+	}
+	out := make([]string, len(names))
+	seen := map[string]bool{}
+	for i, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return nil, fmt.Errorf("seat %d has no player", i+1)
+		}
+		if utf8.RuneCountInString(name) > models.MaxMemberName {
+			return nil, fmt.Errorf("player names may be at most %d characters", models.MaxMemberName)
+		}
+		key := strings.ToLower(name)
+		if seen[key] {
+			return nil, fmt.Errorf("%q is on the team twice", name)
+		}
+		seen[key] = true
+		out[i] = name
+	}
+	return out, nil
+}
+
+// SetTeamMembers replaces the roster of team regID, in seat order. Rosters
+// can change until the tournament is complete, to seat a substitute; seat
+// results already recorded stay with the seat.
+func SetTeamMembers(ctx context.Context, database *sql.DB, tournamentID, regID int64, names []string) error {
+	return withPlayers(ctx, database, tournamentID, func(tx *sql.Tx, t *models.Tournament, _ *st.Tournament) error {
+		if t.TeamSize == 0 {
+			return errNotTeamEvent
+		}
+		names, err := CheckMembers(t, names)
+		if err != nil {
+			return err
+		}
+		reg, err := registrationOf(ctx, tx, t, regID)
+		if err != nil {
+			return err
+		}
+		return db.SetTeamMembers(ctx, tx, t.ID, reg.ID, names)
+	})
+}
+
+// SeatScore tallies the seat results of a team match from team A's side.
+func SeatScore(results []models.SeatResult) (aWins, bWins, draws int) {
+	for _, r := range results {
+		switch r.Winner {
+		case models.GameWinnerA:
+			aWins++
+		case models.GameWinnerB:
+			bWins++
+		case models.GameDraw:
+			draws++
+		}
+	}
+	return aWins, bWins, draws
+}
+
+// applyTeamMatch writes the tally of a team match's seat results as its
+// match result, each seat counting as one game, once every seat is in.
+// Until then the result is left unset, so the round can't advance past a
+// match still being played.
+func applyTeamMatch(eng *st.Tournament, playerA, teamSize int, results []models.SeatResult) error {
+	if len(results) < teamSize {
+		u := st.UNINITIALIZED_RESULT
+		return eng.AddResult(playerA, u, u, u)
+	}
+	a, b, d := SeatScore(results)
+	return eng.AddResult(playerA, a, b, d)
+}
+
+// ReportSeat records the result of seat s.Seat of the team match at s.Table
+// in the current round, replacing any earlier one; an empty s.Winner clears
+// it. The match result follows the seats (see applyTeamMatch) and replaces
+// any intentional draw or concession recorded for the table. s.Winner and
+// ReportedBy come from the caller; the rest is filled in. Must run inside
+// WithTournamentEngine so the seat and the match result commit together.
+func ReportSeat(ctx context.Context, tx db.DBTX, t *models.Tournament, eng *st.Tournament, s *models.SeatResult) error {
+	if t.TeamSize == 0 {
+		return errNotTeamEvent
+	}
+	switch s.Winner {
+	case models.GameWinnerA, models.GameWinnerB, models.GameDraw, "":
+	default:
+		return errors.New("winner must be a, b or draw")
+	}
+	if s.Seat < 1 || s.Seat > t.TeamSize {
+		return fmt.Errorf("seat must be between 1 and %d", t.TeamSize)
+	}
+	p, ok := pairingAtTable(eng, s.Table)
+	if !ok {
+		return fmt.Errorf("no match at table %d", s.Table)
+	}
+
+	s.TournamentID = t.ID
+	s.Round = eng.GetCurrentRound()
+	var err error
+	if s.Winner == "" {
+		err = db.DeleteSeatResult(ctx, tx, t.ID, s.Round, s.Table, s.Seat)
+	} else {
+		err = db.SetSeatResult(ctx, tx, s)
+	}
+	if err != nil {
+		return err
+	}
+	results, err := db.ListSeatResults(ctx, tx, t.ID, s.Round)
+	if err != nil {
+		return err
+	}
+	if err := db.DeleteMatchResultType(ctx, tx, t.ID, s.Round, s.Table); err != nil {
+		return err
+	}
+	return applyTeamMatch(eng, p.PlayerA(), t.TeamSize, results[s.Table])
+}
+
+// IndividualStanding is one team member's record over the seats they
+// played.
+type IndividualStanding struct {
+	Rank           int    `json:"rank"`
+	Name           string `json:"name"`
+	Team           string `json:"team"`
+	RegistrationID int64  `json:"registration_id"`
+	Seat           int    `json:"seat"`
+	Wins           int    `json:"wins"`
+	Losses         int    `json:"losses"`
+	Draws          int    `json:"draws"`
+	Points         int    `json:"points"`
+}
+
+// IndividualStandings ranks every member of the teams in the engine by the
+// seat results they played, scored with the tournament's match points. A
+// team's bye counts for none of its members. Ties go by seat wins, then by
+// their team's place in the standings, then by seat. A seat is credited to
+// whoever holds it on the roster now.
+func IndividualStandings(t *models.Tournament, eng *st.Tournament, regs []models.Registration, results []models.SeatResult) []IndividualStanding {
+	teamRank := map[int]int{}
+	for _, s := range Standings(eng, t.TiebreakOrder(), TiebreakSeeds(regs)) {
+		teamRank[s.PlayerID] = s.Rank
+	}
+	type seatKey struct {
+		player, seat int
+	}
+	index := map[seatKey]int{}
+	var out []IndividualStanding
+	for _, r := range regs {
+		if r.EnginePlayerID == nil {
+			continue
+		}
+		for i, name := range r.Members {
+			index[seatKey{*r.EnginePlayerID, i + 1}] = len(out)
+			out = append(out, IndividualStanding{Name: name, Team: r.DisplayName, RegistrationID: r.ID, Seat: i + 1})
+		}
+	}
+	player := map[int64]int{}
+	for _, r := range regs {
+		if r.EnginePlayerID != nil {
+			player[r.ID] = *r.EnginePlayerID
+		}
+	}
+
+	rounds := map[int][]st.Pairing{}
+	for _, res := range results {
+		pairings, ok := rounds[res.Round]
+		if !ok {
+			pairings, _ = eng.GetRoundByNumber(res.Round)
+			rounds[res.Round] = pairings
+		}
+		var p *st.Pairing
+		for i := range pairings {
+			if TableNumber(i, pairings[i]) == res.Table {
+				p = &pairings[i]
+				break
+			}
+		}
+		if p == nil {
+			continue
+		}
+		a, okA := index[seatKey{p.PlayerA(), res.Seat}]
+		b, okB := index[seatKey{p.PlayerB(), res.Seat}]
+		record := func(i int, ok bool, won, lost bool) {
+			if !ok {
+				return
+			}
+			switch {
+			case won:
+				out[i].Wins++
+			case lost:
+				out[i].Losses++
+			default:
+				out[i].Draws++
+			}
+		}
+		record(a, okA, res.Winner == models.GameWinnerA, res.Winner == models.GameWinnerB)
+		record(b, okB, res.Winner == models.GameWinnerB, res.Winner == models.GameWinnerA)
+	}
+
+	for i := range out {
+		out[i].Points = out[i].Wins*t.PointsWin + out[i].Draws*t.PointsDraw + out[i].Losses*t.PointsLoss
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		if a.Points != b.Points {
+			return a.Points > b.Points
+		}
+		if a.Wins != b.Wins {
+			return a.Wins > b.Wins
+		}
+		if ra, rb := teamRank[player[a.RegistrationID]], teamRank[player[b.RegistrationID]]; ra != rb {
+			return ra < rb
+		}
+		return a.Seat < b.Seat
+	})
+	for i := range out {
+		out[i].Rank = i + 1
+	}
+	return out
+}
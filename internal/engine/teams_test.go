@@ -0,0 +1,113 @@
+package engine
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/models"
+	st "github.com/dstathis/swisstools"
+)
+
+func TestParseMembers(t *testing.T) {
+	got := ParseMembers("  Ann \r\n\nBea\n Cy\n")
+	if want := []string{"Ann", "Bea", "Cy"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseMembers = %q, want %q", got, want)
+	}
+	if got := ParseMembers(" \n"); got != nil {
+		t.Errorf("blank roster = %q, want nil", got)
+	}
+}
+
+func TestCheckMembers(t *testing.T) {
+	team := &models.Tournament{TeamSize: 3}
+	got, err := CheckMembers(team, []string{" Ann", "Bea ", "Cy"})
+	if err != nil || !reflect.DeepEqual(got, []string{"Ann", "Bea", "Cy"}) {
+		t.Errorf("CheckMembers = %q, %v", got, err)
+	}
+	for name, names := range map[string][]string{
+		"short":     {"Ann", "Bea"},
+		"long":      {"Ann", "Bea", "Cy", "Di"},
+		"blank":     {"Ann", " ", "Cy"},
+		"repeated":  {"Ann", "ann", "Cy"},
+		"too long":  {"Ann", "Bea", strings.Repeat("x", models.MaxMemberName+1)},
+		"no roster": nil,
+	} {
+		if _, err := CheckMembers(team, names); err == nil {
+			t.Errorf("%s: roster %q accepted", name, names)
+		}
+	}
+}
+
+func TestApplyTeamMatch(t *testing.T) {
+	eng := startedEngine(t, 2)
+	p, ok := pairingAtTable(eng, 1)
+	if !ok {
+		t.Fatal("no pairing at table 1")
+	}
+	seats := []models.SeatResult{
+		{Seat: 1, Winner: models.GameWinnerA},
+		{Seat: 2, Winner: models.GameWinnerB},
+	}
+	if err := applyTeamMatch(eng, p.PlayerA(), 3, seats); err != nil {
+		t.Fatal(err)
+	}
+	if got := eng.GetRound()[0]; got.PlayerAWins() != st.UNINITIALIZED_RESULT {
+		t.Errorf("match with a seat to go = %d-%d-%d, want unset", got.PlayerAWins(), got.PlayerBWins(), got.Draws())
+	}
+
+	seats = append(seats, models.SeatResult{Seat: 3, Winner: models.GameDraw})
+	if err := applyTeamMatch(eng, p.PlayerA(), 3, seats); err != nil {
+		t.Fatal(err)
+	}
+	if got := eng.GetRound()[0]; got.PlayerAWins() != 1 || got.PlayerBWins() != 1 || got.Draws() != 1 {
+		t.Errorf("match = %d-%d-%d, want 1-1-1", got.PlayerAWins(), got.PlayerBWins(), got.Draws())
+	}
+}
+
+func TestIndividualStandings(t *testing.T) {
+	eng := startedEngine(t, 3)
+	var match st.Pairing
+	for _, p := range eng.GetRound() {
+		if p.PlayerB() != st.BYE_OPPONENT_ID {
+			match = p
+		}
+	}
+	teams := map[int][]string{}
+	var regs []models.Registration
+	for id, p := range eng.GetPlayers() {
+		pid := id
+		teams[id] = []string{p.Name + "1", p.Name + "2"}
+		regs = append(regs, models.Registration{ID: int64(100 + id), DisplayName: p.Name, EnginePlayerID: &pid, Members: teams[id]})
+	}
+	// A bench registration that never entered the engine has no standing.
+	regs = append(regs, models.Registration{ID: 1, DisplayName: "Bench", Members: []string{"X", "Y"}})
+
+	tm := &models.Tournament{TeamSize: 2, PointsWin: 3, PointsDraw: 1}
+	results := []models.SeatResult{
+		{Round: 1, Table: 1, Seat: 1, Winner: models.GameWinnerA},
+		{Round: 1, Table: 1, Seat: 2, Winner: models.GameDraw},
+		// No such table: ignored.
+		{Round: 1, Table: 9, Seat: 1, Winner: models.GameWinnerA},
+	}
+	got := IndividualStandings(tm, eng, regs, results)
+	if len(got) != 6 {
+		t.Fatalf("got %d standings, want 6: %+v", len(got), got)
+	}
+	a, b := teams[match.PlayerA()], teams[match.PlayerB()]
+	if s := got[0]; s.Rank != 1 || s.Name != a[0] || s.Wins != 1 || s.Points != 3 {
+		t.Errorf("leader = %+v, want %s with the win", s, a[0])
+	}
+	// The two drawn seats tie on points; their teams' places order them.
+	drawn := map[string]bool{a[1]: true, b[1]: true}
+	for _, s := range got[1:3] {
+		if !drawn[s.Name] || s.Draws != 1 || s.Points != 1 {
+			t.Errorf("standing %+v, want one of the drawn seats %s, %s", s, a[1], b[1])
+		}
+	}
+	for _, s := range got[3:] {
+		if wantLoss := s.Name == b[0]; (s.Losses == 1) != wantLoss || s.Points != 0 {
+			t.Errorf("standing %+v: want only %s to have a loss, and no points", s, b[0])
+		}
+	}
+}
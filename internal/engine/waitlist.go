@@ -0,0 +1,76 @@
+package engine
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+// ErrWaitlistClosed is returned for a promotion once the tournament has
+// started; late players are added from the dashboard instead.
+var ErrWaitlistClosed = errors.New("waitlisted players can only be promoted before the tournament starts")
+
+// Waitlist returns the waitlisted registrations among regs, next in line
+// first. regs must be in sign-up order, as db.ListRegistrations returns
+// them.
+func Waitlist(regs []models.Registration) []models.Registration {
+	var out []models.Registration
+	for _, r := range regs {
+		if r.Status == models.RegistrationStatusWaitlisted {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// WaitlistPosition returns registration regID's place on the waitlist of
+// regs, 1 for next in line, or 0 when it isn't waitlisted.
+func WaitlistPosition(regs []models.Registration, regID int64) int {
+	for i, r := range Waitlist(regs) {
+		if r.ID == regID {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// PlacesTaken returns how many of regs count toward Max Players, as
+// db.CountRegistrations counts them: all but dropped and waitlisted ones.
+func PlacesTaken(regs []models.Registration) int {
+	n := 0
+	for _, r := range regs {
+		if r.Status != models.RegistrationStatusDropped && r.Status != models.RegistrationStatusWaitlisted {
+			n++
+		}
+	}
+	return n
+}
+
+// PromoteWaitlisted takes registration regID off tournament id's waitlist
+// (see db.PromoteRegistration), whether or not there is a free place: staff
+// promote a player when someone who had a place doesn't show up. It
+// returns db.ErrNotWaitlisted, or ErrWaitlistClosed once the tournament has
+// started.
+func PromoteWaitlisted(ctx context.Context, database *sql.DB, id, regID int64) (*models.Registration, error) {
+	tx, err := database.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+	t, err := db.GetTournamentForUpdate(ctx, tx, id)
+	if err != nil {
+		return nil, fmt.Errorf("get tournament: %w", err)
+	}
+	if t.Status != models.TournamentStatusScheduled && t.Status != models.TournamentStatusRegistrationOpen {
+		return nil, ErrWaitlistClosed
+	}
+	reg, err := db.PromoteRegistration(ctx, tx, id, regID, t.RequireDecklist)
+	if err != nil {
+		return nil, err
+	}
+	return reg, tx.Commit()
+}
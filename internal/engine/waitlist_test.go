@@ -0,0 +1,28 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+func TestWaitlist(t *testing.T) {
+	regs := []models.Registration{
+		{ID: 1, Status: models.RegistrationStatusConfirmed},
+		{ID: 2, Status: models.RegistrationStatusWaitlisted},
+		{ID: 3, Status: models.RegistrationStatusPending},
+		{ID: 4, Status: models.RegistrationStatusWaitlisted},
+	}
+	if w := Waitlist(regs); len(w) != 2 || w[0].ID != 2 || w[1].ID != 4 {
+		t.Errorf("Waitlist = %+v, want registrations 2 and 4", w)
+	}
+	for id, want := range map[int64]int{1: 0, 2: 1, 4: 2, 9: 0} {
+		if got := WaitlistPosition(regs, id); got != want {
+			t.Errorf("WaitlistPosition(%d) = %d, want %d", id, got, want)
+		}
+	}
+	regs = append(regs, models.Registration{ID: 5, Status: models.RegistrationStatusDropped})
+	if got := PlacesTaken(regs); got != 2 {
+		t.Errorf("PlacesTaken = %d, want 2", got)
+	}
+}
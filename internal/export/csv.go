@@ -0,0 +1,15 @@
+package export
+
+// CSVCell makes a free-text value safe for a CSV cell. Spreadsheets run a
+// cell starting with =, +, -, @, a tab or a carriage return as a formula,
+// so such a value, a player name say, gets a leading ' to keep it text.
+func CSVCell(s string) string {
+	if s == "" {
+		return s
+	}
+	switch s[0] {
+	case '=', '+', '-', '@', '\t', '\r':
+		return "'" + s
+	}
+	return s
+}
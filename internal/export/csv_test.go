@@ -0,0 +1,21 @@
+package export
+
+import "testing"
+
+func TestCSVCell(t *testing.T) {
+	for in, want := range map[string]string{
+		"":                  "",
+		"Ada":               "Ada",
+		"=HYPERLINK(\"x\")": "'=HYPERLINK(\"x\")",
+		"+1":                "'+1",
+		"-1":                "'-1",
+		"@SUM(A1)":          "'@SUM(A1)",
+		"\tcash":            "'\tcash",
+		"\rcash":            "'\rcash",
+		"a=b":               "a=b",
+	} {
+		if got := CSVCell(in); got != want {
+			t.Errorf("CSVCell(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
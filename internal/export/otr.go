@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 
+	"github.com/dstathis/openswiss/internal/engine"
 	"github.com/dstathis/openswiss/internal/models"
 	"github.com/dstathis/swisstools"
 )
@@ -84,8 +85,12 @@ type OTRPlayoffRound struct {
 	Pairings  []OTRPairing `json:"pairings"`
 }
 
-func GenerateOTR(t *models.Tournament, eng *swisstools.Tournament) ([]byte, error) {
-	standings := eng.GetStandings()
+// GenerateOTR renders the tournament as an OTR document. seeds are the
+// per-player tiebreak seeds (engine.TiebreakSeeds) so final_rank matches the
+// standings shown on the site; nil falls back to ordering ties by player ID.
+// Ranks follow t's tiebreak order.
+func GenerateOTR(t *models.Tournament, eng *swisstools.Tournament, seeds map[int]int64) ([]byte, error) {
+	standings := engine.Standings(eng, t.TiebreakOrder(), seeds)
 	players := eng.GetPlayers()
 
 	otr := OTR{
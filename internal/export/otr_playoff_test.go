@@ -63,7 +63,7 @@ func setupPlayoffTournament(t *testing.T) (*models.Tournament, *swisstools.Tourn
 
 func TestGenerateOTR_WithPlayoffStarted(t *testing.T) {
 	mt, eng := setupPlayoffTournament(t)
-	data, err := GenerateOTR(mt, eng)
+	data, err := GenerateOTR(mt, eng, nil)
 	if err != nil {
 		t.Fatalf("GenerateOTR: %v", err)
 	}
@@ -103,7 +103,7 @@ func TestGenerateOTR_WithPlayoffFinished(t *testing.T) {
 		}
 	}
 
-	data, err := GenerateOTR(mt, eng)
+	data, err := GenerateOTR(mt, eng, nil)
 	if err != nil {
 		t.Fatalf("GenerateOTR: %v", err)
 	}
@@ -150,7 +150,7 @@ func TestGenerateOTR_DecklistPublic(t *testing.T) {
 		PointsLoss:     0,
 		DecklistPublic: true,
 	}
-	data, err := GenerateOTR(mt, &eng)
+	data, err := GenerateOTR(mt, &eng, nil)
 	if err != nil {
 		t.Fatalf("GenerateOTR: %v", err)
 	}
@@ -199,7 +199,7 @@ func TestGenerateOTR_DecklistPrivate(t *testing.T) {
 		PointsLoss:     0,
 		DecklistPublic: false,
 	}
-	data, _ := GenerateOTR(mt, &eng)
+	data, _ := GenerateOTR(mt, &eng, nil)
 	var otr OTR
 	json.Unmarshal(data, &otr)
 	for _, p := range otr.Players {
@@ -231,7 +231,7 @@ func TestGenerateOTR_WithBye(t *testing.T) {
 	}
 
 	mt := &models.Tournament{Name: "With Bye", PointsWin: 3, PointsDraw: 1, PointsLoss: 0}
-	data, err := GenerateOTR(mt, &eng)
+	data, err := GenerateOTR(mt, &eng, nil)
 	if err != nil {
 		t.Fatalf("GenerateOTR: %v", err)
 	}
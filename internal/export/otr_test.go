@@ -72,7 +72,7 @@ func setupTestTournament(t *testing.T) (*models.Tournament, *swisstools.Tourname
 
 func TestGenerateOTR_BasicStructure(t *testing.T) {
 	mt, eng := setupTestTournament(t)
-	data, err := GenerateOTR(mt, eng)
+	data, err := GenerateOTR(mt, eng, nil)
 	if err != nil {
 		t.Fatalf("GenerateOTR error: %v", err)
 	}
@@ -96,7 +96,7 @@ func TestGenerateOTR_BasicStructure(t *testing.T) {
 
 func TestGenerateOTR_HasPlayers(t *testing.T) {
 	mt, eng := setupTestTournament(t)
-	data, err := GenerateOTR(mt, eng)
+	data, err := GenerateOTR(mt, eng, nil)
 	if err != nil {
 		t.Fatalf("GenerateOTR error: %v", err)
 	}
@@ -121,7 +121,7 @@ func TestGenerateOTR_HasPlayers(t *testing.T) {
 
 func TestGenerateOTR_HasRounds(t *testing.T) {
 	mt, eng := setupTestTournament(t)
-	data, _ := GenerateOTR(mt, eng)
+	data, _ := GenerateOTR(mt, eng, nil)
 	var otr OTR
 	json.Unmarshal(data, &otr)
 	if len(otr.Rounds) != 2 {
@@ -140,7 +140,7 @@ func TestGenerateOTR_HasRounds(t *testing.T) {
 
 func TestGenerateOTR_DateAndLocation(t *testing.T) {
 	mt, eng := setupTestTournament(t)
-	data, _ := GenerateOTR(mt, eng)
+	data, _ := GenerateOTR(mt, eng, nil)
 	var otr OTR
 	json.Unmarshal(data, &otr)
 	if otr.Tournament.Date == "" {
@@ -155,7 +155,7 @@ func TestGenerateOTR_NoDateLocation(t *testing.T) {
 	mt, eng := setupTestTournament(t)
 	mt.ScheduledAt = nil
 	mt.Location = nil
-	data, _ := GenerateOTR(mt, eng)
+	data, _ := GenerateOTR(mt, eng, nil)
 	var otr OTR
 	json.Unmarshal(data, &otr)
 	if otr.Tournament.Date != "" {
@@ -168,7 +168,7 @@ func TestGenerateOTR_NoDateLocation(t *testing.T) {
 
 func TestGenerateOTR_NoPlayoff(t *testing.T) {
 	mt, eng := setupTestTournament(t)
-	data, _ := GenerateOTR(mt, eng)
+	data, _ := GenerateOTR(mt, eng, nil)
 	var otr OTR
 	json.Unmarshal(data, &otr)
 	if otr.Playoff != nil {
@@ -179,7 +179,7 @@ func TestGenerateOTR_NoPlayoff(t *testing.T) {
 func TestGenerateOTR_TopCut(t *testing.T) {
 	mt, eng := setupTestTournament(t)
 	mt.TopCut = 4
-	data, _ := GenerateOTR(mt, eng)
+	data, _ := GenerateOTR(mt, eng, nil)
 	var otr OTR
 	json.Unmarshal(data, &otr)
 	if otr.Tournament.TopCut != 4 {
@@ -189,7 +189,7 @@ func TestGenerateOTR_TopCut(t *testing.T) {
 
 func TestGenerateOTR_ValidJSON(t *testing.T) {
 	mt, eng := setupTestTournament(t)
-	data, err := GenerateOTR(mt, eng)
+	data, err := GenerateOTR(mt, eng, nil)
 	if err != nil {
 		t.Fatalf("GenerateOTR error: %v", err)
 	}
@@ -225,7 +225,7 @@ func TestPlayoffRoundName(t *testing.T) {
 
 func TestGenerateOTR_PairingStructure(t *testing.T) {
 	mt, eng := setupTestTournament(t)
-	data, _ := GenerateOTR(mt, eng)
+	data, _ := GenerateOTR(mt, eng, nil)
 	var otr OTR
 	json.Unmarshal(data, &otr)
 	for _, round := range otr.Rounds {
@@ -0,0 +1,161 @@
+package export
+
+import (
+	"encoding/xml"
+	"strings"
+
+	"github.com/dstathis/openswiss/internal/engine"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/dstathis/swisstools"
+)
+
+// WEREvent is the root of the WER-style XML export: the event, its players
+// and every match, in the layout of the event files that reporting tools
+// used by sanctioning bodies import.
+type WEREvent struct {
+	XMLName        xml.Name    `xml:"event"`
+	Title          string      `xml:"title,attr"`
+	StartDate      string      `xml:"startdate,attr,omitempty"`
+	Location       string      `xml:"location,attr,omitempty"`
+	Format         string      `xml:"format,attr"`
+	NumberOfRounds int         `xml:"numberofrounds,attr"`
+	TopCut         int         `xml:"topcut,attr,omitempty"`
+	Persons        []WERPerson `xml:"participation>person"`
+	Rounds         []WERRound  `xml:"matches>round"`
+}
+
+// WERPerson is a player. MemberID is their external ID (membership or
+// rating number) when they have one.
+type WERPerson struct {
+	ID       int    `xml:"id,attr"`
+	First    string `xml:"first,attr"`
+	Last     string `xml:"last,attr"`
+	MemberID *int   `xml:"memberid,attr,omitempty"`
+	Rank     int    `xml:"rank,attr"`
+	Points   int    `xml:"points,attr"`
+	Dropped  int    `xml:"dropped,attr,omitempty"`
+}
+
+// WERRound is a Swiss or playoff round. Playoff rounds are numbered on from
+// the last Swiss round; those not yet paired are left out.
+type WERRound struct {
+	Number  int        `xml:"number,attr"`
+	Playoff bool       `xml:"playoff,attr,omitempty"`
+	Name    string     `xml:"name,attr,omitempty"`
+	Matches []WERMatch `xml:"match"`
+}
+
+// WERMatch is one match from Person's side. Opponent is absent for a bye.
+type WERMatch struct {
+	Person   int    `xml:"person,attr"`
+	Opponent int    `xml:"opponent,attr,omitempty"`
+	Win      int    `xml:"win,attr"`
+	Loss     int    `xml:"loss,attr"`
+	Draw     int    `xml:"draw,attr"`
+	Outcome  string `xml:"outcome,attr"`
+}
+
+// GenerateWER renders the tournament as a WER-style XML event file. Ranks
+// are the final places (engine.FinalStandings), so playoff finishers come
+// first.
+func GenerateWER(t *models.Tournament, eng *swisstools.Tournament, seeds map[int]int64) ([]byte, error) {
+	ev := WEREvent{
+		Title:          t.Name,
+		Format:         "Swiss",
+		NumberOfRounds: eng.GetCurrentRound(),
+		TopCut:         t.TopCut,
+	}
+	if t.ScheduledAt != nil {
+		ev.StartDate = t.ScheduledAt.Format("2006-01-02")
+	}
+	if t.Location != nil {
+		ev.Location = *t.Location
+	}
+
+	players := eng.GetPlayers()
+	for _, p := range engine.FinalStandings(eng, t.TiebreakOrder(), seeds) {
+		first, last := splitName(p.Standing.Name)
+		person := WERPerson{
+			ID:     p.Standing.PlayerID,
+			First:  first,
+			Last:   last,
+			Rank:   p.Place,
+			Points: p.Standing.Points,
+		}
+		if player, ok := players[p.Standing.PlayerID]; ok {
+			person.MemberID = player.ExternalID
+			if player.Removed {
+				person.Dropped = player.RemovedInRound
+			}
+		}
+		ev.Persons = append(ev.Persons, person)
+	}
+
+	for i := 1; i <= eng.GetCurrentRound(); i++ {
+		pairings, err := eng.GetRoundByNumber(i)
+		if err != nil {
+			continue
+		}
+		ev.Rounds = append(ev.Rounds, WERRound{Number: i, Matches: werMatches(pairings)})
+	}
+	if po := eng.GetPlayoff(); po != nil {
+		for i, round := range po.Rounds {
+			if len(round) == 0 {
+				continue // not paired yet
+			}
+			ev.Rounds = append(ev.Rounds, WERRound{
+				Number:  eng.GetCurrentRound() + i + 1,
+				Playoff: true,
+				Name:    playoffRoundName(len(po.Rounds), i),
+				Matches: werMatches(round),
+			})
+		}
+	}
+
+	out, err := xml.MarshalIndent(ev, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), append(out, '\n')...), nil
+}
+
+func werMatches(pairings []swisstools.Pairing) []WERMatch {
+	matches := make([]WERMatch, 0, len(pairings))
+	for _, p := range pairings {
+		m := WERMatch{
+			Person: p.PlayerA(),
+			Win:    max(p.PlayerAWins(), 0),
+			Loss:   max(p.PlayerBWins(), 0),
+			Draw:   max(p.Draws(), 0),
+		}
+		switch {
+		case p.PlayerB() == swisstools.BYE_OPPONENT_ID:
+			m.Outcome = "bye"
+		case p.PlayerAWins() == swisstools.UNINITIALIZED_RESULT:
+			m.Opponent = p.PlayerB()
+			m.Outcome = "unreported"
+		default:
+			m.Opponent = p.PlayerB()
+			switch {
+			case m.Win > m.Loss:
+				m.Outcome = "win"
+			case m.Loss > m.Win:
+				m.Outcome = "loss"
+			default:
+				m.Outcome = "draw"
+			}
+		}
+		matches = append(matches, m)
+	}
+	return matches
+}
+
+// splitName splits a display name into first and last name at its last
+// space; a single word is all first name.
+func splitName(name string) (first, last string) {
+	name = strings.TrimSpace(name)
+	if i := strings.LastIndexByte(name, ' '); i > 0 {
+		return strings.TrimSpace(name[:i]), name[i+1:]
+	}
+	return name, ""
+}
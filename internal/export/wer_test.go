@@ -0,0 +1,94 @@
+package export
+
+import (
+	"bytes"
+	"encoding/xml"
+	"testing"
+
+	"github.com/dstathis/swisstools"
+)
+
+func TestGenerateWER(t *testing.T) {
+	mt, eng := setupTestTournament(t)
+	data, err := GenerateWER(mt, eng, nil)
+	if err != nil {
+		t.Fatalf("GenerateWER: %v", err)
+	}
+	if !bytes.HasPrefix(data, []byte(xml.Header)) {
+		t.Error("no XML declaration")
+	}
+	var ev WEREvent
+	if err := xml.Unmarshal(data, &ev); err != nil {
+		t.Fatalf("invalid XML: %v", err)
+	}
+	if ev.Title != "Test Open" || ev.StartDate != "2025-06-15" || ev.Location != "Test Venue" || ev.NumberOfRounds != 2 {
+		t.Errorf("event = %+v", ev)
+	}
+	if len(ev.Persons) != 4 {
+		t.Fatalf("%d persons, want 4", len(ev.Persons))
+	}
+	for i, p := range ev.Persons {
+		if p.Rank != i+1 {
+			t.Errorf("person %d has rank %d", i, p.Rank)
+		}
+	}
+	if len(ev.Rounds) != 2 || len(ev.Rounds[0].Matches) != 2 {
+		t.Fatalf("rounds = %+v", ev.Rounds)
+	}
+	m := ev.Rounds[1].Matches[0]
+	if m.Win != 2 || m.Loss != 1 || m.Outcome != "win" || m.Opponent == 0 {
+		t.Errorf("round 2 match = %+v", m)
+	}
+}
+
+func TestGenerateWER_PlayoffAndBye(t *testing.T) {
+	mt, eng := setupPlayoffTournament(t)
+	data, err := GenerateWER(mt, eng, nil)
+	if err != nil {
+		t.Fatalf("GenerateWER: %v", err)
+	}
+	var ev WEREvent
+	xml.Unmarshal(data, &ev)
+	last := ev.Rounds[len(ev.Rounds)-1]
+	if !last.Playoff || last.Number != 3 || last.Name != "Semifinals" || last.Matches[0].Outcome != "unreported" {
+		t.Errorf("playoff round = %+v", last)
+	}
+	if ev.TopCut != 4 {
+		t.Errorf("topcut = %d", ev.TopCut)
+	}
+
+	bye := swisstools.NewTournamentWithConfig(swisstools.TournamentConfig{PointsForWin: 3, ByeWins: swisstools.BYE_WINS})
+	for _, n := range []string{"Ann Marie Smith", "B", "C"} {
+		bye.AddPlayer(n)
+	}
+	bye.StartTournament()
+	data, _ = GenerateWER(mt, &bye, nil)
+	ev = WEREvent{}
+	xml.Unmarshal(data, &ev)
+	var sawBye bool
+	for _, m := range ev.Rounds[0].Matches {
+		if m.Outcome == "bye" && m.Opponent == 0 {
+			sawBye = true
+		}
+	}
+	if !sawBye {
+		t.Error("no bye in a three-player round")
+	}
+	for _, p := range ev.Persons {
+		if p.ID == 1 && (p.First != "Ann Marie" || p.Last != "Smith") {
+			t.Errorf("name split into %q %q", p.First, p.Last)
+		}
+	}
+}
+
+func TestSplitName(t *testing.T) {
+	for name, want := range map[string][2]string{
+		"Alice":             {"Alice", ""},
+		"Alice Smith":       {"Alice", "Smith"},
+		" Jean Luc Picard ": {"Jean Luc", "Picard"},
+	} {
+		if f, l := splitName(name); f != want[0] || l != want[1] {
+			t.Errorf("splitName(%q) = %q, %q", name, f, l)
+		}
+	}
+}
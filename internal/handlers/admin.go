@@ -2,28 +2,129 @@ package handlers
 
 import (
 	"database/sql"
+	"fmt"
+	"log/slog"
 	"net/http"
 	"strconv"
+	"strings"
 
+	"github.com/dstathis/openswiss/internal/auth"
 	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/engine"
 	"github.com/dstathis/openswiss/internal/middleware"
 	"github.com/dstathis/openswiss/internal/models"
+	"github.com/dstathis/openswiss/internal/readonly"
 	"github.com/go-chi/chi/v5"
 )
 
 type AdminHandler struct {
-	DB   *sql.DB
-	Tmpl TemplateRenderer
+	DB       *sql.DB
+	Tmpl     TemplateRenderer
+	ReadOnly *readonly.Mode
+	BaseURL  string
 }
 
 func (h *AdminHandler) UsersPage(w http.ResponseWriter, r *http.Request) {
+	h.renderUsers(w, r, nil)
+}
+
+func (h *AdminHandler) renderUsers(w http.ResponseWriter, r *http.Request, extra map[string]interface{}) {
 	users, _ := db.ListUsers(r.Context(), h.DB, 1, 100)
-	h.Tmpl.ExecuteTemplate(w, "admin_users.html", map[string]interface{}{
-		"User":  middleware.GetUser(r.Context()),
-		"Users": users,
+	requests, _ := db.ListDeletionRequests(r.Context(), h.DB)
+	data := map[string]interface{}{
+		"User":             middleware.GetUser(r.Context()),
+		"CSRFToken":        middleware.CSRFToken(r),
+		"Theme":            middleware.Theme(r),
+		"Flash":            middleware.Flash(r),
+		"Lang":             middleware.Locale(r),
+		"Users":            users,
+		"DeletionRequests": requests,
+	}
+	for k, v := range extra {
+		data[k] = v
+	}
+	h.Tmpl.ExecuteTemplate(w, "admin_users.html", data)
+}
+
+// IssueResetLink creates a one-time password reset link for a user, for the
+// admin to pass on when the user can't get the emailed one (or SMTP isn't
+// configured). It replaces any earlier link of theirs and is shown once.
+func (h *AdminHandler) IssueResetLink(w http.ResponseWriter, r *http.Request) {
+	userID, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	target, err := db.GetUserByID(r.Context(), h.DB, userID)
+	if err != nil {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+	rawToken, tokenHash, expiresAt, err := auth.NewPasswordReset(auth.IssuedResetLinkTTL)
+	if err != nil {
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	if err := db.CreatePasswordReset(r.Context(), h.DB, target.ID, tokenHash, expiresAt); err != nil {
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	slog.InfoContext(r.Context(), "password reset link issued by admin",
+		"user_id", target.ID, "admin_id", middleware.GetUser(r.Context()).ID)
+	h.renderUsers(w, r, map[string]interface{}{
+		"ResetLink":    h.BaseURL + auth.ResetPath(rawToken),
+		"ResetUser":    target,
+		"ResetExpires": expiresAt,
 	})
 }
 
+// AnonymizeUser removes a user's name from every tournament they played,
+// which then shows them as "Player #N", and unlinks those registrations
+// from the account (see engine.AnonymizeUser). The account stays.
+func (h *AdminHandler) AnonymizeUser(w http.ResponseWriter, r *http.Request) {
+	userID, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	target, err := db.GetUserByID(r.Context(), h.DB, userID)
+	if err != nil {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+	n, err := engine.AnonymizeUser(r.Context(), h.DB, target.ID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "anonymize user", "err", err, "user_id", target.ID)
+		middleware.SetFlash(w, r, middleware.FlashError, fmt.Sprintf("%s could not be anonymized, nothing was changed: %v", target.DisplayName, err))
+		http.Redirect(w, r, "/admin/users", http.StatusSeeOther)
+		return
+	}
+	slog.InfoContext(r.Context(), "user anonymized by admin",
+		"user_id", target.ID, "admin_id", middleware.GetUser(r.Context()).ID, "registrations", n)
+	middleware.SetFlash(w, r, middleware.FlashSuccess, fmt.Sprintf("Anonymized %s in %d tournaments.", target.DisplayName, n))
+	http.Redirect(w, r, "/admin/users", http.StatusSeeOther)
+}
+
+// DeleteUser anonymizes a user like AnonymizeUser and then deletes their
+// account, with everything in it (see engine.DeleteUser). Admins can't
+// delete themselves.
+func (h *AdminHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
+	userID, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	admin := middleware.GetUser(r.Context())
+	target, err := db.GetUserByID(r.Context(), h.DB, userID)
+	if err != nil {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+	if target.ID == admin.ID {
+		http.Error(w, "You can't delete your own account", http.StatusBadRequest)
+		return
+	}
+	n, err := engine.DeleteUser(r.Context(), h.DB, target.ID, admin.ID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "delete user", "err", err, "user_id", target.ID)
+		middleware.SetFlash(w, r, middleware.FlashError, fmt.Sprintf("%s could not be deleted, nothing was changed: %v", target.DisplayName, err))
+		http.Redirect(w, r, "/admin/users", http.StatusSeeOther)
+		return
+	}
+	slog.InfoContext(r.Context(), "user deleted by admin",
+		"user_id", target.ID, "admin_id", admin.ID, "registrations", n)
+	middleware.SetFlash(w, r, middleware.FlashSuccess, fmt.Sprintf("Deleted the account of %s and anonymized them in %d tournaments.", target.DisplayName, n))
+	http.Redirect(w, r, "/admin/users", http.StatusSeeOther)
+}
+
 func (h *AdminHandler) UpdateRole(w http.ResponseWriter, r *http.Request) {
 	userID, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
 	if err := r.ParseForm(); err != nil {
@@ -34,6 +135,101 @@ func (h *AdminHandler) UpdateRole(w http.ResponseWriter, r *http.Request) {
 	if len(roles) == 0 {
 		roles = []string{models.RolePlayer}
 	}
-	db.UpdateUserRoles(r.Context(), h.DB, userID, roles)
+	if err := db.UpdateUserRoles(r.Context(), h.DB, userID, roles); err != nil {
+		middleware.SetFlash(w, r, middleware.FlashError, "The roles could not be saved.")
+	} else {
+		middleware.SetFlash(w, r, middleware.FlashSuccess, "Roles saved: "+strings.Join(roles, ", ")+".")
+	}
 	http.Redirect(w, r, "/admin/users", http.StatusSeeOther)
 }
+
+// renderChangePassword shows the change password form, with errMsg tied to
+// the input named errField.
+func (h *AdminHandler) renderChangePassword(w http.ResponseWriter, r *http.Request, errMsg, errField, success string) {
+	h.Tmpl.ExecuteTemplate(w, "admin_change_password.html", map[string]interface{}{
+		"User":       middleware.GetUser(r.Context()),
+		"CSRFToken":  middleware.CSRFToken(r),
+		"Theme":      middleware.Theme(r),
+		"Flash":      middleware.Flash(r),
+		"Lang":       middleware.Locale(r),
+		"Error":      errMsg,
+		"ErrorField": errField,
+		"Success":    success,
+	})
+}
+
+func (h *AdminHandler) ChangePasswordPage(w http.ResponseWriter, r *http.Request) {
+	h.renderChangePassword(w, r, "", "", "")
+}
+
+// ChangePassword changes the signed-in admin's own password. The current
+// password is required, so a hijacked session alone can't lock the admin
+// out. Every other session of the account is logged out.
+func (h *AdminHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+	user := middleware.GetUser(r.Context())
+	current := r.FormValue("current_password")
+	password := r.FormValue("password")
+
+	switch {
+	case !auth.CheckPassword(user.PasswordHash, current):
+		h.renderChangePassword(w, r, "Current password is incorrect.", "current_password", "")
+		return
+	case len(password) < 8:
+		h.renderChangePassword(w, r, "Password must be at least 8 characters.", "password", "")
+		return
+	case password != r.FormValue("confirm_password"):
+		h.renderChangePassword(w, r, "Passwords do not match.", "confirm_password", "")
+		return
+	}
+
+	hash, err := auth.HashPassword(password)
+	if err != nil {
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	if err := db.UpdateUserPassword(r.Context(), h.DB, user.ID, hash); err != nil {
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	keep := ""
+	if cookie, err := r.Cookie("session"); err == nil {
+		keep = cookie.Value
+	}
+	db.DeleteOtherSessions(r.Context(), h.DB, user.ID, keep)
+	h.renderChangePassword(w, r, "", "", "Password changed. Other sessions have been logged out.")
+}
+
+// ReadOnlyPage shows whether the server is read-only and why.
+func (h *AdminHandler) ReadOnlyPage(w http.ResponseWriter, r *http.Request) {
+	h.Tmpl.ExecuteTemplate(w, "admin_read_only.html", map[string]interface{}{
+		"User":      middleware.GetUser(r.Context()),
+		"CSRFToken": middleware.CSRFToken(r),
+		"Theme":     middleware.Theme(r),
+		"Flash":     middleware.Flash(r),
+		"Lang":      middleware.Locale(r),
+		"Status":    h.ReadOnly.Status(),
+	})
+}
+
+// SetReadOnly switches manual read-only mode. Form fields: enabled
+// ("true" or "false"), reason. Automatic read-only mode can't be switched
+// off here; it ends once the database takes writes again.
+func (h *AdminHandler) SetReadOnly(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+	on := r.FormValue("enabled") == "true"
+	h.ReadOnly.Set(on, r.FormValue("reason"))
+	slog.Info("read-only mode set by admin", "enabled", on, "user_id", middleware.GetUser(r.Context()).ID)
+	if on {
+		middleware.SetFlash(w, r, middleware.FlashSuccess, "Read-only mode is on.")
+	} else {
+		middleware.SetFlash(w, r, middleware.FlashSuccess, "Read-only mode is off.")
+	}
+	http.Redirect(w, r, "/admin/read-only", http.StatusSeeOther)
+}
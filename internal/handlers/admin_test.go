@@ -4,14 +4,21 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"strconv"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/dstathis/openswiss/internal/auth"
 	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/engine"
 	"github.com/dstathis/openswiss/internal/models"
+	"github.com/dstathis/openswiss/internal/readonly"
+	"github.com/dstathis/swisstools"
 )
 
 func TestAdminHandler_UsersPage(t *testing.T) {
@@ -73,3 +80,226 @@ func TestAdminHandler_UpdateRole_EmptyDefaultsToPlayer(t *testing.T) {
 		t.Errorf("expected [player] default, got %v", got.Roles)
 	}
 }
+
+func TestAdminHandler_ChangePassword(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	tmpl := &mockTemplate{}
+	h := &AdminHandler{DB: database, Tmpl: tmpl}
+	admin := mustCreateUser(t, database, "admin@example.com", "Admin", models.RoleAdmin)
+	hash, _ := auth.HashPassword("old-password")
+	if err := db.UpdateUserPassword(ctx, database, admin.ID, hash); err != nil {
+		t.Fatal(err)
+	}
+	admin.PasswordHash = hash
+	expires := time.Now().Add(time.Hour)
+	db.CreateSession(ctx, database, "this-browser", admin.ID, expires)
+	db.CreateSession(ctx, database, "elsewhere", admin.ID, expires)
+
+	change := func(current, password, confirm string) string {
+		form := url.Values{"current_password": {current}, "password": {password}, "confirm_password": {confirm}}
+		req := requestWithUser("POST", "/", form.Encode(), admin, nil)
+		req.AddCookie(&http.Cookie{Name: "session", Value: "this-browser"})
+		h.ChangePassword(httptest.NewRecorder(), req)
+		return tmpl.calls[len(tmpl.calls)-1].Data.(map[string]interface{})["Error"].(string)
+	}
+
+	if msg := change("wrong-password", "new-password", "new-password"); msg == "" {
+		t.Error("wrong current password accepted")
+	}
+	if msg := change("old-password", "short", "short"); msg == "" {
+		t.Error("short password accepted")
+	}
+	if msg := change("old-password", "new-password", "other-password"); msg == "" {
+		t.Error("mismatched confirmation accepted")
+	}
+	if msg := change("old-password", "new-password", "new-password"); msg != "" {
+		t.Fatalf("change refused: %s", msg)
+	}
+
+	got, _ := db.GetUserByID(ctx, database, admin.ID)
+	if !auth.CheckPassword(got.PasswordHash, "new-password") {
+		t.Error("password not changed")
+	}
+	if _, err := db.GetSession(ctx, database, "this-browser"); err != nil {
+		t.Errorf("current session logged out: %v", err)
+	}
+	if _, err := db.GetSession(ctx, database, "elsewhere"); err == nil {
+		t.Error("other session survived the password change")
+	}
+}
+
+func TestAdminHandler_IssueResetLink(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	tmpl := &mockTemplate{}
+	h := &AdminHandler{DB: database, Tmpl: tmpl, BaseURL: "https://swiss.example"}
+	admin := mustCreateUser(t, database, "admin-reset@example.com", "AdminReset", models.RoleAdmin)
+	target := mustCreateUser(t, database, "judge-reset@example.com", "JudgeReset", models.RoleOrganizer)
+	db.CreateSession(ctx, database, "judge-browser", target.ID, time.Now().Add(time.Hour))
+	for i := 0; i < db.AuthLockoutThreshold; i++ {
+		db.RecordFailedLogin(ctx, database, target.ID)
+	}
+
+	rec := httptest.NewRecorder()
+	h.IssueResetLink(rec, requestWithUser("POST", "/", "", admin, map[string]string{"id": strconv.FormatInt(target.ID, 10)}))
+	data := tmpl.calls[len(tmpl.calls)-1].Data.(map[string]interface{})
+	link, _ := data["ResetLink"].(string)
+	const prefix = "https://swiss.example/reset-password?token="
+	if !strings.HasPrefix(link, prefix) {
+		t.Fatalf("reset link = %q", link)
+	}
+	if expires := data["ResetExpires"].(time.Time); expires.Before(time.Now().Add(auth.IssuedResetLinkTTL - time.Minute)) {
+		t.Errorf("link expires at %v", expires)
+	}
+
+	// The link works like an emailed one, logs the user out everywhere and
+	// lifts their lockout.
+	authH := &AuthHandler{DB: database, Tmpl: &mockTemplate{}}
+	form := url.Values{"token": {strings.TrimPrefix(link, prefix)}, "password": {"brand-new-pass"}, "confirm_password": {"brand-new-pass"}}
+	authH.ResetPassword(httptest.NewRecorder(), requestWithUser("POST", "/", form.Encode(), nil, nil))
+	got, _ := db.GetUserByID(ctx, database, target.ID)
+	if !auth.CheckPassword(got.PasswordHash, "brand-new-pass") {
+		t.Error("password not reset")
+	}
+	if got.IsLocked(time.Now()) || got.FailedLoginAttempts != 0 {
+		t.Errorf("lockout survived the reset: %d attempts, locked until %v", got.FailedLoginAttempts, got.LockedUntil)
+	}
+	if _, err := db.GetSession(ctx, database, "judge-browser"); err == nil {
+		t.Error("session survived the reset")
+	}
+	if _, err := db.GetPasswordResetByTokenHash(ctx, database, auth.HashResetToken(form.Get("token"))); err == nil {
+		t.Error("reset link works twice")
+	}
+
+	rec = httptest.NewRecorder()
+	h.IssueResetLink(rec, requestWithUser("POST", "/", "", admin, map[string]string{"id": "999999"}))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("unknown user: status %d, want 404", rec.Code)
+	}
+}
+
+func TestAdminHandler_ReadOnly(t *testing.T) {
+	database := testDB(t)
+	tmpl := &mockTemplate{}
+	mode := readonly.New(readonly.Config{})
+	h := &AdminHandler{DB: database, Tmpl: tmpl, ReadOnly: mode}
+	admin := mustCreateUser(t, database, "admin@example.com", "Admin", models.RoleAdmin)
+
+	form := url.Values{"enabled": {"true"}, "reason": {"moving venues"}}
+	rec := httptest.NewRecorder()
+	h.SetReadOnly(rec, requestWithUser("POST", "/", form.Encode(), admin, nil))
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("enable: status %d", rec.Code)
+	}
+	if s := mode.Status(); !s.Manual || s.Reason != "moving venues" {
+		t.Errorf("status after enable = %+v", s)
+	}
+
+	h.ReadOnlyPage(httptest.NewRecorder(), requestWithUser("GET", "/", "", admin, nil))
+	if tmpl.calls[0].Name != "admin_read_only.html" {
+		t.Errorf("template = %q", tmpl.calls[0].Name)
+	}
+	if s := tmpl.calls[0].Data.(map[string]interface{})["Status"].(readonly.Status); !s.Enabled {
+		t.Errorf("page status = %+v", s)
+	}
+
+	rec = httptest.NewRecorder()
+	h.SetReadOnly(rec, requestWithUser("POST", "/", "enabled=false", admin, nil))
+	if mode.Status().Enabled {
+		t.Error("still read-only after disable")
+	}
+}
+
+func TestAdminHandler_AnonymizeAndDeleteUser(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	h := &AdminHandler{DB: database, Tmpl: &mockTemplate{}}
+	admin := mustCreateUser(t, database, "admin-gdpr@example.com", "AdminGDPR", models.RoleAdmin)
+	_, tourn := startedTournament(t, database)
+	if _, err := db.SnapshotTournament(ctx, database, tourn.ID, models.SnapshotRound, 1); err != nil {
+		t.Fatal(err)
+	}
+	regs, _ := db.ListRegistrations(ctx, database, tourn.ID)
+	reg := regs[0]
+	userID := *reg.UserID
+	params := map[string]string{"id": strconv.FormatInt(userID, 10)}
+	upcoming := mustCreateTournament(t, database, admin.ID, models.TournamentStatusRegistrationOpen)
+	if _, err := db.CreateRegistration(ctx, database, upcoming.ID, userID, reg.DisplayName); err != nil {
+		t.Fatal(err)
+	}
+	created := mustCreateTournament(t, database, userID, models.TournamentStatusScheduled)
+	contact := "player-gdpr@example.com"
+	entry := &models.Player{Name: reg.DisplayName, Contact: &contact}
+	if err := db.CreatePlayer(ctx, database, entry); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := database.ExecContext(ctx, `UPDATE registrations SET player_id = $1 WHERE id = $2`, entry.ID, reg.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	// Anonymizing renames the player where they played, in the engine too,
+	// and drops the snapshots that still hold their name.
+	rec := httptest.NewRecorder()
+	h.AnonymizeUser(rec, requestWithUser("POST", "/", "", admin, params))
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("anonymize: status %d body %s", rec.Code, rec.Body.String())
+	}
+	name := engine.AnonymousName(reg.ID)
+	got, _ := db.GetRegistrationByID(ctx, database, reg.ID)
+	if got.DisplayName != name || got.UserID != nil || got.GuestName == nil || got.EnginePlayerID == nil {
+		t.Errorf("anonymized registration = %+v", got)
+	}
+	updated, _ := db.GetTournament(ctx, database, tourn.ID)
+	eng, err := swisstools.LoadTournament(updated.EngineState)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p, _ := eng.GetPlayerById(*reg.EnginePlayerID); p.Name != name {
+		t.Errorf("engine name = %q, want %q", p.Name, name)
+	}
+	if snaps, _ := db.ListSnapshots(ctx, database, tourn.ID); len(snaps) != 0 {
+		t.Errorf("%d snapshots left", len(snaps))
+	}
+	if _, err := db.GetPlayer(ctx, database, entry.ID); !errors.Is(err, db.ErrPlayerNotFound) {
+		t.Errorf("registry entry: err = %v, want db.ErrPlayerNotFound", err)
+	}
+	if _, err := db.GetRegistration(ctx, database, upcoming.ID, userID); err != nil {
+		t.Errorf("upcoming sign-up: %v", err)
+	}
+	if _, err := db.GetUserByID(ctx, database, userID); err != nil {
+		t.Errorf("account gone after anonymizing: %v", err)
+	}
+
+	rec = httptest.NewRecorder()
+	h.DeleteUser(rec, requestWithUser("POST", "/", "", admin, map[string]string{"id": strconv.FormatInt(admin.ID, 10)}))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("deleting yourself: status %d, want 400", rec.Code)
+	}
+
+	// Deleting takes the account and its upcoming sign-ups; the tournaments
+	// it created pass to the admin.
+	rec = httptest.NewRecorder()
+	h.DeleteUser(rec, requestWithUser("POST", "/", "", admin, params))
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("delete: status %d body %s", rec.Code, rec.Body.String())
+	}
+	if _, err := db.GetUserByID(ctx, database, userID); err == nil {
+		t.Error("account still there after deleting")
+	}
+	if _, err := db.GetRegistration(ctx, database, upcoming.ID, userID); err == nil {
+		t.Error("upcoming sign-up still there after deleting")
+	}
+	if got, _ := db.GetTournament(ctx, database, created.ID); got == nil || got.OrganizerID != admin.ID {
+		t.Errorf("created tournament = %+v, want organizer %d", got, admin.ID)
+	}
+	if got, _ := db.GetRegistrationByID(ctx, database, reg.ID); got == nil || got.DisplayName != name {
+		t.Errorf("anonymized registration after deleting = %+v", got)
+	}
+
+	rec = httptest.NewRecorder()
+	h.DeleteUser(rec, requestWithUser("POST", "/", "", admin, params))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("deleting again: status %d, want 404", rec.Code)
+	}
+}
@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/engine"
+	"github.com/dstathis/openswiss/internal/middleware"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/go-chi/chi/v5"
+)
+
+// AnalyticsPage shows how many spectators followed the tournament: the
+// most watching at once, how many are watching now, and the views of its
+// public pages per round. In series mode it sums up the games played too
+// (see engine.SummarizeGames). Min tier: Co-organizer.
+func (h *TournamentHandler) AnalyticsPage(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if !middleware.AuthorizeTournament(w, r, h.DB, id, models.TierCoOrganizer) {
+		return
+	}
+	t, err := db.GetTournament(r.Context(), h.DB, id)
+	if err != nil {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+	stats, err := db.GetAnalytics(r.Context(), h.DB, id)
+	if err != nil {
+		http.Error(w, "Failed to load analytics", http.StatusInternalServerError)
+		return
+	}
+	stats.Viewers = h.Views.Viewers(id)
+	data := map[string]interface{}{
+		"User":       middleware.GetUser(r.Context()),
+		"CSRFToken":  middleware.CSRFToken(r),
+		"Theme":      middleware.Theme(r),
+		"Flash":      middleware.Flash(r),
+		"Lang":       middleware.Locale(r),
+		"Tournament": t,
+		"Analytics":  stats,
+	}
+	if t.SeriesMode {
+		games, err := db.ListAllMatchGames(r.Context(), h.DB, id)
+		if err != nil {
+			http.Error(w, "Failed to load games", http.StatusInternalServerError)
+			return
+		}
+		data["Games"] = engine.SummarizeGames(games)
+	}
+	h.Tmpl.ExecuteTemplate(w, "tournament_analytics.html", data)
+}
@@ -0,0 +1,52 @@
+//go:build integration
+
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/dstathis/openswiss/internal/analytics"
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+func TestTournamentHandler_Analytics(t *testing.T) {
+	database := testDB(t)
+	tmpl := &mockTemplate{}
+	h := &TournamentHandler{DB: database, Tmpl: tmpl, Views: analytics.New(database, time.Minute)}
+	owner := mustCreateUser(t, database, "owner-analytics@example.com", "OwnerAnalytics")
+	tourn := mustCreateTournament(t, database, owner.ID, models.TournamentStatusRegistrationOpen)
+	params := map[string]string{"id": strconv.FormatInt(tourn.ID, 10)}
+
+	// Two spectators, one of them reloading.
+	for _, ua := range []string{"phone", "phone", "laptop"} {
+		req := requestWithUser("GET", "/", "", nil, params)
+		req.Header.Set("User-Agent", ua)
+		h.Detail(httptest.NewRecorder(), req)
+	}
+	if err := h.Views.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	tmpl.calls = nil
+	rec := httptest.NewRecorder()
+	h.AnalyticsPage(rec, requestWithUser("GET", "/", "", owner, params))
+	if rec.Code != http.StatusOK || len(tmpl.calls) != 1 {
+		t.Fatalf("status %d, %d renders", rec.Code, len(tmpl.calls))
+	}
+	a := tmpl.calls[0].Data.(map[string]interface{})["Analytics"].(*models.Analytics)
+	if a.Views != 2 || a.Viewers != 2 || a.PeakViewers != 2 || len(a.Rounds) != 1 || a.Rounds[0].Tournament != 2 {
+		t.Errorf("analytics = %+v, want 2 views and 2 viewers before round 1", a)
+	}
+
+	player := mustCreateUser(t, database, "player-analytics@example.com", "PlayerAnalytics")
+	rec = httptest.NewRecorder()
+	h.AnalyticsPage(rec, requestWithUser("GET", "/", "", player, params))
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("player: status %d, want 403", rec.Code)
+	}
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/engine"
+	"github.com/dstathis/openswiss/internal/middleware"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/go-chi/chi/v5"
+)
+
+// activeAnnouncements returns the announcements the banner of a tournament
+// page shows. A failure to load them shows none rather than failing the
+// page.
+func activeAnnouncements(ctx context.Context, database *sql.DB, tournamentID int64) []models.Announcement {
+	as, _ := db.ActiveAnnouncements(ctx, database, tournamentID, time.Now())
+	return as
+}
+
+// Announce posts an announcement from the dashboard. Form fields: message,
+// and minutes to show it for (blank or 0: until ended). Min tier: Judge.
+func (h *TournamentHandler) Announce(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if !middleware.AuthorizeTournament(w, r, h.DB, id, models.TierJudge) {
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+	minutes := 0
+	if v := r.FormValue("minutes"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			http.Error(w, "Minutes must be a whole number", http.StatusBadRequest)
+			return
+		}
+		minutes = n
+	}
+	user := middleware.GetUser(r.Context())
+	if _, err := engine.Announce(r.Context(), h.DB, id, user.ID, r.FormValue("message"), minutes); err != nil {
+		if errors.Is(err, engine.ErrAnnouncementsClosed) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	http.Redirect(w, r, fmt.Sprintf("/tournaments/%d/manage#announcements", id), http.StatusSeeOther)
+}
+
+// EndAnnouncement stops showing an announcement before it expires. Min
+// tier: Judge.
+func (h *TournamentHandler) EndAnnouncement(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if !middleware.AuthorizeTournament(w, r, h.DB, id, models.TierJudge) {
+		return
+	}
+	aid, _ := strconv.ParseInt(chi.URLParam(r, "announcementID"), 10, 64)
+	if err := db.EndAnnouncement(r.Context(), h.DB, id, aid, time.Now()); err != nil {
+		if errors.Is(err, db.ErrAnnouncementNotFound) {
+			http.Error(w, "Not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Failed to end the announcement", http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, r, fmt.Sprintf("/tournaments/%d/manage#announcements", id), http.StatusSeeOther)
+}
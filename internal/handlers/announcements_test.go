@@ -0,0 +1,66 @@
+//go:build integration
+
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+func TestTournamentHandler_Announcements(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	tmpl := &mockTemplate{}
+	h := &TournamentHandler{DB: database, Tmpl: tmpl}
+	owner, tourn := startedTournament(t, database)
+	params := map[string]string{"id": strconv.FormatInt(tourn.ID, 10)}
+
+	post := func(handler http.HandlerFunc, user *models.User, form url.Values, params map[string]string) int {
+		rec := httptest.NewRecorder()
+		handler(rec, requestWithUser("POST", "/", form.Encode(), user, params))
+		return rec.Code
+	}
+	stranger := mustCreateUser(t, database, "stranger-announce@example.com", "StrangerAnnounce")
+	if code := post(h.Announce, stranger, url.Values{"message": {"Hi"}}, params); code != http.StatusForbidden {
+		t.Errorf("stranger: status %d, want 403", code)
+	}
+	if code := post(h.Announce, owner, url.Values{"message": {"Delay"}, "minutes": {"soon"}}, params); code != http.StatusBadRequest {
+		t.Errorf("bad minutes: status %d, want 400", code)
+	}
+	if code := post(h.Announce, owner, url.Values{"message": {"Round 2 is delayed 10 minutes"}}, params); code != http.StatusSeeOther {
+		t.Fatalf("announce: status %d", code)
+	}
+	active, _ := db.ActiveAnnouncements(ctx, database, tourn.ID, time.Now())
+	if len(active) != 1 || active[0].ExpiresAt != nil {
+		t.Fatalf("active = %+v", active)
+	}
+
+	rec := httptest.NewRecorder()
+	h.Detail(rec, requestWithUser("GET", "/", "", nil, params))
+	data := tmpl.calls[len(tmpl.calls)-1].Data.(map[string]interface{})
+	if as, _ := data["Announcements"].([]models.Announcement); len(as) != 1 {
+		t.Errorf("detail page announcements = %v", data["Announcements"])
+	}
+
+	endParams := map[string]string{"id": params["id"], "announcementID": strconv.FormatInt(active[0].ID, 10)}
+	if code := post(h.EndAnnouncement, owner, nil, endParams); code != http.StatusSeeOther {
+		t.Errorf("end: status %d", code)
+	}
+	if code := post(h.EndAnnouncement, owner, nil, endParams); code != http.StatusNotFound {
+		t.Errorf("end twice: status %d, want 404", code)
+	}
+	rec = httptest.NewRecorder()
+	h.ManagePage(rec, requestWithUser("GET", "/", "", owner, params))
+	data = tmpl.calls[len(tmpl.calls)-1].Data.(map[string]interface{})
+	if as, _ := data["AnnouncementHistory"].([]models.Announcement); len(as) != 1 || as[0].ExpiresAt == nil {
+		t.Errorf("history = %v", data["AnnouncementHistory"])
+	}
+}
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/dstathis/openswiss/internal/auth"
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/middleware"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/go-chi/chi/v5"
+)
+
+// APIKeysPage lists every user's API keys, with a form to create one.
+func (h *AdminHandler) APIKeysPage(w http.ResponseWriter, r *http.Request) {
+	h.renderAPIKeys(w, r, nil)
+}
+
+func (h *AdminHandler) renderAPIKeys(w http.ResponseWriter, r *http.Request, extra map[string]interface{}) {
+	keys, _ := db.ListAllAPIKeys(r.Context(), h.DB)
+	data := map[string]interface{}{
+		"User":      middleware.GetUser(r.Context()),
+		"CSRFToken": middleware.CSRFToken(r),
+		"Theme":     middleware.Theme(r),
+		"Flash":     middleware.Flash(r),
+		"Lang":      middleware.Locale(r),
+		"Keys":      keys,
+	}
+	for k, v := range extra {
+		data[k] = v
+	}
+	h.Tmpl.ExecuteTemplate(w, "admin_api_keys.html", data)
+}
+
+// CreateAPIKey creates an API key for the account with the given email,
+// such as a service account for a results-entry script. Form fields:
+// email, name, scope. The full key is shown once.
+func (h *AdminHandler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+	name := strings.TrimSpace(r.FormValue("name"))
+	scope := r.FormValue("scope")
+	owner, err := db.GetUserByEmail(r.Context(), h.DB, strings.TrimSpace(r.FormValue("email")))
+	switch {
+	case err != nil:
+		h.renderAPIKeys(w, r, map[string]interface{}{"Error": "No account has that email.", "ErrorField": "email"})
+		return
+	case name == "":
+		h.renderAPIKeys(w, r, map[string]interface{}{"Error": "Name is required.", "ErrorField": "name"})
+		return
+	case !models.ValidAPIKeyScope(scope):
+		h.renderAPIKeys(w, r, map[string]interface{}{"Error": "Choose read or read-write access.", "ErrorField": "scope"})
+		return
+	}
+	fullKey, prefix, hash, err := auth.NewAPIKey()
+	if err != nil {
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	key, err := db.CreateAPIKey(r.Context(), h.DB, owner.ID, hash, prefix, name, scope, nil)
+	if err != nil {
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	slog.InfoContext(r.Context(), "API key created by admin",
+		"key_id", key.ID, "user_id", owner.ID, "scope", scope, "admin_id", middleware.GetUser(r.Context()).ID)
+	h.renderAPIKeys(w, r, map[string]interface{}{"NewKey": fullKey, "NewKeyOwner": owner})
+}
+
+// RevokeAPIKey deletes any user's API key.
+func (h *AdminHandler) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	keyID, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err := db.RevokeAPIKey(r.Context(), h.DB, keyID); err != nil {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+	slog.InfoContext(r.Context(), "API key revoked by admin", "key_id", keyID, "admin_id", middleware.GetUser(r.Context()).ID)
+	middleware.SetFlash(w, r, middleware.FlashSuccess, "API key revoked.")
+	http.Redirect(w, r, "/admin/api-keys", http.StatusSeeOther)
+}
@@ -0,0 +1,57 @@
+//go:build integration
+
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+func TestAdminHandler_APIKeys(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	tmpl := &mockTemplate{}
+	h := &AdminHandler{DB: database, Tmpl: tmpl}
+	admin := mustCreateUser(t, database, "admin-keys@example.com", "AdminKeys", models.RoleAdmin)
+	bot := mustCreateUser(t, database, "scanner@example.com", "Scanner")
+	create := func(form url.Values) map[string]interface{} {
+		h.CreateAPIKey(httptest.NewRecorder(), requestWithUser("POST", "/", form.Encode(), admin, nil))
+		return tmpl.calls[len(tmpl.calls)-1].Data.(map[string]interface{})
+	}
+
+	data := create(url.Values{"email": {bot.Email}, "name": {"scanner"}, "scope": {models.APIKeyScopeRead}})
+	if key, _ := data["NewKey"].(string); !strings.HasPrefix(key, "os_") {
+		t.Fatalf("new key = %v, error %v", data["NewKey"], data["Error"])
+	}
+	keys, _ := db.ListAPIKeysByUser(ctx, database, bot.ID)
+	if len(keys) != 1 || keys[0].Scope != models.APIKeyScopeRead {
+		t.Fatalf("bot's keys = %+v", keys)
+	}
+
+	for _, form := range []url.Values{
+		{"email": {"nobody@example.com"}, "name": {"x"}, "scope": {"read"}},
+		{"email": {bot.Email}, "name": {" "}, "scope": {"read"}},
+		{"email": {bot.Email}, "name": {"x"}, "scope": {"everything"}},
+	} {
+		if data := create(form); data["Error"] == nil || data["NewKey"] != nil {
+			t.Errorf("%v: accepted", form)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	h.RevokeAPIKey(rec, requestWithUser("POST", "/", "", admin, map[string]string{"id": strconv.FormatInt(keys[0].ID, 10)}))
+	if rec.Code != http.StatusSeeOther {
+		t.Errorf("revoke: status %d, want 303", rec.Code)
+	}
+	if keys, _ := db.ListAPIKeysByUser(ctx, database, bot.ID); len(keys) != 0 {
+		t.Errorf("key survived revoking: %+v", keys)
+	}
+}
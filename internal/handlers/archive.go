@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/middleware"
+	"github.com/go-chi/chi/v5"
+)
+
+// ArchiveHandler serves the public archive of complete tournaments and
+// their final standings, as they stood when each one finished.
+type ArchiveHandler struct {
+	DB   *sql.DB
+	Tmpl TemplateRenderer
+}
+
+// List shows every archived tournament, most recent first. With ?q= it
+// shows instead the finishes of players whose name contains q, across
+// every archived tournament.
+func (h *ArchiveHandler) List(w http.ResponseWriter, r *http.Request) {
+	q := nameQuery(r)
+	data := map[string]interface{}{
+		"User":      middleware.GetUser(r.Context()),
+		"CSRFToken": middleware.CSRFToken(r),
+		"Theme":     middleware.Theme(r),
+		"Flash":     middleware.Flash(r),
+		"Lang":      middleware.Locale(r),
+		"Query":     q,
+	}
+	if q != "" {
+		found, err := db.SearchArchive(r.Context(), h.DB, q)
+		if err != nil {
+			http.Error(w, "Failed to search the archive", http.StatusInternalServerError)
+			return
+		}
+		data["Results"] = found
+	} else {
+		events, err := db.ListArchivedEvents(r.Context(), h.DB)
+		if err != nil {
+			http.Error(w, "Failed to load the archive", http.StatusInternalServerError)
+			return
+		}
+		data["Events"] = events
+	}
+	h.Tmpl.ExecuteTemplate(w, "archive.html", data)
+}
+
+// Detail shows an archived tournament's final standings.
+func (h *ArchiveHandler) Detail(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	e, err := db.GetArchivedEvent(r.Context(), h.DB, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		http.Error(w, "Failed to load the archive", http.StatusInternalServerError)
+		return
+	}
+	results, err := db.ListArchivedResults(r.Context(), h.DB, id)
+	if err != nil {
+		http.Error(w, "Failed to load the archive", http.StatusInternalServerError)
+		return
+	}
+	h.Tmpl.ExecuteTemplate(w, "archive_event.html", map[string]interface{}{
+		"User":      middleware.GetUser(r.Context()),
+		"CSRFToken": middleware.CSRFToken(r),
+		"Theme":     middleware.Theme(r),
+		"Flash":     middleware.Flash(r),
+		"Lang":      middleware.Locale(r),
+		"Event":     e,
+		"Results":   results,
+		"Playoff":   len(results) > 0 && results[0].Playoff != "",
+	})
+}
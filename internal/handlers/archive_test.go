@@ -0,0 +1,71 @@
+//go:build integration
+
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/engine"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/dstathis/swisstools"
+)
+
+func TestArchiveHandler(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	tmpl := &mockTemplate{}
+	h := &ArchiveHandler{DB: database, Tmpl: tmpl}
+
+	_, tourn := startedTournament(t, database)
+	params := map[string]string{"id": strconv.FormatInt(tourn.ID, 10)}
+	rec := httptest.NewRecorder()
+	h.Detail(rec, requestWithUser("GET", "/", "", nil, params))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("before the finish: status %d, want 404", rec.Code)
+	}
+
+	if err := engine.WithTournamentEngine(ctx, database, tourn.ID,
+		func(tx *sql.Tx, tm *models.Tournament, eng *swisstools.Tournament) (string, error) {
+			return models.TournamentStatusFinished, eng.FinishTournament()
+		}); err != nil {
+		t.Fatalf("finish: %v", err)
+	}
+
+	rec = httptest.NewRecorder()
+	h.Detail(rec, requestWithUser("GET", "/", "", nil, params))
+	if rec.Code != http.StatusOK || len(tmpl.calls) != 1 {
+		t.Fatalf("detail: status %d, %d renders", rec.Code, len(tmpl.calls))
+	}
+	results := tmpl.calls[0].Data.(map[string]interface{})["Results"].([]models.ArchivedResult)
+	if len(results) != 4 || results[0].Place != 1 || results[0].UserID == nil {
+		t.Fatalf("results = %+v, want 4 places with their accounts", results)
+	}
+
+	rec = httptest.NewRecorder()
+	h.List(rec, requestWithUser("GET", "/", "", nil, nil))
+	events := tmpl.calls[1].Data.(map[string]interface{})["Events"].([]models.ArchivedEvent)
+	if len(events) != 1 || events[0].TournamentID != tourn.ID || events[0].Winner != results[0].Name {
+		t.Errorf("events = %+v, want the tournament won by %s", events, results[0].Name)
+	}
+
+	rec = httptest.NewRecorder()
+	h.List(rec, requestWithUser("GET", "/?q="+results[2].Name, "", nil, nil))
+	found := tmpl.calls[2].Data.(map[string]interface{})["Results"].([]models.ArchivedResult)
+	if len(found) != 1 || found[0].Place != 3 || found[0].Tournament != tourn.Name {
+		t.Errorf("search = %+v, want %s's third place", found, results[2].Name)
+	}
+
+	// Resetting the tournament takes it out of the archive.
+	if err := engine.Reset(ctx, database, tourn.ID); err != nil {
+		t.Fatalf("reset: %v", err)
+	}
+	if events, _ := db.ListArchivedEvents(ctx, database); len(events) != 0 {
+		t.Errorf("events after reset = %+v", events)
+	}
+}
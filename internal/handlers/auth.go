@@ -7,11 +7,13 @@ import (
 	"net/http"
 	"regexp"
 	"time"
+	"unicode/utf8"
 
 	"github.com/dstathis/openswiss/internal/auth"
 	"github.com/dstathis/openswiss/internal/db"
 	"github.com/dstathis/openswiss/internal/email"
 	"github.com/dstathis/openswiss/internal/middleware"
+	"github.com/dstathis/openswiss/internal/models"
 )
 
 var emailRegexp = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`)
@@ -56,6 +58,9 @@ func (h *AuthHandler) LoginPage(w http.ResponseWriter, r *http.Request) {
 	h.Tmpl.ExecuteTemplate(w, "login.html", map[string]interface{}{
 		"User":      middleware.GetUser(r.Context()),
 		"CSRFToken": middleware.CSRFToken(r),
+		"Theme":     middleware.Theme(r),
+		"Flash":     middleware.Flash(r),
+		"Lang":      middleware.Locale(r),
 	})
 }
 
@@ -70,8 +75,13 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 
 	genericFail := func() {
 		h.Tmpl.ExecuteTemplate(w, "login.html", map[string]interface{}{
-			"Error":     "Invalid email or password.",
-			"CSRFToken": middleware.CSRFToken(r),
+			"Error":      "Invalid email or password.",
+			"ErrorField": "email",
+			"Email":      addr,
+			"CSRFToken":  middleware.CSRFToken(r),
+			"Theme":      middleware.Theme(r),
+			"Flash":      middleware.Flash(r),
+			"Lang":       middleware.Locale(r),
 		})
 	}
 
@@ -114,6 +124,9 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 			"Error":           "Please verify your email address before logging in.",
 			"UnverifiedEmail": addr,
 			"CSRFToken":       middleware.CSRFToken(r),
+			"Theme":           middleware.Theme(r),
+			"Flash":           middleware.Flash(r),
+			"Lang":            middleware.Locale(r),
 		})
 		return
 	}
@@ -153,6 +166,9 @@ func (h *AuthHandler) RegisterPage(w http.ResponseWriter, r *http.Request) {
 	h.Tmpl.ExecuteTemplate(w, "register.html", map[string]interface{}{
 		"User":      middleware.GetUser(r.Context()),
 		"CSRFToken": middleware.CSRFToken(r),
+		"Theme":     middleware.Theme(r),
+		"Flash":     middleware.Flash(r),
+		"Lang":      middleware.Locale(r),
 	})
 }
 
@@ -163,55 +179,65 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 	}
 
 	email := r.FormValue("email")
-	displayName := r.FormValue("display_name")
+	displayName := models.NormalizePlayerName(r.FormValue("display_name"))
 	password := r.FormValue("password")
 	confirmPassword := r.FormValue("confirm_password")
 
-	if email == "" || displayName == "" || password == "" {
+	// fail shows the form again with the error tied to the field at fault
+	// and what was typed kept, minus the passwords.
+	fail := func(msg, field string) {
 		h.Tmpl.ExecuteTemplate(w, "register.html", map[string]interface{}{
-			"Error":     "All fields are required.",
-			"CSRFToken": middleware.CSRFToken(r),
+			"Error":       msg,
+			"ErrorField":  field,
+			"Email":       email,
+			"DisplayName": displayName,
+			"CSRFToken":   middleware.CSRFToken(r),
+			"Theme":       middleware.Theme(r),
+			"Flash":       middleware.Flash(r),
+			"Lang":        middleware.Locale(r),
 		})
+	}
+
+	if email == "" || displayName == "" || password == "" {
+		field := "password"
+		switch {
+		case displayName == "":
+			field = "display_name"
+		case email == "":
+			field = "email"
+		}
+		fail("All fields are required.", field)
 		return
 	}
 
 	if !emailRegexp.MatchString(email) {
-		h.Tmpl.ExecuteTemplate(w, "register.html", map[string]interface{}{
-			"Error":     "Please enter a valid email address.",
-			"CSRFToken": middleware.CSRFToken(r),
-		})
+		fail("Please enter a valid email address.", "email")
 		return
 	}
 
 	if len(email) > 254 {
-		h.Tmpl.ExecuteTemplate(w, "register.html", map[string]interface{}{
-			"Error":     "Email address is too long.",
-			"CSRFToken": middleware.CSRFToken(r),
-		})
+		fail("Email address is too long.", "email")
 		return
 	}
 
-	if len(displayName) > 100 {
-		h.Tmpl.ExecuteTemplate(w, "register.html", map[string]interface{}{
-			"Error":     "Display name must be 100 characters or fewer.",
-			"CSRFToken": middleware.CSRFToken(r),
-		})
+	if utf8.RuneCountInString(displayName) > models.MaxPlayerNameLength {
+		fail("Display name must be 100 characters or fewer.", "display_name")
+		return
+	}
+
+	// The display name is the account's name in every tournament it enters.
+	if _, err := models.ValidatePlayerName(displayName); err != nil {
+		fail("That display name can't be used. Please choose another.", "display_name")
 		return
 	}
 
 	if len(password) < 8 {
-		h.Tmpl.ExecuteTemplate(w, "register.html", map[string]interface{}{
-			"Error":     "Password must be at least 8 characters.",
-			"CSRFToken": middleware.CSRFToken(r),
-		})
+		fail("Password must be at least 8 characters.", "password")
 		return
 	}
 
 	if password != confirmPassword {
-		h.Tmpl.ExecuteTemplate(w, "register.html", map[string]interface{}{
-			"Error":     "Passwords do not match.",
-			"CSRFToken": middleware.CSRFToken(r),
-		})
+		fail("Passwords do not match.", "confirm_password")
 		return
 	}
 
@@ -223,12 +249,15 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 
 	user, err := db.CreateUser(r.Context(), h.DB, email, displayName, hash)
 	if err != nil {
-		h.Tmpl.ExecuteTemplate(w, "register.html", map[string]interface{}{
-			"Error":     "Email or display name already taken.",
-			"CSRFToken": middleware.CSRFToken(r),
-		})
+		fail("Email or display name already taken.", "email")
 		return
 	}
+	// The avatar is optional, so a failure to save it doesn't fail sign-up.
+	if icon := r.FormValue("icon"); models.ValidAvatarIcon(icon) {
+		if err := db.SetAvatar(r.Context(), h.DB, &models.Avatar{UserID: user.ID, Icon: icon}); err != nil {
+			slog.ErrorContext(r.Context(), "set avatar", "err", err, "user_id", user.ID)
+		}
+	}
 
 	// When SMTP is configured, send a verification email and stop short of
 	// auto-login — the user must click the link first.
@@ -239,6 +268,9 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 		h.Tmpl.ExecuteTemplate(w, "check_email.html", map[string]interface{}{
 			"Email":     user.Email,
 			"CSRFToken": middleware.CSRFToken(r),
+			"Theme":     middleware.Theme(r),
+			"Flash":     middleware.Flash(r),
+			"Lang":      middleware.Locale(r),
 		})
 		return
 	}
@@ -283,6 +315,9 @@ func (h *AuthHandler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
 		h.Tmpl.ExecuteTemplate(w, "verify_email.html", map[string]interface{}{
 			"Error":     "Missing verification token.",
 			"CSRFToken": middleware.CSRFToken(r),
+			"Theme":     middleware.Theme(r),
+			"Flash":     middleware.Flash(r),
+			"Lang":      middleware.Locale(r),
 		})
 		return
 	}
@@ -294,6 +329,9 @@ func (h *AuthHandler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
 			"Error":      "Invalid or expired verification link. Request a new one below.",
 			"ShowResend": true,
 			"CSRFToken":  middleware.CSRFToken(r),
+			"Theme":      middleware.Theme(r),
+			"Flash":      middleware.Flash(r),
+			"Lang":       middleware.Locale(r),
 		})
 		return
 	}
@@ -308,6 +346,9 @@ func (h *AuthHandler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
 	h.Tmpl.ExecuteTemplate(w, "verify_email.html", map[string]interface{}{
 		"Success":   "Email verified. You can now log in.",
 		"CSRFToken": middleware.CSRFToken(r),
+		"Theme":     middleware.Theme(r),
+		"Flash":     middleware.Flash(r),
+		"Lang":      middleware.Locale(r),
 	})
 }
 
@@ -329,6 +370,9 @@ func (h *AuthHandler) ResendVerification(w http.ResponseWriter, r *http.Request)
 	successData := map[string]interface{}{
 		"Success":   "If an unverified account exists for that email, a new link has been sent.",
 		"CSRFToken": middleware.CSRFToken(r),
+		"Theme":     middleware.Theme(r),
+		"Flash":     middleware.Flash(r),
+		"Lang":      middleware.Locale(r),
 	}
 
 	user, err := db.GetUserByEmail(r.Context(), h.DB, addr)
@@ -368,6 +412,9 @@ func (h *AuthHandler) ForgotPasswordPage(w http.ResponseWriter, r *http.Request)
 		"User":        middleware.GetUser(r.Context()),
 		"SMTPEnabled": h.Email != nil && h.Email.Config.Enabled(),
 		"CSRFToken":   middleware.CSRFToken(r),
+		"Theme":       middleware.Theme(r),
+		"Flash":       middleware.Flash(r),
+		"Lang":        middleware.Locale(r),
 	})
 }
 
@@ -389,6 +436,9 @@ func (h *AuthHandler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
 		"User":      middleware.GetUser(r.Context()),
 		"Success":   "If an account with that email exists, a reset link has been sent.",
 		"CSRFToken": middleware.CSRFToken(r),
+		"Theme":     middleware.Theme(r),
+		"Flash":     middleware.Flash(r),
+		"Lang":      middleware.Locale(r),
 	}
 
 	user, err := db.GetUserByEmail(r.Context(), h.DB, addr)
@@ -397,21 +447,20 @@ func (h *AuthHandler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	rawToken, tokenHash, err := auth.GenerateResetToken()
+	rawToken, tokenHash, expiresAt, err := auth.NewPasswordReset(auth.ResetLinkTTL)
 	if err != nil {
 		slog.ErrorContext(r.Context(), "generate reset token", "err", err)
 		h.Tmpl.ExecuteTemplate(w, "forgot_password.html", successData)
 		return
 	}
 
-	expiresAt := time.Now().Add(1 * time.Hour)
 	if err := db.CreatePasswordReset(r.Context(), h.DB, user.ID, tokenHash, expiresAt); err != nil {
 		slog.ErrorContext(r.Context(), "create password reset", "err", err)
 		h.Tmpl.ExecuteTemplate(w, "forgot_password.html", successData)
 		return
 	}
 
-	resetURL := fmt.Sprintf("%s/reset-password?token=%s", h.BaseURL, rawToken)
+	resetURL := h.BaseURL + auth.ResetPath(rawToken)
 	if err := h.Email.SendPasswordReset(user.Email, resetURL); err != nil {
 		slog.ErrorContext(r.Context(), "send password reset email", "err", err)
 	}
@@ -432,6 +481,9 @@ func (h *AuthHandler) ResetPasswordPage(w http.ResponseWriter, r *http.Request)
 		h.Tmpl.ExecuteTemplate(w, "reset_password.html", map[string]interface{}{
 			"Error":     "Invalid or expired reset link. Please request a new one.",
 			"CSRFToken": middleware.CSRFToken(r),
+			"Theme":     middleware.Theme(r),
+			"Flash":     middleware.Flash(r),
+			"Lang":      middleware.Locale(r),
 		})
 		return
 	}
@@ -439,6 +491,9 @@ func (h *AuthHandler) ResetPasswordPage(w http.ResponseWriter, r *http.Request)
 	h.Tmpl.ExecuteTemplate(w, "reset_password.html", map[string]interface{}{
 		"Token":     token,
 		"CSRFToken": middleware.CSRFToken(r),
+		"Theme":     middleware.Theme(r),
+		"Flash":     middleware.Flash(r),
+		"Lang":      middleware.Locale(r),
 	})
 }
 
@@ -454,27 +509,39 @@ func (h *AuthHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
 
 	if token == "" || password == "" {
 		h.Tmpl.ExecuteTemplate(w, "reset_password.html", map[string]interface{}{
-			"Error":     "All fields are required.",
-			"Token":     token,
-			"CSRFToken": middleware.CSRFToken(r),
+			"Error":      "All fields are required.",
+			"ErrorField": "password",
+			"Token":      token,
+			"CSRFToken":  middleware.CSRFToken(r),
+			"Theme":      middleware.Theme(r),
+			"Flash":      middleware.Flash(r),
+			"Lang":       middleware.Locale(r),
 		})
 		return
 	}
 
 	if len(password) < 8 {
 		h.Tmpl.ExecuteTemplate(w, "reset_password.html", map[string]interface{}{
-			"Error":     "Password must be at least 8 characters.",
-			"Token":     token,
-			"CSRFToken": middleware.CSRFToken(r),
+			"Error":      "Password must be at least 8 characters.",
+			"ErrorField": "password",
+			"Token":      token,
+			"CSRFToken":  middleware.CSRFToken(r),
+			"Theme":      middleware.Theme(r),
+			"Flash":      middleware.Flash(r),
+			"Lang":       middleware.Locale(r),
 		})
 		return
 	}
 
 	if password != confirmPassword {
 		h.Tmpl.ExecuteTemplate(w, "reset_password.html", map[string]interface{}{
-			"Error":     "Passwords do not match.",
-			"Token":     token,
-			"CSRFToken": middleware.CSRFToken(r),
+			"Error":      "Passwords do not match.",
+			"ErrorField": "confirm_password",
+			"Token":      token,
+			"CSRFToken":  middleware.CSRFToken(r),
+			"Theme":      middleware.Theme(r),
+			"Flash":      middleware.Flash(r),
+			"Lang":       middleware.Locale(r),
 		})
 		return
 	}
@@ -485,6 +552,9 @@ func (h *AuthHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
 		h.Tmpl.ExecuteTemplate(w, "reset_password.html", map[string]interface{}{
 			"Error":     "Invalid or expired reset link. Please request a new one.",
 			"CSRFToken": middleware.CSRFToken(r),
+			"Theme":     middleware.Theme(r),
+			"Flash":     middleware.Flash(r),
+			"Lang":      middleware.Locale(r),
 		})
 		return
 	}
@@ -500,11 +570,17 @@ func (h *AuthHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Consume the reset token
+	// Consume the reset token. Whoever knew the old password is logged out,
+	// and a lockout from guessing it no longer applies.
 	db.DeletePasswordReset(r.Context(), h.DB, reset.ID)
+	db.DeleteOtherSessions(r.Context(), h.DB, reset.UserID, "")
+	db.ResetFailedLogins(r.Context(), h.DB, reset.UserID)
 
 	h.Tmpl.ExecuteTemplate(w, "login.html", map[string]interface{}{
 		"Success":   "Password reset successfully. Please log in.",
 		"CSRFToken": middleware.CSRFToken(r),
+		"Theme":     middleware.Theme(r),
+		"Flash":     middleware.Flash(r),
+		"Lang":      middleware.Locale(r),
 	})
 }
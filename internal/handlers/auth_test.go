@@ -111,6 +111,12 @@ func TestAuthHandler_Register_PasswordMismatch(t *testing.T) {
 	if data["Error"] != "Passwords do not match." {
 		t.Errorf("expected Passwords do not match, got %q", data["Error"])
 	}
+	if data["ErrorField"] != "confirm_password" {
+		t.Errorf("ErrorField = %q, want confirm_password", data["ErrorField"])
+	}
+	if data["Email"] != "test@example.com" || data["DisplayName"] != "Test User" {
+		t.Errorf("typed values not kept: email %q, display name %q", data["Email"], data["DisplayName"])
+	}
 }
 
 func TestAuthHandler_Logout_NoCookie(t *testing.T) {
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/engine"
+	"github.com/dstathis/openswiss/internal/middleware"
+	"github.com/go-chi/chi/v5"
+)
+
+// maxBackupTournaments caps the tournaments listed on the backup page.
+const maxBackupTournaments = 200
+
+func (h *AdminHandler) renderBackup(w http.ResponseWriter, r *http.Request, errMsg string) {
+	tournaments, _ := db.ListTournaments(r.Context(), h.DB, "", 1, maxBackupTournaments)
+	h.Tmpl.ExecuteTemplate(w, "admin_backup.html", map[string]interface{}{
+		"User":        middleware.GetUser(r.Context()),
+		"CSRFToken":   middleware.CSRFToken(r),
+		"Theme":       middleware.Theme(r),
+		"Flash":       middleware.Flash(r),
+		"Lang":        middleware.Locale(r),
+		"Tournaments": tournaments,
+		"Error":       errMsg,
+	})
+}
+
+// BackupPage lists the tournaments to back up and holds the restore form.
+func (h *AdminHandler) BackupPage(w http.ResponseWriter, r *http.Request) {
+	h.renderBackup(w, r, "")
+}
+
+// DownloadBackup sends one tournament's backup as a JSON file.
+func (h *AdminHandler) DownloadBackup(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	b, err := db.BackupTournament(r.Context(), h.DB, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to back up tournament", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, b.Filename()))
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(b)
+}
+
+// Restore loads an uploaded backup into a new tournament, or over the one
+// picked in replace_id. Form fields: backup (the file), replace_id.
+func (h *AdminHandler) Restore(w http.ResponseWriter, r *http.Request) {
+	file, _, err := r.FormFile("backup")
+	if err != nil {
+		h.renderBackup(w, r, "Choose a backup file to restore.")
+		return
+	}
+	defer file.Close()
+	var replaceID int64
+	if v := r.FormValue("replace_id"); v != "" {
+		if replaceID, err = strconv.ParseInt(v, 10, 64); err != nil {
+			h.renderBackup(w, r, "Invalid tournament to replace.")
+			return
+		}
+	}
+	b, err := engine.ReadBackup(file)
+	if err != nil {
+		h.renderBackup(w, r, err.Error())
+		return
+	}
+	user := middleware.GetUser(r.Context())
+	id, err := engine.RestoreBackup(r.Context(), h.DB, b, user.ID, replaceID)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		h.renderBackup(w, r, "The tournament to replace doesn't exist.")
+		return
+	case errors.Is(err, db.ErrBackupUserConflict):
+		h.renderBackup(w, r, err.Error())
+		return
+	case err != nil:
+		h.renderBackup(w, r, "Failed to restore backup.")
+		return
+	}
+	slog.Info("tournament restored from backup", "tournament_id", id, "replaced", replaceID != 0, "user_id", user.ID)
+	http.Redirect(w, r, fmt.Sprintf("/tournaments/%d/manage", id), http.StatusSeeOther)
+}
@@ -0,0 +1,99 @@
+//go:build integration
+
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/middleware"
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+// restoreRequest builds a multipart restore upload of data.
+func restoreRequest(t *testing.T, user *models.User, data []byte, replaceID string) *http.Request {
+	t.Helper()
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	part, err := mw.CreateFormFile("backup", "backup.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	part.Write(data)
+	mw.WriteField("replace_id", replaceID)
+	mw.Close()
+	req := httptest.NewRequest("POST", "/admin/restore", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	return req.WithContext(context.WithValue(req.Context(), middleware.UserContextKey, user))
+}
+
+func TestAdminHandler_BackupAndRestore(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	tmpl := &mockTemplate{}
+	h := &AdminHandler{DB: database, Tmpl: tmpl}
+	_, tourn := startedTournament(t, database)
+	admin := mustCreateUser(t, database, "admin-backup@example.com", "AdminBackup")
+
+	rec := httptest.NewRecorder()
+	h.DownloadBackup(rec, requestWithUser("GET", "/", "", admin, map[string]string{"id": strconv.FormatInt(tourn.ID, 10)}))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("download: status %d body %s", rec.Code, rec.Body.String())
+	}
+	if cd := rec.Header().Get("Content-Disposition"); !strings.HasPrefix(cd, "attachment;") {
+		t.Errorf("Content-Disposition = %q", cd)
+	}
+	data := rec.Body.Bytes()
+
+	rec = httptest.NewRecorder()
+	h.DownloadBackup(rec, requestWithUser("GET", "/", "", admin, map[string]string{"id": "999999"}))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("missing tournament: status %d, want 404", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	h.Restore(rec, restoreRequest(t, admin, data, ""))
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("restore: status %d body %s", rec.Code, rec.Body.String())
+	}
+	loc := rec.Header().Get("Location")
+	id, err := strconv.ParseInt(strings.TrimSuffix(strings.TrimPrefix(loc, "/tournaments/"), "/manage"), 10, 64)
+	if err != nil || id == tourn.ID {
+		t.Fatalf("redirected to %q", loc)
+	}
+	restored, _ := db.GetTournament(ctx, database, id)
+	if restored.Status != tourn.Status || len(restored.EngineState) == 0 {
+		t.Errorf("restored tournament = %+v", restored)
+	}
+	regs, _ := db.ListRegistrations(ctx, database, id)
+	if len(regs) != 4 || regs[0].UserID == nil {
+		t.Errorf("restored registrations = %+v", regs)
+	}
+
+	// Over the original.
+	rec = httptest.NewRecorder()
+	h.Restore(rec, restoreRequest(t, admin, data, strconv.FormatInt(tourn.ID, 10)))
+	if want := "/tournaments/" + strconv.FormatInt(tourn.ID, 10) + "/manage"; rec.Header().Get("Location") != want {
+		t.Errorf("replace: status %d, location %q", rec.Code, rec.Header().Get("Location"))
+	}
+
+	for name, req := range map[string]*http.Request{
+		"not json":       restoreRequest(t, admin, []byte("not a backup"), ""),
+		"missing target": restoreRequest(t, admin, data, "999999"),
+		"no file":        requestWithUser("POST", "/", "replace_id=", admin, nil),
+	} {
+		tmpl.calls = nil
+		rec = httptest.NewRecorder()
+		h.Restore(rec, req)
+		if len(tmpl.calls) != 1 || tmpl.calls[0].Data.(map[string]interface{})["Error"] == "" {
+			t.Errorf("%s: status %d, no error shown", name, rec.Code)
+		}
+	}
+}
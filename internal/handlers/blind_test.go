@@ -0,0 +1,89 @@
+//go:build integration
+
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/swisstools"
+)
+
+func TestTournamentHandler_BlindFinalRound(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	h := &TournamentHandler{DB: database, Tmpl: &mockTemplate{}}
+	owner, tourn := startedTournament(t, database)
+	params := map[string]string{"id": strconv.FormatInt(tourn.ID, 10)}
+
+	tourn.BlindFinalRound = true
+	if err := db.UpdateTournament(ctx, database, tourn); err != nil {
+		t.Fatalf("enable blind final round: %v", err)
+	}
+	detail := func(user bool) map[string]interface{} {
+		tmpl := &mockTemplate{}
+		h.Tmpl = tmpl
+		req := requestWithUser("GET", "/", "", nil, params)
+		if user {
+			req = requestWithUser("GET", "/", "", owner, params)
+		}
+		h.Detail(httptest.NewRecorder(), req)
+		return tmpl.calls[0].Data.(map[string]interface{})
+	}
+
+	// Round 1 of 2 shows the standings to everyone.
+	if data := detail(false); data["StandingsHidden"] != false || len(data["Standings"].([]swisstools.PlayerStanding)) != 4 {
+		t.Errorf("round 1: hidden = %v, standings = %v", data["StandingsHidden"], data["Standings"])
+	}
+
+	rec := httptest.NewRecorder()
+	h.NextRound(rec, requestWithUser("POST", "/", "", owner, params))
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("next round: status %d body %s", rec.Code, rec.Body.String())
+	}
+
+	// In the final round spectators get the pairings but no standings;
+	// staff get both.
+	data := detail(false)
+	if data["StandingsHidden"] != true || len(data["Standings"].([]swisstools.PlayerStanding)) != 0 {
+		t.Errorf("final round: hidden = %v, standings = %v", data["StandingsHidden"], data["Standings"])
+	}
+	if len(data["Pairings"].([]resolvedPairing)) != 2 {
+		t.Errorf("final round pairings = %v", data["Pairings"])
+	}
+	if data := detail(true); len(data["Standings"].([]swisstools.PlayerStanding)) != 4 {
+		t.Errorf("staff standings = %v", data["Standings"])
+	}
+
+	tmpl := &mockTemplate{}
+	h.Tmpl = tmpl
+	h.DisplayStandings(httptest.NewRecorder(), requestWithUser("GET", "/", "", owner, params))
+	if data := tmpl.calls[0].Data.(map[string]interface{}); data["Hidden"] != true || len(data["Standings"].([]swisstools.PlayerStanding)) != 0 {
+		t.Errorf("display: hidden = %v, standings = %v", data["Hidden"], data["Standings"])
+	}
+	rec = httptest.NewRecorder()
+	h.OverlayStandings(rec, requestWithUser("GET", "/?format=json", "", nil, params))
+	if body := rec.Body.String(); !strings.Contains(body, `"hidden":true`) || !strings.Contains(body, `"standings":[]`) {
+		t.Errorf("overlay: %s", body)
+	}
+
+	// The feature match overlay shows the match without the records.
+	if err := db.ReplaceFeatureMatches(ctx, database, tourn.ID, 2, []int{1}); err != nil {
+		t.Fatal(err)
+	}
+	rec = httptest.NewRecorder()
+	h.OverlayMatch(rec, requestWithUser("GET", "/?format=json", "", nil, map[string]string{"id": params["id"], "table": "feature"}))
+	var match overlayMatch
+	if err := json.NewDecoder(rec.Body).Decode(&match); err != nil {
+		t.Fatal(err)
+	}
+	if m := match.Match; !match.Hidden || m == nil || m.PlayerA.Name == "" || m.PlayerA.Points != 0 || m.PlayerA.Wins != 0 || m.PlayerA.Rank != 0 || m.PlayerB.Rank != 0 {
+		t.Errorf("match overlay: %+v", match)
+	}
+}
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/engine"
+	"github.com/dstathis/openswiss/internal/middleware"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/go-chi/chi/v5"
+)
+
+// AssignBye gives a player a bye in a coming round, for instance a judge
+// who is only playing in to even out the field. Min tier: Co-organizer.
+func (h *TournamentHandler) AssignBye(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	t, err := db.GetTournament(r.Context(), h.DB, id)
+	if err != nil {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+	if !middleware.AuthorizeTournament(w, r, h.DB, t.ID, models.TierCoOrganizer) {
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+	regID, err := strconv.ParseInt(r.FormValue("registration_id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Choose a player", http.StatusBadRequest)
+		return
+	}
+	round, err := strconv.Atoi(r.FormValue("round"))
+	if err != nil {
+		http.Error(w, "Invalid round", http.StatusBadRequest)
+		return
+	}
+	reg, _ := db.GetRegistrationByID(r.Context(), h.DB, regID)
+	if err := engine.CheckAssignedBye(t, reg, round); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	user := middleware.GetUser(r.Context())
+	if err := db.AddAssignedBye(r.Context(), h.DB, &models.AssignedBye{
+		TournamentID:   t.ID,
+		Round:          round,
+		RegistrationID: reg.ID,
+		AssignedBy:     &user.ID,
+	}); err != nil {
+		http.Error(w, "Failed to assign bye", http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, r, fmt.Sprintf("/tournaments/%d/manage/players", id), http.StatusSeeOther)
+}
+
+// RemoveBye takes an assigned bye back. A bye already paired stays in its
+// round. Min tier: Co-organizer.
+func (h *TournamentHandler) RemoveBye(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	round, err := strconv.Atoi(chi.URLParam(r, "round"))
+	if err != nil {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+	regID, err := strconv.ParseInt(chi.URLParam(r, "regID"), 10, 64)
+	if err != nil {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+	if !middleware.AuthorizeTournament(w, r, h.DB, id, models.TierCoOrganizer) {
+		return
+	}
+	if err := db.DeleteAssignedBye(r.Context(), h.DB, id, round, regID); err != nil {
+		if errors.Is(err, db.ErrAssignedByeNotFound) {
+			http.Error(w, "Not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Failed to remove bye", http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, r, fmt.Sprintf("/tournaments/%d/manage/players", id), http.StatusSeeOther)
+}
@@ -0,0 +1,105 @@
+//go:build integration
+
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/dstathis/swisstools"
+)
+
+func TestTournamentHandler_AssignBye(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	h := &TournamentHandler{DB: database, Tmpl: &mockTemplate{}}
+	owner := mustCreateUser(t, database, "owner-bye@example.com", "OwnerBye")
+	tourn := mustCreateTournament(t, database, owner.ID, models.TournamentStatusRegistrationOpen)
+	idStr := strconv.FormatInt(tourn.ID, 10)
+	var regs []*models.Registration
+	for _, name := range []string{"Judge", "A", "B", "C"} {
+		reg, err := db.CreateGuestRegistration(ctx, database, tourn.ID, name)
+		if err != nil {
+			t.Fatalf("register %s: %v", name, err)
+		}
+		regs = append(regs, reg)
+	}
+	judge := regs[0]
+
+	for name, body := range map[string]string{
+		"round 0":        fmt.Sprintf("registration_id=%d&round=0", judge.ID),
+		"no player":      "round=1",
+		"unknown player": "registration_id=999999&round=1",
+		"not a round":    fmt.Sprintf("registration_id=%d&round=x", judge.ID),
+	} {
+		rec := httptest.NewRecorder()
+		h.AssignBye(rec, requestWithUser("POST", "/", body, owner, map[string]string{"id": idStr}))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status %d, want 400", name, rec.Code)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	h.AssignBye(rec, requestWithUser("POST", "/", fmt.Sprintf("registration_id=%d&round=1", judge.ID), owner, map[string]string{"id": idStr}))
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("assign: status %d body %s", rec.Code, rec.Body.String())
+	}
+
+	// Starting pairs round 1 with the judge sitting out; three players are
+	// left, so one of them gets a bye as well.
+	rec = httptest.NewRecorder()
+	h.Start(rec, requestWithUser("POST", "/", "", owner, map[string]string{"id": idStr}))
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("start: status %d body %s", rec.Code, rec.Body.String())
+	}
+	got, _ := db.GetTournament(ctx, database, tourn.ID)
+	eng, err := swisstools.LoadTournament(got.EngineState)
+	if err != nil {
+		t.Fatal(err)
+	}
+	judgeReg, _ := db.GetRegistrationByID(ctx, database, judge.ID)
+	byes := 0
+	judgeBye := false
+	for _, p := range eng.GetRound() {
+		if p.PlayerB() == swisstools.BYE_OPPONENT_ID {
+			byes++
+			judgeBye = judgeBye || p.PlayerA() == *judgeReg.EnginePlayerID
+		}
+	}
+	if byes != 2 || !judgeBye {
+		t.Errorf("round 1 has %d byes, judge's among them: %v", byes, judgeBye)
+	}
+
+	remove := map[string]string{"id": idStr, "round": "1", "regID": strconv.FormatInt(judge.ID, 10)}
+	rec = httptest.NewRecorder()
+	h.RemoveBye(rec, requestWithUser("POST", "/", "", owner, remove))
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("remove: status %d", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	h.RemoveBye(rec, requestWithUser("POST", "/", "", owner, remove))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("second remove: status %d, want 404", rec.Code)
+	}
+}
+
+func TestTournamentHandler_AssignBye_Forbidden(t *testing.T) {
+	database := testDB(t)
+	h := &TournamentHandler{DB: database, Tmpl: &mockTemplate{}}
+	_, tourn := startedTournament(t, database)
+	other := mustCreateUser(t, database, "other-bye@example.com", "OtherBye")
+	regs := mustListRegs(t, database, tourn.ID)
+
+	rec := httptest.NewRecorder()
+	h.AssignBye(rec, requestWithUser("POST", "/", fmt.Sprintf("registration_id=%d&round=2", regs[0].ID), other,
+		map[string]string{"id": strconv.FormatInt(tourn.ID, 10)}))
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("status %d, want 403", rec.Code)
+	}
+}
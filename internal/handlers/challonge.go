@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/dstathis/openswiss/internal/challonge"
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/engine"
+	"github.com/dstathis/openswiss/internal/middleware"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/dstathis/swisstools"
+	"github.com/go-chi/chi/v5"
+)
+
+// IntegrationsPage shows the third-party API keys the site is set up with.
+func (h *AdminHandler) IntegrationsPage(w http.ResponseWriter, r *http.Request) {
+	key, _ := db.GetSetting(r.Context(), h.DB, db.SettingChallongeAPIKey)
+	var hint string
+	if len(key) > 4 {
+		hint = key[len(key)-4:]
+	}
+	h.Tmpl.ExecuteTemplate(w, "admin_integrations.html", map[string]interface{}{
+		"User":         middleware.GetUser(r.Context()),
+		"CSRFToken":    middleware.CSRFToken(r),
+		"Theme":        middleware.Theme(r),
+		"Flash":        middleware.Flash(r),
+		"Lang":         middleware.Locale(r),
+		"ChallongeSet": key != "",
+		"ChallongeEnd": hint,
+	})
+}
+
+// SetChallongeKey saves or, when empty, clears the Challonge API key.
+// Form field: api_key.
+func (h *AdminHandler) SetChallongeKey(w http.ResponseWriter, r *http.Request) {
+	key := strings.TrimSpace(r.FormValue("api_key"))
+	if err := db.SetSetting(r.Context(), h.DB, db.SettingChallongeAPIKey, key); err != nil {
+		http.Error(w, "Failed to save the key", http.StatusInternalServerError)
+		return
+	}
+	slog.Info("challonge api key set by admin", "cleared", key == "", "user_id", middleware.GetUser(r.Context()).ID)
+	if key == "" {
+		middleware.SetFlash(w, r, middleware.FlashSuccess, "Challonge API key cleared.")
+	} else {
+		middleware.SetFlash(w, r, middleware.FlashSuccess, "Challonge API key saved.")
+	}
+	http.Redirect(w, r, "/admin/integrations", http.StatusSeeOther)
+}
+
+// PushChallonge creates the tournament on Challonge with the site's API
+// key: its final Swiss standings, and the top-cut bracket with its results
+// when it had one (see challonge.Build). Only complete tournaments are
+// pushed. Min tier: Co-organizer.
+func (h *TournamentHandler) PushChallonge(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if !middleware.AuthorizeTournament(w, r, h.DB, id, models.TierCoOrganizer) {
+		return
+	}
+	t, err := db.GetTournament(r.Context(), h.DB, id)
+	if err != nil {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+	key, err := db.GetSetting(r.Context(), h.DB, db.SettingChallongeAPIKey)
+	if err != nil {
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	if key == "" {
+		http.Error(w, "No Challonge API key is set up; a site admin can add one under Integrations", http.StatusBadRequest)
+		return
+	}
+	if len(t.EngineState) == 0 {
+		http.Error(w, "The tournament is not finished", http.StatusBadRequest)
+		return
+	}
+	eng, err := swisstools.LoadTournament(t.EngineState)
+	if err != nil {
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	if !engine.Complete(t, &eng) {
+		http.Error(w, "The tournament is not finished", http.StatusBadRequest)
+		return
+	}
+	regs, _ := db.ListRegistrations(r.Context(), h.DB, id)
+	push := challonge.Build(t, &eng, engine.TiebreakSeeds(regs))
+	url, err := challonge.New(key, h.ChallongeBaseURL).Push(r.Context(), push)
+	if err != nil {
+		slog.WarnContext(r.Context(), "challonge push failed", "tournament_id", id, "err", err)
+		http.Error(w, "Challonge: "+err.Error(), http.StatusBadGateway)
+		return
+	}
+	if err := db.SetChallongeURL(r.Context(), h.DB, id, url); err != nil {
+		slog.ErrorContext(r.Context(), "record challonge url", "tournament_id", id, "err", err)
+	}
+	slog.Info("tournament pushed to challonge", "tournament_id", id, "url", url,
+		"user_id", middleware.GetUser(r.Context()).ID)
+	http.Redirect(w, r, fmt.Sprintf("/tournaments/%d/manage/results#challonge", id), http.StatusSeeOther)
+}
@@ -0,0 +1,92 @@
+//go:build integration
+
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/db"
+)
+
+func TestChallonge_KeyAndPush(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	var created int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/tournaments.json":
+			created++
+			fmt.Fprint(w, `{"tournament": {"id": 3, "full_challonge_url": "https://challonge.com/pushed"}}`)
+		case "/tournaments/3/participants/bulk_add.json":
+			fmt.Fprint(w, `[]`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	tmpl := &mockTemplate{}
+	admin := &AdminHandler{DB: database, Tmpl: tmpl}
+	h := &TournamentHandler{DB: database, Tmpl: tmpl, ChallongeBaseURL: srv.URL}
+	owner, tourn := startedTournament(t, database)
+	params := map[string]string{"id": strconv.FormatInt(tourn.ID, 10)}
+
+	rec := httptest.NewRecorder()
+	h.Finish(rec, requestWithUser("POST", "/", "", owner, params))
+	rec = httptest.NewRecorder()
+	h.PushChallonge(rec, requestWithUser("POST", "/", "", owner, params))
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "API key") {
+		t.Errorf("without a key: status %d, body %q", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	admin.SetChallongeKey(rec, requestWithUser("POST", "/", url.Values{"api_key": {" abcdef1234 "}}.Encode(), owner, nil))
+	if key, _ := db.GetSetting(ctx, database, db.SettingChallongeAPIKey); rec.Code != http.StatusSeeOther || key != "abcdef1234" {
+		t.Fatalf("set key: status %d, key %q", rec.Code, key)
+	}
+	admin.IntegrationsPage(httptest.NewRecorder(), requestWithUser("GET", "/", "", owner, nil))
+	if data := tmpl.calls[len(tmpl.calls)-1].Data.(map[string]interface{}); data["ChallongeEnd"] != "1234" {
+		t.Errorf("page shows key ending %v", data["ChallongeEnd"])
+	}
+
+	rec = httptest.NewRecorder()
+	h.PushChallonge(rec, requestWithUser("POST", "/", "", owner, params))
+	if rec.Code != http.StatusSeeOther || created != 1 {
+		t.Fatalf("push: status %d, body %q", rec.Code, rec.Body.String())
+	}
+	if u, _ := db.GetChallongeURL(ctx, database, tourn.ID); u != "https://challonge.com/pushed" {
+		t.Errorf("recorded url %q", u)
+	}
+
+	outsider := mustCreateUser(t, database, "out-challonge@example.com", "OutChallonge")
+	rec = httptest.NewRecorder()
+	h.PushChallonge(rec, requestWithUser("POST", "/", "", outsider, params))
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("outsider: status %d, want 403", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	admin.SetChallongeKey(rec, requestWithUser("POST", "/", "api_key=", owner, nil))
+	if key, _ := db.GetSetting(ctx, database, db.SettingChallongeAPIKey); key != "" {
+		t.Errorf("key not cleared: %q", key)
+	}
+}
+
+func TestChallonge_PushUnfinished(t *testing.T) {
+	database := testDB(t)
+	h := &TournamentHandler{DB: database, Tmpl: &mockTemplate{}, ChallongeBaseURL: "http://127.0.0.1:1"}
+	owner, tourn := startedTournament(t, database)
+	db.SetSetting(context.Background(), database, db.SettingChallongeAPIKey, "key")
+	rec := httptest.NewRecorder()
+	h.PushChallonge(rec, requestWithUser("POST", "/", "", owner, map[string]string{"id": strconv.FormatInt(tourn.ID, 10)}))
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "not finished") {
+		t.Errorf("status %d, body %q", rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/engine"
+	"github.com/dstathis/openswiss/internal/middleware"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/go-chi/chi/v5"
+)
+
+// SetClubs saves the players' clubs from the dashboard's clubs box, one
+// "name, club" line per player (see engine.ParseClubs). Players left out
+// keep their club. Min tier: Co-organizer.
+func (h *TournamentHandler) SetClubs(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if !middleware.AuthorizeTournament(w, r, h.DB, id, models.TierCoOrganizer) {
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+	regs, err := db.ListRegistrations(r.Context(), h.DB, id)
+	if err != nil {
+		http.Error(w, "Failed to load players", http.StatusInternalServerError)
+		return
+	}
+	clubs, err := engine.ParseClubs(r.FormValue("clubs"), regs)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := engine.SetClubs(r.Context(), h.DB, id, clubs); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	http.Redirect(w, r, fmt.Sprintf("/tournaments/%d/manage/players#clubs", id), http.StatusSeeOther)
+}
@@ -0,0 +1,61 @@
+//go:build integration
+
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+func TestTournamentHandler_SetClubs(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	h := &TournamentHandler{DB: database, Tmpl: &mockTemplate{}}
+	owner := mustCreateUser(t, database, "owner-clubs@example.com", "OwnerClubs")
+	tourn := mustCreateTournament(t, database, owner.ID, models.TournamentStatusRegistrationOpen)
+	alice, _ := db.CreateGuestRegistration(ctx, database, tourn.ID, "Alice")
+	bob, _ := db.CreateGuestRegistration(ctx, database, tourn.ID, "Bob")
+	params := map[string]string{"id": strconv.FormatInt(tourn.ID, 10)}
+	post := func(user *models.User, clubs string) int {
+		rec := httptest.NewRecorder()
+		h.SetClubs(rec, requestWithUser("POST", "/", url.Values{"clubs": {clubs}}.Encode(), user, params))
+		return rec.Code
+	}
+
+	stranger := mustCreateUser(t, database, "stranger-clubs@example.com", "StrangerClubs")
+	if code := post(stranger, "Alice, North CC"); code != http.StatusForbidden {
+		t.Errorf("stranger: status %d, want 403", code)
+	}
+	if code := post(owner, "alice, North CC\nBob\tSouth Games\n"); code != http.StatusSeeOther {
+		t.Fatalf("status %d", code)
+	}
+	if got, _ := db.GetRegistrationByID(ctx, database, bob.ID); got.Club == nil || *got.Club != "South Games" {
+		t.Errorf("Bob's club = %v", got.Club)
+	}
+	if code := post(owner, "Alice, East\nNobody, West"); code != http.StatusBadRequest {
+		t.Errorf("unknown player: status %d, want 400", code)
+	}
+	if got, _ := db.GetRegistrationByID(ctx, database, alice.ID); *got.Club != "North CC" {
+		t.Errorf("Alice's club changed to %q by a refused import", *got.Club)
+	}
+
+	// Clubs can still change during the event, but not once it's over.
+	db.UpdateTournamentStatus(ctx, database, tourn.ID, models.TournamentStatusInProgress)
+	if code := post(owner, "Bob"); code != http.StatusSeeOther {
+		t.Errorf("during the event: status %d, want 303", code)
+	}
+	if got, _ := db.GetRegistrationByID(ctx, database, bob.ID); got.Club != nil {
+		t.Errorf("Bob's club = %q, want cleared", *got.Club)
+	}
+	db.UpdateTournamentStatus(ctx, database, tourn.ID, models.TournamentStatusFinished)
+	if code := post(owner, "Alice, East"); code != http.StatusBadRequest {
+		t.Errorf("after the finish: status %d, want 400", code)
+	}
+}
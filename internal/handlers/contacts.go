@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/export"
+	"github.com/dstathis/openswiss/internal/middleware"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/go-chi/chi/v5"
+)
+
+// SetContactConsent lets a registered player give or withdraw consent to
+// hear from the organizer after the event, at any time. Form field:
+// contact_consent, set to agree and absent to withdraw.
+func (h *TournamentHandler) SetContactConsent(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	user := middleware.GetUser(r.Context())
+	consent := r.FormValue("contact_consent") != ""
+	if err := db.SetContactConsent(r.Context(), h.DB, id, user.ID, consent); err != nil {
+		http.Error(w, "Not registered", http.StatusNotFound)
+		return
+	}
+	msg := "The organizer won't contact you about this event."
+	if consent {
+		msg = "The organizer may contact you about this event."
+	}
+	middleware.SetFlash(w, r, middleware.FlashSuccess, msg)
+	http.Redirect(w, r, fmt.Sprintf("/tournaments/%d", id), http.StatusSeeOther)
+}
+
+// ExportMailingList downloads, as CSV, the name, email and status of the
+// players who agreed to be contacted after the event, for follow-ups.
+// Guests and players who declined or withdrew consent are left out.
+// Min tier: Admin.
+func (h *TournamentHandler) ExportMailingList(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if !middleware.AuthorizeTournament(w, r, h.DB, id, models.TierAdmin) {
+		return
+	}
+	contacts, err := db.ListMailingList(r.Context(), h.DB, id)
+	if err != nil {
+		http.Error(w, "Failed to load players", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="mailing-list-%d.csv"`, id))
+	out := csv.NewWriter(w)
+	out.Write([]string{"name", "email", "status"})
+	for _, c := range contacts {
+		out.Write([]string{export.CSVCell(c.Name), export.CSVCell(c.Email), c.Status})
+	}
+	out.Flush()
+}
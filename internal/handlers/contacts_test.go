@@ -0,0 +1,70 @@
+//go:build integration
+
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+func TestTournamentHandler_MailingList(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	h := &TournamentHandler{DB: database, Tmpl: &mockTemplate{}}
+	owner := mustCreateUser(t, database, "owner-mailing@example.com", "OwnerMailing", models.RoleOrganizer)
+	co := mustCreateUser(t, database, "co-mailing@example.com", "CoMailing")
+	ann := mustCreateUser(t, database, "ann-mailing@example.com", "Ann")
+	bob := mustCreateUser(t, database, "bob-mailing@example.com", "Bob")
+	tourn := mustCreateTournament(t, database, owner.ID, models.TournamentStatusRegistrationOpen)
+	if err := db.AddTournamentStaff(ctx, database, &models.TournamentStaff{
+		TournamentID: tourn.ID, UserID: co.ID, Tier: models.TierCoOrganizer,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	db.CreateGuestRegistration(ctx, database, tourn.ID, "Cat")
+	params := map[string]string{"id": strconv.FormatInt(tourn.ID, 10)}
+
+	// Ann agrees when signing up; Bob doesn't.
+	h.Register(httptest.NewRecorder(), requestWithUser("POST", "/", "contact_consent=1", ann, params))
+	h.Register(httptest.NewRecorder(), requestWithUser("POST", "/", "", bob, params))
+	if reg, _ := db.GetRegistration(ctx, database, tourn.ID, ann.ID); reg == nil || !reg.ContactConsent {
+		t.Fatalf("Ann's registration = %+v, want consent", reg)
+	}
+	export := func(user *models.User) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.ExportMailingList(rec, requestWithUser("GET", "/", "", user, params))
+		return rec
+	}
+	rec := export(owner)
+	if want := "name,email,status\nAnn,ann-mailing@example.com,confirmed\n"; rec.Code != http.StatusOK || rec.Body.String() != want {
+		t.Errorf("export: status %d body %q, want %q", rec.Code, rec.Body.String(), want)
+	}
+	if rec := export(co); rec.Code != http.StatusForbidden {
+		t.Errorf("export by a co-organizer: status %d, want 403", rec.Code)
+	}
+
+	// Consent can be given later and withdrawn, even after the event.
+	rec = httptest.NewRecorder()
+	h.SetContactConsent(rec, requestWithUser("POST", "/", "contact_consent=1", bob, params))
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("allow: status %d", rec.Code)
+	}
+	if err := db.UpdateTournamentStatus(ctx, database, tourn.ID, models.TournamentStatusFinished); err != nil {
+		t.Fatal(err)
+	}
+	h.SetContactConsent(httptest.NewRecorder(), requestWithUser("POST", "/", "", ann, params))
+	if want := "name,email,status\nBob,bob-mailing@example.com,confirmed\n"; export(owner).Body.String() != want {
+		t.Errorf("after changes: %q, want %q", export(owner).Body.String(), want)
+	}
+	rec = httptest.NewRecorder()
+	h.SetContactConsent(rec, requestWithUser("POST", "/", "contact_consent=1", co, params))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("unregistered user: status %d, want 404", rec.Code)
+	}
+}
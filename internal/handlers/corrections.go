@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/engine"
+	"github.com/dstathis/openswiss/internal/middleware"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/dstathis/swisstools"
+	"github.com/go-chi/chi/v5"
+)
+
+// CorrectResult changes the result of a match in a closed Swiss round and
+// returns to that round's staff page. Form fields: table, wins_a, wins_b,
+// draws. Min tier: Admin.
+func (h *TournamentHandler) CorrectResult(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if !middleware.AuthorizeTournament(w, r, h.DB, id, models.TierAdmin) {
+		return
+	}
+	round, err := strconv.Atoi(chi.URLParam(r, "round"))
+	if err != nil {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+	table, _ := strconv.Atoi(r.FormValue("table"))
+	wins, _ := strconv.Atoi(r.FormValue("wins_a"))
+	losses, _ := strconv.Atoi(r.FormValue("wins_b"))
+	draws, _ := strconv.Atoi(r.FormValue("draws"))
+	user := middleware.GetUser(r.Context())
+
+	var c *models.ResultCorrection
+	err = engine.WithTournamentEngine(r.Context(), h.DB, id,
+		func(tx *sql.Tx, t *models.Tournament, eng *swisstools.Tournament) (string, error) {
+			var err error
+			c, err = engine.CorrectResult(r.Context(), tx, t, eng, round, table, wins, losses, draws, &user.ID)
+			return "", err
+		})
+	if err != nil {
+		engineError(w, r, err)
+		return
+	}
+	slog.Info("result corrected", "tournament_id", id, "round", round, "table", table,
+		"old", fmt.Sprintf("%d-%d-%d", c.OldA, c.OldB, c.OldDraws),
+		"new", fmt.Sprintf("%d-%d-%d", c.NewA, c.NewB, c.NewDraws), "user_id", user.ID)
+	http.Redirect(w, r, fmt.Sprintf("/tournaments/%d/manage/rounds/%d#corrections", id, round), http.StatusSeeOther)
+}
+
+// roundCorrections splits a tournament's result corrections into those
+// made to round and those to earlier rounds that round was paired before.
+func roundCorrections(ctx context.Context, database db.DBTX, tournamentID int64, round int) (made, pairedBefore []models.ResultCorrection) {
+	all, err := db.ListResultCorrections(ctx, database, tournamentID)
+	if err != nil {
+		return nil, nil
+	}
+	for _, c := range all {
+		switch {
+		case c.Round == round:
+			made = append(made, c)
+		case c.Affects(round):
+			pairedBefore = append(pairedBefore, c)
+		}
+	}
+	return made, pairedBefore
+}
@@ -0,0 +1,80 @@
+//go:build integration
+
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/dstathis/swisstools"
+)
+
+func TestTournamentHandler_CorrectResult(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	tmpl := &mockTemplate{}
+	h := &TournamentHandler{DB: database, Tmpl: tmpl}
+	owner, tourn := startedTournament(t, database)
+	id := strconv.FormatInt(tourn.ID, 10)
+	params := map[string]string{"id": id}
+	round1 := map[string]string{"id": id, "round": "1"}
+	round2 := map[string]string{"id": id, "round": "2"}
+
+	h.NextRound(httptest.NewRecorder(), requestWithUser("POST", "/", "", owner, params))
+	tm, _ := db.GetTournament(ctx, database, tourn.ID)
+	eng, _ := swisstools.LoadTournament(tm.EngineState)
+	first, _ := eng.GetRoundByNumber(1)
+	p := first[0]
+	form := url.Values{"table": {"1"}, "wins_a": {strconv.Itoa(p.PlayerBWins())}, "wins_b": {strconv.Itoa(p.PlayerAWins())}, "draws": {"0"}}
+
+	stranger := mustCreateUser(t, database, "stranger@example.com", "Stranger")
+	rec := httptest.NewRecorder()
+	h.CorrectResult(rec, requestWithUser("POST", "/", form.Encode(), stranger, round1))
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("non-staff: status %d, want 403", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	h.CorrectResult(rec, requestWithUser("POST", "/", form.Encode(), owner, round1))
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("correct: status %d: %s", rec.Code, rec.Body.String())
+	}
+	tm, _ = db.GetTournament(ctx, database, tourn.ID)
+	eng, _ = swisstools.LoadTournament(tm.EngineState)
+	first, _ = eng.GetRoundByNumber(1)
+	if got := first[0]; got.PlayerAWins() != p.PlayerBWins() || got.PlayerBWins() != p.PlayerAWins() {
+		t.Errorf("round 1 table 1 = %d-%d", got.PlayerAWins(), got.PlayerBWins())
+	}
+	corrections, _ := db.ListResultCorrections(ctx, database, tourn.ID)
+	if len(corrections) != 1 || corrections[0].PairedThrough != 2 || corrections[0].CorrectedBy == nil {
+		t.Fatalf("corrections = %+v", corrections)
+	}
+
+	rec = httptest.NewRecorder()
+	h.CorrectResult(rec, requestWithUser("POST", "/", form.Encode(), owner, round1))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("unchanged result: status %d, want 400", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	h.CorrectResult(rec, requestWithUser("POST", "/", form.Encode(), owner, round2))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("current round: status %d, want 400", rec.Code)
+	}
+
+	h.ManageRoundPage(httptest.NewRecorder(), requestWithUser("GET", "/", "", owner, round1))
+	data := tmpl.calls[len(tmpl.calls)-1].Data.(map[string]interface{})
+	if data["CanCorrect"] != true || len(data["Corrections"].([]models.ResultCorrection)) != 1 {
+		t.Errorf("staff round 1: CanCorrect %v, Corrections %v", data["CanCorrect"], data["Corrections"])
+	}
+	h.RoundPage(httptest.NewRecorder(), requestWithUser("GET", "/", "", nil, round2))
+	data = tmpl.calls[len(tmpl.calls)-1].Data.(map[string]interface{})
+	if data["CanCorrect"] == true || len(data["PairedBefore"].([]models.ResultCorrection)) != 1 {
+		t.Errorf("public round 2: CanCorrect %v, PairedBefore %v", data["CanCorrect"], data["PairedBefore"])
+	}
+}
@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/engine"
+	"github.com/dstathis/openswiss/internal/middleware"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/dstathis/swisstools"
+	"github.com/go-chi/chi/v5"
+)
+
+// DiagnosticsPage shows how well the Swiss rounds were paired: the
+// rematches, matches across records and byes of each round, and who has had
+// a bye (see engine.DiagnosePairings). Min tier: Co-organizer.
+func (h *TournamentHandler) DiagnosticsPage(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if !middleware.AuthorizeTournament(w, r, h.DB, id, models.TierCoOrganizer) {
+		return
+	}
+	t, err := db.GetTournament(r.Context(), h.DB, id)
+	if err != nil {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+	data := map[string]interface{}{
+		"User":       middleware.GetUser(r.Context()),
+		"CSRFToken":  middleware.CSRFToken(r),
+		"Theme":      middleware.Theme(r),
+		"Flash":      middleware.Flash(r),
+		"Lang":       middleware.Locale(r),
+		"Tournament": t,
+	}
+	if len(t.EngineState) > 0 {
+		eng, err := swisstools.LoadTournament(t.EngineState)
+		if err != nil {
+			http.Error(w, "Failed to load tournament", http.StatusInternalServerError)
+			return
+		}
+		data["Diagnostics"] = engine.DiagnosePairings(t, &eng)
+	}
+	h.Tmpl.ExecuteTemplate(w, "tournament_diagnostics.html", data)
+}
@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/engine"
+	"github.com/dstathis/openswiss/internal/middleware"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/dstathis/swisstools"
+	"github.com/go-chi/chi/v5"
+)
+
+// Projector pages reload every defaultDisplayRefresh seconds unless the URL
+// asks for another interval within [minDisplayRefresh, maxDisplayRefresh].
+const (
+	defaultDisplayRefresh = 30
+	minDisplayRefresh     = 10
+	maxDisplayRefresh     = 600
+)
+
+// displaySeat is one player's line on the alphabetical pairings display.
+type displaySeat struct {
+	// ID is the player's engine player ID.
+	ID       int
+	Name     string
+	Table    int
+	Opponent string
+	IsBye    bool
+	// White is set for player A of a match, who plays white when the
+	// tournament tracks colours.
+	White bool
+}
+
+// displayRefresh reads the ?refresh= interval in seconds, clamped to the
+// allowed range.
+func displayRefresh(r *http.Request) int {
+	n, err := strconv.Atoi(r.URL.Query().Get("refresh"))
+	if err != nil {
+		return defaultDisplayRefresh
+	}
+	return min(max(n, minDisplayRefresh), maxDisplayRefresh)
+}
+
+// seatsByName lists every paired player, A or B side, sorted by name, so
+// players can find themselves on a long list.
+func seatsByName(pairings []resolvedPairing) []displaySeat {
+	var seats []displaySeat
+	for _, p := range pairings {
+		seats = append(seats, displaySeat{ID: p.PlayerAID, Name: p.PlayerAName, Table: p.Table, Opponent: p.PlayerBName, IsBye: p.IsBye, White: !p.IsBye})
+		if !p.IsBye {
+			seats = append(seats, displaySeat{ID: p.PlayerBID, Name: p.PlayerBName, Table: p.Table, Opponent: p.PlayerAName})
+		}
+	}
+	sort.SliceStable(seats, func(i, j int) bool {
+		return strings.ToLower(seats[i].Name) < strings.ToLower(seats[j].Name)
+	})
+	return seats
+}
+
+// loadDisplay fetches the tournament for a projector page and its engine, if
+// it has started. It writes the error response and returns ok=false when the
+// tournament doesn't exist.
+func (h *TournamentHandler) loadDisplay(w http.ResponseWriter, r *http.Request) (*models.Tournament, *swisstools.Tournament, bool) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return nil, nil, false
+	}
+	t, err := db.GetTournament(r.Context(), h.DB, id)
+	if err != nil {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return nil, nil, false
+	}
+	if len(t.EngineState) == 0 {
+		return t, nil, true
+	}
+	eng, err := swisstools.LoadTournament(t.EngineState)
+	if err != nil {
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return nil, nil, false
+	}
+	return t, &eng, true
+}
+
+// DisplayPairings shows the current round's pairings for a projector at the
+// venue: large type, no navigation, scrolling through long lists and
+// reloading on its own. ?by=name lists every player alphabetically with
+// their table instead of one row per table.
+func (h *TournamentHandler) DisplayPairings(w http.ResponseWriter, r *http.Request) {
+	t, eng, ok := h.loadDisplay(w, r)
+	if !ok {
+		return
+	}
+	var pairings []resolvedPairing
+	var currentRound int
+	var avatars map[int]*models.Avatar
+	if eng != nil {
+		avatars, _ = db.TournamentAvatars(r.Context(), h.DB, t.ID)
+		currentRound = eng.GetCurrentRound()
+		if !t.PairingsDraft(currentRound) {
+			pairings = resolvePairings(eng, eng.GetRound())
+		}
+		attachResultTypes(r.Context(), h.DB, t.ID, currentRound, pairings)
+		attachFeatureMatches(r.Context(), h.DB, t.ID, currentRound, pairings)
+		pinFeatureMatches(pairings)
+	}
+	h.Views.View(r, t.ID, currentRound, models.PagePairings)
+	byName := r.URL.Query().Get("by") == "name"
+	var seats []displaySeat
+	if byName {
+		seats = seatsByName(pairings)
+	}
+	h.Tmpl.ExecuteTemplate(w, "display_pairings.html", map[string]interface{}{
+		"Theme":         middleware.Theme(r),
+		"Flash":         middleware.Flash(r),
+		"Lang":          middleware.Locale(r),
+		"Display":       true,
+		"Refresh":       displayRefresh(r),
+		"Tournament":    t,
+		"CurrentRound":  currentRound,
+		"Pairings":      pairings,
+		"Avatars":       avatars,
+		"Announcements": activeAnnouncements(r.Context(), h.DB, t.ID),
+		"ByName":        byName,
+		"Seats":         seats,
+	})
+}
+
+// DisplayStandings is the projector version of the standings table. In a
+// blind final round it shows a notice instead, to staff too: the screen
+// faces the room.
+func (h *TournamentHandler) DisplayStandings(w http.ResponseWriter, r *http.Request) {
+	t, eng, ok := h.loadDisplay(w, r)
+	if !ok {
+		return
+	}
+	var standings []swisstools.PlayerStanding
+	var currentRound int
+	var avatars map[int]*models.Avatar
+	var hidden bool
+	if eng != nil {
+		currentRound = eng.GetCurrentRound()
+		hidden = engine.StandingsHidden(t, eng)
+	}
+	if eng != nil && !hidden {
+		avatars, _ = db.TournamentAvatars(r.Context(), h.DB, t.ID)
+		regs, _ := db.ListRegistrations(r.Context(), h.DB, t.ID)
+		standings = engine.CachedStandings(t, eng, engine.TiebreakSeeds(regs))
+	}
+	h.Views.View(r, t.ID, currentRound, models.PageStandings)
+	h.Tmpl.ExecuteTemplate(w, "display_standings.html", map[string]interface{}{
+		"Theme":         middleware.Theme(r),
+		"Flash":         middleware.Flash(r),
+		"Lang":          middleware.Locale(r),
+		"Display":       true,
+		"Refresh":       displayRefresh(r),
+		"Tournament":    t,
+		"CurrentRound":  currentRound,
+		"Standings":     standings,
+		"Hidden":        hidden,
+		"Avatars":       avatars,
+		"Announcements": activeAnnouncements(r.Context(), h.DB, t.ID),
+	})
+}
@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDisplayRefresh(t *testing.T) {
+	for query, want := range map[string]int{
+		"":              defaultDisplayRefresh,
+		"?refresh=x":    defaultDisplayRefresh,
+		"?refresh=45":   45,
+		"?refresh=1":    minDisplayRefresh,
+		"?refresh=9999": maxDisplayRefresh,
+	} {
+		if got := displayRefresh(httptest.NewRequest("GET", "/"+query, nil)); got != want {
+			t.Errorf("%q: refresh = %d, want %d", query, got, want)
+		}
+	}
+}
+
+func TestSeatsByName(t *testing.T) {
+	seats := seatsByName([]resolvedPairing{
+		{Table: 1, PlayerAName: "dora", PlayerBName: "Bob"},
+		{Table: 2, PlayerAName: "Cleo", PlayerBName: "alice"},
+		{PlayerAName: "Eve", IsBye: true},
+	})
+	want := []displaySeat{
+		{Name: "alice", Table: 2, Opponent: "Cleo"},
+		{Name: "Bob", Table: 1, Opponent: "dora"},
+		{Name: "Cleo", Table: 2, Opponent: "alice", White: true},
+		{Name: "dora", Table: 1, Opponent: "Bob", White: true},
+		{Name: "Eve", IsBye: true},
+	}
+	if len(seats) != len(want) {
+		t.Fatalf("seats = %+v", seats)
+	}
+	for i := range want {
+		if seats[i] != want[i] {
+			t.Errorf("seat %d = %+v, want %+v", i, seats[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/middleware"
+)
+
+// ErrorHandler renders the page shown when a request fails unexpectedly.
+type ErrorHandler struct {
+	Tmpl TemplateRenderer
+}
+
+// Internal answers 500 with the error page, quoting the request ID so a
+// report can be matched to the logs. If the page won't render either, the
+// answer is plain text.
+func (h *ErrorHandler) Internal(w http.ResponseWriter, r *http.Request) {
+	h.Page(w, r, http.StatusInternalServerError, "")
+}
+
+// Page answers status with the error page, showing msg, the reason a
+// handler gave http.Error (see middleware.ErrorPages), and a link back to
+// the page the visitor came from. A 500 quotes the request ID instead of
+// its message, which is no use to the visitor.
+func (h *ErrorHandler) Page(w http.ResponseWriter, r *http.Request, status int, msg string) {
+	data := map[string]interface{}{
+		"User":      middleware.GetUser(r.Context()),
+		"CSRFToken": middleware.CSRFToken(r),
+		"Theme":     middleware.Theme(r),
+		"Lang":      middleware.Locale(r),
+		"Status":    status,
+		"Back":      backPath(r),
+	}
+	if status >= http.StatusInternalServerError {
+		data["RequestID"] = middleware.RequestIDOf(r.Context())
+	} else {
+		data["Message"] = msg
+	}
+	var buf bytes.Buffer
+	if err := h.Tmpl.ExecuteTemplate(&buf, "error.html", data); err != nil {
+		if msg == "" {
+			msg = http.StatusText(status)
+		}
+		http.Error(w, msg, status)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	_, _ = buf.WriteTo(w)
+}
+
+// engineError answers a tournament change that failed: 503 with Retry-After
+// if the tournament was busy (see db.ErrBusy), else 400 with the reason. A
+// form submitted from a page is sent back to it instead, with the reason
+// as an error banner.
+func engineError(w http.ResponseWriter, r *http.Request, err error) {
+	if middleware.WantsHTML(r) {
+		msg := err.Error()
+		if errors.Is(err, db.ErrBusy) {
+			msg = db.ErrBusy.Error()
+		}
+		middleware.SetFlash(w, r, middleware.FlashError, msg)
+		http.Redirect(w, r, backPath(r), http.StatusSeeOther)
+		return
+	}
+	if errors.Is(err, db.ErrBusy) {
+		w.Header().Set("Retry-After", "5")
+		http.Error(w, db.ErrBusy.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	http.Error(w, err.Error(), http.StatusBadRequest)
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/middleware"
+)
+
+type failingTemplate struct{}
+
+func (failingTemplate) ExecuteTemplate(io.Writer, string, interface{}) error {
+	return errors.New("broken")
+}
+
+func TestErrorHandler_Internal(t *testing.T) {
+	tmpl := &mockTemplate{}
+	h := &ErrorHandler{Tmpl: tmpl}
+	req := httptest.NewRequest("GET", "/tournaments/3", nil)
+	rec := httptest.NewRecorder()
+	h.Internal(rec, req)
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want 500", rec.Code)
+	}
+	if len(tmpl.calls) != 1 || tmpl.calls[0].Name != "error.html" {
+		t.Fatalf("rendered %v, want error.html", tmpl.calls)
+	}
+	if _, ok := tmpl.calls[0].Data.(map[string]interface{})["RequestID"]; !ok {
+		t.Error("the page gets no request ID to quote")
+	}
+}
+
+func TestErrorHandler_InternalFallsBackToText(t *testing.T) {
+	h := &ErrorHandler{Tmpl: failingTemplate{}}
+	rec := httptest.NewRecorder()
+	h.Internal(rec, httptest.NewRequest("GET", "/", nil))
+	if rec.Code != http.StatusInternalServerError || rec.Body.String() != "Internal Server Error\n" {
+		t.Errorf("got %d %q", rec.Code, rec.Body.String())
+	}
+}
+
+func TestErrorHandler_Page(t *testing.T) {
+	tmpl := &mockTemplate{}
+	h := &ErrorHandler{Tmpl: tmpl}
+	req := httptest.NewRequest("POST", "/tournaments/3/players", nil)
+	req.Header.Set("Referer", "http://localhost/tournaments/3/manage/players")
+	rec := httptest.NewRecorder()
+	h.Page(rec, req, http.StatusNotFound, "Player not found")
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", rec.Code)
+	}
+	data := tmpl.calls[0].Data.(map[string]interface{})
+	if data["Message"] != "Player not found" || data["Back"] != "/tournaments/3/manage/players" {
+		t.Errorf("message %v, back %v", data["Message"], data["Back"])
+	}
+	if _, ok := data["RequestID"]; ok {
+		t.Error("a 404 quotes a request ID")
+	}
+}
+
+func TestEngineError(t *testing.T) {
+	req := httptest.NewRequest("POST", "/tournaments/1/next-round", nil)
+	rec := httptest.NewRecorder()
+	engineError(rec, req, fmt.Errorf("get tournament: %w", db.ErrBusy))
+	if rec.Code != http.StatusServiceUnavailable || rec.Header().Get("Retry-After") == "" {
+		t.Errorf("busy: got %d, Retry-After %q", rec.Code, rec.Header().Get("Retry-After"))
+	}
+	if rec.Body.String() != db.ErrBusy.Error()+"\n" {
+		t.Errorf("busy: body %q", rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	engineError(rec, req, errors.New("round 2 is not complete"))
+	if rec.Code != http.StatusBadRequest || rec.Body.String() != "round 2 is not complete\n" {
+		t.Errorf("refused: got %d %q", rec.Code, rec.Body.String())
+	}
+
+	// A form posted from a page goes back to it, with the reason to show.
+	req.Header.Set("Accept", "text/html")
+	req.Header.Set("Referer", "http://localhost/tournaments/1/manage/rounds")
+	rec = httptest.NewRecorder()
+	engineError(rec, req, errors.New("round 2 is not complete"))
+	if rec.Code != http.StatusSeeOther || rec.Header().Get("Location") != "/tournaments/1/manage/rounds" {
+		t.Errorf("form: got %d to %q", rec.Code, rec.Header().Get("Location"))
+	}
+	if cookie := rec.Result().Cookies(); len(cookie) != 1 || cookie[0].Name != middleware.FlashCookie {
+		t.Errorf("form: cookies %v, want the flash", cookie)
+	}
+}
@@ -0,0 +1,207 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/engine"
+	"github.com/dstathis/openswiss/internal/export"
+	"github.com/dstathis/openswiss/internal/middleware"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/dstathis/openswiss/internal/pdf"
+	"github.com/dstathis/swisstools"
+	"github.com/go-chi/chi/v5"
+)
+
+// sendPDF sends what render writes as a PDF download.
+func sendPDF(w http.ResponseWriter, r *http.Request, filename string, render func(io.Writer) error) {
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	if err := render(w); err != nil {
+		slog.ErrorContext(r.Context(), "render pdf", "filename", filename, "err", err)
+	}
+}
+
+// exportTournament authorizes a judge to print from a started tournament
+// and loads it. It writes the error response and returns ok false if not.
+func (h *TournamentHandler) exportTournament(w http.ResponseWriter, r *http.Request) (t *models.Tournament, eng *swisstools.Tournament, ok bool) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if !middleware.AuthorizeTournament(w, r, h.DB, id, models.TierJudge) {
+		return nil, nil, false
+	}
+	t, err := db.GetTournament(r.Context(), h.DB, id)
+	if err != nil {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return nil, nil, false
+	}
+	if len(t.EngineState) == 0 {
+		http.Error(w, "The tournament hasn't started", http.StatusNotFound)
+		return nil, nil, false
+	}
+	loaded, err := swisstools.LoadTournament(t.EngineState)
+	if err != nil {
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return nil, nil, false
+	}
+	return t, &loaded, true
+}
+
+// ExportSlips downloads the current round's match slips, four to a page,
+// with each table's kiosk PIN when the kiosk is on and the players' colours
+// when the tournament tracks them. Byes get no slip.
+// Min tier: Judge.
+func (h *TournamentHandler) ExportSlips(w http.ResponseWriter, r *http.Request) {
+	t, eng, ok := h.exportTournament(w, r)
+	if !ok {
+		return
+	}
+	var pins map[int]string
+	if secret, err := db.GetKioskSecret(r.Context(), h.DB, t.ID); err == nil && secret != "" {
+		pins = engine.TablePINs(secret, eng)
+	}
+	round := eng.GetCurrentRound()
+	var slips []pdf.Slip
+	for _, p := range resolvePairings(eng, eng.GetRound()) {
+		if p.IsBye {
+			continue
+		}
+		slips = append(slips, pdf.Slip{
+			Tournament: t.Name,
+			Round:      round,
+			Table:      p.Table,
+			PlayerA:    p.PlayerAName,
+			PlayerB:    p.PlayerBName,
+			PIN:        pins[p.Table],
+			Colors:     t.Colors,
+		})
+	}
+	sendPDF(w, r, fmt.Sprintf("slips-%d-round-%d.pdf", t.ID, round), func(w io.Writer) error {
+		return pdf.RenderSlips(w, slips)
+	})
+}
+
+// ExportSeatings downloads the current round's pairings by player name,
+// for posting at the venue, with a colour column when the tournament tracks
+// colours. Min tier: Judge.
+func (h *TournamentHandler) ExportSeatings(w http.ResponseWriter, r *http.Request) {
+	t, eng, ok := h.exportTournament(w, r)
+	if !ok {
+		return
+	}
+	columns := []pdf.Column{{Heading: "Player", Width: 230}, {Heading: "Table", Width: 60}, {Heading: "Opponent", Width: 233}}
+	row := func(player, table, colour, opponent string) []string {
+		return []string{player, table, opponent}
+	}
+	if t.Colors {
+		columns = []pdf.Column{{Heading: "Player", Width: 200}, {Heading: "Table", Width: 60}, {Heading: "Colour", Width: 60}, {Heading: "Opponent", Width: 203}}
+		row = func(player, table, colour, opponent string) []string {
+			return []string{player, table, colour, opponent}
+		}
+	}
+	var rows [][]string
+	for _, p := range resolvePairings(eng, eng.GetRound()) {
+		if p.IsBye {
+			rows = append(rows, row(p.PlayerAName, "-", "-", "Bye"))
+			continue
+		}
+		table := strconv.Itoa(p.Table)
+		rows = append(rows,
+			row(p.PlayerAName, table, "White", p.PlayerBName),
+			row(p.PlayerBName, table, "Black", p.PlayerAName))
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		return strings.ToLower(rows[i][0]) < strings.ToLower(rows[j][0])
+	})
+	round := eng.GetCurrentRound()
+	sendPDF(w, r, fmt.Sprintf("seatings-%d-round-%d.pdf", t.ID, round), func(w io.Writer) error {
+		return pdf.RenderTable(w, pdf.Table{
+			Title:    t.Name + " - Seatings",
+			Subtitle: fmt.Sprintf("Round %d, by player", round),
+			Columns:  columns,
+			Rows:     rows,
+			Empty:    "Round " + strconv.Itoa(round) + " has no pairings yet.",
+		})
+	})
+}
+
+// ExportRatings downloads the Elo changes of a finished tournament's rated
+// players as CSV (see engine.RatingChanges), with the K-factor from ?k=.
+// Min tier: Judge.
+func (h *TournamentHandler) ExportRatings(w http.ResponseWriter, r *http.Request) {
+	k, err := engine.ParseRatingK(r.URL.Query().Get("k"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	t, eng, ok := h.exportTournament(w, r)
+	if !ok {
+		return
+	}
+	if !engine.Complete(t, eng) {
+		http.Error(w, "The tournament is not finished", http.StatusBadRequest)
+		return
+	}
+	regs, _ := db.ListRegistrations(r.Context(), h.DB, t.ID)
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="ratings-%d.csv"`, t.ID))
+	out := csv.NewWriter(w)
+	out.Write([]string{"name", "rating", "matches", "score", "expected", "change", "new_rating"})
+	for _, c := range engine.RatingChanges(regs, eng, k) {
+		out.Write([]string{
+			export.CSVCell(c.Name),
+			strconv.Itoa(c.Rating),
+			strconv.Itoa(c.Matches),
+			strconv.FormatFloat(c.Score, 'f', -1, 64),
+			fmt.Sprintf("%.2f", c.Expected),
+			strconv.Itoa(c.Delta),
+			strconv.Itoa(c.New),
+		})
+	}
+	out.Flush()
+}
+
+// ExportStandings downloads the standings with their tiebreakers.
+// Min tier: Judge.
+func (h *TournamentHandler) ExportStandings(w http.ResponseWriter, r *http.Request) {
+	t, eng, ok := h.exportTournament(w, r)
+	if !ok {
+		return
+	}
+	regs, _ := db.ListRegistrations(r.Context(), h.DB, t.ID)
+	var rows [][]string
+	for _, s := range engine.CachedStandings(t, eng, engine.TiebreakSeeds(regs)) {
+		rows = append(rows, []string{
+			strconv.Itoa(s.Rank),
+			s.Name,
+			strconv.Itoa(s.Points),
+			fmt.Sprintf("%d-%d-%d", s.Wins, s.Losses, s.Draws),
+			fmt.Sprintf("%.1f%%", 100*s.Tiebreakers.OpponentMatchWinPct),
+			fmt.Sprintf("%.1f%%", 100*s.Tiebreakers.GameWinPercentage),
+			fmt.Sprintf("%.1f%%", 100*s.Tiebreakers.OpponentGameWinPct),
+		})
+	}
+	subtitle := fmt.Sprintf("Round %d", eng.GetCurrentRound())
+	if t.Status == models.TournamentStatusFinished {
+		subtitle = "Final"
+	}
+	sendPDF(w, r, fmt.Sprintf("standings-%d.pdf", t.ID), func(w io.Writer) error {
+		return pdf.RenderTable(w, pdf.Table{
+			Title:    t.Name + " - Standings",
+			Subtitle: subtitle,
+			Columns: []pdf.Column{
+				{Heading: "Rank", Width: 40}, {Heading: "Player", Width: 193}, {Heading: "Points", Width: 50},
+				{Heading: "W-L-D", Width: 60}, {Heading: "OMW%", Width: 60}, {Heading: "GW%", Width: 60},
+				{Heading: "OGW%", Width: 60},
+			},
+			Rows:  rows,
+			Empty: "No standings yet.",
+		})
+	})
+}
@@ -0,0 +1,107 @@
+//go:build integration
+
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+func TestTournamentHandler_ExportPDFs(t *testing.T) {
+	database := testDB(t)
+	h := &TournamentHandler{DB: database, Tmpl: &mockTemplate{}}
+	owner, tourn := startedTournament(t, database)
+	outsider := mustCreateUser(t, database, "out-export@example.com", "OutExport")
+	params := map[string]string{"id": strconv.FormatInt(tourn.ID, 10)}
+
+	for name, handler := range map[string]http.HandlerFunc{
+		"slips":     h.ExportSlips,
+		"seatings":  h.ExportSeatings,
+		"standings": h.ExportStandings,
+	} {
+		rec := httptest.NewRecorder()
+		handler(rec, requestWithUser("GET", "/", "", owner, params))
+		if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "application/pdf" {
+			t.Fatalf("%s: status %d, content type %q", name, rec.Code, rec.Header().Get("Content-Type"))
+		}
+		if !bytes.HasPrefix(rec.Body.Bytes(), []byte("%PDF-")) {
+			t.Errorf("%s: not a PDF", name)
+		}
+		if !bytes.Contains(rec.Body.Bytes(), []byte("(P0-"+t.Name()+")")) {
+			t.Errorf("%s: lacks player P0", name)
+		}
+
+		rec = httptest.NewRecorder()
+		handler(rec, requestWithUser("GET", "/", "", outsider, params))
+		if rec.Code != http.StatusForbidden {
+			t.Errorf("%s: outsider got status %d, want 403", name, rec.Code)
+		}
+	}
+
+	// Four players: two slips, and every player seated once.
+	rec := httptest.NewRecorder()
+	h.ExportSlips(rec, requestWithUser("GET", "/", "", owner, params))
+	if n := bytes.Count(rec.Body.Bytes(), []byte("(Table ")); n != 2 {
+		t.Errorf("%d slips, want 2", n)
+	}
+	if bytes.Contains(rec.Body.Bytes(), []byte("Kiosk PIN")) {
+		t.Error("PIN printed with the kiosk off")
+	}
+	h.EnableKiosk(httptest.NewRecorder(), requestWithUser("POST", "/", "", owner, params))
+	rec = httptest.NewRecorder()
+	h.ExportSlips(rec, requestWithUser("GET", "/", "", owner, params))
+	if n := bytes.Count(rec.Body.Bytes(), []byte("(Kiosk PIN: ")); n != 2 {
+		t.Errorf("%d slips with a PIN, want 2", n)
+	}
+}
+
+func TestTournamentHandler_ExportRatings(t *testing.T) {
+	database := testDB(t)
+	h := &TournamentHandler{DB: database, Tmpl: &mockTemplate{}}
+	owner, tourn := startedTournament(t, database)
+	params := map[string]string{"id": strconv.FormatInt(tourn.ID, 10)}
+
+	rec := httptest.NewRecorder()
+	h.ExportRatings(rec, requestWithUser("GET", "/", "", owner, params))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("running tournament: status %d, want 400", rec.Code)
+	}
+
+	regs, _ := db.ListRegistrations(context.Background(), database, tourn.ID)
+	rating := 1600
+	if err := db.SetRatings(context.Background(), database, tourn.ID, map[int64]*int{regs[0].ID: &rating}); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.UpdateTournamentStatus(context.Background(), database, tourn.ID, models.TournamentStatusFinished); err != nil {
+		t.Fatal(err)
+	}
+	rec = httptest.NewRecorder()
+	h.ExportRatings(rec, requestWithUser("GET", "/?k=16", "", owner, params))
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "text/csv; charset=utf-8" {
+		t.Fatalf("status %d, content type %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	// One rated player, whose matches were all against unrated players.
+	want := "name,rating,matches,score,expected,change,new_rating\n" + regs[0].DisplayName + ",1600,0,0,0.00,0,1600\n"
+	if rec.Body.String() != want {
+		t.Errorf("CSV = %q, want %q", rec.Body.String(), want)
+	}
+}
+
+func TestTournamentHandler_ExportBeforeStart(t *testing.T) {
+	database := testDB(t)
+	h := &TournamentHandler{DB: database, Tmpl: &mockTemplate{}}
+	owner := mustCreateUser(t, database, "owner-export-ns@example.com", "OwnerExportNS")
+	tourn := mustCreateTournament(t, database, owner.ID, models.TournamentStatusRegistrationOpen)
+	rec := httptest.NewRecorder()
+	h.ExportSlips(rec, requestWithUser("GET", "/", "", owner, map[string]string{"id": strconv.FormatInt(tourn.ID, 10)}))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status %d, want 404", rec.Code)
+	}
+}
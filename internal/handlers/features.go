@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/engine"
+	"github.com/dstathis/openswiss/internal/middleware"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/go-chi/chi/v5"
+)
+
+// attachFeatureMatches marks the round's feature matches and returns their
+// tables.
+func attachFeatureMatches(ctx context.Context, database db.DBTX, tournamentID int64, round int, pairings []resolvedPairing) []int {
+	tables, err := db.ListFeatureMatches(ctx, database, tournamentID, round)
+	if err != nil || len(tables) == 0 {
+		return nil
+	}
+	featured := make(map[int]bool, len(tables))
+	for _, table := range tables {
+		featured[table] = true
+	}
+	for i := range pairings {
+		pairings[i].Feature = pairings[i].Table > 0 && featured[pairings[i].Table]
+	}
+	return tables
+}
+
+// pinFeatureMatches moves the feature matches to the top of pairings, the
+// stream area, keeping the rest in table order.
+func pinFeatureMatches(pairings []resolvedPairing) {
+	sort.SliceStable(pairings, func(i, j int) bool {
+		return pairings[i].Feature && !pairings[j].Feature
+	})
+}
+
+// formatTables writes tables the way the feature matches form takes them.
+func formatTables(tables []int) string {
+	parts := make([]string, len(tables))
+	for i, table := range tables {
+		parts[i] = strconv.Itoa(table)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// SetFeatureMatches sets the current round's feature matches from the
+// dashboard. Form field: tables, table numbers separated by commas or
+// spaces; blank features none. Min tier: Judge.
+func (h *TournamentHandler) SetFeatureMatches(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if !middleware.AuthorizeTournament(w, r, h.DB, id, models.TierJudge) {
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+	var tables []int
+	for _, f := range strings.FieldsFunc(r.FormValue("tables"), func(c rune) bool { return c == ',' || c == ' ' }) {
+		table, err := strconv.Atoi(f)
+		if err != nil {
+			http.Error(w, "Tables must be table numbers, such as 1, 4", http.StatusBadRequest)
+			return
+		}
+		tables = append(tables, table)
+	}
+	if _, _, err := engine.SetFeatureMatches(r.Context(), h.DB, id, tables); err != nil {
+		if errors.Is(err, engine.ErrNoRoundUnderway) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	http.Redirect(w, r, fmt.Sprintf("/tournaments/%d/manage/rounds#feature-matches", id), http.StatusSeeOther)
+}
@@ -0,0 +1,67 @@
+//go:build integration
+
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/db"
+)
+
+func TestTournamentHandler_FeatureMatches(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	tmpl := &mockTemplate{}
+	h := &TournamentHandler{DB: database, Tmpl: tmpl}
+	owner, tourn := startedTournament(t, database)
+	params := map[string]string{"id": strconv.FormatInt(tourn.ID, 10)}
+
+	post := func(tables string) int {
+		rec := httptest.NewRecorder()
+		h.SetFeatureMatches(rec, requestWithUser("POST", "/", url.Values{"tables": {tables}}.Encode(), owner, params))
+		return rec.Code
+	}
+	for _, bad := range []string{"one", "7"} {
+		if code := post(bad); code != http.StatusBadRequest {
+			t.Errorf("tables %q: status %d, want 400", bad, code)
+		}
+	}
+	if code := post("2"); code != http.StatusSeeOther {
+		t.Fatalf("feature table 2: status %d", code)
+	}
+	if got, _ := db.ListFeatureMatches(ctx, database, tourn.ID, 1); !reflect.DeepEqual(got, []int{2}) {
+		t.Fatalf("feature matches = %v, want [2]", got)
+	}
+
+	// Table 2 leads the public pairings, still numbered 2.
+	rec := httptest.NewRecorder()
+	h.Detail(rec, requestWithUser("GET", "/", "", nil, params))
+	data := tmpl.calls[len(tmpl.calls)-1].Data.(map[string]interface{})
+	pairings := data["Pairings"].([]resolvedPairing)
+	if len(pairings) != 2 || pairings[0].Table != 2 || !pairings[0].Feature || pairings[1].Feature {
+		t.Errorf("detail pairings = %+v", pairings)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ManageRoundsPage(rec, requestWithUser("GET", "/", "", owner, params))
+	data = tmpl.calls[len(tmpl.calls)-1].Data.(map[string]interface{})
+	if data["FeatureTables"] != "2" {
+		t.Errorf("form tables = %v, want 2", data["FeatureTables"])
+	}
+	if pairings := data["Pairings"].([]resolvedPairing); pairings[0].Table != 1 || !pairings[1].Feature {
+		t.Errorf("manage pairings moved or unmarked: %+v", pairings)
+	}
+
+	if code := post(""); code != http.StatusSeeOther {
+		t.Fatalf("clear: status %d", code)
+	}
+	if got, _ := db.ListFeatureMatches(ctx, database, tourn.ID, 1); len(got) != 0 {
+		t.Errorf("after clearing = %v", got)
+	}
+}
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// defaultPageSize is how many rows a long table on a web page shows at a
+// time; ?per_page= picks another size up to maxPageSize.
+const (
+	defaultPageSize = 50
+	maxPageSize     = 100
+)
+
+// pager describes the page of a filtered table being shown, with links to
+// its neighbours that keep the rest of the query string.
+type pager struct {
+	Page  int
+	Pages int
+	// All counts the rows before the name search, Total after it.
+	All     int
+	Total   int
+	PrevURL string
+	NextURL string
+}
+
+// nameQuery returns the page's ?q= player search.
+func nameQuery(r *http.Request) string {
+	return strings.TrimSpace(r.URL.Query().Get("q"))
+}
+
+// matchesName reports whether any of names contains q, ignoring case. An
+// empty q matches everything.
+func matchesName(q string, names ...string) bool {
+	if q == "" {
+		return true
+	}
+	q = strings.ToLower(q)
+	for _, n := range names {
+		if strings.Contains(strings.ToLower(n), q) {
+			return true
+		}
+	}
+	return false
+}
+
+// filterPage keeps the items that match and returns the page of them named
+// by the param query parameter. anchor is the id of the table's section, so
+// the pager links land back on it.
+func filterPage[T any](r *http.Request, param, anchor string, items []T, match func(T) bool) ([]T, pager) {
+	var kept []T
+	for _, it := range items {
+		if match(it) {
+			kept = append(kept, it)
+		}
+	}
+	q := r.URL.Query()
+	perPage := defaultPageSize
+	if v, err := strconv.Atoi(q.Get("per_page")); err == nil && v > 0 && v <= maxPageSize {
+		perPage = v
+	}
+	pg := pager{Page: 1, All: len(items), Total: len(kept), Pages: max((len(kept)+perPage-1)/perPage, 1)}
+	if v, err := strconv.Atoi(q.Get(param)); err == nil && v > 0 {
+		pg.Page = min(v, pg.Pages)
+	}
+	link := func(page int) string {
+		q.Set(param, strconv.Itoa(page))
+		return r.URL.Path + "?" + q.Encode() + "#" + anchor
+	}
+	if pg.Page > 1 {
+		pg.PrevURL = link(pg.Page - 1)
+	}
+	if pg.Page < pg.Pages {
+		pg.NextURL = link(pg.Page + 1)
+	}
+	start := (pg.Page - 1) * perPage
+	return kept[start:min(start+perPage, len(kept))], pg
+}
@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMatchesName(t *testing.T) {
+	if !matchesName("", "anyone") {
+		t.Error("empty search should match")
+	}
+	if !matchesName("ali", "Bob", "ALICE") {
+		t.Error("search should ignore case and check every name")
+	}
+	if matchesName("zed", "Bob", "Alice") {
+		t.Error("zed matched")
+	}
+}
+
+func TestFilterPage(t *testing.T) {
+	items := make([]int, 120)
+	for i := range items {
+		items[i] = i
+	}
+	even := func(n int) bool { return n%2 == 0 }
+
+	r := httptest.NewRequest("GET", "/tournaments/1?q=x&rows_page=2", nil)
+	rows, pg := filterPage(r, "rows_page", "rows", items, even)
+	if pg.All != 120 || pg.Total != 60 || pg.Page != 2 || pg.Pages != 2 {
+		t.Errorf("pager = %+v", pg)
+	}
+	if len(rows) != 10 || rows[0] != 100 {
+		t.Errorf("page 2 = %v", rows)
+	}
+	if pg.PrevURL != "/tournaments/1?q=x&rows_page=1#rows" || pg.NextURL != "" {
+		t.Errorf("links = %q, %q", pg.PrevURL, pg.NextURL)
+	}
+
+	// Out-of-range pages clamp; per_page picks the size.
+	r = httptest.NewRequest("GET", "/?rows_page=99&per_page=7", nil)
+	if rows, pg = filterPage(r, "rows_page", "rows", items, even); pg.Page != 9 || len(rows) != 4 {
+		t.Errorf("clamped page %d has %d rows", pg.Page, len(rows))
+	}
+
+	rows, pg = filterPage(httptest.NewRequest("GET", "/", nil), "rows_page", "rows", items, func(int) bool { return false })
+	if len(rows) != 0 || pg.Pages != 1 || pg.PrevURL != "" || pg.NextURL != "" {
+		t.Errorf("no matches: %v, %+v", rows, pg)
+	}
+}
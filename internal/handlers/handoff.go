@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/dstathis/openswiss/internal/auth"
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/middleware"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/go-chi/chi/v5"
+)
+
+// CreateHandoff issues an admin handoff code for the tournament and shows it
+// on the staff page, once. Issuing a code expires any earlier unclaimed one.
+// Min tier: Admin.
+func (h *StaffHandler) CreateHandoff(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if !middleware.AuthorizeTournament(w, r, h.DB, id, models.TierAdmin) {
+		return
+	}
+	t, err := db.GetTournament(r.Context(), h.DB, id)
+	if err != nil {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+	code, codeHash, err := auth.GenerateHandoffCode()
+	if err != nil {
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	user := middleware.GetUser(r.Context())
+	if err := db.CreateHandoff(r.Context(), h.DB, id, user.ID, codeHash, time.Now().Add(models.HandoffTTL)); err != nil {
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	slog.InfoContext(r.Context(), "tournament handoff code issued", "tournament_id", id, "user_id", user.ID)
+	h.renderStaffPage(w, r, t, code)
+}
+
+func (h *StaffHandler) renderClaimHandoff(w http.ResponseWriter, r *http.Request, errMsg string) {
+	h.Tmpl.ExecuteTemplate(w, "handoff_claim.html", map[string]interface{}{
+		"User":      middleware.GetUser(r.Context()),
+		"CSRFToken": middleware.CSRFToken(r),
+		"Theme":     middleware.Theme(r),
+		"Flash":     middleware.Flash(r),
+		"Lang":      middleware.Locale(r),
+		"Error":     errMsg,
+	})
+}
+
+func (h *StaffHandler) ClaimHandoffPage(w http.ResponseWriter, r *http.Request) {
+	h.renderClaimHandoff(w, r, "")
+}
+
+// ClaimHandoff takes over admin rights on a tournament with a handoff code.
+// The issuing admin is removed from the tournament's staff and logged out
+// everywhere.
+func (h *StaffHandler) ClaimHandoff(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+	user := middleware.GetUser(r.Context())
+	handoff, err := db.ClaimHandoff(r.Context(), h.DB, auth.HashHandoffCode(r.FormValue("code")), user.ID)
+	switch {
+	case errors.Is(err, db.ErrHandoffInvalid):
+		h.renderClaimHandoff(w, r, "That code is invalid, expired or already used.")
+		return
+	case errors.Is(err, db.ErrHandoffSelf):
+		h.renderClaimHandoff(w, r, "You issued this code; it must be claimed by someone else.")
+		return
+	case err != nil:
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	logHandoff(r, handoff)
+	http.Redirect(w, r, fmt.Sprintf("/tournaments/%d/manage", handoff.TournamentID), http.StatusSeeOther)
+}
+
+// logHandoff records a completed admin transfer. The handoff row is the
+// durable record; this puts it in the server log too.
+func logHandoff(r *http.Request, h *models.TournamentHandoff) {
+	var from int64
+	if h.CreatedBy != nil {
+		from = *h.CreatedBy
+	}
+	slog.InfoContext(r.Context(), "tournament admin handed off",
+		"tournament_id", h.TournamentID, "from_user_id", from, "to_user_id", *h.ClaimedBy)
+}
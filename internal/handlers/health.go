@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"time"
+)
+
+// HealthHandler answers the probes container orchestrators poll.
+type HealthHandler struct {
+	DB *sql.DB
+}
+
+// Live reports that the process is up, so orchestrators know whether to
+// restart it.
+func (h *HealthHandler) Live(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte("ok"))
+}
+
+// Ready reports that the process is up and the database is reachable, to
+// gate load-balancer traffic. The ping gets two seconds, so a flaky database
+// doesn't hang the probe.
+func (h *HealthHandler) Ready(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 2*time.Second)
+	defer cancel()
+	if err := h.DB.PingContext(ctx); err != nil {
+		http.Error(w, "db unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte("ok"))
+}
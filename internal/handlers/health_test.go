@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	_ "github.com/lib/pq"
+)
+
+func TestHealthHandler(t *testing.T) {
+	// Nothing listens on port 1, so the ping fails straight away.
+	database, err := sql.Open("postgres", "postgres://127.0.0.1:1/openswiss?sslmode=disable&connect_timeout=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	h := &HealthHandler{DB: database}
+
+	rec := httptest.NewRecorder()
+	h.Live(rec, httptest.NewRequest("GET", "/healthz", nil))
+	if rec.Code != http.StatusOK || rec.Body.String() != "ok" {
+		t.Errorf("Live: status %d, body %q", rec.Code, rec.Body.String())
+	}
+	rec = httptest.NewRecorder()
+	h.Ready(rec, httptest.NewRequest("GET", "/readyz", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("Ready without a database: status %d, want 503", rec.Code)
+	}
+}
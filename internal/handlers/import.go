@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/engine"
+	"github.com/dstathis/openswiss/internal/middleware"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/dstathis/swisstools"
+	"github.com/go-chi/chi/v5"
+)
+
+// Import starts the tournament from a CSV of the rounds played so far in
+// another tool (see engine.ReadImportCSV and engine.ImportTournament), in
+// place of Start. Form field: file. Min tier: Co-organizer.
+func (h *TournamentHandler) Import(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if !middleware.AuthorizeTournament(w, r, h.DB, id, models.TierCoOrganizer) {
+		return
+	}
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		http.Error(w, "Choose a CSV file to import", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+	ev, err := engine.ReadImportCSV(file)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	regs, _ := db.ListRegistrations(r.Context(), h.DB, id)
+	err = engine.WithTournamentEngine(r.Context(), h.DB, id,
+		func(tx *sql.Tx, t *models.Tournament, eng *swisstools.Tournament) (string, error) {
+			state, err := engine.ImportTournament(r.Context(), tx, t, regs, ev)
+			if err != nil {
+				return "", err
+			}
+			newEng, err := swisstools.LoadTournament(state)
+			if err != nil {
+				return "", err
+			}
+			*eng = newEng
+			return models.TournamentStatusInProgress, nil
+		})
+	if err != nil {
+		engineError(w, r, err)
+		return
+	}
+	slog.Info("tournament imported", "tournament_id", id, "rounds", len(ev.Rounds),
+		"user_id", middleware.GetUser(r.Context()).ID)
+	http.Redirect(w, r, fmt.Sprintf("/tournaments/%d/manage/rounds", id), http.StatusSeeOther)
+}
@@ -0,0 +1,96 @@
+//go:build integration
+
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/dstathis/swisstools"
+)
+
+// importRequest builds a multipart upload of csv to tournament id.
+func importRequest(t *testing.T, user *models.User, id int64, csv string) *http.Request {
+	t.Helper()
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	part, err := mw.CreateFormFile("file", "rounds.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	part.Write([]byte(csv))
+	mw.Close()
+	req := requestWithUser("POST", "/", "", user, map[string]string{"id": strconv.FormatInt(id, 10)})
+	req.Body = io.NopCloser(&body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	return req
+}
+
+func TestTournamentHandler_Import(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	h := &TournamentHandler{DB: database, Tmpl: &mockTemplate{}}
+	owner := mustCreateUser(t, database, "import-owner@example.com", "ImportOwner")
+	tourn := mustCreateTournament(t, database, owner.ID, models.TournamentStatusRegistrationOpen)
+	if _, err := db.CreateGuestRegistration(ctx, database, tourn.ID, "Alice"); err != nil {
+		t.Fatal(err)
+	}
+	file := "Round,Table,Player,Opponent,Result\n" +
+		"1,1,alice,Bob,2-0\n1,2,Carol,Dan,1-2\n" +
+		"2,1,Alice,Dan,\n2,2,Bob,Carol,2-1\n"
+
+	// Alice is registered, so a file without her is refused.
+	rec := httptest.NewRecorder()
+	h.Import(rec, importRequest(t, owner, tourn.ID, "round,player,opponent,result\n1,Bob,Carol,2-0\n"))
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "Alice registered but not in the file") {
+		t.Fatalf("without Alice: status %d body %s", rec.Code, rec.Body.String())
+	}
+	if regs, _ := db.ListRegistrations(ctx, database, tourn.ID); len(regs) != 1 {
+		t.Errorf("refused import left %d registrations", len(regs))
+	}
+
+	rec = httptest.NewRecorder()
+	h.Import(rec, importRequest(t, owner, tourn.ID, file))
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("import: status %d body %s", rec.Code, rec.Body.String())
+	}
+	got, _ := db.GetTournament(ctx, database, tourn.ID)
+	if got.Status != models.TournamentStatusInProgress {
+		t.Errorf("status = %s", got.Status)
+	}
+	regs, _ := db.ListRegistrations(ctx, database, tourn.ID)
+	if len(regs) != 4 {
+		t.Fatalf("registrations = %+v", regs)
+	}
+	for _, r := range regs {
+		if r.EnginePlayerID == nil {
+			t.Errorf("%s has no engine player", r.DisplayName)
+		}
+	}
+	eng, err := swisstools.LoadTournament(got.EngineState)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if eng.GetCurrentRound() != 2 || len(eng.GetRound()) != 2 || eng.GetRound()[0].PlayerAWins() != swisstools.UNINITIALIZED_RESULT {
+		t.Errorf("round %d = %v", eng.GetCurrentRound(), eng.GetRound())
+	}
+	if p, _ := eng.GetPlayerById(*regs[0].EnginePlayerID); p.Name != "Alice" || p.Points != 3 {
+		t.Errorf("Alice = %+v", p)
+	}
+
+	// Only once.
+	rec = httptest.NewRecorder()
+	h.Import(rec, importRequest(t, owner, tourn.ID, file))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("second import: status %d, want 400", rec.Code)
+	}
+}
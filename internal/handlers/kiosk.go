@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/dstathis/openswiss/internal/auth"
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/engine"
+	"github.com/dstathis/openswiss/internal/middleware"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/dstathis/swisstools"
+	"github.com/go-chi/chi/v5"
+)
+
+// kioskSlip is one table's slip: who plays there and the PIN that lets
+// them report the result at the kiosk.
+type kioskSlip struct {
+	Table       int
+	PlayerAName string
+	PlayerBName string
+	PIN         string
+}
+
+// EnableKiosk turns on kiosk result entry, or gives it a new secret if it
+// is on, which changes every PIN. Min tier: Co-organizer.
+func (h *TournamentHandler) EnableKiosk(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if !middleware.AuthorizeTournament(w, r, h.DB, id, models.TierCoOrganizer) {
+		return
+	}
+	secret, err := auth.GenerateKioskSecret()
+	if err != nil {
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	if err := db.SetKioskSecret(r.Context(), h.DB, id, secret); err != nil {
+		http.Error(w, "Failed to turn on the kiosk", http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, r, fmt.Sprintf("/tournaments/%d/manage#kiosk", id), http.StatusSeeOther)
+}
+
+// DisableKiosk turns kiosk result entry off. Min tier: Co-organizer.
+func (h *TournamentHandler) DisableKiosk(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if !middleware.AuthorizeTournament(w, r, h.DB, id, models.TierCoOrganizer) {
+		return
+	}
+	if err := db.SetKioskSecret(r.Context(), h.DB, id, ""); err != nil {
+		http.Error(w, "Failed to turn off the kiosk", http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, r, fmt.Sprintf("/tournaments/%d/manage#kiosk", id), http.StatusSeeOther)
+}
+
+// KioskSlips is a printable page of the current round's slips, one per
+// table with its players and PIN. Min tier: Judge.
+func (h *TournamentHandler) KioskSlips(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if !middleware.AuthorizeTournament(w, r, h.DB, id, models.TierJudge) {
+		return
+	}
+	t, err := db.GetTournament(r.Context(), h.DB, id)
+	if err != nil {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+	secret, err := db.GetKioskSecret(r.Context(), h.DB, id)
+	if err != nil || secret == "" || len(t.EngineState) == 0 {
+		http.Error(w, "The kiosk is off or the tournament hasn't started", http.StatusNotFound)
+		return
+	}
+	eng, err := swisstools.LoadTournament(t.EngineState)
+	if err != nil {
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	pins := engine.TablePINs(secret, &eng)
+	var slips []kioskSlip
+	for _, p := range resolvePairings(&eng, eng.GetRound()) {
+		if pin, ok := pins[p.Table]; ok {
+			slips = append(slips, kioskSlip{Table: p.Table, PlayerAName: p.PlayerAName, PlayerBName: p.PlayerBName, PIN: pin})
+		}
+	}
+	h.Tmpl.ExecuteTemplate(w, "tournament_kiosk_slips.html", map[string]interface{}{
+		"User":       middleware.GetUser(r.Context()),
+		"CSRFToken":  middleware.CSRFToken(r),
+		"Theme":      middleware.Theme(r),
+		"Flash":      middleware.Flash(r),
+		"Lang":       middleware.Locale(r),
+		"Tournament": t,
+		"Round":      eng.GetCurrentRound(),
+		"Draft":      t.PairingsDraft(eng.GetCurrentRound()),
+		"KioskPath":  models.KioskPath(id),
+		"Slips":      slips,
+	})
+}
+
+// kioskTournament loads the tournament behind a kiosk URL and its secret.
+// It answers 404 and returns nil if there is no such tournament or its
+// kiosk is off.
+func (h *TournamentHandler) kioskTournament(w http.ResponseWriter, r *http.Request) (*models.Tournament, string) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	t, err := db.GetTournament(r.Context(), h.DB, id)
+	if err != nil {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return nil, ""
+	}
+	secret, err := db.GetKioskSecret(r.Context(), h.DB, id)
+	if err != nil || secret == "" {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return nil, ""
+	}
+	return t, secret
+}
+
+func (h *TournamentHandler) renderKiosk(w http.ResponseWriter, r *http.Request, t *models.Tournament, extra map[string]interface{}) {
+	data := map[string]interface{}{
+		"CSRFToken":  middleware.CSRFToken(r),
+		"Theme":      middleware.Theme(r),
+		"Flash":      middleware.Flash(r),
+		"Lang":       middleware.Locale(r),
+		"Kiosk":      true,
+		"Tournament": t,
+	}
+	for k, v := range extra {
+		data[k] = v
+	}
+	h.Tmpl.ExecuteTemplate(w, "kiosk.html", data)
+}
+
+// KioskPage is the kiosk's first step, where players enter their table
+// number and PIN. It needs no account. It has no navigation, so players at
+// a shared terminal stay on it.
+func (h *TournamentHandler) KioskPage(w http.ResponseWriter, r *http.Request) {
+	t, _ := h.kioskTournament(w, r)
+	if t == nil {
+		return
+	}
+	h.renderKiosk(w, r, t, nil)
+}
+
+// KioskSubmit handles both kiosk steps. With just table and pin it shows
+// the match at that table for the players to confirm; with confirm set it
+// also takes wins_a, wins_b and draws and records the result. A wrong
+// table or PIN, or a match the kiosk can't take, sends them back to the
+// first step with the reason.
+func (h *TournamentHandler) KioskSubmit(w http.ResponseWriter, r *http.Request) {
+	t, secret := h.kioskTournament(w, r)
+	if t == nil {
+		return
+	}
+	table, _ := strconv.Atoi(r.FormValue("table"))
+	pin := strings.TrimSpace(r.FormValue("pin"))
+
+	if r.FormValue("confirm") == "" {
+		if len(t.EngineState) == 0 {
+			h.renderKiosk(w, r, t, map[string]interface{}{"Error": "there is no round to report a result for"})
+			return
+		}
+		eng, err := swisstools.LoadTournament(t.EngineState)
+		if err != nil {
+			http.Error(w, "Internal error", http.StatusInternalServerError)
+			return
+		}
+		p, err := engine.KioskMatch(t, &eng, secret, table, pin)
+		if err != nil {
+			h.renderKiosk(w, r, t, map[string]interface{}{"Error": err.Error()})
+			return
+		}
+		resolved := resolvePairings(&eng, []swisstools.Pairing{p})[0]
+		h.renderKiosk(w, r, t, map[string]interface{}{
+			"Table":   table,
+			"PIN":     pin,
+			"Pairing": resolved,
+		})
+		return
+	}
+
+	wins, errA := strconv.Atoi(r.FormValue("wins_a"))
+	losses, errB := strconv.Atoi(r.FormValue("wins_b"))
+	draws, errD := strconv.Atoi(r.FormValue("draws"))
+	if err := errors.Join(errA, errB, errD); err != nil {
+		h.renderKiosk(w, r, t, map[string]interface{}{"Error": "enter the number of games each player won"})
+		return
+	}
+	err := engine.WithTournamentEngine(r.Context(), h.DB, t.ID,
+		func(tx *sql.Tx, t *models.Tournament, eng *swisstools.Tournament) (string, error) {
+			return "", engine.KioskReport(r.Context(), tx, t, eng, secret, table, pin, wins, losses, draws)
+		})
+	if errors.Is(err, db.ErrBusy) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Retry-After", "5")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		h.renderKiosk(w, r, t, map[string]interface{}{"Error": db.ErrBusy.Error()})
+		return
+	}
+	if err != nil {
+		h.renderKiosk(w, r, t, map[string]interface{}{"Error": err.Error()})
+		return
+	}
+	h.renderKiosk(w, r, t, map[string]interface{}{"Reported": table})
+}
@@ -0,0 +1,89 @@
+//go:build integration
+
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/engine"
+	"github.com/dstathis/swisstools"
+)
+
+func TestTournamentHandler_Kiosk(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	tmpl := &mockTemplate{}
+	h := &TournamentHandler{DB: database, Tmpl: tmpl}
+	owner, tourn := startedTournament(t, database)
+	params := map[string]string{"id": strconv.FormatInt(tourn.ID, 10)}
+
+	kiosk := func(form url.Values) (*httptest.ResponseRecorder, map[string]interface{}) {
+		rec := httptest.NewRecorder()
+		n := len(tmpl.calls)
+		h.KioskSubmit(rec, requestWithUser("POST", "/", form.Encode(), nil, params))
+		if len(tmpl.calls) == n {
+			return rec, nil
+		}
+		return rec, tmpl.calls[n].Data.(map[string]interface{})
+	}
+	if rec, _ := kiosk(url.Values{"table": {"1"}, "pin": {"000000"}}); rec.Code != http.StatusNotFound {
+		t.Fatalf("kiosk off: status %d, want 404", rec.Code)
+	}
+
+	// Round 2, where nothing has been reported yet.
+	h.NextRound(httptest.NewRecorder(), requestWithUser("POST", "/", "", owner, params))
+	rec := httptest.NewRecorder()
+	h.EnableKiosk(rec, requestWithUser("POST", "/", "", owner, params))
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("enable: status %d", rec.Code)
+	}
+	secret, _ := db.GetKioskSecret(ctx, database, tourn.ID)
+	tm, _ := db.GetTournament(ctx, database, tourn.ID)
+	eng, _ := swisstools.LoadTournament(tm.EngineState)
+	if eng.GetCurrentRound() != 2 {
+		t.Fatalf("round = %d, want 2", eng.GetCurrentRound())
+	}
+	pin := engine.TablePINs(secret, &eng)[1]
+
+	if _, data := kiosk(url.Values{"table": {"1"}, "pin": {"12345x"}}); data["Error"] != engine.ErrKioskPIN.Error() {
+		t.Errorf("wrong PIN: error %v", data["Error"])
+	}
+	_, data := kiosk(url.Values{"table": {"1"}, "pin": {pin}})
+	p, ok := data["Pairing"].(resolvedPairing)
+	if !ok || p.Table != 1 || p.PlayerAName == "" {
+		t.Fatalf("lookup: data %+v", data)
+	}
+
+	confirm := url.Values{"table": {"1"}, "pin": {pin}, "confirm": {"1"}, "wins_a": {"2"}, "wins_b": {"1"}, "draws": {"0"}}
+	if _, data := kiosk(confirm); data["Reported"] != 1 {
+		t.Fatalf("report: data %+v", data)
+	}
+	tm, _ = db.GetTournament(ctx, database, tourn.ID)
+	eng, _ = swisstools.LoadTournament(tm.EngineState)
+	if got := eng.GetRound()[0]; got.PlayerAWins() != 2 || got.PlayerBWins() != 1 {
+		t.Errorf("result = %d-%d", got.PlayerAWins(), got.PlayerBWins())
+	}
+	if _, data := kiosk(confirm); data["Error"] == nil {
+		t.Error("second report accepted")
+	}
+
+	rec = httptest.NewRecorder()
+	h.KioskSlips(rec, requestWithUser("GET", "/", "", owner, params))
+	slips := tmpl.calls[len(tmpl.calls)-1].Data.(map[string]interface{})["Slips"].([]kioskSlip)
+	if len(slips) != 2 || slips[0].PIN != pin {
+		t.Errorf("slips = %+v", slips)
+	}
+
+	h.DisableKiosk(httptest.NewRecorder(), requestWithUser("POST", "/", "", owner, params))
+	rec = httptest.NewRecorder()
+	h.KioskPage(rec, requestWithUser("GET", "/", "", nil, params))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("after disable: status %d, want 404", rec.Code)
+	}
+}
@@ -0,0 +1,247 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/engine"
+	"github.com/dstathis/openswiss/internal/middleware"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/go-chi/chi/v5"
+)
+
+// LeagueHandler serves leagues: seasons whose leaderboard adds up points
+// from the final results of several tournaments. The list and leaderboard
+// are public; everything else needs the organizer role.
+type LeagueHandler struct {
+	DB   *sql.DB
+	Tmpl TemplateRenderer
+}
+
+// List shows every league.
+func (h *LeagueHandler) List(w http.ResponseWriter, r *http.Request) {
+	leagues, err := db.ListLeagues(r.Context(), h.DB)
+	if err != nil {
+		http.Error(w, "Failed to load leagues", http.StatusInternalServerError)
+		return
+	}
+	h.Tmpl.ExecuteTemplate(w, "leagues.html", map[string]interface{}{
+		"User":      middleware.GetUser(r.Context()),
+		"CSRFToken": middleware.CSRFToken(r),
+		"Theme":     middleware.Theme(r),
+		"Flash":     middleware.Flash(r),
+		"Lang":      middleware.Locale(r),
+		"Leagues":   leagues,
+	})
+}
+
+// Detail shows a league's leaderboard and the tournaments behind it.
+func (h *LeagueHandler) Detail(w http.ResponseWriter, r *http.Request) {
+	l, ok := h.league(w, r)
+	if !ok {
+		return
+	}
+	table, err := engine.LoadLeagueTable(r.Context(), h.DB, l)
+	if err != nil {
+		http.Error(w, "Failed to load the league's tournaments", http.StatusInternalServerError)
+		return
+	}
+	h.Tmpl.ExecuteTemplate(w, "league.html", map[string]interface{}{
+		"User":      middleware.GetUser(r.Context()),
+		"CSRFToken": middleware.CSRFToken(r),
+		"Theme":     middleware.Theme(r),
+		"Flash":     middleware.Flash(r),
+		"Lang":      middleware.Locale(r),
+		"League":    l,
+		"Points":    pointsText(l.PlacementPoints),
+		"Table":     table,
+	})
+}
+
+// NewPage shows the form that creates a league, filled in with the default
+// points table.
+func (h *LeagueHandler) NewPage(w http.ResponseWriter, r *http.Request) {
+	h.Tmpl.ExecuteTemplate(w, "league_new.html", map[string]interface{}{
+		"User":      middleware.GetUser(r.Context()),
+		"CSRFToken": middleware.CSRFToken(r),
+		"Theme":     middleware.Theme(r),
+		"Flash":     middleware.Flash(r),
+		"Lang":      middleware.Locale(r),
+		"Points":    pointsText(models.DefaultPlacementPoints),
+	})
+}
+
+// leagueForm reads a league from the form fields name, placement_points
+// (see models.ParsePlacementPoints) and participation_points, blank for 0.
+func leagueForm(r *http.Request) (*models.League, error) {
+	points, err := models.ParsePlacementPoints(r.FormValue("placement_points"))
+	if err != nil {
+		return nil, err
+	}
+	l := &models.League{Name: r.FormValue("name"), PlacementPoints: points}
+	if v := strings.TrimSpace(r.FormValue("participation_points")); v != "" {
+		if l.ParticipationPoints, err = strconv.Atoi(v); err != nil {
+			return nil, fmt.Errorf("%q is not a number of points", v)
+		}
+	}
+	return l, l.Validate()
+}
+
+// pointsText is a points table as the league forms show it.
+func pointsText(points []int) string {
+	s := make([]string, len(points))
+	for i, p := range points {
+		s[i] = strconv.Itoa(p)
+	}
+	return strings.Join(s, ", ")
+}
+
+// Create adds a league and takes the organizer to where its tournaments are
+// added. Form fields as for leagueForm.
+func (h *LeagueHandler) Create(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+	l, err := leagueForm(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	l.CreatedBy = &middleware.GetUser(r.Context()).ID
+	if err := db.CreateLeague(r.Context(), h.DB, l); err != nil {
+		http.Error(w, "Failed to create league", http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, r, fmt.Sprintf("/leagues/%d/manage", l.ID), http.StatusSeeOther)
+}
+
+// ManagePage shows the forms that edit a league, with its tournaments and
+// the recent ones it could add.
+func (h *LeagueHandler) ManagePage(w http.ResponseWriter, r *http.Request) {
+	l, ok := h.league(w, r)
+	if !ok {
+		return
+	}
+	in, err := db.ListLeagueTournaments(r.Context(), h.DB, l.ID)
+	if err != nil {
+		http.Error(w, "Failed to load the league's tournaments", http.StatusInternalServerError)
+		return
+	}
+	recent, _ := db.ListTournaments(r.Context(), h.DB, "", 1, 100)
+	inLeague := make(map[int64]bool, len(in))
+	for _, t := range in {
+		inLeague[t.ID] = true
+	}
+	var addable []models.Tournament
+	for _, t := range recent {
+		if !inLeague[t.ID] {
+			addable = append(addable, t)
+		}
+	}
+	h.Tmpl.ExecuteTemplate(w, "league_manage.html", map[string]interface{}{
+		"User":        middleware.GetUser(r.Context()),
+		"CSRFToken":   middleware.CSRFToken(r),
+		"Theme":       middleware.Theme(r),
+		"Flash":       middleware.Flash(r),
+		"Lang":        middleware.Locale(r),
+		"League":      l,
+		"Points":      pointsText(l.PlacementPoints),
+		"Tournaments": in,
+		"Addable":     addable,
+	})
+}
+
+// Update saves a league's name and points table; the leaderboard is
+// recomputed from them. Form fields as for leagueForm.
+func (h *LeagueHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "lid"), 10, 64)
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+	l, err := leagueForm(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	l.ID = id
+	if err := db.UpdateLeague(r.Context(), h.DB, l); err != nil {
+		if errors.Is(err, db.ErrLeagueNotFound) {
+			http.Error(w, "Not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Failed to save league", http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, r, fmt.Sprintf("/leagues/%d/manage", id), http.StatusSeeOther)
+}
+
+// AddTournament counts the tournament in form field tournament_id towards
+// the league.
+func (h *LeagueHandler) AddTournament(w http.ResponseWriter, r *http.Request) {
+	l, ok := h.league(w, r)
+	if !ok {
+		return
+	}
+	tid, err := strconv.ParseInt(r.FormValue("tournament_id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Choose a tournament", http.StatusBadRequest)
+		return
+	}
+	if _, err := db.GetTournament(r.Context(), h.DB, tid); err != nil {
+		http.Error(w, "Tournament not found", http.StatusNotFound)
+		return
+	}
+	if err := db.AddLeagueTournament(r.Context(), h.DB, l.ID, tid); err != nil {
+		http.Error(w, "Failed to add tournament", http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, r, fmt.Sprintf("/leagues/%d/manage", l.ID), http.StatusSeeOther)
+}
+
+// RemoveTournament stops a tournament counting towards the league.
+func (h *LeagueHandler) RemoveTournament(w http.ResponseWriter, r *http.Request) {
+	lid, _ := strconv.ParseInt(chi.URLParam(r, "lid"), 10, 64)
+	tid, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err := db.RemoveLeagueTournament(r.Context(), h.DB, lid, tid); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, "Not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Failed to remove tournament", http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, r, fmt.Sprintf("/leagues/%d/manage", lid), http.StatusSeeOther)
+}
+
+// Delete removes a league. Its tournaments are untouched.
+func (h *LeagueHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "lid"), 10, 64)
+	if err := db.DeleteLeague(r.Context(), h.DB, id); err != nil {
+		if errors.Is(err, db.ErrLeagueNotFound) {
+			http.Error(w, "Not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Failed to delete league", http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, r, "/leagues", http.StatusSeeOther)
+}
+
+// league loads the league named by the lid URL parameter, answering 404
+// if there is none.
+func (h *LeagueHandler) league(w http.ResponseWriter, r *http.Request) (*models.League, bool) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "lid"), 10, 64)
+	l, err := db.GetLeague(r.Context(), h.DB, id)
+	if err != nil {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return nil, false
+	}
+	return l, true
+}
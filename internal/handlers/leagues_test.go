@@ -0,0 +1,100 @@
+//go:build integration
+
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/engine"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/dstathis/swisstools"
+)
+
+func TestLeagueHandler(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	tmpl := &mockTemplate{}
+	h := &LeagueHandler{DB: database, Tmpl: tmpl}
+	org := mustCreateUser(t, database, "org-league@example.com", "OrgLeague", models.RoleOrganizer)
+
+	rec := httptest.NewRecorder()
+	h.Create(rec, requestWithUser("POST", "/", url.Values{"name": {"Season"}, "placement_points": {"10, eight"}}.Encode(), org, nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("bad points: status %d, want 400", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	h.Create(rec, requestWithUser("POST", "/", url.Values{"name": {" Season "}, "placement_points": {"5 3 1"}, "participation_points": {"1"}}.Encode(), org, nil))
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("create: status %d body %s", rec.Code, rec.Body.String())
+	}
+	leagues, _ := db.ListLeagues(ctx, database)
+	if len(leagues) != 1 || leagues[0].Name != "Season" || len(leagues[0].PlacementPoints) != 3 {
+		t.Fatalf("leagues = %+v", leagues)
+	}
+	l := leagues[0]
+	lid := strconv.FormatInt(l.ID, 10)
+
+	_, done := startedTournament(t, database)
+	if err := engine.WithTournamentEngine(ctx, database, done.ID,
+		func(tx *sql.Tx, tm *models.Tournament, eng *swisstools.Tournament) (string, error) {
+			return models.TournamentStatusFinished, eng.FinishTournament()
+		}); err != nil {
+		t.Fatalf("finish: %v", err)
+	}
+	pending := mustCreateTournament(t, database, org.ID, models.TournamentStatusScheduled)
+	for _, tourn := range []*models.Tournament{done, pending} {
+		rec = httptest.NewRecorder()
+		h.AddTournament(rec, requestWithUser("POST", "/", url.Values{"tournament_id": {strconv.FormatInt(tourn.ID, 10)}}.Encode(), org, map[string]string{"lid": lid}))
+		if rec.Code != http.StatusSeeOther {
+			t.Fatalf("add tournament: status %d body %s", rec.Code, rec.Body.String())
+		}
+	}
+
+	rec = httptest.NewRecorder()
+	h.Detail(rec, requestWithUser("GET", "/", "", nil, map[string]string{"lid": lid}))
+	table := tmpl.calls[0].Data.(map[string]interface{})["Table"].(*engine.LeagueTable)
+	if len(table.Counted) != 1 || len(table.Pending) != 1 || len(table.Standings) != 4 {
+		t.Fatalf("table = %+v", table)
+	}
+	if top := table.Standings[0]; top.Rank != 1 || top.Points != 5 || top.Titles != 1 {
+		t.Errorf("leader = %+v", top)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ManagePage(rec, requestWithUser("GET", "/", "", org, map[string]string{"lid": lid}))
+	for _, addable := range tmpl.calls[1].Data.(map[string]interface{})["Addable"].([]models.Tournament) {
+		if addable.ID == done.ID || addable.ID == pending.ID {
+			t.Errorf("tournament %d offered again", addable.ID)
+		}
+	}
+
+	params := map[string]string{"lid": lid, "id": strconv.FormatInt(pending.ID, 10)}
+	rec = httptest.NewRecorder()
+	h.RemoveTournament(rec, requestWithUser("POST", "/", "", org, params))
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("remove: status %d", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	h.RemoveTournament(rec, requestWithUser("POST", "/", "", org, params))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("second remove: status %d, want 404", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	h.Delete(rec, requestWithUser("POST", "/", "", org, map[string]string{"lid": lid}))
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("delete: status %d", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	h.Detail(rec, requestWithUser("GET", "/", "", nil, map[string]string{"lid": lid}))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("detail after delete: status %d, want 404", rec.Code)
+	}
+}
@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/engine"
+	"github.com/dstathis/openswiss/internal/middleware"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/dstathis/swisstools"
+	"github.com/go-chi/chi/v5"
+)
+
+// FindPairing is the pairing lookup: a player types their name (?q=) and
+// gets just their table and opponent in the current round, instead of
+// scrolling a big event's pairings on a phone (see engine.LookupMatches).
+// A draft round isn't shown.
+func (h *TournamentHandler) FindPairing(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	t, err := db.GetTournament(r.Context(), h.DB, id)
+	if err != nil {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+	q := nameQuery(r)
+	data := map[string]interface{}{
+		"User":       middleware.GetUser(r.Context()),
+		"CSRFToken":  middleware.CSRFToken(r),
+		"Theme":      middleware.Theme(r),
+		"Flash":      middleware.Flash(r),
+		"Lang":       middleware.Locale(r),
+		"Tournament": t,
+		"Query":      q,
+	}
+	if len(t.EngineState) > 0 {
+		eng, err := swisstools.LoadTournament(t.EngineState)
+		if err != nil {
+			http.Error(w, "Internal error", http.StatusInternalServerError)
+			return
+		}
+		round := eng.GetCurrentRound()
+		h.Views.View(r, t.ID, round, models.PagePairings)
+		data["Round"] = round
+		if t.PairingsDraft(round) {
+			data["Draft"] = true
+		} else if q != "" {
+			data["Matches"] = engine.LookupMatches(&eng, eng.GetRound(), q)
+		}
+	}
+	h.Tmpl.ExecuteTemplate(w, "tournament_find.html", data)
+}
@@ -0,0 +1,52 @@
+//go:build integration
+
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/engine"
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+func TestTournamentHandler_FindPairing(t *testing.T) {
+	database := testDB(t)
+	tmpl := &mockTemplate{}
+	h := &TournamentHandler{DB: database, Tmpl: tmpl}
+	_, tourn := startedTournament(t, database)
+	params := map[string]string{"id": strconv.FormatInt(tourn.ID, 10)}
+
+	rec := httptest.NewRecorder()
+	h.FindPairing(rec, requestWithUser("GET", "/?q="+url.QueryEscape("p2-"+t.Name()), "", nil, params))
+	if rec.Code != http.StatusOK || len(tmpl.calls) != 1 {
+		t.Fatalf("status %d, %d renders", rec.Code, len(tmpl.calls))
+	}
+	matches := tmpl.calls[0].Data.(map[string]interface{})["Matches"].([]engine.PlayerMatch)
+	if len(matches) != 1 || matches[0].Name != "P2-"+t.Name() || matches[0].Table == 0 || matches[0].Opponent == "" {
+		t.Errorf("matches = %+v, want P2's table and opponent", matches)
+	}
+
+	// Without a name the page only asks for one.
+	rec = httptest.NewRecorder()
+	h.FindPairing(rec, requestWithUser("GET", "/", "", nil, params))
+	if _, ok := tmpl.calls[1].Data.(map[string]interface{})["Matches"]; ok {
+		t.Error("matches shown without a name")
+	}
+
+	unstarted := mustCreateTournament(t, database, tourn.OrganizerID, models.TournamentStatusScheduled)
+	rec = httptest.NewRecorder()
+	h.FindPairing(rec, requestWithUser("GET", "/?q=p", "", nil, map[string]string{"id": strconv.FormatInt(unstarted.ID, 10)}))
+	if data := tmpl.calls[2].Data.(map[string]interface{}); data["Round"] != nil || data["Matches"] != nil {
+		t.Errorf("unstarted tournament: round %v, matches %v", data["Round"], data["Matches"])
+	}
+
+	rec = httptest.NewRecorder()
+	h.FindPairing(rec, requestWithUser("GET", "/", "", nil, map[string]string{"id": "0"}))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("missing tournament: status %d, want 404", rec.Code)
+	}
+}
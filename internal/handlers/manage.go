@@ -0,0 +1,345 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/engine"
+	"github.com/dstathis/openswiss/internal/middleware"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/dstathis/swisstools"
+	"github.com/go-chi/chi/v5"
+)
+
+// The management dashboard is split into pages, one per job, each loading
+// only what it shows: the overview (status, start, settings, sharing), the
+// players, the current round with result entry, and the standings and final
+// results. Every page is for Judges and up.
+const (
+	manageOverview = "overview"
+	managePlayers  = "players"
+	manageRounds   = "rounds"
+	manageResults  = "results"
+)
+
+// manageData authorizes a Judge on the tournament in the URL and returns it
+// with the template data every dashboard page shares, Tab naming the page for
+// the navigation. It has written the response when ok is false.
+func (h *TournamentHandler) manageData(w http.ResponseWriter, r *http.Request, tab string) (t *models.Tournament, data map[string]interface{}, ok bool) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	t, err := db.GetTournament(r.Context(), h.DB, id)
+	if err != nil {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return nil, nil, false
+	}
+	if !middleware.AuthorizeTournament(w, r, h.DB, t.ID, models.TierJudge) {
+		return nil, nil, false
+	}
+	user := middleware.GetUser(r.Context())
+	tier, _ := db.EffectiveTournamentTier(r.Context(), h.DB, t.ID, user)
+	return t, map[string]interface{}{
+		"User":          user,
+		"CSRFToken":     middleware.CSRFToken(r),
+		"Theme":         middleware.Theme(r),
+		"Flash":         middleware.Flash(r),
+		"Lang":          middleware.Locale(r),
+		"Tournament":    t,
+		"Tab":           tab,
+		"IsAdmin":       tier == models.TierAdmin,
+		"IsCoOrganizer": tier.AtLeast(models.TierCoOrganizer),
+	}, true
+}
+
+// manageEngine loads the tournament's engine, or returns nil before it
+// starts.
+func manageEngine(t *models.Tournament) *swisstools.Tournament {
+	if len(t.EngineState) == 0 {
+		return nil
+	}
+	eng, err := swisstools.LoadTournament(t.EngineState)
+	if err != nil {
+		return nil
+	}
+	return &eng
+}
+
+// ManagePage is the dashboard overview: where the tournament stands and
+// which results are outstanding, the actions that open and start it, and
+// its settings, schedule, announcements, sharing and stages.
+func (h *TournamentHandler) ManagePage(w http.ResponseWriter, r *http.Request) {
+	t, data, ok := h.manageData(w, r, manageOverview)
+	if !ok {
+		return
+	}
+	regs, _ := db.ListRegistrations(r.Context(), h.DB, t.ID)
+	pending, contacts := 0, 0
+	for _, reg := range regs {
+		if reg.Status == models.RegistrationStatusPending {
+			pending++
+		}
+		if reg.ContactConsent {
+			contacts++
+		}
+	}
+	var currentRound int
+	var missingTables []int
+	var playoffStatus string
+	eng := manageEngine(t)
+	if eng != nil {
+		currentRound = eng.GetCurrentRound()
+		missingTables = engine.MissingTables(eng)
+		playoffStatus = eng.GetPlayoffStatus()
+	}
+	h.attachOutstanding(r.Context(), t, eng, data)
+	schedule, _ := db.ListSchedule(r.Context(), h.DB, t.ID)
+	var sharePath string
+	if token, _ := db.GetShareToken(r.Context(), h.DB, t.ID); token != "" {
+		sharePath = models.SharePath(t.ID, token)
+	}
+	kioskSecret, _ := db.GetKioskSecret(r.Context(), h.DB, t.ID)
+	announcements, _ := db.ListAnnouncements(r.Context(), h.DB, t.ID)
+
+	data["PlayerCount"] = len(regs)
+	data["PendingCount"] = pending
+	data["ContactCount"] = contacts
+	data["WaitlistCount"] = len(engine.Waitlist(regs))
+	data["UnpaidCount"] = len(engine.Unpaid(regs))
+	data["StartCheck"] = engine.CheckStart(t, regs)
+	data["CurrentRound"] = currentRound
+	data["MissingTables"] = missingTables
+	data["Draft"] = t.PairingsDraft(currentRound)
+	data["PlayoffStatus"] = playoffStatus
+	data["Stages"] = loadStages(r.Context(), h.DB, t, true)
+	data["SharePath"] = sharePath
+	data["KioskOn"] = kioskSecret != ""
+	data["KioskPath"] = models.KioskPath(t.ID)
+	data["Schedule"] = schedule
+	data["ScheduleText"] = engine.FormatSchedule(schedule, t.Zone())
+	data["AnnouncementHistory"] = announcements
+	data["Now"] = time.Now()
+	h.Tmpl.ExecuteTemplate(w, "tournament_manage.html", data)
+}
+
+// ManagePlayersPage lists the registrations with their actions, and the
+// forms that change the field: possible duplicates to merge or keep apart,
+// pending players, manual adds (typed in, or picked from the player
+// registry by organizers), merges, ratings and assigned byes. ?q= and players_page narrow the table as on the detail
+// page, and ?payment= to one payment status, "unpaid" to chase entry fees
+// before round 1; the forms still see every registration.
+func (h *TournamentHandler) ManagePlayersPage(w http.ResponseWriter, r *http.Request) {
+	if data, ok := h.playersData(w, r); ok {
+		h.Tmpl.ExecuteTemplate(w, "tournament_manage_players.html", data)
+	}
+}
+
+// RegistrationsFragment renders the players page's registrations section on
+// its own, pending actions included, for the page to swap in after a change
+// (see static/app.js). It takes the page's ?q=, ?payment= and players_page.
+func (h *TournamentHandler) RegistrationsFragment(w http.ResponseWriter, r *http.Request) {
+	if data, ok := h.playersData(w, fragmentRequest(r, managePlayers)); ok {
+		h.Tmpl.ExecuteTemplate(w, "tournament_manage_players.html#registrations", data)
+	}
+}
+
+func (h *TournamentHandler) playersData(w http.ResponseWriter, r *http.Request) (map[string]interface{}, bool) {
+	t, data, ok := h.manageData(w, r, managePlayers)
+	if !ok {
+		return nil, false
+	}
+	regs, _ := db.ListRegistrations(r.Context(), h.DB, t.ID)
+	pending, contacts := 0, 0
+	for _, reg := range regs {
+		if reg.Status == models.RegistrationStatusPending {
+			pending++
+		}
+		if reg.ContactConsent {
+			contacts++
+		}
+	}
+	var currentRound int
+	if eng := manageEngine(t); eng != nil {
+		currentRound = eng.GetCurrentRound()
+	}
+	byes, _ := db.ListAssignedByes(r.Context(), h.DB, t.ID)
+	q := nameQuery(r)
+	payment := r.URL.Query().Get("payment")
+	if !models.ValidPaymentStatus(payment) {
+		payment = ""
+	}
+	regRows, regPager := filterPage(r, "players_page", "players", regs,
+		func(reg models.Registration) bool {
+			return matchesName(q, reg.DisplayName) && (payment == "" || reg.PaymentStatus == payment)
+		})
+
+	data["Query"] = q
+	data["Payment"] = payment
+	data["UnpaidCount"] = len(engine.Unpaid(regs))
+	data["TrackPayments"] = trackPayments(t, regs)
+	data["Registrations"] = regs
+	data["RegistrationRows"] = regRows
+	data["RegistrationsPager"] = regPager
+	data["PendingCount"] = pending
+	data["ContactCount"] = contacts
+	data["Waitlist"] = engine.Waitlist(regs)
+	data["PlacesTaken"] = engine.PlacesTaken(regs)
+	data["AssignedByes"] = byes
+	data["NextByeRound"] = currentRound + 1
+	if middleware.GetUser(r.Context()).CanOrganize() {
+		data["Registry"] = unregistered(r.Context(), h.DB, regs)
+	}
+	if data["IsCoOrganizer"] == true && mergeable(t) {
+		distinct, _ := db.ListDistinctRegistrations(r.Context(), h.DB, t.ID)
+		data["PossibleDuplicates"] = engine.PossibleDuplicates(regs, distinct)
+	}
+	return data, true
+}
+
+// trackPayments reports whether the players page shows payments: when t
+// has an entry fee, or once someone's payment was noted anyway.
+func trackPayments(t *models.Tournament, regs []models.Registration) bool {
+	if t.EntryFee > 0 {
+		return true
+	}
+	for _, reg := range regs {
+		if reg.PaymentStatus != models.PaymentUnpaid {
+			return true
+		}
+	}
+	return false
+}
+
+// ManageRoundsPage runs the current round: result entry (with series games
+// and team seats), the player reports waiting, the draft editor, pairing fields, the round and playoff
+// actions, and links to the projector and print views. ?q= and pairings_page
+// narrow the result entry table.
+func (h *TournamentHandler) ManageRoundsPage(w http.ResponseWriter, r *http.Request) {
+	if data, ok := h.roundsData(w, r); ok {
+		h.Tmpl.ExecuteTemplate(w, "tournament_manage_rounds.html", data)
+	}
+}
+
+// RoundFragment renders the rounds page's round actions and result entry
+// table on their own, for the page to swap in after results are saved. It
+// takes the page's ?q= and pairings_page.
+func (h *TournamentHandler) RoundFragment(w http.ResponseWriter, r *http.Request) {
+	if data, ok := h.roundsData(w, fragmentRequest(r, manageRounds)); ok {
+		h.Tmpl.ExecuteTemplate(w, "tournament_manage_rounds.html#round", data)
+	}
+}
+
+func (h *TournamentHandler) roundsData(w http.ResponseWriter, r *http.Request) (map[string]interface{}, bool) {
+	t, data, ok := h.manageData(w, r, manageRounds)
+	if !ok {
+		return nil, false
+	}
+	var pairings []resolvedPairing
+	var currentRound int
+	var playoffStatus string
+	var playoffPairings []resolvedPairing
+	var missingTables []int
+	var draftSeats []displaySeat
+	if eng := manageEngine(t); eng != nil {
+		pairings = resolvePairings(eng, eng.GetRound())
+		currentRound = eng.GetCurrentRound()
+		data["Clock"], _ = db.GetRoundClock(r.Context(), h.DB, t.ID, currentRound)
+		missingTables = engine.MissingTables(eng)
+		if t.PairingsDraft(currentRound) {
+			draftSeats = seatsByName(pairings)
+		}
+		playoffStatus = eng.GetPlayoffStatus()
+		playoffPairings = resolvePairings(eng, eng.GetPlayoffRound())
+	}
+	pairingFields := attachPairingFields(r.Context(), h.DB, t.ID, currentRound, pairings, false)
+	attachResultTypes(r.Context(), h.DB, t.ID, currentRound, pairings)
+	if t.SeriesMode {
+		attachGames(r.Context(), h.DB, t.ID, currentRound, pairings)
+	}
+	if t.TeamSize > 0 {
+		regs, _ := db.ListRegistrations(r.Context(), h.DB, t.ID)
+		attachSeats(r.Context(), h.DB, t, currentRound, regs, pairings)
+	}
+	featured := attachFeatureMatches(r.Context(), h.DB, t.ID, currentRound, pairings)
+	reports := loadReports(r.Context(), h.DB, t, currentRound, pairings)
+	kioskSecret, _ := db.GetKioskSecret(r.Context(), h.DB, t.ID)
+	q := nameQuery(r)
+	pairings, pairingsPager := filterPage(r, "pairings_page", "pairings", pairings,
+		func(p resolvedPairing) bool { return matchesName(q, p.PlayerAName, p.PlayerBName) })
+
+	data["Query"] = q
+	data["Pairings"] = pairings
+	data["PairingsPager"] = pairingsPager
+	data["PairingFields"] = pairingFields
+	data["CurrentRound"] = currentRound
+	data["Rounds"] = roundNumbers(currentRound)
+	data["Round"] = 0
+	data["StaffView"] = true
+	data["PlayoffStatus"] = playoffStatus
+	data["PlayoffPairings"] = playoffPairings
+	data["MissingTables"] = missingTables
+	data["Draft"] = t.PairingsDraft(currentRound)
+	data["DraftSeats"] = draftSeats
+	data["KioskOn"] = kioskSecret != ""
+	data["FeatureTables"] = formatTables(featured)
+	data["Reports"] = reports
+	return data, true
+}
+
+// fragmentRequest returns r addressed to the dashboard page the fragment
+// belongs to, so the fragment's pager links lead to the page.
+func fragmentRequest(r *http.Request, tab string) *http.Request {
+	r = r.Clone(r.Context())
+	r.URL.Path = "/tournaments/" + chi.URLParam(r, "id") + "/manage/" + tab
+	return r
+}
+
+// ManageResultsPage shows the standings (with each player's colours when the
+// tournament tracks them) and, once the tournament is finished, the exports,
+// the final results, the Challonge push and the rated players' Elo changes
+// with the K-factor from ?k=. ?q= and standings_page narrow the standings.
+func (h *TournamentHandler) ManageResultsPage(w http.ResponseWriter, r *http.Request) {
+	t, data, ok := h.manageData(w, r, manageResults)
+	if !ok {
+		return
+	}
+	var standings []swisstools.PlayerStanding
+	var playoffStatus string
+	var colors map[int]string
+	var ratingChanges []engine.RatingChange
+	var hidden bool
+	k, err := engine.ParseRatingK(r.URL.Query().Get("k"))
+	if err != nil {
+		k = engine.DefaultRatingK
+	}
+	if eng := manageEngine(t); eng != nil {
+		regs, _ := db.ListRegistrations(r.Context(), h.DB, t.ID)
+		standings = engine.CachedStandings(t, eng, engine.TiebreakSeeds(regs))
+		playoffStatus = eng.GetPlayoffStatus()
+		hidden = engine.StandingsHidden(t, eng)
+		if t.Colors {
+			colors = engine.ColorHistory(eng)
+		}
+		if engine.Complete(t, eng) {
+			ratingChanges = engine.RatingChanges(regs, eng, k)
+		}
+	}
+	challongeURL, _ := db.GetChallongeURL(r.Context(), h.DB, t.ID)
+	challongeKey, _ := db.GetSetting(r.Context(), h.DB, db.SettingChallongeAPIKey)
+	q := nameQuery(r)
+	standings, standingsPager := filterPage(r, "standings_page", "standings", standings,
+		func(s swisstools.PlayerStanding) bool { return matchesName(q, s.Name) })
+
+	data["Query"] = q
+	data["Standings"] = standings
+	data["StandingsPager"] = standingsPager
+	data["StandingsHidden"] = hidden
+	data["ColorHistory"] = colors
+	data["PlayoffStatus"] = playoffStatus
+	data["ChallongeURL"] = challongeURL
+	data["ChallongeReady"] = challongeKey != ""
+	data["RatingK"] = k
+	data["MaxRatingK"] = engine.MaxRatingK
+	data["RatingChanges"] = ratingChanges
+	h.Tmpl.ExecuteTemplate(w, "tournament_manage_results.html", data)
+}
@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/dstathis/openswiss/internal/engine"
+	"github.com/dstathis/openswiss/internal/middleware"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/go-chi/chi/v5"
+	"github.com/lib/pq"
+)
+
+// RenamePlayer corrects a player's name, say a typo made at registration,
+// everywhere it shows. Min tier: Co-organizer.
+func (h *TournamentHandler) RenamePlayer(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	regID, err := strconv.ParseInt(chi.URLParam(r, "regID"), 10, 64)
+	if err != nil {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+	if !middleware.AuthorizeTournament(w, r, h.DB, id, models.TierCoOrganizer) {
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+	if err := engine.RenamePlayer(r.Context(), h.DB, id, regID, r.FormValue("name")); err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			http.Error(w, "Another player already has that name", http.StatusConflict)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	http.Redirect(w, r, fmt.Sprintf("/tournaments/%d/manage/players", id), http.StatusSeeOther)
+}
+
+// AnonymizePlayer removes a player's personal data from the tournament,
+// guest or not, leaving them as "Player #N" (see
+// engine.AnonymizeRegistration). Min tier: Admin.
+func (h *TournamentHandler) AnonymizePlayer(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	regID, err := strconv.ParseInt(chi.URLParam(r, "regID"), 10, 64)
+	if err != nil {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+	if !middleware.AuthorizeTournament(w, r, h.DB, id, models.TierAdmin) {
+		return
+	}
+	if err := engine.AnonymizeRegistration(r.Context(), h.DB, id, regID); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	http.Redirect(w, r, fmt.Sprintf("/tournaments/%d/manage/players", id), http.StatusSeeOther)
+}
+
+// MergePlayers folds a duplicate registration into the one to keep. Min
+// tier: Co-organizer.
+func (h *TournamentHandler) MergePlayers(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if !middleware.AuthorizeTournament(w, r, h.DB, id, models.TierCoOrganizer) {
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+	keepID, err1 := strconv.ParseInt(r.FormValue("keep_id"), 10, 64)
+	dupID, err2 := strconv.ParseInt(r.FormValue("duplicate_id"), 10, 64)
+	if err1 != nil || err2 != nil {
+		http.Error(w, "Choose the player to keep and the duplicate", http.StatusBadRequest)
+		return
+	}
+	if err := engine.MergePlayers(r.Context(), h.DB, id, keepID, dupID); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	middleware.SetFlash(w, r, middleware.FlashSuccess, "The duplicate is merged.")
+	http.Redirect(w, r, fmt.Sprintf("/tournaments/%d/manage/players", id), http.StatusSeeOther)
+}
+
+// MarkDistinct answers a possible duplicate on the players page: the
+// registration and other_id are two different people, so they are no longer
+// flagged. Min tier: Co-organizer.
+func (h *TournamentHandler) MarkDistinct(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	regID, err := strconv.ParseInt(chi.URLParam(r, "regID"), 10, 64)
+	if err != nil {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+	if !middleware.AuthorizeTournament(w, r, h.DB, id, models.TierCoOrganizer) {
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+	otherID, err := strconv.ParseInt(r.FormValue("other_id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Choose the other player", http.StatusBadRequest)
+		return
+	}
+	user := middleware.GetUser(r.Context())
+	if err := engine.MarkDistinct(r.Context(), h.DB, id, regID, otherID, user.ID); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	middleware.SetFlash(w, r, middleware.FlashSuccess, "Both players are kept.")
+	http.Redirect(w, r, fmt.Sprintf("/tournaments/%d/manage/players", id), http.StatusSeeOther)
+}
+
+// mergeable reports whether t's registrations can still be merged (see
+// engine.MergePlayers).
+func mergeable(t *models.Tournament) bool {
+	switch t.Status {
+	case models.TournamentStatusScheduled, models.TournamentStatusRegistrationOpen, models.TournamentStatusInProgress:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,211 @@
+//go:build integration
+
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/engine"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/dstathis/swisstools"
+)
+
+func TestTournamentHandler_RenamePlayer(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	h := &TournamentHandler{DB: database, Tmpl: &mockTemplate{}}
+	owner, tourn := startedTournament(t, database)
+	idStr := strconv.FormatInt(tourn.ID, 10)
+	regs, _ := db.ListRegistrations(ctx, database, tourn.ID)
+	reg := regs[0]
+	params := map[string]string{"id": idStr, "regID": strconv.FormatInt(reg.ID, 10)}
+
+	stranger := mustCreateUser(t, database, "stranger-rename@example.com", "StrangerRename")
+	rec := httptest.NewRecorder()
+	h.RenamePlayer(rec, requestWithUser("POST", "/", "name=Hijacked", stranger, params))
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("stranger: status %d, want 403", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	h.RenamePlayer(rec, requestWithUser("POST", "/", "name=+Fixed+Name+", owner, params))
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("rename: status %d body %s", rec.Code, rec.Body.String())
+	}
+	got, _ := db.GetRegistrationByID(ctx, database, reg.ID)
+	if got.DisplayName != "Fixed Name" {
+		t.Errorf("registration name = %q, want Fixed Name", got.DisplayName)
+	}
+	updated, _ := db.GetTournament(ctx, database, tourn.ID)
+	eng, err := swisstools.LoadTournament(updated.EngineState)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p, _ := eng.GetPlayerById(*reg.EnginePlayerID); p.Name != "Fixed Name" {
+		t.Errorf("engine name = %q, want Fixed Name", p.Name)
+	}
+
+	for body, want := range map[string]int{
+		"name=":                       http.StatusBadRequest,
+		"name=" + regs[1].DisplayName: http.StatusConflict,
+		"name=fixed+name":             http.StatusSeeOther, // its own name, recased
+	} {
+		rec = httptest.NewRecorder()
+		h.RenamePlayer(rec, requestWithUser("POST", "/", body, owner, params))
+		if rec.Code != want {
+			t.Errorf("%q: status %d, want %d", body, rec.Code, want)
+		}
+	}
+
+	other := map[string]string{"id": idStr, "regID": "999999"}
+	rec = httptest.NewRecorder()
+	h.RenamePlayer(rec, requestWithUser("POST", "/", "name=Ghost", owner, other))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("unknown registration: status %d, want 400", rec.Code)
+	}
+}
+
+func TestTournamentHandler_AnonymizePlayer(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	h := &TournamentHandler{DB: database, Tmpl: &mockTemplate{}}
+	owner, tourn := startedTournament(t, database)
+	regs, _ := db.ListRegistrations(ctx, database, tourn.ID)
+	reg := regs[0]
+	params := map[string]string{"id": strconv.FormatInt(tourn.ID, 10), "regID": strconv.FormatInt(reg.ID, 10)}
+
+	co := mustCreateUser(t, database, "co-anonymize@example.com", "CoAnonymize")
+	if err := db.AddTournamentStaff(ctx, database, &models.TournamentStaff{
+		TournamentID: tourn.ID, UserID: co.ID, Tier: models.TierCoOrganizer,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	rec := httptest.NewRecorder()
+	h.AnonymizePlayer(rec, requestWithUser("POST", "/", "", co, params))
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("co-organizer: status %d, want 403", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	h.AnonymizePlayer(rec, requestWithUser("POST", "/", "", owner, params))
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("anonymize: status %d body %s", rec.Code, rec.Body.String())
+	}
+	name := engine.AnonymousName(reg.ID)
+	got, _ := db.GetRegistrationByID(ctx, database, reg.ID)
+	if got.DisplayName != name || got.UserID != nil {
+		t.Errorf("registration = %+v", got)
+	}
+	updated, _ := db.GetTournament(ctx, database, tourn.ID)
+	eng, err := swisstools.LoadTournament(updated.EngineState)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p, _ := eng.GetPlayerById(*reg.EnginePlayerID); p.Name != name {
+		t.Errorf("engine name = %q, want %q", p.Name, name)
+	}
+
+	rec = httptest.NewRecorder()
+	h.AnonymizePlayer(rec, requestWithUser("POST", "/", "", owner, map[string]string{"id": params["id"], "regID": "999999"}))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("unknown registration: status %d, want 400", rec.Code)
+	}
+}
+
+func TestTournamentHandler_MergePlayers(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	h := &TournamentHandler{DB: database, Tmpl: &mockTemplate{}}
+	owner, tourn := startedTournament(t, database)
+	idStr := strconv.FormatInt(tourn.ID, 10)
+	params := map[string]string{"id": idStr}
+
+	// A walk-in added by hand for the player who was already registered.
+	rec := httptest.NewRecorder()
+	h.AddPlayer(rec, requestWithUser("POST", "/", "player_name=Walk+In", owner, params))
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("add player: status %d body %s", rec.Code, rec.Body.String())
+	}
+	regs, _ := db.ListRegistrations(ctx, database, tourn.ID)
+	keep, played, dup := regs[0], regs[1], regs[len(regs)-1]
+	if dup.DisplayName != "Walk In" || dup.EnginePlayerID == nil {
+		t.Fatalf("walk-in registration = %+v", dup)
+	}
+
+	merge := func(keepID, dupID int64) int {
+		rec := httptest.NewRecorder()
+		h.MergePlayers(rec, requestWithUser("POST", "/", fmt.Sprintf("keep_id=%d&duplicate_id=%d", keepID, dupID), owner, params))
+		return rec.Code
+	}
+	if code := merge(keep.ID, played.ID); code != http.StatusBadRequest {
+		t.Errorf("merging a player who has played: status %d, want 400", code)
+	}
+	if code := merge(keep.ID, keep.ID); code != http.StatusBadRequest {
+		t.Errorf("merging a player into themselves: status %d, want 400", code)
+	}
+	if code := merge(keep.ID, dup.ID); code != http.StatusSeeOther {
+		t.Fatalf("merge: status %d", code)
+	}
+
+	if _, err := db.GetRegistrationByID(ctx, database, dup.ID); err == nil {
+		t.Error("duplicate registration still there")
+	}
+	updated, _ := db.GetTournament(ctx, database, tourn.ID)
+	eng, err := swisstools.LoadTournament(updated.EngineState)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := eng.GetPlayerById(*dup.EnginePlayerID); ok {
+		t.Error("duplicate still in the engine")
+	}
+	if _, ok := eng.GetPlayerById(*keep.EnginePlayerID); !ok {
+		t.Error("kept player missing from the engine")
+	}
+}
+
+func TestTournamentHandler_PossibleDuplicates(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	tmpl := &mockTemplate{}
+	h := &TournamentHandler{DB: database, Tmpl: tmpl}
+	owner := mustCreateUser(t, database, "owner@example.com", "Owner")
+	tourn := mustCreateTournament(t, database, owner.ID, models.TournamentStatusRegistrationOpen)
+	first, _ := db.CreateGuestRegistration(ctx, database, tourn.ID, "John Smith")
+	second, _ := db.CreateGuestRegistration(ctx, database, tourn.ID, "Jon Smith")
+	idStr := strconv.FormatInt(tourn.ID, 10)
+
+	duplicates := func() []engine.PossibleDuplicate {
+		t.Helper()
+		tmpl.calls = nil
+		h.ManagePlayersPage(httptest.NewRecorder(), requestWithUser("GET", "/", "", owner, map[string]string{"id": idStr}))
+		if len(tmpl.calls) != 1 {
+			t.Fatalf("expected a render, got %d", len(tmpl.calls))
+		}
+		dups, _ := tmpl.calls[0].Data.(map[string]interface{})["PossibleDuplicates"].([]engine.PossibleDuplicate)
+		return dups
+	}
+	if dups := duplicates(); len(dups) != 1 || dups[0].Registration.ID != second.ID || dups[0].Existing.ID != first.ID {
+		t.Fatalf("PossibleDuplicates = %+v, want Jon Smith against John Smith", dups)
+	}
+
+	params := map[string]string{"id": idStr, "regID": strconv.FormatInt(second.ID, 10)}
+	rec := httptest.NewRecorder()
+	h.MarkDistinct(rec, requestWithUser("POST", "/", "other_id=999999", owner, params))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("unknown other: status %d, want 400", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	h.MarkDistinct(rec, requestWithUser("POST", "/", fmt.Sprintf("other_id=%d", first.ID), owner, params))
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("keep both: status %d body %s", rec.Code, rec.Body.String())
+	}
+	if dups := duplicates(); len(dups) != 0 {
+		t.Errorf("after keeping both: %+v, want none", dups)
+	}
+}
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/engine"
+	"github.com/dstathis/openswiss/internal/middleware"
+	"github.com/dstathis/swisstools"
+	"github.com/go-chi/chi/v5"
+)
+
+// formArchetype reads the archetype field of a decklist form, and reports
+// whether it is short enough to keep.
+func formArchetype(r *http.Request) (string, bool) {
+	a := strings.TrimSpace(r.FormValue("archetype"))
+	return a, utf8.RuneCountInString(a) <= db.MaxArchetypeLength
+}
+
+// Meta is the public metagame page: the field by archetype, with each one's
+// top-cut conversion once the playoff is seeded (see engine.BuildMetagame).
+// Archetypes stay private until the tournament starts; before that it
+// redirects to the tournament page.
+func (h *TournamentHandler) Meta(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+	t, err := db.GetTournament(r.Context(), h.DB, id)
+	if err != nil {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+	eng, err := swisstools.LoadTournament(t.EngineState)
+	if len(t.EngineState) == 0 || err != nil {
+		http.Redirect(w, r, fmt.Sprintf("/tournaments/%d", id), http.StatusFound)
+		return
+	}
+	regs, _ := db.ListRegistrations(r.Context(), h.DB, id)
+	h.Tmpl.ExecuteTemplate(w, "tournament_meta.html", map[string]interface{}{
+		"User":       middleware.GetUser(r.Context()),
+		"CSRFToken":  middleware.CSRFToken(r),
+		"Theme":      middleware.Theme(r),
+		"Flash":      middleware.Flash(r),
+		"Lang":       middleware.Locale(r),
+		"Tournament": t,
+		"Meta":       engine.BuildMetagame(regs, &eng),
+	})
+}
@@ -0,0 +1,84 @@
+//go:build integration
+
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/engine"
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+func TestTournamentHandler_OrganizerSubmitDecklist_Archetype(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	h := &TournamentHandler{DB: database, Tmpl: &mockTemplate{}}
+	owner := mustCreateUser(t, database, "arch-owner@example.com", "ArchOwner")
+	tourn := mustCreateTournament(t, database, owner.ID, models.TournamentStatusRegistrationOpen)
+	reg, _ := db.CreateGuestRegistration(ctx, database, tourn.ID, "Alice")
+	params := map[string]string{"id": strconv.FormatInt(tourn.ID, 10), "regID": strconv.FormatInt(reg.ID, 10)}
+
+	form := url.Values{"decklist": {"4 Brainstorm"}, "archetype": {strings.Repeat("x", db.MaxArchetypeLength+1)}}
+	rec := httptest.NewRecorder()
+	h.OrganizerSubmitDecklist(rec, requestWithUser("POST", "/", form.Encode(), owner, params))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("long archetype: status %d, want 400", rec.Code)
+	}
+
+	form.Set("archetype", "  Mono-Red  Aggro ")
+	rec = httptest.NewRecorder()
+	h.OrganizerSubmitDecklist(rec, requestWithUser("POST", "/", form.Encode(), owner, params))
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("status %d body %s", rec.Code, rec.Body.String())
+	}
+	got, _ := db.GetRegistrationByID(ctx, database, reg.ID)
+	if got.Archetype == nil || *got.Archetype != "Mono-Red  Aggro" {
+		t.Errorf("archetype = %v", got.Archetype)
+	}
+
+	form.Set("archetype", "")
+	rec = httptest.NewRecorder()
+	h.OrganizerSubmitDecklist(rec, requestWithUser("POST", "/", form.Encode(), owner, params))
+	if got, _ := db.GetRegistrationByID(ctx, database, reg.ID); got.Archetype != nil {
+		t.Errorf("cleared archetype = %q", *got.Archetype)
+	}
+}
+
+func TestTournamentHandler_Meta(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	tmpl := &mockTemplate{}
+	h := &TournamentHandler{DB: database, Tmpl: tmpl}
+
+	owner := mustCreateUser(t, database, "meta-owner@example.com", "MetaOwner")
+	open := mustCreateTournament(t, database, owner.ID, models.TournamentStatusRegistrationOpen)
+	rec := httptest.NewRecorder()
+	h.Meta(rec, requestWithUser("GET", "/", "", nil, map[string]string{"id": strconv.FormatInt(open.ID, 10)}))
+	if rec.Code != http.StatusFound {
+		t.Errorf("before the start: status %d, want 302", rec.Code)
+	}
+
+	_, tourn := startedTournament(t, database)
+	regs, _ := db.ListRegistrations(ctx, database, tourn.ID)
+	for i, a := range []string{"Burn", "burn", "Control"} {
+		if err := db.SetArchetype(ctx, database, regs[i].ID, a); err != nil {
+			t.Fatal(err)
+		}
+	}
+	rec = httptest.NewRecorder()
+	h.Meta(rec, requestWithUser("GET", "/", "", nil, map[string]string{"id": strconv.FormatInt(tourn.ID, 10)}))
+	if rec.Code != http.StatusOK || len(tmpl.calls) != 1 {
+		t.Fatalf("status %d, %d renders", rec.Code, len(tmpl.calls))
+	}
+	m := tmpl.calls[0].Data.(map[string]interface{})["Meta"].(engine.Metagame)
+	if m.Players != 4 || len(m.Archetypes) != 3 || m.Archetypes[0].Name != "Burn" || m.Archetypes[0].Players != 2 || !m.Archetypes[2].Unknown {
+		t.Errorf("meta = %+v", m)
+	}
+}
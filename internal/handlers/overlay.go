@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/engine"
+	"github.com/dstathis/openswiss/internal/middleware"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/dstathis/swisstools"
+	"github.com/go-chi/chi/v5"
+)
+
+// Stream overlays update every defaultOverlayRefresh seconds unless the URL
+// asks for another interval within [minOverlayRefresh, maxDisplayRefresh].
+// The standings overlay lists the top defaultOverlayTop players, or ?top=
+// up to maxOverlayTop.
+const (
+	defaultOverlayRefresh = 10
+	minOverlayRefresh     = 5
+	defaultOverlayTop     = 8
+	maxOverlayTop         = 32
+)
+
+type overlayTournament struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+// overlayStandings is the standings overlay's JSON. Standings is empty
+// while Hidden, in a blind final round (see engine.StandingsHidden).
+type overlayStandings struct {
+	Tournament overlayTournament      `json:"tournament"`
+	Round      int                    `json:"round"`
+	Hidden     bool                   `json:"hidden"`
+	Standings  []engine.FeaturePlayer `json:"standings"`
+}
+
+// overlayMatch is the match overlay's JSON. Match is null when there is
+// no match to show. While Hidden, in a blind final round, its players'
+// records and ranks are zero.
+type overlayMatch struct {
+	Tournament overlayTournament    `json:"tournament"`
+	Round      int                  `json:"round"`
+	Hidden     bool                 `json:"hidden"`
+	Match      *engine.FeatureMatch `json:"match"`
+}
+
+// queryInt reads the query parameter name as a number, clamped to [lo, hi],
+// or def if it is missing or not a number.
+func queryInt(r *http.Request, name string, def, lo, hi int) int {
+	n, err := strconv.Atoi(r.URL.Query().Get(name))
+	if err != nil {
+		return def
+	}
+	return min(max(n, lo), hi)
+}
+
+// renderOverlay writes an overlay as JSON with ?format=json, and otherwise
+// as the page, which fetches itself again every ?refresh= seconds. Neither
+// is cached, so a browser source never shows a stale score.
+func (h *TournamentHandler) renderOverlay(w http.ResponseWriter, r *http.Request, page string, v interface{}, data map[string]interface{}) {
+	w.Header().Set("Cache-Control", "no-store")
+	if r.URL.Query().Get("format") == "json" {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(v)
+		return
+	}
+	data["Overlay"] = true
+	data["Lang"] = middleware.Locale(r)
+	data["Refresh"] = queryInt(r, "refresh", defaultOverlayRefresh, minOverlayRefresh, maxDisplayRefresh)
+	h.Tmpl.ExecuteTemplate(w, page, data)
+}
+
+// OverlayStandings is a stream overlay of the top of the standings: a
+// minimal page on a transparent background, for a browser source in OBS.
+// ?top= sets how many players it lists. In a blind final round it is empty.
+// Public.
+func (h *TournamentHandler) OverlayStandings(w http.ResponseWriter, r *http.Request) {
+	t, eng, ok := h.loadDisplay(w, r)
+	if !ok {
+		return
+	}
+	out := overlayStandings{Tournament: overlayTournament{ID: t.ID, Name: t.Name}, Standings: []engine.FeaturePlayer{}}
+	if eng != nil {
+		out.Round = eng.GetCurrentRound()
+		out.Hidden = engine.StandingsHidden(t, eng)
+	}
+	if eng != nil && !out.Hidden {
+		regs, _ := db.ListRegistrations(r.Context(), h.DB, t.ID)
+		standings := engine.CachedStandings(t, eng, engine.TiebreakSeeds(regs))
+		top := queryInt(r, "top", defaultOverlayTop, 1, maxOverlayTop)
+		for _, s := range standings[:min(top, len(standings))] {
+			out.Standings = append(out.Standings, engine.FeaturePlayer{
+				ID: s.PlayerID, Name: s.Name, Rank: s.Rank, Points: s.Points, Wins: s.Wins, Losses: s.Losses, Draws: s.Draws,
+			})
+		}
+	}
+	h.renderOverlay(w, r, "overlay_standings.html", out, map[string]interface{}{
+		"Tournament": t,
+		"Standings":  out.Standings,
+	})
+}
+
+// OverlayMatch is a stream overlay of one match of the current Swiss round:
+// the players, their records going into the round and the score. {table}
+// is a table number, or "feature" for the round's first feature match
+// (?n=2 for the second, and so on), so one browser source follows the
+// stream table from round to round. With no such match, or while the round
+// is a draft, the overlay is empty rather than an error. In a blind final
+// round the players' records and ranks are left out. Public.
+func (h *TournamentHandler) OverlayMatch(w http.ResponseWriter, r *http.Request) {
+	t, eng, ok := h.loadDisplay(w, r)
+	if !ok {
+		return
+	}
+	param := chi.URLParam(r, "table")
+	table, err := strconv.Atoi(param)
+	if param != "feature" && (err != nil || table < 1) {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+	out := overlayMatch{Tournament: overlayTournament{ID: t.ID, Name: t.Name}}
+	if eng != nil && t.Status == models.TournamentStatusInProgress {
+		out.Round = eng.GetCurrentRound()
+		if param == "feature" {
+			table = 0
+			tables, _ := db.ListFeatureMatches(r.Context(), h.DB, t.ID, out.Round)
+			if n := queryInt(r, "n", 1, 1, models.MaxFeatureMatches); n <= len(tables) {
+				table = tables[n-1]
+			}
+		}
+		if table > 0 && !t.PairingsDraft(out.Round) {
+			var standings []swisstools.PlayerStanding
+			if out.Hidden = engine.StandingsHidden(t, eng); !out.Hidden {
+				regs, _ := db.ListRegistrations(r.Context(), h.DB, t.ID)
+				standings = engine.CachedStandings(t, eng, engine.TiebreakSeeds(regs))
+			}
+			if matches := engine.FeatureMatches(eng, standings, []int{table}); len(matches) == 1 {
+				out.Match = &matches[0]
+			}
+		}
+	}
+	h.renderOverlay(w, r, "overlay_match.html", out, map[string]interface{}{
+		"Tournament": t,
+		"Round":      out.Round,
+		"Hidden":     out.Hidden,
+		"Match":      out.Match,
+	})
+}
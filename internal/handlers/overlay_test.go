@@ -0,0 +1,87 @@
+//go:build integration
+
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/engine"
+)
+
+func TestTournamentHandler_Overlays(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	tmpl := &mockTemplate{}
+	h := &TournamentHandler{DB: database, Tmpl: tmpl}
+	_, tourn := startedTournament(t, database)
+	id := strconv.FormatInt(tourn.ID, 10)
+
+	get := func(handler http.HandlerFunc, path, table string, v interface{}) int {
+		t.Helper()
+		params := map[string]string{"id": id, "table": table}
+		rec := httptest.NewRecorder()
+		handler(rec, requestWithUser("GET", path, "", nil, params))
+		if rec.Header().Get("Cache-Control") != "no-store" {
+			t.Errorf("%s: Cache-Control %q", path, rec.Header().Get("Cache-Control"))
+		}
+		if v != nil && rec.Code == http.StatusOK {
+			if err := json.Unmarshal(rec.Body.Bytes(), v); err != nil {
+				t.Fatalf("%s: %v", path, err)
+			}
+		}
+		return rec.Code
+	}
+
+	var standings overlayStandings
+	if code := get(h.OverlayStandings, "/?format=json&top=3", "", &standings); code != http.StatusOK {
+		t.Fatalf("standings: status %d", code)
+	}
+	if standings.Tournament.ID != tourn.ID || standings.Round != 1 || len(standings.Standings) != 3 {
+		t.Errorf("standings = %+v", standings)
+	}
+
+	var match overlayMatch
+	if code := get(h.OverlayMatch, "/?format=json", "2", &match); code != http.StatusOK {
+		t.Fatalf("match: status %d", code)
+	}
+	if match.Match == nil || match.Match.Table != 2 || match.Match.PlayerA.Name == "" || match.Match.Reported {
+		t.Errorf("table 2 = %+v", match.Match)
+	}
+
+	// The feature alias is empty until a match is featured, then follows it.
+	match = overlayMatch{}
+	get(h.OverlayMatch, "/?format=json", "feature", &match)
+	if match.Match != nil {
+		t.Errorf("feature with none set = %+v", match.Match)
+	}
+	if err := db.ReplaceFeatureMatches(ctx, database, tourn.ID, 1, []int{2}); err != nil {
+		t.Fatal(err)
+	}
+	get(h.OverlayMatch, "/?format=json", "feature", &match)
+	if match.Match == nil || match.Match.Table != 2 {
+		t.Errorf("feature = %+v, want table 2", match.Match)
+	}
+
+	// A table the round doesn't have is an empty overlay; a bad one is 404.
+	match = overlayMatch{}
+	get(h.OverlayMatch, "/?format=json", "9", &match)
+	if match.Match != nil {
+		t.Errorf("table 9 = %+v", match.Match)
+	}
+	if code := get(h.OverlayMatch, "/", "x", nil); code != http.StatusNotFound {
+		t.Errorf("table x: status %d, want 404", code)
+	}
+
+	// The page is rendered as an overlay, at the asked refresh.
+	get(h.OverlayMatch, "/?refresh=1", "1", nil)
+	data := tmpl.calls[len(tmpl.calls)-1].Data.(map[string]interface{})
+	if data["Overlay"] != true || data["Refresh"] != minOverlayRefresh || data["Match"].(*engine.FeatureMatch) == nil {
+		t.Errorf("page data = %v", data)
+	}
+}
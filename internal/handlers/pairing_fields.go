@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/middleware"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/go-chi/chi/v5"
+	"github.com/lib/pq"
+)
+
+// AddPairingField defines a new custom field shown on every pairing of the
+// tournament. Min tier: Co-organizer.
+func (h *TournamentHandler) AddPairingField(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if !middleware.AuthorizeTournament(w, r, h.DB, id, models.TierCoOrganizer) {
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+	label := r.FormValue("label")
+	if !models.ValidPairingFieldLabel(label) {
+		http.Error(w, fmt.Sprintf("Label is required and may be at most %d characters", models.MaxPairingFieldLabel), http.StatusBadRequest)
+		return
+	}
+	f := &models.PairingField{
+		TournamentID: id,
+		Label:        strings.TrimSpace(label),
+		Public:       r.FormValue("public") == "on",
+	}
+	if err := db.CreatePairingField(r.Context(), h.DB, f); err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			http.Error(w, "A field with that label already exists", http.StatusConflict)
+			return
+		}
+		http.Error(w, "Failed to add field", http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, r, fmt.Sprintf("/tournaments/%d/manage/rounds", id), http.StatusSeeOther)
+}
+
+// RemovePairingField deletes a custom field along with all of its values.
+// Min tier: Co-organizer.
+func (h *TournamentHandler) RemovePairingField(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	fieldID, err := strconv.ParseInt(chi.URLParam(r, "fieldID"), 10, 64)
+	if err != nil {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+	if !middleware.AuthorizeTournament(w, r, h.DB, id, models.TierCoOrganizer) {
+		return
+	}
+	if err := db.DeletePairingField(r.Context(), h.DB, id, fieldID); err != nil {
+		if errors.Is(err, db.ErrPairingFieldNotFound) {
+			http.Error(w, "Not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Failed to remove field", http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, r, fmt.Sprintf("/tournaments/%d/manage/rounds", id), http.StatusSeeOther)
+}
+
+// attachPairingFields loads the tournament's custom fields and fills in each
+// pairing's values for the given round. With publicOnly, staff-only fields
+// are left out. Returns the fields in display order.
+func attachPairingFields(ctx context.Context, database db.DBTX, tournamentID int64, round int, pairings []resolvedPairing, publicOnly bool) []models.PairingField {
+	all, err := db.ListPairingFields(ctx, database, tournamentID)
+	if err != nil || len(all) == 0 {
+		return nil
+	}
+	var fields []models.PairingField
+	for _, f := range all {
+		if f.Public || !publicOnly {
+			fields = append(fields, f)
+		}
+	}
+	values, err := db.ListPairingFieldValues(ctx, database, tournamentID, round)
+	if err != nil {
+		return fields
+	}
+	for i := range pairings {
+		pairings[i].Fields = values[pairings[i].Table]
+	}
+	return fields
+}
+
+// savePairingFieldForm stores the field_<fieldID>_<table> inputs of the
+// results form for the given round. Inputs missing from the form are left
+// alone; blank inputs clear the value.
+func savePairingFieldForm(ctx context.Context, database db.DBTX, tournamentID int64, round int, pairings []resolvedPairing, form url.Values) error {
+	fields, err := db.ListPairingFields(ctx, database, tournamentID)
+	if err != nil {
+		return err
+	}
+	for _, f := range fields {
+		for _, p := range pairings {
+			if p.Table == 0 {
+				continue
+			}
+			vals, ok := form[fmt.Sprintf("field_%d_%d", f.ID, p.Table)]
+			if !ok {
+				continue
+			}
+			value := strings.TrimSpace(vals[0])
+			if utf8.RuneCountInString(value) > models.MaxPairingFieldValue {
+				return fmt.Errorf("%s for table %d is longer than %d characters", f.Label, p.Table, models.MaxPairingFieldValue)
+			}
+			if err := db.SetPairingFieldValue(ctx, database, f.ID, round, p.Table, value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+//go:build integration
+
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+func TestTournamentHandler_PairingFields(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	h := &TournamentHandler{DB: database, Tmpl: &mockTemplate{}}
+	owner, tourn := startedTournament(t, database)
+	idStr := strconv.FormatInt(tourn.ID, 10)
+	params := map[string]string{"id": idStr}
+
+	form := url.Values{"label": {"Board"}, "public": {"on"}}
+	rec := httptest.NewRecorder()
+	h.AddPairingField(rec, requestWithUser("POST", "/", form.Encode(), owner, params))
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("add: status %d body %s", rec.Code, rec.Body.String())
+	}
+	rec = httptest.NewRecorder()
+	h.AddPairingField(rec, requestWithUser("POST", "/", form.Encode(), owner, params))
+	if rec.Code != http.StatusConflict {
+		t.Errorf("duplicate add: status %d, want 409", rec.Code)
+	}
+	fields, _ := db.ListPairingFields(ctx, database, tourn.ID)
+	if len(fields) != 1 || !fields[0].Public {
+		t.Fatalf("fields = %+v", fields)
+	}
+	fieldKey := "field_" + strconv.FormatInt(fields[0].ID, 10) + "_1"
+
+	// Values ride along with the results form.
+	rec = httptest.NewRecorder()
+	h.SubmitResults(rec, requestWithUser("POST", "/", url.Values{fieldKey: {"Feature"}}.Encode(), owner, params))
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("submit: status %d body %s", rec.Code, rec.Body.String())
+	}
+	values, _ := db.ListPairingFieldValues(ctx, database, tourn.ID, 1)
+	if values[1][fields[0].ID] != "Feature" {
+		t.Errorf("values = %v", values)
+	}
+
+	tmpl := &mockTemplate{}
+	h.Tmpl = tmpl
+	h.Detail(httptest.NewRecorder(), requestWithUser("GET", "/", "", nil, params))
+	pairings := tmpl.calls[0].Data.(map[string]interface{})["Pairings"].([]resolvedPairing)
+	if pairings[0].Fields[fields[0].ID] != "Feature" {
+		t.Errorf("detail pairing fields = %v", pairings[0].Fields)
+	}
+
+	// Re-pairing clears the round's values.
+	rec = httptest.NewRecorder()
+	h.RepairRound(rec, requestWithUser("POST", "/", "", owner, params))
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("re-pair: status %d body %s", rec.Code, rec.Body.String())
+	}
+	if values, _ = db.ListPairingFieldValues(ctx, database, tourn.ID, 1); len(values) != 0 {
+		t.Errorf("values after re-pair = %v", values)
+	}
+
+	removeParams := map[string]string{"id": idStr, "fieldID": strconv.FormatInt(fields[0].ID, 10)}
+	rec = httptest.NewRecorder()
+	h.RemovePairingField(rec, requestWithUser("POST", "/", "", owner, removeParams))
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("remove: status %d", rec.Code)
+	}
+	if fields, _ = db.ListPairingFields(ctx, database, tourn.ID); len(fields) != 0 {
+		t.Errorf("fields after remove = %+v", fields)
+	}
+}
+
+func TestTournamentHandler_AddPairingField_Forbidden(t *testing.T) {
+	database := testDB(t)
+	h := &TournamentHandler{DB: database, Tmpl: &mockTemplate{}}
+	owner := mustCreateUser(t, database, "owner-pf@example.com", "OwnerPF")
+	other := mustCreateUser(t, database, "other-pf@example.com", "OtherPF")
+	tourn := mustCreateTournament(t, database, owner.ID, models.TournamentStatusScheduled)
+
+	rec := httptest.NewRecorder()
+	h.AddPairingField(rec, requestWithUser("POST", "/", "label=Board", other, map[string]string{"id": strconv.FormatInt(tourn.ID, 10)}))
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("expected 403, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+func TestMarkMyPairing(t *testing.T) {
+	pairings := func() []resolvedPairing {
+		return []resolvedPairing{
+			{Table: 1, PlayerAID: 1, PlayerBID: 2, PlayerAName: "Ann", PlayerBName: "Bob"},
+			{Table: 2, PlayerAID: 3, PlayerBID: 4, PlayerAName: "Cleo", PlayerBName: "Dora"},
+			{PlayerAID: 5, PlayerBID: -1, PlayerAName: "Eve", IsBye: true},
+		}
+	}
+	reg := func(id int) *models.Registration { return &models.Registration{EnginePlayerID: &id} }
+
+	for _, tc := range []struct {
+		name string
+		reg  *models.Registration
+		want *myPairing
+		row  int
+	}{
+		{"player A", reg(3), &myPairing{Table: 2, Opponent: "Dora"}, 1},
+		{"player B", reg(2), &myPairing{Table: 1, Opponent: "Ann"}, 0},
+		{"bye", reg(5), &myPairing{Bye: true}, 2},
+		{"not paired", reg(9), nil, -1},
+		{"not in engine", &models.Registration{}, nil, -1},
+		{"no registration", nil, nil, -1},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ps := pairings()
+			got := markMyPairing(ps, tc.reg)
+			if (got == nil) != (tc.want == nil) || got != nil && *got != *tc.want {
+				t.Fatalf("markMyPairing = %+v, want %+v", got, tc.want)
+			}
+			for i, p := range ps {
+				if p.Mine != (i == tc.row) {
+					t.Errorf("row %d Mine = %v", i, p.Mine)
+				}
+			}
+		})
+	}
+}
+
+func TestResolvedPairing_Winner(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		p    resolvedPairing
+		want string
+	}{
+		{"A won", resolvedPairing{PlayerAName: "Ann", PlayerBName: "Bob", PlayerAWins: 2, PlayerBWins: 1, Reported: true}, "Ann"},
+		{"B won", resolvedPairing{PlayerAName: "Ann", PlayerBName: "Bob", PlayerBWins: 2, Reported: true}, "Bob"},
+		{"draw", resolvedPairing{PlayerAName: "Ann", PlayerBName: "Bob", PlayerAWins: 1, PlayerBWins: 1, Draws: 1, Reported: true}, ""},
+		{"bye", resolvedPairing{PlayerAName: "Eve", PlayerAWins: 2, IsBye: true, Reported: true}, ""},
+		{"unreported", resolvedPairing{PlayerAName: "Ann", PlayerBName: "Bob"}, ""},
+	} {
+		if got := tc.p.Winner(); got != tc.want {
+			t.Errorf("%s: Winner = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
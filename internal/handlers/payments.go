@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/engine"
+	"github.com/dstathis/openswiss/internal/export"
+	"github.com/dstathis/openswiss/internal/middleware"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/dstathis/openswiss/internal/stripe"
+	"github.com/go-chi/chi/v5"
+)
+
+// SetPayment saves a player's payment from the Players page. Form fields:
+// payment_status, payment_amount as a decimal such as 10.50 (blank if not
+// noted; the entry fee for a player marked paid) and payment_method.
+// Min tier: Co-organizer.
+func (h *TournamentHandler) SetPayment(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	regID, _ := strconv.ParseInt(chi.URLParam(r, "regID"), 10, 64)
+	if !middleware.AuthorizeTournament(w, r, h.DB, id, models.TierCoOrganizer) {
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+	method := r.FormValue("payment_method")
+	p := engine.Payment{Status: r.FormValue("payment_status"), Method: &method}
+	if a := r.FormValue("payment_amount"); a != "" {
+		amount, err := models.ParseAmount(a)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		p.Amount = &amount
+	}
+	if _, err := engine.SetPayment(r.Context(), h.DB, id, regID, p); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	http.Redirect(w, r, fmt.Sprintf("/tournaments/%d/manage/players#players", id), http.StatusSeeOther)
+}
+
+// onlineFee is the entry fee reg can pay online, with its currency, e.g.
+// "12.50 USD"; "" when there is nothing to pay or no Stripe to pay it with.
+func (h *TournamentHandler) onlineFee(t *models.Tournament, reg *models.Registration) string {
+	if h.Stripe == nil || !engine.OwesEntryFee(t, reg) {
+		return ""
+	}
+	return models.FormatAmount(t.EntryFee) + " " + strings.ToUpper(h.Stripe.Config.Currency)
+}
+
+// PayOnline sends the signed-in player to a Stripe Checkout page for the
+// entry fee. The Stripe webhook marks them paid once it goes through (see
+// api.StripeAPI); the success page is the tournament page, which says so
+// until then. Only routed with Stripe configured.
+func (h *TournamentHandler) PayOnline(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	t, err := db.GetTournament(r.Context(), h.DB, id)
+	if err != nil {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+	user := middleware.GetUser(r.Context())
+	reg, err := db.GetRegistration(r.Context(), h.DB, id, user.ID)
+	if err != nil {
+		http.Error(w, "Not registered", http.StatusNotFound)
+		return
+	}
+	page := fmt.Sprintf("%s/tournaments/%d", h.BaseURL, id)
+	if !engine.OwesEntryFee(t, reg) {
+		http.Redirect(w, r, page, http.StatusSeeOther)
+		return
+	}
+	session, err := h.Stripe.CreateCheckout(r.Context(), stripe.Checkout{
+		TournamentID:   id,
+		RegistrationID: reg.ID,
+		Amount:         t.EntryFee,
+		Description:    t.Name + " entry fee",
+		Email:          user.Email,
+		SuccessURL:     page + "?paid=1",
+		CancelURL:      page,
+	})
+	if err != nil {
+		slog.WarnContext(r.Context(), "stripe checkout failed", "tournament_id", id, "err", err)
+		http.Error(w, "The payment page couldn't be opened; please try again later.", http.StatusBadGateway)
+		return
+	}
+	http.Redirect(w, r, session.URL, http.StatusSeeOther)
+}
+
+// ExportPlayers downloads the registrations as CSV, in sign-up order, with
+// their payments. Min tier: Co-organizer.
+func (h *TournamentHandler) ExportPlayers(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if !middleware.AuthorizeTournament(w, r, h.DB, id, models.TierCoOrganizer) {
+		return
+	}
+	regs, err := db.ListRegistrations(r.Context(), h.DB, id)
+	if err != nil {
+		http.Error(w, "Failed to load players", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="players-%d.csv"`, id))
+	out := csv.NewWriter(w)
+	out.Write([]string{"name", "status", "guest", "rating", "club", "payment_status", "payment_amount", "payment_method"})
+	for _, reg := range regs {
+		var rating, club, amount, method string
+		if reg.Rating != nil {
+			rating = strconv.Itoa(*reg.Rating)
+		}
+		if reg.Club != nil {
+			club = *reg.Club
+		}
+		if reg.PaymentAmount != nil {
+			amount = models.FormatAmount(*reg.PaymentAmount)
+		}
+		if reg.PaymentMethod != nil {
+			method = *reg.PaymentMethod
+		}
+		out.Write([]string{
+			export.CSVCell(reg.DisplayName),
+			reg.Status,
+			strconv.FormatBool(reg.IsGuest()),
+			rating,
+			export.CSVCell(club),
+			reg.PaymentStatus,
+			amount,
+			export.CSVCell(method),
+		})
+	}
+	out.Flush()
+}
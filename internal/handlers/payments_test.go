@@ -0,0 +1,119 @@
+//go:build integration
+
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/dstathis/openswiss/internal/stripe"
+)
+
+func TestTournamentHandler_Payments(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	tmpl := &mockTemplate{}
+	h := &TournamentHandler{DB: database, Tmpl: tmpl}
+	owner := mustCreateUser(t, database, "owner-payments@example.com", "OwnerPayments")
+	tourn := mustCreateTournament(t, database, owner.ID, models.TournamentStatusRegistrationOpen)
+	tourn.EntryFee = 1000
+	if err := db.UpdateTournament(ctx, database, tourn); err != nil {
+		t.Fatal(err)
+	}
+	ann, _ := db.CreateGuestRegistration(ctx, database, tourn.ID, "Ann")
+	db.CreateGuestRegistration(ctx, database, tourn.ID, "Bob")
+	idStr := strconv.FormatInt(tourn.ID, 10)
+	setPayment := func(form string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.SetPayment(rec, requestWithUser("POST", "/", form, owner, map[string]string{"id": idStr, "regID": strconv.FormatInt(ann.ID, 10)}))
+		return rec
+	}
+
+	if rec := setPayment("payment_status=paid&payment_amount=&payment_method=card"); rec.Code != http.StatusSeeOther {
+		t.Fatalf("status %d body %s", rec.Code, rec.Body.String())
+	}
+	got, _ := db.GetRegistrationByID(ctx, database, ann.ID)
+	if got.PaymentStatus != models.PaymentPaid || got.PaymentAmount == nil || *got.PaymentAmount != 1000 || got.PaymentMethod == nil || *got.PaymentMethod != "card" {
+		t.Errorf("Ann = %+v, want paid the entry fee by card", got)
+	}
+	if rec := setPayment("payment_status=paid&payment_amount=ten"); rec.Code != http.StatusBadRequest {
+		t.Errorf("bad amount: status %d, want 400", rec.Code)
+	}
+
+	// Only the unpaid are listed with ?payment=unpaid.
+	h.ManagePlayersPage(httptest.NewRecorder(), requestWithUser("GET", "/?payment=unpaid", "", owner, map[string]string{"id": idStr}))
+	data := tmpl.calls[0].Data.(map[string]interface{})
+	if rows := data["RegistrationRows"].([]models.Registration); len(rows) != 1 || rows[0].DisplayName != "Bob" {
+		t.Errorf("unpaid rows = %+v, want Bob", rows)
+	}
+	if data["UnpaidCount"] != 1 || data["TrackPayments"] != true {
+		t.Errorf("UnpaidCount %v, TrackPayments %v", data["UnpaidCount"], data["TrackPayments"])
+	}
+
+	// A name a spreadsheet would run as a formula is exported as text.
+	if _, err := db.CreateGuestRegistration(ctx, database, tourn.ID, "=1+1"); err != nil {
+		t.Fatal(err)
+	}
+	rec := httptest.NewRecorder()
+	h.ExportPlayers(rec, requestWithUser("GET", "/", "", owner, map[string]string{"id": idStr}))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "Ann,confirmed,true,,,paid,10.00,card\n") || !strings.Contains(rec.Body.String(), "\n'=1+1,confirmed,") {
+		t.Errorf("export: status %d body %q", rec.Code, rec.Body.String())
+	}
+
+	player := mustCreateUser(t, database, "player-payments@example.com", "PlayerPayments")
+	rec = httptest.NewRecorder()
+	h.ExportPlayers(rec, requestWithUser("GET", "/", "", player, map[string]string{"id": idStr}))
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("export by a player: status %d, want 403", rec.Code)
+	}
+}
+
+func TestTournamentHandler_PayOnline(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	var amount string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		amount = r.PostForm.Get("line_items[0][price_data][unit_amount]")
+		fmt.Fprint(w, `{"id": "cs_1", "url": "https://checkout.stripe.com/c/cs_1"}`)
+	}))
+	defer srv.Close()
+	h := &TournamentHandler{DB: database, Tmpl: &mockTemplate{}, BaseURL: "http://swiss.test",
+		Stripe: stripe.New(stripe.Config{SecretKey: "sk_test", WebhookSecret: "whsec", Currency: "usd"}, srv.URL)}
+	owner := mustCreateUser(t, database, "owner-payonline@example.com", "OwnerPayOnline")
+	player := mustCreateUser(t, database, "player-payonline@example.com", "PlayerPayOnline")
+	tourn := mustCreateTournament(t, database, owner.ID, models.TournamentStatusRegistrationOpen)
+	tourn.EntryFee = 1250
+	if err := db.UpdateTournament(ctx, database, tourn); err != nil {
+		t.Fatal(err)
+	}
+	params := map[string]string{"id": strconv.FormatInt(tourn.ID, 10)}
+	pay := func() *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.PayOnline(rec, requestWithUser("POST", "/", "", player, params))
+		return rec
+	}
+
+	if rec := pay(); rec.Code != http.StatusNotFound {
+		t.Errorf("not registered: status %d, want 404", rec.Code)
+	}
+	db.RegisterUser(ctx, database, tourn.ID, player.ID, player.DisplayName, models.RegistrationStatusConfirmed, 0)
+	rec := pay()
+	if rec.Code != http.StatusSeeOther || rec.Header().Get("Location") != "https://checkout.stripe.com/c/cs_1" || amount != "1250" {
+		t.Errorf("status %d, Location %q, amount %q; want Stripe's page for 1250", rec.Code, rec.Header().Get("Location"), amount)
+	}
+
+	reg, _ := db.GetRegistration(ctx, database, tourn.ID, player.ID)
+	db.SetPayment(ctx, database, tourn.ID, reg.ID, models.PaymentPaid, nil, nil)
+	amount = ""
+	if rec := pay(); rec.Header().Get("Location") != fmt.Sprintf("http://swiss.test/tournaments/%d", tourn.ID) || amount != "" {
+		t.Errorf("already paid: Location %q, Stripe asked for %q; want back to the tournament", rec.Header().Get("Location"), amount)
+	}
+}
@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/middleware"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/go-chi/chi/v5"
+)
+
+// AcceptAllPending confirms every pending registration in one go, for
+// clearing a sign-up rush without waiting on each decklist. Min tier:
+// Co-organizer.
+func (h *TournamentHandler) AcceptAllPending(w http.ResponseWriter, r *http.Request) {
+	h.bulkPending(w, r, db.ConfirmPendingRegistrations)
+}
+
+// RejectAllPending removes every pending registration in one go. Min tier:
+// Co-organizer.
+func (h *TournamentHandler) RejectAllPending(w http.ResponseWriter, r *http.Request) {
+	h.bulkPending(w, r, db.DeletePendingRegistrations)
+}
+
+func (h *TournamentHandler) bulkPending(w http.ResponseWriter, r *http.Request,
+	apply func(ctx context.Context, database *sql.DB, tournamentID int64) (int64, error)) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	t, err := db.GetTournament(r.Context(), h.DB, id)
+	if err != nil {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+	if !middleware.AuthorizeTournament(w, r, h.DB, t.ID, models.TierCoOrganizer) {
+		return
+	}
+	if !pendingEditable(t) {
+		http.Error(w, "Pending registrations can only be changed before the tournament starts", http.StatusBadRequest)
+		return
+	}
+	if _, err := apply(r.Context(), h.DB, id); err != nil {
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, r, fmt.Sprintf("/tournaments/%d/manage/players", id), http.StatusSeeOther)
+}
+
+// pendingEditable reports whether the tournament's pending registrations can
+// still be accepted or rejected. Once it starts, only confirmed players are
+// in the engine.
+func pendingEditable(t *models.Tournament) bool {
+	return t.Status == models.TournamentStatusScheduled || t.Status == models.TournamentStatusRegistrationOpen
+}
@@ -0,0 +1,82 @@
+//go:build integration
+
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+func TestTournamentHandler_AcceptRejectAllPending(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	h := &TournamentHandler{DB: database, Tmpl: &mockTemplate{}}
+	owner := mustCreateUser(t, database, "owner@example.com", "Owner")
+	tourn := mustCreateTournament(t, database, owner.ID, models.TournamentStatusRegistrationOpen)
+	params := map[string]string{"id": strconv.FormatInt(tourn.ID, 10)}
+	addPending := func(email, name string) {
+		u := mustCreateUser(t, database, email, name)
+		if _, err := db.CreatePendingRegistration(ctx, database, tourn.ID, u.ID, u.DisplayName); err != nil {
+			t.Fatal(err)
+		}
+	}
+	addPending("p1@example.com", "P1")
+	addPending("p2@example.com", "P2")
+	db.CreateGuestRegistration(ctx, database, tourn.ID, "Walk-in")
+
+	tmpl := &mockTemplate{}
+	h.Tmpl = tmpl
+	h.ManagePage(httptest.NewRecorder(), requestWithUser("GET", "/", "", owner, params))
+	if got := tmpl.calls[0].Data.(map[string]interface{})["PendingCount"]; got != 2 {
+		t.Errorf("PendingCount = %v, want 2", got)
+	}
+
+	rec := httptest.NewRecorder()
+	h.RejectAllPending(rec, requestWithUser("POST", "/", "", owner, params))
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("reject: status %d body %s", rec.Code, rec.Body.String())
+	}
+	if regs, _ := db.ListRegistrations(ctx, database, tourn.ID); len(regs) != 1 || regs[0].DisplayName != "Walk-in" {
+		t.Errorf("after reject: %+v", regs)
+	}
+
+	addPending("p3@example.com", "P3")
+	rec = httptest.NewRecorder()
+	h.AcceptAllPending(rec, requestWithUser("POST", "/", "", owner, params))
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("accept: status %d body %s", rec.Code, rec.Body.String())
+	}
+	for _, reg := range mustListRegs(t, database, tourn.ID) {
+		if reg.Status != models.RegistrationStatusConfirmed {
+			t.Errorf("%s: status %q after accept", reg.DisplayName, reg.Status)
+		}
+	}
+}
+
+func TestTournamentHandler_AcceptAllPending_ForbiddenAndStarted(t *testing.T) {
+	database := testDB(t)
+	h := &TournamentHandler{DB: database, Tmpl: &mockTemplate{}}
+	owner := mustCreateUser(t, database, "owner@example.com", "Owner")
+	other := mustCreateUser(t, database, "other@example.com", "Other")
+	tourn := mustCreateTournament(t, database, owner.ID, models.TournamentStatusRegistrationOpen)
+	params := map[string]string{"id": strconv.FormatInt(tourn.ID, 10)}
+
+	rec := httptest.NewRecorder()
+	h.AcceptAllPending(rec, requestWithUser("POST", "/", "", other, params))
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("non-staff: status %d, want 403", rec.Code)
+	}
+
+	started := mustCreateTournament(t, database, owner.ID, models.TournamentStatusInProgress)
+	rec = httptest.NewRecorder()
+	h.RejectAllPending(rec, requestWithUser("POST", "/", "", owner, map[string]string{"id": strconv.FormatInt(started.ID, 10)}))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("started: status %d, want 400", rec.Code)
+	}
+}
@@ -3,6 +3,7 @@ package handlers
 import (
 	"database/sql"
 	"net/http"
+	"time"
 
 	"github.com/dstathis/openswiss/internal/db"
 	"github.com/dstathis/openswiss/internal/middleware"
@@ -29,8 +30,16 @@ func (h *PlayerHandler) Dashboard(w http.ResponseWriter, r *http.Request) {
 		regList = append(regList, RegWithTournament{Registration: reg, Tournament: t})
 	}
 
+	// The banner shows what staff announced in the player's own events.
+	announcements, _ := db.UserAnnouncements(r.Context(), h.DB, user.ID, time.Now())
+
 	h.Tmpl.ExecuteTemplate(w, "dashboard.html", map[string]interface{}{
 		"User":          user,
+		"CSRFToken":     middleware.CSRFToken(r),
+		"Theme":         middleware.Theme(r),
+		"Flash":         middleware.Flash(r),
+		"Lang":          middleware.Locale(r),
 		"Registrations": regList,
+		"Announcements": announcements,
 	})
 }
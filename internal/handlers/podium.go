@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/engine"
+	"github.com/dstathis/openswiss/internal/middleware"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/dstathis/swisstools"
+	"github.com/go-chi/chi/v5"
+)
+
+// podiumSize is how many finishers the results page puts on the podium.
+const podiumSize = 3
+
+// Results is the public results page of a complete tournament (see
+// engine.Complete): the podium, then every player's final place with their
+// record and tiebreakers. Until then it sends visitors to the tournament
+// page.
+func (h *TournamentHandler) Results(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+	t, err := db.GetTournament(r.Context(), h.DB, id)
+	if err != nil {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+	eng, err := swisstools.LoadTournament(t.EngineState)
+	if t.EngineState == nil || err != nil || !engine.Complete(t, &eng) {
+		http.Redirect(w, r, fmt.Sprintf("/tournaments/%d", id), http.StatusFound)
+		return
+	}
+	h.Views.View(r, id, eng.GetCurrentRound(), models.PageStandings)
+	regs, _ := db.ListRegistrations(r.Context(), h.DB, id)
+	placings := engine.FinalStandings(&eng, t.TiebreakOrder(), engine.TiebreakSeeds(regs))
+	podium := placings
+	if len(podium) > podiumSize {
+		podium = podium[:podiumSize]
+	}
+
+	q := nameQuery(r)
+	rows, pager := filterPage(r, "page", "results", placings,
+		func(p engine.Placing) bool { return matchesName(q, p.Standing.Name) })
+	h.Tmpl.ExecuteTemplate(w, "tournament_results.html", map[string]interface{}{
+		"User":       middleware.GetUser(r.Context()),
+		"CSRFToken":  middleware.CSRFToken(r),
+		"Theme":      middleware.Theme(r),
+		"Flash":      middleware.Flash(r),
+		"Lang":       middleware.Locale(r),
+		"Tournament": t,
+		"Query":      q,
+		"Podium":     podium,
+		"Placings":   rows,
+		"Pager":      pager,
+		"Playoff":    eng.GetPlayoff() != nil,
+	})
+}
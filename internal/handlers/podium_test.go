@@ -0,0 +1,51 @@
+//go:build integration
+
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/engine"
+)
+
+func TestTournamentHandler_Results(t *testing.T) {
+	database := testDB(t)
+	tmpl := &mockTemplate{}
+	h := &TournamentHandler{DB: database, Tmpl: tmpl}
+	owner, tourn := startedTournament(t, database)
+	params := map[string]string{"id": strconv.FormatInt(tourn.ID, 10)}
+
+	rec := httptest.NewRecorder()
+	h.Results(rec, requestWithUser("GET", "/", "", nil, params))
+	if rec.Code != http.StatusFound || len(tmpl.calls) != 0 {
+		t.Errorf("running tournament: status %d, want a redirect", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	h.Finish(rec, requestWithUser("POST", "/", "", owner, params))
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("finish: status %d, body %s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	h.Results(rec, requestWithUser("GET", "/", "", nil, params))
+	if len(tmpl.calls) != 1 || tmpl.calls[0].Name != "tournament_results.html" {
+		t.Fatalf("status %d, calls %+v", rec.Code, tmpl.calls)
+	}
+	data := tmpl.calls[0].Data.(map[string]interface{})
+	podium := data["Podium"].([]engine.Placing)
+	placings := data["Placings"].([]engine.Placing)
+	if len(podium) != podiumSize || len(placings) != 4 || podium[0] != placings[0] || data["Playoff"] != false {
+		t.Errorf("podium %+v, placings %+v", podium, placings)
+	}
+
+	// No more changes once it's over.
+	rec = httptest.NewRecorder()
+	h.Finish(rec, requestWithUser("POST", "/", "", owner, params))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("finishing again: status %d, want 400", rec.Code)
+	}
+}
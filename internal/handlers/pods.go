@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/engine"
+	"github.com/dstathis/openswiss/internal/middleware"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/go-chi/chi/v5"
+)
+
+// stages is what the detail and manage pages show of a multi-stage event:
+// for a pod, the event it feeds; for the event, its pods and the combined
+// standings across them and the finals.
+type stages struct {
+	Parent   *models.Tournament
+	Pods     []models.Tournament
+	Combined []engine.StagePlacing
+}
+
+// loadStages reads t's place in a multi-stage event. Unless staff is set,
+// stages in a blind final round are left out of the combined standings.
+// Errors leave the section off the page.
+func loadStages(ctx context.Context, database *sql.DB, t *models.Tournament, staff bool) stages {
+	var s stages
+	if t.ParentID != nil {
+		s.Parent, _ = db.GetTournament(ctx, database, *t.ParentID)
+		return s
+	}
+	s.Pods, _ = db.ListPods(ctx, database, t.ID)
+	if len(s.Pods) == 0 {
+		return s
+	}
+	finals, pods, err := engine.LoadStages(ctx, database, t, s.Pods)
+	if err == nil {
+		if !staff {
+			engine.HideStages(finals, pods)
+		}
+		s.Combined = engine.CombinedStandings(finals, pods)
+	}
+	return s
+}
+
+// AddPod adds a pod to a tournament, making it a multi-stage event. Form
+// field: name. Min tier: Co-organizer.
+func (h *TournamentHandler) AddPod(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if !middleware.AuthorizeTournament(w, r, h.DB, id, models.TierCoOrganizer) {
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+	pod, err := engine.CreatePod(r.Context(), h.DB, id, r.FormValue("name"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	http.Redirect(w, r, fmt.Sprintf("/tournaments/%d/manage", pod.ID), http.StatusSeeOther)
+}
+
+// AdvancePods registers the top finishers of every pod into the finals.
+// Min tier: Co-organizer.
+func (h *TournamentHandler) AdvancePods(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if !middleware.AuthorizeTournament(w, r, h.DB, id, models.TierCoOrganizer) {
+		return
+	}
+	if _, err := engine.AdvancePods(r.Context(), h.DB, id); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	http.Redirect(w, r, fmt.Sprintf("/tournaments/%d/manage/players", id), http.StatusSeeOther)
+}
+
+// SplitPools deals the tournament's players into round robin pools (see
+// engine.SplitPools). Form field: count. Min tier: Co-organizer.
+func (h *TournamentHandler) SplitPools(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if !middleware.AuthorizeTournament(w, r, h.DB, id, models.TierCoOrganizer) {
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+	count, _ := strconv.Atoi(r.FormValue("count"))
+	if _, err := engine.SplitPools(r.Context(), h.DB, id, count); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	http.Redirect(w, r, fmt.Sprintf("/tournaments/%d/manage#stages", id), http.StatusSeeOther)
+}
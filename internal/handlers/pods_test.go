@@ -0,0 +1,159 @@
+//go:build integration
+
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/engine"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/dstathis/swisstools"
+)
+
+// playPod registers four guests in pod podID and plays it to the end: one
+// round in which the first-listed player of each pairing wins.
+func playPod(t *testing.T, database *sql.DB, podID int64) {
+	t.Helper()
+	ctx := context.Background()
+	for i := 0; i < 4; i++ {
+		if _, err := db.CreateGuestRegistration(ctx, database, podID, fmt.Sprintf("Pod%d Player %d", podID, i)); err != nil {
+			t.Fatalf("register %d: %v", i, err)
+		}
+	}
+	regs, _ := db.ListRegistrations(ctx, database, podID)
+	if err := engine.WithTournamentEngine(ctx, database, podID,
+		func(tx *sql.Tx, tm *models.Tournament, eng *swisstools.Tournament) (string, error) {
+			state, err := engine.InitTournamentEngine(ctx, tx, tm, regs)
+			if err != nil {
+				return "", err
+			}
+			ne, err := swisstools.LoadTournament(state)
+			if err != nil {
+				return "", err
+			}
+			*eng = ne
+			for _, p := range eng.GetRound() {
+				if err := eng.AddResult(p.PlayerA(), 2, 0, 0); err != nil {
+					return "", err
+				}
+			}
+			return models.TournamentStatusFinished, eng.FinishTournament()
+		}); err != nil {
+		t.Fatalf("play pod: %v", err)
+	}
+}
+
+func TestTournamentHandler_Pods(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	tmpl := &mockTemplate{}
+	h := &TournamentHandler{DB: database, Tmpl: tmpl}
+	owner := mustCreateUser(t, database, "owner-pods@example.com", "Owner Pods")
+	finals := mustCreateTournament(t, database, owner.ID, models.TournamentStatusScheduled)
+	finals.PodAdvance = 1
+	if err := db.UpdateTournament(ctx, database, finals); err != nil {
+		t.Fatalf("set pod_advance: %v", err)
+	}
+	params := map[string]string{"id": strconv.FormatInt(finals.ID, 10)}
+
+	for _, name := range []string{"Pod A", "Pod B"} {
+		rec := httptest.NewRecorder()
+		h.AddPod(rec, requestWithUser("POST", "/", url.Values{"name": {name}}.Encode(), owner, params))
+		if rec.Code != http.StatusSeeOther {
+			t.Fatalf("add %s: status %d %s", name, rec.Code, rec.Body.String())
+		}
+	}
+	pods, _ := db.ListPods(ctx, database, finals.ID)
+	if len(pods) != 2 || pods[0].Status != models.TournamentStatusRegistrationOpen || pods[0].PointsWin != 3 {
+		t.Fatalf("pods = %+v", pods)
+	}
+
+	// A pod can't have pods, and the finals wait for every pod.
+	rec := httptest.NewRecorder()
+	h.AddPod(rec, requestWithUser("POST", "/", "name=Sub", owner, map[string]string{"id": strconv.FormatInt(pods[0].ID, 10)}))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("pod of a pod: status %d", rec.Code)
+	}
+	playPod(t, database, pods[0].ID)
+	rec = httptest.NewRecorder()
+	h.AdvancePods(rec, requestWithUser("POST", "/", "", owner, params))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("advance with a pod unplayed: status %d", rec.Code)
+	}
+
+	playPod(t, database, pods[1].ID)
+	rec = httptest.NewRecorder()
+	h.AdvancePods(rec, requestWithUser("POST", "/", "", owner, params))
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("advance: status %d %s", rec.Code, rec.Body.String())
+	}
+	if regs := mustListRegs(t, database, finals.ID); len(regs) != 2 || regs[0].AdvancedFrom == nil {
+		t.Errorf("finalists = %+v", regs)
+	}
+
+	rec = httptest.NewRecorder()
+	h.Detail(rec, requestWithUser("GET", "/", "", nil, params))
+	data := tmpl.calls[len(tmpl.calls)-1].Data.(map[string]interface{})
+	s := data["Stages"].(stages)
+	if len(s.Pods) != 2 || len(s.Combined) != 8 || !s.Combined[0].Advanced || s.Combined[2].Advanced {
+		t.Errorf("stages = %+v", s)
+	}
+
+	rec = httptest.NewRecorder()
+	h.Detail(rec, requestWithUser("GET", "/", "", nil, map[string]string{"id": strconv.FormatInt(pods[0].ID, 10)}))
+	data = tmpl.calls[len(tmpl.calls)-1].Data.(map[string]interface{})
+	if p := data["Stages"].(stages).Parent; p == nil || p.ID != finals.ID {
+		t.Errorf("pod's parent = %+v", p)
+	}
+}
+
+func TestTournamentHandler_SplitPools(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	h := &TournamentHandler{DB: database, Tmpl: &mockTemplate{}}
+	owner := mustCreateUser(t, database, "owner-pools@example.com", "Owner Pools")
+	event := mustCreateTournament(t, database, owner.ID, models.TournamentStatusScheduled)
+	params := map[string]string{"id": strconv.FormatInt(event.ID, 10)}
+	for i := 0; i < 5; i++ {
+		if _, err := db.CreateGuestRegistration(ctx, database, event.ID, fmt.Sprintf("Pooled %d", i)); err != nil {
+			t.Fatalf("register %d: %v", i, err)
+		}
+	}
+
+	// Three pools of five players leave one with a single player.
+	rec := httptest.NewRecorder()
+	h.SplitPools(rec, requestWithUser("POST", "/", "count=3", owner, params))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("too few players: status %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	h.SplitPools(rec, requestWithUser("POST", "/", "count=2", owner, params))
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("split: status %d %s", rec.Code, rec.Body.String())
+	}
+	pools, _ := db.ListPods(ctx, database, event.ID)
+	if len(pools) != 2 || pools[0].Name != "Pool A" || pools[1].PairingAlgorithm != models.PairingRoundRobin {
+		t.Fatalf("pools = %+v", pools)
+	}
+	if regs := mustListRegs(t, database, event.ID); len(regs) != 0 {
+		t.Errorf("%d players left in the event", len(regs))
+	}
+	if a, b := mustListRegs(t, database, pools[0].ID), mustListRegs(t, database, pools[1].ID); len(a) != 3 || len(b) != 2 {
+		t.Errorf("pool sizes = %d, %d", len(a), len(b))
+	}
+
+	rec = httptest.NewRecorder()
+	h.SplitPools(rec, requestWithUser("POST", "/", "count=2", owner, params))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("split twice: status %d", rec.Code)
+	}
+}
@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/engine"
+	"github.com/dstathis/openswiss/internal/middleware"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/go-chi/chi/v5"
+)
+
+// PresetHandler serves the settings presets organizers start new
+// tournaments from (see models.Preset). Every page needs the organizer
+// role.
+type PresetHandler struct {
+	DB   *sql.DB
+	Tmpl TemplateRenderer
+}
+
+// ListPage lists the presets, each with links to start a tournament from
+// it and to export it, and the form that imports one.
+func (h *PresetHandler) ListPage(w http.ResponseWriter, r *http.Request) {
+	presets, err := db.ListPresets(r.Context(), h.DB)
+	if err != nil {
+		http.Error(w, "Failed to load presets", http.StatusInternalServerError)
+		return
+	}
+	h.Tmpl.ExecuteTemplate(w, "presets.html", map[string]interface{}{
+		"User":      middleware.GetUser(r.Context()),
+		"CSRFToken": middleware.CSRFToken(r),
+		"Theme":     middleware.Theme(r),
+		"Flash":     middleware.Flash(r),
+		"Lang":      middleware.Locale(r),
+		"Presets":   presets,
+	})
+}
+
+// Export downloads a preset as JSON, to import on another server or keep.
+func (h *PresetHandler) Export(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "pid"), 10, 64)
+	p, err := db.GetPreset(r.Context(), h.DB, id)
+	if err != nil {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="preset-%d.json"`, p.ID))
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(p)
+}
+
+// Import saves a preset from an exported file. Form fields (multipart):
+// file, and name to save it under instead of the file's.
+func (h *PresetHandler) Import(w http.ResponseWriter, r *http.Request) {
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		http.Error(w, "Choose a preset file to import", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+	p, err := engine.ReadPreset(file)
+	if err != nil {
+		presetError(w, err)
+		return
+	}
+	if name := r.FormValue("name"); name != "" {
+		p.Name = name
+	}
+	user := middleware.GetUser(r.Context())
+	if err := engine.SavePreset(r.Context(), h.DB, p, user.ID); err != nil {
+		presetError(w, err)
+		return
+	}
+	slog.Info("preset imported", "preset_id", p.ID, "name", p.Name, "user_id", user.ID)
+	middleware.SetFlash(w, r, middleware.FlashSuccess, fmt.Sprintf("Preset %s imported.", p.Name))
+	http.Redirect(w, r, "/presets", http.StatusSeeOther)
+}
+
+// Delete removes a preset. Tournaments created from it keep their settings.
+func (h *PresetHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "pid"), 10, 64)
+	if err := db.DeletePreset(r.Context(), h.DB, id); err != nil {
+		if errors.Is(err, db.ErrPresetNotFound) {
+			http.Error(w, "Not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Failed to delete preset", http.StatusInternalServerError)
+		return
+	}
+	middleware.SetFlash(w, r, middleware.FlashSuccess, "The preset is deleted.")
+	http.Redirect(w, r, "/presets", http.StatusSeeOther)
+}
+
+// SavePreset saves the tournament's settings and pairing fields as a
+// preset, replacing one of the same name. Form field: name. Min tier:
+// Co-organizer, and the organizer role, as presets are shared by
+// organizers.
+func (h *TournamentHandler) SavePreset(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	t, err := db.GetTournament(r.Context(), h.DB, id)
+	if err != nil {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+	if !middleware.AuthorizeTournament(w, r, h.DB, t.ID, models.TierCoOrganizer) {
+		return
+	}
+	fields, err := db.ListPairingFields(r.Context(), h.DB, t.ID)
+	if err != nil {
+		http.Error(w, "Failed to load pairing fields", http.StatusInternalServerError)
+		return
+	}
+	p := &models.Preset{Name: r.FormValue("name"), Settings: models.PresetOf(t, fields)}
+	user := middleware.GetUser(r.Context())
+	if err := engine.SavePreset(r.Context(), h.DB, p, user.ID); err != nil {
+		presetError(w, err)
+		return
+	}
+	slog.Info("preset saved", "preset_id", p.ID, "name", p.Name, "tournament_id", t.ID, "user_id", user.ID)
+	middleware.SetFlash(w, r, middleware.FlashSuccess, fmt.Sprintf("Settings saved as preset %s.", p.Name))
+	http.Redirect(w, r, fmt.Sprintf("/tournaments/%d/manage", t.ID), http.StatusSeeOther)
+}
+
+// presetError answers for an error from engine.SavePreset.
+func presetError(w http.ResponseWriter, err error) {
+	if errors.Is(err, engine.ErrInvalidPreset) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	http.Error(w, "Failed to save preset", http.StatusInternalServerError)
+}
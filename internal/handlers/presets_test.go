@@ -0,0 +1,142 @@
+//go:build integration
+
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+// presetUpload builds a multipart preset import of data, saved as name.
+func presetUpload(t *testing.T, user *models.User, data, name string) *http.Request {
+	t.Helper()
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	part, err := mw.CreateFormFile("file", "preset.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	part.Write([]byte(data))
+	mw.WriteField("name", name)
+	mw.Close()
+	req := requestWithUser("POST", "/", "", user, nil)
+	req.Body = io.NopCloser(&body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	return req
+}
+
+func TestPresets(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	h := &TournamentHandler{DB: database, Tmpl: &mockTemplate{}}
+	ph := &PresetHandler{DB: database, Tmpl: &mockTemplate{}}
+	org := mustCreateUser(t, database, "org-preset@example.com", "OrgPreset", models.RoleOrganizer)
+
+	rounds := 5
+	src := mustCreateTournament(t, database, org.ID, models.TournamentStatusScheduled)
+	src.NumRounds = &rounds
+	src.PointsWin = 2
+	src.Colors = true
+	if err := db.UpdateTournament(ctx, database, src); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.CreatePairingField(ctx, database, &models.PairingField{TournamentID: src.ID, Label: "Board", Public: true}); err != nil {
+		t.Fatal(err)
+	}
+	params := map[string]string{"id": strconv.FormatInt(src.ID, 10)}
+
+	rec := httptest.NewRecorder()
+	h.SavePreset(rec, requestWithUser("POST", "/", url.Values{"name": {" "}}.Encode(), org, params))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("blank name: status %d, want 400", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	h.SavePreset(rec, requestWithUser("POST", "/", url.Values{"name": {"Weekly Swiss"}}.Encode(), org, params))
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("save: status %d body %s", rec.Code, rec.Body.String())
+	}
+	presets, _ := db.ListPresets(ctx, database)
+	if len(presets) != 1 || presets[0].Name != "Weekly Swiss" {
+		t.Fatalf("presets = %+v", presets)
+	}
+	preset := presets[0]
+	if s := preset.Settings; s.NumRounds == nil || *s.NumRounds != 5 || s.PointsWin != 2 || !s.Colors ||
+		len(s.PairingFields) != 1 || s.PairingFields[0] != (models.PresetField{Label: "Board", Public: true}) {
+		t.Errorf("preset settings = %+v", s)
+	}
+	pidStr := strconv.FormatInt(preset.ID, 10)
+
+	// The new tournament form starts from the preset.
+	tmpl := &mockTemplate{}
+	h.Tmpl = tmpl
+	h.NewPage(httptest.NewRecorder(), requestWithUser("GET", "/tournaments/new?preset="+pidStr, "", org, nil))
+	data := tmpl.calls[0].Data.(map[string]interface{})
+	if f := data["Form"].(*models.Tournament); f.PointsWin != 2 || !f.Colors || data["Preset"].(*models.Preset).ID != preset.ID {
+		t.Errorf("form = %+v, preset %+v", f, data["Preset"])
+	}
+	rec = httptest.NewRecorder()
+	h.NewPage(rec, requestWithUser("GET", "/tournaments/new?preset=999999", "", org, nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("unknown preset: status %d, want 404", rec.Code)
+	}
+
+	// Creating from it copies the pairing fields.
+	form := url.Values{"name": {"Weekly #2"}, "preset_id": {pidStr}, "num_rounds": {"5"}, "points_win": {"2"}}
+	rec = httptest.NewRecorder()
+	h.Create(rec, requestWithUser("POST", "/tournaments", form.Encode(), org, nil))
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("create: status %d body %s", rec.Code, rec.Body.String())
+	}
+	id, _ := strconv.ParseInt(strings.TrimPrefix(rec.Header().Get("Location"), "/tournaments/"), 10, 64)
+	fields, _ := db.ListPairingFields(ctx, database, id)
+	if len(fields) != 1 || fields[0].Label != "Board" || !fields[0].Public {
+		t.Errorf("created fields = %+v", fields)
+	}
+
+	// Export and import round trip, under a new name.
+	rec = httptest.NewRecorder()
+	ph.Export(rec, requestWithUser("GET", "/", "", org, map[string]string{"pid": pidStr}))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Header().Get("Content-Disposition"), "preset-"+pidStr+".json") {
+		t.Fatalf("export: status %d headers %v", rec.Code, rec.Header())
+	}
+	exported := rec.Body.String()
+	rec = httptest.NewRecorder()
+	ph.Import(rec, presetUpload(t, org, exported, "Weekly Copy"))
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("import: status %d body %s", rec.Code, rec.Body.String())
+	}
+	presets, _ = db.ListPresets(ctx, database)
+	if len(presets) != 2 {
+		t.Fatalf("after import: %+v", presets)
+	}
+	for _, p := range presets {
+		if p.Name == "Weekly Copy" && p.Settings.PointsWin != 2 {
+			t.Errorf("imported settings = %+v", p.Settings)
+		}
+	}
+	rec = httptest.NewRecorder()
+	ph.Import(rec, presetUpload(t, org, `{"name":"Bad","settings":{"pairing_algorithm":"random"}}`, ""))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("invalid import: status %d, want 400", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	ph.Delete(rec, requestWithUser("POST", "/", "", org, map[string]string{"pid": pidStr}))
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("delete: status %d", rec.Code)
+	}
+	if _, err := db.GetPreset(ctx, database, preset.ID); err != db.ErrPresetNotFound {
+		t.Errorf("after delete: err %v", err)
+	}
+}
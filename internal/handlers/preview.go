@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/dstathis/openswiss/internal/engine"
+	"github.com/dstathis/openswiss/internal/middleware"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/dstathis/swisstools"
+	"github.com/go-chi/chi/v5"
+)
+
+// PublishPairings shows the current round's draft pairings to players and
+// spectators. Min tier: Co-organizer.
+func (h *TournamentHandler) PublishPairings(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if !middleware.AuthorizeTournament(w, r, h.DB, id, models.TierCoOrganizer) {
+		return
+	}
+	err := engine.WithTournamentEngine(r.Context(), h.DB, id,
+		func(tx *sql.Tx, t *models.Tournament, eng *swisstools.Tournament) (string, error) {
+			return "", engine.PublishPairings(r.Context(), tx, t, eng)
+		})
+	if err != nil {
+		engineError(w, r, err)
+		return
+	}
+	http.Redirect(w, r, fmt.Sprintf("/tournaments/%d/manage/rounds", id), http.StatusSeeOther)
+}
+
+// SwapPlayers hand-edits the draft round by swapping the seats of two
+// players. Form fields: player_a, player_b (engine player IDs). Min tier:
+// Co-organizer.
+func (h *TournamentHandler) SwapPlayers(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if !middleware.AuthorizeTournament(w, r, h.DB, id, models.TierCoOrganizer) {
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+	a, errA := strconv.Atoi(r.FormValue("player_a"))
+	b, errB := strconv.Atoi(r.FormValue("player_b"))
+	if errA != nil || errB != nil {
+		http.Error(w, "Pick two players", http.StatusBadRequest)
+		return
+	}
+	err := engine.WithTournamentEngine(r.Context(), h.DB, id,
+		func(tx *sql.Tx, t *models.Tournament, eng *swisstools.Tournament) (string, error) {
+			return "", engine.SwapPlayers(r.Context(), tx, t, eng, a, b)
+		})
+	if err != nil {
+		engineError(w, r, err)
+		return
+	}
+	http.Redirect(w, r, fmt.Sprintf("/tournaments/%d/manage/rounds#pairings", id), http.StatusSeeOther)
+}
@@ -0,0 +1,97 @@
+//go:build integration
+
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/swisstools"
+)
+
+func TestTournamentHandler_PairingPreview(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	h := &TournamentHandler{DB: database, Tmpl: &mockTemplate{}}
+	owner, tourn := startedTournament(t, database)
+	idStr := strconv.FormatInt(tourn.ID, 10)
+	params := map[string]string{"id": idStr}
+
+	tourn.PreviewPairings = true
+	if err := db.UpdateTournament(ctx, database, tourn); err != nil {
+		t.Fatalf("enable preview: %v", err)
+	}
+	rec := httptest.NewRecorder()
+	h.NextRound(rec, requestWithUser("POST", "/", "", owner, params))
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("next round: status %d body %s", rec.Code, rec.Body.String())
+	}
+	if got, _ := db.GetTournament(ctx, database, tourn.ID); got.DraftRound != 2 {
+		t.Fatalf("draft round = %d, want 2", got.DraftRound)
+	}
+
+	// Players see no pairings yet; staff do.
+	tmpl := &mockTemplate{}
+	h.Tmpl = tmpl
+	h.Detail(httptest.NewRecorder(), requestWithUser("GET", "/", "", nil, params))
+	data := tmpl.calls[0].Data.(map[string]interface{})
+	if data["Draft"] != true || len(data["Pairings"].([]resolvedPairing)) != 0 {
+		t.Errorf("detail: Draft = %v, pairings = %v", data["Draft"], data["Pairings"])
+	}
+	h.RoundPage(httptest.NewRecorder(), requestWithUser("GET", "/", "", nil, map[string]string{"id": idStr, "round": "2"}))
+	if data = tmpl.calls[1].Data.(map[string]interface{}); len(data["Pairings"].([]resolvedPairing)) != 0 {
+		t.Errorf("round page shows the draft: %v", data["Pairings"])
+	}
+	h.ManageRoundsPage(httptest.NewRecorder(), requestWithUser("GET", "/", "", owner, params))
+	data = tmpl.calls[2].Data.(map[string]interface{})
+	if data["Draft"] != true || len(data["Pairings"].([]resolvedPairing)) != 2 || len(data["DraftSeats"].([]displaySeat)) != 4 {
+		t.Errorf("manage: Draft = %v, pairings = %v", data["Draft"], data["Pairings"])
+	}
+
+	// A draft round can't be closed.
+	rec = httptest.NewRecorder()
+	h.NextRound(rec, requestWithUser("POST", "/", "", owner, params))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("next round on a draft: status %d", rec.Code)
+	}
+
+	// Swapping two players from different tables changes both matches.
+	got, _ := db.GetTournament(ctx, database, tourn.ID)
+	eng, _ := swisstools.LoadTournament(got.EngineState)
+	round := eng.GetRound()
+	a, b := round[0].PlayerA(), round[1].PlayerA()
+	form := url.Values{"player_a": {strconv.Itoa(a)}, "player_b": {strconv.Itoa(b)}}
+	rec = httptest.NewRecorder()
+	h.SwapPlayers(rec, requestWithUser("POST", "/", form.Encode(), owner, params))
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("swap: status %d body %s", rec.Code, rec.Body.String())
+	}
+	got, _ = db.GetTournament(ctx, database, tourn.ID)
+	eng, _ = swisstools.LoadTournament(got.EngineState)
+	if p := eng.GetRound()[0]; p.PlayerA() != b || p.PlayerB() != round[0].PlayerB() {
+		t.Errorf("table 1 after swap = %d-%d, want %d-%d", p.PlayerA(), p.PlayerB(), b, round[0].PlayerB())
+	}
+
+	rec = httptest.NewRecorder()
+	h.PublishPairings(rec, requestWithUser("POST", "/", "", owner, params))
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("publish: status %d body %s", rec.Code, rec.Body.String())
+	}
+	h.Detail(httptest.NewRecorder(), requestWithUser("GET", "/", "", nil, params))
+	data = tmpl.calls[3].Data.(map[string]interface{})
+	if data["Draft"] != false || len(data["Pairings"].([]resolvedPairing)) != 2 {
+		t.Errorf("after publish: Draft = %v, pairings = %v", data["Draft"], data["Pairings"])
+	}
+
+	// Publishing twice has nothing left to publish.
+	rec = httptest.NewRecorder()
+	h.PublishPairings(rec, requestWithUser("POST", "/", "", owner, params))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("second publish: status %d", rec.Code)
+	}
+}
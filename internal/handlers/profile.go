@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/dstathis/openswiss/internal/avatar"
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/middleware"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/go-chi/chi/v5"
+)
+
+// ProfilePage shows the logged-in user's avatar with the forms to pick an
+// icon, upload an image or remove it, their notification settings, and
+// their request to have their data deleted.
+func (h *PlayerHandler) ProfilePage(w http.ResponseWriter, r *http.Request) {
+	h.renderProfile(w, r, "")
+}
+
+func (h *PlayerHandler) renderProfile(w http.ResponseWriter, r *http.Request, errMsg string) {
+	user := middleware.GetUser(r.Context())
+	a, err := db.GetAvatar(r.Context(), h.DB, user.ID)
+	if err != nil && !errors.Is(err, db.ErrAvatarNotFound) {
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	if errMsg != "" {
+		w.WriteHeader(http.StatusBadRequest)
+	}
+	h.Tmpl.ExecuteTemplate(w, "profile.html", map[string]interface{}{
+		"User":      user,
+		"CSRFToken": middleware.CSRFToken(r),
+		"Theme":     middleware.Theme(r),
+		"Flash":     middleware.Flash(r),
+		"Lang":      middleware.Locale(r),
+		"Avatar":    a,
+		"Channels":  []string{models.NotifyEmail, models.NotifyNone},
+		"Error":     errMsg,
+	})
+}
+
+// SetAvatar saves the logged-in user's avatar. Form fields (multipart):
+// image, a GIF, JPEG or PNG upload, which wins if given; otherwise icon,
+// one of models.AvatarIcons.
+func (h *PlayerHandler) SetAvatar(w http.ResponseWriter, r *http.Request) {
+	user := middleware.GetUser(r.Context())
+	a := &models.Avatar{UserID: user.ID}
+	file, _, err := r.FormFile("image")
+	switch {
+	case err == nil:
+		defer file.Close()
+		if a.Image, err = avatar.Normalize(file); err != nil {
+			h.renderProfile(w, r, "Upload a GIF, JPEG or PNG image of at most 4096×4096 pixels.")
+			return
+		}
+	case errors.Is(err, http.ErrMissingFile), errors.Is(err, http.ErrNotMultipart):
+		a.Icon = r.FormValue("icon")
+		if !models.ValidAvatarIcon(a.Icon) {
+			h.renderProfile(w, r, "Pick an icon or choose an image to upload.")
+			return
+		}
+	default:
+		h.renderProfile(w, r, "Upload a GIF, JPEG or PNG image of at most 4096×4096 pixels.")
+		return
+	}
+	if err := db.SetAvatar(r.Context(), h.DB, a); err != nil {
+		slog.ErrorContext(r.Context(), "set avatar", "err", err, "user_id", user.ID)
+		http.Error(w, "Failed to save avatar", http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, r, "/profile", http.StatusSeeOther)
+}
+
+// RemoveAvatar deletes the logged-in user's avatar.
+func (h *PlayerHandler) RemoveAvatar(w http.ResponseWriter, r *http.Request) {
+	user := middleware.GetUser(r.Context())
+	if err := db.DeleteAvatar(r.Context(), h.DB, user.ID); err != nil {
+		http.Error(w, "Failed to remove avatar", http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, r, "/profile", http.StatusSeeOther)
+}
+
+// SetNotifications saves how the logged-in user wants to be notified.
+// Form field: notify_by, one of the models.Notify* channels.
+func (h *PlayerHandler) SetNotifications(w http.ResponseWriter, r *http.Request) {
+	user := middleware.GetUser(r.Context())
+	channel := r.FormValue("notify_by")
+	if !models.ValidNotifyChannel(channel) {
+		h.renderProfile(w, r, "Pick how you want to be notified.")
+		return
+	}
+	if err := db.SetUserNotifyBy(r.Context(), h.DB, user.ID, channel); err != nil {
+		slog.ErrorContext(r.Context(), "set notifications", "err", err, "user_id", user.ID)
+		http.Error(w, "Failed to save notification settings", http.StatusInternalServerError)
+		return
+	}
+	middleware.SetFlash(w, r, middleware.FlashSuccess, "Notification settings saved.")
+	http.Redirect(w, r, "/profile", http.StatusSeeOther)
+}
+
+// RequestDeletion asks the admins to delete the logged-in user's personal
+// data, or with form field withdraw set takes the request back. An admin
+// carries it out from the users page.
+func (h *PlayerHandler) RequestDeletion(w http.ResponseWriter, r *http.Request) {
+	user := middleware.GetUser(r.Context())
+	requested := r.FormValue("withdraw") == ""
+	if err := db.SetDeletionRequested(r.Context(), h.DB, user.ID, requested); err != nil {
+		slog.ErrorContext(r.Context(), "set deletion request", "err", err, "user_id", user.ID)
+		http.Error(w, "Failed to save your request", http.StatusInternalServerError)
+		return
+	}
+	if requested {
+		slog.InfoContext(r.Context(), "data deletion requested", "user_id", user.ID)
+		middleware.SetFlash(w, r, middleware.FlashSuccess, "Deletion requested. An admin will delete your data.")
+	} else {
+		middleware.SetFlash(w, r, middleware.FlashSuccess, "Deletion request withdrawn.")
+	}
+	http.Redirect(w, r, "/profile#privacy", http.StatusSeeOther)
+}
+
+// AvatarImage serves a user's uploaded avatar. Pages link to it with the
+// avatar's version in the query, so it can be cached for a day.
+func (h *PlayerHandler) AvatarImage(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.ParseInt(chi.URLParam(r, "uid"), 10, 64)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	a, err := db.GetAvatar(r.Context(), h.DB, userID)
+	if errors.Is(err, db.ErrAvatarNotFound) || err == nil && a.Image == nil {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Content-Length", fmt.Sprint(len(a.Image)))
+	w.Header().Set("Cache-Control", "public, max-age=86400")
+	w.Write(a.Image)
+}
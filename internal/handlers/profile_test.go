@@ -0,0 +1,178 @@
+//go:build integration
+
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/middleware"
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+// avatarUpload builds a multipart avatar form with the file data, if any,
+// and the picked icon.
+func avatarUpload(t *testing.T, user *models.User, data []byte, icon string) *http.Request {
+	t.Helper()
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	if data != nil {
+		part, err := mw.CreateFormFile("image", "me.png")
+		if err != nil {
+			t.Fatal(err)
+		}
+		part.Write(data)
+	}
+	mw.WriteField("icon", icon)
+	mw.Close()
+	req := httptest.NewRequest("POST", "/profile/avatar", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	return req.WithContext(context.WithValue(req.Context(), middleware.UserContextKey, user))
+}
+
+func TestPlayerHandler_Avatar(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	tmpl := &mockTemplate{}
+	h := &PlayerHandler{DB: database, Tmpl: tmpl}
+	user := mustCreateUser(t, database, "ava@example.com", "Ava")
+
+	rec := httptest.NewRecorder()
+	h.SetAvatar(rec, avatarUpload(t, user, nil, "unicorn"))
+	if rec.Code != http.StatusBadRequest || tmpl.calls[0].Data.(map[string]interface{})["Error"] == "" {
+		t.Fatalf("unknown icon: status %d", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	h.SetAvatar(rec, avatarUpload(t, user, []byte("not an image"), ""))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("bad upload: status %d, want 400", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	h.SetAvatar(rec, avatarUpload(t, user, nil, "owl"))
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("icon: status %d", rec.Code)
+	}
+	if a, err := db.GetAvatar(ctx, database, user.ID); err != nil || a.Icon != "owl" {
+		t.Fatalf("after picking an icon: %+v, %v", a, err)
+	}
+
+	var pic bytes.Buffer
+	png.Encode(&pic, image.NewGray(image.Rect(0, 0, 200, 100)))
+	rec = httptest.NewRecorder()
+	h.SetAvatar(rec, avatarUpload(t, user, pic.Bytes(), "owl"))
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("upload: status %d", rec.Code)
+	}
+
+	// The upload won over the icon, and is served as a square PNG.
+	req := requestWithUser("GET", "/", "", nil, map[string]string{"uid": strconv.FormatInt(user.ID, 10)})
+	rec = httptest.NewRecorder()
+	h.AvatarImage(rec, req)
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "image/png" {
+		t.Fatalf("image: status %d, type %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	if cfg, err := png.DecodeConfig(rec.Body); err != nil || cfg.Width != cfg.Height {
+		t.Errorf("served image: %+v, %v", cfg, err)
+	}
+
+	h.ProfilePage(httptest.NewRecorder(), requestWithUser("GET", "/", "", user, nil))
+	if a := tmpl.calls[len(tmpl.calls)-1].Data.(map[string]interface{})["Avatar"].(*models.Avatar); a.Icon != "" {
+		t.Errorf("profile avatar = %+v", a)
+	}
+
+	rec = httptest.NewRecorder()
+	h.RemoveAvatar(rec, requestWithUser("POST", "/", "", user, nil))
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("remove: status %d", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	h.AvatarImage(rec, req)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("removed image: status %d, want 404", rec.Code)
+	}
+}
+
+func TestPlayerHandler_SetNotifications(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	tmpl := &mockTemplate{}
+	h := &PlayerHandler{DB: database, Tmpl: tmpl}
+	user := mustCreateUser(t, database, "notify@example.com", "Notify")
+	if user.NotifyBy != models.NotifyEmail {
+		t.Fatalf("new account notify_by = %q, want email", user.NotifyBy)
+	}
+
+	rec := httptest.NewRecorder()
+	h.SetNotifications(rec, requestWithUser("POST", "/", url.Values{"notify_by": {"carrier pigeon"}}.Encode(), user, nil))
+	if rec.Code != http.StatusBadRequest || tmpl.calls[0].Name != "profile.html" {
+		t.Errorf("unknown channel: status %d", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	h.SetNotifications(rec, requestWithUser("POST", "/", url.Values{"notify_by": {models.NotifyNone}}.Encode(), user, nil))
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("none: status %d body %s", rec.Code, rec.Body.String())
+	}
+	got, _ := db.GetUserByID(ctx, database, user.ID)
+	if got.NotifyBy != models.NotifyNone || got.Notifies(models.NotifyEmail) {
+		t.Errorf("after opting out: notify_by = %q", got.NotifyBy)
+	}
+}
+
+func TestPlayerHandler_RequestDeletion(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	h := &PlayerHandler{DB: database, Tmpl: &mockTemplate{}}
+	user := mustCreateUser(t, database, "forget-me@example.com", "ForgetMe")
+
+	rec := httptest.NewRecorder()
+	h.RequestDeletion(rec, requestWithUser("POST", "/", "", user, nil))
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("request: status %d", rec.Code)
+	}
+	got, _ := db.GetUserByID(ctx, database, user.ID)
+	if got.DeletionRequestedAt == nil {
+		t.Fatal("no deletion request recorded")
+	}
+	if requests, _ := db.ListDeletionRequests(ctx, database); len(requests) != 1 || requests[0].ID != user.ID {
+		t.Errorf("requests = %+v", requests)
+	}
+
+	h.RequestDeletion(httptest.NewRecorder(), requestWithUser("POST", "/", "withdraw=1", user, nil))
+	if got, _ := db.GetUserByID(ctx, database, user.ID); got.DeletionRequestedAt != nil {
+		t.Errorf("request still there after withdrawing: %v", got.DeletionRequestedAt)
+	}
+}
+
+func TestAuthHandler_Register_AvatarIcon(t *testing.T) {
+	database := testDB(t)
+	h := &AuthHandler{DB: database, Tmpl: &mockTemplate{}}
+	form := url.Values{
+		"email": {"new@example.com"}, "display_name": {"Newcomer"},
+		"password": {"longenough"}, "confirm_password": {"longenough"}, "icon": {"dragon"},
+	}
+	req := httptest.NewRequest("POST", "/register", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec := httptest.NewRecorder()
+	h.Register(rec, req)
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("status %d", rec.Code)
+	}
+	u, err := db.GetUserByEmail(context.Background(), database, "new@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a, err := db.GetAvatar(context.Background(), database, u.ID); err != nil || a.Icon != "dragon" {
+		t.Errorf("avatar = %+v, %v", a, err)
+	}
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/middleware"
+	"github.com/dstathis/openswiss/internal/qr"
+)
+
+// maxQRTournaments caps the tournaments offered on the QR code page.
+const maxQRTournaments = 50
+
+// qrSign is one printable QR code: where it leads and the image that does.
+type qrSign struct {
+	Title string
+	URL   string
+	Image string
+}
+
+// QRPage shows QR codes for the venue door: one for the sign-up page, and
+// one per scheduled or open tournament leading to its page, where players
+// register.
+func (h *AdminHandler) QRPage(w http.ResponseWriter, r *http.Request) {
+	tournaments, _ := db.ListUpcomingTournaments(r.Context(), h.DB, maxQRTournaments)
+	signs := []qrSign{{Title: "Create an account", URL: h.BaseURL + "/register", Image: "/admin/qr.svg"}}
+	for _, t := range tournaments {
+		signs = append(signs, qrSign{
+			Title: t.Name,
+			URL:   tournamentURL(h.BaseURL, t.ID),
+			Image: fmt.Sprintf("/admin/qr.svg?tournament=%d", t.ID),
+		})
+	}
+	h.Tmpl.ExecuteTemplate(w, "admin_qr.html", map[string]interface{}{
+		"User":      middleware.GetUser(r.Context()),
+		"CSRFToken": middleware.CSRFToken(r),
+		"Theme":     middleware.Theme(r),
+		"Flash":     middleware.Flash(r),
+		"Lang":      middleware.Locale(r),
+		"Signs":     signs,
+	})
+}
+
+func tournamentURL(baseURL string, id int64) string {
+	return fmt.Sprintf("%s/tournaments/%d", baseURL, id)
+}
+
+// QRCode serves a QR code as SVG: for the sign-up page, or with
+// ?tournament=ID for that tournament's page. It only encodes this server's
+// own URLs, so it can't be used to make codes for arbitrary links.
+func (h *AdminHandler) QRCode(w http.ResponseWriter, r *http.Request) {
+	target := h.BaseURL + "/register"
+	if v := r.URL.Query().Get("tournament"); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			http.Error(w, "Not found", http.StatusNotFound)
+			return
+		}
+		t, err := db.GetTournament(r.Context(), h.DB, id)
+		if err != nil {
+			http.Error(w, "Not found", http.StatusNotFound)
+			return
+		}
+		target = tournamentURL(h.BaseURL, t.ID)
+	}
+	code, err := qr.Encode(target)
+	if err != nil {
+		http.Error(w, "BASE_URL is too long for a QR code", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "image/svg+xml")
+	if err := code.WriteSVG(w); err != nil {
+		slog.ErrorContext(r.Context(), "write qr code", "err", err)
+	}
+}
@@ -0,0 +1,49 @@
+//go:build integration
+
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+func TestAdminHandler_QR(t *testing.T) {
+	database := testDB(t)
+	tmpl := &mockTemplate{}
+	h := &AdminHandler{DB: database, Tmpl: tmpl, BaseURL: "https://swiss.example.com"}
+	owner := mustCreateUser(t, database, "owner@example.com", "Owner")
+	open := mustCreateTournament(t, database, owner.ID, models.TournamentStatusRegistrationOpen)
+	mustCreateTournament(t, database, owner.ID, models.TournamentStatusFinished)
+
+	h.QRPage(httptest.NewRecorder(), requestWithUser("GET", "/admin/qr", "", owner, nil))
+	signs := tmpl.calls[0].Data.(map[string]interface{})["Signs"].([]qrSign)
+	if len(signs) != 2 {
+		t.Fatalf("signs = %+v, want sign-up and the open tournament", signs)
+	}
+	if signs[0].URL != "https://swiss.example.com/register" {
+		t.Errorf("sign-up URL = %q", signs[0].URL)
+	}
+	if want := fmt.Sprintf("https://swiss.example.com/tournaments/%d", open.ID); signs[1].URL != want {
+		t.Errorf("tournament URL = %q, want %q", signs[1].URL, want)
+	}
+
+	rec := httptest.NewRecorder()
+	h.QRCode(rec, requestWithUser("GET", signs[1].Image, "", owner, nil))
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "image/svg+xml" {
+		t.Fatalf("code: %d %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	if !strings.HasPrefix(rec.Body.String(), "<svg") {
+		t.Errorf("body = %.40q", rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	h.QRCode(rec, requestWithUser("GET", "/admin/qr.svg?tournament=999999", "", owner, nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("unknown tournament: %d, want 404", rec.Code)
+	}
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/engine"
+	"github.com/dstathis/openswiss/internal/middleware"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/dstathis/swisstools"
+	"github.com/go-chi/chi/v5"
+)
+
+// QuickEntryPage is a phone-sized results page for a scorekeeper walking
+// the floor: the current round's tables still without a result, each with
+// one big button per result (see engine.QuickResults). Min tier: Judge.
+func (h *TournamentHandler) QuickEntryPage(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if !middleware.AuthorizeTournament(w, r, h.DB, id, models.TierJudge) {
+		return
+	}
+	t, err := db.GetTournament(r.Context(), h.DB, id)
+	if err != nil {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+	var extra map[string]interface{}
+	if table, err := strconv.Atoi(r.URL.Query().Get("reported")); err == nil {
+		extra = map[string]interface{}{"Reported": table, "ReportedResult": r.URL.Query().Get("result")}
+	}
+	h.renderQuickEntry(w, r, t, extra)
+}
+
+// QuickReport records the result a scorekeeper tapped on the quick entry
+// page, from the form fields table and result, and goes back to the page.
+// A table someone else reported first is refused with 409. Min tier: Judge.
+func (h *TournamentHandler) QuickReport(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if !middleware.AuthorizeTournament(w, r, h.DB, id, models.TierJudge) {
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+	table, _ := strconv.Atoi(r.FormValue("table"))
+	result := r.FormValue("result")
+
+	err := engine.WithTournamentEngine(r.Context(), h.DB, id,
+		func(tx *sql.Tx, t *models.Tournament, eng *swisstools.Tournament) (string, error) {
+			return "", engine.QuickReport(r.Context(), tx, t, eng, table, result)
+		})
+	if err != nil {
+		t, gerr := db.GetTournament(r.Context(), h.DB, id)
+		if gerr != nil {
+			engineError(w, r, err)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		switch {
+		case errors.Is(err, db.ErrBusy):
+			w.Header().Set("Retry-After", "5")
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case errors.Is(err, engine.ErrTableReported):
+			w.WriteHeader(http.StatusConflict)
+			err = fmt.Errorf("table %d already has a result", table)
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+		h.renderQuickEntry(w, r, t, map[string]interface{}{"Error": err.Error()})
+		return
+	}
+	back := url.Values{"reported": {strconv.Itoa(table)}, "result": {result}}
+	http.Redirect(w, r, fmt.Sprintf("/tournaments/%d/manage/quick?%s", id, back.Encode()), http.StatusSeeOther)
+}
+
+// renderQuickEntry renders the quick entry page for t's current round with
+// the tables still open, fewest-numbered first.
+func (h *TournamentHandler) renderQuickEntry(w http.ResponseWriter, r *http.Request, t *models.Tournament, extra map[string]interface{}) {
+	data := map[string]interface{}{
+		"User":       middleware.GetUser(r.Context()),
+		"CSRFToken":  middleware.CSRFToken(r),
+		"Theme":      middleware.Theme(r),
+		"Flash":      middleware.Flash(r),
+		"Lang":       middleware.Locale(r),
+		"Tournament": t,
+		"Results":    engine.QuickResults(t.BestOf),
+		"Supported":  !t.SeriesMode && t.TeamSize == 0,
+	}
+	if t.Status == models.TournamentStatusInProgress && len(t.EngineState) > 0 {
+		eng, err := swisstools.LoadTournament(t.EngineState)
+		if err != nil {
+			http.Error(w, "Internal error", http.StatusInternalServerError)
+			return
+		}
+		var open []resolvedPairing
+		for _, p := range resolvePairings(&eng, eng.GetRound()) {
+			if !p.IsBye && !p.Reported {
+				open = append(open, p)
+			}
+		}
+		sort.Slice(open, func(i, j int) bool { return open[i].Table < open[j].Table })
+		data["Round"] = eng.GetCurrentRound()
+		data["Open"] = open
+	}
+	for k, v := range extra {
+		data[k] = v
+	}
+	h.Tmpl.ExecuteTemplate(w, "tournament_quick.html", data)
+}
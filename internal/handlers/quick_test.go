@@ -0,0 +1,79 @@
+//go:build integration
+
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/swisstools"
+)
+
+func TestTournamentHandler_QuickEntry(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	tmpl := &mockTemplate{}
+	h := &TournamentHandler{DB: database, Tmpl: tmpl}
+	owner, tourn := startedTournament(t, database)
+	params := map[string]string{"id": strconv.FormatInt(tourn.ID, 10)}
+
+	page := func(query string) map[string]interface{} {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		h.QuickEntryPage(rec, requestWithUser("GET", "/"+query, "", owner, params))
+		if rec.Code != http.StatusOK || len(tmpl.calls) == 0 {
+			t.Fatalf("page: status %d", rec.Code)
+		}
+		return tmpl.calls[len(tmpl.calls)-1].Data.(map[string]interface{})
+	}
+	report := func(table, result string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		form := url.Values{"table": {table}, "result": {result}}
+		h.QuickReport(rec, requestWithUser("POST", "/", form.Encode(), owner, params))
+		return rec
+	}
+
+	// Round 2, where nothing has been reported yet.
+	h.NextRound(httptest.NewRecorder(), requestWithUser("POST", "/", "", owner, params))
+	open := page("")["Open"].([]resolvedPairing)
+	if len(open) == 0 || open[0].Table != 1 {
+		t.Fatalf("open tables = %+v, want table 1 first", open)
+	}
+
+	rec := report("1", "2-1")
+	if rec.Code != http.StatusSeeOther || rec.Header().Get("Location") != "/tournaments/"+params["id"]+"/manage/quick?reported=1&result=2-1" {
+		t.Fatalf("report: status %d, location %q", rec.Code, rec.Header().Get("Location"))
+	}
+	tm, _ := db.GetTournament(ctx, database, tourn.ID)
+	eng, _ := swisstools.LoadTournament(tm.EngineState)
+	if p := eng.GetRound()[0]; p.PlayerAWins() != 2 || p.PlayerBWins() != 1 {
+		t.Errorf("table 1 result = %d-%d, want 2-1", p.PlayerAWins(), p.PlayerBWins())
+	}
+
+	data := page("?reported=1&result=2-1")
+	if data["Reported"] != 1 {
+		t.Errorf("Reported = %v, want 1", data["Reported"])
+	}
+	for _, p := range data["Open"].([]resolvedPairing) {
+		if p.Table == 1 {
+			t.Error("reported table still listed")
+		}
+	}
+
+	if rec := report("1", "2-0"); rec.Code != http.StatusConflict {
+		t.Errorf("second report of table 1: status %d, want 409", rec.Code)
+	}
+	if rec := report("1", "5-5"); rec.Code != http.StatusConflict {
+		t.Errorf("bad result for a reported table: status %d, want 409", rec.Code)
+	}
+	if len(open) > 1 {
+		if rec := report(strconv.Itoa(open[1].Table), "5-5"); rec.Code != http.StatusBadRequest {
+			t.Errorf("unknown result: status %d, want 400", rec.Code)
+		}
+	}
+}
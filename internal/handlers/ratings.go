@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/engine"
+	"github.com/dstathis/openswiss/internal/middleware"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/go-chi/chi/v5"
+)
+
+// SetRatings imports the players' ratings from the dashboard's ratings box,
+// one "name, rating" line per player (see engine.ParseRatings). Players
+// left out keep their rating. Min tier: Co-organizer.
+func (h *TournamentHandler) SetRatings(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if !middleware.AuthorizeTournament(w, r, h.DB, id, models.TierCoOrganizer) {
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+	regs, err := db.ListRegistrations(r.Context(), h.DB, id)
+	if err != nil {
+		http.Error(w, "Failed to load players", http.StatusInternalServerError)
+		return
+	}
+	ratings, err := engine.ParseRatings(r.FormValue("ratings"), regs)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := engine.SetRatings(r.Context(), h.DB, id, ratings); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	http.Redirect(w, r, fmt.Sprintf("/tournaments/%d/manage/players#ratings", id), http.StatusSeeOther)
+}
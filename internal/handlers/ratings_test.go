@@ -0,0 +1,53 @@
+//go:build integration
+
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+func TestTournamentHandler_SetRatings(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	h := &TournamentHandler{DB: database, Tmpl: &mockTemplate{}}
+	owner := mustCreateUser(t, database, "owner-ratings@example.com", "OwnerRatings")
+	tourn := mustCreateTournament(t, database, owner.ID, models.TournamentStatusRegistrationOpen)
+	alice, _ := db.CreateGuestRegistration(ctx, database, tourn.ID, "Alice")
+	bob, _ := db.CreateGuestRegistration(ctx, database, tourn.ID, "Bob")
+	params := map[string]string{"id": strconv.FormatInt(tourn.ID, 10)}
+	post := func(user *models.User, ratings string) int {
+		rec := httptest.NewRecorder()
+		h.SetRatings(rec, requestWithUser("POST", "/", url.Values{"ratings": {ratings}}.Encode(), user, params))
+		return rec.Code
+	}
+
+	stranger := mustCreateUser(t, database, "stranger-ratings@example.com", "StrangerRatings")
+	if code := post(stranger, "Alice, 2000"); code != http.StatusForbidden {
+		t.Errorf("stranger: status %d, want 403", code)
+	}
+	if code := post(owner, "alice, 2000\nBob\t1700\n"); code != http.StatusSeeOther {
+		t.Fatalf("status %d", code)
+	}
+	if got, _ := db.GetRegistrationByID(ctx, database, bob.ID); got.Rating == nil || *got.Rating != 1700 {
+		t.Errorf("Bob's rating = %v", got.Rating)
+	}
+	if code := post(owner, "Alice, 2100\nNobody, 1200"); code != http.StatusBadRequest {
+		t.Errorf("unknown player: status %d, want 400", code)
+	}
+	if got, _ := db.GetRegistrationByID(ctx, database, alice.ID); *got.Rating != 2000 {
+		t.Errorf("Alice's rating changed to %d by a refused import", *got.Rating)
+	}
+
+	db.UpdateTournamentStatus(ctx, database, tourn.ID, models.TournamentStatusInProgress)
+	if code := post(owner, "Alice, 2100"); code != http.StatusBadRequest {
+		t.Errorf("after the start: status %d, want 400", code)
+	}
+}
@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/engine"
+	"github.com/dstathis/openswiss/internal/middleware"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/go-chi/chi/v5"
+)
+
+// RegistryHandler serves the player registry: the players organizers keep
+// on file across tournaments. Every page needs the organizer role.
+type RegistryHandler struct {
+	DB   *sql.DB
+	Tmpl TemplateRenderer
+}
+
+// ListPage lists the registry with the form that adds to it. ?q= searches
+// names and contacts; page picks the page.
+func (h *RegistryHandler) ListPage(w http.ResponseWriter, r *http.Request) {
+	players, err := db.ListPlayers(r.Context(), h.DB, "")
+	if err != nil {
+		http.Error(w, "Failed to load players", http.StatusInternalServerError)
+		return
+	}
+	q := nameQuery(r)
+	rows, pg := filterPage(r, "page", "players", players, func(p models.Player) bool {
+		contact := ""
+		if p.Contact != nil {
+			contact = *p.Contact
+		}
+		return matchesName(q, p.Name, contact)
+	})
+	h.Tmpl.ExecuteTemplate(w, "players.html", map[string]interface{}{
+		"User":      middleware.GetUser(r.Context()),
+		"CSRFToken": middleware.CSRFToken(r),
+		"Theme":     middleware.Theme(r),
+		"Flash":     middleware.Flash(r),
+		"Lang":      middleware.Locale(r),
+		"Query":     q,
+		"Players":   rows,
+		"Pager":     pg,
+	})
+}
+
+// playerForm reads a registry entry from the form fields name, contact,
+// rating and club, an empty rating leaving it unrated.
+func playerForm(r *http.Request) (*models.Player, error) {
+	contact, club := r.FormValue("contact"), r.FormValue("club")
+	p := &models.Player{Name: r.FormValue("name"), Contact: &contact, Club: &club}
+	if v := strings.TrimSpace(r.FormValue("rating")); v != "" {
+		rating, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a rating", v)
+		}
+		p.Rating = &rating
+	}
+	return p, engine.CheckPlayer(p)
+}
+
+// Create adds a player to the registry and shows them. Form fields: name,
+// contact, rating, club.
+func (h *RegistryHandler) Create(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+	p, err := playerForm(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	p.CreatedBy = &middleware.GetUser(r.Context()).ID
+	if err := db.CreatePlayer(r.Context(), h.DB, p); err != nil {
+		http.Error(w, "Failed to add player", http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, r, fmt.Sprintf("/players/%d", p.ID), http.StatusSeeOther)
+}
+
+// DetailPage shows a registry player with the form that edits them, their
+// lifetime record, their record by season and every tournament they were
+// added to from the registry.
+func (h *RegistryHandler) DetailPage(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "pid"), 10, 64)
+	p, err := db.GetPlayer(r.Context(), h.DB, id)
+	if err != nil {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+	events, err := engine.PlayerHistory(r.Context(), h.DB, p.ID)
+	if err != nil {
+		http.Error(w, "Failed to load the player's tournaments", http.StatusInternalServerError)
+		return
+	}
+	h.Tmpl.ExecuteTemplate(w, "player_detail.html", map[string]interface{}{
+		"User":      middleware.GetUser(r.Context()),
+		"CSRFToken": middleware.CSRFToken(r),
+		"Theme":     middleware.Theme(r),
+		"Flash":     middleware.Flash(r),
+		"Lang":      middleware.Locale(r),
+		"Player":    p,
+		"Events":    events,
+		"Lifetime":  engine.Lifetime(events),
+		"Seasons":   engine.Seasons(events),
+	})
+}
+
+// Update saves a registry player's name, contact, rating and club. Registrations
+// already made from the entry keep their own. Form fields as for Create.
+func (h *RegistryHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "pid"), 10, 64)
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+	p, err := playerForm(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	p.ID = id
+	if err := db.UpdatePlayer(r.Context(), h.DB, p); err != nil {
+		if errors.Is(err, db.ErrPlayerNotFound) {
+			http.Error(w, "Not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Failed to save player", http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, r, fmt.Sprintf("/players/%d", id), http.StatusSeeOther)
+}
+
+// Delete removes a player from the registry. Their registrations stay in
+// their tournaments, no longer linked.
+func (h *RegistryHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "pid"), 10, 64)
+	if err := db.DeletePlayer(r.Context(), h.DB, id); err != nil {
+		if errors.Is(err, db.ErrPlayerNotFound) {
+			http.Error(w, "Not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Failed to delete player", http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, r, "/players", http.StatusSeeOther)
+}
+
+// unregistered returns the registry players none of regs was made from, for
+// the players page's picker.
+func unregistered(ctx context.Context, database *sql.DB, regs []models.Registration) []models.Player {
+	players, _ := db.ListPlayers(ctx, database, "")
+	in := make(map[int64]bool, len(regs))
+	for _, reg := range regs {
+		if reg.PlayerID != nil {
+			in[*reg.PlayerID] = true
+		}
+	}
+	var out []models.Player
+	for _, p := range players {
+		if !in[p.ID] {
+			out = append(out, p)
+		}
+	}
+	return out
+}
@@ -0,0 +1,117 @@
+//go:build integration
+
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/engine"
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+func TestRegistryHandler(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	tmpl := &mockTemplate{}
+	h := &RegistryHandler{DB: database, Tmpl: tmpl}
+	org := mustCreateUser(t, database, "org-reg@example.com", "OrgReg", models.RoleOrganizer)
+
+	rec := httptest.NewRecorder()
+	h.Create(rec, requestWithUser("POST", "/", url.Values{"name": {"Ada"}, "rating": {"high"}}.Encode(), org, nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("bad rating: status %d, want 400", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	h.Create(rec, requestWithUser("POST", "/", url.Values{"name": {" Ada "}, "contact": {"ada@example.com"}, "rating": {"1600"}}.Encode(), org, nil))
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("create: status %d body %s", rec.Code, rec.Body.String())
+	}
+	players, _ := db.ListPlayers(ctx, database, "")
+	if len(players) != 1 || players[0].Name != "Ada" || players[0].Rating == nil || *players[0].Rating != 1600 {
+		t.Fatalf("players = %+v", players)
+	}
+	ada := players[0]
+	params := map[string]string{"pid": strconv.FormatInt(ada.ID, 10)}
+
+	rec = httptest.NewRecorder()
+	h.ListPage(rec, requestWithUser("GET", "/players?q=ADA", "", org, nil))
+	if got := tmpl.calls[0].Data.(map[string]interface{})["Players"].([]models.Player); len(got) != 1 {
+		t.Errorf("list = %+v", got)
+	}
+
+	rec = httptest.NewRecorder()
+	h.Update(rec, requestWithUser("POST", "/", url.Values{"name": {"Ada L."}, "contact": {""}}.Encode(), org, params))
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("update: status %d body %s", rec.Code, rec.Body.String())
+	}
+	if got, _ := db.GetPlayer(ctx, database, ada.ID); got.Name != "Ada L." || got.Contact != nil || got.Rating != nil {
+		t.Errorf("after update = %+v", got)
+	}
+
+	rec = httptest.NewRecorder()
+	h.DetailPage(rec, requestWithUser("GET", "/", "", org, params))
+	data := tmpl.calls[1].Data.(map[string]interface{})
+	if data["Player"].(*models.Player).ID != ada.ID || len(data["Events"].([]engine.EventRecord)) != 0 {
+		t.Errorf("detail data = %+v", data)
+	}
+
+	rec = httptest.NewRecorder()
+	h.Delete(rec, requestWithUser("POST", "/", "", org, params))
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("delete: status %d", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	h.DetailPage(rec, requestWithUser("GET", "/", "", org, params))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("detail after delete: status %d, want 404", rec.Code)
+	}
+}
+
+func TestTournamentHandler_AddPlayerFromRegistry(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	h := &TournamentHandler{DB: database, Tmpl: &mockTemplate{}}
+	org := mustCreateUser(t, database, "org-pick@example.com", "OrgPick", models.RoleOrganizer)
+	co := mustCreateUser(t, database, "co-pick@example.com", "CoPick")
+	tourn := mustCreateTournament(t, database, org.ID, models.TournamentStatusRegistrationOpen)
+	if err := db.AddTournamentStaff(ctx, database, &models.TournamentStaff{
+		TournamentID: tourn.ID, UserID: co.ID, Tier: models.TierCoOrganizer,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	rating := 1720
+	ada := &models.Player{Name: "Ada", Rating: &rating}
+	if err := db.CreatePlayer(ctx, database, ada); err != nil {
+		t.Fatal(err)
+	}
+	params := map[string]string{"id": strconv.FormatInt(tourn.ID, 10)}
+	form := url.Values{"player_id": {strconv.FormatInt(ada.ID, 10)}}.Encode()
+
+	// Co-organizers without the organizer role can't reach the registry.
+	rec := httptest.NewRecorder()
+	h.AddPlayer(rec, requestWithUser("POST", "/", form, co, params))
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("co-organizer pick: status %d, want 403", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	h.AddPlayer(rec, requestWithUser("POST", "/", form, org, params))
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("pick: status %d body %s", rec.Code, rec.Body.String())
+	}
+	regs, _ := db.ListRegistrations(ctx, database, tourn.ID)
+	if len(regs) != 1 || regs[0].DisplayName != "Ada" || regs[0].PlayerID == nil || *regs[0].PlayerID != ada.ID ||
+		regs[0].Rating == nil || *regs[0].Rating != rating {
+		t.Fatalf("registrations = %+v", regs)
+	}
+	rec = httptest.NewRecorder()
+	h.AddPlayer(rec, requestWithUser("POST", "/", form, org, params))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("second pick: status %d, want 400", rec.Code)
+	}
+}
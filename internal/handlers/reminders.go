@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/engine"
+	"github.com/dstathis/openswiss/internal/middleware"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/dstathis/swisstools"
+	"github.com/go-chi/chi/v5"
+)
+
+// attachOutstanding adds what the overview's outstanding results widget
+// shows to data: the current Swiss round's clock, and its matches still
+// waiting for a result while the round is being played.
+func (h *TournamentHandler) attachOutstanding(ctx context.Context, t *models.Tournament, eng *swisstools.Tournament, data map[string]interface{}) {
+	if eng == nil || t.Status != models.TournamentStatusInProgress || eng.GetStatus() == "finished" {
+		return
+	}
+	round := eng.GetCurrentRound()
+	if t.PairingsDraft(round) {
+		return
+	}
+	data["OutstandingRound"] = round
+	data["Outstanding"] = engine.Outstanding(eng)
+	data["Clock"], _ = db.GetRoundClock(ctx, h.DB, t.ID, round)
+}
+
+// OutstandingFragment renders the overview's outstanding results widget on
+// its own; the overview reloads it every 30 seconds (see static/app.js).
+func (h *TournamentHandler) OutstandingFragment(w http.ResponseWriter, r *http.Request) {
+	t, data, ok := h.manageData(w, fragmentRequest(r, manageOverview), manageOverview)
+	if !ok {
+		return
+	}
+	h.attachOutstanding(r.Context(), t, manageEngine(t), data)
+	h.Tmpl.ExecuteTemplate(w, "tournament_manage.html#outstanding", data)
+}
+
+// SetClock starts the current Swiss round's clock, running for the form
+// field minutes from now, or stops it when the form's stop field is set.
+// The tables still playing are reminded shortly before it runs out (see
+// engine.Reminder). Min tier: Judge.
+func (h *TournamentHandler) SetClock(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if !middleware.AuthorizeTournament(w, r, h.DB, id, models.TierJudge) {
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+	back := fmt.Sprintf("/tournaments/%d/manage/rounds", id)
+	if r.FormValue("stop") != "" {
+		if err := db.StopRoundClock(r.Context(), h.DB, id); err != nil {
+			http.Error(w, "Failed to stop the clock", http.StatusInternalServerError)
+			return
+		}
+		http.Redirect(w, r, back, http.StatusSeeOther)
+		return
+	}
+	minutes, err := strconv.Atoi(strings.TrimSpace(r.FormValue("minutes")))
+	if err != nil || minutes < 1 || minutes > models.MaxRoundMinutes {
+		http.Error(w, fmt.Sprintf("Round time must be 1 to %d minutes", models.MaxRoundMinutes), http.StatusBadRequest)
+		return
+	}
+	t, err := db.GetTournament(r.Context(), h.DB, id)
+	if err != nil {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+	if _, err := engine.StartRoundClock(r.Context(), h.DB, t, minutes); err != nil {
+		if errors.Is(err, engine.ErrNoRoundUnderway) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		http.Error(w, "Failed to start the clock", http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, r, back, http.StatusSeeOther)
+}
+
+// Remind reminds the current round's tables without a result to report now,
+// as the round clock does near its end: a results.outstanding webhook event
+// and an email to each of their players with an account. Min tier: Judge.
+func (h *TournamentHandler) Remind(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if !middleware.AuthorizeTournament(w, r, h.DB, id, models.TierJudge) {
+		return
+	}
+	url := fmt.Sprintf("%s/tournaments/%d", h.BaseURL, id)
+	if _, err := engine.Remind(r.Context(), h.DB, h.Email, url, id); err != nil {
+		if errors.Is(err, engine.ErrNothingOutstanding) {
+			http.Error(w, "No results are outstanding", http.StatusConflict)
+			return
+		}
+		http.Error(w, "Failed to send the reminder", http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, r, fmt.Sprintf("/tournaments/%d/manage", id), http.StatusSeeOther)
+}
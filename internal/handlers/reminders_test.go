@@ -0,0 +1,85 @@
+//go:build integration
+
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/engine"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/dstathis/openswiss/internal/webhook"
+	"github.com/dstathis/swisstools"
+)
+
+func TestTournamentHandler_OutstandingResults(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	tmpl := &mockTemplate{}
+	h := &TournamentHandler{DB: database, Tmpl: tmpl}
+	owner, tourn := startedTournament(t, database)
+	params := map[string]string{"id": strconv.FormatInt(tourn.ID, 10)}
+
+	post := func(handler http.HandlerFunc, user *models.User, form url.Values) int {
+		rec := httptest.NewRecorder()
+		handler(rec, requestWithUser("POST", "/", form.Encode(), user, params))
+		return rec.Code
+	}
+	// Every round 1 result is in.
+	if code := post(h.Remind, owner, nil); code != http.StatusConflict {
+		t.Errorf("remind with nothing outstanding: status %d, want 409", code)
+	}
+
+	if err := engine.WithTournamentEngine(ctx, database, tourn.ID,
+		func(tx *sql.Tx, tm *models.Tournament, eng *swisstools.Tournament) (string, error) {
+			return engine.AdvanceRound(eng, tm, false, nil, nil, nil)
+		}); err != nil {
+		t.Fatal(err)
+	}
+
+	stranger := mustCreateUser(t, database, "stranger-clock@example.com", "StrangerClock")
+	if code := post(h.SetClock, stranger, url.Values{"minutes": {"50"}}); code != http.StatusForbidden {
+		t.Errorf("stranger: status %d, want 403", code)
+	}
+	if code := post(h.SetClock, owner, url.Values{"minutes": {"0"}}); code != http.StatusBadRequest {
+		t.Errorf("0 minutes: status %d, want 400", code)
+	}
+	if code := post(h.SetClock, owner, url.Values{"minutes": {"50"}}); code != http.StatusSeeOther {
+		t.Fatalf("set clock: status %d", code)
+	}
+	if c, _ := db.GetRoundClock(ctx, database, tourn.ID, 2); c == nil {
+		t.Fatal("no round 2 clock")
+	}
+
+	rec := httptest.NewRecorder()
+	h.ManagePage(rec, requestWithUser("GET", "/", "", owner, params))
+	data := tmpl.calls[len(tmpl.calls)-1].Data.(map[string]interface{})
+	if out, _ := data["Outstanding"].([]webhook.Pairing); data["OutstandingRound"] != 2 || len(out) != 2 || data["Clock"] == nil {
+		t.Errorf("overview: round %v, outstanding %+v, clock %v", data["OutstandingRound"], data["Outstanding"], data["Clock"])
+	}
+	rec = httptest.NewRecorder()
+	h.OutstandingFragment(rec, requestWithUser("GET", "/", "", owner, params))
+	if call := tmpl.calls[len(tmpl.calls)-1]; call.Name != "tournament_manage.html#outstanding" {
+		t.Errorf("fragment rendered %q", call.Name)
+	}
+
+	if code := post(h.Remind, owner, nil); code != http.StatusSeeOther {
+		t.Errorf("remind: status %d", code)
+	}
+	if c, _ := db.GetRoundClock(ctx, database, tourn.ID, 2); c == nil || c.RemindedAt == nil {
+		t.Errorf("clock after a reminder by hand = %+v", c)
+	}
+
+	if code := post(h.SetClock, owner, url.Values{"stop": {"1"}}); code != http.StatusSeeOther {
+		t.Errorf("stop clock: status %d", code)
+	}
+	if c, _ := db.GetRoundClock(ctx, database, tourn.ID, 2); c != nil {
+		t.Errorf("clock after stop = %+v", c)
+	}
+}
@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/engine"
+	"github.com/dstathis/openswiss/internal/middleware"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/dstathis/swisstools"
+	"github.com/go-chi/chi/v5"
+)
+
+// repairRow puts a current pairing next to the proposed pairing at the same
+// position, with what re-pairing does to the current match.
+type repairRow struct {
+	Current  resolvedPairing
+	Proposed resolvedPairing
+	Change   engine.RepairChange
+}
+
+// RepairPreview shows a proposed re-pairing of the current round next to the
+// live one, without changing anything. Confirming posts the proposal to
+// RepairRound; reloading the page draws a new one. Min tier: Co-organizer.
+func (h *TournamentHandler) RepairPreview(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	t, err := db.GetTournament(r.Context(), h.DB, id)
+	if err != nil {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+	if !middleware.AuthorizeTournament(w, r, h.DB, t.ID, models.TierCoOrganizer) {
+		return
+	}
+	if len(t.EngineState) == 0 {
+		http.Error(w, "Tournament has not started", http.StatusBadRequest)
+		return
+	}
+	eng, err := swisstools.LoadTournament(t.EngineState)
+	if err != nil {
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	assigned, err := engine.AssignedByes(r.Context(), h.DB, t, &eng)
+	if err != nil {
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	clubs, err := engine.Clubs(r.Context(), h.DB, t)
+	if err != nil {
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	seeds, err := engine.Seeds(r.Context(), h.DB, t)
+	if err != nil {
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	proposed, err := engine.PreviewRepair(t, &eng, assigned, clubs, seeds)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	current := resolvePairings(&eng, eng.GetRound())
+	next := resolvePairings(proposed, proposed.GetRound())
+	changes := engine.DiffRepair(eng.GetRound(), proposed.GetRound())
+	rows := make([]repairRow, max(len(current), len(next)))
+	kept, lost := 0, 0
+	for i := range rows {
+		if i < len(current) {
+			rows[i].Current, rows[i].Change = current[i], changes[i]
+			if changes[i].NewTable == changes[i].Table {
+				kept++
+			}
+			if changes[i].Reported {
+				lost++
+			}
+		}
+		if i < len(next) {
+			rows[i].Proposed = next[i]
+		}
+	}
+
+	h.Tmpl.ExecuteTemplate(w, "tournament_repair.html", map[string]interface{}{
+		"User":         middleware.GetUser(r.Context()),
+		"CSRFToken":    middleware.CSRFToken(r),
+		"Theme":        middleware.Theme(r),
+		"Flash":        middleware.Flash(r),
+		"Lang":         middleware.Locale(r),
+		"Tournament":   t,
+		"CurrentRound": eng.GetCurrentRound(),
+		"Rows":         rows,
+		"Kept":         kept,
+		"LostResults":  lost,
+		"RoundKey":     engine.RoundKey(&eng),
+		"Proposal":     engine.EncodeRound(proposed.GetRound()),
+	})
+}
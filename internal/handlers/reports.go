@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/engine"
+	"github.com/dstathis/openswiss/internal/middleware"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/dstathis/swisstools"
+	"github.com/go-chi/chi/v5"
+)
+
+// myResultReport is what the tournament page shows a player of a match
+// waiting for its result when players report their own: their report and
+// their opponent's, each from the player's side, or nil before they make
+// one.
+type myResultReport struct {
+	Mine   *models.ResultReport
+	Theirs *models.ResultReport
+}
+
+// Conflict reports whether the two reports give different scores.
+func (m *myResultReport) Conflict() bool {
+	return m.Mine != nil && m.Theirs != nil && !m.Mine.Agrees(*m.Theirs)
+}
+
+// fromSide returns r's score as the player of side sees it.
+func fromSide(r models.ResultReport, side string) *models.ResultReport {
+	if side == models.GameWinnerB {
+		r.Wins, r.Losses = r.Losses, r.Wins
+	}
+	return &r
+}
+
+// loadMyReport returns reg's view of the reports of their match among
+// pairings, the current round's, or nil unless t takes player reports and
+// the match is still waiting for a result.
+func loadMyReport(ctx context.Context, database db.DBTX, t *models.Tournament, round int, pairings []resolvedPairing, reg *models.Registration) *myResultReport {
+	if !t.SelfReport || t.Status != models.TournamentStatusInProgress || reg == nil || reg.EnginePlayerID == nil {
+		return nil
+	}
+	for _, p := range pairings {
+		if !p.Mine || p.IsBye || p.Reported {
+			continue
+		}
+		side := models.GameWinnerA
+		if p.PlayerBID == *reg.EnginePlayerID {
+			side = models.GameWinnerB
+		}
+		reports, err := db.ListTableReports(ctx, database, t.ID, round, p.Table)
+		if err != nil {
+			return nil
+		}
+		out := &myResultReport{}
+		for _, r := range reports {
+			if r.Side == side {
+				out.Mine = fromSide(r, side)
+			} else {
+				out.Theirs = fromSide(r, side)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+// ReportResult lets a registered player report their current-round match
+// result from the tournament page, from their side: form fields wins,
+// losses and draws. It counts once the opponent's report agrees (see
+// engine.ReportOwnResult).
+func (h *TournamentHandler) ReportResult(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	user := middleware.GetUser(r.Context())
+	reg, err := db.GetRegistration(r.Context(), h.DB, id, user.ID)
+	if err != nil || reg.EnginePlayerID == nil || reg.Status == models.RegistrationStatusDropped {
+		http.Error(w, "You are not playing in this tournament", http.StatusBadRequest)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+	wins, _ := strconv.Atoi(r.FormValue("wins"))
+	losses, _ := strconv.Atoi(r.FormValue("losses"))
+	draws, _ := strconv.Atoi(r.FormValue("draws"))
+
+	err = engine.WithTournamentEngine(r.Context(), h.DB, id,
+		func(tx *sql.Tx, t *models.Tournament, eng *swisstools.Tournament) (string, error) {
+			_, err := engine.ReportOwnResult(r.Context(), tx, t, eng, *reg.EnginePlayerID, wins, losses, draws, &user.ID)
+			return "", err
+		})
+	if err != nil {
+		engineError(w, r, err)
+		return
+	}
+	http.Redirect(w, r, fmt.Sprintf("/tournaments/%d#pairings", id), http.StatusSeeOther)
+}
+
+// reportRow is a table of the current round with player reports waiting,
+// for the rounds page: side A's and side B's report, nil if not made, and
+// their State (see engine.ReportState).
+type reportRow struct {
+	Table       int
+	PlayerAName string
+	PlayerBName string
+	A, B        *models.ResultReport
+	State       string
+}
+
+// Sides returns side A's and side B's report, in that order.
+func (r reportRow) Sides() []*models.ResultReport {
+	return []*models.ResultReport{r.A, r.B}
+}
+
+// loadReports returns the tables among pairings, the current round's, with
+// player reports waiting, conflicts first and then by table.
+func loadReports(ctx context.Context, database db.DBTX, t *models.Tournament, round int, pairings []resolvedPairing) []reportRow {
+	if !t.SelfReport || round == 0 {
+		return nil
+	}
+	reports, err := db.ListResultReports(ctx, database, t.ID, round)
+	if err != nil || len(reports) == 0 {
+		return nil
+	}
+	var conflicts, pending []reportRow
+	for _, p := range pairings {
+		table, ok := reports[p.Table]
+		if !ok || p.Reported {
+			continue
+		}
+		row := reportRow{Table: p.Table, PlayerAName: p.PlayerAName, PlayerBName: p.PlayerBName, State: engine.ReportState(table)}
+		for i := range table {
+			if table[i].Side == models.GameWinnerA {
+				row.A = &table[i]
+			} else {
+				row.B = &table[i]
+			}
+		}
+		if row.State == engine.ReportConflict {
+			conflicts = append(conflicts, row)
+		} else {
+			pending = append(pending, row)
+		}
+	}
+	return append(conflicts, pending...)
+}
+
+// AcceptReport resolves a table's player reports from the rounds page by
+// entering the one from form field side ("a" or "b") at form field table.
+// Min tier: Judge.
+func (h *TournamentHandler) AcceptReport(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if !middleware.AuthorizeTournament(w, r, h.DB, id, models.TierJudge) {
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+	table, _ := strconv.Atoi(r.FormValue("table"))
+	side := r.FormValue("side")
+
+	err := engine.WithTournamentEngine(r.Context(), h.DB, id,
+		func(tx *sql.Tx, t *models.Tournament, eng *swisstools.Tournament) (string, error) {
+			return "", engine.AcceptReport(r.Context(), tx, t, eng, table, side)
+		})
+	if err != nil {
+		engineError(w, r, err)
+		return
+	}
+	http.Redirect(w, r, fmt.Sprintf("/tournaments/%d/manage/rounds#reports", id), http.StatusSeeOther)
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"strconv"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/engine"
+	"github.com/dstathis/openswiss/internal/middleware"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/dstathis/swisstools"
+	"github.com/go-chi/chi/v5"
+)
+
+// Values of the results form's type_<playerID> select besides "" (played).
+const (
+	formConcessionA = "concession_a"
+	formConcessionB = "concession_b"
+)
+
+// recordFormResult enters one row of the results form: the played score, or
+// the special result picked in the row's type select, which wins over the
+// score inputs.
+func recordFormResult(ctx context.Context, tx *sql.Tx, t *models.Tournament, eng *swisstools.Tournament, p resolvedPairing, typ string, wins, losses, draws int, reportedBy *int64) error {
+	switch typ {
+	case "":
+		return engine.RecordResult(ctx, tx, t, eng, p.PlayerAID, wins, losses, draws)
+	case models.ResultIntentionalDraw:
+		return engine.RecordSpecialResult(ctx, tx, t, eng, p.PlayerAID, typ, reportedBy)
+	case formConcessionA:
+		return engine.RecordSpecialResult(ctx, tx, t, eng, p.PlayerAID, models.ResultConcession, reportedBy)
+	case formConcessionB:
+		return engine.RecordSpecialResult(ctx, tx, t, eng, p.PlayerBID, models.ResultConcession, reportedBy)
+	}
+	return engine.RecordSpecialResult(ctx, tx, t, eng, p.PlayerAID, typ, reportedBy)
+}
+
+// attachResultTypes marks each pairing that ended in an intentional draw or
+// a concession.
+func attachResultTypes(ctx context.Context, database db.DBTX, tournamentID int64, round int, pairings []resolvedPairing) {
+	types, err := db.ListMatchResultTypes(ctx, database, tournamentID, round)
+	if err != nil {
+		return
+	}
+	for i := range pairings {
+		if rt, ok := types[pairings[i].Table]; ok {
+			pairings[i].ResultType = rt.Type
+			pairings[i].ConcededBy = rt.ConcededBy
+		}
+	}
+}
+
+// FormResultType is the value the results form's type select should start
+// on for the pairing.
+func (p resolvedPairing) FormResultType() string {
+	if p.ResultType == models.ResultConcession {
+		return "concession_" + p.ConcededBy
+	}
+	return p.ResultType
+}
+
+// ResultNote describes a result that wasn't played out, e.g. "ID" or
+// "Alice conceded", and is empty for played results.
+func (p resolvedPairing) ResultNote() string {
+	switch {
+	case p.ResultType == models.ResultIntentionalDraw:
+		return "ID"
+	case p.ResultType == models.ResultConcession && p.ConcededBy == models.GameWinnerB:
+		return p.PlayerBName + " conceded"
+	case p.ResultType == models.ResultConcession:
+		return p.PlayerAName + " conceded"
+	}
+	return ""
+}
+
+// Winner names the player who won a reported match, for reading the result
+// out to screen readers; it is empty for a draw, a bye or a match without a
+// result.
+func (p resolvedPairing) Winner() string {
+	switch {
+	case !p.Reported || p.IsBye || p.PlayerAWins == p.PlayerBWins:
+		return ""
+	case p.PlayerAWins > p.PlayerBWins:
+		return p.PlayerAName
+	}
+	return p.PlayerBName
+}
+
+// Concede lets a registered player concede their match in the current
+// round from the dashboard. Refused once the match has a result.
+func (h *TournamentHandler) Concede(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	user := middleware.GetUser(r.Context())
+	reg, err := db.GetRegistration(r.Context(), h.DB, id, user.ID)
+	if err != nil || reg.EnginePlayerID == nil || reg.Status == models.RegistrationStatusDropped {
+		http.Error(w, "You are not playing in this tournament", http.StatusBadRequest)
+		return
+	}
+
+	err = engine.WithTournamentEngine(r.Context(), h.DB, id,
+		func(tx *sql.Tx, t *models.Tournament, eng *swisstools.Tournament) (string, error) {
+			return "", engine.ConcedeMatch(r.Context(), tx, t, eng, *reg.EnginePlayerID, &user.ID)
+		})
+	if err != nil {
+		engineError(w, r, err)
+		return
+	}
+	http.Redirect(w, r, "/dashboard", http.StatusSeeOther)
+}
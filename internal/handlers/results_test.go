@@ -0,0 +1,115 @@
+//go:build integration
+
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/dstathis/swisstools"
+)
+
+func TestTournamentHandler_SubmitResults_SpecialTypes(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	h := &TournamentHandler{DB: database, Tmpl: &mockTemplate{}}
+	owner, tourn := startedTournament(t, database)
+	params := map[string]string{"id": strconv.FormatInt(tourn.ID, 10)}
+	eng, _ := swisstools.LoadTournament(tourn.EngineState)
+	round := eng.GetRound()
+	a1, a2 := strconv.Itoa(round[0].PlayerA()), strconv.Itoa(round[1].PlayerA())
+
+	form := url.Values{
+		"wins_a_" + a1: {"2"}, "wins_b_" + a1: {"0"}, "draws_" + a1: {"0"}, "type_" + a1: {"intentional_draw"},
+		"wins_a_" + a2: {"2"}, "wins_b_" + a2: {"0"}, "draws_" + a2: {"0"}, "type_" + a2: {"concession_a"},
+	}
+	rec := httptest.NewRecorder()
+	h.SubmitResults(rec, requestWithUser("POST", "/", form.Encode(), owner, params))
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("submit: status %d body %s", rec.Code, rec.Body.String())
+	}
+	got, _ := db.GetTournament(ctx, database, tourn.ID)
+	eng, _ = swisstools.LoadTournament(got.EngineState)
+	if p := eng.GetRound()[0]; p.PlayerAWins() != 0 || p.PlayerBWins() != 0 || p.Draws() != 3 {
+		t.Errorf("ID result = %d-%d-%d, want 0-0-3", p.PlayerAWins(), p.PlayerBWins(), p.Draws())
+	}
+	if p := eng.GetRound()[1]; p.PlayerAWins() != 0 || p.PlayerBWins() != 2 {
+		t.Errorf("concession result = %d-%d-%d, want 0-2-0", p.PlayerAWins(), p.PlayerBWins(), p.Draws())
+	}
+	types, _ := db.ListMatchResultTypes(ctx, database, tourn.ID, 1)
+	if types[1].Type != models.ResultIntentionalDraw || types[2].ConcededBy != models.GameWinnerA {
+		t.Fatalf("types = %+v", types)
+	}
+
+	tmpl := &mockTemplate{}
+	h.Tmpl = tmpl
+	h.ManageRoundsPage(httptest.NewRecorder(), requestWithUser("GET", "/", "", owner, params))
+	pairings := tmpl.calls[0].Data.(map[string]interface{})["Pairings"].([]resolvedPairing)
+	if pairings[0].ResultNote() != "ID" || pairings[1].FormResultType() != "concession_a" {
+		t.Errorf("manage pairings = %+v", pairings[:2])
+	}
+
+	// A played result replaces the special one.
+	form = url.Values{"wins_a_" + a1: {"2"}, "wins_b_" + a1: {"1"}, "draws_" + a1: {"0"}, "type_" + a1: {""}}
+	rec = httptest.NewRecorder()
+	h.SubmitResults(rec, requestWithUser("POST", "/", form.Encode(), owner, params))
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("resubmit: status %d body %s", rec.Code, rec.Body.String())
+	}
+	if types, _ = db.ListMatchResultTypes(ctx, database, tourn.ID, 1); len(types) != 1 {
+		t.Errorf("types after played result = %+v", types)
+	}
+}
+
+func TestTournamentHandler_Concede(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	h := &TournamentHandler{DB: database, Tmpl: &mockTemplate{}}
+	owner, tourn := startedTournament(t, database)
+	params := map[string]string{"id": strconv.FormatInt(tourn.ID, 10)}
+	reg := mustListRegs(t, database, tourn.ID)[0]
+	player, err := db.GetUserByID(ctx, database, *reg.UserID)
+	if err != nil {
+		t.Fatalf("get player: %v", err)
+	}
+
+	// Round 1 already has its results.
+	rec := httptest.NewRecorder()
+	h.Concede(rec, requestWithUser("POST", "/", "", player, params))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("concede a reported match: status %d, want 400", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	h.NextRound(rec, requestWithUser("POST", "/", "", owner, params))
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("next round: status %d body %s", rec.Code, rec.Body.String())
+	}
+	rec = httptest.NewRecorder()
+	h.Concede(rec, requestWithUser("POST", "/", "", player, params))
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("concede: status %d body %s", rec.Code, rec.Body.String())
+	}
+	types, _ := db.ListMatchResultTypes(ctx, database, tourn.ID, 2)
+	if len(types) != 1 {
+		t.Fatalf("types = %+v", types)
+	}
+	for _, rt := range types {
+		if rt.Type != models.ResultConcession || rt.ReportedBy == nil || *rt.ReportedBy != player.ID {
+			t.Errorf("type = %+v", rt)
+		}
+	}
+
+	other := mustCreateUser(t, database, "other-concede@example.com", "OtherConcede")
+	rec = httptest.NewRecorder()
+	h.Concede(rec, requestWithUser("POST", "/", "", other, params))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("non-player concede: status %d, want 400", rec.Code)
+	}
+}
@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/middleware"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/dstathis/swisstools"
+	"github.com/go-chi/chi/v5"
+)
+
+// RoundPage shows the pairings and results of one Swiss round, past or
+// current, with the public pairing fields.
+func (h *TournamentHandler) RoundPage(w http.ResponseWriter, r *http.Request) {
+	h.renderRound(w, r, false)
+}
+
+// ManageRoundPage is RoundPage for staff: it adds the staff-only pairing
+// fields and links back to the dashboard. Min tier: Judge.
+func (h *TournamentHandler) ManageRoundPage(w http.ResponseWriter, r *http.Request) {
+	h.renderRound(w, r, true)
+}
+
+func (h *TournamentHandler) renderRound(w http.ResponseWriter, r *http.Request, staff bool) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	round, err := strconv.Atoi(chi.URLParam(r, "round"))
+	if err != nil {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+	t, err := db.GetTournament(r.Context(), h.DB, id)
+	if err != nil {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+	if staff && !middleware.AuthorizeTournament(w, r, h.DB, t.ID, models.TierJudge) {
+		return
+	}
+	if len(t.EngineState) == 0 {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+	eng, err := swisstools.LoadTournament(t.EngineState)
+	if err != nil {
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	if round < 1 || round > eng.GetCurrentRound() {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+	pairings, err := eng.GetRoundByNumber(round)
+	if err != nil {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+	// Players and spectators don't see a draft round until it is published.
+	draft := !staff && t.PairingsDraft(round)
+	if !staff {
+		h.Views.View(r, t.ID, round, models.PagePairings)
+	}
+	if draft {
+		pairings = nil
+	}
+	resolved := resolvePairings(&eng, pairings)
+	user := middleware.GetUser(r.Context())
+	regs, _ := db.ListRegistrations(r.Context(), h.DB, id)
+	var mine *myPairing
+	if !staff {
+		mine = markMyPairing(resolved, userRegistration(regs, user))
+	}
+	fields := attachPairingFields(r.Context(), h.DB, id, round, resolved, !staff)
+	attachResultTypes(r.Context(), h.DB, id, round, resolved)
+	if t.SeriesMode {
+		attachGames(r.Context(), h.DB, id, round, resolved)
+	}
+	if t.TeamSize > 0 {
+		attachSeats(r.Context(), h.DB, t, round, regs, resolved)
+	}
+	attachFeatureMatches(r.Context(), h.DB, id, round, resolved)
+	pinFeatureMatches(resolved)
+	avatars, _ := db.TournamentAvatars(r.Context(), h.DB, id)
+	corrections, pairedBefore := roundCorrections(r.Context(), h.DB, id, round)
+	// Admins correct closed Swiss rounds from the staff page.
+	canCorrect := false
+	if staff && round < eng.GetCurrentRound() && t.Status == models.TournamentStatusInProgress && !t.SeriesMode && t.TeamSize == 0 {
+		tier, _ := db.EffectiveTournamentTier(r.Context(), h.DB, t.ID, user)
+		canCorrect = tier.AtLeast(models.TierAdmin)
+	}
+
+	h.Tmpl.ExecuteTemplate(w, "tournament_round.html", map[string]interface{}{
+		"User":          user,
+		"CSRFToken":     middleware.CSRFToken(r),
+		"Theme":         middleware.Theme(r),
+		"Flash":         middleware.Flash(r),
+		"Lang":          middleware.Locale(r),
+		"Tournament":    t,
+		"Round":         round,
+		"CurrentRound":  eng.GetCurrentRound(),
+		"Rounds":        roundNumbers(eng.GetCurrentRound()),
+		"Pairings":      resolved,
+		"Avatars":       avatars,
+		"Announcements": activeAnnouncements(r.Context(), h.DB, id),
+		"PairingFields": fields,
+		"StaffView":     staff,
+		"Draft":         draft,
+		"MyPairing":     mine,
+		"Corrections":   corrections,
+		"PairedBefore":  pairedBefore,
+		"CanCorrect":    canCorrect,
+	})
+}
+
+// roundNumbers returns 1..n, for linking every round of a tournament.
+func roundNumbers(n int) []int {
+	out := make([]int, n)
+	for i := range out {
+		out[i] = i + 1
+	}
+	return out
+}
@@ -0,0 +1,142 @@
+//go:build integration
+
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/dstathis/swisstools"
+)
+
+func TestTournamentHandler_RoundPage(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	h := &TournamentHandler{DB: database, Tmpl: &mockTemplate{}}
+	owner, tourn := startedTournament(t, database)
+	idStr := strconv.FormatInt(tourn.ID, 10)
+
+	staffOnly := &models.PairingField{TournamentID: tourn.ID, Label: "Judge note"}
+	if err := db.CreatePairingField(ctx, database, staffOnly); err != nil {
+		t.Fatalf("create field: %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	h.NextRound(rec, requestWithUser("POST", "/", "", owner, map[string]string{"id": idStr}))
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("next round: status %d body %s", rec.Code, rec.Body.String())
+	}
+
+	// Round 1 stays viewable with its results after the tournament moves on.
+	tmpl := &mockTemplate{}
+	h.Tmpl = tmpl
+	rec = httptest.NewRecorder()
+	h.RoundPage(rec, requestWithUser("GET", "/", "", nil, map[string]string{"id": idStr, "round": "1"}))
+	if rec.Code != http.StatusOK || len(tmpl.calls) != 1 {
+		t.Fatalf("round 1: status %d, %d template calls", rec.Code, len(tmpl.calls))
+	}
+	data := tmpl.calls[0].Data.(map[string]interface{})
+	if data["Round"] != 1 || data["CurrentRound"] != 2 {
+		t.Errorf("Round = %v, CurrentRound = %v", data["Round"], data["CurrentRound"])
+	}
+	for _, p := range data["Pairings"].([]resolvedPairing) {
+		if !p.Reported {
+			t.Errorf("round 1 table %d not reported", p.Table)
+		}
+	}
+	if fields := data["PairingFields"].([]models.PairingField); len(fields) != 0 {
+		t.Errorf("public round page shows staff fields: %+v", fields)
+	}
+
+	// The manage view adds staff-only fields.
+	tmpl = &mockTemplate{}
+	h.Tmpl = tmpl
+	rec = httptest.NewRecorder()
+	h.ManageRoundPage(rec, requestWithUser("GET", "/", "", owner, map[string]string{"id": idStr, "round": "1"}))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("manage round 1: status %d", rec.Code)
+	}
+	data = tmpl.calls[0].Data.(map[string]interface{})
+	if fields := data["PairingFields"].([]models.PairingField); len(fields) != 1 {
+		t.Errorf("manage round page fields = %+v", fields)
+	}
+	if data["StaffView"] != true {
+		t.Error("StaffView should be set on the manage round page")
+	}
+
+	for _, round := range []string{"0", "3", "x"} {
+		rec = httptest.NewRecorder()
+		h.RoundPage(rec, requestWithUser("GET", "/", "", nil, map[string]string{"id": idStr, "round": round}))
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("round %s: status %d, want 404", round, rec.Code)
+		}
+	}
+}
+
+func TestTournamentHandler_RoundPage_NotStarted(t *testing.T) {
+	database := testDB(t)
+	h := &TournamentHandler{DB: database, Tmpl: &mockTemplate{}}
+	owner := mustCreateUser(t, database, "owner-rp@example.com", "OwnerRP")
+	tourn := mustCreateTournament(t, database, owner.ID, models.TournamentStatusRegistrationOpen)
+
+	rec := httptest.NewRecorder()
+	h.RoundPage(rec, requestWithUser("GET", "/", "", nil, map[string]string{"id": strconv.FormatInt(tourn.ID, 10), "round": "1"}))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status %d, want 404", rec.Code)
+	}
+}
+
+func TestTournamentHandler_ManageRoundPage_Forbidden(t *testing.T) {
+	database := testDB(t)
+	h := &TournamentHandler{DB: database, Tmpl: &mockTemplate{}}
+	_, tourn := startedTournament(t, database)
+	other := mustCreateUser(t, database, "other-rp@example.com", "OtherRP")
+
+	rec := httptest.NewRecorder()
+	h.ManageRoundPage(rec, requestWithUser("GET", "/", "", other, map[string]string{"id": strconv.FormatInt(tourn.ID, 10), "round": "1"}))
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("status %d, want 403", rec.Code)
+	}
+}
+
+func TestTournamentHandler_Display(t *testing.T) {
+	database := testDB(t)
+	_, tourn := startedTournament(t, database)
+	idStr := strconv.FormatInt(tourn.ID, 10)
+
+	tmpl := &mockTemplate{}
+	h := &TournamentHandler{DB: database, Tmpl: tmpl}
+	rec := httptest.NewRecorder()
+	h.DisplayPairings(rec, requestWithUser("GET", "/?by=name&refresh=20", "", nil, map[string]string{"id": idStr}))
+	if rec.Code != http.StatusOK || len(tmpl.calls) != 1 {
+		t.Fatalf("pairings: status %d, %d template calls", rec.Code, len(tmpl.calls))
+	}
+	data := tmpl.calls[0].Data.(map[string]interface{})
+	if data["Display"] != true || data["Refresh"] != 20 || data["CurrentRound"] != 1 {
+		t.Errorf("Display = %v, Refresh = %v, CurrentRound = %v", data["Display"], data["Refresh"], data["CurrentRound"])
+	}
+	if seats := data["Seats"].([]displaySeat); len(seats) != 4 {
+		t.Errorf("seats = %+v, want all four players", seats)
+	}
+
+	tmpl.calls = nil
+	rec = httptest.NewRecorder()
+	h.DisplayStandings(rec, requestWithUser("GET", "/", "", nil, map[string]string{"id": idStr}))
+	if rec.Code != http.StatusOK || tmpl.calls[0].Name != "display_standings.html" {
+		t.Fatalf("standings: status %d", rec.Code)
+	}
+	if standings := tmpl.calls[0].Data.(map[string]interface{})["Standings"].([]swisstools.PlayerStanding); len(standings) != 4 {
+		t.Errorf("standings has %d rows, want 4", len(standings))
+	}
+
+	rec = httptest.NewRecorder()
+	h.DisplayPairings(rec, requestWithUser("GET", "/", "", nil, map[string]string{"id": "999999"}))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("unknown tournament: status %d, want 404", rec.Code)
+	}
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"fmt"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/engine"
+	"github.com/dstathis/openswiss/internal/middleware"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/go-chi/chi/v5"
+)
+
+// SetSchedule replaces the schedule from the dashboard's schedule box, one
+// "[date] HH:MM label" line per item in the tournament's time zone (see
+// engine.ParseSchedule). Min tier: Co-organizer.
+func (h *TournamentHandler) SetSchedule(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if !middleware.AuthorizeTournament(w, r, h.DB, id, models.TierCoOrganizer) {
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+	t, err := db.GetTournament(r.Context(), h.DB, id)
+	if err != nil {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+	items, err := engine.ParseSchedule(r.FormValue("schedule"), t.Zone(), t.ScheduledAt)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := engine.SetSchedule(r.Context(), h.DB, id, items); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	http.Redirect(w, r, fmt.Sprintf("/tournaments/%d/manage#schedule", id), http.StatusSeeOther)
+}
+
+// ScheduleICS serves the tournament's schedule as an iCalendar feed (see
+// engine.ScheduleCalendar), which players subscribe to from their phones'
+// calendars. It is public like the tournament page, and sits outside the
+// web chain: calendar apps fetching it take no cookies.
+func (h *TournamentHandler) ScheduleICS(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	t, err := db.GetTournament(r.Context(), h.DB, id)
+	if err != nil {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+	items, err := db.ListSchedule(r.Context(), h.DB, t.ID)
+	if err != nil {
+		http.Error(w, "Failed to load schedule", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`inline; filename="tournament-%d.ics"`, t.ID))
+	if err := engine.ScheduleCalendar(t, items, h.BaseURL, time.Now()).Write(w); err != nil {
+		slog.Warn("write schedule feed", "tournament_id", t.ID, "err", err)
+	}
+}
+
+// calendarURL is the webcal:// address of tournament id's schedule feed,
+// which phones open as a subscription rather than a one-off import. It is
+// a template.URL as html/template would otherwise refuse the scheme.
+func calendarURL(baseURL string, id int64) template.URL {
+	host := strings.TrimPrefix(strings.TrimPrefix(baseURL, "https://"), "http://")
+	return template.URL(fmt.Sprintf("webcal://%s/tournaments/%d/schedule.ics", host, id))
+}
@@ -0,0 +1,113 @@
+//go:build integration
+
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/engine"
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+func TestTournamentHandler_SetSchedule(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	tmpl := &mockTemplate{}
+	h := &TournamentHandler{DB: database, Tmpl: tmpl}
+	owner := mustCreateUser(t, database, "owner-schedule@example.com", "OwnerSchedule")
+	tourn := mustCreateTournament(t, database, owner.ID, models.TournamentStatusRegistrationOpen)
+	params := map[string]string{"id": strconv.FormatInt(tourn.ID, 10)}
+
+	// Settings take the time zone the start time is read in.
+	form := url.Values{"name": {tourn.Name}, "scheduled_at": {"2026-05-01T09:00"}, "time_zone": {"Europe/Berlin"}, "min_players": {"2"}}
+	rec := httptest.NewRecorder()
+	h.EditTournament(rec, requestWithUser("POST", "/", form.Encode(), owner, params))
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("edit: status %d body %s", rec.Code, rec.Body.String())
+	}
+	got, _ := db.GetTournament(ctx, database, tourn.ID)
+	if got.TimeZone != "Europe/Berlin" || got.ScheduledAt == nil || !got.ScheduledAt.Equal(time.Date(2026, 5, 1, 7, 0, 0, 0, time.UTC)) {
+		t.Errorf("time zone %q, start %v", got.TimeZone, got.ScheduledAt)
+	}
+	form.Set("time_zone", "Mars/Olympus")
+	rec = httptest.NewRecorder()
+	h.EditTournament(rec, requestWithUser("POST", "/", form.Encode(), owner, params))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("unknown time zone: status %d, want 400", rec.Code)
+	}
+
+	post := func(user *models.User, schedule string) int {
+		rec := httptest.NewRecorder()
+		h.SetSchedule(rec, requestWithUser("POST", "/", url.Values{"schedule": {schedule}}.Encode(), user, params))
+		return rec.Code
+	}
+	stranger := mustCreateUser(t, database, "stranger-schedule@example.com", "StrangerSchedule")
+	if code := post(stranger, "10:00 Round 1"); code != http.StatusForbidden {
+		t.Errorf("stranger: status %d, want 403", code)
+	}
+	if code := post(owner, "09:00 Round 1\n12:30 Lunch break\n"); code != http.StatusSeeOther {
+		t.Fatalf("status %d", code)
+	}
+	items, _ := db.ListSchedule(ctx, database, tourn.ID)
+	if len(items) != 2 || items[1].Label != "Lunch break" || !items[1].StartsAt.Equal(time.Date(2026, 5, 1, 10, 30, 0, 0, time.UTC)) {
+		t.Errorf("schedule = %+v", items)
+	}
+	if code := post(owner, "noon Lunch"); code != http.StatusBadRequest {
+		t.Errorf("bad time: status %d, want 400", code)
+	}
+	if items, _ := db.ListSchedule(ctx, database, tourn.ID); len(items) != 2 {
+		t.Errorf("refused schedule replaced the old one: %+v", items)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ManagePage(rec, requestWithUser("GET", "/", "", owner, params))
+	data := tmpl.calls[len(tmpl.calls)-1].Data.(map[string]interface{})
+	if text := data["ScheduleText"]; text != "2026-05-01 09:00 Round 1\n12:30 Lunch break\n" {
+		t.Errorf("ScheduleText = %q", text)
+	}
+
+	db.UpdateTournamentStatus(ctx, database, tourn.ID, models.TournamentStatusFinished)
+	if code := post(owner, "09:00 Round 1"); code != http.StatusBadRequest {
+		t.Errorf("finished: status %d, want 400", code)
+	}
+}
+
+func TestTournamentHandler_ScheduleICS(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	h := &TournamentHandler{DB: database, Tmpl: &mockTemplate{}, BaseURL: "https://swiss.example.com"}
+	owner := mustCreateUser(t, database, "owner-ics@example.com", "OwnerICS")
+	tourn := mustCreateTournament(t, database, owner.ID, models.TournamentStatusRegistrationOpen)
+	items := []models.ScheduleItem{
+		{StartsAt: time.Date(2026, 5, 1, 7, 0, 0, 0, time.UTC), Label: "Round 1"},
+		{StartsAt: time.Date(2026, 5, 1, 8, 0, 0, 0, time.UTC), Label: "Round 2"},
+	}
+	if err := engine.SetSchedule(ctx, database, tourn.ID, items); err != nil {
+		t.Fatal(err)
+	}
+
+	rec := httptest.NewRecorder()
+	h.ScheduleICS(rec, requestWithUser("GET", "/", "", nil, map[string]string{"id": strconv.FormatInt(tourn.ID, 10)}))
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "text/calendar; charset=utf-8" {
+		t.Fatalf("status %d, content type %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	body := rec.Body.String()
+	if strings.Count(body, "BEGIN:VEVENT") != 2 || !strings.Contains(body, "DTSTART:20260501T070000Z\r\nDTEND:20260501T080000Z") ||
+		!strings.Contains(body, "SUMMARY:"+tourn.Name+": Round 2") {
+		t.Errorf("feed:\n%s", body)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ScheduleICS(rec, requestWithUser("GET", "/", "", nil, map[string]string{"id": "999999"}))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("unknown tournament: status %d, want 404", rec.Code)
+	}
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/middleware"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/dstathis/openswiss/internal/scorecard"
+	"github.com/go-chi/chi/v5"
+)
+
+// confirmedPlayers counts the registrations that will be seated.
+func confirmedPlayers(regs []models.Registration) int {
+	n := 0
+	for _, r := range regs {
+		if r.Status == models.RegistrationStatusConfirmed {
+			n++
+		}
+	}
+	return n
+}
+
+// writeScorecards sends cards as a PDF download.
+func writeScorecards(w http.ResponseWriter, r *http.Request, filename string, cards []scorecard.Card) {
+	sendPDF(w, r, filename, func(w io.Writer) error {
+		return scorecard.Render(w, cards)
+	})
+}
+
+// Scorecards downloads a printable scorecard for every confirmed player, in
+// registration order. Min tier: Judge.
+func (h *TournamentHandler) Scorecards(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if !middleware.AuthorizeTournament(w, r, h.DB, id, models.TierJudge) {
+		return
+	}
+	t, err := db.GetTournament(r.Context(), h.DB, id)
+	if err != nil {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+	regs, err := db.ListRegistrations(r.Context(), h.DB, id)
+	if err != nil {
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	players := confirmedPlayers(regs)
+	var cards []scorecard.Card
+	for _, reg := range regs {
+		if reg.Status == models.RegistrationStatusConfirmed {
+			cards = append(cards, scorecard.NewCard(t, reg.DisplayName, players))
+		}
+	}
+	writeScorecards(w, r, fmt.Sprintf("scorecards-%d.pdf", id), cards)
+}
+
+// MyScorecard downloads the signed-in player's own scorecard.
+func (h *TournamentHandler) MyScorecard(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	user := middleware.GetUser(r.Context())
+	reg, err := db.GetRegistration(r.Context(), h.DB, id, user.ID)
+	if err != nil || reg.Status == models.RegistrationStatusDropped {
+		http.Error(w, "Not registered", http.StatusNotFound)
+		return
+	}
+	t, err := db.GetTournament(r.Context(), h.DB, id)
+	if err != nil {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+	regs, _ := db.ListRegistrations(r.Context(), h.DB, id)
+	card := scorecard.NewCard(t, reg.DisplayName, confirmedPlayers(regs))
+	writeScorecards(w, r, fmt.Sprintf("scorecard-%d.pdf", id), []scorecard.Card{card})
+}
@@ -0,0 +1,65 @@
+//go:build integration
+
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+func TestTournamentHandler_Scorecards(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	h := &TournamentHandler{DB: database, Tmpl: &mockTemplate{}}
+	owner := mustCreateUser(t, database, "owner-sc@example.com", "OwnerSC")
+	tourn := mustCreateTournament(t, database, owner.ID, models.TournamentStatusRegistrationOpen)
+	alice := mustCreateUser(t, database, "alice-sc@example.com", "AliceSC")
+	bob := mustCreateUser(t, database, "bob-sc@example.com", "BobSC")
+	pending := mustCreateUser(t, database, "pending-sc@example.com", "PendingSC")
+	outsider := mustCreateUser(t, database, "out-sc@example.com", "OutSC")
+	db.CreateRegistration(ctx, database, tourn.ID, alice.ID, alice.DisplayName)
+	db.CreateRegistration(ctx, database, tourn.ID, bob.ID, bob.DisplayName)
+	db.CreatePendingRegistration(ctx, database, tourn.ID, pending.ID, pending.DisplayName)
+	params := map[string]string{"id": strconv.FormatInt(tourn.ID, 10)}
+
+	rec := httptest.NewRecorder()
+	h.Scorecards(rec, requestWithUser("GET", "/", "", owner, params))
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "application/pdf" {
+		t.Fatalf("status %d, content type %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	body := rec.Body.Bytes()
+	if n := bytes.Count(body, []byte("/Type /Page ")); n != 2 {
+		t.Errorf("%d pages, want 2 (pending players get no card)", n)
+	}
+	if !bytes.Contains(body, []byte("(Player: AliceSC)")) || bytes.Contains(body, []byte("PendingSC")) {
+		t.Error("wrong players on the cards")
+	}
+
+	rec = httptest.NewRecorder()
+	h.Scorecards(rec, requestWithUser("GET", "/", "", outsider, params))
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("outsider: status %d, want 403", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	h.MyScorecard(rec, requestWithUser("GET", "/", "", bob, params))
+	if rec.Code != http.StatusOK || !bytes.Contains(rec.Body.Bytes(), []byte("(Player: BobSC)")) {
+		t.Errorf("own scorecard: status %d", rec.Code)
+	}
+	if bytes.Contains(rec.Body.Bytes(), []byte("AliceSC")) {
+		t.Error("own scorecard includes another player")
+	}
+
+	rec = httptest.NewRecorder()
+	h.MyScorecard(rec, requestWithUser("GET", "/", "", outsider, params))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("unregistered user: status %d, want 404", rec.Code)
+	}
+}
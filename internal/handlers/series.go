@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/engine"
+	"github.com/dstathis/openswiss/internal/middleware"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/dstathis/swisstools"
+	"github.com/go-chi/chi/v5"
+)
+
+// ReportGame records one game of a best-of-N series in the current round.
+// Form fields: table, winner (a/b/draw), went_first (a/b/empty), map,
+// player_a_pick, player_b_pick, notes. Min tier: Judge.
+func (h *TournamentHandler) ReportGame(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if !middleware.AuthorizeTournament(w, r, h.DB, id, models.TierJudge) {
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+	table, err := strconv.Atoi(r.FormValue("table"))
+	if err != nil {
+		http.Error(w, "Invalid table", http.StatusBadRequest)
+		return
+	}
+	g := formGame(r, table)
+
+	err = engine.WithTournamentEngine(r.Context(), h.DB, id,
+		func(tx *sql.Tx, t *models.Tournament, eng *swisstools.Tournament) (string, error) {
+			return "", engine.ReportGame(r.Context(), tx, t, eng, g)
+		})
+	if err != nil {
+		engineError(w, r, err)
+		return
+	}
+	http.Redirect(w, r, fmt.Sprintf("/tournaments/%d/manage/rounds", id), http.StatusSeeOther)
+}
+
+// EditGame changes a game already reported at a table of the current
+// round. Form fields: table, game (its number), and the fields ReportGame
+// takes. Min tier: Judge.
+func (h *TournamentHandler) EditGame(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if !middleware.AuthorizeTournament(w, r, h.DB, id, models.TierJudge) {
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+	table, err := strconv.Atoi(r.FormValue("table"))
+	if err != nil {
+		http.Error(w, "Invalid table", http.StatusBadRequest)
+		return
+	}
+	g := formGame(r, table)
+	if g.GameNumber, err = strconv.Atoi(r.FormValue("game")); err != nil {
+		http.Error(w, "Invalid game", http.StatusBadRequest)
+		return
+	}
+
+	err = engine.WithTournamentEngine(r.Context(), h.DB, id,
+		func(tx *sql.Tx, t *models.Tournament, eng *swisstools.Tournament) (string, error) {
+			return "", engine.EditGame(r.Context(), tx, t, eng, g)
+		})
+	if err != nil {
+		engineError(w, r, err)
+		return
+	}
+	http.Redirect(w, r, fmt.Sprintf("/tournaments/%d/manage/rounds", id), http.StatusSeeOther)
+}
+
+// formGame reads a game at table from the game form, reported by the
+// signed-in user.
+func formGame(r *http.Request, table int) *models.MatchGame {
+	user := middleware.GetUser(r.Context())
+	return &models.MatchGame{
+		Table:       table,
+		Winner:      r.FormValue("winner"),
+		WentFirst:   r.FormValue("went_first"),
+		Map:         r.FormValue("map"),
+		PlayerAPick: r.FormValue("player_a_pick"),
+		PlayerBPick: r.FormValue("player_b_pick"),
+		Notes:       r.FormValue("notes"),
+		ReportedBy:  &user.ID,
+	}
+}
+
+// UndoGame removes the last reported game at a table. Form field: table.
+// Min tier: Judge.
+func (h *TournamentHandler) UndoGame(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if !middleware.AuthorizeTournament(w, r, h.DB, id, models.TierJudge) {
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+	table, err := strconv.Atoi(r.FormValue("table"))
+	if err != nil {
+		http.Error(w, "Invalid table", http.StatusBadRequest)
+		return
+	}
+
+	err = engine.WithTournamentEngine(r.Context(), h.DB, id,
+		func(tx *sql.Tx, t *models.Tournament, eng *swisstools.Tournament) (string, error) {
+			return "", engine.UndoGame(r.Context(), tx, t, eng, table)
+		})
+	if err != nil {
+		engineError(w, r, err)
+		return
+	}
+	http.Redirect(w, r, fmt.Sprintf("/tournaments/%d/manage/rounds", id), http.StatusSeeOther)
+}
+
+// attachGames fills in each pairing's reported series games for the round.
+func attachGames(ctx context.Context, database db.DBTX, tournamentID int64, round int, pairings []resolvedPairing) {
+	games, err := db.ListMatchGames(ctx, database, tournamentID, round)
+	if err != nil {
+		return
+	}
+	for i := range pairings {
+		pairings[i].Games = games[pairings[i].Table]
+	}
+}
+
+// SeriesScore formats the running score of the pairing's series from player
+// A's side, e.g. "2-1", with draws appended only when there are any.
+func (p resolvedPairing) SeriesScore() string {
+	a, b, d := engine.SeriesScore(p.Games)
+	if d > 0 {
+		return fmt.Sprintf("%d-%d-%d", a, b, d)
+	}
+	return fmt.Sprintf("%d-%d", a, b)
+}
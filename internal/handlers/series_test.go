@@ -0,0 +1,139 @@
+//go:build integration
+
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/swisstools"
+)
+
+func TestTournamentHandler_ReportGame(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	h := &TournamentHandler{DB: database, Tmpl: &mockTemplate{}}
+	owner, tourn := startedTournament(t, database)
+	tourn.BestOf = 3
+	tourn.SeriesMode = true
+	if err := db.UpdateTournament(ctx, database, tourn); err != nil {
+		t.Fatalf("enable series mode: %v", err)
+	}
+	params := map[string]string{"id": strconv.FormatInt(tourn.ID, 10)}
+
+	report := func(winner string) int {
+		form := url.Values{"table": {"1"}, "winner": {winner}, "map": {"Inferno"}}
+		rec := httptest.NewRecorder()
+		h.ReportGame(rec, requestWithUser("POST", "/", form.Encode(), owner, params))
+		return rec.Code
+	}
+	result := func() swisstools.Pairing {
+		tm, _ := db.GetTournament(ctx, database, tourn.ID)
+		eng, err := swisstools.LoadTournament(tm.EngineState)
+		if err != nil {
+			t.Fatalf("load engine: %v", err)
+		}
+		return eng.GetRound()[0]
+	}
+
+	if code := report("a"); code != http.StatusSeeOther {
+		t.Fatalf("game 1: status %d", code)
+	}
+	if p := result(); p.PlayerAWins() != swisstools.UNINITIALIZED_RESULT {
+		t.Errorf("result written mid-series: %d-%d", p.PlayerAWins(), p.PlayerBWins())
+	}
+	if code := report("b"); code != http.StatusSeeOther {
+		t.Fatalf("game 2: status %d", code)
+	}
+	if code := report("a"); code != http.StatusSeeOther {
+		t.Fatalf("game 3: status %d", code)
+	}
+	if p := result(); p.PlayerAWins() != 2 || p.PlayerBWins() != 1 {
+		t.Errorf("series result = %d-%d, want 2-1", p.PlayerAWins(), p.PlayerBWins())
+	}
+	if code := report("a"); code != http.StatusBadRequest {
+		t.Errorf("game after series decided: status %d, want 400", code)
+	}
+
+	rec := httptest.NewRecorder()
+	h.UndoGame(rec, requestWithUser("POST", "/", "table=1", owner, params))
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("undo: status %d body %s", rec.Code, rec.Body.String())
+	}
+	if p := result(); p.PlayerAWins() != swisstools.UNINITIALIZED_RESULT {
+		t.Errorf("result after undo = %d-%d, want unset", p.PlayerAWins(), p.PlayerBWins())
+	}
+
+	tmpl := &mockTemplate{}
+	h.Tmpl = tmpl
+	h.Detail(httptest.NewRecorder(), requestWithUser("GET", "/", "", nil, params))
+	pairings := tmpl.calls[0].Data.(map[string]interface{})["Pairings"].([]resolvedPairing)
+	if len(pairings[0].Games) != 2 || pairings[0].SeriesScore() != "1-1" {
+		t.Errorf("detail games = %+v", pairings[0].Games)
+	}
+}
+
+func TestTournamentHandler_EditGame(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	h := &TournamentHandler{DB: database, Tmpl: &mockTemplate{}}
+	owner, tourn := startedTournament(t, database)
+	tourn.BestOf = 3
+	tourn.SeriesMode = true
+	if err := db.UpdateTournament(ctx, database, tourn); err != nil {
+		t.Fatalf("enable series mode: %v", err)
+	}
+	params := map[string]string{"id": strconv.FormatInt(tourn.ID, 10)}
+	for _, winner := range []string{"a", "b"} {
+		form := url.Values{"table": {"1"}, "winner": {winner}, "went_first": {"a"}}
+		rec := httptest.NewRecorder()
+		h.ReportGame(rec, requestWithUser("POST", "/", form.Encode(), owner, params))
+		if rec.Code != http.StatusSeeOther {
+			t.Fatalf("report %s: status %d %s", winner, rec.Code, rec.Body.String())
+		}
+	}
+	edit := func(game, winner string) int {
+		form := url.Values{"table": {"1"}, "game": {game}, "winner": {winner}, "went_first": {"b"}, "notes": {" mulligan to six "}}
+		rec := httptest.NewRecorder()
+		h.EditGame(rec, requestWithUser("POST", "/", form.Encode(), owner, params))
+		return rec.Code
+	}
+
+	// Game 2 going to A decides the series 2-0.
+	if code := edit("2", "a"); code != http.StatusSeeOther {
+		t.Fatalf("edit game 2: status %d", code)
+	}
+	games, _ := db.ListTableGames(ctx, database, tourn.ID, 1, 1)
+	if len(games) != 2 || games[1].Winner != "a" || games[1].WentFirst != "b" || games[1].Notes != "mulligan to six" {
+		t.Errorf("games = %+v", games)
+	}
+	tm, _ := db.GetTournament(ctx, database, tourn.ID)
+	eng, _ := swisstools.LoadTournament(tm.EngineState)
+	if p := eng.GetRound()[0]; p.PlayerAWins() != 2 || p.PlayerBWins() != 0 {
+		t.Errorf("result = %d-%d, want 2-0", p.PlayerAWins(), p.PlayerBWins())
+	}
+
+	if code := edit("3", "a"); code != http.StatusBadRequest {
+		t.Errorf("edit unreported game: status %d", code)
+	}
+	if code := edit("1", "x"); code != http.StatusBadRequest {
+		t.Errorf("edit with a bad winner: status %d", code)
+	}
+}
+
+func TestTournamentHandler_ReportGame_NotSeriesMode(t *testing.T) {
+	database := testDB(t)
+	h := &TournamentHandler{DB: database, Tmpl: &mockTemplate{}}
+	owner, tourn := startedTournament(t, database)
+
+	rec := httptest.NewRecorder()
+	h.ReportGame(rec, requestWithUser("POST", "/", "table=1&winner=a", owner, map[string]string{"id": strconv.FormatInt(tourn.ID, 10)}))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", rec.Code)
+	}
+}
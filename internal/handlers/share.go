@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/dstathis/openswiss/internal/auth"
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/engine"
+	"github.com/dstathis/openswiss/internal/middleware"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/dstathis/swisstools"
+	"github.com/go-chi/chi/v5"
+)
+
+// shareRefresh is how often, in seconds, the share link page reloads.
+const shareRefresh = 60
+
+// ShareView is a tournament's read-only share link for remote spectators:
+// the current pairings and the standings, without navigation or forms (the
+// standings not in a blind final round; see engine.StandingsHidden). It
+// is served outside the CSRF middleware so it sets no cookies, and keeps
+// the token out of Referer headers and search engines. An unknown or
+// revoked token is a 404.
+func (h *TournamentHandler) ShareView(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+	token, err := db.GetShareToken(r.Context(), h.DB, id)
+	if err != nil || token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(chi.URLParam(r, "token"))) != 1 {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+	t, err := db.GetTournament(r.Context(), h.DB, id)
+	if err != nil {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+
+	var standings []swisstools.PlayerStanding
+	var pairings []resolvedPairing
+	var currentRound int
+	var hidden bool
+	if len(t.EngineState) > 0 {
+		eng, err := swisstools.LoadTournament(t.EngineState)
+		if err != nil {
+			http.Error(w, "Internal error", http.StatusInternalServerError)
+			return
+		}
+		if hidden = engine.StandingsHidden(t, &eng); !hidden {
+			regs, _ := db.ListRegistrations(r.Context(), h.DB, id)
+			standings = engine.CachedStandings(t, &eng, engine.TiebreakSeeds(regs))
+		}
+		currentRound = eng.GetCurrentRound()
+		if !t.PairingsDraft(currentRound) {
+			pairings = resolvePairings(&eng, eng.GetRound())
+		}
+	}
+	h.Views.View(r, id, currentRound, models.PageTournament)
+	pairingFields := attachPairingFields(r.Context(), h.DB, id, currentRound, pairings, true)
+	attachResultTypes(r.Context(), h.DB, id, currentRound, pairings)
+	attachFeatureMatches(r.Context(), h.DB, id, currentRound, pairings)
+	pinFeatureMatches(pairings)
+
+	w.Header().Set("Referrer-Policy", "no-referrer")
+	w.Header().Set("X-Robots-Tag", "noindex, nofollow")
+	h.Tmpl.ExecuteTemplate(w, "tournament_share.html", map[string]interface{}{
+		"Theme":         middleware.Theme(r),
+		"Flash":         middleware.Flash(r),
+		"Lang":          middleware.Locale(r),
+		"Spectator":     true,
+		"Refresh":       shareRefresh,
+		"Tournament":    t,
+		"CurrentRound":  currentRound,
+		"Draft":         t.PairingsDraft(currentRound),
+		"Standings":     standings,
+		"Hidden":        hidden,
+		"Pairings":      pairings,
+		"PairingFields": pairingFields,
+		"Announcements": activeAnnouncements(r.Context(), h.DB, id),
+	})
+}
+
+// CreateShareLink gives the tournament a new read-only share link,
+// revoking any earlier one. Min tier: Co-organizer.
+func (h *TournamentHandler) CreateShareLink(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if !middleware.AuthorizeTournament(w, r, h.DB, id, models.TierCoOrganizer) {
+		return
+	}
+	token, err := auth.GenerateShareToken()
+	if err != nil {
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	if err := db.SetShareToken(r.Context(), h.DB, id, token); err != nil {
+		http.Error(w, "Failed to create share link", http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, r, fmt.Sprintf("/tournaments/%d/manage#share", id), http.StatusSeeOther)
+}
+
+// RevokeShareLink turns the tournament's share link off. Min tier:
+// Co-organizer.
+func (h *TournamentHandler) RevokeShareLink(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if !middleware.AuthorizeTournament(w, r, h.DB, id, models.TierCoOrganizer) {
+		return
+	}
+	if err := db.SetShareToken(r.Context(), h.DB, id, ""); err != nil {
+		http.Error(w, "Failed to revoke share link", http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, r, fmt.Sprintf("/tournaments/%d/manage#share", id), http.StatusSeeOther)
+}
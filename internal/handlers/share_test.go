@@ -0,0 +1,64 @@
+//go:build integration
+
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/db"
+)
+
+func TestTournamentHandler_ShareLink(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	tmpl := &mockTemplate{}
+	h := &TournamentHandler{DB: database, Tmpl: tmpl}
+	owner, tourn := startedTournament(t, database)
+	id := strconv.FormatInt(tourn.ID, 10)
+
+	view := func(token string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.ShareView(rec, requestWithUser("GET", "/", "", nil, map[string]string{"id": id, "token": token}))
+		return rec
+	}
+	if rec := view("anything"); rec.Code != http.StatusNotFound {
+		t.Errorf("no link: status %d", rec.Code)
+	}
+
+	rec := httptest.NewRecorder()
+	h.CreateShareLink(rec, requestWithUser("POST", "/", "", owner, map[string]string{"id": id}))
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("create: status %d", rec.Code)
+	}
+	token, _ := db.GetShareToken(ctx, database, tourn.ID)
+	rec = view(token)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("view: status %d", rec.Code)
+	}
+	if c := rec.Header().Values("Set-Cookie"); len(c) != 0 {
+		t.Errorf("share view set cookies: %v", c)
+	}
+	if rec.Header().Get("Referrer-Policy") != "no-referrer" {
+		t.Errorf("Referrer-Policy = %q", rec.Header().Get("Referrer-Policy"))
+	}
+	data := tmpl.calls[len(tmpl.calls)-1].Data.(map[string]interface{})
+	if data["CurrentRound"] != 1 || data["Spectator"] != true || data["CSRFToken"] != nil {
+		t.Errorf("template data = %+v", data)
+	}
+	if rec := view(token + "x"); rec.Code != http.StatusNotFound {
+		t.Errorf("wrong token: status %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	h.RevokeShareLink(rec, requestWithUser("POST", "/", "", owner, map[string]string{"id": id}))
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("revoke: status %d", rec.Code)
+	}
+	if rec := view(token); rec.Code != http.StatusNotFound {
+		t.Errorf("revoked link: status %d", rec.Code)
+	}
+}
@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/engine"
+	"github.com/dstathis/openswiss/internal/middleware"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/go-chi/chi/v5"
+)
+
+// SnapshotsPage lists the tournament's snapshots, newest first, each with
+// a download link and a roll back button. Min tier: Admin.
+func (h *TournamentHandler) SnapshotsPage(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if !middleware.AuthorizeTournament(w, r, h.DB, id, models.TierAdmin) {
+		return
+	}
+	t, err := db.GetTournament(r.Context(), h.DB, id)
+	if err != nil {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+	snapshots, _ := db.ListSnapshots(r.Context(), h.DB, id)
+	h.Tmpl.ExecuteTemplate(w, "tournament_snapshots.html", map[string]interface{}{
+		"User":       middleware.GetUser(r.Context()),
+		"CSRFToken":  middleware.CSRFToken(r),
+		"Theme":      middleware.Theme(r),
+		"Flash":      middleware.Flash(r),
+		"Lang":       middleware.Locale(r),
+		"Tournament": t,
+		"Snapshots":  snapshots,
+	})
+}
+
+// DownloadSnapshot sends a snapshot as a backup file, which can be restored
+// like any other (see AdminHandler.Restore). Min tier: Admin.
+func (h *TournamentHandler) DownloadSnapshot(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	snapshotID, err := strconv.ParseInt(chi.URLParam(r, "snapshotID"), 10, 64)
+	if err != nil {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+	if !middleware.AuthorizeTournament(w, r, h.DB, id, models.TierAdmin) {
+		return
+	}
+	b, err := db.GetSnapshotBackup(r.Context(), h.DB, id, snapshotID)
+	if errors.Is(err, sql.ErrNoRows) {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to load snapshot", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, b.Filename()))
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(b)
+}
+
+// RollBack returns the tournament to a snapshot. The state it replaces is
+// kept as a snapshot of its own. Min tier: Admin.
+func (h *TournamentHandler) RollBack(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	snapshotID, err := strconv.ParseInt(chi.URLParam(r, "snapshotID"), 10, 64)
+	if err != nil {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+	if !middleware.AuthorizeTournament(w, r, h.DB, id, models.TierAdmin) {
+		return
+	}
+	err = engine.RollBack(r.Context(), h.DB, id, snapshotID)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	case errors.Is(err, engine.ErrInvalidBackup), errors.Is(err, db.ErrBackupUserConflict):
+		http.Error(w, "Can't roll back to this snapshot: "+err.Error(), http.StatusConflict)
+		return
+	case err != nil:
+		http.Error(w, "Failed to roll back", http.StatusInternalServerError)
+		return
+	}
+	user := middleware.GetUser(r.Context())
+	slog.Info("tournament rolled back", "tournament_id", id, "snapshot_id", snapshotID, "user_id", user.ID)
+	http.Redirect(w, r, fmt.Sprintf("/tournaments/%d/manage", id), http.StatusSeeOther)
+}
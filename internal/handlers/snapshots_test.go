@@ -0,0 +1,75 @@
+//go:build integration
+
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/dstathis/swisstools"
+)
+
+func TestTournamentHandler_Snapshots(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	tmpl := &mockTemplate{}
+	h := &TournamentHandler{DB: database, Tmpl: tmpl}
+	owner, tourn := startedTournament(t, database)
+	judge := mustCreateUser(t, database, "judge-snap@example.com", "JudgeSnap")
+	if err := db.AddTournamentStaff(ctx, database, &models.TournamentStaff{
+		TournamentID: tourn.ID, UserID: judge.ID, Tier: models.TierJudge,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	params := map[string]string{"id": strconv.FormatInt(tourn.ID, 10)}
+
+	rec := httptest.NewRecorder()
+	h.NextRound(rec, requestWithUser("POST", "/", "", owner, params))
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("next round: status %d body %s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	h.SnapshotsPage(rec, requestWithUser("GET", "/", "", judge, params))
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("judge: status %d, want 403", rec.Code)
+	}
+	h.SnapshotsPage(httptest.NewRecorder(), requestWithUser("GET", "/", "", owner, params))
+	snaps := tmpl.calls[0].Data.(map[string]interface{})["Snapshots"].([]models.TournamentSnapshot)
+	if len(snaps) != 2 || snaps[0].Round != 2 || snaps[1].Round != 1 {
+		t.Fatalf("snapshots = %+v", snaps)
+	}
+
+	snapParams := map[string]string{"id": params["id"], "snapshotID": strconv.FormatInt(snaps[1].ID, 10)}
+	rec = httptest.NewRecorder()
+	h.DownloadSnapshot(rec, requestWithUser("GET", "/", "", owner, snapParams))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"openswiss_backup"`) {
+		t.Errorf("download: status %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	h.RollBack(rec, requestWithUser("POST", "/", "", owner, snapParams))
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("roll back: status %d body %s", rec.Code, rec.Body.String())
+	}
+	got, _ := db.GetTournament(ctx, database, tourn.ID)
+	if eng, err := swisstools.LoadTournament(got.EngineState); err != nil || eng.GetCurrentRound() != 1 {
+		t.Errorf("after roll back: round %d, err %v; want round 1", eng.GetCurrentRound(), err)
+	}
+	if snaps, _ = db.ListSnapshots(ctx, database, tourn.ID); len(snaps) != 3 || snaps[0].Reason != models.SnapshotRollback {
+		t.Errorf("snapshots after roll back = %+v", snaps)
+	}
+
+	missing := map[string]string{"id": params["id"], "snapshotID": "999999"}
+	rec = httptest.NewRecorder()
+	h.RollBack(rec, requestWithUser("POST", "/", "", owner, missing))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("missing snapshot: status %d, want 404", rec.Code)
+	}
+}
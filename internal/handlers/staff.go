@@ -41,15 +41,29 @@ func (h *StaffHandler) StaffPage(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Not found", http.StatusNotFound)
 		return
 	}
-	staff, err := db.ListTournamentStaff(r.Context(), h.DB, id)
+	h.renderStaffPage(w, r, t, "")
+}
+
+// renderStaffPage renders the staff page. handoffCode is a freshly issued
+// handoff code, shown this once.
+func (h *StaffHandler) renderStaffPage(w http.ResponseWriter, r *http.Request, t *models.Tournament, handoffCode string) {
+	staff, err := db.ListTournamentStaff(r.Context(), h.DB, t.ID)
 	if err != nil {
 		http.Error(w, "Internal error", http.StatusInternalServerError)
 		return
 	}
+	handoffs, _ := db.ListHandoffs(r.Context(), h.DB, t.ID)
 	h.Tmpl.ExecuteTemplate(w, "tournament_staff.html", map[string]interface{}{
-		"User":       middleware.GetUser(r.Context()),
-		"Tournament": t,
-		"Staff":      staff,
+		"User":           middleware.GetUser(r.Context()),
+		"CSRFToken":      middleware.CSRFToken(r),
+		"Theme":          middleware.Theme(r),
+		"Flash":          middleware.Flash(r),
+		"Lang":           middleware.Locale(r),
+		"Tournament":     t,
+		"Staff":          staff,
+		"Handoffs":       handoffs,
+		"HandoffCode":    handoffCode,
+		"HandoffMinutes": int(models.HandoffTTL.Minutes()),
 	})
 }
 
@@ -185,7 +199,7 @@ func (h *StaffHandler) RemoveStaff(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *StaffHandler) sendGrantEmail(target, granter *models.User, t *models.Tournament, tier models.TournamentTier) {
-	if h.Email == nil || !h.Email.Config.Enabled() {
+	if h.Email == nil || !h.Email.Config.Enabled() || !target.Notifies(models.NotifyEmail) {
 		return
 	}
 	url := fmt.Sprintf("%s/tournaments/%d", h.BaseURL, t.ID)
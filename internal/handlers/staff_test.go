@@ -5,7 +5,9 @@ package handlers
 import (
 	"context"
 	"net/http/httptest"
+	"net/url"
 	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/dstathis/openswiss/internal/db"
@@ -53,3 +55,50 @@ func TestStaffHandler_StaffPage_NonAdminForbidden(t *testing.T) {
 		t.Errorf("expected 403, got %d", rec.Code)
 	}
 }
+
+func TestStaffHandler_Handoff(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	tmpl := &mockTemplate{}
+	h := &StaffHandler{DB: database, Tmpl: tmpl}
+	owner := mustCreateUser(t, database, "owner-handoff@example.com", "OwnerHandoff")
+	next := mustCreateUser(t, database, "next-handoff@example.com", "NextHandoff")
+	tourn := mustCreateTournament(t, database, owner.ID, models.TournamentStatusInProgress)
+	params := map[string]string{"id": strconv.FormatInt(tourn.ID, 10)}
+
+	rec := httptest.NewRecorder()
+	h.CreateHandoff(rec, requestWithUser("POST", "/", "", next, params))
+	if rec.Code != 403 {
+		t.Errorf("non-staff create: status %d, want 403", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	h.CreateHandoff(rec, requestWithUser("POST", "/", "", owner, params))
+	if rec.Code != 200 || len(tmpl.calls) != 1 {
+		t.Fatalf("create: status %d, calls %d", rec.Code, len(tmpl.calls))
+	}
+	code, _ := tmpl.calls[0].Data.(map[string]interface{})["HandoffCode"].(string)
+	if code == "" {
+		t.Fatal("handoff code not shown")
+	}
+
+	rec = httptest.NewRecorder()
+	h.ClaimHandoff(rec, requestWithUser("POST", "/", url.Values{"code": {"WRONG-CODE-0000"}}.Encode(), next, nil))
+	if rec.Code != 200 || tmpl.calls[1].Data.(map[string]interface{})["Error"] == "" {
+		t.Errorf("bad code: status %d, data %+v", rec.Code, tmpl.calls[1].Data)
+	}
+
+	// Codes are accepted regardless of case and dashes.
+	typed := strings.ToLower(strings.ReplaceAll(code, "-", " "))
+	rec = httptest.NewRecorder()
+	h.ClaimHandoff(rec, requestWithUser("POST", "/", url.Values{"code": {typed}}.Encode(), next, nil))
+	if rec.Code != 303 || rec.Header().Get("Location") != "/tournaments/"+params["id"]+"/manage" {
+		t.Fatalf("claim: status %d location %q", rec.Code, rec.Header().Get("Location"))
+	}
+	if tier, _ := db.GetTournamentTier(ctx, database, tourn.ID, next.ID); tier != models.TierAdmin {
+		t.Errorf("claimer tier = %q", tier)
+	}
+	if tier, _ := db.GetTournamentTier(ctx, database, tourn.ID, owner.ID); tier != "" {
+		t.Errorf("issuer tier = %q, want none", tier)
+	}
+}
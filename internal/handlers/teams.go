@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/engine"
+	"github.com/dstathis/openswiss/internal/middleware"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/dstathis/swisstools"
+	"github.com/go-chi/chi/v5"
+)
+
+// seatRow is one seat of a team match: the two members seated there and
+// the result, if reported.
+type seatRow struct {
+	Seat    int
+	PlayerA string
+	PlayerB string
+	Winner  string
+}
+
+// SetTeamMembers replaces a team's roster. Form field: members, one name per
+// line in seat order. Min tier: Co-organizer.
+func (h *TournamentHandler) SetTeamMembers(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	regID, err := strconv.ParseInt(chi.URLParam(r, "regID"), 10, 64)
+	if err != nil {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+	if !middleware.AuthorizeTournament(w, r, h.DB, id, models.TierCoOrganizer) {
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+	if err := engine.SetTeamMembers(r.Context(), h.DB, id, regID, engine.ParseMembers(r.FormValue("members"))); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	http.Redirect(w, r, fmt.Sprintf("/tournaments/%d/manage/players", id), http.StatusSeeOther)
+}
+
+// ReportSeats records the seat results of one team match in the current
+// round. Form fields: table, and seat_1 … seat_N each a, b, draw or empty
+// to leave the seat unreported. Min tier: Judge.
+func (h *TournamentHandler) ReportSeats(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if !middleware.AuthorizeTournament(w, r, h.DB, id, models.TierJudge) {
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+	table, err := strconv.Atoi(r.FormValue("table"))
+	if err != nil {
+		http.Error(w, "Invalid table", http.StatusBadRequest)
+		return
+	}
+	user := middleware.GetUser(r.Context())
+
+	err = engine.WithTournamentEngine(r.Context(), h.DB, id,
+		func(tx *sql.Tx, t *models.Tournament, eng *swisstools.Tournament) (string, error) {
+			for seat := 1; seat <= t.TeamSize; seat++ {
+				s := &models.SeatResult{
+					Table:      table,
+					Seat:       seat,
+					Winner:     r.FormValue(fmt.Sprintf("seat_%d", seat)),
+					ReportedBy: &user.ID,
+				}
+				if err := engine.ReportSeat(r.Context(), tx, t, eng, s); err != nil {
+					return "", err
+				}
+			}
+			return "", nil
+		})
+	if err != nil {
+		engineError(w, r, err)
+		return
+	}
+	http.Redirect(w, r, fmt.Sprintf("/tournaments/%d/manage/rounds", id), http.StatusSeeOther)
+}
+
+// attachSeats fills in the seats of each team match of the round: who sits
+// where, from the teams' rosters, and the results reported so far.
+func attachSeats(ctx context.Context, database db.DBTX, t *models.Tournament, round int, regs []models.Registration, pairings []resolvedPairing) {
+	results, err := db.ListSeatResults(ctx, database, t.ID, round)
+	if err != nil {
+		return
+	}
+	rosters := map[int][]string{}
+	for _, reg := range regs {
+		if reg.EnginePlayerID != nil {
+			rosters[*reg.EnginePlayerID] = reg.Members
+		}
+	}
+	member := func(player, seat int) string {
+		if roster := rosters[player]; seat <= len(roster) {
+			return roster[seat-1]
+		}
+		return ""
+	}
+	for i, p := range pairings {
+		if p.IsBye {
+			continue
+		}
+		seats := make([]seatRow, t.TeamSize)
+		for s := range seats {
+			seats[s] = seatRow{Seat: s + 1, PlayerA: member(p.PlayerAID, s+1), PlayerB: member(p.PlayerBID, s+1)}
+		}
+		for _, res := range results[p.Table] {
+			if res.Seat <= len(seats) {
+				seats[res.Seat-1].Winner = res.Winner
+			}
+		}
+		pairings[i].Seats = seats
+	}
+}
+
+// TeamScore formats the seats won so far in the pairing's team match from
+// team A's side, e.g. "2-1", with draws appended only when there are any.
+func (p resolvedPairing) TeamScore() string {
+	results := make([]models.SeatResult, len(p.Seats))
+	for i, s := range p.Seats {
+		results[i].Winner = s.Winner
+	}
+	a, b, d := engine.SeatScore(results)
+	if d > 0 {
+		return fmt.Sprintf("%d-%d-%d", a, b, d)
+	}
+	return fmt.Sprintf("%d-%d", a, b)
+}
+
+// individualStandings returns the individual standings of a team event
+// (see engine.IndividualStandings), or nil for an individual event or on a
+// read error.
+func individualStandings(ctx context.Context, database db.DBTX, t *models.Tournament, eng *swisstools.Tournament, regs []models.Registration) []engine.IndividualStanding {
+	if t.TeamSize == 0 {
+		return nil
+	}
+	results, err := db.ListAllSeatResults(ctx, database, t.ID)
+	if err != nil {
+		return nil
+	}
+	return engine.IndividualStandings(t, eng, regs, results)
+}
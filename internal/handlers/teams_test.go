@@ -0,0 +1,147 @@
+//go:build integration
+
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/engine"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/dstathis/swisstools"
+)
+
+// startedTeamTournament returns a started team event of four teams of two,
+// round 1 paired and unreported.
+func startedTeamTournament(t *testing.T, database *sql.DB) (*models.User, *models.Tournament) {
+	t.Helper()
+	ctx := context.Background()
+	owner := mustCreateUser(t, database, "owner-team-"+t.Name()+"@example.com", "Owner-team-"+t.Name())
+	numRounds := 2
+	tourn := &models.Tournament{
+		Name: "Teams " + t.Name(), NumRounds: &numRounds, PointsWin: 3, PointsDraw: 1, TeamSize: 2,
+		Status: models.TournamentStatusRegistrationOpen, OrganizerID: owner.ID,
+	}
+	if err := db.CreateTournament(ctx, database, tourn); err != nil {
+		t.Fatalf("create tournament: %v", err)
+	}
+	for i := 0; i < 4; i++ {
+		reg, err := db.CreateGuestRegistration(ctx, database, tourn.ID, fmt.Sprintf("Team %d", i))
+		if err != nil {
+			t.Fatalf("register team %d: %v", i, err)
+		}
+		members := []string{fmt.Sprintf("T%d Alpha", i), fmt.Sprintf("T%d Beta", i)}
+		if err := db.SetTeamMembers(ctx, database, tourn.ID, reg.ID, members); err != nil {
+			t.Fatalf("roster team %d: %v", i, err)
+		}
+	}
+	regs, _ := db.ListRegistrations(ctx, database, tourn.ID)
+	if err := engine.WithTournamentEngine(ctx, database, tourn.ID,
+		func(tx *sql.Tx, tm *models.Tournament, eng *swisstools.Tournament) (string, error) {
+			state, err := engine.InitTournamentEngine(ctx, tx, tm, regs)
+			if err != nil {
+				return "", err
+			}
+			ne, err := swisstools.LoadTournament(state)
+			if err != nil {
+				return "", err
+			}
+			*eng = ne
+			return models.TournamentStatusInProgress, nil
+		}); err != nil {
+		t.Fatalf("init engine: %v", err)
+	}
+	tourn, err := db.GetTournament(ctx, database, tourn.ID)
+	if err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	return owner, tourn
+}
+
+func TestTournamentHandler_ReportSeats(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	h := &TournamentHandler{DB: database, Tmpl: &mockTemplate{}}
+	owner, tourn := startedTeamTournament(t, database)
+	params := map[string]string{"id": strconv.FormatInt(tourn.ID, 10)}
+
+	report := func(seats ...string) int {
+		form := url.Values{"table": {"1"}}
+		for i, s := range seats {
+			form.Set(fmt.Sprintf("seat_%d", i+1), s)
+		}
+		rec := httptest.NewRecorder()
+		h.ReportSeats(rec, requestWithUser("POST", "/", form.Encode(), owner, params))
+		return rec.Code
+	}
+	result := func() swisstools.Pairing {
+		tm, _ := db.GetTournament(ctx, database, tourn.ID)
+		eng, err := swisstools.LoadTournament(tm.EngineState)
+		if err != nil {
+			t.Fatalf("load engine: %v", err)
+		}
+		return eng.GetRound()[0]
+	}
+
+	if code := report("a", ""); code != http.StatusSeeOther {
+		t.Fatalf("one seat: status %d", code)
+	}
+	if p := result(); p.PlayerAWins() != swisstools.UNINITIALIZED_RESULT {
+		t.Errorf("result written with a seat open: %d-%d", p.PlayerAWins(), p.PlayerBWins())
+	}
+	if code := report("a", "draw"); code != http.StatusSeeOther {
+		t.Fatalf("both seats: status %d", code)
+	}
+	if p := result(); p.PlayerAWins() != 1 || p.PlayerBWins() != 0 || p.Draws() != 1 {
+		t.Errorf("team result = %d-%d-%d, want 1-0-1", p.PlayerAWins(), p.PlayerBWins(), p.Draws())
+	}
+	if code := report("x", "a"); code != http.StatusBadRequest {
+		t.Errorf("bad winner: status %d, want 400", code)
+	}
+
+	tmpl := &mockTemplate{}
+	h.Tmpl = tmpl
+	h.Detail(httptest.NewRecorder(), requestWithUser("GET", "/", "", nil, params))
+	data := tmpl.calls[0].Data.(map[string]interface{})
+	pairings := data["Pairings"].([]resolvedPairing)
+	if len(pairings[0].Seats) != 2 || pairings[0].Seats[1].Winner != models.GameDraw || pairings[0].TeamScore() != "1-0-1" {
+		t.Errorf("detail seats = %+v", pairings[0].Seats)
+	}
+	if individual := data["Individual"].([]engine.IndividualStanding); len(individual) != 8 || individual[0].Wins != 1 {
+		t.Errorf("individual standings = %+v", individual)
+	}
+}
+
+func TestTournamentHandler_SetTeamMembers(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	h := &TournamentHandler{DB: database, Tmpl: &mockTemplate{}}
+	owner, tourn := startedTeamTournament(t, database)
+	regs, _ := db.ListRegistrations(ctx, database, tourn.ID)
+	params := map[string]string{"id": strconv.FormatInt(tourn.ID, 10), "regID": strconv.FormatInt(regs[0].ID, 10)}
+
+	set := func(members string) int {
+		rec := httptest.NewRecorder()
+		h.SetTeamMembers(rec, requestWithUser("POST", "/", url.Values{"members": {members}}.Encode(), owner, params))
+		return rec.Code
+	}
+	if code := set("Sub\n\nT0 Beta\n"); code != http.StatusSeeOther {
+		t.Fatalf("status %d", code)
+	}
+	reg, _ := db.GetRegistrationByID(ctx, database, regs[0].ID)
+	if len(reg.Members) != 2 || reg.Members[0] != "Sub" {
+		t.Errorf("members = %v", reg.Members)
+	}
+	for _, members := range []string{"Only One", "Same\nsame", "A\nB\nC"} {
+		if code := set(members); code != http.StatusBadRequest {
+			t.Errorf("%q: status %d, want 400", members, code)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/dstathis/openswiss/internal/middleware"
+)
+
+// ThemeHandler switches the colour theme for browsers without JavaScript;
+// app.js sets the same cookie itself and skips the round trip.
+type ThemeHandler struct {
+	SecureCookies bool
+}
+
+// themeCookieAge keeps the choice for a year.
+const themeCookieAge = 365 * 24 * time.Hour
+
+// SetTheme stores the submitted theme in the theme cookie and sends the
+// visitor back to the page they came from.
+func (h *ThemeHandler) SetTheme(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+	theme := r.FormValue("theme")
+	if !middleware.ValidTheme(theme) {
+		http.Error(w, "Unknown theme", http.StatusBadRequest)
+		return
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     middleware.ThemeCookie,
+		Value:    theme,
+		Path:     "/",
+		MaxAge:   int(themeCookieAge.Seconds()),
+		Secure:   h.SecureCookies,
+		SameSite: http.SameSiteLaxMode,
+	})
+	http.Redirect(w, r, backPath(r), http.StatusSeeOther)
+}
+
+// backPath is the local path of the request's Referer, or "/". Only the
+// path and query are kept, so the redirect can't leave the site.
+func backPath(r *http.Request) string {
+	u, err := url.Parse(r.Referer())
+	if err != nil || !strings.HasPrefix(u.Path, "/") || strings.HasPrefix(u.Path, "//") {
+		return "/"
+	}
+	if u.RawQuery != "" {
+		return u.Path + "?" + u.RawQuery
+	}
+	return u.Path
+}
@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/middleware"
+)
+
+func TestThemeHandler_SetTheme(t *testing.T) {
+	h := &ThemeHandler{}
+	for referer, want := range map[string]string{
+		"":                                   "/",
+		"https://example.com/tournaments/3":  "/tournaments/3",
+		"/tournaments/3/rounds/2?x=1":        "/tournaments/3/rounds/2?x=1",
+		"https://evil.example//evil.example": "/",
+	} {
+		r := httptest.NewRequest("POST", "/theme", strings.NewReader("theme=light"))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if referer != "" {
+			r.Header.Set("Referer", referer)
+		}
+		rec := httptest.NewRecorder()
+		h.SetTheme(rec, r)
+		if rec.Code != http.StatusSeeOther || rec.Header().Get("Location") != want {
+			t.Errorf("referer %q: status %d, location %q, want %q", referer, rec.Code, rec.Header().Get("Location"), want)
+		}
+		cookies := rec.Result().Cookies()
+		if len(cookies) != 1 || cookies[0].Name != middleware.ThemeCookie || cookies[0].Value != "light" {
+			t.Errorf("cookies = %+v", cookies)
+		}
+	}
+
+	r := httptest.NewRequest("POST", "/theme", strings.NewReader("theme=purple"))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec := httptest.NewRecorder()
+	h.SetTheme(rec, r)
+	if rec.Code != http.StatusBadRequest || len(rec.Result().Cookies()) != 0 {
+		t.Errorf("unknown theme: status %d, cookies %+v", rec.Code, rec.Result().Cookies())
+	}
+}
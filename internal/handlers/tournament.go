@@ -3,16 +3,22 @@ package handlers
 import (
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/dstathis/openswiss/internal/analytics"
 	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/email"
 	"github.com/dstathis/openswiss/internal/engine"
 	"github.com/dstathis/openswiss/internal/middleware"
 	"github.com/dstathis/openswiss/internal/models"
+	"github.com/dstathis/openswiss/internal/pow"
+	"github.com/dstathis/openswiss/internal/stripe"
 	"github.com/dstathis/swisstools"
 	"github.com/go-chi/chi/v5"
 )
@@ -20,9 +26,32 @@ import (
 type TournamentHandler struct {
 	DB   *sql.DB
 	Tmpl TemplateRenderer
+	// ChallongeBaseURL overrides the Challonge API's address; empty means
+	// challonge.DefaultBaseURL.
+	ChallongeBaseURL string
+	// Email and BaseURL send the results reminders staff ask for; without
+	// SMTP configured they only go to webhooks.
+	Email   *email.Sender
+	BaseURL string
+	// Defaults are what a new tournament's settings start as; nil means
+	// models.StandardDefaults.
+	Defaults *models.TournamentDefaults
+	// Stripe lets players pay the entry fee online; nil when it isn't
+	// configured.
+	Stripe *stripe.Client
+	// Views counts views of the public pages; nil counts none.
+	Views *analytics.Tracker
+}
+
+func (h *TournamentHandler) defaults() models.TournamentDefaults {
+	if h.Defaults == nil {
+		return models.StandardDefaults
+	}
+	return *h.Defaults
 }
 
 type resolvedPairing struct {
+	Table       int
 	PlayerAID   int
 	PlayerBID   int
 	PlayerAName string
@@ -31,18 +60,90 @@ type resolvedPairing struct {
 	PlayerBWins int
 	Draws       int
 	IsBye       bool
+	// Reported is set once the match has a result; byes always do.
+	Reported bool
+	// Fields holds custom pairing field values keyed by field ID.
+	Fields map[int64]string
+	// Games lists the games reported so far in series mode.
+	Games []models.MatchGame
+	// Seats lists the seats of a team match in a team event.
+	Seats []seatRow
+	// ResultType is set for an intentional draw or a concession, with
+	// ConcededBy naming the conceding side (see models.MatchResultType).
+	ResultType string
+	ConcededBy string
+	// Feature is set on a match staff put on stream.
+	Feature bool
+	// Mine is set on the logged-in player's own match.
+	Mine bool
+}
+
+// myPairing is the logged-in player's match in a round, shown above the
+// pairings so they don't have to search the table for their name.
+type myPairing struct {
+	Table    int
+	Opponent string
+	Bye      bool
+}
+
+// waitlistPosition returns reg's place on the waitlist of regs, or 0 when
+// it is nil or not waitlisted.
+func waitlistPosition(regs []models.Registration, reg *models.Registration) int {
+	if reg == nil {
+		return 0
+	}
+	return engine.WaitlistPosition(regs, reg.ID)
+}
+
+// userRegistration returns user's registration among regs, or nil.
+func userRegistration(regs []models.Registration, user *models.User) *models.Registration {
+	if user == nil {
+		return nil
+	}
+	for i := range regs {
+		if regs[i].UserID != nil && *regs[i].UserID == user.ID {
+			return &regs[i]
+		}
+	}
+	return nil
+}
+
+// markMyPairing flags reg's match in pairings and returns it. It returns nil
+// if reg is nil or isn't paired in the round, e.g. after dropping.
+func markMyPairing(pairings []resolvedPairing, reg *models.Registration) *myPairing {
+	if reg == nil || reg.EnginePlayerID == nil {
+		return nil
+	}
+	id := *reg.EnginePlayerID
+	for i := range pairings {
+		p := &pairings[i]
+		switch {
+		case p.PlayerAID == id && p.IsBye:
+			p.Mine = true
+			return &myPairing{Bye: true}
+		case p.PlayerAID == id:
+			p.Mine = true
+			return &myPairing{Table: p.Table, Opponent: p.PlayerBName}
+		case p.PlayerBID == id && !p.IsBye:
+			p.Mine = true
+			return &myPairing{Table: p.Table, Opponent: p.PlayerAName}
+		}
+	}
+	return nil
 }
 
 func resolvePairings(eng *swisstools.Tournament, pairings []swisstools.Pairing) []resolvedPairing {
 	resolved := make([]resolvedPairing, len(pairings))
 	for i, p := range pairings {
 		rp := resolvedPairing{
+			Table:       engine.TableNumber(i, p),
 			PlayerAID:   p.PlayerA(),
 			PlayerBID:   p.PlayerB(),
 			PlayerAWins: max(p.PlayerAWins(), 0),
 			PlayerBWins: max(p.PlayerBWins(), 0),
 			Draws:       max(p.Draws(), 0),
 			IsBye:       p.PlayerB() == swisstools.BYE_OPPONENT_ID,
+			Reported:    p.PlayerAWins() != swisstools.UNINITIALIZED_RESULT,
 		}
 		if player, ok := eng.GetPlayerById(p.PlayerA()); ok {
 			rp.PlayerAName = player.Name
@@ -57,9 +158,15 @@ func resolvePairings(eng *swisstools.Tournament, pairings []swisstools.Pairing)
 
 func (h *TournamentHandler) Home(w http.ResponseWriter, r *http.Request) {
 	tournaments, _ := db.ListUpcomingTournaments(r.Context(), h.DB, 20)
+	upNext, _ := db.ListUpNext(r.Context(), h.DB, time.Now(), 10)
 	h.Tmpl.ExecuteTemplate(w, "home.html", map[string]interface{}{
 		"User":        middleware.GetUser(r.Context()),
+		"CSRFToken":   middleware.CSRFToken(r),
+		"Theme":       middleware.Theme(r),
+		"Flash":       middleware.Flash(r),
+		"Lang":        middleware.Locale(r),
 		"Tournaments": tournaments,
+		"UpNext":      upNext,
 	})
 }
 
@@ -68,6 +175,10 @@ func (h *TournamentHandler) List(w http.ResponseWriter, r *http.Request) {
 	tournaments, _ := db.ListTournaments(r.Context(), h.DB, status, 1, 50)
 	h.Tmpl.ExecuteTemplate(w, "tournaments.html", map[string]interface{}{
 		"User":        middleware.GetUser(r.Context()),
+		"CSRFToken":   middleware.CSRFToken(r),
+		"Theme":       middleware.Theme(r),
+		"Flash":       middleware.Flash(r),
+		"Lang":        middleware.Locale(r),
 		"Tournaments": tournaments,
 		"Status":      status,
 	})
@@ -87,28 +198,48 @@ func (h *TournamentHandler) Detail(w http.ResponseWriter, r *http.Request) {
 	regs, _ := db.ListRegistrations(r.Context(), h.DB, id)
 
 	user := middleware.GetUser(r.Context())
-	var myReg *models.Registration
-	if user != nil {
-		for i := range regs {
-			if regs[i].UserID != nil && *regs[i].UserID == user.ID {
-				myReg = &regs[i]
-				break
-			}
-		}
-	}
+	myReg := userRegistration(regs, user)
 
 	// Load engine for standings/pairings if in progress
 	var standings []swisstools.PlayerStanding
 	var pairings []resolvedPairing
 	var currentRound int
+	var complete bool
+	var individual []engine.IndividualStanding
+	var colors map[int]string
+	var avatars map[int]*models.Avatar
+	var hidden bool
 	if t.EngineState != nil && len(t.EngineState) > 0 {
 		eng, err := swisstools.LoadTournament(t.EngineState)
 		if err == nil {
-			standings = eng.GetStandings()
-			pairings = resolvePairings(&eng, eng.GetRound())
+			avatars, _ = db.TournamentAvatars(r.Context(), h.DB, id)
+			standings = engine.CachedStandings(t, &eng, engine.TiebreakSeeds(regs))
+			if t.Colors {
+				colors = engine.ColorHistory(&eng)
+			}
 			currentRound = eng.GetCurrentRound()
+			if !t.PairingsDraft(currentRound) {
+				pairings = resolvePairings(&eng, eng.GetRound())
+			}
+			complete = engine.Complete(t, &eng)
+			individual = individualStandings(r.Context(), h.DB, t, &eng, regs)
+			hidden = engine.StandingsHidden(t, &eng)
 		}
 	}
+	h.Views.View(r, t.ID, currentRound, models.PageTournament)
+	// Found before filtering, so a search doesn't hide the player's own match.
+	myPairing := markMyPairing(pairings, myReg)
+	myReport := loadMyReport(r.Context(), h.DB, t, currentRound, pairings, myReg)
+	pairingFields := attachPairingFields(r.Context(), h.DB, id, currentRound, pairings, true)
+	attachResultTypes(r.Context(), h.DB, id, currentRound, pairings)
+	if t.SeriesMode {
+		attachGames(r.Context(), h.DB, id, currentRound, pairings)
+	}
+	if t.TeamSize > 0 {
+		attachSeats(r.Context(), h.DB, t, currentRound, regs, pairings)
+	}
+	attachFeatureMatches(r.Context(), h.DB, id, currentRound, pairings)
+	pinFeatureMatches(pairings)
 
 	tier, err := db.EffectiveTournamentTier(r.Context(), h.DB, t.ID, user)
 	if err != nil {
@@ -117,22 +248,107 @@ func (h *TournamentHandler) Detail(w http.ResponseWriter, r *http.Request) {
 	}
 	canManage := tier.AtLeast(models.TierJudge)
 	staff, _ := db.ListTournamentStaff(r.Context(), h.DB, id)
+	schedule, _ := db.ListSchedule(r.Context(), h.DB, id)
+	// In a blind final round only staff see the standings.
+	if hidden && !canManage {
+		standings, individual = nil, nil
+	}
+
+	q := nameQuery(r)
+	standings, standingsPager := filterPage(r, "standings_page", "standings", standings,
+		func(s swisstools.PlayerStanding) bool { return matchesName(q, s.Name) })
+	individual, individualPager := filterPage(r, "individual_page", "individual", individual,
+		func(s engine.IndividualStanding) bool { return matchesName(q, s.Name, s.Team) })
+	pairings, pairingsPager := filterPage(r, "pairings_page", "pairings", pairings,
+		func(p resolvedPairing) bool { return matchesName(q, p.PlayerAName, p.PlayerBName) })
+	regRows, regPager := filterPage(r, "players_page", "players", regs,
+		func(reg models.Registration) bool { return matchesName(q, reg.DisplayName) })
 	h.Tmpl.ExecuteTemplate(w, "tournament_detail.html", map[string]interface{}{
-		"User":           user,
-		"Tournament":     t,
-		"Registrations":  regs,
-		"MyRegistration": myReg,
-		"Standings":      standings,
-		"Pairings":       pairings,
-		"CurrentRound":   currentRound,
-		"CanManage":      canManage,
-		"Staff":          staff,
+		"User":               user,
+		"CSRFToken":          middleware.CSRFToken(r),
+		"Theme":              middleware.Theme(r),
+		"Flash":              middleware.Flash(r),
+		"Lang":               middleware.Locale(r),
+		"Tournament":         t,
+		"Query":              q,
+		"Registrations":      regRows,
+		"RegistrationsPager": regPager,
+		"MyRegistration":     myReg,
+		"WaitlistPosition":   waitlistPosition(regs, myReg),
+		"OnlineFee":          h.onlineFee(t, myReg),
+		"JustPaid":           r.URL.Query().Get("paid") != "",
+		"Full":               t.MaxPlayers > 0 && engine.PlacesTaken(regs) >= t.MaxPlayers,
+		"BotChallenge":       botChallenge(t, user),
+		"BotBits":            pow.Bits,
+		"MyPairing":          myPairing,
+		"MyReport":           myReport,
+		"Standings":          standings,
+		"StandingsPager":     standingsPager,
+		"StandingsHidden":    hidden,
+		"ColorHistory":       colors,
+		"Avatars":            avatars,
+		"Announcements":      activeAnnouncements(r.Context(), h.DB, t.ID),
+		"Individual":         individual,
+		"IndividualPager":    individualPager,
+		"Pairings":           pairings,
+		"PairingsPager":      pairingsPager,
+		"PairingFields":      pairingFields,
+		"CurrentRound":       currentRound,
+		"Rounds":             roundNumbers(currentRound),
+		"Round":              0,
+		"Draft":              t.PairingsDraft(currentRound),
+		"CanManage":          canManage,
+		"Complete":           complete,
+		"Staff":              staff,
+		"Stages":             loadStages(r.Context(), h.DB, t, canManage),
+		"Schedule":           schedule,
+		"CalendarURL":        calendarURL(h.BaseURL, t.ID),
 	})
 }
 
+// botChallenge returns the proof-of-work puzzle user's browser solves to
+// sign up for t, or "" when t has no Bot Check or nobody is signed in.
+func botChallenge(t *models.Tournament, user *models.User) string {
+	if !t.BotCheck || user == nil {
+		return ""
+	}
+	return pow.Challenge(t.ID, user.ID)
+}
+
+// NewPage shows the new tournament form filled in with the server's
+// defaults, or with ?preset= those of a preset.
 func (h *TournamentHandler) NewPage(w http.ResponseWriter, r *http.Request) {
+	form := &models.Tournament{MinPlayers: models.DefaultMinPlayers}
+	h.defaults().Apply(form)
+	var preset *models.Preset
+	if pid, err := strconv.ParseInt(r.URL.Query().Get("preset"), 10, 64); err == nil {
+		if preset, err = db.GetPreset(r.Context(), h.DB, pid); err != nil {
+			http.Error(w, "Preset not found", http.StatusNotFound)
+			return
+		}
+		preset.Settings.Apply(form)
+	}
+	h.renderNewForm(w, r, form, preset, "")
+}
+
+// renderNewForm renders the new tournament form with form's settings, the
+// presets to start from and the one chosen, if any.
+func (h *TournamentHandler) renderNewForm(w http.ResponseWriter, r *http.Request, form *models.Tournament, preset *models.Preset, errMsg string) {
+	presets, err := db.ListPresets(r.Context(), h.DB)
+	if err != nil {
+		http.Error(w, "Failed to load presets", http.StatusInternalServerError)
+		return
+	}
 	h.Tmpl.ExecuteTemplate(w, "tournament_new.html", map[string]interface{}{
-		"User": middleware.GetUser(r.Context()),
+		"User":      middleware.GetUser(r.Context()),
+		"CSRFToken": middleware.CSRFToken(r),
+		"Theme":     middleware.Theme(r),
+		"Flash":     middleware.Flash(r),
+		"Lang":      middleware.Locale(r),
+		"Form":      form,
+		"Presets":   presets,
+		"Preset":    preset,
+		"Error":     errMsg,
 	})
 }
 
@@ -147,19 +363,21 @@ func (h *TournamentHandler) Create(w http.ResponseWriter, r *http.Request) {
 		OrganizerID:     user.ID,
 		RequireDecklist: r.FormValue("require_decklist") == "on",
 		DecklistPublic:  r.FormValue("decklist_public") == "on",
-		PointsWin:       3,
-		PointsDraw:      1,
-		PointsLoss:      0,
+		MinPlayers:      models.DefaultMinPlayers,
 		Status:          models.TournamentStatusScheduled,
 	}
+	h.defaults().Apply(t)
 	if desc := r.FormValue("description"); desc != "" {
 		t.Description = &desc
 	}
 	if loc := r.FormValue("location"); loc != "" {
 		t.Location = &loc
 	}
+	if tz := strings.TrimSpace(r.FormValue("time_zone")); tz != "" {
+		t.TimeZone = tz
+	}
 	if sa := r.FormValue("scheduled_at"); sa != "" {
-		if parsed, err := time.Parse("2006-01-02T15:04", sa); err == nil {
+		if parsed, err := time.ParseInLocation("2006-01-02T15:04", sa, t.Zone()); err == nil {
 			t.ScheduledAt = &parsed
 		}
 	}
@@ -168,6 +386,11 @@ func (h *TournamentHandler) Create(w http.ResponseWriter, r *http.Request) {
 			t.MaxPlayers = v
 		}
 	}
+	if mp := r.FormValue("min_players"); mp != "" {
+		if v, err := strconv.Atoi(mp); err == nil {
+			t.MinPlayers = v
+		}
+	}
 	if nr := r.FormValue("num_rounds"); nr != "" {
 		if v, err := strconv.Atoi(nr); err == nil {
 			t.NumRounds = &v
@@ -178,6 +401,47 @@ func (h *TournamentHandler) Create(w http.ResponseWriter, r *http.Request) {
 			t.TopCut = v
 		}
 	}
+	if pa := r.FormValue("pairing_algorithm"); models.ValidPairingAlgorithm(pa) {
+		t.PairingAlgorithm = pa
+	}
+	if bp := r.FormValue("bye_policy"); models.ValidByePolicy(bp) {
+		t.ByePolicy = bp
+	}
+	if rp := r.FormValue("round1_pairing"); models.ValidRound1Pairing(rp) {
+		t.Round1Pairing = rp
+	}
+	if ac := r.FormValue("avoid_club_rounds"); ac != "" {
+		if v, err := strconv.Atoi(ac); err == nil {
+			t.AvoidClubRounds = v
+		}
+	}
+	if ef := r.FormValue("entry_fee"); ef != "" {
+		if v, err := models.ParseAmount(ef); err == nil {
+			t.EntryFee = v
+		}
+	}
+	if bo := r.FormValue("best_of"); bo != "" {
+		if v, err := strconv.Atoi(bo); err == nil {
+			t.BestOf = v
+		}
+	}
+	t.SeriesMode = r.FormValue("series_mode") == "on"
+	t.PreviewPairings = r.FormValue("preview_pairings") == "on"
+	t.BlindFinalRound = r.FormValue("blind_final_round") == "on"
+	t.Colors = r.FormValue("colors") == "on"
+	t.AutoRounds = r.FormValue("auto_rounds") == "on"
+	t.SelfReport = r.FormValue("self_report") == "on"
+	t.BotCheck = r.FormValue("bot_check") == "on"
+	if rm := r.FormValue("report_confirm_minutes"); rm != "" {
+		if v, err := strconv.Atoi(rm); err == nil {
+			t.ReportConfirmMinutes = v
+		}
+	}
+	if ts := r.FormValue("team_size"); ts != "" {
+		if v, err := strconv.Atoi(ts); err == nil {
+			t.TeamSize = v
+		}
+	}
 	if pw := r.FormValue("points_win"); pw != "" {
 		if v, err := strconv.Atoi(pw); err == nil {
 			t.PointsWin = v
@@ -193,17 +457,63 @@ func (h *TournamentHandler) Create(w http.ResponseWriter, r *http.Request) {
 			t.PointsLoss = v
 		}
 	}
+	if tb := models.ParseTiebreakers(r.FormValue("tiebreakers")); len(tb) > 0 {
+		t.Tiebreakers = tb
+	}
 
-	if err := db.CreateTournament(r.Context(), h.DB, t); err != nil {
-		h.Tmpl.ExecuteTemplate(w, "tournament_new.html", map[string]interface{}{
-			"User":  user,
-			"Error": "Failed to create tournament.",
-		})
+	// The form carries the preset's settings; its pairing fields come
+	// from the preset itself.
+	var preset *models.Preset
+	var fields []models.PresetField
+	if pid, err := strconv.ParseInt(r.FormValue("preset_id"), 10, 64); err == nil {
+		if preset, err = db.GetPreset(r.Context(), h.DB, pid); err != nil {
+			h.renderNewForm(w, r, t, nil, "The preset no longer exists.")
+			return
+		}
+		fields = preset.Settings.PairingFields
+	}
+
+	if err := validateSettings(t); err != nil {
+		h.renderNewForm(w, r, t, preset, err.Error())
+		return
+	}
+	if err := db.CreateTournamentWithFields(r.Context(), h.DB, t, fields); err != nil {
+		h.renderNewForm(w, r, t, preset, "Failed to create tournament.")
 		return
 	}
 	http.Redirect(w, r, fmt.Sprintf("/tournaments/%d", t.ID), http.StatusSeeOther)
 }
 
+// validateSettings runs the cross-field checks on a tournament's settings
+// shared by Create and EditTournament.
+func validateSettings(t *models.Tournament) error {
+	if err := t.ValidatePlayerLimits(); err != nil {
+		return err
+	}
+	if err := t.ValidateTimeZone(); err != nil {
+		return err
+	}
+	if err := t.ValidateTiebreakers(); err != nil {
+		return err
+	}
+	if err := t.ValidateClubRounds(); err != nil {
+		return err
+	}
+	if err := t.ValidateEntryFee(); err != nil {
+		return err
+	}
+	return t.ValidateMatchFormat()
+}
+
+// formTimeZone reads the time_zone field of the settings form, which
+// defaults to UTC when left empty.
+func formTimeZone(r *http.Request) string {
+	if tz := strings.TrimSpace(r.FormValue("time_zone")); tz != "" {
+		return tz
+	}
+	return models.DefaultTimeZone
+}
+
 func (h *TournamentHandler) EditTournament(w http.ResponseWriter, r *http.Request) {
 	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
 	t, err := db.GetTournament(r.Context(), h.DB, id)
@@ -236,8 +546,9 @@ func (h *TournamentHandler) EditTournament(w http.ResponseWriter, r *http.Reques
 	} else {
 		t.Location = nil
 	}
+	t.TimeZone = formTimeZone(r)
 	if sa := r.FormValue("scheduled_at"); sa != "" {
-		if parsed, err := time.Parse("2006-01-02T15:04", sa); err == nil {
+		if parsed, err := time.ParseInLocation("2006-01-02T15:04", sa, t.Zone()); err == nil {
 			t.ScheduledAt = &parsed
 		}
 	} else {
@@ -248,6 +559,11 @@ func (h *TournamentHandler) EditTournament(w http.ResponseWriter, r *http.Reques
 			t.MaxPlayers = v
 		}
 	}
+	if mp := r.FormValue("min_players"); mp != "" {
+		if v, err := strconv.Atoi(mp); err == nil {
+			t.MinPlayers = v
+		}
+	}
 	if nr := r.FormValue("num_rounds"); nr != "" {
 		if v, err := strconv.Atoi(nr); err == nil {
 			t.NumRounds = &v
@@ -260,6 +576,56 @@ func (h *TournamentHandler) EditTournament(w http.ResponseWriter, r *http.Reques
 			t.TopCut = v
 		}
 	}
+	if pa := r.FormValue("pairing_algorithm"); models.ValidPairingAlgorithm(pa) {
+		t.PairingAlgorithm = pa
+	}
+	if bp := r.FormValue("bye_policy"); models.ValidByePolicy(bp) {
+		t.ByePolicy = bp
+	}
+	if rp := r.FormValue("round1_pairing"); models.ValidRound1Pairing(rp) {
+		t.Round1Pairing = rp
+	}
+	if ac := r.FormValue("avoid_club_rounds"); ac != "" {
+		if v, err := strconv.Atoi(ac); err == nil {
+			t.AvoidClubRounds = v
+		}
+	}
+	if ef := r.FormValue("entry_fee"); ef != "" {
+		if v, err := models.ParseAmount(ef); err == nil {
+			t.EntryFee = v
+		}
+	} else {
+		t.EntryFee = 0
+	}
+	if bo := r.FormValue("best_of"); bo != "" {
+		if v, err := strconv.Atoi(bo); err == nil {
+			t.BestOf = v
+		}
+	}
+	t.SeriesMode = r.FormValue("series_mode") == "on"
+	t.PreviewPairings = r.FormValue("preview_pairings") == "on"
+	t.BlindFinalRound = r.FormValue("blind_final_round") == "on"
+	t.Colors = r.FormValue("colors") == "on"
+	t.AutoRounds = r.FormValue("auto_rounds") == "on"
+	t.SelfReport = r.FormValue("self_report") == "on"
+	t.BotCheck = r.FormValue("bot_check") == "on"
+	if rm := r.FormValue("report_confirm_minutes"); rm != "" {
+		if v, err := strconv.Atoi(rm); err == nil {
+			t.ReportConfirmMinutes = v
+		}
+	} else {
+		t.ReportConfirmMinutes = 0
+	}
+	if ts := r.FormValue("team_size"); ts != "" {
+		if v, err := strconv.Atoi(ts); err == nil {
+			t.TeamSize = v
+		}
+	}
+	if pa := r.FormValue("pod_advance"); pa != "" {
+		if v, err := strconv.Atoi(pa); err == nil {
+			t.PodAdvance = v
+		}
+	}
 	if pw := r.FormValue("points_win"); pw != "" {
 		if v, err := strconv.Atoi(pw); err == nil {
 			t.PointsWin = v
@@ -275,55 +641,19 @@ func (h *TournamentHandler) EditTournament(w http.ResponseWriter, r *http.Reques
 			t.PointsLoss = v
 		}
 	}
-
-	if err := db.UpdateTournament(r.Context(), h.DB, t); err != nil {
-		http.Error(w, "Failed to update tournament", http.StatusInternalServerError)
-		return
+	if tb, ok := r.Form["tiebreakers"]; ok {
+		t.Tiebreakers = models.ParseTiebreakers(tb[0])
 	}
-	http.Redirect(w, r, fmt.Sprintf("/tournaments/%d/manage", id), http.StatusSeeOther)
-}
 
-func (h *TournamentHandler) ManagePage(w http.ResponseWriter, r *http.Request) {
-	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
-	t, err := db.GetTournament(r.Context(), h.DB, id)
-	if err != nil {
-		http.Error(w, "Not found", http.StatusNotFound)
+	if err := validateSettings(t); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-	if !middleware.AuthorizeTournament(w, r, h.DB, t.ID, models.TierJudge) {
+	if err := db.UpdateTournament(r.Context(), h.DB, t); err != nil {
+		http.Error(w, "Failed to update tournament", http.StatusInternalServerError)
 		return
 	}
-	user := middleware.GetUser(r.Context())
-	tier, _ := db.EffectiveTournamentTier(r.Context(), h.DB, t.ID, user)
-	regs, _ := db.ListRegistrations(r.Context(), h.DB, id)
-
-	var standings []swisstools.PlayerStanding
-	var pairings []resolvedPairing
-	var currentRound int
-	var playoffStatus string
-	var playoffPairings []resolvedPairing
-	if t.EngineState != nil && len(t.EngineState) > 0 {
-		eng, err := swisstools.LoadTournament(t.EngineState)
-		if err == nil {
-			standings = eng.GetStandings()
-			pairings = resolvePairings(&eng, eng.GetRound())
-			currentRound = eng.GetCurrentRound()
-			playoffStatus = eng.GetPlayoffStatus()
-			playoffPairings = resolvePairings(&eng, eng.GetPlayoffRound())
-		}
-	}
-
-	h.Tmpl.ExecuteTemplate(w, "tournament_manage.html", map[string]interface{}{
-		"User":            user,
-		"Tournament":      t,
-		"Registrations":   regs,
-		"Standings":       standings,
-		"Pairings":        pairings,
-		"CurrentRound":    currentRound,
-		"PlayoffStatus":   playoffStatus,
-		"PlayoffPairings": playoffPairings,
-		"IsAdmin":         tier == models.TierAdmin,
-	})
+	http.Redirect(w, r, fmt.Sprintf("/tournaments/%d/manage", id), http.StatusSeeOther)
 }
 
 func (h *TournamentHandler) OpenRegistration(w http.ResponseWriter, r *http.Request) {
@@ -344,6 +674,8 @@ func (h *TournamentHandler) OpenRegistration(w http.ResponseWriter, r *http.Requ
 	http.Redirect(w, r, fmt.Sprintf("/tournaments/%d/manage", id), http.StatusSeeOther)
 }
 
+// Register signs the user up. With Bot Check on, the form carries the
+// solution to the user's puzzle in pow_nonce (see botChallenge).
 func (h *TournamentHandler) Register(w http.ResponseWriter, r *http.Request) {
 	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
 	t, err := db.GetTournament(r.Context(), h.DB, id)
@@ -356,19 +688,17 @@ func (h *TournamentHandler) Register(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	user := middleware.GetUser(r.Context())
-
-	if t.MaxPlayers > 0 {
-		count, _ := db.CountRegistrations(r.Context(), h.DB, id)
-		if count >= t.MaxPlayers {
-			http.Error(w, "Tournament is full", http.StatusBadRequest)
-			return
-		}
+	if t.BotCheck && !pow.Verify(pow.Challenge(t.ID, user.ID), r.FormValue("pow_nonce"), pow.Bits) {
+		http.Error(w, "The bot check didn't finish. Please try signing up again.", http.StatusBadRequest)
+		return
 	}
 
+	status := models.RegistrationStatusConfirmed
 	if t.RequireDecklist {
-		db.CreatePendingRegistration(r.Context(), h.DB, id, user.ID, user.DisplayName)
-	} else {
-		db.CreateRegistration(r.Context(), h.DB, id, user.ID, user.DisplayName)
+		status = models.RegistrationStatusPending
+	}
+	if _, err := db.RegisterUser(r.Context(), h.DB, id, user.ID, user.DisplayName, status, t.MaxPlayers); err == nil && r.FormValue("contact_consent") != "" {
+		db.SetContactConsent(r.Context(), h.DB, id, user.ID, true)
 	}
 	http.Redirect(w, r, fmt.Sprintf("/tournaments/%d", id), http.StatusSeeOther)
 }
@@ -409,8 +739,13 @@ func (h *TournamentHandler) DecklistPage(w http.ResponseWriter, r *http.Request)
 
 	h.Tmpl.ExecuteTemplate(w, "decklist.html", map[string]interface{}{
 		"User":       user,
+		"CSRFToken":  middleware.CSRFToken(r),
+		"Theme":      middleware.Theme(r),
+		"Flash":      middleware.Flash(r),
+		"Lang":       middleware.Locale(r),
 		"Tournament": t,
 		"DeckText":   deckText,
+		"Archetype":  reg.Archetype,
 	})
 }
 
@@ -421,10 +756,18 @@ func (h *TournamentHandler) SubmitDecklist(w http.ResponseWriter, r *http.Reques
 		return
 	}
 	user := middleware.GetUser(r.Context())
+	archetype, ok := formArchetype(r)
+	if !ok {
+		http.Error(w, fmt.Sprintf("The archetype can be at most %d characters", db.MaxArchetypeLength), http.StatusBadRequest)
+		return
+	}
 	deckText := r.FormValue("decklist")
 	dl := parseDecklist(deckText)
 	data, _ := json.Marshal(dl)
 	db.UpdateRegistrationDecklist(r.Context(), h.DB, id, user.ID, data)
+	if reg, err := db.GetRegistration(r.Context(), h.DB, id, user.ID); err == nil {
+		db.SetArchetype(r.Context(), h.DB, reg.ID, archetype)
+	}
 	http.Redirect(w, r, fmt.Sprintf("/tournaments/%d", id), http.StatusSeeOther)
 }
 
@@ -438,8 +781,8 @@ func (h *TournamentHandler) Start(w http.ResponseWriter, r *http.Request) {
 
 	err := engine.WithTournamentEngine(r.Context(), h.DB, id,
 		func(tx *sql.Tx, t *models.Tournament, eng *swisstools.Tournament) (string, error) {
-			if t.Status != models.TournamentStatusRegistrationOpen && t.Status != models.TournamentStatusScheduled {
-				return "", fmt.Errorf("tournament cannot be started from state %s", t.Status)
+			if err := engine.CheckStart(t, regs).Err(); err != nil {
+				return "", err
 			}
 
 			// Initialize the engine: add all confirmed players, start tournament
@@ -458,10 +801,11 @@ func (h *TournamentHandler) Start(w http.ResponseWriter, r *http.Request) {
 		})
 
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		engineError(w, r, err)
 		return
 	}
-	http.Redirect(w, r, fmt.Sprintf("/tournaments/%d/manage", id), http.StatusSeeOther)
+	middleware.SetFlash(w, r, middleware.FlashSuccess, "The tournament has started. Round 1 is paired.")
+	http.Redirect(w, r, fmt.Sprintf("/tournaments/%d/manage/rounds", id), http.StatusSeeOther)
 }
 
 func (h *TournamentHandler) SubmitResults(w http.ResponseWriter, r *http.Request) {
@@ -474,82 +818,129 @@ func (h *TournamentHandler) SubmitResults(w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	user := middleware.GetUser(r.Context())
+
 	err := engine.WithTournamentEngine(r.Context(), h.DB, id,
 		func(tx *sql.Tx, t *models.Tournament, eng *swisstools.Tournament) (string, error) {
-			// Parse results from form: wins_a_<playerID>, wins_b_<playerID>, draws_<playerID>
-			for key := range r.Form {
-				if !strings.HasPrefix(key, "wins_a_") {
+			// Parse results from form: wins_a_<playerID>, wins_b_<playerID>,
+			// draws_<playerID>, and type_<playerID> for a special result.
+			pairings := resolvePairings(eng, eng.GetRound())
+			for _, p := range pairings {
+				idStr := strconv.Itoa(p.PlayerAID)
+				if _, ok := r.Form["wins_a_"+idStr]; !ok {
 					continue
 				}
-				playerIDStr := strings.TrimPrefix(key, "wins_a_")
-				playerID, err := strconv.Atoi(playerIDStr)
-				if err != nil {
-					continue
-				}
-				wins, _ := strconv.Atoi(r.FormValue("wins_a_" + playerIDStr))
-				losses, _ := strconv.Atoi(r.FormValue("wins_b_" + playerIDStr))
-				draws, _ := strconv.Atoi(r.FormValue("draws_" + playerIDStr))
-				if err := eng.AddResult(playerID, wins, losses, draws); err != nil {
-					return "", fmt.Errorf("adding result for player %d: %w", playerID, err)
+				wins, _ := strconv.Atoi(r.FormValue("wins_a_" + idStr))
+				losses, _ := strconv.Atoi(r.FormValue("wins_b_" + idStr))
+				draws, _ := strconv.Atoi(r.FormValue("draws_" + idStr))
+				if err := recordFormResult(r.Context(), tx, t, eng, p, r.FormValue("type_"+idStr), wins, losses, draws, &user.ID); err != nil {
+					return "", fmt.Errorf("adding result for player %d: %w", p.PlayerAID, err)
 				}
 			}
-			return "", nil
+			return "", savePairingFieldForm(r.Context(), tx, t.ID, eng.GetCurrentRound(), pairings, r.Form)
 		})
 
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		engineError(w, r, err)
 		return
 	}
-	http.Redirect(w, r, fmt.Sprintf("/tournaments/%d/manage", id), http.StatusSeeOther)
+	// Back to the same search and page of the results table.
+	back := url.Values{}
+	for _, k := range []string{"q", "pairings_page"} {
+		if v := r.FormValue(k); v != "" {
+			back.Set(k, v)
+		}
+	}
+	target := fmt.Sprintf("/tournaments/%d/manage/rounds", id)
+	if len(back) > 0 {
+		target += "?" + back.Encode() + "#pairings"
+	}
+	http.Redirect(w, r, target, http.StatusSeeOther)
 }
 
+// NextRound closes the current round and pairs the next. While matches are
+// unreported it answers 409 naming their tables, unless the form's force
+// checkbox is ticked, which records them as 0-0-1 draws. Min tier:
+// Co-organizer.
 func (h *TournamentHandler) NextRound(w http.ResponseWriter, r *http.Request) {
 	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
 	if !middleware.AuthorizeTournament(w, r, h.DB, id, models.TierCoOrganizer) {
 		return
 	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+	force := r.FormValue("force") == "on"
 
+	done := ""
 	err := engine.WithTournamentEngine(r.Context(), h.DB, id,
 		func(tx *sql.Tx, t *models.Tournament, eng *swisstools.Tournament) (string, error) {
-			if err := eng.NextRound(); err != nil {
+			assigned, err := engine.AssignedByes(r.Context(), tx, t, eng)
+			if err != nil {
 				return "", err
 			}
-			// Check if tournament auto-finished (max rounds reached)
-			if eng.GetStatus() == "finished" {
-				return models.TournamentStatusFinished, nil
+			clubs, err := engine.Clubs(r.Context(), tx, t)
+			if err != nil {
+				return "", err
 			}
-			if err := eng.Pair(false); err != nil {
+			seeds, err := engine.Seeds(r.Context(), tx, t)
+			if err != nil {
 				return "", err
 			}
-			return "", nil
+			status, err := engine.AdvanceRound(eng, t, force, assigned, clubs, seeds)
+			if status == models.TournamentStatusFinished {
+				done = "The tournament is finished."
+			} else {
+				done = fmt.Sprintf("Round %d is paired.", eng.GetCurrentRound())
+			}
+			return status, err
 		})
 
+	var missing *engine.MissingResultsError
+	if errors.As(err, &missing) {
+		http.Error(w, err.Error()+". Enter the results, or tick \"Record missing results as draws\" to advance anyway.", http.StatusConflict)
+		return
+	}
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		engineError(w, r, err)
 		return
 	}
-	http.Redirect(w, r, fmt.Sprintf("/tournaments/%d/manage", id), http.StatusSeeOther)
+	middleware.SetFlash(w, r, middleware.FlashSuccess, done)
+	http.Redirect(w, r, fmt.Sprintf("/tournaments/%d/manage/rounds", id), http.StatusSeeOther)
 }
 
+// RepairRound re-pairs the current round. With the round_key and proposal
+// form fields from the preview page it applies exactly the previewed
+// pairings; without them it pairs afresh. Min tier: Co-organizer.
 func (h *TournamentHandler) RepairRound(w http.ResponseWriter, r *http.Request) {
 	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
 	if !middleware.AuthorizeTournament(w, r, h.DB, id, models.TierCoOrganizer) {
 		return
 	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+	proposal := r.FormValue("proposal")
 
 	err := engine.WithTournamentEngine(r.Context(), h.DB, id,
 		func(tx *sql.Tx, t *models.Tournament, eng *swisstools.Tournament) (string, error) {
-			if err := eng.Pair(true); err != nil {
-				return "", err
+			if proposal != "" {
+				return "", engine.ApplyRepair(r.Context(), tx, t, eng, r.FormValue("round_key"), proposal)
 			}
-			return "", nil
+			return "", engine.RepairRound(r.Context(), tx, t, eng)
 		})
 
+	if errors.Is(err, engine.ErrRoundChanged) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		engineError(w, r, err)
 		return
 	}
-	http.Redirect(w, r, fmt.Sprintf("/tournaments/%d/manage", id), http.StatusSeeOther)
+	http.Redirect(w, r, fmt.Sprintf("/tournaments/%d/manage/rounds", id), http.StatusSeeOther)
 }
 
 func (h *TournamentHandler) Finish(w http.ResponseWriter, r *http.Request) {
@@ -560,6 +951,9 @@ func (h *TournamentHandler) Finish(w http.ResponseWriter, r *http.Request) {
 
 	err := engine.WithTournamentEngine(r.Context(), h.DB, id,
 		func(tx *sql.Tx, t *models.Tournament, eng *swisstools.Tournament) (string, error) {
+			if t.PairingsDraft(eng.GetCurrentRound()) {
+				return "", engine.ErrPairingsDraft
+			}
 			if err := eng.FinishTournament(); err != nil {
 				return "", err
 			}
@@ -570,13 +964,15 @@ func (h *TournamentHandler) Finish(w http.ResponseWriter, r *http.Request) {
 		})
 
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		engineError(w, r, err)
 		return
 	}
-	http.Redirect(w, r, fmt.Sprintf("/tournaments/%d/manage", id), http.StatusSeeOther)
+	middleware.SetFlash(w, r, middleware.FlashSuccess, "The tournament is finished.")
+	http.Redirect(w, r, fmt.Sprintf("/tournaments/%d/manage/rounds", id), http.StatusSeeOther)
 }
 
-// AddPlayer manually adds a guest player (no user account) to a tournament.
+// AddPlayer manually adds a guest player (no user account) to a tournament,
+// typed in as player_name or picked from the player registry as player_id.
 // Pre-tournament (scheduled / registration_open) it writes a guest registration
 // only; mid-tournament (in_progress) it also adds the player to the engine and
 // records the engine_player_id back onto the registration row.
@@ -587,7 +983,8 @@ func (h *TournamentHandler) AddPlayer(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	playerName := strings.TrimSpace(r.FormValue("player_name"))
-	if playerName == "" {
+	pickedID, _ := strconv.ParseInt(r.FormValue("player_id"), 10, 64)
+	if playerName == "" && pickedID == 0 {
 		http.Error(w, "player name is required", http.StatusBadRequest)
 		return
 	}
@@ -608,12 +1005,69 @@ func (h *TournamentHandler) AddPlayer(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "cannot add players in this tournament state", http.StatusBadRequest)
 		return
 	}
+	if t.Status == models.TournamentStatusInProgress && t.PairingAlgorithm == models.PairingRoundRobin {
+		http.Error(w, "a round robin can't take new players once it has started", http.StatusBadRequest)
+		return
+	}
+
+	// In a team event the new registration is a team, entered with its roster.
+	var members []string
+	if t.TeamSize > 0 {
+		if members, err = engine.CheckMembers(t, engine.ParseMembers(r.FormValue("members"))); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	var picked *models.Player
+	if pickedID != 0 {
+		// The registry is for organizers, not every co-organizer.
+		if !middleware.GetUser(r.Context()).CanOrganize() {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		if picked, err = db.GetPlayer(r.Context(), h.DB, pickedID); err != nil {
+			http.Error(w, "no such player in the registry", http.StatusBadRequest)
+			return
+		}
+		playerName = picked.Name
+	} else if playerName, err = models.ValidatePlayerName(playerName); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	// A name already entered is refused unless staff tick "Add anyway". A
+	// registry player entered twice is refused below either way.
+	if r.FormValue("allow_duplicate") != "on" {
+		err := engine.CheckNewName(r.Context(), h.DB, id, playerName)
+		var dup *engine.DuplicateNameError
+		switch {
+		case errors.As(err, &dup):
+			if picked == nil || dup.Existing.PlayerID == nil || *dup.Existing.PlayerID != picked.ID {
+				http.Error(w, err.Error()+`. Tick "Add anyway" to add a second entry.`, http.StatusConflict)
+				return
+			}
+		case err != nil:
+			http.Error(w, "Internal error", http.StatusInternalServerError)
+			return
+		}
+	}
 
-	reg, err := db.CreateGuestRegistration(r.Context(), h.DB, id, playerName)
+	var reg *models.Registration
+	if picked != nil {
+		reg, err = db.CreatePlayerRegistration(r.Context(), h.DB, id, picked)
+	} else {
+		reg, err = db.CreateGuestRegistration(r.Context(), h.DB, id, playerName)
+	}
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
+	if members != nil {
+		if err := db.SetTeamMembers(r.Context(), h.DB, id, reg.ID, members); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
 
 	// Mid-tournament: also push into the engine and record engine_player_id.
 	if t.Status == models.TournamentStatusInProgress {
@@ -633,7 +1087,7 @@ func (h *TournamentHandler) AddPlayer(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 	}
-	http.Redirect(w, r, fmt.Sprintf("/tournaments/%d/manage", id), http.StatusSeeOther)
+	http.Redirect(w, r, fmt.Sprintf("/tournaments/%d/manage/players", id), http.StatusSeeOther)
 }
 
 // DropPlayer removes a player from a tournament. Form takes either
@@ -670,7 +1124,7 @@ func (h *TournamentHandler) DropPlayer(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, err.Error(), http.StatusBadRequest)
 			return
 		}
-		http.Redirect(w, r, fmt.Sprintf("/tournaments/%d/manage", id), http.StatusSeeOther)
+		http.Redirect(w, r, fmt.Sprintf("/tournaments/%d/manage/players", id), http.StatusSeeOther)
 		return
 	}
 
@@ -685,13 +1139,13 @@ func (h *TournamentHandler) DropPlayer(w http.ResponseWriter, r *http.Request) {
 			return "", eng.RemovePlayerById(playerID)
 		})
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		engineError(w, r, err)
 		return
 	}
 	if reg, err := db.GetRegistrationByEnginePlayerID(r.Context(), h.DB, id, playerID); err == nil {
 		db.UpdateRegistrationStatusByID(r.Context(), h.DB, reg.ID, models.RegistrationStatusDropped)
 	}
-	http.Redirect(w, r, fmt.Sprintf("/tournaments/%d/manage", id), http.StatusSeeOther)
+	http.Redirect(w, r, fmt.Sprintf("/tournaments/%d/manage/players", id), http.StatusSeeOther)
 }
 
 func (h *TournamentHandler) StartPlayoff(w http.ResponseWriter, r *http.Request) {
@@ -705,17 +1159,21 @@ func (h *TournamentHandler) StartPlayoff(w http.ResponseWriter, r *http.Request)
 			if t.TopCut <= 0 {
 				return "", fmt.Errorf("tournament has no top cut configured")
 			}
-			if err := eng.StartPlayoff(t.TopCut); err != nil {
+			regs, err := db.ListRegistrations(r.Context(), tx, id)
+			if err != nil {
+				return "", err
+			}
+			if err := engine.StartPlayoff(eng, t, engine.TiebreakSeeds(regs)); err != nil {
 				return "", err
 			}
 			return models.TournamentStatusPlayoff, nil
 		})
 
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		engineError(w, r, err)
 		return
 	}
-	http.Redirect(w, r, fmt.Sprintf("/tournaments/%d/manage", id), http.StatusSeeOther)
+	http.Redirect(w, r, fmt.Sprintf("/tournaments/%d/manage/rounds", id), http.StatusSeeOther)
 }
 
 func (h *TournamentHandler) PlayoffResults(w http.ResponseWriter, r *http.Request) {
@@ -742,6 +1200,9 @@ func (h *TournamentHandler) PlayoffResults(w http.ResponseWriter, r *http.Reques
 				wins, _ := strconv.Atoi(r.FormValue("wins_a_" + playerIDStr))
 				losses, _ := strconv.Atoi(r.FormValue("wins_b_" + playerIDStr))
 				draws, _ := strconv.Atoi(r.FormValue("draws_" + playerIDStr))
+				if err := engine.CheckScore(t, wins, losses, draws); err != nil {
+					return "", fmt.Errorf("playoff result for player %d: %w", playerID, err)
+				}
 				if err := eng.AddPlayoffResult(playerID, wins, losses, draws); err != nil {
 					return "", fmt.Errorf("adding playoff result for player %d: %w", playerID, err)
 				}
@@ -750,10 +1211,10 @@ func (h *TournamentHandler) PlayoffResults(w http.ResponseWriter, r *http.Reques
 		})
 
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		engineError(w, r, err)
 		return
 	}
-	http.Redirect(w, r, fmt.Sprintf("/tournaments/%d/manage", id), http.StatusSeeOther)
+	http.Redirect(w, r, fmt.Sprintf("/tournaments/%d/manage/rounds", id), http.StatusSeeOther)
 }
 
 func (h *TournamentHandler) NextPlayoffRound(w http.ResponseWriter, r *http.Request) {
@@ -774,10 +1235,10 @@ func (h *TournamentHandler) NextPlayoffRound(w http.ResponseWriter, r *http.Requ
 		})
 
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		engineError(w, r, err)
 		return
 	}
-	http.Redirect(w, r, fmt.Sprintf("/tournaments/%d/manage", id), http.StatusSeeOther)
+	http.Redirect(w, r, fmt.Sprintf("/tournaments/%d/manage/rounds", id), http.StatusSeeOther)
 }
 
 func (h *TournamentHandler) RequestDrop(w http.ResponseWriter, r *http.Request) {
@@ -821,6 +1282,10 @@ func (h *TournamentHandler) OrganizerDecklistPage(w http.ResponseWriter, r *http
 
 	h.Tmpl.ExecuteTemplate(w, "organizer_decklist.html", map[string]interface{}{
 		"User":         user,
+		"CSRFToken":    middleware.CSRFToken(r),
+		"Theme":        middleware.Theme(r),
+		"Flash":        middleware.Flash(r),
+		"Lang":         middleware.Locale(r),
 		"Tournament":   t,
 		"Registration": reg,
 		"DeckText":     deckText,
@@ -851,13 +1316,22 @@ func (h *TournamentHandler) OrganizerSubmitDecklist(w http.ResponseWriter, r *ht
 		http.Error(w, "Bad request", http.StatusBadRequest)
 		return
 	}
+	archetype, ok := formArchetype(r)
+	if !ok {
+		http.Error(w, fmt.Sprintf("The archetype can be at most %d characters", db.MaxArchetypeLength), http.StatusBadRequest)
+		return
+	}
 	dl := parseDecklist(r.FormValue("decklist"))
 	data, _ := json.Marshal(dl)
 	if err := db.UpdateRegistrationDecklistByID(r.Context(), h.DB, regID, data); err != nil {
 		http.Error(w, "Failed to save decklist", http.StatusInternalServerError)
 		return
 	}
-	http.Redirect(w, r, fmt.Sprintf("/tournaments/%d/manage", id), http.StatusSeeOther)
+	if err := db.SetArchetype(r.Context(), h.DB, regID, archetype); err != nil {
+		http.Error(w, "Failed to save archetype", http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, r, fmt.Sprintf("/tournaments/%d/manage/players", id), http.StatusSeeOther)
 }
 
 // Helpers
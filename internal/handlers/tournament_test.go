@@ -13,7 +13,10 @@ import (
 	"testing"
 
 	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/engine"
 	"github.com/dstathis/openswiss/internal/models"
+	"github.com/dstathis/openswiss/internal/pow"
+	"github.com/dstathis/swisstools"
 )
 
 func TestTournamentHandler_Home(t *testing.T) {
@@ -104,6 +107,33 @@ func TestTournamentHandler_Detail_AsRegisteredPlayer(t *testing.T) {
 	}
 }
 
+func TestTournamentHandler_Detail_MyPairing(t *testing.T) {
+	database := testDB(t)
+	tmpl := &mockTemplate{}
+	h := &TournamentHandler{DB: database, Tmpl: tmpl}
+	ctx := context.Background()
+	_, tourn := startedTournament(t, database)
+	regs, _ := db.ListRegistrations(ctx, database, tourn.ID)
+	player, err := db.GetUserByID(ctx, database, *regs[0].UserID)
+	if err != nil {
+		t.Fatalf("get user: %v", err)
+	}
+
+	// A search for someone else must not hide the player's own match.
+	req := requestWithUser("GET", "/?q=nobody", "", player, map[string]string{"id": strconv.FormatInt(tourn.ID, 10)})
+	h.Detail(httptest.NewRecorder(), req)
+	data := tmpl.calls[0].Data.(map[string]interface{})
+	mine, _ := data["MyPairing"].(*myPairing)
+	if mine == nil || mine.Bye || mine.Table == 0 || mine.Opponent == "" {
+		t.Fatalf("MyPairing = %+v, want a table and opponent", data["MyPairing"])
+	}
+
+	h.Detail(httptest.NewRecorder(), requestWithUser("GET", "/", "", nil, map[string]string{"id": strconv.FormatInt(tourn.ID, 10)}))
+	if data := tmpl.calls[1].Data.(map[string]interface{}); data["MyPairing"].(*myPairing) != nil {
+		t.Errorf("anonymous MyPairing = %+v, want nil", data["MyPairing"])
+	}
+}
+
 func TestTournamentHandler_Detail_NotFound(t *testing.T) {
 	database := testDB(t)
 	h := &TournamentHandler{DB: database, Tmpl: &mockTemplate{}}
@@ -157,6 +187,11 @@ func TestTournamentHandler_Create(t *testing.T) {
 	form.Set("points_win", "3")
 	form.Set("points_draw", "1")
 	form.Set("points_loss", "0")
+	form.Set("tiebreakers", "GW, ogw")
+	form.Set("colors", "on")
+	form.Set("avoid_club_rounds", "3")
+	form.Set("auto_rounds", "on")
+	form.Set("entry_fee", "12,50")
 	form.Set("require_decklist", "on")
 	form.Set("decklist_public", "on")
 	form.Set("scheduled_at", "2026-06-15T10:00")
@@ -171,6 +206,68 @@ func TestTournamentHandler_Create(t *testing.T) {
 	if !strings.HasPrefix(loc, "/tournaments/") {
 		t.Errorf("expected redirect to /tournaments/<id>, got %q", loc)
 	}
+	id, _ := strconv.ParseInt(strings.TrimPrefix(loc, "/tournaments/"), 10, 64)
+	got, err := db.GetTournament(context.Background(), database, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(got.Tiebreakers, ",") != "gw,ogw" {
+		t.Errorf("tiebreakers = %v, want gw,ogw", got.Tiebreakers)
+	}
+	if !got.Colors {
+		t.Error("colours not saved")
+	}
+	if got.AvoidClubRounds != 3 {
+		t.Errorf("avoid_club_rounds = %d, want 3", got.AvoidClubRounds)
+	}
+	if !got.AutoRounds {
+		t.Error("auto_rounds not saved")
+	}
+	if got.EntryFee != 1250 {
+		t.Errorf("entry_fee = %d, want 1250", got.EntryFee)
+	}
+
+	tmpl := &mockTemplate{}
+	h.Tmpl = tmpl
+	form.Set("tiebreakers", "omw, buchholz")
+	h.Create(httptest.NewRecorder(), requestWithUser("POST", "/tournaments", form.Encode(), user, nil))
+	if len(tmpl.calls) != 1 || !strings.Contains(tmpl.calls[0].Data.(map[string]interface{})["Error"].(string), "buchholz") {
+		t.Errorf("unknown tiebreaker: rendered %+v", tmpl.calls)
+	}
+}
+
+func TestTournamentHandler_Create_Defaults(t *testing.T) {
+	database := testDB(t)
+	tmpl := &mockTemplate{}
+	h := &TournamentHandler{DB: database, Tmpl: tmpl, Defaults: &models.TournamentDefaults{
+		TimeZone: "Europe/Berlin", MaxPlayers: 24, PointsWin: 2, PointsDraw: 1, Tiebreakers: []string{models.TiebreakGW},
+	}}
+	user := mustCreateUser(t, database, "u@example.com", "U")
+
+	h.NewPage(httptest.NewRecorder(), requestWithUser("GET", "/", "", user, nil))
+	data := tmpl.calls[0].Data.(map[string]interface{})
+	if f := data["Form"].(*models.Tournament); f.TimeZone != "Europe/Berlin" || f.MaxPlayers != 24 || f.MinPlayers != models.DefaultMinPlayers {
+		t.Errorf("form defaults = %+v", f)
+	}
+
+	// Fields left out of the form keep the server's defaults.
+	form := url.Values{"name": {"Spring Open"}, "points_win": {"3"}}
+	rec := httptest.NewRecorder()
+	h.Create(rec, requestWithUser("POST", "/tournaments", form.Encode(), user, nil))
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("expected 303, got %d", rec.Code)
+	}
+	id, _ := strconv.ParseInt(strings.TrimPrefix(rec.Header().Get("Location"), "/tournaments/"), 10, 64)
+	got, err := db.GetTournament(context.Background(), database, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.TimeZone != "Europe/Berlin" || got.MaxPlayers != 24 || got.PointsWin != 3 || got.PointsDraw != 1 {
+		t.Errorf("tournament = %+v", got)
+	}
+	if strings.Join(got.Tiebreakers, ",") != "gw" {
+		t.Errorf("tiebreakers = %v, want gw", got.Tiebreakers)
+	}
 }
 
 func TestTournamentHandler_Create_DBError(t *testing.T) {
@@ -331,6 +428,139 @@ func TestTournamentHandler_ManagePage_Started(t *testing.T) {
 	}
 }
 
+func TestTournamentHandler_ManagePages(t *testing.T) {
+	database := testDB(t)
+	tmpl := &mockTemplate{}
+	h := &TournamentHandler{DB: database, Tmpl: tmpl}
+	owner, tourn := startedTournament(t, database)
+	judge := mustCreateUser(t, database, "judge-pages@example.com", "JudgePages")
+	if err := db.AddTournamentStaff(context.Background(), database, &models.TournamentStaff{
+		TournamentID: tourn.ID,
+		UserID:       judge.ID,
+		Tier:         models.TierJudge,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	other := mustCreateUser(t, database, "other-pages@example.com", "OtherPages")
+	params := map[string]string{"id": strconv.FormatInt(tourn.ID, 10)}
+
+	pages := []struct {
+		handler  http.HandlerFunc
+		template string
+		tab      string
+	}{
+		{h.ManagePage, "tournament_manage.html", "overview"},
+		{h.ManagePlayersPage, "tournament_manage_players.html", "players"},
+		{h.ManageRoundsPage, "tournament_manage_rounds.html", "rounds"},
+		{h.ManageResultsPage, "tournament_manage_results.html", "results"},
+	}
+	for _, p := range pages {
+		tmpl.calls = nil
+		p.handler(httptest.NewRecorder(), requestWithUser("GET", "/", "", judge, params))
+		if len(tmpl.calls) != 1 || tmpl.calls[0].Name != p.template {
+			t.Fatalf("%s: rendered %+v", p.tab, tmpl.calls)
+		}
+		data := tmpl.calls[0].Data.(map[string]interface{})
+		if data["Tab"] != p.tab || data["IsCoOrganizer"] != false {
+			t.Errorf("%s: Tab = %v, IsCoOrganizer = %v", p.tab, data["Tab"], data["IsCoOrganizer"])
+		}
+
+		rec := httptest.NewRecorder()
+		p.handler(rec, requestWithUser("GET", "/", "", other, params))
+		if rec.Code != http.StatusForbidden {
+			t.Errorf("%s: non-staff status %d, want 403", p.tab, rec.Code)
+		}
+	}
+
+	// Each page loads only its own part of the dashboard.
+	tmpl.calls = nil
+	h.ManagePlayersPage(httptest.NewRecorder(), requestWithUser("GET", "/", "", owner, params))
+	if _, ok := tmpl.calls[0].Data.(map[string]interface{})["Pairings"]; ok {
+		t.Error("players page loaded the pairings")
+	}
+	h.ManageRoundsPage(httptest.NewRecorder(), requestWithUser("GET", "/", "", owner, params))
+	if _, ok := tmpl.calls[1].Data.(map[string]interface{})["Standings"]; ok {
+		t.Error("rounds page loaded the standings")
+	}
+}
+
+func TestTournamentHandler_Fragments(t *testing.T) {
+	database := testDB(t)
+	tmpl := &mockTemplate{}
+	h := &TournamentHandler{DB: database, Tmpl: tmpl}
+	owner, tourn := startedTournament(t, database)
+	other := mustCreateUser(t, database, "other-fragments@example.com", "OtherFragments")
+	idStr := strconv.FormatInt(tourn.ID, 10)
+	params := map[string]string{"id": idStr}
+
+	// The fragment takes the page's query, and its pager leads to the page.
+	h.RegistrationsFragment(httptest.NewRecorder(),
+		requestWithUser("GET", "/tournaments/"+idStr+"/manage/fragments/registrations?per_page=1", "", owner, params))
+	if len(tmpl.calls) != 1 || tmpl.calls[0].Name != "tournament_manage_players.html#registrations" {
+		t.Fatalf("rendered %+v", tmpl.calls)
+	}
+	pg := tmpl.calls[0].Data.(map[string]interface{})["RegistrationsPager"].(pager)
+	if want := "/tournaments/" + idStr + "/manage/players?"; !strings.HasPrefix(pg.NextURL, want) {
+		t.Errorf("next page = %q, want it under %q", pg.NextURL, want)
+	}
+
+	h.RoundFragment(httptest.NewRecorder(),
+		requestWithUser("GET", "/tournaments/"+idStr+"/manage/fragments/round?per_page=1", "", owner, params))
+	if tmpl.calls[1].Name != "tournament_manage_rounds.html#round" {
+		t.Fatalf("rendered %q", tmpl.calls[1].Name)
+	}
+	data := tmpl.calls[1].Data.(map[string]interface{})
+	if pairings := data["Pairings"].([]resolvedPairing); len(pairings) != 1 {
+		t.Errorf("pairings = %d, want 1", len(pairings))
+	}
+	if pg := data["PairingsPager"].(pager); !strings.Contains(pg.NextURL, "/manage/rounds?") {
+		t.Errorf("next page = %q, want the rounds page", pg.NextURL)
+	}
+
+	for name, handler := range map[string]http.HandlerFunc{"registrations": h.RegistrationsFragment, "round": h.RoundFragment} {
+		rec := httptest.NewRecorder()
+		handler(rec, requestWithUser("GET", "/", "", other, params))
+		if rec.Code != http.StatusForbidden {
+			t.Errorf("%s: non-staff status %d, want 403", name, rec.Code)
+		}
+	}
+}
+
+func TestTournamentHandler_ManagePage_Search(t *testing.T) {
+	database := testDB(t)
+	tmpl := &mockTemplate{}
+	h := &TournamentHandler{DB: database, Tmpl: tmpl}
+	owner, tourn := startedTournament(t, database)
+	idStr := strconv.FormatInt(tourn.ID, 10)
+
+	req := func() *http.Request {
+		return requestWithUser("GET", "/?q=p3-", "", owner, map[string]string{"id": idStr})
+	}
+	h.ManagePlayersPage(httptest.NewRecorder(), req())
+	data := tmpl.calls[0].Data.(map[string]interface{})
+	if rows := data["RegistrationRows"].([]models.Registration); len(rows) != 1 {
+		t.Errorf("registration rows = %d, want 1", len(rows))
+	}
+	if regs := data["Registrations"].([]models.Registration); len(regs) != 4 {
+		t.Errorf("registrations = %d, want all 4", len(regs))
+	}
+	h.ManageResultsPage(httptest.NewRecorder(), req())
+	if standings := tmpl.calls[1].Data.(map[string]interface{})["Standings"].([]swisstools.PlayerStanding); len(standings) != 1 {
+		t.Errorf("standings = %d, want 1", len(standings))
+	}
+	h.ManageRoundsPage(httptest.NewRecorder(), req())
+	if pairings := tmpl.calls[2].Data.(map[string]interface{})["Pairings"].([]resolvedPairing); len(pairings) != 1 {
+		t.Errorf("pairings = %d, want 1", len(pairings))
+	}
+
+	// Saving results returns to the same search.
+	rec := httptest.NewRecorder()
+	h.SubmitResults(rec, requestWithUser("POST", "/", "q=p3-&pairings_page=1", owner, map[string]string{"id": idStr}))
+	if want := "/tournaments/" + idStr + "/manage/rounds?pairings_page=1&q=p3-#pairings"; rec.Header().Get("Location") != want {
+		t.Errorf("Location = %q, want %q", rec.Header().Get("Location"), want)
+	}
+}
+
 func TestTournamentHandler_OpenRegistration(t *testing.T) {
 	database := testDB(t)
 	h := &TournamentHandler{DB: database, Tmpl: &mockTemplate{}}
@@ -430,6 +660,40 @@ func TestTournamentHandler_Register_RequiresDecklist(t *testing.T) {
 	}
 }
 
+func TestTournamentHandler_Register_BotCheck(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	h := &TournamentHandler{DB: database, Tmpl: &mockTemplate{}}
+	owner := mustCreateUser(t, database, "owner@example.com", "Owner")
+	tourn := mustCreateTournament(t, database, owner.ID, models.TournamentStatusRegistrationOpen)
+	tourn.BotCheck = true
+	if err := db.UpdateTournament(ctx, database, tourn); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	user := mustCreateUser(t, database, "u@example.com", "U")
+	params := map[string]string{"id": strconv.FormatInt(tourn.ID, 10)}
+
+	for _, body := range []string{"", "pow_nonce=1", "pow_nonce=" + pow.Solve(pow.Challenge(tourn.ID, owner.ID), pow.Bits)} {
+		rec := httptest.NewRecorder()
+		h.Register(rec, requestWithUser("POST", "/", body, user, params))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%q: status = %d, want 400", body, rec.Code)
+		}
+	}
+	if _, err := db.GetRegistration(ctx, database, tourn.ID, user.ID); err == nil {
+		t.Fatal("registered without solving the bot check")
+	}
+
+	rec := httptest.NewRecorder()
+	h.Register(rec, requestWithUser("POST", "/", "pow_nonce="+pow.Solve(pow.Challenge(tourn.ID, user.ID), pow.Bits), user, params))
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("solved: status = %d, body=%s", rec.Code, rec.Body.String())
+	}
+	if _, err := db.GetRegistration(ctx, database, tourn.ID, user.ID); err != nil {
+		t.Errorf("not registered after solving the bot check: %v", err)
+	}
+}
+
 func TestTournamentHandler_Register_Closed(t *testing.T) {
 	database := testDB(t)
 	h := &TournamentHandler{DB: database, Tmpl: &mockTemplate{}}
@@ -618,6 +882,32 @@ func TestTournamentHandler_Start(t *testing.T) {
 	}
 }
 
+func TestTournamentHandler_Start_TooFewPlayers(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	tmpl := &mockTemplate{}
+	h := &TournamentHandler{DB: database, Tmpl: tmpl}
+	owner := mustCreateUser(t, database, "owner@example.com", "Owner")
+	tourn := mustCreateTournament(t, database, owner.ID, models.TournamentStatusRegistrationOpen)
+	params := map[string]string{"id": strconv.FormatInt(tourn.ID, 10)}
+	u := mustCreateUser(t, database, "p0@example.com", "P0")
+	if _, err := db.CreateRegistration(ctx, database, tourn.ID, u.ID, u.DisplayName); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+
+	h.ManagePage(httptest.NewRecorder(), requestWithUser("GET", "/", "", owner, params))
+	check := tmpl.calls[0].Data.(map[string]interface{})["StartCheck"].(engine.StartCheck)
+	if check.CanStart || check.Players != 1 {
+		t.Errorf("dashboard start check = %+v", check)
+	}
+
+	rec := httptest.NewRecorder()
+	h.Start(rec, requestWithUser("POST", "/", "", owner, params))
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "at least 2 confirmed players") {
+		t.Errorf("status %d body %s", rec.Code, rec.Body.String())
+	}
+}
+
 func TestTournamentHandler_Start_Forbidden(t *testing.T) {
 	database := testDB(t)
 	h := &TournamentHandler{DB: database, Tmpl: &mockTemplate{}}
@@ -710,6 +1000,43 @@ func TestTournamentHandler_NextRound(t *testing.T) {
 	}
 }
 
+func TestTournamentHandler_NextRound_MissingResults(t *testing.T) {
+	database := testDB(t)
+	h := &TournamentHandler{DB: database, Tmpl: &mockTemplate{}}
+	owner, tourn := startedTournament(t, database)
+	params := map[string]string{"id": strconv.FormatInt(tourn.ID, 10)}
+
+	rec := httptest.NewRecorder()
+	h.NextRound(rec, requestWithUser("POST", "/", "", owner, params))
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("round 1: status %d, body=%s", rec.Code, rec.Body.String())
+	}
+
+	// Round 2 has no results yet.
+	rec = httptest.NewRecorder()
+	h.NextRound(rec, requestWithUser("POST", "/", "", owner, params))
+	if rec.Code != http.StatusConflict || !strings.Contains(rec.Body.String(), "round 2 has no result at tables 1, 2") {
+		t.Fatalf("without force: status %d, body=%s", rec.Code, rec.Body.String())
+	}
+
+	tmpl := &mockTemplate{}
+	h.Tmpl = tmpl
+	h.ManagePage(httptest.NewRecorder(), requestWithUser("GET", "/", "", owner, params))
+	if missing := tmpl.calls[0].Data.(map[string]interface{})["MissingTables"].([]int); len(missing) != 2 {
+		t.Errorf("MissingTables = %v", missing)
+	}
+
+	rec = httptest.NewRecorder()
+	h.NextRound(rec, requestWithUser("POST", "/", "force=on", owner, params))
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("forced: status %d, body=%s", rec.Code, rec.Body.String())
+	}
+	got, _ := db.GetTournament(context.Background(), database, tourn.ID)
+	if got.Status != models.TournamentStatusFinished {
+		t.Errorf("status = %s, want finished after the last round", got.Status)
+	}
+}
+
 func TestTournamentHandler_NextRound_Forbidden(t *testing.T) {
 	database := testDB(t)
 	h := &TournamentHandler{DB: database, Tmpl: &mockTemplate{}}
@@ -751,6 +1078,49 @@ func TestTournamentHandler_RepairRound_Forbidden(t *testing.T) {
 	}
 }
 
+func TestTournamentHandler_RepairPreview(t *testing.T) {
+	database := testDB(t)
+	tmpl := &mockTemplate{}
+	h := &TournamentHandler{DB: database, Tmpl: tmpl}
+	owner, tourn := startedTournament(t, database)
+	params := map[string]string{"id": strconv.FormatInt(tourn.ID, 10)}
+	before, _ := db.GetTournament(context.Background(), database, tourn.ID)
+
+	rec := httptest.NewRecorder()
+	h.RepairPreview(rec, requestWithUser("GET", "/", "", owner, params))
+	if rec.Code != http.StatusOK || len(tmpl.calls) != 1 || tmpl.calls[0].Name != "tournament_repair.html" {
+		t.Fatalf("preview: status %d calls %+v", rec.Code, tmpl.calls)
+	}
+	data := tmpl.calls[0].Data.(map[string]interface{})
+	if rows := data["Rows"].([]repairRow); len(rows) == 0 {
+		t.Fatal("preview has no rows")
+	}
+	after, _ := db.GetTournament(context.Background(), database, tourn.ID)
+	if string(after.EngineState) != string(before.EngineState) {
+		t.Fatal("preview changed the engine state")
+	}
+
+	// Confirming applies exactly the previewed pairings.
+	form := url.Values{"round_key": {data["RoundKey"].(string)}, "proposal": {data["Proposal"].(string)}}
+	rec = httptest.NewRecorder()
+	h.RepairRound(rec, requestWithUser("POST", "/", form.Encode(), owner, params))
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("confirm: status %d body %s", rec.Code, rec.Body.String())
+	}
+	after, _ = db.GetTournament(context.Background(), database, tourn.ID)
+	eng, _ := swisstools.LoadTournament(after.EngineState)
+	if got := engine.EncodeRound(eng.GetRound()); got != form.Get("proposal") {
+		t.Errorf("round = %q, want %q", got, form.Get("proposal"))
+	}
+
+	// The key is now stale.
+	rec = httptest.NewRecorder()
+	h.RepairRound(rec, requestWithUser("POST", "/", form.Encode(), owner, params))
+	if rec.Code != http.StatusConflict {
+		t.Errorf("stale confirm: status %d, want 409", rec.Code)
+	}
+}
+
 func TestTournamentHandler_Finish(t *testing.T) {
 	database := testDB(t)
 	h := &TournamentHandler{DB: database, Tmpl: &mockTemplate{}}
@@ -835,6 +1205,53 @@ func TestTournamentHandler_AddPlayer_BlankName(t *testing.T) {
 	}
 }
 
+func TestTournamentHandler_AddPlayer_ReservedName(t *testing.T) {
+	database := testDB(t)
+	h := &TournamentHandler{DB: database, Tmpl: &mockTemplate{}}
+	owner := mustCreateUser(t, database, "owner@example.com", "Owner")
+	tourn := mustCreateTournament(t, database, owner.ID, models.TournamentStatusRegistrationOpen)
+
+	form := url.Values{}
+	form.Set("player_name", " BYE ")
+	req := requestWithUser("POST", "/", form.Encode(), owner, map[string]string{"id": strconv.FormatInt(tourn.ID, 10)})
+	rec := httptest.NewRecorder()
+	h.AddPlayer(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", rec.Code)
+	}
+}
+
+func TestTournamentHandler_AddPlayer_DuplicateName(t *testing.T) {
+	database := testDB(t)
+	h := &TournamentHandler{DB: database, Tmpl: &mockTemplate{}}
+	owner := mustCreateUser(t, database, "owner@example.com", "Owner")
+	tourn := mustCreateTournament(t, database, owner.ID, models.TournamentStatusRegistrationOpen)
+	if _, err := db.CreateGuestRegistration(context.Background(), database, tourn.ID, "Bob"); err != nil {
+		t.Fatal(err)
+	}
+
+	form := url.Values{}
+	form.Set("player_name", "bob")
+	req := requestWithUser("POST", "/", form.Encode(), owner, map[string]string{"id": strconv.FormatInt(tourn.ID, 10)})
+	rec := httptest.NewRecorder()
+	h.AddPlayer(rec, req)
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("expected 409, got %d", rec.Code)
+	}
+
+	form.Set("allow_duplicate", "on")
+	req = requestWithUser("POST", "/", form.Encode(), owner, map[string]string{"id": strconv.FormatInt(tourn.ID, 10)})
+	rec = httptest.NewRecorder()
+	h.AddPlayer(rec, req)
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("with allow_duplicate: status = %d, body=%s", rec.Code, rec.Body.String())
+	}
+	regs, _ := db.ListRegistrations(context.Background(), database, tourn.ID)
+	if len(regs) != 2 {
+		t.Errorf("expected 2 registrations, got %+v", regs)
+	}
+}
+
 func TestTournamentHandler_AddPlayer_NotFound(t *testing.T) {
 	database := testDB(t)
 	h := &TournamentHandler{DB: database, Tmpl: &mockTemplate{}}
@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/engine"
+	"github.com/dstathis/openswiss/internal/middleware"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/go-chi/chi/v5"
+)
+
+// PromoteWaitlisted takes a player off the waitlist, typically into the
+// place of someone who didn't show up (see engine.PromoteWaitlisted). Min
+// tier: Co-organizer.
+func (h *TournamentHandler) PromoteWaitlisted(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	regID, _ := strconv.ParseInt(chi.URLParam(r, "regID"), 10, 64)
+	if !middleware.AuthorizeTournament(w, r, h.DB, id, models.TierCoOrganizer) {
+		return
+	}
+	if _, err := engine.PromoteWaitlisted(r.Context(), h.DB, id, regID); err != nil {
+		switch {
+		case errors.Is(err, db.ErrNotWaitlisted), errors.Is(err, engine.ErrWaitlistClosed):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		default:
+			http.Error(w, "Failed to promote player", http.StatusInternalServerError)
+		}
+		return
+	}
+	http.Redirect(w, r, fmt.Sprintf("/tournaments/%d/manage/players", id), http.StatusSeeOther)
+}
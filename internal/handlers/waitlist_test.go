@@ -0,0 +1,60 @@
+//go:build integration
+
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+func TestTournamentHandler_Waitlist(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	h := &TournamentHandler{DB: database, Tmpl: &mockTemplate{}}
+	owner := mustCreateUser(t, database, "owner-waitlist@example.com", "OwnerWaitlist")
+	tourn := mustCreateTournament(t, database, owner.ID, models.TournamentStatusRegistrationOpen)
+	tourn.MaxPlayers = 1
+	if err := db.UpdateTournament(ctx, database, tourn); err != nil {
+		t.Fatal(err)
+	}
+	params := map[string]string{"id": strconv.FormatInt(tourn.ID, 10)}
+	first := mustCreateUser(t, database, "first-waitlist@example.com", "FirstWaitlist")
+	second := mustCreateUser(t, database, "second-waitlist@example.com", "SecondWaitlist")
+	for _, u := range []*models.User{first, second} {
+		h.Register(httptest.NewRecorder(), requestWithUser("POST", "/", "", u, params))
+	}
+	waiting, err := db.GetRegistration(ctx, database, tourn.ID, second.ID)
+	if err != nil || waiting.Status != models.RegistrationStatusWaitlisted {
+		t.Fatalf("second sign-up = %+v, %v; want waitlisted", waiting, err)
+	}
+
+	tmpl := &mockTemplate{}
+	h.Tmpl = tmpl
+	h.Detail(httptest.NewRecorder(), requestWithUser("GET", "/", "", second, params))
+	if got := tmpl.calls[0].Data.(map[string]interface{})["WaitlistPosition"]; got != 1 {
+		t.Errorf("WaitlistPosition = %v, want 1", got)
+	}
+
+	promoteParams := map[string]string{"id": params["id"], "regID": strconv.FormatInt(waiting.ID, 10)}
+	rec := httptest.NewRecorder()
+	h.PromoteWaitlisted(rec, requestWithUser("POST", "/", "", owner, promoteParams))
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("promote: status %d body %s", rec.Code, rec.Body.String())
+	}
+	if got, _ := db.GetRegistrationByID(ctx, database, waiting.ID); got.Status != models.RegistrationStatusConfirmed {
+		t.Errorf("status = %q, want confirmed", got.Status)
+	}
+
+	db.UpdateTournamentStatus(ctx, database, tourn.ID, models.TournamentStatusInProgress)
+	rec = httptest.NewRecorder()
+	h.PromoteWaitlisted(rec, requestWithUser("POST", "/", "", owner, promoteParams))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("after the start: status %d, want 400", rec.Code)
+	}
+}
@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/dstathis/openswiss/internal/auth"
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/middleware"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/dstathis/openswiss/internal/webhook"
+	"github.com/go-chi/chi/v5"
+)
+
+// recentDeliveries is how many deliveries the webhooks page lists.
+const recentDeliveries = 20
+
+// WebhooksPage lists the tournament's webhooks and their recent deliveries.
+// Min tier: Co-organizer.
+func (h *TournamentHandler) WebhooksPage(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if !middleware.AuthorizeTournament(w, r, h.DB, id, models.TierCoOrganizer) {
+		return
+	}
+	t, err := db.GetTournament(r.Context(), h.DB, id)
+	if err != nil {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+	hooks, _ := db.ListWebhooks(r.Context(), h.DB, id)
+	deliveries, _ := db.ListWebhookDeliveries(r.Context(), h.DB, id, recentDeliveries)
+	h.Tmpl.ExecuteTemplate(w, "tournament_webhooks.html", map[string]interface{}{
+		"User":       middleware.GetUser(r.Context()),
+		"CSRFToken":  middleware.CSRFToken(r),
+		"Theme":      middleware.Theme(r),
+		"Flash":      middleware.Flash(r),
+		"Lang":       middleware.Locale(r),
+		"Tournament": t,
+		"Webhooks":   hooks,
+		"Deliveries": deliveries,
+		"Events":     models.WebhookEvents,
+	})
+}
+
+// AddWebhook registers a webhook. Form fields: url, and one event field per
+// subscribed event type. Min tier: Co-organizer.
+func (h *TournamentHandler) AddWebhook(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if !middleware.AuthorizeTournament(w, r, h.DB, id, models.TierCoOrganizer) {
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+	hookURL := strings.TrimSpace(r.FormValue("url"))
+	if err := webhook.ValidateURL(hookURL); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	events := r.Form["event"]
+	if len(events) == 0 {
+		http.Error(w, "Pick at least one event", http.StatusBadRequest)
+		return
+	}
+	for _, e := range events {
+		if !models.ValidWebhookEvent(e) {
+			http.Error(w, fmt.Sprintf("Unknown event %q", e), http.StatusBadRequest)
+			return
+		}
+	}
+	hooks, err := db.ListWebhooks(r.Context(), h.DB, id)
+	if err != nil {
+		http.Error(w, "Failed to add webhook", http.StatusInternalServerError)
+		return
+	}
+	if len(hooks) >= models.MaxWebhooks {
+		http.Error(w, fmt.Sprintf("A tournament can have at most %d webhooks", models.MaxWebhooks), http.StatusConflict)
+		return
+	}
+	secret, err := auth.GenerateWebhookSecret()
+	if err != nil {
+		http.Error(w, "Failed to add webhook", http.StatusInternalServerError)
+		return
+	}
+	user := middleware.GetUser(r.Context())
+	wh := &models.Webhook{TournamentID: id, URL: hookURL, Secret: secret, Events: events, CreatedBy: &user.ID}
+	if err := db.CreateWebhook(r.Context(), h.DB, wh); err != nil {
+		http.Error(w, "Failed to add webhook", http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, r, fmt.Sprintf("/tournaments/%d/webhooks", id), http.StatusSeeOther)
+}
+
+// RemoveWebhook deletes a webhook along with its queued deliveries.
+// Min tier: Co-organizer.
+func (h *TournamentHandler) RemoveWebhook(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	webhookID, err := strconv.ParseInt(chi.URLParam(r, "webhookID"), 10, 64)
+	if err != nil {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+	if !middleware.AuthorizeTournament(w, r, h.DB, id, models.TierCoOrganizer) {
+		return
+	}
+	if err := db.DeleteWebhook(r.Context(), h.DB, id, webhookID); err != nil {
+		if errors.Is(err, db.ErrWebhookNotFound) {
+			http.Error(w, "Not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Failed to remove webhook", http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, r, fmt.Sprintf("/tournaments/%d/webhooks", id), http.StatusSeeOther)
+}
@@ -0,0 +1,65 @@
+//go:build integration
+
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+func TestTournamentHandler_Webhooks(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	tmpl := &mockTemplate{}
+	h := &TournamentHandler{DB: database, Tmpl: tmpl}
+	owner := mustCreateUser(t, database, "owner-wh@example.com", "OwnerWH")
+	judge := mustCreateUser(t, database, "judge-wh@example.com", "JudgeWH")
+	tourn := mustCreateTournament(t, database, owner.ID, models.TournamentStatusScheduled)
+	if err := db.AddTournamentStaff(ctx, database, &models.TournamentStaff{
+		TournamentID: tourn.ID, UserID: judge.ID, Tier: models.TierJudge,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	params := map[string]string{"id": strconv.FormatInt(tourn.ID, 10)}
+	form := url.Values{"url": {"https://example.com/hook"}, "event": {models.WebhookResultEntered}}
+
+	rec := httptest.NewRecorder()
+	h.AddWebhook(rec, requestWithUser("POST", "/", form.Encode(), judge, params))
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("judge add: status %d, want 403", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	h.AddWebhook(rec, requestWithUser("POST", "/", url.Values{"url": {"https://example.com/hook"}}.Encode(), owner, params))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("no events: status %d, want 400", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	h.AddWebhook(rec, requestWithUser("POST", "/", form.Encode(), owner, params))
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("add: status %d body %s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	h.WebhooksPage(rec, requestWithUser("GET", "/", "", owner, params))
+	hooks := tmpl.calls[0].Data.(map[string]interface{})["Webhooks"].([]models.Webhook)
+	if len(hooks) != 1 || hooks[0].Secret == "" || len(hooks[0].Events) != 1 || hooks[0].Events[0] != models.WebhookResultEntered {
+		t.Fatalf("webhooks = %+v", hooks)
+	}
+
+	removeParams := map[string]string{"id": params["id"], "webhookID": strconv.FormatInt(hooks[0].ID, 10)}
+	rec = httptest.NewRecorder()
+	h.RemoveWebhook(rec, requestWithUser("POST", "/", "", owner, removeParams))
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("remove: status %d", rec.Code)
+	}
+	if hooks, _ = db.ListWebhooks(ctx, database, tourn.ID); len(hooks) != 0 {
+		t.Errorf("webhooks after remove = %+v", hooks)
+	}
+}
@@ -0,0 +1,117 @@
+// Package i18n translates the player-facing pages. Messages are keyed by
+// their English text: the catalogs in locales/ map that text to another
+// language, and a message missing from a catalog is shown in English.
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Default is the locale templates are written in; it has no catalog.
+const Default = "en"
+
+// Supported lists the locales pages can be served in, Default first.
+var Supported = []string{Default, "de", "es"}
+
+//go:embed locales/*.json
+var localeFS embed.FS
+
+// catalogs maps a locale to its messages, English text to translation.
+var catalogs = mustLoadCatalogs()
+
+func mustLoadCatalogs() map[string]map[string]string {
+	out := map[string]map[string]string{}
+	for _, lang := range Supported[1:] {
+		b, err := localeFS.ReadFile(path.Join("locales", lang+".json"))
+		if err != nil {
+			panic(fmt.Sprintf("i18n: %v", err))
+		}
+		var c map[string]string
+		if err := json.Unmarshal(b, &c); err != nil {
+			panic(fmt.Sprintf("i18n: parse %s catalog: %v", lang, err))
+		}
+		out[lang] = c
+	}
+	return out
+}
+
+// Valid reports whether lang is a supported locale.
+func Valid(lang string) bool {
+	for _, l := range Supported {
+		if l == lang {
+			return true
+		}
+	}
+	return false
+}
+
+// Catalog returns lang's messages, nil for Default or an unknown locale.
+func Catalog(lang string) map[string]string {
+	return catalogs[lang]
+}
+
+// T translates msg into lang, falling back to msg itself. With args, the
+// translation is a format string for fmt.Sprintf.
+func T(lang, msg string, args ...interface{}) string {
+	if s, ok := catalogs[lang][msg]; ok && s != "" {
+		msg = s
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(msg, args...)
+	}
+	return msg
+}
+
+// dateTimeLayouts are the time.Format layouts for a date with a time of day.
+var dateTimeLayouts = map[string]string{
+	Default: "Jan 2, 2006 3:04 PM",
+	"de":    "02.01.2006, 15:04",
+	"es":    "02/01/2006 15:04",
+}
+
+// FormatDateTime formats t the way lang writes a date and time.
+func FormatDateTime(lang string, t time.Time) string {
+	layout, ok := dateTimeLayouts[lang]
+	if !ok {
+		layout = dateTimeLayouts[Default]
+	}
+	return t.Format(layout)
+}
+
+// Match picks the supported locale an Accept-Language header prefers,
+// comparing primary language subtags only ("de-AT" is "de"). Default if
+// nothing matches.
+func Match(acceptLanguage string) string {
+	type choice struct {
+		lang string
+		q    float64
+	}
+	var choices []choice
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = f
+		}
+		lang, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(tag)), "-")
+		if q > 0 && Valid(lang) {
+			choices = append(choices, choice{lang, q})
+		}
+	}
+	sort.SliceStable(choices, func(i, j int) bool { return choices[i].q > choices[j].q })
+	if len(choices) == 0 {
+		return Default
+	}
+	return choices[0].lang
+}
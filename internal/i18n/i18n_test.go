@@ -0,0 +1,93 @@
+package i18n
+
+import (
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestMatch(t *testing.T) {
+	for header, want := range map[string]string{
+		"":                             "en",
+		"*":                            "en",
+		"fr-FR, fr;q=0.9":              "en",
+		"de-AT":                        "de",
+		"ES":                           "es",
+		"en-US,en;q=0.9,de;q=0.8":      "en",
+		"fr;q=1, es;q=0.4, de;q=0.7":   "de",
+		"de;q=0, es":                   "es",
+		"de;q=banana, es;q=0.1":        "es",
+		"es-MX;q=0.5, de-CH;q=0.5, en": "en",
+	} {
+		if got := Match(header); got != want {
+			t.Errorf("Match(%q) = %q, want %q", header, got, want)
+		}
+	}
+}
+
+func TestT(t *testing.T) {
+	if got := T("de", "Standings"); got != "Tabelle" {
+		t.Errorf("T(de, Standings) = %q", got)
+	}
+	if got := T("es", "Round %d", 3); got != "Ronda 3" {
+		t.Errorf("T(es, Round %%d) = %q", got)
+	}
+	if got := T("en", "Round %d", 3); got != "Round 3" {
+		t.Errorf("T(en, Round %%d) = %q", got)
+	}
+	if got := T("de", "Not in any catalog"); got != "Not in any catalog" {
+		t.Errorf("missing message = %q", got)
+	}
+	if got := T("fr", "Standings"); got != "Standings" {
+		t.Errorf("unknown locale = %q", got)
+	}
+}
+
+func TestFormatDateTime(t *testing.T) {
+	at := time.Date(2026, 3, 7, 18, 30, 0, 0, time.UTC)
+	for lang, want := range map[string]string{
+		"en": "Mar 7, 2026 6:30 PM",
+		"de": "07.03.2026, 18:30",
+		"es": "07/03/2026 18:30",
+		"fr": "Mar 7, 2026 6:30 PM",
+	} {
+		if got := FormatDateTime(lang, at); got != want {
+			t.Errorf("FormatDateTime(%s) = %q, want %q", lang, got, want)
+		}
+	}
+}
+
+var verbs = regexp.MustCompile(`%[a-z]`)
+
+// Every catalog translates the same messages, none empty, each keeping the
+// formatting verbs of its English text in order.
+func TestCatalogs(t *testing.T) {
+	de := Catalog("de")
+	for _, lang := range Supported[1:] {
+		c := Catalog(lang)
+		if len(c) != len(de) {
+			t.Errorf("%s has %d messages, de has %d", lang, len(c), len(de))
+		}
+		for msg, tr := range c {
+			if _, ok := de[msg]; !ok {
+				t.Errorf("%s translates %q, which de doesn't", lang, msg)
+			}
+			if tr == "" {
+				t.Errorf("%s: empty translation of %q", lang, msg)
+			}
+			if want, got := verbs.FindAllString(msg, -1), verbs.FindAllString(tr, -1); len(want) != len(got) {
+				t.Errorf("%s: %q has verbs %v, translation %q has %v", lang, msg, want, tr, got)
+			} else {
+				for i := range want {
+					if want[i] != got[i] {
+						t.Errorf("%s: %q has verbs %v, translation %q has %v", lang, msg, want, tr, got)
+						break
+					}
+				}
+			}
+		}
+	}
+	if Catalog(Default) != nil {
+		t.Error("English has a catalog")
+	}
+}
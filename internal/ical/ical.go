@@ -0,0 +1,127 @@
+// Package ical writes iCalendar (RFC 5545) feeds, for the round schedule
+// players subscribe to so each round's start shows up in their phones'
+// calendars. It only does what that needs: a calendar of timed events,
+// with times in UTC.
+package ical
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Event is a VEVENT of a Calendar.
+type Event struct {
+	// UID identifies the event across fetches of the feed, so a calendar
+	// updates it rather than adding a copy.
+	UID     string
+	Start   time.Time
+	End     time.Time // zero for an event that is only a point in time
+	Summary string
+	// Location and URL are left out when empty.
+	Location string
+	URL      string
+}
+
+// Calendar is a feed of events.
+type Calendar struct {
+	Name string // shown by calendars as the subscription's name
+	// Refresh suggests how often subscribers fetch the feed again; zero
+	// leaves it to them.
+	Refresh time.Duration
+	// Stamp is when the feed was made: every event's DTSTAMP.
+	Stamp  time.Time
+	Events []Event
+}
+
+// maxLine is the longest a content line may be, in bytes, before it is
+// folded.
+const maxLine = 75
+
+// Write writes c to w as an iCalendar object.
+func (c *Calendar) Write(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	line := func(name, value string) {
+		fold(bw, name+":"+value)
+	}
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", "-//OpenSwiss//Schedule//EN")
+	line("CALSCALE", "GREGORIAN")
+	line("METHOD", "PUBLISH")
+	if c.Name != "" {
+		line("X-WR-CALNAME", escape(c.Name))
+	}
+	if c.Refresh > 0 {
+		d := duration(c.Refresh)
+		line("REFRESH-INTERVAL;VALUE=DURATION", d)
+		line("X-PUBLISHED-TTL", d)
+	}
+	for _, e := range c.Events {
+		line("BEGIN", "VEVENT")
+		line("UID", escape(e.UID))
+		line("DTSTAMP", utc(c.Stamp))
+		line("DTSTART", utc(e.Start))
+		if !e.End.IsZero() {
+			line("DTEND", utc(e.End))
+		}
+		line("SUMMARY", escape(e.Summary))
+		if e.Location != "" {
+			line("LOCATION", escape(e.Location))
+		}
+		if e.URL != "" {
+			line("URL", e.URL)
+		}
+		line("END", "VEVENT")
+	}
+	line("END", "VCALENDAR")
+	return bw.Flush()
+}
+
+// utc formats t as a UTC date-time.
+func utc(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// duration formats d, rounded down to the minute, as a duration value such
+// as PT1H30M.
+func duration(d time.Duration) string {
+	m := int(d / time.Minute)
+	if m < 1 {
+		m = 1
+	}
+	var b strings.Builder
+	b.WriteString("PT")
+	if h := m / 60; h > 0 {
+		b.WriteString(strconv.Itoa(h) + "H")
+	}
+	if m%60 > 0 {
+		b.WriteString(strconv.Itoa(m%60) + "M")
+	}
+	return b.String()
+}
+
+// escape escapes a TEXT value: backslashes, semicolons, commas and line
+// breaks.
+var escape = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`).Replace
+
+// fold writes a content line ending in CRLF, breaking it before maxLine
+// bytes with CRLF and a space, and never inside a UTF-8 sequence.
+func fold(w *bufio.Writer, s string) {
+	limit := maxLine
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		w.WriteString(s[:cut])
+		w.WriteString("\r\n ")
+		s = s[cut:]
+		limit = maxLine - 1 // the space starts the next line
+	}
+	w.WriteString(s)
+	w.WriteString("\r\n")
+}
@@ -0,0 +1,58 @@
+package ical
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCalendarWrite(t *testing.T) {
+	start := time.Date(2026, 6, 15, 10, 0, 0, 0, time.FixedZone("CEST", 2*60*60))
+	c := &Calendar{
+		Name:    "Spring Open, Berlin",
+		Refresh: 90 * time.Minute,
+		Stamp:   time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC),
+		Events: []Event{
+			{UID: "1@swiss.example.com", Start: start, End: start.Add(time.Hour), Summary: "Round 1; seats\nposted", Location: "Hall", URL: "https://swiss.example.com/tournaments/1"},
+			{UID: "2@swiss.example.com", Start: start.Add(time.Hour), Summary: strings.Repeat("ä", 50)},
+		},
+	}
+	var buf bytes.Buffer
+	if err := c.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"BEGIN:VCALENDAR\r\nVERSION:2.0\r\n",
+		"X-WR-CALNAME:Spring Open\\, Berlin\r\n",
+		"REFRESH-INTERVAL;VALUE=DURATION:PT1H30M\r\n",
+		"DTSTAMP:20260601T120000Z\r\nDTSTART:20260615T080000Z\r\nDTEND:20260615T090000Z\r\n",
+		"SUMMARY:Round 1\\; seats\\nposted\r\n",
+		"DTSTART:20260615T090000Z\r\nSUMMARY:",
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in\n%s", want, out)
+		}
+	}
+	if strings.Count(out, "BEGIN:VEVENT") != 2 || strings.Count(out, "DTEND") != 1 {
+		t.Errorf("events:\n%s", out)
+	}
+
+	// Long lines fold at 75 bytes without splitting a character.
+	var unfolded strings.Builder
+	for _, l := range strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n") {
+		if len(l) > 75 {
+			t.Errorf("line of %d bytes: %q", len(l), l)
+		}
+		if strings.HasPrefix(l, " ") {
+			unfolded.WriteString(l[1:])
+			continue
+		}
+		unfolded.WriteString("\n" + l)
+	}
+	if !strings.Contains(unfolded.String(), "\nSUMMARY:"+strings.Repeat("ä", 50)+"\n") {
+		t.Errorf("folded summary doesn't unfold:\n%s", out)
+	}
+}
@@ -20,6 +20,7 @@ type Collector struct {
 	statusCounts     sync.Map // status code (int) -> *atomic.Int64
 	routeCounts      sync.Map // method+path pattern (string) -> *atomic.Int64
 	totalRequestSize atomic.Int64
+	panics           atomic.Int64
 }
 
 // New creates a new metrics collector.
@@ -76,6 +77,11 @@ func (c *Collector) Wrap(next http.Handler) http.Handler {
 	})
 }
 
+// RecordPanic counts a panic recovered from a handler.
+func (c *Collector) RecordPanic() {
+	c.panics.Add(1)
+}
+
 // Snapshot holds a point-in-time view of the collected metrics.
 type Snapshot struct {
 	Uptime           string           `json:"uptime"`
@@ -86,6 +92,7 @@ type Snapshot struct {
 	StatusCounts     map[string]int64 `json:"status_counts"`
 	TopRoutes        []RouteCount     `json:"top_routes"`
 	TotalRequestSize int64            `json:"total_request_bytes"`
+	Panics           int64            `json:"panics"`
 	Go               GoMetrics        `json:"go"`
 }
 
@@ -145,6 +152,7 @@ func (c *Collector) Snapshot() Snapshot {
 		StatusCounts:     statusCounts,
 		TopRoutes:        routes,
 		TotalRequestSize: c.totalRequestSize.Load(),
+		Panics:           c.panics.Load(),
 		Go: GoMetrics{
 			Goroutines: runtime.NumGoroutine(),
 			HeapAlloc:  mem.HeapAlloc,
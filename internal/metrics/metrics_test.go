@@ -169,6 +169,15 @@ func TestCollector_Snapshot_AvgResponseMs(t *testing.T) {
 	}
 }
 
+func TestCollector_RecordPanic(t *testing.T) {
+	c := New()
+	c.RecordPanic()
+	c.RecordPanic()
+	if got := c.Snapshot().Panics; got != 2 {
+		t.Errorf("expected 2 panics, got %d", got)
+	}
+}
+
 func TestCollector_Handler_ReturnsJSON(t *testing.T) {
 	c := New()
 	h := c.Handler()
@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// captureLog sends the default logger to a buffer for the rest of the test.
+func captureLog(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	old := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&buf, nil)))
+	t.Cleanup(func() { slog.SetDefault(old) })
+	return &buf
+}
+
+func TestAccessLog_LogsStatusAndSize(t *testing.T) {
+	buf := captureLog(t)
+	h := AccessLog(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "nope", http.StatusTeapot)
+	}))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/tournaments/7/start", nil))
+
+	var got map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("not one JSON line: %v\n%s", err, buf.String())
+	}
+	if got["msg"] != "request" || got["method"] != "POST" || got["path"] != "/tournaments/7/start" {
+		t.Errorf("unexpected line: %v", got)
+	}
+	if got["status"] != float64(http.StatusTeapot) {
+		t.Errorf("status = %v, want 418", got["status"])
+	}
+	if got["bytes"] != float64(len("nope\n")) {
+		t.Errorf("bytes = %v, want %d", got["bytes"], len("nope\n"))
+	}
+}
+
+func TestAccessLog_ImplicitOK(t *testing.T) {
+	buf := captureLog(t)
+	AccessLog(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})).
+		ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	var got map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got["status"] != float64(http.StatusOK) {
+		t.Errorf("status = %v, want 200", got["status"])
+	}
+}
+
+func TestAccessLog_SkipsNoise(t *testing.T) {
+	buf := captureLog(t)
+	h := AccessLog(http.NotFoundHandler())
+	for _, path := range []string{"/healthz", "/readyz", "/metrics", "/static/style.css"} {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+	}
+	if buf.Len() != 0 {
+		t.Errorf("expected no log lines, got %s", buf.String())
+	}
+}
@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+// AllowIPs refuses requests whose client IP (see ClientIP) is outside
+// allowed with 403, e.g. to keep the admin pages and the login form to a
+// venue's LAN when the server is reachable from the internet. Behind a
+// proxy it relies on RealIP's trusted proxies, or every request comes from
+// the proxy. An empty allowed lets everyone through.
+func AllowIPs(allowed []*net.IPNet) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if len(allowed) == 0 {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !inNets(ClientIP(r), allowed) {
+				if strings.HasPrefix(r.URL.Path, "/api/") {
+					http.Error(w, `{"error":"forbidden"}`, http.StatusForbidden)
+				} else {
+					http.Error(w, "Forbidden", http.StatusForbidden)
+				}
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
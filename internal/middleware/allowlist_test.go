@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAllowIPs(t *testing.T) {
+	allowed, err := ParseTrustedProxies("192.168.1.0/24, 2001:db8::1")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	tests := []struct {
+		nets   string
+		remote string
+		path   string
+		status int
+		body   string
+	}{
+		{"allowed", "192.168.1.20:5000", "/admin/users", http.StatusOK, ""},
+		{"allowed", "[2001:db8::1]:5000", "/login", http.StatusOK, ""},
+		{"allowed", "203.0.113.5:5000", "/admin/users", http.StatusForbidden, "Forbidden\n"},
+		{"allowed", "203.0.113.5:5000", "/api/v1/admin/users", http.StatusForbidden, "{\"error\":\"forbidden\"}\n"},
+		{"none", "203.0.113.5:5000", "/admin/users", http.StatusOK, ""},
+	}
+	for _, tt := range tests {
+		nets := allowed
+		if tt.nets == "none" {
+			nets = nil
+		}
+		req := httptest.NewRequest("GET", tt.path, nil)
+		req.RemoteAddr = tt.remote
+		rec := httptest.NewRecorder()
+		RealIP(nil)(AllowIPs(nets)(ok)).ServeHTTP(rec, req)
+		if rec.Code != tt.status || rec.Body.String() != tt.body {
+			t.Errorf("%s from %s with %s: %d %q, want %d %q", tt.path, tt.remote, tt.nets, rec.Code, rec.Body.String(), tt.status, tt.body)
+		}
+	}
+}
+
+func TestAllowIPs_ForwardedByTrustedProxy(t *testing.T) {
+	allowed, _ := ParseTrustedProxies("192.168.1.0/24")
+	proxies, _ := ParseTrustedProxies("10.0.0.2")
+	h := RealIP(proxies)(AllowIPs(allowed)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})))
+	for forwarded, want := range map[string]int{"192.168.1.20": http.StatusOK, "203.0.113.5": http.StatusForbidden} {
+		req := httptest.NewRequest("GET", "/login", nil)
+		req.RemoteAddr = "10.0.0.2:5000"
+		req.Header.Set("X-Forwarded-For", forwarded)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != want {
+			t.Errorf("forwarded for %s: status %d, want %d", forwarded, rec.Code, want)
+		}
+	}
+}
@@ -46,7 +46,8 @@ func SessionAuth(database *sql.DB) func(http.Handler) http.Handler {
 	}
 }
 
-// APIKeyAuth populates the user from Bearer token (for REST API).
+// APIKeyAuth populates the user from Bearer token (for REST API). A
+// read-scoped key is refused with 403 on anything but a safe method.
 func APIKeyAuth(database *sql.DB) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -79,6 +80,10 @@ func APIKeyAuth(database *sql.DB) func(http.Handler) http.Handler {
 					if err != nil {
 						break
 					}
+					if k.Scope == models.APIKeyScopeRead && !safeMethod(r.Method) {
+						http.Error(w, `{"error":"this API key is read-only"}`, http.StatusForbidden)
+						return
+					}
 					ctx := context.WithValue(r.Context(), UserContextKey, user)
 					next.ServeHTTP(w, r.WithContext(ctx))
 					return
@@ -89,6 +94,11 @@ func APIKeyAuth(database *sql.DB) func(http.Handler) http.Handler {
 	}
 }
 
+// safeMethod reports whether method only reads.
+func safeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
 // RequireAuth returns 401/redirect if no user is authenticated.
 func RequireAuth(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -191,7 +191,7 @@ func TestAPIKeyAuth_ValidKey(t *testing.T) {
 	user, _ := db.CreateUser(ctx, database, "u@example.com", "U", "hash")
 	full, prefix, _ := auth.GenerateAPIKey()
 	hash, _ := auth.HashAPIKey(full)
-	if _, err := db.CreateAPIKey(ctx, database, user.ID, hash, prefix, "Test", nil); err != nil {
+	if _, err := db.CreateAPIKey(ctx, database, user.ID, hash, prefix, "Test", models.APIKeyScopeReadWrite, nil); err != nil {
 		t.Fatalf("create key: %v", err)
 	}
 
@@ -211,13 +211,38 @@ func TestAPIKeyAuth_ValidKey(t *testing.T) {
 	}
 }
 
+func TestAPIKeyAuth_ReadScope(t *testing.T) {
+	database := testDB(t)
+	ctx := context.Background()
+	user, _ := db.CreateUser(ctx, database, "reader@example.com", "Reader", "hash")
+	full, prefix, hash, _ := auth.NewAPIKey()
+	if _, err := db.CreateAPIKey(ctx, database, user.ID, hash, prefix, "Reader", models.APIKeyScopeRead, nil); err != nil {
+		t.Fatalf("create key: %v", err)
+	}
+
+	handler := APIKeyAuth(database)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if GetUser(r.Context()) == nil {
+			t.Error("read key not authenticated")
+		}
+	}))
+	for method, want := range map[string]int{"GET": http.StatusOK, "HEAD": http.StatusOK, "POST": http.StatusForbidden, "PUT": http.StatusForbidden, "DELETE": http.StatusForbidden} {
+		req := httptest.NewRequest(method, "/api/v1/tournaments/1/rounds/current/results", nil)
+		req.Header.Set("Authorization", "Bearer "+full)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != want {
+			t.Errorf("%s: status %d, want %d", method, rec.Code, want)
+		}
+	}
+}
+
 func TestAPIKeyAuth_BadKeyMatchingPrefix(t *testing.T) {
 	database := testDB(t)
 	ctx := context.Background()
 	user, _ := db.CreateUser(ctx, database, "u@example.com", "U", "hash")
 	full, prefix, _ := auth.GenerateAPIKey()
 	hash, _ := auth.HashAPIKey(full)
-	if _, err := db.CreateAPIKey(ctx, database, user.ID, hash, prefix, "Test", nil); err != nil {
+	if _, err := db.CreateAPIKey(ctx, database, user.ID, hash, prefix, "Test", models.APIKeyScopeReadWrite, nil); err != nil {
 		t.Fatalf("create key: %v", err)
 	}
 
@@ -0,0 +1,22 @@
+package middleware
+
+import "net/http"
+
+// Chain is a stack of middleware, outermost first. Routes registered under
+// the same chain get the same treatment; see routes.go for the server's.
+type Chain []func(http.Handler) http.Handler
+
+// Append returns a new chain running c and then mws, leaving c as it was,
+// so a narrower chain can build on a wider one.
+func (c Chain) Append(mws ...func(http.Handler) http.Handler) Chain {
+	out := make(Chain, 0, len(c)+len(mws))
+	return append(append(out, c...), mws...)
+}
+
+// Then wraps h in the chain: the first middleware sees the request first.
+func (c Chain) Then(h http.Handler) http.Handler {
+	for i := len(c) - 1; i >= 0; i-- {
+		h = c[i](h)
+	}
+	return h
+}
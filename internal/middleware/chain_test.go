@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// tag is a middleware that appends name to the X-Order header on the way in.
+func tag(name string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("X-Order", name)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func TestChain_ThenRunsOutermostFirst(t *testing.T) {
+	h := Chain{tag("a"), tag("b")}.Append(tag("c")).Then(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("X-Order", "handler")
+	}))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if got := strings.Join(rec.Header().Values("X-Order"), ","); got != "a,b,c,handler" {
+		t.Errorf("order = %s, want a,b,c,handler", got)
+	}
+}
+
+func TestChain_AppendLeavesBaseAlone(t *testing.T) {
+	base := make(Chain, 1, 4)
+	base[0] = tag("base")
+	one := base.Append(tag("one"))
+	two := base.Append(tag("two"))
+	if len(base) != 1 {
+		t.Fatalf("base grew to %d", len(base))
+	}
+
+	rec := httptest.NewRecorder()
+	one.Then(http.NotFoundHandler()).ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if got := strings.Join(rec.Header().Values("X-Order"), ","); got != "base,one" {
+		t.Errorf("first chain ran %s, want base,one (a later Append overwrote it)", got)
+	}
+	rec = httptest.NewRecorder()
+	two.Then(http.NotFoundHandler()).ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if got := strings.Join(rec.Header().Values("X-Order"), ","); got != "base,two" {
+		t.Errorf("second chain ran %s, want base,two", got)
+	}
+}
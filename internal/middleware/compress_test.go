@@ -0,0 +1,131 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+var standingsPage = "<table>" + strings.Repeat("<tr><td>Player</td><td>9</td></tr>", 200) + "</table>"
+
+func compressGet(t *testing.T, h http.Handler, acceptEncoding string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest("GET", "/tournaments/1", nil)
+	if acceptEncoding != "" {
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+	}
+	rec := httptest.NewRecorder()
+	Compress(h).ServeHTTP(rec, req)
+	return rec
+}
+
+func gunzip(t *testing.T, b []byte) string {
+	t.Helper()
+	zr, err := gzip.NewReader(strings.NewReader(string(b)))
+	if err != nil {
+		t.Fatalf("not gzip: %v", err)
+	}
+	out, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatalf("gunzip: %v", err)
+	}
+	return string(out)
+}
+
+func TestCompress_HTML(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("ETag", `"abc"`)
+		_, _ = io.WriteString(w, standingsPage)
+	})
+	rec := compressGet(t, h, "br, gzip;q=0.8")
+	if rec.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatalf("Content-Encoding = %q", rec.Header().Get("Content-Encoding"))
+	}
+	if rec.Body.Len() >= len(standingsPage) {
+		t.Errorf("compressed %d bytes to %d", len(standingsPage), rec.Body.Len())
+	}
+	if got := gunzip(t, rec.Body.Bytes()); got != standingsPage {
+		t.Error("body changed by the round trip")
+	}
+	if rec.Header().Get("Vary") != "Accept-Encoding" {
+		t.Errorf("Vary = %q", rec.Header().Get("Vary"))
+	}
+	if rec.Header().Get("ETag") != `W/"abc"` {
+		t.Errorf("ETag = %q, want weak", rec.Header().Get("ETag"))
+	}
+
+	// Without gzip in Accept-Encoding the page goes as it is.
+	for _, ae := range []string{"", "br", "gzip;q=0", "*;q=0.0"} {
+		rec = compressGet(t, h, ae)
+		if rec.Header().Get("Content-Encoding") != "" || rec.Body.String() != standingsPage {
+			t.Errorf("Accept-Encoding %q: compressed anyway", ae)
+		}
+		if rec.Header().Get("Vary") != "Accept-Encoding" {
+			t.Errorf("Accept-Encoding %q: Vary missing", ae)
+		}
+	}
+}
+
+func TestCompress_SniffedJSONAndErrors(t *testing.T) {
+	// No Content-Type set: sniffed as text, so compressed.
+	rec := compressGet(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, standingsPage)
+	}), "gzip")
+	if rec.Header().Get("Content-Encoding") != "gzip" || gunzip(t, rec.Body.Bytes()) != standingsPage {
+		t.Error("sniffed HTML not compressed")
+	}
+
+	rec = compressGet(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = io.WriteString(w, `{"error": "not found"}`)
+	}), "gzip")
+	if rec.Code != http.StatusNotFound || gunzip(t, rec.Body.Bytes()) != `{"error": "not found"}` {
+		t.Errorf("JSON error: status %d", rec.Code)
+	}
+}
+
+func TestCompress_PassesThrough(t *testing.T) {
+	for name, h := range map[string]http.HandlerFunc{
+		"pdf": func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/pdf")
+			_, _ = io.WriteString(w, standingsPage)
+		},
+		"small": func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/css")
+			w.Header().Set("Content-Length", strconv.Itoa(len("body{}")))
+			_, _ = io.WriteString(w, "body{}")
+		},
+		"not modified": func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/html")
+			w.WriteHeader(http.StatusNotModified)
+		},
+		"encoded": func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/html")
+			w.Header().Set("Content-Encoding", "br")
+			_, _ = io.WriteString(w, "already")
+		},
+	} {
+		rec := compressGet(t, h, "gzip")
+		if enc := rec.Header().Get("Content-Encoding"); enc == "gzip" {
+			t.Errorf("%s: compressed", name)
+		}
+	}
+
+	req := httptest.NewRequest("GET", "/static/style.css", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	req.Header.Set("Range", "bytes=0-10")
+	rec := httptest.NewRecorder()
+	Compress(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/css")
+		_, _ = io.WriteString(w, standingsPage)
+	})).ServeHTTP(rec, req)
+	if rec.Header().Get("Content-Encoding") != "" {
+		t.Error("range request compressed")
+	}
+}
@@ -2,45 +2,44 @@ package middleware
 
 import (
 	"context"
-	"crypto/rand"
 	"crypto/subtle"
-	"encoding/base64"
 	"net/http"
 	"strings"
+
+	"github.com/dstathis/openswiss/internal/auth"
 )
 
 const (
 	csrfCookieName = "csrf_token"
 	csrfFieldName  = "csrf_token"
-	csrfTokenLen   = 32
+	csrfHeaderName = "X-CSRF-Token"
 )
 
 type csrfContextKey struct{}
 
-func generateCSRFToken() (string, error) {
-	b := make([]byte, csrfTokenLen)
-	if _, err := rand.Read(b); err != nil {
-		return "", err
-	}
-	return base64.RawURLEncoding.EncodeToString(b), nil
-}
-
 // CSRFProtect is middleware that sets a CSRF cookie and validates it on
-// state-changing requests (POST, PUT, PATCH, DELETE) for non-API routes.
-// API routes (starting with /api/) are excluded since they use Bearer tokens.
+// state-changing requests (POST, PUT, PATCH, DELETE). The token must come
+// back in the csrf_token form field (templates embed it in every POST form)
+// or the X-CSRF-Token header.
+//
+// API routes (starting with /api/) are only checked when the request carries
+// the browser session cookie, and then only through the header: Bearer-token
+// clients can't be driven cross-site, but a logged-in browser can.
 func CSRFProtect(secureCookie bool) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			// Skip CSRF for API routes (they use Bearer token auth)
-			if strings.HasPrefix(r.URL.Path, "/api/") {
-				next.ServeHTTP(w, r)
-				return
+			isAPI := strings.HasPrefix(r.URL.Path, "/api/")
+			if isAPI {
+				if _, err := r.Cookie("session"); err != nil {
+					next.ServeHTTP(w, r)
+					return
+				}
 			}
 
 			// Ensure a CSRF cookie exists
 			cookie, err := r.Cookie(csrfCookieName)
 			if err != nil || cookie.Value == "" {
-				token, err := generateCSRFToken()
+				token, err := auth.GenerateCSRFToken()
 				if err != nil {
 					http.Error(w, "Internal error", http.StatusInternalServerError)
 					return
@@ -49,7 +48,7 @@ func CSRFProtect(secureCookie bool) func(http.Handler) http.Handler {
 					Name:     csrfCookieName,
 					Value:    token,
 					Path:     "/",
-					HttpOnly: false, // base.html JS reads it to auto-inject the token into POST forms
+					HttpOnly: false, // page scripts read it to send X-CSRF-Token on fetch() calls
 					Secure:   secureCookie,
 					SameSite: http.SameSiteLaxMode,
 				})
@@ -63,12 +62,19 @@ func CSRFProtect(secureCookie bool) func(http.Handler) http.Handler {
 			// Validate CSRF token on state-changing methods
 			switch r.Method {
 			case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
-				formToken := r.FormValue(csrfFieldName)
-				if formToken == "" {
-					formToken = r.Header.Get("X-CSRF-Token")
+				var token string
+				if !isAPI {
+					token = r.FormValue(csrfFieldName)
+				}
+				if token == "" {
+					token = r.Header.Get(csrfHeaderName)
 				}
-				if subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(formToken)) != 1 {
-					http.Error(w, "Forbidden — invalid CSRF token", http.StatusForbidden)
+				if subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(token)) != 1 {
+					if isAPI {
+						http.Error(w, `{"error":"invalid CSRF token"}`, http.StatusForbidden)
+					} else {
+						http.Error(w, "Forbidden — invalid CSRF token", http.StatusForbidden)
+					}
 					return
 				}
 			}
@@ -192,3 +192,36 @@ func TestCSRFProtect_ValidatesDeleteMethod(t *testing.T) {
 		t.Errorf("expected 403 for DELETE without token, got %d", rec.Code)
 	}
 }
+
+func TestCSRFProtect_APIWithSessionNeedsHeader(t *testing.T) {
+	var called bool
+	handler := CSRFProtect(false)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	token := "sessiontoken"
+	form := url.Values{}
+	form.Set(csrfFieldName, token)
+
+	// A session-authenticated API call is a CSRF target; a form field won't do.
+	req := httptest.NewRequest("POST", "/api/v1/tournaments", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.AddCookie(&http.Cookie{Name: "session", Value: "s"})
+	req.AddCookie(&http.Cookie{Name: csrfCookieName, Value: token})
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if called || rec.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 without header, got %d", rec.Code)
+	}
+	if ct := rec.Body.String(); !strings.Contains(ct, `"error"`) {
+		t.Errorf("expected JSON error body, got %q", ct)
+	}
+
+	req = httptest.NewRequest("POST", "/api/v1/tournaments", nil)
+	req.Header.Set("X-CSRF-Token", token)
+	req.AddCookie(&http.Cookie{Name: "session", Value: "s"})
+	req.AddCookie(&http.Cookie{Name: csrfCookieName, Value: token})
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	if !called {
+		t.Error("handler should have been called with X-CSRF-Token header")
+	}
+}
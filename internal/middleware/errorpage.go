@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+)
+
+// ErrorPages turns the plain text errors handlers answer with http.Error
+// into a page with the site's layout: when a browser loading a page (see
+// WantsHTML) gets a 4xx or 5xx text/plain response, page is called with
+// its status and message instead. Scripts' fetch calls still get the text.
+func ErrorPages(page func(w http.ResponseWriter, r *http.Request, status int, msg string)) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !WantsHTML(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			ew := &errorPageWriter{ResponseWriter: w}
+			next.ServeHTTP(ew, r)
+			if ew.status == 0 {
+				return
+			}
+			for _, k := range []string{"Content-Type", "Content-Length"} {
+				w.Header().Del(k)
+			}
+			page(w, r, ew.status, strings.TrimSpace(ew.body.String()))
+		})
+	}
+}
+
+// errorPageWriter holds back the body of a plain text error, setting
+// status; anything else goes straight through.
+type errorPageWriter struct {
+	http.ResponseWriter
+	wroteHeader bool
+	status      int
+	body        bytes.Buffer
+}
+
+func (ew *errorPageWriter) WriteHeader(code int) {
+	if ew.wroteHeader {
+		return
+	}
+	ew.wroteHeader = true
+	if code >= 400 && strings.HasPrefix(ew.Header().Get("Content-Type"), "text/plain") {
+		ew.status = code
+		return
+	}
+	ew.ResponseWriter.WriteHeader(code)
+}
+
+func (ew *errorPageWriter) Write(b []byte) (int, error) {
+	if !ew.wroteHeader {
+		ew.WriteHeader(http.StatusOK)
+	}
+	if ew.status != 0 {
+		return ew.body.Write(b)
+	}
+	return ew.ResponseWriter.Write(b)
+}
+
+// Flush sends what has been written so far, unless it is being held back.
+func (ew *errorPageWriter) Flush() {
+	if f, ok := ew.ResponseWriter.(http.Flusher); ok && ew.status == 0 {
+		f.Flush()
+	}
+}
+
+// Unwrap lets http.ResponseController reach the writer underneath.
+func (ew *errorPageWriter) Unwrap() http.ResponseWriter {
+	return ew.ResponseWriter
+}
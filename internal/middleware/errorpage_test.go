@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestErrorPages(t *testing.T) {
+	page := func(w http.ResponseWriter, r *http.Request, status int, msg string) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(status)
+		fmt.Fprintf(w, "<p>%d: %s</p>", status, msg)
+	}
+	tests := []struct {
+		name    string
+		accept  string
+		handler http.HandlerFunc
+		status  int
+		body    string
+	}{
+		{"page", "text/html", func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "Player not found", http.StatusNotFound)
+		}, http.StatusNotFound, "<p>404: Player not found</p>"},
+		{"fetch", "*/*", func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "Player not found", http.StatusNotFound)
+		}, http.StatusNotFound, "Player not found\n"},
+		{"success", "text/html", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			fmt.Fprint(w, "ok")
+		}, http.StatusOK, "ok"},
+		{"JSON error", "text/html", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"error":"bad"}`)
+		}, http.StatusBadRequest, `{"error":"bad"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/tournaments/1", nil)
+			req.Header.Set("Accept", tt.accept)
+			rec := httptest.NewRecorder()
+			ErrorPages(page)(tt.handler).ServeHTTP(rec, req)
+			if rec.Code != tt.status || rec.Body.String() != tt.body {
+				t.Errorf("got %d %q, want %d %q", rec.Code, rec.Body.String(), tt.status, tt.body)
+			}
+		})
+	}
+}
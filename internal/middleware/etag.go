@@ -0,0 +1,127 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"strings"
+)
+
+// maxETagBody is the largest response ConditionalGet holds back to hash;
+// bigger ones are sent as they are, without an ETag.
+const maxETagBody = 4 << 20
+
+// ConditionalGet gives successful GET responses under the path prefixes an
+// ETag, a hash of the body, and answers a request whose If-None-Match
+// already has it with 304 Not Modified and no body. A page that hasn't
+// changed since a player last loaded it, such as the pairings between
+// rounds, then costs a few headers instead of the whole page. Responses
+// that set their own ETag or Cache-Control are left alone.
+func ConditionalGet(prefixes ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet || !hasPathPrefix(r.URL.Path, prefixes) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			ew := &etagWriter{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(ew, r)
+			if ew.passed {
+				return
+			}
+			h := w.Header()
+			if ew.status != http.StatusOK || h.Get("ETag") != "" || h.Get("Cache-Control") != "" {
+				w.WriteHeader(ew.status)
+				_, _ = w.Write(ew.body.Bytes())
+				return
+			}
+			sum := sha256.Sum256(ew.body.Bytes())
+			tag := `"` + base64.RawURLEncoding.EncodeToString(sum[:16]) + `"`
+			h.Set("ETag", tag)
+			// Browsers may keep the page but must ask before reusing it; a
+			// signed-in user's page is theirs alone.
+			if GetUser(r.Context()) != nil {
+				h.Set("Cache-Control", "private, no-cache")
+			} else {
+				h.Set("Cache-Control", "no-cache")
+			}
+			if etagMatch(r.Header.Get("If-None-Match"), tag) {
+				h.Del("Content-Type")
+				h.Del("Content-Length")
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write(ew.body.Bytes())
+		})
+	}
+}
+
+// NoStore marks every response Cache-Control: no-store unless its handler
+// sets its own, so neither browsers nor ConditionalGet keep a copy. The
+// server uses it in -dev mode, where pages and static files change under
+// it.
+func NoStore(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "no-store")
+		next.ServeHTTP(w, r)
+	})
+}
+
+func hasPathPrefix(path string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if path == p || strings.HasPrefix(path, p+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// etagMatch reports whether an If-None-Match header lists tag. Weak
+// validators match their strong form, as RFC 9110 asks for GET.
+func etagMatch(header, tag string) bool {
+	for _, v := range strings.Split(header, ",") {
+		v = strings.TrimPrefix(strings.TrimSpace(v), "W/")
+		if v == tag || v == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// etagWriter holds a response back so it can be hashed. Once the body
+// passes maxETagBody it gives up: what it has and the rest go straight
+// through.
+type etagWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+	passed      bool
+}
+
+func (ew *etagWriter) WriteHeader(code int) {
+	if ew.wroteHeader {
+		return
+	}
+	ew.wroteHeader = true
+	ew.status = code
+}
+
+func (ew *etagWriter) Write(b []byte) (int, error) {
+	ew.wroteHeader = true
+	if ew.passed {
+		return ew.ResponseWriter.Write(b)
+	}
+	if ew.body.Len()+len(b) <= maxETagBody {
+		return ew.body.Write(b)
+	}
+	ew.passed = true
+	ew.ResponseWriter.WriteHeader(ew.status)
+	if _, err := ew.ResponseWriter.Write(ew.body.Bytes()); err != nil {
+		return 0, err
+	}
+	ew.body = bytes.Buffer{}
+	return ew.ResponseWriter.Write(b)
+}
@@ -0,0 +1,126 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+func etagHandler(body string) http.Handler {
+	return ConditionalGet("/tournaments")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte(body))
+	}))
+}
+
+func TestConditionalGet_NotModified(t *testing.T) {
+	h := etagHandler("<p>pairings</p>")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/tournaments/1", nil))
+	tag := rec.Header().Get("ETag")
+	if rec.Code != http.StatusOK || tag == "" || rec.Body.String() != "<p>pairings</p>" {
+		t.Fatalf("first load: status %d, ETag %q, body %q", rec.Code, tag, rec.Body.String())
+	}
+	if cc := rec.Header().Get("Cache-Control"); cc != "no-cache" {
+		t.Errorf("Cache-Control = %q, want no-cache", cc)
+	}
+
+	for _, inm := range []string{tag, `"other", ` + tag, "W/" + tag, "*"} {
+		req := httptest.NewRequest("GET", "/tournaments/1", nil)
+		req.Header.Set("If-None-Match", inm)
+		rec = httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != http.StatusNotModified || rec.Body.Len() != 0 {
+			t.Errorf("If-None-Match %s: status %d, %d bytes", inm, rec.Code, rec.Body.Len())
+		}
+		if rec.Header().Get("ETag") != tag {
+			t.Errorf("If-None-Match %s: 304 without the ETag", inm)
+		}
+	}
+
+	// The page changed: the old tag gets the new page.
+	req := httptest.NewRequest("GET", "/tournaments/1", nil)
+	req.Header.Set("If-None-Match", tag)
+	rec = httptest.NewRecorder()
+	etagHandler("<p>round 2</p>").ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || rec.Body.String() != "<p>round 2</p>" || rec.Header().Get("ETag") == tag {
+		t.Errorf("changed page: status %d, ETag %q, body %q", rec.Code, rec.Header().Get("ETag"), rec.Body.String())
+	}
+}
+
+func TestConditionalGet_Skips(t *testing.T) {
+	h := etagHandler("x")
+	for _, req := range []*http.Request{
+		httptest.NewRequest("GET", "/admin", nil),
+		httptest.NewRequest("GET", "/tournamentsx", nil),
+		httptest.NewRequest("POST", "/tournaments/1", nil),
+	} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Header().Get("ETag") != "" {
+			t.Errorf("%s %s got an ETag", req.Method, req.URL.Path)
+		}
+	}
+
+	errH := ConditionalGet("/tournaments")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "Not found", http.StatusNotFound)
+	}))
+	rec := httptest.NewRecorder()
+	errH.ServeHTTP(rec, httptest.NewRequest("GET", "/tournaments/9", nil))
+	if rec.Code != http.StatusNotFound || rec.Header().Get("ETag") != "" || !strings.Contains(rec.Body.String(), "Not found") {
+		t.Errorf("404: status %d, ETag %q, body %q", rec.Code, rec.Header().Get("ETag"), rec.Body.String())
+	}
+
+	ownH := ConditionalGet("/tournaments")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "no-store")
+		_, _ = w.Write([]byte("secret"))
+	}))
+	rec = httptest.NewRecorder()
+	ownH.ServeHTTP(rec, httptest.NewRequest("GET", "/tournaments/9", nil))
+	if rec.Header().Get("ETag") != "" || rec.Header().Get("Cache-Control") != "no-store" || rec.Body.String() != "secret" {
+		t.Errorf("own Cache-Control overridden: %v %q", rec.Header(), rec.Body.String())
+	}
+}
+
+func TestConditionalGet_SignedInIsPrivate(t *testing.T) {
+	req := httptest.NewRequest("GET", "/tournaments/1", nil)
+	req = req.WithContext(context.WithValue(req.Context(), UserContextKey, &models.User{ID: 1}))
+	rec := httptest.NewRecorder()
+	etagHandler("mine").ServeHTTP(rec, req)
+	if cc := rec.Header().Get("Cache-Control"); cc != "private, no-cache" {
+		t.Errorf("Cache-Control = %q, want private, no-cache", cc)
+	}
+}
+
+func TestConditionalGet_LargeBodyPassesThrough(t *testing.T) {
+	chunk := strings.Repeat("x", 1<<20)
+	h := ConditionalGet("/tournaments")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for i := 0; i < 5; i++ {
+			_, _ = w.Write([]byte(chunk))
+		}
+	}))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/tournaments/1/scorecards", nil))
+	if rec.Code != http.StatusOK || rec.Body.Len() != 5<<20 || rec.Header().Get("ETag") != "" {
+		t.Errorf("large body: status %d, %d bytes, ETag %q", rec.Code, rec.Body.Len(), rec.Header().Get("ETag"))
+	}
+}
+
+func TestNoStore(t *testing.T) {
+	// Inside ConditionalGet, as in the server's chain: no ETag either.
+	h := ConditionalGet("/tournaments")(NoStore(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("<p>pairings</p>"))
+	})))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/tournaments/1", nil))
+	if cc := rec.Header().Get("Cache-Control"); cc != "no-store" {
+		t.Errorf("Cache-Control = %q, want no-store", cc)
+	}
+	if tag := rec.Header().Get("ETag"); tag != "" {
+		t.Errorf("ETag = %q, want none", tag)
+	}
+}
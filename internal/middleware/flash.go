@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"strings"
+)
+
+// FlashCookie carries a one-off message from a form action to the page it
+// redirects to, where the layout shows it as a banner.
+const FlashCookie = "flash"
+
+// Flash message kinds.
+const (
+	FlashSuccess = "success"
+	FlashError   = "error"
+)
+
+// FlashMessage is a message set by SetFlash.
+type FlashMessage struct {
+	Kind    string
+	Message string
+}
+
+type flashContextKey struct{}
+
+type flashState struct {
+	secure  bool
+	message *FlashMessage
+}
+
+// Flashes is middleware for pages that show flash messages. A page load
+// (a GET that accepts HTML) takes the flash cookie's message for Flash and
+// clears the cookie, so the message shows once. secureCookie marks the
+// cookies SetFlash sets Secure.
+func Flashes(secureCookie bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			state := &flashState{secure: secureCookie}
+			if c, err := r.Cookie(FlashCookie); err == nil && r.Method == http.MethodGet && WantsHTML(r) {
+				state.message = parseFlash(c.Value)
+				http.SetCookie(w, &http.Cookie{Name: FlashCookie, Path: "/", MaxAge: -1})
+			}
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), flashContextKey{}, state)))
+		})
+	}
+}
+
+// SetFlash queues msg, of kind FlashSuccess or FlashError, for the next
+// page the browser loads. Call it before redirecting.
+func SetFlash(w http.ResponseWriter, r *http.Request, kind, msg string) {
+	secure := false
+	if state, ok := r.Context().Value(flashContextKey{}).(*flashState); ok {
+		secure = state.secure
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     FlashCookie,
+		Value:    base64.RawURLEncoding.EncodeToString([]byte(kind + ":" + msg)),
+		Path:     "/",
+		MaxAge:   60,
+		HttpOnly: true,
+		Secure:   secure,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// Flash returns the message the page load brought with it, or nil.
+func Flash(r *http.Request) *FlashMessage {
+	if state, ok := r.Context().Value(flashContextKey{}).(*flashState); ok {
+		return state.message
+	}
+	return nil
+}
+
+func parseFlash(value string) *FlashMessage {
+	b, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil
+	}
+	kind, msg, ok := strings.Cut(string(b), ":")
+	if !ok || (kind != FlashSuccess && kind != FlashError) || msg == "" {
+		return nil
+	}
+	return &FlashMessage{Kind: kind, Message: msg}
+}
+
+// WantsHTML reports whether r comes from a browser loading a page or
+// submitting a form, rather than a script's fetch, by its Accept header.
+func WantsHTML(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept"), "text/html")
+}
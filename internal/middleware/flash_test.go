@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFlashes(t *testing.T) {
+	var got *FlashMessage
+	h := Flashes(true)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			SetFlash(w, r, FlashSuccess, "Round 2 is paired.")
+			return
+		}
+		got = Flash(r)
+	}))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("POST", "/tournaments/1/next-round", nil))
+	cookies := rec.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != FlashCookie || !cookies[0].Secure || !cookies[0].HttpOnly {
+		t.Fatalf("cookies = %+v, want a secure HttpOnly flash cookie", cookies)
+	}
+
+	// A script's fetch leaves it for the page.
+	req := httptest.NewRequest("GET", "/tournaments/1/manage/rounds", nil)
+	req.AddCookie(cookies[0])
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if got != nil || len(rec.Result().Cookies()) != 0 {
+		t.Errorf("fetch took the flash: %+v", got)
+	}
+
+	req.Header.Set("Accept", "text/html,application/xhtml+xml")
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if got == nil || *got != (FlashMessage{Kind: FlashSuccess, Message: "Round 2 is paired."}) {
+		t.Errorf("flash = %+v", got)
+	}
+	if cleared := rec.Result().Cookies(); len(cleared) != 1 || cleared[0].MaxAge >= 0 {
+		t.Errorf("cookies = %+v, want the flash cleared", cleared)
+	}
+
+	// A made-up cookie shows nothing.
+	for _, value := range []string{"not base64!", "aW5mbzpoaQ"} {
+		req = httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("Accept", "text/html")
+		req.AddCookie(&http.Cookie{Name: FlashCookie, Value: value})
+		h.ServeHTTP(httptest.NewRecorder(), req)
+		if got != nil {
+			t.Errorf("cookie %q: flash = %+v", value, got)
+		}
+	}
+}
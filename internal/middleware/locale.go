@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/dstathis/openswiss/internal/i18n"
+)
+
+type localeKey struct{}
+
+// Localize picks the language pages are rendered in and makes it available
+// via Locale(r). A non-empty forced locale (the LOCALE setting) applies to
+// every request; otherwise each request gets the best match for its
+// Accept-Language header, and responses say they vary by it.
+func Localize(forced string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			lang := forced
+			if lang == "" {
+				lang = i18n.Match(r.Header.Get("Accept-Language"))
+				w.Header().Add("Vary", "Accept-Language")
+			}
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), localeKey{}, lang)))
+		})
+	}
+}
+
+// Locale returns the request's locale as chosen by Localize, or
+// i18n.Default outside it.
+func Locale(r *http.Request) string {
+	if lang, ok := r.Context().Value(localeKey{}).(string); ok {
+		return lang
+	}
+	return i18n.Default
+}
@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestLocalize(t *testing.T) {
+	tests := []struct {
+		forced, accept string
+		want, vary     string
+	}{
+		{"", "", "en", "Accept-Language"},
+		{"", "de-DE,de;q=0.9,en;q=0.8", "de", "Accept-Language"},
+		{"", "fr, es;q=0.5", "es", "Accept-Language"},
+		{"es", "de", "es", ""},
+	}
+	for _, tt := range tests {
+		var got string
+		h := Localize(tt.forced)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got = Locale(r)
+		}))
+		req := httptest.NewRequest("GET", "/", nil)
+		if tt.accept != "" {
+			req.Header.Set("Accept-Language", tt.accept)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if got != tt.want || rec.Header().Get("Vary") != tt.vary {
+			t.Errorf("forced %q, Accept-Language %q: locale %q, Vary %q; want %q, %q",
+				tt.forced, tt.accept, got, rec.Header().Get("Vary"), tt.want, tt.vary)
+		}
+	}
+	if got := Locale(httptest.NewRequest("GET", "/", nil)); got != "en" {
+		t.Errorf("Locale outside Localize = %q", got)
+	}
+}
@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// routeMethods are the methods Allow headers can list, in the order listed.
+var routeMethods = []string{http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+
+// allowedMethods returns the methods routes has a route for at path. HEAD
+// counts wherever GET does (see GetHead).
+func allowedMethods(routes chi.Routes, path string) []string {
+	var allowed []string
+	get := false
+	for _, m := range routeMethods {
+		ok := routes.Match(chi.NewRouteContext(), m, path)
+		if m == http.MethodGet {
+			get = ok
+		}
+		if ok || (m == http.MethodHead && get) {
+			allowed = append(allowed, m)
+		}
+	}
+	return allowed
+}
+
+// MethodNotAllowed answers a request whose path routes knows, but not for
+// its method: 405 with an Allow header listing the methods that are routed,
+// and an error as JSON under /api/ and as text elsewhere.
+func MethodNotAllowed(routes chi.Routes) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Allow", strings.Join(allowedMethods(routes, r.URL.Path), ", "))
+		if strings.HasPrefix(r.URL.Path, "/api/") {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			fmt.Fprint(w, `{"error":"method not allowed"}`)
+			return
+		}
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// GetHead routes a HEAD request to the GET route for its path unless routes
+// has a HEAD route of its own. net/http drops the body.
+func GetHead(routes chi.Routes) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodHead {
+				if rctx := chi.RouteContext(r.Context()); rctx != nil && !routes.Match(chi.NewRouteContext(), http.MethodHead, r.URL.Path) {
+					rctx.RouteMethod = http.MethodGet
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func methodsRouter() *chi.Mux {
+	ok := func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("body")) }
+	r := chi.NewRouter()
+	r.MethodNotAllowed(MethodNotAllowed(r))
+	r.Use(GetHead(r))
+	r.Group(func(r chi.Router) {
+		r.Get("/tournaments/{id}", ok)
+		r.Post("/tournaments/{id}/edit", ok)
+	})
+	r.Route("/api/v1", func(r chi.Router) {
+		r.Get("/tournaments/{id}", ok)
+		r.Patch("/tournaments/{id}", ok)
+		r.Delete("/tournaments/{id}", ok)
+	})
+	return r
+}
+
+func TestMethodNotAllowed(t *testing.T) {
+	r := methodsRouter()
+	for _, tc := range []struct {
+		method, path, allow, body string
+	}{
+		{"GET", "/tournaments/3/edit", "POST", "Method not allowed\n"},
+		{"DELETE", "/tournaments/3", "GET, HEAD", "Method not allowed\n"},
+		{"POST", "/api/v1/tournaments/3", "GET, HEAD, PATCH, DELETE", `{"error":"method not allowed"}`},
+	} {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(tc.method, tc.path, nil))
+		if rec.Code != http.StatusMethodNotAllowed || rec.Header().Get("Allow") != tc.allow || rec.Body.String() != tc.body {
+			t.Errorf("%s %s: status %d, Allow %q, body %q", tc.method, tc.path, rec.Code, rec.Header().Get("Allow"), rec.Body.String())
+		}
+		if api := strings.HasPrefix(tc.path, "/api/"); api != (rec.Header().Get("Content-Type") == "application/json") {
+			t.Errorf("%s %s: Content-Type %q", tc.method, tc.path, rec.Header().Get("Content-Type"))
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest("GET", "/nowhere", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("unknown path: status %d, want 404", rec.Code)
+	}
+}
+
+func TestGetHead(t *testing.T) {
+	r := methodsRouter()
+	for _, path := range []string{"/tournaments/3", "/api/v1/tournaments/3"} {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest("HEAD", path, nil))
+		if rec.Code != http.StatusOK {
+			t.Errorf("HEAD %s: status %d, want 200", path, rec.Code)
+		}
+	}
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest("HEAD", "/tournaments/3/edit", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("HEAD on a POST route: status %d, want 405", rec.Code)
+	}
+}
@@ -24,7 +24,7 @@ func RealIP(trustedProxies []*net.IPNet) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			ip := remoteIP(r)
-			if ip != "" && isTrustedProxy(ip, trustedProxies) {
+			if ip != "" && inNets(ip, trustedProxies) {
 				if forwarded := firstForwardedFor(r.Header.Get("X-Forwarded-For")); forwarded != "" {
 					ip = forwarded
 				} else if real := strings.TrimSpace(r.Header.Get("X-Real-IP")); real != "" {
@@ -92,15 +92,16 @@ func firstForwardedFor(header string) string {
 	return strings.TrimSpace(first)
 }
 
-func isTrustedProxy(ip string, trusted []*net.IPNet) bool {
-	if len(trusted) == 0 {
+// inNets reports whether ip is in one of nets.
+func inNets(ip string, nets []*net.IPNet) bool {
+	if len(nets) == 0 {
 		return false
 	}
 	parsed := net.ParseIP(ip)
 	if parsed == nil {
 		return false
 	}
-	for _, cidr := range trusted {
+	for _, cidr := range nets {
 		if cidr.Contains(parsed) {
 			return true
 		}
@@ -1,34 +1,88 @@
 package middleware
 
 import (
+	"fmt"
 	"log/slog"
 	"net/http"
 	"runtime/debug"
+	"strings"
 )
 
-// Recover catches panics in downstream handlers, logs them with a stack trace,
-// and writes a 500. Without it, a single panic kills the process.
-func Recover(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		defer func() {
-			rec := recover()
-			if rec == nil {
-				return
-			}
-			// http.ErrAbortHandler is the documented signal that a handler
-			// is intentionally aborting; propagate it so the server logs it
-			// and closes the connection.
-			if rec == http.ErrAbortHandler {
-				panic(rec)
-			}
-			slog.ErrorContext(r.Context(), "panic recovered",
-				"err", rec,
-				"method", r.Method,
-				"path", r.URL.Path,
-				"stack", string(debug.Stack()),
-			)
-			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
-		}()
-		next.ServeHTTP(w, r)
-	})
+// Recover catches panics in downstream handlers, logs them with a stack
+// trace, counts them with onPanic (if not nil) and answers 500: page renders
+// the error for web requests (plain text if page is nil), and the API gets
+// JSON. Without it, a single panic kills the process.
+//
+// A response that had already started can't be taken back, so its
+// connection is cut instead and the client sees a truncated reply.
+func Recover(page http.Handler, onPanic func()) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rw := &recoverWriter{ResponseWriter: w}
+			defer func() {
+				rec := recover()
+				if rec == nil {
+					return
+				}
+				// http.ErrAbortHandler is the documented signal that a handler
+				// is intentionally aborting; propagate it so the server logs it
+				// and closes the connection.
+				if rec == http.ErrAbortHandler {
+					panic(rec)
+				}
+				slog.ErrorContext(r.Context(), "panic recovered",
+					"err", rec,
+					"method", r.Method,
+					"path", r.URL.Path,
+					"stack", string(debug.Stack()),
+				)
+				if onPanic != nil {
+					onPanic()
+				}
+				if rw.started {
+					panic(http.ErrAbortHandler)
+				}
+				// Drop what the handler set up for the reply it never sent.
+				for _, k := range []string{"Content-Type", "Content-Length", "Content-Disposition", "ETag", "Last-Modified"} {
+					w.Header().Del(k)
+				}
+				w.Header().Set("Cache-Control", "no-store")
+				switch {
+				case strings.HasPrefix(r.URL.Path, "/api/"):
+					w.Header().Set("Content-Type", "application/json")
+					w.WriteHeader(http.StatusInternalServerError)
+					fmt.Fprint(w, `{"error":"internal server error"}`)
+				case page != nil:
+					page.ServeHTTP(w, r)
+				default:
+					http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+				}
+			}()
+			next.ServeHTTP(rw, r)
+		})
+	}
+}
+
+// recoverWriter notes whether the response has started.
+type recoverWriter struct {
+	http.ResponseWriter
+	started bool
+}
+
+func (rw *recoverWriter) WriteHeader(code int) {
+	// 1xx informational responses leave the real one still to come.
+	if code >= http.StatusOK {
+		rw.started = true
+	}
+	rw.ResponseWriter.WriteHeader(code)
+}
+
+func (rw *recoverWriter) Write(b []byte) (int, error) {
+	rw.started = true
+	return rw.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the writer underneath.
+func (rw *recoverWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
 }
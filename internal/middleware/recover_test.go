@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func panicking(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/pdf")
+	panic("boom")
+}
+
+func TestRecover_RendersPageAndCounts(t *testing.T) {
+	captureLog(t)
+	panics := 0
+	page := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte("error page"))
+	})
+	h := Recover(page, func() { panics++ })(http.HandlerFunc(panicking))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/tournaments/1/slips.pdf", nil))
+	if rec.Code != http.StatusInternalServerError || rec.Body.String() != "error page" {
+		t.Errorf("got %d %q, want the error page", rec.Code, rec.Body.String())
+	}
+	if ct := rec.Header().Get("Content-Type"); ct == "application/pdf" {
+		t.Error("the panicking handler's Content-Type was kept")
+	}
+	if panics != 1 {
+		t.Errorf("onPanic called %d times, want 1", panics)
+	}
+}
+
+func TestRecover_APIGetsJSON(t *testing.T) {
+	captureLog(t)
+	h := Recover(http.NotFoundHandler(), nil)(http.HandlerFunc(panicking))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("POST", "/api/v1/tournaments", nil))
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want 500", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	if !strings.Contains(rec.Body.String(), `"error"`) {
+		t.Errorf("body = %s", rec.Body.String())
+	}
+}
+
+func TestRecover_LogsStack(t *testing.T) {
+	buf := captureLog(t)
+	Recover(nil, nil)(http.HandlerFunc(panicking)).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	if out := buf.String(); !strings.Contains(out, "panic recovered") || !strings.Contains(out, "boom") || !strings.Contains(out, "runtime/debug.Stack") {
+		t.Errorf("log lacks the panic and its stack: %s", out)
+	}
+}
+
+func TestRecover_CutsStartedResponse(t *testing.T) {
+	captureLog(t)
+	h := Recover(http.NotFoundHandler(), nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("half a page"))
+		panic("boom")
+	}))
+	defer func() {
+		if rec := recover(); !errors.Is(asError(rec), http.ErrAbortHandler) {
+			t.Errorf("recovered %v, want http.ErrAbortHandler", rec)
+		}
+	}()
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	t.Error("a started response should be aborted")
+}
+
+func TestRecover_PassesAbortHandlerThrough(t *testing.T) {
+	panics := 0
+	h := Recover(http.NotFoundHandler(), func() { panics++ })(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+	defer func() {
+		if rec := recover(); rec != http.ErrAbortHandler {
+			t.Errorf("recovered %v, want http.ErrAbortHandler", rec)
+		}
+		if panics != 0 {
+			t.Error("an intentional abort was counted as a panic")
+		}
+	}()
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+}
+
+func asError(v any) error {
+	err, _ := v.(error)
+	return err
+}
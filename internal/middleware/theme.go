@@ -0,0 +1,30 @@
+package middleware
+
+import "net/http"
+
+// ThemeCookie holds the visitor's colour theme. It is set by POST /theme, or
+// by app.js when the toggle is clicked, and read on every page render so the
+// layout is served in the right theme without a flash.
+const ThemeCookie = "theme"
+
+const (
+	ThemeDark  = "dark"
+	ThemeLight = "light"
+	// ThemeContrast is black and white with yellow links and focus rings,
+	// for visitors who need more contrast than either theme gives.
+	ThemeContrast = "contrast"
+)
+
+// ValidTheme reports whether t is a known theme.
+func ValidTheme(t string) bool {
+	return t == ThemeDark || t == ThemeLight || t == ThemeContrast
+}
+
+// Theme returns the request's colour theme from ThemeCookie, or ThemeDark if
+// it has none or an unknown one.
+func Theme(r *http.Request) string {
+	if c, err := r.Cookie(ThemeCookie); err == nil && ValidTheme(c.Value) {
+		return c.Value
+	}
+	return ThemeDark
+}
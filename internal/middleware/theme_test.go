@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTheme(t *testing.T) {
+	for cookie, want := range map[string]string{
+		"":         ThemeDark,
+		"light":    ThemeLight,
+		"dark":     ThemeDark,
+		"contrast": ThemeContrast,
+		"purple":   ThemeDark,
+	} {
+		r := httptest.NewRequest("GET", "/", nil)
+		if cookie != "" {
+			r.AddCookie(&http.Cookie{Name: ThemeCookie, Value: cookie})
+		}
+		if got := Theme(r); got != want {
+			t.Errorf("Theme with cookie %q = %q, want %q", cookie, got, want)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestTimeout_SetsDeadline(t *testing.T) {
+	var deadline time.Time
+	var ok bool
+	h := Timeout(time.Minute)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		deadline, ok = r.Context().Deadline()
+	}))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	if !ok {
+		t.Fatal("no deadline on the request context")
+	}
+	if left := time.Until(deadline); left <= 0 || left > time.Minute {
+		t.Errorf("deadline %v away, want within a minute", left)
+	}
+}
+
+func TestTimeout_ZeroSetsNone(t *testing.T) {
+	h := Timeout(0)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := r.Context().Deadline(); ok {
+			t.Error("a zero timeout set a deadline")
+		}
+	}))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+}
@@ -1,7 +1,18 @@
 package models
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
 )
 
 type User struct {
@@ -13,6 +24,10 @@ type User struct {
 	EmailVerifiedAt     *time.Time `json:"email_verified_at,omitempty"`
 	FailedLoginAttempts int        `json:"-"`
 	LockedUntil         *time.Time `json:"-"`
+	NotifyBy            string     `json:"notify_by"`
+	// DeletionRequestedAt is when the user asked for their personal data
+	// to be deleted; nil if they haven't.
+	DeletionRequestedAt *time.Time `json:"deletion_requested_at,omitempty"`
 	CreatedAt           time.Time  `json:"created_at"`
 	UpdatedAt           time.Time  `json:"updated_at"`
 }
@@ -36,6 +51,79 @@ func (u *User) HasRole(role string) bool {
 	return false
 }
 
+// Notifies reports whether the user wants to be notified through channel:
+// result reminders and staff invitations are only sent through the one
+// chosen in their profile. Account emails, such as a password reset, are
+// sent regardless.
+func (u *User) Notifies(channel string) bool {
+	return u.NotifyBy == channel
+}
+
+// Notification channels a user can choose between (see User.NotifyBy).
+const (
+	NotifyEmail = "email"
+	NotifyNone  = "none"
+)
+
+// ValidNotifyChannel reports whether c is a notification channel.
+func ValidNotifyChannel(c string) bool {
+	return c == NotifyEmail || c == NotifyNone
+}
+
+// CanOrganize reports whether the user holds the organizer role, or is an
+// admin, who can do anything an organizer can: create tournaments and use
+// the player registry.
+func (u *User) CanOrganize() bool {
+	return u.HasRole(RoleOrganizer) || u.HasRole(RoleAdmin)
+}
+
+// Avatar is the picture shown next to a player's name in pairings and
+// standings: one of AvatarIcons, or an uploaded image the server has
+// scaled to a small PNG (see package avatar).
+type Avatar struct {
+	UserID int64 `json:"user_id"`
+	// Icon names one of AvatarIcons; it is empty for an uploaded image.
+	Icon string `json:"icon,omitempty"`
+	// Image is the uploaded image as PNG. Lists of avatars leave it out.
+	Image     []byte    `json:"-"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Emoji returns the avatar's icon, or "" for an uploaded image.
+func (a *Avatar) Emoji() string {
+	for _, icon := range AvatarIcons {
+		if icon.Name == a.Icon {
+			return icon.Emoji
+		}
+	}
+	return ""
+}
+
+// Version changes whenever the avatar does, so its image URL can be cached
+// for long.
+func (a *Avatar) Version() int64 {
+	return a.UpdatedAt.Unix()
+}
+
+// An AvatarIcon is an avatar a player can pick instead of uploading one.
+type AvatarIcon struct {
+	Name  string
+	Emoji string
+}
+
+// AvatarIcons are the icons players can pick from, in the order offered.
+var AvatarIcons = []AvatarIcon{
+	{"cat", "🐱"}, {"dog", "🐶"}, {"fox", "🦊"}, {"owl", "🦉"},
+	{"dragon", "🐉"}, {"octopus", "🐙"}, {"crown", "👑"}, {"star", "⭐"},
+	{"rocket", "🚀"}, {"fire", "🔥"}, {"clover", "🍀"}, {"dice", "🎲"},
+}
+
+// ValidAvatarIcon reports whether name is one of AvatarIcons.
+func ValidAvatarIcon(name string) bool {
+	a := Avatar{Icon: name}
+	return a.Emoji() != ""
+}
+
 type Session struct {
 	ID        string    `json:"id"`
 	UserID    int64     `json:"user_id"`
@@ -44,16 +132,31 @@ type Session struct {
 }
 
 type APIKey struct {
-	ID        int64      `json:"id"`
-	UserID    int64      `json:"user_id"`
-	KeyHash   string     `json:"-"`
-	Prefix    string     `json:"prefix"`
-	Name      string     `json:"name"`
+	ID      int64  `json:"id"`
+	UserID  int64  `json:"user_id"`
+	KeyHash string `json:"-"`
+	Prefix  string `json:"prefix"`
+	Name    string `json:"name"`
+	// Scope limits what requests the key may make: APIKeyScopeRead or
+	// APIKeyScopeReadWrite.
+	Scope     string     `json:"scope"`
 	LastUsed  *time.Time `json:"last_used,omitempty"`
 	CreatedAt time.Time  `json:"created_at"`
 	ExpiresAt *time.Time `json:"expires_at,omitempty"`
 }
 
+// API key scopes. A read key can only make safe (GET, HEAD, OPTIONS)
+// requests; a read_write key has all of its owner's permissions.
+const (
+	APIKeyScopeRead      = "read"
+	APIKeyScopeReadWrite = "read_write"
+)
+
+// ValidAPIKeyScope reports whether s is a known API key scope.
+func ValidAPIKeyScope(s string) bool {
+	return s == APIKeyScopeRead || s == APIKeyScopeReadWrite
+}
+
 type Tournament struct {
 	ID              int64      `json:"id"`
 	Name            string     `json:"name"`
@@ -68,11 +171,539 @@ type Tournament struct {
 	PointsDraw      int        `json:"points_draw"`
 	PointsLoss      int        `json:"points_loss"`
 	TopCut          int        `json:"top_cut"`
-	Status          string     `json:"status"`
-	OrganizerID     int64      `json:"organizer_id"`
-	EngineState     []byte     `json:"-"`
-	CreatedAt       time.Time  `json:"created_at"`
-	UpdatedAt       time.Time  `json:"updated_at"`
+	// PairingAlgorithm is PairingSwiss, PairingWeighted, PairingRoundRobin
+	// or PairingDanish.
+	PairingAlgorithm string `json:"pairing_algorithm"`
+	// ByePolicy is ByeLowest or ByeRandom; see engine.PairRound.
+	ByePolicy string `json:"bye_policy"`
+	// Round1Pairing is Round1Random, or Round1Cross or Round1Fold to pair
+	// round 1 by rating; see engine.InitTournamentEngine.
+	Round1Pairing string `json:"round1_pairing"`
+	// Tiebreakers orders the tiebreakers that rank players level on
+	// points, from TiebreakOMW, TiebreakGW and TiebreakOGW; see
+	// TiebreakOrder.
+	Tiebreakers []string `json:"tiebreakers"`
+	// BestOf is the number of games in a match; 0 leaves it unspecified.
+	BestOf int `json:"best_of"`
+	// SeriesMode makes every match a best-of-BestOf series reported game by
+	// game (see MatchGame).
+	SeriesMode bool `json:"series_mode"`
+	// Colors tracks chess colours: player A of every Swiss match plays
+	// white, and pairing balances and alternates each player's colours
+	// (see engine.PairRound).
+	Colors bool `json:"colors"`
+	// AvoidClubRounds is how many Swiss rounds, from round 1, keep players
+	// of the same club (Registration.Club) apart where the pairing allows;
+	// 0 turns it off. See engine.PairRound.
+	AvoidClubRounds int `json:"avoid_club_rounds"`
+	// AutoRounds runs a Swiss without a NumRounds for the recommended
+	// number of rounds for the field at the start (pairing.SwissRounds),
+	// finishing it after the last one. See engine.InitTournamentEngine.
+	AutoRounds bool `json:"auto_rounds"`
+	// EntryFee is what a player pays to enter, in minor units (cents); 0 is
+	// a free event. See FormatAmount.
+	EntryFee int `json:"entry_fee"`
+	// SelfReport lets each player of a match report its result. A result
+	// counts once both players' reports agree, or once a lone report is
+	// ReportConfirmMinutes old (0 waits for the opponent or staff); reports
+	// that differ wait for staff. See engine.ReportOwnResult.
+	SelfReport           bool `json:"self_report"`
+	ReportConfirmMinutes int  `json:"report_confirm_minutes"`
+	// BotCheck makes online sign-ups solve a proof-of-work puzzle in the
+	// browser first (see package pow).
+	BotCheck bool `json:"bot_check"`
+	// BlindFinalRound hides the standings from players and spectators
+	// while the last Swiss round is played; staff still see them. See
+	// engine.StandingsHidden.
+	BlindFinalRound bool `json:"blind_final_round"`
+	// TeamSize is 0 for an individual event. Otherwise every registration
+	// is a team of TeamSize players whose seat results roll up into the
+	// team's match result (see SeatResult).
+	TeamSize int `json:"team_size"`
+	// MinPlayers is the fewest confirmed players the tournament can start
+	// with; see engine.CheckStart.
+	MinPlayers int `json:"min_players"`
+	// ParentID is set on a pod of a multi-stage event and names the
+	// tournament whose finals the pod feeds.
+	ParentID *int64 `json:"parent_id,omitempty"`
+	// PodAdvance is how many players each of the tournament's pods sends on
+	// to its finals; see engine.AdvancePods.
+	PodAdvance int `json:"pod_advance"`
+	// TimeZone is the IANA name of the venue's time zone, e.g.
+	// "Europe/Berlin". Times staff type in are read in it.
+	TimeZone string `json:"time_zone"`
+	// PreviewPairings holds each newly paired Swiss round back as a draft
+	// that only staff see until it is published (see PairingsDraft).
+	PreviewPairings bool `json:"preview_pairings"`
+	// DraftRound is the Swiss round whose pairings wait to be published, 0
+	// when there is none.
+	DraftRound  int       `json:"draft_round"`
+	Status      string    `json:"status"`
+	OrganizerID int64     `json:"organizer_id"`
+	EngineState []byte    `json:"-"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// DefaultTimeZone is the time zone of a tournament that doesn't set one.
+const DefaultTimeZone = "UTC"
+
+// ValidTimeZone reports whether tz names an IANA time zone. "Local" is
+// refused: it means the server's zone, not the venue's.
+func ValidTimeZone(tz string) bool {
+	if tz == "" || tz == "Local" {
+		return false
+	}
+	_, err := time.LoadLocation(tz)
+	return err == nil
+}
+
+// Zone returns the tournament's time zone, UTC if it is unset or unknown.
+func (t *Tournament) Zone() *time.Location {
+	if ValidTimeZone(t.TimeZone) {
+		loc, _ := time.LoadLocation(t.TimeZone)
+		return loc
+	}
+	return time.UTC
+}
+
+// PairingsDraft reports whether round's pairings are an unpublished draft,
+// hidden from players and spectators.
+func (t *Tournament) PairingsDraft(round int) bool {
+	return t.PreviewPairings && round > 0 && t.DraftRound == round
+}
+
+// ValidateTimeZone checks that TimeZone names a time zone.
+func (t *Tournament) ValidateTimeZone() error {
+	if !ValidTimeZone(t.TimeZone) {
+		return fmt.Errorf("unknown time zone %q; use an IANA name such as Europe/Berlin", t.TimeZone)
+	}
+	return nil
+}
+
+// ScheduleItem is one timed entry of a tournament's schedule, such as a
+// round start or a lunch break.
+type ScheduleItem struct {
+	ID           int64     `json:"id"`
+	TournamentID int64     `json:"-"`
+	StartsAt     time.Time `json:"starts_at"`
+	Label        string    `json:"label"`
+}
+
+// Schedule limits: how many items a tournament's schedule may have, and how
+// long an item's label may be, in characters.
+const (
+	MaxScheduleItems = 50
+	MaxScheduleLabel = 100
+)
+
+// ValidateSchedule checks the size of a schedule and that every item has a
+// label of at most MaxScheduleLabel characters.
+func ValidateSchedule(items []ScheduleItem) error {
+	if len(items) > MaxScheduleItems {
+		return fmt.Errorf("a schedule can have at most %d items", MaxScheduleItems)
+	}
+	for _, s := range items {
+		if strings.TrimSpace(s.Label) == "" {
+			return errors.New("every schedule item needs a label")
+		}
+		if utf8.RuneCountInString(s.Label) > MaxScheduleLabel {
+			return fmt.Errorf("schedule labels can be at most %d characters", MaxScheduleLabel)
+		}
+	}
+	return nil
+}
+
+// SharePath is the path of tournament id's read-only share link with the
+// given token.
+func SharePath(id int64, token string) string {
+	return fmt.Sprintf("/t/%d/view/%s", id, token)
+}
+
+// KioskPath is the path of tournament id's kiosk result entry page.
+func KioskPath(id int64) string {
+	return fmt.Sprintf("/t/%d/kiosk", id)
+}
+
+// DefaultMinPlayers is the minimum field size when none is configured: the
+// smallest field that can be paired.
+const DefaultMinPlayers = 2
+
+// ValidatePlayerLimits checks MinPlayers against MaxPlayers, and that
+// PodAdvance is a non-negative count on a tournament that isn't a pod.
+func (t *Tournament) ValidatePlayerLimits() error {
+	if t.MinPlayers < DefaultMinPlayers {
+		return fmt.Errorf("min_players must be at least %d", DefaultMinPlayers)
+	}
+	if t.MaxPlayers > 0 && t.MinPlayers > t.MaxPlayers {
+		return errors.New("min_players cannot be more than max_players")
+	}
+	if t.PodAdvance < 0 {
+		return errors.New("pod_advance cannot be negative")
+	}
+	if t.ParentID != nil && t.PodAdvance > 0 {
+		return errors.New("a pod has no pods of its own to advance players from")
+	}
+	return nil
+}
+
+// ValidateEntryFee checks EntryFee.
+func (t *Tournament) ValidateEntryFee() error {
+	if t.EntryFee < 0 {
+		return errors.New("entry_fee cannot be negative")
+	}
+	return nil
+}
+
+// MaxClubName is the length limit, in runes, of a player's club.
+const MaxClubName = 60
+
+// ValidateClubRounds checks AvoidClubRounds.
+func (t *Tournament) ValidateClubRounds() error {
+	if t.AvoidClubRounds < 0 {
+		return errors.New("avoid_club_rounds cannot be negative")
+	}
+	return nil
+}
+
+// MaxBestOf is the longest series a tournament may be configured for.
+const MaxBestOf = 9
+
+// Team sizes a team event may be configured for.
+const (
+	MinTeamSize = 2
+	MaxTeamSize = 6
+)
+
+// MaxMemberName is the length limit, in runes, of a team member's name.
+const MaxMemberName = 100
+
+// ValidateMatchFormat checks BestOf, SeriesMode, TeamSize and Colors
+// together.
+func (t *Tournament) ValidateMatchFormat() error {
+	if t.BestOf < 0 || t.BestOf > MaxBestOf {
+		return fmt.Errorf("best_of must be between 0 and %d", MaxBestOf)
+	}
+	if t.SeriesMode && t.BestOf == 0 {
+		return errors.New("series mode needs best_of of at least 1")
+	}
+	if t.TeamSize != 0 && (t.TeamSize < MinTeamSize || t.TeamSize > MaxTeamSize) {
+		return fmt.Errorf("team_size must be 0 or between %d and %d", MinTeamSize, MaxTeamSize)
+	}
+	if t.TeamSize > 0 && t.SeriesMode {
+		return errors.New("team events can't use series mode")
+	}
+	if t.TeamSize > 0 && t.Colors {
+		return errors.New("team events can't track colours")
+	}
+	if t.ReportConfirmMinutes < 0 || t.ReportConfirmMinutes > MaxReportConfirmMinutes {
+		return fmt.Errorf("report_confirm_minutes must be between 0 and %d", MaxReportConfirmMinutes)
+	}
+	if t.SelfReport && (t.SeriesMode || t.TeamSize > 0) {
+		return errors.New("series and team events can't use player reporting")
+	}
+	return nil
+}
+
+// MaxReportConfirmMinutes is the longest a lone player report can wait for
+// the opponent before it counts, a day.
+const MaxReportConfirmMinutes = 24 * 60
+
+// GamesToWin is how many games take a match of t: a majority of BestOf,
+// or 0 when BestOf is unset.
+func (t *Tournament) GamesToWin() int {
+	if t.BestOf <= 0 {
+		return 0
+	}
+	return t.BestOf/2 + 1
+}
+
+// ValidPairingAlgorithm reports whether a is a known pairing algorithm.
+func ValidPairingAlgorithm(a string) bool {
+	switch a {
+	case PairingSwiss, PairingWeighted, PairingRoundRobin, PairingDanish:
+		return true
+	}
+	return false
+}
+
+// ValidByePolicy reports whether p is a known bye policy.
+func ValidByePolicy(p string) bool {
+	return p == ByeLowest || p == ByeRandom
+}
+
+// ValidRound1Pairing reports whether m is a known round 1 pairing mode.
+func ValidRound1Pairing(m string) bool {
+	return m == Round1Random || m == Round1Cross || m == Round1Fold
+}
+
+// DefaultTiebreakers is the tiebreak order of a tournament that doesn't set
+// one: opponents' match win percentage, then game win percentage, then
+// opponents' game win percentage.
+var DefaultTiebreakers = []string{TiebreakOMW, TiebreakGW, TiebreakOGW}
+
+// TournamentDefaults are the settings a new tournament starts with unless
+// its creator picks others. The server's configuration sets them.
+type TournamentDefaults struct {
+	TimeZone   string
+	MaxPlayers int // 0 for no limit
+	PointsWin  int
+	PointsDraw int
+	PointsLoss int
+	// Tiebreakers is the tiebreak order; nil leaves DefaultTiebreakers.
+	Tiebreakers []string
+}
+
+// StandardDefaults are the defaults of a server that configures none.
+var StandardDefaults = TournamentDefaults{TimeZone: DefaultTimeZone, PointsWin: 3, PointsDraw: 1}
+
+// Apply sets t's settings to the defaults. t gets its own copy of the
+// tiebreakers, so decoding into it leaves the defaults alone.
+func (d TournamentDefaults) Apply(t *Tournament) {
+	t.TimeZone = d.TimeZone
+	t.MaxPlayers = d.MaxPlayers
+	t.PointsWin, t.PointsDraw, t.PointsLoss = d.PointsWin, d.PointsDraw, d.PointsLoss
+	t.Tiebreakers = append([]string(nil), d.Tiebreakers...)
+}
+
+// TiebreakerText is the tiebreak order as the new tournament form shows it.
+func (d TournamentDefaults) TiebreakerText() string {
+	t := Tournament{Tiebreakers: d.Tiebreakers}
+	return strings.Join(t.TiebreakOrder(), ", ")
+}
+
+// Validate checks the defaults as a tournament's settings.
+func (d TournamentDefaults) Validate() error {
+	if d.MaxPlayers < 0 || d.PointsWin < 0 || d.PointsDraw < 0 || d.PointsLoss < 0 {
+		return errors.New("max players and points can't be negative")
+	}
+	t := Tournament{MinPlayers: DefaultMinPlayers}
+	d.Apply(&t)
+	if err := t.ValidatePlayerLimits(); err != nil {
+		return err
+	}
+	if err := t.ValidateTimeZone(); err != nil {
+		return err
+	}
+	return t.ValidateTiebreakers()
+}
+
+// Preset is a named set of tournament settings organizers saved from one
+// event to start later ones from. Like the player registry, presets are
+// shared by every organizer.
+type Preset struct {
+	ID        int64          `json:"id"`
+	Name      string         `json:"name"`
+	Settings  PresetSettings `json:"settings"`
+	CreatedBy *int64         `json:"created_by,omitempty"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+}
+
+// MaxPresetName is the length limit, in runes, of a preset's name.
+const MaxPresetName = 60
+
+// PresetSettings are what a preset keeps of a tournament: its format, not
+// its name, description, date, venue or players. Names are as in
+// Tournament; a file exported from one server imports into another.
+type PresetSettings struct {
+	TimeZone             string        `json:"time_zone"`
+	EntryFee             int           `json:"entry_fee"`
+	MaxPlayers           int           `json:"max_players"`
+	MinPlayers           int           `json:"min_players"`
+	NumRounds            *int          `json:"num_rounds,omitempty"`
+	AutoRounds           bool          `json:"auto_rounds"`
+	TopCut               int           `json:"top_cut"`
+	RequireDecklist      bool          `json:"require_decklist"`
+	DecklistPublic       bool          `json:"decklist_public"`
+	PointsWin            int           `json:"points_win"`
+	PointsDraw           int           `json:"points_draw"`
+	PointsLoss           int           `json:"points_loss"`
+	Tiebreakers          []string      `json:"tiebreakers"`
+	PairingAlgorithm     string        `json:"pairing_algorithm"`
+	ByePolicy            string        `json:"bye_policy"`
+	Round1Pairing        string        `json:"round1_pairing"`
+	AvoidClubRounds      int           `json:"avoid_club_rounds"`
+	BestOf               int           `json:"best_of"`
+	SeriesMode           bool          `json:"series_mode"`
+	Colors               bool          `json:"colors"`
+	SelfReport           bool          `json:"self_report"`
+	ReportConfirmMinutes int           `json:"report_confirm_minutes"`
+	BotCheck             bool          `json:"bot_check"`
+	BlindFinalRound      bool          `json:"blind_final_round"`
+	TeamSize             int           `json:"team_size"`
+	PreviewPairings      bool          `json:"preview_pairings"`
+	PairingFields        []PresetField `json:"pairing_fields"`
+}
+
+// PresetField is a custom pairing field a preset creates (see
+// PairingField).
+type PresetField struct {
+	Label  string `json:"label"`
+	Public bool   `json:"public"`
+}
+
+// PresetOf returns t's settings with its pairing fields, for saving as a
+// preset.
+func PresetOf(t *Tournament, fields []PairingField) PresetSettings {
+	p := PresetSettings{
+		TimeZone:             t.TimeZone,
+		EntryFee:             t.EntryFee,
+		MaxPlayers:           t.MaxPlayers,
+		MinPlayers:           t.MinPlayers,
+		AutoRounds:           t.AutoRounds,
+		TopCut:               t.TopCut,
+		RequireDecklist:      t.RequireDecklist,
+		DecklistPublic:       t.DecklistPublic,
+		PointsWin:            t.PointsWin,
+		PointsDraw:           t.PointsDraw,
+		PointsLoss:           t.PointsLoss,
+		Tiebreakers:          append([]string(nil), t.Tiebreakers...),
+		PairingAlgorithm:     t.PairingAlgorithm,
+		ByePolicy:            t.ByePolicy,
+		Round1Pairing:        t.Round1Pairing,
+		AvoidClubRounds:      t.AvoidClubRounds,
+		BestOf:               t.BestOf,
+		SeriesMode:           t.SeriesMode,
+		Colors:               t.Colors,
+		SelfReport:           t.SelfReport,
+		ReportConfirmMinutes: t.ReportConfirmMinutes,
+		BotCheck:             t.BotCheck,
+		BlindFinalRound:      t.BlindFinalRound,
+		TeamSize:             t.TeamSize,
+		PreviewPairings:      t.PreviewPairings,
+		PairingFields:        []PresetField{},
+	}
+	if t.NumRounds != nil {
+		n := *t.NumRounds
+		p.NumRounds = &n
+	}
+	for _, f := range fields {
+		p.PairingFields = append(p.PairingFields, PresetField{Label: f.Label, Public: f.Public})
+	}
+	return p
+}
+
+// Apply sets t's settings to the preset's. The pairing fields are left to
+// the caller, as they are rows of their own.
+func (p PresetSettings) Apply(t *Tournament) {
+	t.TimeZone, t.EntryFee, t.MaxPlayers, t.MinPlayers = p.TimeZone, p.EntryFee, p.MaxPlayers, p.MinPlayers
+	t.NumRounds = nil
+	if p.NumRounds != nil {
+		n := *p.NumRounds
+		t.NumRounds = &n
+	}
+	t.AutoRounds, t.TopCut = p.AutoRounds, p.TopCut
+	t.RequireDecklist, t.DecklistPublic = p.RequireDecklist, p.DecklistPublic
+	t.PointsWin, t.PointsDraw, t.PointsLoss = p.PointsWin, p.PointsDraw, p.PointsLoss
+	t.Tiebreakers = append([]string(nil), p.Tiebreakers...)
+	t.PairingAlgorithm, t.ByePolicy, t.Round1Pairing = p.PairingAlgorithm, p.ByePolicy, p.Round1Pairing
+	t.AvoidClubRounds, t.BestOf, t.SeriesMode, t.Colors = p.AvoidClubRounds, p.BestOf, p.SeriesMode, p.Colors
+	t.SelfReport, t.ReportConfirmMinutes, t.BotCheck = p.SelfReport, p.ReportConfirmMinutes, p.BotCheck
+	t.TeamSize, t.PreviewPairings, t.BlindFinalRound = p.TeamSize, p.PreviewPairings, p.BlindFinalRound
+}
+
+// Validate checks the preset's settings as a tournament's, for presets
+// that come from an imported file rather than a tournament. It trims the
+// pairing field labels first, as adding a field does.
+func (p *PresetSettings) Validate() error {
+	if p.PairingAlgorithm != "" && !ValidPairingAlgorithm(p.PairingAlgorithm) {
+		return errors.New("pairing_algorithm must be swiss, weighted, round_robin or danish")
+	}
+	if p.ByePolicy != "" && !ValidByePolicy(p.ByePolicy) {
+		return errors.New("bye_policy must be lowest or random")
+	}
+	if p.Round1Pairing != "" && !ValidRound1Pairing(p.Round1Pairing) {
+		return errors.New("round1_pairing must be random, cross or fold")
+	}
+	if p.NumRounds != nil && *p.NumRounds < 1 {
+		return errors.New("num_rounds must be at least 1")
+	}
+	if p.MaxPlayers < 0 || p.TopCut < 0 || p.PointsWin < 0 || p.PointsDraw < 0 || p.PointsLoss < 0 {
+		return errors.New("max players, top cut and points can't be negative")
+	}
+	var t Tournament
+	p.Apply(&t)
+	for _, check := range []func() error{
+		t.ValidatePlayerLimits, t.ValidateTimeZone, t.ValidateTiebreakers,
+		t.ValidateClubRounds, t.ValidateEntryFee, t.ValidateMatchFormat,
+	} {
+		if err := check(); err != nil {
+			return err
+		}
+	}
+	seen := make(map[string]bool)
+	for i, f := range p.PairingFields {
+		f.Label = strings.TrimSpace(f.Label)
+		p.PairingFields[i] = f
+		if !ValidPairingFieldLabel(f.Label) {
+			return fmt.Errorf("pairing field %q: labels are 1 to %d characters", f.Label, MaxPairingFieldLabel)
+		}
+		if seen[f.Label] {
+			return fmt.Errorf("pairing field %q is listed twice", f.Label)
+		}
+		seen[f.Label] = true
+	}
+	return nil
+}
+
+// ValidatePresetName trims name and checks it is non-empty and at most
+// MaxPresetName long.
+func ValidatePresetName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", errors.New("a preset needs a name")
+	}
+	if utf8.RuneCountInString(name) > MaxPresetName {
+		return "", fmt.Errorf("preset names are at most %d characters", MaxPresetName)
+	}
+	return name, nil
+}
+
+// TiebreakOrder returns the tournament's tiebreakers, first applied first,
+// or DefaultTiebreakers when it has none.
+func (t *Tournament) TiebreakOrder() []string {
+	if len(t.Tiebreakers) == 0 {
+		return DefaultTiebreakers
+	}
+	return t.Tiebreakers
+}
+
+// ValidateTiebreakers checks that every tiebreaker is a known one and none
+// is listed twice. Tiebreakers left out are not used.
+func (t *Tournament) ValidateTiebreakers() error {
+	seen := make(map[string]bool)
+	for _, n := range t.Tiebreakers {
+		if TiebreakerLabel(n) == "" {
+			return fmt.Errorf("unknown tiebreaker %q; use omw, gw or ogw", n)
+		}
+		if seen[n] {
+			return fmt.Errorf("tiebreaker %q is listed twice", n)
+		}
+		seen[n] = true
+	}
+	return nil
+}
+
+// ParseTiebreakers reads a tiebreak order as staff type it, such as
+// "gw, omw": names separated by commas or spaces, in any case. An empty s
+// gives the default order. The names are checked by ValidateTiebreakers.
+func ParseTiebreakers(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool { return r == ',' || unicode.IsSpace(r) })
+}
+
+// TiebreakerLabel is the column heading for a tiebreaker, or "" for an
+// unknown name.
+func TiebreakerLabel(name string) string {
+	switch name {
+	case TiebreakOMW:
+		return "OMW%"
+	case TiebreakGW:
+		return "GW%"
+	case TiebreakOGW:
+		return "OGW%"
+	}
+	return ""
 }
 
 // TournamentTier is a per-tournament management role. Compare with AtLeast,
@@ -110,21 +741,655 @@ type TournamentStaff struct {
 	GrantedAt    time.Time      `json:"granted_at"`
 }
 
+// TournamentHandoff is an admin handoff code issued for a tournament. The
+// code itself is never stored. Claimed handoffs form the tournament's
+// transfer log; the names are joined in for display.
+type TournamentHandoff struct {
+	ID            int64      `json:"id"`
+	TournamentID  int64      `json:"tournament_id"`
+	CreatedBy     *int64     `json:"created_by,omitempty"`
+	CreatedByName string     `json:"created_by_name,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	ExpiresAt     time.Time  `json:"expires_at"`
+	ClaimedBy     *int64     `json:"claimed_by,omitempty"`
+	ClaimedByName string     `json:"claimed_by_name,omitempty"`
+	ClaimedAt     *time.Time `json:"claimed_at,omitempty"`
+}
+
+// HandoffTTL is how long a handoff code can be claimed.
+const HandoffTTL = 30 * time.Minute
+
+// PairingField is an organizer-defined label attached to every pairing of a
+// tournament (stream notes, board, map pick). Values are stored per round and
+// table. Public fields are shown on the public pairings page and API; the
+// rest are visible to staff only.
+type PairingField struct {
+	ID           int64     `json:"id"`
+	TournamentID int64     `json:"tournament_id"`
+	Label        string    `json:"label"`
+	Public       bool      `json:"public"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// Length limits for pairing field labels and values, in runes.
+const (
+	MaxPairingFieldLabel = 40
+	MaxPairingFieldValue = 200
+)
+
+// ValidPairingFieldLabel reports whether label is a usable field label:
+// non-blank once trimmed and no longer than MaxPairingFieldLabel.
+func ValidPairingFieldLabel(label string) bool {
+	label = strings.TrimSpace(label)
+	return label != "" && utf8.RuneCountInString(label) <= MaxPairingFieldLabel
+}
+
+// Webhook is a URL that is POSTed a signed JSON payload whenever one of
+// Events happens in the tournament. Secret signs the payloads; it is only
+// shown to the tournament's staff.
+type Webhook struct {
+	ID           int64     `json:"id"`
+	TournamentID int64     `json:"tournament_id"`
+	URL          string    `json:"url"`
+	Secret       string    `json:"secret"`
+	Events       []string  `json:"events"`
+	CreatedBy    *int64    `json:"created_by,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// Webhook event types.
+const (
+	WebhookRoundPaired        = "round.paired"
+	WebhookResultEntered      = "result.entered"
+	WebhookTournamentFinished = "tournament.finished"
+	WebhookResultsOutstanding = "results.outstanding"
+	WebhookAnnouncementPosted = "announcement.posted"
+)
+
+// WebhookEvents lists every event type a webhook can subscribe to.
+var WebhookEvents = []string{WebhookRoundPaired, WebhookResultEntered, WebhookTournamentFinished, WebhookResultsOutstanding, WebhookAnnouncementPosted}
+
+// ValidWebhookEvent reports whether event is one of WebhookEvents.
+func ValidWebhookEvent(event string) bool {
+	return slices.Contains(WebhookEvents, event)
+}
+
+// MaxWebhooks is how many webhooks a tournament may have.
+const MaxWebhooks = 10
+
+// WebhookDelivery is one queued POST of an event to a webhook. NextAttemptAt
+// is nil once the delivery succeeded or was given up on.
+type WebhookDelivery struct {
+	ID            int64           `json:"id"`
+	WebhookID     int64           `json:"webhook_id"`
+	Event         string          `json:"event"`
+	Payload       json.RawMessage `json:"payload"`
+	Attempts      int             `json:"attempts"`
+	NextAttemptAt *time.Time      `json:"next_attempt_at,omitempty"`
+	DeliveredAt   *time.Time      `json:"delivered_at,omitempty"`
+	LastError     string          `json:"last_error,omitempty"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
 type Registration struct {
-	ID             int64     `json:"id"`
-	TournamentID   int64     `json:"tournament_id"`
-	UserID         *int64    `json:"user_id,omitempty"`
-	GuestName      *string   `json:"guest_name,omitempty"`
+	ID             int64   `json:"id"`
+	TournamentID   int64   `json:"tournament_id"`
+	UserID         *int64  `json:"user_id,omitempty"`
+	GuestName      *string `json:"guest_name,omitempty"`
+	DisplayName    string  `json:"display_name"`
+	Decklist       []byte  `json:"decklist,omitempty"`
+	Status         string  `json:"status"`
+	EnginePlayerID *int    `json:"engine_player_id,omitempty"`
+	// Rating is the player's rating for seeding round 1; nil if unrated.
+	Rating *int `json:"rating,omitempty"`
+	// Archetype is the name of the deck the player brought; nil if not given.
+	Archetype *string `json:"archetype,omitempty"`
+	// Club is the player's club or team; nil if they have none.
+	Club *string `json:"club,omitempty"`
+	// Members are a team's players in seat order; empty outside team events.
+	Members []string `json:"members,omitempty"`
+	// TiebreakSeed is the final standings comparator, fixed when the player
+	// enters the engine. Internal only.
+	TiebreakSeed *int64 `json:"-"`
+	// AdvancedFrom is, for a finalist of a multi-stage event, the pod
+	// registration they advanced from.
+	AdvancedFrom *int64 `json:"advanced_from,omitempty"`
+	// PodSeed is, for a finalist of a multi-stage event, their seed from
+	// the pod results (1 = best); a seeded round 1 pairs by it.
+	PodSeed *int `json:"pod_seed,omitempty"`
+	// PlayerID is the player registry entry the registration was made
+	// from, if any.
+	PlayerID *int64 `json:"player_id,omitempty"`
+	// PaymentStatus is PaymentUnpaid, PaymentPaid or PaymentComped.
+	PaymentStatus string `json:"payment_status"`
+	// PaymentAmount is what the player paid, in minor units; nil if not
+	// noted.
+	PaymentAmount *int `json:"payment_amount,omitempty"`
+	// PaymentMethod is how they paid (cash, card, …); nil if not noted.
+	PaymentMethod *string `json:"payment_method,omitempty"`
+	// ContactConsent is whether the player agreed to hear from the
+	// organizer after the event; always false for a guest.
+	ContactConsent bool      `json:"contact_consent"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// MaxPlayerNameLength is the longest a player's or team's name can be, in
+// characters.
+const MaxPlayerNameLength = 100
+
+// reservedPlayerNames read as something other than a player on pairings
+// and standings, so no one can be called them.
+var reservedPlayerNames = []string{"bye", "tbd"}
+
+// NormalizePlayerName tidies a typed name: composed to Unicode NFC, with
+// control, zero-width and direction-override characters dropped and every
+// run of whitespace made one space, trimmed.
+func NormalizePlayerName(name string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range norm.NFC.String(name) {
+		switch {
+		case unicode.IsSpace(r):
+			space = true
+			continue
+		case unicode.IsControl(r), unicode.Is(unicode.Cf, r):
+			continue
+		}
+		if space && b.Len() > 0 {
+			b.WriteByte(' ')
+		}
+		space = false
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// ValidatePlayerName normalizes a player's or team's name (see
+// NormalizePlayerName) and checks it is given, not too long and not a
+// reserved word such as "Bye".
+func ValidatePlayerName(name string) (string, error) {
+	name = NormalizePlayerName(name)
+	if name == "" {
+		return "", errors.New("name is required")
+	}
+	if utf8.RuneCountInString(name) > MaxPlayerNameLength {
+		return "", fmt.Errorf("names can be at most %d characters", MaxPlayerNameLength)
+	}
+	for _, reserved := range reservedPlayerNames {
+		if SamePlayerName(name, reserved) {
+			return "", fmt.Errorf("%q can't be used as a name", name)
+		}
+	}
+	return name, nil
+}
+
+// SamePlayerName reports whether two names would read as the same player:
+// equal once normalized, ignoring case and compatibility forms such as
+// full-width letters.
+func SamePlayerName(a, b string) bool {
+	return strings.EqualFold(norm.NFKC.String(NormalizePlayerName(a)), norm.NFKC.String(NormalizePlayerName(b)))
+}
+
+// SameNameRegistration returns the registration in regs, of any status,
+// whose name is the same as name (see SamePlayerName), or nil.
+func SameNameRegistration(regs []Registration, name string) *Registration {
+	for i := range regs {
+		if SamePlayerName(regs[i].DisplayName, name) {
+			return &regs[i]
+		}
+	}
+	return nil
+}
+
+// collisionSuffix is the "(2)" added to a name the tournament already had.
+var collisionSuffix = regexp.MustCompile(` \(\d+\)$`)
+
+// foldPlayerName reduces name to what SimilarPlayerName compares: without a
+// collision suffix, compatibility forms, accents or punctuation, in lower
+// case, and its words as written and in sorted order.
+func foldPlayerName(name string) (asWritten, sorted string) {
+	name = collisionSuffix.ReplaceAllString(NormalizePlayerName(name), "")
+	var b strings.Builder
+	for _, r := range norm.NFKD.String(name) {
+		switch {
+		case unicode.Is(unicode.Mn, r), unicode.IsPunct(r):
+			continue
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	words := strings.Fields(b.String())
+	asWritten = strings.Join(words, " ")
+	slices.Sort(words)
+	return asWritten, strings.Join(words, " ")
+}
+
+// SimilarPlayerName reports whether two names are close enough to be the
+// same person entered twice: "Jon Smith" and "John Smith", "José" and
+// "Jose", "Smith, John" and "John Smith", or "Ann" and "Ann (2)". Case,
+// accents, punctuation and word order are ignored, and then a typo or two
+// is allowed: none in names under 4 letters, one under 12, two beyond.
+func SimilarPlayerName(a, b string) bool {
+	a1, a2 := foldPlayerName(a)
+	b1, b2 := foldPlayerName(b)
+	if a1 == "" || b1 == "" {
+		return false
+	}
+	n := min(utf8.RuneCountInString(a1), utf8.RuneCountInString(b1))
+	allowed := 2
+	switch {
+	case n < 4:
+		allowed = 0
+	case n < 12:
+		allowed = 1
+	}
+	return editDistance(a1, b1, allowed) <= allowed || editDistance(a2, b2, allowed) <= allowed
+}
+
+// editDistance is the Levenshtein distance between a and b, in runes, or
+// some number over limit once it is sure to be.
+func editDistance(a, b string, limit int) int {
+	ra, rb := []rune(a), []rune(b)
+	if d := len(ra) - len(rb); d > limit || -d > limit {
+		return limit + 1
+	}
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		best := i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			best = min(best, cur[j])
+		}
+		if best > limit {
+			return limit + 1
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
+
+// Player is an entry in the player registry: someone kept on file across
+// tournaments so they can be added to the next one without retyping them.
+// Contact is free text; Rating and Club are copied onto registrations made
+// from the entry.
+type Player struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	Contact   *string   `json:"contact,omitempty"`
+	Rating    *int      `json:"rating,omitempty"`
+	Club      *string   `json:"club,omitempty"`
+	CreatedBy *int64    `json:"created_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// RoundClock is the time limit staff set on a tournament's current Swiss
+// round. RemindedAt is when the tables still playing were reminded to
+// report, nil until then.
+type RoundClock struct {
+	TournamentID int64      `json:"tournament_id"`
+	Round        int        `json:"round"`
+	EndsAt       time.Time  `json:"ends_at"`
+	RemindedAt   *time.Time `json:"reminded_at,omitempty"`
+}
+
+// MaxRoundMinutes is the longest a round clock can be set to.
+const MaxRoundMinutes = 240
+
+// Announcement is a message staff post to a tournament's players, shown as
+// a banner on the tournament's pages while it is active: until ExpiresAt,
+// or until staff end it when ExpiresAt is nil. TournamentName is only set
+// where announcements of several tournaments are listed together.
+type Announcement struct {
+	ID             int64      `json:"id"`
+	TournamentID   int64      `json:"tournament_id"`
+	TournamentName string     `json:"-"`
+	Message        string     `json:"message"`
+	CreatedBy      *int64     `json:"created_by,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	ExpiresAt      *time.Time `json:"expires_at,omitempty"`
+}
+
+// Active reports whether the announcement is still shown at now.
+func (a *Announcement) Active(now time.Time) bool {
+	return a.ExpiresAt == nil || a.ExpiresAt.After(now)
+}
+
+// Announcement limits: the longest message, in characters, and the longest
+// an announcement can be set to last, in minutes.
+const (
+	MaxAnnouncementLength  = 280
+	MaxAnnouncementMinutes = 7 * 24 * 60
+)
+
+// ValidateAnnouncement checks an announcement's message and how many
+// minutes it lasts, 0 meaning until staff end it.
+func ValidateAnnouncement(message string, minutes int) error {
+	if strings.TrimSpace(message) == "" {
+		return errors.New("an announcement needs a message")
+	}
+	if utf8.RuneCountInString(message) > MaxAnnouncementLength {
+		return fmt.Errorf("announcements can be at most %d characters", MaxAnnouncementLength)
+	}
+	if minutes < 0 || minutes > MaxAnnouncementMinutes {
+		return fmt.Errorf("an announcement can last at most %d minutes", MaxAnnouncementMinutes)
+	}
+	return nil
+}
+
+// MaxFeatureMatches is how many matches of a round can be featured on
+// stream at once.
+const MaxFeatureMatches = 4
+
+// Kinds of public page whose views are counted for a tournament's
+// analytics: the tournament page and share link, a round's pairings, and
+// the standings.
+const (
+	PageTournament = "tournament"
+	PagePairings   = "pairings"
+	PageStandings  = "standings"
+)
+
+// RoundViews counts the page views of a tournament while it showed Round,
+// 0 being before round 1.
+type RoundViews struct {
+	Round      int   `json:"round"`
+	Tournament int64 `json:"tournament"`
+	Pairings   int64 `json:"pairings"`
+	Standings  int64 `json:"standings"`
+}
+
+// Total is the round's views of every page.
+func (v RoundViews) Total() int64 {
+	return v.Tournament + v.Pairings + v.Standings
+}
+
+// Analytics is what a tournament's spectators are known to have done: the
+// most seen watching at once and when, and the views per round, in round
+// order. Viewers is how many are watching now.
+type Analytics struct {
+	PeakViewers int          `json:"peak_viewers"`
+	PeakAt      *time.Time   `json:"peak_at,omitempty"`
+	Viewers     int          `json:"viewers"`
+	Views       int64        `json:"views"`
+	Rounds      []RoundViews `json:"rounds"`
+}
+
+// ArchivedEvent is a complete tournament in the archive: its name, date
+// and place, how many played, who won and when it was archived.
+type ArchivedEvent struct {
+	TournamentID int64      `json:"tournament_id"`
+	Name         string     `json:"name"`
+	ScheduledAt  *time.Time `json:"scheduled_at,omitempty"`
+	Location     *string    `json:"location,omitempty"`
+	Players      int        `json:"players"`
+	Winner       string     `json:"winner"`
+	ArchivedAt   time.Time  `json:"archived_at"`
+}
+
+// ArchivedResult is a player's final place in an archived tournament, as it
+// stood when the tournament finished. Playoff is how far they got in the top
+// cut, as for the results page. Tournament names the event in searches
+// across the archive.
+type ArchivedResult struct {
+	TournamentID int64  `json:"tournament_id"`
+	Tournament   string `json:"tournament,omitempty"`
+	Place        int    `json:"place"`
+	Name         string `json:"name"`
+	Playoff      string `json:"playoff,omitempty"`
+	Points       int    `json:"points"`
+	Wins         int    `json:"wins"`
+	Losses       int    `json:"losses"`
+	Draws        int    `json:"draws"`
+	UserID       *int64 `json:"-"`
+	PlayerID     *int64 `json:"player_id,omitempty"`
+}
+
+// League is a season whose leaderboard adds up points from the final
+// results of its tournaments: PlacementPoints[i] for place i+1, and
+// ParticipationPoints for every other finisher (see PointsFor).
+type League struct {
+	ID                  int64     `json:"id"`
+	Name                string    `json:"name"`
+	PlacementPoints     []int     `json:"placement_points"`
+	ParticipationPoints int       `json:"participation_points"`
+	CreatedBy           *int64    `json:"created_by,omitempty"`
+	CreatedAt           time.Time `json:"created_at"`
+	UpdatedAt           time.Time `json:"updated_at"`
+}
+
+// Bounds on a league's points table: how many places it can score, and the
+// most one place can be worth.
+const (
+	MaxLeaguePlaces = 64
+	MaxLeaguePoints = 1000
+)
+
+// DefaultPlacementPoints is the points table a new league starts with.
+var DefaultPlacementPoints = []int{10, 8, 6, 5, 4, 3, 2, 1}
+
+// PointsFor is what finishing in place (1-based) of a tournament is worth
+// in the league.
+func (l *League) PointsFor(place int) int {
+	if place >= 1 && place <= len(l.PlacementPoints) {
+		return l.PlacementPoints[place-1]
+	}
+	return l.ParticipationPoints
+}
+
+// Validate trims the league's name and checks it has one, and that its
+// points table fits MaxLeaguePlaces and every value is from 0 to
+// MaxLeaguePoints.
+func (l *League) Validate() error {
+	l.Name = strings.TrimSpace(l.Name)
+	if l.Name == "" {
+		return errors.New("name is required")
+	}
+	if len(l.PlacementPoints) > MaxLeaguePlaces {
+		return fmt.Errorf("points can be given for at most %d places", MaxLeaguePlaces)
+	}
+	for _, p := range append([]int{l.ParticipationPoints}, l.PlacementPoints...) {
+		if p < 0 || p > MaxLeaguePoints {
+			return fmt.Errorf("points must be between 0 and %d", MaxLeaguePoints)
+		}
+	}
+	return nil
+}
+
+// ParsePlacementPoints reads a points table typed as a list, first place
+// first, such as "10, 8, 6"; commas, spaces or both separate the values.
+func ParsePlacementPoints(s string) ([]int, error) {
+	fields := strings.FieldsFunc(s, func(r rune) bool { return r == ',' || unicode.IsSpace(r) })
+	points := make([]int, 0, len(fields))
+	for _, f := range fields {
+		p, err := strconv.Atoi(f)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a number of points", f)
+		}
+		points = append(points, p)
+	}
+	return points, nil
+}
+
+// MatchGame is one game of a best-of-N series, identified by round, table and
+// game number. Winner is GameWinnerA, GameWinnerB or GameDraw, relative to the
+// pairing's player A and B. Map and the picks are free-form metadata (stage,
+// character, deck, faction). WentFirst is GameWinnerA or GameWinnerB for the
+// player who went first, or "" when not noted; Notes is free text.
+type MatchGame struct {
+	TournamentID int64     `json:"-"`
+	Round        int       `json:"round"`
+	Table        int       `json:"table"`
+	GameNumber   int       `json:"game_number"`
+	Winner       string    `json:"winner"`
+	WentFirst    string    `json:"went_first,omitempty"`
+	Map          string    `json:"map,omitempty"`
+	PlayerAPick  string    `json:"player_a_pick,omitempty"`
+	PlayerBPick  string    `json:"player_b_pick,omitempty"`
+	Notes        string    `json:"notes,omitempty"`
+	ReportedBy   *int64    `json:"reported_by,omitempty"`
+	ReportedAt   time.Time `json:"reported_at"`
+}
+
+// MaxGameDetail is the length limit, in runes, of a game's map and picks.
+const MaxGameDetail = 100
+
+// MaxGameNotes is the length limit, in runes, of a game's notes.
+const MaxGameNotes = 500
+
+// SeatResult is the result of one seat of a team match: seat N of team A
+// against seat N of team B. Winner is GameWinnerA, GameWinnerB or GameDraw,
+// relative to the pairing's teams.
+type SeatResult struct {
+	TournamentID int64     `json:"-"`
+	Round        int       `json:"round"`
+	Table        int       `json:"table"`
+	Seat         int       `json:"seat"`
+	Winner       string    `json:"winner"`
+	ReportedBy   *int64    `json:"reported_by,omitempty"`
+	ReportedAt   time.Time `json:"reported_at"`
+}
+
+// MatchResultType marks a match result that wasn't played out: Type is
+// ResultIntentionalDraw or ResultConcession. For a concession, ConcededBy is
+// the conceding side, GameWinnerA or GameWinnerB. Plain results have none.
+type MatchResultType struct {
+	TournamentID int64     `json:"-"`
+	Round        int       `json:"round"`
+	Table        int       `json:"table"`
+	Type         string    `json:"type"`
+	ConcededBy   string    `json:"conceded_by,omitempty"`
+	ReportedBy   *int64    `json:"reported_by,omitempty"`
+	ReportedAt   time.Time `json:"reported_at"`
+}
+
+// ResultReport is one player's report of their match result, side
+// GameWinnerA or GameWinnerB of the table. Scores are from player A's side
+// whoever reported: A's games won, B's, and drawn games.
+type ResultReport struct {
+	TournamentID int64     `json:"-"`
+	Round        int       `json:"round"`
+	Table        int       `json:"table"`
+	Side         string    `json:"side"`
+	Wins         int       `json:"wins"`
+	Losses       int       `json:"losses"`
+	Draws        int       `json:"draws"`
+	UserID       *int64    `json:"user_id,omitempty"`
+	ReportedAt   time.Time `json:"reported_at"`
+}
+
+// Agrees reports whether r and o give the same score.
+func (r ResultReport) Agrees(o ResultReport) bool {
+	return r.Wins == o.Wins && r.Losses == o.Losses && r.Draws == o.Draws
+}
+
+// ResultCorrection is a result changed after its round closed. Scores are
+// from player A's side: A's games won, B's, and drawn games, before (Old)
+// and after (New). PairedThrough was the current round when it was made, so
+// rounds Round+1 to PairedThrough were paired with the old result.
+type ResultCorrection struct {
+	ID            int64     `json:"id"`
+	TournamentID  int64     `json:"-"`
+	Round         int       `json:"round"`
+	Table         int       `json:"table"`
+	OldA          int       `json:"old_a"`
+	OldB          int       `json:"old_b"`
+	OldDraws      int       `json:"old_draws"`
+	NewA          int       `json:"new_a"`
+	NewB          int       `json:"new_b"`
+	NewDraws      int       `json:"new_draws"`
+	PairedThrough int       `json:"paired_through"`
+	CorrectedBy   *int64    `json:"corrected_by,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// Affects reports whether round was paired with the result c replaced.
+func (c ResultCorrection) Affects(round int) bool {
+	return round > c.Round && round <= c.PairedThrough
+}
+
+// AssignedBye is a bye staff gave a registration for a round, on top of
+// any bye the pairing hands out. DisplayName and EnginePlayerID come from
+// the registration.
+type AssignedBye struct {
+	TournamentID   int64     `json:"-"`
+	Round          int       `json:"round"`
+	RegistrationID int64     `json:"registration_id"`
 	DisplayName    string    `json:"display_name"`
-	Decklist       []byte    `json:"decklist,omitempty"`
-	Status         string    `json:"status"`
 	EnginePlayerID *int      `json:"engine_player_id,omitempty"`
-	CreatedAt      time.Time `json:"created_at"`
+	AssignedBy     *int64    `json:"assigned_by,omitempty"`
+	AssignedAt     time.Time `json:"assigned_at"`
+}
+
+// TournamentSnapshot is a saved copy of a tournament that staff can roll
+// back to. Reason is SnapshotRound, SnapshotPeriodic or SnapshotRollback;
+// Status and Round are the tournament's at the time, and Size the length
+// of the stored backup in bytes.
+type TournamentSnapshot struct {
+	ID           int64     `json:"id"`
+	TournamentID int64     `json:"tournament_id"`
+	Reason       string    `json:"reason"`
+	Status       string    `json:"status"`
+	Round        int       `json:"round"`
+	Size         int       `json:"size"`
+	CreatedAt    time.Time `json:"created_at"`
 }
 
 // IsGuest reports whether this registration is a guest entry (no user account).
 func (r Registration) IsGuest() bool { return r.UserID == nil }
 
+// ValidPaymentStatus reports whether s is a known payment status.
+func ValidPaymentStatus(s string) bool {
+	return s == PaymentUnpaid || s == PaymentPaid || s == PaymentComped
+}
+
+// MaxPaymentMethod is the length limit, in runes, of a payment method.
+const MaxPaymentMethod = 40
+
+// MaxAmount is the largest entry fee or payment, in minor units.
+const MaxAmount = 100_000_000
+
+// ParseAmount reads a sum of money such as "10", "10.5" or "10,50" into
+// minor units, rejecting more than two decimals, negatives and anything
+// over MaxAmount.
+func ParseAmount(s string) (int, error) {
+	s = strings.TrimSpace(s)
+	whole, frac, _ := strings.Cut(strings.Replace(s, ",", ".", 1), ".")
+	if whole == "" {
+		whole = "0"
+	}
+	bad := fmt.Errorf("%q is not an amount such as 10 or 10.50", s)
+	if s == "" || len(frac) > 2 || strings.ContainsFunc(whole+frac, func(r rune) bool { return r < '0' || r > '9' }) {
+		return 0, bad
+	}
+	w, err := strconv.Atoi(whole)
+	if err != nil || w > MaxAmount/100 {
+		return 0, bad
+	}
+	cents, _ := strconv.Atoi((frac + "00")[:2])
+	if n := w*100 + cents; n <= MaxAmount {
+		return n, nil
+	}
+	return 0, bad
+}
+
+// FormatAmount writes minor units as a decimal amount, "10.50".
+func FormatAmount(n int) string {
+	sign := ""
+	if n < 0 {
+		sign, n = "-", -n
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, n/100, n%100)
+}
+
 type PasswordReset struct {
 	ID        int64     `json:"id"`
 	UserID    int64     `json:"user_id"`
@@ -155,4 +1420,39 @@ const (
 	RegistrationStatusPending   = "pending"
 	RegistrationStatusConfirmed = "confirmed"
 	RegistrationStatusDropped   = "dropped"
+	// RegistrationStatusWaitlisted is a sign-up made once the tournament
+	// was full. It isn't seated until staff promote it.
+	RegistrationStatusWaitlisted = "waitlisted"
+
+	PaymentUnpaid = "unpaid"
+	PaymentPaid   = "paid"
+	// PaymentComped is a player let in without paying.
+	PaymentComped = "comped"
+
+	PairingSwiss      = "swiss"
+	PairingWeighted   = "weighted"
+	PairingRoundRobin = "round_robin"
+	PairingDanish     = "danish"
+
+	ByeLowest = "lowest"
+	ByeRandom = "random"
+
+	Round1Random = "random"
+	Round1Cross  = "cross"
+	Round1Fold   = "fold"
+
+	TiebreakOMW = "omw"
+	TiebreakGW  = "gw"
+	TiebreakOGW = "ogw"
+
+	GameWinnerA = "a"
+	GameWinnerB = "b"
+	GameDraw    = "draw"
+
+	ResultIntentionalDraw = "intentional_draw"
+	ResultConcession      = "concession"
+
+	SnapshotRound    = "round"
+	SnapshotPeriodic = "periodic"
+	SnapshotRollback = "rollback"
 )
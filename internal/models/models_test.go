@@ -1,7 +1,11 @@
 package models
 
 import (
+	"fmt"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestUser_HasRole(t *testing.T) {
@@ -31,6 +35,18 @@ func TestUser_HasRole(t *testing.T) {
 	}
 }
 
+func TestUser_CanOrganize(t *testing.T) {
+	for roles, want := range map[string]bool{
+		RolePlayer:    false,
+		RoleOrganizer: true,
+		RoleAdmin:     true,
+	} {
+		if got := (&User{Roles: []string{roles}}).CanOrganize(); got != want {
+			t.Errorf("CanOrganize with %q = %v, want %v", roles, got, want)
+		}
+	}
+}
+
 func TestTournamentTier_AtLeast(t *testing.T) {
 	tests := []struct {
 		name string
@@ -93,3 +109,484 @@ func TestConstants(t *testing.T) {
 		t.Errorf("RegistrationStatusDropped = %q", RegistrationStatusDropped)
 	}
 }
+
+func TestValidPairingFieldLabel(t *testing.T) {
+	tests := []struct {
+		label string
+		want  bool
+	}{
+		{"Stream", true},
+		{"  Board  ", true},
+		{"", false},
+		{"   ", false},
+		{strings.Repeat("é", MaxPairingFieldLabel), true},
+		{strings.Repeat("é", MaxPairingFieldLabel+1), false},
+	}
+	for _, tt := range tests {
+		if got := ValidPairingFieldLabel(tt.label); got != tt.want {
+			t.Errorf("ValidPairingFieldLabel(%q) = %v, want %v", tt.label, got, tt.want)
+		}
+	}
+}
+
+func TestTournament_ValidateMatchFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		t       Tournament
+		wantErr bool
+	}{
+		{"unspecified", Tournament{}, false},
+		{"bo3", Tournament{BestOf: 3}, false},
+		{"bo3 series", Tournament{BestOf: 3, SeriesMode: true}, false},
+		{"series without best_of", Tournament{SeriesMode: true}, true},
+		{"negative", Tournament{BestOf: -1}, true},
+		{"too long", Tournament{BestOf: MaxBestOf + 1}, true},
+		{"teams of 3", Tournament{TeamSize: 3, BestOf: 3}, false},
+		{"team of 1", Tournament{TeamSize: 1}, true},
+		{"team too big", Tournament{TeamSize: MaxTeamSize + 1}, true},
+		{"team series", Tournament{TeamSize: 3, BestOf: 3, SeriesMode: true}, true},
+		{"colours", Tournament{Colors: true}, false},
+		{"team colours", Tournament{TeamSize: 3, Colors: true}, true},
+		{"player reporting", Tournament{SelfReport: true, ReportConfirmMinutes: 10}, false},
+		{"negative report wait", Tournament{ReportConfirmMinutes: -1}, true},
+		{"report wait too long", Tournament{ReportConfirmMinutes: MaxReportConfirmMinutes + 1}, true},
+		{"series player reporting", Tournament{SelfReport: true, BestOf: 3, SeriesMode: true}, true},
+		{"team player reporting", Tournament{SelfReport: true, TeamSize: 3}, true},
+	}
+	for _, tt := range tests {
+		if err := tt.t.ValidateMatchFormat(); (err != nil) != tt.wantErr {
+			t.Errorf("%s: err = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestTournament_ValidateClubRounds(t *testing.T) {
+	for rounds, wantErr := range map[int]bool{-1: true, 0: false, 3: false} {
+		tourn := Tournament{AvoidClubRounds: rounds}
+		if err := tourn.ValidateClubRounds(); (err != nil) != wantErr {
+			t.Errorf("%d rounds: err = %v, wantErr %v", rounds, err, wantErr)
+		}
+	}
+}
+
+func TestTournament_ValidatePlayerLimits(t *testing.T) {
+	tests := []struct {
+		name    string
+		t       Tournament
+		wantErr bool
+	}{
+		{"default", Tournament{MinPlayers: DefaultMinPlayers}, false},
+		{"unset", Tournament{}, true},
+		{"one", Tournament{MinPlayers: 1}, true},
+		{"under max", Tournament{MinPlayers: 8, MaxPlayers: 16}, false},
+		{"equals max", Tournament{MinPlayers: 16, MaxPlayers: 16}, false},
+		{"over max", Tournament{MinPlayers: 17, MaxPlayers: 16}, true},
+		{"unlimited max", Tournament{MinPlayers: 64}, false},
+		{"pods advance", Tournament{MinPlayers: 2, PodAdvance: 4}, false},
+		{"negative advance", Tournament{MinPlayers: 2, PodAdvance: -1}, true},
+		{"pod advancing", Tournament{MinPlayers: 2, PodAdvance: 1, ParentID: new(int64)}, true},
+	}
+	for _, tt := range tests {
+		if err := tt.t.ValidatePlayerLimits(); (err != nil) != tt.wantErr {
+			t.Errorf("%s: err = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestTournament_ValidateTimeZone(t *testing.T) {
+	for tz, ok := range map[string]bool{
+		"UTC":           true,
+		"Europe/Berlin": true,
+		"":              false,
+		"Local":         false,
+		"Mars/Olympus":  false,
+	} {
+		tm := Tournament{TimeZone: tz}
+		if err := tm.ValidateTimeZone(); (err == nil) != ok {
+			t.Errorf("%q: err = %v", tz, err)
+		}
+	}
+	if got := (&Tournament{TimeZone: "Mars/Olympus"}).Zone(); got != time.UTC {
+		t.Errorf("Zone of an unknown time zone = %v, want UTC", got)
+	}
+}
+
+func TestTournament_PairingsDraft(t *testing.T) {
+	tm := Tournament{PreviewPairings: true, DraftRound: 2}
+	if !tm.PairingsDraft(2) || tm.PairingsDraft(1) || tm.PairingsDraft(3) {
+		t.Errorf("draft round 2: PairingsDraft = %v, %v, %v", tm.PairingsDraft(1), tm.PairingsDraft(2), tm.PairingsDraft(3))
+	}
+	if (&Tournament{PreviewPairings: true}).PairingsDraft(0) {
+		t.Error("no round is a draft before the tournament starts")
+	}
+	// Turning previews off shows a waiting draft.
+	if (&Tournament{DraftRound: 2}).PairingsDraft(2) {
+		t.Error("draft without PreviewPairings")
+	}
+}
+
+func TestValidateSchedule(t *testing.T) {
+	at := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	tooMany := make([]ScheduleItem, MaxScheduleItems+1)
+	for i := range tooMany {
+		tooMany[i] = ScheduleItem{StartsAt: at, Label: "Round"}
+	}
+	tests := []struct {
+		name    string
+		items   []ScheduleItem
+		wantErr bool
+	}{
+		{"empty", nil, false},
+		{"round", []ScheduleItem{{StartsAt: at, Label: "Round 1"}}, false},
+		{"blank label", []ScheduleItem{{StartsAt: at, Label: " "}}, true},
+		{"long label", []ScheduleItem{{StartsAt: at, Label: strings.Repeat("é", MaxScheduleLabel+1)}}, true},
+		{"too many", tooMany, true},
+	}
+	for _, tt := range tests {
+		if err := ValidateSchedule(tt.items); (err != nil) != tt.wantErr {
+			t.Errorf("%s: err = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestValidateAnnouncement(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		minutes int
+		wantErr bool
+	}{
+		{"until ended", "Round 3 is delayed 10 minutes", 0, false},
+		{"an hour", "Lunch until 13:00", 60, false},
+		{"blank", "  ", 10, true},
+		{"long", strings.Repeat("é", MaxAnnouncementLength+1), 0, true},
+		{"negative", "Delay", -1, true},
+		{"too long-lived", "Delay", MaxAnnouncementMinutes + 1, true},
+	}
+	for _, tt := range tests {
+		if err := ValidateAnnouncement(tt.message, tt.minutes); (err != nil) != tt.wantErr {
+			t.Errorf("%s: err = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestValidatePlayerName(t *testing.T) {
+	tests := []struct {
+		name, in, want string
+		wantErr        bool
+	}{
+		{"plain", "Ann Smith", "Ann Smith", false},
+		{"whitespace", "  Ann \t\n Smith ", "Ann Smith", false},
+		{"decomposed accent", "Jose\u0301", "José", false},
+		{"control and zero-width", "An\u0000n\u200b \u202eSmith", "Ann Smith", false},
+		{"blank", " \u200b ", "", true},
+		{"long", strings.Repeat("é", MaxPlayerNameLength+1), "", true},
+		{"just long enough", strings.Repeat("é", MaxPlayerNameLength), strings.Repeat("é", MaxPlayerNameLength), false},
+		{"bye", " BYE ", "", true},
+		{"bye in a name", "Bye Felicia", "Bye Felicia", false},
+	}
+	for _, tt := range tests {
+		got, err := ValidatePlayerName(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("%s: got %q, %v; want %q, error %v", tt.name, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestSameNameRegistration(t *testing.T) {
+	regs := []Registration{
+		{ID: 1, DisplayName: "Ann Smith", Status: RegistrationStatusConfirmed},
+		{ID: 2, DisplayName: "José", Status: RegistrationStatusPending},
+	}
+	for _, tt := range []struct {
+		name string
+		want int64
+	}{
+		{"ann  SMITH", 1},
+		{"ＡＮＮ Smith", 1},
+		{"JOSE\u0301", 2},
+		{"Jose", 0},
+		{"Anne Smith", 0},
+	} {
+		var got int64
+		if reg := SameNameRegistration(regs, tt.name); reg != nil {
+			got = reg.ID
+		}
+		if got != tt.want {
+			t.Errorf("%q: matched registration %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSimilarPlayerName(t *testing.T) {
+	for _, tt := range []struct {
+		a, b string
+		want bool
+	}{
+		{"Jon Smith", "John Smith", true},
+		{"José García", "Jose Garcia", true},
+		{"Smith, John", "john smith", true},
+		{"Ann", "Ann (2)", true},
+		{"Bob", "bob", true},
+		{"Bob", "Rob", false},
+		{"Ann Smith", "Dan Smith", false},
+		{"Alexandra Johnson", "Alexsandra Jonson", true},
+		{"Alexandra Johnson", "Alexander Jameson", false},
+		{"", "", false},
+	} {
+		if got := SimilarPlayerName(tt.a, tt.b); got != tt.want {
+			t.Errorf("SimilarPlayerName(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestAnnouncement_Active(t *testing.T) {
+	now := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	later, earlier := now.Add(time.Minute), now.Add(-time.Minute)
+	for _, tt := range []struct {
+		expires *time.Time
+		want    bool
+	}{{nil, true}, {&later, true}, {&now, false}, {&earlier, false}} {
+		a := Announcement{ExpiresAt: tt.expires}
+		if got := a.Active(now); got != tt.want {
+			t.Errorf("expires %v: Active = %v, want %v", tt.expires, got, tt.want)
+		}
+	}
+}
+
+func TestTournament_ValidateTiebreakers(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{"", "", false},
+		{"gw, OMW", "gw,omw", false},
+		{"ogw omw,gw", "ogw,omw,gw", false},
+		{"omw,buchholz", "", true},
+		{"gw, gw", "", true},
+	}
+	for _, tt := range tests {
+		tour := Tournament{Tiebreakers: ParseTiebreakers(tt.in)}
+		err := tour.ValidateTiebreakers()
+		if (err != nil) != tt.wantErr || !tt.wantErr && strings.Join(tour.Tiebreakers, ",") != tt.want {
+			t.Errorf("%q: got %v, err = %v; want %q", tt.in, tour.Tiebreakers, err, tt.want)
+		}
+	}
+
+	var tour Tournament
+	if got := strings.Join(tour.TiebreakOrder(), ","); got != "omw,gw,ogw" {
+		t.Errorf("default order = %s", got)
+	}
+	tour.Tiebreakers = []string{TiebreakGW}
+	if got := strings.Join(tour.TiebreakOrder(), ","); got != "gw" {
+		t.Errorf("order = %s, want gw", got)
+	}
+}
+
+func TestTournamentDefaults(t *testing.T) {
+	if err := StandardDefaults.Validate(); err != nil {
+		t.Fatalf("standard defaults invalid: %v", err)
+	}
+	if got := StandardDefaults.TiebreakerText(); got != "omw, gw, ogw" {
+		t.Errorf("standard tiebreakers = %q", got)
+	}
+
+	d := TournamentDefaults{TimeZone: "Europe/Berlin", MaxPlayers: 32, PointsWin: 2, PointsDraw: 1, Tiebreakers: []string{TiebreakGW, TiebreakOMW}}
+	var tour Tournament
+	d.Apply(&tour)
+	if tour.TimeZone != "Europe/Berlin" || tour.MaxPlayers != 32 || tour.PointsWin != 2 || tour.PointsDraw != 1 || tour.PointsLoss != 0 {
+		t.Errorf("applied = %+v", tour)
+	}
+	tour.Tiebreakers[0] = TiebreakOGW
+	if d.Tiebreakers[0] != TiebreakGW {
+		t.Error("Apply shares the defaults' tiebreakers with the tournament")
+	}
+	if got := d.TiebreakerText(); got != "gw, omw" {
+		t.Errorf("tiebreakers = %q", got)
+	}
+
+	for _, bad := range []TournamentDefaults{
+		{TimeZone: "Mars/Olympus"},
+		{TimeZone: "UTC", PointsWin: -1},
+		{TimeZone: "UTC", MaxPlayers: 1},
+		{TimeZone: "UTC", Tiebreakers: []string{"buchholz"}},
+	} {
+		if err := bad.Validate(); err == nil {
+			t.Errorf("%+v: no error", bad)
+		}
+	}
+}
+
+func TestAvatar(t *testing.T) {
+	if !ValidAvatarIcon("owl") || ValidAvatarIcon("") || ValidAvatarIcon("unicorn") {
+		t.Error("ValidAvatarIcon accepts the wrong names")
+	}
+	icon := Avatar{Icon: "owl"}
+	if got := icon.Emoji(); got != "🦉" {
+		t.Errorf("Emoji = %q", got)
+	}
+	image := Avatar{Image: []byte{1}, UpdatedAt: time.Unix(1700000000, 0)}
+	if image.Emoji() != "" || image.Version() != 1700000000 {
+		t.Errorf("image avatar: emoji %q, version %d", image.Emoji(), image.Version())
+	}
+	seen := map[string]bool{}
+	for _, icon := range AvatarIcons {
+		if seen[icon.Name] || icon.Emoji == "" {
+			t.Errorf("icon %q repeated or without emoji", icon.Name)
+		}
+		seen[icon.Name] = true
+	}
+}
+
+func TestValidAPIKeyScope(t *testing.T) {
+	for scope, want := range map[string]bool{"read": true, "read_write": true, "": false, "write": false} {
+		if got := ValidAPIKeyScope(scope); got != want {
+			t.Errorf("ValidAPIKeyScope(%q) = %v, want %v", scope, got, want)
+		}
+	}
+}
+
+func TestResultCorrection_Affects(t *testing.T) {
+	c := ResultCorrection{Round: 2, PairedThrough: 4}
+	for round, want := range map[int]bool{1: false, 2: false, 3: true, 4: true, 5: false} {
+		if got := c.Affects(round); got != want {
+			t.Errorf("Affects(%d) = %v, want %v", round, got, want)
+		}
+	}
+}
+
+func TestLeague_PointsTable(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{"", "[]", false},
+		{"10, 8,6\n5", "[10 8 6 5]", false},
+		{"10 eight", "", true},
+		{"10, -1", "", true},
+		{"1001", "", true},
+	}
+	for _, tt := range tests {
+		points, err := ParsePlacementPoints(tt.in)
+		if err == nil {
+			l := League{Name: "Season", PlacementPoints: points}
+			err = l.Validate()
+		}
+		if (err != nil) != tt.wantErr || !tt.wantErr && fmt.Sprint(points) != tt.want {
+			t.Errorf("%q: got %v, err = %v; want %s", tt.in, points, err, tt.want)
+		}
+	}
+	if err := (&League{Name: " "}).Validate(); err == nil {
+		t.Error("Validate accepted a blank name")
+	}
+	if err := (&League{Name: "Season", PlacementPoints: make([]int, MaxLeaguePlaces+1)}).Validate(); err == nil {
+		t.Error("Validate accepted too many places")
+	}
+
+	l := League{PlacementPoints: []int{10, 8, 6}, ParticipationPoints: 1}
+	for place, want := range map[int]int{1: 10, 3: 6, 4: 1, 40: 1} {
+		if got := l.PointsFor(place); got != want {
+			t.Errorf("PointsFor(%d) = %d, want %d", place, got, want)
+		}
+	}
+}
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    int
+		wantErr bool
+	}{
+		{"10", 1000, false},
+		{" 10.5 ", 1050, false},
+		{"10,50", 1050, false},
+		{".99", 99, false},
+		{"0", 0, false},
+		{"1000000", MaxAmount, false},
+		{"", 0, true},
+		{"10.505", 0, true},
+		{"-5", 0, true},
+		{"1e3", 0, true},
+		{"1.2.3", 0, true},
+		{"1000000.01", 0, true},
+		{"99999999999999999999", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseAmount(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseAmount(%q) = %d, %v; want %d, error %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+	for n, want := range map[int]string{0: "0.00", 5: "0.05", 1050: "10.50", -250: "-2.50"} {
+		if got := FormatAmount(n); got != want {
+			t.Errorf("FormatAmount(%d) = %q, want %q", n, got, want)
+		}
+	}
+	if ValidPaymentStatus("refunded") || !ValidPaymentStatus(PaymentComped) {
+		t.Error("ValidPaymentStatus: wrong set of statuses")
+	}
+}
+
+func TestPresetSettings(t *testing.T) {
+	rounds := 5
+	src := &Tournament{
+		Name: "Spring Open", TimeZone: "Europe/Berlin", EntryFee: 1000, MaxPlayers: 32, MinPlayers: 4,
+		NumRounds: &rounds, TopCut: 8, RequireDecklist: true, PointsWin: 3, PointsDraw: 1,
+		Tiebreakers: []string{TiebreakGW, TiebreakOMW}, PairingAlgorithm: PairingWeighted,
+		ByePolicy: ByeRandom, Round1Pairing: Round1Random, BestOf: 3, SelfReport: true,
+		ReportConfirmMinutes: 10, BotCheck: true,
+	}
+	p := PresetOf(src, []PairingField{{Label: "Stream", Public: true}})
+	if len(p.PairingFields) != 1 || p.PairingFields[0] != (PresetField{Label: "Stream", Public: true}) {
+		t.Errorf("pairing fields = %+v", p.PairingFields)
+	}
+
+	dst := &Tournament{Name: "Summer Open", Colors: true}
+	p.Apply(dst)
+	if dst.Name != "Summer Open" || dst.Colors {
+		t.Errorf("Apply touched the name or left colours on: %+v", dst)
+	}
+	src.Name = dst.Name
+	if !reflect.DeepEqual(src, dst) {
+		t.Errorf("applied preset = %+v, want %+v", dst, src)
+	}
+	// The tournaments don't share the preset's round count or tiebreakers.
+	*dst.NumRounds = 7
+	dst.Tiebreakers[0] = TiebreakOGW
+	if *p.NumRounds != 5 || p.Tiebreakers[0] != TiebreakGW {
+		t.Error("Apply shares the preset's slices and pointers")
+	}
+
+	if err := p.Validate(); err != nil {
+		t.Errorf("Validate: %v", err)
+	}
+	p.PairingFields = []PresetField{{Label: " Judge "}}
+	if err := p.Validate(); err != nil || p.PairingFields[0].Label != "Judge" {
+		t.Errorf("Validate = %v, label %q; want the label trimmed", err, p.PairingFields[0].Label)
+	}
+	for name, spoil := range map[string]func(*PresetSettings){
+		"pairing algorithm": func(p *PresetSettings) { p.PairingAlgorithm = "monrad" },
+		"time zone":         func(p *PresetSettings) { p.TimeZone = "" },
+		"min players":       func(p *PresetSettings) { p.MinPlayers = 1 },
+		"rounds":            func(p *PresetSettings) { zero := 0; p.NumRounds = &zero },
+		"points":            func(p *PresetSettings) { p.PointsWin = -3 },
+		"series":            func(p *PresetSettings) { p.BestOf, p.SeriesMode = 0, true },
+		"field label":       func(p *PresetSettings) { p.PairingFields = []PresetField{{Label: " "}} },
+		"field twice":       func(p *PresetSettings) { p.PairingFields = []PresetField{{Label: "Judge"}, {Label: "Judge "}} },
+	} {
+		bad := PresetOf(src, nil)
+		spoil(&bad)
+		if bad.Validate() == nil {
+			t.Errorf("%s: accepted", name)
+		}
+	}
+
+	if _, err := ValidatePresetName("  "); err == nil {
+		t.Error("blank preset name accepted")
+	}
+	if name, err := ValidatePresetName(" Weekly Modern "); err != nil || name != "Weekly Modern" {
+		t.Errorf("ValidatePresetName = %q, %v", name, err)
+	}
+}
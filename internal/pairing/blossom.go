@@ -0,0 +1,606 @@
+package pairing
+
+// Edge is an undirected, weighted edge between vertices I and J for
+// MaxWeightMatching. Vertices are numbered 0..n-1.
+type Edge struct {
+	I, J   int
+	Weight int64
+}
+
+// MaxWeightMatching computes a maximum-weight matching of the general graph
+// described by edges using Edmonds' blossom algorithm with the primal-dual
+// method (O(n^3)). When maxCardinality is true the result is the heaviest of
+// the maximum-cardinality matchings, which is what pairing needs: everyone
+// gets an opponent, and among those assignments the cheapest wins.
+//
+// The returned slice has one entry per vertex: the vertex it is matched to,
+// or -1 if it is unmatched.
+//
+// This is a port of Joris van Rantwijk's mwmatching.py (public domain),
+// which follows Galil, "Efficient algorithms for finding maximum matching
+// in graphs" (1986). Weights are doubled internally so the dual variables
+// stay integral.
+func MaxWeightMatching(edges []Edge, maxCardinality bool) []int {
+	if len(edges) == 0 {
+		return nil
+	}
+	m := newMatcher(edges, maxCardinality)
+	m.run()
+	return m.result()
+}
+
+type matcher struct {
+	edges          []Edge
+	maxCardinality bool
+	nvertex        int
+
+	// endpoint[p] is the vertex at endpoint p; edge k has endpoints 2k and
+	// 2k+1. neighbend[v] lists the remote endpoints of v's edges.
+	endpoint  []int
+	neighbend [][]int
+
+	// mate[v] is the remote endpoint of v's matched edge, or -1.
+	mate []int
+
+	// Per top-level blossom (and vertex): label 0 = free, 1 = S, 2 = T;
+	// labelend is the endpoint through which the label was assigned.
+	label    []int
+	labelend []int
+
+	inblossom        []int
+	blossomparent    []int
+	blossomchilds    [][]int
+	blossombase      []int
+	blossomendps     [][]int
+	bestedge         []int
+	blossombestedges [][]int
+	unusedblossoms   []int
+	dualvar          []int64
+	allowedge        []bool
+	queue            []int
+}
+
+func newMatcher(edges []Edge, maxCardinality bool) *matcher {
+	m := &matcher{maxCardinality: maxCardinality}
+	var maxweight int64
+	m.edges = make([]Edge, len(edges))
+	for k, e := range edges {
+		m.edges[k] = Edge{I: e.I, J: e.J, Weight: 2 * e.Weight}
+		m.nvertex = max(m.nvertex, e.I+1, e.J+1)
+		maxweight = max(maxweight, 2*e.Weight)
+	}
+	n := m.nvertex
+
+	m.endpoint = make([]int, 2*len(edges))
+	m.neighbend = make([][]int, n)
+	for k, e := range m.edges {
+		m.endpoint[2*k] = e.I
+		m.endpoint[2*k+1] = e.J
+		m.neighbend[e.I] = append(m.neighbend[e.I], 2*k+1)
+		m.neighbend[e.J] = append(m.neighbend[e.J], 2*k)
+	}
+
+	m.mate = filled(n, -1)
+	m.label = make([]int, 2*n)
+	m.labelend = filled(2*n, -1)
+	m.inblossom = make([]int, n)
+	m.blossomparent = filled(2*n, -1)
+	m.blossomchilds = make([][]int, 2*n)
+	m.blossombase = filled(2*n, -1)
+	m.blossomendps = make([][]int, 2*n)
+	m.bestedge = filled(2*n, -1)
+	m.blossombestedges = make([][]int, 2*n)
+	m.dualvar = make([]int64, 2*n)
+	m.allowedge = make([]bool, len(edges))
+	for v := 0; v < n; v++ {
+		m.inblossom[v] = v
+		m.blossombase[v] = v
+		m.dualvar[v] = maxweight
+		m.unusedblossoms = append(m.unusedblossoms, n+v)
+	}
+	return m
+}
+
+func filled(n, v int) []int {
+	s := make([]int, n)
+	for i := range s {
+		s[i] = v
+	}
+	return s
+}
+
+func (m *matcher) slack(k int) int64 {
+	e := m.edges[k]
+	return m.dualvar[e.I] + m.dualvar[e.J] - 2*e.Weight
+}
+
+// leaves returns the vertices contained in blossom b.
+func (m *matcher) leaves(b int) []int {
+	if b < m.nvertex {
+		return []int{b}
+	}
+	var out []int
+	for _, t := range m.blossomchilds[b] {
+		out = append(out, m.leaves(t)...)
+	}
+	return out
+}
+
+// assignLabel labels the top-level blossom containing w with t, reached
+// through endpoint p. S-blossoms are queued for scanning; a T-blossom's
+// mate is labelled S in turn.
+func (m *matcher) assignLabel(w, t, p int) {
+	b := m.inblossom[w]
+	m.label[w], m.label[b] = t, t
+	m.labelend[w], m.labelend[b] = p, p
+	m.bestedge[w], m.bestedge[b] = -1, -1
+	if t == 1 {
+		m.queue = append(m.queue, m.leaves(b)...)
+	} else if t == 2 {
+		base := m.blossombase[b]
+		m.assignLabel(m.endpoint[m.mate[base]], 1, m.mate[base]^1)
+	}
+}
+
+// scanBlossom traces back from v and w to find either a new blossom (its
+// base vertex is returned) or an augmenting path (-1 is returned).
+func (m *matcher) scanBlossom(v, w int) int {
+	var path []int
+	base := -1
+	for v != -1 || w != -1 {
+		b := m.inblossom[v]
+		if m.label[b]&4 != 0 {
+			base = m.blossombase[b]
+			break
+		}
+		path = append(path, b)
+		m.label[b] = 5
+		if m.labelend[b] == -1 {
+			v = -1
+		} else {
+			v = m.endpoint[m.labelend[b]]
+			b = m.inblossom[v]
+			v = m.endpoint[m.labelend[b]]
+		}
+		if w != -1 {
+			v, w = w, v
+		}
+	}
+	for _, b := range path {
+		m.label[b] = 1
+	}
+	return base
+}
+
+// addBlossom creates a new blossom with the given base through edge k,
+// which connects two S-vertices.
+func (m *matcher) addBlossom(base, k int) {
+	v, w := m.edges[k].I, m.edges[k].J
+	bb := m.inblossom[base]
+	bv := m.inblossom[v]
+	bw := m.inblossom[w]
+
+	b := m.unusedblossoms[len(m.unusedblossoms)-1]
+	m.unusedblossoms = m.unusedblossoms[:len(m.unusedblossoms)-1]
+	m.blossombase[b] = base
+	m.blossomparent[b] = -1
+	m.blossomparent[bb] = b
+
+	var path, endps []int
+	for bv != bb {
+		m.blossomparent[bv] = b
+		path = append(path, bv)
+		endps = append(endps, m.labelend[bv])
+		v = m.endpoint[m.labelend[bv]]
+		bv = m.inblossom[v]
+	}
+	path = append(path, bb)
+	reverse(path)
+	reverse(endps)
+	endps = append(endps, 2*k)
+	for bw != bb {
+		m.blossomparent[bw] = b
+		path = append(path, bw)
+		endps = append(endps, m.labelend[bw]^1)
+		w = m.endpoint[m.labelend[bw]]
+		bw = m.inblossom[w]
+	}
+	m.blossomchilds[b] = path
+	m.blossomendps[b] = endps
+
+	m.label[b] = 1
+	m.labelend[b] = m.labelend[bb]
+	m.dualvar[b] = 0
+	for _, v := range m.leaves(b) {
+		if m.label[m.inblossom[v]] == 2 {
+			// Former T-vertices are now S-vertices and need scanning.
+			m.queue = append(m.queue, v)
+		}
+		m.inblossom[v] = b
+	}
+
+	// Compute the least-slack edges from the new blossom to each
+	// neighbouring S-blossom.
+	bestedgeto := filled(2*m.nvertex, -1)
+	for _, bv := range path {
+		var nblists [][]int
+		if m.blossombestedges[bv] == nil {
+			for _, v := range m.leaves(bv) {
+				nb := make([]int, len(m.neighbend[v]))
+				for i, p := range m.neighbend[v] {
+					nb[i] = p / 2
+				}
+				nblists = append(nblists, nb)
+			}
+		} else {
+			nblists = [][]int{m.blossombestedges[bv]}
+		}
+		for _, nblist := range nblists {
+			for _, k := range nblist {
+				i, j := m.edges[k].I, m.edges[k].J
+				if m.inblossom[j] == b {
+					i, j = j, i
+				}
+				_ = i
+				bj := m.inblossom[j]
+				if bj != b && m.label[bj] == 1 &&
+					(bestedgeto[bj] == -1 || m.slack(k) < m.slack(bestedgeto[bj])) {
+					bestedgeto[bj] = k
+				}
+			}
+		}
+		m.blossombestedges[bv] = nil
+		m.bestedge[bv] = -1
+	}
+	var best []int
+	for _, k := range bestedgeto {
+		if k != -1 {
+			best = append(best, k)
+		}
+	}
+	m.blossombestedges[b] = best
+	m.bestedge[b] = -1
+	for _, k := range best {
+		if m.bestedge[b] == -1 || m.slack(k) < m.slack(m.bestedge[b]) {
+			m.bestedge[b] = k
+		}
+	}
+}
+
+// expandBlossom turns the sub-blossoms of b back into top-level blossoms.
+func (m *matcher) expandBlossom(b int, endstage bool) {
+	for _, s := range m.blossomchilds[b] {
+		m.blossomparent[s] = -1
+		if s < m.nvertex {
+			m.inblossom[s] = s
+		} else if endstage && m.dualvar[s] == 0 {
+			m.expandBlossom(s, endstage)
+		} else {
+			for _, v := range m.leaves(s) {
+				m.inblossom[v] = s
+			}
+		}
+	}
+
+	// Mid-stage expansion of a T-blossom: relabel the sub-blossoms along
+	// the even-length path from the entry child to the base.
+	if !endstage && m.label[b] == 2 {
+		childs := m.blossomchilds[b]
+		endps := m.blossomendps[b]
+		at := func(s []int, i int) int { return s[((i%len(s))+len(s))%len(s)] }
+
+		entrychild := m.inblossom[m.endpoint[m.labelend[b]^1]]
+		j := indexOf(childs, entrychild)
+		var jstep, endptrick int
+		if j&1 != 0 {
+			j -= len(childs)
+			jstep, endptrick = 1, 0
+		} else {
+			jstep, endptrick = -1, 1
+		}
+		p := m.labelend[b]
+		for j != 0 {
+			m.label[m.endpoint[p^1]] = 0
+			m.label[m.endpoint[at(endps, j-endptrick)^endptrick^1]] = 0
+			m.assignLabel(m.endpoint[p^1], 2, p)
+			m.allowedge[at(endps, j-endptrick)/2] = true
+			j += jstep
+			p = at(endps, j-endptrick) ^ endptrick
+			m.allowedge[p/2] = true
+			j += jstep
+		}
+		bv := at(childs, j)
+		m.label[m.endpoint[p^1]], m.label[bv] = 2, 2
+		m.labelend[m.endpoint[p^1]], m.labelend[bv] = p, p
+		m.bestedge[bv] = -1
+		j += jstep
+		for at(childs, j) != entrychild {
+			bv := at(childs, j)
+			if m.label[bv] == 1 {
+				j += jstep
+				continue
+			}
+			for _, v := range m.leaves(bv) {
+				if m.label[v] != 0 {
+					m.label[v] = 0
+					m.label[m.endpoint[m.mate[m.blossombase[bv]]]] = 0
+					m.assignLabel(v, 2, m.labelend[v])
+					break
+				}
+			}
+			j += jstep
+		}
+	}
+
+	m.label[b], m.labelend[b] = -1, -1
+	m.blossomchilds[b], m.blossomendps[b] = nil, nil
+	m.blossombase[b] = -1
+	m.blossombestedges[b] = nil
+	m.bestedge[b] = -1
+	m.unusedblossoms = append(m.unusedblossoms, b)
+}
+
+// augmentBlossom swaps matched and unmatched edges inside blossom b along
+// the path from vertex v to the base, making v the new base.
+func (m *matcher) augmentBlossom(b, v int) {
+	t := v
+	for m.blossomparent[t] != b {
+		t = m.blossomparent[t]
+	}
+	if t >= m.nvertex {
+		m.augmentBlossom(t, v)
+	}
+	childs := m.blossomchilds[b]
+	endps := m.blossomendps[b]
+	at := func(s []int, i int) int { return s[((i%len(s))+len(s))%len(s)] }
+
+	i := indexOf(childs, t)
+	j := i
+	var jstep, endptrick int
+	if i&1 != 0 {
+		j -= len(childs)
+		jstep, endptrick = 1, 0
+	} else {
+		jstep, endptrick = -1, 1
+	}
+	for j != 0 {
+		j += jstep
+		t = at(childs, j)
+		p := at(endps, j-endptrick) ^ endptrick
+		if t >= m.nvertex {
+			m.augmentBlossom(t, m.endpoint[p])
+		}
+		j += jstep
+		t = at(childs, j)
+		if t >= m.nvertex {
+			m.augmentBlossom(t, m.endpoint[p^1])
+		}
+		m.mate[m.endpoint[p]] = p ^ 1
+		m.mate[m.endpoint[p^1]] = p
+	}
+	m.blossomchilds[b] = append(append([]int{}, childs[i:]...), childs[:i]...)
+	m.blossomendps[b] = append(append([]int{}, endps[i:]...), endps[:i]...)
+	m.blossombase[b] = m.blossombase[m.blossomchilds[b][0]]
+}
+
+// augmentMatching flips the augmenting path through edge k.
+func (m *matcher) augmentMatching(k int) {
+	v, w := m.edges[k].I, m.edges[k].J
+	for _, sp := range [2][2]int{{v, 2*k + 1}, {w, 2 * k}} {
+		s, p := sp[0], sp[1]
+		for {
+			bs := m.inblossom[s]
+			if bs >= m.nvertex {
+				m.augmentBlossom(bs, s)
+			}
+			m.mate[s] = p
+			if m.labelend[bs] == -1 {
+				break
+			}
+			t := m.endpoint[m.labelend[bs]]
+			bt := m.inblossom[t]
+			s = m.endpoint[m.labelend[bt]]
+			j := m.endpoint[m.labelend[bt]^1]
+			if bt >= m.nvertex {
+				m.augmentBlossom(bt, j)
+			}
+			m.mate[j] = m.labelend[bt]
+			p = m.labelend[bt] ^ 1
+		}
+	}
+}
+
+func (m *matcher) run() {
+	n := m.nvertex
+	for stage := 0; stage < n; stage++ {
+		for i := range m.label {
+			m.label[i] = 0
+			m.bestedge[i] = -1
+		}
+		for b := n; b < 2*n; b++ {
+			m.blossombestedges[b] = nil
+		}
+		for k := range m.allowedge {
+			m.allowedge[k] = false
+		}
+		m.queue = m.queue[:0]
+
+		for v := 0; v < n; v++ {
+			if m.mate[v] == -1 && m.label[m.inblossom[v]] == 0 {
+				m.assignLabel(v, 1, -1)
+			}
+		}
+
+		augmented := false
+		for {
+			for len(m.queue) > 0 && !augmented {
+				v := m.queue[len(m.queue)-1]
+				m.queue = m.queue[:len(m.queue)-1]
+				for _, p := range m.neighbend[v] {
+					k := p / 2
+					w := m.endpoint[p]
+					if m.inblossom[v] == m.inblossom[w] {
+						continue
+					}
+					var kslack int64
+					if !m.allowedge[k] {
+						kslack = m.slack(k)
+						if kslack <= 0 {
+							m.allowedge[k] = true
+						}
+					}
+					if m.allowedge[k] {
+						switch {
+						case m.label[m.inblossom[w]] == 0:
+							m.assignLabel(w, 2, p^1)
+						case m.label[m.inblossom[w]] == 1:
+							if base := m.scanBlossom(v, w); base >= 0 {
+								m.addBlossom(base, k)
+							} else {
+								m.augmentMatching(k)
+								augmented = true
+							}
+						case m.label[w] == 0:
+							m.label[w] = 2
+							m.labelend[w] = p ^ 1
+						}
+						if augmented {
+							break
+						}
+					} else if m.label[m.inblossom[w]] == 1 {
+						b := m.inblossom[v]
+						if m.bestedge[b] == -1 || kslack < m.slack(m.bestedge[b]) {
+							m.bestedge[b] = k
+						}
+					} else if m.label[w] == 0 {
+						if m.bestedge[w] == -1 || kslack < m.slack(m.bestedge[w]) {
+							m.bestedge[w] = k
+						}
+					}
+				}
+			}
+			if augmented {
+				break
+			}
+
+			// No augmenting path yet: find the smallest dual adjustment
+			// that makes progress.
+			deltatype := -1
+			var delta int64
+			deltaedge, deltablossom := -1, -1
+			if !m.maxCardinality {
+				deltatype = 1
+				delta = minDual(m.dualvar[:n])
+			}
+			for v := 0; v < n; v++ {
+				if m.label[m.inblossom[v]] == 0 && m.bestedge[v] != -1 {
+					d := m.slack(m.bestedge[v])
+					if deltatype == -1 || d < delta {
+						delta, deltatype, deltaedge = d, 2, m.bestedge[v]
+					}
+				}
+			}
+			for b := 0; b < 2*n; b++ {
+				if m.blossomparent[b] == -1 && m.label[b] == 1 && m.bestedge[b] != -1 {
+					d := m.slack(m.bestedge[b]) / 2
+					if deltatype == -1 || d < delta {
+						delta, deltatype, deltaedge = d, 3, m.bestedge[b]
+					}
+				}
+			}
+			for b := n; b < 2*n; b++ {
+				if m.blossombase[b] >= 0 && m.blossomparent[b] == -1 && m.label[b] == 2 &&
+					(deltatype == -1 || m.dualvar[b] < delta) {
+					delta, deltatype, deltablossom = m.dualvar[b], 4, b
+				}
+			}
+			if deltatype == -1 {
+				// Max-cardinality mode with nothing left to do: one final
+				// adjustment so the duals stay optimal.
+				deltatype = 1
+				delta = max(0, minDual(m.dualvar[:n]))
+			}
+
+			for v := 0; v < n; v++ {
+				switch m.label[m.inblossom[v]] {
+				case 1:
+					m.dualvar[v] -= delta
+				case 2:
+					m.dualvar[v] += delta
+				}
+			}
+			for b := n; b < 2*n; b++ {
+				if m.blossombase[b] >= 0 && m.blossomparent[b] == -1 {
+					switch m.label[b] {
+					case 1:
+						m.dualvar[b] += delta
+					case 2:
+						m.dualvar[b] -= delta
+					}
+				}
+			}
+
+			if deltatype == 1 {
+				break
+			}
+			switch deltatype {
+			case 2:
+				m.allowedge[deltaedge] = true
+				i, j := m.edges[deltaedge].I, m.edges[deltaedge].J
+				if m.label[m.inblossom[i]] == 0 {
+					i, j = j, i
+				}
+				_ = j
+				m.queue = append(m.queue, i)
+			case 3:
+				m.allowedge[deltaedge] = true
+				m.queue = append(m.queue, m.edges[deltaedge].I)
+			case 4:
+				m.expandBlossom(deltablossom, false)
+			}
+		}
+
+		if !augmented {
+			break
+		}
+		for b := n; b < 2*n; b++ {
+			if m.blossomparent[b] == -1 && m.blossombase[b] >= 0 && m.label[b] == 1 && m.dualvar[b] == 0 {
+				m.expandBlossom(b, true)
+			}
+		}
+	}
+}
+
+func (m *matcher) result() []int {
+	out := make([]int, m.nvertex)
+	for v := range out {
+		out[v] = -1
+		if m.mate[v] >= 0 {
+			out[v] = m.endpoint[m.mate[v]]
+		}
+	}
+	return out
+}
+
+func minDual(s []int64) int64 {
+	out := s[0]
+	for _, v := range s[1:] {
+		out = min(out, v)
+	}
+	return out
+}
+
+func indexOf(s []int, v int) int {
+	for i, x := range s {
+		if x == v {
+			return i
+		}
+	}
+	return -1
+}
+
+func reverse(s []int) {
+	for i, j := 0, len(s)-1; i < j; i, j = i+1, j-1 {
+		s[i], s[j] = s[j], s[i]
+	}
+}
@@ -0,0 +1,175 @@
+package pairing
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// bruteForce returns the best (cardinality, weight) over all matchings, with
+// cardinality only taking precedence when maxCardinality is set.
+func bruteForce(n int, edges []Edge, maxCardinality bool) (int, int64) {
+	adj := make([][]Edge, n)
+	for _, e := range edges {
+		adj[e.I] = append(adj[e.I], e)
+		adj[e.J] = append(adj[e.J], Edge{I: e.J, J: e.I, Weight: e.Weight})
+	}
+	used := make([]bool, n)
+	bestCard, bestW := 0, int64(0)
+	better := func(c int, w int64) bool {
+		if maxCardinality && c != bestCard {
+			return c > bestCard
+		}
+		return w > bestW
+	}
+	var rec func(v, card int, w int64)
+	rec = func(v, card int, w int64) {
+		for v < n && used[v] {
+			v++
+		}
+		if v == n {
+			if better(card, w) {
+				bestCard, bestW = card, w
+			}
+			return
+		}
+		used[v] = true
+		rec(v+1, card, w)
+		for _, e := range adj[v] {
+			if !used[e.J] {
+				used[e.J] = true
+				rec(v+1, card+1, w+e.Weight)
+				used[e.J] = false
+			}
+		}
+		used[v] = false
+	}
+	rec(0, 0, 0)
+	return bestCard, bestW
+}
+
+// checkMatching verifies mate is a valid matching over edges and returns its
+// cardinality and weight.
+func checkMatching(t *testing.T, edges []Edge, mate []int) (int, int64) {
+	t.Helper()
+	weight := map[[2]int]int64{}
+	for _, e := range edges {
+		weight[[2]int{e.I, e.J}] = e.Weight
+		weight[[2]int{e.J, e.I}] = e.Weight
+	}
+	card, total := 0, int64(0)
+	for v, w := range mate {
+		if w == -1 {
+			continue
+		}
+		if mate[w] != v {
+			t.Fatalf("mate not symmetric: mate[%d]=%d, mate[%d]=%d", v, w, w, mate[w])
+		}
+		ew, ok := weight[[2]int{v, w}]
+		if !ok {
+			t.Fatalf("matched %d-%d without an edge", v, w)
+		}
+		if v < w {
+			card++
+			total += ew
+		}
+	}
+	return card, total
+}
+
+func vertexCount(edges []Edge) int {
+	n := 0
+	for _, e := range edges {
+		n = max(n, e.I+1, e.J+1)
+	}
+	return n
+}
+
+func TestMaxWeightMatching_Simple(t *testing.T) {
+	if got := MaxWeightMatching(nil, false); got != nil {
+		t.Errorf("empty graph: got %v", got)
+	}
+	got := MaxWeightMatching([]Edge{{0, 1, 1}}, false)
+	if got[0] != 1 || got[1] != 0 {
+		t.Errorf("single edge: got %v", got)
+	}
+	got = MaxWeightMatching([]Edge{{1, 2, 10}, {2, 3, 11}}, false)
+	if want := []int{-1, -1, 3, 2}; !equalInts(got, want) {
+		t.Errorf("path: got %v, want %v", got, want)
+	}
+	// Heaviest single edge versus two lighter ones.
+	edges := []Edge{{1, 2, 5}, {2, 3, 11}, {3, 4, 5}}
+	if got, want := MaxWeightMatching(edges, false), []int{-1, -1, 3, 2, -1}; !equalInts(got, want) {
+		t.Errorf("max weight: got %v, want %v", got, want)
+	}
+	if got, want := MaxWeightMatching(edges, true), []int{-1, 2, 1, 4, 3}; !equalInts(got, want) {
+		t.Errorf("max cardinality: got %v, want %v", got, want)
+	}
+}
+
+// Graphs that exercise blossom creation, nesting, relabelling and expansion.
+var blossomGraphs = map[string][]Edge{
+	"s-blossom":            {{1, 2, 8}, {1, 3, 9}, {2, 3, 10}, {3, 4, 7}, {1, 6, 5}, {4, 5, 6}},
+	"t-blossom":            {{1, 2, 9}, {1, 3, 8}, {2, 3, 10}, {1, 4, 5}, {4, 5, 4}, {1, 6, 3}},
+	"nested s-blossom":     {{1, 2, 9}, {1, 3, 9}, {2, 3, 10}, {2, 4, 8}, {3, 5, 8}, {4, 5, 10}, {5, 6, 6}},
+	"relabel nested":       {{1, 2, 10}, {1, 7, 10}, {2, 3, 12}, {3, 4, 20}, {3, 5, 20}, {4, 5, 25}, {5, 6, 10}, {6, 7, 10}, {7, 8, 8}},
+	"nested expand":        {{1, 2, 8}, {1, 3, 8}, {2, 3, 10}, {2, 4, 12}, {3, 5, 12}, {4, 5, 14}, {4, 6, 12}, {5, 7, 12}, {6, 7, 14}, {7, 8, 12}},
+	"s-blossom t-expand":   {{1, 2, 23}, {1, 5, 22}, {1, 6, 15}, {2, 3, 25}, {3, 4, 22}, {4, 5, 25}, {4, 8, 14}, {5, 7, 13}},
+	"nested t-expand":      {{1, 2, 19}, {1, 3, 20}, {1, 8, 8}, {2, 3, 25}, {2, 4, 18}, {3, 5, 18}, {4, 5, 13}, {4, 7, 7}, {5, 6, 7}},
+	"nasty t-expand":       {{1, 2, 45}, {1, 5, 45}, {2, 3, 50}, {3, 4, 45}, {4, 5, 50}, {1, 6, 30}, {3, 9, 35}, {4, 8, 35}, {5, 7, 26}, {9, 10, 5}},
+	"least-slack t-expand": {{1, 2, 45}, {1, 5, 45}, {2, 3, 50}, {3, 4, 45}, {4, 5, 50}, {1, 6, 30}, {3, 9, 35}, {4, 8, 28}, {5, 7, 26}, {9, 10, 5}},
+	"nested nasty expand":  {{1, 2, 45}, {1, 7, 45}, {2, 3, 50}, {3, 4, 45}, {4, 5, 95}, {4, 6, 94}, {5, 6, 94}, {6, 7, 50}, {1, 8, 30}, {3, 11, 35}, {5, 9, 36}, {7, 10, 26}, {11, 12, 5}},
+	"nested relabel":       {{1, 2, 40}, {1, 3, 40}, {2, 3, 60}, {2, 4, 55}, {3, 5, 55}, {4, 5, 50}, {1, 8, 15}, {5, 7, 30}, {7, 6, 10}, {8, 10, 10}, {4, 9, 30}},
+	"negative weights":     {{1, 2, 2}, {1, 3, -2}, {2, 3, 1}, {2, 4, -1}, {3, 4, -6}},
+}
+
+func TestMaxWeightMatching_Blossoms(t *testing.T) {
+	for name, edges := range blossomGraphs {
+		for _, maxCard := range []bool{false, true} {
+			mate := MaxWeightMatching(edges, maxCard)
+			card, w := checkMatching(t, edges, mate)
+			wantCard, wantW := bruteForce(vertexCount(edges), edges, maxCard)
+			if w != wantW || (maxCard && card != wantCard) {
+				t.Errorf("%s (maxCardinality=%v): got %d edges weight %d, want %d edges weight %d",
+					name, maxCard, card, w, wantCard, wantW)
+			}
+		}
+	}
+}
+
+func TestMaxWeightMatching_RandomAgainstBruteForce(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for iter := 0; iter < 500; iter++ {
+		n := 2 + rng.Intn(9)
+		var edges []Edge
+		for i := 0; i < n; i++ {
+			for j := i + 1; j < n; j++ {
+				if rng.Intn(3) > 0 {
+					edges = append(edges, Edge{I: i, J: j, Weight: int64(rng.Intn(40) - 10)})
+				}
+			}
+		}
+		if len(edges) == 0 {
+			continue
+		}
+		maxCard := iter%2 == 0
+		mate := MaxWeightMatching(edges, maxCard)
+		card, w := checkMatching(t, edges, mate)
+		wantCard, wantW := bruteForce(vertexCount(edges), edges, maxCard)
+		if w != wantW || (maxCard && card != wantCard) {
+			t.Fatalf("iter %d (maxCardinality=%v) edges %v: got %d/%d, want %d/%d",
+				iter, maxCard, edges, card, w, wantCard, wantW)
+		}
+	}
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,64 @@
+package pairing
+
+// sameClub reports whether a and b are from the same club.
+func sameClub(a, b Player) bool {
+	return a.Club != "" && a.Club == b.Club
+}
+
+// AvoidClubs breaks up the pairings of two players from the same club, for
+// the algorithms that don't weigh clubs themselves. Each such pairing swaps
+// opponents with another one, provided neither new pairing is a rematch or
+// another pair of club mates. Of the swaps that qualify it makes the one
+// adding the least squared score difference, then the one with the nearest
+// pairing, so the round stays as close to pairs as it can. A pairing no
+// swap can fix is kept. Byes are left alone.
+func AvoidClubs(players []Player, pairs []Pair) []Pair {
+	byID := make(map[int]Player, len(players))
+	for _, p := range players {
+		byID[p.ID] = p
+	}
+	met := func(a, b int) bool {
+		for _, o := range byID[a].Opponents {
+			if o == b {
+				return true
+			}
+		}
+		return false
+	}
+	ok := func(a, b int) bool {
+		return !met(a, b) && !sameClub(byID[a], byID[b])
+	}
+	cost := func(a, b int) int {
+		d := byID[a].Points - byID[b].Points
+		return d * d
+	}
+
+	out := append([]Pair(nil), pairs...)
+	for i, p := range out {
+		if p.B == Bye || !sameClub(byID[p.A], byID[p.B]) {
+			continue
+		}
+		var best [2]Pair
+		bestJ, bestCost, bestDist := -1, 0, 0
+		for j, q := range out {
+			if j == i || q.B == Bye {
+				continue
+			}
+			dist := max(i-j, j-i)
+			// Either of p's players can take either of q's.
+			for _, swap := range [2][2]Pair{{{p.A, q.A}, {p.B, q.B}}, {{p.A, q.B}, {p.B, q.A}}} {
+				if !ok(swap[0].A, swap[0].B) || !ok(swap[1].A, swap[1].B) {
+					continue
+				}
+				c := cost(swap[0].A, swap[0].B) + cost(swap[1].A, swap[1].B)
+				if bestJ < 0 || c < bestCost || c == bestCost && dist < bestDist {
+					best, bestJ, bestCost, bestDist = swap, j, c, dist
+				}
+			}
+		}
+		if bestJ >= 0 {
+			out[i], out[bestJ] = best[0], best[1]
+		}
+	}
+	return out
+}
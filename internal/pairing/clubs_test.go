@@ -0,0 +1,68 @@
+package pairing
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAvoidClubs(t *testing.T) {
+	players := []Player{
+		{ID: 1, Points: 3, Club: "north"}, {ID: 2, Points: 3, Club: "north"},
+		{ID: 3, Points: 3, Club: "south"}, {ID: 4, Points: 3},
+		{ID: 5, Points: 0, Club: "north"}, {ID: 6, Points: 0},
+	}
+	tests := []struct {
+		name  string
+		pairs []Pair
+		want  []Pair
+	}{
+		{"no club mates", []Pair{{1, 3}, {2, 4}, {5, 6}}, []Pair{{1, 3}, {2, 4}, {5, 6}}},
+		{"swap in the score group", []Pair{{1, 2}, {3, 4}, {5, 6}}, []Pair{{1, 3}, {2, 4}, {5, 6}}},
+		// 1-6 would add a score difference; 2 and 3 can swap for free.
+		{"least score difference", []Pair{{5, 6}, {1, 2}, {3, 4}}, []Pair{{5, 6}, {1, 3}, {2, 4}}},
+		{"byes stay", []Pair{{1, 2}, {3, Bye}}, []Pair{{1, 2}, {3, Bye}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := AvoidClubs(players, tt.pairs); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("AvoidClubs = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAvoidClubs_NoRematch(t *testing.T) {
+	// 1 and 2 are club mates, but 1 has met 3 and 2 has met 4: swapping
+	// would make a rematch, so the pairing stands.
+	players := []Player{
+		{ID: 1, Club: "north", Opponents: []int{3, 4}}, {ID: 2, Club: "north", Opponents: []int{4, 3}},
+		{ID: 3, Opponents: []int{1, 2}}, {ID: 4, Opponents: []int{2, 1}},
+	}
+	pairs := []Pair{{1, 2}, {3, 4}}
+	if got := AvoidClubs(players, pairs); !reflect.DeepEqual(got, pairs) {
+		t.Errorf("AvoidClubs = %v, want %v unchanged", got, pairs)
+	}
+}
+
+func TestWeighted_AvoidsClubMates(t *testing.T) {
+	players := []Player{
+		{ID: 1, Points: 3, Club: "north"}, {ID: 2, Points: 3, Club: "north"},
+		{ID: 3, Points: 3, Club: "south"}, {ID: 4, Points: 3, Club: "south"},
+	}
+	pairs, err := Weighted{}.Pair(players)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opp := checkRound(t, players, pairs)
+	if sameClub(players[0], players[opp[1]-1]) || sameClub(players[2], players[opp[3]-1]) {
+		t.Errorf("club mates paired: %v", pairs)
+	}
+
+	// A rematch is worse than club mates meeting.
+	players[0].Opponents, players[1].Opponents = []int{3, 4}, []int{3, 4}
+	players[2].Opponents, players[3].Opponents = []int{1, 2}, []int{1, 2}
+	pairs, _ = Weighted{}.Pair(players)
+	if opp := checkRound(t, players, pairs); opp[1] != 2 {
+		t.Errorf("1 paired with %d, want their club mate 2 over a rematch", opp[1])
+	}
+}
@@ -0,0 +1,90 @@
+package pairing
+
+// Color is the side a player took in a chess game.
+type Color byte
+
+const (
+	White Color = 'W'
+	Black Color = 'B'
+)
+
+// other returns the opposite colour.
+func (c Color) other() Color {
+	if c == White {
+		return Black
+	}
+	return White
+}
+
+// colorBalance is how many more whites than blacks colors holds.
+func colorBalance(colors []Color) int {
+	n := 0
+	for _, c := range colors {
+		switch c {
+		case White:
+			n++
+		case Black:
+			n--
+		}
+	}
+	return n
+}
+
+// mustHave returns the colour a player who has played colors, oldest first,
+// has to get next, or 0 when either will do: the one they have had less of
+// once they are two apart, or the other one after two the same in a row.
+func mustHave(colors []Color) Color {
+	switch b := colorBalance(colors); {
+	case b <= -2:
+		return White
+	case b >= 2:
+		return Black
+	}
+	if n := len(colors); n >= 2 && colors[n-1] == colors[n-2] {
+		return colors[n-1].other()
+	}
+	return 0
+}
+
+// colorClash reports whether a and b both have to get the same colour, so
+// one of them can't.
+func colorClash(a, b Player) bool {
+	c := mustHave(a.Colors)
+	return c != 0 && c == mustHave(b.Colors)
+}
+
+// Orient decides who of a and b plays white and returns their pair with
+// the white player as A. In order:
+//
+//   - a player who has to get a colour (see mustHave) gets it;
+//   - the player who has had fewer whites than blacks gets white;
+//   - going back through their games, at the latest round they had
+//     different colours, the one who had black then gets white;
+//   - otherwise white goes to a when aWhite is set, and to b if not.
+//
+// Pass a as the higher-placed player, and alternate aWhite down the boards
+// so that round 1 splits the colours evenly.
+func Orient(a, b Player, aWhite bool) Pair {
+	white := func(first bool) Pair {
+		if first {
+			return Pair{A: a.ID, B: b.ID}
+		}
+		return Pair{A: b.ID, B: a.ID}
+	}
+	ma, mb := mustHave(a.Colors), mustHave(b.Colors)
+	if ma != mb {
+		if ma != 0 {
+			return white(ma == White)
+		}
+		return white(mb == Black)
+	}
+	if ba, bb := colorBalance(a.Colors), colorBalance(b.Colors); ba != bb {
+		return white(ba < bb)
+	}
+	for i, j := len(a.Colors)-1, len(b.Colors)-1; i >= 0 && j >= 0; i, j = i-1, j-1 {
+		if a.Colors[i] != b.Colors[j] {
+			return white(a.Colors[i] == Black)
+		}
+	}
+	return white(aWhite)
+}
@@ -0,0 +1,73 @@
+package pairing
+
+import "testing"
+
+func colors(s string) []Color {
+	out := make([]Color, len(s))
+	for i := range s {
+		out[i] = Color(s[i])
+	}
+	return out
+}
+
+func TestMustHave(t *testing.T) {
+	for hist, want := range map[string]Color{
+		"":     0,
+		"W":    0,
+		"WB":   0,
+		"WW":   Black,
+		"BB":   White,
+		"WBB":  White,
+		"WWBW": Black,
+		"BWBB": White,
+		"WBWB": 0,
+	} {
+		if got := mustHave(colors(hist)); got != want {
+			t.Errorf("mustHave(%q) = %q, want %q", hist, got, want)
+		}
+	}
+}
+
+func TestOrient(t *testing.T) {
+	tests := []struct {
+		name   string
+		a, b   string
+		aWhite bool
+		white  int
+	}{
+		{"new players, board 1", "", "", true, 1},
+		{"new players, board 2", "", "", false, 2},
+		{"b must have white", "B", "WBB", true, 2},
+		{"a must have black", "WW", "W", true, 2},
+		{"both must have black", "WW", "BWW", true, 2},
+		{"fewer whites", "B", "W", false, 1},
+		{"alternate", "WB", "BW", false, 1},
+		{"alternate further back", "WBWB", "BWWB", false, 1},
+		{"same history", "WB", "WB", false, 2},
+	}
+	for _, tt := range tests {
+		got := Orient(Player{ID: 1, Colors: colors(tt.a)}, Player{ID: 2, Colors: colors(tt.b)}, tt.aWhite)
+		if got.A != tt.white {
+			t.Errorf("%s: white is %d, want %d", tt.name, got.A, tt.white)
+		}
+	}
+}
+
+func TestWeighted_AvoidsColorClash(t *testing.T) {
+	// 1 and 2 both had white twice in a row and must have black; pairing
+	// them would give one of them a third white.
+	players := []Player{
+		{ID: 1, Points: 6, Colors: colors("WW")},
+		{ID: 2, Points: 6, Colors: colors("BWW")},
+		{ID: 3, Points: 6, Colors: colors("BB")},
+		{ID: 4, Points: 6, Colors: colors("WBB")},
+	}
+	pairs, err := Weighted{}.Pair(players)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opp := checkRound(t, players, pairs)
+	if opp[1] == 2 || opp[3] == 4 {
+		t.Errorf("paired players who need the same colour: %v", pairs)
+	}
+}
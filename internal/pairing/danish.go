@@ -0,0 +1,23 @@
+package pairing
+
+import "sort"
+
+// Danish pairs a round down the standings, first against second, third
+// against fourth, and so on, with no regard for who has met before. Players
+// come in standings order; Danish only re-sorts them by points, keeping the
+// order within a score group. With an odd field the last player gets the
+// bye.
+type Danish struct{}
+
+func (Danish) Pair(players []Player) ([]Pair, error) {
+	ranked := append([]Player(nil), players...)
+	sort.SliceStable(ranked, func(i, j int) bool { return ranked[i].Points > ranked[j].Points })
+	pairs := make([]Pair, 0, (len(ranked)+1)/2)
+	for i := 0; i+1 < len(ranked); i += 2 {
+		pairs = append(pairs, Pair{A: ranked[i].ID, B: ranked[i+1].ID})
+	}
+	if n := len(ranked); n%2 == 1 {
+		pairs = append(pairs, Pair{A: ranked[n-1].ID, B: Bye})
+	}
+	return pairs, nil
+}
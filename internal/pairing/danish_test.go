@@ -0,0 +1,22 @@
+package pairing
+
+import "testing"
+
+func TestDanish_PairsDownTheStandings(t *testing.T) {
+	// 1 and 2 have met already; Danish pairs them anyway.
+	players := []Player{
+		{ID: 1, Points: 6, Opponents: []int{2}},
+		{ID: 2, Points: 6, Opponents: []int{1}},
+		{ID: 5, Points: 3},
+		{ID: 3, Points: 0},
+		{ID: 4, Points: 3},
+	}
+	pairs, err := Danish{}.Pair(players)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opp := checkRound(t, players, pairs)
+	if opp[1] != 2 || opp[5] != 4 || opp[3] != Bye {
+		t.Errorf("pairs = %v", pairs)
+	}
+}
@@ -0,0 +1,55 @@
+// Package pairing holds the pluggable pairing algorithms. The default
+// greedy pairing lives in swisstools itself; the algorithms here produce a
+// list of pairings that internal/engine writes into the engine state.
+package pairing
+
+import "math/bits"
+
+// Bye is the opponent ID for a bye. It matches swisstools.BYE_OPPONENT_ID so
+// pairings can be copied into the engine state unchanged.
+const Bye = -1
+
+// Player is what a Pairer knows about one active (not dropped) player when
+// pairing a round.
+type Player struct {
+	ID     int
+	Points int
+	// Byes is how many byes the player has already received.
+	Byes int
+	// Opponents lists everyone the player has already faced, once per
+	// meeting.
+	Opponents []int
+	// Seed is the player's place in the round 1 seeding, 1 for the top
+	// seed. Only Cross and Fold look at it.
+	Seed int
+	// Colors lists the colours the player had in their games, oldest
+	// first, when the tournament tracks chess colours; nil otherwise. Only
+	// Weighted and Orient look at it.
+	Colors []Color
+	// Club is the player's club or team while the tournament keeps club
+	// mates apart, "" otherwise or when they have none. Only Weighted and
+	// AvoidClubs look at it.
+	Club string
+}
+
+// Pair is one match of a round. B is Bye when A receives the bye.
+type Pair struct {
+	A, B int
+}
+
+// Pairer produces the pairings for one Swiss round. Implementations must
+// pair every player exactly once; with an odd player count exactly one Pair
+// has B == Bye.
+type Pairer interface {
+	Pair(players []Player) ([]Pair, error)
+}
+
+// SwissRounds returns the recommended number of Swiss rounds for n players:
+// ceil(log2(n)), the fewest after which at most one player can be
+// undefeated. It is 0 for fewer than two players.
+func SwissRounds(n int) int {
+	if n < 2 {
+		return 0
+	}
+	return bits.Len(uint(n - 1))
+}
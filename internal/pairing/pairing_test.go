@@ -0,0 +1,13 @@
+package pairing
+
+import "testing"
+
+func TestSwissRounds(t *testing.T) {
+	for _, tt := range []struct{ players, want int }{
+		{0, 0}, {1, 0}, {2, 1}, {3, 2}, {4, 2}, {5, 3}, {8, 3}, {9, 4}, {16, 4}, {17, 5}, {128, 7}, {129, 8},
+	} {
+		if got := SwissRounds(tt.players); got != tt.want {
+			t.Errorf("SwissRounds(%d) = %d, want %d", tt.players, got, tt.want)
+		}
+	}
+}
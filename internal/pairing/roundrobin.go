@@ -0,0 +1,80 @@
+package pairing
+
+import "sort"
+
+// RoundRobin pairs round Round (from 1) of a round-robin schedule by the
+// circle method: one place stays put while everyone else rotates one place
+// a round, so over a cycle of N-1 rounds (N with an odd field) every player
+// meets every other exactly once. Rounds past the end of the cycle start it
+// again. Whoever holds the fixed place alternates between sides A and B.
+//
+// The fixed place is seed 1's, or with an odd field an empty seat's, so the
+// bye goes to the lowest seed in round 1 and then round the field.
+//
+// Unlike the Swiss pairers, the schedule ignores results: it depends only
+// on the field and the round, so the caller must pass the same field every
+// round, players who dropped included.
+type RoundRobin struct {
+	Round int
+}
+
+// Cycle returns how many rounds a round robin of n players takes.
+func Cycle(n int) int {
+	if n%2 == 1 {
+		return n
+	}
+	return n - 1
+}
+
+func (rr RoundRobin) Pair(players []Player) ([]Pair, error) {
+	if len(players) == 0 {
+		return nil, nil
+	}
+	seats := append([]Player(nil), players...)
+	sort.SliceStable(seats, func(i, j int) bool {
+		if seats[i].Seed != seats[j].Seed {
+			return seats[i].Seed < seats[j].Seed
+		}
+		return seats[i].ID < seats[j].ID
+	})
+	ids := make([]int, 0, len(seats)+1)
+	if len(seats)%2 == 1 {
+		ids = append(ids, Bye)
+	}
+	for _, p := range seats {
+		ids = append(ids, p.ID)
+	}
+	n := len(ids)
+	if n == 2 {
+		return []Pair{byeLast(ids[0], ids[1])}, nil
+	}
+
+	// Position 0 is fixed; positions 1..n-1 rotate by one each round.
+	shift := (rr.Round - 1) % (n - 1)
+	if shift < 0 {
+		shift += n - 1
+	}
+	at := func(pos int) int {
+		if pos == 0 {
+			return ids[0]
+		}
+		return ids[1+(pos-1+shift)%(n-1)]
+	}
+	pairs := make([]Pair, 0, n/2)
+	for i := 0; i < n/2; i++ {
+		a, b := at(i), at(n-1-i)
+		if i == 0 && shift%2 == 1 {
+			a, b = b, a
+		}
+		pairs = append(pairs, byeLast(a, b))
+	}
+	return pairs, nil
+}
+
+// byeLast returns the pair of a and b with any Bye on side B.
+func byeLast(a, b int) Pair {
+	if a == Bye {
+		return Pair{A: b, B: a}
+	}
+	return Pair{A: a, B: b}
+}
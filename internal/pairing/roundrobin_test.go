@@ -0,0 +1,73 @@
+package pairing
+
+import "testing"
+
+func TestRoundRobin_EveryoneMeetsOnce(t *testing.T) {
+	for _, n := range []int{2, 3, 4, 5, 8, 9} {
+		players := seededField(n)
+		met := map[[2]int]int{}
+		byes := map[int]int{}
+		for round := 1; round <= Cycle(n); round++ {
+			pairs, err := RoundRobin{Round: round}.Pair(players)
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkRound(t, players, pairs)
+			for _, p := range pairs {
+				if p.B == Bye {
+					byes[p.A]++
+					continue
+				}
+				a, b := p.A, p.B
+				if a > b {
+					a, b = b, a
+				}
+				met[[2]int{a, b}]++
+			}
+		}
+		if want := n * (n - 1) / 2; len(met) != want {
+			t.Errorf("n=%d: %d distinct matches, want %d", n, len(met), want)
+		}
+		for m, c := range met {
+			if c != 1 {
+				t.Errorf("n=%d: %v met %d times", n, m, c)
+			}
+		}
+		if n%2 == 1 && len(byes) != n {
+			t.Errorf("n=%d: byes %v, want one each", n, byes)
+		}
+	}
+}
+
+func TestRoundRobin_FollowsSeeds(t *testing.T) {
+	// Seed s has ID 5-s; in round 1 seed 1 plays seed 4.
+	players := seededField(4)
+	pairs, err := RoundRobin{Round: 1}.Pair(players)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opp := checkRound(t, players, pairs); opp[4] != 1 {
+		t.Errorf("seed 1 plays player %d, want 1", opp[4])
+	}
+
+	// The next cycle repeats the first.
+	again, _ := RoundRobin{Round: 1 + Cycle(4)}.Pair(players)
+	for i := range pairs {
+		if pairs[i] != again[i] {
+			t.Errorf("second cycle round 1 = %v, want %v", again, pairs)
+			break
+		}
+	}
+}
+
+func TestRoundRobin_OddFieldByeToLowestSeedFirst(t *testing.T) {
+	players := seededField(5)
+	pairs, err := RoundRobin{Round: 1}.Pair(players)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The lowest seed, 5, has ID 1.
+	if opp := checkRound(t, players, pairs); opp[1] != Bye {
+		t.Errorf("round 1 bye went elsewhere: %v", pairs)
+	}
+}
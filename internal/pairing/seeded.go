@@ -0,0 +1,50 @@
+package pairing
+
+import "sort"
+
+// Cross pairs a round by seed, top half against bottom half: with N
+// players, seed 1 plays seed N/2+1, seed 2 plays N/2+2, and so on. With an
+// odd field the lowest seed gets the bye. It is meant for round 1 of a
+// rated event, where every player has the same score.
+type Cross struct{}
+
+func (Cross) Pair(players []Player) ([]Pair, error) {
+	seeded, pairs := bySeed(players)
+	half := len(seeded) / 2
+	for i := 0; i < half; i++ {
+		pairs = append(pairs, Pair{A: seeded[i].ID, B: seeded[half+i].ID})
+	}
+	return pairs, nil
+}
+
+// Fold pairs a round by seed, folding the seeding in half: seed 1 plays
+// seed N, seed 2 plays N-1, and so on. With an odd field the lowest seed
+// gets the bye.
+type Fold struct{}
+
+func (Fold) Pair(players []Player) ([]Pair, error) {
+	seeded, pairs := bySeed(players)
+	n := len(seeded)
+	for i := 0; i < n/2; i++ {
+		pairs = append(pairs, Pair{A: seeded[i].ID, B: seeded[n-1-i].ID})
+	}
+	return pairs, nil
+}
+
+// bySeed sorts a copy of players by seed, then ID. With an odd field it
+// takes the lowest seed off the end and returns their bye.
+func bySeed(players []Player) ([]Player, []Pair) {
+	seeded := append([]Player(nil), players...)
+	sort.SliceStable(seeded, func(i, j int) bool {
+		if seeded[i].Seed != seeded[j].Seed {
+			return seeded[i].Seed < seeded[j].Seed
+		}
+		return seeded[i].ID < seeded[j].ID
+	})
+	var pairs []Pair
+	if n := len(seeded); n%2 == 1 {
+		pairs = append(pairs, Pair{A: seeded[n-1].ID, B: Bye})
+		seeded = seeded[:n-1]
+	}
+	return seeded, pairs
+}
@@ -0,0 +1,58 @@
+package pairing
+
+import "testing"
+
+// seededField returns n players whose seeds run opposite to their IDs, so a
+// pairer that ignores Seed gets it wrong.
+func seededField(n int) []Player {
+	players := make([]Player, n)
+	for i := range players {
+		players[i] = Player{ID: i + 1, Seed: n - i}
+	}
+	return players
+}
+
+func TestCross(t *testing.T) {
+	players := seededField(8)
+	pairs, err := Cross{}.Pair(players)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opp := checkRound(t, players, pairs)
+	// Seed s has ID 9-s: seed 1 v 5, 2 v 6, 3 v 7, 4 v 8.
+	for seed := 1; seed <= 4; seed++ {
+		if got, want := opp[9-seed], 9-(seed+4); got != want {
+			t.Errorf("seed %d plays player %d, want %d", seed, got, want)
+		}
+	}
+}
+
+func TestFold(t *testing.T) {
+	players := seededField(8)
+	pairs, err := Fold{}.Pair(players)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opp := checkRound(t, players, pairs)
+	// Seed 1 v 8, 2 v 7, 3 v 6, 4 v 5.
+	for seed := 1; seed <= 4; seed++ {
+		if got, want := opp[9-seed], 9-(9-seed); got != want {
+			t.Errorf("seed %d plays player %d, want %d", seed, got, want)
+		}
+	}
+}
+
+func TestSeeded_OddFieldByeToLowestSeed(t *testing.T) {
+	for name, p := range map[string]Pairer{"cross": Cross{}, "fold": Fold{}} {
+		players := seededField(5)
+		pairs, err := p.Pair(players)
+		if err != nil {
+			t.Fatal(err)
+		}
+		opp := checkRound(t, players, pairs)
+		// The lowest seed, 5, has ID 1.
+		if opp[1] != Bye {
+			t.Errorf("%s: bye went elsewhere: %v", name, pairs)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package pairing
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Penalties for the weighted pairer, in decreasing order of importance. A
+// rematch costs more than any bye arrangement, a second bye more than any
+// colour clash, a colour clash more than pairing two club mates, and that
+// more than any amount of pairing across score groups, so the optimum never
+// trades a worse class of problem for a lesser one.
+const (
+	repeatPenalty = 1_000_000_000
+	byePenalty    = 10_000_000
+	colorPenalty  = 100_000
+	clubPenalty   = 10_000
+
+	// baseWeight keeps every edge weight positive; the matching maximises
+	// baseWeight minus the penalty of each pairing.
+	baseWeight = int64(1) << 40
+)
+
+// Weighted pairs a round by maximum weight matching over every possible
+// pairing of the field, instead of walking down the standings greedily. It
+// minimises, in priority order, rematches, repeat byes, pairings of two
+// players who both have to get the same colour (when colours are tracked),
+// pairings of two players from the same club (when clubs are kept apart),
+// and the squared score difference between opponents. The bye (when the field is odd) is modelled
+// as an extra vertex, so it goes to the lowest-scored player who hasn't had
+// one yet.
+//
+// Ties between equally good pairings are broken by input order, so callers
+// that want variety should shuffle within score groups first.
+type Weighted struct{}
+
+func (Weighted) Pair(players []Player) ([]Pair, error) {
+	index := make(map[int]int, len(players))
+	for i, p := range players {
+		if p.ID == Bye {
+			return nil, errors.New("pairing: player id -1 is reserved for the bye")
+		}
+		if _, dup := index[p.ID]; dup {
+			return nil, fmt.Errorf("pairing: duplicate player %d", p.ID)
+		}
+		index[p.ID] = i
+	}
+	switch len(players) {
+	case 0:
+		return nil, nil
+	case 1:
+		return []Pair{{A: players[0].ID, B: Bye}}, nil
+	}
+
+	met := make([]map[int]int, len(players))
+	for i, p := range players {
+		met[i] = make(map[int]int)
+		for _, o := range p.Opponents {
+			met[i][o]++
+		}
+	}
+
+	var edges []Edge
+	for i := range players {
+		for j := i + 1; j < len(players); j++ {
+			d := int64(players[i].Points - players[j].Points)
+			penalty := d*d + repeatPenalty*int64(met[i][players[j].ID])
+			if colorClash(players[i], players[j]) {
+				penalty += colorPenalty
+			}
+			if sameClub(players[i], players[j]) {
+				penalty += clubPenalty
+			}
+			edges = append(edges, Edge{I: i, J: j, Weight: baseWeight - penalty})
+		}
+	}
+	byeVertex := -1
+	if len(players)%2 == 1 {
+		byeVertex = len(players)
+		for i, p := range players {
+			pts := int64(p.Points)
+			penalty := pts*pts + byePenalty*int64(p.Byes)
+			edges = append(edges, Edge{I: i, J: byeVertex, Weight: baseWeight - penalty})
+		}
+	}
+
+	mate := MaxWeightMatching(edges, true)
+	pairs := make([]Pair, 0, (len(players)+1)/2)
+	for i, p := range players {
+		j := mate[i]
+		switch {
+		case j == -1:
+			return nil, fmt.Errorf("pairing: player %d left unpaired", p.ID)
+		case j == byeVertex:
+			pairs = append(pairs, Pair{A: p.ID, B: Bye})
+		case j > i:
+			pairs = append(pairs, Pair{A: p.ID, B: players[j].ID})
+		}
+	}
+	return pairs, nil
+}
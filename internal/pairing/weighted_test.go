@@ -0,0 +1,96 @@
+package pairing
+
+import "testing"
+
+// checkRound verifies every player appears in exactly one pair and returns
+// the pairs keyed by player for easy lookup.
+func checkRound(t *testing.T, players []Player, pairs []Pair) map[int]int {
+	t.Helper()
+	opp := map[int]int{}
+	for _, p := range pairs {
+		for _, id := range []int{p.A, p.B} {
+			if id == Bye {
+				continue
+			}
+			if _, dup := opp[id]; dup {
+				t.Fatalf("player %d paired twice in %v", id, pairs)
+			}
+		}
+		opp[p.A] = p.B
+		if p.B != Bye {
+			opp[p.B] = p.A
+		}
+	}
+	if len(opp) != len(players) {
+		t.Fatalf("paired %d players, want %d: %v", len(opp), len(players), pairs)
+	}
+	return opp
+}
+
+func TestWeighted_PairsWithinScoreGroups(t *testing.T) {
+	players := []Player{
+		{ID: 1, Points: 6}, {ID: 2, Points: 6},
+		{ID: 3, Points: 3}, {ID: 4, Points: 3},
+		{ID: 5, Points: 0}, {ID: 6, Points: 0},
+	}
+	pairs, err := Weighted{}.Pair(players)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opp := checkRound(t, players, pairs)
+	for a, b := range map[int]int{1: 2, 3: 4, 5: 6} {
+		if opp[a] != b {
+			t.Errorf("player %d paired with %d, want %d", a, opp[a], b)
+		}
+	}
+}
+
+func TestWeighted_AvoidsRematches(t *testing.T) {
+	// Greedy top-down pairing would give 1-2 (a rematch) or strand 3-4;
+	// the global optimum pairs 1-3 and 2-4.
+	players := []Player{
+		{ID: 1, Points: 6, Opponents: []int{2}},
+		{ID: 2, Points: 6, Opponents: []int{1}},
+		{ID: 3, Points: 3, Opponents: []int{4}},
+		{ID: 4, Points: 3, Opponents: []int{3}},
+	}
+	pairs, err := Weighted{}.Pair(players)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opp := checkRound(t, players, pairs)
+	if opp[1] == 2 || opp[3] == 4 {
+		t.Errorf("rematch in %v", pairs)
+	}
+}
+
+func TestWeighted_ByeFairness(t *testing.T) {
+	players := []Player{
+		{ID: 1, Points: 6},
+		{ID: 2, Points: 3},
+		{ID: 3, Points: 0, Byes: 1},
+		{ID: 4, Points: 0},
+		{ID: 5, Points: 3},
+	}
+	pairs, err := Weighted{}.Pair(players)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opp := checkRound(t, players, pairs)
+	if opp[4] != Bye {
+		t.Errorf("bye went to %v, want lowest-scored player without a bye (4)", pairs)
+	}
+}
+
+func TestWeighted_EdgeCases(t *testing.T) {
+	if pairs, err := (Weighted{}).Pair(nil); err != nil || pairs != nil {
+		t.Errorf("no players: got %v, %v", pairs, err)
+	}
+	pairs, err := Weighted{}.Pair([]Player{{ID: 7}})
+	if err != nil || len(pairs) != 1 || pairs[0] != (Pair{A: 7, B: Bye}) {
+		t.Errorf("one player: got %v, %v", pairs, err)
+	}
+	if _, err := (Weighted{}).Pair([]Player{{ID: 1}, {ID: 1}}); err == nil {
+		t.Error("duplicate ids: want error")
+	}
+}
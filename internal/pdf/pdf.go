@@ -0,0 +1,105 @@
+// Package pdf writes the small PDFs OpenSwiss prints: plain A4 pages of
+// text and lines in the standard Helvetica faces, so no fonts are embedded.
+// Besides the page primitives it renders the documents printed at a venue:
+// match slips and paged tables (standings, seatings).
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// A4 in PDF points.
+const (
+	PageWidth  = 595
+	PageHeight = 842
+)
+
+// Page collects the drawing operators of one page. Coordinates are in
+// points from the bottom-left corner.
+type Page struct {
+	buf bytes.Buffer
+}
+
+// pdfString encodes s as a PDF literal string in WinAnsiEncoding. Characters
+// outside Latin-1 can't be shown by the standard fonts and become '?'.
+func pdfString(s string) string {
+	var b strings.Builder
+	b.WriteByte('(')
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r < 0x20 || (r >= 0x7f && r < 0xa0) || r > 0xff:
+			b.WriteByte('?')
+		default:
+			b.WriteByte(byte(r))
+		}
+	}
+	b.WriteByte(')')
+	return b.String()
+}
+
+// Text draws s with its baseline starting at x, y, in Helvetica or
+// Helvetica-Bold.
+func (p *Page) Text(x, y, size float64, bold bool, s string) {
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+	fmt.Fprintf(&p.buf, "BT /%s %.1f Tf %.1f %.1f Td %s Tj ET\n", font, size, x, y, pdfString(s))
+}
+
+// Line draws a solid line.
+func (p *Page) Line(x1, y1, x2, y2 float64) {
+	fmt.Fprintf(&p.buf, "%.1f %.1f m %.1f %.1f l S\n", x1, y1, x2, y2)
+}
+
+// DashedLine draws a dashed line, for cutting along.
+func (p *Page) DashedLine(x1, y1, x2, y2 float64) {
+	fmt.Fprintf(&p.buf, "[4 3] 0 d %.1f %.1f m %.1f %.1f l S [] 0 d\n", x1, y1, x2, y2)
+}
+
+// Rect draws the outline of a box with its bottom-left corner at x, y.
+func (p *Page) Rect(x, y, w, h float64) {
+	fmt.Fprintf(&p.buf, "%.1f %.1f %.1f %.1f re S\n", x, y, w, h)
+}
+
+// Write writes pages as a complete PDF document.
+func Write(w io.Writer, pages []*Page) error {
+	var out bytes.Buffer
+	var offsets []int
+	obj := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	out.WriteString("%PDF-1.4\n")
+	// Objects 1-4 are fixed; page i is object 5+2i, its content 6+2i.
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+2*i)
+	}
+	obj("<< /Type /Catalog /Pages 2 0 R >>")
+	obj(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	obj("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	obj("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	for i, p := range pages {
+		obj(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] "+
+			"/Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			PageWidth, PageHeight, 6+2*i))
+		obj(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", p.buf.Len(), p.buf.String()))
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	_, err := w.Write(out.Bytes())
+	return err
+}
@@ -0,0 +1,50 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+var xrefEntry = regexp.MustCompile(`(\d{10}) 00000 n `)
+
+// checkPDF fails t unless out is a complete PDF whose cross-reference table
+// points at its objects, and returns its page count.
+func checkPDF(t *testing.T, out []byte) int {
+	t.Helper()
+	if !bytes.HasPrefix(out, []byte("%PDF-1.4\n")) || !bytes.HasSuffix(out, []byte("%%EOF\n")) {
+		t.Fatal("not a PDF")
+	}
+	for i, e := range xrefEntry.FindAllSubmatch(out, -1) {
+		off, _ := strconv.Atoi(string(e[1]))
+		if !bytes.HasPrefix(out[off:], []byte(fmt.Sprintf("%d 0 obj", i+1))) {
+			t.Errorf("xref entry %d points at %q", i+1, out[off:off+10])
+		}
+	}
+	start := bytes.LastIndex(out, []byte("startxref\n"))
+	off, _ := strconv.Atoi(strings.Fields(string(out[start+10:]))[0])
+	if !bytes.HasPrefix(out[off:], []byte("xref\n")) {
+		t.Error("startxref does not point at the xref table")
+	}
+	return bytes.Count(out, []byte("/Type /Page "))
+}
+
+func TestWrite(t *testing.T) {
+	a, b := &Page{}, &Page{}
+	a.Text(10, 10, 12, true, `Zoë (李) \`)
+	b.Rect(10, 10, 20, 20)
+	b.DashedLine(0, 5, 100, 5)
+	var buf bytes.Buffer
+	if err := Write(&buf, []*Page{a, b}); err != nil {
+		t.Fatal(err)
+	}
+	if n := checkPDF(t, buf.Bytes()); n != 2 {
+		t.Errorf("%d pages, want 2", n)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte("/F2 12.0 Tf 10.0 10.0 Td (Zo\xeb \\(?\\) \\\\) Tj")) {
+		t.Error("text not encoded as WinAnsi with escapes")
+	}
+}
@@ -0,0 +1,96 @@
+package pdf
+
+import (
+	"fmt"
+	"io"
+)
+
+// Slip is one table's match slip: the players report the result on it and
+// hand it in.
+type Slip struct {
+	Tournament string
+	Round      int
+	Table      int
+	PlayerA    string
+	PlayerB    string
+	// PIN is the table's kiosk PIN, printed when the kiosk is on.
+	PIN string
+	// Colors labels PlayerA as white and PlayerB as black.
+	Colors bool
+}
+
+const (
+	margin        = 36
+	slipsPerPage  = 4
+	slipHeight    = (PageHeight - 2*margin) / slipsPerPage
+	slipGamesX    = 330
+	slipSignX     = 420
+	slipBoxWidth  = 50
+	slipBoxHeight = 20
+)
+
+// RenderSlips writes slips as a PDF, four to an A4 page with dashed lines
+// to cut along.
+func RenderSlips(w io.Writer, slips []Slip) error {
+	var pages []*Page
+	for i, s := range slips {
+		if i%slipsPerPage == 0 {
+			pages = append(pages, &Page{})
+		}
+		p := pages[len(pages)-1]
+		top := float64(PageHeight - margin - slipHeight*(i%slipsPerPage))
+		renderSlip(p, top, s)
+		if i%slipsPerPage != slipsPerPage-1 && i != len(slips)-1 {
+			p.DashedLine(margin, top-slipHeight, PageWidth-margin, top-slipHeight)
+		}
+	}
+	if len(pages) == 0 {
+		p := &Page{}
+		p.Text(margin, PageHeight-margin-18, 12, false, "No matches to print slips for.")
+		pages = append(pages, p)
+	}
+	return Write(w, pages)
+}
+
+func renderSlip(p *Page, top float64, s Slip) {
+	right := float64(PageWidth - margin)
+	p.Text(margin, top-24, 11, true, fit(s.Tournament, 50))
+	p.Text(margin, top-38, 10, false, fmt.Sprintf("Round %d", s.Round))
+	p.Text(right-90, top-30, 18, true, fmt.Sprintf("Table %d", s.Table))
+
+	y := top - 62
+	p.Text(margin, y, 9, true, "Player")
+	p.Text(slipGamesX, y, 9, true, "Games won")
+	p.Text(slipSignX, y, 9, true, "Signature")
+	for i, name := range []string{s.PlayerA, s.PlayerB} {
+		y -= 28
+		if s.Colors {
+			name = [2]string{"White: ", "Black: "}[i] + fit(name, 38)
+		}
+		p.Text(margin, y, 12, true, fit(name, 45))
+		p.Rect(slipGamesX, y-6, slipBoxWidth, slipBoxHeight)
+		p.Line(slipSignX, y-4, right, y-4)
+	}
+	y -= 28
+	p.Text(margin, y, 10, false, "Drawn games")
+	p.Rect(slipGamesX, y-6, slipBoxWidth, slipBoxHeight)
+
+	y -= 28
+	note := "Both players sign; hand this slip to the judges."
+	if s.PIN != "" {
+		note = fmt.Sprintf("Kiosk PIN: %s  |  Report at the kiosk or hand this slip to the judges.", s.PIN)
+	}
+	p.Text(margin, y, 9, false, note)
+}
+
+// fit shortens s to at most n characters, marking the cut.
+func fit(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	if n < 4 {
+		return string(r[:max(n, 0)])
+	}
+	return string(r[:n-3]) + "..."
+}
@@ -0,0 +1,62 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestRenderSlips(t *testing.T) {
+	var slips []Slip
+	for i := 1; i <= 5; i++ {
+		slips = append(slips, Slip{Tournament: "Spring Open", Round: 2, Table: i,
+			PlayerA: fmt.Sprintf("Alice %d", i), PlayerB: fmt.Sprintf("Bob %d", i)})
+	}
+	slips[4].PIN = "4821"
+	slips[4].PlayerB = strings.Repeat("B", 60)
+	var buf bytes.Buffer
+	if err := RenderSlips(&buf, slips); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.Bytes()
+	if n := checkPDF(t, out); n != 2 {
+		t.Errorf("%d pages, want 2 (four slips a page)", n)
+	}
+	for _, want := range []string{"(Table 5)", "(Round 2)", "(Alice 3)", "(Kiosk PIN: 4821", "(" + strings.Repeat("B", 42) + "...)"} {
+		if !bytes.Contains(out, []byte(want)) {
+			t.Errorf("PDF lacks %q", want)
+		}
+	}
+	if n := bytes.Count(out, []byte("Kiosk PIN")); n != 1 {
+		t.Errorf("%d slips show a PIN, want 1", n)
+	}
+	// Cut lines between the slips of a page, none after a page's last.
+	if n := bytes.Count(out, []byte("[4 3] 0 d")); n != 3 {
+		t.Errorf("%d cut lines, want 3", n)
+	}
+}
+
+func TestRenderSlips_Colors(t *testing.T) {
+	var buf bytes.Buffer
+	err := RenderSlips(&buf, []Slip{{Tournament: "Club Rapid", Round: 1, Table: 1,
+		PlayerA: "Alice", PlayerB: strings.Repeat("B", 60), Colors: true}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"(White: Alice)", "(Black: " + strings.Repeat("B", 35) + "...)"} {
+		if !bytes.Contains(buf.Bytes(), []byte(want)) {
+			t.Errorf("PDF lacks %q", want)
+		}
+	}
+}
+
+func TestRenderSlips_None(t *testing.T) {
+	var buf bytes.Buffer
+	if err := RenderSlips(&buf, nil); err != nil {
+		t.Fatal(err)
+	}
+	if checkPDF(t, buf.Bytes()) != 1 || !bytes.Contains(buf.Bytes(), []byte("No matches")) {
+		t.Error("empty render lacks its notice")
+	}
+}
@@ -0,0 +1,78 @@
+package pdf
+
+import (
+	"fmt"
+	"io"
+)
+
+// Column is a table column: its heading and width in points.
+type Column struct {
+	Heading string
+	Width   float64
+}
+
+// Table is a titled list, such as standings or seatings, printed over as
+// many pages as it needs with the column headings repeated on each.
+type Table struct {
+	Title    string
+	Subtitle string
+	Columns  []Column
+	Rows     [][]string
+	// Empty is printed instead of the table when it has no rows.
+	Empty string
+}
+
+const (
+	tableRowHeight = 18
+	tableTextSize  = 10
+	// tableCharWidth is roughly Helvetica's average character width at
+	// tableTextSize, for cutting cells to their column.
+	tableCharWidth = 5.5
+)
+
+// RenderTable writes t as a PDF.
+func RenderTable(w io.Writer, t Table) error {
+	var pages []*Page
+	rows := t.Rows
+	for len(pages) == 0 || len(rows) > 0 {
+		p := &Page{}
+		y := float64(PageHeight - margin - 18)
+		if len(pages) == 0 {
+			p.Text(margin, y, 16, true, t.Title)
+			y -= 16
+			p.Text(margin, y, 10, false, t.Subtitle)
+			y -= 26
+		}
+		pages = append(pages, p)
+		if len(rows) == 0 {
+			p.Text(margin, y, 12, false, t.Empty)
+			break
+		}
+
+		x := float64(margin)
+		for _, c := range t.Columns {
+			p.Text(x+2, y, tableTextSize, true, c.Heading)
+			x += c.Width
+		}
+		p.Line(margin, y-5, PageWidth-margin, y-5)
+		fits := int((y-margin-24)/tableRowHeight) - 1
+		n := min(fits, len(rows))
+		for _, row := range rows[:n] {
+			y -= tableRowHeight
+			x = margin
+			for i, c := range t.Columns {
+				if i < len(row) {
+					p.Text(x+2, y, tableTextSize, false, fit(row[i], int(c.Width/tableCharWidth)))
+				}
+				x += c.Width
+			}
+		}
+		rows = rows[n:]
+	}
+	if len(pages) > 1 {
+		for i, p := range pages {
+			p.Text(margin, margin, 9, false, fmt.Sprintf("%s  |  Page %d of %d", t.Title, i+1, len(pages)))
+		}
+	}
+	return Write(w, pages)
+}
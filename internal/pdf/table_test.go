@@ -0,0 +1,50 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func TestRenderTable(t *testing.T) {
+	tbl := Table{
+		Title:    "Spring Open - Standings",
+		Subtitle: "After round 3",
+		Columns:  []Column{{"Rank", 40}, {"Player", 30}, {"Points", 50}},
+	}
+	for i := 1; i <= 100; i++ {
+		tbl.Rows = append(tbl.Rows, []string{fmt.Sprint(i), fmt.Sprintf("Player %d", i), "9"})
+	}
+	var buf bytes.Buffer
+	if err := RenderTable(&buf, tbl); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.Bytes()
+	n := checkPDF(t, out)
+	if n < 2 {
+		t.Fatalf("%d pages for 100 rows", n)
+	}
+	if got := bytes.Count(out, []byte("(Rank)")); got != n {
+		t.Errorf("headings on %d of %d pages", got, n)
+	}
+	if !bytes.Contains(out, []byte(fmt.Sprintf("Page %d of %d)", n, n))) {
+		t.Error("no page footer")
+	}
+	if !bytes.Contains(out, []byte("(100)")) || !bytes.Contains(out, []byte("(Pl...)")) {
+		t.Error("rows missing or not cut to their column")
+	}
+	if bytes.Count(out, []byte("(After round 3)")) != 1 {
+		t.Error("subtitle not on the first page only")
+	}
+}
+
+func TestRenderTable_Empty(t *testing.T) {
+	var buf bytes.Buffer
+	if err := RenderTable(&buf, Table{Title: "Seatings", Empty: "No pairings yet."}); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.Bytes()
+	if checkPDF(t, out) != 1 || !bytes.Contains(out, []byte("(No pairings yet.)")) || bytes.Contains(out, []byte("Page 1")) {
+		t.Error("empty table not a single page with its notice")
+	}
+}
@@ -0,0 +1,66 @@
+// Package pow is the proof-of-work puzzle behind a tournament's Bot Check:
+// before an online sign-up counts, the browser must find a nonce whose
+// SHA-256 hash with the sign-up's challenge starts with Bits zero bits.
+// That takes a person's browser a second or two once, but makes signing up
+// thousands of bot accounts costly. It needs no third-party service, which
+// the site's Content-Security-Policy would block anyway.
+//
+// The challenge names the tournament and the account, so it needs no
+// server-side state or secret: each account solves it once per
+// tournament, which is as often as it can sign up.
+package pow
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"math/bits"
+	"strconv"
+)
+
+// Bits is how many leading zero bits a solution's hash needs: about
+// 130,000 hashes on average, a second or two in a browser.
+const Bits = 17
+
+// maxNonceLength bounds the nonce a client may send.
+const maxNonceLength = 20
+
+// Challenge is the puzzle account userID solves to sign up for tournament
+// tournamentID.
+func Challenge(tournamentID, userID int64) string {
+	return fmt.Sprintf("openswiss-register:%d:%d", tournamentID, userID)
+}
+
+// Verify reports whether nonce, a decimal number, solves challenge at
+// difficulty bits: SHA-256 of challenge, ":" and nonce starts with that
+// many zero bits.
+func Verify(challenge, nonce string, bits int) bool {
+	if nonce == "" || len(nonce) > maxNonceLength {
+		return false
+	}
+	if _, err := strconv.ParseUint(nonce, 10, 64); err != nil {
+		return false
+	}
+	return leadingZeros(sha256.Sum256([]byte(challenge+":"+nonce))) >= bits
+}
+
+// Solve finds the smallest nonce that solves challenge at difficulty bits,
+// as the browser does. It is for clients and tests.
+func Solve(challenge string, bits int) string {
+	for n := uint64(0); ; n++ {
+		nonce := strconv.FormatUint(n, 10)
+		if Verify(challenge, nonce, bits) {
+			return nonce
+		}
+	}
+}
+
+func leadingZeros(sum [sha256.Size]byte) int {
+	n := 0
+	for _, b := range sum {
+		if b != 0 {
+			return n + bits.LeadingZeros8(b)
+		}
+		n += 8
+	}
+	return n
+}
@@ -0,0 +1,47 @@
+package pow
+
+import (
+	"crypto/sha256"
+	"strings"
+	"testing"
+)
+
+func TestChallenge(t *testing.T) {
+	if got := Challenge(12, 34); got != "openswiss-register:12:34" {
+		t.Errorf("Challenge = %q", got)
+	}
+	if Challenge(12, 34) == Challenge(12, 35) || Challenge(12, 34) == Challenge(13, 34) {
+		t.Error("challenges of different accounts or tournaments should differ")
+	}
+}
+
+func TestSolveVerify(t *testing.T) {
+	challenge := Challenge(1, 2)
+	nonce := Solve(challenge, 12)
+	if !Verify(challenge, nonce, 12) {
+		t.Fatalf("Verify(%q) = false for its own solution", nonce)
+	}
+	sum := sha256.Sum256([]byte(challenge + ":" + nonce))
+	if sum[0] != 0 || sum[1]>>4 != 0 {
+		t.Errorf("hash %x doesn't start with 12 zero bits", sum)
+	}
+	if Verify(Challenge(1, 3), nonce, 12) && Verify(Challenge(2, 2), nonce, 12) {
+		t.Error("a solution shouldn't carry over to other challenges")
+	}
+	for _, bad := range []string{"", "-1", "1e3", " " + nonce, strings.Repeat("1", 21)} {
+		if Verify(challenge, bad, 0) {
+			t.Errorf("Verify(%q) = true, want false", bad)
+		}
+	}
+}
+
+func TestLeadingZeros(t *testing.T) {
+	var sum [sha256.Size]byte
+	if got := leadingZeros(sum); got != 256 {
+		t.Errorf("all zero = %d, want 256", got)
+	}
+	sum[1] = 0x10
+	if got := leadingZeros(sum); got != 11 {
+		t.Errorf("0x0010… = %d, want 11", got)
+	}
+}
@@ -0,0 +1,470 @@
+// Package qr encodes short text, such as a registration URL, as a QR code
+// and draws it as SVG, for signs printed and taped up at the venue. It only
+// does what that needs: byte mode, error correction level M, versions 1-10
+// (up to 213 bytes).
+package qr
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ErrTooLong is returned for text that doesn't fit in a version 10 code.
+var ErrTooLong = errors.New("qr: text too long")
+
+// block describes a version's error correction blocks at level M: each
+// block has ecLen error correction codewords, and the data codewords are
+// split into blocks1 blocks of data1 bytes followed by blocks2 of data1+1.
+type block struct {
+	ecLen, blocks1, data1, blocks2 int
+}
+
+// levelM holds the level M block layout of versions 1-10, indexed by
+// version.
+var levelM = [...]block{
+	{},
+	{10, 1, 16, 0},
+	{16, 1, 28, 0},
+	{26, 1, 44, 0},
+	{18, 2, 32, 0},
+	{24, 2, 43, 0},
+	{16, 4, 27, 0},
+	{18, 4, 31, 0},
+	{22, 2, 38, 2},
+	{22, 3, 36, 2},
+	{26, 4, 43, 1},
+}
+
+// alignment lists the alignment pattern centre coordinates per version.
+var alignment = [...][]int{
+	{}, {},
+	{6, 18},
+	{6, 22},
+	{6, 26},
+	{6, 30},
+	{6, 34},
+	{6, 22, 38},
+	{6, 24, 42},
+	{6, 26, 46},
+	{6, 28, 50},
+}
+
+func (b block) dataLen() int { return b.blocks1*b.data1 + b.blocks2*(b.data1+1) }
+
+// Code is an encoded QR code: a square grid of dark and light modules,
+// without the quiet zone.
+type Code struct {
+	Version int
+	Size    int
+	modules [][]bool
+	// function marks the finder, timing, alignment, format and version
+	// modules, which hold no data and are never masked.
+	function [][]bool
+}
+
+// Dark reports whether the module at column x, row y is dark.
+func (c *Code) Dark(x, y int) bool { return c.modules[y][x] }
+
+// Encode encodes text as the smallest QR code that holds it, with the mask
+// that scores best by the standard's penalty rules.
+func Encode(text string) (*Code, error) {
+	version := 0
+	for v := 1; v < len(levelM); v++ {
+		if 4+countBits(v)+8*len(text) <= 8*levelM[v].dataLen() {
+			version = v
+			break
+		}
+	}
+	if version == 0 {
+		return nil, ErrTooLong
+	}
+	size := 17 + 4*version
+	c := &Code{Version: version, Size: size, modules: grid(size), function: grid(size)}
+	c.drawFunctionPatterns()
+	c.drawCodewords(interleave(version, dataCodewords(version, text)))
+
+	best, bestPenalty := 0, -1
+	for mask := 0; mask < 8; mask++ {
+		c.applyMask(mask)
+		c.drawFormat(mask)
+		if p := c.penalty(); bestPenalty < 0 || p < bestPenalty {
+			best, bestPenalty = mask, p
+		}
+		c.applyMask(mask) // undo: masking is an XOR
+	}
+	c.applyMask(best)
+	c.drawFormat(best)
+	return c, nil
+}
+
+func grid(size int) [][]bool {
+	g := make([][]bool, size)
+	for i := range g {
+		g[i] = make([]bool, size)
+	}
+	return g
+}
+
+// countBits is the width of the byte mode character count field.
+func countBits(version int) int {
+	if version < 10 {
+		return 8
+	}
+	return 16
+}
+
+// dataCodewords builds the data codewords: mode indicator, count, the
+// bytes, terminator, then pad bytes up to the version's capacity.
+func dataCodewords(version int, text string) []byte {
+	capacity := levelM[version].dataLen()
+	var bits []bool
+	put := func(v, n int) {
+		for i := n - 1; i >= 0; i-- {
+			bits = append(bits, v>>i&1 == 1)
+		}
+	}
+	put(0b0100, 4)
+	put(len(text), countBits(version))
+	for i := 0; i < len(text); i++ {
+		put(int(text[i]), 8)
+	}
+	put(0, min(4, 8*capacity-len(bits)))
+	put(0, (8-len(bits)%8)%8)
+
+	out := make([]byte, 0, capacity)
+	for i := 0; i < len(bits); i += 8 {
+		var b byte
+		for j := 0; j < 8; j++ {
+			if bits[i+j] {
+				b |= 0x80 >> j
+			}
+		}
+		out = append(out, b)
+	}
+	for pad := byte(0xEC); len(out) < capacity; pad ^= 0xEC ^ 0x11 {
+		out = append(out, pad)
+	}
+	return out
+}
+
+// interleave splits data into the version's blocks, adds each block's
+// error correction and interleaves the result in transmission order.
+func interleave(version int, data []byte) []byte {
+	b := levelM[version]
+	var blocks, ecs [][]byte
+	for i := 0; i < b.blocks1+b.blocks2; i++ {
+		n := b.data1
+		if i >= b.blocks1 {
+			n++
+		}
+		blocks = append(blocks, data[:n])
+		ecs = append(ecs, reedSolomon(data[:n], b.ecLen))
+		data = data[n:]
+	}
+	var out []byte
+	for i := 0; i <= b.data1; i++ {
+		for _, blk := range blocks {
+			if i < len(blk) {
+				out = append(out, blk[i])
+			}
+		}
+	}
+	for i := 0; i < b.ecLen; i++ {
+		for _, ec := range ecs {
+			out = append(out, ec[i])
+		}
+	}
+	return out
+}
+
+// gfMul multiplies in GF(256) with the QR polynomial x^8+x^4+x^3+x^2+1.
+func gfMul(a, b byte) byte {
+	var p byte
+	for ; b > 0; b >>= 1 {
+		if b&1 == 1 {
+			p ^= a
+		}
+		carry := a&0x80 != 0
+		a <<= 1
+		if carry {
+			a ^= 0x1D
+		}
+	}
+	return p
+}
+
+// reedSolomon returns the n error correction codewords of data.
+func reedSolomon(data []byte, n int) []byte {
+	// Generator polynomial (x - α^0)(x - α^1)...(x - α^(n-1)), highest
+	// coefficient (always 1) dropped.
+	gen := make([]byte, n)
+	gen[n-1] = 1
+	root := byte(1)
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			gen[j] = gfMul(gen[j], root)
+			if j+1 < n {
+				gen[j] ^= gen[j+1]
+			}
+		}
+		root = gfMul(root, 2)
+	}
+	rem := make([]byte, n)
+	for _, d := range data {
+		factor := d ^ rem[0]
+		copy(rem, rem[1:])
+		rem[n-1] = 0
+		for j := range rem {
+			rem[j] ^= gfMul(gen[j], factor)
+		}
+	}
+	return rem
+}
+
+func (c *Code) set(x, y int, dark bool) {
+	c.modules[y][x] = dark
+	c.function[y][x] = true
+}
+
+func (c *Code) drawFunctionPatterns() {
+	for i := 0; i < c.Size; i++ {
+		c.set(6, i, i%2 == 0)
+		c.set(i, 6, i%2 == 0)
+	}
+	c.drawFinder(3, 3)
+	c.drawFinder(c.Size-4, 3)
+	c.drawFinder(3, c.Size-4)
+
+	pos := alignment[c.Version]
+	last := len(pos) - 1
+	for i, y := range pos {
+		for j, x := range pos {
+			// The corners next to the finders stay free.
+			if i == 0 && j == 0 || i == 0 && j == last || i == last && j == 0 {
+				continue
+			}
+			for dy := -2; dy <= 2; dy++ {
+				for dx := -2; dx <= 2; dx++ {
+					c.set(x+dx, y+dy, max(abs(dx), abs(dy)) != 1)
+				}
+			}
+		}
+	}
+
+	// Reserve the format areas until drawFormat fills them in, and draw
+	// the version information, which doesn't depend on the mask.
+	c.drawFormat(0)
+	if c.Version >= 7 {
+		bits := versionBits(c.Version)
+		for i := 0; i < 18; i++ {
+			dark := bits>>i&1 == 1
+			a, b := c.Size-11+i%3, i/3
+			c.set(a, b, dark)
+			c.set(b, a, dark)
+		}
+	}
+}
+
+// drawFinder draws a finder pattern centred on (x, y), with its light
+// separator.
+func (c *Code) drawFinder(x, y int) {
+	for dy := -4; dy <= 4; dy++ {
+		for dx := -4; dx <= 4; dx++ {
+			xx, yy := x+dx, y+dy
+			if xx < 0 || xx >= c.Size || yy < 0 || yy >= c.Size {
+				continue
+			}
+			d := max(abs(dx), abs(dy))
+			c.set(xx, yy, d != 2 && d != 4)
+		}
+	}
+}
+
+// versionBits is the 18-bit version information of versions 7 and up:
+// the version protected by a BCH code.
+func versionBits(version int) int {
+	rem := version
+	for i := 0; i < 12; i++ {
+		rem = rem<<1 ^ (rem>>11)*0x1F25
+	}
+	return version<<12 | rem
+}
+
+// formatBits is the 15-bit format information: level M and the mask,
+// protected by a BCH code and XORed with the standard's fixed pattern.
+func formatBits(mask int) int {
+	data := mask // level M's indicator is 00
+	rem := data
+	for i := 0; i < 10; i++ {
+		rem = rem<<1 ^ (rem>>9)*0x537
+	}
+	return (data<<10 | rem) ^ 0x5412
+}
+
+// drawFormat draws both copies of the format information and sets the dark
+// module.
+func (c *Code) drawFormat(mask int) {
+	bits := formatBits(mask)
+	bit := func(i int) bool { return bits>>i&1 == 1 }
+
+	for i := 0; i <= 5; i++ {
+		c.set(8, i, bit(i))
+	}
+	c.set(8, 7, bit(6))
+	c.set(8, 8, bit(7))
+	c.set(7, 8, bit(8))
+	for i := 9; i < 15; i++ {
+		c.set(14-i, 8, bit(i))
+	}
+	for i := 0; i < 8; i++ {
+		c.set(c.Size-1-i, 8, bit(i))
+	}
+	for i := 8; i < 15; i++ {
+		c.set(8, c.Size-15+i, bit(i))
+	}
+	c.set(8, c.Size-8, true)
+}
+
+// drawCodewords places data in the zigzag of two-module columns, from the
+// bottom right corner, skipping function modules. Leftover remainder
+// modules stay light.
+func (c *Code) drawCodewords(data []byte) {
+	i := 0
+	for right := c.Size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5 // the vertical timing pattern
+		}
+		for vert := 0; vert < c.Size; vert++ {
+			for j := 0; j < 2; j++ {
+				x := right - j
+				y := vert
+				if (right+1)&2 == 0 {
+					y = c.Size - 1 - vert // upward column
+				}
+				if c.function[y][x] || i >= 8*len(data) {
+					continue
+				}
+				c.modules[y][x] = data[i>>3]>>(7-i&7)&1 == 1
+				i++
+			}
+		}
+	}
+}
+
+func (c *Code) applyMask(mask int) {
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			if c.function[y][x] {
+				continue
+			}
+			var flip bool
+			switch mask {
+			case 0:
+				flip = (x+y)%2 == 0
+			case 1:
+				flip = y%2 == 0
+			case 2:
+				flip = x%3 == 0
+			case 3:
+				flip = (x+y)%3 == 0
+			case 4:
+				flip = (x/3+y/2)%2 == 0
+			case 5:
+				flip = x*y%2+x*y%3 == 0
+			case 6:
+				flip = (x*y%2+x*y%3)%2 == 0
+			case 7:
+				flip = ((x+y)%2+x*y%3)%2 == 0
+			}
+			if flip {
+				c.modules[y][x] = !c.modules[y][x]
+			}
+		}
+	}
+}
+
+// finderLike is the 1:1:3:1:1 finder ratio with four light modules after
+// it, which penalty looks for in both directions.
+var finderLike = []bool{true, false, true, true, true, false, true, false, false, false, false}
+
+// penalty scores the code by the standard's four rules; the encoder picks
+// the mask with the lowest score.
+func (c *Code) penalty() int {
+	n := c.Size
+	p := 0
+	line := func(get func(i int) bool) {
+		run := 1
+		for i := 1; i <= n; i++ {
+			if i < n && get(i) == get(i-1) {
+				run++
+				continue
+			}
+			if run >= 5 {
+				p += 3 + run - 5
+			}
+			run = 1
+		}
+		for i := 0; i+len(finderLike) <= n; i++ {
+			fwd, back := true, true
+			for k, dark := range finderLike {
+				fwd = fwd && get(i+k) == dark
+				back = back && get(i+len(finderLike)-1-k) == dark
+			}
+			if fwd {
+				p += 40
+			}
+			if back {
+				p += 40
+			}
+		}
+	}
+	dark := 0
+	for i := 0; i < n; i++ {
+		line(func(j int) bool { return c.modules[i][j] })
+		line(func(j int) bool { return c.modules[j][i] })
+		for j := 0; j < n; j++ {
+			if c.modules[i][j] {
+				dark++
+			}
+			if i+1 < n && j+1 < n {
+				m := c.modules[i][j]
+				if m == c.modules[i][j+1] && m == c.modules[i+1][j] && m == c.modules[i+1][j+1] {
+					p += 3
+				}
+			}
+		}
+	}
+	total := n * n
+	k := (abs(dark*20-total*10)+total-1)/total - 1
+	return p + 10*k
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// QuietZone is the light border, in modules, WriteSVG draws around the code.
+const QuietZone = 4
+
+// WriteSVG writes the code as a standalone SVG image, one unit per module,
+// with the quiet zone included. It scales to any size without blurring.
+func (c *Code) WriteSVG(w io.Writer) error {
+	n := c.Size + 2*QuietZone
+	var path strings.Builder
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			if c.modules[y][x] {
+				fmt.Fprintf(&path, "M%d %dh1v1h-1z", x+QuietZone, y+QuietZone)
+			}
+		}
+	}
+	_, err := fmt.Fprintf(w, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" shape-rendering="crispEdges">`+
+		`<rect width="%d" height="%d" fill="#fff"/><path d="%s" fill="#000"/></svg>`,
+		n, n, n, n, path.String())
+	return err
+}
@@ -0,0 +1,228 @@
+package qr
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestReedSolomon(t *testing.T) {
+	// "HELLO WORLD" as a 1-M code, from the standard's worked example.
+	data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	want := []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}
+	if got := reedSolomon(data, 10); !bytes.Equal(got, want) {
+		t.Errorf("reedSolomon = %v, want %v", got, want)
+	}
+}
+
+func TestFormatBits(t *testing.T) {
+	want := []string{
+		"101010000010010", "101000100100101", "101111001111100", "101101101001011",
+		"100010111111001", "100000011001110", "100111110010111", "100101010100000",
+	}
+	for mask, w := range want {
+		if got := fmt.Sprintf("%015b", formatBits(mask)); got != w {
+			t.Errorf("mask %d: format = %s, want %s", mask, got, w)
+		}
+	}
+}
+
+func TestVersionBits(t *testing.T) {
+	if got := fmt.Sprintf("%018b", versionBits(7)); got != "000111110010010100" {
+		t.Errorf("version 7 = %s", got)
+	}
+}
+
+func TestEncode_Version(t *testing.T) {
+	for _, tt := range []struct {
+		n, version int
+	}{
+		{1, 1}, {14, 1}, {15, 2}, {26, 2}, {27, 3}, {152, 8}, {153, 9}, {180, 9}, {181, 10}, {213, 10},
+	} {
+		c, err := Encode(strings.Repeat("a", tt.n))
+		if err != nil {
+			t.Fatalf("%d bytes: %v", tt.n, err)
+		}
+		if c.Version != tt.version || c.Size != 17+4*tt.version {
+			t.Errorf("%d bytes: version %d size %d, want version %d", tt.n, c.Version, c.Size, tt.version)
+		}
+	}
+	if _, err := Encode(strings.Repeat("a", 214)); !errors.Is(err, ErrTooLong) {
+		t.Errorf("214 bytes: err = %v, want ErrTooLong", err)
+	}
+}
+
+func TestEncode_RoundTrip(t *testing.T) {
+	for _, text := range []string{
+		"",
+		"https://openswiss.example.com/register",
+		"https://openswiss.example.com/tournaments/12345",
+		strings.Repeat("https://example.com/ü/", 6), // version 8: two block sizes
+		strings.Repeat("x", 213),
+	} {
+		c, err := Encode(text)
+		if err != nil {
+			t.Fatalf("%q: %v", text, err)
+		}
+		if got := decode(t, c); got != text {
+			t.Errorf("v%d: decoded %q, want %q", c.Version, got, text)
+		}
+	}
+}
+
+func TestEncode_FunctionPatterns(t *testing.T) {
+	c, err := Encode("https://openswiss.example.com/register")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The top left finder's outer ring and centre, its separator, and the
+	// timing pattern.
+	for i := 0; i < 7; i++ {
+		if !c.Dark(i, 0) || !c.Dark(0, i) || !c.Dark(6, i) || c.Dark(7, i) {
+			t.Fatalf("finder broken at %d", i)
+		}
+	}
+	if !c.Dark(3, 3) || c.Dark(1, 1) {
+		t.Error("finder centre or gap wrong")
+	}
+	for i := 8; i < c.Size-8; i++ {
+		if c.Dark(i, 6) != (i%2 == 0) {
+			t.Errorf("timing module %d wrong", i)
+		}
+	}
+	if !c.Dark(8, c.Size-8) {
+		t.Error("dark module missing")
+	}
+}
+
+func TestWriteSVG(t *testing.T) {
+	c, err := Encode("hi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := c.WriteSVG(&buf); err != nil {
+		t.Fatal(err)
+	}
+	svg := buf.String()
+	n := strconv.Itoa(c.Size + 2*QuietZone)
+	if !strings.HasPrefix(svg, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 `+n+" "+n+`"`) {
+		t.Errorf("svg header: %.80s", svg)
+	}
+	// The top left module of the finder sits inside the quiet zone.
+	if !strings.Contains(svg, "M4 4h1v1h-1z") {
+		t.Error("finder corner missing")
+	}
+}
+
+// decode reads c back the way a scanner would once it has located the
+// grid: format information, unmasking, the codeword zigzag, the blocks and
+// their error correction, then the byte mode segment.
+func decode(t *testing.T, c *Code) string {
+	t.Helper()
+	read := 0
+	for i := 0; i <= 5; i++ {
+		read |= b2i(c.Dark(8, i)) << i
+	}
+	read |= b2i(c.Dark(8, 7))<<6 | b2i(c.Dark(8, 8))<<7 | b2i(c.Dark(7, 8))<<8
+	for i := 9; i < 15; i++ {
+		read |= b2i(c.Dark(14-i, 8)) << i
+	}
+	mask := -1
+	for m := 0; m < 8; m++ {
+		if formatBits(m) == read {
+			mask = m
+		}
+	}
+	if mask < 0 {
+		t.Fatalf("format %015b matches no mask", read)
+	}
+
+	// A blank code of the same version knows which modules carry data.
+	plain := &Code{Version: c.Version, Size: c.Size, modules: grid(c.Size), function: grid(c.Size)}
+	plain.drawFunctionPatterns()
+	for y := range c.modules {
+		copy(plain.modules[y], c.modules[y])
+	}
+	plain.applyMask(mask)
+
+	b := levelM[c.Version]
+	nblocks := b.blocks1 + b.blocks2
+	total := b.dataLen() + nblocks*b.ecLen
+	raw := make([]byte, total)
+	i := 0
+	for right := c.Size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		for vert := 0; vert < c.Size; vert++ {
+			for j := 0; j < 2; j++ {
+				x, y := right-j, vert
+				if (right+1)&2 == 0 {
+					y = c.Size - 1 - vert
+				}
+				if plain.function[y][x] || i >= 8*total {
+					continue
+				}
+				if plain.modules[y][x] {
+					raw[i>>3] |= 0x80 >> (i & 7)
+				}
+				i++
+			}
+		}
+	}
+	if i != 8*total {
+		t.Fatalf("read %d bits, want %d", i, 8*total)
+	}
+
+	blocks := make([][]byte, nblocks)
+	k := 0
+	for pos := 0; pos <= b.data1; pos++ {
+		for n := range blocks {
+			if pos < b.data1 || n >= b.blocks1 {
+				blocks[n] = append(blocks[n], raw[k])
+				k++
+			}
+		}
+	}
+	var data []byte
+	for n, blk := range blocks {
+		var ec []byte
+		for e := 0; e < b.ecLen; e++ {
+			ec = append(ec, raw[k+e*nblocks+n])
+		}
+		if want := reedSolomon(blk, b.ecLen); !bytes.Equal(ec, want) {
+			t.Fatalf("block %d error correction mismatch", n)
+		}
+		data = append(data, blk...)
+	}
+
+	if data[0]>>4 != 0b0100 {
+		t.Fatalf("mode %04b, want byte mode", data[0]>>4)
+	}
+	bit := func(p int) int { return int(data[p>>3]>>(7-p&7)) & 1 }
+	num := func(p, n int) int {
+		v := 0
+		for j := 0; j < n; j++ {
+			v = v<<1 | bit(p+j)
+		}
+		return v
+	}
+	cb := countBits(c.Version)
+	length := num(4, cb)
+	out := make([]byte, length)
+	for j := range out {
+		out[j] = byte(num(4+cb+8*j, 8))
+	}
+	return string(out)
+}
+
+func b2i(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,195 @@
+package readonly
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dstathis/openswiss/internal/middleware"
+)
+
+const (
+	// retryAfter is the Retry-After sent with refused writes, in seconds.
+	retryAfter = 60
+	// maxCachedPages and maxCachedBody bound the memory the page cache uses.
+	maxCachedPages = 256
+	maxCachedBody  = 1 << 20
+	// StaleHeader marks a response served from the page cache; its value is
+	// when the page was rendered.
+	StaleHeader = "X-OpenSwiss-Stale"
+)
+
+// Wrap returns middleware that refuses writes while read-only and keeps the
+// public pages in Config.Cached, serving the last good copy when rendering
+// one fails while read-only. It also puts the Mode into the request context
+// for Report, so it must run before any handler that writes.
+func (m *Mode) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r = r.WithContext(context.WithValue(r.Context(), ctxKey{}, m))
+		readOnly := m.Reason()
+
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+		default:
+			if readOnly != "" && !m.exempt[r.URL.Path] {
+				refuse(w, r, readOnly)
+				return
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if r.Method != http.MethodGet || middleware.GetUser(r.Context()) != nil || !m.isCached(r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		// Pages are rendered per locale, so each language is kept apart.
+		key := middleware.Locale(r) + " " + r.URL.RequestURI()
+		if readOnly == "" {
+			// Writable: pass the response through and keep a copy.
+			tw := &teeWriter{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(tw, r)
+			if tw.status == http.StatusOK && !tw.overflow {
+				m.pages.put(key, tw.Header().Get("Content-Type"), tw.body.Bytes())
+			}
+			return
+		}
+		// Read-only: hold the response back until we know it rendered.
+		bw := &bufferWriter{header: http.Header{}, status: http.StatusOK}
+		next.ServeHTTP(bw, r)
+		if bw.status >= http.StatusInternalServerError {
+			if p, ok := m.pages.get(key); ok {
+				w.Header().Set("Content-Type", p.contentType)
+				w.Header().Set(StaleHeader, p.stored.UTC().Format(http.TimeFormat))
+				w.Header().Set("Cache-Control", "no-store")
+				_, _ = w.Write(p.body)
+				return
+			}
+		}
+		for k, v := range bw.header {
+			w.Header()[k] = v
+		}
+		w.WriteHeader(bw.status)
+		_, _ = w.Write(bw.body.Bytes())
+	})
+}
+
+func (m *Mode) isCached(path string) bool {
+	for _, p := range m.cached {
+		if path == p || strings.HasPrefix(path, p+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// refuse answers a write while read-only: JSON for the API, plain text
+// otherwise.
+func refuse(w http.ResponseWriter, r *http.Request, reason string) {
+	msg := "OpenSwiss is temporarily read-only (" + reason + "). Nothing was changed; please try again later."
+	w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+	if strings.HasPrefix(r.URL.Path, "/api/") {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": msg})
+		return
+	}
+	http.Error(w, msg, http.StatusServiceUnavailable)
+}
+
+// teeWriter passes a response through while copying up to maxCachedBody of
+// it.
+type teeWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+	overflow    bool
+}
+
+func (tw *teeWriter) WriteHeader(code int) {
+	if !tw.wroteHeader {
+		tw.status = code
+		tw.wroteHeader = true
+	}
+	tw.ResponseWriter.WriteHeader(code)
+}
+
+func (tw *teeWriter) Write(b []byte) (int, error) {
+	tw.wroteHeader = true
+	if !tw.overflow {
+		if tw.body.Len()+len(b) > maxCachedBody {
+			tw.overflow = true
+			tw.body = bytes.Buffer{}
+		} else {
+			tw.body.Write(b)
+		}
+	}
+	return tw.ResponseWriter.Write(b)
+}
+
+// bufferWriter holds a whole response in memory.
+type bufferWriter struct {
+	header      http.Header
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (bw *bufferWriter) Header() http.Header { return bw.header }
+
+func (bw *bufferWriter) WriteHeader(code int) {
+	if !bw.wroteHeader {
+		bw.status = code
+		bw.wroteHeader = true
+	}
+}
+
+func (bw *bufferWriter) Write(b []byte) (int, error) {
+	bw.wroteHeader = true
+	return bw.body.Write(b)
+}
+
+type cachedPage struct {
+	contentType string
+	body        []byte
+	stored      time.Time
+}
+
+// pageCache keeps the most recently stored pages, dropping the oldest once
+// it holds max of them.
+type pageCache struct {
+	mu    sync.Mutex
+	max   int
+	pages map[string]cachedPage
+	order []string
+}
+
+func newPageCache(max int) *pageCache {
+	return &pageCache{max: max, pages: map[string]cachedPage{}}
+}
+
+func (c *pageCache) put(key, contentType string, body []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.pages[key]; !ok {
+		if len(c.order) >= c.max {
+			delete(c.pages, c.order[0])
+			c.order = c.order[1:]
+		}
+		c.order = append(c.order, key)
+	}
+	c.pages[key] = cachedPage{contentType: contentType, body: bytes.Clone(body), stored: time.Now()}
+}
+
+func (c *pageCache) get(key string) (cachedPage, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	p, ok := c.pages[key]
+	return p, ok
+}
@@ -0,0 +1,216 @@
+// Package readonly implements the server's read-only mode. While it is on,
+// every state-changing request is refused with 503 and public pages keep
+// serving the last copy that rendered fine. An admin can switch it on and
+// off by hand; it also switches itself on when the database stops taking
+// writes and back off once writes succeed again.
+package readonly
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"log/slog"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// MaxReason is the longest reason Set keeps, in characters.
+const MaxReason = 200
+
+// Status describes the current mode. Manual and Automatic can both be set;
+// the mode is on while either is.
+type Status struct {
+	Enabled   bool      `json:"enabled"`
+	Manual    bool      `json:"manual"`
+	Automatic bool      `json:"automatic"`
+	Reason    string    `json:"reason,omitempty"`
+	Since     time.Time `json:"since,omitzero"`
+}
+
+// Mode is the read-only switch. The zero value is not usable; use New.
+type Mode struct {
+	mu           sync.RWMutex
+	manual       bool
+	manualReason string
+	manualSince  time.Time
+	auto         bool
+	autoReason   string
+	autoSince    time.Time
+
+	exempt map[string]bool
+	cached []string
+	pages  *pageCache
+}
+
+// Config says which requests Wrap treats specially.
+type Config struct {
+	// Exempt paths accept writes even while read-only, e.g. login and the
+	// switch itself.
+	Exempt []string
+	// Cached path prefixes are the public pages kept for serving while
+	// read-only. A prefix matches itself and everything below it; "/"
+	// matches only the home page.
+	Cached []string
+}
+
+// New returns a writable Mode.
+func New(cfg Config) *Mode {
+	m := &Mode{exempt: map[string]bool{}, cached: cfg.Cached, pages: newPageCache(maxCachedPages)}
+	for _, p := range cfg.Exempt {
+		m.exempt[p] = true
+	}
+	return m
+}
+
+// Status returns the current mode. A manual reason wins over an automatic
+// one.
+func (m *Mode) Status() Status {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	s := Status{Enabled: m.manual || m.auto, Manual: m.manual, Automatic: m.auto}
+	switch {
+	case m.manual:
+		s.Reason, s.Since = m.manualReason, m.manualSince
+	case m.auto:
+		s.Reason, s.Since = m.autoReason, m.autoSince
+	}
+	return s
+}
+
+// Reason returns why the server is read-only, or "" if it isn't. Templates
+// use it for the banner.
+func (m *Mode) Reason() string {
+	if s := m.Status(); s.Enabled {
+		if s.Reason == "" {
+			return "maintenance"
+		}
+		return s.Reason
+	}
+	return ""
+}
+
+// Set switches manual read-only mode on or off.
+func (m *Mode) Set(on bool, reason string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if on && !m.manual {
+		m.manualSince = time.Now()
+	}
+	m.manual = on
+	m.manualReason = ""
+	if on {
+		reason = strings.TrimSpace(reason)
+		if r := []rune(reason); len(r) > MaxReason {
+			reason = string(r[:MaxReason])
+		}
+		m.manualReason = reason
+	}
+}
+
+// trip switches automatic read-only mode on.
+func (m *Mode) trip(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.auto {
+		return
+	}
+	m.auto = true
+	m.autoReason = "the database is not accepting writes"
+	m.autoSince = time.Now()
+	slog.Error("entering read-only mode", "err", err)
+}
+
+// recover switches automatic read-only mode off.
+func (m *Mode) recover() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.auto {
+		return
+	}
+	m.auto = false
+	slog.Info("database writes succeed again, leaving read-only mode", "after", time.Since(m.autoSince).Round(time.Second))
+}
+
+// IsStorageFailure reports whether err means the database can't take writes
+// at all, as opposed to rejecting one request: a lost connection, a full
+// disk, an I/O error, a server shutting down, or a read-only server (a
+// replica after failover).
+func IsStorageFailure(err error) bool {
+	// A request that ran out of time says nothing about the database.
+	if err == nil || errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return false
+	}
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, sql.ErrConnDone) {
+		return true
+	}
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code.Class() {
+		case "08", "53", "58", "XX": // connection, insufficient resources, system error, internal
+			return true
+		}
+		switch pqErr.Code {
+		case "25006", "57P01", "57P02", "57P03": // read-only transaction, shutdowns, cannot connect now
+			return true
+		}
+		return false
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+type ctxKey struct{}
+
+// Report switches the Mode carried by ctx (see Wrap) to read-only if err is
+// a storage failure. Write paths call it with their errors.
+func Report(ctx context.Context, err error) {
+	if m, ok := ctx.Value(ctxKey{}).(*Mode); ok && IsStorageFailure(err) {
+		m.trip(err)
+	}
+}
+
+// Probe checks every interval that the database takes writes, switching
+// automatic read-only mode on when it doesn't and off again when it does.
+// It returns when ctx is cancelled.
+func (m *Mode) Probe(ctx context.Context, database *sql.DB, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		probeCtx, cancel := context.WithTimeout(ctx, interval)
+		err := probeWrite(probeCtx, database)
+		cancel()
+		switch {
+		case ctx.Err() != nil:
+			return
+		case err != nil:
+			m.trip(err)
+		default:
+			m.recover()
+		}
+	}
+}
+
+// probeWrite commits a transaction with a transaction ID assigned. Postgres
+// writes and flushes a commit record to the WAL for it, so this fails when
+// the disk is full or the server is read-only, without touching any table.
+func probeWrite(ctx context.Context, database *sql.DB) error {
+	tx, err := database.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.ExecContext(ctx, `SELECT txid_current()`); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,224 @@
+package readonly
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/lib/pq"
+
+	"github.com/dstathis/openswiss/internal/middleware"
+)
+
+func newMode() *Mode {
+	return New(Config{Exempt: []string{"/login"}, Cached: []string{"/", "/tournaments"}})
+}
+
+func TestSetAndStatus(t *testing.T) {
+	m := newMode()
+	if s := m.Status(); s.Enabled || m.Reason() != "" {
+		t.Fatalf("new mode is read-only: %+v", s)
+	}
+	m.Set(true, "  moving servers ")
+	s := m.Status()
+	if !s.Enabled || !s.Manual || s.Automatic || s.Reason != "moving servers" || s.Since.IsZero() {
+		t.Errorf("status = %+v", s)
+	}
+	m.Set(true, "")
+	if got := m.Reason(); got != "maintenance" {
+		t.Errorf("Reason() with no reason = %q, want maintenance", got)
+	}
+	m.Set(true, strings.Repeat("x", MaxReason+10))
+	if got := len(m.Status().Reason); got != MaxReason {
+		t.Errorf("reason length = %d, want %d", got, MaxReason)
+	}
+
+	m.Set(false, "")
+	m.trip(errors.New("disk full"))
+	if s := m.Status(); !s.Enabled || s.Manual || !s.Automatic {
+		t.Errorf("after trip: %+v", s)
+	}
+	// Switching manual mode off leaves automatic mode on.
+	m.Set(false, "")
+	if !m.Status().Enabled {
+		t.Error("manual off cleared automatic mode")
+	}
+	m.recover()
+	if m.Status().Enabled {
+		t.Error("still read-only after recover")
+	}
+}
+
+func TestIsStorageFailure(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{errors.New("tournament is not running"), false},
+		{driver.ErrBadConn, true},
+		{fmt.Errorf("begin tx: %w", driver.ErrBadConn), true},
+		{&pq.Error{Code: "53100"}, true}, // disk_full
+		{&pq.Error{Code: "58030"}, true}, // io_error
+		{&pq.Error{Code: "08006"}, true}, // connection_failure
+		{&pq.Error{Code: "25006"}, true}, // read_only_sql_transaction
+		{&pq.Error{Code: "57P01"}, true}, // admin_shutdown
+		{&pq.Error{Code: "23505"}, false},
+		{&pq.Error{Code: "40001"}, false},
+		{&pq.Error{Code: "55P03"}, false}, // lock_not_available
+		{fmt.Errorf("begin tx: %w", context.DeadlineExceeded), false},
+		{fmt.Errorf("save: %w", &pq.Error{Code: "53100"}), true},
+	}
+	for _, tt := range tests {
+		if got := IsStorageFailure(tt.err); got != tt.want {
+			t.Errorf("IsStorageFailure(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestReport(t *testing.T) {
+	m := newMode()
+	Report(context.Background(), &pq.Error{Code: "53100"}) // no Mode in context: no-op
+	var ctx context.Context
+	m.Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx = r.Context()
+	})).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/x", nil))
+
+	Report(ctx, errors.New("bad request"))
+	if m.Status().Enabled {
+		t.Fatal("ordinary error switched to read-only")
+	}
+	Report(ctx, &pq.Error{Code: "53100"})
+	if !m.Status().Automatic {
+		t.Error("disk full did not switch to read-only")
+	}
+}
+
+func TestWrap_RefusesWrites(t *testing.T) {
+	m := newMode()
+	var calls int
+	h := m.Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { calls++ }))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/tournaments/1/start", nil))
+	if calls != 1 {
+		t.Fatal("write refused while writable")
+	}
+
+	m.Set(true, "upgrade")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("POST", "/tournaments/1/start", nil))
+	if rec.Code != http.StatusServiceUnavailable || rec.Header().Get("Retry-After") == "" {
+		t.Errorf("web write: status %d, Retry-After %q", rec.Code, rec.Header().Get("Retry-After"))
+	}
+	if !strings.Contains(rec.Body.String(), "temporarily read-only (upgrade)") {
+		t.Errorf("web body = %q", rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("DELETE", "/api/v1/tournaments/1", nil))
+	if rec.Code != http.StatusServiceUnavailable || rec.Header().Get("Content-Type") != "application/json" {
+		t.Errorf("api write: status %d, type %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	if !strings.Contains(rec.Body.String(), `"error"`) {
+		t.Errorf("api body = %q", rec.Body.String())
+	}
+
+	for _, req := range []*http.Request{
+		httptest.NewRequest("POST", "/login", nil),
+		httptest.NewRequest("GET", "/tournaments/1/manage", nil),
+	} {
+		h.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	if calls != 3 {
+		t.Errorf("handler ran %d times, want 3 (exempt POST and GET let through)", calls)
+	}
+}
+
+func TestWrap_ServesLastGoodPage(t *testing.T) {
+	m := newMode()
+	body, status := "round 3 pairings", http.StatusOK
+	h := m.Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+		return rec
+	}
+
+	get("/tournaments/1")
+	get("/dashboard") // not a cached path
+
+	body, status = "db down", http.StatusInternalServerError
+	// Writable: failures go through as they are.
+	if rec := get("/tournaments/1"); rec.Code != http.StatusInternalServerError {
+		t.Errorf("writable failure: status %d", rec.Code)
+	}
+
+	m.trip(errors.New("connection refused"))
+	rec := get("/tournaments/1")
+	if rec.Code != http.StatusOK || rec.Body.String() != "round 3 pairings" || rec.Header().Get(StaleHeader) == "" {
+		t.Errorf("read-only failure: status %d body %q stale %q", rec.Code, rec.Body.String(), rec.Header().Get(StaleHeader))
+	}
+	if rec := get("/dashboard"); rec.Code != http.StatusInternalServerError {
+		t.Errorf("uncached path: status %d", rec.Code)
+	}
+	if rec := get("/tournaments/2"); rec.Code != http.StatusInternalServerError {
+		t.Errorf("never-cached page: status %d", rec.Code)
+	}
+
+	// A page that still renders is served live.
+	body, status = "round 3 results", http.StatusOK
+	if rec := get("/tournaments/1"); rec.Body.String() != "round 3 results" || rec.Header().Get(StaleHeader) != "" {
+		t.Errorf("live page: body %q stale %q", rec.Body.String(), rec.Header().Get(StaleHeader))
+	}
+}
+
+func TestWrap_CachesPerLocale(t *testing.T) {
+	m := newMode()
+	status := http.StatusOK
+	h := middleware.Localize("")(m.Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		w.Write([]byte("page in " + middleware.Locale(r)))
+	})))
+	get := func(lang string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/tournaments/1", nil)
+		req.Header.Set("Accept-Language", lang)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	get("de")
+	status = http.StatusInternalServerError
+	m.trip(errors.New("connection refused"))
+	if rec := get("de"); rec.Body.String() != "page in de" {
+		t.Errorf("German copy: status %d body %q", rec.Code, rec.Body.String())
+	}
+	if rec := get("en"); rec.Code != http.StatusInternalServerError {
+		t.Errorf("English page served from the German copy: %q", rec.Body.String())
+	}
+}
+
+func TestPageCache_Bounded(t *testing.T) {
+	c := newPageCache(2)
+	c.put("a", "", nil)
+	c.put("b", "", nil)
+	c.put("a", "", []byte("again"))
+	c.put("c", "", nil)
+	if _, ok := c.get("a"); ok {
+		t.Error("oldest page kept past the limit")
+	}
+	for _, k := range []string{"b", "c"} {
+		if _, ok := c.get(k); !ok {
+			t.Errorf("page %q dropped", k)
+		}
+	}
+}
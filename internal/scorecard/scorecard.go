@@ -0,0 +1,125 @@
+// Package scorecard renders printable per-player scorecards as PDF: one A4
+// page per player with their name and an empty results grid, for events
+// where players also track their results on paper.
+package scorecard
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/dstathis/openswiss/internal/pairing"
+	"github.com/dstathis/openswiss/internal/pdf"
+)
+
+// MaxRounds is the most rows a card has room for.
+const MaxRounds = 20
+
+// Card is one player's scorecard.
+type Card struct {
+	Tournament string
+	// Details is the line under the title: date, location, scoring.
+	Details string
+	Player  string
+	Rounds  int
+}
+
+// Rounds picks how many rows a card gets: the tournament's round count when
+// it has one, otherwise the recommended Swiss rounds for the field (see
+// pairing.SwissRounds), at least 3.
+func Rounds(numRounds *int, players int) int {
+	n := 3
+	if numRounds != nil && *numRounds > 0 {
+		n = *numRounds
+	} else {
+		n = max(n, pairing.SwissRounds(players))
+	}
+	return min(n, MaxRounds)
+}
+
+// NewCard builds the card for player in tournament t, with players the
+// number of confirmed players (used when t has no round count).
+func NewCard(t *models.Tournament, player string, players int) Card {
+	var details []string
+	if t.ScheduledAt != nil {
+		details = append(details, t.ScheduledAt.Format("Jan 2, 2006 3:04 PM"))
+	}
+	if t.Location != nil && *t.Location != "" {
+		details = append(details, *t.Location)
+	}
+	details = append(details, fmt.Sprintf("Win %d / Draw %d / Loss %d", t.PointsWin, t.PointsDraw, t.PointsLoss))
+	return Card{
+		Tournament: t.Name,
+		Details:    strings.Join(details, "  |  "),
+		Player:     player,
+		Rounds:     Rounds(t.NumRounds, players),
+	}
+}
+
+// Grid columns: heading and width in points. They span the page between the
+// margins.
+var columns = []struct {
+	heading string
+	width   float64
+}{
+	{"Round", 45}, {"Table", 45}, {"Opponent", 175}, {"W", 35}, {"L", 35}, {"D", 35},
+	{"Points", 55}, {"Opp. initials", 70},
+}
+
+const (
+	margin    = 50
+	rowHeight = 28
+)
+
+// Render writes the cards as a PDF, one page each.
+func Render(w io.Writer, cards []Card) error {
+	pages := make([]*pdf.Page, 0, len(cards))
+	for _, c := range cards {
+		pages = append(pages, renderCard(c))
+	}
+	if len(pages) == 0 {
+		p := &pdf.Page{}
+		p.Text(margin, pdf.PageHeight-margin-18, 12, false, "No players to print scorecards for.")
+		pages = append(pages, p)
+	}
+	return pdf.Write(w, pages)
+}
+
+func renderCard(c Card) *pdf.Page {
+	p := &pdf.Page{}
+	y := float64(pdf.PageHeight - margin - 18)
+	p.Text(margin, y, 18, true, c.Tournament)
+	y -= 18
+	p.Text(margin, y, 10, false, c.Details)
+	y -= 30
+	p.Text(margin, y, 14, true, "Player: "+c.Player)
+	y -= 30
+
+	right := float64(pdf.PageWidth - margin)
+	top := y
+	x := float64(margin)
+	for _, col := range columns {
+		p.Text(x+4, y-15, 10, true, col.heading)
+		x += col.width
+	}
+	rows := max(1, min(c.Rounds, MaxRounds))
+	bottom := top - float64(rowHeight*(rows+1))
+	for i := 0; i <= rows+1; i++ {
+		ly := top - float64(rowHeight*i)
+		p.Line(margin, ly, right, ly)
+		if i > 0 && i <= rows {
+			p.Text(margin+4, ly-18, 11, false, fmt.Sprint(i))
+		}
+	}
+	x = margin
+	p.Line(x, top, x, bottom)
+	for _, col := range columns {
+		x += col.width
+		p.Line(x, top, x, bottom)
+	}
+
+	p.Text(margin, bottom-24, 9, false,
+		"Fill in each result with your opponent and check it against the posted pairings and standings.")
+	return p
+}
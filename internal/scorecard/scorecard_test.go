@@ -0,0 +1,100 @@
+package scorecard
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+func TestRounds(t *testing.T) {
+	five, zero, many := 5, 0, 40
+	tests := []struct {
+		numRounds *int
+		players   int
+		want      int
+	}{
+		{nil, 0, 3},
+		{nil, 8, 3},
+		{nil, 9, 4},
+		{nil, 33, 6},
+		{&five, 100, 5},
+		{&zero, 16, 4},
+		{&many, 8, MaxRounds},
+	}
+	for _, tt := range tests {
+		if got := Rounds(tt.numRounds, tt.players); got != tt.want {
+			t.Errorf("Rounds(%v, %d) = %d, want %d", tt.numRounds, tt.players, got, tt.want)
+		}
+	}
+}
+
+func TestNewCard(t *testing.T) {
+	when := time.Date(2026, 3, 14, 10, 0, 0, 0, time.UTC)
+	loc := "Game Haven"
+	tm := &models.Tournament{Name: "Spring Open", ScheduledAt: &when, Location: &loc, PointsWin: 3, PointsDraw: 1}
+	c := NewCard(tm, "Alice", 12)
+	if c.Rounds != 4 || c.Player != "Alice" {
+		t.Errorf("card = %+v", c)
+	}
+	if c.Details != "Mar 14, 2026 10:00 AM  |  Game Haven  |  Win 3 / Draw 1 / Loss 0" {
+		t.Errorf("details = %q", c.Details)
+	}
+}
+
+var xrefEntry = regexp.MustCompile(`(\d{10}) 00000 n `)
+
+func TestRender(t *testing.T) {
+	var buf bytes.Buffer
+	cards := []Card{
+		{Tournament: "Spring (Open)", Details: "x", Player: "Alice", Rounds: 3},
+		{Tournament: "Spring (Open)", Details: "x", Player: "Zoë 李", Rounds: 5},
+	}
+	if err := Render(&buf, cards); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.Bytes()
+	if !bytes.HasPrefix(out, []byte("%PDF-1.4\n")) || !bytes.HasSuffix(out, []byte("%%EOF\n")) {
+		t.Fatal("not a PDF")
+	}
+	if n := bytes.Count(out, []byte("/Type /Page ")); n != 2 {
+		t.Errorf("%d pages, want 2", n)
+	}
+	for _, want := range []string{`(Spring \(Open\))`, "(Player: Alice)", "(Player: Zo\xeb ?)", "(5)"} {
+		if !bytes.Contains(out, []byte(want)) {
+			t.Errorf("PDF lacks %q", want)
+		}
+	}
+
+	// Every xref offset must point at the start of its object.
+	entries := xrefEntry.FindAllSubmatch(out, -1)
+	if len(entries) != 8 {
+		t.Fatalf("%d xref entries, want 8", len(entries))
+	}
+	for i, e := range entries {
+		off, _ := strconv.Atoi(string(e[1]))
+		if !bytes.HasPrefix(out[off:], []byte(fmt.Sprintf("%d 0 obj", i+1))) {
+			t.Errorf("xref entry %d points at %q", i+1, out[off:off+10])
+		}
+	}
+	start := bytes.LastIndex(out, []byte("startxref\n"))
+	off, _ := strconv.Atoi(strings.Fields(string(out[start+10:]))[0])
+	if !bytes.HasPrefix(out[off:], []byte("xref\n")) {
+		t.Error("startxref does not point at the xref table")
+	}
+}
+
+func TestRender_NoCards(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Render(&buf, nil); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte("No players")) {
+		t.Error("empty render lacks its notice")
+	}
+}
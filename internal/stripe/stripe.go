@@ -0,0 +1,232 @@
+// Package stripe takes entry fees online through Stripe Checkout: it opens
+// a hosted payment page for one player's fee and reads the signed webhook
+// events Stripe sends once the payment has gone through. It calls Stripe's
+// REST API directly rather than through Stripe's SDK.
+package stripe
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultBaseURL is Stripe's API.
+const DefaultBaseURL = "https://api.stripe.com"
+
+// SignatureTolerance is how old a webhook event's signature may be before
+// it is refused as a possible replay.
+const SignatureTolerance = 5 * time.Minute
+
+// Config holds the Stripe account settings.
+type Config struct {
+	SecretKey     string
+	WebhookSecret string
+	// Currency is the ISO 4217 code, in lower case, every fee is charged
+	// in.
+	Currency string
+}
+
+// Enabled returns true if Stripe is configured.
+func (c *Config) Enabled() bool {
+	return c.SecretKey != "" && c.WebhookSecret != ""
+}
+
+// ValidCurrency reports whether code looks like an ISO 4217 currency code
+// as Stripe takes it: three lower-case letters.
+func ValidCurrency(code string) bool {
+	if len(code) != 3 {
+		return false
+	}
+	for _, c := range code {
+		if c < 'a' || c > 'z' {
+			return false
+		}
+	}
+	return true
+}
+
+// Client talks to the Stripe API with one account's secret key.
+type Client struct {
+	Config  Config
+	BaseURL string
+	HTTP    *http.Client
+}
+
+// New returns a client for cfg against baseURL, or DefaultBaseURL when it
+// is empty.
+func New(cfg Config, baseURL string) *Client {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	return &Client{Config: cfg, BaseURL: strings.TrimRight(baseURL, "/"), HTTP: &http.Client{Timeout: 15 * time.Second}}
+}
+
+// Checkout is one player's entry fee to collect.
+type Checkout struct {
+	TournamentID   int64
+	RegistrationID int64
+	// Amount is in minor units of the configured currency.
+	Amount int
+	// Description names what is paid for on Stripe's page.
+	Description string
+	Email       string
+	SuccessURL  string
+	CancelURL   string
+}
+
+// Session is a created Checkout session. URL is the payment page to send
+// the player to.
+type Session struct {
+	ID  string `json:"id"`
+	URL string `json:"url"`
+}
+
+// CreateCheckout opens a Checkout session for c. The tournament and
+// registration go in its metadata, to come back in the webhook event.
+func (cl *Client) CreateCheckout(ctx context.Context, c Checkout) (*Session, error) {
+	form := url.Values{
+		"mode":                                          {"payment"},
+		"success_url":                                   {c.SuccessURL},
+		"cancel_url":                                    {c.CancelURL},
+		"client_reference_id":                           {strconv.FormatInt(c.RegistrationID, 10)},
+		"metadata[tournament]":                          {strconv.FormatInt(c.TournamentID, 10)},
+		"metadata[registration]":                        {strconv.FormatInt(c.RegistrationID, 10)},
+		"line_items[0][quantity]":                       {"1"},
+		"line_items[0][price_data][currency]":           {cl.Config.Currency},
+		"line_items[0][price_data][unit_amount]":        {strconv.Itoa(c.Amount)},
+		"line_items[0][price_data][product_data][name]": {c.Description},
+	}
+	if c.Email != "" {
+		form.Set("customer_email", c.Email)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, cl.BaseURL+"/v1/checkout/sessions", strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.SetBasicAuth(cl.Config.SecretKey, "")
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := cl.HTTP.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("stripe: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		var e struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if json.NewDecoder(resp.Body).Decode(&e) == nil && e.Error.Message != "" {
+			return nil, fmt.Errorf("stripe: %s (%s)", e.Error.Message, resp.Status)
+		}
+		return nil, fmt.Errorf("stripe: %s", resp.Status)
+	}
+	var s Session
+	if err := json.NewDecoder(resp.Body).Decode(&s); err != nil {
+		return nil, fmt.Errorf("stripe: decode session: %w", err)
+	}
+	if s.URL == "" {
+		return nil, errors.New("stripe: session has no payment page")
+	}
+	return &s, nil
+}
+
+// ErrBadSignature is returned for a webhook event whose Stripe-Signature
+// header is missing, wrong or too old.
+var ErrBadSignature = errors.New("stripe: bad webhook signature")
+
+// VerifySignature checks the Stripe-Signature header of a webhook event's
+// payload: an HMAC-SHA256 of "t.payload" under secret, signed at time t
+// within SignatureTolerance of now. Any of several v1 signatures may match,
+// as while the secret is being rolled.
+func VerifySignature(payload []byte, header, secret string, now time.Time) error {
+	var ts int64
+	var sigs [][]byte
+	for _, part := range strings.Split(header, ",") {
+		k, v, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch k {
+		case "t":
+			ts, _ = strconv.ParseInt(v, 10, 64)
+		case "v1":
+			if b, err := hex.DecodeString(v); err == nil {
+				sigs = append(sigs, b)
+			}
+		}
+	}
+	if ts == 0 || len(sigs) == 0 {
+		return ErrBadSignature
+	}
+	if d := now.Sub(time.Unix(ts, 0)); d > SignatureTolerance || d < -SignatureTolerance {
+		return ErrBadSignature
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", ts)
+	mac.Write(payload)
+	want := mac.Sum(nil)
+	for _, s := range sigs {
+		if hmac.Equal(s, want) {
+			return nil
+		}
+	}
+	return ErrBadSignature
+}
+
+// Event types that complete a Checkout session. An asynchronous method
+// such as a bank debit completes the session unpaid and succeeds later.
+const (
+	EventCheckoutCompleted     = "checkout.session.completed"
+	EventAsyncPaymentSucceeded = "checkout.session.async_payment_succeeded"
+)
+
+// Payment is a paid entry fee read from a webhook event.
+type Payment struct {
+	TournamentID   int64
+	RegistrationID int64
+	Amount         int
+	Currency       string
+}
+
+type event struct {
+	Type string `json:"type"`
+	Data struct {
+		Object struct {
+			PaymentStatus string            `json:"payment_status"`
+			AmountTotal   int               `json:"amount_total"`
+			Currency      string            `json:"currency"`
+			Metadata      map[string]string `json:"metadata"`
+		} `json:"object"`
+	} `json:"data"`
+}
+
+// ParsePayment reads a verified webhook event. It returns the payment if
+// the event says an entry fee opened by CreateCheckout has been paid, and
+// nil for any other event, which needs nothing done.
+func ParsePayment(payload []byte) (*Payment, error) {
+	var e event
+	if err := json.Unmarshal(payload, &e); err != nil {
+		return nil, fmt.Errorf("stripe: decode event: %w", err)
+	}
+	if e.Type != EventCheckoutCompleted && e.Type != EventAsyncPaymentSucceeded {
+		return nil, nil
+	}
+	o := e.Data.Object
+	if o.PaymentStatus != "paid" {
+		return nil, nil
+	}
+	tid, err1 := strconv.ParseInt(o.Metadata["tournament"], 10, 64)
+	rid, err2 := strconv.ParseInt(o.Metadata["registration"], 10, 64)
+	if err1 != nil || err2 != nil {
+		// A payment for something else on the same account.
+		return nil, nil
+	}
+	return &Payment{TournamentID: tid, RegistrationID: rid, Amount: o.AmountTotal, Currency: o.Currency}, nil
+}
@@ -0,0 +1,136 @@
+package stripe
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCreateCheckout(t *testing.T) {
+	var form map[string]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, _, _ := r.BasicAuth(); user != "sk_test" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"error": {"message": "Invalid API Key provided"}}`)
+			return
+		}
+		if r.Method != "POST" || r.URL.Path != "/v1/checkout/sessions" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		r.ParseForm()
+		form = map[string]string{}
+		for k := range r.PostForm {
+			form[k] = r.PostForm.Get(k)
+		}
+		fmt.Fprint(w, `{"id": "cs_1", "url": "https://checkout.stripe.com/c/cs_1"}`)
+	}))
+	defer srv.Close()
+
+	c := New(Config{SecretKey: "sk_test", Currency: "eur"}, srv.URL)
+	s, err := c.CreateCheckout(context.Background(), Checkout{
+		TournamentID: 3, RegistrationID: 9, Amount: 1250, Description: "Friday Modern entry fee",
+		Email: "ann@example.com", SuccessURL: "http://x/tournaments/3?paid=1", CancelURL: "http://x/tournaments/3",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.ID != "cs_1" || s.URL != "https://checkout.stripe.com/c/cs_1" {
+		t.Errorf("session = %+v", s)
+	}
+	for k, want := range map[string]string{
+		"mode":                                   "payment",
+		"metadata[tournament]":                   "3",
+		"metadata[registration]":                 "9",
+		"line_items[0][price_data][currency]":    "eur",
+		"line_items[0][price_data][unit_amount]": "1250",
+		"customer_email":                         "ann@example.com",
+		"success_url":                            "http://x/tournaments/3?paid=1",
+	} {
+		if form[k] != want {
+			t.Errorf("%s = %q, want %q", k, form[k], want)
+		}
+	}
+
+	c.Config.SecretKey = "wrong"
+	if _, err := c.CreateCheckout(context.Background(), Checkout{Amount: 1}); err == nil || !strings.Contains(err.Error(), "Invalid API Key") {
+		t.Errorf("wrong key: err = %v, want Stripe's message", err)
+	}
+}
+
+func sign(payload, secret string, ts int64) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.%s", ts, payload)
+	return fmt.Sprintf("t=%d,v1=%s", ts, hex.EncodeToString(mac.Sum(nil)))
+}
+
+func TestVerifySignature(t *testing.T) {
+	payload := `{"type": "checkout.session.completed"}`
+	now := time.Unix(1_700_000_000, 0)
+	good := sign(payload, "whsec", now.Unix())
+	if err := VerifySignature([]byte(payload), good, "whsec", now); err != nil {
+		t.Errorf("good signature: %v", err)
+	}
+	// A second signature, as while the secret is rolled, also counts.
+	if err := VerifySignature([]byte(payload), good+",v1=00ff", "whsec", now); err != nil {
+		t.Errorf("with an old signature too: %v", err)
+	}
+	for name, c := range map[string]struct {
+		payload, header string
+		at              time.Time
+	}{
+		"tampered":   {payload + " ", good, now},
+		"other key":  {payload, sign(payload, "other", now.Unix()), now},
+		"too old":    {payload, good, now.Add(SignatureTolerance + time.Second)},
+		"no header":  {payload, "", now},
+		"only t":     {payload, fmt.Sprintf("t=%d", now.Unix()), now},
+		"v0 instead": {payload, strings.Replace(good, "v1=", "v0=", 1), now},
+	} {
+		if err := VerifySignature([]byte(c.payload), c.header, "whsec", c.at); err != ErrBadSignature {
+			t.Errorf("%s: err = %v, want ErrBadSignature", name, err)
+		}
+	}
+}
+
+func TestParsePayment(t *testing.T) {
+	event := func(typ, status, metadata string) []byte {
+		return []byte(fmt.Sprintf(`{"type": %q, "data": {"object": {"payment_status": %q, "amount_total": 1250, "currency": "eur", "metadata": %s}}}`, typ, status, metadata))
+	}
+	ours := `{"tournament": "3", "registration": "9"}`
+
+	p, err := ParsePayment(event(EventCheckoutCompleted, "paid", ours))
+	if err != nil || p == nil || *p != (Payment{TournamentID: 3, RegistrationID: 9, Amount: 1250, Currency: "eur"}) {
+		t.Errorf("completed: %+v, %v", p, err)
+	}
+	if p, err := ParsePayment(event(EventAsyncPaymentSucceeded, "paid", ours)); err != nil || p == nil {
+		t.Errorf("async success: %+v, %v", p, err)
+	}
+	for name, payload := range map[string][]byte{
+		"unpaid":       event(EventCheckoutCompleted, "unpaid", ours),
+		"other event":  event("charge.refunded", "paid", ours),
+		"not ours":     event(EventCheckoutCompleted, "paid", `{}`),
+		"bad metadata": event(EventCheckoutCompleted, "paid", `{"tournament": "x", "registration": "9"}`),
+	} {
+		if p, err := ParsePayment(payload); err != nil || p != nil {
+			t.Errorf("%s: %+v, %v; want nothing to do", name, p, err)
+		}
+	}
+	if _, err := ParsePayment([]byte("{")); err == nil {
+		t.Error("bad JSON accepted")
+	}
+}
+
+func TestValidCurrency(t *testing.T) {
+	for code, want := range map[string]bool{"usd": true, "eur": true, "USD": false, "us": false, "euro": false, "": false} {
+		if ValidCurrency(code) != want {
+			t.Errorf("ValidCurrency(%q) = %v", code, !want)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/dstathis/openswiss/internal/db"
+)
+
+// retryDelays is how long to wait before each retry of a failed delivery.
+// A delivery is given up on after its last retry fails.
+var retryDelays = []time.Duration{30 * time.Second, 2 * time.Minute, 10 * time.Minute, time.Hour}
+
+// lease is how long a claimed delivery is left alone before another worker
+// may retry it. It covers the client timeout with room to spare.
+const lease = time.Minute
+
+const batchSize = 20
+
+// RetryAt returns when to retry a delivery whose attempt-th attempt just
+// failed, or nil when it should be given up on.
+func RetryAt(attempt int, now time.Time) *time.Time {
+	if attempt < 1 || attempt > len(retryDelays) {
+		return nil
+	}
+	at := now.Add(retryDelays[attempt-1])
+	return &at
+}
+
+// NewClient returns the HTTP client deliveries are sent with. It has short
+// timeouts and doesn't follow redirects. Unless allowPrivate is set it
+// refuses to connect to loopback, private, link-local and other non-public
+// addresses, so a webhook can't be pointed at the server's own network.
+func NewClient(allowPrivate bool) *http.Client {
+	dialer := &net.Dialer{Timeout: 5 * time.Second}
+	if !allowPrivate {
+		dialer.Control = refuseNonPublic
+	}
+	return &http.Client{
+		Timeout: 10 * time.Second,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: 5 * time.Second,
+			MaxIdleConns:        10,
+			IdleConnTimeout:     30 * time.Second,
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// refuseNonPublic is a net.Dialer Control hook. It runs after name
+// resolution, on the address actually being dialled.
+func refuseNonPublic(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+	if !publicAddr(ip) {
+		return fmt.Errorf("refusing to connect to non-public address %s", ip)
+	}
+	return nil
+}
+
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+func publicAddr(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	return ip.IsGlobalUnicast() && !ip.IsPrivate() && !sharedAddressSpace.Contains(ip)
+}
+
+// Deliver POSTs one payload to url. Any response other than 2xx is an error.
+func Deliver(ctx context.Context, client *http.Client, url, secret, event string, deliveryID int64, payload []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "OpenSwiss-Webhook")
+	req.Header.Set(HeaderEvent, event)
+	req.Header.Set(HeaderDelivery, strconv.FormatInt(deliveryID, 10))
+	req.Header.Set(HeaderSignature, Sign(secret, payload))
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("endpoint returned %s", resp.Status)
+	}
+	return nil
+}
+
+// Dispatcher sends queued deliveries. Several may run against the same
+// database; claimed deliveries are locked so each goes out once per attempt.
+type Dispatcher struct {
+	DB       *sql.DB
+	Client   *http.Client
+	Interval time.Duration
+}
+
+// Run polls for due deliveries every Interval until ctx is cancelled.
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.Interval)
+	defer ticker.Stop()
+	for {
+		if err := d.Flush(ctx); err != nil && ctx.Err() == nil {
+			slog.ErrorContext(ctx, "webhook dispatch", "err", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Flush sends every delivery that is due now.
+func (d *Dispatcher) Flush(ctx context.Context) error {
+	for {
+		batch, err := db.ClaimWebhookDeliveries(ctx, d.DB, batchSize, lease)
+		if err != nil {
+			return err
+		}
+		for _, p := range batch {
+			d.send(ctx, p)
+		}
+		if len(batch) < batchSize {
+			return nil
+		}
+	}
+}
+
+func (d *Dispatcher) send(ctx context.Context, p db.PendingWebhookDelivery) {
+	err := Deliver(ctx, d.Client, p.URL, p.Secret, p.Event, p.ID, p.Payload)
+	if err == nil {
+		if err := db.MarkWebhookDelivered(ctx, d.DB, p.ID); err != nil {
+			slog.ErrorContext(ctx, "webhook: record delivery", "delivery_id", p.ID, "err", err)
+		}
+		return
+	}
+	retry := RetryAt(p.Attempts, time.Now())
+	slog.WarnContext(ctx, "webhook delivery failed", "delivery_id", p.ID, "webhook_id", p.WebhookID,
+		"attempt", p.Attempts, "giving_up", retry == nil, "err", err)
+	if err := db.FailWebhookDelivery(ctx, d.DB, p.ID, err.Error(), retry); err != nil {
+		slog.ErrorContext(ctx, "webhook: record failure", "delivery_id", p.ID, "err", err)
+	}
+}
@@ -0,0 +1,138 @@
+// Package webhook builds, signs and delivers the JSON payloads POSTed to
+// tournament webhooks. Events are queued in the database by the engine (see
+// engine.WithTournamentEngine); the Dispatcher here sends them.
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"time"
+)
+
+// Headers sent with every delivery.
+const (
+	HeaderEvent     = "X-OpenSwiss-Event"
+	HeaderDelivery  = "X-OpenSwiss-Delivery"
+	HeaderSignature = "X-OpenSwiss-Signature"
+)
+
+// Stages a round can belong to.
+const (
+	StageSwiss   = "swiss"
+	StagePlayoff = "playoff"
+)
+
+// MaxURLLength is the longest webhook URL accepted.
+const MaxURLLength = 2048
+
+// Envelope is the body of every delivery.
+type Envelope struct {
+	Event      string      `json:"event"`
+	Tournament Tournament  `json:"tournament"`
+	OccurredAt time.Time   `json:"occurred_at"`
+	Data       interface{} `json:"data"`
+}
+
+// Tournament identifies the tournament an event happened in.
+type Tournament struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+// Pairing is one match of a round, shaped like the pairings of the rounds
+// API.
+type Pairing struct {
+	Table       int    `json:"table"`
+	PlayerA     int    `json:"player_a"`
+	PlayerB     int    `json:"player_b"`
+	PlayerAName string `json:"player_a_name"`
+	PlayerBName string `json:"player_b_name"`
+	IsBye       bool   `json:"is_bye"`
+}
+
+// RoundPaired is the data of a round.paired event. It is sent for every new
+// round and again when a round is re-paired.
+type RoundPaired struct {
+	Stage    string    `json:"stage"`
+	Round    int       `json:"round"`
+	Pairings []Pairing `json:"pairings"`
+}
+
+// ResultEntered is the data of a result.entered event, sent once per match
+// whose result was set or changed.
+type ResultEntered struct {
+	Stage string `json:"stage"`
+	Round int    `json:"round"`
+	Pairing
+	PlayerAWins int `json:"player_a_wins"`
+	PlayerBWins int `json:"player_b_wins"`
+	Draws       int `json:"draws"`
+}
+
+// Standing is one row of the final standings.
+type Standing struct {
+	Rank     int    `json:"rank"`
+	PlayerID int    `json:"player_id"`
+	Name     string `json:"name"`
+	Points   int    `json:"points"`
+	Wins     int    `json:"wins"`
+	Losses   int    `json:"losses"`
+	Draws    int    `json:"draws"`
+}
+
+// TournamentFinished is the data of a tournament.finished event. With a top
+// cut it is sent twice: when the Swiss rounds end (stage "swiss") and again
+// when the playoff does (stage "playoff"). Standings are always the Swiss
+// standings.
+type TournamentFinished struct {
+	Stage     string     `json:"stage"`
+	Standings []Standing `json:"standings"`
+}
+
+// ResultsOutstanding is the data of a results.outstanding event, sent when
+// the tables of a Swiss round still without a result are reminded to
+// report: shortly before the round clock runs out, or when staff ask.
+// EndsAt is when the clock runs out, if one is set.
+type ResultsOutstanding struct {
+	Stage    string     `json:"stage"`
+	Round    int        `json:"round"`
+	EndsAt   *time.Time `json:"ends_at,omitempty"`
+	Pairings []Pairing  `json:"pairings"`
+}
+
+// AnnouncementPosted is the data of an announcement.posted event, sent when
+// staff post an announcement. ExpiresAt is when it stops being shown, if it
+// was given a time limit.
+type AnnouncementPosted struct {
+	ID        int64      `json:"id"`
+	Message   string     `json:"message"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// Sign returns the signature header value for body: "sha256=" followed by
+// the hex HMAC-SHA256 of body keyed with secret.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// ValidateURL checks that raw is an absolute http or https URL that can be
+// used as a webhook. Whether its host is reachable is only known at
+// delivery.
+func ValidateURL(raw string) error {
+	if len(raw) > MaxURLLength {
+		return errors.New("URL is too long")
+	}
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return errors.New("URL must be an absolute http or https URL")
+	}
+	if u.User != nil {
+		return errors.New("URL must not contain credentials")
+	}
+	return nil
+}
@@ -8,6 +8,8 @@ import (
 	"time"
 
 	_ "github.com/lib/pq"
+	// Tournament time zones must resolve in images without zoneinfo.
+	_ "time/tzdata"
 
 	mw "github.com/dstathis/openswiss/internal/middleware"
 )
@@ -32,6 +34,12 @@ func main() {
 		runServe(args)
 	case "migrate":
 		runMigrate(args)
+	case "hash-password":
+		runHashPassword(args)
+	case "simulate":
+		runSimulate(args)
+	case "players", "result", "export":
+		runManage(cmd, args)
 	case "-h", "--help", "help":
 		printUsage(os.Stdout)
 	default:
@@ -45,29 +53,23 @@ func printUsage(w *os.File) {
 	fmt.Fprintf(w, `openswiss — Swiss-system tournament server
 
 Usage:
-  openswiss serve     Run the HTTP server (default)
-  openswiss migrate   Apply database migrations and exit
-  openswiss help      Show this message
+  openswiss serve          Run the HTTP server (default)
+  openswiss migrate        Apply database migrations and exit
+  openswiss hash-password  Print the bcrypt hash of a password read from stdin
+  openswiss simulate       Play a made-up tournament with random results
+  openswiss players add    Add a player to a tournament
+  openswiss result set     Enter or correct the result at a table
+  openswiss export standings
+                           Print a tournament's standings as CSV or JSON
+  openswiss help           Show this message
 
-Configuration is via environment variables. See README.md.
+Every subcommand but hash-password reads its configuration from a TOML
+file (-config or OPENSWISS_CONFIG), then environment variables, then flags,
+each overriding the last. Run "openswiss serve -h" to list the settings, and
+"openswiss <subcommand> -h" for a subcommand's own flags; see README.md.
 `)
 }
 
-func getenv(key, fallback string) string {
-	if v := os.Getenv(key); v != "" {
-		return v
-	}
-	return fallback
-}
-
-func mustEnv(key string) string {
-	v := os.Getenv(key)
-	if v == "" {
-		fatal("missing required env var", "name", key)
-	}
-	return v
-}
-
 // fatal logs a structured error and exits 1. Use only at startup; a running
 // process should never abort the whole server for one bad request.
 func fatal(msg string, args ...any) {
@@ -0,0 +1,266 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/engine"
+	"github.com/dstathis/openswiss/internal/export"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/dstathis/swisstools"
+)
+
+// A manageCommand is a "openswiss <noun> <verb>" subcommand that works on a
+// tournament straight in the database, for scripting and fixing things from
+// a terminal. It defines its flags on fs and returns what runs once they
+// are parsed.
+type manageCommand func(fs *flag.FlagSet) func(ctx context.Context, database *sql.DB, out io.Writer) error
+
+// manageCommands are the management subcommands, by noun and verb.
+var manageCommands = map[string]map[string]manageCommand{
+	"players": {"add": playersAdd},
+	"result":  {"set": resultSet},
+	"export":  {"standings": exportStandings},
+}
+
+// runManage runs the management subcommand noun with the verb that starts
+// args. It acts as nobody in particular: there is no account or tier to
+// check, as anyone who can run it can reach the database anyway.
+func runManage(noun string, args []string) {
+	verbs := manageCommands[noun]
+	var command manageCommand
+	if len(args) > 0 {
+		command = verbs[args[0]]
+	}
+	if command == nil {
+		names := make([]string, 0, len(verbs))
+		for verb := range verbs {
+			names = append(names, verb)
+		}
+		sort.Strings(names)
+		fmt.Fprintf(os.Stderr, "usage: openswiss %s %s [flags]\n", noun, strings.Join(names, "|"))
+		os.Exit(2)
+	}
+	var run func(context.Context, *sql.DB, io.Writer) error
+	cfg := mustLoadConfig(noun+" "+args[0], args[1:], func(fs *flag.FlagSet) { run = command(fs) })
+
+	database, err := openDB(cfg.DatabaseURL)
+	if err != nil {
+		fatal("connect db", "err", err)
+	}
+	defer database.Close()
+	if err := run(context.Background(), database, os.Stdout); err != nil {
+		fatal(noun+" "+args[0], "err", err)
+	}
+}
+
+// tournamentFlag defines the -tournament flag every management subcommand
+// takes.
+func tournamentFlag(fs *flag.FlagSet) *int64 {
+	return fs.Int64("tournament", 0, "ID of the tournament (required)")
+}
+
+// getTournament loads tournament id, checking the flag was given.
+func getTournament(ctx context.Context, database *sql.DB, id int64) (*models.Tournament, error) {
+	if id <= 0 {
+		return nil, errors.New("-tournament is required")
+	}
+	t, err := db.GetTournament(ctx, database, id)
+	if err != nil {
+		return nil, fmt.Errorf("tournament %d: %w", id, err)
+	}
+	return t, nil
+}
+
+// playersAdd adds a guest player, as organizers do from the players page:
+// before the start a registration only, once under way a late entry seated
+// in the engine too. A team event takes the roster with -members. A name
+// the tournament already has is refused without -allow-duplicate.
+func playersAdd(fs *flag.FlagSet) func(context.Context, *sql.DB, io.Writer) error {
+	id := tournamentFlag(fs)
+	name := fs.String("name", "", "the player's name (required)")
+	members := fs.String("members", "", "comma-separated roster, in a team event")
+	allowDuplicate := fs.Bool("allow-duplicate", false, "add the player even if the tournament already has the name")
+	return func(ctx context.Context, database *sql.DB, out io.Writer) error {
+		t, err := getTournament(ctx, database, *id)
+		if err != nil {
+			return err
+		}
+		if strings.TrimSpace(*name) == "" {
+			return errors.New("-name is required")
+		}
+		playerName, err := models.ValidatePlayerName(*name)
+		if err != nil {
+			return err
+		}
+		switch t.Status {
+		case models.TournamentStatusScheduled, models.TournamentStatusRegistrationOpen, models.TournamentStatusInProgress:
+		default:
+			return fmt.Errorf("can't add players to a tournament that is %s", t.Status)
+		}
+		if t.Status == models.TournamentStatusInProgress && t.PairingAlgorithm == models.PairingRoundRobin {
+			return errors.New("a round robin can't take new players once it has started")
+		}
+		var roster []string
+		if t.TeamSize > 0 {
+			if roster, err = engine.CheckMembers(t, engine.ParseMembers(*members)); err != nil {
+				return err
+			}
+		}
+
+		if !*allowDuplicate {
+			if err := engine.CheckNewName(ctx, database, t.ID, playerName); err != nil {
+				return err
+			}
+		}
+
+		reg, err := db.CreateGuestRegistration(ctx, database, t.ID, playerName)
+		if err != nil {
+			return err
+		}
+		if roster != nil {
+			if err := db.SetTeamMembers(ctx, database, t.ID, reg.ID, roster); err != nil {
+				return err
+			}
+		}
+		if t.Status == models.TournamentStatusInProgress {
+			err := engine.WithTournamentEngine(ctx, database, t.ID,
+				func(tx *sql.Tx, _ *models.Tournament, eng *swisstools.Tournament) (string, error) {
+					if err := eng.AddPlayer(reg.DisplayName); err != nil {
+						return "", err
+					}
+					playerID, ok := eng.GetPlayerID(reg.DisplayName)
+					if !ok {
+						return "", fmt.Errorf("player %s not found after adding", reg.DisplayName)
+					}
+					return "", db.UpdateRegistrationEnginePlayerID(ctx, tx, reg.ID, playerID)
+				})
+			if err != nil {
+				return err
+			}
+		}
+		fmt.Fprintf(out, "registration %d: %s\n", reg.ID, reg.DisplayName)
+		return nil
+	}
+}
+
+// resultSet enters the result at a table of the current round, or with
+// -round corrects one of a closed round (see engine.CorrectResult). Scores
+// are from player A's side.
+func resultSet(fs *flag.FlagSet) func(context.Context, *sql.DB, io.Writer) error {
+	id := tournamentFlag(fs)
+	round := fs.Int("round", 0, "round of the match; 0 for the current round")
+	table := fs.Int("table", 0, "table of the match (required)")
+	wins := fs.Int("wins", 0, "games won by player A")
+	losses := fs.Int("losses", 0, "games won by player B")
+	draws := fs.Int("draws", 0, "games drawn")
+	return func(ctx context.Context, database *sql.DB, out io.Writer) error {
+		if _, err := getTournament(ctx, database, *id); err != nil {
+			return err
+		}
+		if *table < 1 {
+			return errors.New("-table is required")
+		}
+		var names [2]string
+		err := engine.WithTournamentEngine(ctx, database, *id,
+			func(tx *sql.Tx, t *models.Tournament, eng *swisstools.Tournament) (string, error) {
+				current := *round == 0 || *round == eng.GetCurrentRound()
+				if current && (t.SeriesMode || t.TeamSize > 0) {
+					return "", errors.New("series and team results are entered game by game or seat by seat")
+				}
+				r := *round
+				if r == 0 {
+					r = eng.GetCurrentRound()
+				}
+				pairings, err := eng.GetRoundByNumber(r)
+				if err != nil {
+					return "", err
+				}
+				for i, p := range pairings {
+					if engine.TableNumber(i, p) != *table {
+						continue
+					}
+					for j, pid := range []int{p.PlayerA(), p.PlayerB()} {
+						if player, ok := eng.GetPlayerById(pid); ok {
+							names[j] = player.Name
+						}
+					}
+					if !current {
+						_, err := engine.CorrectResult(ctx, tx, t, eng, r, *table, *wins, *losses, *draws, nil)
+						return "", err
+					}
+					return "", engine.RecordResult(ctx, tx, t, eng, p.PlayerA(), *wins, *losses, *draws)
+				}
+				return "", fmt.Errorf("round %d has no table %d", r, *table)
+			})
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "table %d: %s %d-%d-%d %s\n", *table, names[0], *wins, *losses, *draws, names[1])
+		return nil
+	}
+}
+
+// exportStandings writes the current standings with their tiebreakers, as
+// the standings PDF has them, in CSV or as the API's JSON.
+func exportStandings(fs *flag.FlagSet) func(context.Context, *sql.DB, io.Writer) error {
+	id := tournamentFlag(fs)
+	format := fs.String("format", "csv", "csv or json")
+	return func(ctx context.Context, database *sql.DB, out io.Writer) error {
+		if *format != "csv" && *format != "json" {
+			return errors.New("-format must be csv or json")
+		}
+		t, err := getTournament(ctx, database, *id)
+		if err != nil {
+			return err
+		}
+		standings := []swisstools.PlayerStanding{}
+		if t.EngineState != nil {
+			eng, err := swisstools.LoadTournament(t.EngineState)
+			if err != nil {
+				return err
+			}
+			regs, err := db.ListRegistrations(ctx, database, t.ID)
+			if err != nil {
+				return err
+			}
+			standings = engine.Standings(&eng, t.TiebreakOrder(), engine.TiebreakSeeds(regs))
+		}
+		return writeStandings(out, standings, *format)
+	}
+}
+
+// writeStandings writes standings to out in format, "csv" or "json".
+func writeStandings(out io.Writer, standings []swisstools.PlayerStanding, format string) error {
+	if format == "json" {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(standings)
+	}
+	w := csv.NewWriter(out)
+	w.Write([]string{"Rank", "Player", "Points", "W-L-D", "OMW%", "GW%", "OGW%"})
+	for _, s := range standings {
+		w.Write([]string{
+			strconv.Itoa(s.Rank),
+			export.CSVCell(s.Name),
+			strconv.Itoa(s.Points),
+			fmt.Sprintf("%d-%d-%d", s.Wins, s.Losses, s.Draws),
+			fmt.Sprintf("%.1f", 100*s.Tiebreakers.OpponentMatchWinPct),
+			fmt.Sprintf("%.1f", 100*s.Tiebreakers.GameWinPercentage),
+			fmt.Sprintf("%.1f", 100*s.Tiebreakers.OpponentGameWinPct),
+		})
+	}
+	w.Flush()
+	return w.Error()
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"io"
+	"testing"
+
+	"github.com/dstathis/swisstools"
+)
+
+func TestManageCommandFlags(t *testing.T) {
+	for noun, verbs := range manageCommands {
+		for verb, command := range verbs {
+			_, err := loadConfig(noun+" "+verb, []string{"-tournament", "3"}, env(map[string]string{"DATABASE_URL": "postgres://db"}), io.Discard,
+				func(fs *flag.FlagSet) { command(fs) })
+			if err != nil {
+				t.Errorf("%s %s: %v", noun, verb, err)
+			}
+		}
+	}
+}
+
+func TestWriteStandings(t *testing.T) {
+	standings := []swisstools.PlayerStanding{{
+		Rank: 1, Name: "Smith, Ann", Points: 9, Wins: 3,
+		Tiebreakers: swisstools.TiebreakerData{OpponentMatchWinPct: 0.5, GameWinPercentage: 0.857, OpponentGameWinPct: 0.45},
+	}, {
+		Rank: 2, Name: "=1+1", Points: 0, Losses: 3,
+	}}
+	var buf bytes.Buffer
+	if err := writeStandings(&buf, standings, "csv"); err != nil {
+		t.Fatal(err)
+	}
+	want := "Rank,Player,Points,W-L-D,OMW%,GW%,OGW%\n1,\"Smith, Ann\",9,3-0-0,50.0,85.7,45.0\n2,'=1+1,0,0-3-0,0.0,0.0,0.0\n"
+	if buf.String() != want {
+		t.Errorf("csv = %q, want %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	if err := writeStandings(&buf, standings, "json"); err != nil {
+		t.Fatal(err)
+	}
+	var got []swisstools.PlayerStanding
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil || len(got) != 2 || got[0].Name != "Smith, Ann" {
+		t.Errorf("json = %s (%v)", buf.String(), err)
+	}
+}
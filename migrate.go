@@ -1,11 +1,13 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
 	"log/slog"
 
+	"github.com/dstathis/openswiss/internal/engine"
 	"github.com/golang-migrate/migrate/v4"
 	"github.com/golang-migrate/migrate/v4/database/postgres"
 	"github.com/golang-migrate/migrate/v4/source/iofs"
@@ -14,10 +16,10 @@ import (
 // runMigrate applies all pending migrations and exits. Intended to run as a
 // one-shot pod / compose service before the app boots, so a multi-replica
 // rollout doesn't have N replicas racing on migrate.Up().
-func runMigrate(_ []string) {
-	dsn := mustEnv("DATABASE_URL")
+func runMigrate(args []string) {
+	cfg := mustLoadConfig("migrate", args)
 
-	database, err := openDB(dsn)
+	database, err := openDB(cfg.DatabaseURL)
 	if err != nil {
 		fatal("connect db", "err", err)
 	}
@@ -26,6 +28,10 @@ func runMigrate(_ []string) {
 	if err := applyMigrations(database); err != nil {
 		fatal("apply migrations", "err", err)
 	}
+	// Tournaments finished before the archive existed join it now.
+	if err := engine.ArchiveFinished(context.Background(), database); err != nil {
+		fatal("archive finished tournaments", "err", err)
+	}
 	slog.Info("migrations applied")
 }
 
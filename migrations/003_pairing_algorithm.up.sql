@@ -0,0 +1,8 @@
+-- Per-tournament choice of Swiss pairing algorithm. 'swiss' is the greedy
+-- top-down pairing built into swisstools (the behaviour before this
+-- column existed); 'weighted' pairs each round by maximum weight matching
+-- over the whole field (see internal/pairing).
+
+ALTER TABLE tournaments
+    ADD COLUMN pairing_algorithm TEXT NOT NULL DEFAULT 'swiss'
+        CHECK (pairing_algorithm IN ('swiss', 'weighted'));
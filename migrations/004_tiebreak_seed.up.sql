@@ -0,0 +1,7 @@
+-- Per-player random tiebreak value. swisstools leaves players whose points
+-- and tiebreakers are all equal in arbitrary order, so the same standings
+-- could come out differently on every page load and in exports. The seed
+-- is rolled once, when the player enters the engine (tournament start or a
+-- mid-tournament add), and is the final comparator in standings.
+
+ALTER TABLE registrations ADD COLUMN tiebreak_seed BIGINT;
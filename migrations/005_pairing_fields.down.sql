@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS pairing_field_values;
+DROP TABLE IF EXISTS pairing_fields;
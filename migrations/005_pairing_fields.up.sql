@@ -0,0 +1,24 @@
+-- Organizer-defined fields attached to the pairings of a tournament: stream
+-- notes, board assignments, map picks and the like. Each field is a label
+-- the organizer creates once per tournament; values are stored per round
+-- and table. Tables are stable within a round (see engine.AssignTables), so
+-- (round, table_number) identifies a pairing. Public fields are shown on
+-- the public pairings page and API; the rest are staff-only.
+
+CREATE TABLE pairing_fields (
+    id            BIGSERIAL   PRIMARY KEY,
+    tournament_id BIGINT      NOT NULL REFERENCES tournaments(id) ON DELETE CASCADE,
+    label         TEXT        NOT NULL,
+    public        BOOLEAN     NOT NULL DEFAULT FALSE,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (tournament_id, label)
+);
+
+CREATE TABLE pairing_field_values (
+    field_id     BIGINT      NOT NULL REFERENCES pairing_fields(id) ON DELETE CASCADE,
+    round        INTEGER     NOT NULL,
+    table_number INTEGER     NOT NULL,
+    value        TEXT        NOT NULL,
+    updated_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (field_id, round, table_number)
+);
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS match_games;
+ALTER TABLE tournaments DROP CONSTRAINT IF EXISTS tournaments_series_mode_best_of_check;
+ALTER TABLE tournaments DROP COLUMN IF EXISTS series_mode;
+ALTER TABLE tournaments DROP COLUMN IF EXISTS best_of;
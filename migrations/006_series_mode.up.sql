@@ -0,0 +1,26 @@
+-- Best-of-N series mode. best_of is the number of games in a match (0 =
+-- unspecified). With series_mode on, each pairing is a best-of-best_of
+-- series reported game by game into match_games; the match result in
+-- engine_state is the aggregate of those games and is only written once
+-- the series is decided. Games are keyed by round and table like
+-- pairing_field_values. Per-game metadata (map/stage, each side's pick of
+-- character, deck or faction) is free text.
+
+ALTER TABLE tournaments
+    ADD COLUMN best_of     INT  NOT NULL DEFAULT 0 CHECK (best_of BETWEEN 0 AND 9),
+    ADD COLUMN series_mode BOOL NOT NULL DEFAULT false,
+    ADD CONSTRAINT tournaments_series_mode_best_of_check CHECK (NOT series_mode OR best_of > 0);
+
+CREATE TABLE match_games (
+    tournament_id BIGINT      NOT NULL REFERENCES tournaments(id) ON DELETE CASCADE,
+    round         INTEGER     NOT NULL,
+    table_number  INTEGER     NOT NULL,
+    game_number   INTEGER     NOT NULL CHECK (game_number >= 1),
+    winner        TEXT        NOT NULL CHECK (winner IN ('a', 'b', 'draw')),
+    map           TEXT        NOT NULL DEFAULT '',
+    player_a_pick TEXT        NOT NULL DEFAULT '',
+    player_b_pick TEXT        NOT NULL DEFAULT '',
+    reported_by   BIGINT               REFERENCES users(id) ON DELETE SET NULL,
+    reported_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (tournament_id, round, table_number, game_number)
+);
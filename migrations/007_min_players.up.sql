@@ -0,0 +1,7 @@
+-- Smallest field a tournament may be started with. Starting is refused
+-- below it, so organizers get a clear message instead of an engine error
+-- from pairing a field too small to pair.
+
+ALTER TABLE tournaments
+    ADD COLUMN min_players INT NOT NULL DEFAULT 2
+        CHECK (min_players >= 2);
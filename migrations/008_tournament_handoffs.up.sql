@@ -0,0 +1,17 @@
+-- Admin handoff codes. An admin issues a short-lived code; whoever claims it
+-- becomes admin of the tournament and the issuer is removed from staff.
+-- Only the SHA-256 of the code is stored. Claimed rows are kept as the
+-- tournament's transfer log.
+
+CREATE TABLE tournament_handoffs (
+    id            BIGSERIAL PRIMARY KEY,
+    tournament_id BIGINT      NOT NULL REFERENCES tournaments(id) ON DELETE CASCADE,
+    code_hash     TEXT        NOT NULL UNIQUE,
+    created_by    BIGINT               REFERENCES users(id)       ON DELETE SET NULL,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT now(),
+    expires_at    TIMESTAMPTZ NOT NULL,
+    claimed_by    BIGINT               REFERENCES users(id)       ON DELETE SET NULL,
+    claimed_at    TIMESTAMPTZ
+);
+
+CREATE INDEX idx_tournament_handoffs_tournament_id ON tournament_handoffs(tournament_id);
@@ -0,0 +1,31 @@
+-- Per-tournament webhooks. Events are queued in webhook_deliveries inside
+-- the transaction that caused them and POSTed by a background worker, so a
+-- slow or dead endpoint never holds up the tournament.
+
+CREATE TABLE tournament_webhooks (
+    id            BIGSERIAL PRIMARY KEY,
+    tournament_id BIGINT      NOT NULL REFERENCES tournaments(id) ON DELETE CASCADE,
+    url           TEXT        NOT NULL,
+    secret        TEXT        NOT NULL,
+    events        TEXT[]      NOT NULL,
+    created_by    BIGINT               REFERENCES users(id)       ON DELETE SET NULL,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_tournament_webhooks_tournament_id ON tournament_webhooks(tournament_id);
+
+CREATE TABLE webhook_deliveries (
+    id              BIGSERIAL PRIMARY KEY,
+    webhook_id      BIGINT      NOT NULL REFERENCES tournament_webhooks(id) ON DELETE CASCADE,
+    event           TEXT        NOT NULL,
+    payload         JSONB       NOT NULL,
+    attempts        INTEGER     NOT NULL DEFAULT 0,
+    -- NULL once delivered or given up on.
+    next_attempt_at TIMESTAMPTZ          DEFAULT now(),
+    delivered_at    TIMESTAMPTZ,
+    last_error      TEXT        NOT NULL DEFAULT '',
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_webhook_deliveries_pending ON webhook_deliveries(next_attempt_at) WHERE next_attempt_at IS NOT NULL;
+CREATE INDEX idx_webhook_deliveries_webhook_id ON webhook_deliveries(webhook_id);
@@ -0,0 +1,17 @@
+-- Results that weren't played out. An intentional draw is recorded in
+-- engine_state as 0-0-3 and a concession as a straight win for the other
+-- side, which on their own look like any other score; this table keeps what
+-- they were for reporting. Plain results have no row. Keyed by round and
+-- table like match_games; conceded_by is the conceding side.
+
+CREATE TABLE match_result_types (
+    tournament_id BIGINT      NOT NULL REFERENCES tournaments(id) ON DELETE CASCADE,
+    round         INTEGER     NOT NULL,
+    table_number  INTEGER     NOT NULL,
+    type          TEXT        NOT NULL CHECK (type IN ('intentional_draw', 'concession')),
+    conceded_by   TEXT        NOT NULL DEFAULT '' CHECK (conceded_by IN ('', 'a', 'b')),
+    reported_by   BIGINT               REFERENCES users(id) ON DELETE SET NULL,
+    reported_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (tournament_id, round, table_number),
+    CHECK ((type = 'concession') = (conceded_by <> ''))
+);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS assigned_byes;
+ALTER TABLE tournaments DROP COLUMN IF EXISTS bye_policy;
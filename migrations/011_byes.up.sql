@@ -0,0 +1,19 @@
+-- Bye handling. bye_policy picks who sits out when an odd number of players
+-- is left to pair: 'lowest' is the lowest-standing player among those with
+-- the fewest byes so far, 'random' a random one of them. assigned_byes are
+-- byes staff hand out on top of that for a given round, for instance to a
+-- judge who is only playing in to even out the field. They are keyed by
+-- registration, so they survive a reset.
+
+ALTER TABLE tournaments
+    ADD COLUMN bye_policy TEXT NOT NULL DEFAULT 'lowest'
+        CHECK (bye_policy IN ('lowest', 'random'));
+
+CREATE TABLE assigned_byes (
+    tournament_id   BIGINT      NOT NULL REFERENCES tournaments(id) ON DELETE CASCADE,
+    round           INTEGER     NOT NULL CHECK (round >= 1),
+    registration_id BIGINT      NOT NULL REFERENCES registrations(id) ON DELETE CASCADE,
+    assigned_by     BIGINT               REFERENCES users(id) ON DELETE SET NULL,
+    assigned_at     TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (tournament_id, round, registration_id)
+);
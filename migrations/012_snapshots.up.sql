@@ -0,0 +1,16 @@
+-- Tournament snapshots: full backups (see db.TournamentBackup) taken when a
+-- round is paired or the tournament finishes, every few minutes while it
+-- changes, and just before a rollback. Staff roll a tournament back to any
+-- of them; the newest few per tournament are kept.
+
+CREATE TABLE tournament_snapshots (
+    id            BIGSERIAL   PRIMARY KEY,
+    tournament_id BIGINT      NOT NULL REFERENCES tournaments(id) ON DELETE CASCADE,
+    reason        TEXT        NOT NULL CHECK (reason IN ('round', 'periodic', 'rollback')),
+    status        TEXT        NOT NULL,
+    round         INTEGER     NOT NULL DEFAULT 0,
+    data          JSONB       NOT NULL,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_tournament_snapshots_tournament ON tournament_snapshots(tournament_id, created_at DESC);
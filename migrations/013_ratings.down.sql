@@ -0,0 +1,2 @@
+ALTER TABLE tournaments DROP COLUMN IF EXISTS round1_pairing;
+ALTER TABLE registrations DROP COLUMN IF EXISTS rating;
@@ -0,0 +1,10 @@
+-- Seeded round 1. rating is a player's rating as imported or entered by the
+-- organizer; NULL means unrated. round1_pairing picks how round 1 is paired:
+-- 'random' as before, or by rating, either 'cross' (top half against bottom
+-- half, 1 v N/2+1) or 'fold' (1 v N, 2 v N-1).
+
+ALTER TABLE registrations ADD COLUMN rating INTEGER;
+
+ALTER TABLE tournaments
+    ADD COLUMN round1_pairing TEXT NOT NULL DEFAULT 'random'
+        CHECK (round1_pairing IN ('random', 'cross', 'fold'));
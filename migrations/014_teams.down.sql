@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS seat_results;
+ALTER TABLE registrations DROP COLUMN IF EXISTS members;
+ALTER TABLE tournaments DROP CONSTRAINT IF EXISTS tournaments_team_size_series_mode_check;
+ALTER TABLE tournaments DROP COLUMN IF EXISTS team_size;
@@ -0,0 +1,24 @@
+-- Team Swiss. With team_size > 0 every registration is a team: the engine
+-- pairs teams as it would players, and members lists the team's players in
+-- seat order (seat 1 first). In each match seat N of one team plays seat N
+-- of the other; those individual results go into seat_results, keyed by
+-- round and table like match_games, and the team match result in
+-- engine_state is their tally once every seat is in. Team and series mode
+-- both roll results up into the match result, so they don't mix.
+
+ALTER TABLE tournaments
+    ADD COLUMN team_size INT NOT NULL DEFAULT 0 CHECK (team_size = 0 OR team_size BETWEEN 2 AND 6),
+    ADD CONSTRAINT tournaments_team_size_series_mode_check CHECK (team_size = 0 OR NOT series_mode);
+
+ALTER TABLE registrations ADD COLUMN members TEXT[] NOT NULL DEFAULT '{}';
+
+CREATE TABLE seat_results (
+    tournament_id BIGINT      NOT NULL REFERENCES tournaments(id) ON DELETE CASCADE,
+    round         INTEGER     NOT NULL,
+    table_number  INTEGER     NOT NULL,
+    seat          INTEGER     NOT NULL CHECK (seat >= 1),
+    winner        TEXT        NOT NULL CHECK (winner IN ('a', 'b', 'draw')),
+    reported_by   BIGINT               REFERENCES users(id) ON DELETE SET NULL,
+    reported_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (tournament_id, round, table_number, seat)
+);
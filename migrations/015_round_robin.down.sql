@@ -0,0 +1,5 @@
+UPDATE tournaments SET pairing_algorithm = 'swiss'
+    WHERE pairing_algorithm IN ('round_robin', 'danish');
+ALTER TABLE tournaments DROP CONSTRAINT IF EXISTS tournaments_pairing_algorithm_check;
+ALTER TABLE tournaments ADD CONSTRAINT tournaments_pairing_algorithm_check
+    CHECK (pairing_algorithm IN ('swiss', 'weighted'));
@@ -0,0 +1,7 @@
+-- Round-robin and Danish pairing. 'round_robin' pairs every player against
+-- every other on a fixed schedule; 'danish' pairs down the standings,
+-- first against second, with rematches allowed (see internal/pairing).
+
+ALTER TABLE tournaments DROP CONSTRAINT IF EXISTS tournaments_pairing_algorithm_check;
+ALTER TABLE tournaments ADD CONSTRAINT tournaments_pairing_algorithm_check
+    CHECK (pairing_algorithm IN ('swiss', 'weighted', 'round_robin', 'danish'));
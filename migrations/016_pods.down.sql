@@ -0,0 +1,6 @@
+ALTER TABLE registrations DROP COLUMN IF EXISTS advanced_from;
+DROP INDEX IF EXISTS idx_tournaments_parent;
+ALTER TABLE tournaments
+    DROP CONSTRAINT IF EXISTS tournaments_parent_id_check,
+    DROP COLUMN IF EXISTS pod_advance,
+    DROP COLUMN IF EXISTS parent_id;
@@ -0,0 +1,15 @@
+-- Multi-stage events. A pod is a tournament with a parent_id: the parent is
+-- the umbrella event and its own rounds are the finals. Once every pod is
+-- complete the top pod_advance finishers of each (pod_advance is set on the
+-- parent) are registered into the finals, and advanced_from records which
+-- pod registration each finalist came from.
+
+ALTER TABLE tournaments
+    ADD COLUMN parent_id   BIGINT REFERENCES tournaments(id) ON DELETE CASCADE,
+    ADD COLUMN pod_advance INT NOT NULL DEFAULT 0 CHECK (pod_advance >= 0),
+    ADD CONSTRAINT tournaments_parent_id_check CHECK (parent_id <> id);
+
+CREATE INDEX idx_tournaments_parent ON tournaments (parent_id) WHERE parent_id IS NOT NULL;
+
+ALTER TABLE registrations
+    ADD COLUMN advanced_from BIGINT REFERENCES registrations(id) ON DELETE SET NULL;
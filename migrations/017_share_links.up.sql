@@ -0,0 +1,6 @@
+-- Read-only share links. A tournament with a share_token can be followed
+-- at /t/{id}/view/{token}: standings and pairings only, with no forms and
+-- no cookies. The token is stored as is so staff can see the link again;
+-- creating a new one or clearing it revokes the old link.
+
+ALTER TABLE tournaments ADD COLUMN share_token TEXT UNIQUE;
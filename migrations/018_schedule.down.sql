@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS schedule_items;
+ALTER TABLE tournaments DROP COLUMN IF EXISTS time_zone;
@@ -0,0 +1,16 @@
+-- Round schedule. time_zone is the IANA zone of the venue: times typed by
+-- staff (scheduled_at, schedule items) are read in it, and pages show them
+-- in it until the viewer's browser converts them to its own zone.
+-- schedule_items lists the timed parts of the day, such as round starts and
+-- a lunch break, each with a free-form label.
+
+ALTER TABLE tournaments ADD COLUMN time_zone TEXT NOT NULL DEFAULT 'UTC';
+
+CREATE TABLE schedule_items (
+    id            BIGSERIAL PRIMARY KEY,
+    tournament_id BIGINT NOT NULL REFERENCES tournaments(id) ON DELETE CASCADE,
+    starts_at     TIMESTAMPTZ NOT NULL,
+    label         TEXT NOT NULL CHECK (label <> '')
+);
+
+CREATE INDEX idx_schedule_items_tournament ON schedule_items (tournament_id, starts_at);
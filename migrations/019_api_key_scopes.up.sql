@@ -0,0 +1,6 @@
+-- API key scopes. A read key may only make GET (and HEAD) requests; a
+-- read_write key acts with its owner's full permissions, as every key did
+-- before scopes existed.
+
+ALTER TABLE api_keys ADD COLUMN scope TEXT NOT NULL DEFAULT 'read_write'
+    CHECK (scope IN ('read', 'read_write'));
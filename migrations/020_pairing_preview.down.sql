@@ -0,0 +1,2 @@
+ALTER TABLE tournaments DROP COLUMN IF EXISTS draft_round;
+ALTER TABLE tournaments DROP COLUMN IF EXISTS preview_pairings;
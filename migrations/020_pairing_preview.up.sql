@@ -0,0 +1,6 @@
+-- Pairing preview. With preview_pairings set, each newly paired Swiss round
+-- is a draft that only staff see until it is published; draft_round names
+-- that round, 0 when nothing is waiting to be published.
+
+ALTER TABLE tournaments ADD COLUMN preview_pairings BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE tournaments ADD COLUMN draft_round INT NOT NULL DEFAULT 0;
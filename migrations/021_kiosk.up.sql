@@ -0,0 +1,7 @@
+-- Kiosk result entry. While a tournament has a kiosk_secret, players can
+-- report their match at /t/{id}/kiosk with the table number and the PIN
+-- printed on their slip. PINs are derived from the secret, the round and
+-- the players at the table, so none are stored; a new secret changes them
+-- all, and clearing it turns the kiosk off.
+
+ALTER TABLE tournaments ADD COLUMN kiosk_secret TEXT;
@@ -0,0 +1,22 @@
+-- Results changed after their round closed. Scores are from player A's
+-- side: A's games won, B's, and drawn games, before and after. The rounds
+-- after round up to paired_through (the current round at the time) were
+-- paired with the old result counted, and are flagged as such.
+
+CREATE TABLE result_corrections (
+    id             BIGSERIAL   PRIMARY KEY,
+    tournament_id  BIGINT      NOT NULL REFERENCES tournaments(id) ON DELETE CASCADE,
+    round          INTEGER     NOT NULL,
+    table_number   INTEGER     NOT NULL,
+    old_a          INTEGER     NOT NULL,
+    old_b          INTEGER     NOT NULL,
+    old_draws      INTEGER     NOT NULL,
+    new_a          INTEGER     NOT NULL,
+    new_b          INTEGER     NOT NULL,
+    new_draws      INTEGER     NOT NULL,
+    paired_through INTEGER     NOT NULL,
+    corrected_by   BIGINT               REFERENCES users(id) ON DELETE SET NULL,
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_result_corrections_tournament ON result_corrections(tournament_id, round);
@@ -0,0 +1,2 @@
+ALTER TABLE tournaments DROP COLUMN IF EXISTS challonge_url;
+DROP TABLE IF EXISTS site_settings;
@@ -0,0 +1,11 @@
+-- Site-wide settings an admin edits on /admin/integrations, such as the
+-- Challonge API key. One row per key; a setting that was never saved or
+-- was cleared has no row.
+CREATE TABLE site_settings (
+    key        TEXT        PRIMARY KEY,
+    value      TEXT        NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Where a tournament's results were last pushed on Challonge.
+ALTER TABLE tournaments ADD COLUMN challonge_url TEXT;
@@ -0,0 +1,3 @@
+-- The deck archetype a player brought, as they or staff named it alongside
+-- the decklist; NULL when not given. The metagame page groups players by it.
+ALTER TABLE registrations ADD COLUMN archetype TEXT;
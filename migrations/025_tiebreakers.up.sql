@@ -0,0 +1,7 @@
+-- The order of the tiebreakers that rank players level on points, first
+-- applied first: 'omw' (opponents' match win percentage), 'gw' (game win
+-- percentage) and 'ogw' (opponents' game win percentage). Any left out are
+-- not used; fully tied players then go by their tiebreak seed.
+ALTER TABLE tournaments
+    ADD COLUMN tiebreakers TEXT[] NOT NULL DEFAULT '{omw,gw,ogw}'
+        CHECK (tiebreakers <@ ARRAY['omw', 'gw', 'ogw']);
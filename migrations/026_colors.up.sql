@@ -0,0 +1,5 @@
+-- Chess colours. With colors on, player A of every Swiss match plays white;
+-- pairing gives each player alternating colours and keeps their whites and
+-- blacks within one of each other where it can. The colours themselves live
+-- in the engine state, as the side of each pairing.
+ALTER TABLE tournaments ADD COLUMN colors BOOLEAN NOT NULL DEFAULT false;
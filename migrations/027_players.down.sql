@@ -0,0 +1,2 @@
+ALTER TABLE registrations DROP COLUMN IF EXISTS player_id;
+DROP TABLE IF EXISTS players;
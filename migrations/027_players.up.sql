@@ -0,0 +1,20 @@
+-- The player registry: people who come back event after event, kept across
+-- tournaments so organizers can add them from a picker instead of typing
+-- them in again. contact is free text (an email, a phone number); rating is
+-- copied onto a registration made from the entry. A registration made from
+-- an entry links to it in player_id, which is how a player's lifetime
+-- record is gathered; deleting the entry keeps the registrations.
+CREATE TABLE players (
+    id         BIGSERIAL   PRIMARY KEY,
+    name       TEXT        NOT NULL,
+    contact    TEXT,
+    rating     INTEGER,
+    created_by BIGINT      REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_players_name ON players(lower(name));
+
+ALTER TABLE registrations ADD COLUMN player_id BIGINT REFERENCES players(id) ON DELETE SET NULL;
+CREATE INDEX idx_registrations_player ON registrations(player_id);
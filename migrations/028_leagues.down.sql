@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS league_tournaments;
+DROP TABLE IF EXISTS leagues;
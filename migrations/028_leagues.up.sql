@@ -0,0 +1,22 @@
+-- Leagues: seasons whose leaderboard adds up points from the final results
+-- of their tournaments. placement_points[i] is what place i (1-based) in a
+-- complete tournament is worth; every other finisher gets
+-- participation_points. A tournament can count towards any number of
+-- leagues.
+CREATE TABLE leagues (
+    id                   BIGSERIAL   PRIMARY KEY,
+    name                 TEXT        NOT NULL CHECK (name <> ''),
+    placement_points     INTEGER[]   NOT NULL DEFAULT '{}',
+    participation_points INTEGER     NOT NULL DEFAULT 0 CHECK (participation_points >= 0),
+    created_by           BIGINT      REFERENCES users(id) ON DELETE SET NULL,
+    created_at           TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at           TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE league_tournaments (
+    league_id     BIGINT NOT NULL REFERENCES leagues(id) ON DELETE CASCADE,
+    tournament_id BIGINT NOT NULL REFERENCES tournaments(id) ON DELETE CASCADE,
+    PRIMARY KEY (league_id, tournament_id)
+);
+
+CREATE INDEX idx_league_tournaments_tournament ON league_tournaments(tournament_id);
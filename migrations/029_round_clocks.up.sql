@@ -0,0 +1,12 @@
+-- Round clocks: the time limit staff set on a tournament's current Swiss
+-- round. Shortly before ends_at, the tables still without a result are
+-- reminded once (see engine.Reminder); reminded_at records that it happened.
+-- Starting the clock again resets it.
+CREATE TABLE round_clocks (
+    tournament_id BIGINT      PRIMARY KEY REFERENCES tournaments(id) ON DELETE CASCADE,
+    round         INTEGER     NOT NULL,
+    ends_at       TIMESTAMPTZ NOT NULL,
+    reminded_at   TIMESTAMPTZ
+);
+
+CREATE INDEX idx_round_clocks_due ON round_clocks(ends_at) WHERE reminded_at IS NULL;
@@ -0,0 +1,11 @@
+-- Avatars: the icon a user picked, or the image they uploaded (already
+-- scaled to a small PNG by the server), shown next to their name in
+-- pairings and standings. A user has at most one, and exactly one of icon
+-- and image is set.
+CREATE TABLE avatars (
+    user_id    BIGINT      PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    icon       TEXT,
+    image      BYTEA,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CHECK ((icon IS NULL) <> (image IS NULL))
+);
@@ -0,0 +1,15 @@
+-- Announcements: messages staff post to a tournament's players, such as
+-- "Round 3 is delayed 10 minutes". One shows as a banner on the
+-- tournament's pages while it is active: until expires_at, or until staff
+-- end it when expires_at is NULL. Ending one sets expires_at to the time it
+-- ended, so every announcement stays in the history.
+CREATE TABLE announcements (
+    id            BIGSERIAL PRIMARY KEY,
+    tournament_id BIGINT      NOT NULL REFERENCES tournaments(id) ON DELETE CASCADE,
+    message       TEXT        NOT NULL,
+    created_by    BIGINT               REFERENCES users(id)       ON DELETE SET NULL,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT now(),
+    expires_at    TIMESTAMPTZ
+);
+
+CREATE INDEX idx_announcements_tournament_id ON announcements(tournament_id, created_at);
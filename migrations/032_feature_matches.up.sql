@@ -0,0 +1,10 @@
+-- Feature matches: the pairings of a Swiss round staff put on stream. They
+-- are keyed by table like the other per-pairing rows and cleared with them
+-- when the round is re-paired.
+CREATE TABLE feature_matches (
+    tournament_id BIGINT      NOT NULL REFERENCES tournaments(id) ON DELETE CASCADE,
+    round         INTEGER     NOT NULL,
+    table_number  INTEGER     NOT NULL,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (tournament_id, round, table_number)
+);
@@ -0,0 +1,3 @@
+ALTER TABLE tournaments DROP COLUMN IF EXISTS avoid_club_rounds;
+ALTER TABLE players DROP COLUMN IF EXISTS club;
+ALTER TABLE registrations DROP COLUMN IF EXISTS club;
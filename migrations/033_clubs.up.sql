@@ -0,0 +1,8 @@
+-- Clubs. A player's club or team is free text on their registration, NULL
+-- when they have none; a registry entry's club is copied onto registrations
+-- made from it, like its rating. With avoid_club_rounds set, the first that
+-- many Swiss rounds keep players of the same club apart where the pairing
+-- allows; 0 turns it off.
+ALTER TABLE registrations ADD COLUMN club TEXT;
+ALTER TABLE players ADD COLUMN club TEXT;
+ALTER TABLE tournaments ADD COLUMN avoid_club_rounds INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,4 @@
+-- Recommended rounds. A Swiss without num_rounds and with auto_rounds on
+-- runs ceil(log2(players)) rounds, counted when it starts, and finishes
+-- after the last one.
+ALTER TABLE tournaments ADD COLUMN auto_rounds BOOLEAN NOT NULL DEFAULT false;
@@ -0,0 +1,4 @@
+ALTER TABLE registrations DROP COLUMN IF EXISTS payment_method;
+ALTER TABLE registrations DROP COLUMN IF EXISTS payment_amount;
+ALTER TABLE registrations DROP COLUMN IF EXISTS payment_status;
+ALTER TABLE tournaments DROP COLUMN IF EXISTS entry_fee;
@@ -0,0 +1,8 @@
+-- Entry fees and payments. Amounts are in minor units (cents), with no
+-- currency of their own. entry_fee 0 is a free event. A registration's
+-- payment_status is unpaid, paid or comped (let in free); payment_amount
+-- and payment_method record what was taken and how, NULL when not noted.
+ALTER TABLE tournaments ADD COLUMN entry_fee INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE registrations ADD COLUMN payment_status TEXT NOT NULL DEFAULT 'unpaid';
+ALTER TABLE registrations ADD COLUMN payment_amount INTEGER;
+ALTER TABLE registrations ADD COLUMN payment_method TEXT;
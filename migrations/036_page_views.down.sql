@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS viewer_peaks;
+DROP TABLE IF EXISTS page_views;
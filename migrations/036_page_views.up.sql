@@ -0,0 +1,17 @@
+-- Spectator analytics. page_views counts views of a tournament's public
+-- pages by the round they showed (0 before round 1) and kind of page:
+-- tournament, pairings or standings. viewer_peaks keeps the most people
+-- seen watching a tournament at once. Nothing identifies a viewer.
+CREATE TABLE page_views (
+    tournament_id BIGINT NOT NULL REFERENCES tournaments(id) ON DELETE CASCADE,
+    round         INT    NOT NULL,
+    page          TEXT   NOT NULL,
+    views         BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (tournament_id, round, page)
+);
+
+CREATE TABLE viewer_peaks (
+    tournament_id BIGINT      PRIMARY KEY REFERENCES tournaments(id) ON DELETE CASCADE,
+    viewers       INT         NOT NULL,
+    seen_at       TIMESTAMPTZ NOT NULL
+);
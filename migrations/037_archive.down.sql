@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS archive_results;
+DROP TABLE IF EXISTS archives;
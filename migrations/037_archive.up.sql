@@ -0,0 +1,25 @@
+-- The archive of complete tournaments. archives has a row for each one,
+-- written when it finishes; archive_results freezes its final standings
+-- then, so renamed players and deleted accounts don't rewrite history.
+-- user_id and player_id say who a finisher was, for searches across events.
+CREATE TABLE archives (
+    tournament_id BIGINT      PRIMARY KEY REFERENCES tournaments(id) ON DELETE CASCADE,
+    players       INT         NOT NULL,
+    archived_at   TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE archive_results (
+    tournament_id BIGINT NOT NULL REFERENCES archives(tournament_id) ON DELETE CASCADE,
+    place         INT    NOT NULL,
+    name          TEXT   NOT NULL,
+    playoff       TEXT   NOT NULL DEFAULT '',
+    points        INT    NOT NULL,
+    wins          INT    NOT NULL,
+    losses        INT    NOT NULL,
+    draws         INT    NOT NULL,
+    user_id       BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    player_id     BIGINT REFERENCES players(id) ON DELETE SET NULL,
+    PRIMARY KEY (tournament_id, place)
+);
+
+CREATE INDEX archive_results_name ON archive_results (lower(name));
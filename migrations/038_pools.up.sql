@@ -0,0 +1,7 @@
+-- Pools. A multi-stage event can deal its players into round robin pods
+-- (pools) instead of having them register for a pod. pod_seed is a
+-- finalist's seed from the pod results: pod winners first, then
+-- runners-up, and so on. A seeded round 1 of the finals pairs by it ahead
+-- of rating.
+
+ALTER TABLE registrations ADD COLUMN pod_seed INT CHECK (pod_seed >= 1);
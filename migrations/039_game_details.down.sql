@@ -0,0 +1 @@
+ALTER TABLE match_games DROP COLUMN IF EXISTS notes, DROP COLUMN IF EXISTS went_first;
@@ -0,0 +1,7 @@
+-- Game details. A series game can record which player went first ('a' or
+-- 'b', '' = not noted) and free-form notes alongside its map and picks.
+-- Games can be edited in place as well as undone.
+
+ALTER TABLE match_games
+    ADD COLUMN went_first TEXT NOT NULL DEFAULT '' CHECK (went_first IN ('', 'a', 'b')),
+    ADD COLUMN notes      TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS result_reports;
+ALTER TABLE tournaments DROP COLUMN IF EXISTS report_confirm_minutes, DROP COLUMN IF EXISTS self_report;
@@ -0,0 +1,21 @@
+-- Player result reporting. With self_report on, each player of a match can
+-- report its result; it counts once both reports agree, or once a lone
+-- report is report_confirm_minutes old (0 = never). Reports that differ
+-- wait for staff. Scores are from player A's side, whoever reported.
+
+ALTER TABLE tournaments
+    ADD COLUMN self_report             BOOLEAN NOT NULL DEFAULT false,
+    ADD COLUMN report_confirm_minutes  INTEGER NOT NULL DEFAULT 0 CHECK (report_confirm_minutes >= 0);
+
+CREATE TABLE result_reports (
+    tournament_id BIGINT      NOT NULL REFERENCES tournaments(id) ON DELETE CASCADE,
+    round         INTEGER     NOT NULL,
+    table_number  INTEGER     NOT NULL,
+    side          TEXT        NOT NULL CHECK (side IN ('a', 'b')),
+    wins          INTEGER     NOT NULL,
+    losses        INTEGER     NOT NULL,
+    draws         INTEGER     NOT NULL,
+    user_id       BIGINT      REFERENCES users(id) ON DELETE SET NULL,
+    reported_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (tournament_id, round, table_number, side)
+);
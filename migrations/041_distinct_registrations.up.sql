@@ -0,0 +1,16 @@
+-- Pairs of registrations whose names look alike (see
+-- models.SimilarPlayerName) that staff checked are two different people,
+-- so the players page stops flagging them as a possible duplicate. The
+-- pair is stored lower ID first.
+
+CREATE TABLE distinct_registrations (
+    tournament_id   BIGINT      NOT NULL REFERENCES tournaments(id) ON DELETE CASCADE,
+    registration_id BIGINT      NOT NULL REFERENCES registrations(id) ON DELETE CASCADE,
+    other_id        BIGINT      NOT NULL REFERENCES registrations(id) ON DELETE CASCADE,
+    marked_by       BIGINT      REFERENCES users(id) ON DELETE SET NULL,
+    marked_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (registration_id, other_id),
+    CHECK (registration_id < other_id)
+);
+
+CREATE INDEX distinct_registrations_tournament ON distinct_registrations (tournament_id);
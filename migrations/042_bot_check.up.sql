@@ -0,0 +1,4 @@
+-- Bot Check: online sign-ups solve a proof-of-work puzzle in the browser
+-- before they count (see internal/pow).
+
+ALTER TABLE tournaments ADD COLUMN bot_check BOOLEAN NOT NULL DEFAULT false;
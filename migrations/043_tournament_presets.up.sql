@@ -0,0 +1,16 @@
+-- Named sets of tournament settings (format, points, rounds, pairing
+-- fields) organizers save from one event to create later ones from. The
+-- settings are models.PresetSettings as JSON, the same as an exported
+-- preset file. Names are unique ignoring case; saving under a taken name
+-- replaces that preset.
+
+CREATE TABLE tournament_presets (
+    id         BIGSERIAL   PRIMARY KEY,
+    name       TEXT        NOT NULL,
+    settings   JSONB       NOT NULL,
+    created_by BIGINT      REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX tournament_presets_name ON tournament_presets (lower(name));
@@ -0,0 +1,5 @@
+-- Notification preferences: how each user wants to hear about their
+-- matches. More channels can join the check later.
+
+ALTER TABLE users ADD COLUMN notify_by TEXT NOT NULL DEFAULT 'email'
+    CHECK (notify_by IN ('email', 'none'));
@@ -0,0 +1,4 @@
+-- Whether a player agreed, when signing up, to hear from the organizer
+-- after the event. Only those who did are in the mailing list export.
+
+ALTER TABLE registrations ADD COLUMN contact_consent BOOLEAN NOT NULL DEFAULT false;
@@ -0,0 +1,4 @@
+-- Players asking for their personal data to be deleted. An admin then
+-- anonymizes or deletes the account (see 3.4).
+
+ALTER TABLE users ADD COLUMN deletion_requested_at TIMESTAMPTZ;
@@ -0,0 +1,4 @@
+-- Blind final round: the standings are hidden from players and spectators
+-- while the last Swiss round is played (see engine.StandingsHidden).
+
+ALTER TABLE tournaments ADD COLUMN blind_final_round BOOLEAN NOT NULL DEFAULT false;
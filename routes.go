@@ -0,0 +1,583 @@
+package main
+
+import (
+	"database/sql"
+	"io/fs"
+	"net/http"
+	"os"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/dstathis/openswiss/internal/analytics"
+	"github.com/dstathis/openswiss/internal/api"
+	"github.com/dstathis/openswiss/internal/email"
+	"github.com/dstathis/openswiss/internal/handlers"
+	"github.com/dstathis/openswiss/internal/metrics"
+	mw "github.com/dstathis/openswiss/internal/middleware"
+	"github.com/dstathis/openswiss/internal/readonly"
+	"github.com/dstathis/openswiss/internal/stripe"
+)
+
+// server is what the routes are built from: the database and templates,
+// the services shared with the background workers, and the configuration
+// the handlers and middleware read.
+type server struct {
+	db        *sql.DB
+	renderer  *namedTemplate
+	readOnly  *readonly.Mode
+	collector *metrics.Collector
+	email     *email.Sender
+	// views counts public page views; nil in tests counts none.
+	views *analytics.Tracker
+	cfg   *config
+}
+
+// chains are the middleware stacks routes are registered under. Each one
+// builds on a wider one, so a route added to a group gets the same CSRF
+// check, rate limit and auth as every other route in it; pick the group by
+// who may call the route.
+type chains struct {
+	// base wraps every request, static files and probes included.
+	base mw.Chain
+
+	web       mw.Chain // pages and forms: CSRF
+	kiosk     mw.Chain // web, limited per IP since a table PIN is all it takes
+	login     mw.Chain // web, with the tighter per-IP limit of the auth endpoints
+	signIn    mw.Chain // login, from ADMIN_ALLOWLIST only
+	signedIn  mw.Chain // web, for any account
+	organizer mw.Chain // signedIn, with the global organizer role
+	admin     mw.Chain // signedIn from ADMIN_ALLOWLIST, with the admin role
+
+	api          mw.Chain // the REST API: rate limited, CSRF for session callers
+	apiSignedIn  mw.Chain // api, by session or API key
+	apiOrganizer mw.Chain // apiSignedIn, with the global organizer role
+	apiAdmin     mw.Chain // apiSignedIn from ADMIN_ALLOWLIST, with the admin role
+}
+
+// chains builds the stacks for router, which the 405 and HEAD handling
+// look routes up in.
+func (s *server) chains(router chi.Routes) chains {
+	// The panic page is rendered outside the chain it stands in for, so it
+	// picks its own language.
+	errorH := &handlers.ErrorHandler{Tmpl: s.renderer}
+	errorPage := mw.Chain{mw.Localize(s.cfg.Locale)}.Then(http.HandlerFunc(errorH.Internal))
+
+	var c chains
+	c.base = mw.Chain{
+		// RequestID is outermost so the request ID is in context for any
+		// log line emitted by Recover or anything downstream.
+		mw.RequestID,
+		// Recover catches panics in any later middleware or handler so a
+		// single bad request doesn't kill the process.
+		mw.Recover(errorPage, s.collector.RecordPanic),
+		mw.AccessLog,
+		// Handlers waiting on a busy tournament give up with a 503 once
+		// the deadline passes, well before the server's write timeout.
+		mw.Timeout(s.cfg.RequestTimeout),
+		// RealIP must come before any middleware that keys on the client IP
+		// (rate limiter, future audit logging) so they all see the same value.
+		mw.RealIP(s.cfg.TrustedProxies),
+		mw.SecureHeaders(s.cfg.SecureCookies),
+		// Outside the ETag and page cache layers, which see the plain body.
+		mw.Compress,
+		s.collector.Wrap,
+		mw.MaxBodySize(2 << 20),
+		mw.GetHead(router),
+		// Before read-only mode, whose page cache keeps a copy per locale.
+		mw.Localize(s.cfg.Locale),
+		mw.SessionAuth(s.db),
+		mw.APIKeyAuth(s.db),
+		// After auth, so cached pages are only ever anonymous ones.
+		s.readOnly.Wrap,
+		// Inside read-only mode, so stale copies (no-store) get no ETag.
+		mw.ConditionalGet("/", "/tournaments", "/t", "/api/v1/tournaments"),
+	}
+	if s.cfg.Dev {
+		// Nothing is worth keeping while templates and static files change.
+		c.base = c.base.Append(mw.NoStore)
+	}
+
+	// Error pages sit inside CSRF, which gives the page its token.
+	c.web = mw.Chain{mw.CSRFProtect(s.cfg.SecureCookies), mw.Flashes(s.cfg.SecureCookies), mw.ErrorPages(errorH.Page)}
+	c.kiosk = c.web.Append(mw.RateLimit(s.cfg.RateLimit))
+	// The auth endpoints' per-IP limit comes on top of the per-account
+	// lockout enforced inside the Login handler. Together they bound
+	// credential-stuffing throughput from any single source.
+	c.login = c.web.Append(mw.RateLimit(s.cfg.AuthRateLimit))
+	// The admin allowlist refuses other addresses before they learn
+	// whether they are signed in.
+	adminIPs := mw.AllowIPs(s.cfg.AdminAllowlist)
+	c.signIn = c.login.Append(adminIPs)
+	c.signedIn = c.web.Append(mw.RequireAuth)
+	c.organizer = c.signedIn.Append(mw.RequireRole("organizer"))
+	c.admin = c.web.Append(adminIPs, mw.RequireAuth, mw.RequireRole("admin"))
+
+	// CSRF is only enforced for session-authenticated requests, via
+	// X-CSRF-Token. All API chains share one limiter.
+	c.api = mw.Chain{mw.RateLimit(s.cfg.RateLimit), mw.CSRFProtect(s.cfg.SecureCookies)}
+	c.apiSignedIn = c.api.Append(mw.RequireAuth)
+	c.apiOrganizer = c.apiSignedIn.Append(mw.RequireRole("organizer"))
+	c.apiAdmin = c.api.Append(adminIPs, mw.RequireAuth, mw.RequireRole("admin"))
+	return c
+}
+
+// routes registers every route under its chain and returns the router.
+func (s *server) routes() (http.Handler, error) {
+	database, renderer, emailSender, baseURL := s.db, s.renderer, s.email, s.cfg.BaseURL
+
+	tournamentH := &handlers.TournamentHandler{DB: database, Tmpl: renderer, Email: emailSender, BaseURL: baseURL, Defaults: &s.cfg.Defaults, Views: s.views}
+	if s.cfg.Stripe.Enabled() {
+		tournamentH.Stripe = stripe.New(s.cfg.Stripe, "")
+	}
+	authH := &handlers.AuthHandler{DB: database, Tmpl: renderer, Email: emailSender, BaseURL: baseURL, SecureCookies: s.cfg.SecureCookies}
+	playerH := &handlers.PlayerHandler{DB: database, Tmpl: renderer}
+	adminH := &handlers.AdminHandler{DB: database, Tmpl: renderer, ReadOnly: s.readOnly, BaseURL: baseURL}
+	themeH := &handlers.ThemeHandler{SecureCookies: s.cfg.SecureCookies}
+	staffH := &handlers.StaffHandler{DB: database, Tmpl: renderer, Email: emailSender, BaseURL: baseURL}
+	registryH := &handlers.RegistryHandler{DB: database, Tmpl: renderer}
+	presetH := &handlers.PresetHandler{DB: database, Tmpl: renderer}
+	leagueH := &handlers.LeagueHandler{DB: database, Tmpl: renderer}
+	archiveH := &handlers.ArchiveHandler{DB: database, Tmpl: renderer}
+	healthH := &handlers.HealthHandler{DB: database}
+
+	tournamentAPI := &api.TournamentAPI{DB: database, Defaults: &s.cfg.Defaults}
+	playersAPI := &api.PlayersAPI{DB: database}
+	roundsAPI := &api.RoundsAPI{DB: database}
+	lifecycleAPI := &api.LifecycleAPI{DB: database}
+	pairingFieldsAPI := &api.PairingFieldsAPI{DB: database}
+	byesAPI := &api.ByesAPI{DB: database}
+	webhooksAPI := &api.WebhooksAPI{DB: database}
+	analyticsAPI := &api.AnalyticsAPI{DB: database, Views: s.views}
+	snapshotsAPI := &api.SnapshotsAPI{DB: database}
+	playoffAPI := &api.PlayoffAPI{DB: database}
+	usersAPI := &api.UsersAPI{DB: database}
+	adminAPI := &api.AdminAPI{DB: database, ReadOnly: s.readOnly, BaseURL: baseURL}
+	staffAPI := &api.StaffAPI{DB: database, Email: emailSender, BaseURL: baseURL}
+	discordAPI := &api.DiscordAPI{DB: database, PublicKey: s.cfg.DiscordPublicKey, BaseURL: baseURL}
+	stripeAPI := &api.StripeAPI{DB: database, WebhookSecret: s.cfg.Stripe.WebhookSecret}
+	remindersAPI := &api.RemindersAPI{DB: database, Email: emailSender, BaseURL: baseURL}
+	registryAPI := &api.RegistryAPI{DB: database}
+	presetsAPI := &api.PresetsAPI{DB: database}
+	leaguesAPI := &api.LeaguesAPI{DB: database}
+	archiveAPI := &api.ArchiveAPI{DB: database}
+
+	staticSub, err := fs.Sub(staticFS, "static")
+	if err != nil {
+		return nil, err
+	}
+	if s.cfg.Dev {
+		staticSub = os.DirFS("static")
+	}
+
+	r := chi.NewRouter()
+	c := s.chains(r)
+	// A known path asked with the wrong method is a 405 listing the right
+	// ones, here and in the API's sub-router.
+	r.MethodNotAllowed(mw.MethodNotAllowed(r))
+	r.Use(c.base...)
+
+	r.Handle("/static/*", http.StripPrefix("/static/", http.FileServer(http.FS(staticSub))))
+	r.Get("/metrics", s.collector.Handler())
+	r.Get("/healthz", healthH.Live)
+	r.Get("/readyz", healthH.Ready)
+
+	// Read-only share links, stream overlays, schedule feeds and avatar
+	// images sit outside the web chain, whose CSRF token cookie would
+	// otherwise be set on every visit: spectators, browser sources and
+	// calendar apps get no cookies and no forms.
+	r.Get("/t/{id}/view/{token}", tournamentH.ShareView)
+	r.Get("/tournaments/{id}/schedule.ics", tournamentH.ScheduleICS)
+	r.Get("/tournaments/{id}/overlay/standings", tournamentH.OverlayStandings)
+	r.Get("/tournaments/{id}/overlay/match/{table}", tournamentH.OverlayMatch)
+	r.Get("/avatars/{uid}", playerH.AvatarImage)
+
+	r.With(c.web...).Group(func(r chi.Router) {
+		r.Get("/", tournamentH.Home)
+		r.Get("/tournaments", tournamentH.List)
+		r.Get("/tournaments/{id}", tournamentH.Detail)
+		r.Get("/tournaments/{id}/rounds/{round}", tournamentH.RoundPage)
+		r.Get("/tournaments/{id}/find", tournamentH.FindPairing)
+		r.Get("/tournaments/{id}/display/pairings", tournamentH.DisplayPairings)
+		r.Get("/tournaments/{id}/display/standings", tournamentH.DisplayStandings)
+		r.Get("/tournaments/{id}/results", tournamentH.Results)
+		r.Get("/tournaments/{id}/meta", tournamentH.Meta)
+		r.Get("/leagues", leagueH.List)
+		r.Get("/leagues/{lid}", leagueH.Detail)
+		r.Get("/archive", archiveH.List)
+		r.Get("/archive/{id}", archiveH.Detail)
+		r.Post("/theme", themeH.SetTheme)
+	})
+
+	r.With(c.kiosk...).Group(func(r chi.Router) {
+		r.Get("/t/{id}/kiosk", tournamentH.KioskPage)
+		r.Post("/t/{id}/kiosk", tournamentH.KioskSubmit)
+	})
+
+	r.With(c.signIn...).Group(func(r chi.Router) {
+		r.Get("/login", authH.LoginPage)
+		r.Post("/login", authH.Login)
+	})
+
+	r.With(c.login...).Group(func(r chi.Router) {
+		r.Get("/register", authH.RegisterPage)
+		r.Post("/register", authH.Register)
+		r.Post("/logout", authH.Logout)
+		r.Get("/forgot-password", authH.ForgotPasswordPage)
+		r.Post("/forgot-password", authH.ForgotPassword)
+		r.Get("/reset-password", authH.ResetPasswordPage)
+		r.Post("/reset-password", authH.ResetPassword)
+		r.Get("/verify-email", authH.VerifyEmail)
+		r.Post("/resend-verification", authH.ResendVerification)
+	})
+
+	r.With(c.signedIn...).Group(func(r chi.Router) {
+		r.Get("/dashboard", playerH.Dashboard)
+		r.Get("/profile", playerH.ProfilePage)
+		r.Post("/profile/avatar", playerH.SetAvatar)
+		r.Post("/profile/avatar/remove", playerH.RemoveAvatar)
+		r.Post("/profile/notifications", playerH.SetNotifications)
+		r.Post("/profile/deletion-request", playerH.RequestDeletion)
+		r.Post("/tournaments/{id}/register", tournamentH.Register)
+		r.Post("/tournaments/{id}/unregister", tournamentH.Unregister)
+		r.Post("/tournaments/{id}/contact-consent", tournamentH.SetContactConsent)
+		if tournamentH.Stripe != nil {
+			r.Post("/tournaments/{id}/pay", tournamentH.PayOnline)
+		}
+		r.Post("/tournaments/{id}/drop", tournamentH.RequestDrop)
+		r.Post("/tournaments/{id}/concede", tournamentH.Concede)
+		r.Post("/tournaments/{id}/report", tournamentH.ReportResult)
+		r.Get("/tournaments/{id}/decklist", tournamentH.DecklistPage)
+		r.Post("/tournaments/{id}/decklist", tournamentH.SubmitDecklist)
+		r.Get("/tournaments/{id}/scorecard.pdf", tournamentH.MyScorecard)
+		r.Get("/handoff", staffH.ClaimHandoffPage)
+		r.Post("/handoff", staffH.ClaimHandoff)
+	})
+
+	// Creation, presets, the player registry and leagues require the
+	// global 'organizer' role.
+	r.With(c.organizer...).Group(func(r chi.Router) {
+		r.Get("/tournaments/new", tournamentH.NewPage)
+		r.Post("/tournaments/new", tournamentH.Create)
+
+		r.Get("/players", registryH.ListPage)
+		r.Post("/players", registryH.Create)
+		r.Get("/players/{pid}", registryH.DetailPage)
+		r.Post("/players/{pid}", registryH.Update)
+		r.Post("/players/{pid}/delete", registryH.Delete)
+
+		r.Get("/presets", presetH.ListPage)
+		r.Post("/presets/import", presetH.Import)
+		r.Get("/presets/{pid}/export", presetH.Export)
+		r.Post("/presets/{pid}/delete", presetH.Delete)
+		r.Post("/tournaments/{id}/preset", tournamentH.SavePreset)
+
+		r.Get("/leagues/new", leagueH.NewPage)
+		r.Post("/leagues", leagueH.Create)
+		r.Get("/leagues/{lid}/manage", leagueH.ManagePage)
+		r.Post("/leagues/{lid}/edit", leagueH.Update)
+		r.Post("/leagues/{lid}/tournaments", leagueH.AddTournament)
+		r.Post("/leagues/{lid}/tournaments/{id}/remove", leagueH.RemoveTournament)
+		r.Post("/leagues/{lid}/delete", leagueH.Delete)
+	})
+
+	// Per-tournament management only needs an account; the per-tournament
+	// staff tier (admin / co_organizer / judge) decides access in each
+	// handler.
+	r.With(c.signedIn...).Group(func(r chi.Router) {
+		r.Get("/tournaments/{id}/manage", tournamentH.ManagePage)
+		r.Get("/tournaments/{id}/manage/players", tournamentH.ManagePlayersPage)
+		r.Get("/tournaments/{id}/manage/rounds", tournamentH.ManageRoundsPage)
+		r.Get("/tournaments/{id}/manage/results", tournamentH.ManageResultsPage)
+		r.Get("/tournaments/{id}/manage/quick", tournamentH.QuickEntryPage)
+		r.Get("/tournaments/{id}/manage/fragments/registrations", tournamentH.RegistrationsFragment)
+		r.Get("/tournaments/{id}/manage/fragments/round", tournamentH.RoundFragment)
+		r.Get("/tournaments/{id}/manage/fragments/outstanding", tournamentH.OutstandingFragment)
+		r.Get("/tournaments/{id}/manage/rounds/{round}", tournamentH.ManageRoundPage)
+		r.Post("/tournaments/{id}/rounds/{round}/correct", tournamentH.CorrectResult)
+		r.Get("/tournaments/{id}/scorecards.pdf", tournamentH.Scorecards)
+		r.Get("/tournaments/{id}/export/slips.pdf", tournamentH.ExportSlips)
+		r.Get("/tournaments/{id}/export/seatings.pdf", tournamentH.ExportSeatings)
+		r.Get("/tournaments/{id}/export/standings.pdf", tournamentH.ExportStandings)
+		r.Get("/tournaments/{id}/export/ratings.csv", tournamentH.ExportRatings)
+		r.Get("/tournaments/{id}/export/players.csv", tournamentH.ExportPlayers)
+		r.Get("/tournaments/{id}/export/mailing-list.csv", tournamentH.ExportMailingList)
+		r.Post("/tournaments/{id}/edit", tournamentH.EditTournament)
+		r.Post("/tournaments/{id}/open-registration", tournamentH.OpenRegistration)
+		r.Post("/tournaments/{id}/start", tournamentH.Start)
+		r.Post("/tournaments/{id}/import", tournamentH.Import)
+		r.Post("/tournaments/{id}/results", tournamentH.SubmitResults)
+		r.Post("/tournaments/{id}/quick-results", tournamentH.QuickReport)
+		r.Post("/tournaments/{id}/next-round", tournamentH.NextRound)
+		r.Post("/tournaments/{id}/clock", tournamentH.SetClock)
+		r.Post("/tournaments/{id}/remind", tournamentH.Remind)
+		r.Post("/tournaments/{id}/announcements", tournamentH.Announce)
+		r.Post("/tournaments/{id}/announcements/{announcementID}/end", tournamentH.EndAnnouncement)
+		r.Post("/tournaments/{id}/feature-matches", tournamentH.SetFeatureMatches)
+		r.Get("/tournaments/{id}/re-pair", tournamentH.RepairPreview)
+		r.Post("/tournaments/{id}/re-pair", tournamentH.RepairRound)
+		r.Post("/tournaments/{id}/publish-pairings", tournamentH.PublishPairings)
+		r.Post("/tournaments/{id}/swap-players", tournamentH.SwapPlayers)
+		r.Post("/tournaments/{id}/kiosk", tournamentH.EnableKiosk)
+		r.Post("/tournaments/{id}/kiosk/disable", tournamentH.DisableKiosk)
+		r.Get("/tournaments/{id}/kiosk/slips", tournamentH.KioskSlips)
+		r.Post("/tournaments/{id}/games", tournamentH.ReportGame)
+		r.Post("/tournaments/{id}/games/undo", tournamentH.UndoGame)
+		r.Post("/tournaments/{id}/games/edit", tournamentH.EditGame)
+		r.Post("/tournaments/{id}/reports/accept", tournamentH.AcceptReport)
+		r.Post("/tournaments/{id}/seats", tournamentH.ReportSeats)
+		r.Post("/tournaments/{id}/pairing-fields", tournamentH.AddPairingField)
+		r.Post("/tournaments/{id}/pairing-fields/{fieldID}/remove", tournamentH.RemovePairingField)
+		r.Post("/tournaments/{id}/byes", tournamentH.AssignBye)
+		r.Post("/tournaments/{id}/byes/{round}/{regID}/remove", tournamentH.RemoveBye)
+		r.Get("/tournaments/{id}/webhooks", tournamentH.WebhooksPage)
+		r.Get("/tournaments/{id}/analytics", tournamentH.AnalyticsPage)
+		r.Get("/tournaments/{id}/diagnostics", tournamentH.DiagnosticsPage)
+		r.Post("/tournaments/{id}/webhooks", tournamentH.AddWebhook)
+		r.Post("/tournaments/{id}/webhooks/{webhookID}/remove", tournamentH.RemoveWebhook)
+		r.Get("/tournaments/{id}/snapshots", tournamentH.SnapshotsPage)
+		r.Get("/tournaments/{id}/snapshots/{snapshotID}", tournamentH.DownloadSnapshot)
+		r.Post("/tournaments/{id}/snapshots/{snapshotID}/rollback", tournamentH.RollBack)
+		r.Post("/tournaments/{id}/finish", tournamentH.Finish)
+		r.Post("/tournaments/{id}/add-player", tournamentH.AddPlayer)
+		r.Post("/tournaments/{id}/drop-player", tournamentH.DropPlayer)
+		r.Post("/tournaments/{id}/registrations/accept-all", tournamentH.AcceptAllPending)
+		r.Post("/tournaments/{id}/registrations/reject-all", tournamentH.RejectAllPending)
+		r.Post("/tournaments/{id}/registrations/merge", tournamentH.MergePlayers)
+		r.Post("/tournaments/{id}/registrations/{regID}/distinct", tournamentH.MarkDistinct)
+		r.Post("/tournaments/{id}/registrations/{regID}/rename", tournamentH.RenamePlayer)
+		r.Post("/tournaments/{id}/registrations/{regID}/anonymize", tournamentH.AnonymizePlayer)
+		r.Post("/tournaments/{id}/registrations/{regID}/members", tournamentH.SetTeamMembers)
+		r.Post("/tournaments/{id}/registrations/{regID}/promote", tournamentH.PromoteWaitlisted)
+		r.Post("/tournaments/{id}/registrations/{regID}/payment", tournamentH.SetPayment)
+		r.Post("/tournaments/{id}/pods", tournamentH.AddPod)
+		r.Post("/tournaments/{id}/pods/advance", tournamentH.AdvancePods)
+		r.Post("/tournaments/{id}/pools", tournamentH.SplitPools)
+		r.Post("/tournaments/{id}/share-link", tournamentH.CreateShareLink)
+		r.Post("/tournaments/{id}/share-link/revoke", tournamentH.RevokeShareLink)
+		r.Post("/tournaments/{id}/challonge", tournamentH.PushChallonge)
+		r.Post("/tournaments/{id}/ratings", tournamentH.SetRatings)
+		r.Post("/tournaments/{id}/clubs", tournamentH.SetClubs)
+		r.Post("/tournaments/{id}/schedule", tournamentH.SetSchedule)
+		r.Post("/tournaments/{id}/start-playoff", tournamentH.StartPlayoff)
+		r.Post("/tournaments/{id}/playoff-results", tournamentH.PlayoffResults)
+		r.Post("/tournaments/{id}/next-playoff-round", tournamentH.NextPlayoffRound)
+		r.Get("/tournaments/{id}/registrations/{regID}/decklist", tournamentH.OrganizerDecklistPage)
+		r.Post("/tournaments/{id}/registrations/{regID}/decklist", tournamentH.OrganizerSubmitDecklist)
+
+		r.Get("/tournaments/{id}/staff", staffH.StaffPage)
+		r.Post("/tournaments/{id}/staff", staffH.GrantStaff)
+		r.Post("/tournaments/{id}/staff/{userID}/tier", staffH.UpdateStaffTier)
+		r.Post("/tournaments/{id}/staff/{userID}/remove", staffH.RemoveStaff)
+		r.Post("/tournaments/{id}/handoff", staffH.CreateHandoff)
+	})
+
+	r.With(c.admin...).Group(func(r chi.Router) {
+		r.Get("/admin/users", adminH.UsersPage)
+		r.Post("/admin/users/{id}/role", adminH.UpdateRole)
+		r.Post("/admin/users/{id}/reset-link", adminH.IssueResetLink)
+		r.Post("/admin/users/{id}/anonymize", adminH.AnonymizeUser)
+		r.Post("/admin/users/{id}/delete", adminH.DeleteUser)
+		r.Get("/admin/api-keys", adminH.APIKeysPage)
+		r.Post("/admin/api-keys", adminH.CreateAPIKey)
+		r.Post("/admin/api-keys/{id}/revoke", adminH.RevokeAPIKey)
+		r.Get("/admin/change-password", adminH.ChangePasswordPage)
+		r.Post("/admin/change-password", adminH.ChangePassword)
+		r.Get("/admin/read-only", adminH.ReadOnlyPage)
+		r.Post("/admin/read-only", adminH.SetReadOnly)
+		r.Get("/admin/backup", adminH.BackupPage)
+		r.Get("/admin/backup/{id}", adminH.DownloadBackup)
+		r.Post("/admin/restore", adminH.Restore)
+		r.Get("/admin/qr", adminH.QRPage)
+		r.Get("/admin/qr.svg", adminH.QRCode)
+		r.Get("/admin/integrations", adminH.IntegrationsPage)
+		r.Post("/admin/integrations/challonge", adminH.SetChallongeKey)
+	})
+
+	// REST API (auth by session or API key).
+	r.Route("/api/v1", func(r chi.Router) {
+		r.With(c.api...).Group(func(r chi.Router) {
+			r.Get("/tournaments", tournamentAPI.List)
+			r.Get("/tournaments/{id}", tournamentAPI.Get)
+			r.Get("/tournaments/{id}/players", playersAPI.List)
+			r.Get("/tournaments/{id}/rounds", roundsAPI.ListRounds)
+			r.Get("/tournaments/{id}/rounds/current", roundsAPI.GetCurrentRound)
+			r.Get("/tournaments/{id}/rounds/current/lookup", roundsAPI.LookupPairing)
+			r.Get("/tournaments/{id}/rounds/current/outstanding", remindersAPI.Outstanding)
+			r.Get("/tournaments/{id}/rounds/{round}", roundsAPI.GetRound)
+			r.Get("/tournaments/{id}/standings", roundsAPI.GetStandings)
+			r.Get("/tournaments/{id}/standings/individual", roundsAPI.GetIndividualStandings)
+			r.Get("/tournaments/{id}/standings/combined", tournamentAPI.GetCombinedStandings)
+			r.Get("/tournaments/{id}/pods", tournamentAPI.ListPods)
+			r.Get("/tournaments/{id}/schedule", tournamentAPI.GetSchedule)
+			r.Get("/tournaments/{id}/announcements", tournamentAPI.ListAnnouncements)
+			r.Get("/tournaments/{id}/feature-matches", roundsAPI.GetFeatureMatches)
+			r.Get("/tournaments/{id}/results", roundsAPI.GetResults)
+			r.Get("/tournaments/{id}/meta", roundsAPI.GetMeta)
+			r.Get("/tournaments/{id}/export", roundsAPI.Export)
+			r.Get("/tournaments/{id}/ratings/changes", playersAPI.RatingChanges)
+			r.Get("/tournaments/{id}/corrections", roundsAPI.ListCorrections)
+			r.Get("/tournaments/{id}/games", roundsAPI.Games)
+			r.Get("/tournaments/{id}/playoff", playoffAPI.Get)
+			r.Get("/tournaments/{id}/playoff/rounds/current", playoffAPI.GetCurrentRound)
+			r.Get("/tournaments/{id}/staff", staffAPI.List)
+			r.Get("/tournaments/{id}/discord/pairings", discordAPI.Pairings)
+			r.Get("/tournaments/{id}/discord/standings", discordAPI.Standings)
+			r.Get("/tournaments/{id}/discord/outstanding", discordAPI.Outstanding)
+			r.Get("/tournaments/{id}/discord/announcements", discordAPI.Announcements)
+			r.Get("/leagues", leaguesAPI.List)
+			r.Get("/leagues/{lid}", leaguesAPI.Get)
+			r.Get("/archive", archiveAPI.List)
+			r.Get("/archive/search", archiveAPI.Search)
+			r.Get("/archive/{id}", archiveAPI.Get)
+			// Discord signs its requests; the endpoint only exists once the
+			// application's key is configured.
+			if discordAPI.PublicKey != nil {
+				r.Post("/discord/interactions", discordAPI.Interactions)
+			}
+			// Likewise Stripe's webhook, once Stripe is configured.
+			if stripeAPI.WebhookSecret != "" {
+				r.Post("/stripe/webhook", stripeAPI.Webhook)
+			}
+		})
+
+		r.With(c.apiSignedIn...).Group(func(r chi.Router) {
+			r.Get("/users/me", usersAPI.GetMe)
+			r.Put("/users/me/notifications", usersAPI.SetNotifications)
+			r.Post("/users/me/deletion-request", usersAPI.RequestDeletion)
+			r.Delete("/users/me/deletion-request", usersAPI.RequestDeletion)
+			r.Post("/users/me/api-keys", usersAPI.CreateAPIKey)
+			r.Get("/users/me/api-keys", usersAPI.ListAPIKeys)
+			r.Delete("/users/me/api-keys/{id}", usersAPI.DeleteAPIKey)
+
+			r.Post("/tournaments/{id}/players", playersAPI.Register)
+			r.Delete("/tournaments/{id}/players/me", playersAPI.Unregister)
+			r.Put("/tournaments/{id}/players/me/contact-consent", playersAPI.SetContactConsent)
+			r.Get("/tournaments/{id}/players/me/decklist", playersAPI.GetDecklist)
+			r.Put("/tournaments/{id}/players/me/decklist", playersAPI.SubmitDecklist)
+			r.Put("/tournaments/{id}/players/me/result", playersAPI.ReportResult)
+		})
+
+		r.With(c.apiOrganizer...).Group(func(r chi.Router) {
+			r.Post("/tournaments", tournamentAPI.Create)
+
+			r.Get("/players", registryAPI.List)
+			r.Post("/players", registryAPI.Create)
+			r.Get("/players/{pid}", registryAPI.Get)
+			r.Put("/players/{pid}", registryAPI.Update)
+			r.Delete("/players/{pid}", registryAPI.Delete)
+
+			r.Get("/presets", presetsAPI.List)
+			r.Post("/presets", presetsAPI.Import)
+			r.Get("/presets/{pid}", presetsAPI.Get)
+			r.Delete("/presets/{pid}", presetsAPI.Delete)
+			r.Post("/tournaments/{id}/preset", presetsAPI.Save)
+
+			r.Post("/leagues", leaguesAPI.Create)
+			r.Put("/leagues/{lid}", leaguesAPI.Update)
+			r.Delete("/leagues/{lid}", leaguesAPI.Delete)
+			r.Put("/leagues/{lid}/tournaments/{id}", leaguesAPI.AddTournament)
+			r.Delete("/leagues/{lid}/tournaments/{id}", leaguesAPI.RemoveTournament)
+		})
+
+		// Per-tournament management, with access decided by the staff tier
+		// as on the web.
+		r.With(c.apiSignedIn...).Group(func(r chi.Router) {
+			r.Patch("/tournaments/{id}", tournamentAPI.Update)
+			r.Delete("/tournaments/{id}", tournamentAPI.Delete)
+			r.Post("/tournaments/{id}/open-registration", tournamentAPI.OpenRegistration)
+			r.Get("/tournaments/{id}/can-start", tournamentAPI.CanStart)
+			r.Post("/tournaments/{id}/start", tournamentAPI.Start)
+			r.Post("/tournaments/{id}/import", tournamentAPI.Import)
+			r.Post("/tournaments/{id}/finish", tournamentAPI.Finish)
+			r.Get("/tournaments/{id}/lifecycle", lifecycleAPI.Get)
+			r.Post("/tournaments/{id}/lifecycle/{action}", lifecycleAPI.Run)
+			r.Post("/tournaments/{id}/pods", tournamentAPI.CreatePod)
+			r.Post("/tournaments/{id}/pods/advance", tournamentAPI.AdvancePods)
+			r.Post("/tournaments/{id}/pools", tournamentAPI.SplitPools)
+			r.Get("/tournaments/{id}/share-link", tournamentAPI.GetShareLink)
+			r.Post("/tournaments/{id}/share-link", tournamentAPI.CreateShareLink)
+			r.Delete("/tournaments/{id}/share-link", tournamentAPI.RevokeShareLink)
+			r.Put("/tournaments/{id}/schedule", tournamentAPI.SetSchedule)
+
+			r.Post("/tournaments/{id}/players/add", playersAPI.AddPlayer)
+			r.Post("/tournaments/{id}/players/{pid}/drop", playersAPI.DropPlayer)
+			r.Post("/tournaments/{id}/registrations/accept-all", playersAPI.AcceptAllPending)
+			r.Post("/tournaments/{id}/registrations/reject-all", playersAPI.RejectAllPending)
+			r.Get("/tournaments/{id}/players/{pid}/decklist", playersAPI.GetPlayerDecklist)
+			r.Get("/tournaments/{id}/registrations/{regID}/decklist", playersAPI.GetRegistrationDecklist)
+			r.Put("/tournaments/{id}/registrations/{regID}/decklist", playersAPI.SetRegistrationDecklist)
+			r.Put("/tournaments/{id}/registrations/{regID}/name", playersAPI.RenamePlayer)
+			r.Post("/tournaments/{id}/registrations/{regID}/anonymize", playersAPI.AnonymizePlayer)
+			r.Put("/tournaments/{id}/registrations/{regID}/members", playersAPI.SetTeamMembers)
+			r.Post("/tournaments/{id}/registrations/{regID}/promote", playersAPI.PromoteWaitlisted)
+			r.Put("/tournaments/{id}/registrations/{regID}/payment", playersAPI.SetPayment)
+			r.Post("/tournaments/{id}/registrations/merge", playersAPI.MergePlayers)
+			r.Get("/tournaments/{id}/registrations/duplicates", playersAPI.PossibleDuplicates)
+			r.Post("/tournaments/{id}/registrations/{regID}/distinct", playersAPI.MarkDistinct)
+			r.Put("/tournaments/{id}/ratings", playersAPI.SetRatings)
+			r.Put("/tournaments/{id}/clubs", playersAPI.SetClubs)
+
+			r.Post("/tournaments/{id}/rounds/current/results", roundsAPI.SubmitResults)
+			r.Post("/tournaments/{id}/rounds/next", roundsAPI.NextRound)
+			r.Put("/tournaments/{id}/rounds/current/clock", remindersAPI.SetClock)
+			r.Delete("/tournaments/{id}/rounds/current/clock", remindersAPI.StopClock)
+			r.Post("/tournaments/{id}/rounds/current/remind", remindersAPI.Remind)
+			r.Post("/tournaments/{id}/announcements", tournamentAPI.Announce)
+			r.Post("/tournaments/{id}/announcements/{announcementID}/end", tournamentAPI.EndAnnouncement)
+			r.Put("/tournaments/{id}/feature-matches", roundsAPI.SetFeatureMatches)
+			r.Get("/tournaments/{id}/rounds/current/repair-preview", roundsAPI.RepairPreview)
+			r.Post("/tournaments/{id}/rounds/current/repair", roundsAPI.Repair)
+			r.Post("/tournaments/{id}/rounds/current/pairings/{table}/games", roundsAPI.ReportGame)
+			r.Delete("/tournaments/{id}/rounds/current/pairings/{table}/games/last", roundsAPI.UndoGame)
+			r.Put("/tournaments/{id}/rounds/current/pairings/{table}/games/{game}", roundsAPI.EditGame)
+			r.Get("/tournaments/{id}/rounds/current/reports", roundsAPI.Reports)
+			r.Post("/tournaments/{id}/rounds/current/pairings/{table}/reports/{side}/accept", roundsAPI.AcceptReport)
+			r.Put("/tournaments/{id}/rounds/current/pairings/{table}/seats/{seat}", roundsAPI.ReportSeat)
+			r.Put("/tournaments/{id}/rounds/current/pairings/{table}/fields", pairingFieldsAPI.SetValues)
+			r.Post("/tournaments/{id}/rounds/{round}/pairings/{table}/correction", roundsAPI.CorrectResult)
+
+			r.Get("/tournaments/{id}/pairing-fields", pairingFieldsAPI.List)
+			r.Post("/tournaments/{id}/pairing-fields", pairingFieldsAPI.Create)
+			r.Delete("/tournaments/{id}/pairing-fields/{fieldID}", pairingFieldsAPI.Delete)
+
+			r.Get("/tournaments/{id}/byes", byesAPI.List)
+			r.Post("/tournaments/{id}/byes", byesAPI.Assign)
+			r.Delete("/tournaments/{id}/byes/{round}/{regID}", byesAPI.Remove)
+
+			r.Get("/tournaments/{id}/webhooks", webhooksAPI.List)
+			r.Get("/tournaments/{id}/analytics", analyticsAPI.Get)
+			r.Get("/tournaments/{id}/diagnostics", roundsAPI.Diagnostics)
+			r.Post("/tournaments/{id}/webhooks", webhooksAPI.Create)
+			r.Delete("/tournaments/{id}/webhooks/{webhookID}", webhooksAPI.Delete)
+
+			r.Get("/tournaments/{id}/snapshots", snapshotsAPI.List)
+			r.Get("/tournaments/{id}/snapshots/{snapshotID}", snapshotsAPI.Get)
+			r.Post("/tournaments/{id}/snapshots/{snapshotID}/rollback", snapshotsAPI.RollBack)
+
+			r.Post("/tournaments/{id}/playoff/start", playoffAPI.Start)
+			r.Post("/tournaments/{id}/playoff/rounds/current/results", playoffAPI.SubmitResults)
+			r.Post("/tournaments/{id}/playoff/rounds/next", playoffAPI.NextRound)
+
+			r.Post("/tournaments/{id}/staff", staffAPI.Grant)
+			r.Get("/tournaments/{id}/staff/search", staffAPI.Search)
+			r.Patch("/tournaments/{id}/staff/{userID}", staffAPI.UpdateTier)
+			r.Delete("/tournaments/{id}/staff/{userID}", staffAPI.Remove)
+		})
+
+		r.With(c.apiAdmin...).Group(func(r chi.Router) {
+			r.Get("/admin/users", adminAPI.ListUsers)
+			r.Patch("/admin/users/{id}", adminAPI.UpdateUser)
+			r.Delete("/admin/users/{id}", adminAPI.DeleteUser)
+			r.Post("/admin/users/{id}/anonymize", adminAPI.AnonymizeUser)
+			r.Post("/admin/users/{id}/reset-link", adminAPI.IssueResetLink)
+			r.Post("/admin/users/{id}/api-keys", adminAPI.CreateAPIKey)
+			r.Get("/admin/api-keys", adminAPI.ListAPIKeys)
+			r.Delete("/admin/api-keys/{id}", adminAPI.RevokeAPIKey)
+			r.Get("/admin/read-only", adminAPI.GetReadOnly)
+			r.Post("/admin/read-only", adminAPI.SetReadOnly)
+			r.Get("/admin/backup/{id}", adminAPI.Backup)
+			r.Post("/admin/restore", adminAPI.Restore)
+		})
+	})
+	return r, nil
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/metrics"
+	"github.com/dstathis/openswiss/internal/readonly"
+	"github.com/dstathis/openswiss/internal/stripe"
+)
+
+// TestRoutes_Chains checks that each group's routes get its chain. No
+// request here gets as far as the database.
+func TestRoutes_Chains(t *testing.T) {
+	tmpl, err := loadTemplates(templateFS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &server{
+		renderer:  &namedTemplate{root: tmpl},
+		readOnly:  readonly.New(readonly.Config{}),
+		collector: metrics.New(),
+		cfg:       &config{RateLimit: 60, AuthRateLimit: 10},
+	}
+	h, err := s.routes()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		method, path string
+		status       int
+		location     string
+	}{
+		{"GET", "/healthz", http.StatusOK, ""},
+		{"HEAD", "/healthz", http.StatusOK, ""},
+		{"DELETE", "/healthz", http.StatusMethodNotAllowed, ""},
+		{"GET", "/dashboard", http.StatusSeeOther, "/login"},
+		{"GET", "/profile", http.StatusSeeOther, "/login"},
+		{"GET", "/avatars/x", http.StatusNotFound, ""},
+		{"GET", "/tournaments/x/overlay/standings", http.StatusNotFound, ""},
+		{"GET", "/tournaments/new", http.StatusSeeOther, "/login"},
+		{"GET", "/presets", http.StatusSeeOther, "/login"},
+		{"GET", "/tournaments/1/manage", http.StatusSeeOther, "/login"},
+		{"GET", "/tournaments/1/analytics", http.StatusSeeOther, "/login"},
+		{"GET", "/tournaments/1/diagnostics", http.StatusSeeOther, "/login"},
+		{"GET", "/tournaments/1/manage/quick", http.StatusSeeOther, "/login"},
+		{"GET", "/tournaments/1/export/mailing-list.csv", http.StatusSeeOther, "/login"},
+		{"DELETE", "/archive", http.StatusMethodNotAllowed, ""},
+		{"GET", "/admin/users", http.StatusSeeOther, "/login"},
+		// Web forms check the CSRF token before anything else.
+		{"POST", "/tournaments/1/start", http.StatusForbidden, ""},
+		{"GET", "/api/v1/users/me", http.StatusUnauthorized, ""},
+		{"POST", "/api/v1/tournaments", http.StatusUnauthorized, ""},
+		{"POST", "/api/v1/tournaments/1/start", http.StatusUnauthorized, ""},
+		{"POST", "/api/v1/tournaments/1/pools", http.StatusUnauthorized, ""},
+		{"PUT", "/api/v1/tournaments/1/players/me/result", http.StatusUnauthorized, ""},
+		{"GET", "/api/v1/tournaments/1/rounds/current/reports", http.StatusUnauthorized, ""},
+		{"POST", "/api/v1/tournaments/1/announcements", http.StatusUnauthorized, ""},
+		{"PUT", "/api/v1/tournaments/1/feature-matches", http.StatusUnauthorized, ""},
+		{"GET", "/api/v1/admin/users", http.StatusUnauthorized, ""},
+		{"DELETE", "/api/v1/admin/users/1", http.StatusUnauthorized, ""},
+		{"GET", "/api/v1/tournaments/1/analytics", http.StatusUnauthorized, ""},
+		{"GET", "/api/v1/tournaments/1/diagnostics", http.StatusUnauthorized, ""},
+		// Without a public key there is no interactions endpoint.
+		{"POST", "/api/v1/discord/interactions", http.StatusNotFound, ""},
+		// Nor, without Stripe, a webhook or online payments.
+		{"POST", "/api/v1/stripe/webhook", http.StatusNotFound, ""},
+		{"POST", "/tournaments/1/pay", http.StatusNotFound, ""},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, nil))
+		if rec.Code != tt.status {
+			t.Errorf("%s %s: status %d, want %d", tt.method, tt.path, rec.Code, tt.status)
+		}
+		if loc := rec.Header().Get("Location"); loc != tt.location {
+			t.Errorf("%s %s: Location %q, want %q", tt.method, tt.path, loc, tt.location)
+		}
+		if rec.Header().Get("X-Request-Id") == "" {
+			t.Errorf("%s %s: no X-Request-Id; the base chain was skipped", tt.method, tt.path)
+		}
+	}
+
+	// With an admin allowlist, other addresses can't reach the admin
+	// pages or sign in, but can still register and browse.
+	s.cfg.AdminAllowlist = []*net.IPNet{{IP: net.IPv4(10, 0, 0, 0), Mask: net.CIDRMask(8, 32)}}
+	if h, err = s.routes(); err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		remote, path string
+		status       int
+	}{
+		{"192.0.2.1:1234", "/admin/users", http.StatusForbidden},
+		{"192.0.2.1:1234", "/api/v1/admin/users", http.StatusForbidden},
+		{"192.0.2.1:1234", "/login", http.StatusForbidden},
+		{"192.0.2.1:1234", "/register", http.StatusOK},
+		{"10.1.2.3:1234", "/admin/users", http.StatusSeeOther},
+		{"10.1.2.3:1234", "/api/v1/admin/users", http.StatusUnauthorized},
+		{"10.1.2.3:1234", "/login", http.StatusOK},
+	} {
+		req := httptest.NewRequest("GET", tt.path, nil)
+		req.RemoteAddr = tt.remote
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != tt.status {
+			t.Errorf("GET %s from %s: status %d, want %d", tt.path, tt.remote, rec.Code, tt.status)
+		}
+	}
+	s.cfg.AdminAllowlist = nil
+
+	// With Stripe configured the webhook exists and checks its signature.
+	s.cfg.Stripe = stripe.Config{SecretKey: "sk_test", WebhookSecret: "whsec", Currency: "usd"}
+	if h, err = s.routes(); err != nil {
+		t.Fatal(err)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("POST", "/api/v1/stripe/webhook", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("unsigned Stripe webhook: status %d, want 401", rec.Code)
+	}
+}
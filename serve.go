@@ -12,285 +12,136 @@ import (
 	"os"
 	"os/signal"
 	"path"
-	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
-	"github.com/go-chi/chi/v5"
-
-	"github.com/dstathis/openswiss/internal/api"
+	"github.com/dstathis/openswiss/internal/analytics"
 	"github.com/dstathis/openswiss/internal/email"
-	"github.com/dstathis/openswiss/internal/handlers"
+	"github.com/dstathis/openswiss/internal/engine"
+	"github.com/dstathis/openswiss/internal/i18n"
 	"github.com/dstathis/openswiss/internal/metrics"
-	mw "github.com/dstathis/openswiss/internal/middleware"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/dstathis/openswiss/internal/readonly"
+	"github.com/dstathis/openswiss/internal/webhook"
 )
 
-func runServe(_ []string) {
-	dsn := mustEnv("DATABASE_URL")
-	listen := getenv("LISTEN_ADDR", ":8080")
-	secureCookies := getenv("SECURE_COOKIES", "true") != "false"
-	baseURL := getenv("BASE_URL", "http://localhost:8080")
-	rateLimit, _ := strconv.Atoi(getenv("RATE_LIMIT_PER_MIN", "60"))
-	if rateLimit <= 0 {
-		rateLimit = 60
-	}
-	authRateLimit, _ := strconv.Atoi(getenv("AUTH_RATE_LIMIT_PER_MIN", "10"))
-	if authRateLimit <= 0 {
-		authRateLimit = 10
-	}
-	trustedProxies, err := mw.ParseTrustedProxies(os.Getenv("TRUSTED_PROXIES"))
-	if err != nil {
-		fatal("invalid TRUSTED_PROXIES", "err", err)
-	}
+func runServe(args []string) {
+	cfg := mustLoadConfig("serve", args)
 
-	database, err := openDB(dsn)
+	database, err := openDB(cfg.DatabaseURL)
 	if err != nil {
 		fatal("connect db", "err", err)
 	}
 	defer database.Close()
+	if err := bootstrapAdmin(context.Background(), database, cfg); err != nil {
+		fatal("bootstrap admin", "err", err)
+	}
 
-	tmpl, err := loadTemplates(templateFS)
+	// Read-only mode refuses writes while the database misbehaves or an
+	// admin has switched it on; public pages keep serving their last copy.
+	readOnly := readonly.New(readonly.Config{
+		Exempt: []string{"/login", "/logout", "/admin/read-only", "/api/v1/admin/read-only", "/api/v1/discord/interactions"},
+		Cached: []string{"/", "/tournaments", "/api/v1/tournaments"},
+	})
+	if cfg.ReadOnly {
+		readOnly.Set(true, cfg.ReadOnlyReason)
+	}
+
+	// In dev mode the templates are read from the working directory, a
+	// checkout, and parsed again for every page so edits show on reload.
+	tplFS := fs.FS(templateFS)
+	if cfg.Dev {
+		tplFS = os.DirFS(".")
+	}
+	load := func() (map[string]*template.Template, error) {
+		tmpl, err := loadTemplates(tplFS)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range tmpl {
+			t.Funcs(template.FuncMap{"readOnlyReason": readOnly.Reason})
+		}
+		return tmpl, nil
+	}
+	tmpl, err := load()
 	if err != nil {
 		fatal("templates", "err", err)
 	}
 	renderer := &namedTemplate{root: tmpl}
+	if cfg.Dev {
+		renderer.reload = load
+		slog.Warn("dev mode: templates and static files are read from disk on every request")
+	}
 
-	emailSender := &email.Sender{Config: email.Config{
-		Host:     os.Getenv("SMTP_HOST"),
-		Port:     getenv("SMTP_PORT", "587"),
-		User:     os.Getenv("SMTP_USER"),
-		Password: os.Getenv("SMTP_PASSWORD"),
-		From:     os.Getenv("SMTP_FROM"),
-	}}
-
-	tournamentH := &handlers.TournamentHandler{DB: database, Tmpl: renderer}
-	authH := &handlers.AuthHandler{DB: database, Tmpl: renderer, Email: emailSender, BaseURL: baseURL, SecureCookies: secureCookies}
-	playerH := &handlers.PlayerHandler{DB: database, Tmpl: renderer}
-	adminH := &handlers.AdminHandler{DB: database, Tmpl: renderer}
-	staffH := &handlers.StaffHandler{DB: database, Tmpl: renderer, Email: emailSender, BaseURL: baseURL}
-
-	tournamentAPI := &api.TournamentAPI{DB: database}
-	playersAPI := &api.PlayersAPI{DB: database}
-	roundsAPI := &api.RoundsAPI{DB: database}
-	playoffAPI := &api.PlayoffAPI{DB: database}
-	usersAPI := &api.UsersAPI{DB: database}
-	adminAPI := &api.AdminAPI{DB: database}
-	staffAPI := &api.StaffAPI{DB: database, Email: emailSender, BaseURL: baseURL}
-
-	collector := metrics.New()
-
-	r := chi.NewRouter()
-	// RequestID is outermost so the request ID is in context for any log line
-	// emitted by Recover or anything downstream.
-	r.Use(mw.RequestID)
-	// Recover catches panics in any later middleware or handler so a single
-	// bad request doesn't kill the process.
-	r.Use(mw.Recover)
-	// RealIP must come before any middleware that keys on the client IP
-	// (rate limiter, future audit logging) so they all see the same value.
-	r.Use(mw.RealIP(trustedProxies))
-	r.Use(mw.SecureHeaders(secureCookies))
-	r.Use(collector.Wrap)
-	r.Use(mw.MaxBodySize(2 << 20))
-	r.Use(mw.SessionAuth(database))
-	r.Use(mw.APIKeyAuth(database))
-
-	staticSub, err := fs.Sub(staticFS, "static")
+	emailSender := &email.Sender{Config: cfg.SMTP}
+	// Page views are counted in memory and saved once a minute.
+	views := analytics.New(database, time.Minute)
+
+	s := &server{
+		db:        database,
+		renderer:  renderer,
+		readOnly:  readOnly,
+		collector: metrics.New(),
+		email:     emailSender,
+		views:     views,
+		cfg:       cfg,
+	}
+	handler, err := s.routes()
 	if err != nil {
-		fatal("static fs", "err", err)
+		fatal("routes", "err", err)
 	}
-	r.Handle("/static/*", http.StripPrefix("/static/", http.FileServer(http.FS(staticSub))))
-	r.Get("/metrics", collector.Handler())
-
-	// Liveness: process is up. Used by container orchestrators to decide
-	// whether to restart the pod.
-	r.Get("/healthz", func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
-		_, _ = w.Write([]byte("ok"))
-	})
-	// Readiness: process is up AND the database is reachable. Used to gate
-	// load-balancer traffic. Pings with a short context so a flaky DB doesn't
-	// hang the probe.
-	r.Get("/readyz", func(w http.ResponseWriter, r *http.Request) {
-		ctx, cancel := context.WithTimeout(r.Context(), 2*time.Second)
-		defer cancel()
-		if err := database.PingContext(ctx); err != nil {
-			http.Error(w, "db unavailable", http.StatusServiceUnavailable)
-			return
-		}
-		w.WriteHeader(http.StatusOK)
-		_, _ = w.Write([]byte("ok"))
-	})
-
-	// Public web routes (CSRF-protected for state-changing requests).
-	r.Group(func(r chi.Router) {
-		r.Use(mw.CSRFProtect(secureCookies))
-
-		r.Get("/", tournamentH.Home)
-		r.Get("/tournaments", tournamentH.List)
-		r.Get("/tournaments/{id}", tournamentH.Detail)
-
-		// Auth endpoints get an aggressive per-IP rate limit on top of the
-		// per-account lockout enforced inside the Login handler. Together
-		// they bound credential-stuffing throughput from any single source.
-		r.Group(func(r chi.Router) {
-			r.Use(mw.RateLimit(authRateLimit))
-
-			r.Get("/login", authH.LoginPage)
-			r.Post("/login", authH.Login)
-			r.Get("/register", authH.RegisterPage)
-			r.Post("/register", authH.Register)
-			r.Post("/logout", authH.Logout)
-			r.Get("/forgot-password", authH.ForgotPasswordPage)
-			r.Post("/forgot-password", authH.ForgotPassword)
-			r.Get("/reset-password", authH.ResetPasswordPage)
-			r.Post("/reset-password", authH.ResetPassword)
-			r.Get("/verify-email", authH.VerifyEmail)
-			r.Post("/resend-verification", authH.ResendVerification)
-		})
-
-		r.Group(func(r chi.Router) {
-			r.Use(mw.RequireAuth)
-
-			r.Get("/dashboard", playerH.Dashboard)
-			r.Post("/tournaments/{id}/register", tournamentH.Register)
-			r.Post("/tournaments/{id}/unregister", tournamentH.Unregister)
-			r.Post("/tournaments/{id}/drop", tournamentH.RequestDrop)
-			r.Get("/tournaments/{id}/decklist", tournamentH.DecklistPage)
-			r.Post("/tournaments/{id}/decklist", tournamentH.SubmitDecklist)
-		})
-
-		// Creation requires the global 'organizer' role; per-tournament
-		// management routes only require auth and let the per-tournament
-		// staff tier (admin / co_organizer / judge) decide access.
-		r.Group(func(r chi.Router) {
-			r.Use(mw.RequireAuth)
-			r.Use(mw.RequireRole("organizer"))
-
-			r.Get("/tournaments/new", tournamentH.NewPage)
-			r.Post("/tournaments/new", tournamentH.Create)
-		})
-
-		r.Group(func(r chi.Router) {
-			r.Use(mw.RequireAuth)
-
-			r.Get("/tournaments/{id}/manage", tournamentH.ManagePage)
-			r.Post("/tournaments/{id}/edit", tournamentH.EditTournament)
-			r.Post("/tournaments/{id}/open-registration", tournamentH.OpenRegistration)
-			r.Post("/tournaments/{id}/start", tournamentH.Start)
-			r.Post("/tournaments/{id}/results", tournamentH.SubmitResults)
-			r.Post("/tournaments/{id}/next-round", tournamentH.NextRound)
-			r.Post("/tournaments/{id}/re-pair", tournamentH.RepairRound)
-			r.Post("/tournaments/{id}/finish", tournamentH.Finish)
-			r.Post("/tournaments/{id}/add-player", tournamentH.AddPlayer)
-			r.Post("/tournaments/{id}/drop-player", tournamentH.DropPlayer)
-			r.Post("/tournaments/{id}/start-playoff", tournamentH.StartPlayoff)
-			r.Post("/tournaments/{id}/playoff-results", tournamentH.PlayoffResults)
-			r.Post("/tournaments/{id}/next-playoff-round", tournamentH.NextPlayoffRound)
-			r.Get("/tournaments/{id}/registrations/{regID}/decklist", tournamentH.OrganizerDecklistPage)
-			r.Post("/tournaments/{id}/registrations/{regID}/decklist", tournamentH.OrganizerSubmitDecklist)
-
-			r.Get("/tournaments/{id}/staff", staffH.StaffPage)
-			r.Post("/tournaments/{id}/staff", staffH.GrantStaff)
-			r.Post("/tournaments/{id}/staff/{userID}/tier", staffH.UpdateStaffTier)
-			r.Post("/tournaments/{id}/staff/{userID}/remove", staffH.RemoveStaff)
-		})
-
-		r.Group(func(r chi.Router) {
-			r.Use(mw.RequireAuth)
-			r.Use(mw.RequireRole("admin"))
-
-			r.Get("/admin/users", adminH.UsersPage)
-			r.Post("/admin/users/{id}/role", adminH.UpdateRole)
-		})
-	})
-
-	// REST API (no CSRF; rate-limited; auth by session or API key).
-	r.Route("/api/v1", func(r chi.Router) {
-		r.Use(mw.RateLimit(rateLimit))
-
-		// Public
-		r.Get("/tournaments", tournamentAPI.List)
-		r.Get("/tournaments/{id}", tournamentAPI.Get)
-		r.Get("/tournaments/{id}/players", playersAPI.List)
-		r.Get("/tournaments/{id}/rounds", roundsAPI.ListRounds)
-		r.Get("/tournaments/{id}/rounds/current", roundsAPI.GetCurrentRound)
-		r.Get("/tournaments/{id}/rounds/{round}", roundsAPI.GetRound)
-		r.Get("/tournaments/{id}/standings", roundsAPI.GetStandings)
-		r.Get("/tournaments/{id}/playoff", playoffAPI.Get)
-		r.Get("/tournaments/{id}/playoff/rounds/current", playoffAPI.GetCurrentRound)
-		r.Get("/tournaments/{id}/staff", staffAPI.List)
-
-		// Authenticated (session or API key)
-		r.Group(func(r chi.Router) {
-			r.Use(mw.RequireAuth)
-
-			r.Get("/users/me", usersAPI.GetMe)
-			r.Post("/users/me/api-keys", usersAPI.CreateAPIKey)
-			r.Get("/users/me/api-keys", usersAPI.ListAPIKeys)
-			r.Delete("/users/me/api-keys/{id}", usersAPI.DeleteAPIKey)
-
-			r.Post("/tournaments/{id}/players", playersAPI.Register)
-			r.Delete("/tournaments/{id}/players/me", playersAPI.Unregister)
-			r.Get("/tournaments/{id}/players/me/decklist", playersAPI.GetDecklist)
-			r.Put("/tournaments/{id}/players/me/decklist", playersAPI.SubmitDecklist)
-
-			// Creation requires the global 'organizer' role.
-			r.Group(func(r chi.Router) {
-				r.Use(mw.RequireRole("organizer"))
-
-				r.Post("/tournaments", tournamentAPI.Create)
-			})
-
-			// Per-tournament management. The per-tournament staff tier
-			// (admin / co_organizer / judge) decides access.
-			r.Patch("/tournaments/{id}", tournamentAPI.Update)
-			r.Delete("/tournaments/{id}", tournamentAPI.Delete)
-			r.Post("/tournaments/{id}/open-registration", tournamentAPI.OpenRegistration)
-			r.Post("/tournaments/{id}/start", tournamentAPI.Start)
-			r.Post("/tournaments/{id}/finish", tournamentAPI.Finish)
-
-			r.Post("/tournaments/{id}/players/add", playersAPI.AddPlayer)
-			r.Post("/tournaments/{id}/players/{pid}/drop", playersAPI.DropPlayer)
-			r.Get("/tournaments/{id}/players/{pid}/decklist", playersAPI.GetPlayerDecklist)
-			r.Get("/tournaments/{id}/registrations/{regID}/decklist", playersAPI.GetRegistrationDecklist)
-			r.Put("/tournaments/{id}/registrations/{regID}/decklist", playersAPI.SetRegistrationDecklist)
-
-			r.Post("/tournaments/{id}/rounds/current/results", roundsAPI.SubmitResults)
-			r.Post("/tournaments/{id}/rounds/next", roundsAPI.NextRound)
-
-			r.Post("/tournaments/{id}/playoff/start", playoffAPI.Start)
-			r.Post("/tournaments/{id}/playoff/rounds/current/results", playoffAPI.SubmitResults)
-			r.Post("/tournaments/{id}/playoff/rounds/next", playoffAPI.NextRound)
-
-			r.Post("/tournaments/{id}/staff", staffAPI.Grant)
-			r.Get("/tournaments/{id}/staff/search", staffAPI.Search)
-			r.Patch("/tournaments/{id}/staff/{userID}", staffAPI.UpdateTier)
-			r.Delete("/tournaments/{id}/staff/{userID}", staffAPI.Remove)
-
-			// Admin-only
-			r.Group(func(r chi.Router) {
-				r.Use(mw.RequireRole("admin"))
-
-				r.Get("/admin/users", adminAPI.ListUsers)
-				r.Patch("/admin/users/{id}", adminAPI.UpdateUser)
-			})
-		})
-	})
 
 	srv := &http.Server{
-		Addr:              listen,
-		Handler:           r,
+		Addr:              cfg.ListenAddr,
+		Handler:           handler,
 		ReadHeaderTimeout: 10 * time.Second,
 		ReadTimeout:       30 * time.Second,
 		WriteTimeout:      60 * time.Second,
 		IdleTimeout:       120 * time.Second,
 	}
 
+	// Webhook deliveries are queued in the database by the handlers and sent
+	// from here, off the request path.
+	dispatchCtx, stopDispatch := context.WithCancel(context.Background())
+	dispatcher := &webhook.Dispatcher{
+		DB:       database,
+		Client:   webhook.NewClient(cfg.WebhookAllowPrivate),
+		Interval: 2 * time.Second,
+	}
+	dispatchDone := make(chan struct{})
+	go func() {
+		dispatcher.Run(dispatchCtx)
+		close(dispatchDone)
+	}()
+	// The probe shares the dispatcher's lifetime.
+	go readOnly.Probe(dispatchCtx, database, 10*time.Second)
+	if cfg.SnapshotInterval > 0 {
+		snapshotter := &engine.Snapshotter{DB: database, Interval: cfg.SnapshotInterval, Keep: cfg.SnapshotKeep}
+		go snapshotter.Run(dispatchCtx)
+	}
+	// Round clocks about to run out remind their tables to report.
+	reminder := &engine.Reminder{DB: database, Email: emailSender, BaseURL: cfg.BaseURL, Interval: 30 * time.Second}
+	go reminder.Run(dispatchCtx)
+	// Lone player reports count once their tournament's wait is over.
+	reportLocker := &engine.ReportLocker{DB: database, Interval: 30 * time.Second}
+	go reportLocker.Run(dispatchCtx)
+	viewsDone := make(chan struct{})
+	go func() {
+		views.Run(dispatchCtx)
+		close(viewsDone)
+	}()
+
 	serverErr := make(chan error, 1)
 	go func() {
-		slog.Info("openswiss listening", "addr", listen)
-		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		slog.Info("openswiss listening", "addr", cfg.ListenAddr, "tls", cfg.TLSCertFile != "")
+		var err error
+		if cfg.TLSCertFile != "" {
+			err = srv.ListenAndServeTLS(cfg.TLSCertFile, cfg.TLSKeyFile)
+		} else {
+			err = srv.ListenAndServe()
+		}
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
 			serverErr <- err
 		}
 		close(serverErr)
@@ -313,12 +164,34 @@ func runServe(_ []string) {
 	if err := srv.Shutdown(shutdownCtx); err != nil {
 		slog.Error("graceful shutdown failed", "err", err)
 	}
+	// Deliveries cut off here are retried on the next start.
+	stopDispatch()
+	<-dispatchDone
+	// The last page views are saved on the way out.
+	<-viewsDone
 }
 
-// templateFuncs are exposed to all templates.
-func templateFuncs() template.FuncMap {
+// templateFuncs are exposed to all templates; t, date and lang work in
+// the locale lang the template set is parsed for.
+func templateFuncs(lang string) template.FuncMap {
 	return template.FuncMap{
-		"add": func(a, b int) int { return a + b },
+		"t": func(msg string, args ...interface{}) string {
+			return i18n.T(lang, msg, args...)
+		},
+		"date": func(t time.Time) string { return i18n.FormatDateTime(lang, t) },
+		// zoned shows t in time zone tz, naming the zone; app.js then
+		// rewrites <time class="local-time"> in the viewer's own zone.
+		"zoned": func(t time.Time, tz string) string {
+			t = inZone(t, tz)
+			return i18n.FormatDateTime(lang, t) + " " + t.Format("MST")
+		},
+		"inZone": inZone,
+		"utc":    func(t time.Time) string { return t.UTC().Format(time.RFC3339) },
+		"countdown": func(t time.Time) string {
+			return countdown(time.Until(t))
+		},
+		"lang": func() string { return lang },
+		"add":  func(a, b int) int { return a + b },
 		"deref": func(v interface{}) interface{} {
 			switch p := v.(type) {
 			case *string:
@@ -346,12 +219,61 @@ func templateFuncs() template.FuncMap {
 			return *p
 		},
 		"mul100": func(v float64) float64 { return v * 100 },
+		// field gives form field name its accessibility attributes (see
+		// fieldAttrs).
+		"field": fieldAttrs,
+		// amount shows minor units (an entry fee or payment) as 10.50.
+		"amount": models.FormatAmount,
+		// avatarOf looks up an engine player's avatar in a page's Avatars
+		// (db.TournamentAvatars); nil if they have none or the page has no
+		// avatars. avatarIcons are the icons a player can pick.
+		"avatarOf":    func(avatars map[int]*models.Avatar, id int) *models.Avatar { return avatars[id] },
+		"avatarIcons": func() []models.AvatarIcon { return models.AvatarIcons },
+		// tiebreak and tiebreakLabel show a standing's tiebreakers in the
+		// tournament's order (models.Tournament.TiebreakOrder).
+		"tiebreak":      engine.TiebreakValue,
+		"tiebreakLabel": models.TiebreakerLabel,
+		// readOnlyReason is replaced per server with the read-only mode's
+		// Reason; the stub keeps templates parseable on their own.
+		"readOnlyReason": func() string { return "" },
+	}
+}
+
+// fieldAttrs returns the attributes of form field name on a page whose
+// handler blamed errorField for the form's error: that field is marked
+// invalid, described by the error (template form_error) and focused. With
+// no error the field focused is the form's first one, when first is set.
+func fieldAttrs(errorField interface{}, name string, first bool) template.HTMLAttr {
+	// Pages rendered without an error have no ErrorField at all.
+	blamed, _ := errorField.(string)
+	switch {
+	case blamed == name:
+		return `aria-invalid="true" aria-describedby="form-error" autofocus`
+	case blamed == "" && first:
+		return "autofocus"
+	}
+	return ""
+}
+
+// inZone returns t in the tournament time zone tz.
+func inZone(t time.Time, tz string) time.Time {
+	return t.In((&models.Tournament{TimeZone: tz}).Zone())
+}
+
+// countdown formats d as H:MM:SS, or "" once it has passed. app.js keeps
+// the same format as it ticks.
+func countdown(d time.Duration) string {
+	if d <= 0 {
+		return ""
 	}
+	s := int(d.Round(time.Second) / time.Second)
+	return fmt.Sprintf("%d:%02d:%02d", s/3600, s/60%60, s%60)
 }
 
-// loadTemplates parses the layout once and one parsed *Template per page,
-// each containing its page + the shared layout. Reads from the embedded FS
-// so the binary is self-contained.
+// loadTemplates parses one *Template per page and locale, each containing
+// its page + the shared layout, keyed "<locale>/<page>". The server reads
+// from the embedded FS so the binary is self-contained, or from disk in
+// -dev mode.
 func loadTemplates(tplFS fs.FS) (map[string]*template.Template, error) {
 	layouts, err := fs.Glob(tplFS, "templates/layouts/*.html")
 	if err != nil {
@@ -362,15 +284,17 @@ func loadTemplates(tplFS fs.FS) (map[string]*template.Template, error) {
 		return nil, err
 	}
 	out := map[string]*template.Template{}
-	for _, page := range pages {
-		name := path.Base(page)
-		files := append([]string{}, layouts...)
-		files = append(files, page)
-		t, err := template.New(name).Funcs(templateFuncs()).ParseFS(tplFS, files...)
-		if err != nil {
-			return nil, fmt.Errorf("parse %s: %w", name, err)
+	for _, lang := range i18n.Supported {
+		for _, page := range pages {
+			name := path.Base(page)
+			files := append([]string{}, layouts...)
+			files = append(files, page)
+			t, err := template.New(name).Funcs(templateFuncs(lang)).ParseFS(tplFS, files...)
+			if err != nil {
+				return nil, fmt.Errorf("parse %s: %w", name, err)
+			}
+			out[path.Join(lang, name)] = t
 		}
-		out[name] = t
 	}
 	if len(out) == 0 {
 		return nil, fs.ErrNotExist
@@ -379,15 +303,38 @@ func loadTemplates(tplFS fs.FS) (map[string]*template.Template, error) {
 }
 
 // namedTemplate satisfies handlers.TemplateRenderer by dispatching ExecuteTemplate
-// to the page-specific *template.Template, executing its "layout" block.
+// to the page-specific *template.Template, executing its "layout" block. The
+// page is rendered in the locale under the data's "Lang" key, English if unset.
+// A name of the form "page.html#block" renders only that block of the page,
+// without the layout, for the dashboard's HTML fragments.
 type namedTemplate struct {
 	root map[string]*template.Template
+	// reload, if set, parses the templates afresh for every page instead
+	// of using root, for -dev mode.
+	reload func() (map[string]*template.Template, error)
 }
 
 func (n *namedTemplate) ExecuteTemplate(w io.Writer, name string, data interface{}) error {
-	t, ok := n.root[name]
+	root := n.root
+	if n.reload != nil {
+		var err error
+		if root, err = n.reload(); err != nil {
+			return err
+		}
+	}
+	lang := i18n.Default
+	if m, ok := data.(map[string]interface{}); ok {
+		if l, ok := m["Lang"].(string); ok && i18n.Valid(l) {
+			lang = l
+		}
+	}
+	page, block, _ := strings.Cut(name, "#")
+	if block == "" {
+		block = "layout"
+	}
+	t, ok := root[path.Join(lang, page)]
 	if !ok {
 		return fmt.Errorf("template %q not found", name)
 	}
-	return t.ExecuteTemplate(w, "layout", data)
+	return t.ExecuteTemplate(w, block, data)
 }
@@ -0,0 +1,1547 @@
+package main
+
+import (
+	"bytes"
+	"html/template"
+	"io/fs"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/engine"
+	"github.com/dstathis/openswiss/internal/i18n"
+	"github.com/dstathis/openswiss/internal/middleware"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/dstathis/openswiss/internal/pow"
+	"github.com/dstathis/openswiss/internal/readonly"
+	"github.com/dstathis/openswiss/internal/webhook"
+	"github.com/dstathis/swisstools"
+)
+
+var postFormRe = regexp.MustCompile(`(?is)<form\b[^>]*method="post"[^>]*>(.*?)</form>`)
+
+func TestTemplates_PostFormsEmbedCSRFToken(t *testing.T) {
+	files, err := fs.Glob(templateFS, "templates/*/*.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range files {
+		src, err := fs.ReadFile(templateFS, f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, m := range postFormRe.FindAllSubmatch(src, -1) {
+			if !bytes.Contains(m[1], []byte(`{{template "csrf_field" $.CSRFToken}}`)) {
+				t.Errorf("%s: POST form without csrf_field: %.80s", f, m[0])
+			}
+		}
+	}
+}
+
+func TestTemplates_RenderCSRFField(t *testing.T) {
+	tmpls, err := loadTemplates(templateFS)
+	if err != nil {
+		t.Fatalf("loadTemplates: %v", err)
+	}
+	renderer := &namedTemplate{root: tmpls}
+	var buf bytes.Buffer
+	if err := renderer.ExecuteTemplate(&buf, "login.html", map[string]interface{}{"CSRFToken": "tok-123"}); err != nil {
+		t.Fatalf("render login.html: %v", err)
+	}
+	if !strings.Contains(buf.String(), `<input type="hidden" name="csrf_token" value="tok-123">`) {
+		t.Error("login form does not carry the CSRF token")
+	}
+}
+
+func TestTemplates_RenderTheme(t *testing.T) {
+	tmpls, err := loadTemplates(templateFS)
+	if err != nil {
+		t.Fatalf("loadTemplates: %v", err)
+	}
+	renderer := &namedTemplate{root: tmpls}
+	for theme, want := range map[string]string{"": "dark", "dark": "dark", "light": "light", "contrast": "contrast"} {
+		data := map[string]interface{}{"CSRFToken": "tok-123"}
+		if theme != "" {
+			data["Theme"] = theme
+		}
+		var buf bytes.Buffer
+		if err := renderer.ExecuteTemplate(&buf, "login.html", data); err != nil {
+			t.Fatalf("render login.html: %v", err)
+		}
+		if !strings.Contains(buf.String(), `data-theme="`+want+`"`) {
+			t.Errorf("theme %q: page not rendered with data-theme=%q", theme, want)
+		}
+	}
+}
+
+func TestTemplates_RenderAccessibility(t *testing.T) {
+	tmpls, err := loadTemplates(templateFS)
+	if err != nil {
+		t.Fatalf("loadTemplates: %v", err)
+	}
+	renderer := &namedTemplate{root: tmpls}
+
+	var buf bytes.Buffer
+	if err := renderer.ExecuteTemplate(&buf, "register.html", map[string]interface{}{}); err != nil {
+		t.Fatalf("render register.html: %v", err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, `<a href="#main" class="skip-link">`) || !strings.Contains(out, `id="main" tabindex="-1"`) {
+		t.Error("page lacks the skip link to its main content")
+	}
+	if !strings.Contains(out, `name="display_name" value="" autocomplete="nickname" required autofocus>`) || strings.Count(out, "autofocus") != 1 {
+		t.Error("blank form doesn't focus its first field")
+	}
+
+	buf.Reset()
+	data := map[string]interface{}{"Error": "Passwords do not match.", "ErrorField": "confirm_password", "DisplayName": "Ann"}
+	if err := renderer.ExecuteTemplate(&buf, "register.html", data); err != nil {
+		t.Fatalf("render register.html: %v", err)
+	}
+	out = buf.String()
+	for _, want := range []string{
+		`<p class="error" id="form-error" role="alert">Passwords do not match.</p>`,
+		`name="confirm_password" autocomplete="new-password" required minlength="8" aria-invalid="true" aria-describedby="form-error" autofocus>`,
+		`name="display_name" value="Ann"`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("form with an error lacks %s", want)
+		}
+	}
+	if strings.Count(out, "autofocus") != 1 {
+		t.Error("form with an error focuses more than one field")
+	}
+
+	buf.Reset()
+	data = map[string]interface{}{
+		"Tournament": &models.Tournament{ID: 7, Name: "Club Rapid", Status: models.TournamentStatusInProgress},
+		"Pairings": []map[string]interface{}{
+			{"Table": 1, "PlayerAID": 1, "PlayerBID": 2, "PlayerAName": "Ann", "PlayerBName": "Bob", "PlayerAWins": 2, "PlayerBWins": 1, "Draws": 0, "Reported": true, "Winner": "Ann"},
+		},
+		"CurrentRound": 2,
+		"Round":        2,
+	}
+	if err := renderer.ExecuteTemplate(&buf, "tournament_round.html", data); err != nil {
+		t.Fatalf("render tournament_round.html: %v", err)
+	}
+	out = buf.String()
+	for _, want := range []string{
+		`<caption class="visually-hidden">Round 2 pairings</caption>`,
+		`<th role="columnheader" scope="col">Table</th>`,
+		`<span aria-hidden="true">2-1-0</span><span class="visually-hidden">Ann won, 2-1-0</span>`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("round page lacks %s", want)
+		}
+	}
+}
+
+func TestTemplates_RenderDisplay(t *testing.T) {
+	tmpls, err := loadTemplates(templateFS)
+	if err != nil {
+		t.Fatalf("loadTemplates: %v", err)
+	}
+	renderer := &namedTemplate{root: tmpls}
+	for _, page := range []string{"display_pairings.html", "display_standings.html"} {
+		var buf bytes.Buffer
+		data := map[string]interface{}{"Display": true, "Refresh": 45, "Tournament": &models.Tournament{Name: "Friday Night"}}
+		if err := renderer.ExecuteTemplate(&buf, page, data); err != nil {
+			t.Fatalf("render %s: %v", page, err)
+		}
+		out := buf.String()
+		if strings.Contains(out, "site-header") || strings.Contains(out, "site-footer") {
+			t.Errorf("%s: projector page has navigation chrome", page)
+		}
+		if !strings.Contains(out, `<meta http-equiv="refresh" content="45">`) || !strings.Contains(out, `data-refresh="45"`) {
+			t.Errorf("%s: refresh interval not rendered", page)
+		}
+	}
+}
+
+func TestTemplates_RenderAdminBackup(t *testing.T) {
+	tmpls, err := loadTemplates(templateFS)
+	if err != nil {
+		t.Fatalf("loadTemplates: %v", err)
+	}
+	renderer := &namedTemplate{root: tmpls}
+	var buf bytes.Buffer
+	data := map[string]interface{}{
+		"User":        &models.User{DisplayName: "Admin", Roles: []string{models.RoleAdmin}},
+		"CSRFToken":   "tok-123",
+		"Tournaments": []models.Tournament{{ID: 7, Name: "Friday Night", Status: models.TournamentStatusInProgress}},
+		"Error":       "bad file",
+	}
+	if err := renderer.ExecuteTemplate(&buf, "admin_backup.html", data); err != nil {
+		t.Fatalf("render admin_backup.html: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{`enctype="multipart/form-data"`, `href="/admin/backup/7"`, `<option value="7">`, "bad file"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("backup page lacks %q", want)
+		}
+	}
+}
+
+func TestTemplates_RenderResults(t *testing.T) {
+	tmpls, err := loadTemplates(templateFS)
+	if err != nil {
+		t.Fatalf("loadTemplates: %v", err)
+	}
+	renderer := &namedTemplate{root: tmpls}
+	placings := []engine.Placing{
+		{Place: 1, Playoff: "Champion", Standing: swisstools.PlayerStanding{Name: "Alice", Points: 6}},
+		{Place: 2, Playoff: "Finalist", Standing: swisstools.PlayerStanding{Name: "Bob", Points: 6}},
+		{Place: 3, Standing: swisstools.PlayerStanding{Name: "Carol", Points: 3}},
+		{Place: 4, Standing: swisstools.PlayerStanding{Name: "Dan"}},
+	}
+	var buf bytes.Buffer
+	data := map[string]interface{}{
+		"Tournament": &models.Tournament{ID: 7, Name: "Friday Night", Status: models.TournamentStatusFinished},
+		"Podium":     placings[:3],
+		"Placings":   placings,
+		"Pager":      map[string]int{"Page": 1, "Pages": 1, "All": 4, "Total": 4},
+		"Playoff":    true,
+	}
+	if err := renderer.ExecuteTemplate(&buf, "tournament_results.html", data); err != nil {
+		t.Fatalf("render tournament_results.html: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{`class="podium-1"`, `class="podium-3"`, "Champion", `<th scope="col">Top Cut</th>`, "Dan"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("results page lacks %q", want)
+		}
+	}
+	if n := strings.Count(out, `class="podium-row"`); n != 3 {
+		t.Errorf("%d podium rows highlighted, want 3", n)
+	}
+}
+
+func TestTemplates_RenderTeamEvent(t *testing.T) {
+	tmpls, err := loadTemplates(templateFS)
+	if err != nil {
+		t.Fatalf("loadTemplates: %v", err)
+	}
+	renderer := &namedTemplate{root: tmpls}
+	pager := map[string]int{"Page": 1, "Pages": 1, "All": 1, "Total": 1}
+	var buf bytes.Buffer
+	data := map[string]interface{}{
+		"Tournament":         &models.Tournament{ID: 7, Name: "Team Night", Status: models.TournamentStatusInProgress, TeamSize: 2},
+		"Registrations":      []models.Registration{{ID: 1, DisplayName: "Rockets", Members: []string{"Jessie", "James"}}},
+		"RegistrationsPager": pager,
+		"Individual":         []engine.IndividualStanding{{Rank: 1, Name: "Jessie", Team: "Rockets", Seat: 1, Wins: 1, Points: 3}},
+		"IndividualPager":    pager,
+		"StandingsPager":     pager,
+		"PairingsPager":      pager,
+	}
+	if err := renderer.ExecuteTemplate(&buf, "tournament_detail.html", data); err != nil {
+		t.Fatalf("render tournament_detail.html: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{"Team event: teams of 2", `id="individual"`, "Jessie, James", "Registered Teams"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("detail page lacks %q", want)
+		}
+	}
+}
+
+func TestTemplates_RenderMultiStage(t *testing.T) {
+	tmpls, err := loadTemplates(templateFS)
+	if err != nil {
+		t.Fatalf("loadTemplates: %v", err)
+	}
+	renderer := &namedTemplate{root: tmpls}
+	pager := map[string]int{"Page": 1, "Pages": 1, "All": 0, "Total": 0}
+	var buf bytes.Buffer
+	data := map[string]interface{}{
+		"Tournament":         &models.Tournament{ID: 7, Name: "Regionals", Status: models.TournamentStatusRegistrationOpen, PodAdvance: 2},
+		"RegistrationsPager": pager,
+		"IndividualPager":    pager,
+		"StandingsPager":     pager,
+		"PairingsPager":      pager,
+		"Stages": map[string]interface{}{
+			"Pods": []models.Tournament{{ID: 8, Name: "Pod A", Status: models.TournamentStatusFinished}},
+			"Combined": []engine.StagePlacing{
+				{Place: 1, Name: "Ann", Stage: "Pod A", StagePlace: 1, Points: 9, Advanced: true},
+			},
+		},
+	}
+	if err := renderer.ExecuteTemplate(&buf, "tournament_detail.html", data); err != nil {
+		t.Fatalf("render tournament_detail.html: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{"Top 2 of each pod advance", `href="/tournaments/8"`, `id="combined"`, "advanced</span>"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("detail page lacks %q", want)
+		}
+	}
+}
+
+func TestTemplates_RenderManagePages(t *testing.T) {
+	tmpls, err := loadTemplates(templateFS)
+	if err != nil {
+		t.Fatalf("loadTemplates: %v", err)
+	}
+	renderer := &namedTemplate{root: tmpls}
+	pager := map[string]int{"Page": 1, "Pages": 1, "All": 1, "Total": 1}
+	tourn := &models.Tournament{ID: 7, Name: "Friday Night", Status: models.TournamentStatusInProgress}
+	tabs := []struct{ page, tab, path string }{
+		{"tournament_manage.html", "Overview", "/tournaments/7/manage"},
+		{"tournament_manage_players.html", "Players", "/tournaments/7/manage/players"},
+		{"tournament_manage_rounds.html", "Rounds", "/tournaments/7/manage/rounds"},
+		{"tournament_manage_results.html", "Results", "/tournaments/7/manage/results"},
+	}
+	for _, tc := range tabs {
+		data := map[string]interface{}{
+			"Tournament":         tourn,
+			"Tab":                strings.ToLower(tc.tab),
+			"IsCoOrganizer":      true,
+			"StartCheck":         engine.StartCheck{},
+			"PlayerCount":        1,
+			"CurrentRound":       1,
+			"Rounds":             []int{1},
+			"StaffView":          true,
+			"Registrations":      []models.Registration{{ID: 1, DisplayName: "Ann", Status: models.RegistrationStatusConfirmed}},
+			"RegistrationRows":   []models.Registration{{ID: 1, DisplayName: "Ann", Status: models.RegistrationStatusConfirmed}},
+			"RegistrationsPager": pager,
+			"PairingsPager":      pager,
+			"StandingsPager":     pager,
+			"Standings":          []swisstools.PlayerStanding{{Rank: 1, Name: "Ann", Points: 3}},
+			"Stages":             map[string]interface{}{},
+		}
+		var buf bytes.Buffer
+		if err := renderer.ExecuteTemplate(&buf, tc.page, data); err != nil {
+			t.Fatalf("render %s: %v", tc.page, err)
+		}
+		out := buf.String()
+		if !strings.Contains(out, `<strong aria-current="page">`+tc.tab+"</strong>") {
+			t.Errorf("%s: %s is not the current tab", tc.page, tc.tab)
+		}
+		for _, other := range tabs {
+			if other.tab != tc.tab && !strings.Contains(out, `<a href="`+other.path+`">`+other.tab+`</a>`) {
+				t.Errorf("%s lacks the link to %s", tc.page, other.tab)
+			}
+		}
+	}
+}
+
+func TestTemplates_RenderTiebreakOrder(t *testing.T) {
+	tmpls, err := loadTemplates(templateFS)
+	if err != nil {
+		t.Fatalf("loadTemplates: %v", err)
+	}
+	renderer := &namedTemplate{root: tmpls}
+	pager := map[string]int{"Page": 1, "Pages": 1, "All": 1, "Total": 1}
+	standing := swisstools.PlayerStanding{Rank: 1, Name: "Ann", Points: 3,
+		Tiebreakers: swisstools.TiebreakerData{OpponentMatchWinPct: 0.5, GameWinPercentage: 0.25}}
+	data := map[string]interface{}{
+		"Tournament": &models.Tournament{ID: 7, Name: "Friday Night", Status: models.TournamentStatusInProgress,
+			Tiebreakers: []string{models.TiebreakGW, models.TiebreakOMW}},
+		"Standings":      []swisstools.PlayerStanding{standing},
+		"StandingsPager": pager,
+	}
+	for page, want := range map[string][]string{
+		"tournament_detail.html":         {"GW%", "OMW%", "25.0%", "50.0%"},
+		"tournament_manage_results.html": {"GW%", "OMW%", "25.0%", "50.0%"},
+		"tournament_share.html":          {"GW%", "25.0%"},
+	} {
+		var buf bytes.Buffer
+		if err := renderer.ExecuteTemplate(&buf, page, data); err != nil {
+			t.Fatalf("render %s: %v", page, err)
+		}
+		out := buf.String()
+		at := 0
+		for _, w := range want {
+			i := strings.Index(out[at:], w)
+			if i < 0 {
+				t.Errorf("%s: %q missing or out of order", page, w)
+				break
+			}
+			at += i + len(w)
+		}
+		if strings.Contains(out, "OGW%") {
+			t.Errorf("%s shows OGW%%, which the tournament doesn't use", page)
+		}
+	}
+}
+
+func TestTemplates_RenderTournamentDefaults(t *testing.T) {
+	tmpls, err := loadTemplates(templateFS)
+	if err != nil {
+		t.Fatalf("loadTemplates: %v", err)
+	}
+	renderer := &namedTemplate{root: tmpls}
+	form := &models.Tournament{MinPlayers: models.DefaultMinPlayers}
+	models.TournamentDefaults{TimeZone: "Europe/Berlin", MaxPlayers: 32, PointsWin: 2, PointsDraw: 1,
+		Tiebreakers: []string{models.TiebreakGW}}.Apply(form)
+	data := map[string]interface{}{"Form": form}
+	var buf bytes.Buffer
+	if err := renderer.ExecuteTemplate(&buf, "tournament_new.html", data); err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		`name="time_zone" value="Europe/Berlin"`,
+		`name="max_players" value="32"`,
+		`name="points_win" value="2"`,
+		`name="points_loss" value="0"`,
+		`name="tiebreakers" value="gw"`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %s", want)
+		}
+	}
+}
+
+func TestTemplates_RenderColors(t *testing.T) {
+	tmpls, err := loadTemplates(templateFS)
+	if err != nil {
+		t.Fatalf("loadTemplates: %v", err)
+	}
+	renderer := &namedTemplate{root: tmpls}
+	pager := map[string]int{"Page": 1, "Pages": 1, "All": 1, "Total": 1}
+	pairing := map[string]interface{}{"Table": 1, "PlayerAID": 1, "PlayerBID": 2, "PlayerAName": "Ann", "PlayerBName": "Bob"}
+	data := map[string]interface{}{
+		"Tournament": &models.Tournament{ID: 7, Name: "Club Rapid", Status: models.TournamentStatusInProgress,
+			Colors: true},
+		"Standings":      []swisstools.PlayerStanding{{Rank: 1, PlayerID: 1, Name: "Ann", Points: 3}},
+		"StandingsPager": pager,
+		"ColorHistory":   map[int]string{1: "BW"},
+		"Pairings":       []map[string]interface{}{pairing},
+		"PairingsPager":  pager,
+		"CurrentRound":   2,
+		"Seats": []map[string]interface{}{
+			{"ID": 1, "Name": "Ann", "Table": 1, "Opponent": "Bob", "White": true},
+			{"ID": 2, "Name": "Bob", "Table": 1, "Opponent": "Ann"},
+		},
+	}
+	for page, want := range map[string][]string{
+		"tournament_detail.html":         {"Colours", "BW", "White", "Black", "Ann", "Bob"},
+		"tournament_manage_results.html": {"Colours", "BW"},
+		"tournament_share.html":          {"White", "Black"},
+		"display_pairings.html":          {"White", "Black"},
+	} {
+		var buf bytes.Buffer
+		if err := renderer.ExecuteTemplate(&buf, page, data); err != nil {
+			t.Fatalf("render %s: %v", page, err)
+		}
+		out := buf.String()
+		at := 0
+		for _, w := range want {
+			i := strings.Index(out[at:], w)
+			if i < 0 {
+				t.Errorf("%s: %q missing or out of order", page, w)
+				break
+			}
+			at += i + len(w)
+		}
+		if strings.Contains(out, "Player A") {
+			t.Errorf("%s still says Player A", page)
+		}
+	}
+
+	data["ByName"] = true
+	var buf bytes.Buffer
+	if err := renderer.ExecuteTemplate(&buf, "display_pairings.html", data); err != nil {
+		t.Fatalf("render by name: %v", err)
+	}
+	if out := buf.String(); !strings.Contains(out, "<td>White</td>") || !strings.Contains(out, "<td>Black</td>") {
+		t.Errorf("by-name display lacks each player's colour")
+	}
+}
+
+func TestTemplates_RenderRatingChanges(t *testing.T) {
+	tmpls, err := loadTemplates(templateFS)
+	if err != nil {
+		t.Fatalf("loadTemplates: %v", err)
+	}
+	renderer := &namedTemplate{root: tmpls}
+	pager := map[string]int{"Page": 1, "Pages": 1, "All": 0, "Total": 0}
+	data := map[string]interface{}{
+		"Tournament":     &models.Tournament{ID: 7, Name: "Club Rapid", Status: models.TournamentStatusFinished},
+		"Tab":            "results",
+		"StandingsPager": pager,
+		"RatingK":        24,
+		"MaxRatingK":     engine.MaxRatingK,
+		"RatingChanges": []engine.RatingChange{
+			{Name: "Ann", Rating: 1500, Matches: 3, Score: 2.5, Expected: 1.5, Delta: 24, New: 1524},
+			{Name: "Bob", Rating: 1700, Matches: 3, Score: 1, Expected: 1.75, Delta: -18, New: 1682},
+		},
+	}
+	var buf bytes.Buffer
+	if err := renderer.ExecuteTemplate(&buf, "tournament_manage_results.html", data); err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{`id="ratings"`, `value="24"`, "<td>+24</td>", "<td>1524</td>", "<td>-18</td>", "<td>1.75</td>", "/export/ratings.csv?k=24"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("results page lacks %q", want)
+		}
+	}
+}
+
+func TestTemplates_RenderPlayerRegistry(t *testing.T) {
+	tmpls, err := loadTemplates(templateFS)
+	if err != nil {
+		t.Fatalf("loadTemplates: %v", err)
+	}
+	renderer := &namedTemplate{root: tmpls}
+	contact, rating := "ann@example.com", 1620
+	ann := models.Player{ID: 4, Name: "Ann", Contact: &contact, Rating: &rating}
+	held := time.Date(2026, 5, 9, 0, 0, 0, 0, time.UTC)
+	events := []engine.EventRecord{
+		{TournamentID: 7, Tournament: "Club Rapid", Date: held, Name: "Ann", Played: true, Final: true, Place: 1, Field: 12, Points: 12, Wins: 4},
+		{TournamentID: 8, Tournament: "Summer Open", Date: held, Name: "Ann (2)"},
+	}
+	pager := map[string]int{"Page": 1, "Pages": 1, "All": 1, "Total": 1}
+	for name, tc := range map[string]struct {
+		data map[string]interface{}
+		want []string
+	}{
+		"players.html": {
+			map[string]interface{}{"Players": []models.Player{ann}, "Pager": pager},
+			[]string{`href="/players/4"`, "ann@example.com", "<td>1620</td>", `action="/players"`},
+		},
+		"player_detail.html": {
+			map[string]interface{}{"Player": &ann, "Events": events, "Lifetime": engine.Lifetime(events), "Seasons": engine.Seasons(events)},
+			[]string{"1 event ·", "4-0-0 in matches", "1 title", "<td>2026</td>", "1 of 12", "Ann (2)", `value="1620"`, `action="/players/4/delete"`},
+		},
+		"tournament_manage_players.html": {
+			map[string]interface{}{
+				"Tournament":         &models.Tournament{ID: 7, Name: "Club Rapid", Status: models.TournamentStatusRegistrationOpen},
+				"Tab":                "players",
+				"Registrations":      []models.Registration{{ID: 1, DisplayName: "Bob", PlayerID: &ann.ID}},
+				"RegistrationRows":   []models.Registration{{ID: 1, DisplayName: "Bob", PlayerID: &ann.ID}},
+				"RegistrationsPager": pager,
+				"Registry":           []models.Player{ann},
+			},
+			[]string{`name="player_id"`, `<option value="4">Ann (1620)</option>`, `<span class="badge">registry</span>`},
+		},
+	} {
+		var buf bytes.Buffer
+		if err := renderer.ExecuteTemplate(&buf, name, tc.data); err != nil {
+			t.Fatalf("render %s: %v", name, err)
+		}
+		for _, want := range tc.want {
+			if !strings.Contains(buf.String(), want) {
+				t.Errorf("%s lacks %q", name, want)
+			}
+		}
+	}
+}
+
+func TestTemplates_RenderLeagues(t *testing.T) {
+	tmpls, err := loadTemplates(templateFS)
+	if err != nil {
+		t.Fatalf("loadTemplates: %v", err)
+	}
+	renderer := &namedTemplate{root: tmpls}
+	org := &models.User{ID: 1, DisplayName: "Org", Roles: []string{models.RoleOrganizer}}
+	league := &models.League{ID: 3, Name: "Spring Season", PlacementPoints: []int{5, 3, 1}, ParticipationPoints: 1}
+	table := &engine.LeagueTable{
+		League:    league,
+		Counted:   []models.Tournament{{ID: 7, Name: "Club Rapid"}},
+		Pending:   []models.Tournament{{ID: 8, Name: "Summer Open"}},
+		Standings: []engine.LeagueStanding{{Rank: 1, Name: "Ann", Points: 8, Events: 2, Titles: 1, Best: 1}},
+	}
+	for name, tc := range map[string]struct {
+		data map[string]interface{}
+		want []string
+	}{
+		"leagues.html": {
+			map[string]interface{}{"User": org, "Leagues": []models.League{*league}},
+			[]string{`href="/leagues/3"`, "Spring Season", `href="/leagues/new"`},
+		},
+		"league.html": {
+			map[string]interface{}{"League": league, "Points": "5, 3, 1", "Table": table, "Lang": "de"},
+			[]string{"Punkte nach Platz: 5, 3, 1.", "<td>Ann</td>", "<td>8</td>", `href="/tournaments/7/results"`, "noch nicht gewertet"},
+		},
+		"league_new.html": {
+			map[string]interface{}{"User": org, "Points": "10, 8, 6"},
+			[]string{`action="/leagues"`, `value="10, 8, 6"`},
+		},
+		"league_manage.html": {
+			map[string]interface{}{"User": org, "League": league, "Points": "5, 3, 1", "Tournaments": table.Counted, "Addable": table.Pending},
+			[]string{`action="/leagues/3/tournaments/7/remove"`, `<option value="8">Summer Open</option>`, `action="/leagues/3/edit"`, `action="/leagues/3/delete"`},
+		},
+	} {
+		var buf bytes.Buffer
+		if err := renderer.ExecuteTemplate(&buf, name, tc.data); err != nil {
+			t.Fatalf("render %s: %v", name, err)
+		}
+		for _, want := range tc.want {
+			if !strings.Contains(buf.String(), want) {
+				t.Errorf("%s lacks %q", name, want)
+			}
+		}
+	}
+}
+
+func TestTemplates_RenderFragments(t *testing.T) {
+	tmpls, err := loadTemplates(templateFS)
+	if err != nil {
+		t.Fatalf("loadTemplates: %v", err)
+	}
+	renderer := &namedTemplate{root: tmpls}
+	pager := map[string]int{"Page": 1, "Pages": 1, "All": 1, "Total": 1}
+	data := map[string]interface{}{
+		"Tournament":         &models.Tournament{ID: 7, Name: "Friday Night", Status: models.TournamentStatusRegistrationOpen},
+		"IsCoOrganizer":      true,
+		"PendingCount":       1,
+		"Registrations":      []models.Registration{{ID: 1, DisplayName: "Ann", Status: models.RegistrationStatusPending}},
+		"RegistrationRows":   []models.Registration{{ID: 1, DisplayName: "Ann", Status: models.RegistrationStatusPending}},
+		"RegistrationsPager": pager,
+		"PairingsPager":      pager,
+	}
+	for name, want := range map[string]string{
+		"tournament_manage_players.html#registrations": "Accept All Pending",
+		"tournament_manage_rounds.html#round":          `class="manage-actions"`,
+	} {
+		var buf bytes.Buffer
+		if err := renderer.ExecuteTemplate(&buf, name, data); err != nil {
+			t.Fatalf("render %s: %v", name, err)
+		}
+		out := buf.String()
+		if !strings.Contains(out, want) {
+			t.Errorf("%s lacks %q", name, want)
+		}
+		if strings.Contains(out, "<html") || strings.Contains(out, "manage-nav") {
+			t.Errorf("%s rendered more than the fragment:\n%s", name, out)
+		}
+	}
+
+	// The page wraps the fragment in the element its forms update.
+	var buf bytes.Buffer
+	if err := renderer.ExecuteTemplate(&buf, "tournament_manage_players.html", data); err != nil {
+		t.Fatal(err)
+	}
+	if out := buf.String(); !strings.Contains(out, `id="registrations" data-fragment="/tournaments/7/manage/fragments/registrations"`) ||
+		!strings.Contains(out, `data-update="registrations"`) {
+		t.Error("players page lacks the fragment wrapper or its forms")
+	}
+}
+
+func TestTemplates_RenderShareView(t *testing.T) {
+	tmpls, err := loadTemplates(templateFS)
+	if err != nil {
+		t.Fatalf("loadTemplates: %v", err)
+	}
+	renderer := &namedTemplate{root: tmpls}
+	var buf bytes.Buffer
+	data := map[string]interface{}{
+		"Spectator":    true,
+		"Refresh":      60,
+		"Tournament":   &models.Tournament{ID: 7, Name: "Friday Night", Status: models.TournamentStatusInProgress},
+		"CurrentRound": 1,
+		"Standings":    []swisstools.PlayerStanding{{Rank: 1, Name: "Ann", Points: 3}},
+	}
+	if err := renderer.ExecuteTemplate(&buf, "tournament_share.html", data); err != nil {
+		t.Fatalf("render tournament_share.html: %v", err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, `http-equiv="refresh" content="60"`) || !strings.Contains(out, "Ann") {
+		t.Errorf("share page lacks the refresh or the standings:\n%s", out)
+	}
+	for _, unwanted := range []string{"<form", `href="/login"`, `href="/register"`} {
+		if strings.Contains(out, unwanted) {
+			t.Errorf("share page has %q", unwanted)
+		}
+	}
+}
+
+var messageRe = regexp.MustCompile(`\{\{t "((?:[^"\\]|\\.)*)"`)
+
+func TestTemplates_MessagesTranslated(t *testing.T) {
+	files, err := fs.Glob(templateFS, "templates/*/*.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range files {
+		src, err := fs.ReadFile(templateFS, f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, m := range messageRe.FindAllStringSubmatch(string(src), -1) {
+			msg, err := strconv.Unquote(`"` + m[1] + `"`)
+			if err != nil {
+				t.Fatalf("%s: %s: %v", f, m[0], err)
+			}
+			for _, lang := range i18n.Supported[1:] {
+				if _, ok := i18n.Catalog(lang)[msg]; !ok {
+					t.Errorf("%s: %q has no %s translation", f, msg, lang)
+				}
+			}
+		}
+	}
+}
+
+func TestTemplates_RenderLocale(t *testing.T) {
+	tmpls, err := loadTemplates(templateFS)
+	if err != nil {
+		t.Fatalf("loadTemplates: %v", err)
+	}
+	renderer := &namedTemplate{root: tmpls}
+	when := time.Date(2026, 5, 1, 19, 0, 0, 0, time.UTC)
+	for lang, want := range map[string][]string{
+		"":   {`<html lang="en"`, "Upcoming Tournaments", "May 1, 2026 7:00 PM", "registration_open"},
+		"de": {`<html lang="de"`, "Anstehende Turniere", "01.05.2026, 19:00", "Anmeldung offen", `href="/login">Anmelden</a>`},
+		"es": {`<html lang="es"`, "Próximos torneos", "01/05/2026 19:00", "inscripción abierta"},
+	} {
+		data := map[string]interface{}{
+			"Tournaments": []models.Tournament{{ID: 1, Name: "Friday Night", ScheduledAt: &when, Status: models.TournamentStatusRegistrationOpen}},
+		}
+		if lang != "" {
+			data["Lang"] = lang
+		}
+		var buf bytes.Buffer
+		if err := renderer.ExecuteTemplate(&buf, "home.html", data); err != nil {
+			t.Fatalf("render home.html in %q: %v", lang, err)
+		}
+		for _, s := range want {
+			if !strings.Contains(buf.String(), s) {
+				t.Errorf("home page in %q lacks %q", lang, s)
+			}
+		}
+	}
+}
+
+func TestTemplates_RenderSchedule(t *testing.T) {
+	tmpls, err := loadTemplates(templateFS)
+	if err != nil {
+		t.Fatalf("loadTemplates: %v", err)
+	}
+	renderer := &namedTemplate{root: tmpls}
+	start := time.Now().Add(90 * time.Minute)
+	next := db.UpNext{
+		Tournament: models.Tournament{ID: 7, Name: "Spring Open", TimeZone: "Asia/Tokyo"},
+		Item:       models.ScheduleItem{StartsAt: start, Label: "Round 2"},
+	}
+	var buf bytes.Buffer
+	if err := renderer.ExecuteTemplate(&buf, "home.html", map[string]interface{}{"Lang": "de", "UpNext": []db.UpNext{next}}); err != nil {
+		t.Fatalf("render home.html: %v", err)
+	}
+	for _, s := range []string{
+		"Als Nächstes",
+		"Round 2",
+		`datetime="` + start.UTC().Format(time.RFC3339) + `"`,
+		i18n.FormatDateTime("de", start.In(next.Tournament.Zone())) + " JST",
+		`class="countdown"`,
+	} {
+		if !strings.Contains(buf.String(), s) {
+			t.Errorf("home page lacks %q", s)
+		}
+	}
+
+	buf.Reset()
+	if err := renderer.ExecuteTemplate(&buf, "tournament_detail.html", map[string]interface{}{
+		"Tournament":  &next.Tournament,
+		"Schedule":    []models.ScheduleItem{next.Item},
+		"CalendarURL": template.URL("webcal://swiss.example.com/tournaments/7/schedule.ics"),
+	}); err != nil {
+		t.Fatalf("render tournament_detail.html: %v", err)
+	}
+	for _, s := range []string{`href="webcal://swiss.example.com/tournaments/7/schedule.ics"`, `href="/tournaments/7/schedule.ics"`} {
+		if !strings.Contains(buf.String(), s) {
+			t.Errorf("tournament page lacks %q", s)
+		}
+	}
+}
+
+func TestTemplates_RenderOutstanding(t *testing.T) {
+	tmpls, err := loadTemplates(templateFS)
+	if err != nil {
+		t.Fatalf("loadTemplates: %v", err)
+	}
+	renderer := &namedTemplate{root: tmpls}
+	tourn := &models.Tournament{ID: 7, Name: "Friday Night", Status: models.TournamentStatusInProgress, TimeZone: "UTC"}
+	ends := time.Now().Add(20 * time.Minute)
+	data := map[string]interface{}{
+		"Tournament":       tourn,
+		"OutstandingRound": 2,
+		"Outstanding":      []webhook.Pairing{{Table: 3, PlayerAName: "Ann", PlayerBName: "Bob"}},
+		"Clock":            &models.RoundClock{TournamentID: 7, Round: 2, EndsAt: ends},
+	}
+	var buf bytes.Buffer
+	if err := renderer.ExecuteTemplate(&buf, "tournament_manage.html", data); err != nil {
+		t.Fatalf("render tournament_manage.html: %v", err)
+	}
+	for _, s := range []string{
+		`id="outstanding" data-fragment="/tournaments/7/manage/fragments/outstanding" data-refresh="30"`,
+		"<td>Bob</td>",
+		`data-until="` + ends.UTC().Format(time.RFC3339) + `"`,
+		`action="/tournaments/7/remind"`,
+	} {
+		if !strings.Contains(buf.String(), s) {
+			t.Errorf("overview lacks %q", s)
+		}
+	}
+
+	data["Outstanding"] = []webhook.Pairing{}
+	buf.Reset()
+	if err := renderer.ExecuteTemplate(&buf, "tournament_manage.html#outstanding", data); err != nil {
+		t.Fatal(err)
+	}
+	if out := buf.String(); !strings.Contains(out, "Every result of round 2 is in.") || strings.Contains(out, "/remind") {
+		t.Errorf("widget with every result in:\n%s", out)
+	}
+
+	data["CurrentRound"], data["Rounds"] = 2, []int{1, 2}
+	buf.Reset()
+	if err := renderer.ExecuteTemplate(&buf, "tournament_manage_rounds.html#round", data); err != nil {
+		t.Fatal(err)
+	}
+	if out := buf.String(); !strings.Contains(out, `action="/tournaments/7/clock"`) || !strings.Contains(out, "Stop Clock") {
+		t.Error("rounds tab lacks the round clock forms")
+	}
+}
+
+func TestTemplates_RenderAvatars(t *testing.T) {
+	tmpls, err := loadTemplates(templateFS)
+	if err != nil {
+		t.Fatalf("loadTemplates: %v", err)
+	}
+	renderer := &namedTemplate{root: tmpls}
+	data := map[string]interface{}{
+		"Tournament":   &models.Tournament{ID: 7, Name: "Friday Night", Status: models.TournamentStatusInProgress},
+		"CurrentRound": 1,
+		"Standings":    []swisstools.PlayerStanding{{Rank: 1, PlayerID: 1, Name: "Ann"}, {Rank: 2, PlayerID: 2, Name: "Bob"}, {Rank: 3, PlayerID: 3, Name: "Cy"}},
+		"Pairings":     []map[string]interface{}{{"Table": 1, "PlayerAID": 1, "PlayerBID": 2, "PlayerAName": "Ann", "PlayerBName": "Bob"}},
+		"Seats":        []map[string]interface{}{{"ID": 1, "Name": "Ann", "Table": 1, "Opponent": "Bob"}},
+		"Avatars": map[int]*models.Avatar{
+			1: {UserID: 11, Icon: "owl"},
+			2: {UserID: 12, UpdatedAt: time.Unix(1700000000, 0)},
+		},
+	}
+	data["StandingsPager"] = map[string]int{"Page": 1, "Pages": 1, "All": 3, "Total": 3}
+	data["PairingsPager"] = data["StandingsPager"]
+	for _, page := range []string{"tournament_detail.html", "display_standings.html", "display_pairings.html"} {
+		var buf bytes.Buffer
+		if err := renderer.ExecuteTemplate(&buf, page, data); err != nil {
+			t.Fatalf("render %s: %v", page, err)
+		}
+		out := buf.String()
+		if !strings.Contains(out, "🦉") || !strings.Contains(out, `src="/avatars/12?v=1700000000"`) {
+			t.Errorf("%s lacks Ann's icon or Bob's image", page)
+		}
+	}
+	data["ByName"] = true
+	var buf bytes.Buffer
+	if err := renderer.ExecuteTemplate(&buf, "display_pairings.html", data); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "🦉") {
+		t.Error("by-name pairings lack Ann's icon")
+	}
+
+	// The profile offers the icons with the current one picked.
+	buf.Reset()
+	err = renderer.ExecuteTemplate(&buf, "profile.html", map[string]interface{}{
+		"User":     &models.User{DisplayName: "Ann", Email: "ann@example.com", NotifyBy: models.NotifyNone},
+		"Avatar":   &models.Avatar{UserID: 11, Icon: "fox"},
+		"Channels": []string{models.NotifyEmail, models.NotifyNone},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, `value="fox" aria-label="fox" checked`) || !strings.Contains(out, `action="/profile/avatar/remove"`) {
+		t.Errorf("profile page:\n%s", out)
+	}
+	if !strings.Contains(out, `name="notify_by" value="none" checked`) || strings.Contains(out, `value="email" checked`) {
+		t.Errorf("profile notifications:\n%s", out)
+	}
+	buf.Reset()
+	if err := renderer.ExecuteTemplate(&buf, "register.html", map[string]interface{}{}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), `name="icon" value="cat"`) {
+		t.Error("sign-up form lacks the icon picker")
+	}
+}
+
+func TestTemplates_RenderAnnouncements(t *testing.T) {
+	tmpls, err := loadTemplates(templateFS)
+	if err != nil {
+		t.Fatalf("loadTemplates: %v", err)
+	}
+	renderer := &namedTemplate{root: tmpls}
+	tourn := &models.Tournament{ID: 7, Name: "Friday Night", Status: models.TournamentStatusInProgress, TimeZone: "UTC"}
+	delay := models.Announcement{ID: 3, TournamentID: 7, Message: "Round 3 is delayed <10 minutes>"}
+	data := map[string]interface{}{
+		"Tournament":    tourn,
+		"Announcements": []models.Announcement{delay},
+	}
+	data["StandingsPager"] = map[string]int{"Page": 1, "Pages": 1}
+	data["PairingsPager"] = data["StandingsPager"]
+	for _, page := range []string{"tournament_detail.html", "display_pairings.html", "display_standings.html", "tournament_share.html"} {
+		var buf bytes.Buffer
+		if err := renderer.ExecuteTemplate(&buf, page, data); err != nil {
+			t.Fatalf("render %s: %v", page, err)
+		}
+		if !strings.Contains(buf.String(), `class="announcement"`) || !strings.Contains(buf.String(), "delayed &lt;10 minutes&gt;") {
+			t.Errorf("%s lacks the announcement banner", page)
+		}
+	}
+
+	// The dashboard names each announcement's tournament.
+	delay.TournamentName = "Friday Night"
+	var buf bytes.Buffer
+	err = renderer.ExecuteTemplate(&buf, "dashboard.html", map[string]interface{}{
+		"User":          &models.User{DisplayName: "Ann"},
+		"Announcements": []models.Announcement{delay},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), `<a href="/tournaments/7">Friday Night</a>: Round 3`) {
+		t.Error("dashboard banner lacks the tournament")
+	}
+
+	// The dashboard overview lists the history, with End only on active ones.
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	ended := now.Add(-time.Hour)
+	buf.Reset()
+	err = renderer.ExecuteTemplate(&buf, "tournament_manage.html", map[string]interface{}{
+		"Tournament": tourn,
+		"Now":        now,
+		"AnnouncementHistory": []models.Announcement{
+			{ID: 4, TournamentID: 7, Message: "Lunch", CreatedAt: now},
+			{ID: 3, TournamentID: 7, Message: "Delay", CreatedAt: now.Add(-2 * time.Hour), ExpiresAt: &ended},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, `action="/tournaments/7/announcements"`) || !strings.Contains(out, `action="/tournaments/7/announcements/4/end"`) {
+		t.Error("overview lacks the announce form or Lunch's End button")
+	}
+	if strings.Contains(out, "/announcements/3/end") || strings.Contains(out, `class="announcement"`) {
+		t.Error("overview offers to end an ended announcement, or shows the banner")
+	}
+}
+
+func TestTemplates_RenderError(t *testing.T) {
+	tmpls, err := loadTemplates(templateFS)
+	if err != nil {
+		t.Fatalf("loadTemplates: %v", err)
+	}
+	renderer := &namedTemplate{root: tmpls}
+	var buf bytes.Buffer
+	if err := renderer.ExecuteTemplate(&buf, "error.html", map[string]interface{}{"Status": 500, "RequestID": "abc123", "Lang": "de"}); err != nil {
+		t.Fatalf("render error.html: %v", err)
+	}
+	if out := buf.String(); !strings.Contains(out, "Etwas ist schiefgelaufen") || !strings.Contains(out, "abc123") {
+		t.Errorf("error page lacks its heading or reference:\n%s", out)
+	}
+
+	buf.Reset()
+	if err := renderer.ExecuteTemplate(&buf, "error.html", map[string]interface{}{
+		"Status": 404, "Message": "Player not found", "Back": "/tournaments/3/manage/players",
+	}); err != nil {
+		t.Fatalf("render error.html: %v", err)
+	}
+	for _, want := range []string{"Page not found", "Player not found", `href="/tournaments/3/manage/players"`} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("404 page lacks %q:\n%s", want, buf.String())
+		}
+	}
+}
+
+func TestTemplates_RenderFlash(t *testing.T) {
+	tmpls, err := loadTemplates(templateFS)
+	if err != nil {
+		t.Fatalf("loadTemplates: %v", err)
+	}
+	renderer := &namedTemplate{root: tmpls}
+	var buf bytes.Buffer
+	if err := renderer.ExecuteTemplate(&buf, "admin_read_only.html", map[string]interface{}{
+		"Flash":  &middleware.FlashMessage{Kind: middleware.FlashSuccess, Message: "Read-only mode is off."},
+		"Status": readonly.Status{},
+	}); err != nil {
+		t.Fatalf("render admin_read_only.html: %v", err)
+	}
+	if !strings.Contains(buf.String(), `<p class="success" role="status">Read-only mode is off.</p>`) {
+		t.Errorf("page lacks the flash banner:\n%s", buf.String())
+	}
+}
+
+func TestNamedTemplate_Reload(t *testing.T) {
+	version := "one"
+	n := &namedTemplate{reload: func() (map[string]*template.Template, error) {
+		page := template.Must(template.New("page.html").Parse(`{{define "layout"}}` + version + `{{end}}`))
+		return map[string]*template.Template{"en/page.html": page}, nil
+	}}
+	for _, want := range []string{"one", "two"} {
+		version = want
+		var buf bytes.Buffer
+		if err := n.ExecuteTemplate(&buf, "page.html", nil); err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != want {
+			t.Errorf("rendered %q, want %q", buf.String(), want)
+		}
+	}
+}
+
+func TestCountdown(t *testing.T) {
+	for d, want := range map[time.Duration]string{
+		-time.Second:                  "",
+		0:                             "",
+		59 * time.Second:              "0:00:59",
+		time.Hour + 2*time.Minute + 3: "1:02:00",
+		26*time.Hour + 5*time.Second:  "26:00:05",
+	} {
+		if got := countdown(d); got != want {
+			t.Errorf("countdown(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
+
+func TestTemplates_RenderFeatureMatches(t *testing.T) {
+	tmpls, err := loadTemplates(templateFS)
+	if err != nil {
+		t.Fatalf("loadTemplates: %v", err)
+	}
+	renderer := &namedTemplate{root: tmpls}
+	pager := map[string]int{"Page": 1, "Pages": 1, "All": 2, "Total": 2}
+	data := map[string]interface{}{
+		"Tournament": &models.Tournament{ID: 7, Name: "Club Rapid", Status: models.TournamentStatusInProgress},
+		"Pairings": []map[string]interface{}{
+			{"Table": 3, "PlayerAID": 5, "PlayerBID": 6, "PlayerAName": "Eve", "PlayerBName": "Fay", "Feature": true},
+			{"Table": 1, "PlayerAID": 1, "PlayerBID": 2, "PlayerAName": "Ann", "PlayerBName": "Bob"},
+		},
+		"PairingsPager":  pager,
+		"StandingsPager": pager,
+		"CurrentRound":   2,
+		"Round":          2,
+		"FeatureTables":  "3",
+	}
+	for _, page := range []string{"tournament_detail.html", "tournament_round.html", "tournament_share.html", "display_pairings.html", "tournament_manage_rounds.html"} {
+		var buf bytes.Buffer
+		if err := renderer.ExecuteTemplate(&buf, page, data); err != nil {
+			t.Fatalf("render %s: %v", page, err)
+		}
+		if n := strings.Count(buf.String(), `class="badge badge-feature"`); n != 1 {
+			t.Errorf("%s: %d feature badges, want 1", page, n)
+		}
+	}
+	var buf bytes.Buffer
+	if err := renderer.ExecuteTemplate(&buf, "tournament_manage_rounds.html", data); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), `action="/tournaments/7/feature-matches"`) || !strings.Contains(buf.String(), `name="tables" value="3"`) {
+		t.Error("rounds dashboard lacks the feature matches form")
+	}
+}
+
+func TestTemplates_RenderResultReports(t *testing.T) {
+	tmpls, err := loadTemplates(templateFS)
+	if err != nil {
+		t.Fatalf("loadTemplates: %v", err)
+	}
+	renderer := &namedTemplate{root: tmpls}
+	pager := map[string]int{"Page": 1, "Pages": 1, "All": 1, "Total": 1}
+	a := &models.ResultReport{Table: 1, Side: models.GameWinnerA, Wins: 2, Losses: 1}
+	b := &models.ResultReport{Table: 1, Side: models.GameWinnerB, Wins: 1, Losses: 2}
+	data := map[string]interface{}{
+		"Tournament":     &models.Tournament{ID: 7, Name: "Club Rapid", Status: models.TournamentStatusInProgress, SelfReport: true, BestOf: 3, ReportConfirmMinutes: 10},
+		"Pairings":       []map[string]interface{}{{"Table": 1, "PlayerAID": 1, "PlayerBID": 2, "PlayerAName": "Ann", "PlayerBName": "Bob", "Mine": true}},
+		"PairingsPager":  pager,
+		"StandingsPager": pager,
+		"CurrentRound":   2,
+		"MyPairing":      map[string]interface{}{"Table": 1, "Opponent": "Bob"},
+		"MyReport":       map[string]interface{}{"Mine": a, "Theirs": b, "Conflict": true},
+		"Reports": []map[string]interface{}{
+			{"Table": 1, "PlayerAName": "Ann", "PlayerBName": "Bob", "State": "conflict", "Sides": []*models.ResultReport{a, b}},
+		},
+	}
+	var buf bytes.Buffer
+	if err := renderer.ExecuteTemplate(&buf, "tournament_detail.html", data); err != nil {
+		t.Fatalf("render tournament_detail.html: %v", err)
+	}
+	for _, s := range []string{`action="/tournaments/7/report"`, "You reported 2-1-0.", "Your opponent reported 1-2-0 for you.", `max="2"`} {
+		if !strings.Contains(buf.String(), s) {
+			t.Errorf("tournament page lacks %q", s)
+		}
+	}
+	buf.Reset()
+	if err := renderer.ExecuteTemplate(&buf, "tournament_manage_rounds.html", data); err != nil {
+		t.Fatalf("render tournament_manage_rounds.html: %v", err)
+	}
+	for _, s := range []string{`id="reports"`, "10 minutes after a lone report", "<td>2-1-0", "<td>1-2-0", `name="side" value="b"`, "Conflict"} {
+		if !strings.Contains(buf.String(), s) {
+			t.Errorf("rounds page lacks %q", s)
+		}
+	}
+}
+
+func TestTemplates_RenderQuickEntry(t *testing.T) {
+	tmpls, err := loadTemplates(templateFS)
+	if err != nil {
+		t.Fatalf("loadTemplates: %v", err)
+	}
+	renderer := &namedTemplate{root: tmpls}
+	data := map[string]interface{}{
+		"Tournament": &models.Tournament{ID: 7, Name: "Club Rapid", Status: models.TournamentStatusInProgress},
+		"Round":      2,
+		"Supported":  true,
+		"Results":    engine.QuickResults(3),
+		"Open": []map[string]interface{}{
+			{"Table": 4, "PlayerAName": "Ann", "PlayerBName": "Bob"},
+		},
+		"Reported":       3,
+		"ReportedResult": "draw",
+	}
+	var buf bytes.Buffer
+	if err := renderer.ExecuteTemplate(&buf, "tournament_quick.html", data); err != nil {
+		t.Fatalf("render tournament_quick.html: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		`action="/tournaments/7/quick-results"`,
+		`name="table" value="4"`,
+		`name="result" value="2-1" class="btn"`,
+		`aria-label="Table 4: 1-2 to Bob"`,
+		`name="result" value="draw" class="btn quick-draw"`,
+		"Table 3: Draw recorded.",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("quick entry page lacks %s", want)
+		}
+	}
+}
+
+func TestTemplates_RenderOverlays(t *testing.T) {
+	tmpls, err := loadTemplates(templateFS)
+	if err != nil {
+		t.Fatalf("loadTemplates: %v", err)
+	}
+	renderer := &namedTemplate{root: tmpls}
+	tourn := &models.Tournament{ID: 7, Name: "Club Rapid", Status: models.TournamentStatusInProgress}
+	player := func(name string) engine.FeaturePlayer { return engine.FeaturePlayer{Name: name, Rank: 1, Wins: 2} }
+	var buf bytes.Buffer
+	err = renderer.ExecuteTemplate(&buf, "overlay_match.html", map[string]interface{}{
+		"Overlay":    true,
+		"Refresh":    10,
+		"Tournament": tourn,
+		"Round":      3,
+		"Match":      &engine.FeatureMatch{Table: 4, PlayerA: player("Ann"), PlayerB: player("Bob"), PlayerAWins: 1},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{`<body class="overlay" data-refresh="10">`, "Table 4", "Ann", "Bob", "1 – 0", "2-0-0"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("match overlay lacks %q", want)
+		}
+	}
+	if strings.Contains(out, "site-header") || strings.Contains(out, "<form") {
+		t.Error("match overlay has the site's chrome")
+	}
+
+	// With no match the overlay is blank, not an error page.
+	buf.Reset()
+	if err := renderer.ExecuteTemplate(&buf, "overlay_match.html", map[string]interface{}{"Overlay": true, "Tournament": tourn}); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buf.String(), "overlay-panel") {
+		t.Error("empty match overlay shows a panel")
+	}
+
+	buf.Reset()
+	err = renderer.ExecuteTemplate(&buf, "overlay_standings.html", map[string]interface{}{
+		"Overlay":    true,
+		"Tournament": tourn,
+		"Standings":  []engine.FeaturePlayer{player("Ann"), {Name: "Bob", Rank: 2}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out := buf.String(); !strings.Contains(out, "Ann") || !strings.Contains(out, "Bob") || !strings.Contains(out, "overlay-panel") {
+		t.Error("standings overlay lacks its players")
+	}
+}
+
+func TestTemplates_RenderPayments(t *testing.T) {
+	tmpls, err := loadTemplates(templateFS)
+	if err != nil {
+		t.Fatalf("loadTemplates: %v", err)
+	}
+	renderer := &namedTemplate{root: tmpls}
+	paid, cash := 1000, "cash"
+	regs := []models.Registration{
+		{ID: 1, DisplayName: "Ann", Status: models.RegistrationStatusConfirmed, PaymentStatus: models.PaymentPaid, PaymentAmount: &paid, PaymentMethod: &cash},
+		{ID: 2, DisplayName: "Bob", Status: models.RegistrationStatusConfirmed, PaymentStatus: models.PaymentUnpaid},
+	}
+	data := map[string]interface{}{
+		"Tournament":         &models.Tournament{ID: 7, Name: "Paid Event", Status: models.TournamentStatusRegistrationOpen, EntryFee: 1000},
+		"Tab":                "players",
+		"IsCoOrganizer":      true,
+		"Registrations":      regs,
+		"RegistrationRows":   regs,
+		"RegistrationsPager": map[string]int{"Page": 1, "Pages": 1, "All": 2, "Total": 2},
+		"TrackPayments":      true,
+		"UnpaidCount":        1,
+	}
+	var buf bytes.Buffer
+	if err := renderer.ExecuteTemplate(&buf, "tournament_manage_players.html", data); err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"Entry fee 10.00. 1 unpaid.", `href="?payment=unpaid#players"`, "/export/players.csv",
+		"/registrations/1/payment", `value="10.00"`, `value="cash"`, `placeholder="10.00"`,
+		`<option value="paid" selected>`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("players page lacks %q", want)
+		}
+	}
+
+	// A registered player who owes the fee can pay it online.
+	pager := map[string]int{"Page": 1, "Pages": 1, "All": 2, "Total": 2}
+	buf.Reset()
+	if err := renderer.ExecuteTemplate(&buf, "tournament_detail.html", map[string]interface{}{
+		"User":               &models.User{ID: 2, DisplayName: "Bob"},
+		"Tournament":         data["Tournament"],
+		"Registrations":      regs,
+		"RegistrationsPager": pager,
+		"IndividualPager":    pager,
+		"StandingsPager":     pager,
+		"PairingsPager":      pager,
+		"MyRegistration":     &regs[1],
+		"OnlineFee":          "10.00 USD",
+	}); err != nil {
+		t.Fatalf("render detail: %v", err)
+	}
+	out = buf.String()
+	for _, want := range []string{"Entry fee: 10.00", "You haven&#39;t paid the entry fee yet.", `action="/tournaments/7/pay"`, "Pay 10.00 USD Online"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("detail page lacks %q", want)
+		}
+	}
+}
+
+func TestTemplates_RenderContactConsent(t *testing.T) {
+	tmpls, err := loadTemplates(templateFS)
+	if err != nil {
+		t.Fatalf("loadTemplates: %v", err)
+	}
+	renderer := &namedTemplate{root: tmpls}
+	uid := int64(2)
+	regs := []models.Registration{{ID: 1, UserID: &uid, DisplayName: "Ann", Status: models.RegistrationStatusConfirmed, ContactConsent: true}}
+	tourn := &models.Tournament{ID: 7, Name: "Club Rapid", Status: models.TournamentStatusRegistrationOpen}
+	pager := map[string]int{"Page": 1, "Pages": 1, "All": 1, "Total": 1}
+	detail := map[string]interface{}{
+		"User":               &models.User{ID: 3, DisplayName: "Bob"},
+		"Tournament":         tourn,
+		"Registrations":      regs,
+		"RegistrationsPager": pager,
+		"IndividualPager":    pager,
+		"StandingsPager":     pager,
+		"PairingsPager":      pager,
+	}
+
+	// Signing up asks for consent.
+	var buf bytes.Buffer
+	if err := renderer.ExecuteTemplate(&buf, "tournament_detail.html", detail); err != nil {
+		t.Fatalf("render detail: %v", err)
+	}
+	if out := buf.String(); !strings.Contains(out, `name="contact_consent"`) || strings.Contains(out, "/contact-consent") {
+		t.Error("sign-up form lacks the consent checkbox")
+	}
+
+	// Once registered, the player can withdraw it.
+	detail["User"] = &models.User{ID: 2, DisplayName: "Ann"}
+	detail["MyRegistration"] = &regs[0]
+	buf.Reset()
+	if err := renderer.ExecuteTemplate(&buf, "tournament_detail.html", detail); err != nil {
+		t.Fatalf("render detail: %v", err)
+	}
+	for _, want := range []string{`action="/tournaments/7/contact-consent"`, "Withdraw"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("detail page lacks %q", want)
+		}
+	}
+
+	// Only admins get the mailing list.
+	data := map[string]interface{}{
+		"Tournament":         tourn,
+		"Tab":                "players",
+		"IsCoOrganizer":      true,
+		"Registrations":      regs,
+		"RegistrationRows":   regs,
+		"RegistrationsPager": pager,
+		"ContactCount":       1,
+	}
+	for _, admin := range []bool{false, true} {
+		data["IsAdmin"] = admin
+		buf.Reset()
+		if err := renderer.ExecuteTemplate(&buf, "tournament_manage_players.html", data); err != nil {
+			t.Fatalf("render players: %v", err)
+		}
+		if got := strings.Contains(buf.String(), "/export/mailing-list.csv"); got != admin {
+			t.Errorf("admin %v: mailing list link shown = %v", admin, got)
+		}
+	}
+}
+
+func TestTemplates_RenderDeletionRequests(t *testing.T) {
+	tmpls, err := loadTemplates(templateFS)
+	if err != nil {
+		t.Fatalf("loadTemplates: %v", err)
+	}
+	renderer := &namedTemplate{root: tmpls}
+	asked := time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC)
+	ann := models.User{ID: 2, DisplayName: "Ann", Email: "ann@example.com", DeletionRequestedAt: &asked}
+	admin := models.User{ID: 1, DisplayName: "Admin", Email: "admin@example.com", Roles: []string{models.RoleAdmin}}
+
+	var buf bytes.Buffer
+	if err := renderer.ExecuteTemplate(&buf, "admin_users.html", map[string]interface{}{
+		"User":             &admin,
+		"Users":            []models.User{admin, ann},
+		"DeletionRequests": []models.User{ann},
+	}); err != nil {
+		t.Fatalf("render admin_users.html: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{"Deletion Requests (1)", "Ann (ann@example.com)", `action="/admin/users/2/anonymize"`, `action="/admin/users/2/delete"`, "deletion requested"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("users page lacks %q", want)
+		}
+	}
+	if strings.Contains(out, `action="/admin/users/1/delete"`) {
+		t.Error("users page offers to delete the admin's own account")
+	}
+
+	for _, tc := range []struct {
+		user *models.User
+		want string
+	}{
+		{&models.User{ID: 3, DisplayName: "Bob"}, "Request Deletion"},
+		{&ann, `name="withdraw"`},
+	} {
+		buf.Reset()
+		if err := renderer.ExecuteTemplate(&buf, "profile.html", map[string]interface{}{"User": tc.user}); err != nil {
+			t.Fatalf("render profile.html: %v", err)
+		}
+		if !strings.Contains(buf.String(), tc.want) || !strings.Contains(buf.String(), `action="/profile/deletion-request"`) {
+			t.Errorf("profile of %s lacks %q", tc.user.DisplayName, tc.want)
+		}
+	}
+}
+
+func TestTemplates_RenderAnalytics(t *testing.T) {
+	tmpls, err := loadTemplates(templateFS)
+	if err != nil {
+		t.Fatalf("loadTemplates: %v", err)
+	}
+	renderer := &namedTemplate{root: tmpls}
+	peak := time.Date(2026, 5, 1, 14, 30, 0, 0, time.UTC)
+	var buf bytes.Buffer
+	if err := renderer.ExecuteTemplate(&buf, "tournament_analytics.html", map[string]interface{}{
+		"Tournament": &models.Tournament{ID: 7, Name: "Regionals", TimeZone: "UTC"},
+		"Analytics": &models.Analytics{
+			PeakViewers: 42, PeakAt: &peak, Viewers: 5, Views: 130,
+			Rounds: []models.RoundViews{{Round: 0, Tournament: 30}, {Round: 1, Tournament: 20, Pairings: 60, Standings: 20}},
+		},
+	}); err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"<strong>42</strong> watching at most", `datetime="2026-05-01T14:30:00Z"`, "<strong>5</strong> watching now",
+		"<strong>130</strong> page views", "<td>Before round 1</td>", "<td>100</td>", "/api/v1/tournaments/7/analytics",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("analytics page lacks %q", want)
+		}
+	}
+}
+
+func TestTemplates_RenderDiagnostics(t *testing.T) {
+	tmpls, err := loadTemplates(templateFS)
+	if err != nil {
+		t.Fatalf("loadTemplates: %v", err)
+	}
+	renderer := &namedTemplate{root: tmpls}
+	ann := engine.DiagnosticPlayer{ID: 1, Name: "Ann", Points: 3}
+	bob := engine.DiagnosticPlayer{ID: 2, Name: "Bob", Points: 0}
+	cat := engine.DiagnosticPlayer{ID: 3, Name: "Cat", Points: 3}
+	var buf bytes.Buffer
+	if err := renderer.ExecuteTemplate(&buf, "tournament_diagnostics.html", map[string]interface{}{
+		"Tournament": &models.Tournament{ID: 7, Name: "Regionals"},
+		"Diagnostics": &engine.PairingDiagnostics{
+			Rounds: []engine.RoundDiagnostics{{
+				Round: 2, Draft: true, Tables: 1,
+				Rematches:   []engine.FlaggedPairing{{Table: 1, PlayerA: ann, PlayerB: bob, Met: 1}},
+				CrossRecord: []engine.FlaggedPairing{{Table: 1, PlayerA: ann, PlayerB: bob, Met: 1}},
+				Byes:        []engine.RoundBye{{Player: cat, Byes: 2, Lower: 1}},
+			}},
+			Rematches: 1, CrossRecord: 1, RepeatByes: 1,
+			Byes: []engine.PlayerByes{{Player: cat, Rounds: []int{1, 2}}},
+		},
+	}); err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"<strong>1</strong> rematch<", "<strong>1</strong> repeat bye<", "Round 2 <span class=\"badge\">Draft</span>",
+		"<td>Ann (3)</td>", "met 1 time before", "Across records", "Bye: Cat (3) <span class=\"badge\">Bye 2</span>",
+		"1 paired player on fewer points", "<td>1, 2</td>", "/api/v1/tournaments/7/diagnostics",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("diagnostics page lacks %q", want)
+		}
+	}
+}
+
+func TestTemplates_RenderPossibleDuplicates(t *testing.T) {
+	tmpls, err := loadTemplates(templateFS)
+	if err != nil {
+		t.Fatalf("loadTemplates: %v", err)
+	}
+	renderer := &namedTemplate{root: tmpls}
+	uid, club := int64(3), "Northside"
+	regs := []models.Registration{
+		{ID: 1, DisplayName: "John Smith", Status: models.RegistrationStatusConfirmed, Club: &club},
+		{ID: 2, DisplayName: "Jon Smith", Status: models.RegistrationStatusPending, UserID: &uid},
+	}
+	data := map[string]interface{}{
+		"Tournament":         &models.Tournament{ID: 7, Name: "Club Rapid", Status: models.TournamentStatusRegistrationOpen},
+		"Tab":                "players",
+		"IsCoOrganizer":      true,
+		"Registrations":      regs,
+		"RegistrationRows":   regs,
+		"RegistrationsPager": map[string]int{"Page": 1, "Pages": 1, "All": 2, "Total": 2},
+		"PossibleDuplicates": []engine.PossibleDuplicate{{Registration: regs[1], Existing: regs[0]}},
+	}
+	var buf bytes.Buffer
+	if err := renderer.ExecuteTemplate(&buf, "tournament_manage_players.html", data); err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"Possible Duplicates (1)", "<td>John Smith</td><td>Jon Smith</td>", "<td>guest</td><td>account</td>",
+		"<td>Northside</td><td>—</td>", `name="keep_id" value="1"`, `name="duplicate_id" value="1"`,
+		"Merge into John Smith", "Merge into Jon Smith", `action="/tournaments/7/registrations/2/distinct"`,
+		`name="other_id" value="1"`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("players page lacks %q", want)
+		}
+	}
+}
+
+func TestTemplates_RenderBotCheck(t *testing.T) {
+	tmpls, err := loadTemplates(templateFS)
+	if err != nil {
+		t.Fatalf("loadTemplates: %v", err)
+	}
+	renderer := &namedTemplate{root: tmpls}
+	tourn := &models.Tournament{ID: 7, Name: "Club Rapid", Status: models.TournamentStatusRegistrationOpen, BotCheck: true}
+	for _, withCheck := range []bool{false, true} {
+		data := map[string]interface{}{
+			"User":       &models.User{ID: 3, DisplayName: "Ann"},
+			"Tournament": tourn,
+		}
+		if withCheck {
+			data["BotChallenge"] = pow.Challenge(7, 3)
+			data["BotBits"] = pow.Bits
+		}
+		var buf bytes.Buffer
+		if err := renderer.ExecuteTemplate(&buf, "tournament_detail.html", data); err != nil {
+			t.Fatalf("render: %v", err)
+		}
+		for _, s := range []string{`data-pow="openswiss-register:7:3"`, `data-pow-bits="17"`, `name="pow_nonce"`} {
+			if strings.Contains(buf.String(), s) != withCheck {
+				t.Errorf("bot check %v: has %q = %v", withCheck, s, !withCheck)
+			}
+		}
+	}
+}
+
+func TestTemplates_RenderPresets(t *testing.T) {
+	tmpls, err := loadTemplates(templateFS)
+	if err != nil {
+		t.Fatalf("loadTemplates: %v", err)
+	}
+	renderer := &namedTemplate{root: tmpls}
+	rounds := 4
+	preset := &models.Preset{ID: 5, Name: "Weekly", Settings: models.PresetSettings{
+		NumRounds: &rounds, BestOf: 3, PointsWin: 3, PointsDraw: 1,
+		PairingFields: []models.PresetField{{Label: "Board"}, {Label: "Judge"}},
+	}}
+	user := &models.User{ID: 1, DisplayName: "Org", Roles: []string{models.RoleOrganizer}}
+	var buf bytes.Buffer
+	if err := renderer.ExecuteTemplate(&buf, "presets.html", map[string]interface{}{
+		"User": user, "Presets": []models.Preset{*preset},
+	}); err != nil {
+		t.Fatalf("render presets: %v", err)
+	}
+	for _, want := range []string{`href="/tournaments/new?preset=5"`, `href="/presets/5/export"`, "swiss, 4 rounds, best of 3, 3/1/0 points, 2 pairing fields"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("presets page missing %s", want)
+		}
+	}
+
+	form := &models.Tournament{MinPlayers: models.DefaultMinPlayers}
+	preset.Settings.Apply(form)
+	buf.Reset()
+	if err := renderer.ExecuteTemplate(&buf, "tournament_new.html", map[string]interface{}{
+		"User": user, "Form": form, "Presets": []models.Preset{*preset}, "Preset": preset,
+	}); err != nil {
+		t.Fatalf("render new: %v", err)
+	}
+	for _, want := range []string{`<option value="5" selected>Weekly</option>`, `name="preset_id" value="5"`, "pairing fields: Board, Judge.", `name="num_rounds" value="4"`} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("new tournament form missing %s", want)
+		}
+	}
+}
+
+func TestTemplates_RenderBlindFinalRound(t *testing.T) {
+	tmpls, err := loadTemplates(templateFS)
+	if err != nil {
+		t.Fatalf("loadTemplates: %v", err)
+	}
+	renderer := &namedTemplate{root: tmpls}
+	tourn := &models.Tournament{ID: 7, Name: "Club Rapid", Status: models.TournamentStatusInProgress, BlindFinalRound: true}
+	pager := map[string]int{"Page": 1, "Pages": 1, "All": 1, "Total": 1}
+	data := map[string]interface{}{
+		"Tournament":         tourn,
+		"StandingsHidden":    true,
+		"Hidden":             true,
+		"CurrentRound":       3,
+		"RegistrationsPager": pager,
+		"IndividualPager":    map[string]int{},
+		"StandingsPager":     map[string]int{},
+		"PairingsPager":      pager,
+	}
+	const notice = "Standings are hidden during the final round."
+	for _, page := range []string{"tournament_detail.html", "tournament_share.html", "display_standings.html"} {
+		var buf bytes.Buffer
+		if err := renderer.ExecuteTemplate(&buf, page, data); err != nil {
+			t.Fatalf("render %s: %v", page, err)
+		}
+		if !strings.Contains(buf.String(), notice) {
+			t.Errorf("%s lacks the notice", page)
+		}
+	}
+
+	// Staff see the standings, told that players don't.
+	data["CanManage"] = true
+	data["StandingsPager"] = pager
+	data["Standings"] = []swisstools.PlayerStanding{{PlayerID: 1, Name: "Ann", Rank: 1, Points: 6}}
+	var buf bytes.Buffer
+	if err := renderer.ExecuteTemplate(&buf, "tournament_detail.html", data); err != nil {
+		t.Fatalf("render detail: %v", err)
+	}
+	if out := buf.String(); strings.Contains(out, notice) || !strings.Contains(out, "Ann") || !strings.Contains(out, "until the final round ends") {
+		t.Error("staff view of the blind standings")
+	}
+}
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"time"
+
+	"github.com/dstathis/openswiss/internal/db"
+	"github.com/dstathis/openswiss/internal/engine"
+	"github.com/dstathis/openswiss/internal/models"
+	"github.com/dstathis/swisstools"
+)
+
+// simulation is the made-up tournament "openswiss simulate" plays.
+type simulation struct {
+	Players   int
+	Rounds    int // 0 for the recommended number
+	Pairing   string
+	BestOf    int
+	DrawRate  float64
+	Seed      int64
+	Name      string
+	Organizer string // email of the organizer's account
+}
+
+// define adds the simulation's flags to fs.
+func (s *simulation) define(fs *flag.FlagSet) {
+	fs.IntVar(&s.Players, "players", 64, "number of players")
+	fs.IntVar(&s.Rounds, "rounds", 0, "number of rounds; 0 for the recommended number, or a full cycle of a round robin")
+	fs.StringVar(&s.Pairing, "pairing", models.PairingSwiss, "pairing algorithm: swiss, weighted, danish or round_robin")
+	fs.IntVar(&s.BestOf, "best-of", 3, "games per match; 0 leaves the match format unset")
+	fs.Float64Var(&s.DrawRate, "draws", 0.05, "share of matches drawn, 0 to 1")
+	fs.Int64Var(&s.Seed, "seed", 0, "random seed; 0 picks one from the clock")
+	fs.StringVar(&s.Name, "name", "", "tournament name (default \"Simulation <time>\")")
+	fs.StringVar(&s.Organizer, "organizer", "", "email of the account that organizes the tournament (required)")
+}
+
+// check validates the flags.
+func (s *simulation) check() error {
+	var errs []error
+	if s.Players < models.DefaultMinPlayers {
+		errs = append(errs, fmt.Errorf("-players: at least %d are needed", models.DefaultMinPlayers))
+	}
+	if s.Rounds < 0 {
+		errs = append(errs, errors.New("-rounds: can't be negative"))
+	}
+	if !models.ValidPairingAlgorithm(s.Pairing) {
+		errs = append(errs, fmt.Errorf("-pairing: unknown algorithm %q", s.Pairing))
+	}
+	if s.BestOf < 0 || s.BestOf > models.MaxBestOf {
+		errs = append(errs, fmt.Errorf("-best-of: must be between 0 and %d", models.MaxBestOf))
+	}
+	if s.DrawRate < 0 || s.DrawRate > 1 {
+		errs = append(errs, errors.New("-draws: must be between 0 and 1"))
+	}
+	if s.Organizer == "" {
+		errs = append(errs, errors.New("-organizer: the email of an existing account is required"))
+	}
+	return errors.Join(errs...)
+}
+
+// randomScore draws a result of t's match format from player A's side: a
+// draw with probability drawRate, else a win for either player with the
+// loser taking a random number of games. Without a format it plays best of
+// three.
+func randomScore(rng *rand.Rand, t *models.Tournament, drawRate float64) (wins, losses, draws int) {
+	need := t.GamesToWin()
+	if need == 0 {
+		need = 2
+	}
+	if rng.Float64() < drawRate {
+		if need == 1 {
+			return 0, 0, 1
+		}
+		return 1, 1, 0
+	}
+	wins, losses = need, rng.Intn(need)
+	if rng.Intn(2) == 0 {
+		wins, losses = losses, wins
+	}
+	return wins, losses, 0
+}
+
+// runSimulate plays a made-up tournament of guest players end to end
+// through the same storage and engine code as the server: it creates the
+// tournament, registers the players, starts it, enters a random result for
+// every match and advances until it finishes. It prints how long each step
+// took and the pairing diagnostics (see engine.DiagnosePairings), for
+// load-testing pages and checking pairings at scale. The tournament is left
+// in the database to look at.
+func runSimulate(args []string) {
+	sim := &simulation{}
+	cfg := mustLoadConfig("simulate", args, sim.define)
+	if err := sim.check(); err != nil {
+		fatal("invalid simulation", "err", err)
+	}
+	if sim.Seed == 0 {
+		sim.Seed = time.Now().UnixNano()
+	}
+
+	database, err := openDB(cfg.DatabaseURL)
+	if err != nil {
+		fatal("connect db", "err", err)
+	}
+	defer database.Close()
+
+	id, err := simulate(context.Background(), database, cfg.Defaults, sim, os.Stdout)
+	if err != nil {
+		fatal("simulate", "err", err)
+	}
+	fmt.Printf("%s/tournaments/%d\n", cfg.BaseURL, id)
+}
+
+// simulate plays sim against database, reporting to out, and returns the
+// tournament's ID.
+func simulate(ctx context.Context, database *sql.DB, defaults models.TournamentDefaults, sim *simulation, out io.Writer) (int64, error) {
+	organizer, err := db.GetUserByEmail(ctx, database, sim.Organizer)
+	if err != nil {
+		return 0, fmt.Errorf("organizer %s: %w", sim.Organizer, err)
+	}
+	rng := rand.New(rand.NewSource(sim.Seed))
+	step := time.Now()
+	lap := func(format string, args ...any) {
+		fmt.Fprintf(out, "%-36s %v\n", fmt.Sprintf(format, args...), time.Since(step).Round(time.Millisecond))
+		step = time.Now()
+	}
+
+	t := &models.Tournament{OrganizerID: organizer.ID, Status: models.TournamentStatusRegistrationOpen}
+	defaults.Apply(t)
+	t.Name = sim.Name
+	if t.Name == "" {
+		t.Name = "Simulation " + time.Now().Format("2006-01-02 15:04:05")
+	}
+	t.MaxPlayers = 0
+	t.PairingAlgorithm = sim.Pairing
+	t.BestOf = sim.BestOf
+	if sim.Rounds > 0 {
+		t.NumRounds = &sim.Rounds
+	} else {
+		t.AutoRounds = true
+	}
+	if err := db.CreateTournament(ctx, database, t); err != nil {
+		return 0, fmt.Errorf("create tournament: %w", err)
+	}
+	id := t.ID
+	fmt.Fprintf(out, "tournament %d %q, seed %d\n", id, t.Name, sim.Seed)
+
+	for i := 1; i <= sim.Players; i++ {
+		if _, err := db.CreateGuestRegistration(ctx, database, id, fmt.Sprintf("Player %d", i)); err != nil {
+			return id, fmt.Errorf("register player %d: %w", i, err)
+		}
+	}
+	lap("register %d players", sim.Players)
+
+	regs, err := db.ListRegistrations(ctx, database, id)
+	if err != nil {
+		return id, err
+	}
+	if err := runAction(ctx, database, id, regs, engine.ActionStart); err != nil {
+		return id, fmt.Errorf("start: %w", err)
+	}
+	lap("start and pair round 1")
+
+	for round := 1; ; round++ {
+		err := engine.WithTournamentEngine(ctx, database, id,
+			func(tx *sql.Tx, t *models.Tournament, eng *swisstools.Tournament) (string, error) {
+				for _, p := range eng.GetRound() {
+					if p.PlayerB() == swisstools.BYE_OPPONENT_ID {
+						continue
+					}
+					wins, losses, draws := randomScore(rng, t, sim.DrawRate)
+					if err := engine.RecordResult(ctx, tx, t, eng, p.PlayerA(), wins, losses, draws); err != nil {
+						return "", err
+					}
+				}
+				return "", nil
+			})
+		if err != nil {
+			return id, fmt.Errorf("round %d results: %w", round, err)
+		}
+		lap("round %d results", round)
+
+		if err := runAction(ctx, database, id, regs, engine.ActionNextRound); err != nil {
+			return id, fmt.Errorf("advance round %d: %w", round, err)
+		}
+		if t, err = db.GetTournament(ctx, database, id); err != nil {
+			return id, err
+		}
+		if t.Status == models.TournamentStatusFinished {
+			lap("finish")
+			break
+		}
+		lap("pair round %d", round+1)
+	}
+
+	eng, err := swisstools.LoadTournament(t.EngineState)
+	if err != nil {
+		return id, err
+	}
+	if regs, err = db.ListRegistrations(ctx, database, id); err != nil {
+		return id, err
+	}
+	engine.Standings(&eng, t.TiebreakOrder(), engine.TiebreakSeeds(regs))
+	lap("standings")
+	d := engine.DiagnosePairings(t, &eng)
+	fmt.Fprintf(out, "%d rounds: %d rematches, %d matches across records, %d repeat byes\n",
+		len(d.Rounds), d.Rematches, d.CrossRecord, d.RepeatByes)
+	return id, nil
+}
+
+// runAction runs lifecycle action on tournament id as its organizer would.
+func runAction(ctx context.Context, database *sql.DB, id int64, regs []models.Registration, action string) error {
+	return engine.WithTournamentEngine(ctx, database, id,
+		func(tx *sql.Tx, t *models.Tournament, eng *swisstools.Tournament) (string, error) {
+			return engine.RunAction(ctx, tx, t, eng, regs, action)
+		})
+}
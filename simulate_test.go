@@ -0,0 +1,55 @@
+package main
+
+import (
+	"io"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/dstathis/openswiss/internal/engine"
+	"github.com/dstathis/openswiss/internal/models"
+)
+
+func TestRandomScore(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for _, bestOf := range []int{0, 1, 3, 5} {
+		tourn := &models.Tournament{BestOf: bestOf}
+		for _, drawRate := range []float64{0, 0.5, 1} {
+			for i := 0; i < 200; i++ {
+				wins, losses, draws := randomScore(rng, tourn, drawRate)
+				if err := engine.CheckScore(tourn, wins, losses, draws); err != nil {
+					t.Fatalf("best of %d: %d-%d-%d: %v", bestOf, wins, losses, draws, err)
+				}
+				if drawn := wins == losses; drawRate == 0 && drawn || drawRate == 1 && !drawn {
+					t.Fatalf("best of %d, draw rate %v: %d-%d-%d", bestOf, drawRate, wins, losses, draws)
+				}
+			}
+		}
+	}
+}
+
+func TestSimulationFlags(t *testing.T) {
+	sim := &simulation{}
+	cfg, err := loadConfig("simulate", []string{"-players", "128", "-rounds", "7", "-pairing", "weighted", "-organizer", "to@example.com"},
+		env(map[string]string{"DATABASE_URL": "postgres://db"}), io.Discard, sim.define)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.DatabaseURL != "postgres://db" || sim.Players != 128 || sim.Rounds != 7 || sim.Pairing != models.PairingWeighted || sim.BestOf != 3 {
+		t.Errorf("simulation = %+v", sim)
+	}
+	if err := sim.check(); err != nil {
+		t.Errorf("check: %v", err)
+	}
+
+	bad := &simulation{Players: 1, Rounds: -1, Pairing: "random", BestOf: 10, DrawRate: 2}
+	err = bad.check()
+	if err == nil {
+		t.Fatal("bad simulation accepted")
+	}
+	for _, want := range []string{"-players", "-rounds", "-pairing", "-best-of", "-draws", "-organizer"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error doesn't mention %s:\n%v", want, err)
+		}
+	}
+}
@@ -2,55 +2,94 @@
 // served from /static and locked down by a strict Content-Security-Policy
 // (no inline scripts, no inline event handlers).
 
-// Theme: apply the saved preference before the body paints to avoid a flash
-// of the wrong theme. Runs at parse time, before DOMContentLoaded.
+// Theme: the server renders the page in the theme from the `theme` cookie,
+// and the toggle is a form posting to /theme so it works without scripts.
+// With scripts the toggle flips the theme in place and writes the same
+// cookie. A choice saved by older versions in localStorage is carried over
+// into the cookie once; runs at parse time, before the body paints.
 (function () {
     var t = localStorage.getItem('theme');
-    if (t) document.documentElement.setAttribute('data-theme', t);
+    if ((t === 'light' || t === 'dark') && !/(^|; )theme=/.test(document.cookie)) {
+        setThemeCookie(t);
+        document.documentElement.setAttribute('data-theme', t);
+    }
+    localStorage.removeItem('theme');
 })();
 
-function toggleTheme() {
+function setThemeCookie(t) {
+    var secure = location.protocol === 'https:' ? '; secure' : '';
+    document.cookie = 'theme=' + t + '; path=/; max-age=31536000; samesite=lax' + secure;
+}
+
+// toggleTheme switches to the theme on the button that submitted the form:
+// light or dark from the theme toggle, or high contrast on and off.
+function toggleTheme(e) {
+    e.preventDefault();
     var root = document.documentElement;
-    var current = root.getAttribute('data-theme');
-    var next = current === 'light' ? 'dark' : 'light';
+    var next = e.submitter && e.submitter.value;
+    if (!next) next = root.getAttribute('data-theme') === 'light' ? 'dark' : 'light';
     root.setAttribute('data-theme', next);
-    localStorage.setItem('theme', next);
-    updateIcon(next);
+    setThemeCookie(next);
+    syncToggle();
 }
 
-function updateIcon(t) {
-    var el = document.querySelector('.theme-icon');
-    if (el) el.textContent = t === 'light' ? '🌙' : '☀️';
+// syncToggle points the toggles at the themes not currently shown.
+function syncToggle() {
+    var t = document.documentElement.getAttribute('data-theme');
+    var btn = document.querySelector('.theme-toggle');
+    if (btn) btn.value = t === 'light' ? 'dark' : 'light';
+    var icon = document.querySelector('.theme-icon');
+    if (icon) icon.textContent = t === 'light' ? '🌙' : '☀️';
+    var contrast = document.querySelector('.contrast-toggle');
+    if (contrast) {
+        contrast.value = t === 'contrast' ? 'dark' : 'contrast';
+        contrast.setAttribute('aria-pressed', String(t === 'contrast'));
+    }
 }
 
 document.addEventListener('DOMContentLoaded', function () {
-    // Theme toggle button.
-    var themeBtn = document.querySelector('.theme-toggle');
-    if (themeBtn) themeBtn.addEventListener('click', toggleTheme);
-    updateIcon(localStorage.getItem('theme') || 'dark');
+    // Theme toggle.
+    var themeForm = document.querySelector('.theme-form');
+    if (themeForm) themeForm.addEventListener('submit', toggleTheme);
+    syncToggle();
 
     // Mobile nav hamburger.
     var navBtn = document.querySelector('.nav-toggle');
     var navLinks = document.querySelector('.nav-links');
     if (navBtn && navLinks) {
         navBtn.addEventListener('click', function () {
-            navLinks.classList.toggle('open');
+            var open = navLinks.classList.toggle('open');
+            navBtn.setAttribute('aria-expanded', String(open));
+        });
+        // Escape closes the open menu and hands focus back to its button.
+        document.addEventListener('keydown', function (e) {
+            if (e.key !== 'Escape' || !navLinks.classList.contains('open')) return;
+            navLinks.classList.remove('open');
+            navBtn.setAttribute('aria-expanded', 'false');
+            navBtn.focus();
         });
     }
 
-    // Auto-inject the CSRF token (read from cookie) into every POST form so
-    // we don't have to add a hidden input to each template by hand.
-    var match = document.cookie.match(/(?:^|;\s*)csrf_token=([^;]+)/);
-    if (match) {
-        var tok = match[1];
-        document.querySelectorAll('form[method="POST"],form[method="post"]').forEach(function (f) {
-            if (f.querySelector('input[name="csrf_token"]')) return;
-            var input = document.createElement('input');
-            input.type = 'hidden';
-            input.name = 'csrf_token';
-            input.value = tok;
-            f.prepend(input);
-        });
+    // Schedule times: the server writes them in the tournament's time zone;
+    // show them in the viewer's, keeping the venue time as a tooltip.
+    // Countdowns are looked up on every tick, so those in fragments swapped
+    // in later tick too.
+    localizeTimes();
+    var tick = function () { tickCountdowns(document.querySelectorAll('.countdown[data-until]')); };
+    tick();
+    setInterval(tick, 1000);
+
+    // Projector pages: scroll slowly through lists longer than the screen,
+    // then reload for fresh results once the refresh interval has passed.
+    // Without scripts a <noscript> meta refresh reloads them instead.
+    if (document.body.classList.contains('display')) {
+        runDisplay(Number(document.body.dataset.refresh) || 30);
+    }
+
+    // Stream overlays: fetch the page again every refresh interval and swap
+    // its content in place. A reload would flash blank in the broadcast.
+    if (document.body.classList.contains('overlay') && window.fetch) {
+        runOverlay(Number(document.body.dataset.refresh) || 10);
     }
 
     // Generic confirm-on-submit. Replaces inline `onsubmit="return confirm(...)"`
@@ -64,4 +103,242 @@ document.addEventListener('DOMContentLoaded', function () {
             }
         }
     }, true);
+
+    // Bot Check: a sign-up form marked `data-pow="<challenge>"` first finds
+    // a nonce whose SHA-256 with the challenge starts with data-pow-bits
+    // zero bits (see internal/pow), then submits it in pow_nonce.
+    document.addEventListener('submit', function (e) {
+        var form = e.target;
+        if (e.defaultPrevented || !form.dataset || !form.dataset.pow) return;
+        e.preventDefault();
+        var button = form.querySelector('button[type="submit"]');
+        if (button) {
+            button.disabled = true;
+            button.textContent = form.dataset.powWorking || '…';
+        }
+        solvePow(form.dataset.pow, Number(form.dataset.powBits) || 17, function (nonce) {
+            form.elements.pow_nonce.value = nonce;
+            form.submit();
+        });
+    });
+
+    // Dashboard fragments: a form marked `data-update="<id>"` posts in the
+    // background, then the element with that id is refreshed from the
+    // fragment URL in its data-fragment, keeping the page's search and page.
+    // Without scripts the form posts and redirects as usual.
+    document.addEventListener('submit', function (e) {
+        var form = e.target;
+        if (e.defaultPrevented || !form.dataset || !form.dataset.update || !window.fetch) return;
+        var target = document.getElementById(form.dataset.update);
+        if (!target || !target.dataset.fragment) return;
+        e.preventDefault();
+        postAndRefresh(form, target);
+    });
+
+    // A fragment marked `data-refresh="<seconds>"` also reloads on its own
+    // every so often, so a dashboard left open keeps up with reports.
+    document.querySelectorAll('[data-fragment][data-refresh]').forEach(function (target) {
+        var seconds = parseInt(target.dataset.refresh, 10);
+        if (!(seconds > 0) || !window.fetch) return;
+        setInterval(function () { refreshFragment(target); }, seconds * 1000);
+    });
 });
+
+// refreshFragment reloads target from its fragment URL, leaving it as it is
+// if that fails; the next refresh tries again.
+function refreshFragment(target) {
+    fetch(target.dataset.fragment + location.search, { credentials: 'same-origin' })
+        .then(function (res) {
+            if (!res.ok || res.redirected) throw new Error(res.statusText);
+            return res.text();
+        })
+        .then(function (html) { target.innerHTML = html; })
+        .catch(function () {});
+}
+
+// postAndRefresh submits form with fetch and swaps target's contents for
+// its fresh fragment. Handlers answer a done action with a redirect, which
+// is not followed; a refused one shows its error above the fragment.
+function postAndRefresh(form, target) {
+    var buttons = form.querySelectorAll('button');
+    buttons.forEach(function (b) { b.disabled = true; });
+    fetch(form.action, {
+        method: 'POST',
+        body: new URLSearchParams(new FormData(form)),
+        credentials: 'same-origin',
+        redirect: 'manual'
+    }).then(function (res) {
+        if (res.type !== 'opaqueredirect' && !res.ok) {
+            return res.text().then(function (msg) {
+                buttons.forEach(function (b) { b.disabled = false; });
+                fragmentError(target, msg.trim() || res.statusText);
+            });
+        }
+        return fetch(target.dataset.fragment + location.search, { credentials: 'same-origin' })
+            .then(function (res) {
+                // A redirect here is the login page: the session is gone.
+                if (!res.ok || res.redirected) throw new Error(res.statusText);
+                return res.text();
+            })
+            .then(function (html) { target.innerHTML = html; });
+    }).catch(function () {
+        // The action may or may not have gone through; show the page as it is.
+        location.reload();
+    });
+}
+
+function fragmentError(target, msg) {
+    var p = target.querySelector('.fragment-error');
+    if (!p) {
+        p = document.createElement('p');
+        p.className = 'error fragment-error';
+        p.setAttribute('role', 'alert');
+        target.insertBefore(p, target.firstChild);
+    }
+    p.textContent = msg;
+}
+
+// solvePow counts up from 0 until challenge + ':' + nonce hashes to bits
+// leading zero bits, then calls done with the nonce. It works in slices so
+// the page stays responsive.
+function solvePow(challenge, bits, done) {
+    var nonce = 0;
+    (function slice() {
+        for (var end = nonce + 5000; nonce < end; nonce++) {
+            if (leadingZeroBits(sha256(challenge + ':' + nonce)) >= bits) {
+                done(String(nonce));
+                return;
+            }
+        }
+        setTimeout(slice, 0);
+    })();
+}
+
+function leadingZeroBits(words) {
+    for (var i = 0; i < words.length; i++) {
+        if (words[i] !== 0) return i * 32 + Math.clz32(words[i]);
+    }
+    return words.length * 32;
+}
+
+// sha256 hashes an ASCII string, returning the digest as eight 32-bit words.
+// The browser's crypto.subtle is async per hash and missing on plain HTTP,
+// so Bot Check does its own.
+var sha256K = [
+    0x428a2f98, 0x71374491, 0xb5c0fbcf, 0xe9b5dba5, 0x3956c25b, 0x59f111f1, 0x923f82a4, 0xab1c5ed5,
+    0xd807aa98, 0x12835b01, 0x243185be, 0x550c7dc3, 0x72be5d74, 0x80deb1fe, 0x9bdc06a7, 0xc19bf174,
+    0xe49b69c1, 0xefbe4786, 0x0fc19dc6, 0x240ca1cc, 0x2de92c6f, 0x4a7484aa, 0x5cb0a9dc, 0x76f988da,
+    0x983e5152, 0xa831c66d, 0xb00327c8, 0xbf597fc7, 0xc6e00bf3, 0xd5a79147, 0x06ca6351, 0x14292967,
+    0x27b70a85, 0x2e1b2138, 0x4d2c6dfc, 0x53380d13, 0x650a7354, 0x766a0abb, 0x81c2c92e, 0x92722c85,
+    0xa2bfe8a1, 0xa81a664b, 0xc24b8b70, 0xc76c51a3, 0xd192e819, 0xd6990624, 0xf40e3585, 0x106aa070,
+    0x19a4c116, 0x1e376c08, 0x2748774c, 0x34b0bcb5, 0x391c0cb3, 0x4ed8aa4a, 0x5b9cca4f, 0x682e6ff3,
+    0x748f82ee, 0x78a5636f, 0x84c87814, 0x8cc70208, 0x90befffa, 0xa4506ceb, 0xbef9a3f7, 0xc67178f2
+];
+
+function sha256(s) {
+    var n = s.length, blocks = ((n + 8) >> 6) + 1, m = new Array(blocks * 16).fill(0);
+    for (var i = 0; i < n; i++) m[i >> 2] |= s.charCodeAt(i) << (24 - (i % 4) * 8);
+    m[n >> 2] |= 0x80 << (24 - (n % 4) * 8);
+    m[blocks * 16 - 1] = n * 8;
+    var h = [0x6a09e667, 0xbb67ae85, 0x3c6ef372, 0xa54ff53a, 0x510e527f, 0x9b05688c, 0x1f83d9ab, 0x5be0cd19];
+    var w = new Array(64);
+    var rotr = function (x, r) { return (x >>> r) | (x << (32 - r)); };
+    for (var b = 0; b < blocks; b++) {
+        for (var t = 0; t < 64; t++) {
+            if (t < 16) {
+                w[t] = m[b * 16 + t];
+            } else {
+                var s0 = rotr(w[t - 15], 7) ^ rotr(w[t - 15], 18) ^ (w[t - 15] >>> 3);
+                var s1 = rotr(w[t - 2], 17) ^ rotr(w[t - 2], 19) ^ (w[t - 2] >>> 10);
+                w[t] = (w[t - 16] + s0 + w[t - 7] + s1) | 0;
+            }
+        }
+        var a = h[0], c = h[2], d = h[3], e = h[4], f = h[5], g = h[6], k = h[7], bb = h[1];
+        for (t = 0; t < 64; t++) {
+            var t1 = (k + (rotr(e, 6) ^ rotr(e, 11) ^ rotr(e, 25)) + ((e & f) ^ (~e & g)) + sha256K[t] + w[t]) | 0;
+            var t2 = ((rotr(a, 2) ^ rotr(a, 13) ^ rotr(a, 22)) + ((a & bb) ^ (a & c) ^ (bb & c))) | 0;
+            k = g; g = f; f = e; e = (d + t1) | 0;
+            d = c; c = bb; bb = a; a = (t1 + t2) | 0;
+        }
+        h[0] = (h[0] + a) | 0; h[1] = (h[1] + bb) | 0; h[2] = (h[2] + c) | 0; h[3] = (h[3] + d) | 0;
+        h[4] = (h[4] + e) | 0; h[5] = (h[5] + f) | 0; h[6] = (h[6] + g) | 0; h[7] = (h[7] + k) | 0;
+    }
+    return h.map(function (x) { return x >>> 0; });
+}
+
+function localizeTimes() {
+    var lang = document.documentElement.lang || undefined;
+    document.querySelectorAll('time.local-time[datetime]').forEach(function (el) {
+        var d = new Date(el.getAttribute('datetime'));
+        if (isNaN(d)) return;
+        el.title = el.textContent;
+        el.textContent = d.toLocaleString(lang, { dateStyle: 'medium', timeStyle: 'short' });
+    });
+}
+
+// tickCountdowns rewrites each countdown as H:MM:SS until its data-until
+// time, the format the server renders, and blanks it once that has passed.
+function tickCountdowns(els) {
+    var now = Date.now();
+    els.forEach(function (el) {
+        var s = Math.round((new Date(el.dataset.until) - now) / 1000);
+        if (!(s > 0)) {
+            el.textContent = '';
+            return;
+        }
+        var m = Math.floor(s / 60) % 60, sec = s % 60;
+        el.textContent = Math.floor(s / 3600) + ':' + (m < 10 ? '0' : '') + m + ':' + (sec < 10 ? '0' : '') + sec;
+    });
+}
+
+// runDisplay drives a projector page: wait, scroll to the bottom at a
+// readable pace, wait again, and reload no sooner than refresh seconds after
+// the page loaded. Reloads start back at the top.
+// runOverlay refreshes an overlay's content every refresh seconds, keeping
+// what it shows if a fetch fails; the next one tries again.
+function runOverlay(refresh) {
+    var main = document.querySelector('.overlay-main');
+    setInterval(function () {
+        fetch(location.href, { credentials: 'omit', cache: 'no-store' })
+            .then(function (res) {
+                if (!res.ok) throw new Error(res.statusText);
+                return res.text();
+            })
+            .then(function (html) {
+                var fresh = new DOMParser().parseFromString(html, 'text/html').querySelector('.overlay-main');
+                if (fresh) main.innerHTML = fresh.innerHTML;
+            })
+            .catch(function () {});
+    }, refresh * 1000);
+}
+
+function runDisplay(refresh) {
+    var loaded = Date.now();
+    var pause = 5000;
+    if ('scrollRestoration' in history) history.scrollRestoration = 'manual';
+    window.scrollTo(0, 0);
+
+    function reloadWhenDue() {
+        var wait = Math.max(refresh * 1000 - (Date.now() - loaded), 0);
+        setTimeout(function () { location.reload(); }, wait);
+    }
+
+    function atBottom() {
+        return window.innerHeight + window.scrollY >= document.documentElement.scrollHeight - 1;
+    }
+
+    function step() {
+        if (atBottom()) {
+            setTimeout(reloadWhenDue, pause);
+            return;
+        }
+        window.scrollBy(0, 1);
+        setTimeout(step, 30);
+    }
+
+    if (atBottom()) {
+        reloadWhenDue();
+    } else {
+        setTimeout(step, pause);
+    }
+}